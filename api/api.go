@@ -0,0 +1,15 @@
+// Package api embeds the published OpenAPI contract of the HTTP API.
+//
+// openapi.yaml is the source of truth for request/response shapes and must be
+// updated together with handler changes; pkg/client is generated from it
+// (task generate:client).
+package api
+
+import (
+	_ "embed"
+)
+
+// OpenAPISpec is the raw OpenAPI 3 document (YAML).
+//
+//go:embed openapi.yaml
+var OpenAPISpec []byte
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
+	"github.com/luminosita/change-me/pkg/loadtest"
+)
+
+// runBench implements the `bench` subcommand.
+//
+// Usage:
+//
+//	api bench [-url http://localhost:8000/health] [-local -path /health]
+//	          [-c 10] [-n 1000] [-d 10s] [-p95 50ms] [-p99 100ms] [-max-error-rate 0.01]
+//
+// With -local the fully wired router is served in-process on a random port,
+// so middleware regressions can be measured without a running deployment.
+// The command exits non-zero when any configured budget is exceeded.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	url := fs.String("url", "http://localhost:8000/health", "Target URL")
+	local := fs.Bool("local", false, "Serve the application in-process and target it")
+	path := fs.String("path", "/health", "Request path when using -local")
+	method := fs.String("method", "GET", "HTTP method")
+	concurrency := fs.Int("c", 10, "Concurrent workers")
+	requests := fs.Int("n", 0, "Total requests (0 = run for -d)")
+	duration := fs.Duration("d", 10*time.Second, "Maximum run duration")
+	timeout := fs.Duration("timeout", 5*time.Second, "Per-request timeout")
+	p50 := fs.Duration("p50", 0, "Fail when p50 latency exceeds this budget")
+	p95 := fs.Duration("p95", 0, "Fail when p95 latency exceeds this budget")
+	p99 := fs.Duration("p99", 0, "Fail when p99 latency exceeds this budget")
+	maxErrorRate := fs.Float64("max-error-rate", 0, "Fail when error rate (0..1) exceeds this budget; 0 allows no errors")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// The error budget is checked only when given, so 0 means no errors
+	thresholds := loadtest.Thresholds{P50: *p50, P95: *p95, P99: *p99}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "max-error-rate" {
+			thresholds.MaxErrorRate = maxErrorRate
+		}
+	})
+
+	target := *url
+	if *local {
+		container, err := dependencies.InitializeContainer()
+		if err != nil {
+			return fmt.Errorf("failed to initialize dependencies: %w", err)
+		}
+		defer container.Close()
+
+		srv := httptest.NewServer(httpserver.New(container).Router())
+		defer srv.Close()
+		target = srv.URL + "/" + strings.TrimPrefix(*path, "/")
+	}
+
+	report, err := loadtest.Run(context.Background(), loadtest.Options{
+		URL:         target,
+		Method:      strings.ToUpper(*method),
+		Concurrency: *concurrency,
+		Requests:    *requests,
+		Duration:    *duration,
+		Timeout:     *timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "target:      %s %s\n%s", strings.ToUpper(*method), target, report)
+
+	if err := report.Check(thresholds); err != nil {
+		return fmt.Errorf("latency budget exceeded: %w", err)
+	}
+
+	return nil
+}
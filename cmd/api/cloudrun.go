@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// cloudRunShutdownGrace is the time Cloud Run leaves between SIGTERM and
+// SIGKILL.
+const cloudRunShutdownGrace = 10 * time.Second
+
+// checkCloudRun logs the Cloud Run service and warns about settings that
+// do not hold up there: background work stalled by CPU throttling between
+// requests, and shutdowns outlasting the SIGTERM grace period.
+func checkCloudRun(cfg *config.Config, mode string, log *logger.Logger) {
+	if !cfg.CloudRun {
+		return
+	}
+	log.Infow("cloud_run_mode",
+		"service", os.Getenv("K_SERVICE"),
+		"revision", os.Getenv("K_REVISION"),
+		"port", cfg.Port,
+		"cpu_throttled", cfg.CloudRunCPUThrottled,
+	)
+
+	if cfg.CloudRunCPUThrottled {
+		var background []string
+		if mode != modeAPI {
+			background = append(background, "workers")
+		}
+		if len(cfg.HeartbeatURLs) > 0 {
+			background = append(background, "heartbeat")
+		}
+		if len(background) > 0 {
+			log.Warnw("background_work_throttled", "work", background,
+				"reason", "CPU is only allocated during requests; use instance-based billing and set CLOUD_RUN_CPU_THROTTLED=false")
+		}
+	}
+	if cfg.ServerShutdownTimeout > cloudRunShutdownGrace {
+		log.Warnw("shutdown_timeout_exceeds_grace",
+			"shutdown_timeout", cfg.ServerShutdownTimeout.String(),
+			"grace", cloudRunShutdownGrace.String(),
+		)
+	}
+}
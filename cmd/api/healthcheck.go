@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+)
+
+// runHealthcheck implements the `healthcheck` subcommand. It probes the
+// readiness endpoint of the local server and fails unless it answers 2xx,
+// so container images can declare a HEALTHCHECK without shipping curl.
+//
+// Usage:
+//
+//	api healthcheck [-url http://127.0.0.1:8000/health/ready] [-timeout 3s]
+func runHealthcheck(args []string) error {
+	fs := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+	target := fs.String("url", "", "Endpoint to probe (default: /health/ready on HOST:PORT)")
+	timeout := fs.Duration("timeout", 3*time.Second, "Request timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *target == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		*target = healthcheckURL(cfg.Host, cfg.Port)
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get(*target)
+	if err != nil {
+		return fmt.Errorf("healthcheck %s: %w", *target, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("healthcheck %s: status %d", *target, resp.StatusCode)
+	}
+	return nil
+}
+
+// healthcheckURL returns the readiness URL of a server listening on
+// host:port, probing loopback when it listens on all interfaces.
+func healthcheckURL(host string, port int) string {
+	switch host {
+	case "", "0.0.0.0":
+		host = "127.0.0.1"
+	case "::", "[::]":
+		host = "::1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port)) + "/health/ready"
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/pkg/heartbeat"
+)
+
+// newHeartbeat builds the reporter pushing health status to the monitors in
+// HEARTBEAT_URLS, or returns nil when none are configured.
+func newHeartbeat(container *dependencies.Container) *heartbeat.Reporter {
+	cfg := container.Config
+	if len(cfg.HeartbeatURLs) == 0 {
+		return nil
+	}
+
+	instance, _ := os.Hostname()
+	opts := heartbeat.Options{
+		URLs:       cfg.HeartbeatURLs,
+		Interval:   cfg.HeartbeatInterval,
+		Timeout:    cfg.HeartbeatTimeout,
+		Retries:    cfg.HeartbeatRetries,
+		FailSuffix: cfg.HeartbeatFailSuffix,
+		Service:    cfg.AppName,
+		Version:    cfg.AppVersion,
+		Instance:   instance,
+	}
+	return heartbeat.New(opts, container.HTTPClient, heartbeatStatus(container), container.Logger)
+}
+
+// heartbeatStatus reports unhealthy while a configured shared dependency is
+// unreachable.
+func heartbeatStatus(container *dependencies.Container) func(context.Context) string {
+	return func(ctx context.Context) string {
+		if container.Redis != nil {
+			if err := container.Redis.Ping(ctx).Err(); err != nil {
+				return heartbeat.StatusUnhealthy
+			}
+		}
+		return heartbeat.StatusHealthy
+	}
+}
@@ -1,27 +1,218 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/luminosita/change-me/internal/config"
 	"github.com/luminosita/change-me/internal/core/dependencies"
 	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
+	"github.com/luminosita/change-me/pkg/httpclient"
+	"github.com/luminosita/change-me/pkg/startup"
+	"golang.org/x/sync/errgroup"
 )
 
+// processStart approximates the process start time for startup timing.
+var processStart = time.Now()
+
+// command is a CLI subcommand entry point.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the available CLI subcommands.
+var commands = map[string]command{
+	"serve":       {summary: "Start the HTTP server and/or workers (default)", run: serve},
+	"bench":       {summary: "Drive load and report latency percentiles", run: runBench},
+	"seed":        {summary: "Run development seed data", run: runSeed},
+	"replay":      {summary: "Replay recorded requests against an instance", run: runReplay},
+	"run":         {summary: "Run a registered one-shot task", run: runTask},
+	"service":     {summary: "Install or control the server as a system service", run: runService},
+	"snapshot":    {summary: "Diff the routes and OpenAPI shapes against the committed snapshot", run: runSnapshot},
+	"healthcheck": {summary: "Probe the local readiness endpoint (container HEALTHCHECK)", run: runHealthcheck},
+}
+
+// exitError carries a specific process exit code for a command failure.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
 // @title CHANGE_ME API
 // @version 0.1.0
 // @description Go HTTP server with health check, logging, and dependency injection
 // @host localhost:8000
 // @BasePath /
 func main() {
+	if err := run(os.Args[1:]); err != nil {
+		code := 1
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+		}
+		log.Printf("Error: %v", err)
+		os.Exit(code)
+	}
+}
+
+// run dispatches to the requested subcommand.
+// Without a subcommand the HTTP server is started.
+func run(args []string) error {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	return cmd.run(args)
+}
+
+// usage prints the available subcommands.
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// serve initializes dependencies and runs the HTTP server, the background
+// workers, or both (selected with -mode) until SIGINT/SIGTERM. With -mock
+// it serves example responses of the OpenAPI contract instead.
+func serve(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serveContext(ctx, args)
+}
+
+// serveContext runs the serve command until ctx is canceled, then shuts
+// down gracefully.
+func serveContext(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	mode := fs.String("mode", modeAPI, "process mode: api, worker or all")
+	var mock mockFlags
+	fs.BoolVar(&mock.enabled, "mock", false, "Serve example responses of the OpenAPI contract without handlers")
+	fs.StringVar(&mock.spec, "mock-spec", "", "OpenAPI document path or URL to mock (default: embedded contract)")
+	fs.DurationVar(&mock.opts.Latency, "mock-latency", 0, "Latency added to every mock response")
+	fs.DurationVar(&mock.opts.Jitter, "mock-jitter", 0, "Random extra latency up to this value")
+	fs.Float64Var(&mock.opts.ErrorRate, "mock-error-rate", 0, "Fraction of mock requests answered with an error (0-1)")
+	fs.IntVar(&mock.opts.ErrorStatus, "mock-error-status", http.StatusInternalServerError, "Status of injected mock errors")
+	fs.BoolVar(&mock.opts.SkipValidation, "mock-no-validate", false, "Serve mock requests that do not match the contract")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if mock.enabled {
+		return serveMock(ctx, mock)
+	}
+	if err := validateMode(*mode); err != nil {
+		return err
+	}
+
+	// Startup phases are timed from process start
+	timer := startup.New(processStart)
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	timer.Mark(startup.PhaseConfig)
+
 	// Initialize dependency container with Wire
-	container, err := dependencies.InitializeContainer()
+	container, err := dependencies.InitializeContainerFrom(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize dependencies: %w", err)
+	}
+	log := container.Logger
+	timer.Mark(startup.PhaseDependencies)
+
+	// Size the runtime to the container before starting work
+	if err := tuneRuntime(container.Config, log); err != nil {
+		return fmt.Errorf("failed to tune runtime: %w", err)
+	}
+	checkCloudRun(container.Config, *mode, log)
+
+	// Seed development data before accepting traffic
+	if err := seedOnStartup(container); err != nil {
+		return err
+	}
+
+	workers, err := newWorkerRegistry(container)
 	if err != nil {
-		log.Fatalf("Failed to initialize dependencies: %v", err)
+		return fmt.Errorf("failed to register workers: %w", err)
 	}
+	timer.Mark(startup.PhaseRuntime)
 
-	// Create and start HTTP server
-	server := httpserver.New(container)
-	if err := server.Start(); err != nil {
-		log.Fatalf("Server error: %v", err)
+	// A failing listener or worker cancels the group so the process exits
+	group, ctx := errgroup.WithContext(ctx)
+	if *mode != modeWorker {
+		server := httpserver.New(container).WithStartup(timer)
+		timer.Mark(startup.PhaseRoutes)
+		group.Go(func() error { return server.Run(ctx) })
 	}
+	if *mode == modeAPI && container.Notifications != nil && container.Redis == nil {
+		// The in-process queue is only consumed by workers of this process
+		log.Warnw("notifications_not_delivered", "mode", *mode,
+			"reason", "in-process queue without workers; set REDIS_URL or use -mode all")
+	}
+	if *mode != modeAPI {
+		log.Infow("workers_starting", "mode", *mode, "workers", workers.Names())
+		group.Go(func() error { return workers.Run(ctx, log) })
+	}
+	if *mode == modeWorker {
+		// Without a server, startup ends once the workers are started
+		timer.Report(log, container.Config.StartupBudget)
+	}
+	if reporter := newHeartbeat(container); reporter != nil {
+		log.Infow("heartbeat_starting", "monitors", len(container.Config.HeartbeatURLs), "interval", container.Config.HeartbeatInterval)
+		group.Go(func() error { return reporter.Run(ctx) })
+	}
+	if *mode != modeWorker && container.Synthetic != nil {
+		// Results feed /health/details, which only the server exposes
+		log.Infow("synthetic_checks_starting", "checks", container.Synthetic.Names())
+		group.Go(func() error { return container.Synthetic.Run(ctx) })
+	}
+
+	if interval := container.Config.HTTPClientPoolReportInterval; interval > 0 {
+		reporter := httpclient.NewPoolReporter(container.HTTPClients, interval, log)
+		group.Go(func() error { return reporter.Run(ctx) })
+	}
+
+	runErr := group.Wait()
+
+	// Close dependencies shared by the server and workers
+	if err := container.Close(); err != nil {
+		log.Errorw("dependencies_close_error", "error", err)
+		if runErr == nil {
+			runErr = err
+		}
+	}
+	if runErr != nil {
+		return fmt.Errorf("%s error: %w", *mode, runErr)
+	}
+
+	log.Infow("application_shutdown_complete", "mode", *mode)
+	return nil
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/api"
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/middleware"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/mockserver"
+)
+
+// mockFlags are the serve flags of mock mode.
+type mockFlags struct {
+	enabled bool
+	spec    string
+	opts    mockserver.Options
+}
+
+// serveMock serves example responses of the OpenAPI contract on HOST:PORT
+// until ctx is canceled. No dependencies are initialized, so frontends can
+// develop against operations whose handlers do not exist yet.
+func serveMock(ctx context.Context, flags mockFlags) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	log, err := logger.New(logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer func() { _ = log.Sync() }()
+
+	spec, err := readSpec(flags.spec)
+	if err != nil {
+		return err
+	}
+	mock, err := mockserver.New(spec, flags.opts)
+	if err != nil {
+		return err
+	}
+
+	// Browsers call the mock cross-origin like the real API
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.Recovery(), middleware.CORS(middleware.CORSConfig{
+		AllowOrigins:  cfg.CORSAllowOrigins,
+		ExposeHeaders: cfg.CORSExposeHeaders,
+		MaxAge:        cfg.CORSMaxAge,
+	}))
+	router.NoRoute(gin.WrapH(mock))
+
+	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+	log.Infow("mock_server_started",
+		"address", addr,
+		"spec", specName(flags.spec),
+		"latency", flags.opts.Latency,
+		"jitter", flags.opts.Jitter,
+		"error_rate", flags.opts.ErrorRate,
+		"validate", !flags.opts.SkipValidation,
+	)
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			return fmt.Errorf("mock server error: %w", err)
+		}
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ServerShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("mock server shutdown: %w", err)
+	}
+	log.Infow("mock_server_stopped")
+	return nil
+}
+
+// readSpec reads the OpenAPI document at path, an http(s) URL, or the
+// embedded contract when path is empty.
+func readSpec(path string) ([]byte, error) {
+	if path == "" {
+		return api.OpenAPISpec, nil
+	}
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.ReadFile(path)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(path)
+	if err != nil {
+		return nil, fmt.Errorf("fetch OpenAPI spec: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch OpenAPI spec: status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// specName names the served document in logs.
+func specName(path string) string {
+	if path == "" {
+		return "embedded"
+	}
+	return path
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/pkg/recording"
+)
+
+// headerFlags collects repeated -H "Name: value" flags.
+type headerFlags http.Header
+
+func (h headerFlags) String() string { return "" }
+
+func (h headerFlags) Set(v string) error {
+	name, value, ok := strings.Cut(v, ":")
+	if !ok {
+		return fmt.Errorf("invalid header %q, expected \"Name: value\"", v)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+// runReplay implements the `replay` subcommand.
+//
+// Usage:
+//
+//	api replay [-dir ./recordings] [-target http://localhost:8000] [-H "Authorization: Bearer ..."] [-strict]
+//	api replay -source object [-prefix recordings] [-target ...]
+//
+// Recorded requests are re-sent in order and each response status is compared
+// with the recorded one. With -strict any mismatch makes the command fail.
+// With -source object the recordings are read from the object store of
+// OBJECT_STORAGE_PROVIDER, as written by RECORDER_STORAGE=object.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	source := fs.String("source", "dir", "Where recordings are read from: dir or object")
+	dir := fs.String("dir", "./recordings", "Directory containing recordings")
+	prefix := fs.String("prefix", dependencies.RecordingsPrefix, "Object key prefix of the recordings (-source object)")
+	target := fs.String("target", "http://localhost:8000", "Base URL of the instance to replay against")
+	timeout := fs.Duration("timeout", 10*time.Second, "Per-request timeout")
+	strict := fs.Bool("strict", false, "Fail when any replayed status differs from the recording")
+	headers := headerFlags{}
+	fs.Var(headers, "H", "Extra header sent with every request (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := readRecordings(*source, *dir, *prefix)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	results := recording.Replay(context.Background(), client, *target, entries, http.Header(headers))
+
+	mismatches := 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			mismatches++
+			fmt.Fprintf(os.Stdout, "ERR  %s %s: %v\n", r.Entry.Request.Method, r.Entry.Request.Path, r.Err)
+		case !r.StatusMatches():
+			mismatches++
+			fmt.Fprintf(os.Stdout, "DIFF %s %s: recorded %d, got %d (%s)\n",
+				r.Entry.Request.Method, r.Entry.Request.Path, r.Entry.Response.Status, r.Status, r.Duration)
+		default:
+			fmt.Fprintf(os.Stdout, "OK   %s %s: %d (%s)\n",
+				r.Entry.Request.Method, r.Entry.Request.Path, r.Status, r.Duration)
+		}
+	}
+	fmt.Fprintf(os.Stdout, "replayed %d requests, %d mismatches\n", len(results), mismatches)
+
+	if *strict && mismatches > 0 {
+		return fmt.Errorf("%d replayed requests did not match their recordings", mismatches)
+	}
+	return nil
+}
+
+// readRecordings loads the recordings of source, a directory or the
+// configured object store.
+func readRecordings(source, dir, prefix string) ([]recording.Entry, error) {
+	switch source {
+	case "dir":
+		return recording.ReadDir(dir)
+	case "object":
+		container, err := dependencies.InitializeContainer()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize dependencies: %w", err)
+		}
+		defer container.Close()
+
+		objects, ok := container.ObjectStore.(recording.Objects)
+		if !ok {
+			return nil, fmt.Errorf("-source object requires OBJECT_STORAGE_PROVIDER")
+		}
+		return recording.ReadObjects(context.Background(), objects, prefix)
+	default:
+		return nil, fmt.Errorf("unknown recording source %q, expected dir or object", source)
+	}
+}
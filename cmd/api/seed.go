@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/internal/core/seed"
+	"github.com/luminosita/change-me/internal/core/seed/fixtures"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+)
+
+// sampleUserCount is the number of users created by the users seeder.
+const sampleUserCount = 25
+
+// newSeedRegistry collects the seeders contributed by application modules.
+// Modules register their seeders here as they are added.
+func newSeedRegistry(container *dependencies.Container) (*seed.Registry, error) {
+	registry := seed.NewRegistry()
+
+	if err := registry.Register(
+		newUserSeeder(container.UserService),
+	); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// newUserSeeder creates sample users from the shared fixtures.
+// Existing users (by email) are left untouched, so reruns are no-ops.
+func newUserSeeder(service users.UserService) seed.Seeder {
+	return seed.New("users", nil, func(ctx context.Context) error {
+		for i := 1; i <= sampleUserCount; i++ {
+			sample := fixtures.NewSampleUser(
+				fixtures.WithUserID(i),
+				fixtures.WithUserEmail(fmt.Sprintf("user%02d@example.com", i)),
+				fixtures.WithUsername(fmt.Sprintf("user%02d", i)),
+				fixtures.WithUserActive(i%5 != 0),
+			)
+
+			_, err := service.Create(ctx, users.CreateInput{
+				Email:    sample.Email,
+				Username: sample.Username,
+				FullName: fmt.Sprintf("%s %02d", sample.FullName, i),
+				IsActive: sample.IsActive,
+			})
+			if err != nil && !errors.Is(err, users.ErrEmailTaken) && !errors.Is(err, users.ErrUsernameTaken) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// runSeed implements the `seed` subcommand.
+//
+// Usage:
+//
+//	api seed [-only users,orders] [-list]
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	only := fs.String("only", "", "Comma-separated list of seeders to run")
+	list := fs.Bool("list", false, "List registered seeders and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	container, err := dependencies.InitializeContainer()
+	if err != nil {
+		return fmt.Errorf("failed to initialize dependencies: %w", err)
+	}
+	defer container.Close()
+
+	registry, err := newSeedRegistry(container)
+	if err != nil {
+		return fmt.Errorf("failed to register seeders: %w", err)
+	}
+
+	if *list {
+		for _, name := range registry.Names() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	var names []string
+	if *only != "" {
+		names = strings.Split(*only, ",")
+	}
+
+	ctx := tracecontext.FromEnv(context.Background())
+	report, err := registry.Run(ctx, container.Config.Environment, container.Logger, names...)
+	if err != nil {
+		return err
+	}
+
+	container.Logger.Infow("seed_complete",
+		"applied", report.Applied,
+		"skipped", report.Skipped,
+		"trace_id", tracecontext.TraceID(ctx),
+	)
+	return nil
+}
+
+// seedOnStartup runs all seeders when SEED_ON_STARTUP is enabled in the
+// development environment. It is a no-op in every other profile.
+func seedOnStartup(container *dependencies.Container) error {
+	cfg := container.Config
+	if !cfg.SeedOnStartup || cfg.Environment != constants.EnvDevelopment {
+		return nil
+	}
+
+	registry, err := newSeedRegistry(container)
+	if err != nil {
+		return fmt.Errorf("failed to register seeders: %w", err)
+	}
+
+	if _, err := registry.Run(context.Background(), cfg.Environment, container.Logger); err != nil {
+		return fmt.Errorf("startup seeding failed: %w", err)
+	}
+
+	return nil
+}
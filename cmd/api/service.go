@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kardianos/service"
+)
+
+// Actions of the `service` subcommand.
+const (
+	serviceInstall   = "install"
+	serviceUninstall = "uninstall"
+	serviceStart     = "start"
+	serviceStop      = "stop"
+	serviceRestart   = "restart"
+	serviceStatus    = "status"
+	serviceRun       = "run"
+)
+
+// exitServiceUsage is the exit code of a missing or unknown action.
+const exitServiceUsage = 2
+
+// program runs the serve command under the service manager. Stopping the
+// service cancels its context, so it shuts down like on SIGTERM.
+type program struct {
+	args   []string
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// Start implements service.Interface; it must return quickly, so serving
+// happens in the background.
+func (p *program) Start(service.Service) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel, p.done = cancel, make(chan error, 1)
+	go func() {
+		err := serveContext(ctx, p.args)
+		if ctx.Err() == nil {
+			// Serving ended without a stop request, e.g. the listener
+			// failed: exit so the service manager restarts the process
+			code := 0
+			if err != nil {
+				log.Printf("Error: %v", err)
+				code = 1
+			}
+			os.Exit(code)
+		}
+		p.done <- err
+	}()
+	return nil
+}
+
+// Stop implements service.Interface, waiting for the graceful shutdown
+// (bounded by SERVER_SHUTDOWN_TIMEOUT) to complete.
+func (p *program) Stop(service.Service) error {
+	p.cancel()
+	return <-p.done
+}
+
+// runService implements the `service` subcommand, which installs and
+// controls the binary as a Windows service, launchd daemon or systemd
+// unit. Flags after install are passed to serve when the service runs.
+//
+// Usage:
+//
+//	api service [-name api] [-user svc] [-workdir /srv/api] install [-mode all]
+//	api service [-name api] start|stop|restart|status|uninstall
+//	api service [-name api] run [serve flags]   (started by the service manager)
+func runService(args []string) error {
+	fs := flag.NewFlagSet("service", flag.ExitOnError)
+	name := fs.String("name", defaultServiceName(), "Service name")
+	user := fs.String("user", "", "Account the service runs as (default: the service manager's)")
+	workdir := fs.String("workdir", "", "Working directory holding .env and relative paths (default: current directory)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return &exitError{code: exitServiceUsage, err: errors.New("service action required: install, uninstall, start, stop, restart, status or run")}
+	}
+	action, serveArgs := fs.Arg(0), fs.Args()[1:]
+
+	if *workdir == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		*workdir = dir
+	}
+	prg := &program{args: serveArgs}
+	svc, err := service.New(prg, serviceConfig(*name, *user, *workdir, serveArgs))
+	if err != nil {
+		return fmt.Errorf("service %s: %w", *name, err)
+	}
+
+	switch action {
+	case serviceRun:
+		// Windows services start in the system directory
+		if err := os.Chdir(*workdir); err != nil {
+			return fmt.Errorf("service %s: %w", *name, err)
+		}
+		return svc.Run()
+	case serviceStatus:
+		status, err := svc.Status()
+		if err != nil {
+			return fmt.Errorf("service %s: %w", *name, err)
+		}
+		fmt.Printf("%s (%s): %s\n", *name, svc.Platform(), statusName(status))
+		return nil
+	case serviceInstall, serviceUninstall, serviceStart, serviceStop, serviceRestart:
+		if err := service.Control(svc, action); err != nil {
+			return err
+		}
+		fmt.Printf("%s (%s): %s done\n", *name, svc.Platform(), action)
+		return nil
+	default:
+		return &exitError{code: exitServiceUsage, err: fmt.Errorf("unknown service action %q", action)}
+	}
+}
+
+// serviceConfig describes the service: the manager runs `service run`
+// with the serve flags, restarts it when it fails, and starts it after
+// the network is up.
+func serviceConfig(name, user, workdir string, serveArgs []string) *service.Config {
+	return &service.Config{
+		Name:             name,
+		DisplayName:      name,
+		Description:      "CHANGE_ME API server",
+		UserName:         user,
+		WorkingDirectory: workdir,
+		Arguments:        append([]string{"service", "-name", name, "-workdir", workdir, serviceRun}, serveArgs...),
+		Dependencies:     []string{"After=network-online.target", "Wants=network-online.target"},
+		Option: service.KeyValue{
+			"Restart":   "on-failure", // systemd
+			"RunAtLoad": true,         // launchd
+			"OnFailure": "restart",    // Windows
+		},
+	}
+}
+
+// defaultServiceName is the executable name without extension.
+func defaultServiceName() string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+	return strings.TrimSuffix(filepath.Base(exe), filepath.Ext(exe))
+}
+
+// statusName returns a readable service status.
+func statusName(status service.Status) string {
+	switch status {
+	case service.StatusRunning:
+		return "running"
+	case service.StatusStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
+	"github.com/luminosita/change-me/pkg/apisnapshot"
+)
+
+// defaultSnapshotFile is the committed API snapshot.
+const defaultSnapshotFile = "api/snapshot.txt"
+
+// runSnapshot implements the `snapshot` subcommand.
+//
+// Usage:
+//
+//	api snapshot [-file api/snapshot.txt] [-spec api/openapi.yaml] [-update] [-breaking]
+//
+// The mounted route table and the OpenAPI contract are compared with the
+// committed snapshot and the differences printed. Any difference fails
+// the command; with -breaking only changes that can break clients do.
+// Routes depend on the configuration, so run it with the environment CI
+// uses. -update rewrites the snapshot after intended changes.
+func runSnapshot(args []string) error {
+	flags := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	file := flags.String("file", defaultSnapshotFile, "Committed snapshot to compare with")
+	specPath := flags.String("spec", "", "OpenAPI document path or URL (default: embedded contract)")
+	update := flags.Bool("update", false, "Rewrite the snapshot instead of comparing")
+	breaking := flags.Bool("breaking", false, "Only fail on changes that can break existing clients")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	current, err := currentSnapshot(*specPath)
+	if err != nil {
+		return err
+	}
+
+	if *update {
+		var buf bytes.Buffer
+		if _, err := current.WriteTo(&buf); err != nil {
+			return err
+		}
+		if err := os.WriteFile(*file, buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("write snapshot: %w", err)
+		}
+		fmt.Printf("Wrote %d lines to %s\n", len(current), *file)
+		return nil
+	}
+
+	committed, err := os.Open(*file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("snapshot %s does not exist; create it with -update", *file)
+	}
+	if err != nil {
+		return err
+	}
+	defer committed.Close()
+	previous, err := apisnapshot.Parse(committed)
+	if err != nil {
+		return err
+	}
+
+	changes := apisnapshot.Diff(previous, current)
+	failing := 0
+	for _, change := range changes {
+		marker := ""
+		if change.Breaking {
+			marker = "  (breaking)"
+		}
+		fmt.Println(change.String() + marker)
+		if change.Breaking || !*breaking {
+			failing++
+		}
+	}
+	if len(changes) == 0 {
+		fmt.Printf("API matches %s\n", *file)
+		return nil
+	}
+	if failing == 0 {
+		fmt.Printf("\n%d compatible changes; update %s with -update\n", len(changes), *file)
+		return nil
+	}
+	return &exitError{code: 1, err: fmt.Errorf("API differs from %s (%d failing changes); rerun with -update if the change is intended", *file, failing)}
+}
+
+// currentSnapshot describes the routes mounted by the server and the
+// OpenAPI document at specPath.
+func currentSnapshot(specPath string) (apisnapshot.Snapshot, error) {
+	spec, err := readSpec(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := dependencies.InitializeContainer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize dependencies: %w", err)
+	}
+	defer container.Close()
+
+	mounted := httpserver.New(container).Router().Routes()
+	routes := make([]apisnapshot.Route, len(mounted))
+	for i, route := range mounted {
+		routes[i] = apisnapshot.Route{Method: route.Method, Path: route.Path}
+	}
+	return apisnapshot.Build(routes, spec)
+}
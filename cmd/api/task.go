@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/internal/core/task"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+)
+
+// Exit codes reported by `run`.
+const (
+	exitTaskUsage       = 2   // Unknown task or invalid arguments
+	exitTaskTimeout     = 124 // Task exceeded -timeout (matches timeout(1))
+	exitTaskInterrupted = 130 // Task canceled by SIGINT/SIGTERM
+)
+
+// newTaskRegistry collects the one-shot tasks contributed by application
+// modules. Modules register their tasks here as they are added.
+func newTaskRegistry(container *dependencies.Container) (*task.Registry, error) {
+	registry := task.NewRegistry()
+
+	if err := registry.Register(
+		newQuotaResetTask(container),
+	); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// newQuotaResetTask clears the current quota counters of the given subjects.
+func newQuotaResetTask(container *dependencies.Container) task.Task {
+	return task.New("quota:reset", "Reset current quota usage: quota:reset <subject>...", func(ctx context.Context, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("%w: at least one subject is required", task.ErrUsage)
+		}
+		for _, subject := range args {
+			if err := container.QuotaService.Reset(ctx, subject); err != nil {
+				return fmt.Errorf("reset %q: %w", subject, err)
+			}
+			container.Logger.Infow("quota_usage_reset", "subject", subject)
+		}
+		return nil
+	})
+}
+
+// runTask implements the `run` subcommand.
+//
+// Usage:
+//
+//	api run [-timeout 30m] <task> [args...]
+//	api run -list
+func runTask(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 30*time.Minute, "Maximum task run time (0 = no limit)")
+	list := fs.Bool("list", false, "List registered tasks and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	container, err := dependencies.InitializeContainer()
+	if err != nil {
+		return fmt.Errorf("failed to initialize dependencies: %w", err)
+	}
+	defer container.Close()
+
+	registry, err := newTaskRegistry(container)
+	if err != nil {
+		return fmt.Errorf("failed to register tasks: %w", err)
+	}
+
+	if *list {
+		for _, t := range registry.List() {
+			fmt.Printf("%-24s %s\n", t.Name(), t.Description())
+		}
+		return nil
+	}
+
+	if fs.NArg() == 0 {
+		return &exitError{code: exitTaskUsage, err: errors.New("task name required (see run -list)")}
+	}
+	name := fs.Arg(0)
+	t, ok := registry.Lookup(name)
+	if !ok {
+		return &exitError{code: exitTaskUsage, err: fmt.Errorf("unknown task %q (see run -list)", name)}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Continue the caller's trace (TRACEPARENT) so task logs correlate with it
+	ctx = tracecontext.FromEnv(ctx)
+
+	err = task.Execute(ctx, t, fs.Args()[1:], *timeout, container.Logger)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, task.ErrUsage):
+		return &exitError{code: exitTaskUsage, err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &exitError{code: exitTaskTimeout, err: fmt.Errorf("task %q timed out after %s", name, *timeout)}
+	case ctx.Err() != nil:
+		return &exitError{code: exitTaskInterrupted, err: fmt.Errorf("task %q interrupted", name)}
+	default:
+		return fmt.Errorf("task %q failed: %w", name, err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/tuning"
+)
+
+// tuneRuntime sizes GOMAXPROCS, GOMEMLIMIT and GOGC from the configuration
+// and container limits, and logs the effective values.
+func tuneRuntime(cfg *config.Config, log *logger.Logger) error {
+	opts := tuning.Options{
+		MaxProcs:         cfg.RuntimeMaxProcs,
+		MemoryLimitRatio: cfg.RuntimeMemoryLimitRatio,
+		GCPercent:        cfg.RuntimeGCPercent,
+	}
+	if cfg.RuntimeMemoryLimit != "" {
+		limit, err := tuning.ParseBytes(cfg.RuntimeMemoryLimit)
+		if err != nil {
+			return err
+		}
+		opts.MemoryLimit = limit
+	}
+
+	res, err := tuning.Apply(opts)
+	if err != nil {
+		return err
+	}
+
+	log.Infow("runtime_tuned",
+		"gomaxprocs", res.MaxProcs, "gomaxprocs_source", res.MaxProcsSource,
+		"gomemlimit", tuning.FormatBytes(res.MemoryLimit), "gomemlimit_source", res.MemoryLimitSource,
+		"gogc", res.GCPercent, "gogc_source", res.GCPercentSource,
+	)
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/internal/core/worker"
+	sdkworker "go.temporal.io/sdk/worker"
+)
+
+// Process modes selected with `serve -mode`.
+const (
+	modeAPI    = "api"    // HTTP listener only
+	modeWorker = "worker" // Background workers only
+	modeAll    = "all"    // HTTP listener and workers in one process
+)
+
+// meteringMonitorInterval is how often dropped metering events are checked.
+const meteringMonitorInterval = time.Minute
+
+// newWorkerRegistry collects the background workers contributed by
+// application modules. Modules register their consumers here as they are added.
+func newWorkerRegistry(container *dependencies.Container) (*worker.Registry, error) {
+	registry := worker.NewRegistry()
+
+	if container.Metering != nil {
+		if err := registry.Register(newMeteringMonitor(container)); err != nil {
+			return nil, err
+		}
+	}
+
+	if container.Notifications != nil {
+		if err := registry.Register(container.Notifications.Worker()); err != nil {
+			return nil, err
+		}
+	}
+
+	if container.Privacy != nil {
+		if err := registry.Register(container.Privacy.Worker()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Temporal workers polling the task queues of the registered workflows
+	if container.Temporal != nil {
+		for _, w := range container.Workflows.Workers(container.Temporal, sdkworker.Options{}) {
+			if err := registry.Register(w); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return registry, nil
+}
+
+// newMeteringMonitor reports usage events dropped because the metering
+// buffer was full, so lost billing data is visible in logs.
+func newMeteringMonitor(container *dependencies.Container) worker.Worker {
+	var reported int64
+	return worker.Every("metering-monitor", meteringMonitorInterval, container.Logger, func(ctx context.Context) error {
+		dropped := container.Metering.Dropped()
+		if dropped > reported {
+			container.Logger.Warnw("metering_events_dropped", "total", dropped, "since_last_check", dropped-reported)
+			reported = dropped
+		}
+		return nil
+	})
+}
+
+// validateMode reports whether mode is a known process mode.
+func validateMode(mode string) error {
+	switch mode {
+	case modeAPI, modeWorker, modeAll:
+		return nil
+	default:
+		return fmt.Errorf("unknown mode %q (want %s, %s or %s)", mode, modeAPI, modeWorker, modeAll)
+	}
+}
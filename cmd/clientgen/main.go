@@ -0,0 +1,926 @@
+// Command clientgen generates a typed API client from an OpenAPI 3
+// document, so consumers of the service get an SDK that follows the
+// published contract.
+//
+// The Go client is generated into pkg/client through go:generate:
+//
+//	//go:generate go run github.com/luminosita/change-me/cmd/clientgen -spec ../../api/openapi.yaml -output client_gen.go
+//
+// It declares a type per component schema and a Client method per
+// operation, named after its operationId. The document can also be fetched
+// from a running instance serving it (-spec http://localhost:8080/docs/openapi.yaml),
+// and -lang ts writes a TypeScript client built on fetch instead.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("clientgen: ")
+
+	spec := flag.String("spec", "", "OpenAPI document path or http(s) URL (required)")
+	lang := flag.String("lang", "go", "client language: go or ts")
+	output := flag.String("output", "", "output file (default client_gen.go or client.ts)")
+	pkg := flag.String("package", "client", "package of the Go client")
+	flag.Parse()
+
+	if *spec == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = map[string]string{"go": "client_gen.go", "ts": "client.ts"}[*lang]
+	}
+
+	data, err := readSpec(*spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(data, *lang, *pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(*output), 0o755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readSpec reads the document at a path or http(s) URL.
+func readSpec(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", location, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// generate returns the client source for lang of the OpenAPI document data.
+func generate(data []byte, lang, pkg string) ([]byte, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("load spec: %w", err)
+	}
+	m, err := buildModel(doc, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch lang {
+	case "go":
+		if err := goTemplate.Execute(&buf, m); err != nil {
+			return nil, err
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("format generated code: %w\n%s", err, buf.Bytes())
+		}
+		return src, nil
+	case "ts":
+		if err := tsTemplate.Execute(&buf, m); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown language %q", lang)
+	}
+}
+
+// model describes the client for the templates.
+type model struct {
+	Package    string
+	Title      string
+	Version    string
+	Types      []typeDef
+	Operations []operation
+	Imports    []string // Of the Go client
+}
+
+// typeDef is a schema type: a struct when Fields are set, an alias of
+// GoType/TSType otherwise.
+type typeDef struct {
+	Name   string
+	Doc    string
+	Fields []field
+	GoType string
+	TSType string
+}
+
+type field struct {
+	Name     string // Go field name
+	JSON     string
+	GoType   string
+	TSType   string
+	Doc      string
+	Optional bool
+}
+
+type operation struct {
+	Name    string // Go method name
+	TSName  string
+	Method  string
+	Path    string
+	Summary string
+
+	PathParams []param
+	Query      []param
+	Headers    []param
+	ParamsType string // Struct of the query and header parameters, if any
+
+	// Body is the Go type of a JSON request body, or "io.Reader" for other
+	// media types sent with ContentType
+	Body        string
+	TSBody      string
+	ContentType string
+
+	// Result is the Go type of a JSON response; Stream marks other media
+	// types returned as a body to close
+	Result   string
+	TSResult string
+	Stream   bool
+	Accept   string
+}
+
+type param struct {
+	Name     string // Go argument or field name
+	TSName   string
+	Key      string // Name in the request
+	GoType   string
+	TSType   string
+	Repeated bool // An array sent as repeated keys
+	Optional bool
+}
+
+// builder accumulates the types referenced while building the model.
+type builder struct {
+	types   map[string]typeDef
+	imports map[string]bool
+}
+
+// buildModel converts doc into the template model, in a stable order.
+func buildModel(doc *openapi3.T, pkg string) (*model, error) {
+	b := &builder{types: make(map[string]typeDef), imports: map[string]bool{"context": true}}
+	m := &model{Package: pkg}
+	if doc.Info != nil {
+		m.Title, m.Version = doc.Info.Title, doc.Info.Version
+	}
+
+	if doc.Components != nil {
+		names := make([]string, 0, len(doc.Components.Schemas))
+		for name := range doc.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := b.namedType(name, doc.Components.Schemas[name].Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	paths := doc.Paths.Map()
+	keys := make([]string, 0, len(paths))
+	for path := range paths {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+	seen := make(map[string]string)
+	for _, path := range keys {
+		ops := paths[path].Operations()
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions} {
+			op := ops[method]
+			if op == nil {
+				continue
+			}
+			built, err := b.operation(method, path, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if other, ok := seen[built.Name]; ok {
+				return nil, fmt.Errorf("%s %s: operationId %q already used by %s", method, path, op.OperationID, other)
+			}
+			seen[built.Name] = method + " " + path
+			m.Operations = append(m.Operations, built)
+		}
+	}
+
+	names := make([]string, 0, len(b.types))
+	for name := range b.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m.Types = append(m.Types, b.types[name])
+	}
+	for imp := range b.imports {
+		m.Imports = append(m.Imports, imp)
+	}
+	sort.Strings(m.Imports)
+	return m, nil
+}
+
+// namedType declares the type name for schema.
+func (b *builder) namedType(name string, schema *openapi3.Schema) error {
+	def := typeDef{Name: name, Doc: docLine(schema.Description)}
+	if isStruct(schema) {
+		required := make(map[string]bool, len(schema.Required))
+		for _, r := range schema.Required {
+			required[r] = true
+		}
+		keys := make([]string, 0, len(schema.Properties))
+		for key := range schema.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop := schema.Properties[key]
+			goType, tsType, err := b.typeOf(prop, name+exported(key))
+			if err != nil {
+				return fmt.Errorf("schema %s: property %s: %w", name, key, err)
+			}
+			f := field{Name: exported(key), JSON: key, GoType: goType, TSType: tsType, Optional: !required[key]}
+			if prop.Value != nil {
+				f.Doc = docLine(prop.Value.Description)
+			}
+			if f.Optional && pointable(prop) {
+				f.GoType = "*" + f.GoType
+			}
+			def.Fields = append(def.Fields, f)
+		}
+	} else {
+		var err error
+		def.GoType, def.TSType, err = b.typeOf(&openapi3.SchemaRef{Value: schema}, name)
+		if err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	b.types[name] = def
+	return nil
+}
+
+// typeOf returns the Go and TypeScript types of ref, declaring inline
+// object schemas as name.
+func (b *builder) typeOf(ref *openapi3.SchemaRef, name string) (string, string, error) {
+	if ref == nil || ref.Value == nil {
+		return "any", "unknown", nil
+	}
+	if ref.Ref != "" {
+		named := refName(ref.Ref)
+		return named, named, nil
+	}
+
+	s := ref.Value
+	if len(s.AllOf) == 1 && s.Type == nil && len(s.Properties) == 0 {
+		// A lone allOf wraps a reference, e.g. to make it nullable
+		return b.typeOf(s.AllOf[0], name)
+	}
+	switch {
+	case s.Type.Is("string"):
+		switch s.Format {
+		case "date-time":
+			b.imports["time"] = true
+			return "time.Time", "string", nil
+		case "binary":
+			return "[]byte", "Blob", nil
+		}
+		return "string", "string", nil
+	case s.Type.Is("integer"):
+		switch s.Format {
+		case "int32":
+			return "int32", "number", nil
+		case "int64":
+			return "int64", "number", nil
+		}
+		return "int", "number", nil
+	case s.Type.Is("number"):
+		if s.Format == "float" {
+			return "float32", "number", nil
+		}
+		return "float64", "number", nil
+	case s.Type.Is("boolean"):
+		return "bool", "boolean", nil
+	case s.Type.Is("array"):
+		goType, tsType, err := b.typeOf(s.Items, name+"Item")
+		if err != nil {
+			return "", "", err
+		}
+		return "[]" + goType, arrayOf(tsType), nil
+	case isStruct(s):
+		if err := b.namedType(name, s); err != nil {
+			return "", "", err
+		}
+		return name, name, nil
+	case s.Type.Is("object") || s.Type == nil:
+		if ap := s.AdditionalProperties.Schema; ap != nil {
+			goType, tsType, err := b.typeOf(ap, name+"Value")
+			if err != nil {
+				return "", "", err
+			}
+			return "map[string]" + goType, "Record<string, " + tsType + ">", nil
+		}
+		if s.Type == nil {
+			return "any", "unknown", nil
+		}
+		return "map[string]any", "Record<string, unknown>", nil
+	default:
+		return "", "", fmt.Errorf("unsupported schema type %v", s.Type.Slice())
+	}
+}
+
+// operation converts op into the template model.
+func (b *builder) operation(method, path string, op *openapi3.Operation) (operation, error) {
+	if op.OperationID == "" {
+		return operation{}, errors.New("operationId required")
+	}
+	o := operation{
+		Name:    exported(op.OperationID),
+		TSName:  lowerFirst(exported(op.OperationID)),
+		Method:  method,
+		Path:    path,
+		Summary: docLine(op.Summary),
+	}
+
+	for _, ref := range op.Parameters {
+		p := ref.Value
+		goType, tsType, err := b.typeOf(p.Schema, o.Name+exported(p.Name))
+		if err != nil {
+			return operation{}, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		prm := param{
+			Key:      p.Name,
+			GoType:   goType,
+			TSType:   tsType,
+			Optional: !p.Required,
+			Repeated: strings.HasPrefix(goType, "[]"),
+		}
+		switch p.In {
+		case openapi3.ParameterInPath:
+			prm.Name = argName(p.Name)
+			prm.TSName = prm.Name
+			o.PathParams = append(o.PathParams, prm)
+		case openapi3.ParameterInQuery, openapi3.ParameterInHeader:
+			prm.Name = exported(p.Name)
+			prm.TSName = lowerFirst(prm.Name)
+			if prm.Optional && !prm.Repeated {
+				prm.GoType = "*" + prm.GoType
+			}
+			if p.In == openapi3.ParameterInQuery {
+				o.Query = append(o.Query, prm)
+			} else {
+				o.Headers = append(o.Headers, prm)
+			}
+		default:
+			// Cookies are left to the HTTP client
+		}
+	}
+	for _, p := range o.PathParams {
+		if !strings.Contains(path, "{"+p.Key+"}") {
+			return operation{}, fmt.Errorf("path parameter %s not in path", p.Key)
+		}
+	}
+	if len(o.Query) > 0 || len(o.Headers) > 0 {
+		o.ParamsType = o.Name + "Params"
+		b.imports["net/url"] = true
+	}
+	if len(o.PathParams) > 0 {
+		b.imports["net/url"] = true
+	}
+	if len(o.PathParams) > 0 || len(o.Query) > 0 || len(o.Headers) > 0 {
+		b.imports["fmt"] = true
+	}
+	if len(o.Headers) > 0 {
+		b.imports["net/http"] = true
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		content := op.RequestBody.Value.Content
+		// Exact lookups: Content.Get falls back to wildcards such as */*
+		if mt := content["application/json"]; mt != nil {
+			goType, tsType, err := b.typeOf(mt.Schema, o.Name+"Request")
+			if err != nil {
+				return operation{}, fmt.Errorf("request body: %w", err)
+			}
+			o.Body, o.TSBody = goType, tsType
+		} else if types := mediaTypes(content); len(types) > 0 {
+			o.Body, o.TSBody, o.ContentType = "io.Reader", "BodyInit", types[0]
+			b.imports["io"] = true
+		}
+	}
+
+	codes := make([]string, 0)
+	for code := range op.Responses.Map() {
+		if len(code) == 3 && code[0] == '2' {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) > 0 {
+		resp := op.Responses.Value(codes[0]).Value
+		if mt := resp.Content["application/json"]; mt != nil {
+			goType, tsType, err := b.typeOf(mt.Schema, o.Name+"Response")
+			if err != nil {
+				return operation{}, fmt.Errorf("response: %w", err)
+			}
+			o.Result, o.TSResult = goType, tsType
+		} else if types := mediaTypes(resp.Content); len(types) > 0 {
+			o.Stream, o.TSResult, o.Accept = true, "Blob", strings.Join(types, ", ")
+			b.imports["io"] = true
+			b.imports["net/http"] = true
+		}
+	}
+	return o, nil
+}
+
+// isStruct reports whether s is an object with declared properties.
+func isStruct(s *openapi3.Schema) bool {
+	return (s.Type.Is("object") || s.Type == nil) && len(s.Properties) > 0
+}
+
+// pointable reports whether an optional ref is held by pointer: scalars
+// and structs are, slices, maps and any are nil when absent.
+func pointable(ref *openapi3.SchemaRef) bool {
+	if ref == nil || ref.Value == nil {
+		return false
+	}
+	s := ref.Value
+	if s.Type.Is("array") || (s.Type.Is("string") && s.Format == "binary") {
+		return false
+	}
+	if s.Type.Is("object") || s.Type == nil {
+		return isStruct(s)
+	}
+	return true
+}
+
+// mediaTypes returns the sorted media types of content.
+func mediaTypes(content openapi3.Content) []string {
+	types := make([]string, 0, len(content))
+	for mt := range content {
+		types = append(types, mt)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// refName returns the schema name of a local reference.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// initialisms are spelled in upper case in Go names.
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true, "otp": true,
+	"uri": true, "url": true, "uuid": true,
+}
+
+// exported returns the Go name of an identifier like full_name, otpauth-uri
+// or listUsers.
+func exported(s string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var out strings.Builder
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		if initialisms[lower] {
+			out.WriteString(strings.ToUpper(lower))
+			continue
+		}
+		runes := []rune(w)
+		out.WriteRune(unicode.ToUpper(runes[0]))
+		out.WriteString(string(runes[1:]))
+	}
+	name := out.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// reservedArgs are identifiers used by the generated method bodies.
+var reservedArgs = map[string]bool{
+	"ctx": true, "params": true, "body": true, "contentType": true, "out": true, "req": true, "c": true,
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// argName returns the Go argument name of a path parameter.
+func argName(s string) string {
+	name := exported(s)
+	if initialisms[strings.ToLower(name)] {
+		name = strings.ToLower(name)
+	} else {
+		name = lowerFirst(name)
+	}
+	if reservedArgs[name] {
+		name += "Param"
+	}
+	return name
+}
+
+// lowerFirst lower-cases the first letter of s.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// docLine returns the first line of a description, for comments.
+func docLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(s)
+}
+
+// arrayOf returns the TypeScript array of tsType.
+func arrayOf(tsType string) string {
+	if strings.ContainsAny(tsType, " |") {
+		return "Array<" + tsType + ">"
+	}
+	return tsType + "[]"
+}
+
+// pathExpr returns the Go expression building path from the arguments.
+func pathExpr(o operation) string {
+	return splitPath(o, func(literal string) string { return fmt.Sprintf("%q", literal) },
+		func(p param) string { return "url.PathEscape(fmt.Sprint(" + p.Name + "))" }, " + ")
+}
+
+// tsPathExpr returns the TypeScript template literal building path.
+func tsPathExpr(o operation) string {
+	return "`" + splitPath(o, func(literal string) string { return strings.NewReplacer("`", "\\`", "${", "\\${").Replace(literal) },
+		func(p param) string { return "${encodeURIComponent(String(" + p.TSName + "))}" }, "") + "`"
+}
+
+// splitPath renders the literal segments and parameters of the path of o.
+func splitPath(o operation, literal func(string) string, arg func(param) string, sep string) string {
+	byKey := make(map[string]param, len(o.PathParams))
+	for _, p := range o.PathParams {
+		byKey[p.Key] = p
+	}
+	var parts []string
+	rest := o.Path
+	for rest != "" {
+		open := strings.Index(rest, "{")
+		if open < 0 {
+			parts = append(parts, literal(rest))
+			break
+		}
+		end := strings.Index(rest[open:], "}")
+		if end < 0 {
+			parts = append(parts, literal(rest))
+			break
+		}
+		if open > 0 {
+			parts = append(parts, literal(rest[:open]))
+		}
+		parts = append(parts, arg(byKey[rest[open+1:open+end]]))
+		rest = rest[open+end+1:]
+	}
+	return strings.Join(parts, sep)
+}
+
+// goSignature returns the parameters of the Go method of o.
+func goSignature(o operation) string {
+	args := []string{"ctx context.Context"}
+	for _, p := range o.PathParams {
+		args = append(args, p.Name+" "+p.GoType)
+	}
+	if o.Body != "" {
+		args = append(args, "body "+o.Body)
+		if o.ContentType != "" {
+			args = append(args, "contentType string")
+		}
+	}
+	if o.ParamsType != "" {
+		args = append(args, "params *"+o.ParamsType)
+	}
+	return strings.Join(args, ", ")
+}
+
+// tsSignature returns the parameters of the TypeScript method of o.
+func tsSignature(o operation) string {
+	var args []string
+	for _, p := range o.PathParams {
+		args = append(args, p.TSName+": "+p.TSType)
+	}
+	if o.TSBody != "" {
+		args = append(args, "body: "+o.TSBody)
+		if o.ContentType != "" {
+			args = append(args, "contentType = "+fmt.Sprintf("%q", o.ContentType))
+		}
+	}
+	if o.ParamsType != "" {
+		args = append(args, "params: "+o.ParamsType+" = {}")
+	}
+	return strings.Join(args, ", ")
+}
+
+var funcs = template.FuncMap{
+	"path":        pathExpr,
+	"tsPath":      tsPathExpr,
+	"goSignature": goSignature,
+	"tsSignature": tsSignature,
+	"quote":       func(s string) string { return fmt.Sprintf("%q", s) },
+	"deref": func(p param) string {
+		if strings.HasPrefix(p.GoType, "*") {
+			return "*params." + p.Name
+		}
+		return "params." + p.Name
+	},
+	"isPointer": func(t string) bool { return strings.HasPrefix(t, "*") },
+	"tsKey":     tsKey,
+	"hasOptions": func(o operation) bool {
+		return len(o.Query) > 0 || len(o.Headers) > 0 || o.TSBody != "" || o.Accept != ""
+	},
+}
+
+// tsKey returns key as a TypeScript property name, quoted unless an
+// identifier.
+func tsKey(key string) string {
+	for i, r := range key {
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return fmt.Sprintf("%q", key)
+		}
+	}
+	return key
+}
+
+var goTemplate = template.Must(template.New("go").Funcs(funcs).Parse(`// Code generated by clientgen from {{.Title}} {{.Version}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{quote .}}
+{{- end}}
+)
+{{range .Types}}
+{{- if .Doc}}
+// {{.Name}} {{.Doc}}
+{{- else}}
+// {{.Name}} is a schema of the API.
+{{- end}}
+{{- if .Fields}}
+type {{.Name}} struct {
+{{- range .Fields}}
+{{- if .Doc}}
+	// {{.Doc}}
+{{- end}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.JSON}}{{if .Optional}},omitempty{{end}}"` + "`" + `
+{{- end}}
+}
+{{else}}
+type {{.Name}} {{.GoType}}
+{{end}}
+{{- end}}
+{{- range .Operations}}
+{{- if .ParamsType}}
+// {{.ParamsType}} holds the query and header parameters of {{.Name}}.
+type {{.ParamsType}} struct {
+{{- range .Query}}
+	{{.Name}} {{.GoType}}
+{{- end}}
+{{- range .Headers}}
+	{{.Name}} {{.GoType}}
+{{- end}}
+}
+{{end}}
+// {{.Name}} calls {{.Method}} {{.Path}}{{if .Summary}}: {{.Summary}}{{end}}.
+{{- if .Stream}}
+// The caller closes the returned body.
+func (c *Client) {{.Name}}({{goSignature .}}) (io.ReadCloser, error) {
+{{- else if .Result}}
+func (c *Client) {{.Name}}({{goSignature .}}) (*{{.Result}}, error) {
+{{- else}}
+func (c *Client) {{.Name}}({{goSignature .}}) error {
+{{- end}}
+	req := request{method: {{quote .Method}}, path: {{path .}}}
+{{- if .Body}}
+	req.body = body
+{{- if .ContentType}}
+	req.contentType = contentType
+{{- end}}
+{{- end}}
+{{- if .ParamsType}}
+	if params != nil {
+{{- if .Query}}
+		req.query = url.Values{}
+{{- end}}
+{{- range .Query}}
+{{- if .Repeated}}
+		for _, v := range params.{{.Name}} {
+			req.query.Add({{quote .Key}}, fmt.Sprint(v))
+		}
+{{- else if isPointer .GoType}}
+		if params.{{.Name}} != nil {
+			req.query.Set({{quote .Key}}, fmt.Sprint({{deref .}}))
+		}
+{{- else}}
+		req.query.Set({{quote .Key}}, fmt.Sprint({{deref .}}))
+{{- end}}
+{{- end}}
+{{- if .Headers}}
+		req.header = http.Header{}
+{{- end}}
+{{- range .Headers}}
+{{- if isPointer .GoType}}
+		if params.{{.Name}} != nil {
+			req.header.Set({{quote .Key}}, fmt.Sprint({{deref .}}))
+		}
+{{- else}}
+		req.header.Set({{quote .Key}}, fmt.Sprint({{deref .}}))
+{{- end}}
+{{- end}}
+	}
+{{- end}}
+{{- if .Stream}}
+	if req.header == nil {
+		req.header = http.Header{}
+	}
+	req.header.Set("Accept", {{quote .Accept}})
+	return c.stream(ctx, req)
+{{- else if .Result}}
+	var out {{.Result}}
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+{{- else}}
+	return c.do(ctx, req, nil)
+{{- end}}
+}
+{{end}}`))
+
+var tsTemplate = template.Must(template.New("ts").Funcs(funcs).Parse(`// Code generated by clientgen from {{.Title}} {{.Version}}. DO NOT EDIT.
+{{range .Types}}
+{{- if .Doc}}
+/** {{.Doc}} */
+{{- end}}
+{{- if .Fields}}
+export interface {{.Name}} {
+{{- range .Fields}}
+{{- if .Doc}}
+  /** {{.Doc}} */
+{{- end}}
+  {{tsKey .JSON}}{{if .Optional}}?{{end}}: {{.TSType}};
+{{- end}}
+}
+{{else}}
+export type {{.Name}} = {{.TSType}};
+{{end}}
+{{- end}}
+{{- range .Operations}}
+{{- if .ParamsType}}
+/** Query and header parameters of {{.TSName}}. */
+export interface {{.ParamsType}} {
+{{- range .Query}}
+  {{.TSName}}?: {{.TSType}};
+{{- end}}
+{{- range .Headers}}
+  {{.TSName}}?: {{.TSType}};
+{{- end}}
+}
+{{end}}
+{{- end}}
+/** A non-2xx response with the API error body, when it has one. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly kind: string,
+    readonly code: string,
+    message: string,
+  ) {
+    super(message);
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Headers sent with every request, e.g. the principal header. */
+  headers?: Record<string, string>;
+  fetch?: typeof fetch;
+}
+
+type Query = Record<string, unknown>;
+
+export class Client {
+  private readonly baseURL: string;
+
+  constructor(
+    baseURL: string,
+    private readonly options: ClientOptions = {},
+  ) {
+    this.baseURL = baseURL.replace(/\/$/, "");
+  }
+{{range .Operations}}
+  /** {{.Method}} {{.Path}}{{if .Summary}}: {{.Summary}}{{end}} */
+  async {{.TSName}}({{tsSignature .}}): Promise<{{if .TSResult}}{{.TSResult}}{{else}}void{{end}}> {
+{{- if hasOptions .}}
+    {{if .TSResult}}const res = {{end}}await this.send({{quote .Method}}, {{tsPath .}}, {
+{{- if .Query}}
+      query: { {{range $i, $p := .Query}}{{if $i}}, {{end}}{{tsKey $p.Key}}: params.{{$p.TSName}}{{end}} },
+{{- end}}
+{{- if .Headers}}
+      headers: { {{range $i, $p := .Headers}}{{if $i}}, {{end}}{{tsKey $p.Key}}: params.{{$p.TSName}}{{end}} },
+{{- end}}
+{{- if .TSBody}}
+{{- if .ContentType}}
+      body,
+      contentType,
+{{- else}}
+      body: JSON.stringify(body),
+      contentType: "application/json",
+{{- end}}
+{{- end}}
+{{- if .Accept}}
+      accept: {{quote .Accept}},
+{{- end}}
+    });
+{{- else}}
+    {{if .TSResult}}const res = {{end}}await this.send({{quote .Method}}, {{tsPath .}});
+{{- end}}
+{{- if .Stream}}
+    return res.blob();
+{{- else if .TSResult}}
+    return (await res.json()) as {{.TSResult}};
+{{- end}}
+  }
+{{end}}
+  private async send(
+    method: string,
+    path: string,
+    req: { query?: Query; headers?: Query; body?: BodyInit; contentType?: string; accept?: string } = {},
+  ): Promise<Response> {
+    const url = new URL(this.baseURL + path);
+    for (const [key, value] of Object.entries(req.query ?? {})) {
+      for (const v of Array.isArray(value) ? value : [value]) {
+        if (v !== undefined && v !== null) url.searchParams.append(key, String(v));
+      }
+    }
+    const headers: Record<string, string> = { Accept: req.accept ?? "application/json", ...this.options.headers };
+    for (const [key, value] of Object.entries(req.headers ?? {})) {
+      if (value !== undefined && value !== null) headers[key] = String(value);
+    }
+    if (req.contentType) headers["Content-Type"] = req.contentType;
+
+    const res = await (this.options.fetch ?? fetch)(url, { method, headers, body: req.body });
+    if (!res.ok) {
+      const err = await res.json().catch(() => ({}));
+      throw new ApiError(res.status, err.error ?? "", err.code ?? "", err.message ?? res.statusText);
+    }
+    return res;
+  }
+}
+`))
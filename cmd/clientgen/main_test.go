@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_ClientUpToDate fails when the committed Go client drifts
+// from the generator output or the spec; rerun `go generate ./...`.
+func TestGenerate_ClientUpToDate(t *testing.T) {
+	spec, err := os.ReadFile(filepath.Join("..", "..", "api", "openapi.yaml"))
+	require.NoError(t, err)
+
+	got, err := generate(spec, "go", "client")
+	require.NoError(t, err)
+
+	want, err := os.ReadFile(filepath.Join("..", "..", "pkg", "client", "client_gen.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGenerate_TypeScript(t *testing.T) {
+	spec, err := os.ReadFile(filepath.Join("..", "..", "api", "openapi.yaml"))
+	require.NoError(t, err)
+
+	src, err := generate(spec, "ts", "")
+	require.NoError(t, err)
+	out := string(src)
+	assert.Contains(t, out, "export interface UserResponse {\n")
+	assert.Contains(t, out, "  full_name: string;\n")
+	assert.Contains(t, out, "  is_active?: boolean;\n")
+	assert.Contains(t, out, "async getUser(id: string): Promise<UserResponse> {")
+	assert.Contains(t, out, "async listUsers(params: ListUsersParams = {}): Promise<UserListResponse> {")
+	assert.Contains(t, out, "async exportUsers(params: ExportUsersParams = {}): Promise<Blob> {")
+	assert.Contains(t, out, "`/api/v1/users/${encodeURIComponent(String(id))}`")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	spec := []byte(`openapi: 3.0.3
+info: {title: T, version: "1"}
+paths:
+  /things:
+    get:
+      responses:
+        "200": {description: ok}
+`)
+	_, err := generate(spec, "go", "client")
+	assert.ErrorContains(t, err, "operationId required")
+
+	_, err = generate([]byte(`openapi: 3.0.3
+info: {title: T, version: "1"}
+paths: {}
+`), "rust", "client")
+	assert.ErrorContains(t, err, "unknown language")
+}
+
+func TestExported(t *testing.T) {
+	assert.Equal(t, "FullName", exported("full_name"))
+	assert.Equal(t, "OtpauthURI", exported("otpauth_uri"))
+	assert.Equal(t, "ListUsers", exported("listUsers"))
+	assert.Equal(t, "XRequestID", exported("X-Request-Id"))
+	assert.Equal(t, "id", argName("id"))
+	assert.Equal(t, "typeParam", argName("type"))
+}
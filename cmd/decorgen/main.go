@@ -0,0 +1,426 @@
+// Command decorgen generates logging, metrics and tracing decorators for a
+// service interface, so cross-cutting concerns stay out of business logic.
+//
+// Run it through go:generate next to the interface declaration:
+//
+//	//go:generate go run github.com/luminosita/change-me/cmd/decorgen -type UserService
+//
+// For an interface T it writes <t>_decorators.go with NewTLogging,
+// NewTMetrics and NewTTracing, each returning T. Methods whose first
+// parameter is a context.Context get a span; a trailing error result is
+// reported as the call outcome.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("decorgen: ")
+
+	typeName := flag.String("type", "", "service interface to decorate (required)")
+	service := flag.String("service", "", "service label used in logs, metrics and spans (default <package>.<type>)")
+	output := flag.String("output", "", "output file (default <type>_decorators.go)")
+	dir := flag.String("dir", ".", "package directory")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = snakeCase(*typeName) + "_decorators.go"
+	}
+
+	if err := generate(*dir, *typeName, *service, *output); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate parses the package in dir and writes the decorators for typeName.
+func generate(dir, typeName, service, output string) error {
+	spec, err := parseInterface(dir, typeName, output)
+	if err != nil {
+		return err
+	}
+	if service != "" {
+		spec.Service = service
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, spec); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w\n%s", err, buf.Bytes())
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// interfaceSpec describes the decorated interface for the template.
+type interfaceSpec struct {
+	Package    string
+	Type       string
+	Service    string
+	StdImports []importSpec
+	Imports    []importSpec
+	Methods    []methodSpec
+}
+
+type importSpec struct {
+	Name string // Alias, empty when it matches the package name
+	Path string
+}
+
+type methodSpec struct {
+	Name    string
+	Params  []paramSpec
+	Results []paramSpec
+	Context string // Name of the leading context.Context parameter, if any
+	HasErr  bool   // Last result is an error named err
+}
+
+type paramSpec struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+// fixedImports are always imported by the generated file.
+var fixedImports = []importSpec{
+	{Path: "time"},
+	{Path: "github.com/luminosita/change-me/internal/core/apperrors"},
+	{Path: "github.com/luminosita/change-me/pkg/decorate"},
+	{Path: "github.com/luminosita/change-me/pkg/logger"},
+	{Name: "otelcodes", Path: "go.opentelemetry.io/otel/codes"},
+	{Path: "go.opentelemetry.io/otel/trace"},
+}
+
+// reservedNames are identifiers used by the generated method bodies.
+var reservedNames = map[string]bool{"d": true, "start": true, "span": true}
+
+// parseInterface finds typeName among the package's non-test files.
+func parseInterface(dir, typeName, output string) (*interfaceSpec, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		base := filepath.Base(path)
+		if strings.HasSuffix(base, "_test.go") || base == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gen.Specs {
+				ts := s.(*ast.TypeSpec)
+				if ts.Name.Name != typeName {
+					continue
+				}
+				iface, ok := ts.Type.(*ast.InterfaceType)
+				if !ok {
+					return nil, fmt.Errorf("%s is not an interface", typeName)
+				}
+				return buildSpec(fset, file, typeName, iface)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s not found in %s", typeName, dir)
+}
+
+// buildSpec converts the interface AST into the template model.
+func buildSpec(fset *token.FileSet, file *ast.File, typeName string, iface *ast.InterfaceType) (*interfaceSpec, error) {
+	spec := &interfaceSpec{
+		Package: file.Name.Name,
+		Type:    typeName,
+		Service: file.Name.Name + "." + typeName,
+	}
+
+	used := make(map[string]bool)
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, errors.New("embedded interfaces are not supported")
+		}
+
+		ast.Inspect(fn, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					used[id.Name] = true
+				}
+			}
+			return true
+		})
+
+		m := methodSpec{Name: field.Names[0].Name}
+		m.Params = fields(fset, fn.Params, "p")
+		if fn.Results != nil {
+			m.Results = fields(fset, fn.Results, "r")
+		}
+		for _, p := range m.Params {
+			if reservedNames[p.Name] {
+				return nil, fmt.Errorf("%s.%s: parameter name %q is reserved", typeName, m.Name, p.Name)
+			}
+		}
+		if len(m.Params) > 0 && m.Params[0].Type == "context.Context" {
+			m.Context = m.Params[0].Name
+		}
+		if n := len(m.Results); n > 0 && m.Results[n-1].Type == "error" {
+			m.Results[n-1].Name = "err"
+			m.HasErr = true
+		}
+		spec.Methods = append(spec.Methods, m)
+	}
+
+	imports := make(map[string]importSpec)
+	for _, imp := range fixedImports {
+		imports[imp.Path] = imp
+	}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := packageName(path)
+		alias := ""
+		if imp.Name != nil {
+			name, alias = imp.Name.Name, imp.Name.Name
+		}
+		if _, fixed := imports[path]; used[name] && !fixed {
+			imports[path] = importSpec{Name: alias, Path: path}
+		}
+	}
+
+	// Standard library first, then the rest, as goimports groups them
+	for _, imp := range imports {
+		if strings.Contains(strings.Split(imp.Path, "/")[0], ".") {
+			spec.Imports = append(spec.Imports, imp)
+		} else {
+			spec.StdImports = append(spec.StdImports, imp)
+		}
+	}
+	for _, group := range [][]importSpec{spec.StdImports, spec.Imports} {
+		sort.Slice(group, func(i, j int) bool { return group[i].Path < group[j].Path })
+	}
+
+	return spec, nil
+}
+
+// fields flattens a parameter list, naming unnamed entries prefix0, prefix1...
+func fields(fset *token.FileSet, list *ast.FieldList, prefix string) []paramSpec {
+	var out []paramSpec
+	for _, field := range list.List {
+		typ := field.Type
+		variadic := false
+		if ell, ok := typ.(*ast.Ellipsis); ok {
+			typ, variadic = ell.Elt, true
+		}
+
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, fset, typ)
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for _, name := range names {
+			p := paramSpec{Type: buf.String(), Variadic: variadic}
+			if name == nil || name.Name == "_" {
+				p.Name = prefix + strconv.Itoa(len(out))
+			} else {
+				p.Name = name.Name
+			}
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// packageName guesses the default package name from an import path,
+// skipping major version suffixes.
+func packageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+// snakeCase converts an identifier such as UserService to user_service.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// lowerFirst converts UserService to userService.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+var fileTemplate = template.Must(template.New("decorators").Funcs(template.FuncMap{
+	"lower": lowerFirst,
+	"signature": func(m methodSpec) string {
+		params := make([]string, len(m.Params))
+		for i, p := range m.Params {
+			typ := p.Type
+			if p.Variadic {
+				typ = "..." + typ
+			}
+			params[i] = p.Name + " " + typ
+		}
+		results := make([]string, len(m.Results))
+		for i, r := range m.Results {
+			results[i] = r.Name + " " + r.Type
+		}
+		sig := m.Name + "(" + strings.Join(params, ", ") + ")"
+		if len(results) > 0 {
+			sig += " (" + strings.Join(results, ", ") + ")"
+		}
+		return sig
+	},
+	"call": func(m methodSpec) string {
+		args := make([]string, len(m.Params))
+		for i, p := range m.Params {
+			args[i] = p.Name
+			if p.Variadic {
+				args[i] += "..."
+			}
+		}
+		return m.Name + "(" + strings.Join(args, ", ") + ")"
+	},
+}).Parse(`// Code generated by decorgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import ({{range .StdImports}}
+	{{if .Name}}{{.Name}} {{end}}"{{.Path}}"{{end}}
+{{range .Imports}}
+	{{if .Name}}{{.Name}} {{end}}"{{.Path}}"{{end}}
+)
+
+{{$type := .Type}}{{$lower := lower .Type}}
+// {{$lower}}Name labels {{.Type}} calls in logs, metrics and spans.
+const {{$lower}}Name = "{{.Service}}"
+
+// {{$lower}}Logging logs {{.Type}} calls.
+type {{$lower}}Logging struct {
+	next {{.Type}}
+	log  *logger.Logger
+}
+
+// New{{.Type}}Logging wraps next with call logging. Successful calls and
+// domain errors are logged at debug level, internal errors at error level.
+func New{{.Type}}Logging(next {{.Type}}, log *logger.Logger) {{.Type}} {
+	return &{{$lower}}Logging{next: next, log: log}
+}
+{{range .Methods}}
+// {{.Name}} implements {{$type}}.
+func (d *{{$lower}}Logging) {{signature .}} {
+	defer func(start time.Time) { d.logCall("{{.Name}}", start, {{if .HasErr}}err{{else}}nil{{end}}) }(time.Now())
+	{{if .Results}}return {{end}}d.next.{{call .}}
+}
+{{end}}
+func (d *{{$lower}}Logging) logCall(method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	switch {
+	case err == nil:
+		d.log.Debugw("service_call", "service", {{$lower}}Name, "method", method, "duration", elapsed)
+	case apperrors.KindOf(err) == apperrors.KindInternal:
+		d.log.Errorw("service_call_failed", "service", {{$lower}}Name, "method", method, "duration", elapsed, "error", err)
+	default:
+		d.log.Debugw("service_call", "service", {{$lower}}Name, "method", method, "duration", elapsed, "error", err)
+	}
+}
+
+// {{$lower}}Metrics records {{.Type}} call latency and outcomes.
+type {{$lower}}Metrics struct {
+	next {{.Type}}
+	rec  decorate.Recorder
+}
+
+// New{{.Type}}Metrics wraps next with call metrics.
+func New{{.Type}}Metrics(next {{.Type}}, rec decorate.Recorder) {{.Type}} {
+	return &{{$lower}}Metrics{next: next, rec: rec}
+}
+{{range .Methods}}
+// {{.Name}} implements {{$type}}.
+func (d *{{$lower}}Metrics) {{signature .}} {
+	defer func(start time.Time) { d.rec.Observe({{$lower}}Name, "{{.Name}}", time.Since(start), {{if .HasErr}}err{{else}}nil{{end}}) }(time.Now())
+	{{if .Results}}return {{end}}d.next.{{call .}}
+}
+{{end}}
+// {{$lower}}Tracing opens a span per {{.Type}} call.
+type {{$lower}}Tracing struct {
+	next   {{.Type}}
+	tracer trace.Tracer
+}
+
+// New{{.Type}}Tracing wraps next with a span per call for methods taking a
+// context. Errors are recorded on the span.
+func New{{.Type}}Tracing(next {{.Type}}, tracer trace.Tracer) {{.Type}} {
+	return &{{$lower}}Tracing{next: next, tracer: tracer}
+}
+{{range .Methods}}
+// {{.Name}} implements {{$type}}.
+func (d *{{$lower}}Tracing) {{signature .}} {
+	{{- if .Context}}
+	{{.Context}}, span := d.tracer.Start({{.Context}}, {{$lower}}Name+"/{{.Name}}")
+	{{- if .HasErr}}
+	defer func() { d.endSpan(span, err) }()
+	{{- else}}
+	defer span.End()
+	{{- end}}
+	{{- end}}
+	{{if .Results}}return {{end}}d.next.{{call .}}
+}
+{{end}}
+// endSpan records err on span and ends it.
+func (d *{{$lower}}Tracing) endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+`))
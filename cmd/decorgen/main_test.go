@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_UsersDecoratorsUpToDate fails when the committed users
+// decorators drift from the generator output; rerun `go generate ./...`.
+func TestGenerate_UsersDecoratorsUpToDate(t *testing.T) {
+	src := filepath.Join("..", "..", "internal", "core", "users")
+	dir := t.TempDir()
+
+	entries, err := os.ReadDir(src)
+	require.NoError(t, err)
+	for _, e := range entries {
+		if e.Name() == "user_service_decorators.go" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, e.Name()), data, 0o600))
+	}
+
+	require.NoError(t, generate(dir, "UserService", "", "user_service_decorators.go"))
+
+	want, err := os.ReadFile(filepath.Join(src, "user_service_decorators.go"))
+	require.NoError(t, err)
+	got, err := os.ReadFile(filepath.Join(dir, "user_service_decorators.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "svc.go"), []byte(`package svc
+
+type Concrete struct{}
+
+type Reserved interface {
+	Do(d int) error
+}
+`), 0o600))
+
+	assert.ErrorContains(t, generate(dir, "Missing", "", "out.go"), "not found")
+	assert.ErrorContains(t, generate(dir, "Concrete", "", "out.go"), "not an interface")
+	assert.ErrorContains(t, generate(dir, "Reserved", "", "out.go"), "reserved")
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "user_service", snakeCase("UserService"))
+	assert.Equal(t, "store", snakeCase("Store"))
+}
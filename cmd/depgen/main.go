@@ -0,0 +1,357 @@
+// Command depgen generates typed accessors for the dependencies each module
+// takes from the container, so a module gets its dependencies checked in
+// one place instead of reading container fields that may be nil.
+//
+// Modules declare the container fields they need with a directive in the
+// container's package; a trailing ? marks an optional field that may be nil:
+//
+//	//go:generate go run github.com/luminosita/change-me/cmd/depgen -type Container
+//	//depgen:module users Logger UserService Redis?
+//
+// For each module it writes a <Module>Deps struct holding the fields with
+// their container types, and a <Module>Deps method on the container
+// returning it, or an error naming the required fields that are nil. The
+// accessors reference the fields by name and type, so renaming or retyping
+// a container field breaks the build until the declarations are updated.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("depgen: ")
+
+	typeName := flag.String("type", "", "container struct type (required)")
+	output := flag.String("output", "", "output file (default <type>_modules.go)")
+	dir := flag.String("dir", ".", "package directory")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = snakeCase(*typeName) + "_modules.go"
+	}
+
+	if err := generate(*dir, *typeName, *output); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// directive declares the dependencies of a module.
+const directive = "//depgen:module "
+
+// moduleName matches module names such as users or usage_admin.
+var moduleName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// generate parses the package in dir and writes the accessors of the
+// modules declared for typeName.
+func generate(dir, typeName, output string) error {
+	spec, err := parsePackage(dir, typeName, output)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, spec); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w\n%s", err, buf.Bytes())
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// packageSpec describes the container and its modules for the template.
+type packageSpec struct {
+	Package string
+	Type    string
+	Imports []importSpec
+	Modules []moduleSpec
+}
+
+type importSpec struct {
+	Name string // Alias, empty when it matches the package name
+	Path string
+}
+
+type moduleSpec struct {
+	Name   string // Module name, e.g. usage_admin
+	Type   string // Generated struct and method name, e.g. UsageAdminDeps
+	Fields []fieldSpec
+}
+
+type fieldSpec struct {
+	Name     string
+	Type     string
+	Optional bool
+}
+
+// parsePackage finds typeName and the module directives among the
+// package's non-test files.
+func parsePackage(dir, typeName, output string) (*packageSpec, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var (
+		spec       *packageSpec
+		fieldTypes map[string]string
+		structFile *ast.File
+		directives []string
+	)
+	for _, path := range paths {
+		base := filepath.Base(path)
+		if strings.HasSuffix(base, "_test.go") || base == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if rest, ok := strings.CutPrefix(c.Text, directive); ok {
+					directives = append(directives, rest)
+				}
+			}
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gen.Specs {
+				ts := s.(*ast.TypeSpec)
+				if ts.Name.Name != typeName {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return nil, fmt.Errorf("%s is not a struct", typeName)
+				}
+				spec = &packageSpec{Package: file.Name.Name, Type: typeName}
+				fieldTypes = structFields(fset, st)
+				structFile = file
+			}
+		}
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("struct %s not found in %s", typeName, dir)
+	}
+	if len(directives) == 0 {
+		return nil, fmt.Errorf("no %s directives in %s", strings.TrimSpace(directive), dir)
+	}
+
+	seen := make(map[string]bool)
+	for _, d := range directives {
+		m, err := parseDirective(d, typeName, fieldTypes)
+		if err != nil {
+			return nil, err
+		}
+		if seen[m.Name] {
+			return nil, fmt.Errorf("module %q declared twice", m.Name)
+		}
+		seen[m.Name] = true
+		spec.Modules = append(spec.Modules, m)
+	}
+	sort.Slice(spec.Modules, func(i, j int) bool { return spec.Modules[i].Name < spec.Modules[j].Name })
+
+	spec.Imports = usedImports(structFile, spec.Modules)
+	return spec, nil
+}
+
+// structFields maps the named fields of st to their source types.
+func structFields(fset *token.FileSet, st *ast.StructType) map[string]string {
+	types := make(map[string]string)
+	for _, field := range st.Fields.List {
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, fset, field.Type)
+		for _, name := range field.Names {
+			types[name.Name] = buf.String()
+		}
+	}
+	return types
+}
+
+// parseDirective parses "name Field Field? ..." against the container
+// fields.
+func parseDirective(d, typeName string, fieldTypes map[string]string) (moduleSpec, error) {
+	parts := strings.Fields(d)
+	if len(parts) < 2 {
+		return moduleSpec{}, fmt.Errorf("directive %q: want a module name and at least one field", d)
+	}
+	if !moduleName.MatchString(parts[0]) {
+		return moduleSpec{}, fmt.Errorf("directive %q: invalid module name %q", d, parts[0])
+	}
+
+	m := moduleSpec{Name: parts[0], Type: camelCase(parts[0]) + "Deps"}
+	fields := make(map[string]bool)
+	for _, part := range parts[1:] {
+		name, optional := strings.CutSuffix(part, "?")
+		typ, ok := fieldTypes[name]
+		if !ok || !ast.IsExported(name) {
+			return moduleSpec{}, fmt.Errorf("module %s: %s has no exported field %q", m.Name, typeName, name)
+		}
+		if fields[name] {
+			return moduleSpec{}, fmt.Errorf("module %s: field %s listed twice", m.Name, name)
+		}
+		fields[name] = true
+		m.Fields = append(m.Fields, fieldSpec{Name: name, Type: typ, Optional: optional})
+	}
+	return m, nil
+}
+
+// usedImports returns the imports of file referenced by the module field
+// types.
+func usedImports(file *ast.File, modules []moduleSpec) []importSpec {
+	used := make(map[string]bool)
+	for _, m := range modules {
+		for _, f := range m.Fields {
+			for _, sel := range qualifier.FindAllStringSubmatch(f.Type, -1) {
+				used[sel[1]] = true
+			}
+		}
+	}
+
+	var imports []importSpec
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name, alias := packageName(path), ""
+		if imp.Name != nil {
+			name, alias = imp.Name.Name, imp.Name.Name
+		}
+		if used[name] {
+			imports = append(imports, importSpec{Name: alias, Path: path})
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Path < imports[j].Path })
+	return imports
+}
+
+// qualifier matches the package qualifiers of a type expression.
+var qualifier = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+// packageName guesses the default package name from an import path,
+// skipping major version suffixes.
+func packageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+// camelCase converts a module name such as usage_admin to UsageAdmin.
+func camelCase(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// snakeCase converts an identifier such as Container to container.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var fileTemplate = template.Must(template.New("modules").Parse(`// Code generated by depgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+{{range .Imports}}
+	{{if .Name}}{{.Name}} {{end}}"{{.Path}}"{{end}}
+)
+
+{{$type := .Type}}
+{{- range .Modules}}
+// {{.Type}} are the dependencies of the {{.Name}} module.
+type {{.Type}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}{{if .Optional}} // Optional, may be nil{{end}}
+{{- end}}
+}
+
+// {{.Type}} returns the dependencies of the {{.Name}} module, or an error
+// naming the required ones that are nil.
+func (c *{{$type}}) {{.Type}}() ({{.Type}}, error) {
+	deps := {{.Type}}{
+{{- range .Fields}}
+		{{.Name}}: c.{{.Name}},
+{{- end}}
+	}
+	var missing []string
+{{- range .Fields}}{{if not .Optional}}
+	if isNilDependency(deps.{{.Name}}) {
+		missing = append(missing, "{{.Name}}")
+	}
+{{- end}}{{end}}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module {{.Name}}: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+{{end}}
+// isNilDependency reports whether v is nil or a nil pointer, interface,
+// map, slice, channel or function.
+func isNilDependency(v any) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+`))
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_ContainerModulesUpToDate fails when the committed container
+// accessors drift from the generator output; rerun `go generate ./...`.
+func TestGenerate_ContainerModulesUpToDate(t *testing.T) {
+	src := filepath.Join("..", "..", "internal", "core", "dependencies")
+	dir := t.TempDir()
+
+	entries, err := os.ReadDir(src)
+	require.NoError(t, err)
+	for _, e := range entries {
+		if e.Name() == "container_modules.go" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, e.Name()), data, 0o600))
+	}
+
+	require.NoError(t, generate(dir, "Container", "container_modules.go"))
+
+	want, err := os.ReadFile(filepath.Join(src, "container_modules.go"))
+	require.NoError(t, err)
+	got, err := os.ReadFile(filepath.Join(dir, "container_modules.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	write := func(t *testing.T, directives string) string {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "deps.go"), []byte(`package deps
+
+type Container struct {
+	Name   string
+	Lookup map[string]int
+	hidden *int
+}
+
+type Alias = int
+`+directives), 0o600))
+		return dir
+	}
+
+	dir := write(t, "")
+	assert.ErrorContains(t, generate(dir, "Missing", "out.go"), "not found")
+	assert.ErrorContains(t, generate(dir, "Alias", "out.go"), "not a struct")
+	assert.ErrorContains(t, generate(dir, "Container", "out.go"), "no //depgen:module directives")
+
+	cases := map[string]string{
+		"//depgen:module users\n":                            "at least one field",
+		"//depgen:module Users Name\n":                       "invalid module name",
+		"//depgen:module users Unknown\n":                    `no exported field "Unknown"`,
+		"//depgen:module users hidden\n":                     `no exported field "hidden"`,
+		"//depgen:module users Name Name?\n":                 "listed twice",
+		"//depgen:module a Name\n//depgen:module a Lookup\n": "declared twice",
+	}
+	for directives, want := range cases {
+		assert.ErrorContains(t, generate(write(t, directives), "Container", "out.go"), want, directives)
+	}
+}
+
+func TestGenerate_OptionalFields(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "deps.go"), []byte(`package deps
+
+type Container struct {
+	Name   string
+	Lookup map[string]int
+}
+
+//depgen:module usage_admin Name Lookup?
+`), 0o600))
+
+	require.NoError(t, generate(dir, "Container", "out.go"))
+
+	got, err := os.ReadFile(filepath.Join(dir, "out.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "func (c *Container) UsageAdminDeps() (UsageAdminDeps, error)")
+	assert.Contains(t, string(got), "Lookup map[string]int // Optional, may be nil")
+	assert.Contains(t, string(got), `missing = append(missing, "Name")`)
+	assert.NotContains(t, string(got), `missing = append(missing, "Lookup")`)
+}
+
+func TestCase(t *testing.T) {
+	assert.Equal(t, "UsageAdmin", camelCase("usage_admin"))
+	assert.Equal(t, "container", snakeCase("Container"))
+	assert.Equal(t, "redis", packageName("github.com/redis/go-redis/v9"))
+}
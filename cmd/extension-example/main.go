@@ -0,0 +1,51 @@
+// Command extension-example is an extension process answering a greeting
+// route and requiring an X-Api-Key header as middleware. Declare it in
+// EXTENSIONS_CONFIG (see configs/extensions.example.yaml):
+//
+//	go build -o bin/extension-example ./cmd/extension-example
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/luminosita/change-me/pkg/extension"
+)
+
+type greeter struct {
+	greeting string
+}
+
+// Handle answers GET /greet/:name.
+func (g greeter) Handle(_ context.Context, req *extension.Request) (*extension.Response, error) {
+	return &extension.Response{
+		Status: http.StatusOK,
+		Header: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:   fmt.Appendf(nil, "%s, %s!\n", g.greeting, req.Params["name"]),
+	}, nil
+}
+
+// Intercept lets requests carrying an API key continue.
+func (g greeter) Intercept(_ context.Context, req *extension.Request) (*extension.Decision, error) {
+	if req.Header.Get("X-Api-Key") == "" {
+		return &extension.Decision{Response: &extension.Response{Status: http.StatusUnauthorized}}, nil
+	}
+	return &extension.Decision{Continue: true}, nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("extension-example: ")
+
+	greeting := os.Getenv("GREETING")
+	if greeting == "" {
+		greeting = "Hello"
+	}
+	// Logs go to stderr; stdout carries the handshake
+	if err := extension.Serve(greeter{greeting: greeting}); err != nil {
+		log.Fatal(err)
+	}
+}
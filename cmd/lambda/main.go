@@ -0,0 +1,81 @@
+// Command lambda runs the HTTP API as an AWS Lambda function behind API
+// Gateway (REST or HTTP APIs) or an Application Load Balancer. The
+// container and router are built once per execution environment, during
+// the init phase, and shared by its invocations; each invocation is served
+// with the invocation context. Background workers, the heartbeat and the
+// pool reporter of the serve command do not run here.
+//
+//	GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -tags lambda.norpc -o bootstrap ./cmd/lambda
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
+	"github.com/luminosita/change-me/pkg/serverless"
+	"github.com/luminosita/change-me/pkg/startup"
+)
+
+// processStart approximates the process start time for startup timing.
+var processStart = time.Now()
+
+func main() {
+	adapter, shutdown, err := setup(context.Background())
+	if err != nil {
+		log.Printf("Error: %v", err)
+		os.Exit(1)
+	}
+
+	// Lambda sends SIGTERM shortly before freezing the environment for good
+	lambda.StartWithOptions(adapter, lambda.WithEnableSIGTERM(shutdown))
+}
+
+// setup builds the container and the router, starts the plugins and runs
+// the warmup hooks. shutdown stops the plugins and closes the container.
+func setup(ctx context.Context) (adapter *serverless.Adapter, shutdown func(), err error) {
+	timer := startup.New(processStart)
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	timer.Mark(startup.PhaseConfig)
+
+	container, err := dependencies.InitializeContainerFrom(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize dependencies: %w", err)
+	}
+	logger := container.Logger
+	timer.Mark(startup.PhaseDependencies)
+
+	server := httpserver.New(container)
+	timer.Mark(startup.PhaseRoutes)
+
+	if err := server.Plugins().Start(ctx); err != nil {
+		_ = container.Close()
+		return nil, nil, fmt.Errorf("failed to start plugins: %w", err)
+	}
+	container.Warmup.Run(ctx, logger, cfg.WarmupTimeout)
+	timer.Mark(startup.PhaseWarmup)
+	timer.Report(logger, cfg.StartupBudget)
+
+	shutdown = func() {
+		logger.Infow("application_shutdown_started")
+		stopCtx, cancel := context.WithTimeout(context.Background(), cfg.ServerShutdownTimeout)
+		defer cancel()
+		if err := server.Plugins().Stop(stopCtx); err != nil {
+			logger.Errorw("plugins_stop_failed", "error", err)
+		}
+		if err := container.Close(); err != nil {
+			logger.Errorw("dependencies_close_error", "error", err)
+		}
+		logger.Infow("application_shutdown_complete")
+	}
+	return serverless.New(server.Router(), serverless.Options{BasePath: cfg.LambdaBasePath}), shutdown, nil
+}
@@ -1,30 +1,521 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/luminosita/change-me/internal/core/ratelimit"
+	"github.com/luminosita/change-me/internal/core/routeflags"
+	"github.com/luminosita/change-me/internal/core/slo"
+	"github.com/luminosita/change-me/internal/interfaces/http/routing"
+	"github.com/luminosita/change-me/pkg/extension"
+	"github.com/luminosita/change-me/pkg/grpcclient"
+	"github.com/luminosita/change-me/pkg/httpclient"
+	"github.com/luminosita/change-me/pkg/proxy"
+	"github.com/luminosita/change-me/pkg/synthetic"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds all application configuration.
 // Configuration values are loaded from:
 // 1. Environment variables (highest priority)
-// 2. .env file (development default)
-// 3. Default values (fallback)
+// 2. Age-encrypted env file (CONFIG_ENCRYPTED_FILE)
+// 3. .env file (development default)
+// 4. Default values (fallback)
 type Config struct {
 	// Application metadata
 	AppName    string `mapstructure:"APP_NAME" validate:"required"`
 	AppVersion string `mapstructure:"APP_VERSION" validate:"required"`
 	Debug      bool   `mapstructure:"DEBUG"`
 
+	// Environment profile (development, test, staging, production)
+	Environment string `mapstructure:"APP_ENV" validate:"required,oneof=development test staging production"`
+
 	// Server configuration
 	Host string `mapstructure:"HOST" validate:"required"`
 	Port int    `mapstructure:"PORT" validate:"required,min=1,max=65535"`
 
+	// HTTP server timeouts and limits; SERVER_MAX_CONNECTIONS caps
+	// concurrently accepted connections (0 is unlimited)
+	ServerReadTimeout       time.Duration `mapstructure:"SERVER_READ_TIMEOUT" validate:"min=0"`
+	ServerReadHeaderTimeout time.Duration `mapstructure:"SERVER_READ_HEADER_TIMEOUT" validate:"min=0"`
+	ServerWriteTimeout      time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT" validate:"min=0"`
+	ServerIdleTimeout       time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT" validate:"min=0"`
+	ServerShutdownTimeout   time.Duration `mapstructure:"SERVER_SHUTDOWN_TIMEOUT" validate:"min=1s"`
+	ServerMaxHeaderBytes    int           `mapstructure:"SERVER_MAX_HEADER_BYTES" validate:"min=1024,max=16777216"`
+	ServerMaxConnections    int           `mapstructure:"SERVER_MAX_CONNECTIONS" validate:"min=0"`
+
+	// Proxies (addresses or CIDRs) whose X-Forwarded-For and X-Real-IP
+	// headers tell the client address; none by default, so clients cannot
+	// pose as another address
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES" validate:"omitempty,dive,cidr|ip"`
+
+	// Deadline of the request context (0 disables it); repository queries
+	// and outbound calls get a share of the time remaining (see
+	// STORE_DEADLINE_BUDGET and HTTP_CLIENT_DEADLINE_BUDGET)
+	ServerRequestTimeout time.Duration `mapstructure:"SERVER_REQUEST_TIMEOUT" validate:"min=0"`
+
+	// Connection lifecycle: HTTP keep-alives, requests served per connection
+	// before it is closed (0 is unlimited) and TCP keep-alive probes (zero
+	// values use the operating system defaults)
+	ServerKeepAlive            bool          `mapstructure:"SERVER_KEEP_ALIVE"`
+	ServerMaxRequestsPerConn   int           `mapstructure:"SERVER_MAX_REQUESTS_PER_CONN" validate:"min=0"`
+	ServerTCPKeepAlive         bool          `mapstructure:"SERVER_TCP_KEEPALIVE"`
+	ServerTCPKeepAliveIdle     time.Duration `mapstructure:"SERVER_TCP_KEEPALIVE_IDLE" validate:"min=0"`
+	ServerTCPKeepAliveInterval time.Duration `mapstructure:"SERVER_TCP_KEEPALIVE_INTERVAL" validate:"min=0"`
+	ServerTCPKeepAliveCount    int           `mapstructure:"SERVER_TCP_KEEPALIVE_COUNT" validate:"min=0"`
+
+	// Prioritized request queue: admin, health and metrics traffic and
+	// public traffic get separate concurrency budgets and wait queues
+	PriorityQueueEnabled      bool          `mapstructure:"PRIORITY_QUEUE_ENABLED"`
+	PriorityAdminConcurrency  int           `mapstructure:"PRIORITY_ADMIN_CONCURRENCY" validate:"min=1"`
+	PriorityAdminQueueSize    int           `mapstructure:"PRIORITY_ADMIN_QUEUE_SIZE" validate:"min=0"`
+	PriorityPublicConcurrency int           `mapstructure:"PRIORITY_PUBLIC_CONCURRENCY" validate:"min=1"`
+	PriorityPublicQueueSize   int           `mapstructure:"PRIORITY_PUBLIC_QUEUE_SIZE" validate:"min=0"`
+	PriorityQueueTimeout      time.Duration `mapstructure:"PRIORITY_QUEUE_TIMEOUT" validate:"min=0"`
+
 	// Logging configuration
 	LogLevel  string `mapstructure:"LOG_LEVEL" validate:"required,oneof=DEBUG INFO WARNING ERROR CRITICAL"`
-	LogFormat string `mapstructure:"LOG_FORMAT" validate:"required,oneof=json text"`
+	LogFormat string `mapstructure:"LOG_FORMAT" validate:"required,oneof=json text gcp"`
+
+	// Log destinations: console, syslog (RFC 5424), journald, loki and/or elasticsearch
+	LogOutput         []string `mapstructure:"LOG_OUTPUT" validate:"omitempty,dive,oneof=console syslog journald loki elasticsearch"`
+	LogSyslogNetwork  string   `mapstructure:"LOG_SYSLOG_NETWORK" validate:"omitempty,oneof=udp tcp unix"`
+	LogSyslogAddress  string   `mapstructure:"LOG_SYSLOG_ADDRESS"`
+	LogSyslogFacility string   `mapstructure:"LOG_SYSLOG_FACILITY"`
+
+	// Log shipping to Loki or Elasticsearch (batched, bounded queue, drops counted)
+	LogShipURL           string        `mapstructure:"LOG_SHIP_URL" validate:"omitempty,url"`
+	LogShipLabels        []string      `mapstructure:"LOG_SHIP_LABELS" validate:"omitempty,dive,label_pair"`
+	LogShipIndex         string        `mapstructure:"LOG_SHIP_INDEX"`
+	LogShipHeaders       []string      `mapstructure:"LOG_SHIP_HEADERS" validate:"omitempty,dive,header_pair" pii:"secret"`
+	LogShipBatchSize     int           `mapstructure:"LOG_SHIP_BATCH_SIZE" validate:"min=0"`
+	LogShipFlushInterval time.Duration `mapstructure:"LOG_SHIP_FLUSH_INTERVAL" validate:"min=0"`
+	LogShipQueueSize     int           `mapstructure:"LOG_SHIP_QUEUE_SIZE" validate:"min=0"`
+	LogShipRetries       int           `mapstructure:"LOG_SHIP_RETRIES" validate:"min=0,max=10"`
+
+	// Optional log file written alongside console output, with its own format and level
+	LogFile       string `mapstructure:"LOG_FILE"`
+	LogFileFormat string `mapstructure:"LOG_FILE_FORMAT" validate:"omitempty,oneof=json text"`
+	LogFileLevel  string `mapstructure:"LOG_FILE_LEVEL" validate:"omitempty,oneof=DEBUG INFO WARNING ERROR CRITICAL"`
+
+	// Duplicate log sampling per LOG_SAMPLING_TICK (LOG_SAMPLING_INITIAL=0 disables)
+	LogSamplingTick       time.Duration `mapstructure:"LOG_SAMPLING_TICK" validate:"min=0"`
+	LogSamplingInitial    int           `mapstructure:"LOG_SAMPLING_INITIAL" validate:"min=0"`
+	LogSamplingThereafter int           `mapstructure:"LOG_SAMPLING_THEREAFTER" validate:"min=0"`
+
+	// Mask personal data (pii-tagged fields and keys such as email) in logs
+	LogRedactPII bool `mapstructure:"LOG_REDACT_PII"`
+
+	// Request logging: paths not logged (exact, route template or /* prefix),
+	// level per status class ("5xx=ERROR") and request headers captured
+	LogRequestSkipPaths []string `mapstructure:"LOG_REQUEST_SKIP_PATHS" validate:"omitempty,dive,startswith=/"`
+	LogRequestLevels    []string `mapstructure:"LOG_REQUEST_LEVELS" validate:"omitempty,dive,status_level"`
+	LogRequestHeaders   []string `mapstructure:"LOG_REQUEST_HEADERS"`
+
+	// Per-request phase timing (middleware, handler, serialization) added
+	// to request logs and a Server-Timing header: off, debug (header in
+	// DEBUG mode or for callers presenting ADMIN_TOKEN) or always
+	ServerTiming string `mapstructure:"SERVER_TIMING" validate:"omitempty,oneof=off debug always"`
+
+	// Slow request watchdog: requests in flight longer than the threshold
+	// are logged, with the stack of their goroutine, and counted (0
+	// disables); the scan interval defaults to min(1s, threshold/4)
+	SlowRequestThreshold     time.Duration `mapstructure:"SLOW_REQUEST_THRESHOLD" validate:"min=0"`
+	SlowRequestCheckInterval time.Duration `mapstructure:"SLOW_REQUEST_CHECK_INTERVAL" validate:"min=0"`
+	SlowRequestStacks        bool          `mapstructure:"SLOW_REQUEST_STACKS"`
+
+	// Go runtime sizing (zero values derive GOMAXPROCS/GOMEMLIMIT from cgroup limits)
+	RuntimeMaxProcs         int     `mapstructure:"RUNTIME_MAX_PROCS" validate:"min=0"`
+	RuntimeMemoryLimit      string  `mapstructure:"RUNTIME_MEMORY_LIMIT" validate:"omitempty,byte_size"`
+	RuntimeMemoryLimitRatio float64 `mapstructure:"RUNTIME_MEMORY_LIMIT_RATIO" validate:"min=0,max=1"`
+	RuntimeGCPercent        int     `mapstructure:"RUNTIME_GC_PERCENT" validate:"min=-1"`
+
+	// Infrastructure endpoints (optional)
+	DatabaseURL  string   `mapstructure:"DATABASE_URL" validate:"omitempty,url"`
+	RedisURL     string   `mapstructure:"REDIS_URL" validate:"omitempty,url"`
+	KafkaBrokers []string `mapstructure:"KAFKA_BROKERS" validate:"omitempty,dive,hostname_port"`
+
+	// Embedded bbolt file backing the users and, without Redis, the cache
+	// stores (disabled when empty); compacted every interval (0 = never)
+	EmbeddedStorePath            string        `mapstructure:"EMBEDDED_STORE_PATH"`
+	EmbeddedStoreCompactInterval time.Duration `mapstructure:"EMBEDDED_STORE_COMPACT_INTERVAL" validate:"min=0"`
+
+	// Share of the remaining request deadline granted to each repository
+	// query (0 or 1 grant all of it)
+	StoreDeadlineBudget float64 `mapstructure:"STORE_DEADLINE_BUDGET" validate:"min=0,max=1"`
+
+	// CORS policy (preflight results cached by browsers for CORS_MAX_AGE)
+	CORSAllowOrigins  []string      `mapstructure:"CORS_ALLOW_ORIGINS" validate:"omitempty,dive,required"`
+	CORSExposeHeaders []string      `mapstructure:"CORS_EXPOSE_HEADERS"`
+	CORSMaxAge        time.Duration `mapstructure:"CORS_MAX_AGE" validate:"min=0"`
+
+	// Headers added to every response as Name=Value pairs
+	DefaultHeaders       []string `mapstructure:"DEFAULT_HEADERS" validate:"omitempty,dive,header_pair"`
+	VersionHeaderEnabled bool     `mapstructure:"VERSION_HEADER_ENABLED"`
+
+	// Per-request context (caller identity, locale, time zone, feature flags).
+	// Callers are identified by the ID of the API key they present in
+	// PRINCIPAL_HEADER, declared as "id=sha256 hex digest" pairs; other
+	// callers are anonymous. API_KEY_TENANTS assigns key IDs to tenants as
+	// "id=tenant" pairs
+	PrincipalHeader string   `mapstructure:"PRINCIPAL_HEADER"`
+	APIKeys         []string `mapstructure:"API_KEYS" validate:"api_keys" pii:"secret"`
+	APIKeyTenants   []string `mapstructure:"API_KEY_TENANTS" validate:"omitempty,dive,key_tenant"`
+	TenantHeader    string   `mapstructure:"TENANT_HEADER"`
+	DefaultLocale   string   `mapstructure:"DEFAULT_LOCALE" validate:"required,bcp47_language_tag"`
+	DefaultTimezone string   `mapstructure:"DEFAULT_TIMEZONE" validate:"required,timezone"`
+	FeatureFlags    []string `mapstructure:"FEATURE_FLAGS"`
+
+	// Request recorder configuration (debugging aid); recordings go to
+	// RECORDER_DIR or, with RECORDER_STORAGE=object, the object store
+	RecorderEnabled      bool   `mapstructure:"RECORDER_ENABLED"`
+	RecorderStorage      string `mapstructure:"RECORDER_STORAGE" validate:"omitempty,oneof=file object"`
+	RecorderDir          string `mapstructure:"RECORDER_DIR"`
+	RecorderMaxEntries   int    `mapstructure:"RECORDER_MAX_ENTRIES" validate:"min=0"`
+	RecorderMaxBodyBytes int    `mapstructure:"RECORDER_MAX_BODY_BYTES" validate:"min=0"`
+
+	// Mask the personal data of JSON responses unless the caller presents
+	// the admin token; recordings are always masked
+	PIIMaskResponses bool `mapstructure:"PII_MASK_RESPONSES"`
+
+	// OpenAPI contract validation (off, log, reject)
+	OpenAPIValidation        string `mapstructure:"OPENAPI_VALIDATION" validate:"omitempty,oneof=off log reject"`
+	OpenAPIValidateResponses bool   `mapstructure:"OPENAPI_VALIDATE_RESPONSES"`
+
+	// Strict JSON decoding of request bodies, for every route or for the
+	// routes listed ("METHOD /route/template"): unknown fields are rejected
+	// and documents are bounded in nesting and array length (0 = unlimited)
+	StrictJSON            bool     `mapstructure:"STRICT_JSON"`
+	StrictJSONRoutes      []string `mapstructure:"STRICT_JSON_ROUTES" validate:"omitempty,dive,route_flag"`
+	StrictJSONMaxDepth    int      `mapstructure:"STRICT_JSON_MAX_DEPTH" validate:"min=0"`
+	StrictJSONMaxArrayLen int      `mapstructure:"STRICT_JSON_MAX_ARRAY_LEN" validate:"min=0"`
+
+	// JSON library of response rendering and request binding (default keeps
+	// the one of Gin's build tags, encoding/json unless built with one)
+	JSONEncoder string `mapstructure:"JSON_ENCODER" validate:"omitempty,oneof=default std go-json"`
+
+	// Request deduplication (coalesces identical concurrent GET/HEAD requests)
+	DedupEnabled bool `mapstructure:"DEDUP_ENABLED"`
+
+	// Usage quotas per API key or tenant (0 = unlimited). QUOTA_SUBJECT=tenant
+	// counts keys under their API_KEY_TENANTS tenant
+	QuotaEnabled       bool     `mapstructure:"QUOTA_ENABLED"`
+	QuotaSubject       string   `mapstructure:"QUOTA_SUBJECT" validate:"omitempty,oneof=key tenant"`
+	QuotaSubjectHeader string   `mapstructure:"QUOTA_SUBJECT_HEADER"`
+	QuotaDailyLimit    int64    `mapstructure:"QUOTA_DAILY_LIMIT" validate:"min=0"`
+	QuotaMonthlyLimit  int64    `mapstructure:"QUOTA_MONTHLY_LIMIT" validate:"min=0"`
+	QuotaOverrides     []string `mapstructure:"QUOTA_OVERRIDES" validate:"omitempty,dive,quota_override"`
+
+	// Cost-weighted rate limits per route group declared in a YAML file
+	// (see configs/ratelimit.example.yaml)
+	RateLimitConfigFile    string           `mapstructure:"RATE_LIMIT_CONFIG"`
+	RateLimitSubjectHeader string           `mapstructure:"RATE_LIMIT_SUBJECT_HEADER"`
+	RateLimitRules         []ratelimit.Rule `mapstructure:"-" validate:"dive"`
+
+	// Service level objectives per route prefix declared in a YAML file
+	// (see configs/slo.example.yaml)
+	SLOConfigFile         string          `mapstructure:"SLO_CONFIG"`
+	SLOEvaluationInterval time.Duration   `mapstructure:"SLO_EVALUATION_INTERVAL" validate:"min=1s"`
+	SLOObjectives         []slo.Objective `mapstructure:"-" validate:"dive"`
+
+	// Synthetic HTTP checks probing the server itself and upstreams,
+	// declared in a YAML file (see configs/synthetic.example.yaml); path
+	// URLs resolve against SYNTHETIC_BASE_URL, by default the loopback
+	// address of the server
+	SyntheticConfigFile string            `mapstructure:"SYNTHETIC_CONFIG"`
+	SyntheticBaseURL    string            `mapstructure:"SYNTHETIC_BASE_URL" validate:"omitempty,url"`
+	SyntheticInterval   time.Duration     `mapstructure:"SYNTHETIC_INTERVAL" validate:"min=1s"`
+	SyntheticTimeout    time.Duration     `mapstructure:"SYNTHETIC_TIMEOUT" validate:"min=1ms"`
+	SyntheticChecks     []synthetic.Check `mapstructure:"-" validate:"unique=Name,dive"`
+
+	// Route groups composing handler modules with middleware declared in a
+	// YAML file (see configs/routes.example.yaml); built-in groups otherwise
+	RoutesConfigFile string          `mapstructure:"ROUTES_CONFIG"`
+	RouteGroups      []routing.Group `mapstructure:"-" validate:"dive"`
+
+	// Per-route enable flags declared in a YAML file that is reloaded when
+	// it changes (see configs/routeflags.example.yaml)
+	RouteFlagsConfigFile     string            `mapstructure:"ROUTE_FLAGS_CONFIG"`
+	RouteFlagsReloadInterval time.Duration     `mapstructure:"ROUTE_FLAGS_RELOAD_INTERVAL" validate:"min=1s"`
+	RouteFlags               []routeflags.Flag `mapstructure:"-" validate:"dive"`
+
+	// Optional feature plugins enabled by name, configured per plugin in a
+	// YAML file (see configs/plugins.example.yaml)
+	PluginsEnabled    []string             `mapstructure:"PLUGINS_ENABLED" validate:"dive,required"`
+	PluginsConfigFile string               `mapstructure:"PLUGINS_CONFIG"`
+	PluginSettings    map[string]yaml.Node `mapstructure:"-"`
+
+	// Handlers and middleware served by external processes declared in a
+	// YAML file (see configs/extensions.example.yaml); each is a plugin
+	// enabled with PLUGINS_ENABLED
+	ExtensionsConfigFile string             `mapstructure:"EXTENSIONS_CONFIG"`
+	Extensions           []extension.Config `mapstructure:"-" validate:"dive"`
+
+	// Usage metering (billable events flushed in batches to sinks)
+	MeteringEnabled       bool          `mapstructure:"METERING_ENABLED"`
+	MeteringSubjectHeader string        `mapstructure:"METERING_SUBJECT_HEADER"`
+	MeteringKafkaTopic    string        `mapstructure:"METERING_KAFKA_TOPIC"`
+	MeteringBatchSize     int           `mapstructure:"METERING_BATCH_SIZE" validate:"min=0"`
+	MeteringFlushInterval time.Duration `mapstructure:"METERING_FLUSH_INTERVAL" validate:"min=0"`
+
+	// Service result caching (Redis when REDIS_URL is set, memory otherwise)
+	CacheEnabled    bool          `mapstructure:"CACHE_ENABLED"`
+	CacheDefaultTTL time.Duration `mapstructure:"CACHE_DEFAULT_TTL" validate:"min=0"`
+	CacheTTLs       []string      `mapstructure:"CACHE_TTLS" validate:"omitempty,dive,cache_ttl"`
+	CacheMaxEntries int           `mapstructure:"CACHE_MAX_ENTRIES" validate:"min=0"`
+
+	// Largest page repositories return; unbounded or oversized queries are
+	// bounded to it, and fail in debug mode
+	PaginationMaxPageSize int `mapstructure:"PAGINATION_MAX_PAGE_SIZE" validate:"min=1"`
+
+	// Signing key and validity of list cursors; without a key, cursors are
+	// signed with a per-process key and only valid on the issuing instance,
+	// so production requires one
+	PaginationCursorKey string        `mapstructure:"PAGINATION_CURSOR_KEY" validate:"required_if=Environment production" pii:"secret"`
+	PaginationCursorTTL time.Duration `mapstructure:"PAGINATION_CURSOR_TTL" validate:"min=0"`
+
+	// Heartbeat pushed to external monitors (disabled without URLs)
+	HeartbeatURLs       []string      `mapstructure:"HEARTBEAT_URLS" validate:"omitempty,dive,url" pii:"secret"`
+	HeartbeatInterval   time.Duration `mapstructure:"HEARTBEAT_INTERVAL" validate:"min=0"`
+	HeartbeatTimeout    time.Duration `mapstructure:"HEARTBEAT_TIMEOUT" validate:"min=0"`
+	HeartbeatRetries    int           `mapstructure:"HEARTBEAT_RETRIES" validate:"min=0,max=10"`
+	HeartbeatFailSuffix string        `mapstructure:"HEARTBEAT_FAIL_SUFFIX"`
+
+	// Embedded frontend SPA served for unmatched non-API routes
+	SPAEnabled bool `mapstructure:"SPA_ENABLED"`
+
+	// Outbound HTTP clients; named clients are declared in a YAML file
+	// (see configs/httpclients.example.yaml) and inherit these settings
+	HTTPClientsConfigFile           string              `mapstructure:"HTTP_CLIENTS_CONFIG"`
+	HTTPClientTimeout               time.Duration       `mapstructure:"HTTP_CLIENT_TIMEOUT" validate:"min=0"`
+	HTTPClientMaxIdleConns          int                 `mapstructure:"HTTP_CLIENT_MAX_IDLE_CONNS" validate:"min=0"`
+	HTTPClientMaxIdleConnsPerHost   int                 `mapstructure:"HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST" validate:"min=0"`
+	HTTPClientMaxConnsPerHost       int                 `mapstructure:"HTTP_CLIENT_MAX_CONNS_PER_HOST" validate:"min=0"`
+	HTTPClientIdleConnTimeout       time.Duration       `mapstructure:"HTTP_CLIENT_IDLE_CONN_TIMEOUT" validate:"min=0"`
+	HTTPClientDialTimeout           time.Duration       `mapstructure:"HTTP_CLIENT_DIAL_TIMEOUT" validate:"min=0"`
+	HTTPClientTLSHandshakeTimeout   time.Duration       `mapstructure:"HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT" validate:"min=0"`
+	HTTPClientResponseHeaderTimeout time.Duration       `mapstructure:"HTTP_CLIENT_RESPONSE_HEADER_TIMEOUT" validate:"min=0"`
+	HTTPClientPoolReportInterval    time.Duration       `mapstructure:"HTTP_CLIENT_POOL_REPORT_INTERVAL" validate:"min=0"`
+	HTTPClientDeadlineBudget        float64             `mapstructure:"HTTP_CLIENT_DEADLINE_BUDGET" validate:"min=0,max=1"`
+	HTTPClientDNSCache              bool                `mapstructure:"HTTP_CLIENT_DNS_CACHE"`
+	HTTPClientDNSMaxTTL             time.Duration       `mapstructure:"HTTP_CLIENT_DNS_MAX_TTL" validate:"min=0"`
+	HTTPClientDNSNegativeTTL        time.Duration       `mapstructure:"HTTP_CLIENT_DNS_NEGATIVE_TTL" validate:"min=0"`
+	HTTPClientCache                 bool                `mapstructure:"HTTP_CLIENT_CACHE"`
+	HTTPClientCacheMaxBodyBytes     int64               `mapstructure:"HTTP_CLIENT_CACHE_MAX_BODY_BYTES" validate:"min=0"`
+	HTTPClientCacheRevalidateTTL    time.Duration       `mapstructure:"HTTP_CLIENT_CACHE_REVALIDATE_TTL" validate:"min=0"`
+	HTTPClients                     []httpclient.Config `mapstructure:"-" validate:"dive"`
+
+	// Egress policy of the default HTTP client, inherited by named clients
+	// without their own (empty allowlists allow any value)
+	EgressAllowHosts     []string `mapstructure:"EGRESS_ALLOW_HOSTS" validate:"omitempty,dive,required"`
+	EgressAllowPorts     []int    `mapstructure:"EGRESS_ALLOW_PORTS" validate:"omitempty,dive,min=1,max=65535"`
+	EgressAllowSchemes   []string `mapstructure:"EGRESS_ALLOW_SCHEMES" validate:"omitempty,dive,oneof=http https"`
+	EgressBlockLinkLocal bool     `mapstructure:"EGRESS_BLOCK_LINK_LOCAL"`
+	EgressBlockPrivate   bool     `mapstructure:"EGRESS_BLOCK_PRIVATE"`
+
+	// Service discovery for named clients declaring a service (consul or
+	// dns SRV records under DISCOVERY_DNS_DOMAIN); empty disables it
+	DiscoveryProvider        string        `mapstructure:"DISCOVERY_PROVIDER" validate:"omitempty,oneof=consul dns"`
+	DiscoveryRefreshInterval time.Duration `mapstructure:"DISCOVERY_REFRESH_INTERVAL" validate:"min=0"`
+	DiscoveryEjectionPeriod  time.Duration `mapstructure:"DISCOVERY_EJECTION_PERIOD" validate:"min=0"`
+	DiscoveryDNSDomain       string        `mapstructure:"DISCOVERY_DNS_DOMAIN" validate:"required_if=DiscoveryProvider dns"`
+	ConsulAddr               string        `mapstructure:"CONSUL_ADDR" validate:"omitempty,url"`
+	ConsulToken              string        `mapstructure:"CONSUL_TOKEN" pii:"secret"`
+	ConsulDatacenter         string        `mapstructure:"CONSUL_DATACENTER"`
+
+	// Full-text search (embedded in-process index, or an Elasticsearch or
+	// OpenSearch cluster at SEARCH_URL); empty disables it
+	SearchProvider       string `mapstructure:"SEARCH_PROVIDER" validate:"omitempty,oneof=embedded elasticsearch opensearch"`
+	SearchURL            string `mapstructure:"SEARCH_URL" validate:"required_if=SearchProvider elasticsearch,required_if=SearchProvider opensearch,omitempty,url"`
+	SearchUsername       string `mapstructure:"SEARCH_USERNAME"`
+	SearchPassword       string `mapstructure:"SEARCH_PASSWORD" pii:"secret"`
+	SearchAPIKey         string `mapstructure:"SEARCH_API_KEY" pii:"secret"`
+	SearchRetainVersions int    `mapstructure:"SEARCH_RETAIN_VERSIONS" validate:"min=1"`
+
+	// Notifications delivered by the workers from a bounded queue (Redis
+	// when configured); each channel is enabled once configured. Retry
+	// policies per channel are declared in a YAML file (see
+	// configs/notifications.example.yaml), templates override the built-in
+	// ones from NOTIFICATIONS_TEMPLATES_DIR
+	NotificationsConfigFile   string                               `mapstructure:"NOTIFICATIONS_CONFIG"`
+	NotificationsRetry        map[string]notifications.RetryPolicy `mapstructure:"-" validate:"dive"`
+	NotificationsTemplatesDir string                               `mapstructure:"NOTIFICATIONS_TEMPLATES_DIR"`
+	NotificationsConcurrency  int                                  `mapstructure:"NOTIFICATIONS_CONCURRENCY" validate:"min=1"`
+	NotificationsQueueSize    int                                  `mapstructure:"NOTIFICATIONS_QUEUE_SIZE" validate:"min=1"`
+	NotificationsWelcomeEmail bool                                 `mapstructure:"NOTIFICATIONS_WELCOME_EMAIL"`
+	SMTPHost                  string                               `mapstructure:"SMTP_HOST"`
+	SMTPPort                  int                                  `mapstructure:"SMTP_PORT" validate:"min=0,max=65535"`
+	SMTPUsername              string                               `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword              string                               `mapstructure:"SMTP_PASSWORD" pii:"secret"`
+	SMTPFrom                  string                               `mapstructure:"SMTP_FROM" validate:"required_with=SMTPHost"`
+	TwilioAccountSID          string                               `mapstructure:"TWILIO_ACCOUNT_SID"`
+	TwilioAuthToken           string                               `mapstructure:"TWILIO_AUTH_TOKEN" validate:"required_with=TwilioAccountSID" pii:"secret"`
+	TwilioFrom                string                               `mapstructure:"TWILIO_FROM" validate:"required_with=TwilioAccountSID"`
+	FCMCredentialsFile        string                               `mapstructure:"FCM_CREDENTIALS_FILE"`
+	FCMProjectID              string                               `mapstructure:"FCM_PROJECT_ID"`
+	SlackWebhooks             []string                             `mapstructure:"SLACK_WEBHOOKS" validate:"omitempty,dive,slack_webhook" pii:"secret"`
+
+	// Object storage of generated files (a local directory served through
+	// signed /api/v1/files links, or an S3-compatible bucket with presigned
+	// links); empty disables the modules storing files
+	ObjectStorageProvider        string `mapstructure:"OBJECT_STORAGE_PROVIDER" validate:"omitempty,oneof=local s3"`
+	ObjectStorageDir             string `mapstructure:"OBJECT_STORAGE_DIR" validate:"required_if=ObjectStorageProvider local"`
+	ObjectStoragePublicURL       string `mapstructure:"OBJECT_STORAGE_PUBLIC_URL"`
+	ObjectStorageSigningKey      string `mapstructure:"OBJECT_STORAGE_SIGNING_KEY" pii:"secret"`
+	ObjectStorageEndpoint        string `mapstructure:"OBJECT_STORAGE_ENDPOINT" validate:"required_if=ObjectStorageProvider s3,omitempty,url"`
+	ObjectStorageRegion          string `mapstructure:"OBJECT_STORAGE_REGION"`
+	ObjectStorageBucket          string `mapstructure:"OBJECT_STORAGE_BUCKET" validate:"required_if=ObjectStorageProvider s3"`
+	ObjectStorageAccessKeyID     string `mapstructure:"OBJECT_STORAGE_ACCESS_KEY_ID" validate:"required_if=ObjectStorageProvider s3"`
+	ObjectStorageSecretAccessKey string `mapstructure:"OBJECT_STORAGE_SECRET_ACCESS_KEY" validate:"required_if=ObjectStorageProvider s3" pii:"secret"`
+	ObjectStoragePathStyle       bool   `mapstructure:"OBJECT_STORAGE_PATH_STYLE"`
+
+	// Reports rendered to PDF or HTML in the background (available with
+	// object storage); templates override the built-in HTML layout
+	ReportsTemplatesDir string        `mapstructure:"REPORTS_TEMPLATES_DIR"`
+	ReportsConcurrency  int           `mapstructure:"REPORTS_CONCURRENCY" validate:"min=1"`
+	ReportsMaxPending   int           `mapstructure:"REPORTS_MAX_PENDING" validate:"min=1"`
+	ReportsRetention    int           `mapstructure:"REPORTS_RETENTION" validate:"min=1"`
+	ReportsLinkTTL      time.Duration `mapstructure:"REPORTS_LINK_TTL" validate:"min=1s"`
+
+	// Files uploaded through /api/v1/uploads (available with object storage)
+	UploadsMaxSize int64         `mapstructure:"UPLOADS_MAX_SIZE" validate:"min=1"`
+	UploadsLinkTTL time.Duration `mapstructure:"UPLOADS_LINK_TTL" validate:"min=1s"`
+
+	// Malware scanning of uploads before they are stored (clamd over TCP or
+	// an external HTTP API); empty stores uploads unscanned. Fail open
+	// stores files the scanner could not check instead of rejecting them.
+	MalwareScanner      string        `mapstructure:"MALWARE_SCANNER" validate:"omitempty,oneof=clamd http"`
+	MalwareClamdAddress string        `mapstructure:"MALWARE_CLAMD_ADDRESS" validate:"required_if=MalwareScanner clamd"`
+	MalwareScanURL      string        `mapstructure:"MALWARE_SCAN_URL" validate:"required_if=MalwareScanner http,omitempty,url"`
+	MalwareScanToken    string        `mapstructure:"MALWARE_SCAN_TOKEN" pii:"secret"`
+	MalwareScanTimeout  time.Duration `mapstructure:"MALWARE_SCAN_TIMEOUT" validate:"min=1s"`
+	MalwareFailOpen     bool          `mapstructure:"MALWARE_FAIL_OPEN"`
+	MalwareQuarantine   bool          `mapstructure:"MALWARE_QUARANTINE"`
+
+	// Data subject requests (/admin/privacy): user deletions are erased
+	// after the grace period, export archives deleted after their TTL
+	PrivacyDeletionGrace time.Duration `mapstructure:"PRIVACY_DELETION_GRACE" validate:"min=0s"`
+	PrivacyExportTTL     time.Duration `mapstructure:"PRIVACY_EXPORT_TTL" validate:"min=1m"`
+	PrivacyLinkTTL       time.Duration `mapstructure:"PRIVACY_LINK_TTL" validate:"min=1s"`
+	PrivacySweepInterval time.Duration `mapstructure:"PRIVACY_SWEEP_INTERVAL" validate:"min=1s"`
+
+	// Named gRPC client connections declared in a YAML file
+	// (see configs/grpcclients.example.yaml)
+	GRPCClientsConfigFile string              `mapstructure:"GRPC_CLIENTS_CONFIG"`
+	GRPCClients           []grpcclient.Config `mapstructure:"-" validate:"dive"`
+
+	// Temporal workflow engine; empty TEMPORAL_ADDRESS disables the client
+	// and its workers. Module workflows without a task queue poll
+	// TEMPORAL_TASK_QUEUE; Temporal Cloud takes TEMPORAL_TLS with an API
+	// key or client certificate
+	TemporalAddress       string `mapstructure:"TEMPORAL_ADDRESS" validate:"omitempty,hostname_port"`
+	TemporalNamespace     string `mapstructure:"TEMPORAL_NAMESPACE" validate:"required_with=TemporalAddress"`
+	TemporalTaskQueue     string `mapstructure:"TEMPORAL_TASK_QUEUE" validate:"required_with=TemporalAddress"`
+	TemporalAPIKey        string `mapstructure:"TEMPORAL_API_KEY" pii:"secret"`
+	TemporalTLS           bool   `mapstructure:"TEMPORAL_TLS"`
+	TemporalTLSCAFile     string `mapstructure:"TEMPORAL_TLS_CA_FILE"`
+	TemporalTLSCertFile   string `mapstructure:"TEMPORAL_TLS_CERT_FILE" validate:"required_with=TemporalTLSKeyFile"`
+	TemporalTLSKeyFile    string `mapstructure:"TEMPORAL_TLS_KEY_FILE" validate:"required_with=TemporalTLSCertFile"`
+	TemporalTLSServerName string `mapstructure:"TEMPORAL_TLS_SERVER_NAME"`
+
+	// Reverse proxy routes declared in a YAML file (see configs/proxy.example.yaml)
+	ProxyConfigFile string        `mapstructure:"PROXY_CONFIG"`
+	ProxyRoutes     []proxy.Route `mapstructure:"-" validate:"dive"`
+
+	// Access control for /health/details and /metrics; open when all are empty
+	ObservabilityBasicAuth   string   `mapstructure:"OBSERVABILITY_BASIC_AUTH" validate:"omitempty,contains=:" pii:"secret"`
+	ObservabilityBearerToken string   `mapstructure:"OBSERVABILITY_BEARER_TOKEN" pii:"secret"`
+	ObservabilityAllowCIDRs  []string `mapstructure:"OBSERVABILITY_ALLOW_CIDRS" validate:"omitempty,dive,cidr"`
+
+	// Admin API (mounted under /admin only when a token is set)
+	AdminToken string `mapstructure:"ADMIN_TOKEN" pii:"secret"`
+
+	// On-demand runtime profiles captured through the admin API; profiles
+	// go to PROFILING_DIR or, with PROFILING_STORAGE=object, the object
+	// store, while the capture index always stays in PROFILING_DIR
+	ProfilingStorage     string        `mapstructure:"PROFILING_STORAGE" validate:"omitempty,oneof=file object"`
+	ProfilingDir         string        `mapstructure:"PROFILING_DIR"`
+	ProfilingMaxDuration time.Duration `mapstructure:"PROFILING_MAX_DURATION" validate:"min=0"`
+	ProfilingMaxCaptures int           `mapstructure:"PROFILING_MAX_CAPTURES" validate:"min=0"`
+
+	// Field-level encryption keys as id=base64 pairs of 32-byte keys; new
+	// values are encrypted with the primary key, the others only decrypt
+	EncryptionKeys         []string `mapstructure:"ENCRYPTION_KEYS" validate:"omitempty,dive,encryption_key" pii:"secret"`
+	EncryptionPrimaryKeyID string   `mapstructure:"ENCRYPTION_PRIMARY_KEY_ID" validate:"encryption_primary"`
+
+	// TOTP two-factor authentication (available with field encryption);
+	// the issuer defaults to APP_NAME
+	TOTPIssuer      string `mapstructure:"TOTP_ISSUER"`
+	TOTPSkew        int    `mapstructure:"TOTP_SKEW" validate:"min=0,max=5"`
+	TOTPBackupCodes int    `mapstructure:"TOTP_BACKUP_CODES" validate:"min=0,max=50"`
+
+	// Wrong codes in a row that lock verification, and for how long
+	// (0 = 5 and 15m)
+	TOTPMaxAttempts int           `mapstructure:"TOTP_MAX_ATTEMPTS" validate:"min=0"`
+	TOTPLockout     time.Duration `mapstructure:"TOTP_LOCKOUT" validate:"min=0"`
+
+	// Refresh tokens, rotated on every use and renewed for REFRESH_TOKEN_TTL
+	// up to REFRESH_TOKEN_MAX_AGE after login (0 = unlimited); stored in
+	// Redis when configured so revocation applies to all instances
+	RefreshTokenTTL    time.Duration `mapstructure:"REFRESH_TOKEN_TTL" validate:"min=1m"`
+	RefreshTokenMaxAge time.Duration `mapstructure:"REFRESH_TOKEN_MAX_AGE" validate:"min=0"`
+
+	// Access tokens, HS256 JWTs minted with every refresh token and valid
+	// for ACCESS_TOKEN_TTL; only refresh tokens are issued without a
+	// signing key (at least 32 bytes)
+	AccessTokenSigningKey string        `mapstructure:"ACCESS_TOKEN_SIGNING_KEY" validate:"omitempty,min=32" pii:"secret"`
+	AccessTokenTTL        time.Duration `mapstructure:"ACCESS_TOKEN_TTL" validate:"min=1m"`
+
+	// CAPTCHA verification of sensitive routes ("METHOD /route/template");
+	// disabled without a provider and bypassed in the test profile
+	CaptchaProvider  string   `mapstructure:"CAPTCHA_PROVIDER" validate:"omitempty,oneof=turnstile hcaptcha recaptcha"`
+	CaptchaSecret    string   `mapstructure:"CAPTCHA_SECRET" validate:"required_with=CaptchaProvider" pii:"secret"`
+	CaptchaVerifyURL string   `mapstructure:"CAPTCHA_VERIFY_URL" validate:"omitempty,url"`
+	CaptchaRoutes    []string `mapstructure:"CAPTCHA_ROUTES" validate:"omitempty,dive,route_flag"`
+	CaptchaHeader    string   `mapstructure:"CAPTCHA_HEADER"`
+	CaptchaFailOpen  bool     `mapstructure:"CAPTCHA_FAIL_OPEN"`
+
+	// Routes ("METHOD /route/template") admitting only principals who
+	// accepted the latest version of CONSENT_POLICIES; empty disables
+	ConsentRoutes   []string `mapstructure:"CONSENT_ROUTES" validate:"omitempty,dive,route_flag"`
+	ConsentPolicies []string `mapstructure:"CONSENT_POLICIES"`
+
+	// Seed data configuration
+	SeedOnStartup bool `mapstructure:"SEED_ON_STARTUP"`
+
+	// Startup warmup: each hook is bounded by WARMUP_TIMEOUT (0 = unbounded)
+	// and /health/ready answers 503 until all of them ran
+	WarmupTimeout time.Duration `mapstructure:"WARMUP_TIMEOUT" validate:"min=0"`
+
+	// Startup phases are logged once warm; exceeding STARTUP_BUDGET
+	// (0 = unlimited) is a warning. Heavy optional modules are built on
+	// first use or by their warmup hook when STARTUP_DEFER_MODULES is set
+	StartupBudget       time.Duration `mapstructure:"STARTUP_BUDGET" validate:"min=0"`
+	StartupDeferModules bool          `mapstructure:"STARTUP_DEFER_MODULES"`
+
+	// Path prefix stripped from requests by the Lambda entrypoint, e.g. the
+	// stage of an HTTP API or a custom domain base path mapping
+	LambdaBasePath string `mapstructure:"LAMBDA_BASE_PATH" validate:"omitempty,startswith=/"`
+
+	// Google Cloud Run compatibility, enabled when K_SERVICE is set: json
+	// logs use the gcp format, and with CLOUD_RUN_CPU_THROTTLED (CPU only
+	// allocated during requests) warmup runs before listening and
+	// background work is reported at startup. GCP_PROJECT_ID (default
+	// GOOGLE_CLOUD_PROJECT) ties gcp log entries to request traces
+	CloudRun             bool   `mapstructure:"CLOUD_RUN"`
+	CloudRunCPUThrottled bool   `mapstructure:"CLOUD_RUN_CPU_THROTTLED"`
+	GCPProjectID         string `mapstructure:"GCP_PROJECT_ID"`
+
+	// Origin of each setting set by a file or the environment, recorded by
+	// Load; settings missing here have their default value
+	Sources map[string]Origin `mapstructure:"-"`
 }
 
 // Load reads configuration from environment variables and .env file.
@@ -32,8 +523,9 @@ type Config struct {
 //
 // Configuration precedence:
 // 1. Environment variables (highest)
-// 2. .env file
-// 3. Default values (lowest)
+// 2. Age-encrypted env file
+// 3. .env file
+// 4. Default values (lowest)
 func Load() (*Config, error) {
 	v := viper.New()
 
@@ -41,10 +533,257 @@ func Load() (*Config, error) {
 	v.SetDefault("APP_NAME", "CHANGE_ME")
 	v.SetDefault("APP_VERSION", "0.1.0")
 	v.SetDefault("DEBUG", false)
+	v.SetDefault("APP_ENV", "development")
 	v.SetDefault("HOST", "0.0.0.0")
 	v.SetDefault("PORT", 8000)
+	v.SetDefault("SERVER_READ_TIMEOUT", "10s")
+	v.SetDefault("SERVER_READ_HEADER_TIMEOUT", "5s")
+	v.SetDefault("SERVER_WRITE_TIMEOUT", "10s")
+	v.SetDefault("SERVER_IDLE_TIMEOUT", "120s")
+	v.SetDefault("SERVER_SHUTDOWN_TIMEOUT", "30s")
+	v.SetDefault("SERVER_MAX_HEADER_BYTES", 1048576)
+	v.SetDefault("SERVER_MAX_CONNECTIONS", 0)
+	v.SetDefault("TRUSTED_PROXIES", []string{})
+	v.SetDefault("SERVER_REQUEST_TIMEOUT", "0s")
+	v.SetDefault("SERVER_KEEP_ALIVE", true)
+	v.SetDefault("SERVER_MAX_REQUESTS_PER_CONN", 0)
+	v.SetDefault("SERVER_TCP_KEEPALIVE", true)
+	v.SetDefault("SERVER_TCP_KEEPALIVE_IDLE", "15s")
+	v.SetDefault("SERVER_TCP_KEEPALIVE_INTERVAL", "15s")
+	v.SetDefault("SERVER_TCP_KEEPALIVE_COUNT", 9)
+	v.SetDefault("PRIORITY_QUEUE_ENABLED", false)
+	v.SetDefault("PRIORITY_ADMIN_CONCURRENCY", 16)
+	v.SetDefault("PRIORITY_ADMIN_QUEUE_SIZE", 64)
+	v.SetDefault("PRIORITY_PUBLIC_CONCURRENCY", 256)
+	v.SetDefault("PRIORITY_PUBLIC_QUEUE_SIZE", 1024)
+	v.SetDefault("PRIORITY_QUEUE_TIMEOUT", "2s")
 	v.SetDefault("LOG_LEVEL", "INFO")
 	v.SetDefault("LOG_FORMAT", "json")
+	v.SetDefault("LOG_OUTPUT", []string{"console"})
+	v.SetDefault("LOG_SYSLOG_NETWORK", "")
+	v.SetDefault("LOG_SYSLOG_ADDRESS", "")
+	v.SetDefault("LOG_SYSLOG_FACILITY", "local0")
+	v.SetDefault("LOG_SHIP_URL", "")
+	v.SetDefault("LOG_SHIP_LABELS", []string{})
+	v.SetDefault("LOG_SHIP_INDEX", "logs-change-me")
+	v.SetDefault("LOG_SHIP_HEADERS", []string{})
+	v.SetDefault("LOG_SHIP_BATCH_SIZE", 500)
+	v.SetDefault("LOG_SHIP_FLUSH_INTERVAL", "2s")
+	v.SetDefault("LOG_SHIP_QUEUE_SIZE", 10000)
+	v.SetDefault("LOG_SHIP_RETRIES", 3)
+	v.SetDefault("LOG_FILE", "")
+	v.SetDefault("LOG_FILE_FORMAT", "json")
+	v.SetDefault("LOG_FILE_LEVEL", "INFO")
+	v.SetDefault("LOG_SAMPLING_TICK", "1s")
+	v.SetDefault("LOG_SAMPLING_INITIAL", 100)
+	v.SetDefault("LOG_SAMPLING_THEREAFTER", 100)
+	v.SetDefault("LOG_REDACT_PII", true)
+	v.SetDefault("LOG_REQUEST_SKIP_PATHS", []string{"/health/*", "/metrics"})
+	v.SetDefault("LOG_REQUEST_LEVELS", []string{"2xx=INFO", "3xx=INFO", "4xx=WARNING", "5xx=ERROR"})
+	v.SetDefault("LOG_REQUEST_HEADERS", []string{})
+	v.SetDefault("SERVER_TIMING", "off")
+	v.SetDefault("SLOW_REQUEST_THRESHOLD", "0s")
+	v.SetDefault("SLOW_REQUEST_CHECK_INTERVAL", "0s")
+	v.SetDefault("SLOW_REQUEST_STACKS", true)
+	v.SetDefault("RUNTIME_MAX_PROCS", 0)
+	v.SetDefault("RUNTIME_MEMORY_LIMIT", "")
+	v.SetDefault("RUNTIME_MEMORY_LIMIT_RATIO", 0.9)
+	v.SetDefault("RUNTIME_GC_PERCENT", 0)
+	v.SetDefault("DATABASE_URL", "")
+	v.SetDefault("REDIS_URL", "")
+	v.SetDefault("KAFKA_BROKERS", []string{})
+	v.SetDefault("EMBEDDED_STORE_PATH", "")
+	v.SetDefault("EMBEDDED_STORE_COMPACT_INTERVAL", "24h")
+	v.SetDefault("STORE_DEADLINE_BUDGET", 0.8)
+	v.SetDefault("CORS_ALLOW_ORIGINS", constants.CORSAllowOrigins)
+	v.SetDefault("CORS_EXPOSE_HEADERS", constants.CORSExposeHeaders)
+	v.SetDefault("CORS_MAX_AGE", "10m")
+	v.SetDefault("DEFAULT_HEADERS", []string{})
+	v.SetDefault("VERSION_HEADER_ENABLED", false)
+	v.SetDefault("PRINCIPAL_HEADER", "X-API-Key")
+	v.SetDefault("API_KEYS", []string{})
+	v.SetDefault("API_KEY_TENANTS", []string{})
+	v.SetDefault("TENANT_HEADER", "X-Tenant-ID")
+	v.SetDefault("DEFAULT_LOCALE", "en")
+	v.SetDefault("DEFAULT_TIMEZONE", "UTC")
+	v.SetDefault("FEATURE_FLAGS", []string{})
+	v.SetDefault("RECORDER_ENABLED", false)
+	v.SetDefault("RECORDER_STORAGE", "file")
+	v.SetDefault("RECORDER_DIR", "./recordings")
+	v.SetDefault("RECORDER_MAX_ENTRIES", 1000)
+	v.SetDefault("RECORDER_MAX_BODY_BYTES", 65536)
+	v.SetDefault("PII_MASK_RESPONSES", false)
+	v.SetDefault("OPENAPI_VALIDATION", "off")
+	v.SetDefault("OPENAPI_VALIDATE_RESPONSES", false)
+	v.SetDefault("STRICT_JSON", false)
+	v.SetDefault("STRICT_JSON_ROUTES", []string{})
+	v.SetDefault("STRICT_JSON_MAX_DEPTH", 32)
+	v.SetDefault("STRICT_JSON_MAX_ARRAY_LEN", 1000)
+	v.SetDefault("JSON_ENCODER", "default")
+	v.SetDefault("DEDUP_ENABLED", false)
+	v.SetDefault("QUOTA_ENABLED", false)
+	v.SetDefault("QUOTA_SUBJECT", "key")
+	v.SetDefault("QUOTA_SUBJECT_HEADER", "X-API-Key")
+	v.SetDefault("QUOTA_DAILY_LIMIT", 0)
+	v.SetDefault("QUOTA_MONTHLY_LIMIT", 0)
+	v.SetDefault("QUOTA_OVERRIDES", []string{})
+	v.SetDefault("RATE_LIMIT_CONFIG", "")
+	v.SetDefault("SLO_CONFIG", "")
+	v.SetDefault("SLO_EVALUATION_INTERVAL", "30s")
+	v.SetDefault("SYNTHETIC_CONFIG", "")
+	v.SetDefault("SYNTHETIC_BASE_URL", "")
+	v.SetDefault("SYNTHETIC_INTERVAL", "1m")
+	v.SetDefault("SYNTHETIC_TIMEOUT", "10s")
+	v.SetDefault("ROUTES_CONFIG", "")
+	v.SetDefault("ROUTE_FLAGS_CONFIG", "")
+	v.SetDefault("ROUTE_FLAGS_RELOAD_INTERVAL", "10s")
+	v.SetDefault("PLUGINS_ENABLED", []string{})
+	v.SetDefault("PLUGINS_CONFIG", "")
+	v.SetDefault("EXTENSIONS_CONFIG", "")
+	v.SetDefault("RATE_LIMIT_SUBJECT_HEADER", "X-API-Key")
+	v.SetDefault("METERING_ENABLED", false)
+	v.SetDefault("METERING_SUBJECT_HEADER", "X-API-Key")
+	v.SetDefault("METERING_KAFKA_TOPIC", "")
+	v.SetDefault("METERING_BATCH_SIZE", 500)
+	v.SetDefault("METERING_FLUSH_INTERVAL", "5s")
+	v.SetDefault("CACHE_ENABLED", false)
+	v.SetDefault("CACHE_DEFAULT_TTL", "1m")
+	v.SetDefault("CACHE_TTLS", []string{})
+	v.SetDefault("CACHE_MAX_ENTRIES", 10000)
+	v.SetDefault("PAGINATION_MAX_PAGE_SIZE", 100)
+	v.SetDefault("PAGINATION_CURSOR_KEY", "")
+	v.SetDefault("PAGINATION_CURSOR_TTL", "1h")
+	v.SetDefault("HEARTBEAT_URLS", []string{})
+	v.SetDefault("HEARTBEAT_INTERVAL", "1m")
+	v.SetDefault("HEARTBEAT_TIMEOUT", "10s")
+	v.SetDefault("HEARTBEAT_RETRIES", 2)
+	v.SetDefault("HEARTBEAT_FAIL_SUFFIX", "")
+	v.SetDefault("SPA_ENABLED", false)
+	v.SetDefault("HTTP_CLIENTS_CONFIG", "")
+	v.SetDefault("HTTP_CLIENT_TIMEOUT", "30s")
+	v.SetDefault("HTTP_CLIENT_MAX_IDLE_CONNS", 10)
+	v.SetDefault("HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST", 10)
+	v.SetDefault("HTTP_CLIENT_MAX_CONNS_PER_HOST", 0)
+	v.SetDefault("HTTP_CLIENT_IDLE_CONN_TIMEOUT", "90s")
+	v.SetDefault("HTTP_CLIENT_DIAL_TIMEOUT", "30s")
+	v.SetDefault("HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT", "10s")
+	v.SetDefault("HTTP_CLIENT_RESPONSE_HEADER_TIMEOUT", "0s")
+	v.SetDefault("HTTP_CLIENT_POOL_REPORT_INTERVAL", "1m")
+	v.SetDefault("HTTP_CLIENT_DEADLINE_BUDGET", 0.8)
+	v.SetDefault("HTTP_CLIENT_DNS_CACHE", false)
+	v.SetDefault("HTTP_CLIENT_DNS_MAX_TTL", "5m")
+	v.SetDefault("HTTP_CLIENT_DNS_NEGATIVE_TTL", "5s")
+	v.SetDefault("HTTP_CLIENT_CACHE", false)
+	v.SetDefault("HTTP_CLIENT_CACHE_MAX_BODY_BYTES", 1048576)
+	v.SetDefault("HTTP_CLIENT_CACHE_REVALIDATE_TTL", "1h")
+	v.SetDefault("EGRESS_ALLOW_HOSTS", []string{})
+	v.SetDefault("EGRESS_ALLOW_PORTS", []int{})
+	v.SetDefault("EGRESS_ALLOW_SCHEMES", []string{})
+	v.SetDefault("EGRESS_BLOCK_LINK_LOCAL", true)
+	v.SetDefault("EGRESS_BLOCK_PRIVATE", false)
+	v.SetDefault("DISCOVERY_PROVIDER", "")
+	v.SetDefault("SEARCH_PROVIDER", "")
+	v.SetDefault("SEARCH_URL", "")
+	v.SetDefault("SEARCH_USERNAME", "")
+	v.SetDefault("SEARCH_PASSWORD", "")
+	v.SetDefault("SEARCH_API_KEY", "")
+	v.SetDefault("SEARCH_RETAIN_VERSIONS", 2)
+	v.SetDefault("NOTIFICATIONS_CONFIG", "")
+	v.SetDefault("NOTIFICATIONS_TEMPLATES_DIR", "")
+	v.SetDefault("NOTIFICATIONS_CONCURRENCY", 4)
+	v.SetDefault("NOTIFICATIONS_QUEUE_SIZE", 1000)
+	v.SetDefault("NOTIFICATIONS_WELCOME_EMAIL", false)
+	v.SetDefault("SMTP_HOST", "")
+	v.SetDefault("SMTP_PORT", 587)
+	v.SetDefault("SMTP_USERNAME", "")
+	v.SetDefault("SMTP_PASSWORD", "")
+	v.SetDefault("SMTP_FROM", "")
+	v.SetDefault("TWILIO_ACCOUNT_SID", "")
+	v.SetDefault("TWILIO_AUTH_TOKEN", "")
+	v.SetDefault("TWILIO_FROM", "")
+	v.SetDefault("FCM_CREDENTIALS_FILE", "")
+	v.SetDefault("FCM_PROJECT_ID", "")
+	v.SetDefault("SLACK_WEBHOOKS", []string{})
+	v.SetDefault("OBJECT_STORAGE_PROVIDER", "")
+	v.SetDefault("OBJECT_STORAGE_DIR", "")
+	v.SetDefault("OBJECT_STORAGE_PUBLIC_URL", "/api/v1/files")
+	v.SetDefault("OBJECT_STORAGE_SIGNING_KEY", "")
+	v.SetDefault("OBJECT_STORAGE_ENDPOINT", "")
+	v.SetDefault("OBJECT_STORAGE_REGION", "us-east-1")
+	v.SetDefault("OBJECT_STORAGE_BUCKET", "")
+	v.SetDefault("OBJECT_STORAGE_ACCESS_KEY_ID", "")
+	v.SetDefault("OBJECT_STORAGE_SECRET_ACCESS_KEY", "")
+	v.SetDefault("OBJECT_STORAGE_PATH_STYLE", false)
+	v.SetDefault("REPORTS_TEMPLATES_DIR", "")
+	v.SetDefault("REPORTS_CONCURRENCY", 2)
+	v.SetDefault("REPORTS_MAX_PENDING", 100)
+	v.SetDefault("REPORTS_RETENTION", 1000)
+	v.SetDefault("REPORTS_LINK_TTL", "15m")
+	v.SetDefault("UPLOADS_MAX_SIZE", 10<<20)
+	v.SetDefault("UPLOADS_LINK_TTL", "15m")
+	v.SetDefault("MALWARE_SCANNER", "")
+	v.SetDefault("MALWARE_CLAMD_ADDRESS", "localhost:3310")
+	v.SetDefault("MALWARE_SCAN_URL", "")
+	v.SetDefault("MALWARE_SCAN_TOKEN", "")
+	v.SetDefault("MALWARE_SCAN_TIMEOUT", "30s")
+	v.SetDefault("MALWARE_FAIL_OPEN", false)
+	v.SetDefault("MALWARE_QUARANTINE", true)
+	v.SetDefault("PRIVACY_DELETION_GRACE", "720h")
+	v.SetDefault("PRIVACY_EXPORT_TTL", "24h")
+	v.SetDefault("PRIVACY_LINK_TTL", "15m")
+	v.SetDefault("PRIVACY_SWEEP_INTERVAL", "1m")
+	v.SetDefault("DISCOVERY_REFRESH_INTERVAL", "30s")
+	v.SetDefault("DISCOVERY_EJECTION_PERIOD", "30s")
+	v.SetDefault("DISCOVERY_DNS_DOMAIN", "")
+	v.SetDefault("CONSUL_ADDR", "http://127.0.0.1:8500")
+	v.SetDefault("CONSUL_TOKEN", "")
+	v.SetDefault("CONSUL_DATACENTER", "")
+	v.SetDefault("GRPC_CLIENTS_CONFIG", "")
+	v.SetDefault("TEMPORAL_ADDRESS", "")
+	v.SetDefault("TEMPORAL_NAMESPACE", "default")
+	v.SetDefault("TEMPORAL_TASK_QUEUE", "change-me")
+	v.SetDefault("TEMPORAL_API_KEY", "")
+	v.SetDefault("TEMPORAL_TLS", false)
+	v.SetDefault("TEMPORAL_TLS_CA_FILE", "")
+	v.SetDefault("TEMPORAL_TLS_CERT_FILE", "")
+	v.SetDefault("TEMPORAL_TLS_KEY_FILE", "")
+	v.SetDefault("TEMPORAL_TLS_SERVER_NAME", "")
+	v.SetDefault("PROXY_CONFIG", "")
+	v.SetDefault("OBSERVABILITY_BASIC_AUTH", "")
+	v.SetDefault("OBSERVABILITY_BEARER_TOKEN", "")
+	v.SetDefault("OBSERVABILITY_ALLOW_CIDRS", []string{})
+	v.SetDefault("ADMIN_TOKEN", "")
+	v.SetDefault("ENCRYPTION_KEYS", []string{})
+	v.SetDefault("ENCRYPTION_PRIMARY_KEY_ID", "")
+	v.SetDefault("TOTP_ISSUER", "")
+	v.SetDefault("TOTP_SKEW", 1)
+	v.SetDefault("TOTP_BACKUP_CODES", 10)
+	v.SetDefault("TOTP_MAX_ATTEMPTS", 5)
+	v.SetDefault("TOTP_LOCKOUT", "15m")
+	v.SetDefault("REFRESH_TOKEN_TTL", "168h")
+	v.SetDefault("REFRESH_TOKEN_MAX_AGE", "720h")
+	v.SetDefault("ACCESS_TOKEN_SIGNING_KEY", "")
+	v.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	v.SetDefault("CAPTCHA_PROVIDER", "")
+	v.SetDefault("CAPTCHA_SECRET", "")
+	v.SetDefault("CAPTCHA_VERIFY_URL", "")
+	v.SetDefault("CAPTCHA_ROUTES", []string{"POST /api/v1/users", "POST /api/v1/auth/sessions"})
+	v.SetDefault("CAPTCHA_HEADER", "X-Captcha-Token")
+	v.SetDefault("CAPTCHA_FAIL_OPEN", false)
+	v.SetDefault("CONSENT_ROUTES", []string{})
+	v.SetDefault("CONSENT_POLICIES", []string{"terms"})
+	v.SetDefault("PROFILING_STORAGE", "file")
+	v.SetDefault("PROFILING_DIR", "./profiles")
+	v.SetDefault("PROFILING_MAX_DURATION", "1m")
+	v.SetDefault("PROFILING_MAX_CAPTURES", 20)
+	v.SetDefault("SEED_ON_STARTUP", false)
+	v.SetDefault("WARMUP_TIMEOUT", "10s")
+	v.SetDefault("STARTUP_BUDGET", "10s")
+	v.SetDefault("STARTUP_DEFER_MODULES", true)
+	v.SetDefault("LAMBDA_BASE_PATH", "")
+	v.SetDefault("CLOUD_RUN", os.Getenv("K_SERVICE") != "")
+	v.SetDefault("CLOUD_RUN_CPU_THROTTLED", true)
+	v.SetDefault("GCP_PROJECT_ID", os.Getenv("GOOGLE_CLOUD_PROJECT"))
 
 	// Read from .env file (optional, won't error if missing)
 	v.SetConfigName(".env")
@@ -58,14 +797,136 @@ func Load() (*Config, error) {
 	// Environment variables override file config
 	v.AutomaticEnv()
 
+	// Merge the encrypted env file so environment config can live in git
+	var encrypted *viper.Viper
+	if path := v.GetString(envEncryptedFile); path != "" {
+		data, err := decryptEnvFile(path, v.GetString(envAgeIdentity), v.GetString(envAgeKeyFile))
+		if err != nil {
+			return nil, err
+		}
+		encrypted = viper.New()
+		encrypted.SetConfigFile(path)
+		encrypted.SetConfigType("env")
+		if err := encrypted.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("failed to parse encrypted config: %w", err)
+		}
+		if err := v.MergeConfigMap(encrypted.AllSettings()); err != nil {
+			return nil, fmt.Errorf("failed to parse encrypted config: %w", err)
+		}
+	}
+
 	// Unmarshal into Config struct
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	cfg.Sources = sources(v, encrypted)
 
-	// Normalize log level to uppercase
+	// Normalize log levels to uppercase
 	cfg.LogLevel = strings.ToUpper(cfg.LogLevel)
+	cfg.LogFileLevel = strings.ToUpper(cfg.LogFileLevel)
+
+	// Normalize environment to lowercase
+	cfg.Environment = strings.ToLower(cfg.Environment)
+
+	// Load named HTTP client declarations
+	if cfg.HTTPClientsConfigFile != "" {
+		clients, err := loadHTTPClients(cfg.HTTPClientsConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.HTTPClients = clients
+	}
+
+	// Load named gRPC client declarations
+	if cfg.GRPCClientsConfigFile != "" {
+		clients, err := loadGRPCClients(cfg.GRPCClientsConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.GRPCClients = clients
+	}
+
+	// Load proxy route declarations
+	if cfg.ProxyConfigFile != "" {
+		routes, err := loadProxyRoutes(cfg.ProxyConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ProxyRoutes = routes
+	}
+
+	// Load rate limit rules
+	if cfg.RateLimitConfigFile != "" {
+		rules, err := loadRateLimitRules(cfg.RateLimitConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RateLimitRules = rules
+	}
+
+	// Load service level objectives
+	if cfg.SLOConfigFile != "" {
+		objectives, err := loadSLOObjectives(cfg.SLOConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SLOObjectives = objectives
+	}
+
+	// Load synthetic checks
+	if cfg.SyntheticConfigFile != "" {
+		checks, err := loadSyntheticChecks(cfg.SyntheticConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SyntheticChecks = checks
+	}
+
+	// Load notification retry policies
+	if cfg.NotificationsConfigFile != "" {
+		retry, err := loadNotificationsRetry(cfg.NotificationsConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.NotificationsRetry = retry
+	}
+
+	// Load route groups
+	if cfg.RoutesConfigFile != "" {
+		groups, err := loadRouteGroups(cfg.RoutesConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RouteGroups = groups
+	}
+
+	// Load route flags
+	if cfg.RouteFlagsConfigFile != "" {
+		flags, err := loadRouteFlags(cfg.RouteFlagsConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RouteFlags = flags
+	}
+
+	// Load plugin settings
+	if cfg.PluginsConfigFile != "" {
+		settings, err := loadPluginSettings(cfg.PluginsConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.PluginSettings = settings
+	}
+
+	// Load extension processes
+	if cfg.ExtensionsConfigFile != "" {
+		extensions, err := loadExtensions(cfg.ExtensionsConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Extensions = extensions
+	}
 
 	// Validate configuration
 	if err := validate.Struct(&cfg); err != nil {
@@ -74,3 +935,197 @@ func Load() (*Config, error) {
 
 	return &cfg, nil
 }
+
+// loadHTTPClients reads the clients list from an HTTP clients YAML file.
+func loadHTTPClients(path string) ([]httpclient.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read http clients config: %w", err)
+	}
+
+	var file struct {
+		Clients []httpclient.Config `yaml:"clients"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse http clients config: %w", err)
+	}
+	return file.Clients, nil
+}
+
+// loadGRPCClients reads the clients list from a gRPC clients YAML file.
+func loadGRPCClients(path string) ([]grpcclient.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grpc clients config: %w", err)
+	}
+
+	var file struct {
+		Clients []grpcclient.Config `yaml:"clients"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse grpc clients config: %w", err)
+	}
+	return file.Clients, nil
+}
+
+// loadProxyRoutes reads the routes list from a proxy YAML file.
+func loadProxyRoutes(path string) ([]proxy.Route, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proxy config: %w", err)
+	}
+
+	var file struct {
+		Routes []proxy.Route `yaml:"routes"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse proxy config: %w", err)
+	}
+	return file.Routes, nil
+}
+
+// loadRateLimitRules reads the rules list from a rate limit YAML file.
+func loadRateLimitRules(path string) ([]ratelimit.Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit config: %w", err)
+	}
+
+	var file struct {
+		Rules []ratelimit.Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit config: %w", err)
+	}
+	return file.Rules, nil
+}
+
+// loadSLOObjectives reads the objectives list from an SLO YAML file.
+func loadSLOObjectives(path string) ([]slo.Objective, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read slo config: %w", err)
+	}
+
+	var file struct {
+		Objectives []slo.Objective `yaml:"objectives"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse slo config: %w", err)
+	}
+	return file.Objectives, nil
+}
+
+// loadSyntheticChecks reads the checks list from a synthetic checks YAML
+// file.
+func loadSyntheticChecks(path string) ([]synthetic.Check, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read synthetic config: %w", err)
+	}
+
+	var file struct {
+		Checks []synthetic.Check `yaml:"checks"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse synthetic config: %w", err)
+	}
+	return file.Checks, nil
+}
+
+// loadNotificationsRetry reads the retry policies per channel from a
+// notifications YAML file.
+func loadNotificationsRetry(path string) (map[string]notifications.RetryPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifications config: %w", err)
+	}
+
+	var file struct {
+		Retry map[string]notifications.RetryPolicy `yaml:"retry"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse notifications config: %w", err)
+	}
+	return file.Retry, nil
+}
+
+// loadRouteGroups reads the groups list from a routes YAML file.
+func loadRouteGroups(path string) ([]routing.Group, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes config: %w", err)
+	}
+
+	var file struct {
+		Groups []routing.Group `yaml:"groups"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse routes config: %w", err)
+	}
+	return file.Groups, nil
+}
+
+// loadRouteFlags reads the routes list from a route flags YAML file.
+func loadRouteFlags(path string) ([]routeflags.Flag, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read route flags config: %w", err)
+	}
+
+	var file struct {
+		Routes []routeflags.Flag `yaml:"routes"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse route flags config: %w", err)
+	}
+	return file.Routes, nil
+}
+
+// loadPluginSettings reads the per-plugin settings from a plugins YAML
+// file; each plugin decodes its own entry.
+func loadPluginSettings(path string) (map[string]yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins config: %w", err)
+	}
+
+	var file struct {
+		Plugins map[string]yaml.Node `yaml:"plugins"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse plugins config: %w", err)
+	}
+	return file.Plugins, nil
+}
+
+// loadExtensions reads the extensions list from an extensions YAML file.
+func loadExtensions(path string) ([]extension.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extensions config: %w", err)
+	}
+
+	var file struct {
+		Extensions []extension.Config `yaml:"extensions"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse extensions config: %w", err)
+	}
+	return file.Extensions, nil
+}
+
+// LoadRouteFlags reads and validates a route flags YAML file; it reloads
+// ROUTE_FLAGS_CONFIG at runtime.
+func LoadRouteFlags(path string) ([]routeflags.Flag, error) {
+	flags, err := loadRouteFlags(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, flag := range flags {
+		if err := validate.Struct(flag); err != nil {
+			return nil, fmt.Errorf("route flags validation failed: %w", err)
+		}
+	}
+	return flags, nil
+}
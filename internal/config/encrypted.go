@@ -0,0 +1,66 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Environment variables locating the encrypted config and its key. They are
+// read before the config is decoded and are not part of Config, so the key
+// never ends up in the loaded configuration.
+const (
+	envEncryptedFile = "CONFIG_ENCRYPTED_FILE"
+	envAgeIdentity   = "AGE_IDENTITY"
+	envAgeKeyFile    = "AGE_IDENTITY_FILE"
+)
+
+// decryptEnvFile decrypts an age-encrypted env file (armored or binary)
+// with the identities in identity, or read from identityFile when identity
+// is empty. identityFile is typically a secret mounted from a KMS or
+// secret manager.
+func decryptEnvFile(path, identity, identityFile string) ([]byte, error) {
+	if identity == "" && identityFile != "" {
+		data, err := os.ReadFile(identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read age identity file: %w", err)
+		}
+		identity = string(data)
+	}
+	if identity == "" {
+		return nil, fmt.Errorf("%s requires %s or %s", envEncryptedFile, envAgeIdentity, envAgeKeyFile)
+	}
+
+	identities, err := age.ParseIdentities(strings.NewReader(identity))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open encrypted config: %w", err)
+	}
+	defer file.Close()
+
+	var src io.Reader = bufio.NewReader(file)
+	if peek, _ := src.(*bufio.Reader).Peek(len(armor.Header)); string(peek) == armor.Header {
+		src = armor.NewReader(src)
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, r); err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return out.Bytes(), nil
+}
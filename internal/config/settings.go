@@ -0,0 +1,135 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/pii"
+	"github.com/spf13/viper"
+)
+
+// Sources of a setting, in increasing precedence.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file" // .env or the encrypted env file
+	SourceEnv     = "env"
+)
+
+// Origin is where a setting was loaded from; File names the env file of
+// settings from a file.
+type Origin struct {
+	Source string `json:"source" example:"env"`
+	File   string `json:"file,omitempty" example:".env"`
+}
+
+// Setting is the effective value of a configuration key and its origin.
+// Secrets (pii-tagged fields) are replaced with pii.Redacted and passwords
+// in URLs are masked; Redacted reports either.
+type Setting struct {
+	Value any `json:"value"`
+	Origin
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// Settings returns the effective configuration keyed by environment
+// variable, as loaded and normalized by Load. Settings declared in YAML
+// files are not included, only the variables locating the files.
+func (c *Config) Settings() map[string]Setting {
+	rv := reflect.ValueOf(c).Elem()
+	rt := rv.Type()
+	settings := make(map[string]Setting, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		key := settingKey(sf)
+		if key == "" {
+			continue
+		}
+		origin, ok := c.Sources[key]
+		if !ok {
+			origin = Origin{Source: SourceDefault}
+		}
+		value, redacted := settingValue(rv.Field(i), sf)
+		settings[key] = Setting{Value: value, Origin: origin, Redacted: redacted}
+	}
+	return settings
+}
+
+// sources records the origin of the settings not left at their default,
+// mirroring the precedence of Load. encrypted holds the encrypted env file
+// when one was merged.
+func sources(v, encrypted *viper.Viper) map[string]Origin {
+	origins := make(map[string]Origin)
+	rt := reflect.TypeOf(Config{})
+	for i := 0; i < rt.NumField(); i++ {
+		key := settingKey(rt.Field(i))
+		switch {
+		case key == "":
+		case os.Getenv(key) != "":
+			// Viper ignores empty environment variables
+			origins[key] = Origin{Source: SourceEnv}
+		case encrypted != nil && encrypted.InConfig(key):
+			origins[key] = Origin{Source: SourceFile, File: encrypted.ConfigFileUsed()}
+		case v.InConfig(key):
+			origins[key] = Origin{Source: SourceFile, File: v.ConfigFileUsed()}
+		}
+	}
+	return origins
+}
+
+// settingKey returns the environment variable of a Config field, or ""
+// for fields that are not loaded from one.
+func settingKey(sf reflect.StructField) string {
+	key := sf.Tag.Get("mapstructure")
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+// settingValue returns the displayed value of a setting and whether it
+// was redacted. Durations are shown as configured ("10s") rather than in
+// nanoseconds; unset secrets stay visible as unset.
+func settingValue(v reflect.Value, sf reflect.StructField) (any, bool) {
+	if _, secret := sf.Tag.Lookup(pii.Tag); secret {
+		if v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+			return v.Interface(), false
+		}
+		return pii.Redacted, true
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String(), false
+	case string:
+		return redactURL(value)
+	case []string:
+		masked, redacted := make([]string, len(value)), false
+		for i, s := range value {
+			var ok bool
+			masked[i], ok = redactURL(s)
+			redacted = redacted || ok
+		}
+		return masked, redacted
+	default:
+		return value, false
+	}
+}
+
+// redactURL masks the password of a URL such as DATABASE_URL, reporting
+// whether there was one.
+func redactURL(s string) (string, bool) {
+	if !strings.Contains(s, "@") {
+		return s, false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s, false
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s, false
+	}
+	return u.Redacted(), true
+}
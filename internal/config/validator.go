@@ -1,14 +1,93 @@
 package config
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
+	"github.com/luminosita/change-me/internal/core/routeflags"
+	"github.com/luminosita/change-me/pkg/apikey"
+	"github.com/luminosita/change-me/pkg/validation"
 )
 
 var validate = newValidator()
 
-// newValidator creates a new validator instance with custom validation rules.
+// quotaOverridePattern matches "subject=daily/monthly" quota overrides.
+var quotaOverridePattern = regexp.MustCompile(`^[^=\s]+=\d+/\d+$`)
+
+// cacheTTLPattern matches "method=duration" cache TTL overrides.
+var cacheTTLPattern = regexp.MustCompile(`^[^=\s]+=([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`)
+
+// headerPairPattern matches "Name=Value" response header declarations.
+var headerPairPattern = regexp.MustCompile(`^[A-Za-z0-9-]+=.*$`)
+
+// labelPairPattern matches "name=value" Loki stream labels.
+var labelPairPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=.*$`)
+
+// byteSizePattern matches sizes in GOMEMLIMIT syntax, e.g. "512MiB".
+var byteSizePattern = regexp.MustCompile(`^[0-9]+(B|KiB|MiB|GiB|TiB)?$`)
+
+// statusLevelPattern matches "5xx=ERROR" status class log levels.
+var statusLevelPattern = regexp.MustCompile(`^[1-5]xx=(DEBUG|INFO|WARNING|ERROR)$`)
+
+// extensionRoutePattern matches "METHOD /route/template" extension routes.
+var extensionRoutePattern = regexp.MustCompile(`^[A-Z]+ /\S*$`)
+
+// encryptionKeyPattern matches "id=base64" pairs of 32-byte keys.
+var encryptionKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+=[A-Za-z0-9+/]{43}=$`)
+
+// slackWebhookPattern matches "name=https://hooks..." webhook pairs.
+var slackWebhookPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+=https?://\S+$`)
+
+// keyTenantPattern matches "key-id=tenant" API key tenant assignments.
+var keyTenantPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+=[A-Za-z0-9_.-]+$`)
+
+// newValidator creates a new validator instance with the shared custom tags
+// (see pkg/validation) and configuration-specific rules.
 func newValidator() *validator.Validate {
-	return validator.New()
+	v := validator.New()
+	_ = validation.Apply(v)
+	_ = v.RegisterValidation("quota_override", func(fl validator.FieldLevel) bool {
+		return quotaOverridePattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("cache_ttl", func(fl validator.FieldLevel) bool {
+		return cacheTTLPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("header_pair", func(fl validator.FieldLevel) bool {
+		return headerPairPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("label_pair", func(fl validator.FieldLevel) bool {
+		return labelPairPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("byte_size", func(fl validator.FieldLevel) bool {
+		return byteSizePattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("status_level", func(fl validator.FieldLevel) bool {
+		return statusLevelPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("route_flag", func(fl validator.FieldLevel) bool {
+		return routeflags.ValidRoute(fl.Field().String())
+	})
+	_ = v.RegisterValidation("extension_route", func(fl validator.FieldLevel) bool {
+		return extensionRoutePattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("encryption_key", func(fl validator.FieldLevel) bool {
+		return encryptionKeyPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("slack_webhook", func(fl validator.FieldLevel) bool {
+		return slackWebhookPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("api_keys", func(fl validator.FieldLevel) bool {
+		_, err := apikey.Parse(fl.Field().Interface().([]string))
+		return err == nil
+	})
+	_ = v.RegisterValidation("key_tenant", func(fl validator.FieldLevel) bool {
+		return keyTenantPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("encryption_primary", func(fl validator.FieldLevel) bool {
+		return validEncryptionPrimary(fl.Field().String(), fl.Parent().FieldByName("EncryptionKeys").Interface().([]string))
+	})
+	return v
 }
 
 // Validate validates the configuration struct using go-playground/validator.
@@ -16,3 +95,17 @@ func newValidator() *validator.Validate {
 func Validate(cfg *Config) error {
 	return validate.Struct(cfg)
 }
+
+// validEncryptionPrimary reports whether primary names one of keys, or is
+// empty without keys.
+func validEncryptionPrimary(primary string, keys []string) bool {
+	if len(keys) == 0 {
+		return primary == ""
+	}
+	for _, key := range keys {
+		if id, _, _ := strings.Cut(key, "="); id == primary {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+// Package apperrors defines the domain error taxonomy shared by all modules.
+//
+// Modules declare their errors with New, tagging each with a Kind and a
+// stable machine-readable code. Transport layers translate errors with
+// HTTPStatus and GRPCCode without knowing about individual modules:
+//
+//	var ErrNotFound = apperrors.New(apperrors.KindNotFound, "user_not_found", "user not found")
+//
+//	errors.Is(err, users.ErrNotFound)      // specific error
+//	errors.Is(err, apperrors.ErrNotFound)  // any error of the kind
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"runtime"
+	"strings"
+)
+
+// Kind classifies an error independently of the module that produced it.
+type Kind string
+
+// Error kinds understood by the transport mapping tables.
+const (
+	KindInternal     Kind = "internal_error"
+	KindInvalid      Kind = "invalid_request"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindRateLimited  Kind = "rate_limited"
+	KindUnavailable  Kind = "unavailable"
+	KindTimeout      Kind = "deadline_exceeded"
+)
+
+// Sentinels matching any error of their kind via errors.Is.
+var (
+	ErrInternal     = sentinel(KindInternal)
+	ErrInvalid      = sentinel(KindInvalid)
+	ErrUnauthorized = sentinel(KindUnauthorized)
+	ErrForbidden    = sentinel(KindForbidden)
+	ErrNotFound     = sentinel(KindNotFound)
+	ErrConflict     = sentinel(KindConflict)
+	ErrRateLimited  = sentinel(KindRateLimited)
+	ErrUnavailable  = sentinel(KindUnavailable)
+	ErrTimeout      = sentinel(KindTimeout)
+)
+
+// maxStackDepth bounds the number of frames recorded per error.
+const maxStackDepth = 32
+
+// Error is a classified domain error.
+type Error struct {
+	kind     Kind
+	code     string
+	message  string
+	meta     map[string]any
+	cause    error
+	stack    []uintptr
+	sentinel bool
+}
+
+// New declares a domain error and adds its code to the catalog, using the
+// message as description. Declared errors are package-level variables, so
+// no stack is recorded; use WithMeta or Wrap at the call site.
+// It panics if the code is already registered.
+func New(kind Kind, code, message string) *Error {
+	Register(Entry{Code: code, Kind: kind, Description: message})
+	return &Error{kind: kind, code: code, message: message}
+}
+
+func sentinel(kind Kind) *Error {
+	e := New(kind, string(kind), strings.ReplaceAll(string(kind), "_", " "))
+	e.sentinel = true
+	return e
+}
+
+// Describe sets the catalog description of a declared error and returns it.
+func (e *Error) Describe(description string) *Error {
+	describe(e.code, description)
+	return e
+}
+
+// Error returns the message followed by the cause, if any.
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.message + ": " + e.cause.Error()
+	}
+	return e.message
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is reports whether target is this error, or the sentinel for its kind.
+// Copies made by WithMeta and WithCause match the declared error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if t.sentinel {
+		return t.kind == e.kind
+	}
+	return t.kind == e.kind && t.code == e.code && t.message == e.message && t.cause == nil && len(t.meta) == 0
+}
+
+// Kind returns the error kind.
+func (e *Error) Kind() Kind { return e.kind }
+
+// Code returns the stable machine-readable error code.
+func (e *Error) Code() string { return e.code }
+
+// Message returns the human-readable message without the cause.
+func (e *Error) Message() string { return e.message }
+
+// Meta returns a copy of the attached metadata.
+func (e *Error) Meta() map[string]any { return maps.Clone(e.meta) }
+
+// WithMeta returns a copy carrying the key/value pairs and the caller's
+// stack. Keys must be strings; pairs with other keys are ignored.
+func (e *Error) WithMeta(keyvals ...any) *Error {
+	c := e.clone()
+	if c.meta == nil {
+		c.meta = make(map[string]any, len(keyvals)/2)
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key, ok := keyvals[i].(string); ok {
+			c.meta[key] = keyvals[i+1]
+		}
+	}
+	return c
+}
+
+// WithCause returns a copy wrapping cause and recording the caller's stack.
+func (e *Error) WithCause(cause error) *Error {
+	c := e.clone()
+	c.cause = cause
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	c.sentinel = false
+	c.meta = maps.Clone(e.meta)
+	c.stack = callers(4)
+	return &c
+}
+
+// withStack annotates an error with context and the stack where it was wrapped.
+type withStack struct {
+	message string
+	err     error
+	stack   []uintptr
+}
+
+func (w *withStack) Error() string { return w.message + ": " + w.err.Error() }
+func (w *withStack) Unwrap() error { return w.err }
+
+// Wrap annotates err with a message and the caller's stack, preserving its
+// kind, code and metadata. It returns nil when err is nil.
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &withStack{message: message, err: err, stack: callers(3)}
+}
+
+// Wrapf is Wrap with a formatted message.
+func Wrapf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return &withStack{message: fmt.Sprintf(format, args...), err: err, stack: callers(3)}
+}
+
+// KindOf returns the kind of the first classified error in err's chain.
+// Unclassified errors are KindTimeout when the context deadline expired,
+// otherwise KindInternal.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	return KindInternal
+}
+
+// CodeOf returns the code of the first classified error in err's chain,
+// or the code of the unclassified error's kind (see KindOf).
+func CodeOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return string(KindOf(err))
+}
+
+// MetaOf merges the metadata of every classified error in err's chain.
+// Outer errors win on conflicting keys.
+func MetaOf(err error) map[string]any {
+	var meta map[string]any
+	for ; err != nil; err = errors.Unwrap(err) {
+		e, ok := err.(*Error)
+		if !ok {
+			continue
+		}
+		for k, v := range e.meta {
+			if meta == nil {
+				meta = make(map[string]any)
+			}
+			if _, exists := meta[k]; !exists {
+				meta[k] = v
+			}
+		}
+	}
+	return meta
+}
+
+// StackOf returns the frames recorded closest to where err originated,
+// or nil when no error in the chain captured a stack.
+func StackOf(err error) []runtime.Frame {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch e := err.(type) {
+		case *withStack:
+			pcs = e.stack
+		case *Error:
+			if e.stack != nil {
+				pcs = e.stack
+			}
+		}
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	var out []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		out = append(out, frame)
+		if !more {
+			return out
+		}
+	}
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	return pcs[:n]
+}
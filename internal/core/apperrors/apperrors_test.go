@@ -0,0 +1,82 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var errWidgetMissing = New(KindNotFound, "widget_not_found", "widget not found")
+
+func TestError_IsMatchesDeclaredErrorAndKind(t *testing.T) {
+	err := fmt.Errorf("load: %w", errWidgetMissing.WithMeta("id", 7))
+
+	assert.ErrorIs(t, err, errWidgetMissing)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "load: widget not found", err.Error())
+}
+
+func TestKindAndCodeOf(t *testing.T) {
+	assert.Equal(t, KindNotFound, KindOf(Wrap(errWidgetMissing, "fetch")))
+	assert.Equal(t, "widget_not_found", CodeOf(Wrap(errWidgetMissing, "fetch")))
+	assert.Equal(t, KindInternal, KindOf(errors.New("boom")))
+	assert.Equal(t, "internal_error", CodeOf(errors.New("boom")))
+
+	expired := fmt.Errorf("query users: %w", context.DeadlineExceeded)
+	assert.Equal(t, KindTimeout, KindOf(expired))
+	assert.Equal(t, "deadline_exceeded", CodeOf(expired))
+}
+
+func TestMetaOf_OuterWins(t *testing.T) {
+	inner := errWidgetMissing.WithMeta("id", 7, "shard", "a")
+	outer := New(KindInternal, "widget_sync_failed", "sync failed").WithCause(inner).WithMeta("shard", "b")
+
+	assert.Equal(t, map[string]any{"id": 7, "shard": "b"}, MetaOf(outer))
+	assert.Nil(t, MetaOf(errors.New("plain")))
+}
+
+func TestWrap_RecordsStackAndPreservesNil(t *testing.T) {
+	assert.NoError(t, Wrap(nil, "ignored"))
+	assert.Nil(t, StackOf(errWidgetMissing))
+
+	err := Wrapf(errWidgetMissing, "widget %d", 7)
+	assert.Equal(t, "widget 7: widget not found", err.Error())
+
+	frames := StackOf(err)
+	require.NotEmpty(t, frames)
+	assert.True(t, strings.HasSuffix(frames[0].Function, "TestWrap_RecordsStackAndPreservesNil"), frames[0].Function)
+}
+
+func TestMappingTables(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   codes.Code
+	}{
+		{ErrInvalid, http.StatusBadRequest, codes.InvalidArgument},
+		{ErrUnauthorized, http.StatusUnauthorized, codes.Unauthenticated},
+		{ErrForbidden, http.StatusForbidden, codes.PermissionDenied},
+		{errWidgetMissing, http.StatusNotFound, codes.NotFound},
+		{ErrConflict, http.StatusConflict, codes.AlreadyExists},
+		{ErrRateLimited, http.StatusTooManyRequests, codes.ResourceExhausted},
+		{ErrUnavailable, http.StatusServiceUnavailable, codes.Unavailable},
+		{ErrTimeout, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{errors.New("boom"), http.StatusInternalServerError, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.status, HTTPStatus(tt.err))
+			assert.Equal(t, tt.code, GRPCCode(tt.err))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package apperrors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Entry documents an error code clients may receive.
+type Entry struct {
+	Code        string `json:"code"`
+	Kind        Kind   `json:"kind"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+// catalog holds every registered error code.
+var catalog = struct {
+	sync.RWMutex
+	entries map[string]Entry
+}{entries: make(map[string]Entry)}
+
+// Register adds a code emitted outside the domain error types (transport
+// or middleware failures) to the catalog and returns the code. Status
+// defaults to the kind's HTTP status. It panics on duplicate codes, so
+// registrations belong in package-level declarations.
+func Register(entry Entry) string {
+	if entry.Status == 0 {
+		entry.Status = statusOf(entry.Kind)
+	}
+
+	catalog.Lock()
+	defer catalog.Unlock()
+	if _, exists := catalog.entries[entry.Code]; exists {
+		panic(fmt.Sprintf("apperrors: duplicate error code %q", entry.Code))
+	}
+	catalog.entries[entry.Code] = entry
+	return entry.Code
+}
+
+// Catalog returns all registered error codes sorted by code.
+func Catalog() []Entry {
+	catalog.RLock()
+	defer catalog.RUnlock()
+
+	entries := make([]Entry, 0, len(catalog.entries))
+	for _, e := range catalog.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// describe replaces the description of a registered code.
+func describe(code, description string) {
+	catalog.Lock()
+	defer catalog.Unlock()
+	if e, ok := catalog.entries[code]; ok {
+		e.Description = description
+		catalog.entries[code] = e
+	}
+}
@@ -0,0 +1,53 @@
+package apperrors
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// httpStatus maps error kinds to HTTP status codes.
+var httpStatus = map[Kind]int{
+	KindInternal:     http.StatusInternalServerError,
+	KindInvalid:      http.StatusBadRequest,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindRateLimited:  http.StatusTooManyRequests,
+	KindUnavailable:  http.StatusServiceUnavailable,
+	KindTimeout:      http.StatusGatewayTimeout,
+}
+
+// grpcCode maps error kinds to gRPC status codes.
+var grpcCode = map[Kind]codes.Code{
+	KindInternal:     codes.Internal,
+	KindInvalid:      codes.InvalidArgument,
+	KindUnauthorized: codes.Unauthenticated,
+	KindForbidden:    codes.PermissionDenied,
+	KindNotFound:     codes.NotFound,
+	KindConflict:     codes.AlreadyExists,
+	KindRateLimited:  codes.ResourceExhausted,
+	KindUnavailable:  codes.Unavailable,
+	KindTimeout:      codes.DeadlineExceeded,
+}
+
+// HTTPStatus returns the HTTP status code for err's kind.
+func HTTPStatus(err error) int {
+	return statusOf(KindOf(err))
+}
+
+func statusOf(kind Kind) int {
+	if status, ok := httpStatus[kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the gRPC status code for err's kind.
+func GRPCCode(err error) codes.Code {
+	if code, ok := grpcCode[KindOf(err)]; ok {
+		return code
+	}
+	return codes.Internal
+}
@@ -0,0 +1,84 @@
+// Package audit provides the audit field and soft-delete conventions of
+// repositories.
+//
+// Entities embed Fields; repositories stamp them on writes with the actor
+// of the request principal and hide soft-deleted entities from reads unless
+// the context opts in with WithDeleted:
+//
+//	user.Fields.Created(ctx, now)          // on insert
+//	user.Fields.Updated(ctx, now)          // on update
+//	user.Fields.Deleted(ctx, now)          // instead of removing the row
+//	if !audit.Visible(ctx, user.Fields) {} // on every read
+//
+// Admin tools and restore flows read deleted entities with:
+//
+//	users, err := repo.List(audit.WithDeleted(ctx), params)
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/reqctx"
+)
+
+// Fields are the audit columns of an entity. Actors are request
+// principals, empty for anonymous requests and background work.
+type Fields struct {
+	CreatedAt time.Time
+	CreatedBy string
+	UpdatedAt time.Time
+	UpdatedBy string
+	DeletedAt time.Time // Zero unless soft-deleted
+	DeletedBy string
+}
+
+// Created stamps a new entity as created and updated at now by the
+// principal of ctx.
+func (f *Fields) Created(ctx context.Context, now time.Time) {
+	now = now.UTC()
+	actor := reqctx.Principal(ctx)
+	f.CreatedAt, f.CreatedBy = now, actor
+	f.UpdatedAt, f.UpdatedBy = now, actor
+}
+
+// Updated stamps an entity as updated at now by the principal of ctx.
+func (f *Fields) Updated(ctx context.Context, now time.Time) {
+	f.UpdatedAt, f.UpdatedBy = now.UTC(), reqctx.Principal(ctx)
+}
+
+// Deleted soft-deletes an entity at now on behalf of the principal of ctx.
+func (f *Fields) Deleted(ctx context.Context, now time.Time) {
+	f.Updated(ctx, now)
+	f.DeletedAt, f.DeletedBy = f.UpdatedAt, f.UpdatedBy
+}
+
+// Restored undoes a soft delete at now on behalf of the principal of ctx.
+func (f *Fields) Restored(ctx context.Context, now time.Time) {
+	f.Updated(ctx, now)
+	f.DeletedAt, f.DeletedBy = time.Time{}, ""
+}
+
+// IsDeleted reports whether the entity is soft-deleted.
+func (f Fields) IsDeleted() bool {
+	return !f.DeletedAt.IsZero()
+}
+
+type withDeletedKey struct{}
+
+// WithDeleted returns a copy of ctx under which repositories also return
+// soft-deleted entities.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withDeletedKey{}, true)
+}
+
+// IncludesDeleted reports whether ctx opted in to soft-deleted entities.
+func IncludesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(withDeletedKey{}).(bool)
+	return include
+}
+
+// Visible reports whether a read under ctx returns an entity with fields.
+func Visible(ctx context.Context, f Fields) bool {
+	return !f.IsDeleted() || IncludesDeleted(ctx)
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields_StampActorsFromPrincipal(t *testing.T) {
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "key-1"})
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	var f Fields
+	f.Created(ctx, created)
+	assert.Equal(t, created.UTC(), f.CreatedAt)
+	assert.Equal(t, "key-1", f.CreatedBy)
+	assert.Equal(t, f.CreatedAt, f.UpdatedAt)
+
+	later := created.Add(time.Hour)
+	f.Updated(context.Background(), later)
+	assert.Equal(t, "key-1", f.CreatedBy)
+	assert.Empty(t, f.UpdatedBy, "background work has no actor")
+	assert.Equal(t, later.UTC(), f.UpdatedAt)
+
+	f.Deleted(ctx, later)
+	assert.True(t, f.IsDeleted())
+	assert.Equal(t, "key-1", f.DeletedBy)
+
+	f.Restored(ctx, later)
+	assert.False(t, f.IsDeleted())
+	assert.Empty(t, f.DeletedBy)
+}
+
+func TestVisible_HidesDeletedByDefault(t *testing.T) {
+	ctx := context.Background()
+	deleted := Fields{DeletedAt: time.Now()}
+
+	assert.True(t, Visible(ctx, Fields{}))
+	assert.False(t, Visible(ctx, deleted))
+	assert.True(t, Visible(WithDeleted(ctx), deleted))
+	assert.False(t, IncludesDeleted(ctx))
+}
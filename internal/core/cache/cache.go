@@ -0,0 +1,135 @@
+// Package cache memoizes service and repository method results in a shared
+// Store.
+//
+// Each cached method is a Method with its own key namespace and TTL.
+// Entries are invalidated explicitly, typically from domain event handlers,
+// rather than by waiting for the TTL:
+//
+//	get := cache.NewMethod[users.UserID, *users.User](store, "users.Get", time.Minute, users.UserID.String)
+//	user, err := get.Do(ctx, id, next.Get)
+//	...
+//	bus.Subscribe(users.EventUserCreated, func(ctx context.Context, e events.Event) {
+//		_ = get.Invalidate(ctx, e.(users.UserCreated).User.ID)
+//	})
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store persists cached values. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value under key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes keys; missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+
+	// DeletePrefix removes every key starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// keyPrefix namespaces cache entries in shared stores.
+const keyPrefix = "cache:"
+
+// Method memoizes the results of one method taking an argument of type A
+// and returning V. Values are stored as JSON, so V must round-trip through
+// encoding/json. Errors are never cached, and store failures fall back to
+// calling the method.
+type Method[A, V any] struct {
+	store Store
+	name  string
+	ttl   time.Duration
+	key   func(A) string
+}
+
+// NewMethod creates a cached method. key derives the cache key from the
+// argument; name must be unique per store. A zero ttl disables caching.
+func NewMethod[A, V any](store Store, name string, ttl time.Duration, key func(A) string) *Method[A, V] {
+	return &Method[A, V]{store: store, name: name, ttl: ttl, key: key}
+}
+
+// Name returns the method name used as key namespace.
+func (m *Method[A, V]) Name() string { return m.name }
+
+// Do returns the cached result for arg, or calls fn and caches its result.
+func (m *Method[A, V]) Do(ctx context.Context, arg A, fn func(context.Context, A) (V, error)) (V, error) {
+	if m.ttl <= 0 {
+		return fn(ctx, arg)
+	}
+
+	key := m.keyFor(arg)
+	if data, ok, err := m.store.Get(ctx, key); err == nil && ok {
+		var v V
+		if json.Unmarshal(data, &v) == nil {
+			return v, nil
+		}
+	}
+
+	v, err := fn(ctx, arg)
+	if err != nil {
+		return v, err
+	}
+	if data, err := json.Marshal(v); err == nil {
+		_ = m.store.Set(ctx, key, data, m.ttl)
+	}
+	return v, nil
+}
+
+// Invalidate removes the cached result for arg.
+func (m *Method[A, V]) Invalidate(ctx context.Context, arg A) error {
+	return m.store.Delete(ctx, m.keyFor(arg))
+}
+
+// InvalidateAll removes every cached result of the method.
+func (m *Method[A, V]) InvalidateAll(ctx context.Context) error {
+	return m.store.DeletePrefix(ctx, keyPrefix+m.name+":")
+}
+
+func (m *Method[A, V]) keyFor(arg A) string {
+	return keyPrefix + m.name + ":" + m.key(arg)
+}
+
+// ParseTTLs parses per-method TTL overrides in "method=duration" form,
+// e.g. "users.Get=5m".
+func ParseTTLs(entries []string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid cache TTL %q: expected method=duration", entry)
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache TTL %q: %w", entry, err)
+		}
+		ttls[name] = ttl
+	}
+	return ttls, nil
+}
+
+// TTLs resolves method TTLs from a default and per-method overrides.
+type TTLs struct {
+	Default   time.Duration
+	Overrides map[string]time.Duration
+}
+
+// For returns the TTL configured for a method.
+func (t TTLs) For(method string) time.Duration {
+	if ttl, ok := t.Overrides[method]; ok {
+		return ttl
+	}
+	return t.Default
+}
+
+// IntKey formats integer arguments as cache keys.
+func IntKey(v int) string { return strconv.Itoa(v) }
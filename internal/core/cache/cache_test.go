@@ -0,0 +1,90 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/cache"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID   int
+	Name string
+}
+
+// counting returns a loader that counts its invocations.
+func counting(calls *int, err error) func(context.Context, int) (item, error) {
+	return func(_ context.Context, id int) (item, error) {
+		*calls++
+		return item{ID: id, Name: "item"}, err
+	}
+}
+
+func TestMethod_MemoizesUntilInvalidated(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewCacheStore(0)
+	get := cache.NewMethod[int, item](store, "items.Get", time.Minute, cache.IntKey)
+	other := cache.NewMethod[int, item](store, "items.Other", time.Minute, cache.IntKey)
+
+	calls := 0
+	for range 3 {
+		v, err := get.Do(ctx, 1, counting(&calls, nil))
+		require.NoError(t, err)
+		assert.Equal(t, item{ID: 1, Name: "item"}, v)
+	}
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, get.Invalidate(ctx, 1))
+	_, _ = get.Do(ctx, 1, counting(&calls, nil))
+	assert.Equal(t, 2, calls)
+
+	otherCalls := 0
+	_, _ = other.Do(ctx, 1, counting(&otherCalls, nil))
+	_, _ = get.Do(ctx, 2, counting(&calls, nil))
+	require.NoError(t, get.InvalidateAll(ctx))
+
+	_, _ = get.Do(ctx, 1, counting(&calls, nil))
+	_, _ = other.Do(ctx, 1, counting(&otherCalls, nil))
+	assert.Equal(t, 4, calls, "InvalidateAll drops every key of the method")
+	assert.Equal(t, 1, otherCalls, "other methods keep their entries")
+}
+
+func TestMethod_DoesNotCacheErrorsOrZeroTTL(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewCacheStore(0)
+	boom := errors.New("boom")
+
+	failing := cache.NewMethod[int, item](store, "items.Failing", time.Minute, cache.IntKey)
+	calls := 0
+	for range 2 {
+		_, err := failing.Do(ctx, 1, counting(&calls, boom))
+		assert.ErrorIs(t, err, boom)
+	}
+	assert.Equal(t, 2, calls)
+
+	disabled := cache.NewMethod[int, item](store, "items.Disabled", 0, cache.IntKey)
+	calls = 0
+	_, _ = disabled.Do(ctx, 1, counting(&calls, nil))
+	_, _ = disabled.Do(ctx, 1, counting(&calls, nil))
+	assert.Equal(t, 2, calls)
+}
+
+func TestParseTTLsAndTTLs(t *testing.T) {
+	overrides, err := cache.ParseTTLs([]string{"users.Get=5m", "users.List=0s"})
+	require.NoError(t, err)
+
+	ttls := cache.TTLs{Default: time.Minute, Overrides: overrides}
+	assert.Equal(t, 5*time.Minute, ttls.For("users.Get"))
+	assert.Equal(t, time.Duration(0), ttls.For("users.List"))
+	assert.Equal(t, time.Minute, ttls.For("orders.Get"))
+
+	_, err = cache.ParseTTLs([]string{"users.Get"})
+	assert.Error(t, err)
+	_, err = cache.ParseTTLs([]string{"users.Get=later"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+// Package consent tracks the acceptance of versioned policy documents,
+// such as terms of service and privacy policies, by request principals.
+//
+// Publishing a new version of a policy makes earlier acceptances outdated:
+// routes requiring the policy reject principals until they accept the
+// latest version. Acceptances are kept as an append-only history with the
+// client evidence of each one.
+package consent
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+)
+
+// Domain errors returned by the consent module.
+var (
+	ErrPolicyNotFound = apperrors.New(apperrors.KindNotFound, "consent_policy_not_found",
+		"policy not found")
+	ErrPolicyInvalid = apperrors.New(apperrors.KindInvalid, "consent_policy_invalid",
+		"policy name and version are required").
+		Describe("Policy names are lowercase letters, digits, dashes and underscores.")
+	ErrVersionExists = apperrors.New(apperrors.KindConflict, "consent_version_exists",
+		"policy version already published").
+		Describe("Published versions are immutable; publish the change under a new version.")
+	ErrVersionOutdated = apperrors.New(apperrors.KindConflict, "consent_version_outdated",
+		"policy version is not the latest").
+		Describe("Only the latest version of a policy can be accepted; fetch it and accept its version.")
+	ErrConsentRequired = apperrors.New(apperrors.KindForbidden, "consent_required",
+		"acceptance of the latest policy versions required").
+		Describe("The route requires the caller to accept the latest version of the listed policies.")
+)
+
+// Policy is a published version of a policy document.
+type Policy struct {
+	Name        string // e.g. "terms" or "privacy"
+	Version     string // e.g. "2024-01-15" or "v3"
+	Title       string
+	URL         string // Where the document is published, if not in Body
+	Body        string
+	PublishedAt time.Time
+	PublishedBy string
+}
+
+// Acceptance records a principal accepting a policy version.
+type Acceptance struct {
+	Principal  string
+	Policy     string
+	Version    string
+	AcceptedAt time.Time
+	ClientIP   string `pii:"ip"`
+	UserAgent  string
+}
+
+// Evidence is the client context recorded with an acceptance.
+type Evidence struct {
+	ClientIP  string
+	UserAgent string
+}
+
+// Status is a principal's consent to the latest version of a policy.
+type Status struct {
+	Policy Policy
+
+	// AcceptedVersion is the latest version the principal accepted, empty
+	// when none
+	AcceptedVersion string
+	AcceptedAt      time.Time
+}
+
+// Current reports whether the latest version was accepted.
+func (s Status) Current() bool {
+	return s.AcceptedVersion == s.Policy.Version
+}
+
+// Repository persists policies and acceptances.
+type Repository interface {
+	// Publish stores a policy version or returns ErrVersionExists.
+	Publish(ctx context.Context, policy *Policy) error
+
+	// Versions returns the versions of the policy name, latest first, or
+	// ErrPolicyNotFound when none was published.
+	Versions(ctx context.Context, name string) ([]Policy, error)
+
+	// Latest returns the latest version of every policy ordered by name.
+	Latest(ctx context.Context) ([]Policy, error)
+
+	// Accept appends an acceptance to the history of its principal.
+	Accept(ctx context.Context, acceptance *Acceptance) error
+
+	// Acceptances returns the acceptances of principal, oldest first.
+	Acceptances(ctx context.Context, principal string) ([]Acceptance, error)
+}
+
+// Service implements the consent use cases.
+type Service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+// NewService creates a consent service over repo.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Publish publishes a new version of a policy on behalf of the principal
+// of ctx.
+func (s *Service) Publish(ctx context.Context, policy Policy) (Policy, error) {
+	policy.Name = strings.TrimSpace(policy.Name)
+	policy.Version = strings.TrimSpace(policy.Version)
+	if !validName(policy.Name) || policy.Version == "" {
+		return Policy{}, ErrPolicyInvalid
+	}
+	policy.PublishedAt = s.now().UTC()
+	policy.PublishedBy = reqctx.Principal(ctx)
+	if err := s.repo.Publish(ctx, &policy); err != nil {
+		return Policy{}, err
+	}
+	return policy, nil
+}
+
+// Policies returns the latest version of every policy.
+func (s *Service) Policies(ctx context.Context) ([]Policy, error) {
+	return s.repo.Latest(ctx)
+}
+
+// Policy returns the version of the policy name, the latest when version
+// is empty.
+func (s *Service) Policy(ctx context.Context, name, version string) (Policy, error) {
+	versions, err := s.repo.Versions(ctx, name)
+	if err != nil {
+		return Policy{}, err
+	}
+	if version == "" {
+		return versions[0], nil
+	}
+	for _, p := range versions {
+		if p.Version == version {
+			return p, nil
+		}
+	}
+	return Policy{}, ErrPolicyNotFound
+}
+
+// Accept records principal accepting the version of the policy name,
+// which must be the latest.
+func (s *Service) Accept(ctx context.Context, principal, name, version string, evidence Evidence) (Acceptance, error) {
+	latest, err := s.Policy(ctx, name, "")
+	if err != nil {
+		return Acceptance{}, err
+	}
+	if version != latest.Version {
+		if _, err := s.Policy(ctx, name, version); err != nil {
+			return Acceptance{}, err
+		}
+		return Acceptance{}, ErrVersionOutdated
+	}
+	acceptance := Acceptance{
+		Principal:  principal,
+		Policy:     latest.Name,
+		Version:    latest.Version,
+		AcceptedAt: s.now().UTC(),
+		ClientIP:   evidence.ClientIP,
+		UserAgent:  evidence.UserAgent,
+	}
+	if err := s.repo.Accept(ctx, &acceptance); err != nil {
+		return Acceptance{}, err
+	}
+	return acceptance, nil
+}
+
+// Status returns the consent of principal to the latest version of every
+// policy.
+func (s *Service) Status(ctx context.Context, principal string) ([]Status, error) {
+	policies, err := s.repo.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := s.accepted(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, len(policies))
+	for i, p := range policies {
+		out[i] = Status{Policy: p}
+		if a, ok := accepted[p.Name]; ok {
+			out[i].AcceptedVersion, out[i].AcceptedAt = a.Version, a.AcceptedAt
+		}
+	}
+	return out, nil
+}
+
+// History returns the acceptances of principal, oldest first.
+func (s *Service) History(ctx context.Context, principal string) ([]Acceptance, error) {
+	return s.repo.Acceptances(ctx, principal)
+}
+
+// Missing returns the latest versions of the policies names that
+// principal has not accepted. Policies that were never published are not
+// required.
+func (s *Service) Missing(ctx context.Context, principal string, names []string) ([]Policy, error) {
+	accepted, err := s.accepted(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+	var missing []Policy
+	for _, name := range names {
+		latest, err := s.Policy(ctx, name, "")
+		if errors.Is(err, ErrPolicyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if a, ok := accepted[name]; !ok || a.Version != latest.Version {
+			missing = append(missing, latest)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Name < missing[j].Name })
+	return missing, nil
+}
+
+// accepted returns the latest acceptance of principal per policy.
+func (s *Service) accepted(ctx context.Context, principal string) (map[string]Acceptance, error) {
+	history, err := s.repo.Acceptances(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]Acceptance, len(history))
+	for _, a := range history {
+		latest[a.Policy] = a
+	}
+	return latest, nil
+}
+
+// validName reports whether name is a lowercase identifier.
+func validName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package consent_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/consent"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PublishAndAccept(t *testing.T) {
+	ctx := context.Background()
+	service := consent.NewService(memory.NewConsentRepository())
+
+	published, err := service.Publish(reqctx.With(ctx, &reqctx.RequestContext{Principal: "admin"}), consent.Policy{Name: "terms", Version: "v1", Title: "Terms"})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", published.PublishedBy)
+	assert.False(t, published.PublishedAt.IsZero())
+
+	_, err = service.Publish(ctx, consent.Policy{Name: "terms", Version: "v1"})
+	assert.ErrorIs(t, err, consent.ErrVersionExists)
+	_, err = service.Publish(ctx, consent.Policy{Name: "Terms of Service", Version: "v1"})
+	assert.ErrorIs(t, err, consent.ErrPolicyInvalid)
+	_, err = service.Publish(ctx, consent.Policy{Name: "terms"})
+	assert.ErrorIs(t, err, consent.ErrPolicyInvalid)
+
+	acceptance, err := service.Accept(ctx, "jane", "terms", "v1", consent.Evidence{ClientIP: "192.0.2.1", UserAgent: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", acceptance.Version)
+	assert.Equal(t, "192.0.2.1", acceptance.ClientIP)
+
+	// A new version supersedes the acceptance of the previous one
+	_, err = service.Publish(ctx, consent.Policy{Name: "terms", Version: "v2"})
+	require.NoError(t, err)
+	_, err = service.Accept(ctx, "jane", "terms", "v1", consent.Evidence{})
+	assert.ErrorIs(t, err, consent.ErrVersionOutdated)
+	_, err = service.Accept(ctx, "jane", "terms", "v9", consent.Evidence{})
+	assert.ErrorIs(t, err, consent.ErrPolicyNotFound)
+	_, err = service.Accept(ctx, "jane", "privacy", "v1", consent.Evidence{})
+	assert.ErrorIs(t, err, consent.ErrPolicyNotFound)
+
+	status, err := service.Status(ctx, "jane")
+	require.NoError(t, err)
+	require.Len(t, status, 1)
+	assert.Equal(t, "v2", status[0].Policy.Version)
+	assert.Equal(t, "v1", status[0].AcceptedVersion)
+	assert.False(t, status[0].Current())
+
+	old, err := service.Policy(ctx, "terms", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "Terms", old.Title)
+}
+
+func TestService_Missing(t *testing.T) {
+	ctx := context.Background()
+	service := consent.NewService(memory.NewConsentRepository())
+	for _, p := range []consent.Policy{{Name: "terms", Version: "v1"}, {Name: "privacy", Version: "v1"}} {
+		_, err := service.Publish(ctx, p)
+		require.NoError(t, err)
+	}
+
+	missing, err := service.Missing(ctx, "jane", []string{"terms", "privacy", "unpublished"})
+	require.NoError(t, err)
+	require.Len(t, missing, 2, "unpublished policies are not required")
+	assert.Equal(t, "privacy", missing[0].Name)
+	assert.Equal(t, "terms", missing[1].Name)
+
+	_, err = service.Accept(ctx, "jane", "terms", "v1", consent.Evidence{})
+	require.NoError(t, err)
+	missing, err = service.Missing(ctx, "jane", []string{"terms"})
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	history, err := service.History(ctx, "jane")
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
@@ -7,11 +7,20 @@ const (
 	AppDescription = "Go HTTP server with health check, logging, and DI"
 )
 
+// Environment profiles
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
 // API configuration
 const (
-	APIPrefix = "/api/v1"
-	DocsURL   = "/docs"
-	RedocURL  = "/redoc"
+	APIPrefix   = "/api/v1"
+	AdminPrefix = "/admin"
+	DocsURL     = "/docs"
+	RedocURL    = "/redoc"
 )
 
 // Health check status values
@@ -19,8 +28,12 @@ const (
 	HealthStatusHealthy   = "healthy"
 	HealthStatusDegraded  = "degraded"
 	HealthStatusUnhealthy = "unhealthy"
+	HealthStatusStarting  = "starting"
 )
 
+// HeaderAppVersion carries the application version when VERSION_HEADER_ENABLED is set.
+const HeaderAppVersion = "X-App-Version"
+
 // CORS configuration (development)
 var (
 	CORSAllowOrigins = []string{
@@ -30,6 +43,9 @@ var (
 	}
 	CORSAllowMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"}
 	CORSAllowHeaders = []string{"*"}
+	// Response headers readable by browser scripts (correlation, quota state and throttling)
+	CORSExposeHeaders = []string{"X-Request-ID", "X-Quota-Limit", "X-Quota-Remaining", "X-Quota-Reset", "X-Quota-Period",
+		"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"}
 )
 
 // Logging
@@ -37,3 +53,23 @@ const (
 	LogFormatJSON = "json"
 	LogFormatText = "text"
 )
+
+// OpenAPI contract validation modes
+const (
+	OpenAPIValidationOff    = "off"
+	OpenAPIValidationLog    = "log"
+	OpenAPIValidationReject = "reject"
+)
+
+// Server-Timing modes
+const (
+	ServerTimingOff    = "off"
+	ServerTimingDebug  = "debug"  // Header in debug mode or for admin callers
+	ServerTimingAlways = "always" // Header on every response
+)
+
+// Request priority classes
+const (
+	PriorityClassAdmin  = "admin"  // Admin, health and metrics endpoints
+	PriorityClassPublic = "public" // Everything else
+)
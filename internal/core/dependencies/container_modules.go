@@ -0,0 +1,405 @@
+// Code generated by depgen. DO NOT EDIT.
+
+package dependencies
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/consent"
+	"github.com/luminosita/change-me/internal/core/metering"
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/luminosita/change-me/internal/core/privacy"
+	"github.com/luminosita/change-me/internal/core/quota"
+	"github.com/luminosita/change-me/internal/core/reports"
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/luminosita/change-me/internal/core/toggles"
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/luminosita/change-me/internal/core/uploads"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/infrastructure/objectstore/local"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/bolt"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// ConsentDeps are the dependencies of the consent module.
+type ConsentDeps struct {
+	Logger  *logger.Logger
+	Consent *consent.Service
+}
+
+// ConsentDeps returns the dependencies of the consent module, or an error
+// naming the required ones that are nil.
+func (c *Container) ConsentDeps() (ConsentDeps, error) {
+	deps := ConsentDeps{
+		Logger:  c.Logger,
+		Consent: c.Consent,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Consent) {
+		missing = append(missing, "Consent")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module consent: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// FilesDeps are the dependencies of the files module.
+type FilesDeps struct {
+	Logger *logger.Logger
+	Files  *local.Store
+}
+
+// FilesDeps returns the dependencies of the files module, or an error
+// naming the required ones that are nil.
+func (c *Container) FilesDeps() (FilesDeps, error) {
+	deps := FilesDeps{
+		Logger: c.Logger,
+		Files:  c.Files,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Files) {
+		missing = append(missing, "Files")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module files: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// NotificationsDeps are the dependencies of the notifications module.
+type NotificationsDeps struct {
+	Logger        *logger.Logger
+	Notifications *notifications.Service
+}
+
+// NotificationsDeps returns the dependencies of the notifications module, or an error
+// naming the required ones that are nil.
+func (c *Container) NotificationsDeps() (NotificationsDeps, error) {
+	deps := NotificationsDeps{
+		Logger:        c.Logger,
+		Notifications: c.Notifications,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Notifications) {
+		missing = append(missing, "Notifications")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module notifications: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// PrivacyDeps are the dependencies of the privacy module.
+type PrivacyDeps struct {
+	Logger  *logger.Logger
+	Privacy *privacy.Service
+}
+
+// PrivacyDeps returns the dependencies of the privacy module, or an error
+// naming the required ones that are nil.
+func (c *Container) PrivacyDeps() (PrivacyDeps, error) {
+	deps := PrivacyDeps{
+		Logger:  c.Logger,
+		Privacy: c.Privacy,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Privacy) {
+		missing = append(missing, "Privacy")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module privacy: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// QuotasDeps are the dependencies of the quotas module.
+type QuotasDeps struct {
+	Logger       *logger.Logger
+	QuotaService *quota.Service
+}
+
+// QuotasDeps returns the dependencies of the quotas module, or an error
+// naming the required ones that are nil.
+func (c *Container) QuotasDeps() (QuotasDeps, error) {
+	deps := QuotasDeps{
+		Logger:       c.Logger,
+		QuotaService: c.QuotaService,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.QuotaService) {
+		missing = append(missing, "QuotaService")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module quotas: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// ReportsDeps are the dependencies of the reports module.
+type ReportsDeps struct {
+	Logger  *logger.Logger
+	Reports *reports.Service
+}
+
+// ReportsDeps returns the dependencies of the reports module, or an error
+// naming the required ones that are nil.
+func (c *Container) ReportsDeps() (ReportsDeps, error) {
+	deps := ReportsDeps{
+		Logger:  c.Logger,
+		Reports: c.Reports,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Reports) {
+		missing = append(missing, "Reports")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module reports: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// SearchDeps are the dependencies of the search module.
+type SearchDeps struct {
+	Logger     *logger.Logger
+	UserSearch *users.Search
+}
+
+// SearchDeps returns the dependencies of the search module, or an error
+// naming the required ones that are nil.
+func (c *Container) SearchDeps() (SearchDeps, error) {
+	deps := SearchDeps{
+		Logger:     c.Logger,
+		UserSearch: c.UserSearch,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.UserSearch) {
+		missing = append(missing, "UserSearch")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module search: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// SessionsDeps are the dependencies of the sessions module.
+type SessionsDeps struct {
+	Logger   *logger.Logger
+	Sessions *sessions.Service
+}
+
+// SessionsDeps returns the dependencies of the sessions module, or an error
+// naming the required ones that are nil.
+func (c *Container) SessionsDeps() (SessionsDeps, error) {
+	deps := SessionsDeps{
+		Logger:   c.Logger,
+		Sessions: c.Sessions,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Sessions) {
+		missing = append(missing, "Sessions")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module sessions: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// StoreDeps are the dependencies of the store module.
+type StoreDeps struct {
+	Logger *logger.Logger
+	Store  *bolt.DB
+}
+
+// StoreDeps returns the dependencies of the store module, or an error
+// naming the required ones that are nil.
+func (c *Container) StoreDeps() (StoreDeps, error) {
+	deps := StoreDeps{
+		Logger: c.Logger,
+		Store:  c.Store,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Store) {
+		missing = append(missing, "Store")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module store: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// TogglesDeps are the dependencies of the toggles module.
+type TogglesDeps struct {
+	Logger  *logger.Logger
+	Toggles *toggles.Service
+}
+
+// TogglesDeps returns the dependencies of the toggles module, or an error
+// naming the required ones that are nil.
+func (c *Container) TogglesDeps() (TogglesDeps, error) {
+	deps := TogglesDeps{
+		Logger:  c.Logger,
+		Toggles: c.Toggles,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Toggles) {
+		missing = append(missing, "Toggles")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module toggles: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// TwofactorDeps are the dependencies of the twofactor module.
+type TwofactorDeps struct {
+	Logger    *logger.Logger
+	TwoFactor *twofactor.Service
+}
+
+// TwofactorDeps returns the dependencies of the twofactor module, or an error
+// naming the required ones that are nil.
+func (c *Container) TwofactorDeps() (TwofactorDeps, error) {
+	deps := TwofactorDeps{
+		Logger:    c.Logger,
+		TwoFactor: c.TwoFactor,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.TwoFactor) {
+		missing = append(missing, "TwoFactor")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module twofactor: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// UploadsDeps are the dependencies of the uploads module.
+type UploadsDeps struct {
+	Logger  *logger.Logger
+	Uploads *uploads.Service
+}
+
+// UploadsDeps returns the dependencies of the uploads module, or an error
+// naming the required ones that are nil.
+func (c *Container) UploadsDeps() (UploadsDeps, error) {
+	deps := UploadsDeps{
+		Logger:  c.Logger,
+		Uploads: c.Uploads,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.Uploads) {
+		missing = append(missing, "Uploads")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module uploads: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// UsageDeps are the dependencies of the usage module.
+type UsageDeps struct {
+	Config          *config.Config
+	UsageAggregator *metering.Aggregator
+}
+
+// UsageDeps returns the dependencies of the usage module, or an error
+// naming the required ones that are nil.
+func (c *Container) UsageDeps() (UsageDeps, error) {
+	deps := UsageDeps{
+		Config:          c.Config,
+		UsageAggregator: c.UsageAggregator,
+	}
+	var missing []string
+	if isNilDependency(deps.Config) {
+		missing = append(missing, "Config")
+	}
+	if isNilDependency(deps.UsageAggregator) {
+		missing = append(missing, "UsageAggregator")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module usage: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// UsersDeps are the dependencies of the users module.
+type UsersDeps struct {
+	Logger      *logger.Logger
+	UserService users.UserService
+	Cursors     *pagination.Cursors // Optional, may be nil
+}
+
+// UsersDeps returns the dependencies of the users module, or an error
+// naming the required ones that are nil.
+func (c *Container) UsersDeps() (UsersDeps, error) {
+	deps := UsersDeps{
+		Logger:      c.Logger,
+		UserService: c.UserService,
+		Cursors:     c.Cursors,
+	}
+	var missing []string
+	if isNilDependency(deps.Logger) {
+		missing = append(missing, "Logger")
+	}
+	if isNilDependency(deps.UserService) {
+		missing = append(missing, "UserService")
+	}
+	if len(missing) > 0 {
+		return deps, fmt.Errorf("module users: missing %s", strings.Join(missing, ", "))
+	}
+	return deps, nil
+}
+
+// isNilDependency reports whether v is nil or a nil pointer, interface,
+// map, slice, channel or function.
+func isNilDependency(v any) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
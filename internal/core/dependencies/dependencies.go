@@ -1,11 +1,72 @@
 package dependencies
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/cache"
+	"github.com/luminosita/change-me/internal/core/consent"
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/internal/core/malware"
+	"github.com/luminosita/change-me/internal/core/metering"
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/luminosita/change-me/internal/core/objectstore"
+	"github.com/luminosita/change-me/internal/core/privacy"
+	"github.com/luminosita/change-me/internal/core/quota"
+	"github.com/luminosita/change-me/internal/core/ratelimit"
+	"github.com/luminosita/change-me/internal/core/reports"
+	"github.com/luminosita/change-me/internal/core/routeflags"
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/luminosita/change-me/internal/core/slo"
+	"github.com/luminosita/change-me/internal/core/toggles"
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/luminosita/change-me/internal/core/uploads"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/core/warmup"
+	"github.com/luminosita/change-me/internal/core/watchdog"
+	"github.com/luminosita/change-me/internal/infrastructure/malware/clamd"
+	"github.com/luminosita/change-me/internal/infrastructure/malware/httpscan"
+	"github.com/luminosita/change-me/internal/infrastructure/messaging/kafka"
+	"github.com/luminosita/change-me/internal/infrastructure/notifications/fcm"
+	"github.com/luminosita/change-me/internal/infrastructure/notifications/slack"
+	"github.com/luminosita/change-me/internal/infrastructure/notifications/smtp"
+	"github.com/luminosita/change-me/internal/infrastructure/notifications/twilio"
+	"github.com/luminosita/change-me/internal/infrastructure/objectstore/local"
+	"github.com/luminosita/change-me/internal/infrastructure/objectstore/s3"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/bolt"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	redisstore "github.com/luminosita/change-me/internal/infrastructure/persistence/redis"
+	"github.com/luminosita/change-me/internal/infrastructure/search/elastic"
+	"github.com/luminosita/change-me/internal/infrastructure/search/embedded"
+	"github.com/luminosita/change-me/internal/infrastructure/workflow/temporal"
+	"github.com/luminosita/change-me/pkg/accesstoken"
+	"github.com/luminosita/change-me/pkg/conntrack"
+	"github.com/luminosita/change-me/pkg/crypto"
+	"github.com/luminosita/change-me/pkg/decorate"
+	"github.com/luminosita/change-me/pkg/discovery"
+	"github.com/luminosita/change-me/pkg/grpcclient"
+	"github.com/luminosita/change-me/pkg/httpclient"
+	"github.com/luminosita/change-me/pkg/httpmetrics"
+	"github.com/luminosita/change-me/pkg/ids"
 	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/luminosita/change-me/pkg/priority"
+	"github.com/luminosita/change-me/pkg/recording"
+	"github.com/luminosita/change-me/pkg/synthetic"
+	"github.com/prometheus/client_golang/prometheus"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	temporalclient "go.temporal.io/sdk/client"
 )
 
 // Container holds all application dependencies.
@@ -14,6 +75,138 @@ type Container struct {
 	Config     *config.Config
 	Logger     *logger.Logger
 	HTTPClient *http.Client
+
+	// HTTPClients holds the named outbound clients; HTTPClient is its default
+	HTTPClients *httpclient.Registry
+
+	// HTTPClientMetrics records per-host outbound metrics; HTTPClient is
+	// instrumented, other outbound components report retries and breakers
+	HTTPClientMetrics *httpclient.Metrics
+
+	// GRPCClients holds the named gRPC connections closed on shutdown
+	GRPCClients *grpcclient.Registry
+
+	// Metrics collects application metrics from instrumented components
+	Metrics *prometheus.Registry
+
+	// Connections records the states of inbound server connections
+	Connections *conntrack.Metrics
+
+	// HTTPMetrics records inbound requests by route template
+	HTTPMetrics *httpmetrics.Metrics
+
+	// Redis is the shared client when REDIS_URL is configured, nil otherwise
+	Redis *goredis.Client
+
+	// Store is the embedded database when EMBEDDED_STORE_PATH is set, nil
+	// otherwise
+	Store *bolt.DB
+
+	// Events is the in-process domain event bus
+	Events *events.Bus
+
+	// IDs generates the identifiers of new entities
+	IDs ids.Generator
+
+	// Users module
+	UserRepository users.Repository
+	UserService    users.UserService
+
+	// Cursors signs the pagination cursors of lists
+	Cursors *pagination.Cursors
+
+	// Full-text search; both are nil unless SEARCH_PROVIDER is set
+	Search     search.Index
+	UserSearch *users.Search
+
+	// Notifications delivers messages over the configured channels; nil
+	// when no channel is configured
+	Notifications *notifications.Service
+
+	// ObjectStore holds generated files; nil unless OBJECT_STORAGE_PROVIDER
+	// is set. Files is the same store when it is a local directory, whose
+	// links the application serves
+	ObjectStore objectstore.Store
+	Files       *local.Store
+
+	// Recordings stores the requests of the recorder, built on first use
+	// and drained on Close; nil unless RECORDER_ENABLED
+	Recordings *Lazy[recording.Store]
+
+	// Reports renders reports into the object store; nil without it
+	Reports *reports.Service
+
+	// Uploads stores client files in the object store, scanned for malware
+	// when MALWARE_SCANNER is set; nil without object storage
+	Uploads *uploads.Service
+
+	// Privacy runs the data export and deletion requests of users; exports
+	// need the object store
+	Privacy *privacy.Service
+
+	// Usage quotas
+	QuotaService *quota.Service
+
+	// RateLimiter enforces RATE_LIMIT_CONFIG rules; nil when none are declared
+	RateLimiter *ratelimit.Limiter
+
+	// Encryption seals sensitive fields; nil without ENCRYPTION_KEYS
+	Encryption *crypto.Envelope
+
+	// TwoFactor manages TOTP enrollments; nil without Encryption as the
+	// secrets are stored encrypted
+	TwoFactor *twofactor.Service
+
+	// AccessTokens mints the access JWTs of sessions and verifies them on
+	// the API routes; nil without ACCESS_TOKEN_SIGNING_KEY
+	AccessTokens *accesstoken.Signer
+
+	// Sessions issues and rotates refresh tokens
+	Sessions *sessions.Service
+
+	// Consent tracks policy versions and their acceptance, persisted in the
+	// embedded store when available
+	Consent *consent.Service
+
+	// SLO tracks the objectives of SLO_CONFIG; nil when none are declared
+	SLO *slo.Tracker
+
+	// Watchdog flags requests in flight longer than SLOW_REQUEST_THRESHOLD;
+	// nil when disabled
+	Watchdog *watchdog.Watchdog
+
+	// Synthetic probes the checks of SYNTHETIC_CONFIG once the server runs;
+	// nil when none are declared
+	Synthetic *synthetic.Runner
+
+	// RouteFlags disables routes declared in ROUTE_FLAGS_CONFIG; the file
+	// is watched for changes
+	RouteFlags *routeflags.Flags
+
+	// Toggles overrides feature and route flags at runtime, persisted in
+	// the embedded store when available
+	Toggles *toggles.Service
+
+	// RequestQueue admits requests per priority class; nil unless
+	// PRIORITY_QUEUE_ENABLED is set
+	RequestQueue *priority.Queue
+
+	// Warmup runs the startup hooks before the instance reports ready
+	Warmup *warmup.Registry
+
+	// Temporal is the workflow engine client when TEMPORAL_ADDRESS is set,
+	// nil otherwise; modules register their workflows and activities in
+	// Workflows, polled by the workers
+	Temporal  temporalclient.Client
+	Workflows *temporal.Registry
+
+	// Usage metering; Metering is nil unless METERING_ENABLED is set
+	Metering        *metering.Pipeline
+	UsageAggregator *metering.Aggregator
+
+	// lazy holds the dependencies built on first use, closed with the
+	// container when they were
+	lazy []io.Closer
 }
 
 // NewContainer creates a new dependency injection container.
@@ -26,33 +219,852 @@ type Container struct {
 // Returns:
 //   - *Container: Initialized dependency container
 func NewContainer(cfg *config.Config, log *logger.Logger) *Container {
-	// Create shared HTTP client with connection pooling
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        10,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
+	// Optional Redis client (connections are established lazily)
+	var redisClient *goredis.Client
+	if cfg.RedisURL != "" {
+		opts, err := goredis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			log.Errorw("redis_disabled", "error", err)
+		} else {
+			redisClient = goredis.NewClient(opts)
+		}
+	}
+
+	// Registry for metrics of instrumented components
+	metrics := prometheus.NewRegistry()
+	metrics.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "log_entries_suppressed_total",
+		Help: "Log entries dropped by duplicate sampling.",
+	}, func() float64 { return float64(log.Suppressed()) }))
+	metrics.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "log_entries_dropped_total",
+		Help: "Log entries shipping outputs failed to deliver.",
+	}, func() float64 { return float64(log.Dropped()) }))
+
+	// Optional embedded store replacing the in-memory repositories
+	store := newEmbeddedStore(cfg, log, metrics)
+
+	// Create the outbound HTTP clients with connection pooling; outgoing
+	// requests carry the trace context of the request context, are measured,
+	// checked against the egress policy and optionally served from the
+	// response cache
+	resolver := newDiscovery(cfg)
+	clientMetrics := httpclient.NewMetrics(metrics)
+	httpClients := httpclient.NewRegistry(httpclient.Config{
+		Timeout:               cfg.HTTPClientTimeout,
+		MaxIdleConns:          cfg.HTTPClientMaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.HTTPClientMaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.HTTPClientMaxConnsPerHost,
+		IdleConnTimeout:       cfg.HTTPClientIdleConnTimeout,
+		DialTimeout:           cfg.HTTPClientDialTimeout,
+		TLSHandshakeTimeout:   cfg.HTTPClientTLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.HTTPClientResponseHeaderTimeout,
+		Budget:                cfg.HTTPClientDeadlineBudget,
+		DNSCache: httpclient.DNSCacheConfig{
+			Enabled:     cfg.HTTPClientDNSCache,
+			MaxTTL:      cfg.HTTPClientDNSMaxTTL,
+			NegativeTTL: cfg.HTTPClientDNSNegativeTTL,
+		},
+		Cache: httpclient.CacheConfig{
+			Enabled:       cfg.HTTPClientCache,
+			MaxBodyBytes:  cfg.HTTPClientCacheMaxBodyBytes,
+			RevalidateTTL: cfg.HTTPClientCacheRevalidateTTL,
+		},
+		Egress: &httpclient.EgressPolicy{
+			AllowHosts:     cfg.EgressAllowHosts,
+			AllowPorts:     cfg.EgressAllowPorts,
+			AllowSchemes:   cfg.EgressAllowSchemes,
+			BlockLinkLocal: cfg.EgressBlockLinkLocal,
+			BlockPrivate:   cfg.EgressBlockPrivate,
 		},
+	}, cfg.HTTPClients, httpclient.Options{
+		Metrics:    clientMetrics,
+		Logger:     log,
+		CacheStore: newCacheStore(cfg, redisClient, store),
+		Discovery:  resolver,
+	})
+
+	// Create the named gRPC connections; invalid declarations disable them
+	// rather than failing startup, like an unparsable REDIS_URL
+	grpcClients, err := grpcclient.NewRegistry(cfg.GRPCClients, grpcclient.Options{
+		Metrics:   grpcclient.NewMetrics(metrics),
+		Logger:    log,
+		Discovery: resolver,
+	})
+	if err != nil {
+		log.Errorw("grpc_clients_disabled", "error", err)
+		grpcClients, _ = grpcclient.NewRegistry(nil, grpcclient.Options{})
+	}
+
+	// Users module backed by the embedded store or the in-memory repository;
+	// queries get a share of the request deadline
+	bus := events.NewBus()
+	generator := ids.Default()
+	var userRepository users.Repository = memory.NewUserRepository(generator)
+	if store != nil {
+		userRepository = bolt.NewUserRepository(store, generator)
+	}
+	userRepository = users.NewDeadlineRepository(userRepository, cfg.StoreDeadlineBudget)
+
+	container := &Container{
+		Config:            cfg,
+		Logger:            log,
+		HTTPClient:        httpClients.Default(),
+		HTTPClients:       httpClients,
+		HTTPClientMetrics: clientMetrics,
+		GRPCClients:       grpcClients,
+		Metrics:           metrics,
+		Connections:       conntrack.NewMetrics(metrics),
+		HTTPMetrics:       httpmetrics.NewMetrics(metrics),
+		Redis:             redisClient,
+		Store:             store,
+		Events:            bus,
+		IDs:               generator,
+		UserRepository:    userRepository,
+		UserService:       newUserService(cfg, log, metrics, bus, redisClient, store, userRepository),
+		Cursors:           newCursors(cfg, log),
+		QuotaService:      newQuotaService(cfg, log, redisClient),
+		RateLimiter:       newRateLimiter(cfg, redisClient),
+		Encryption:        newEncryption(cfg, log),
+		AccessTokens:      newAccessTokens(cfg, log),
+		SLO:               newSLOTracker(cfg, log, metrics),
+		Synthetic:         newSyntheticRunner(cfg, log, metrics, httpClients),
+		Watchdog:          newWatchdog(cfg, log, metrics),
+		RouteFlags:        newRouteFlags(cfg, log),
+		RequestQueue:      newRequestQueue(cfg, metrics),
+		UsageAggregator:   metering.NewAggregator(),
+	}
+	container.Sessions = newSessionService(cfg, redisClient, container.AccessTokens)
+	container.TwoFactor = newTwoFactorService(cfg, container.Encryption, redisClient, store)
+	container.Search = newSearchIndex(cfg, log, httpClients)
+	if container.Search != nil {
+		manager := search.NewManager(container.Search, search.ManagerOptions{Retain: cfg.SearchRetainVersions})
+		container.UserSearch = users.NewSearch(container.Search, manager, userRepository, bus, log)
+	}
+	container.Notifications = newNotifications(cfg, log, metrics, redisClient, httpClients)
+	if container.Notifications != nil && cfg.NotificationsWelcomeEmail {
+		users.SendWelcome(bus, container.Notifications, log)
+	}
+	// Report operations are metered as jobs, so the pipeline comes first
+	if cfg.MeteringEnabled {
+		sinks := metering.MultiSink{container.UsageAggregator}
+		if cfg.MeteringKafkaTopic != "" && len(cfg.KafkaBrokers) > 0 {
+			sinks = append(sinks, lazyUsageSink{lazy(container, func() (*kafka.UsageSink, error) {
+				return kafka.NewUsageSink(cfg.KafkaBrokers, cfg.MeteringKafkaTopic), nil
+			})})
+		}
+		container.Metering = metering.NewPipeline(sinks, metering.Options{
+			BatchSize:     cfg.MeteringBatchSize,
+			FlushInterval: cfg.MeteringFlushInterval,
+		}, log)
 	}
 
-	return &Container{
-		Config:     cfg,
+	container.ObjectStore, container.Files = newObjectStore(cfg, log, httpClients)
+	if container.ObjectStore != nil {
+		container.Reports = newReports(cfg, log, metrics, container.ObjectStore, container.IDs, container.Metering, userRepository)
+		container.Uploads = newUploads(cfg, log, metrics, container.ObjectStore, httpClients)
+	}
+	if cfg.RecorderEnabled {
+		objects := container.ObjectStore
+		container.Recordings = lazy(container, func() (recording.Store, error) {
+			return newRecordings(cfg, log, objects)
+		})
+	}
+	container.Consent = newConsent(store)
+	container.Toggles = newToggles(cfg, log, store, container.RouteFlags)
+	container.Privacy = newPrivacy(cfg, log, metrics, bus, store, container.ObjectStore, userRepository)
+	container.Temporal, container.Workflows = newTemporal(cfg, log, metrics)
+	container.Warmup = newWarmup(container)
+
+	return container
+}
+
+// lazyUsageSink creates the Kafka usage producer on the first flush, so
+// the process boots without Kafka until usage is published.
+type lazyUsageSink struct {
+	sink *Lazy[*kafka.UsageSink]
+}
+
+// Write implements metering.Sink.
+func (s lazyUsageSink) Write(ctx context.Context, events []metering.Event) error {
+	sink, err := s.sink.Get()
+	if err != nil {
+		return err
+	}
+	return sink.Write(ctx, events)
+}
+
+// newWarmup registers the startup hooks of the container's components:
+// the first Redis connection and the users read path, which fills the
+// result cache when enabled.
+func newWarmup(c *Container) *warmup.Registry {
+	registry := warmup.NewRegistry()
+	if c.Redis != nil {
+		_ = registry.Register(warmup.New("redis", func(ctx context.Context) error {
+			return c.Redis.Ping(ctx).Err()
+		}))
+	}
+	_ = registry.Register(warmup.New("users", func(ctx context.Context) error {
+		_, err := c.UserService.List(ctx, pagination.Params{})
+		return err
+	}))
+	if c.UserSearch != nil {
+		_ = registry.Register(warmup.New("search", c.UserSearch.Ensure))
+	}
+	return registry
+}
+
+// newCursors returns the pagination cursor codec. Without a signing key,
+// which production configurations must set, cursors are signed with a
+// per-process key and only valid on the instance that issued them.
+func newCursors(cfg *config.Config, log *logger.Logger) *pagination.Cursors {
+	key := []byte(cfg.PaginationCursorKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+		log.Warnw("pagination_cursor_key_generated", "reason", "PAGINATION_CURSOR_KEY is not set")
+	}
+	cursors, _ := pagination.NewCursors(key, pagination.CursorOptions{TTL: cfg.PaginationCursorTTL})
+	return cursors
+}
+
+// newUserService builds the users service with the configured pagination
+// guard, wrapped with the optional result cache and the generated
+// cross-cutting decorators (innermost first: cache, logging, metrics,
+// tracing).
+func newUserService(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry, bus *events.Bus,
+	redisClient *goredis.Client, store *bolt.DB, repo users.Repository) users.UserService {
+	guard := pagination.NewGuard(pagination.GuardOptions{
+		MaxLimit: cfg.PaginationMaxPageSize,
+		Strict:   cfg.Debug,
+		Metrics:  pagination.NewGuardMetrics(metrics),
+	})
+	var service users.UserService = users.NewService(repo, users.WithEvents(bus), users.WithPaginationGuard(guard))
+	if cfg.CacheEnabled {
+		ttls, err := cache.ParseTTLs(cfg.CacheTTLs)
+		if err != nil {
+			log.Errorw("cache_ttls_ignored", "error", err)
+		}
+		service = users.NewUserServiceCache(service, newCacheStore(cfg, redisClient, store),
+			cache.TTLs{Default: cfg.CacheDefaultTTL, Overrides: ttls}, bus)
+	}
+	service = users.NewUserServiceLogging(service, log)
+	service = users.NewUserServiceMetrics(service, decorate.NewPrometheusRecorder(metrics))
+	return users.NewUserServiceTracing(service, otel.Tracer(cfg.AppName))
+}
+
+// newDiscovery returns the service discovery resolver of the configured
+// provider, or nil when discovery is disabled.
+func newDiscovery(cfg *config.Config) *discovery.Resolver {
+	var source discovery.Source
+	switch cfg.DiscoveryProvider {
+	case "consul":
+		source = discovery.NewConsulSource(discovery.ConsulConfig{
+			Address:    cfg.ConsulAddr,
+			Token:      cfg.ConsulToken,
+			Datacenter: cfg.ConsulDatacenter,
+		})
+	case "dns":
+		source = discovery.NewSRVSource(cfg.DiscoveryDNSDomain)
+	default:
+		return nil
+	}
+	return discovery.New(source, discovery.Config{
+		RefreshInterval: cfg.DiscoveryRefreshInterval,
+		EjectionPeriod:  cfg.DiscoveryEjectionPeriod,
+	})
+}
+
+// newSearchIndex returns the search index of SEARCH_PROVIDER, or nil when
+// search is disabled or the cluster URL is invalid.
+func newSearchIndex(cfg *config.Config, log *logger.Logger, clients *httpclient.Registry) search.Index {
+	switch cfg.SearchProvider {
+	case "embedded":
+		return embedded.New()
+	case "elasticsearch", "opensearch":
+		client, err := elastic.New(elastic.Config{
+			URL:      cfg.SearchURL,
+			Username: cfg.SearchUsername,
+			Password: cfg.SearchPassword,
+			APIKey:   cfg.SearchAPIKey,
+		}, clients.Client("search"))
+		if err != nil {
+			log.Errorw("search_disabled", "error", err)
+			return nil
+		}
+		return client
+	}
+	return nil
+}
+
+// newNotifications returns the notification service of the configured
+// channels, or nil when none is. Channels failing to initialize are
+// disabled. Jobs are queued in Redis when available so API instances
+// enqueue for the workers, in process otherwise.
+func newNotifications(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry,
+	redisClient *goredis.Client, clients *httpclient.Registry) *notifications.Service {
+	notifiers := make(map[string]notifications.Notifier)
+	add := func(channel string, notifier notifications.Notifier, err error) {
+		if err != nil {
+			log.Errorw("notification_channel_disabled", "channel", channel, "error", err)
+			return
+		}
+		notifiers[channel] = notifier
+	}
+	if cfg.SMTPHost != "" {
+		notifier, err := smtp.New(smtp.Config{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+		add(notifications.ChannelEmail, notifier, err)
+	}
+	if cfg.TwilioAccountSID != "" {
+		notifier, err := twilio.New(twilio.Config{
+			AccountSID: cfg.TwilioAccountSID,
+			AuthToken:  cfg.TwilioAuthToken,
+			From:       cfg.TwilioFrom,
+		}, clients.Client("twilio"))
+		add(notifications.ChannelSMS, notifier, err)
+	}
+	if cfg.FCMCredentialsFile != "" {
+		notifier, err := fcm.New(fcm.Config{
+			CredentialsFile: cfg.FCMCredentialsFile,
+			ProjectID:       cfg.FCMProjectID,
+		}, clients.Client("fcm"))
+		add(notifications.ChannelPush, notifier, err)
+	}
+	if len(cfg.SlackWebhooks) > 0 {
+		notifier, err := slack.New(parsePairs(cfg.SlackWebhooks), clients.Client("slack"))
+		add(notifications.ChannelSlack, notifier, err)
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+
+	templates, err := notifications.NewTemplates(cfg.NotificationsTemplatesDir)
+	if err != nil {
+		log.Errorw("notifications_disabled", "error", err)
+		return nil
+	}
+	var queue notifications.Queue = memory.NewNotificationQueue(cfg.NotificationsQueueSize)
+	if redisClient != nil {
+		queue = redisstore.NewNotificationQueue(redisClient, cfg.NotificationsQueueSize)
+	}
+	return notifications.NewService(notifiers, templates, queue, notifications.Options{
+		Retry:       cfg.NotificationsRetry,
+		Concurrency: cfg.NotificationsConcurrency,
+		Metrics:     metrics,
+	}, log)
+}
+
+// newObjectStore returns the object store of OBJECT_STORAGE_PROVIDER and,
+// for a local directory, its local store; both are nil when object
+// storage is disabled or cannot be initialized. Without a signing key,
+// local links are signed with a per-process key and only valid on the
+// instance that issued them.
+func newObjectStore(cfg *config.Config, log *logger.Logger, clients *httpclient.Registry) (objectstore.Store, *local.Store) {
+	switch cfg.ObjectStorageProvider {
+	case "local":
+		key := []byte(cfg.ObjectStorageSigningKey)
+		if len(key) == 0 {
+			key = make([]byte, 32)
+			_, _ = rand.Read(key)
+			log.Warnw("object_storage_signing_key_generated", "reason", "OBJECT_STORAGE_SIGNING_KEY is not set")
+		}
+		store, err := local.New(cfg.ObjectStorageDir, cfg.ObjectStoragePublicURL, key)
+		if err != nil {
+			log.Errorw("object_storage_disabled", "error", err)
+			return nil, nil
+		}
+		return store, store
+	case "s3":
+		store, err := s3.New(s3.Config{
+			Endpoint:        cfg.ObjectStorageEndpoint,
+			Region:          cfg.ObjectStorageRegion,
+			Bucket:          cfg.ObjectStorageBucket,
+			AccessKeyID:     cfg.ObjectStorageAccessKeyID,
+			SecretAccessKey: cfg.ObjectStorageSecretAccessKey,
+			PathStyle:       cfg.ObjectStoragePathStyle,
+		}, clients.Client("objectstorage"))
+		if err != nil {
+			log.Errorw("object_storage_disabled", "error", err)
+			return nil, nil
+		}
+		return store, nil
+	}
+	return nil, nil
+}
+
+// RecordingsPrefix is the object key prefix of RECORDER_STORAGE=object.
+const RecordingsPrefix = "recordings"
+
+// newRecordings returns the recording store of RECORDER_STORAGE. Objects
+// are uploaded in the background and the cap applies to those left by
+// earlier runs too, so the store needs an object store that can list.
+func newRecordings(cfg *config.Config, log *logger.Logger, store objectstore.Store) (recording.Store, error) {
+	if cfg.RecorderStorage != "object" {
+		return recording.NewFileStore(cfg.RecorderDir, cfg.RecorderMaxEntries)
+	}
+	objects, ok := store.(recording.Objects)
+	if !ok {
+		return nil, errors.New("RECORDER_STORAGE=object requires OBJECT_STORAGE_PROVIDER")
+	}
+	return recording.NewObjectStore(objects, RecordingsPrefix, recording.ObjectOptions{
+		MaxEntries: cfg.RecorderMaxEntries,
 		Logger:     log,
-		HTTPClient: httpClient,
+	})
+}
+
+// newReports returns the report service with the reports of the
+// application modules, or nil when its templates cannot be loaded.
+func newReports(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry,
+	store objectstore.Store, generator ids.Generator, jobs *metering.Pipeline, repo users.Repository) *reports.Service {
+	registry := reports.NewRegistry()
+	_ = registry.Register(users.NewReport(repo))
+
+	renderer, err := reports.NewRenderer(cfg.ReportsTemplatesDir)
+	if err != nil {
+		log.Errorw("reports_disabled", "error", err)
+		return nil
+	}
+	return reports.NewService(registry, renderer, store, reports.Options{
+		Concurrency: cfg.ReportsConcurrency,
+		MaxPending:  cfg.ReportsMaxPending,
+		Retention:   cfg.ReportsRetention,
+		LinkTTL:     cfg.ReportsLinkTTL,
+		IDs:         generator,
+		Metrics:     metrics,
+		Jobs:        jobs,
+	}, log)
+}
+
+// newUploads returns the upload service, storing through the malware
+// scanner of MALWARE_SCANNER when set, or nil when the scanner cannot be
+// initialized so uploads are never stored unscanned by mistake.
+func newUploads(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry,
+	store objectstore.Store, clients *httpclient.Registry) *uploads.Service {
+	var scanner malware.Scanner
+	var err error
+	switch cfg.MalwareScanner {
+	case "clamd":
+		scanner, err = clamd.New(cfg.MalwareClamdAddress)
+	case "http":
+		scanner, err = httpscan.New(cfg.MalwareScanURL, cfg.MalwareScanToken, clients.Client("malwarescan"))
+	}
+	if err != nil {
+		log.Errorw("uploads_disabled", "error", err)
+		return nil
+	}
+	if scanner != nil {
+		store = malware.NewStore(store, scanner, malware.Options{
+			Timeout:    cfg.MalwareScanTimeout,
+			FailOpen:   cfg.MalwareFailOpen,
+			Quarantine: cfg.MalwareQuarantine,
+			Metrics:    metrics,
+		}, log)
+	}
+	return uploads.NewService(store, uploads.Options{
+		MaxSize: cfg.UploadsMaxSize,
+		LinkTTL: cfg.UploadsLinkTTL,
+	})
+}
+
+// newConsent returns the consent service over the embedded store when
+// available so acceptances survive restarts.
+func newConsent(db *bolt.DB) *consent.Service {
+	var repo consent.Repository = memory.NewConsentRepository()
+	if db != nil {
+		repo = bolt.NewConsentRepository(db)
+	}
+	return consent.NewService(repo)
+}
+
+// newToggles returns the toggles service over the embedded store when
+// available, with the overrides persisted there applied.
+func newToggles(cfg *config.Config, log *logger.Logger, db *bolt.DB, routes *routeflags.Flags) *toggles.Service {
+	var repo toggles.Repository = memory.NewToggleRepository()
+	if db != nil {
+		repo = bolt.NewToggleRepository(db)
+	}
+	service := toggles.NewService(repo, cfg.FeatureFlags, routes)
+	if err := service.Restore(context.Background()); err != nil {
+		log.Errorw("toggles_restore_failed", "error", err)
+	}
+	return service
+}
+
+// newPrivacy returns the privacy service over the sources of the
+// application modules, persisting deletion requests in the embedded store
+// when available so the grace period survives restarts.
+func newPrivacy(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry, bus *events.Bus, db *bolt.DB,
+	store objectstore.Store, repo users.Repository) *privacy.Service {
+	registry := privacy.NewRegistry()
+	_ = registry.Register(users.NewPrivacySource(repo, bus))
+
+	var deletions privacy.DeletionRepository = memory.NewPrivacyDeletionRepository()
+	if db != nil {
+		deletions = bolt.NewPrivacyDeletionRepository(db)
+	}
+	return privacy.NewService(registry, users.NewPrivacyLookup(repo), deletions, store, privacy.Options{
+		Grace:         cfg.PrivacyDeletionGrace,
+		ExportTTL:     cfg.PrivacyExportTTL,
+		LinkTTL:       cfg.PrivacyLinkTTL,
+		SweepInterval: cfg.PrivacySweepInterval,
+		Metrics:       metrics,
+	}, log)
+}
+
+// newCacheStore returns the Redis cache store when available so cached
+// results and invalidations are shared by all instances, then the embedded
+// store so they survive restarts.
+func newCacheStore(cfg *config.Config, redisClient *goredis.Client, store *bolt.DB) cache.Store {
+	if redisClient != nil {
+		return redisstore.NewCacheStore(redisClient)
+	}
+	if store != nil {
+		return bolt.NewCacheStore(store)
+	}
+	return memory.NewCacheStore(cfg.CacheMaxEntries)
+}
+
+// newEmbeddedStore opens the embedded store of EMBEDDED_STORE_PATH, or
+// returns nil when it is unset or cannot be opened, falling back to the
+// in-memory stores like an unparsable REDIS_URL.
+func newEmbeddedStore(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry) *bolt.DB {
+	if cfg.EmbeddedStorePath == "" {
+		return nil
+	}
+	store, err := bolt.Open(cfg.EmbeddedStorePath, bolt.Options{
+		CompactInterval: cfg.EmbeddedStoreCompactInterval,
+		Metrics:         metrics,
+		Logger:          log,
+	})
+	if err != nil {
+		log.Errorw("embedded_store_disabled", "error", err)
+		return nil
+	}
+	return store
+}
+
+// newQuotaService builds the quota service, counting in Redis when available
+// so all instances share usage.
+func newQuotaService(cfg *config.Config, log *logger.Logger, redisClient *goredis.Client) *quota.Service {
+	var store quota.Store = memory.NewCounterStore()
+	if redisClient != nil {
+		store = redisstore.NewCounterStore(redisClient)
+	}
+
+	overrides, err := quota.ParseOverrides(cfg.QuotaOverrides)
+	if err != nil {
+		log.Errorw("quota_overrides_ignored", "error", err)
+	}
+
+	return quota.NewService(store, quota.Limits{
+		Daily:   cfg.QuotaDailyLimit,
+		Monthly: cfg.QuotaMonthlyLimit,
+	}, overrides)
+}
+
+// newRateLimiter builds the rate limiter for the declared rules, counting in
+// Redis when available so all instances share the allowance.
+func newRateLimiter(cfg *config.Config, redisClient *goredis.Client) *ratelimit.Limiter {
+	if len(cfg.RateLimitRules) == 0 {
+		return nil
+	}
+	var store ratelimit.Store = memory.NewCounterStore()
+	if redisClient != nil {
+		store = redisstore.NewCounterStore(redisClient)
+	}
+	return ratelimit.New(store, cfg.RateLimitRules)
+}
+
+// newEncryption builds the field encryption envelope from the configured
+// keyring.
+func newEncryption(cfg *config.Config, log *logger.Logger) *crypto.Envelope {
+	if len(cfg.EncryptionKeys) == 0 {
+		return nil
+	}
+	keys, err := crypto.ParseKeys(cfg.EncryptionKeys)
+	if err != nil {
+		log.Errorw("field_encryption_disabled", "error", err)
+		return nil
+	}
+	keyring, err := crypto.NewKeyring(keys, cfg.EncryptionPrimaryKeyID)
+	if err != nil {
+		log.Errorw("field_encryption_disabled", "error", err)
+		return nil
+	}
+	return crypto.NewEnvelope(keyring)
+}
+
+// newAccessTokens returns the access JWT signer of
+// ACCESS_TOKEN_SIGNING_KEY, or nil when it is unset or invalid.
+func newAccessTokens(cfg *config.Config, log *logger.Logger) *accesstoken.Signer {
+	if cfg.AccessTokenSigningKey == "" {
+		return nil
+	}
+	signer, err := accesstoken.New([]byte(cfg.AccessTokenSigningKey), accesstoken.Options{
+		TTL:    cfg.AccessTokenTTL,
+		Issuer: cfg.AppName,
+	})
+	if err != nil {
+		log.Errorw("access_tokens_disabled", "error", err)
+		return nil
+	}
+	return signer
+}
+
+// newSessionService builds the refresh token service, storing families in
+// Redis when available so revocations apply to all instances, and minting
+// access JWTs with signer when set.
+func newSessionService(cfg *config.Config, redisClient *goredis.Client, signer *accesstoken.Signer) *sessions.Service {
+	var store sessions.Store = memory.NewSessionStore()
+	if redisClient != nil {
+		store = redisstore.NewSessionStore(redisClient)
+	}
+	opts := sessions.Options{
+		TTL:    cfg.RefreshTokenTTL,
+		MaxAge: cfg.RefreshTokenMaxAge,
+	}
+	if signer != nil {
+		opts.Access = signer
+	}
+	return sessions.NewService(store, opts)
+}
+
+// newTwoFactorService builds the two-factor service sealing secrets with
+// envelope. Enrollments are kept in Redis when available so lockouts apply
+// to all instances, else in the embedded store so they survive restarts.
+func newTwoFactorService(cfg *config.Config, envelope *crypto.Envelope, redisClient *goredis.Client, db *bolt.DB) *twofactor.Service {
+	if envelope == nil {
+		return nil
+	}
+	var repo twofactor.Repository = memory.NewTwoFactorRepository()
+	switch {
+	case redisClient != nil:
+		repo = redisstore.NewTwoFactorRepository(redisClient)
+	case db != nil:
+		repo = bolt.NewTwoFactorRepository(db)
+	}
+	issuer := cfg.TOTPIssuer
+	if issuer == "" {
+		issuer = cfg.AppName
 	}
+	return twofactor.NewService(repo, envelope, twofactor.Options{
+		Issuer:      issuer,
+		Skew:        cfg.TOTPSkew,
+		BackupCodes: cfg.TOTPBackupCodes,
+		MaxAttempts: cfg.TOTPMaxAttempts,
+		Lockout:     cfg.TOTPLockout,
+	})
+}
+
+// newSLOTracker starts tracking the declared service level objectives.
+func newSLOTracker(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry) *slo.Tracker {
+	if len(cfg.SLOObjectives) == 0 {
+		return nil
+	}
+	return slo.NewTracker(cfg.SLOObjectives, slo.Options{
+		Interval: cfg.SLOEvaluationInterval,
+		Metrics:  metrics,
+	}, log)
+}
+
+// newWatchdog starts the slow request watchdog when a threshold is set.
+func newWatchdog(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry) *watchdog.Watchdog {
+	if cfg.SlowRequestThreshold <= 0 {
+		return nil
+	}
+	return watchdog.New(watchdog.Options{
+		Threshold: cfg.SlowRequestThreshold,
+		Interval:  cfg.SlowRequestCheckInterval,
+		Stacks:    cfg.SlowRequestStacks,
+		Metrics:   metrics,
+	}, log)
+}
+
+// newSyntheticRunner builds the runner of the declared synthetic checks.
+// Path URLs probe the server on its loopback address unless
+// SYNTHETIC_BASE_URL is set.
+func newSyntheticRunner(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry, clients *httpclient.Registry) *synthetic.Runner {
+	if len(cfg.SyntheticChecks) == 0 {
+		return nil
+	}
+	base := cfg.SyntheticBaseURL
+	if base == "" {
+		host := cfg.Host
+		switch host {
+		case "", "0.0.0.0":
+			host = "127.0.0.1"
+		case "::", "[::]":
+			host = "::1"
+		}
+		base = "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Port))
+	}
+	return synthetic.New(cfg.SyntheticChecks, synthetic.Options{
+		BaseURL:  base,
+		Interval: cfg.SyntheticInterval,
+		Timeout:  cfg.SyntheticTimeout,
+		Metrics:  metrics,
+	}, clients.Client, log)
+}
+
+// newRouteFlags builds the route flags and watches their file for changes.
+func newRouteFlags(cfg *config.Config, log *logger.Logger) *routeflags.Flags {
+	flags := routeflags.New(cfg.RouteFlags)
+	if cfg.RouteFlagsConfigFile != "" {
+		flags.Watch(cfg.RouteFlagsConfigFile, cfg.RouteFlagsReloadInterval, config.LoadRouteFlags, log)
+	}
+	return flags
+}
+
+// newTemporal creates the Temporal client and the registry of module
+// workflows when TEMPORAL_ADDRESS is set. The client connects on first use;
+// unreadable TLS files disable it rather than failing startup.
+func newTemporal(cfg *config.Config, log *logger.Logger, metrics *prometheus.Registry) (temporalclient.Client, *temporal.Registry) {
+	if cfg.TemporalAddress == "" {
+		return nil, nil
+	}
+	c, err := temporal.New(temporal.Config{
+		HostPort:  cfg.TemporalAddress,
+		Namespace: cfg.TemporalNamespace,
+		APIKey:    cfg.TemporalAPIKey,
+		TLS: grpcclient.TLSConfig{
+			Enabled:    cfg.TemporalTLS,
+			CAFile:     cfg.TemporalTLSCAFile,
+			CertFile:   cfg.TemporalTLSCertFile,
+			KeyFile:    cfg.TemporalTLSKeyFile,
+			ServerName: cfg.TemporalTLSServerName,
+		},
+	}, temporal.Options{
+		Logger:  log,
+		Metrics: metrics,
+	})
+	if err != nil {
+		log.Errorw("temporal_disabled", "error", err)
+		return nil, nil
+	}
+	return c, temporal.NewRegistry(cfg.TemporalTaskQueue)
+}
+
+// newRequestQueue builds the prioritized request queue when enabled.
+func newRequestQueue(cfg *config.Config, metrics *prometheus.Registry) *priority.Queue {
+	if !cfg.PriorityQueueEnabled {
+		return nil
+	}
+	return priority.New([]priority.Class{
+		{
+			Name:        constants.PriorityClassAdmin,
+			Concurrency: cfg.PriorityAdminConcurrency,
+			QueueSize:   cfg.PriorityAdminQueueSize,
+			MaxWait:     cfg.PriorityQueueTimeout,
+		},
+		{
+			Name:        constants.PriorityClassPublic,
+			Concurrency: cfg.PriorityPublicConcurrency,
+			QueueSize:   cfg.PriorityPublicQueueSize,
+			MaxWait:     cfg.PriorityQueueTimeout,
+		},
+	}, priority.NewMetrics(metrics))
 }
 
 // Close cleans up resources held by the container.
-// Should be called during application shutdown.
+// Should be called during application shutdown. A failing resource does
+// not keep the remaining ones open; their errors are joined.
 func (c *Container) Close() error {
+	var errs []error
+
+	// Cancel the reports and data exports still generating
+	if c.Reports != nil {
+		c.Reports.Close()
+	}
+	if c.Privacy != nil {
+		c.Privacy.Close()
+	}
+
+	// Flush buffered usage events, report jobs included, before closing
+	// their sinks
+	if c.Metering != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := c.Metering.Close(ctx)
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Close the lazy dependencies that were built, newest first
+	for i := len(c.lazy) - 1; i >= 0; i-- {
+		if err := c.lazy[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Stop evaluating objectives
+	if c.SLO != nil {
+		c.SLO.Close()
+	}
+
+	// Stop scanning in-flight requests
+	if c.Watchdog != nil {
+		c.Watchdog.Close()
+	}
+
+	// Stop watching the route flags file
+	if c.RouteFlags != nil {
+		c.RouteFlags.Close()
+	}
+
 	// Close HTTP client connections
-	c.HTTPClient.CloseIdleConnections()
+	if c.HTTPClients != nil {
+		c.HTTPClients.CloseIdleConnections()
+	} else {
+		c.HTTPClient.CloseIdleConnections()
+	}
+
+	// Close the Temporal connection once the workers stopped
+	if c.Temporal != nil {
+		c.Temporal.Close()
+	}
+
+	// Close gRPC connections
+	if c.GRPCClients != nil {
+		if err := c.GRPCClients.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Close the embedded store
+	if c.Store != nil {
+		if err := c.Store.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Close Redis connections
+	if c.Redis != nil {
+		if err := c.Redis.Close(); err != nil && err != goredis.ErrClosed {
+			errs = append(errs, err)
+		}
+	}
 
 	// Sync logger (flush buffered entries)
 	if err := c.Logger.Sync(); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// parsePairs converts "name=value" configuration entries to a map.
+func parsePairs(pairs []string) map[string]string {
+	out := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, _ := strings.Cut(pair, "=")
+		out[name] = value
+	}
+	return out
 }
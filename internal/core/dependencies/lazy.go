@@ -0,0 +1,61 @@
+package dependencies
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Lazy is a dependency constructed on first use, for optional subsystems
+// that should neither slow startup nor fail the boot when unused. The
+// constructor runs once; its error is memoized and returned by every Get,
+// so a misconfigured subsystem fails the requests needing it.
+type Lazy[T any] struct {
+	once  sync.Once
+	build func() (T, error)
+	value T
+	err   error
+	built atomic.Bool
+}
+
+// NewLazy returns a dependency built by build on first use.
+func NewLazy[T any](build func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{build: build}
+}
+
+// Get returns the dependency, constructing it on the first call. It is
+// safe for concurrent use; concurrent first calls wait for one build.
+func (l *Lazy[T]) Get() (T, error) {
+	l.once.Do(func() {
+		l.value, l.err = l.build()
+		l.build = nil
+		l.built.Store(true)
+	})
+	return l.value, l.err
+}
+
+// Initialized reports whether the dependency was constructed (or its
+// construction failed).
+func (l *Lazy[T]) Initialized() bool {
+	return l.built.Load()
+}
+
+// Close closes the dependency if it was constructed and implements
+// io.Closer; unused dependencies are never built just to be closed.
+func (l *Lazy[T]) Close() error {
+	if !l.Initialized() || l.err != nil {
+		return nil
+	}
+	if closer, ok := any(l.value).(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// lazy returns a dependency built by build on first use and closed with
+// c.
+func lazy[T any](c *Container, build func() (T, error)) *Lazy[T] {
+	l := NewLazy(build)
+	c.lazy = append(c.lazy, l)
+	return l
+}
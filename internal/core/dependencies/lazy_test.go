@@ -0,0 +1,71 @@
+package dependencies
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestLazy_BuildsOnceOnFirstUse(t *testing.T) {
+	var builds atomic.Int32
+	l := NewLazy(func() (int, error) {
+		builds.Add(1)
+		return 42, nil
+	})
+	assert.False(t, l.Initialized())
+	assert.Zero(t, builds.Load(), "nothing is built before first use")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Get()
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), builds.Load())
+	assert.True(t, l.Initialized())
+}
+
+func TestLazy_MemoizesErrors(t *testing.T) {
+	var builds int
+	l := NewLazy(func() (int, error) {
+		builds++
+		return 0, errors.New("no brokers")
+	})
+
+	_, err := l.Get()
+	require.EqualError(t, err, "no brokers")
+	_, err = l.Get()
+	assert.EqualError(t, err, "no brokers")
+	assert.Equal(t, 1, builds)
+	assert.NoError(t, l.Close())
+}
+
+func TestLazy_ClosesOnlyBuiltDependencies(t *testing.T) {
+	var closed int
+	l := NewLazy(func() (closerFunc, error) {
+		return func() error { closed++; return nil }, nil
+	})
+
+	require.NoError(t, l.Close())
+	assert.False(t, l.Initialized(), "closing does not build")
+	assert.Zero(t, closed)
+
+	_, err := l.Get()
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	assert.Equal(t, 1, closed)
+}
@@ -0,0 +1,23 @@
+package dependencies
+
+// The route modules take their dependencies from the Container through the
+// accessors generated from these declarations, which fail with the missing
+// fields instead of handing a module a nil dependency. A trailing ? marks a
+// field the module accepts as nil.
+//
+//go:generate go run github.com/luminosita/change-me/cmd/depgen -type Container
+
+//depgen:module users Logger UserService Cursors?
+//depgen:module usage Config UsageAggregator
+//depgen:module quotas Logger QuotaService
+//depgen:module sessions Logger Sessions
+//depgen:module twofactor Logger TwoFactor
+//depgen:module store Logger Store
+//depgen:module search Logger UserSearch
+//depgen:module notifications Logger Notifications
+//depgen:module reports Logger Reports
+//depgen:module files Logger Files
+//depgen:module uploads Logger Uploads
+//depgen:module privacy Logger Privacy
+//depgen:module consent Logger Consent
+//depgen:module toggles Logger Toggles
@@ -0,0 +1,32 @@
+package dependencies
+
+import (
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleDeps_NameMissingDependencies(t *testing.T) {
+	_, err := (&Container{}).TwofactorDeps()
+	require.Error(t, err)
+	assert.Equal(t, "module twofactor: missing Logger, TwoFactor", err.Error())
+
+	c := &Container{Logger: &logger.Logger{}, TwoFactor: &twofactor.Service{}}
+	deps, err := c.TwofactorDeps()
+	require.NoError(t, err)
+	assert.Same(t, c.TwoFactor, deps.TwoFactor)
+}
+
+func TestIsNilDependency(t *testing.T) {
+	var nilLogger *logger.Logger
+	var nilIface any = nilLogger
+
+	assert.True(t, isNilDependency(nil))
+	assert.True(t, isNilDependency(nilIface))
+	assert.True(t, isNilDependency(map[string]int(nil)))
+	assert.False(t, isNilDependency(&logger.Logger{}))
+	assert.False(t, isNilDependency(""))
+}
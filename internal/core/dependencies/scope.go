@@ -0,0 +1,75 @@
+package dependencies
+
+import (
+	"context"
+
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+)
+
+// RequestScope holds the dependencies constructed for each request, next
+// to the singletons of the Container. It is built by InitializeScope and
+// ended when the request completes.
+type RequestScope struct {
+	// Principal is the authenticated caller, empty when anonymous
+	Principal string
+
+	// Logger is the container logger annotated with the request and
+	// trace IDs and the principal
+	Logger *logger.Logger
+
+	// UnitOfWork holds the domain events published during the request
+	// until it succeeded
+	UnitOfWork *events.Unit
+}
+
+type scopeKey struct{}
+
+// ScopeFrom returns the request scope carried by ctx, or nil outside a
+// request.
+func ScopeFrom(ctx context.Context) *RequestScope {
+	s, _ := ctx.Value(scopeKey{}).(*RequestScope)
+	return s
+}
+
+// OpenScope builds the dependency scope of a request and returns ctx
+// carrying it, with the function ending it. Ending a scope that did not
+// fail commits its unit of work; otherwise the held events are dropped.
+func (c *Container) OpenScope(ctx context.Context) (context.Context, func(failed bool)) {
+	scope, cleanup := InitializeScope(ctx, c)
+	ctx = context.WithValue(ctx, scopeKey{}, scope)
+	ctx = events.WithUnit(ctx, scope.UnitOfWork)
+	return ctx, func(failed bool) {
+		if !failed {
+			scope.UnitOfWork.Commit()
+		}
+		cleanup()
+	}
+}
+
+// providePrincipal returns the caller of the request.
+func providePrincipal(ctx context.Context) string {
+	return reqctx.Principal(ctx)
+}
+
+// provideRequestLogger annotates the container logger for the request.
+func provideRequestLogger(ctx context.Context, c *Container) *logger.Logger {
+	return c.Logger.With(
+		"request_id", reqctx.RequestID(ctx),
+		"trace_id", tracecontext.TraceID(ctx),
+		"principal", reqctx.Principal(ctx),
+	)
+}
+
+// provideUnitOfWork starts a unit of work over the event bus; the cleanup
+// drops events that were not committed.
+func provideUnitOfWork(c *Container) (*events.Unit, func()) {
+	unit := events.NewUnit(c.Events)
+	return unit, func() {
+		if dropped := unit.Rollback(); dropped > 0 {
+			c.Logger.Debugw("unit_of_work_rolled_back", "events", dropped)
+		}
+	}
+}
@@ -4,6 +4,9 @@
 package dependencies
 
 import (
+	"context"
+	"slices"
+
 	"github.com/google/wire"
 	"github.com/luminosita/change-me/internal/config"
 	"github.com/luminosita/change-me/pkg/logger"
@@ -20,10 +23,97 @@ func InitializeContainer() (*Container, error) {
 	return nil, nil
 }
 
+// InitializeContainerFrom initializes the container from an already loaded
+// configuration, so callers can time loading it separately.
+// Wire will generate the implementation of this function.
+func InitializeContainerFrom(cfg *config.Config) (*Container, error) {
+	wire.Build(
+		provideLogger,
+		NewContainer,
+	)
+	return nil, nil
+}
+
+// ScopeSet provides the request-scoped dependencies from the container and
+// the request context.
+var ScopeSet = wire.NewSet(
+	providePrincipal,
+	provideRequestLogger,
+	provideUnitOfWork,
+	wire.Struct(new(RequestScope), "*"),
+)
+
+// InitializeScope builds the dependencies of one request over the
+// container's singletons; the cleanup function ends the scope.
+// Wire will generate the implementation of this function.
+func InitializeScope(ctx context.Context, container *Container) (*RequestScope, func()) {
+	wire.Build(ScopeSet)
+	return nil, nil
+}
+
 // provideLogger creates a logger from configuration.
 func provideLogger(cfg *config.Config) (*logger.Logger, error) {
-	return logger.New(logger.Config{
+	// Cloud Logging reads severity and trace fields from gcp entries
+	format := cfg.LogFormat
+	if cfg.CloudRun && format == "json" {
+		format = "gcp"
+	}
+	logCfg := logger.Config{
 		Level:  cfg.LogLevel,
-		Format: cfg.LogFormat,
-	})
+		Format: format,
+		Sampling: &logger.Sampling{
+			Tick:       cfg.LogSamplingTick,
+			Initial:    cfg.LogSamplingInitial,
+			Thereafter: cfg.LogSamplingThereafter,
+		},
+		RedactPII:  cfg.LogRedactPII,
+		GCPProject: cfg.GCPProjectID,
+	}
+	if len(cfg.LogOutput) > 0 {
+		logCfg.NoConsole = !slices.Contains(cfg.LogOutput, "console")
+	}
+	for _, output := range cfg.LogOutput {
+		switch output {
+		case logger.OutputSyslog:
+			logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+				Type:     logger.OutputSyslog,
+				Format:   "json",
+				Level:    cfg.LogLevel,
+				Network:  cfg.LogSyslogNetwork,
+				Address:  cfg.LogSyslogAddress,
+				Facility: cfg.LogSyslogFacility,
+				AppName:  cfg.AppName,
+			})
+		case logger.OutputJournald:
+			logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+				Type:    logger.OutputJournald,
+				Level:   cfg.LogLevel,
+				AppName: cfg.AppName,
+			})
+		case logger.OutputLoki, logger.OutputElasticsearch:
+			logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+				Type:    output,
+				Level:   cfg.LogLevel,
+				Address: cfg.LogShipURL,
+				AppName: cfg.AppName,
+				Labels:  parsePairs(cfg.LogShipLabels),
+				Index:   cfg.LogShipIndex,
+				Headers: parsePairs(cfg.LogShipHeaders),
+				Batch: logger.Batch{
+					Size:      cfg.LogShipBatchSize,
+					Interval:  cfg.LogShipFlushInterval,
+					QueueSize: cfg.LogShipQueueSize,
+					Retries:   cfg.LogShipRetries,
+				},
+			})
+		}
+	}
+	if cfg.LogFile != "" {
+		logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+			Path:   cfg.LogFile,
+			Format: cfg.LogFileFormat,
+			Level:  cfg.LogFileLevel,
+		})
+	}
+	return logger.New(logCfg)
 }
@@ -0,0 +1,138 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package dependencies
+
+import (
+	"context"
+	"github.com/google/wire"
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/pkg/logger"
+	"slices"
+)
+
+// Injectors from wire.go:
+
+// InitializeContainer initializes the dependency injection container using Wire.
+// Wire will generate the implementation of this function.
+func InitializeContainer() (*Container, error) {
+	configConfig, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	logger, err := provideLogger(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	container := NewContainer(configConfig, logger)
+	return container, nil
+}
+
+// InitializeContainerFrom initializes the container from an already loaded
+// configuration, so callers can time loading it separately.
+// Wire will generate the implementation of this function.
+func InitializeContainerFrom(cfg *config.Config) (*Container, error) {
+	logger, err := provideLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
+	container := NewContainer(cfg, logger)
+	return container, nil
+}
+
+// InitializeScope builds the dependencies of one request over the
+// container's singletons; the cleanup function ends the scope.
+// Wire will generate the implementation of this function.
+func InitializeScope(ctx context.Context, container *Container) (*RequestScope, func()) {
+	string2 := providePrincipal(ctx)
+	logger := provideRequestLogger(ctx, container)
+	unit, cleanup := provideUnitOfWork(container)
+	requestScope := &RequestScope{
+		Principal:  string2,
+		Logger:     logger,
+		UnitOfWork: unit,
+	}
+	return requestScope, func() {
+		cleanup()
+	}
+}
+
+// wire.go:
+
+// ScopeSet provides the request-scoped dependencies from the container and
+// the request context.
+var ScopeSet = wire.NewSet(
+	providePrincipal,
+	provideRequestLogger,
+	provideUnitOfWork, wire.Struct(new(RequestScope), "*"),
+)
+
+// provideLogger creates a logger from configuration.
+func provideLogger(cfg *config.Config) (*logger.Logger, error) {
+
+	format := cfg.LogFormat
+	if cfg.CloudRun && format == "json" {
+		format = "gcp"
+	}
+	logCfg := logger.Config{
+		Level:  cfg.LogLevel,
+		Format: format,
+		Sampling: &logger.Sampling{
+			Tick:       cfg.LogSamplingTick,
+			Initial:    cfg.LogSamplingInitial,
+			Thereafter: cfg.LogSamplingThereafter,
+		},
+		RedactPII:  cfg.LogRedactPII,
+		GCPProject: cfg.GCPProjectID,
+	}
+	if len(cfg.LogOutput) > 0 {
+		logCfg.NoConsole = !slices.Contains(cfg.LogOutput, "console")
+	}
+	for _, output := range cfg.LogOutput {
+		switch output {
+		case logger.OutputSyslog:
+			logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+				Type:     logger.OutputSyslog,
+				Format:   "json",
+				Level:    cfg.LogLevel,
+				Network:  cfg.LogSyslogNetwork,
+				Address:  cfg.LogSyslogAddress,
+				Facility: cfg.LogSyslogFacility,
+				AppName:  cfg.AppName,
+			})
+		case logger.OutputJournald:
+			logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+				Type:    logger.OutputJournald,
+				Level:   cfg.LogLevel,
+				AppName: cfg.AppName,
+			})
+		case logger.OutputLoki, logger.OutputElasticsearch:
+			logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+				Type:    output,
+				Level:   cfg.LogLevel,
+				Address: cfg.LogShipURL,
+				AppName: cfg.AppName,
+				Labels:  parsePairs(cfg.LogShipLabels),
+				Index:   cfg.LogShipIndex,
+				Headers: parsePairs(cfg.LogShipHeaders),
+				Batch: logger.Batch{
+					Size:      cfg.LogShipBatchSize,
+					Interval:  cfg.LogShipFlushInterval,
+					QueueSize: cfg.LogShipQueueSize,
+					Retries:   cfg.LogShipRetries,
+				},
+			})
+		}
+	}
+	if cfg.LogFile != "" {
+		logCfg.Outputs = append(logCfg.Outputs, logger.Output{
+			Path:   cfg.LogFile,
+			Format: cfg.LogFileFormat,
+			Level:  cfg.LogFileLevel,
+		})
+	}
+	return logger.New(logCfg)
+}
@@ -0,0 +1,73 @@
+// Package events provides an in-process domain event bus.
+//
+// Modules publish events after state changes commit; other components
+// (cache invalidation, notifications) subscribe by event name without the
+// publisher knowing about them. Delivery is synchronous and in
+// subscription order; within a unit of work (see Unit) it is deferred
+// until the unit commits.
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Event is a domain event.
+type Event interface {
+	// EventName identifies the event type, e.g. "users.created".
+	EventName() string
+}
+
+// Handler reacts to an event. Handlers deal with their own failures;
+// a committed change is never rolled back because a subscriber failed.
+type Handler func(ctx context.Context, e Event)
+
+// Publisher publishes domain events.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Bus dispatches events to the handlers subscribed to their name.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus creates an empty event bus.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe registers h for events with the given name.
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], h)
+}
+
+// Publish delivers e to its subscribers synchronously, or holds it until
+// the unit of work of ctx (see WithUnit) commits.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if u := unitOf(ctx); u != nil && u.bus == b && u.hold(ctx, e) {
+		return
+	}
+	b.deliver(ctx, e)
+}
+
+// deliver runs the subscribers of e.
+func (b *Bus) deliver(ctx context.Context, e Event) {
+	b.mu.RLock()
+	handlers := b.handlers[e.EventName()]
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(ctx, e)
+	}
+}
+
+// Discard is a Publisher that drops every event.
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(context.Context, Event) {}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pinged struct{ n int }
+
+func (pinged) EventName() string { return "test.pinged" }
+
+func TestBus_DeliversInSubscriptionOrder(t *testing.T) {
+	bus := NewBus()
+	var got []int
+
+	bus.Subscribe("test.pinged", func(_ context.Context, e Event) { got = append(got, e.(pinged).n) })
+	bus.Subscribe("test.pinged", func(_ context.Context, e Event) { got = append(got, e.(pinged).n*10) })
+	bus.Subscribe("test.other", func(context.Context, Event) { t.Fatal("unexpected delivery") })
+
+	bus.Publish(context.Background(), pinged{n: 1})
+	bus.Publish(context.Background(), pinged{n: 2})
+
+	assert.Equal(t, []int{1, 10, 2, 20}, got)
+}
+
+func TestDiscard(t *testing.T) {
+	assert.NotPanics(t, func() { Discard.Publish(context.Background(), pinged{}) })
+}
+
+func TestUnit_HoldsEventsUntilCommit(t *testing.T) {
+	bus := NewBus()
+	var got []int
+	bus.Subscribe("test.pinged", func(_ context.Context, e Event) { got = append(got, e.(pinged).n) })
+
+	unit := NewUnit(bus)
+	ctx := WithUnit(context.Background(), unit)
+	bus.Publish(ctx, pinged{n: 1})
+	bus.Publish(ctx, pinged{n: 2})
+	bus.Publish(context.Background(), pinged{n: 3})
+	assert.Equal(t, []int{3}, got, "events outside the unit are delivered immediately")
+
+	assert.Equal(t, 2, unit.Commit())
+	assert.Equal(t, []int{3, 1, 2}, got)
+
+	assert.Zero(t, unit.Rollback(), "rollback after commit is a no-op")
+	bus.Publish(ctx, pinged{n: 4})
+	assert.Equal(t, []int{3, 1, 2, 4}, got, "a finished unit no longer holds events")
+}
+
+func TestUnit_RollbackDropsEvents(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("test.pinged", func(context.Context, Event) { t.Fatal("unexpected delivery") })
+
+	unit := NewUnit(bus)
+	bus.Publish(WithUnit(context.Background(), unit), pinged{n: 1})
+
+	assert.Equal(t, 1, unit.Rollback())
+	assert.Zero(t, unit.Commit())
+}
+
+func TestUnit_IgnoresOtherBuses(t *testing.T) {
+	bus, other := NewBus(), NewBus()
+	var delivered bool
+	other.Subscribe("test.pinged", func(context.Context, Event) { delivered = true })
+
+	other.Publish(WithUnit(context.Background(), NewUnit(bus)), pinged{})
+
+	assert.True(t, delivered)
+}
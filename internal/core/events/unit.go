@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Unit is a unit of work over a Bus: events published with a context
+// carrying the unit are held until Commit delivers them, so subscribers
+// never act on the events of a request that failed. Rollback drops them.
+// After either, events are delivered immediately.
+type Unit struct {
+	bus *Bus
+
+	mu      sync.Mutex
+	pending []pendingEvent
+	done    bool
+}
+
+// pendingEvent is an event held by a Unit with its publishing context.
+type pendingEvent struct {
+	ctx   context.Context
+	event Event
+}
+
+// NewUnit creates a unit of work delivering to bus.
+func NewUnit(bus *Bus) *Unit {
+	return &Unit{bus: bus}
+}
+
+type unitKey struct{}
+
+// WithUnit returns a copy of ctx whose events the Bus holds in u.
+func WithUnit(ctx context.Context, u *Unit) context.Context {
+	return context.WithValue(ctx, unitKey{}, u)
+}
+
+// unitOf returns the unit of work of ctx, or nil.
+func unitOf(ctx context.Context) *Unit {
+	u, _ := ctx.Value(unitKey{}).(*Unit)
+	return u
+}
+
+// hold queues e unless the unit is finished.
+func (u *Unit) hold(ctx context.Context, e Event) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.done {
+		return false
+	}
+	u.pending = append(u.pending, pendingEvent{ctx: ctx, event: e})
+	return true
+}
+
+// Commit delivers the held events in publication order and returns how
+// many there were.
+func (u *Unit) Commit() int {
+	pending := u.finish()
+	for _, p := range pending {
+		u.bus.deliver(p.ctx, p.event)
+	}
+	return len(pending)
+}
+
+// Rollback drops the held events and returns how many there were. It is
+// a no-op after Commit, so it can be deferred.
+func (u *Unit) Rollback() int {
+	return len(u.finish())
+}
+
+// finish ends the unit, returning the events it held.
+func (u *Unit) finish() []pendingEvent {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	pending := u.pending
+	u.pending, u.done = nil, true
+	return pending
+}
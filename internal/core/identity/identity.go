@@ -0,0 +1,21 @@
+// Package identity declares the ID of user accounts, so modules keeping
+// data about users (users, privacy) share one type without importing each
+// other.
+package identity
+
+import "github.com/luminosita/change-me/pkg/ids"
+
+// user is the ID kind of user accounts.
+type user struct{}
+
+func (user) IDFormat() ids.Format { return ids.UUID }
+
+// UserID identifies a user account.
+type UserID = ids.ID[user]
+
+// NewUserID returns a new user ID made by g, or by ids.Default when g is
+// nil.
+func NewUserID(g ids.Generator) UserID { return ids.New[user](g) }
+
+// ParseUserID parses the text form of a user ID.
+func ParseUserID(s string) (UserID, error) { return ids.Parse[user](s) }
@@ -0,0 +1,170 @@
+// Package malware scans uploaded files before they are persisted.
+//
+// A Scanner inspects file content; Store wraps an objectstore.Store so that
+// every Put is scanned first. Clean files are stored, infected ones are
+// rejected with ErrInfected and, with quarantine enabled, kept under
+// QuarantinePrefix for inspection, where no link is ever handed out for
+// them. When the scanner fails or times out, the policy decides: failing
+// closed rejects the file with ErrScanUnavailable, failing open stores it
+// unscanned.
+//
+// Scanner adapters live in internal/infrastructure/malware.
+package malware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/objectstore"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QuarantinePrefix is the key prefix of quarantined files.
+const QuarantinePrefix = "quarantine/"
+
+// Errors returned when storing files.
+var (
+	ErrInfected = apperrors.New(apperrors.KindInvalid, "file_infected",
+		"file rejected by malware scan").
+		Describe("The malware scanner detected a threat in the uploaded file.")
+	ErrScanUnavailable = apperrors.New(apperrors.KindUnavailable, "malware_scan_unavailable",
+		"malware scan unavailable").
+		Describe("The file could not be scanned for malware; retry later.")
+)
+
+// Verdict is the result of a scan.
+type Verdict struct {
+	Infected bool
+	Threat   string // Signature name of the detected threat
+}
+
+// Scanner inspects file content for malware.
+type Scanner interface {
+	// Scan reads r to the end and returns its verdict. Errors mean the
+	// content could not be scanned.
+	Scan(ctx context.Context, r io.Reader) (Verdict, error)
+}
+
+// Scan outcomes used as metric label values.
+const (
+	outcomeClean     = "clean"
+	outcomeInfected  = "infected"
+	outcomeFailed    = "failed"
+	outcomeUnscanned = "unscanned"
+)
+
+// Options configures a Store.
+type Options struct {
+	// Timeout bounds each scan (default 30s)
+	Timeout time.Duration
+
+	// FailOpen stores files when the scanner fails instead of rejecting them
+	FailOpen bool
+
+	// Quarantine keeps infected files under QuarantinePrefix
+	Quarantine bool
+
+	Metrics prometheus.Registerer // Registers the scan counters when set
+}
+
+// Store is an objectstore.Store scanning content before storing it.
+type Store struct {
+	objectstore.Store
+	scanner Scanner
+	opts    Options
+	log     *logger.Logger
+
+	scans *prometheus.CounterVec
+}
+
+// NewStore returns store scanning every Put with scanner.
+func NewStore(store objectstore.Store, scanner Scanner, opts Options, log *logger.Logger) *Store {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	s := &Store{
+		Store:   store,
+		scanner: scanner,
+		opts:    opts,
+		log:     log,
+		scans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "malware_scans_total",
+			Help: "Scans of stored files by outcome (clean, infected, failed, unscanned).",
+		}, []string{"outcome"}),
+	}
+	if opts.Metrics != nil {
+		opts.Metrics.MustRegister(s.scans)
+	}
+	return s
+}
+
+// Put scans the content of r and stores it under key when clean. It fails
+// with ErrInfected for infected content and with ErrScanUnavailable when
+// the scan failed and the store fails closed.
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	// The content is spooled to disk so the scanner and the store both
+	// read it in full without holding large files in memory.
+	spool, err := os.CreateTemp("", "malware-scan-*")
+	if err != nil {
+		return fmt.Errorf("spool upload: %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	if _, err := io.Copy(spool, r); err != nil {
+		return fmt.Errorf("spool upload: %w", err)
+	}
+
+	verdict, err := s.scan(ctx, spool)
+	switch {
+	case err != nil && !s.opts.FailOpen:
+		s.scans.WithLabelValues(outcomeFailed).Inc()
+		s.log.Errorw("malware_scan_failed", "key", key, "error", err)
+		return ErrScanUnavailable
+	case err != nil:
+		s.scans.WithLabelValues(outcomeUnscanned).Inc()
+		s.log.Warnw("malware_scan_failed", "key", key, "fail_open", true, "error", err)
+	case verdict.Infected:
+		s.scans.WithLabelValues(outcomeInfected).Inc()
+		s.log.Warnw("malware_detected", "key", key, "threat", verdict.Threat, "quarantined", s.opts.Quarantine)
+		if s.opts.Quarantine {
+			s.quarantine(ctx, key, spool, contentType)
+		}
+		return ErrInfected.WithMeta("threat", verdict.Threat)
+	default:
+		s.scans.WithLabelValues(outcomeClean).Inc()
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind upload: %w", err)
+	}
+	return s.Store.Put(ctx, key, spool, contentType)
+}
+
+// scan runs the scanner over the spooled content within the timeout.
+func (s *Store) scan(ctx context.Context, spool *os.File) (Verdict, error) {
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return Verdict{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	return s.scanner.Scan(ctx, spool)
+}
+
+// quarantine stores infected content under QuarantinePrefix. Failures are
+// logged: the file is rejected either way.
+func (s *Store) quarantine(ctx context.Context, key string, spool *os.File, contentType string) {
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		s.log.Errorw("malware_quarantine_failed", "key", key, "error", err)
+		return
+	}
+	if err := s.Store.Put(ctx, QuarantinePrefix+key, spool, contentType); err != nil {
+		s.log.Errorw("malware_quarantine_failed", "key", key, "error", err)
+	}
+}
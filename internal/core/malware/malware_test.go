@@ -0,0 +1,102 @@
+package malware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scannerFunc adapts a function to Scanner.
+type scannerFunc func(ctx context.Context, r io.Reader) (Verdict, error)
+
+func (f scannerFunc) Scan(ctx context.Context, r io.Reader) (Verdict, error) { return f(ctx, r) }
+
+// eicarScanner flags content containing "EICAR".
+var eicarScanner = scannerFunc(func(ctx context.Context, r io.Reader) (Verdict, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Verdict{}, err
+	}
+	if bytes.Contains(data, []byte("EICAR")) {
+		return Verdict{Infected: true, Threat: "Eicar-Test-Signature"}, nil
+	}
+	return Verdict{}, nil
+})
+
+func newTestStore(t *testing.T, scanner Scanner, opts Options) (*Store, *mocks.ObjectStore) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	opts.Metrics = prometheus.NewRegistry()
+	backing := mocks.NewObjectStore()
+	return NewStore(backing, scanner, opts, log), backing
+}
+
+func TestStore_PutStoresCleanFiles(t *testing.T) {
+	store, backing := newTestStore(t, eicarScanner, Options{})
+
+	require.NoError(t, store.Put(context.Background(), "uploads/a/notes.txt", strings.NewReader("hello"), "text/plain"))
+
+	assert.Equal(t, []byte("hello"), backing.Object("uploads/a/notes.txt"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(store.scans.WithLabelValues(outcomeClean)))
+}
+
+func TestStore_PutQuarantinesInfectedFiles(t *testing.T) {
+	store, backing := newTestStore(t, eicarScanner, Options{Quarantine: true})
+
+	err := store.Put(context.Background(), "uploads/a/virus.com", strings.NewReader("X5O!P%@AP EICAR"), "")
+
+	require.ErrorIs(t, err, ErrInfected)
+	var appErr *apperrors.Error
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, "Eicar-Test-Signature", appErr.Meta()["threat"])
+	assert.Nil(t, backing.Object("uploads/a/virus.com"))
+	assert.Equal(t, []byte("X5O!P%@AP EICAR"), backing.Object("quarantine/uploads/a/virus.com"))
+}
+
+func TestStore_PutDropsInfectedFilesWithoutQuarantine(t *testing.T) {
+	store, backing := newTestStore(t, eicarScanner, Options{})
+
+	err := store.Put(context.Background(), "uploads/a/virus.com", strings.NewReader("EICAR"), "")
+
+	require.ErrorIs(t, err, ErrInfected)
+	assert.Zero(t, backing.Len())
+}
+
+func TestStore_PutScanFailurePolicy(t *testing.T) {
+	hanging := scannerFunc(func(ctx context.Context, r io.Reader) (Verdict, error) {
+		<-ctx.Done()
+		return Verdict{}, ctx.Err()
+	})
+
+	t.Run("fail closed", func(t *testing.T) {
+		store, backing := newTestStore(t, hanging, Options{Timeout: 10 * time.Millisecond})
+
+		err := store.Put(context.Background(), "uploads/a/notes.txt", strings.NewReader("hello"), "")
+
+		require.ErrorIs(t, err, ErrScanUnavailable)
+		assert.Equal(t, 503, apperrors.HTTPStatus(err))
+		assert.Zero(t, backing.Len())
+		assert.Equal(t, 1.0, testutil.ToFloat64(store.scans.WithLabelValues(outcomeFailed)))
+	})
+
+	t.Run("fail open", func(t *testing.T) {
+		store, backing := newTestStore(t, hanging, Options{Timeout: 10 * time.Millisecond, FailOpen: true})
+
+		require.NoError(t, store.Put(context.Background(), "uploads/a/notes.txt", strings.NewReader("hello"), ""))
+
+		assert.Equal(t, []byte("hello"), backing.Object("uploads/a/notes.txt"))
+		assert.Equal(t, 1.0, testutil.ToFloat64(store.scans.WithLabelValues(outcomeUnscanned)))
+	})
+}
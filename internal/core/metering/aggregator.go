@@ -0,0 +1,168 @@
+package metering
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Period selects the usage window returned by Aggregator.Summary.
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Monthly Period = "monthly"
+)
+
+// retentionDays bounds how long daily buckets are kept in memory.
+const retentionDays = 62
+
+// RouteUsage totals the events recorded for one route.
+type RouteUsage struct {
+	Route    string `json:"route"`
+	Requests int64  `json:"requests"`
+	BytesIn  int64  `json:"bytes_in"`
+	BytesOut int64  `json:"bytes_out"`
+	Jobs     int64  `json:"jobs"`
+}
+
+// Summary is the usage of a subject within a period.
+type Summary struct {
+	Subject string         `json:"subject"`
+	Period  Period         `json:"period"`
+	Start   time.Time      `json:"start"`
+	End     time.Time      `json:"end"`
+	Totals  map[Kind]int64 `json:"totals"`
+	Routes  []RouteUsage   `json:"routes"`
+}
+
+// Aggregator is a Sink that keeps per-day usage totals in memory so usage
+// can be reported without querying the downstream sinks.
+type Aggregator struct {
+	mu      sync.RWMutex
+	buckets map[string]map[time.Time]map[string]*RouteUsage // subject -> day -> route
+	pruned  time.Time
+	now     func() time.Time
+}
+
+// NewAggregator creates an empty aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		buckets: make(map[string]map[time.Time]map[string]*RouteUsage),
+		now:     time.Now,
+	}
+}
+
+// Write implements Sink.
+func (a *Aggregator) Write(ctx context.Context, events []Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, e := range events {
+		days, ok := a.buckets[e.Subject]
+		if !ok {
+			days = make(map[time.Time]map[string]*RouteUsage)
+			a.buckets[e.Subject] = days
+		}
+		day := e.OccurredAt.UTC().Truncate(24 * time.Hour)
+		routes, ok := days[day]
+		if !ok {
+			routes = make(map[string]*RouteUsage)
+			days[day] = routes
+		}
+		ru, ok := routes[e.Route]
+		if !ok {
+			ru = &RouteUsage{Route: e.Route}
+			routes[e.Route] = ru
+		}
+		switch e.Kind {
+		case KindRequest:
+			ru.Requests += e.Quantity
+		case KindBytesIn:
+			ru.BytesIn += e.Quantity
+		case KindBytesOut:
+			ru.BytesOut += e.Quantity
+		case KindJob:
+			ru.Jobs += e.Quantity
+		}
+	}
+
+	a.prune()
+	return nil
+}
+
+// Summary returns the usage of subject in the current period.
+func (a *Aggregator) Summary(subject string, period Period) Summary {
+	now := a.now().UTC()
+	y, m, d := now.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	if period == Monthly {
+		start = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, 0)
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	merged := make(map[string]*RouteUsage)
+	for day, routes := range a.buckets[subject] {
+		if day.Before(start) || !day.Before(end) {
+			continue
+		}
+		for route, ru := range routes {
+			acc, ok := merged[route]
+			if !ok {
+				acc = &RouteUsage{Route: route}
+				merged[route] = acc
+			}
+			acc.Requests += ru.Requests
+			acc.BytesIn += ru.BytesIn
+			acc.BytesOut += ru.BytesOut
+			acc.Jobs += ru.Jobs
+		}
+	}
+
+	summary := Summary{
+		Subject: subject,
+		Period:  period,
+		Start:   start,
+		End:     end,
+		Totals:  map[Kind]int64{KindRequest: 0, KindBytesIn: 0, KindBytesOut: 0, KindJob: 0},
+		Routes:  make([]RouteUsage, 0, len(merged)),
+	}
+	for _, ru := range merged {
+		summary.Totals[KindRequest] += ru.Requests
+		summary.Totals[KindBytesIn] += ru.BytesIn
+		summary.Totals[KindBytesOut] += ru.BytesOut
+		summary.Totals[KindJob] += ru.Jobs
+		summary.Routes = append(summary.Routes, *ru)
+	}
+	sort.Slice(summary.Routes, func(i, j int) bool {
+		return summary.Routes[i].Route < summary.Routes[j].Route
+	})
+	return summary
+}
+
+// prune drops buckets older than the retention window, at most hourly.
+// Callers hold mu.
+func (a *Aggregator) prune() {
+	now := a.now().UTC()
+	if now.Sub(a.pruned) < time.Hour {
+		return
+	}
+	a.pruned = now
+
+	cutoff := now.Truncate(24*time.Hour).AddDate(0, 0, -retentionDays)
+	for subject, days := range a.buckets {
+		for day := range days {
+			if day.Before(cutoff) {
+				delete(days, day)
+			}
+		}
+		if len(days) == 0 {
+			delete(a.buckets, subject)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package metering records billable usage events and delivers them in
+// batches to one or more sinks.
+//
+// Events are buffered in memory and flushed when a batch fills up or the
+// flush interval elapses. Recording never blocks the caller: when the buffer
+// is full, events are dropped and counted so the loss is observable.
+package metering
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Kind classifies a billable event.
+type Kind string
+
+const (
+	KindRequest  Kind = "request"
+	KindBytesIn  Kind = "bytes_in"
+	KindBytesOut Kind = "bytes_out"
+	KindJob      Kind = "job"
+)
+
+// Event is a single billable occurrence.
+type Event struct {
+	Subject    string    `json:"subject"`
+	Kind       Kind      `json:"kind"`
+	Route      string    `json:"route,omitempty"`
+	Quantity   int64     `json:"quantity"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Sink receives batches of events.
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+}
+
+// MultiSink writes every batch to all sinks and joins their errors.
+type MultiSink []Sink
+
+// Write implements Sink.
+func (m MultiSink) Write(ctx context.Context, events []Event) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Write(ctx, events); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Options configures a Pipeline.
+type Options struct {
+	BufferSize    int           // Events buffered before dropping (default 10000)
+	BatchSize     int           // Events per sink write (default 500)
+	FlushInterval time.Duration // Maximum delay before a partial batch is written (default 5s)
+	WriteTimeout  time.Duration // Timeout per sink write (default 10s)
+}
+
+// Pipeline buffers events and flushes them to a sink in batches.
+type Pipeline struct {
+	sink    Sink
+	opts    Options
+	log     *logger.Logger
+	events  chan Event
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+	now     func() time.Time
+}
+
+// NewPipeline creates a pipeline and starts its flush loop.
+//
+// Parameters:
+//   - sink: Destination for event batches
+//   - opts: Buffering and flushing options
+//   - log: Structured logger
+//
+// Returns:
+//   - *Pipeline: Running pipeline; call Close to flush and stop it
+func NewPipeline(sink Sink, opts Options, log *logger.Logger) *Pipeline {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 10000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = 10 * time.Second
+	}
+
+	p := &Pipeline{
+		sink:   sink,
+		opts:   opts,
+		log:    log,
+		events: make(chan Event, opts.BufferSize),
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+	go p.run()
+	return p
+}
+
+// Record enqueues an event. It reports false if the event was dropped
+// because the buffer is full or the pipeline is closed. Zero OccurredAt is
+// set to the current time.
+func (p *Pipeline) Record(e Event) bool {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = p.now().UTC()
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case p.events <- e:
+		return true
+	default:
+		p.dropped.Add(1)
+		return false
+	}
+}
+
+// RecordJob records one execution of a background job for subject.
+func (p *Pipeline) RecordJob(subject, job string) bool {
+	return p.Record(Event{Subject: subject, Kind: KindJob, Route: job, Quantity: 1})
+}
+
+// Dropped returns the number of events dropped so far.
+func (p *Pipeline) Dropped() int64 {
+	return p.dropped.Load()
+}
+
+// Close stops accepting events and flushes everything buffered.
+// It is safe to call more than once.
+func (p *Pipeline) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.events)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run batches events until the channel is closed.
+func (p *Pipeline) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, p.opts.BatchSize)
+	for {
+		select {
+		case e, ok := <-p.events:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= p.opts.BatchSize {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush writes a batch to the sink. Failed batches are logged and dropped.
+func (p *Pipeline) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.WriteTimeout)
+	defer cancel()
+
+	if err := p.sink.Write(ctx, batch); err != nil {
+		p.log.Errorw("metering_flush_failed", "events", len(batch), "error", err)
+	}
+}
@@ -0,0 +1,108 @@
+package metering
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink collects written batches.
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]Event
+	err     error
+}
+
+func (s *recordingSink) Write(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return s.err
+}
+
+func (s *recordingSink) sizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]int, len(s.batches))
+	for i, b := range s.batches {
+		out[i] = len(b)
+	}
+	return out
+}
+
+func TestPipeline_FlushesFullBatchesAndRemainderOnClose(t *testing.T) {
+	sink := &recordingSink{}
+	p := NewPipeline(sink, Options{BatchSize: 2, FlushInterval: time.Hour}, newTestLogger(t))
+
+	for i := 0; i < 5; i++ {
+		require.True(t, p.Record(Event{Subject: "s", Kind: KindRequest, Quantity: 1}))
+	}
+	require.NoError(t, p.Close(context.Background()))
+
+	assert.Equal(t, []int{2, 2, 1}, sink.sizes())
+	assert.False(t, p.Record(Event{Subject: "s"}), "closed pipeline drops events")
+	assert.Equal(t, int64(1), p.Dropped())
+	assert.NoError(t, p.Close(context.Background()))
+}
+
+func TestPipeline_FlushesOnInterval(t *testing.T) {
+	sink := &recordingSink{}
+	p := NewPipeline(sink, Options{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, newTestLogger(t))
+	defer p.Close(context.Background())
+
+	p.RecordJob("tenant-a", "reports.generate")
+
+	assert.Eventually(t, func() bool { return len(sink.sizes()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, KindJob, sink.batches[0][0].Kind)
+	assert.False(t, sink.batches[0][0].OccurredAt.IsZero())
+}
+
+func TestMultiSink_JoinsErrors(t *testing.T) {
+	boom := errors.New("boom")
+	ok := &recordingSink{}
+	failing := &recordingSink{err: boom}
+
+	err := MultiSink{failing, ok}.Write(context.Background(), []Event{{Subject: "s"}})
+
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []int{1}, ok.sizes(), "later sinks still receive the batch")
+}
+
+func TestAggregator_SummarizesByPeriodAndRoute(t *testing.T) {
+	agg := NewAggregator()
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	agg.now = func() time.Time { return now }
+
+	require.NoError(t, agg.Write(context.Background(), []Event{
+		{Subject: "a", Kind: KindRequest, Route: "/api/v1/users", Quantity: 1, OccurredAt: now},
+		{Subject: "a", Kind: KindRequest, Route: "/api/v1/users", Quantity: 1, OccurredAt: now},
+		{Subject: "a", Kind: KindBytesOut, Route: "/api/v1/users", Quantity: 512, OccurredAt: now},
+		{Subject: "a", Kind: KindRequest, Route: "/api/v1/users/:id", Quantity: 1, OccurredAt: now.AddDate(0, 0, -3)},
+		{Subject: "a", Kind: KindRequest, Route: "/api/v1/users", Quantity: 1, OccurredAt: now.AddDate(0, -1, 0)},
+		{Subject: "b", Kind: KindRequest, Route: "/api/v1/users", Quantity: 1, OccurredAt: now},
+	}))
+
+	daily := agg.Summary("a", Daily)
+	assert.Equal(t, int64(2), daily.Totals[KindRequest])
+	assert.Equal(t, int64(512), daily.Totals[KindBytesOut])
+	assert.Equal(t, time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC), daily.End)
+
+	monthly := agg.Summary("a", Monthly)
+	assert.Equal(t, int64(3), monthly.Totals[KindRequest])
+	require.Len(t, monthly.Routes, 2)
+	assert.Equal(t, "/api/v1/users", monthly.Routes[0].Route)
+	assert.Equal(t, int64(1), monthly.Routes[1].Requests)
+}
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
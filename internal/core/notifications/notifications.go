@@ -0,0 +1,146 @@
+// Package notifications delivers templated messages to users over email,
+// SMS, push and Slack.
+//
+// Modules enqueue a Message naming a channel, recipients and a template;
+// the delivery worker (see Service.Worker) renders it and hands it to the
+// channel's Notifier, retrying failures per the channel's RetryPolicy:
+//
+//	id, err := service.Notify(ctx, notifications.Message{
+//		Channel:  notifications.ChannelEmail,
+//		To:       []string{user.Email},
+//		Template: "welcome",
+//		Data:     map[string]any{"Name": user.FullName},
+//	})
+//
+// Channel adapters live in internal/infrastructure/notifications.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+)
+
+// Channels messages are delivered over.
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+	ChannelSlack = "slack"
+)
+
+// Errors returned when enqueuing messages.
+var (
+	ErrChannelUnavailable = apperrors.New(apperrors.KindInvalid, "notification_channel_unavailable",
+		"notification channel not configured")
+	ErrNoRecipients = apperrors.New(apperrors.KindInvalid, "notification_no_recipients",
+		"notification has no recipients")
+	ErrTemplateNotFound = apperrors.New(apperrors.KindInvalid, "notification_template_not_found",
+		"notification template not found")
+	ErrQueueFull = apperrors.New(apperrors.KindRateLimited, "notification_queue_full",
+		"notification queue full").
+		Describe("The notification queue is at capacity; retry later.")
+)
+
+// Message is a notification to render and deliver.
+type Message struct {
+	Channel  string         `json:"channel"`
+	To       []string       `json:"to"`
+	Template string         `json:"template"`
+	Data     map[string]any `json:"data,omitempty"`
+}
+
+// Notification is a rendered message handed to a Notifier.
+type Notification struct {
+	Channel string
+	To      []string
+
+	// Subject is the email subject or push title
+	Subject string
+	Body    string
+
+	// HTML is the optional HTML alternative of email bodies
+	HTML string
+}
+
+// Notifier delivers notifications over one channel.
+type Notifier interface {
+	// Send delivers n to all its recipients. Errors wrapped with Permanent
+	// are not retried.
+	Send(ctx context.Context, n Notification) error
+}
+
+// permanentError marks a failure retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. a rejected recipient.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// RetryPolicy bounds the delivery attempts of a channel. The backoff
+// doubles from InitialBackoff up to MaxBackoff between attempts.
+type RetryPolicy struct {
+	MaxAttempts    int           `yaml:"max_attempts" validate:"min=0"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" validate:"min=0"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" validate:"min=0"`
+}
+
+// DefaultRetryPolicy applies to channels without a policy and fills the
+// zero fields of declared ones.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second}
+
+// withDefaults returns p with zero values replaced by the defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	return p
+}
+
+// backoff returns the delay before the given retry (1 for the first).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// Job is an enqueued message.
+type Job struct {
+	ID         string    `json:"id"`
+	Message    Message   `json:"message"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
+
+// Queue holds jobs until the delivery worker takes them. Jobs are
+// delivered at most once: a job taken by a worker that stops before
+// delivering it is lost.
+type Queue interface {
+	// Enqueue adds job, or returns ErrQueueFull.
+	Enqueue(ctx context.Context, job Job) error
+
+	// Dequeue blocks until a job is available or ctx is done.
+	Dequeue(ctx context.Context) (Job, error)
+}
@@ -0,0 +1,185 @@
+package notifications
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/worker"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options configures a Service.
+type Options struct {
+	// Retry holds the retry policies per channel
+	Retry map[string]RetryPolicy
+
+	// Concurrency bounds the deliveries in flight per worker (default 4)
+	Concurrency int
+
+	Metrics prometheus.Registerer // Registers the delivery counters when set
+}
+
+// Service enqueues messages and delivers them with the channel notifiers.
+type Service struct {
+	notifiers map[string]Notifier
+	templates *Templates
+	queue     Queue
+	opts      Options
+	log       *logger.Logger
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+
+	deliveries *prometheus.CounterVec
+}
+
+// NewService creates a notification service delivering over notifiers,
+// keyed by channel.
+func NewService(notifiers map[string]Notifier, templates *Templates, queue Queue, opts Options, log *logger.Logger) *Service {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	s := &Service{
+		notifiers: notifiers,
+		templates: templates,
+		queue:     queue,
+		opts:      opts,
+		log:       log,
+		now:       time.Now,
+		sleep:     sleep,
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "notifications_delivered_total",
+			Help: "Notification deliveries by channel and outcome (sent, failed).",
+		}, []string{"channel", "outcome"}),
+	}
+	if opts.Metrics != nil {
+		opts.Metrics.MustRegister(s.deliveries)
+	}
+	return s
+}
+
+// Configured reports whether a notifier is configured for channel.
+func (s *Service) Configured(channel string) bool {
+	_, ok := s.notifiers[channel]
+	return ok
+}
+
+// Notify enqueues msg for delivery and returns its job ID. It fails with
+// ErrChannelUnavailable, ErrNoRecipients or ErrTemplateNotFound before
+// enqueuing.
+func (s *Service) Notify(ctx context.Context, msg Message) (string, error) {
+	if !s.Configured(msg.Channel) {
+		return "", ErrChannelUnavailable
+	}
+	if len(msg.To) == 0 {
+		return "", ErrNoRecipients
+	}
+	if !s.templates.Has(msg.Template, msg.Channel) {
+		return "", ErrTemplateNotFound
+	}
+
+	job := Job{ID: newJobID(), Message: msg, EnqueuedAt: s.now()}
+	if err := s.queue.Enqueue(ctx, job); err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// Deliver renders msg and sends it, retrying per the channel's policy.
+func (s *Service) Deliver(ctx context.Context, msg Message) error {
+	notifier, ok := s.notifiers[msg.Channel]
+	if !ok {
+		return ErrChannelUnavailable
+	}
+	n, err := s.templates.Render(msg)
+	if err != nil {
+		return fmt.Errorf("render %s/%s: %w", msg.Template, msg.Channel, err)
+	}
+
+	policy := s.opts.Retry[msg.Channel].withDefaults()
+	for attempt := 1; ; attempt++ {
+		err = notifier.Send(ctx, n)
+		if err == nil || IsPermanent(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+		s.log.Warnw("notification_retry", "channel", msg.Channel, "template", msg.Template,
+			"attempt", attempt, "error", err)
+		if err := s.sleep(ctx, policy.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// Worker returns the worker delivering the queued jobs. Running deliveries
+// complete before it stops.
+func (s *Service) Worker() worker.Worker {
+	return worker.New("notifications", func(ctx context.Context) error {
+		var inflight sync.WaitGroup
+		defer inflight.Wait()
+
+		slots := make(chan struct{}, s.opts.Concurrency)
+		for {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+			job, err := s.queue.Dequeue(ctx)
+			if err != nil {
+				<-slots
+				if ctx.Err() != nil {
+					return nil
+				}
+				s.log.Errorw("notification_dequeue_failed", "error", err)
+				if err := s.sleep(ctx, time.Second); err != nil {
+					return nil
+				}
+				continue
+			}
+
+			inflight.Add(1)
+			go func() {
+				defer inflight.Done()
+				defer func() { <-slots }()
+				s.deliverJob(context.WithoutCancel(ctx), job)
+			}()
+		}
+	})
+}
+
+// deliverJob delivers a dequeued job and records its outcome.
+func (s *Service) deliverJob(ctx context.Context, job Job) {
+	msg := job.Message
+	if err := s.Deliver(ctx, msg); err != nil {
+		s.deliveries.WithLabelValues(msg.Channel, "failed").Inc()
+		s.log.Errorw("notification_failed", "id", job.ID, "channel", msg.Channel, "template", msg.Template,
+			"permanent", IsPermanent(err), "error", err)
+		return
+	}
+	s.deliveries.WithLabelValues(msg.Channel, "sent").Inc()
+	s.log.Infow("notification_sent", "id", job.ID, "channel", msg.Channel, "template", msg.Template,
+		"recipients", len(msg.To), "queued_ms", s.now().Sub(job.EnqueuedAt).Milliseconds())
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newJobID returns a random job ID.
+func newJobID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,137 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotifier fails the first failures sends with err.
+type fakeNotifier struct {
+	mu       sync.Mutex
+	failures int
+	err      error
+	sent     []Notification
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return f.err
+	}
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+// chanQueue is an unbounded test queue.
+type chanQueue chan Job
+
+func (q chanQueue) Enqueue(ctx context.Context, job Job) error { q <- job; return nil }
+func (q chanQueue) Dequeue(ctx context.Context) (Job, error) {
+	select {
+	case job := <-q:
+		return job, nil
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+func newTestService(t *testing.T, notifier Notifier, queue Queue, retry map[string]RetryPolicy) (*Service, *[]time.Duration) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	templates, err := NewTemplates("")
+	require.NoError(t, err)
+
+	s := NewService(map[string]Notifier{ChannelEmail: notifier}, templates, queue, Options{Retry: retry}, log)
+	var waits []time.Duration
+	s.sleep = func(ctx context.Context, d time.Duration) error { waits = append(waits, d); return nil }
+	return s, &waits
+}
+
+var welcome = Message{
+	Channel:  ChannelEmail,
+	To:       []string{"jane@example.com"},
+	Template: "welcome",
+	Data:     map[string]any{"Username": "jane", "FullName": "Jane Doe", "Email": "jane@example.com"},
+}
+
+func TestService_NotifyValidatesBeforeEnqueuing(t *testing.T) {
+	queue := make(chanQueue, 1)
+	s, _ := newTestService(t, &fakeNotifier{}, queue, nil)
+	ctx := context.Background()
+
+	_, err := s.Notify(ctx, Message{Channel: ChannelSMS, To: []string{"+15551234567"}, Template: "message"})
+	assert.ErrorIs(t, err, ErrChannelUnavailable)
+	_, err = s.Notify(ctx, Message{Channel: ChannelEmail, Template: "welcome"})
+	assert.ErrorIs(t, err, ErrNoRecipients)
+	_, err = s.Notify(ctx, Message{Channel: ChannelEmail, To: welcome.To, Template: "missing"})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+	assert.Empty(t, queue)
+
+	id, err := s.Notify(ctx, welcome)
+	require.NoError(t, err)
+	job := <-queue
+	assert.Equal(t, id, job.ID)
+	assert.Equal(t, welcome, job.Message)
+}
+
+func TestService_DeliverRetriesWithBackoff(t *testing.T) {
+	notifier := &fakeNotifier{failures: 3, err: errors.New("connection reset")}
+	s, waits := newTestService(t, notifier, make(chanQueue), map[string]RetryPolicy{
+		ChannelEmail: {MaxAttempts: 4, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second},
+	})
+
+	require.NoError(t, s.Deliver(context.Background(), welcome))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, *waits)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "Welcome, jane", notifier.sent[0].Subject)
+}
+
+func TestService_DeliverGivesUp(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("after max attempts", func(t *testing.T) {
+		notifier := &fakeNotifier{failures: 5, err: errors.New("timeout")}
+		s, waits := newTestService(t, notifier, make(chanQueue), map[string]RetryPolicy{ChannelEmail: {MaxAttempts: 2}})
+		assert.ErrorContains(t, s.Deliver(ctx, welcome), "timeout")
+		assert.Len(t, *waits, 1)
+	})
+
+	t.Run("on permanent errors", func(t *testing.T) {
+		notifier := &fakeNotifier{failures: 5, err: Permanent(errors.New("mailbox unavailable"))}
+		s, waits := newTestService(t, notifier, make(chanQueue), nil)
+		err := s.Deliver(ctx, welcome)
+		assert.True(t, IsPermanent(err))
+		assert.Empty(t, *waits)
+	})
+}
+
+func TestService_WorkerDeliversQueuedJobs(t *testing.T) {
+	notifier := &fakeNotifier{}
+	queue := make(chanQueue, 3)
+	s, _ := newTestService(t, notifier, queue, nil)
+	for range 3 {
+		_, err := s.Notify(context.Background(), welcome)
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Worker().Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		notifier.mu.Lock()
+		defer notifier.mu.Unlock()
+		return len(notifier.sent) == 3
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,117 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// builtin holds the templates shipped with the module.
+//
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+// Templates renders messages. A template named "welcome" sent over email
+// is read from welcome.email.tmpl, falling back to welcome.tmpl for every
+// channel; it may define a "subject" block. Email templates may add an
+// HTML alternative in welcome.email.html.tmpl, escaped as HTML.
+//
+// Files of the templates directory take precedence over the built-in
+// templates of the same name.
+type Templates struct {
+	fsys []fs.FS
+}
+
+// NewTemplates creates the templates of dir layered over the built-in
+// ones; an empty dir uses the built-in templates only.
+func NewTemplates(dir string) (*Templates, error) {
+	embedded, _ := fs.Sub(builtin, "templates")
+	t := &Templates{fsys: []fs.FS{embedded}}
+	if dir == "" {
+		return t, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("notification templates: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("notification templates: %s is not a directory", dir)
+	}
+	t.fsys = append([]fs.FS{os.DirFS(dir)}, t.fsys...)
+	return t, nil
+}
+
+// Has reports whether a template named name exists for channel.
+func (t *Templates) Has(name, channel string) bool {
+	_, _, ok := t.read(name, channel, false)
+	return ok
+}
+
+// Render renders msg into the notification delivered to its recipients.
+func (t *Templates) Render(msg Message) (Notification, error) {
+	n := Notification{Channel: msg.Channel, To: msg.To}
+
+	file, src, ok := t.read(msg.Template, msg.Channel, false)
+	if !ok {
+		return n, ErrTemplateNotFound
+	}
+	tmpl, err := template.New(file).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return n, err
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, msg.Data); err != nil {
+		return n, err
+	}
+	n.Body = strings.TrimSpace(body.String())
+	if subject := tmpl.Lookup("subject"); subject != nil {
+		var b bytes.Buffer
+		if err := subject.Execute(&b, msg.Data); err != nil {
+			return n, err
+		}
+		n.Subject = strings.TrimSpace(b.String())
+	}
+
+	if msg.Channel != ChannelEmail {
+		return n, nil
+	}
+	file, src, ok = t.read(msg.Template, msg.Channel, true)
+	if !ok {
+		return n, nil
+	}
+	html, err := htmltemplate.New(file).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return n, err
+	}
+	var b bytes.Buffer
+	if err := html.Execute(&b, msg.Data); err != nil {
+		return n, err
+	}
+	n.HTML = b.String()
+	return n, nil
+}
+
+// read returns the first template file of name for channel, the HTML one
+// when html is set.
+func (t *Templates) read(name, channel string, html bool) (string, string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", "", false
+	}
+	files := []string{name + "." + channel + ".tmpl", name + ".tmpl"}
+	if html {
+		files = []string{name + "." + channel + ".html.tmpl"}
+	}
+	for _, file := range files {
+		for _, fsys := range t.fsys {
+			if data, err := fs.ReadFile(fsys, file); err == nil {
+				return file, string(data), true
+			}
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,53 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates_Render(t *testing.T) {
+	templates, err := NewTemplates("")
+	require.NoError(t, err)
+
+	n, err := templates.Render(welcome)
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome, jane", n.Subject)
+	assert.Contains(t, n.Body, "jane")
+	assert.Contains(t, n.HTML, "<")
+
+	n, err = templates.Render(Message{
+		Channel: ChannelSlack, To: []string{"alerts"}, Template: "message",
+		Data: map[string]any{"Subject": "Deploy", "Text": "v1.2.0 is live"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Notification{Channel: ChannelSlack, To: []string{"alerts"}, Subject: "Deploy", Body: "v1.2.0 is live"}, n)
+
+	_, err = templates.Render(Message{Channel: ChannelSMS, Template: "message", Data: map[string]any{"Subject": "x"}})
+	assert.ErrorContains(t, err, "Text", "missing keys fail rendering")
+	assert.False(t, templates.Has("../message", ChannelSMS))
+}
+
+func TestTemplates_DirectoryOverridesBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "welcome.email.tmpl"),
+		[]byte(`{{define "subject"}}Hi {{.Username}}{{end}}Custom body`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "code.sms.tmpl"), []byte(`Code: {{.Code}}`), 0o644))
+
+	templates, err := NewTemplates(dir)
+	require.NoError(t, err)
+
+	n, err := templates.Render(welcome)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi jane", n.Subject)
+	assert.Equal(t, "Custom body", n.Body)
+
+	assert.True(t, templates.Has("code", ChannelSMS))
+	assert.False(t, templates.Has("code", ChannelPush))
+
+	_, err = NewTemplates(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+// Package objectstore defines the storage of generated files (reports,
+// exports) handed to clients through time-limited download links.
+//
+// Adapters live in internal/infrastructure/objectstore: a local directory
+// whose links the application serves itself, and S3-compatible buckets
+// whose links are presigned.
+package objectstore
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+)
+
+// ErrNotFound is returned for missing objects.
+var ErrNotFound = apperrors.New(apperrors.KindNotFound, "object_not_found", "object not found")
+
+// ErrInvalidKey is returned for keys outside the key syntax.
+var ErrInvalidKey = apperrors.New(apperrors.KindInvalid, "object_key_invalid", "invalid object key")
+
+// Store holds objects under slash-separated keys such as
+// "reports/1f2e.pdf".
+type Store interface {
+	// Put stores the content of r under key, replacing any object there.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+
+	// URL returns a link downloading the object of key for ttl.
+	URL(ctx context.Context, key string, ttl time.Duration) (string, error)
+
+	// Delete removes the object of key; missing objects are not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Lister is implemented by stores that can also enumerate and read back
+// their objects, as both adapters do.
+type Lister interface {
+	// List returns the keys below prefix in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Get opens the object of key or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// keyPattern matches path segments of letters, digits, '.', '_' and '-'
+// that are not "." or "..".
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+// ValidKey reports whether key is a valid object key.
+func ValidKey(key string) bool {
+	return len(key) <= 512 && keyPattern.MatchString(key)
+}
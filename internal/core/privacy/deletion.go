@@ -0,0 +1,76 @@
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/identity"
+)
+
+// DeletionStatus is the lifecycle state of a deletion request.
+type DeletionStatus string
+
+// Deletion request states.
+const (
+	DeletionScheduled DeletionStatus = "scheduled"
+	DeletionCancelled DeletionStatus = "cancelled"
+	DeletionCompleted DeletionStatus = "completed"
+)
+
+// Audit trail actions of deletion requests.
+const (
+	ActionRequested   = "requested"
+	ActionSuspended   = "suspended"
+	ActionCancelled   = "cancelled"
+	ActionResumed     = "resumed"
+	ActionErased      = "erased"
+	ActionEraseFailed = "erase_failed"
+	ActionCompleted   = "completed"
+)
+
+// Deletion is a request to erase the data of a user.
+type Deletion struct {
+	ID          string          `json:"id"`
+	UserID      identity.UserID `json:"user_id"`
+	Status      DeletionStatus  `json:"status"`
+	RequestedBy string          `json:"requested_by"`
+	RequestedAt time.Time       `json:"requested_at"`
+
+	// PurgeAfter ends the grace period; the data is erased by the first
+	// sweep after it
+	PurgeAfter  time.Time `json:"purge_after"`
+	CompletedAt time.Time `json:"completed_at"`
+
+	// Suspended names the sources that hid the user's data, resumed when
+	// the request is cancelled
+	Suspended []string `json:"suspended,omitempty"`
+
+	Trail []TrailEntry `json:"trail"`
+}
+
+// TrailEntry records a step of a deletion request. Actors are request
+// principals, empty for the background sweep.
+type TrailEntry struct {
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor,omitempty"`
+	Action string    `json:"action"`
+	Source string    `json:"source,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// DeletionRepository persists deletion requests, which must outlive the
+// grace period.
+type DeletionRepository interface {
+	// Create stores a new request.
+	Create(ctx context.Context, deletion *Deletion) error
+
+	// Get returns the request of id or ErrDeletionNotFound.
+	Get(ctx context.Context, id string) (*Deletion, error)
+
+	// Update applies fn to the request of id and stores the result unless
+	// fn fails. It returns ErrDeletionNotFound for unknown requests.
+	Update(ctx context.Context, id string, fn func(*Deletion) error) error
+
+	// Scheduled returns the scheduled requests ordered by PurgeAfter.
+	Scheduled(ctx context.Context) ([]Deletion, error)
+}
@@ -0,0 +1,138 @@
+// Package privacy implements the data subject requests of users: exports
+// of their data as a downloadable archive, and deletions that erase it
+// across the application modules after a grace period.
+//
+// Modules holding personal data register a Source exporting and erasing
+// the data of a user:
+//
+//	_ = registry.Register(privacy.NewSource("orders",
+//		func(ctx context.Context, userID identity.UserID) (any, error) { return orders.ForUser(ctx, userID) },
+//		func(ctx context.Context, userID identity.UserID) error { return orders.DeleteForUser(ctx, userID) },
+//	))
+//
+// A deletion request first suspends the user with the sources that
+// implement Suspender, so the account disappears at once, and erases the
+// data of every source once the grace period elapsed, unless the request
+// is cancelled before. Each step is recorded in the request's audit trail.
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/identity"
+)
+
+// Errors returned by the privacy service.
+var (
+	ErrExportNotFound = apperrors.New(apperrors.KindNotFound, "privacy_export_not_found",
+		"data export not found")
+	ErrExportNotReady = apperrors.New(apperrors.KindConflict, "privacy_export_not_ready",
+		"data export has not succeeded")
+	ErrExportUnavailable = apperrors.New(apperrors.KindUnavailable, "privacy_export_unavailable",
+		"data exports require object storage")
+	ErrBusy = apperrors.New(apperrors.KindRateLimited, "privacy_exports_busy",
+		"too many pending data exports").
+		Describe("The maximum number of pending data exports is reached; retry later.")
+	ErrDeletionNotFound = apperrors.New(apperrors.KindNotFound, "privacy_deletion_not_found",
+		"deletion request not found")
+	ErrDeletionPending = apperrors.New(apperrors.KindConflict, "privacy_deletion_pending",
+		"a deletion of the user is already scheduled")
+	ErrDeletionClosed = apperrors.New(apperrors.KindConflict, "privacy_deletion_closed",
+		"deletion request is no longer scheduled").
+		Describe("Only scheduled deletions can be cancelled; the request was cancelled or completed.")
+)
+
+// Source exports and erases the personal data one module holds on users.
+type Source interface {
+	// Name identifies the source in archives and audit trails.
+	Name() string
+
+	// Export returns the data of the user, encoded as JSON in the archive.
+	// Sources without data on the user return nil.
+	Export(ctx context.Context, userID identity.UserID) (any, error)
+
+	// Erase permanently removes the data of the user. Erasing data that
+	// is already gone is not an error.
+	Erase(ctx context.Context, userID identity.UserID) error
+}
+
+// Suspender is implemented by sources that hide the data of users whose
+// deletion is scheduled, until it is erased or the request cancelled.
+type Suspender interface {
+	// Suspend hides the data of the user and reports whether it did;
+	// data hidden before the request is left for Resume to keep hidden.
+	Suspend(ctx context.Context, userID identity.UserID) (bool, error)
+
+	// Resume undoes Suspend.
+	Resume(ctx context.Context, userID identity.UserID) error
+}
+
+// Lookup returns nil when the user exists, suspended included, or the
+// user module's not found error.
+type Lookup func(ctx context.Context, userID identity.UserID) error
+
+// funcSource adapts functions to the Source interface.
+type funcSource struct {
+	name   string
+	export func(ctx context.Context, userID identity.UserID) (any, error)
+	erase  func(ctx context.Context, userID identity.UserID) error
+}
+
+// NewSource creates a Source from functions.
+func NewSource(name string, export func(ctx context.Context, userID identity.UserID) (any, error),
+	erase func(ctx context.Context, userID identity.UserID) error) Source {
+	return &funcSource{name: name, export: export, erase: erase}
+}
+
+func (s *funcSource) Name() string { return s.name }
+func (s *funcSource) Export(ctx context.Context, userID identity.UserID) (any, error) {
+	return s.export(ctx, userID)
+}
+func (s *funcSource) Erase(ctx context.Context, userID identity.UserID) error {
+	return s.erase(ctx, userID)
+}
+
+// Registry holds the sources contributed by application modules.
+type Registry struct {
+	mu      sync.Mutex
+	sources []Source
+}
+
+// NewRegistry creates an empty source registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds sources to the registry. Sources are exported and erased
+// in registration order, so modules referencing users register before the
+// users module.
+// It returns an error if a source name is empty or already registered.
+func (r *Registry) Register(sources ...Source) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, source := range sources {
+		name := source.Name()
+		if name == "" {
+			return fmt.Errorf("privacy source name must not be empty")
+		}
+		for _, existing := range r.sources {
+			if existing.Name() == name {
+				return fmt.Errorf("privacy source %q already registered", name)
+			}
+		}
+		r.sources = append(r.sources, source)
+	}
+	return nil
+}
+
+// List returns the registered sources in registration order.
+func (r *Registry) List() []Source {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Source(nil), r.sources...)
+}
@@ -0,0 +1,554 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/identity"
+	"github.com/luminosita/change-me/internal/core/objectstore"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/worker"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ExportStatus is the lifecycle state of a data export.
+type ExportStatus string
+
+// Data export states.
+const (
+	ExportPending   ExportStatus = "pending"
+	ExportRunning   ExportStatus = "running"
+	ExportSucceeded ExportStatus = "succeeded"
+	ExportFailed    ExportStatus = "failed"
+)
+
+// Export is the assembly of a user's data into an archive.
+type Export struct {
+	ID          string
+	UserID      identity.UserID
+	Status      ExportStatus
+	RequestedBy string
+
+	// Error describes why a failed export failed
+	Error string
+
+	CreatedAt   time.Time
+	CompletedAt time.Time
+
+	// ExpiresAt is when the archive of a succeeded export is deleted
+	ExpiresAt time.Time
+
+	// key is the object of the archive once succeeded
+	key string
+}
+
+// Done reports whether the export succeeded or failed.
+func (e Export) Done() bool {
+	return e.Status == ExportSucceeded || e.Status == ExportFailed
+}
+
+// Options configures a Service.
+type Options struct {
+	// Grace delays the erasure of users whose deletion was requested, so
+	// requests can be cancelled; 0 erases them on the next sweep
+	Grace time.Duration
+
+	// ExportTTL is how long archives are kept after they were assembled
+	// (default 24h)
+	ExportTTL time.Duration
+
+	// LinkTTL is the validity of download links (default 15m)
+	LinkTTL time.Duration
+
+	// MaxPending bounds the exports pending or running (default 10)
+	MaxPending int
+
+	// SweepInterval is how often the worker erases due deletions and
+	// expired archives (default 1m)
+	SweepInterval time.Duration
+
+	Metrics prometheus.Registerer // Registers the request counters when set
+}
+
+// Service runs data exports and deletion requests. Exports are tracked in
+// memory, so they are only visible on the instance that started them;
+// deletion requests are persisted by the DeletionRepository.
+type Service struct {
+	registry  *Registry
+	lookup    Lookup
+	deletions DeletionRepository
+	store     objectstore.Store
+	opts      Options
+	log       *logger.Logger
+	now       func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	slots  chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	exports map[string]*Export
+
+	exported *prometheus.CounterVec
+	deleted  *prometheus.CounterVec
+}
+
+// NewService creates a privacy service over the sources of registry.
+// lookup resolves the users requests are made for. Exports are stored in
+// store and unavailable when it is nil.
+func NewService(registry *Registry, lookup Lookup, deletions DeletionRepository, store objectstore.Store,
+	opts Options, log *logger.Logger) *Service {
+	if opts.ExportTTL <= 0 {
+		opts.ExportTTL = 24 * time.Hour
+	}
+	if opts.LinkTTL <= 0 {
+		opts.LinkTTL = 15 * time.Minute
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 10
+	}
+	if opts.SweepInterval <= 0 {
+		opts.SweepInterval = time.Minute
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		registry:  registry,
+		lookup:    lookup,
+		deletions: deletions,
+		store:     store,
+		opts:      opts,
+		log:       log,
+		now:       time.Now,
+		ctx:       ctx,
+		cancel:    cancel,
+		slots:     make(chan struct{}, 1),
+		exports:   make(map[string]*Export),
+		exported: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "privacy_exports_total",
+			Help: "Data exports completed by outcome (succeeded, failed).",
+		}, []string{"outcome"}),
+		deleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "privacy_deletions_total",
+			Help: "Deletion requests by outcome (cancelled, completed, failed).",
+		}, []string{"outcome"}),
+	}
+	if opts.Metrics != nil {
+		opts.Metrics.MustRegister(s.exported, s.deleted)
+	}
+	return s
+}
+
+// StartExport starts assembling the data of the user of userID and
+// returns the pending export.
+func (s *Service) StartExport(ctx context.Context, userID identity.UserID) (Export, error) {
+	if s.store == nil {
+		return Export{}, ErrExportUnavailable
+	}
+	if err := s.lookup(ctx, userID); err != nil {
+		return Export{}, err
+	}
+
+	s.mu.Lock()
+	active := 0
+	for _, e := range s.exports {
+		if !e.Done() {
+			active++
+		}
+	}
+	if active >= s.opts.MaxPending {
+		s.mu.Unlock()
+		return Export{}, ErrBusy
+	}
+	export := &Export{
+		ID:          newID(),
+		UserID:      userID,
+		Status:      ExportPending,
+		RequestedBy: reqctx.Principal(ctx),
+		CreatedAt:   s.now(),
+	}
+	s.exports[export.ID] = export
+	snapshot := *export
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	s.log.Infow("privacy_export_requested", "id", export.ID, "user_id", userID.String(), "actor", export.RequestedBy)
+	go s.runExport(export.ID)
+	return snapshot, nil
+}
+
+// GetExport returns the export of id.
+func (s *Service) GetExport(ctx context.Context, id string) (Export, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	export, ok := s.exports[id]
+	if !ok {
+		return Export{}, ErrExportNotFound
+	}
+	return *export, nil
+}
+
+// ExportURL returns the download link of the archive of a succeeded export.
+func (s *Service) ExportURL(ctx context.Context, export Export) (string, error) {
+	if export.Status != ExportSucceeded {
+		return "", ErrExportNotReady
+	}
+	ttl := min(s.opts.LinkTTL, export.ExpiresAt.Sub(s.now()))
+	if ttl <= 0 {
+		return "", ErrExportNotFound
+	}
+	return s.store.URL(ctx, export.key, ttl)
+}
+
+// LinkTTL returns the validity of download links.
+func (s *Service) LinkTTL() time.Duration {
+	return s.opts.LinkTTL
+}
+
+// RequestDeletion schedules the erasure of the data of the user of userID
+// after the grace period and suspends the user meanwhile.
+func (s *Service) RequestDeletion(ctx context.Context, userID identity.UserID) (Deletion, error) {
+	if err := s.lookup(ctx, userID); err != nil {
+		return Deletion{}, err
+	}
+	scheduled, err := s.deletions.Scheduled(ctx)
+	if err != nil {
+		return Deletion{}, err
+	}
+	for _, d := range scheduled {
+		if d.UserID == userID {
+			return Deletion{}, ErrDeletionPending
+		}
+	}
+
+	now := s.now().UTC()
+	actor := reqctx.Principal(ctx)
+	deletion := Deletion{
+		ID:          newID(),
+		UserID:      userID,
+		Status:      DeletionScheduled,
+		RequestedBy: actor,
+		RequestedAt: now,
+		PurgeAfter:  now.Add(s.opts.Grace),
+		Trail:       []TrailEntry{{At: now, Actor: actor, Action: ActionRequested}},
+	}
+	for _, source := range s.registry.List() {
+		suspender, ok := source.(Suspender)
+		if !ok {
+			continue
+		}
+		suspended, err := suspender.Suspend(ctx, userID)
+		if err != nil {
+			// The request is still recorded: the data is erased when due
+			s.log.Errorw("privacy_suspend_failed", "user_id", userID.String(), "source", source.Name(), "error", err)
+			continue
+		}
+		if suspended {
+			deletion.Suspended = append(deletion.Suspended, source.Name())
+			deletion.Trail = append(deletion.Trail, TrailEntry{At: s.now().UTC(), Actor: actor,
+				Action: ActionSuspended, Source: source.Name()})
+		}
+	}
+	if err := s.deletions.Create(ctx, &deletion); err != nil {
+		return Deletion{}, err
+	}
+	s.log.Infow("privacy_deletion_requested", "id", deletion.ID, "user_id", userID.String(), "actor", actor,
+		"purge_after", deletion.PurgeAfter)
+	return deletion, nil
+}
+
+// GetDeletion returns the deletion request of id.
+func (s *Service) GetDeletion(ctx context.Context, id string) (Deletion, error) {
+	deletion, err := s.deletions.Get(ctx, id)
+	if err != nil {
+		return Deletion{}, err
+	}
+	return *deletion, nil
+}
+
+// CancelDeletion cancels a scheduled deletion request and resumes the
+// suspended user. It fails with ErrDeletionClosed once the request was
+// cancelled or completed.
+func (s *Service) CancelDeletion(ctx context.Context, id string) (Deletion, error) {
+	actor := reqctx.Principal(ctx)
+	var cancelled Deletion
+	err := s.deletions.Update(ctx, id, func(d *Deletion) error {
+		if d.Status != DeletionScheduled {
+			return ErrDeletionClosed
+		}
+		now := s.now().UTC()
+		d.Status = DeletionCancelled
+		d.CompletedAt = now
+		d.Trail = append(d.Trail, TrailEntry{At: now, Actor: actor, Action: ActionCancelled})
+		for _, name := range d.Suspended {
+			source := s.source(name)
+			if source == nil {
+				continue
+			}
+			entry := TrailEntry{At: s.now().UTC(), Actor: actor, Action: ActionResumed, Source: name}
+			if err := source.(Suspender).Resume(ctx, d.UserID); err != nil {
+				s.log.Errorw("privacy_resume_failed", "id", d.ID, "user_id", d.UserID, "source", name, "error", err)
+				entry.Error = err.Error()
+			}
+			d.Trail = append(d.Trail, entry)
+		}
+		cancelled = *d
+		return nil
+	})
+	if err != nil {
+		return Deletion{}, err
+	}
+	s.deleted.WithLabelValues(string(DeletionCancelled)).Inc()
+	s.log.Infow("privacy_deletion_cancelled", "id", id, "user_id", cancelled.UserID, "actor", actor)
+	return cancelled, nil
+}
+
+// Sweep erases the data of the deletion requests whose grace period
+// elapsed and deletes expired export archives. It returns the number of
+// completed deletions. Requests failing to erase a source stay scheduled
+// and are retried by the next sweep.
+func (s *Service) Sweep(ctx context.Context) (int, error) {
+	s.expireExports(ctx)
+
+	scheduled, err := s.deletions.Scheduled(ctx)
+	if err != nil {
+		return 0, err
+	}
+	completed := 0
+	now := s.now()
+	for _, d := range scheduled {
+		if d.PurgeAfter.After(now) {
+			break
+		}
+		if s.erase(ctx, d) {
+			completed++
+		}
+	}
+	return completed, nil
+}
+
+// Worker returns the worker sweeping due deletions and expired exports.
+func (s *Service) Worker() worker.Worker {
+	return worker.Every("privacy", s.opts.SweepInterval, s.log, func(ctx context.Context) error {
+		_, err := s.Sweep(ctx)
+		return err
+	})
+}
+
+// Close cancels the running exports and waits for them to complete.
+func (s *Service) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// erase erases the data of d with every source and reports whether the
+// request completed.
+func (s *Service) erase(ctx context.Context, d Deletion) bool {
+	var trail []TrailEntry
+	failed := false
+	for _, source := range s.registry.List() {
+		entry := TrailEntry{At: s.now().UTC(), Action: ActionErased, Source: source.Name()}
+		if err := source.Erase(ctx, d.UserID); err != nil {
+			s.log.Errorw("privacy_erase_failed", "id", d.ID, "user_id", d.UserID, "source", source.Name(), "error", err)
+			entry.Action, entry.Error = ActionEraseFailed, err.Error()
+			failed = true
+		}
+		trail = append(trail, entry)
+	}
+	if !failed {
+		s.deleteExports(ctx, d.UserID)
+		trail = append(trail, TrailEntry{At: s.now().UTC(), Action: ActionCompleted})
+	}
+
+	err := s.deletions.Update(ctx, d.ID, func(stored *Deletion) error {
+		if stored.Status != DeletionScheduled {
+			return ErrDeletionClosed
+		}
+		stored.Trail = append(stored.Trail, trail...)
+		if !failed {
+			stored.Status = DeletionCompleted
+			stored.CompletedAt = s.now().UTC()
+		}
+		return nil
+	})
+	if err != nil {
+		s.log.Errorw("privacy_deletion_update_failed", "id", d.ID, "error", err)
+		return false
+	}
+	if failed {
+		s.deleted.WithLabelValues("failed").Inc()
+		return false
+	}
+	s.deleted.WithLabelValues(string(DeletionCompleted)).Inc()
+	s.log.Infow("privacy_deletion_completed", "id", d.ID, "user_id", d.UserID,
+		"requested_at", d.RequestedAt, "requested_by", d.RequestedBy)
+	return true
+}
+
+// source returns the registered source named name, or nil.
+func (s *Service) source(name string) Source {
+	for _, source := range s.registry.List() {
+		if source.Name() == name {
+			return source
+		}
+	}
+	return nil
+}
+
+// runExport assembles the archive of the export of id once a slot is free.
+func (s *Service) runExport(id string) {
+	defer s.wg.Done()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-s.ctx.Done():
+		s.failExport(id, s.ctx.Err())
+		return
+	}
+
+	export := s.updateExport(id, func(e *Export) { e.Status = ExportRunning })
+	var archive bytes.Buffer
+	if err := s.assemble(s.ctx, &archive, export.UserID); err != nil {
+		s.failExport(id, err)
+		return
+	}
+	size := archive.Len()
+	key := "privacy/exports/" + id + ".zip"
+	if err := s.store.Put(s.ctx, key, &archive, "application/zip"); err != nil {
+		s.failExport(id, err)
+		return
+	}
+
+	export = s.updateExport(id, func(e *Export) {
+		e.Status = ExportSucceeded
+		e.CompletedAt = s.now()
+		e.ExpiresAt = e.CompletedAt.Add(s.opts.ExportTTL)
+		e.key = key
+	})
+	s.exported.WithLabelValues(string(ExportSucceeded)).Inc()
+	s.log.Infow("privacy_export_generated", "id", id, "user_id", export.UserID, "bytes", size,
+		"duration_ms", export.CompletedAt.Sub(export.CreatedAt).Milliseconds())
+}
+
+// manifest describes the content of an export archive.
+type manifest struct {
+	UserID      identity.UserID `json:"user_id"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Sources     []string        `json:"sources"`
+}
+
+// assemble writes the zip archive of the data of the user: a JSON file
+// per source holding data and a manifest listing them.
+func (s *Service) assemble(ctx context.Context, w *bytes.Buffer, userID identity.UserID) error {
+	archive := zip.NewWriter(w)
+	m := manifest{UserID: userID, GeneratedAt: s.now().UTC(), Sources: []string{}}
+	for _, source := range s.registry.List() {
+		data, err := source.Export(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("export %s: %w", source.Name(), err)
+		}
+		if data == nil {
+			continue
+		}
+		if err := writeJSON(archive, source.Name()+".json", data); err != nil {
+			return fmt.Errorf("export %s: %w", source.Name(), err)
+		}
+		m.Sources = append(m.Sources, source.Name())
+	}
+	if err := writeJSON(archive, "manifest.json", m); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// writeJSON adds v as the indented JSON file name to archive.
+func writeJSON(archive *zip.Writer, name string, v any) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// failExport marks the export of id failed. Only invalid request errors
+// are described to clients.
+func (s *Service) failExport(id string, err error) {
+	message := "data export failed"
+	if apperrors.KindOf(err) == apperrors.KindInvalid {
+		message = err.Error()
+	}
+	export := s.updateExport(id, func(e *Export) {
+		e.Status = ExportFailed
+		e.Error = message
+		e.CompletedAt = s.now()
+		e.ExpiresAt = e.CompletedAt.Add(s.opts.ExportTTL)
+	})
+	s.exported.WithLabelValues(string(ExportFailed)).Inc()
+	s.log.Errorw("privacy_export_failed", "id", id, "user_id", export.UserID, "error", err)
+}
+
+// updateExport applies fn to the export of id and returns a copy of it.
+func (s *Service) updateExport(id string, fn func(e *Export)) Export {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	export := s.exports[id]
+	fn(export)
+	return *export
+}
+
+// expireExports forgets the exports completed longer than ExportTTL ago
+// and deletes their archives.
+func (s *Service) expireExports(ctx context.Context) {
+	now := s.now()
+	s.removeExports(ctx, func(e *Export) bool { return e.Done() && !e.ExpiresAt.After(now) })
+}
+
+// deleteExports forgets the completed exports of the user and deletes
+// their archives.
+func (s *Service) deleteExports(ctx context.Context, userID identity.UserID) {
+	s.removeExports(ctx, func(e *Export) bool { return e.Done() && e.UserID == userID })
+}
+
+// removeExports forgets the exports matching fn and deletes their archives.
+func (s *Service) removeExports(ctx context.Context, fn func(e *Export) bool) {
+	var keys []string
+	s.mu.Lock()
+	for id, export := range s.exports {
+		if !fn(export) {
+			continue
+		}
+		if export.key != "" {
+			keys = append(keys, export.key)
+		}
+		delete(s.exports, id)
+	}
+	s.mu.Unlock()
+
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, objectstore.ErrNotFound) {
+			s.log.Warnw("privacy_export_delete_failed", "key", key, "error", err)
+		}
+	}
+}
+
+// newID returns a random export or deletion ID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,350 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/identity"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUserNotFound = apperrors.New(apperrors.KindNotFound, "privacy_test_user_not_found", "user not found")
+
+// memDeletions is an in-memory DeletionRepository.
+type memDeletions struct {
+	mu   sync.Mutex
+	byID map[string]Deletion
+}
+
+func (r *memDeletions) Create(ctx context.Context, d *Deletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID[d.ID] = *d
+	return nil
+}
+
+func (r *memDeletions) Get(ctx context.Context, id string) (*Deletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.byID[id]
+	if !ok {
+		return nil, ErrDeletionNotFound
+	}
+	return &d, nil
+}
+
+func (r *memDeletions) Update(ctx context.Context, id string, fn func(*Deletion) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.byID[id]
+	if !ok {
+		return ErrDeletionNotFound
+	}
+	if err := fn(&d); err != nil {
+		return err
+	}
+	r.byID[id] = d
+	return nil
+}
+
+func (r *memDeletions) Scheduled(ctx context.Context) ([]Deletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Deletion
+	for _, d := range r.byID {
+		if d.Status == DeletionScheduled {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PurgeAfter.Before(out[j].PurgeAfter) })
+	return out, nil
+}
+
+// jane and john are the users of tests, registered with the sources
+// expected to find them.
+var (
+	jane = identity.UserID{15: 1}
+	john = identity.UserID{15: 2}
+)
+
+// accounts is a source holding one record per user, suspending them on
+// request.
+type accounts struct {
+	mu        sync.Mutex
+	records   map[identity.UserID]string
+	suspended map[identity.UserID]bool
+	eraseErr  error
+}
+
+func newAccounts(ids ...identity.UserID) *accounts {
+	a := &accounts{records: make(map[identity.UserID]string), suspended: make(map[identity.UserID]bool)}
+	for _, id := range ids {
+		a.records[id] = "record"
+	}
+	return a
+}
+
+func (a *accounts) Name() string { return "accounts" }
+
+func (a *accounts) Export(ctx context.Context, userID identity.UserID) (any, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	record, ok := a.records[userID]
+	if !ok {
+		return nil, nil
+	}
+	return map[string]any{"id": userID, "record": record}, nil
+}
+
+func (a *accounts) Erase(ctx context.Context, userID identity.UserID) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.eraseErr != nil {
+		return a.eraseErr
+	}
+	delete(a.records, userID)
+	return nil
+}
+
+func (a *accounts) Suspend(ctx context.Context, userID identity.UserID) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.suspended[userID] = true
+	return true, nil
+}
+
+func (a *accounts) Resume(ctx context.Context, userID identity.UserID) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.suspended, userID)
+	return nil
+}
+
+func (a *accounts) lookup(ctx context.Context, userID identity.UserID) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.records[userID]; !ok {
+		return errUserNotFound
+	}
+	return nil
+}
+
+func (a *accounts) has(userID identity.UserID) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.records[userID]
+	return ok
+}
+
+func newTestService(t *testing.T, opts Options, users *accounts, sources ...Source) (*Service, *mocks.ObjectStore) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(sources...))
+	require.NoError(t, registry.Register(users))
+
+	store := mocks.NewObjectStore()
+	s := NewService(registry, users.lookup, &memDeletions{byID: make(map[string]Deletion)}, store, opts, log)
+	t.Cleanup(s.Close)
+	return s, store
+}
+
+func TestService_ExportAssemblesArchive(t *testing.T) {
+	users := newAccounts(jane)
+	notes := NewSource("notes",
+		func(ctx context.Context, userID identity.UserID) (any, error) { return []string{"first note"}, nil },
+		func(ctx context.Context, userID identity.UserID) error { return nil })
+	empty := NewSource("empty",
+		func(ctx context.Context, userID identity.UserID) (any, error) { return nil, nil },
+		func(ctx context.Context, userID identity.UserID) error { return nil })
+	s, store := newTestService(t, Options{}, users, notes, empty)
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "admin"})
+
+	export, err := s.StartExport(ctx, jane)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", export.RequestedBy)
+	require.Eventually(t, func() bool {
+		export, err = s.GetExport(ctx, export.ID)
+		return err == nil && export.Done()
+	}, time.Second, time.Millisecond)
+
+	require.Equal(t, ExportSucceeded, export.Status, export.Error)
+	link, err := s.ExportURL(ctx, export)
+	require.NoError(t, err)
+	assert.Equal(t, "https://files.example.com/privacy/exports/"+export.ID+".zip", link)
+
+	data := store.Object("privacy/exports/" + export.ID + ".zip")
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[f.Name] = string(content)
+	}
+	assert.Len(t, files, 3, "sources without data are left out")
+	assert.JSONEq(t, `["first note"]`, files["notes.json"])
+	assert.JSONEq(t, `{"id":"00000000-0000-0000-0000-000000000001","record":"record"}`, files["accounts.json"])
+	assert.Contains(t, files["manifest.json"], `"sources": [
+    "notes",
+    "accounts"
+  ]`)
+
+	_, err = s.StartExport(ctx, john)
+	assert.ErrorIs(t, err, errUserNotFound)
+	_, err = s.GetExport(ctx, "missing")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+}
+
+func TestService_ExportsExpire(t *testing.T) {
+	users := newAccounts(jane)
+	s, store := newTestService(t, Options{ExportTTL: time.Hour}, users)
+	ctx := context.Background()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	export, err := s.StartExport(ctx, jane)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		export, _ = s.GetExport(ctx, export.ID)
+		return export.Done()
+	}, time.Second, time.Millisecond)
+	require.NotNil(t, store.Object("privacy/exports/"+export.ID+".zip"))
+
+	now = now.Add(2 * time.Hour)
+	_, err = s.Sweep(ctx)
+	require.NoError(t, err)
+
+	_, err = s.GetExport(ctx, export.ID)
+	assert.ErrorIs(t, err, ErrExportNotFound)
+	assert.Nil(t, store.Object("privacy/exports/"+export.ID+".zip"))
+}
+
+func TestService_ExportUnavailableWithoutStore(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	users := newAccounts(jane)
+	s := NewService(NewRegistry(), users.lookup, &memDeletions{byID: map[string]Deletion{}}, nil, Options{}, log)
+
+	_, err = s.StartExport(context.Background(), jane)
+	assert.ErrorIs(t, err, ErrExportUnavailable)
+}
+
+func TestService_DeletionErasesAfterGrace(t *testing.T) {
+	users := newAccounts(jane, john)
+	s, _ := newTestService(t, Options{Grace: 24 * time.Hour}, users)
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "admin"})
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	deletion, err := s.RequestDeletion(ctx, jane)
+	require.NoError(t, err)
+	assert.Equal(t, DeletionScheduled, deletion.Status)
+	assert.Equal(t, now.Add(24*time.Hour), deletion.PurgeAfter)
+	assert.Equal(t, []string{"accounts"}, deletion.Suspended)
+	assert.True(t, users.suspended[jane])
+	_, err = s.RequestDeletion(ctx, jane)
+	assert.ErrorIs(t, err, ErrDeletionPending)
+
+	// Within the grace period nothing is erased
+	completed, err := s.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, completed)
+	assert.True(t, users.has(jane))
+
+	now = now.Add(25 * time.Hour)
+	completed, err = s.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	assert.False(t, users.has(jane))
+	assert.True(t, users.has(john))
+
+	deletion, err = s.GetDeletion(ctx, deletion.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeletionCompleted, deletion.Status)
+	actions := make([]string, len(deletion.Trail))
+	for i, entry := range deletion.Trail {
+		actions[i] = entry.Action
+	}
+	assert.Equal(t, []string{ActionRequested, ActionSuspended, ActionErased, ActionCompleted}, actions)
+	assert.Equal(t, "admin", deletion.Trail[0].Actor)
+	assert.Empty(t, deletion.Trail[2].Actor, "the sweep erases on its own behalf")
+
+	_, err = s.CancelDeletion(ctx, deletion.ID)
+	assert.ErrorIs(t, err, ErrDeletionClosed)
+}
+
+func TestService_CancelDeletionResumes(t *testing.T) {
+	users := newAccounts(jane)
+	s, _ := newTestService(t, Options{Grace: time.Hour}, users)
+	ctx := context.Background()
+
+	deletion, err := s.RequestDeletion(ctx, jane)
+	require.NoError(t, err)
+	deletion, err = s.CancelDeletion(ctx, deletion.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, DeletionCancelled, deletion.Status)
+	assert.False(t, users.suspended[jane])
+	assert.Equal(t, ActionResumed, deletion.Trail[len(deletion.Trail)-1].Action)
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	completed, err := s.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, completed)
+	assert.True(t, users.has(jane))
+
+	_, err = s.CancelDeletion(ctx, "missing")
+	assert.ErrorIs(t, err, ErrDeletionNotFound)
+}
+
+func TestService_FailedErasureIsRetried(t *testing.T) {
+	users := newAccounts(jane)
+	users.eraseErr = errors.New("database unavailable")
+	s, _ := newTestService(t, Options{}, users)
+	ctx := context.Background()
+
+	deletion, err := s.RequestDeletion(ctx, jane)
+	require.NoError(t, err)
+	completed, err := s.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, completed)
+
+	deletion, err = s.GetDeletion(ctx, deletion.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeletionScheduled, deletion.Status)
+	last := deletion.Trail[len(deletion.Trail)-1]
+	assert.Equal(t, ActionEraseFailed, last.Action)
+	assert.Equal(t, "database unavailable", last.Error)
+
+	users.mu.Lock()
+	users.eraseErr = nil
+	users.mu.Unlock()
+	completed, err = s.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	assert.False(t, users.has(jane))
+}
+
+func TestRegistry_RejectsDuplicates(t *testing.T) {
+	registry := NewRegistry()
+	source := NewSource("notes", nil, nil)
+	require.NoError(t, registry.Register(source))
+	assert.Error(t, registry.Register(NewSource("notes", nil, nil)))
+	assert.Error(t, registry.Register(NewSource("", nil, nil)))
+	assert.Equal(t, []Source{source}, registry.List())
+}
@@ -0,0 +1,281 @@
+// Package quota enforces daily and monthly usage allowances per subject
+// (an API key or tenant identifier).
+//
+// Quotas differ from rate limits: they bound the total number of requests
+// within a calendar period rather than the request rate, and their counters
+// are kept in a shared Store so every instance sees the same usage.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+)
+
+// ErrExceeded is returned by Consume when a subject has used up a quota.
+var ErrExceeded = apperrors.New(apperrors.KindRateLimited, "quota_exceeded", "quota exceeded").
+	Describe("The caller used up its daily or monthly request quota; retry after the Retry-After delay.")
+
+// Period is a quota accounting window.
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Monthly Period = "monthly"
+)
+
+// Periods lists the supported periods in evaluation order.
+var Periods = []Period{Daily, Monthly}
+
+// Limits holds the allowance per period. Zero means unlimited.
+type Limits struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+// For returns the limit for period p.
+func (l Limits) For(p Period) int64 {
+	if p == Monthly {
+		return l.Monthly
+	}
+	return l.Daily
+}
+
+// Store persists usage counters. Implementations must be safe for
+// concurrent use and expire counters at expiresAt.
+type Store interface {
+	// Increment adds delta to key and returns the new value. expiresAt is
+	// applied when the key is created; later increments keep the expiry.
+	Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error)
+
+	// Get returns the current value of key (0 if absent).
+	Get(ctx context.Context, key string) (int64, error)
+
+	// Delete removes the given keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// PeriodUsage describes consumption for one period.
+type PeriodUsage struct {
+	Period    Period    `json:"period"`
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// Usage is the quota state of a subject.
+type Usage struct {
+	Subject string        `json:"subject"`
+	Periods []PeriodUsage `json:"periods"`
+}
+
+// Tightest returns the limited period with the fewest remaining requests.
+// It reports false when no period is limited.
+func (u Usage) Tightest() (PeriodUsage, bool) {
+	var (
+		best  PeriodUsage
+		found bool
+	)
+	for _, p := range u.Periods {
+		if p.Limit == 0 {
+			continue
+		}
+		if !found || p.Remaining < best.Remaining {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// Service tracks and enforces quotas.
+type Service struct {
+	store     Store
+	defaults  Limits
+	mu        sync.RWMutex
+	overrides map[string]Limits
+	now       func() time.Time
+}
+
+// NewService creates a quota service.
+//
+// Parameters:
+//   - store: Counter storage shared by all instances
+//   - defaults: Limits applied to subjects without overrides
+//   - overrides: Per-subject limits
+//
+// Returns:
+//   - *Service: Quota service
+func NewService(store Store, defaults Limits, overrides map[string]Limits) *Service {
+	o := make(map[string]Limits, len(overrides))
+	for k, v := range overrides {
+		o[k] = v
+	}
+	return &Service{
+		store:     store,
+		defaults:  defaults,
+		overrides: o,
+		now:       time.Now,
+	}
+}
+
+// LimitsFor returns the limits that apply to subject.
+func (s *Service) LimitsFor(subject string) Limits {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if l, ok := s.overrides[subject]; ok {
+		return l
+	}
+	return s.defaults
+}
+
+// SetLimits overrides the limits for subject.
+func (s *Service) SetLimits(subject string, limits Limits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[subject] = limits
+}
+
+// Consume records one request for subject. When any period would exceed
+// its limit the request is not counted and ErrExceeded is returned along
+// with the current usage.
+func (s *Service) Consume(ctx context.Context, subject string) (Usage, error) {
+	limits := s.LimitsFor(subject)
+	now := s.now().UTC()
+	usage := Usage{Subject: subject}
+
+	var exceeded bool
+	counted := make([]counter, 0, len(Periods))
+	for _, p := range Periods {
+		key, resets := periodKey(subject, p, now)
+		limit := limits.For(p)
+
+		used, err := s.store.Increment(ctx, key, 1, resets)
+		if err != nil {
+			s.rollback(ctx, counted)
+			return Usage{}, fmt.Errorf("increment %s quota: %w", p, err)
+		}
+		counted = append(counted, counter{key, resets})
+		if limit > 0 && used > limit {
+			exceeded = true
+		}
+		usage.Periods = append(usage.Periods, newPeriodUsage(p, limit, used, resets))
+	}
+
+	if exceeded {
+		s.rollback(ctx, counted)
+		for i := range usage.Periods {
+			usage.Periods[i].Used--
+			usage.Periods[i].Remaining = remaining(usage.Periods[i].Limit, usage.Periods[i].Used)
+		}
+		return usage, ErrExceeded
+	}
+	return usage, nil
+}
+
+// Usage returns the current quota state of subject without consuming.
+func (s *Service) Usage(ctx context.Context, subject string) (Usage, error) {
+	limits := s.LimitsFor(subject)
+	now := s.now().UTC()
+	usage := Usage{Subject: subject}
+
+	for _, p := range Periods {
+		key, resets := periodKey(subject, p, now)
+		used, err := s.store.Get(ctx, key)
+		if err != nil {
+			return Usage{}, fmt.Errorf("read %s quota: %w", p, err)
+		}
+		usage.Periods = append(usage.Periods, newPeriodUsage(p, limits.For(p), used, resets))
+	}
+	return usage, nil
+}
+
+// Reset clears the current period counters of subject.
+func (s *Service) Reset(ctx context.Context, subject string) error {
+	now := s.now().UTC()
+	keys := make([]string, 0, len(Periods))
+	for _, p := range Periods {
+		key, _ := periodKey(subject, p, now)
+		keys = append(keys, key)
+	}
+	return s.store.Delete(ctx, keys...)
+}
+
+// counter is a period counter incremented for a request.
+type counter struct {
+	key    string
+	resets time.Time
+}
+
+// rollback undoes increments made for a request that was not admitted.
+// A counter that expired meanwhile is recreated with its period expiry
+// and brought back to zero rather than left negative.
+func (s *Service) rollback(ctx context.Context, keys []counter) {
+	for _, c := range keys {
+		if v, err := s.store.Increment(ctx, c.key, -1, c.resets); err == nil && v < 0 {
+			_, _ = s.store.Increment(ctx, c.key, -v, c.resets)
+		}
+	}
+}
+
+// ParseOverrides parses per-subject limits of the form
+// "subject=daily/monthly", e.g. "tenant-a=1000/20000". Either limit may be 0.
+func ParseOverrides(entries []string) (map[string]Limits, error) {
+	out := make(map[string]Limits, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		subject, spec, ok := strings.Cut(entry, "=")
+		daily, monthly, ok2 := strings.Cut(spec, "/")
+		if !ok || !ok2 || subject == "" {
+			return nil, fmt.Errorf("invalid quota override %q: want subject=daily/monthly", entry)
+		}
+		d, err := strconv.ParseInt(daily, 10, 64)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid daily limit in %q", entry)
+		}
+		m, err := strconv.ParseInt(monthly, 10, 64)
+		if err != nil || m < 0 {
+			return nil, fmt.Errorf("invalid monthly limit in %q", entry)
+		}
+		out[subject] = Limits{Daily: d, Monthly: m}
+	}
+	return out, nil
+}
+
+// periodKey returns the counter key for subject in the period containing
+// now, and the time the period ends.
+func periodKey(subject string, p Period, now time.Time) (string, time.Time) {
+	y, m, d := now.Date()
+	if p == Monthly {
+		return fmt.Sprintf("quota:%s:%s:%04d%02d", subject, p, y, m),
+			time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return fmt.Sprintf("quota:%s:%s:%04d%02d%02d", subject, p, y, m, d),
+		time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+func newPeriodUsage(p Period, limit, used int64, resets time.Time) PeriodUsage {
+	return PeriodUsage{
+		Period:    p,
+		Limit:     limit,
+		Used:      used,
+		Remaining: remaining(limit, used),
+		ResetsAt:  resets,
+	}
+}
+
+// remaining returns the allowance left, or -1 for unlimited periods.
+func remaining(limit, used int64) int64 {
+	if limit == 0 {
+		return -1
+	}
+	return max(limit-used, 0)
+}
@@ -0,0 +1,132 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ConsumeEnforcesDailyLimit(t *testing.T) {
+	svc := newTestService(Limits{Daily: 2, Monthly: 10}, nil)
+	ctx := context.Background()
+
+	_, err := svc.Consume(ctx, "key-1")
+	require.NoError(t, err)
+	usage, err := svc.Consume(ctx, "key-1")
+	require.NoError(t, err)
+
+	tightest, ok := usage.Tightest()
+	require.True(t, ok)
+	assert.Equal(t, Daily, tightest.Period)
+	assert.Equal(t, int64(0), tightest.Remaining)
+
+	usage, err = svc.Consume(ctx, "key-1")
+	assert.ErrorIs(t, err, ErrExceeded)
+	assert.Equal(t, int64(2), usage.Periods[0].Used, "rejected requests are not counted")
+
+	current, err := svc.Usage(ctx, "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), current.Periods[1].Used)
+}
+
+func TestService_PeriodsRollOver(t *testing.T) {
+	svc := newTestService(Limits{Daily: 1}, nil)
+	ctx := context.Background()
+	midnight := time.Now().UTC().Truncate(24 * time.Hour)
+	day := midnight.Add(23 * time.Hour)
+	svc.now = func() time.Time { return day }
+
+	usage, err := svc.Consume(ctx, "key-1")
+	require.NoError(t, err)
+	y, m, _ := midnight.Date()
+	assert.Equal(t, midnight.Add(24*time.Hour), usage.Periods[0].ResetsAt)
+	assert.Equal(t, time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC), usage.Periods[1].ResetsAt)
+
+	_, err = svc.Consume(ctx, "key-1")
+	assert.ErrorIs(t, err, ErrExceeded)
+
+	day = day.Add(2 * time.Hour)
+	_, err = svc.Consume(ctx, "key-1")
+	assert.NoError(t, err)
+}
+
+func TestService_OverridesAndReset(t *testing.T) {
+	svc := newTestService(Limits{Daily: 1}, map[string]Limits{"tenant-a": {Daily: 3}})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Consume(ctx, "tenant-a")
+		require.NoError(t, err)
+	}
+	_, err := svc.Consume(ctx, "tenant-a")
+	assert.ErrorIs(t, err, ErrExceeded)
+
+	require.NoError(t, svc.Reset(ctx, "tenant-a"))
+	_, err = svc.Consume(ctx, "tenant-a")
+	assert.NoError(t, err)
+
+	svc.SetLimits("tenant-b", Limits{})
+	for i := 0; i < 5; i++ {
+		_, err := svc.Consume(ctx, "tenant-b")
+		require.NoError(t, err, "zero limits are unlimited")
+	}
+}
+
+func TestService_RollbackOfExpiredCounter(t *testing.T) {
+	store := &expiringStore{Store: memory.NewCounterStore()}
+	svc := NewService(store, Limits{Daily: 1}, nil)
+	ctx := context.Background()
+
+	_, err := svc.Consume(ctx, "key-1")
+	require.NoError(t, err)
+	store.expireOnRollback = true
+	_, err = svc.Consume(ctx, "key-1")
+	require.ErrorIs(t, err, ErrExceeded)
+
+	current, err := svc.Usage(ctx, "key-1")
+	require.NoError(t, err)
+	for _, p := range current.Periods {
+		assert.Zero(t, p.Used, "%s counter is not left negative", p.Period)
+	}
+	for _, expiresAt := range store.rollbackExpiries {
+		assert.False(t, expiresAt.IsZero(), "recreated counters keep their period expiry")
+	}
+}
+
+// expiringStore drops counters just before they are decremented, as when
+// a period ends between a request's increment and its rollback.
+type expiringStore struct {
+	Store
+	expireOnRollback bool
+	rollbackExpiries []time.Time
+}
+
+func (s *expiringStore) Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	if delta < 0 && s.expireOnRollback {
+		_ = s.Store.Delete(ctx, key)
+		s.rollbackExpiries = append(s.rollbackExpiries, expiresAt)
+	}
+	return s.Store.Increment(ctx, key, delta, expiresAt)
+}
+
+func TestParseOverrides(t *testing.T) {
+	got, err := ParseOverrides([]string{"tenant-a=1000/20000", " key-1=0/5 ", ""})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Limits{
+		"tenant-a": {Daily: 1000, Monthly: 20000},
+		"key-1":    {Daily: 0, Monthly: 5},
+	}, got)
+
+	for _, bad := range []string{"tenant-a", "=1/2", "a=1", "a=x/2", "a=1/-2"} {
+		_, err := ParseOverrides([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
+
+func newTestService(defaults Limits, overrides map[string]Limits) *Service {
+	return NewService(memory.NewCounterStore(), defaults, overrides)
+}
@@ -0,0 +1,146 @@
+// Package ratelimit throttles request rates per subject with cost-weighted
+// fixed windows.
+//
+// Limits are declared per route group: each Rule covers a path prefix and
+// allows Limit cost units per Window. Requests consume their route's cost,
+// so expensive endpoints (exports, bulk writes) use up the allowance faster
+// than cheap reads. Counters live in a shared Store so every instance
+// enforces the same budget.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+)
+
+// ErrExceeded is returned by Allow when a request would exceed the limit.
+var ErrExceeded = apperrors.New(apperrors.KindRateLimited, "rate_limit_exceeded", "rate limit exceeded").
+	Describe("The caller sent more weighted requests than the route group allows per window; retry after the Retry-After delay.")
+
+// Store persists window counters. It is satisfied by the quota counter
+// stores; implementations must be safe for concurrent use and expire
+// counters at expiresAt.
+type Store interface {
+	// Increment adds delta to key and returns the new value. expiresAt is
+	// applied when the key is created; later increments keep the expiry.
+	Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error)
+}
+
+// Rule limits a route group.
+type Rule struct {
+	Name string `yaml:"name" validate:"required"`
+
+	// Prefix is the path of the group, matched on segment boundaries:
+	// "/api/v1/user" covers "/api/v1/user/7" but not "/api/v1/users".
+	Prefix string        `yaml:"prefix" validate:"required,startswith=/"`
+	Limit  int64         `yaml:"limit" validate:"required,min=1"`
+	Window time.Duration `yaml:"window" validate:"required,min=1s"`
+
+	// Cost is consumed by routes without an entry in Costs (default 1;
+	// 0 leaves them free).
+	Cost *int64 `yaml:"cost" validate:"omitempty,min=0"`
+
+	// Costs weights individual routes, keyed by "METHOD /route/template"
+	// (e.g. "POST /api/v1/users/bulk"); a method of * matches any method.
+	Costs map[string]int64 `yaml:"costs" validate:"dive,min=0"`
+}
+
+// Matches reports whether path falls under the rule prefix.
+func (r Rule) Matches(path string) bool {
+	rest, ok := strings.CutPrefix(path, r.Prefix)
+	return ok && (rest == "" || rest[0] == '/' || strings.HasSuffix(r.Prefix, "/"))
+}
+
+// CostOf returns the cost of a request to route (the matched route
+// template, or the path when unmatched).
+func (r Rule) CostOf(method, route string) int64 {
+	if cost, ok := r.Costs[method+" "+route]; ok {
+		return cost
+	}
+	if cost, ok := r.Costs["* "+route]; ok {
+		return cost
+	}
+	if r.Cost == nil {
+		return 1
+	}
+	return *r.Cost
+}
+
+// Result describes the window state after a request.
+type Result struct {
+	Rule      string    `json:"rule"`
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	Cost      int64     `json:"cost"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// Limiter enforces rules.
+type Limiter struct {
+	store Store
+	rules []Rule
+	now   func() time.Time
+}
+
+// New creates a limiter.
+//
+// Parameters:
+//   - store: Counter storage shared by all instances
+//   - rules: Route group limits; the first matching rule applies
+//
+// Returns:
+//   - *Limiter: Rate limiter
+func New(store Store, rules []Rule) *Limiter {
+	return &Limiter{
+		store: store,
+		rules: append([]Rule(nil), rules...),
+		now:   time.Now,
+	}
+}
+
+// Rule returns the first rule matching path.
+func (l *Limiter) Rule(path string) (Rule, bool) {
+	for _, rule := range l.rules {
+		if rule.Matches(path) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Allow consumes the request cost from the subject's window of rule. When
+// the window would exceed its limit the cost is not counted and
+// ErrExceeded is returned along with the current state.
+func (l *Limiter) Allow(ctx context.Context, rule Rule, subject, method, route string) (Result, error) {
+	cost := rule.CostOf(method, route)
+	now := l.now().UTC()
+	start := now.Truncate(rule.Window)
+	result := Result{
+		Rule:     rule.Name,
+		Limit:    rule.Limit,
+		Cost:     cost,
+		ResetsAt: start.Add(rule.Window),
+	}
+
+	key := "ratelimit:" + rule.Name + ":" + subject + ":" + strconv.FormatInt(start.Unix(), 10)
+	used, err := l.store.Increment(ctx, key, cost, result.ResetsAt)
+	if err != nil {
+		return Result{}, fmt.Errorf("increment %s rate limit: %w", rule.Name, err)
+	}
+
+	if used > rule.Limit {
+		// Keep the window expiry should the counter have expired meanwhile
+		if v, err := l.store.Increment(ctx, key, -cost, result.ResetsAt); err == nil && v < 0 {
+			_, _ = l.store.Increment(ctx, key, -v, result.ResetsAt)
+		}
+		result.Remaining = max(rule.Limit-(used-cost), 0)
+		return result, ErrExceeded
+	}
+	result.Remaining = rule.Limit - used
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRule = Rule{
+	Name:   "api",
+	Prefix: "/api/v1/",
+	Limit:  10,
+	Window: time.Minute,
+	Costs: map[string]int64{
+		"POST /api/v1/users/bulk": 5,
+		"* /api/v1/users/export":  8,
+	},
+}
+
+func TestRule_CostOf(t *testing.T) {
+	assert.Equal(t, int64(1), testRule.CostOf("GET", "/api/v1/users"))
+	assert.Equal(t, int64(5), testRule.CostOf("POST", "/api/v1/users/bulk"))
+	assert.Equal(t, int64(1), testRule.CostOf("GET", "/api/v1/users/bulk"))
+	assert.Equal(t, int64(8), testRule.CostOf("HEAD", "/api/v1/users/export"))
+
+	rule := testRule
+	two, zero := int64(2), int64(0)
+	rule.Cost = &two
+	assert.Equal(t, int64(2), rule.CostOf("GET", "/api/v1/users"))
+	rule.Cost = &zero
+	assert.Zero(t, rule.CostOf("GET", "/api/v1/users"), "a zero cost makes routes free")
+}
+
+func TestRule_Matches(t *testing.T) {
+	tests := []struct {
+		prefix, path string
+		want         bool
+	}{
+		{"/api/v1/user", "/api/v1/user", true},
+		{"/api/v1/user", "/api/v1/user/7", true},
+		{"/api/v1/user", "/api/v1/users", false},
+		{"/api/v1/", "/api/v1/users", true},
+		{"/api/v1/", "/api/v1", false},
+		{"/", "/health", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Rule{Prefix: tt.prefix}.Matches(tt.path), "%s %s", tt.prefix, tt.path)
+	}
+}
+
+func TestLimiter_AllowConsumesWeightedCost(t *testing.T) {
+	limiter := New(memory.NewCounterStore(), []Rule{testRule})
+	ctx := context.Background()
+
+	result, err := limiter.Allow(ctx, testRule, "key-1", "POST", "/api/v1/users/bulk")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), result.Remaining)
+	assert.Equal(t, int64(5), result.Cost)
+
+	result, err = limiter.Allow(ctx, testRule, "key-1", "GET", "/api/v1/users/export")
+	assert.ErrorIs(t, err, ErrExceeded)
+	assert.Equal(t, int64(5), result.Remaining, "rejected requests are not counted")
+
+	result, err = limiter.Allow(ctx, testRule, "key-1", "POST", "/api/v1/users/bulk")
+	require.NoError(t, err)
+	assert.Zero(t, result.Remaining)
+
+	_, err = limiter.Allow(ctx, testRule, "key-2", "GET", "/api/v1/users")
+	assert.NoError(t, err, "subjects have separate windows")
+}
+
+func TestLimiter_WindowsRollOver(t *testing.T) {
+	limiter := New(memory.NewCounterStore(), []Rule{testRule})
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Minute)
+	now := start.Add(30 * time.Second)
+	limiter.now = func() time.Time { return now }
+
+	result, err := limiter.Allow(ctx, testRule, "key-1", "GET", "/api/v1/users/export")
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(time.Minute), result.ResetsAt)
+
+	_, err = limiter.Allow(ctx, testRule, "key-1", "GET", "/api/v1/users/export")
+	assert.ErrorIs(t, err, ErrExceeded)
+
+	now = now.Add(time.Minute)
+	_, err = limiter.Allow(ctx, testRule, "key-1", "GET", "/api/v1/users/export")
+	assert.NoError(t, err)
+}
+
+func TestLimiter_RuleMatchesFirstPrefix(t *testing.T) {
+	limiter := New(memory.NewCounterStore(), []Rule{
+		{Name: "users", Prefix: "/api/v1/users", Limit: 1, Window: time.Second},
+		testRule,
+	})
+
+	rule, ok := limiter.Rule("/api/v1/users/42")
+	require.True(t, ok)
+	assert.Equal(t, "users", rule.Name)
+
+	rule, ok = limiter.Rule("/api/v1/usage")
+	require.True(t, ok)
+	assert.Equal(t, "api", rule.Name)
+
+	_, ok = limiter.Rule("/health")
+	assert.False(t, ok)
+}
@@ -0,0 +1,116 @@
+package reports
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/pdf"
+)
+
+// builtin holds the HTML report template shipped with the module.
+//
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+// Renderer renders report content. HTML reports of the "users" report use
+// users.html.tmpl, falling back to report.html.tmpl; templates are
+// executed with the Content and its Generated time. PDF reports are laid
+// out from the content directly.
+//
+// Files of the templates directory take precedence over the built-in
+// template of the same name.
+type Renderer struct {
+	fsys []fs.FS
+	now  func() time.Time
+}
+
+// NewRenderer creates the renderer of the templates of dir layered over
+// the built-in one; an empty dir uses the built-in template only.
+func NewRenderer(dir string) (*Renderer, error) {
+	embedded, _ := fs.Sub(builtin, "templates")
+	r := &Renderer{fsys: []fs.FS{embedded}, now: time.Now}
+	if dir == "" {
+		return r, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("report templates: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("report templates: %s is not a directory", dir)
+	}
+	r.fsys = append([]fs.FS{os.DirFS(dir)}, r.fsys...)
+	return r, nil
+}
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	if format == FormatPDF {
+		return "application/pdf"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// Render writes the content of the report named name to w in format.
+func (r *Renderer) Render(w io.Writer, name, format string, content Content) error {
+	switch format {
+	case FormatPDF:
+		return r.renderPDF(w, content)
+	case FormatHTML:
+		return r.renderHTML(w, name, content)
+	default:
+		return ErrFormatInvalid
+	}
+}
+
+// renderHTML executes the HTML template of the report.
+func (r *Renderer) renderHTML(w io.Writer, name string, content Content) error {
+	file, src, err := r.read(name+".html.tmpl", "report.html.tmpl")
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(file).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, struct {
+		Content
+		Generated time.Time
+	}{content, r.now()})
+}
+
+// renderPDF lays out content as a PDF document.
+func (r *Renderer) renderPDF(w io.Writer, content Content) error {
+	doc := pdf.New(pdf.A4, content.Title)
+	doc.Created = r.now()
+	for _, section := range content.Sections {
+		if section.Heading != "" {
+			doc.Heading(section.Heading)
+		}
+		for _, p := range section.Paragraphs {
+			doc.Paragraph(p)
+		}
+		if section.Table != nil {
+			doc.Table(section.Table.Header, section.Table.Rows)
+		}
+	}
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// read returns the first of files found in the template directories.
+func (r *Renderer) read(files ...string) (string, string, error) {
+	for _, file := range files {
+		for _, fsys := range r.fsys {
+			if data, err := fs.ReadFile(fsys, file); err == nil {
+				return file, string(data), nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("report template %s not found", files[0])
+}
@@ -0,0 +1,150 @@
+// Package reports renders templated reports to PDF or HTML as async
+// operations.
+//
+// Modules register Reports that build their Content (headings,
+// paragraphs and tables) from application data; the Service runs them in
+// the background, tracking the progress of each Operation, and stores the
+// rendered file in the object store, where clients download it through a
+// time-limited link:
+//
+//	op, err := service.Start(ctx, "users", reports.FormatPDF, nil)
+//	...
+//	op, err = service.Get(ctx, op.ID) // op.Progress, op.Status
+//	link, err := service.URL(ctx, op)
+package reports
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+)
+
+// Output formats.
+const (
+	FormatPDF  = "pdf"
+	FormatHTML = "html"
+)
+
+// Errors returned when starting and tracking operations.
+var (
+	ErrReportNotFound = apperrors.New(apperrors.KindNotFound, "report_not_found", "report not found")
+	ErrFormatInvalid  = apperrors.New(apperrors.KindInvalid, "report_format_invalid",
+		"report format must be pdf or html")
+	ErrOperationNotFound = apperrors.New(apperrors.KindNotFound, "report_operation_not_found",
+		"report operation not found")
+	ErrNotReady = apperrors.New(apperrors.KindConflict, "report_not_ready", "report not ready").
+			Describe("The report operation has not succeeded yet; poll it until its status is succeeded.")
+	ErrBusy = apperrors.New(apperrors.KindRateLimited, "report_operations_busy", "too many pending reports").
+		Describe("The report queue is at capacity; retry once pending reports completed.")
+)
+
+// Content is the laid-out data of a report.
+type Content struct {
+	Title    string
+	Sections []Section
+}
+
+// Section is a titled part of a report.
+type Section struct {
+	Heading    string
+	Paragraphs []string
+	Table      *Table
+}
+
+// Table is tabular report data.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Progress records the completed share of a build, from 0 to 1.
+type Progress func(done float64)
+
+// Report builds the content of a report.
+type Report interface {
+	// Name returns the unique report identifier used in routes.
+	Name() string
+
+	// Description is a one-line summary shown in report listings.
+	Description() string
+
+	// Build returns the content of the report for params, recording its
+	// progress. Invalid params fail with an apperrors.KindInvalid error.
+	Build(ctx context.Context, params map[string]string, progress Progress) (Content, error)
+}
+
+// funcReport adapts a function to the Report interface.
+type funcReport struct {
+	name        string
+	description string
+	build       func(ctx context.Context, params map[string]string, progress Progress) (Content, error)
+}
+
+// New creates a Report from a function.
+func New(name, description string, build func(ctx context.Context, params map[string]string, progress Progress) (Content, error)) Report {
+	return &funcReport{name: name, description: description, build: build}
+}
+
+func (r *funcReport) Name() string        { return r.name }
+func (r *funcReport) Description() string { return r.description }
+func (r *funcReport) Build(ctx context.Context, params map[string]string, progress Progress) (Content, error) {
+	return r.build(ctx, params, progress)
+}
+
+// Registry holds the reports contributed by application modules.
+type Registry struct {
+	mu      sync.Mutex
+	reports map[string]Report
+}
+
+// NewRegistry creates an empty report registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		reports: make(map[string]Report),
+	}
+}
+
+// Register adds reports to the registry.
+// It returns an error if a report name is empty or already registered.
+func (r *Registry) Register(reports ...Report) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, report := range reports {
+		name := report.Name()
+		if name == "" {
+			return fmt.Errorf("report name must not be empty")
+		}
+		if _, exists := r.reports[name]; exists {
+			return fmt.Errorf("report %q already registered", name)
+		}
+		r.reports[name] = report
+	}
+
+	return nil
+}
+
+// Get returns the report registered under name.
+func (r *Registry) Get(name string) (Report, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report, ok := r.reports[name]
+	return report, ok
+}
+
+// List returns the registered reports sorted by name.
+func (r *Registry) List() []Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]Report, 0, len(r.reports))
+	for _, report := range r.reports {
+		out = append(out, report)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
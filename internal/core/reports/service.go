@@ -0,0 +1,346 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/metering"
+	"github.com/luminosita/change-me/internal/core/objectstore"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/ids"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Status is the lifecycle state of an operation.
+type Status string
+
+// Operation states.
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// JobGenerate is the metered job of a succeeded operation.
+const JobGenerate = "reports.generate"
+
+// operation is the ID kind of operations.
+type operation struct{}
+
+func (operation) IDFormat() ids.Format { return ids.UUID }
+
+// OperationID identifies an operation.
+type OperationID = ids.ID[operation]
+
+// Operation is the generation of a report.
+type Operation struct {
+	ID     OperationID
+	Report string
+	Format string
+	Params map[string]string
+	Status Status
+
+	// Owner is the principal that started the operation, empty when
+	// anonymous; only the owner sees the operation
+	Owner string
+
+	// Progress is the completed share, from 0 to 1
+	Progress float64
+
+	// Error describes why a failed operation failed
+	Error string
+
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	CompletedAt time.Time
+
+	// key is the object of the rendered report once succeeded
+	key string
+}
+
+// Done reports whether the operation succeeded or failed.
+func (o Operation) Done() bool {
+	return o.Status == StatusSucceeded || o.Status == StatusFailed
+}
+
+// Options configures a Service.
+type Options struct {
+	// Concurrency bounds the reports generated at once (default 2)
+	Concurrency int
+
+	// MaxPending bounds the operations pending or running (default 100)
+	MaxPending int
+
+	// Retention is the number of operations kept; the outputs of older
+	// completed ones are deleted (default 1000)
+	Retention int
+
+	// LinkTTL is the validity of download links (default 15m)
+	LinkTTL time.Duration
+
+	IDs     ids.Generator         // Generates operation IDs (nil uses ids.Default)
+	Metrics prometheus.Registerer // Registers the generation metrics when set
+
+	// Jobs meters a JobGenerate job for the owner of every succeeded
+	// operation when set
+	Jobs *metering.Pipeline
+}
+
+// Service runs report operations in the background. Operations are
+// tracked in memory, so they are only visible on the instance that
+// started them and lost on restart; their outputs stay in the object
+// store.
+type Service struct {
+	registry *Registry
+	renderer *Renderer
+	store    objectstore.Store
+	opts     Options
+	log      *logger.Logger
+	now      func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	slots  chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	ops   map[OperationID]*Operation
+	order []OperationID
+
+	generated *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+// NewService creates a report service rendering the reports of registry
+// into store.
+func NewService(registry *Registry, renderer *Renderer, store objectstore.Store, opts Options, log *logger.Logger) *Service {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 2
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 100
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = 1000
+	}
+	if opts.LinkTTL <= 0 {
+		opts.LinkTTL = 15 * time.Minute
+	}
+	opts.IDs = ids.OrDefault(opts.IDs)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		registry: registry,
+		renderer: renderer,
+		store:    store,
+		opts:     opts,
+		log:      log,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+		slots:    make(chan struct{}, opts.Concurrency),
+		ops:      make(map[OperationID]*Operation),
+		generated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "reports_generated_total",
+			Help: "Report operations completed by report, format and outcome (succeeded, failed).",
+		}, []string{"report", "format", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "reports_generation_duration_seconds",
+			Help:    "Time from the start of a report operation to its completion.",
+			Buckets: []float64{0.1, 0.5, 1, 5, 15, 60, 300},
+		}, []string{"report", "format"}),
+	}
+	if opts.Metrics != nil {
+		opts.Metrics.MustRegister(s.generated, s.duration)
+	}
+	return s
+}
+
+// Reports returns the registered reports sorted by name.
+func (s *Service) Reports() []Report {
+	return s.registry.List()
+}
+
+// Start starts generating the report name in format and returns its
+// pending operation.
+func (s *Service) Start(ctx context.Context, name, format string, params map[string]string) (Operation, error) {
+	report, ok := s.registry.Get(name)
+	if !ok {
+		return Operation{}, ErrReportNotFound
+	}
+	if format != FormatPDF && format != FormatHTML {
+		return Operation{}, ErrFormatInvalid
+	}
+
+	s.mu.Lock()
+	active := 0
+	for _, op := range s.ops {
+		if !op.Done() {
+			active++
+		}
+	}
+	if active >= s.opts.MaxPending {
+		s.mu.Unlock()
+		return Operation{}, ErrBusy
+	}
+	op := &Operation{
+		ID:        ids.New[operation](s.opts.IDs),
+		Report:    name,
+		Format:    format,
+		Params:    params,
+		Status:    StatusPending,
+		Owner:     reqctx.Principal(ctx),
+		CreatedAt: s.now(),
+	}
+	s.ops[op.ID] = op
+	s.order = append(s.order, op.ID)
+	evicted := s.evict()
+	snapshot := *op
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	for _, key := range evicted {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.log.Warnw("report_output_delete_failed", "key", key, "error", err)
+		}
+	}
+
+	go s.run(op.ID, report)
+	return snapshot, nil
+}
+
+// Get returns the operation of id. Operations started by another
+// principal are reported as not found.
+func (s *Service) Get(ctx context.Context, id OperationID) (Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op, ok := s.ops[id]
+	if !ok || op.Owner != reqctx.Principal(ctx) {
+		return Operation{}, ErrOperationNotFound
+	}
+	return *op, nil
+}
+
+// URL returns the download link of the output of a succeeded operation.
+func (s *Service) URL(ctx context.Context, op Operation) (string, error) {
+	if op.Status != StatusSucceeded {
+		return "", ErrNotReady
+	}
+	return s.store.URL(ctx, op.key, s.opts.LinkTTL)
+}
+
+// LinkTTL returns the validity of download links.
+func (s *Service) LinkTTL() time.Duration {
+	return s.opts.LinkTTL
+}
+
+// Close cancels the running operations and waits for them to complete.
+func (s *Service) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// run generates the report of the operation of id once a slot is free.
+func (s *Service) run(id OperationID, report Report) {
+	defer s.wg.Done()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-s.ctx.Done():
+		s.fail(id, report, s.ctx.Err())
+		return
+	}
+
+	op := s.update(id, func(op *Operation) {
+		op.Status = StatusRunning
+		op.StartedAt = s.now()
+	})
+
+	// Building takes up to 90% of the progress, rendering and storing the rest
+	content, err := report.Build(s.ctx, op.Params, func(done float64) {
+		s.update(id, func(op *Operation) { op.Progress = min(max(done, 0), 1) * 0.9 })
+	})
+	if err != nil {
+		s.fail(id, report, err)
+		return
+	}
+
+	var out bytes.Buffer
+	if err := s.renderer.Render(&out, op.Report, op.Format, content); err != nil {
+		s.fail(id, report, err)
+		return
+	}
+	s.update(id, func(op *Operation) { op.Progress = 0.95 })
+
+	size := out.Len()
+	key := "reports/" + id.String() + "." + op.Format
+	if err := s.store.Put(s.ctx, key, &out, ContentType(op.Format)); err != nil {
+		s.fail(id, report, err)
+		return
+	}
+
+	op = s.update(id, func(op *Operation) {
+		op.Status = StatusSucceeded
+		op.Progress = 1
+		op.CompletedAt = s.now()
+		op.key = key
+	})
+	s.generated.WithLabelValues(op.Report, op.Format, string(StatusSucceeded)).Inc()
+	if s.opts.Jobs != nil && op.Owner != "" {
+		s.opts.Jobs.RecordJob(op.Owner, JobGenerate)
+	}
+	s.duration.WithLabelValues(op.Report, op.Format).Observe(op.CompletedAt.Sub(op.CreatedAt).Seconds())
+	s.log.Infow("report_generated", "id", id.String(), "report", op.Report, "format", op.Format,
+		"bytes", size, "duration_ms", op.CompletedAt.Sub(op.CreatedAt).Milliseconds())
+}
+
+// fail marks the operation of id failed. Only invalid request errors are
+// described to clients.
+func (s *Service) fail(id OperationID, report Report, err error) {
+	message := "report generation failed"
+	if apperrors.KindOf(err) == apperrors.KindInvalid {
+		message = err.Error()
+	}
+	op := s.update(id, func(op *Operation) {
+		op.Status = StatusFailed
+		op.Error = message
+		op.CompletedAt = s.now()
+	})
+	s.generated.WithLabelValues(op.Report, op.Format, string(StatusFailed)).Inc()
+	s.log.Errorw("report_failed", "id", id.String(), "report", report.Name(), "format", op.Format, "error", err)
+}
+
+// update applies fn to the operation of id and returns a copy of it.
+func (s *Service) update(id OperationID, fn func(op *Operation)) Operation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := s.ops[id]
+	fn(op)
+	return *op
+}
+
+// evict drops the oldest completed operations beyond the retention and
+// returns the keys of their outputs. Operations still running are kept.
+func (s *Service) evict() []string {
+	var keys []string
+	for i := 0; len(s.ops) > s.opts.Retention && i < len(s.order); {
+		op := s.ops[s.order[i]]
+		if !op.Done() {
+			i++
+			continue
+		}
+		if op.key != "" {
+			keys = append(keys, op.key)
+		}
+		delete(s.ops, op.ID)
+		s.order = append(s.order[:i], s.order[i+1:]...)
+	}
+	return keys
+}
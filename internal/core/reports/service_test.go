@@ -0,0 +1,216 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/metering"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options, reports ...Report) (*Service, *mocks.ObjectStore) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(reports...))
+
+	store := mocks.NewObjectStore()
+	s := NewService(registry, renderer, store, opts, log)
+	t.Cleanup(s.Close)
+	return s, store
+}
+
+// await polls the operation of id as the principal of ctx until it
+// completed.
+func await(ctx context.Context, t *testing.T, s *Service, id OperationID) Operation {
+	t.Helper()
+	var op Operation
+	require.Eventually(t, func() bool {
+		var err error
+		op, err = s.Get(ctx, id)
+		require.NoError(t, err)
+		return op.Done()
+	}, 2*time.Second, 5*time.Millisecond)
+	return op
+}
+
+var teamReport = New("team", "Team members", func(ctx context.Context, params map[string]string, progress Progress) (Content, error) {
+	progress(0.5)
+	return Content{
+		Title: "Team",
+		Sections: []Section{{
+			Heading:    "Members",
+			Paragraphs: []string{"Two members <as of today>."},
+			Table:      &Table{Header: []string{"Name"}, Rows: [][]string{{"Jane"}, {"John"}}},
+		}},
+	}, nil
+})
+
+func TestService_GeneratesReports(t *testing.T) {
+	s, store := newTestService(t, Options{}, teamReport)
+	ctx := context.Background()
+
+	for _, format := range []string{FormatPDF, FormatHTML} {
+		op, err := s.Start(ctx, "team", format, nil)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, op.Status)
+		_, err = s.URL(ctx, op)
+		assert.ErrorIs(t, err, ErrNotReady)
+
+		op = await(ctx, t, s, op.ID)
+		require.Equal(t, StatusSucceeded, op.Status, op.Error)
+		assert.Equal(t, 1.0, op.Progress)
+
+		link, err := s.URL(ctx, op)
+		require.NoError(t, err)
+		assert.Equal(t, "https://files.example.com/reports/"+op.ID.String()+"."+format, link)
+
+		output := store.Object("reports/" + op.ID.String() + "." + format)
+		if format == FormatPDF {
+			assert.True(t, bytes.HasPrefix(output, []byte("%PDF-")))
+		} else {
+			assert.Contains(t, string(output), "<td>Jane</td>")
+			assert.Contains(t, string(output), "&lt;as of today&gt;", "content is HTML escaped")
+		}
+	}
+}
+
+func TestService_StartValidates(t *testing.T) {
+	s, _ := newTestService(t, Options{}, teamReport)
+	ctx := context.Background()
+
+	_, err := s.Start(ctx, "missing", FormatPDF, nil)
+	assert.ErrorIs(t, err, ErrReportNotFound)
+	_, err = s.Start(ctx, "team", "docx", nil)
+	assert.ErrorIs(t, err, ErrFormatInvalid)
+	_, err = s.Get(ctx, OperationID{})
+	assert.ErrorIs(t, err, ErrOperationNotFound)
+}
+
+func TestService_FailedBuilds(t *testing.T) {
+	invalid := apperrors.New(apperrors.KindInvalid, "report_param_invalid", "active must be true or false")
+	s, _ := newTestService(t, Options{},
+		New("invalid", "", func(context.Context, map[string]string, Progress) (Content, error) { return Content{}, invalid }),
+		New("broken", "", func(context.Context, map[string]string, Progress) (Content, error) {
+			return Content{}, errors.New("connection refused")
+		}),
+	)
+	ctx := context.Background()
+
+	op, err := s.Start(ctx, "invalid", FormatPDF, nil)
+	require.NoError(t, err)
+	op = await(ctx, t, s, op.ID)
+	assert.Equal(t, StatusFailed, op.Status)
+	assert.Equal(t, "active must be true or false", op.Error)
+
+	op, err = s.Start(ctx, "broken", FormatPDF, nil)
+	require.NoError(t, err)
+	op = await(ctx, t, s, op.ID)
+	assert.Equal(t, "report generation failed", op.Error, "internal errors are not described")
+}
+
+func TestService_BoundsPendingOperations(t *testing.T) {
+	release := make(chan struct{})
+	blocking := New("slow", "", func(ctx context.Context, _ map[string]string, _ Progress) (Content, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return Content{Title: "Slow"}, nil
+	})
+	s, store := newTestService(t, Options{Concurrency: 1, MaxPending: 2, Retention: 2}, blocking)
+	ctx := context.Background()
+
+	first, err := s.Start(ctx, "slow", FormatHTML, nil)
+	require.NoError(t, err)
+	_, err = s.Start(ctx, "slow", FormatHTML, nil)
+	require.NoError(t, err)
+	_, err = s.Start(ctx, "slow", FormatHTML, nil)
+	assert.ErrorIs(t, err, ErrBusy)
+
+	close(release)
+	await(ctx, t, s, first.ID)
+	require.Eventually(t, func() bool { return store.Len() == 2 }, time.Second, 5*time.Millisecond)
+
+	// Starting a third operation evicts the oldest and deletes its output
+	third, err := s.Start(ctx, "slow", FormatHTML, nil)
+	require.NoError(t, err)
+	_, err = s.Get(ctx, first.ID)
+	assert.ErrorIs(t, err, ErrOperationNotFound)
+	assert.Nil(t, store.Object("reports/"+first.ID.String()+".html"))
+	await(ctx, t, s, third.ID)
+}
+
+func TestService_OperationsVisibleToOwner(t *testing.T) {
+	s, _ := newTestService(t, Options{}, teamReport)
+	jane := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "jane"})
+	john := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "john"})
+
+	op, err := s.Start(jane, "team", FormatHTML, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "jane", op.Owner)
+
+	_, err = s.Get(jane, op.ID)
+	require.NoError(t, err)
+	_, err = s.Get(john, op.ID)
+	assert.ErrorIs(t, err, ErrOperationNotFound)
+	_, err = s.Get(context.Background(), op.ID)
+	assert.ErrorIs(t, err, ErrOperationNotFound, "anonymous callers do not see owned operations")
+	await(jane, t, s, op.ID)
+}
+
+func TestRenderer_DirectoryOverridesBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, writeFile(dir, "team.html.tmpl", `<h1>{{.Title}} ({{len .Sections}})</h1>`))
+	renderer, err := NewRenderer(dir)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	require.NoError(t, renderer.Render(&out, "team", FormatHTML, Content{Title: "Team", Sections: []Section{{}}}))
+	assert.Equal(t, "<h1>Team (1)</h1>", out.String())
+
+	out.Reset()
+	require.NoError(t, renderer.Render(&out, "other", FormatHTML, Content{Title: "Other"}))
+	assert.Contains(t, out.String(), "<title>Other</title>")
+}
+
+func writeFile(dir, name, content string) error {
+	return os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+}
+
+func TestService_MetersOwnedJobs(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	agg := metering.NewAggregator()
+	pipeline := metering.NewPipeline(agg, metering.Options{}, log)
+	s, _ := newTestService(t, Options{Jobs: pipeline}, teamReport)
+	jane := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "jane"})
+
+	for _, ctx := range []context.Context{jane, context.Background()} {
+		op, err := s.Start(ctx, "team", FormatHTML, nil)
+		require.NoError(t, err)
+		op = await(ctx, t, s, op.ID)
+		require.Equal(t, StatusSucceeded, op.Status, op.Error)
+	}
+	s.Close()
+	require.NoError(t, pipeline.Close(context.Background()))
+
+	summary := agg.Summary("jane", metering.Daily)
+	assert.Equal(t, int64(1), summary.Totals[metering.KindJob])
+	require.Len(t, summary.Routes, 1)
+	assert.Equal(t, JobGenerate, summary.Routes[0].Route)
+	assert.Zero(t, agg.Summary("", metering.Daily).Totals[metering.KindJob], "anonymous operations are not metered")
+}
@@ -0,0 +1,88 @@
+// Package reqctx carries per-request metadata (caller identity, locale,
+// time zone, feature flags) through context.Context, so services can use it
+// without depending on the HTTP transport.
+//
+// The HTTP layer assembles a RequestContext in middleware; services read
+// it with the typed accessors:
+//
+//	loc := reqctx.Location(ctx)
+//	if reqctx.FeatureEnabled(ctx, "bulk_import") { ... }
+package reqctx
+
+import (
+	"context"
+	"time"
+)
+
+// Defaults reported when no RequestContext is attached.
+const (
+	DefaultLocale = "en"
+)
+
+// RequestContext is the transport-agnostic view of the current request.
+type RequestContext struct {
+	RequestID  string          // Correlation ID, echoed to the caller
+	Principal  string          // ID of the verified caller, never a credential; empty when anonymous
+	Tenant     string          // Tenant the request acts on, empty when single-tenant
+	Locale     string          // Preferred BCP 47 language tag, e.g. "en-US"
+	Location   *time.Location  // Caller's time zone for rendering dates
+	ReceivedAt time.Time       // When the request was received
+	Deadline   time.Time       // When the request must complete, zero when unbounded
+	Features   map[string]bool // Feature flags enabled for the request; read-only
+}
+
+type contextKey struct{}
+
+// With returns a copy of ctx carrying rc.
+func With(ctx context.Context, rc *RequestContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, rc)
+}
+
+// From returns the RequestContext carried by ctx. Outside a request (jobs,
+// tasks, tests) it returns an empty RequestContext, never nil.
+func From(ctx context.Context) *RequestContext {
+	if rc, ok := ctx.Value(contextKey{}).(*RequestContext); ok && rc != nil {
+		return rc
+	}
+	return &RequestContext{}
+}
+
+// RequestID returns the request correlation ID, or "".
+func RequestID(ctx context.Context) string { return From(ctx).RequestID }
+
+// Principal returns the ID of the verified caller, or "" when anonymous.
+func Principal(ctx context.Context) string { return From(ctx).Principal }
+
+// Tenant returns the request tenant, or "".
+func Tenant(ctx context.Context) string { return From(ctx).Tenant }
+
+// Locale returns the preferred locale, or DefaultLocale.
+func Locale(ctx context.Context) string {
+	if l := From(ctx).Locale; l != "" {
+		return l
+	}
+	return DefaultLocale
+}
+
+// Location returns the caller's time zone, or UTC.
+func Location(ctx context.Context) *time.Location {
+	if loc := From(ctx).Location; loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// Deadline returns the request deadline and whether one is set. The
+// context deadline takes precedence when it is earlier.
+func Deadline(ctx context.Context) (time.Time, bool) {
+	deadline := From(ctx).Deadline
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	return deadline, !deadline.IsZero()
+}
+
+// FeatureEnabled reports whether the named feature flag is on for the request.
+func FeatureEnabled(ctx context.Context, name string) bool {
+	return From(ctx).Features[name]
+}
@@ -0,0 +1,58 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessors_DefaultsWithoutRequestContext(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NotNil(t, From(ctx))
+	assert.Empty(t, RequestID(ctx))
+	assert.Empty(t, Principal(ctx))
+	assert.Equal(t, DefaultLocale, Locale(ctx))
+	assert.Equal(t, time.UTC, Location(ctx))
+	assert.False(t, FeatureEnabled(ctx, "anything"))
+	_, ok := Deadline(ctx)
+	assert.False(t, ok)
+}
+
+func TestAccessors_ReadAttachedRequestContext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	deadline := time.Now().Add(time.Minute)
+
+	ctx := With(context.Background(), &RequestContext{
+		RequestID: "req-1",
+		Principal: "key-1",
+		Tenant:    "acme",
+		Locale:    "de-DE",
+		Location:  berlin,
+		Deadline:  deadline,
+		Features:  map[string]bool{"bulk_import": true},
+	})
+
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Equal(t, "key-1", Principal(ctx))
+	assert.Equal(t, "acme", Tenant(ctx))
+	assert.Equal(t, "de-DE", Locale(ctx))
+	assert.Equal(t, berlin, Location(ctx))
+	assert.True(t, FeatureEnabled(ctx, "bulk_import"))
+	assert.False(t, FeatureEnabled(ctx, "new_search"))
+
+	got, ok := Deadline(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, deadline, got)
+
+	// An earlier context deadline wins
+	earlier, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	got, _ = Deadline(earlier)
+	assert.True(t, got.Before(deadline))
+}
@@ -0,0 +1,200 @@
+// Package routeflags turns individual endpoints off at runtime.
+//
+// Flags are declared per route as "METHOD /route/template" (a method of *
+// matches any method, a trailing /* matches every path below the prefix).
+// Disabled routes answer 404, hiding them, or 503, announcing an outage.
+// The flag file is polled and swapped atomically on change, so operators
+// can disable an endpoint without redeploying:
+//
+//	flags := routeflags.New(cfg.RouteFlags)
+//	flags.Watch(cfg.RouteFlagsConfigFile, 10*time.Second, config.LoadRouteFlags, log)
+//	defer flags.Close()
+//
+// Overrides set at runtime (see internal/core/toggles) take precedence
+// over the declared flags of the same route and are kept across reloads.
+package routeflags
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Flag enables or disables a route.
+type Flag struct {
+	// Route is "METHOD /route/template"; * matches any method and a
+	// trailing /* every path below the prefix
+	Route   string `yaml:"route" validate:"required,route_flag"`
+	Enabled bool   `yaml:"enabled"`
+
+	// Status answered while disabled: 404 (default) or 503
+	Status int `yaml:"status" validate:"omitempty,oneof=404 503"`
+}
+
+// routePattern matches "METHOD /route/template" routes.
+var routePattern = regexp.MustCompile(`^(\*|[A-Z]+) /\S*$`)
+
+// ValidRoute reports whether route is a valid flag route.
+func ValidRoute(route string) bool {
+	return routePattern.MatchString(route)
+}
+
+// StatusOf returns the status answered while the flag is disabled.
+func (f Flag) StatusOf() int {
+	if f.Status == 0 {
+		return http.StatusNotFound
+	}
+	return f.Status
+}
+
+// Loader reads and validates the flag file at path.
+type Loader func(path string) ([]Flag, error)
+
+// Flags holds the current flags. It is safe for concurrent use.
+type Flags struct {
+	current atomic.Pointer[table]
+
+	mu        sync.Mutex // Serializes rebuilding current
+	declared  []Flag
+	overrides []Flag
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// table indexes flags by method and route.
+type table struct {
+	exact    map[string]Flag
+	prefixes []Flag
+}
+
+// New creates flags from the initial declarations.
+func New(flags []Flag) *Flags {
+	f := &Flags{stop: make(chan struct{})}
+	f.Set(flags)
+	return f
+}
+
+// Set replaces the declared flags, keeping the overrides.
+func (f *Flags) Set(flags []Flag) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.declared = flags
+	f.rebuild()
+}
+
+// Override replaces the overrides, which take precedence over the declared
+// flag of the same route.
+func (f *Flags) Override(flags []Flag) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.overrides = flags
+	f.rebuild()
+}
+
+// Declared returns the declared flags, without the overrides.
+func (f *Flags) Declared() []Flag {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Flag(nil), f.declared...)
+}
+
+// rebuild indexes the overrides, then the declared flags they do not
+// replace, so overriding prefixes also match first; f.mu must be held.
+func (f *Flags) rebuild() {
+	t := &table{exact: make(map[string]Flag, len(f.declared)+len(f.overrides))}
+	overridden := make(map[string]bool, len(f.overrides))
+	for _, flag := range f.overrides {
+		overridden[flag.Route] = true
+		t.add(flag)
+	}
+	for _, flag := range f.declared {
+		if !overridden[flag.Route] {
+			t.add(flag)
+		}
+	}
+	f.current.Store(t)
+}
+
+// add indexes flag.
+func (t *table) add(flag Flag) {
+	if strings.HasSuffix(flag.Route, "/*") {
+		t.prefixes = append(t.prefixes, flag)
+		return
+	}
+	t.exact[flag.Route] = flag
+}
+
+// Disabled returns the flag disabling a request to route (the matched
+// route template, or the path when unmatched). Exact routes take
+// precedence over prefixes, and an explicit method over *.
+func (f *Flags) Disabled(method, route string) (Flag, bool) {
+	t := f.current.Load()
+	for _, key := range []string{method + " " + route, "* " + route} {
+		if flag, ok := t.exact[key]; ok {
+			return flag, !flag.Enabled
+		}
+	}
+	for _, flag := range t.prefixes {
+		m, prefix, _ := strings.Cut(strings.TrimSuffix(flag.Route, "*"), " ")
+		if (m == "*" || m == method) && (strings.HasPrefix(route, prefix) || route+"/" == prefix) {
+			return flag, !flag.Enabled
+		}
+	}
+	return Flag{}, false
+}
+
+// Watch reloads the flags with load whenever the file at path changes,
+// checking every interval until Close. Invalid files are logged and the
+// previous flags are kept.
+func (f *Flags) Watch(path string, interval time.Duration, load Loader, log *logger.Logger) {
+	modTime, size := stat(path)
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-ticker.C:
+			}
+
+			mt, sz := stat(path)
+			if mt.Equal(modTime) && sz == size {
+				continue
+			}
+			modTime, size = mt, sz
+			flags, err := load(path)
+			if err != nil {
+				log.Errorw("route_flags_reload_failed", "path", path, "error", err)
+				continue
+			}
+			f.Set(flags)
+			log.Infow("route_flags_reloaded", "path", path, "flags", len(flags))
+		}
+	}()
+}
+
+// Close stops watching the flag file.
+func (f *Flags) Close() {
+	f.once.Do(func() { close(f.stop) })
+	f.wg.Wait()
+}
+
+// stat returns the modification time and size of path, zero when missing.
+func stat(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
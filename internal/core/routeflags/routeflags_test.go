@@ -0,0 +1,104 @@
+package routeflags
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlags_Disabled(t *testing.T) {
+	flags := New([]Flag{
+		{Route: "* /api/v1/export/*", Status: http.StatusServiceUnavailable},
+		{Route: "GET /api/v1/export/status", Enabled: true},
+		{Route: "DELETE /api/v1/users/:id"},
+	})
+
+	flag, disabled := flags.Disabled(http.MethodPost, "/api/v1/export/csv")
+	assert.True(t, disabled)
+	assert.Equal(t, http.StatusServiceUnavailable, flag.StatusOf())
+
+	_, disabled = flags.Disabled(http.MethodPost, "/api/v1/export")
+	assert.True(t, disabled, "prefixes cover their root")
+
+	_, disabled = flags.Disabled(http.MethodGet, "/api/v1/export/status")
+	assert.False(t, disabled, "exact routes take precedence over prefixes")
+
+	flag, disabled = flags.Disabled(http.MethodDelete, "/api/v1/users/:id")
+	assert.True(t, disabled)
+	assert.Equal(t, http.StatusNotFound, flag.StatusOf())
+
+	_, disabled = flags.Disabled(http.MethodGet, "/api/v1/users/:id")
+	assert.False(t, disabled, "other methods are unaffected")
+}
+
+func TestFlags_OverridesTakePrecedence(t *testing.T) {
+	flags := New([]Flag{
+		{Route: "GET /api/v1/users", Enabled: true},
+		{Route: "* /api/v1/export/*"},
+	})
+	flags.Override([]Flag{
+		{Route: "GET /api/v1/users", Status: http.StatusServiceUnavailable},
+		{Route: "* /api/v1/export/*", Enabled: true},
+	})
+
+	flag, disabled := flags.Disabled(http.MethodGet, "/api/v1/users")
+	assert.True(t, disabled)
+	assert.Equal(t, http.StatusServiceUnavailable, flag.StatusOf())
+	_, disabled = flags.Disabled(http.MethodPost, "/api/v1/export/csv")
+	assert.False(t, disabled)
+
+	// Reloading the declared flags keeps the overrides
+	flags.Set([]Flag{{Route: "GET /api/v1/users", Enabled: true}, {Route: "DELETE /api/v1/users/:id"}})
+	_, disabled = flags.Disabled(http.MethodGet, "/api/v1/users")
+	assert.True(t, disabled)
+	_, disabled = flags.Disabled(http.MethodDelete, "/api/v1/users/:id")
+	assert.True(t, disabled)
+	assert.Len(t, flags.Declared(), 2)
+
+	flags.Override(nil)
+	_, disabled = flags.Disabled(http.MethodGet, "/api/v1/users")
+	assert.False(t, disabled)
+}
+
+func TestFlags_WatchReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routeflags.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+
+	reloads := make(chan []Flag, 1)
+	load := func(string) ([]Flag, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		flags := []Flag{{Route: "GET /" + string(data)}}
+		reloads <- flags
+		return flags, nil
+	}
+
+	flags := New(nil)
+	flags.Watch(path, 5*time.Millisecond, load, log)
+	defer flags.Close()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, reloads, "unchanged files are not reloaded")
+
+	require.NoError(t, os.WriteFile(path, []byte("v2-disabled"), 0o600))
+	select {
+	case <-reloads:
+	case <-time.After(time.Second):
+		t.Fatal("changed file was not reloaded")
+	}
+	require.Eventually(t, func() bool {
+		_, disabled := flags.Disabled(http.MethodGet, "/v2-disabled")
+		return disabled
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Source yields the documents an index is rebuilt from, stopping at the
+// first error of yield.
+type Source func(ctx context.Context, yield func(Document) error) error
+
+// ManagerOptions configures a Manager.
+type ManagerOptions struct {
+	// Retain is the number of index versions kept per alias, the current
+	// one included, so a bad rebuild can be rolled back by pointing the
+	// alias to the previous version (default 2)
+	Retain int
+
+	// BatchSize bounds the documents per Put while rebuilding (default 500)
+	BatchSize int
+}
+
+// Manager runs the lifecycle of aliased indexes: each rebuild fills a new
+// version named "<alias>-<timestamp>", then moves the alias to it and
+// deletes the versions beyond the retained ones.
+type Manager struct {
+	index Index
+	opts  ManagerOptions
+	now   func() time.Time
+}
+
+// NewManager creates a lifecycle manager over index.
+func NewManager(index Index, opts ManagerOptions) *Manager {
+	if opts.Retain <= 0 {
+		opts.Retain = 2
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	return &Manager{index: index, opts: opts, now: time.Now}
+}
+
+// Ensure rebuilds alias from source unless it already points to an index.
+// It reports whether it rebuilt.
+func (m *Manager) Ensure(ctx context.Context, alias string, mapping Mapping, source Source) (bool, error) {
+	_, err := m.index.ResolveAlias(ctx, alias)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, ErrIndexNotFound) {
+		return false, err
+	}
+	_, err = m.Rebuild(ctx, alias, mapping, source)
+	return err == nil, err
+}
+
+// Rebuild fills a new version of alias from source and moves the alias to
+// it. Searches keep using the previous version until the move; on failure
+// the new version is deleted. It returns the name of the new version.
+func (m *Manager) Rebuild(ctx context.Context, alias string, mapping Mapping, source Source) (string, error) {
+	now := m.now().UTC()
+	name := fmt.Sprintf("%s-%s%09d", alias, now.Format("20060102150405"), now.Nanosecond())
+	if err := m.index.CreateIndex(ctx, name, mapping); err != nil {
+		return "", fmt.Errorf("rebuild %s: %w", alias, err)
+	}
+
+	if err := m.fill(ctx, name, source); err != nil {
+		_ = m.index.DeleteIndex(context.WithoutCancel(ctx), name)
+		return "", fmt.Errorf("rebuild %s: %w", alias, err)
+	}
+	if err := m.index.SetAlias(ctx, alias, name); err != nil {
+		_ = m.index.DeleteIndex(context.WithoutCancel(ctx), name)
+		return "", fmt.Errorf("rebuild %s: %w", alias, err)
+	}
+
+	if err := m.prune(ctx, alias); err != nil {
+		return name, fmt.Errorf("rebuild %s: prune: %w", alias, err)
+	}
+	return name, nil
+}
+
+// fill puts the documents of source into index in batches.
+func (m *Manager) fill(ctx context.Context, index string, source Source) error {
+	batch := make([]Document, 0, m.opts.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := m.index.Put(ctx, index, batch...)
+		batch = batch[:0]
+		return err
+	}
+
+	err := source(ctx, func(doc Document) error {
+		batch = append(batch, doc)
+		if len(batch) < m.opts.BatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// prune deletes the oldest versions of alias beyond the retained ones,
+// never the one alias points to.
+func (m *Manager) prune(ctx context.Context, alias string) error {
+	versions, err := m.Versions(ctx, alias)
+	if err != nil {
+		return err
+	}
+	current, err := m.index.ResolveAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range versions[min(m.opts.Retain, len(versions)):] {
+		if name == current {
+			continue
+		}
+		errs = append(errs, m.index.DeleteIndex(ctx, name))
+	}
+	return errors.Join(errs...)
+}
+
+// Versions returns the index versions of alias, newest first.
+func (m *Manager) Versions(ctx context.Context, alias string) ([]string, error) {
+	names, err := m.index.Indexes(ctx, alias+"-")
+	if err != nil {
+		return nil, err
+	}
+	// Timestamps sort lexically; other indexes sharing the prefix are not
+	// versions of alias
+	versions := names[:0]
+	for _, name := range names {
+		if isVersion(strings.TrimPrefix(name, alias+"-")) {
+			versions = append(versions, name)
+		}
+	}
+	slices.Sort(versions)
+	slices.Reverse(versions)
+	return versions, nil
+}
+
+// isVersion reports whether suffix is a version timestamp.
+func isVersion(suffix string) bool {
+	if len(suffix) != len("20060102150405")+9 {
+		return false
+	}
+	for _, r := range suffix {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/internal/infrastructure/search/embedded"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mapping = search.Mapping{Text: []string{"name"}}
+
+// docs returns a source yielding a document per name.
+func docs(names ...string) search.Source {
+	return func(ctx context.Context, yield func(search.Document) error) error {
+		for _, name := range names {
+			if err := yield(search.Document{ID: name, Fields: map[string]string{"name": name}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestManager_RebuildSwapsAliasAndRetainsVersions(t *testing.T) {
+	ctx := context.Background()
+	idx := embedded.New()
+	manager := search.NewManager(idx, search.ManagerOptions{Retain: 2, BatchSize: 2})
+	require.NoError(t, idx.CreateIndex(ctx, "people-archive", mapping))
+
+	var versions []string
+	for _, name := range []string{"ann", "bob", "cid"} {
+		version, err := manager.Rebuild(ctx, "people", mapping, docs(name, "zed", "yan"))
+		require.NoError(t, err)
+		versions = append(versions, version)
+
+		page, err := idx.Search(ctx, "people", search.Query{Text: name})
+		require.NoError(t, err)
+		assert.Equal(t, 1, page.Total, "alias points to the latest version")
+	}
+
+	current, err := idx.ResolveAlias(ctx, "people")
+	require.NoError(t, err)
+	assert.Equal(t, versions[2], current)
+	retained, err := manager.Versions(ctx, "people")
+	require.NoError(t, err)
+	assert.Equal(t, []string{versions[2], versions[1]}, retained)
+
+	names, err := idx.Indexes(ctx, "people-")
+	require.NoError(t, err)
+	assert.Contains(t, names, "people-archive", "indexes other than versions are kept")
+}
+
+func TestManager_FailedRebuildKeepsCurrentVersion(t *testing.T) {
+	ctx := context.Background()
+	idx := embedded.New()
+	manager := search.NewManager(idx, search.ManagerOptions{})
+	rebuilt, err := manager.Ensure(ctx, "people", mapping, docs("ann"))
+	require.NoError(t, err)
+	assert.True(t, rebuilt)
+	current, err := idx.ResolveAlias(ctx, "people")
+	require.NoError(t, err)
+
+	failing := func(ctx context.Context, yield func(search.Document) error) error {
+		return errors.New("source down")
+	}
+	_, err = manager.Rebuild(ctx, "people", mapping, failing)
+	require.Error(t, err)
+
+	after, err := idx.ResolveAlias(ctx, "people")
+	require.NoError(t, err)
+	assert.Equal(t, current, after)
+	versions, err := manager.Versions(ctx, "people")
+	require.NoError(t, err)
+	assert.Equal(t, []string{current}, versions, "the failed version is deleted")
+
+	rebuilt, err = manager.Ensure(ctx, "people", mapping, failing)
+	require.NoError(t, err)
+	assert.False(t, rebuilt, "existing aliases are not rebuilt")
+}
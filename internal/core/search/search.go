@@ -0,0 +1,103 @@
+// Package search defines the full-text search contract modules index
+// their entities through, independent of the engine behind it.
+//
+// Modules index documents into named indexes and query them through an
+// alias managed by a Manager, so an index can be rebuilt from the source
+// of truth and swapped in without downtime:
+//
+//	manager := search.NewManager(index, search.ManagerOptions{})
+//	err := manager.Rebuild(ctx, "users", mapping, source)
+//	page, err := index.Search(ctx, "users", search.Query{Text: "jane", Highlight: true})
+//
+// Implementations live in internal/infrastructure/search: an Elasticsearch
+// and OpenSearch adapter, and an embedded in-process index for single
+// instances and tests.
+package search
+
+import (
+	"context"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// ErrIndexNotFound is returned for operations on an unknown index or alias.
+// Clients only meet it before the first build of an index, so it reports
+// the search as unavailable rather than the resource as missing.
+var ErrIndexNotFound = apperrors.New(apperrors.KindUnavailable, "search_index_not_found", "search index not available")
+
+// Highlight tags wrapping the matched terms of highlighted fragments.
+const (
+	HighlightPre  = "<em>"
+	HighlightPost = "</em>"
+)
+
+// Mapping declares the fields of an index. Text fields are analyzed for
+// full-text queries; keyword fields are matched exactly by filters.
+type Mapping struct {
+	Text    []string
+	Keyword []string
+}
+
+// Document is an indexed entity. Fields not declared by the mapping are
+// stored and returned but not searchable.
+type Document struct {
+	ID     string
+	Fields map[string]string
+}
+
+// Query selects and ranks documents.
+type Query struct {
+	// Text is matched against the text fields; empty matches every
+	// document
+	Text string
+
+	// Filters restricts the results to documents whose keyword fields
+	// equal the given values
+	Filters map[string]string
+
+	// Highlight returns the matched fragments of the text fields
+	Highlight bool
+
+	pagination.Params
+}
+
+// Hit is a matching document.
+type Hit struct {
+	ID     string            `json:"id"`
+	Score  float64           `json:"score"`
+	Fields map[string]string `json:"fields"`
+
+	// Highlights holds the fragments of each matched text field, with the
+	// matched terms between HighlightPre and HighlightPost
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// Index is a search engine holding named indexes. Names may be aliases
+// wherever an index is read or written.
+type Index interface {
+	// CreateIndex creates an empty index with mapping.
+	CreateIndex(ctx context.Context, name string, mapping Mapping) error
+
+	// DeleteIndex deletes an index and its documents.
+	DeleteIndex(ctx context.Context, name string) error
+
+	// Indexes returns the names of the indexes starting with prefix.
+	Indexes(ctx context.Context, prefix string) ([]string, error)
+
+	// ResolveAlias returns the index alias points to, or ErrIndexNotFound.
+	ResolveAlias(ctx context.Context, alias string) (string, error)
+
+	// SetAlias points alias to index, atomically moving it from the index
+	// it pointed to.
+	SetAlias(ctx context.Context, alias, index string) error
+
+	// Put adds or replaces documents; they are searchable on return.
+	Put(ctx context.Context, index string, docs ...Document) error
+
+	// Delete removes documents by ID; unknown IDs are ignored.
+	Delete(ctx context.Context, index string, ids ...string) error
+
+	// Search returns a page of the documents matching q, best first.
+	Search(ctx context.Context, index string, q Query) (pagination.Page[Hit], error)
+}
@@ -0,0 +1,57 @@
+// Package fixtures provides sample records shared by seeders and tests.
+// It has no test dependencies, so seeders can use it in production builds.
+package fixtures
+
+// SampleUser represents sample user data.
+type SampleUser struct {
+	ID       int
+	Email    string
+	Username string
+	FullName string
+	IsActive bool
+}
+
+// NewSampleUser creates sample user data with defaults.
+func NewSampleUser(opts ...func(*SampleUser)) *SampleUser {
+	user := &SampleUser{
+		ID:       1,
+		Email:    "test@example.com",
+		Username: "testuser",
+		FullName: "Test User",
+		IsActive: true,
+	}
+
+	for _, opt := range opts {
+		opt(user)
+	}
+
+	return user
+}
+
+// WithUserID sets the user ID.
+func WithUserID(id int) func(*SampleUser) {
+	return func(u *SampleUser) {
+		u.ID = id
+	}
+}
+
+// WithUserEmail sets the user email.
+func WithUserEmail(email string) func(*SampleUser) {
+	return func(u *SampleUser) {
+		u.Email = email
+	}
+}
+
+// WithUsername sets the username.
+func WithUsername(username string) func(*SampleUser) {
+	return func(u *SampleUser) {
+		u.Username = username
+	}
+}
+
+// WithUserActive sets the active status.
+func WithUserActive(active bool) func(*SampleUser) {
+	return func(u *SampleUser) {
+		u.IsActive = active
+	}
+}
@@ -0,0 +1,185 @@
+// Package seed provides an environment-gated framework for populating
+// data stores with sample records during development.
+//
+// Modules register Seeder implementations with a Registry. Seeders are
+// expected to build their records with the factories in seed/fixtures and
+// must be idempotent: running a seeder repeatedly leaves the store in the
+// same state as running it once.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// DefaultEnvironments lists the environments seeders run in when they do
+// not declare any explicitly.
+var DefaultEnvironments = []string{constants.EnvDevelopment, constants.EnvTest}
+
+// Seeder populates sample data for a single module.
+type Seeder interface {
+	// Name returns the unique seeder identifier used for selection and logging.
+	Name() string
+
+	// Environments lists the environments the seeder is allowed to run in.
+	// An empty list falls back to DefaultEnvironments.
+	Environments() []string
+
+	// Seed inserts sample data, skipping records that already exist.
+	Seed(ctx context.Context) error
+}
+
+// funcSeeder adapts a plain function to the Seeder interface.
+type funcSeeder struct {
+	name string
+	envs []string
+	fn   func(ctx context.Context) error
+}
+
+// New creates a Seeder from a function.
+//
+// Parameters:
+//   - name: Unique seeder name
+//   - envs: Environments the seeder may run in (empty for defaults)
+//   - fn: Idempotent seeding function
+//
+// Returns:
+//   - Seeder: Seeder wrapping fn
+func New(name string, envs []string, fn func(ctx context.Context) error) Seeder {
+	return &funcSeeder{name: name, envs: envs, fn: fn}
+}
+
+func (s *funcSeeder) Name() string                   { return s.name }
+func (s *funcSeeder) Environments() []string         { return s.envs }
+func (s *funcSeeder) Seed(ctx context.Context) error { return s.fn(ctx) }
+
+// Report summarizes a seeding run.
+type Report struct {
+	Applied []string
+	Skipped []string
+}
+
+// Registry holds the seeders registered by application modules.
+type Registry struct {
+	mu      sync.Mutex
+	seeders []Seeder
+	names   map[string]struct{}
+}
+
+// NewRegistry creates an empty seeder registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Register adds seeders to the registry. Seeders run in registration order.
+// It returns an error if a seeder name is empty or already registered.
+func (r *Registry) Register(seeders ...Seeder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range seeders {
+		name := s.Name()
+		if name == "" {
+			return fmt.Errorf("seeder name must not be empty")
+		}
+		if _, exists := r.names[name]; exists {
+			return fmt.Errorf("seeder %q already registered", name)
+		}
+		r.names[name] = struct{}{}
+		r.seeders = append(r.seeders, s)
+	}
+
+	return nil
+}
+
+// Names returns the registered seeder names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.seeders))
+	for _, s := range r.seeders {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// Run executes the registered seeders allowed in env.
+// When only is non-empty, just the named seeders are considered.
+// Seeding stops at the first failing seeder.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation
+//   - env: Current environment profile
+//   - log: Structured logger
+//   - only: Optional seeder names to restrict the run to
+//
+// Returns:
+//   - Report: Applied and skipped seeder names
+//   - error: Unknown seeder name or seeder failure
+func (r *Registry) Run(ctx context.Context, env string, log *logger.Logger, only ...string) (Report, error) {
+	r.mu.Lock()
+	seeders := make([]Seeder, len(r.seeders))
+	copy(seeders, r.seeders)
+
+	selected := make(map[string]struct{}, len(only))
+	for _, name := range only {
+		if _, exists := r.names[name]; !exists {
+			r.mu.Unlock()
+			return Report{}, fmt.Errorf("unknown seeder %q", name)
+		}
+		selected[name] = struct{}{}
+	}
+	r.mu.Unlock()
+
+	var report Report
+	for _, s := range seeders {
+		if len(selected) > 0 {
+			if _, ok := selected[s.Name()]; !ok {
+				continue
+			}
+		}
+
+		if !allowed(s, env) {
+			log.Infow("seed_skipped", "seeder", s.Name(), "environment", env)
+			report.Skipped = append(report.Skipped, s.Name())
+			continue
+		}
+
+		start := time.Now()
+		if err := s.Seed(ctx); err != nil {
+			log.Errorw("seed_failed", "seeder", s.Name(), "error", err)
+			return report, fmt.Errorf("seeder %q failed: %w", s.Name(), err)
+		}
+
+		log.Infow("seed_applied",
+			"seeder", s.Name(),
+			"environment", env,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+		report.Applied = append(report.Applied, s.Name())
+	}
+
+	return report, nil
+}
+
+// allowed reports whether the seeder may run in env.
+func allowed(s Seeder, env string) bool {
+	envs := s.Environments()
+	if len(envs) == 0 {
+		envs = DefaultEnvironments
+	}
+	for _, e := range envs {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RegisterRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	noop := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, r.Register(New("users", nil, noop)))
+	assert.Error(t, r.Register(New("users", nil, noop)))
+	assert.Error(t, r.Register(New("", nil, noop)))
+	assert.Equal(t, []string{"users"}, r.Names())
+}
+
+func TestRegistry_RunGatesByEnvironment(t *testing.T) {
+	r := NewRegistry()
+	var ran []string
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+
+	require.NoError(t, r.Register(
+		New("defaults", nil, record("defaults")),
+		New("staging-only", []string{constants.EnvStaging}, record("staging-only")),
+	))
+
+	report, err := r.Run(context.Background(), constants.EnvDevelopment, newTestLogger(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"defaults"}, ran)
+	assert.Equal(t, []string{"defaults"}, report.Applied)
+	assert.Equal(t, []string{"staging-only"}, report.Skipped)
+}
+
+func TestRegistry_RunSkipsProductionByDefault(t *testing.T) {
+	r := NewRegistry()
+	called := false
+	require.NoError(t, r.Register(New("users", nil, func(ctx context.Context) error {
+		called = true
+		return nil
+	})))
+
+	report, err := r.Run(context.Background(), constants.EnvProduction, newTestLogger(t))
+	require.NoError(t, err)
+
+	assert.False(t, called)
+	assert.Equal(t, []string{"users"}, report.Skipped)
+}
+
+func TestRegistry_RunSelectedSeeders(t *testing.T) {
+	r := NewRegistry()
+	var ran []string
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		require.NoError(t, r.Register(New(name, nil, func(ctx context.Context) error {
+			ran = append(ran, name)
+			return nil
+		})))
+	}
+
+	_, err := r.Run(context.Background(), constants.EnvDevelopment, newTestLogger(t), "c", "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, ran)
+
+	_, err = r.Run(context.Background(), constants.EnvDevelopment, newTestLogger(t), "missing")
+	assert.Error(t, err)
+}
+
+func TestRegistry_RunStopsOnFailure(t *testing.T) {
+	r := NewRegistry()
+	boom := errors.New("boom")
+	secondCalled := false
+
+	require.NoError(t, r.Register(
+		New("first", nil, func(ctx context.Context) error { return boom }),
+		New("second", nil, func(ctx context.Context) error {
+			secondCalled = true
+			return nil
+		}),
+	))
+
+	_, err := r.Run(context.Background(), constants.EnvDevelopment, newTestLogger(t))
+	assert.ErrorIs(t, err, boom)
+	assert.False(t, secondCalled)
+}
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
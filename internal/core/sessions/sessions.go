@@ -0,0 +1,290 @@
+// Package sessions issues refresh tokens for authenticated principals and
+// rotates them on every use.
+//
+// Tokens of one login form a family. Refreshing replaces the current
+// token of its family; presenting a token the family already rotated
+// away is treated as theft and revokes the whole family, logging out both
+// the attacker and the legitimate client. Only token digests are stored.
+//
+// Short-lived access tokens are minted by an optional AccessIssuer, for
+// example a JWT signer, each time a refresh token is issued.
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/clock"
+)
+
+// ErrInvalidToken is returned for unknown, malformed, expired or revoked
+// refresh tokens.
+var ErrInvalidToken = apperrors.New(apperrors.KindUnauthorized, "refresh_token_invalid", "invalid or expired refresh token").
+	Describe("The refresh token is unknown, expired or was revoked; authenticate again.")
+
+// ErrTokenReused is returned when a rotated refresh token is presented
+// again. The token family has been revoked.
+var ErrTokenReused = apperrors.New(apperrors.KindUnauthorized, "refresh_token_reused", "refresh token reuse detected").
+	Describe("The refresh token was already used; all tokens issued with it were revoked.")
+
+// MaxUsed is the number of rotated token digests a family keeps for reuse
+// detection. Older rotated tokens are rejected as invalid without revoking
+// the family, so families of unlimited age stay bounded.
+const MaxUsed = 16
+
+// Family is the stored state of the refresh tokens of one login.
+type Family struct {
+	ID         string
+	Principal  string
+	Current    string   // Digest of the current token
+	Used       []string // Digests of the last MaxUsed rotated tokens, oldest first
+	Generation int      // Number of rotations of the family
+	CreatedAt  time.Time
+	ExpiresAt  time.Time // Expiry of the current token
+}
+
+// Store persists token families. Implementations must make Update atomic
+// so concurrent refreshes of one token cannot both succeed.
+type Store interface {
+	// Create stores a new family.
+	Create(ctx context.Context, family *Family) error
+
+	// Update applies fn to the family id and saves it unless fn fails. It
+	// returns ErrInvalidToken when there is no such family.
+	Update(ctx context.Context, id string, fn func(*Family) error) error
+
+	// Delete revokes the family id; deleting an unknown family is a no-op.
+	Delete(ctx context.Context, id string) error
+
+	// DeletePrincipal revokes every family of principal and returns how
+	// many were revoked.
+	DeletePrincipal(ctx context.Context, principal string) (int, error)
+}
+
+// AccessIssuer mints access tokens for a principal.
+type AccessIssuer interface {
+	IssueAccess(ctx context.Context, principal string) (token string, expiresAt time.Time, err error)
+}
+
+// Options configures a Service.
+type Options struct {
+	TTL    time.Duration // Lifetime of a refresh token, renewed on rotation
+	MaxAge time.Duration // Lifetime of a family regardless of rotation (0 = unlimited)
+	Access AccessIssuer  // Mints access tokens (nil issues refresh tokens only)
+	Clock  clock.Clock   // Tells token expiry (nil uses the system clock)
+}
+
+// Tokens are the credentials returned on issuance and refresh.
+type Tokens struct {
+	Principal        string
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	AccessToken      string // Empty without an AccessIssuer
+	AccessExpiresAt  time.Time
+}
+
+// Service implements the refresh token use cases.
+type Service struct {
+	store Store
+	opts  Options
+	now   func() time.Time
+}
+
+// NewService creates a session service over store.
+func NewService(store Store, opts Options) *Service {
+	return &Service{
+		store: store,
+		opts:  opts,
+		now:   clock.OrReal(opts.Clock).Now,
+	}
+}
+
+// Issue starts a token family for principal, whose credentials the
+// caller has verified.
+func (s *Service) Issue(ctx context.Context, principal string) (*Tokens, error) {
+	id, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	token, digest, err := newToken(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	family := &Family{
+		ID:        id,
+		Principal: principal,
+		Current:   digest,
+		CreatedAt: now,
+		ExpiresAt: s.expiry(now, now),
+	}
+	if err := s.store.Create(ctx, family); err != nil {
+		return nil, err
+	}
+	return s.tokens(ctx, principal, token, family.ExpiresAt)
+}
+
+// Refresh rotates refreshToken, returning its successor. Presenting one of
+// the last MaxUsed tokens the family rotated away revokes it and returns
+// ErrTokenReused.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	id, ok := familyID(refreshToken)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	next, nextDigest, err := newToken(id)
+	if err != nil {
+		return nil, err
+	}
+
+	presented := digest(refreshToken)
+	var principal string
+	var expiresAt time.Time
+	err = s.store.Update(ctx, id, func(f *Family) error {
+		now := s.now()
+		switch {
+		case slices.Contains(f.Used, presented):
+			return ErrTokenReused
+		case f.Current != presented || !now.Before(f.ExpiresAt):
+			return ErrInvalidToken
+		}
+		f.Used = append(f.Used, f.Current)
+		if len(f.Used) > MaxUsed {
+			f.Used = slices.Delete(f.Used, 0, len(f.Used)-MaxUsed)
+		}
+		f.Generation++
+		f.Current = nextDigest
+		f.ExpiresAt = s.expiry(f.CreatedAt, now)
+		principal, expiresAt = f.Principal, f.ExpiresAt
+		return nil
+	})
+	if errors.Is(err, ErrTokenReused) {
+		if revokeErr := s.store.Delete(ctx, id); revokeErr != nil {
+			return nil, errors.Join(err, revokeErr)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.tokens(ctx, principal, next, expiresAt)
+}
+
+// Revoke ends the family of refreshToken (logout). refreshToken must be
+// the current or a recently rotated token of the family; tokens of unknown families
+// are ignored so logging out is idempotent.
+func (s *Service) Revoke(ctx context.Context, refreshToken string) error {
+	id, ok := familyID(refreshToken)
+	if !ok {
+		return ErrInvalidToken
+	}
+
+	presented := digest(refreshToken)
+	found := false
+	err := s.store.Update(ctx, id, func(f *Family) error {
+		found = true
+		if f.Current != presented && !slices.Contains(f.Used, presented) {
+			return ErrInvalidToken
+		}
+		return nil
+	})
+	if !found && errors.Is(err, ErrInvalidToken) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// RevokeAll ends every family of the principal of refreshToken (logout
+// everywhere) and returns how many were revoked. refreshToken must be the
+// current token of a live family.
+func (s *Service) RevokeAll(ctx context.Context, refreshToken string) (int, error) {
+	id, ok := familyID(refreshToken)
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+
+	presented := digest(refreshToken)
+	var principal string
+	err := s.store.Update(ctx, id, func(f *Family) error {
+		if f.Current != presented || !s.now().Before(f.ExpiresAt) {
+			return ErrInvalidToken
+		}
+		principal = f.Principal
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return s.store.DeletePrincipal(ctx, principal)
+}
+
+// expiry returns the expiry of a token issued at now in a family created
+// at created.
+func (s *Service) expiry(created, now time.Time) time.Time {
+	expiresAt := now.Add(s.opts.TTL)
+	if s.opts.MaxAge > 0 {
+		if limit := created.Add(s.opts.MaxAge); limit.Before(expiresAt) {
+			return limit
+		}
+	}
+	return expiresAt
+}
+
+// tokens completes refresh with an access token when an issuer is set.
+func (s *Service) tokens(ctx context.Context, principal, refresh string, expiresAt time.Time) (*Tokens, error) {
+	t := &Tokens{Principal: principal, RefreshToken: refresh, RefreshExpiresAt: expiresAt}
+	if s.opts.Access == nil {
+		return t, nil
+	}
+	access, accessExpiresAt, err := s.opts.Access.IssueAccess(ctx, principal)
+	if err != nil {
+		return nil, fmt.Errorf("sessions: issue access token: %w", err)
+	}
+	t.AccessToken, t.AccessExpiresAt = access, accessExpiresAt
+	return t, nil
+}
+
+// newToken returns a token of family id formatted as <id>.<secret> and
+// its digest.
+func newToken(id string) (string, string, error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	token := id + "." + secret
+	return token, digest(token), nil
+}
+
+// familyID returns the family of a token.
+func familyID(token string) (string, bool) {
+	id, secret, ok := strings.Cut(token, ".")
+	return id, ok && id != "" && secret != ""
+}
+
+// digest returns the stored form of a token.
+func digest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString returns n random bytes, base64url encoded.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("sessions: generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
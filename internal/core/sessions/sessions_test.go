@@ -0,0 +1,157 @@
+package sessions_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RefreshRotates(t *testing.T) {
+	ctx := context.Background()
+	service := sessions.NewService(memory.NewSessionStore(), sessions.Options{TTL: time.Hour})
+
+	issued, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	assert.Empty(t, issued.AccessToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), issued.RefreshExpiresAt, time.Minute)
+
+	refreshed, err := service.Refresh(ctx, issued.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, "jane", refreshed.Principal)
+	assert.NotEqual(t, issued.RefreshToken, refreshed.RefreshToken)
+
+	_, err = service.Refresh(ctx, refreshed.RefreshToken+"x")
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken)
+	_, err = service.Refresh(ctx, "malformed")
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken)
+}
+
+func TestService_ReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	service := sessions.NewService(memory.NewSessionStore(), sessions.Options{TTL: time.Hour})
+
+	issued, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	other, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	refreshed, err := service.Refresh(ctx, issued.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = service.Refresh(ctx, issued.RefreshToken)
+	assert.ErrorIs(t, err, sessions.ErrTokenReused)
+	_, err = service.Refresh(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken, "the successor is revoked with its family")
+
+	_, err = service.Refresh(ctx, other.RefreshToken)
+	assert.NoError(t, err, "other logins are unaffected")
+}
+
+func TestService_Revoke(t *testing.T) {
+	ctx := context.Background()
+	service := sessions.NewService(memory.NewSessionStore(), sessions.Options{TTL: time.Hour})
+
+	first, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	second, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	third, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	john, err := service.Issue(ctx, "john")
+	require.NoError(t, err)
+
+	require.NoError(t, service.Revoke(ctx, first.RefreshToken))
+	require.NoError(t, service.Revoke(ctx, first.RefreshToken), "logout is idempotent")
+	_, err = service.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken)
+
+	_, err = service.RevokeAll(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken, "a revoked token cannot log out other sessions")
+	_, err = service.RevokeAll(ctx, "malformed")
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken)
+
+	revoked, err := service.RevokeAll(ctx, second.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+	for _, tokens := range []*sessions.Tokens{second, third} {
+		_, err = service.Refresh(ctx, tokens.RefreshToken)
+		assert.ErrorIs(t, err, sessions.ErrInvalidToken)
+	}
+	_, err = service.Refresh(ctx, john.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestService_RevokeRequiresTheSecret(t *testing.T) {
+	ctx := context.Background()
+	service := sessions.NewService(memory.NewSessionStore(), sessions.Options{TTL: time.Hour})
+
+	issued, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	id, _, _ := strings.Cut(issued.RefreshToken, ".")
+
+	assert.ErrorIs(t, service.Revoke(ctx, id+".x"), sessions.ErrInvalidToken)
+	_, err = service.Refresh(ctx, issued.RefreshToken)
+	assert.NoError(t, err, "a forged secret leaves the family live")
+}
+
+func TestService_MaxAgeAndAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	access := accessFunc(func(_ context.Context, principal string) (string, time.Time, error) {
+		return "access-" + principal, time.Now().Add(time.Minute), nil
+	})
+	service := sessions.NewService(memory.NewSessionStore(), sessions.Options{TTL: time.Hour, MaxAge: time.Minute, Access: access})
+
+	issued, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	assert.Equal(t, "access-jane", issued.AccessToken)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), issued.RefreshExpiresAt, 5*time.Second, "capped by MaxAge")
+
+	failing := accessFunc(func(context.Context, string) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("signer unavailable")
+	})
+	service = sessions.NewService(memory.NewSessionStore(), sessions.Options{TTL: time.Hour, Access: failing})
+	_, err = service.Issue(ctx, "jane")
+	assert.ErrorContains(t, err, "signer unavailable")
+}
+
+type accessFunc func(ctx context.Context, principal string) (string, time.Time, error)
+
+func (f accessFunc) IssueAccess(ctx context.Context, principal string) (string, time.Time, error) {
+	return f(ctx, principal)
+}
+
+func TestService_UsedTokensAreBounded(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewSessionStore()
+	service := sessions.NewService(store, sessions.Options{TTL: time.Hour})
+
+	issued, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	rotated := []string{issued.RefreshToken}
+	current := issued.RefreshToken
+	for range sessions.MaxUsed + 4 {
+		refreshed, err := service.Refresh(ctx, current)
+		require.NoError(t, err)
+		current = refreshed.RefreshToken
+		rotated = append(rotated, current)
+	}
+	id, _, _ := strings.Cut(current, ".")
+	require.NoError(t, store.Update(ctx, id, func(f *sessions.Family) error {
+		assert.Len(t, f.Used, sessions.MaxUsed)
+		assert.Equal(t, sessions.MaxUsed+4, f.Generation)
+		return nil
+	}))
+
+	_, err = service.Refresh(ctx, rotated[0])
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken, "tokens beyond the window are only rejected")
+	_, err = service.Refresh(ctx, rotated[len(rotated)-2])
+	assert.ErrorIs(t, err, sessions.ErrTokenReused)
+	_, err = service.Refresh(ctx, current)
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken, "reuse revoked the family")
+}
@@ -0,0 +1,368 @@
+// Package slo tracks service level objectives of routes and alerts when
+// their error budgets burn too fast.
+//
+// An Objective covers the requests below a path prefix with an
+// availability target (share of requests without a server error) and/or a
+// latency target (share of requests faster than a threshold). Requests are
+// counted in one-minute buckets over the rolling objective window. The
+// evaluation loop publishes compliance, remaining budget and burn rates as
+// metrics and raises multiwindow burn rate alerts from the SRE workbook:
+// a fast burn spends 2% of a 30-day budget in an hour, a slow burn 5% in
+// six hours.
+package slo
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultWindow is the compliance window of objectives without one.
+const DefaultWindow = 30 * 24 * time.Hour
+
+// DefaultInterval is how often objectives are evaluated.
+const DefaultInterval = 30 * time.Second
+
+// SLIs measured by objectives.
+const (
+	SLIAvailability = "availability"
+	SLILatency      = "latency"
+)
+
+// Alert severities.
+const (
+	SeverityFast = "fast" // Page: the budget is gone within days
+	SeveritySlow = "slow" // Ticket: the budget is gone before the window ends
+)
+
+// burnAlert is a multiwindow burn rate alert: it fires while both the long
+// and the short window burn faster than rate.
+type burnAlert struct {
+	severity    string
+	long, short time.Duration
+	rate        float64
+}
+
+// burnAlerts are tried in order; the first firing alert wins.
+var burnAlerts = []burnAlert{
+	{severity: SeverityFast, long: time.Hour, short: 5 * time.Minute, rate: 14.4},
+	{severity: SeveritySlow, long: 6 * time.Hour, short: 30 * time.Minute, rate: 6},
+}
+
+// Objective declares the targets of a route group.
+type Objective struct {
+	Name    string   `yaml:"name" validate:"required"`
+	Prefix  string   `yaml:"prefix" validate:"required,startswith=/"`
+	Methods []string `yaml:"methods"` // Methods covered (default all)
+
+	// Availability is the target share of requests answered without a 5xx
+	// status (e.g. 0.999); zero disables the SLI
+	Availability float64 `yaml:"availability" validate:"min=0,lt=1"`
+
+	// LatencyTarget is the target share of requests served within Latency
+	// (e.g. 0.99); zero disables the SLI
+	Latency       time.Duration `yaml:"latency" validate:"required_with=LatencyTarget,min=0"`
+	LatencyTarget float64       `yaml:"latency_target" validate:"min=0,lt=1"`
+
+	// Window is the rolling compliance window (default 30 days)
+	Window time.Duration `yaml:"window" validate:"omitempty,min=6h"`
+}
+
+// Matches reports whether a request falls under the objective.
+func (o Objective) Matches(method, path string) bool {
+	return strings.HasPrefix(path, o.Prefix) && (len(o.Methods) == 0 || slices.Contains(o.Methods, method))
+}
+
+// Status is the evaluated state of one SLI of an objective.
+type Status struct {
+	Objective  string             `json:"objective"`
+	SLI        string             `json:"sli"`
+	Target     float64            `json:"target"`
+	Compliance float64            `json:"compliance"`
+	Remaining  float64            `json:"budget_remaining"`
+	BurnRates  map[string]float64 `json:"burn_rates"`
+	Alert      string             `json:"alert,omitempty"` // Firing severity
+}
+
+// Alert reports an SLI starting or stopping to burn its budget too fast.
+type Alert struct {
+	Status
+	Resolved bool
+}
+
+// Options configures a Tracker.
+type Options struct {
+	Interval time.Duration         // Evaluation interval (default 30s)
+	Metrics  prometheus.Registerer // Registers the SLO gauges when set
+	Notify   func(Alert)           // Called on alert changes besides logging
+}
+
+// Tracker records requests against objectives. It is safe for concurrent
+// use.
+type Tracker struct {
+	objectives []*objective
+	opts       Options
+	log        *logger.Logger
+	gauges     *gauges
+	now        func() time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// objective is the recorded state of one objective.
+type objective struct {
+	Objective
+	mu      sync.Mutex
+	buckets []bucket
+	alerts  map[string]string // Firing severity by SLI
+}
+
+// bucket counts the requests of one minute.
+type bucket struct {
+	minute int64
+	total  int64
+	errors int64
+	slow   int64
+}
+
+// NewTracker creates a tracker and starts its evaluation loop.
+//
+// Parameters:
+//   - objectives: Route objectives; requests may count toward several
+//   - opts: Evaluation interval, metrics registry and alert hook
+//   - log: Structured logger receiving burn alerts
+//
+// Returns:
+//   - *Tracker: Running tracker; call Close to stop it
+func NewTracker(objectives []Objective, opts Options, log *logger.Logger) *Tracker {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	t := &Tracker{opts: opts, log: log, now: time.Now, done: make(chan struct{})}
+	for _, o := range objectives {
+		if o.Window <= 0 {
+			o.Window = DefaultWindow
+		}
+		t.objectives = append(t.objectives, &objective{
+			Objective: o,
+			buckets:   make([]bucket, int(o.Window/time.Minute)),
+			alerts:    map[string]string{},
+		})
+	}
+	if opts.Metrics != nil {
+		t.gauges = newGauges(opts.Metrics)
+	}
+
+	t.wg.Add(1)
+	go t.run()
+	return t
+}
+
+// Record counts a finished request toward the matching objectives.
+func (t *Tracker) Record(method, path string, status int, elapsed time.Duration) {
+	minute := t.now().Unix() / 60
+	for _, o := range t.objectives {
+		if !o.Matches(method, path) {
+			continue
+		}
+		o.mu.Lock()
+		b := &o.buckets[minute%int64(len(o.buckets))]
+		if b.minute != minute {
+			*b = bucket{minute: minute}
+		}
+		b.total++
+		if status >= http.StatusInternalServerError {
+			b.errors++
+		}
+		if o.Latency > 0 && elapsed > o.Latency {
+			b.slow++
+		}
+		o.mu.Unlock()
+	}
+}
+
+// Evaluate computes the status of every SLI, updates the gauges and
+// raises or resolves alerts.
+func (t *Tracker) Evaluate() []Status {
+	var statuses []Status
+	for _, o := range t.objectives {
+		for _, status := range t.evaluate(o) {
+			t.gauges.set(status)
+			t.alert(o, status)
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// Close stops the evaluation loop. It is safe to call more than once.
+func (t *Tracker) Close() {
+	t.once.Do(func() { close(t.done) })
+	t.wg.Wait()
+}
+
+// run evaluates the objectives every interval until Close.
+func (t *Tracker) run() {
+	defer t.wg.Done()
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			t.Evaluate()
+		}
+	}
+}
+
+// evaluate computes the SLI statuses of o.
+func (t *Tracker) evaluate(o *objective) []Status {
+	minute := t.now().Unix() / 60
+	o.mu.Lock()
+	window := o.sum(minute, len(o.buckets))
+	spans := map[time.Duration]bucket{}
+	for _, alert := range burnAlerts {
+		for _, span := range []time.Duration{alert.long, alert.short} {
+			spans[span] = o.sum(minute, int(span/time.Minute))
+		}
+	}
+	o.mu.Unlock()
+
+	var statuses []Status
+	slis := []struct {
+		name   string
+		target float64
+		bad    func(bucket) int64
+	}{
+		{SLIAvailability, o.Availability, func(b bucket) int64 { return b.errors }},
+		{SLILatency, o.LatencyTarget, func(b bucket) int64 { return b.slow }},
+	}
+	for _, sli := range slis {
+		if sli.target == 0 {
+			continue
+		}
+		budget := 1 - sli.target
+		status := Status{
+			Objective:  o.Name,
+			SLI:        sli.name,
+			Target:     sli.target,
+			Compliance: 1 - ratio(sli.bad(window), window.total),
+			Remaining:  1 - ratio(sli.bad(window), window.total)/budget,
+			BurnRates:  make(map[string]float64, len(spans)),
+		}
+		for span, counts := range spans {
+			status.BurnRates[spanLabel(span)] = ratio(sli.bad(counts), counts.total) / budget
+		}
+		for _, alert := range burnAlerts {
+			if status.BurnRates[spanLabel(alert.long)] > alert.rate && status.BurnRates[spanLabel(alert.short)] > alert.rate {
+				status.Alert = alert.severity
+				break
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// alert logs and notifies changes of the firing severity of an SLI.
+func (t *Tracker) alert(o *objective, status Status) {
+	o.mu.Lock()
+	previous := o.alerts[status.SLI]
+	o.alerts[status.SLI] = status.Alert
+	o.mu.Unlock()
+	if previous == status.Alert {
+		return
+	}
+
+	if status.Alert == "" {
+		t.log.Infow("slo_budget_burn_resolved", "objective", status.Objective, "sli", status.SLI,
+			"budget_remaining", status.Remaining)
+	} else {
+		t.log.Warnw("slo_budget_burn", "objective", status.Objective, "sli", status.SLI, "severity", status.Alert,
+			"burn_rates", status.BurnRates, "budget_remaining", status.Remaining)
+	}
+	if t.opts.Notify != nil {
+		t.opts.Notify(Alert{Status: status, Resolved: status.Alert == ""})
+	}
+}
+
+// sum adds the buckets of the last n minutes up to minute; the caller
+// holds mu.
+func (o *objective) sum(minute int64, n int) bucket {
+	var total bucket
+	for m := minute - int64(n) + 1; m <= minute; m++ {
+		b := o.buckets[m%int64(len(o.buckets))]
+		if b.minute != m {
+			continue
+		}
+		total.total += b.total
+		total.errors += b.errors
+		total.slow += b.slow
+	}
+	return total
+}
+
+// ratio returns bad/total, zero without requests.
+func ratio(bad, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(bad) / float64(total)
+}
+
+// spanLabel formats a burn rate window ("5m", "1h").
+func spanLabel(span time.Duration) string {
+	if span%time.Hour == 0 {
+		return strconv.Itoa(int(span/time.Hour)) + "h"
+	}
+	return strconv.Itoa(int(span/time.Minute)) + "m"
+}
+
+// gauges publishes SLI statuses. A nil *gauges records nothing.
+type gauges struct {
+	compliance *prometheus.GaugeVec
+	remaining  *prometheus.GaugeVec
+	burnRate   *prometheus.GaugeVec
+}
+
+// newGauges creates the SLO gauges and registers them.
+// It panics if the gauges are already registered.
+func newGauges(reg prometheus.Registerer) *gauges {
+	g := &gauges{
+		compliance: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "slo_compliance_ratio",
+			Help: "Share of good requests over the objective window by objective and SLI.",
+		}, []string{"objective", "sli"}),
+		remaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "slo_error_budget_remaining_ratio",
+			Help: "Share of the error budget left over the objective window (negative when overspent).",
+		}, []string{"objective", "sli"}),
+		burnRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "slo_burn_rate",
+			Help: "Error budget burn rate by objective, SLI and window (1 spends the budget exactly over the objective window).",
+		}, []string{"objective", "sli", "window"}),
+	}
+	reg.MustRegister(g.compliance, g.remaining, g.burnRate)
+	return g
+}
+
+// set publishes status.
+func (g *gauges) set(status Status) {
+	if g == nil {
+		return
+	}
+	g.compliance.WithLabelValues(status.Objective, status.SLI).Set(status.Compliance)
+	g.remaining.WithLabelValues(status.Objective, status.SLI).Set(status.Remaining)
+	for window, rate := range status.BurnRates {
+		g.burnRate.WithLabelValues(status.Objective, status.SLI, window).Set(rate)
+	}
+}
@@ -0,0 +1,99 @@
+package slo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_BurnAlerts(t *testing.T) {
+	var alerts []Alert
+	reg := prometheus.NewRegistry()
+	tracker := newTestTracker(t, []Objective{{Name: "users", Prefix: "/api/v1/users", Availability: 0.99, Window: 6 * time.Hour}},
+		Options{Metrics: reg, Notify: func(a Alert) { alerts = append(alerts, a) }})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	record(tracker, "/api/v1/users", 80, http.StatusOK)
+	record(tracker, "/api/v1/users/7", 20, http.StatusServiceUnavailable)
+	record(tracker, "/api/v1/usage", 50, http.StatusInternalServerError)
+
+	statuses := tracker.Evaluate()
+	require.Len(t, statuses, 1)
+	status := statuses[0]
+	assert.Equal(t, SLIAvailability, status.SLI)
+	assert.InDelta(t, 0.8, status.Compliance, 1e-9)
+	assert.InDelta(t, 20, status.BurnRates["5m"], 1e-9)
+	assert.Equal(t, SeverityFast, status.Alert)
+	require.Len(t, alerts, 1)
+	assert.False(t, alerts[0].Resolved)
+	assert.InDelta(t, -19, testutil.ToFloat64(tracker.gauges.remaining.WithLabelValues("users", SLIAvailability)), 1e-9)
+	assert.InDelta(t, 20, testutil.ToFloat64(tracker.gauges.burnRate.WithLabelValues("users", SLIAvailability, "1h")), 1e-9)
+
+	tracker.Evaluate()
+	assert.Len(t, alerts, 1, "alerts are raised on changes only")
+
+	// A calm short window ends the fast burn; the longer windows still burn
+	now = now.Add(10 * time.Minute)
+	record(tracker, "/api/v1/users", 100, http.StatusOK)
+	status = tracker.Evaluate()[0]
+	assert.Equal(t, SeveritySlow, status.Alert)
+	assert.InDelta(t, 10, status.BurnRates["1h"], 1e-9)
+
+	now = now.Add(time.Hour)
+	record(tracker, "/api/v1/users", 10, http.StatusOK)
+	status = tracker.Evaluate()[0]
+	assert.Empty(t, status.Alert)
+	require.Len(t, alerts, 3)
+	assert.True(t, alerts[2].Resolved)
+}
+
+func TestTracker_LatencyAndWindow(t *testing.T) {
+	tracker := newTestTracker(t, []Objective{{
+		Name:          "search",
+		Prefix:        "/api/v1/",
+		Methods:       []string{http.MethodGet},
+		Latency:       100 * time.Millisecond,
+		LatencyTarget: 0.9,
+		Window:        6 * time.Hour,
+	}}, Options{})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		tracker.Record(http.MethodGet, "/api/v1/search", http.StatusOK, time.Duration(i*20)*time.Millisecond)
+	}
+	tracker.Record(http.MethodPost, "/api/v1/search", http.StatusOK, time.Second)
+
+	status := tracker.Evaluate()[0]
+	assert.Equal(t, SLILatency, status.SLI)
+	assert.InDelta(t, 0.6, status.Compliance, 1e-9, "4 of 10 GET requests are slow")
+
+	now = now.Add(6 * time.Hour)
+	status = tracker.Evaluate()[0]
+	assert.Equal(t, 1.0, status.Compliance, "requests leave the rolling window")
+	assert.Equal(t, 1.0, status.Remaining)
+}
+
+func newTestTracker(t *testing.T, objectives []Objective, opts Options) *Tracker {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	opts.Interval = time.Hour
+	tracker := NewTracker(objectives, opts, log)
+	t.Cleanup(tracker.Close)
+	return tracker
+}
+
+// record counts n GET requests to path answered with status.
+func record(tracker *Tracker, path string, n, status int) {
+	for i := 0; i < n; i++ {
+		tracker.Record(http.MethodGet, path, status, time.Millisecond)
+	}
+}
@@ -0,0 +1,139 @@
+// Package task defines one-shot operational tasks (backfills, repairs,
+// maintenance) that run to completion inside the wired application, for
+// cron jobs and ops runbooks.
+package task
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+)
+
+// ErrUsage marks task failures caused by invalid arguments.
+var ErrUsage = apperrors.New(apperrors.KindInvalid, "task_invalid_arguments", "invalid task arguments").
+	Describe("A one-shot task was started with missing or malformed arguments.")
+
+// Task is a named one-shot job.
+type Task interface {
+	// Name returns the unique task identifier used on the command line.
+	Name() string
+
+	// Description is a one-line summary shown in task listings.
+	Description() string
+
+	// Run executes the task with its positional arguments.
+	Run(ctx context.Context, args []string) error
+}
+
+// funcTask adapts a function to the Task interface.
+type funcTask struct {
+	name        string
+	description string
+	fn          func(ctx context.Context, args []string) error
+}
+
+// New creates a Task from a function.
+func New(name, description string, fn func(ctx context.Context, args []string) error) Task {
+	return &funcTask{name: name, description: description, fn: fn}
+}
+
+func (t *funcTask) Name() string                                 { return t.name }
+func (t *funcTask) Description() string                          { return t.description }
+func (t *funcTask) Run(ctx context.Context, args []string) error { return t.fn(ctx, args) }
+
+// Registry holds the tasks contributed by application modules.
+type Registry struct {
+	mu    sync.Mutex
+	tasks map[string]Task
+}
+
+// NewRegistry creates an empty task registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		tasks: make(map[string]Task),
+	}
+}
+
+// Register adds tasks to the registry.
+// It returns an error if a task name is empty or already registered.
+func (r *Registry) Register(tasks ...Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range tasks {
+		name := t.Name()
+		if name == "" {
+			return fmt.Errorf("task name must not be empty")
+		}
+		if _, exists := r.tasks[name]; exists {
+			return fmt.Errorf("task %q already registered", name)
+		}
+		r.tasks[name] = t
+	}
+
+	return nil
+}
+
+// List returns the registered tasks sorted by name.
+func (r *Registry) List() []Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]Task, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// Lookup returns the task registered under name.
+func (r *Registry) Lookup(name string) (Task, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tasks[name]
+	return t, ok
+}
+
+// Execute runs a task with a deadline and logs its start, outcome and duration.
+//
+// Parameters:
+//   - ctx: Parent context (e.g. canceled on SIGTERM)
+//   - t: Task to run
+//   - args: Positional task arguments
+//   - timeout: Maximum run time (0 = no limit)
+//   - log: Structured logger
+//
+// Returns:
+//   - error: Task failure, or context.DeadlineExceeded on timeout
+func Execute(ctx context.Context, t Task, args []string, timeout time.Duration, log *logger.Logger) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	log.Infow("task_started", "task", t.Name(), "args", args, "timeout", timeout.String(), "trace_id", tracecontext.TraceID(ctx))
+
+	err := t.Run(ctx, args)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	duration := time.Since(start).Milliseconds()
+	if err != nil {
+		log.Errorw("task_failed", "task", t.Name(), "duration_ms", duration, "trace_id", tracecontext.TraceID(ctx), "error", err)
+		return err
+	}
+
+	log.Infow("task_completed", "task", t.Name(), "duration_ms", duration, "trace_id", tracecontext.TraceID(ctx))
+	return nil
+}
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RegisterAndLookup(t *testing.T) {
+	r := NewRegistry()
+	noop := func(ctx context.Context, args []string) error { return nil }
+
+	require.NoError(t, r.Register(New("b:task", "second", noop), New("a:task", "first", noop)))
+	assert.Error(t, r.Register(New("a:task", "dup", noop)))
+	assert.Error(t, r.Register(New("", "empty", noop)))
+
+	list := r.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "a:task", list[0].Name())
+
+	got, ok := r.Lookup("b:task")
+	require.True(t, ok)
+	assert.Equal(t, "second", got.Description())
+
+	_, ok = r.Lookup("missing")
+	assert.False(t, ok)
+}
+
+func TestExecute_PassesArgsAndErrors(t *testing.T) {
+	boom := errors.New("boom")
+	var got []string
+	tk := New("echo", "", func(ctx context.Context, args []string) error {
+		got = args
+		return boom
+	})
+
+	err := Execute(context.Background(), tk, []string{"x", "y"}, 0, newTestLogger(t))
+
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []string{"x", "y"}, got)
+}
+
+func TestExecute_Timeout(t *testing.T) {
+	tk := New("slow", "", func(ctx context.Context, args []string) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	err := Execute(context.Background(), tk, nil, 10*time.Millisecond, newTestLogger(t))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
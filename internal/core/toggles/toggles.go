@@ -0,0 +1,290 @@
+// Package toggles flips feature flags and route flags at runtime.
+//
+// Overrides set through the admin API are persisted in a Repository, so
+// they survive restarts, and take precedence over FEATURE_FLAGS and the
+// flags of ROUTE_FLAGS_CONFIG. Every change is recorded with its actor in
+// an append-only audit trail. Updates are optimistic: writers present the
+// version of the override they read (0 when there is none) and lose with
+// ErrVersionConflict when it changed meanwhile.
+//
+//	service := toggles.NewService(repo, cfg.FeatureFlags, routeFlags)
+//	err := service.Restore(ctx) // apply the persisted overrides
+//	if reqctx.FeatureEnabled(ctx, "bulk_import") { ... }
+package toggles
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/routeflags"
+)
+
+// Domain errors returned by the toggles module.
+var (
+	ErrToggleNotFound = apperrors.New(apperrors.KindNotFound, "toggle_not_found",
+		"toggle override not found")
+	ErrToggleInvalid = apperrors.New(apperrors.KindInvalid, "toggle_invalid",
+		"invalid toggle").
+		Describe("Features are named with letters, digits, dots, dashes and underscores; routes are \"METHOD /route/template\" with an optional status of 404 or 503.")
+	ErrVersionConflict = apperrors.New(apperrors.KindConflict, "toggle_version_conflict",
+		"toggle was changed concurrently").
+		Describe("Fetch the toggle and retry with its current version (0 when it is not overridden).")
+)
+
+// Kind is what a toggle switches.
+type Kind string
+
+// Kinds of toggles.
+const (
+	KindFeature Kind = "feature"
+	KindRoute   Kind = "route"
+)
+
+// Actions of recorded changes.
+const (
+	ActionSet   = "set"
+	ActionReset = "reset"
+)
+
+// Toggle is the state of a feature or route. Version is 0 for the state
+// declared by configuration and incremented by every override.
+type Toggle struct {
+	Kind    Kind
+	Name    string // Feature name, or "METHOD /route/template" for routes
+	Enabled bool
+	Status  int // Routes: status answered while disabled, 404 (default) or 503
+	Version int64
+
+	UpdatedAt time.Time
+	UpdatedBy string
+}
+
+// Change is an audit record of an override being set or reset.
+type Change struct {
+	Kind      Kind
+	Name      string
+	Action    string  // ActionSet or ActionReset
+	Before    *Toggle // Override replaced, nil when there was none
+	After     *Toggle // Override written, nil on reset
+	At        time.Time
+	By        string // Request principal
+	RequestID string
+}
+
+// Repository persists the overrides and their change history.
+type Repository interface {
+	// List returns the overrides ordered by kind and name.
+	List(ctx context.Context) ([]Toggle, error)
+
+	// Get returns an override or ErrToggleNotFound.
+	Get(ctx context.Context, kind Kind, name string) (Toggle, error)
+
+	// Put stores toggle when the stored override has toggle.Version-1 (0
+	// when absent), or returns ErrVersionConflict, and records change in
+	// the same transaction.
+	Put(ctx context.Context, toggle *Toggle, change *Change) error
+
+	// Delete removes the override of version, or returns ErrToggleNotFound
+	// or ErrVersionConflict, and records change in the same transaction.
+	Delete(ctx context.Context, kind Kind, name string, version int64, change *Change) error
+
+	// Changes returns the most recent changes, newest first, at most limit.
+	Changes(ctx context.Context, limit int) ([]Change, error)
+}
+
+// Service applies the overrides to the feature flags of requests and to
+// the route flags.
+type Service struct {
+	repo     Repository
+	declared map[string]bool // FEATURE_FLAGS
+	routes   *routeflags.Flags
+	now      func() time.Time
+
+	mu       sync.Mutex // Serializes writes with applying their result
+	features atomic.Pointer[map[string]bool]
+}
+
+// NewService creates a toggles service over repo. features are the
+// feature flags enabled by configuration; overrides of routes are applied
+// to routes.
+func NewService(repo Repository, features []string, routes *routeflags.Flags) *Service {
+	declared := make(map[string]bool, len(features))
+	for _, name := range features {
+		declared[name] = true
+	}
+	s := &Service{repo: repo, declared: declared, routes: routes, now: time.Now}
+	s.features.Store(&declared)
+	return s
+}
+
+// Features returns the enabled feature flags; the map is read-only.
+func (s *Service) Features() map[string]bool {
+	return *s.features.Load()
+}
+
+// Restore applies the persisted overrides, e.g. at startup.
+func (s *Service) Restore(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.apply(ctx)
+}
+
+// List returns the effective toggles: the overrides, and the declared
+// feature and route flags they do not replace, ordered by kind and name.
+func (s *Service) List(ctx context.Context) ([]Toggle, error) {
+	overrides, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overridden := make(map[Kind]map[string]bool)
+	for _, t := range overrides {
+		if overridden[t.Kind] == nil {
+			overridden[t.Kind] = make(map[string]bool)
+		}
+		overridden[t.Kind][t.Name] = true
+	}
+
+	out := overrides
+	for name := range s.declared {
+		if !overridden[KindFeature][name] {
+			out = append(out, Toggle{Kind: KindFeature, Name: name, Enabled: true})
+		}
+	}
+	for _, flag := range s.routes.Declared() {
+		if !overridden[KindRoute][flag.Route] {
+			out = append(out, Toggle{Kind: KindRoute, Name: flag.Route, Enabled: flag.Enabled, Status: flag.Status})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// Set overrides a toggle on behalf of the principal of ctx. version is
+// the version of the override being replaced, 0 when there is none.
+func (s *Service) Set(ctx context.Context, toggle Toggle, version int64) (Toggle, error) {
+	toggle.Name = strings.TrimSpace(toggle.Name)
+	if !valid(toggle) {
+		return Toggle{}, ErrToggleInvalid
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change := s.change(ctx, toggle.Kind, toggle.Name, ActionSet)
+	before, err := s.repo.Get(ctx, toggle.Kind, toggle.Name)
+	switch {
+	case err == nil:
+		change.Before = &before
+	case apperrors.KindOf(err) != apperrors.KindNotFound:
+		return Toggle{}, err
+	}
+
+	toggle.Version = version + 1
+	toggle.UpdatedAt, toggle.UpdatedBy = change.At, change.By
+	change.After = &toggle
+	if err := s.repo.Put(ctx, &toggle, &change); err != nil {
+		return Toggle{}, err
+	}
+	return toggle, s.apply(ctx)
+}
+
+// Reset removes the override of version, restoring the declared state.
+func (s *Service) Reset(ctx context.Context, kind Kind, name string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change := s.change(ctx, kind, name, ActionReset)
+	before, err := s.repo.Get(ctx, kind, name)
+	if err != nil {
+		return err
+	}
+	change.Before = &before
+	if err := s.repo.Delete(ctx, kind, name, version, &change); err != nil {
+		return err
+	}
+	return s.apply(ctx)
+}
+
+// Changes returns the most recent changes, newest first.
+func (s *Service) Changes(ctx context.Context, limit int) ([]Change, error) {
+	return s.repo.Changes(ctx, limit)
+}
+
+// change returns the audit record of a change made by the caller of ctx.
+func (s *Service) change(ctx context.Context, kind Kind, name, action string) Change {
+	rc := reqctx.From(ctx)
+	return Change{
+		Kind:      kind,
+		Name:      name,
+		Action:    action,
+		At:        s.now().UTC(),
+		By:        rc.Principal,
+		RequestID: rc.RequestID,
+	}
+}
+
+// apply swaps in the feature flags and route overrides of the persisted
+// overrides; s.mu must be held.
+func (s *Service) apply(ctx context.Context) error {
+	overrides, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	features := make(map[string]bool, len(s.declared))
+	for name := range s.declared {
+		features[name] = true
+	}
+	var routes []routeflags.Flag
+	for _, t := range overrides {
+		switch t.Kind {
+		case KindFeature:
+			if t.Enabled {
+				features[t.Name] = true
+			} else {
+				delete(features, t.Name)
+			}
+		case KindRoute:
+			routes = append(routes, routeflags.Flag{Route: t.Name, Enabled: t.Enabled, Status: t.Status})
+		}
+	}
+	s.features.Store(&features)
+	s.routes.Override(routes)
+	return nil
+}
+
+// valid reports whether toggle names a feature or route, with a status
+// only for routes.
+func valid(toggle Toggle) bool {
+	switch toggle.Kind {
+	case KindFeature:
+		return toggle.Status == 0 && validFeature(toggle.Name)
+	case KindRoute:
+		return routeflags.ValidRoute(toggle.Name) &&
+			(toggle.Status == 0 || toggle.Status == http.StatusNotFound || toggle.Status == http.StatusServiceUnavailable)
+	default:
+		return false
+	}
+}
+
+// validFeature reports whether name is a feature flag name.
+func validFeature(name string) bool {
+	if name == "" || len(name) > 128 {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package toggles_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/routeflags"
+	"github.com/luminosita/change-me/internal/core/toggles"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SetAndReset(t *testing.T) {
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "ops", RequestID: "req-1"})
+	routes := routeflags.New([]routeflags.Flag{{Route: "POST /api/v1/reports", Enabled: true}})
+	service := toggles.NewService(memory.NewToggleRepository(), []string{"bulk_import"}, routes)
+
+	// Disable a feature enabled by configuration
+	feature, err := service.Set(ctx, toggles.Toggle{Kind: toggles.KindFeature, Name: "bulk_import"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), feature.Version)
+	assert.Equal(t, "ops", feature.UpdatedBy)
+	assert.False(t, service.Features()["bulk_import"])
+
+	// Disable a declared route
+	_, err = service.Set(ctx, toggles.Toggle{Kind: toggles.KindRoute, Name: "POST /api/v1/reports", Status: http.StatusServiceUnavailable}, 0)
+	require.NoError(t, err)
+	flag, disabled := routes.Disabled(http.MethodPost, "/api/v1/reports")
+	assert.True(t, disabled)
+	assert.Equal(t, http.StatusServiceUnavailable, flag.StatusOf())
+
+	// Writers holding a stale version lose
+	_, err = service.Set(ctx, toggles.Toggle{Kind: toggles.KindFeature, Name: "bulk_import", Enabled: true}, 0)
+	assert.ErrorIs(t, err, toggles.ErrVersionConflict)
+	assert.ErrorIs(t, service.Reset(ctx, toggles.KindFeature, "bulk_import", 2), toggles.ErrVersionConflict)
+
+	list, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, toggles.KindFeature, list[0].Kind)
+	assert.Equal(t, int64(1), list[1].Version)
+
+	// Resetting restores the declared state
+	require.NoError(t, service.Reset(ctx, toggles.KindFeature, "bulk_import", 1))
+	assert.True(t, service.Features()["bulk_import"])
+	assert.ErrorIs(t, service.Reset(ctx, toggles.KindFeature, "bulk_import", 1), toggles.ErrToggleNotFound)
+
+	changes, err := service.Changes(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, toggles.ActionReset, changes[0].Action)
+	assert.Nil(t, changes[0].After)
+	assert.Equal(t, int64(1), changes[0].Before.Version)
+	assert.Equal(t, "req-1", changes[0].RequestID)
+	assert.Nil(t, changes[2].Before)
+}
+
+func TestService_RejectsInvalidToggles(t *testing.T) {
+	ctx := context.Background()
+	service := toggles.NewService(memory.NewToggleRepository(), nil, routeflags.New(nil))
+
+	for _, toggle := range []toggles.Toggle{
+		{Kind: toggles.KindFeature, Name: "bulk import"},
+		{Kind: toggles.KindFeature, Name: "bulk_import", Status: http.StatusNotFound},
+		{Kind: toggles.KindRoute, Name: "/api/v1/reports"},
+		{Kind: toggles.KindRoute, Name: "POST /api/v1/reports", Status: http.StatusTeapot},
+		{Kind: "plugin", Name: "audit"},
+	} {
+		_, err := service.Set(ctx, toggle, 0)
+		assert.ErrorIs(t, err, toggles.ErrToggleInvalid, toggle.Name)
+	}
+}
+
+func TestService_RestoreAppliesPersistedOverrides(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewToggleRepository()
+	_, err := toggles.NewService(repo, nil, routeflags.New(nil)).Set(ctx, toggles.Toggle{Kind: toggles.KindFeature, Name: "beta", Enabled: true}, 0)
+	require.NoError(t, err)
+
+	// A new instance over the same repository
+	routes := routeflags.New(nil)
+	service := toggles.NewService(repo, nil, routes)
+	assert.False(t, service.Features()["beta"])
+	require.NoError(t, service.Restore(ctx))
+	assert.True(t, service.Features()["beta"])
+}
@@ -0,0 +1,267 @@
+// Package twofactor implements TOTP two-factor authentication for request
+// principals: enrollment with a provisioning URI for authenticator apps,
+// confirmation issuing single-use backup codes, and code verification.
+//
+// Secrets are stored encrypted with the field-encryption envelope; backup
+// codes only as digests. A code's time step is remembered so an accepted
+// code cannot be replayed, and repeated wrong codes lock verification for
+// a while.
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/pkg/crypto"
+	"github.com/luminosita/change-me/pkg/totp"
+)
+
+// ErrNotEnrolled is returned when the principal has no enrollment.
+var ErrNotEnrolled = apperrors.New(apperrors.KindNotFound, "two_factor_not_enrolled", "two-factor authentication not enabled").
+	Describe("The caller has no confirmed two-factor enrollment.")
+
+// ErrAlreadyEnrolled is returned when enrolling a principal twice.
+var ErrAlreadyEnrolled = apperrors.New(apperrors.KindConflict, "two_factor_already_enrolled", "two-factor authentication already enabled").
+	Describe("The caller already confirmed an enrollment; disable it before enrolling again.")
+
+// ErrInvalidCode is returned for wrong, expired or reused codes.
+var ErrInvalidCode = apperrors.New(apperrors.KindUnauthorized, "two_factor_invalid_code", "invalid two-factor code").
+	Describe("The one-time or backup code is wrong, expired or was already used.")
+
+// ErrLocked is returned while verification is locked after too many wrong
+// codes.
+var ErrLocked = apperrors.New(apperrors.KindRateLimited, "two_factor_locked", "too many invalid two-factor codes").
+	Describe("Too many wrong two-factor codes were entered; try again later.")
+
+// Verification lockout defaults applied to zero-valued Options fields.
+const (
+	DefaultMaxAttempts = 5
+	DefaultLockout     = 15 * time.Minute
+)
+
+// Enrollment is the two-factor state of a principal. Repositories stamp
+// its audit fields.
+type Enrollment struct {
+	Principal   string
+	Secret      string `encrypt:"twofactor.secret"` // Encrypted TOTP secret
+	Confirmed   bool
+	LastStep    int64    // Time step of the last accepted code
+	BackupCodes []string // Digests of the unused backup codes
+
+	FailedAttempts int       // Wrong codes since the last accepted one
+	LockedUntil    time.Time // End of the current lockout
+	audit.Fields
+}
+
+// Repository persists enrollments by principal.
+type Repository interface {
+	// Get returns the enrollment of principal or ErrNotEnrolled.
+	Get(ctx context.Context, principal string) (*Enrollment, error)
+
+	// Save creates or replaces the enrollment of its principal.
+	Save(ctx context.Context, enrollment *Enrollment) error
+
+	// Update applies fn to the enrollment of principal and saves it unless
+	// fn fails, atomically with respect to other writes of the principal.
+	// It returns ErrNotEnrolled when there is no enrollment.
+	Update(ctx context.Context, principal string, fn func(*Enrollment) error) error
+
+	// Delete removes the enrollment of principal or returns ErrNotEnrolled.
+	Delete(ctx context.Context, principal string) error
+}
+
+// Options configures a Service.
+type Options struct {
+	Issuer      string // Account issuer shown by authenticator apps
+	Skew        int    // Time steps of clock drift accepted either way
+	BackupCodes int    // Backup codes issued on confirmation
+
+	// Verification locks for Lockout after MaxAttempts wrong codes in a
+	// row (default 5 and 15m)
+	MaxAttempts int
+	Lockout     time.Duration
+}
+
+// Provisioning is the secret of a new enrollment, shown to the user once.
+type Provisioning struct {
+	Secret string
+	URI    string // otpauth:// URI rendered as a QR code
+}
+
+// Status reports the two-factor state of a principal.
+type Status struct {
+	Enabled          bool
+	BackupCodesCount int
+}
+
+// Service implements the two-factor use cases.
+type Service struct {
+	repo     Repository
+	envelope *crypto.Envelope
+	opts     Options
+	now      func() time.Time
+}
+
+// NewService creates a two-factor service storing secrets sealed by
+// envelope.
+func NewService(repo Repository, envelope *crypto.Envelope, opts Options) *Service {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Lockout <= 0 {
+		opts.Lockout = DefaultLockout
+	}
+	return &Service{
+		repo:     repo,
+		envelope: envelope,
+		opts:     opts,
+		now:      time.Now,
+	}
+}
+
+// Enroll starts an enrollment for principal, replacing an unconfirmed one.
+// account labels the enrollment in authenticator apps, such as the email
+// address of the user.
+func (s *Service) Enroll(ctx context.Context, principal, account string) (*Provisioning, error) {
+	existing, err := s.repo.Get(ctx, principal)
+	if err == nil && existing.Confirmed {
+		return nil, ErrAlreadyEnrolled
+	}
+	if err != nil && !errors.Is(err, ErrNotEnrolled) {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	enrollment := &Enrollment{Principal: principal, Secret: secret}
+	if err := s.envelope.EncryptFields(ctx, enrollment); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, enrollment); err != nil {
+		return nil, err
+	}
+	return &Provisioning{
+		Secret: secret,
+		URI:    totp.URI(s.opts.Issuer, account, secret, s.totpOptions()),
+	}, nil
+}
+
+// Confirm completes the enrollment of principal with a first code from
+// the authenticator app and returns the backup codes, shown once.
+func (s *Service) Confirm(ctx context.Context, principal, code string) ([]string, error) {
+	codes, err := totp.GenerateBackupCodes(s.opts.BackupCodes)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.Update(ctx, principal, func(e *Enrollment) error {
+		if e.Confirmed {
+			return ErrAlreadyEnrolled
+		}
+		if err := s.acceptCode(ctx, e, code); err != nil {
+			return err
+		}
+		e.Confirmed = true
+		e.BackupCodes = make([]string, len(codes))
+		for i, c := range codes {
+			e.BackupCodes[i] = totp.HashBackupCode(c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a one-time code or consumes a backup code of principal.
+// Wrong codes are counted; reaching MaxAttempts locks verification and
+// returns ErrLocked until the lockout ends.
+func (s *Service) Verify(ctx context.Context, principal, code string) error {
+	var verifyErr error
+	err := s.repo.Update(ctx, principal, func(e *Enrollment) error {
+		if !e.Confirmed {
+			return ErrNotEnrolled
+		}
+		now := s.now()
+		if now.Before(e.LockedUntil) {
+			return ErrLocked
+		}
+
+		verifyErr = s.verifyCode(ctx, e, code)
+		if !errors.Is(verifyErr, ErrInvalidCode) {
+			e.FailedAttempts = 0
+			return verifyErr
+		}
+		// Save the failed attempt rather than rolling it back
+		e.FailedAttempts++
+		if e.FailedAttempts >= s.opts.MaxAttempts {
+			e.FailedAttempts = 0
+			e.LockedUntil = now.Add(s.opts.Lockout)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return verifyErr
+}
+
+// Disable removes the enrollment of principal after verifying a code.
+func (s *Service) Disable(ctx context.Context, principal, code string) error {
+	if err := s.Verify(ctx, principal, code); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, principal)
+}
+
+// Status returns the two-factor state of principal.
+func (s *Service) Status(ctx context.Context, principal string) (Status, error) {
+	e, err := s.repo.Get(ctx, principal)
+	if errors.Is(err, ErrNotEnrolled) {
+		return Status{}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{Enabled: e.Confirmed, BackupCodesCount: len(e.BackupCodes)}, nil
+}
+
+// verifyCode accepts a one-time code of e or consumes one of its backup
+// codes.
+func (s *Service) verifyCode(ctx context.Context, e *Enrollment, code string) error {
+	if err := s.acceptCode(ctx, e, code); err == nil || !errors.Is(err, ErrInvalidCode) {
+		return err
+	}
+	i := slices.Index(e.BackupCodes, totp.HashBackupCode(code))
+	if i < 0 {
+		return ErrInvalidCode
+	}
+	e.BackupCodes = slices.Delete(e.BackupCodes, i, i+1)
+	return nil
+}
+
+// acceptCode validates a one-time code against the secret of e and
+// records its time step, rejecting steps already used.
+func (s *Service) acceptCode(ctx context.Context, e *Enrollment, code string) error {
+	plain := *e
+	if err := s.envelope.DecryptFields(ctx, &plain); err != nil {
+		return err
+	}
+	step, ok := totp.Validate(code, plain.Secret, s.now(), s.totpOptions())
+	if !ok || step <= e.LastStep {
+		return ErrInvalidCode
+	}
+	e.LastStep = step
+	return nil
+}
+
+// totpOptions returns the code options of the service.
+func (s *Service) totpOptions() totp.Options {
+	return totp.Options{Skew: s.opts.Skew}
+}
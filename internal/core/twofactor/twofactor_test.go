@@ -0,0 +1,126 @@
+package twofactor_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/bolt"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/pkg/crypto"
+	"github.com/luminosita/change-me/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_EnrollConfirmVerify(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewTwoFactorRepository()
+	service := newTestService(t, repo)
+
+	provisioning, err := service.Enroll(ctx, "jane", "jane@example.com")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(provisioning.URI, "otpauth://totp/Example:jane@example.com?"), provisioning.URI)
+
+	stored, err := repo.Get(ctx, "jane")
+	require.NoError(t, err)
+	assert.NotContains(t, stored.Secret, provisioning.Secret, "secrets are stored encrypted")
+
+	err = service.Verify(ctx, "jane", code(t, provisioning.Secret, 0))
+	assert.ErrorIs(t, err, twofactor.ErrNotEnrolled, "unconfirmed enrollments do not verify")
+
+	backup, err := service.Confirm(ctx, "jane", code(t, provisioning.Secret, 0))
+	require.NoError(t, err)
+	assert.Len(t, backup, 3)
+
+	assert.ErrorIs(t, service.Verify(ctx, "jane", code(t, provisioning.Secret, 0)), twofactor.ErrInvalidCode, "codes cannot be replayed")
+	assert.NoError(t, service.Verify(ctx, "jane", code(t, provisioning.Secret, 30*time.Second)), "the next step is accepted within the drift window")
+	assert.ErrorIs(t, service.Verify(ctx, "jane", "000000"), twofactor.ErrInvalidCode)
+
+	assert.NoError(t, service.Verify(ctx, "jane", strings.ToUpper(backup[0])))
+	assert.ErrorIs(t, service.Verify(ctx, "jane", backup[0]), twofactor.ErrInvalidCode, "backup codes are single use")
+	status, err := service.Status(ctx, "jane")
+	require.NoError(t, err)
+	assert.Equal(t, twofactor.Status{Enabled: true, BackupCodesCount: 2}, status)
+
+	_, err = service.Enroll(ctx, "jane", "jane@example.com")
+	assert.ErrorIs(t, err, twofactor.ErrAlreadyEnrolled)
+
+	require.NoError(t, service.Disable(ctx, "jane", backup[1]))
+	status, err = service.Status(ctx, "jane")
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+}
+
+func TestService_LocksAfterFailedAttempts(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, memory.NewTwoFactorRepository())
+
+	provisioning, err := service.Enroll(ctx, "jane", "jane@example.com")
+	require.NoError(t, err)
+	backup, err := service.Confirm(ctx, "jane", code(t, provisioning.Secret, 0))
+	require.NoError(t, err)
+
+	for i := 0; i < twofactor.DefaultMaxAttempts-1; i++ {
+		assert.ErrorIs(t, service.Verify(ctx, "jane", "000000"), twofactor.ErrInvalidCode)
+	}
+	require.NoError(t, service.Verify(ctx, "jane", backup[2]), "an accepted code resets the count")
+	for i := 0; i < twofactor.DefaultMaxAttempts; i++ {
+		assert.ErrorIs(t, service.Verify(ctx, "jane", "000000"), twofactor.ErrInvalidCode)
+	}
+	assert.ErrorIs(t, service.Verify(ctx, "jane", backup[0]), twofactor.ErrLocked, "valid codes are refused while locked")
+	assert.ErrorIs(t, service.Disable(ctx, "jane", backup[0]), twofactor.ErrLocked)
+
+	status, err := service.Status(ctx, "jane")
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.BackupCodesCount, "locked attempts consume no backup code")
+}
+
+func TestService_EnrollmentAndLockoutSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := bolt.Open(path, bolt.Options{})
+	require.NoError(t, err)
+	service := newTestService(t, bolt.NewTwoFactorRepository(db))
+
+	provisioning, err := service.Enroll(ctx, "jane", "jane@example.com")
+	require.NoError(t, err)
+	backup, err := service.Confirm(ctx, "jane", code(t, provisioning.Secret, 0))
+	require.NoError(t, err)
+	require.NoError(t, service.Verify(ctx, "jane", backup[0]))
+	for i := 0; i < twofactor.DefaultMaxAttempts; i++ {
+		assert.ErrorIs(t, service.Verify(ctx, "jane", "000000"), twofactor.ErrInvalidCode)
+	}
+
+	// Restart against the same store
+	require.NoError(t, db.Close())
+	db, err = bolt.Open(path, bolt.Options{})
+	require.NoError(t, err)
+	defer db.Close()
+	service = newTestService(t, bolt.NewTwoFactorRepository(db))
+
+	status, err := service.Status(ctx, "jane")
+	require.NoError(t, err)
+	assert.Equal(t, twofactor.Status{Enabled: true, BackupCodesCount: 2}, status, "used backup codes stay used")
+	assert.ErrorIs(t, service.Verify(ctx, "jane", backup[1]), twofactor.ErrLocked, "the lockout survives the restart")
+	_, err = service.Enroll(ctx, "jane", "jane@example.com")
+	assert.ErrorIs(t, err, twofactor.ErrAlreadyEnrolled)
+}
+
+func newTestService(t *testing.T, repo twofactor.Repository) *twofactor.Service {
+	t.Helper()
+	keyring, err := crypto.NewKeyring(map[string][]byte{"k1": []byte(strings.Repeat("k", crypto.KeySize))}, "k1")
+	require.NoError(t, err)
+	return twofactor.NewService(repo, crypto.NewEnvelope(keyring), twofactor.Options{Issuer: "Example", Skew: 1, BackupCodes: 3})
+}
+
+// code returns the code of secret offset from now.
+func code(t *testing.T, secret string, offset time.Duration) string {
+	t.Helper()
+	c, err := totp.Code(secret, time.Now().Add(offset), totp.Options{})
+	require.NoError(t, err)
+	return c
+}
@@ -0,0 +1,120 @@
+// Package uploads stores files uploaded by clients in object storage and
+// hands out their download links.
+package uploads
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/objectstore"
+)
+
+// Errors returned when uploading files.
+var (
+	ErrTooLarge = apperrors.New(apperrors.KindInvalid, "upload_too_large",
+		"file exceeds the upload size limit")
+	ErrNameInvalid = apperrors.New(apperrors.KindInvalid, "upload_name_invalid",
+		"invalid file name")
+)
+
+// Upload is a stored file.
+type Upload struct {
+	Key         string
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+// Options configures a Service.
+type Options struct {
+	MaxSize int64         // Largest accepted file in bytes (default 10 MiB)
+	LinkTTL time.Duration // Validity of download links (default 15m)
+}
+
+// Service stores uploads. Wrap the store with malware.NewStore to scan
+// files before they are persisted.
+type Service struct {
+	store objectstore.Store
+	opts  Options
+}
+
+// NewService creates an upload service storing files in store.
+func NewService(store objectstore.Store, opts Options) *Service {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 10 << 20
+	}
+	if opts.LinkTTL <= 0 {
+		opts.LinkTTL = 15 * time.Minute
+	}
+	return &Service{store: store, opts: opts}
+}
+
+// MaxSize returns the largest accepted file in bytes.
+func (s *Service) MaxSize() int64 { return s.opts.MaxSize }
+
+// LinkTTL returns the validity of download links.
+func (s *Service) LinkTTL() time.Duration { return s.opts.LinkTTL }
+
+// Upload stores the size bytes of r as name under a new key. It fails with
+// ErrTooLarge above the size limit, with ErrNameInvalid for names without
+// a usable character, and with the store's errors.
+func (s *Service) Upload(ctx context.Context, name, contentType string, size int64, r io.Reader) (Upload, error) {
+	if size > s.opts.MaxSize {
+		return Upload{}, ErrTooLarge
+	}
+	clean := sanitizeName(name)
+	if clean == "" {
+		return Upload{}, ErrNameInvalid
+	}
+
+	upload := Upload{
+		Key:         "uploads/" + newID() + "/" + clean,
+		Name:        clean,
+		ContentType: contentType,
+		Size:        size,
+	}
+	// The limit guards against sizes declared smaller than the content
+	if err := s.store.Put(ctx, upload.Key, io.LimitReader(r, s.opts.MaxSize), contentType); err != nil {
+		return Upload{}, err
+	}
+	return upload, nil
+}
+
+// URL returns a download link for the upload of key.
+func (s *Service) URL(ctx context.Context, key string) (string, error) {
+	return s.store.URL(ctx, key, s.opts.LinkTTL)
+}
+
+// sanitizeName reduces name to its base name in the object key syntax,
+// replacing other characters with '_'.
+func sanitizeName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+	clean = strings.TrimLeft(clean, ".")
+	if len(clean) > 128 {
+		clean = clean[len(clean)-128:]
+	}
+	if strings.Trim(clean, "_") == "" {
+		return ""
+	}
+	return clean
+}
+
+// newID returns a random upload ID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
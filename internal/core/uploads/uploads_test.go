@@ -0,0 +1,52 @@
+package uploads
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Upload(t *testing.T) {
+	store := mocks.NewObjectStore()
+	s := NewService(store, Options{MaxSize: 8})
+
+	upload, err := s.Upload(context.Background(), `C:\Users\jane\my notes.txt`, "text/plain", 5, strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "my_notes.txt", upload.Name)
+	assert.Regexp(t, `^uploads/[0-9a-f]{32}/my_notes\.txt$`, upload.Key)
+	assert.Equal(t, []byte("hello"), store.Object(upload.Key))
+	link, err := s.URL(context.Background(), upload.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "https://files.example.com/"+upload.Key, link)
+}
+
+func TestService_UploadRejects(t *testing.T) {
+	store := mocks.NewObjectStore()
+	s := NewService(store, Options{MaxSize: 8})
+
+	_, err := s.Upload(context.Background(), "big.bin", "", 9, strings.NewReader("123456789"))
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = s.Upload(context.Background(), "../..", "", 1, strings.NewReader("x"))
+	assert.ErrorIs(t, err, ErrNameInvalid)
+	assert.Zero(t, store.Len())
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := map[string]string{
+		"report.pdf":                      "report.pdf",
+		"../../etc/passwd":                "passwd",
+		".env":                            "env",
+		"résumé 2024.docx":                "r_sum__2024.docx",
+		"/":                               "",
+		strings.Repeat("a", 200) + ".txt": strings.Repeat("a", 124) + ".txt",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, sanitizeName(name), name)
+	}
+}
@@ -0,0 +1,83 @@
+package users
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/internal/core/cache"
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// Cached method names, used as cache namespaces and TTL override keys.
+const (
+	CacheMethodGet  = "users.Get"
+	CacheMethodList = "users.List"
+)
+
+// userServiceCache memoizes UserService reads.
+type userServiceCache struct {
+	UserService
+	get  *cache.Method[UserID, *User]
+	list *cache.Method[pagination.Params, pagination.Page[User]]
+}
+
+// NewUserServiceCache wraps next with result caching for Get and List.
+// Cached entries are invalidated by the module's events on bus, so writes
+// are visible on the next read rather than after the TTL.
+func NewUserServiceCache(next UserService, store cache.Store, ttls cache.TTLs, bus *events.Bus) UserService {
+	c := &userServiceCache{
+		UserService: next,
+		get:         cache.NewMethod[UserID, *User](store, CacheMethodGet, ttls.For(CacheMethodGet), UserID.String),
+		list: cache.NewMethod[pagination.Params, pagination.Page[User]](store, CacheMethodList, ttls.For(CacheMethodList),
+			func(p pagination.Params) string {
+				key := strconv.Itoa(p.Limit) + ":" + strconv.Itoa(max(p.Offset, 0))
+				if len(p.After) > 0 {
+					key = strconv.Itoa(p.Limit) + ":after:" + strings.Join(p.After, ",")
+				}
+				if !p.Filter.IsZero() {
+					key += ":" + p.Filter.String()
+				}
+				return key
+			}),
+	}
+
+	bus.Subscribe(EventUserCreated, func(ctx context.Context, e events.Event) {
+		_ = c.get.Invalidate(ctx, e.(UserCreated).User.ID)
+		_ = c.list.InvalidateAll(ctx)
+	})
+	bus.Subscribe(EventUserUpdated, func(ctx context.Context, e events.Event) {
+		_ = c.get.Invalidate(ctx, e.(UserUpdated).User.ID)
+		_ = c.list.InvalidateAll(ctx)
+	})
+	bus.Subscribe(EventUserDeleted, func(ctx context.Context, e events.Event) {
+		_ = c.get.Invalidate(ctx, e.(UserDeleted).ID)
+		_ = c.list.InvalidateAll(ctx)
+	})
+	bus.Subscribe(EventUserRestored, func(ctx context.Context, e events.Event) {
+		_ = c.get.Invalidate(ctx, e.(UserRestored).User.ID)
+		_ = c.list.InvalidateAll(ctx)
+	})
+
+	return c
+}
+
+// Get implements UserService. Reads including soft-deleted users bypass
+// the cache.
+func (c *userServiceCache) Get(ctx context.Context, id UserID) (*User, error) {
+	if audit.IncludesDeleted(ctx) {
+		return c.UserService.Get(ctx, id)
+	}
+	return c.get.Do(ctx, id, c.UserService.Get)
+}
+
+// List implements UserService. Reads including soft-deleted users bypass
+// the cache.
+func (c *userServiceCache) List(ctx context.Context, params pagination.Params) (pagination.Page[User], error) {
+	if audit.IncludesDeleted(ctx) {
+		return c.UserService.List(ctx, params)
+	}
+	return c.list.Do(ctx, params, c.UserService.List)
+}
@@ -0,0 +1,44 @@
+package users_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/cache"
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserServiceCache_CreateInvalidatesCachedPages(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	service := users.NewUserServiceCache(
+		users.NewService(memory.NewUserRepository(nil), users.WithEvents(bus)),
+		memory.NewCacheStore(0),
+		cache.TTLs{Default: time.Hour},
+		bus,
+	)
+
+	jane, err := service.Create(ctx, users.CreateInput{Email: "jane@example.com", Username: "jane"})
+	require.NoError(t, err)
+
+	page, err := service.List(ctx, pagination.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+
+	_, err = service.Create(ctx, users.CreateInput{Email: "john@example.com", Username: "john"})
+	require.NoError(t, err)
+
+	page, err = service.List(ctx, pagination.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total, "cached page is dropped on users.created")
+
+	first, err := service.Get(ctx, jane.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", first.Email)
+}
@@ -0,0 +1,103 @@
+package users
+
+import (
+	"context"
+
+	"github.com/luminosita/change-me/pkg/deadline"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// deadlineRepository bounds the queries of the wrapped repository by a
+// share of the request deadline.
+type deadlineRepository struct {
+	next   Repository
+	budget float64
+}
+
+// NewDeadlineRepository wraps next so each query gets the budget share of
+// the time remaining until the ctx deadline (see deadline.Budget) and
+// queries of expired requests are not sent. Queries outside a request
+// deadline are unbounded.
+func NewDeadlineRepository(next Repository, budget float64) Repository {
+	return &deadlineRepository{next: next, budget: budget}
+}
+
+// Create implements Repository.
+func (r *deadlineRepository) Create(ctx context.Context, user *User) error {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.next.Create(ctx, user)
+}
+
+// GetByID implements Repository.
+func (r *deadlineRepository) GetByID(ctx context.Context, id UserID) (*User, error) {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.next.GetByID(ctx, id)
+}
+
+// GetByEmail implements Repository.
+func (r *deadlineRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.next.GetByEmail(ctx, email)
+}
+
+// List implements Repository.
+func (r *deadlineRepository) List(ctx context.Context, params pagination.Params) (pagination.Page[User], error) {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return pagination.Page[User]{}, err
+	}
+	return r.next.List(ctx, params)
+}
+
+// Update implements Repository.
+func (r *deadlineRepository) Update(ctx context.Context, user *User) error {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.next.Update(ctx, user)
+}
+
+// Delete implements Repository.
+func (r *deadlineRepository) Delete(ctx context.Context, id UserID) error {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.next.Delete(ctx, id)
+}
+
+// Restore implements Repository.
+func (r *deadlineRepository) Restore(ctx context.Context, id UserID) error {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.next.Restore(ctx, id)
+}
+
+// Purge implements Repository.
+func (r *deadlineRepository) Purge(ctx context.Context, id UserID) error {
+	ctx, cancel := deadline.Budget(ctx, r.budget)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.next.Purge(ctx, id)
+}
@@ -0,0 +1,41 @@
+package users
+
+// Domain event names published by the users module.
+const (
+	EventUserCreated  = "users.created"
+	EventUserUpdated  = "users.updated"
+	EventUserDeleted  = "users.deleted"
+	EventUserRestored = "users.restored"
+)
+
+// UserCreated is published after a user is registered.
+type UserCreated struct {
+	User User
+}
+
+// EventName implements events.Event.
+func (UserCreated) EventName() string { return EventUserCreated }
+
+// UserUpdated is published after the fields of a user are changed.
+type UserUpdated struct {
+	User User
+}
+
+// EventName implements events.Event.
+func (UserUpdated) EventName() string { return EventUserUpdated }
+
+// UserDeleted is published after a user is soft-deleted.
+type UserDeleted struct {
+	ID UserID
+}
+
+// EventName implements events.Event.
+func (UserDeleted) EventName() string { return EventUserDeleted }
+
+// UserRestored is published after the soft delete of a user is undone.
+type UserRestored struct {
+	User User
+}
+
+// EventName implements events.Event.
+func (UserRestored) EventName() string { return EventUserRestored }
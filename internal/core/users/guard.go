@@ -0,0 +1,41 @@
+package users
+
+import (
+	"context"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// guardResource labels the users repository in pagination guard metrics.
+const guardResource = "users"
+
+// ErrPageParams is returned by a strict pagination guard for a list
+// without a limit or above the maximum page size.
+var ErrPageParams = apperrors.New(apperrors.KindInvalid, "users_page_invalid",
+	"limit is required and must not exceed the maximum page size")
+
+// guardedRepository bounds the pages listed by the wrapped repository.
+type guardedRepository struct {
+	Repository
+	guard *pagination.Guard
+}
+
+// NewGuardedRepository wraps next so List queries without a limit or with
+// an excessive one, and pages larger than requested, are caught by guard.
+func NewGuardedRepository(next Repository, guard *pagination.Guard) Repository {
+	return &guardedRepository{Repository: next, guard: guard}
+}
+
+// List implements Repository.
+func (r *guardedRepository) List(ctx context.Context, params pagination.Params) (pagination.Page[User], error) {
+	params, err := r.guard.Params(guardResource, params)
+	if err != nil {
+		return pagination.Page[User]{}, ErrPageParams.WithCause(err)
+	}
+	page, err := r.Repository.List(ctx, params)
+	if err != nil {
+		return page, err
+	}
+	return pagination.Enforce(r.guard, guardResource, params, page)
+}
@@ -0,0 +1,64 @@
+package users_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unboundedRepository ignores the limit like a query missing its LIMIT.
+type unboundedRepository struct {
+	users.Repository
+}
+
+func (r unboundedRepository) List(ctx context.Context, _ pagination.Params) (pagination.Page[users.User], error) {
+	return r.Repository.List(ctx, pagination.Params{Limit: 1 << 20})
+}
+
+func TestGuardedRepository_BoundsPages(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepository(nil)
+	for _, name := range []string{"ann", "bob", "cid"} {
+		require.NoError(t, repo.Create(ctx, &users.User{Email: name + "@example.com", Username: name}))
+	}
+
+	guarded := users.NewGuardedRepository(unboundedRepository{repo}, pagination.NewGuard(pagination.GuardOptions{MaxLimit: 2}))
+	page, err := guarded.List(ctx, pagination.Params{})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, 3, page.Total)
+
+	strict := users.NewGuardedRepository(unboundedRepository{repo}, pagination.NewGuard(pagination.GuardOptions{Strict: true}))
+	_, err = strict.List(ctx, pagination.Params{})
+	assert.ErrorIs(t, err, users.ErrPageParams)
+	assert.ErrorIs(t, err, pagination.ErrMissingLimit)
+	_, err = strict.List(ctx, pagination.Params{Limit: 1})
+	assert.ErrorIs(t, err, pagination.ErrPageTooLarge)
+}
+
+func TestService_ListLeavesLimitToGuard(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepository(nil)
+	for i := range 150 {
+		name := "user" + strconv.Itoa(i)
+		require.NoError(t, repo.Create(ctx, &users.User{Email: name + "@example.com", Username: name}))
+	}
+
+	service := users.NewService(repo, users.WithPaginationGuard(pagination.NewGuard(pagination.GuardOptions{MaxLimit: 120})))
+	page, err := service.List(ctx, pagination.Params{Limit: 150})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 120, "the configured maximum applies")
+	page, err = service.List(ctx, pagination.Params{})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, pagination.DefaultLimit)
+
+	strict := users.NewService(repo, users.WithPaginationGuard(pagination.NewGuard(pagination.GuardOptions{Strict: true})))
+	_, err = strict.List(ctx, pagination.Params{})
+	assert.ErrorIs(t, err, pagination.ErrMissingLimit, "the guard sees lists without a limit")
+}
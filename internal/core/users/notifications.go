@@ -0,0 +1,33 @@
+package users
+
+import (
+	"context"
+
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// WelcomeTemplate is the notification template of the welcome email.
+const WelcomeTemplate = "welcome"
+
+// SendWelcome enqueues a welcome email to users as their creation is
+// published on bus. Users are created even when it cannot be enqueued.
+func SendWelcome(bus *events.Bus, service *notifications.Service, log *logger.Logger) {
+	bus.Subscribe(EventUserCreated, func(ctx context.Context, e events.Event) {
+		user := e.(UserCreated).User
+		_, err := service.Notify(ctx, notifications.Message{
+			Channel:  notifications.ChannelEmail,
+			To:       []string{user.Email},
+			Template: WelcomeTemplate,
+			Data: map[string]any{
+				"Username": user.Username,
+				"FullName": user.FullName,
+				"Email":    user.Email,
+			},
+		})
+		if err != nil {
+			log.Warnw("welcome_email_failed", "user_id", user.ID, "error", err)
+		}
+	})
+}
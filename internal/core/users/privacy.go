@@ -0,0 +1,105 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/internal/core/privacy"
+)
+
+// PrivacyRecord is the exported account data of a user.
+type PrivacyRecord struct {
+	ID        UserID    `json:"id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Phone     string    `json:"phone,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+}
+
+// privacySource exports and erases the accounts of repo.
+type privacySource struct {
+	repo   Repository
+	events events.Publisher
+}
+
+// NewPrivacySource returns the "users" privacy source over repo. Users
+// whose deletion is scheduled are soft-deleted, and restored when the
+// request is cancelled, publishing the module's events on p.
+func NewPrivacySource(repo Repository, p events.Publisher) privacy.Source {
+	return &privacySource{repo: repo, events: p}
+}
+
+// NewPrivacyLookup returns a privacy.Lookup resolving users of repo,
+// soft-deleted included.
+func NewPrivacyLookup(repo Repository) privacy.Lookup {
+	return func(ctx context.Context, userID UserID) error {
+		_, err := repo.GetByID(audit.WithDeleted(ctx), userID)
+		return err
+	}
+}
+
+func (s *privacySource) Name() string { return "users" }
+
+// Export implements privacy.Source.
+func (s *privacySource) Export(ctx context.Context, userID UserID) (any, error) {
+	user, err := s.repo.GetByID(audit.WithDeleted(ctx), userID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return PrivacyRecord{
+		ID:        user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		FullName:  user.FullName,
+		Phone:     user.Phone,
+		IsActive:  user.IsActive,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		DeletedAt: user.DeletedAt,
+	}, nil
+}
+
+// Erase implements privacy.Source.
+func (s *privacySource) Erase(ctx context.Context, userID UserID) error {
+	if err := s.repo.Purge(ctx, userID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Suspend implements privacy.Suspender. Users deleted before the request
+// are left deleted.
+func (s *privacySource) Suspend(ctx context.Context, userID UserID) (bool, error) {
+	err := s.repo.Delete(ctx, userID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s.events.Publish(ctx, UserDeleted{ID: userID})
+	return true, nil
+}
+
+// Resume implements privacy.Suspender.
+func (s *privacySource) Resume(ctx context.Context, userID UserID) error {
+	if err := s.repo.Restore(ctx, userID); err != nil {
+		return err
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.events.Publish(ctx, UserRestored{User: *user})
+	return nil
+}
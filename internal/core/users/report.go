@@ -0,0 +1,72 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/reports"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// ErrReportParams is returned for invalid users report parameters.
+var ErrReportParams = apperrors.New(apperrors.KindInvalid, "users_report_invalid_params",
+	"active must be true or false")
+
+// NewReport returns the "users" report listing the users of repo. The
+// optional "active" parameter ("true" or "false") keeps only active or
+// inactive users.
+func NewReport(repo Repository) reports.Report {
+	return reports.New("users", "Users with their status and creation date",
+		func(ctx context.Context, params map[string]string, progress reports.Progress) (reports.Content, error) {
+			var active *bool
+			if v, ok := params["active"]; ok {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return reports.Content{}, ErrReportParams
+				}
+				active = &b
+			}
+
+			table := &reports.Table{Header: []string{"ID", "Email", "Username", "Full name", "Active", "Created"}}
+			total, activeCount := 0, 0
+			for offset := 0; ; offset += pagination.MaxLimit {
+				page, err := repo.List(ctx, pagination.Params{Limit: pagination.MaxLimit, Offset: offset})
+				if err != nil {
+					return reports.Content{}, err
+				}
+				for _, user := range page.Items {
+					if active != nil && user.IsActive != *active {
+						continue
+					}
+					total++
+					if user.IsActive {
+						activeCount++
+					}
+					table.Rows = append(table.Rows, []string{
+						user.ID.String(), user.Email, user.Username, user.FullName,
+						strconv.FormatBool(user.IsActive), user.CreatedAt.UTC().Format(time.DateOnly),
+					})
+				}
+				if page.Total > 0 {
+					progress(float64(offset+len(page.Items)) / float64(page.Total))
+				}
+				if !page.HasMore() {
+					break
+				}
+			}
+
+			return reports.Content{
+				Title: "Users",
+				Sections: []reports.Section{
+					{
+						Heading:    "Summary",
+						Paragraphs: []string{fmt.Sprintf("%d users, %d active.", total, activeCount)},
+					},
+					{Heading: "Users", Table: table},
+				},
+			}, nil
+		})
+}
@@ -0,0 +1,97 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luminosita/change-me/pkg/filter"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// Repository persists users. It follows the audit conventions: writes
+// stamp the audit fields with the request principal, and reads skip
+// soft-deleted users unless ctx comes from audit.WithDeleted.
+type Repository interface {
+	// Create stores a new user, assigns its ID and stamps its creation.
+	// It returns ErrEmailTaken or ErrUsernameTaken on uniqueness violations,
+	// soft-deleted users included.
+	Create(ctx context.Context, user *User) error
+
+	// GetByID returns the user with the given ID or ErrNotFound.
+	GetByID(ctx context.Context, id UserID) (*User, error)
+
+	// GetByEmail returns the user with the given email or ErrNotFound.
+	GetByEmail(ctx context.Context, email string) (*User, error)
+
+	// List returns a page of the users matching params.Filter (see
+	// ListFilter) ordered by ID, starting after the ID of params.After
+	// when set (see AfterID).
+	List(ctx context.Context, params pagination.Params) (pagination.Page[User], error)
+
+	// Update stores the email, username, full name, phone and active flag of the
+	// user with user.ID and stamps its update; user is refreshed with the
+	// stored state. It returns ErrNotFound unless the user exists and is not
+	// soft-deleted, and the uniqueness errors of Create. When user.UpdatedAt
+	// is set, it returns ErrModified unless it equals the stored one.
+	Update(ctx context.Context, user *User) error
+
+	// Delete soft-deletes the user with the given ID or returns ErrNotFound.
+	Delete(ctx context.Context, id UserID) error
+
+	// Restore undoes the soft delete of the user with the given ID, or
+	// returns ErrNotFound unless it is soft-deleted.
+	Restore(ctx context.Context, id UserID) error
+
+	// Purge permanently removes the user with the given ID, soft-deleted
+	// or not, releasing its email and username, or returns ErrNotFound.
+	Purge(ctx context.Context, id UserID) error
+}
+
+// ListFilter declares the fields users can be listed by. Names and phone
+// numbers are left out and emails match exactly: listings are not a way
+// to look up personal data.
+var ListFilter = filter.Schema{Fields: map[string]filter.Field{
+	"id":         {Type: filter.String},
+	"email":      {Type: filter.String},
+	"username":   {Type: filter.String, Wildcard: true},
+	"is_active":  {Type: filter.Bool},
+	"created_at": {Type: filter.Time},
+	"updated_at": {Type: filter.Time},
+}}
+
+// FilterValue returns the value of the ListFilter field of user.
+func FilterValue(user User, field string) any {
+	switch field {
+	case "id":
+		return user.ID.String()
+	case "email":
+		return user.Email
+	case "username":
+		return user.Username
+	case "is_active":
+		return user.IsActive
+	case "created_at":
+		return user.CreatedAt
+	case "updated_at":
+		return user.UpdatedAt
+	}
+	return nil
+}
+
+// ListKey returns the sort key of user in List: its ID.
+func ListKey(user User) []string {
+	return []string{user.ID.String()}
+}
+
+// AfterID returns the ID of the sort key params.After, the zero ID without
+// one.
+func AfterID(params pagination.Params) (UserID, error) {
+	if len(params.After) == 0 {
+		return UserID{}, nil
+	}
+	id, err := ParseID(params.After[0])
+	if err != nil || len(params.After) != 1 {
+		return UserID{}, fmt.Errorf("%w: %q is not a user key", pagination.ErrInvalidCursor, params.After)
+	}
+	return id, nil
+}
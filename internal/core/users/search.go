@@ -0,0 +1,110 @@
+package users
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// SearchAlias is the search alias of the users index.
+const SearchAlias = "users"
+
+// SearchMapping declares the searchable fields of user documents.
+var SearchMapping = search.Mapping{
+	Text:    []string{"email", "username", "full_name"},
+	Keyword: []string{"is_active"},
+}
+
+// Search keeps the users search index in sync with the module events and
+// rebuilds it from the repository.
+type Search struct {
+	index   search.Index
+	manager *search.Manager
+	repo    Repository
+	log     *logger.Logger
+}
+
+// NewSearch creates the users search over index. Created, updated and
+// restored users are indexed and deleted users removed as their events are
+// published on bus.
+func NewSearch(index search.Index, manager *search.Manager, repo Repository, bus *events.Bus, log *logger.Logger) *Search {
+	s := &Search{index: index, manager: manager, repo: repo, log: log}
+
+	bus.Subscribe(EventUserCreated, func(ctx context.Context, e events.Event) {
+		if err := index.Put(ctx, SearchAlias, SearchDocument(e.(UserCreated).User)); err != nil {
+			log.Warnw("search_index_failed", "index", SearchAlias, "error", err)
+		}
+	})
+	bus.Subscribe(EventUserUpdated, func(ctx context.Context, e events.Event) {
+		if err := index.Put(ctx, SearchAlias, SearchDocument(e.(UserUpdated).User)); err != nil {
+			log.Warnw("search_index_failed", "index", SearchAlias, "error", err)
+		}
+	})
+	bus.Subscribe(EventUserRestored, func(ctx context.Context, e events.Event) {
+		if err := index.Put(ctx, SearchAlias, SearchDocument(e.(UserRestored).User)); err != nil {
+			log.Warnw("search_index_failed", "index", SearchAlias, "error", err)
+		}
+	})
+	bus.Subscribe(EventUserDeleted, func(ctx context.Context, e events.Event) {
+		if err := index.Delete(ctx, SearchAlias, e.(UserDeleted).ID.String()); err != nil {
+			log.Warnw("search_index_failed", "index", SearchAlias, "error", err)
+		}
+	})
+
+	return s
+}
+
+// SearchDocument returns the search document of user.
+func SearchDocument(user User) search.Document {
+	return search.Document{
+		ID: user.ID.String(),
+		Fields: map[string]string{
+			"email":     user.Email,
+			"username":  user.Username,
+			"full_name": user.FullName,
+			"is_active": strconv.FormatBool(user.IsActive),
+		},
+	}
+}
+
+// Query returns a page of the users matching q.
+func (s *Search) Query(ctx context.Context, q search.Query) (pagination.Page[search.Hit], error) {
+	return s.index.Search(ctx, SearchAlias, q)
+}
+
+// Ensure builds the index unless it exists, e.g. on the first start
+// against a cluster or on every start of an embedded index.
+func (s *Search) Ensure(ctx context.Context) error {
+	_, err := s.manager.Ensure(ctx, SearchAlias, SearchMapping, s.source)
+	return err
+}
+
+// Reindex rebuilds the index from the repository and swaps it in. It
+// returns the name of the new index version. Users written while it runs
+// may be missing from the new version until the next rebuild, as their
+// events update the version being replaced.
+func (s *Search) Reindex(ctx context.Context) (string, error) {
+	return s.manager.Rebuild(ctx, SearchAlias, SearchMapping, s.source)
+}
+
+// source yields the documents of the users in the repository.
+func (s *Search) source(ctx context.Context, yield func(search.Document) error) error {
+	for offset := 0; ; offset += pagination.MaxLimit {
+		page, err := s.repo.List(ctx, pagination.Params{Limit: pagination.MaxLimit, Offset: offset})
+		if err != nil {
+			return err
+		}
+		for _, user := range page.Items {
+			if err := yield(SearchDocument(user)); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore() {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package users
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/pkg/batch"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/luminosita/change-me/pkg/phone"
+)
+
+// CreateInput holds the fields required to register a user.
+type CreateInput struct {
+	Email    string `pii:"email"`
+	Username string
+	FullName string `pii:"name"`
+	Phone    string `pii:"phone"`
+	IsActive bool
+}
+
+// UpdateInput holds the replacement values of a user's editable fields.
+type UpdateInput struct {
+	Email    string `pii:"email"`
+	Username string
+	FullName string `pii:"name"`
+	Phone    string `pii:"phone"`
+	IsActive bool
+
+	// UpdatedAt, when set, is the last update of the user the values were
+	// derived from; the update fails with ErrModified if there was another
+	UpdatedAt time.Time
+}
+
+//go:generate go run github.com/luminosita/change-me/cmd/decorgen -type UserService
+
+// UserService is the users use-case boundary consumed by transports.
+// The Container wraps Service with the generated logging, metrics and
+// tracing decorators.
+type UserService interface {
+	Create(ctx context.Context, in CreateInput) (*User, error)
+	CreateMany(ctx context.Context, inputs []CreateInput) []batch.Outcome[*User]
+	Get(ctx context.Context, id UserID) (*User, error)
+	List(ctx context.Context, params pagination.Params) (pagination.Page[User], error)
+	Update(ctx context.Context, id UserID, in UpdateInput) (*User, error)
+	Delete(ctx context.Context, id UserID) error
+}
+
+// Service implements the users use cases.
+type Service struct {
+	repo   Repository
+	events events.Publisher
+	guard  *pagination.Guard
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithEvents publishes the module's domain events to p.
+func WithEvents(p events.Publisher) ServiceOption {
+	return func(s *Service) { s.events = p }
+}
+
+// WithPaginationGuard bounds the pages listed from the repository with g
+// instead of a guard with the default page sizes.
+func WithPaginationGuard(g *pagination.Guard) ServiceOption {
+	return func(s *Service) { s.guard = g }
+}
+
+// NewService creates a users service backed by repo.
+func NewService(repo Repository, opts ...ServiceOption) *Service {
+	s := &Service{
+		events: events.Discard,
+		guard:  pagination.NewGuard(pagination.GuardOptions{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.repo = NewGuardedRepository(repo, s.guard)
+	return s
+}
+
+// Create registers a new user. Emails are normalized to lowercase and
+// phone numbers to E.164.
+func (s *Service) Create(ctx context.Context, in CreateInput) (*User, error) {
+	number, err := normalizePhone(in.Phone)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{
+		Email:    strings.ToLower(strings.TrimSpace(in.Email)),
+		Username: strings.TrimSpace(in.Username),
+		FullName: strings.TrimSpace(in.FullName),
+		Phone:    number,
+		IsActive: in.IsActive,
+	}
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, UserCreated{User: *user})
+	return user, nil
+}
+
+// CreateMany registers users one by one and reports a per-input outcome.
+// Inputs are processed sequentially so uniqueness conflicts inside the batch
+// resolve in input order; a failing input does not stop the rest.
+func (s *Service) CreateMany(ctx context.Context, inputs []CreateInput) []batch.Outcome[*User] {
+	return batch.Process(ctx, inputs, batch.Options{Concurrency: 1}, s.Create)
+}
+
+// Get returns the user with the given ID.
+func (s *Service) Get(ctx context.Context, id UserID) (*User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns a page of users. The pagination guard defaults and bounds
+// the limit.
+func (s *Service) List(ctx context.Context, params pagination.Params) (pagination.Page[User], error) {
+	return s.repo.List(ctx, params)
+}
+
+// Update replaces the editable fields of the user with the given ID.
+// Values are normalized like on Create.
+func (s *Service) Update(ctx context.Context, id UserID, in UpdateInput) (*User, error) {
+	number, err := normalizePhone(in.Phone)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{
+		ID:       id,
+		Email:    strings.ToLower(strings.TrimSpace(in.Email)),
+		Username: strings.TrimSpace(in.Username),
+		FullName: strings.TrimSpace(in.FullName),
+		Phone:    number,
+		IsActive: in.IsActive,
+	}
+	user.UpdatedAt = in.UpdatedAt
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, UserUpdated{User: *user})
+	return user, nil
+}
+
+// Delete soft-deletes the user with the given ID.
+func (s *Service) Delete(ctx context.Context, id UserID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, UserDeleted{ID: id})
+	return nil
+}
+
+// normalizePhone returns raw in E.164 form, or "" when raw is blank.
+func normalizePhone(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	number, err := phone.Normalize(raw, "")
+	if err != nil {
+		return "", ErrInvalidPhone
+	}
+	return number, nil
+}
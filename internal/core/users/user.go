@@ -0,0 +1,40 @@
+// Package users implements the example users module: the domain model,
+// the repository contract, and the application service.
+package users
+
+import (
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/internal/core/identity"
+	"github.com/luminosita/change-me/pkg/ids"
+)
+
+// Domain errors returned by the users module.
+var (
+	ErrNotFound      = apperrors.New(apperrors.KindNotFound, "user_not_found", "user not found")
+	ErrEmailTaken    = apperrors.New(apperrors.KindConflict, "user_email_taken", "email already registered")
+	ErrUsernameTaken = apperrors.New(apperrors.KindConflict, "user_username_taken", "username already taken")
+	ErrInvalidPhone  = apperrors.New(apperrors.KindInvalid, "user_phone_invalid", "phone number must include its country calling code")
+	ErrModified      = apperrors.New(apperrors.KindConflict, "user_modified", "user was modified concurrently")
+)
+
+// UserID identifies a user.
+type UserID = identity.UserID
+
+// NewID returns a new user ID made by g, or by ids.Default when g is nil.
+func NewID(g ids.Generator) UserID { return identity.NewUserID(g) }
+
+// ParseID parses the text form of a user ID.
+func ParseID(s string) (UserID, error) { return identity.ParseUserID(s) }
+
+// User is a registered account. Repositories assign its ID and stamp its
+// audit fields.
+type User struct {
+	ID       UserID
+	Email    string `pii:"email"`
+	Username string
+	FullName string `pii:"name"`
+	Phone    string `pii:"phone"` // E.164, empty when unset
+	IsActive bool
+	audit.Fields
+}
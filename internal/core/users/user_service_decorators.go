@@ -0,0 +1,189 @@
+// Code generated by decorgen. DO NOT EDIT.
+
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/batch"
+	"github.com/luminosita/change-me/pkg/decorate"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pagination"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// userServiceName labels UserService calls in logs, metrics and spans.
+const userServiceName = "users.UserService"
+
+// userServiceLogging logs UserService calls.
+type userServiceLogging struct {
+	next UserService
+	log  *logger.Logger
+}
+
+// NewUserServiceLogging wraps next with call logging. Successful calls and
+// domain errors are logged at debug level, internal errors at error level.
+func NewUserServiceLogging(next UserService, log *logger.Logger) UserService {
+	return &userServiceLogging{next: next, log: log}
+}
+
+// Create implements UserService.
+func (d *userServiceLogging) Create(ctx context.Context, in CreateInput) (r0 *User, err error) {
+	defer func(start time.Time) { d.logCall("Create", start, err) }(time.Now())
+	return d.next.Create(ctx, in)
+}
+
+// CreateMany implements UserService.
+func (d *userServiceLogging) CreateMany(ctx context.Context, inputs []CreateInput) (r0 []batch.Outcome[*User]) {
+	defer func(start time.Time) { d.logCall("CreateMany", start, nil) }(time.Now())
+	return d.next.CreateMany(ctx, inputs)
+}
+
+// Get implements UserService.
+func (d *userServiceLogging) Get(ctx context.Context, id UserID) (r0 *User, err error) {
+	defer func(start time.Time) { d.logCall("Get", start, err) }(time.Now())
+	return d.next.Get(ctx, id)
+}
+
+// List implements UserService.
+func (d *userServiceLogging) List(ctx context.Context, params pagination.Params) (r0 pagination.Page[User], err error) {
+	defer func(start time.Time) { d.logCall("List", start, err) }(time.Now())
+	return d.next.List(ctx, params)
+}
+
+// Update implements UserService.
+func (d *userServiceLogging) Update(ctx context.Context, id UserID, in UpdateInput) (r0 *User, err error) {
+	defer func(start time.Time) { d.logCall("Update", start, err) }(time.Now())
+	return d.next.Update(ctx, id, in)
+}
+
+// Delete implements UserService.
+func (d *userServiceLogging) Delete(ctx context.Context, id UserID) (err error) {
+	defer func(start time.Time) { d.logCall("Delete", start, err) }(time.Now())
+	return d.next.Delete(ctx, id)
+}
+
+func (d *userServiceLogging) logCall(method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	switch {
+	case err == nil:
+		d.log.Debugw("service_call", "service", userServiceName, "method", method, "duration", elapsed)
+	case apperrors.KindOf(err) == apperrors.KindInternal:
+		d.log.Errorw("service_call_failed", "service", userServiceName, "method", method, "duration", elapsed, "error", err)
+	default:
+		d.log.Debugw("service_call", "service", userServiceName, "method", method, "duration", elapsed, "error", err)
+	}
+}
+
+// userServiceMetrics records UserService call latency and outcomes.
+type userServiceMetrics struct {
+	next UserService
+	rec  decorate.Recorder
+}
+
+// NewUserServiceMetrics wraps next with call metrics.
+func NewUserServiceMetrics(next UserService, rec decorate.Recorder) UserService {
+	return &userServiceMetrics{next: next, rec: rec}
+}
+
+// Create implements UserService.
+func (d *userServiceMetrics) Create(ctx context.Context, in CreateInput) (r0 *User, err error) {
+	defer func(start time.Time) { d.rec.Observe(userServiceName, "Create", time.Since(start), err) }(time.Now())
+	return d.next.Create(ctx, in)
+}
+
+// CreateMany implements UserService.
+func (d *userServiceMetrics) CreateMany(ctx context.Context, inputs []CreateInput) (r0 []batch.Outcome[*User]) {
+	defer func(start time.Time) { d.rec.Observe(userServiceName, "CreateMany", time.Since(start), nil) }(time.Now())
+	return d.next.CreateMany(ctx, inputs)
+}
+
+// Get implements UserService.
+func (d *userServiceMetrics) Get(ctx context.Context, id UserID) (r0 *User, err error) {
+	defer func(start time.Time) { d.rec.Observe(userServiceName, "Get", time.Since(start), err) }(time.Now())
+	return d.next.Get(ctx, id)
+}
+
+// List implements UserService.
+func (d *userServiceMetrics) List(ctx context.Context, params pagination.Params) (r0 pagination.Page[User], err error) {
+	defer func(start time.Time) { d.rec.Observe(userServiceName, "List", time.Since(start), err) }(time.Now())
+	return d.next.List(ctx, params)
+}
+
+// Update implements UserService.
+func (d *userServiceMetrics) Update(ctx context.Context, id UserID, in UpdateInput) (r0 *User, err error) {
+	defer func(start time.Time) { d.rec.Observe(userServiceName, "Update", time.Since(start), err) }(time.Now())
+	return d.next.Update(ctx, id, in)
+}
+
+// Delete implements UserService.
+func (d *userServiceMetrics) Delete(ctx context.Context, id UserID) (err error) {
+	defer func(start time.Time) { d.rec.Observe(userServiceName, "Delete", time.Since(start), err) }(time.Now())
+	return d.next.Delete(ctx, id)
+}
+
+// userServiceTracing opens a span per UserService call.
+type userServiceTracing struct {
+	next   UserService
+	tracer trace.Tracer
+}
+
+// NewUserServiceTracing wraps next with a span per call for methods taking a
+// context. Errors are recorded on the span.
+func NewUserServiceTracing(next UserService, tracer trace.Tracer) UserService {
+	return &userServiceTracing{next: next, tracer: tracer}
+}
+
+// Create implements UserService.
+func (d *userServiceTracing) Create(ctx context.Context, in CreateInput) (r0 *User, err error) {
+	ctx, span := d.tracer.Start(ctx, userServiceName+"/Create")
+	defer func() { d.endSpan(span, err) }()
+	return d.next.Create(ctx, in)
+}
+
+// CreateMany implements UserService.
+func (d *userServiceTracing) CreateMany(ctx context.Context, inputs []CreateInput) (r0 []batch.Outcome[*User]) {
+	ctx, span := d.tracer.Start(ctx, userServiceName+"/CreateMany")
+	defer span.End()
+	return d.next.CreateMany(ctx, inputs)
+}
+
+// Get implements UserService.
+func (d *userServiceTracing) Get(ctx context.Context, id UserID) (r0 *User, err error) {
+	ctx, span := d.tracer.Start(ctx, userServiceName+"/Get")
+	defer func() { d.endSpan(span, err) }()
+	return d.next.Get(ctx, id)
+}
+
+// List implements UserService.
+func (d *userServiceTracing) List(ctx context.Context, params pagination.Params) (r0 pagination.Page[User], err error) {
+	ctx, span := d.tracer.Start(ctx, userServiceName+"/List")
+	defer func() { d.endSpan(span, err) }()
+	return d.next.List(ctx, params)
+}
+
+// Update implements UserService.
+func (d *userServiceTracing) Update(ctx context.Context, id UserID, in UpdateInput) (r0 *User, err error) {
+	ctx, span := d.tracer.Start(ctx, userServiceName+"/Update")
+	defer func() { d.endSpan(span, err) }()
+	return d.next.Update(ctx, id, in)
+}
+
+// Delete implements UserService.
+func (d *userServiceTracing) Delete(ctx context.Context, id UserID) (err error) {
+	ctx, span := d.tracer.Start(ctx, userServiceName+"/Delete")
+	defer func() { d.endSpan(span, err) }()
+	return d.next.Delete(ctx, id)
+}
+
+// endSpan records err on span and ends it.
+func (d *userServiceTracing) endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,54 @@
+package users_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// call is a service call observed by fakeRecorder.
+type call struct {
+	method string
+	err    error
+}
+
+type fakeRecorder struct{ calls []call }
+
+func (r *fakeRecorder) Observe(_, method string, _ time.Duration, err error) {
+	r.calls = append(r.calls, call{method: method, err: err})
+}
+
+func TestDecorators_PassThroughAndRecordOutcomes(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	rec := &fakeRecorder{}
+
+	var service users.UserService = users.NewService(memory.NewUserRepository(nil))
+	service = users.NewUserServiceLogging(service, log)
+	service = users.NewUserServiceMetrics(service, rec)
+	service = users.NewUserServiceTracing(service, noop.NewTracerProvider().Tracer("test"))
+
+	ctx := context.Background()
+	_, err = service.Create(ctx, users.CreateInput{Email: "jane@example.com", Username: "jane"})
+	require.NoError(t, err)
+
+	_, err = service.Get(ctx, users.NewID(nil))
+	assert.ErrorIs(t, err, users.ErrNotFound)
+
+	outcomes := service.CreateMany(ctx, []users.CreateInput{{Email: "john@example.com", Username: "john"}})
+	require.Len(t, outcomes, 1)
+	assert.NoError(t, outcomes[0].Err)
+
+	assert.Equal(t, []call{
+		{method: "Create"},
+		{method: "Get", err: users.ErrNotFound},
+		{method: "CreateMany"},
+	}, rec.calls)
+}
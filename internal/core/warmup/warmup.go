@@ -0,0 +1,161 @@
+// Package warmup runs startup hooks after the dependencies are initialized
+// and before the instance reports ready: warming caches, priming
+// connection pools and exercising hot code paths so the first requests do
+// not pay for them.
+//
+// Hooks are best effort. A failing or timed out hook is logged and the
+// remaining hooks still run; readiness flips once every hook finished.
+package warmup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Hook prepares a component for traffic.
+type Hook interface {
+	// Name returns the unique hook identifier used for logging.
+	Name() string
+
+	// Warm runs the warmup; it should return when ctx is done.
+	Warm(ctx context.Context) error
+}
+
+// funcHook adapts a function to the Hook interface.
+type funcHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New creates a Hook from a function.
+func New(name string, fn func(ctx context.Context) error) Hook {
+	return &funcHook{name: name, fn: fn}
+}
+
+func (h *funcHook) Name() string                   { return h.name }
+func (h *funcHook) Warm(ctx context.Context) error { return h.fn(ctx) }
+
+// Report summarizes a warmup run.
+type Report struct {
+	Completed []string
+	Failed    []string
+}
+
+// Registry holds the warmup hooks of application modules and whether they
+// ran.
+type Registry struct {
+	mu    sync.Mutex
+	hooks []Hook
+	names map[string]struct{}
+	ready atomic.Bool
+}
+
+// NewRegistry creates an empty warmup registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Register adds hooks to the registry. Hooks run in registration order.
+// It returns an error if a hook name is empty or already registered.
+func (r *Registry) Register(hooks ...Hook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, h := range hooks {
+		name := h.Name()
+		if name == "" {
+			return fmt.Errorf("warmup hook name must not be empty")
+		}
+		if _, exists := r.names[name]; exists {
+			return fmt.Errorf("warmup hook %q already registered", name)
+		}
+		r.names[name] = struct{}{}
+		r.hooks = append(r.hooks, h)
+	}
+
+	return nil
+}
+
+// Names returns the registered hook names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.hooks))
+	for _, h := range r.hooks {
+		names = append(names, h.Name())
+	}
+	return names
+}
+
+// Run executes the registered hooks, each bounded by timeout (0 means
+// unbounded), logging their durations, then marks the registry ready.
+// It does not mark the registry ready when ctx is canceled first.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation
+//   - log: Structured logger
+//   - timeout: Maximum duration of each hook
+//
+// Returns:
+//   - Report: Completed and failed hook names
+func (r *Registry) Run(ctx context.Context, log *logger.Logger, timeout time.Duration) Report {
+	r.mu.Lock()
+	hooks := make([]Hook, len(r.hooks))
+	copy(hooks, r.hooks)
+	r.mu.Unlock()
+
+	var report Report
+	start := time.Now()
+	for _, h := range hooks {
+		if ctx.Err() != nil {
+			return report
+		}
+
+		hookCtx, cancel := context.WithCancel(ctx)
+		if timeout > 0 {
+			hookCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		hookStart := time.Now()
+		err := h.Warm(hookCtx)
+		cancel()
+
+		if err != nil {
+			log.Warnw("warmup_hook_failed",
+				"hook", h.Name(),
+				"duration_ms", time.Since(hookStart).Milliseconds(),
+				"error", err,
+			)
+			report.Failed = append(report.Failed, h.Name())
+			continue
+		}
+		log.Infow("warmup_hook_completed",
+			"hook", h.Name(),
+			"duration_ms", time.Since(hookStart).Milliseconds(),
+		)
+		report.Completed = append(report.Completed, h.Name())
+	}
+	if ctx.Err() != nil {
+		return report
+	}
+
+	r.ready.Store(true)
+	log.Infow("warmup_complete",
+		"hooks", len(hooks),
+		"failed", len(report.Failed),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	return report
+}
+
+// Ready reports whether the hooks ran.
+func (r *Registry) Ready() bool {
+	return r.ready.Load()
+}
@@ -0,0 +1,87 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RegisterRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	noop := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, r.Register(New("cache", noop)))
+	assert.Error(t, r.Register(New("cache", noop)))
+	assert.Error(t, r.Register(New("", noop)))
+	assert.Equal(t, []string{"cache"}, r.Names())
+}
+
+func TestRegistry_RunContinuesPastFailures(t *testing.T) {
+	r := NewRegistry()
+	var ran []string
+	require.NoError(t, r.Register(
+		New("pool", func(ctx context.Context) error {
+			ran = append(ran, "pool")
+			return errors.New("connection refused")
+		}),
+		New("cache", func(ctx context.Context) error {
+			ran = append(ran, "cache")
+			return nil
+		}),
+	))
+	assert.False(t, r.Ready())
+
+	report := r.Run(context.Background(), newTestLogger(t), 0)
+
+	assert.Equal(t, []string{"pool", "cache"}, ran)
+	assert.Equal(t, []string{"cache"}, report.Completed)
+	assert.Equal(t, []string{"pool"}, report.Failed)
+	assert.True(t, r.Ready(), "failed hooks do not hold readiness back")
+}
+
+func TestRegistry_RunBoundsHooks(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(New("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})))
+
+	report := r.Run(context.Background(), newTestLogger(t), 10*time.Millisecond)
+
+	assert.Equal(t, []string{"slow"}, report.Failed)
+	assert.True(t, r.Ready())
+}
+
+func TestRegistry_RunCanceled(t *testing.T) {
+	r := NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran []string
+	require.NoError(t, r.Register(
+		New("first", func(ctx context.Context) error {
+			ran = append(ran, "first")
+			cancel()
+			return nil
+		}),
+		New("second", func(ctx context.Context) error {
+			ran = append(ran, "second")
+			return nil
+		}),
+	))
+
+	r.Run(ctx, newTestLogger(t), 0)
+
+	assert.Equal(t, []string{"first"}, ran)
+	assert.False(t, r.Ready(), "a shutdown during warmup never reports ready")
+}
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
@@ -0,0 +1,229 @@
+// Package watchdog flags requests that are still in flight after a
+// threshold, so hangs are diagnosed before clients or proxies time out.
+//
+// Requests register when they start and deregister when they finish. A
+// scan loop logs each request once when it exceeds the threshold,
+// optionally with the stack of the goroutine serving it, and counts it in
+// a metric; flagged requests log their final duration when they finish.
+package watchdog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultInterval is how often in-flight requests are scanned when the
+// threshold does not call for a shorter interval.
+const DefaultInterval = time.Second
+
+// maxStackBytes bounds the goroutine dump searched for a request's stack.
+const maxStackBytes = 8 << 20
+
+// Options configures a Watchdog.
+type Options struct {
+	Threshold time.Duration         // In-flight duration flagging a request
+	Interval  time.Duration         // Scan interval (default the smaller of 1s and Threshold/4)
+	Stacks    bool                  // Log the stack of the goroutine serving flagged requests
+	Metrics   prometheus.Registerer // Registers the slow request counter when set
+}
+
+// Request describes an in-flight request.
+type Request struct {
+	Method    string
+	Route     string // Route template, a bounded metrics label
+	Path      string
+	RequestID string
+	TraceID   string
+}
+
+// Watchdog tracks in-flight requests. It is safe for concurrent use.
+type Watchdog struct {
+	opts Options
+	log  *logger.Logger
+	slow *prometheus.CounterVec
+	now  func() time.Time
+
+	mu       sync.Mutex
+	next     uint64
+	inflight map[uint64]*entry
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// entry is a registered request.
+type entry struct {
+	Request
+	start     time.Time
+	goroutine uint64
+	flagged   bool
+}
+
+// New creates a watchdog and starts its scan loop.
+//
+// Parameters:
+//   - opts: Threshold, scan interval, stack capture and metrics registry
+//   - log: Structured logger receiving slow requests
+//
+// Returns:
+//   - *Watchdog: Running watchdog; call Close to stop it
+func New(opts Options, log *logger.Logger) *Watchdog {
+	if opts.Interval <= 0 {
+		opts.Interval = min(DefaultInterval, opts.Threshold/4)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	w := &Watchdog{
+		opts:     opts,
+		log:      log,
+		now:      time.Now,
+		inflight: make(map[uint64]*entry),
+		done:     make(chan struct{}),
+	}
+	if opts.Metrics != nil {
+		w.slow = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_slow_requests_total",
+			Help: "Requests still in flight after the slow request threshold by method and route.",
+		}, []string{"method", "route"})
+		opts.Metrics.MustRegister(w.slow)
+	}
+
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// Start registers a request served by the calling goroutine. The returned
+// function deregisters it and must be called when the request finishes.
+func (w *Watchdog) Start(req Request) func() {
+	e := &entry{Request: req, start: w.now()}
+	if w.opts.Stacks {
+		e.goroutine = goroutineID()
+	}
+
+	w.mu.Lock()
+	w.next++
+	id := w.next
+	w.inflight[id] = e
+	w.mu.Unlock()
+
+	return func() {
+		w.mu.Lock()
+		delete(w.inflight, id)
+		flagged := e.flagged
+		w.mu.Unlock()
+		if flagged {
+			w.log.Infow("slow_request_completed", "method", e.Method, "route", e.Route,
+				"duration_ms", w.now().Sub(e.start).Milliseconds(), "request_id", e.RequestID)
+		}
+	}
+}
+
+// Check flags the requests in flight for longer than the threshold that
+// were not flagged yet and returns how many it flagged.
+func (w *Watchdog) Check() int {
+	now := w.now()
+	var flagged []*entry
+	w.mu.Lock()
+	for _, e := range w.inflight {
+		if !e.flagged && now.Sub(e.start) > w.opts.Threshold {
+			e.flagged = true
+			flagged = append(flagged, e)
+		}
+	}
+	w.mu.Unlock()
+	if len(flagged) == 0 {
+		return 0
+	}
+
+	var stacks []byte
+	if w.opts.Stacks {
+		stacks = allStacks()
+	}
+	for _, e := range flagged {
+		if w.slow != nil {
+			w.slow.WithLabelValues(e.Method, e.Route).Inc()
+		}
+		fields := []any{"method", e.Method, "route", e.Route, "path", e.Path,
+			"elapsed_ms", now.Sub(e.start).Milliseconds(), "threshold_ms", w.opts.Threshold.Milliseconds(),
+			"request_id", e.RequestID, "trace_id", e.TraceID}
+		if stack := goroutineStack(stacks, e.goroutine); stack != "" {
+			fields = append(fields, "stack", stack)
+		}
+		w.log.Warnw("slow_request", fields...)
+	}
+	return len(flagged)
+}
+
+// Close stops the scan loop. It is safe to call more than once.
+func (w *Watchdog) Close() {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+}
+
+// run scans the in-flight requests every interval until Close.
+func (w *Watchdog) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [running]:" header of its stack.
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i > 0 {
+		header = header[:i]
+	}
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
+
+// allStacks returns the stacks of all goroutines, truncated to
+// maxStackBytes.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStackBytes {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// goroutineStack extracts the stack of goroutine id from a dump; empty
+// when id is zero or the goroutine is not in the dump.
+func goroutineStack(stacks []byte, id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	header := []byte("goroutine " + strconv.FormatUint(id, 10) + " [")
+	i := bytes.Index(stacks, header)
+	if i < 0 {
+		return ""
+	}
+	stack := stacks[i:]
+	if end := bytes.Index(stack, []byte("\n\n")); end >= 0 {
+		stack = stack[:end]
+	}
+	return string(stack)
+}
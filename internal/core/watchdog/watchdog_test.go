@@ -0,0 +1,118 @@
+package watchdog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoggedWatchdog returns a watchdog logging JSON lines to the returned
+// buffer, with a clock advanced by the test.
+func newLoggedWatchdog(t *testing.T, opts Options) (*Watchdog, *time.Time, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	log, err := logger.New(logger.Config{
+		Level:     "DEBUG",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Format: "json", Level: "DEBUG", Writer: &buf}},
+	})
+	require.NoError(t, err)
+
+	opts.Interval = time.Hour
+	w := New(opts, log)
+	t.Cleanup(w.Close)
+	now := time.Unix(1_700_000_000, 0)
+	w.now = func() time.Time { return now }
+	return w, &now, &buf
+}
+
+// logEntries decodes the entries of buf with message msg.
+func logEntries(t *testing.T, buf *bytes.Buffer, msg string) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	scanner := bufio.NewScanner(buf)
+	scanner.Buffer(nil, maxStackBytes)
+	for scanner.Scan() {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		if entry["msg"] == msg {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestCheck_FlagsSlowRequestsOnce(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	w, now, buf := newLoggedWatchdog(t, Options{Threshold: 5 * time.Second, Metrics: reg})
+
+	done := w.Start(Request{Method: "GET", Route: "/api/v1/reports/:id", Path: "/api/v1/reports/7", RequestID: "req-1"})
+	fast := w.Start(Request{Method: "GET", Route: "/health"})
+	*now = now.Add(3 * time.Second)
+	fast()
+	assert.Zero(t, w.Check())
+
+	*now = now.Add(3 * time.Second)
+	assert.Equal(t, 1, w.Check())
+	assert.Zero(t, w.Check(), "requests are flagged once")
+	*now = now.Add(time.Second)
+	done()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(w.slow.WithLabelValues("GET", "/api/v1/reports/:id")))
+	slow := logEntries(t, bytes.NewBuffer(buf.Bytes()), "slow_request")
+	require.Len(t, slow, 1)
+	assert.Equal(t, "req-1", slow[0]["request_id"])
+	assert.Equal(t, 6000.0, slow[0]["elapsed_ms"])
+	assert.NotContains(t, slow[0], "stack", "stacks are opt-in")
+	completed := logEntries(t, buf, "slow_request_completed")
+	require.Len(t, completed, 1)
+	assert.Equal(t, 7000.0, completed[0]["duration_ms"])
+}
+
+func TestCheck_LogsStackOfServingGoroutine(t *testing.T) {
+	w, now, buf := newLoggedWatchdog(t, Options{Threshold: time.Second, Stacks: true})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		done := w.Start(Request{Method: "POST", Route: "/api/v1/reports"})
+		defer done()
+		close(started)
+		hangingHandler(release)
+	}()
+	<-started
+	defer close(release)
+
+	*now = now.Add(2 * time.Second)
+	require.Equal(t, 1, w.Check())
+
+	slow := logEntries(t, buf, "slow_request")
+	require.Len(t, slow, 1)
+	stack, _ := slow[0]["stack"].(string)
+	assert.Contains(t, stack, "hangingHandler")
+	assert.NotContains(t, stack, "TestCheck_LogsStackOfServingGoroutine(", "only the serving goroutine is logged")
+}
+
+// hangingHandler blocks until release is closed.
+func hangingHandler(release chan struct{}) {
+	<-release
+}
+
+func TestGoroutineStack(t *testing.T) {
+	dump := []byte("goroutine 7 [running]:\nmain.a()\n\ngoroutine 71 [select]:\nmain.b()\n")
+
+	assert.Equal(t, "goroutine 7 [running]:\nmain.a()", goroutineStack(dump, 7))
+	assert.Equal(t, "goroutine 71 [select]:\nmain.b()\n", goroutineStack(dump, 71))
+	assert.Empty(t, goroutineStack(dump, 8))
+	assert.Empty(t, goroutineStack(dump, 0))
+	assert.NotZero(t, goroutineID())
+}
@@ -0,0 +1,174 @@
+// Package worker runs long-lived background consumers (job processors,
+// queue consumers, schedulers) under a shared lifecycle.
+//
+// Workers run until their context is canceled. A worker returning an error
+// stops the whole group so the process can exit and be restarted by its
+// supervisor, mirroring how a failing HTTP listener ends the server.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/clock"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Worker is a background consumer.
+type Worker interface {
+	// Name returns the unique worker identifier used for logging.
+	Name() string
+
+	// Run blocks until ctx is canceled or the worker fails. Returning nil
+	// or ctx.Err() after cancellation is a clean stop.
+	Run(ctx context.Context) error
+}
+
+// funcWorker adapts a function to the Worker interface.
+type funcWorker struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New creates a Worker from a blocking function.
+func New(name string, fn func(ctx context.Context) error) Worker {
+	return &funcWorker{name: name, fn: fn}
+}
+
+func (w *funcWorker) Name() string                  { return w.name }
+func (w *funcWorker) Run(ctx context.Context) error { return w.fn(ctx) }
+
+// Every creates a scheduled Worker that calls fn once per interval.
+// Errors from fn are logged and do not stop the schedule.
+//
+// Parameters:
+//   - name: Unique worker name
+//   - interval: Delay between runs
+//   - log: Structured logger
+//   - fn: Job executed on each tick
+//
+// Returns:
+//   - Worker: Scheduled worker
+func Every(name string, interval time.Duration, log *logger.Logger, fn func(ctx context.Context) error) Worker {
+	return EveryClock(name, interval, clock.Real(), log, fn)
+}
+
+// EveryClock is Every with the ticks of clk, so schedules can be driven
+// by a fake clock in tests.
+func EveryClock(name string, interval time.Duration, clk clock.Clock, log *logger.Logger, fn func(ctx context.Context) error) Worker {
+	return New(name, func(ctx context.Context) error {
+		ticker := clk.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C():
+				if err := fn(ctx); err != nil {
+					log.Errorw("scheduled_job_failed", "worker", name, "error", err)
+				}
+			}
+		}
+	})
+}
+
+// Registry holds the workers contributed by application modules.
+type Registry struct {
+	mu      sync.Mutex
+	workers []Worker
+	names   map[string]struct{}
+}
+
+// NewRegistry creates an empty worker registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Register adds workers to the registry.
+// It returns an error if a worker name is empty or already registered.
+func (r *Registry) Register(workers ...Worker) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, w := range workers {
+		name := w.Name()
+		if name == "" {
+			return fmt.Errorf("worker name must not be empty")
+		}
+		if _, exists := r.names[name]; exists {
+			return fmt.Errorf("worker %q already registered", name)
+		}
+		r.names[name] = struct{}{}
+		r.workers = append(r.workers, w)
+	}
+
+	return nil
+}
+
+// Names returns the registered worker names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.workers))
+	for _, w := range r.workers {
+		names = append(names, w.Name())
+	}
+	return names
+}
+
+// Run starts every registered worker and blocks until ctx is canceled and
+// all workers have stopped, or until a worker fails. Workers that finish
+// early do not end the run. A failure cancels the remaining workers and is
+// returned once they have stopped.
+//
+// Parameters:
+//   - ctx: Context whose cancellation requests shutdown
+//   - log: Structured logger
+//
+// Returns:
+//   - error: First worker failure, nil on clean shutdown
+func (r *Registry) Run(ctx context.Context, log *logger.Logger) error {
+	r.mu.Lock()
+	workers := make([]Worker, len(r.workers))
+	copy(workers, r.workers)
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w Worker) {
+			defer wg.Done()
+
+			log.Infow("worker_started", "worker", w.Name())
+			err := w.Run(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				log.Errorw("worker_failed", "worker", w.Name(), "error", err)
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("worker %q failed: %w", w.Name(), err)
+					cancel()
+				})
+				return
+			}
+			log.Infow("worker_stopped", "worker", w.Name())
+		}(w)
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RegisterRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	noop := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, r.Register(New("jobs", noop)))
+	assert.Error(t, r.Register(New("jobs", noop)))
+	assert.Error(t, r.Register(New("", noop)))
+	assert.Equal(t, []string{"jobs"}, r.Names())
+}
+
+func TestRegistry_RunStopsOnCancel(t *testing.T) {
+	r := NewRegistry()
+	var stopped int32
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		atomic.AddInt32(&stopped, 1)
+		return ctx.Err()
+	}
+	require.NoError(t, r.Register(New("a", block), New("b", block)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx, newTestLogger(t)) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("workers did not stop")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&stopped))
+}
+
+func TestRegistry_RunFailureStopsOthers(t *testing.T) {
+	r := NewRegistry()
+	boom := errors.New("boom")
+	require.NoError(t, r.Register(
+		New("failing", func(ctx context.Context) error { return boom }),
+		New("blocking", func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}),
+	))
+
+	err := r.Run(context.Background(), newTestLogger(t))
+
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestRegistry_RunBlocksWithoutWorkers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.NoError(t, NewRegistry().Run(ctx, newTestLogger(t)))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestEvery_RunsOnSchedule(t *testing.T) {
+	var runs int32
+	w := Every("tick", 5*time.Millisecond, newTestLogger(t), func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("logged, not fatal")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, w.Run(ctx))
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&runs), int32(2))
+}
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
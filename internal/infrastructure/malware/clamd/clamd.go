@@ -0,0 +1,103 @@
+// Package clamd scans files with a ClamAV daemon over its INSTREAM
+// protocol.
+package clamd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/luminosita/change-me/internal/core/malware"
+)
+
+// chunkSize is the size of the INSTREAM chunks sent to clamd.
+const chunkSize = 64 << 10
+
+// Scanner is a malware.Scanner streaming content to clamd.
+type Scanner struct {
+	network string
+	address string
+	dialer  net.Dialer
+}
+
+// New creates a scanner for the clamd listening on address: host:port for
+// TCP, or unix:/path/to/clamd.sock for a local socket.
+func New(address string) (*Scanner, error) {
+	if address == "" {
+		return nil, errors.New("clamd address is required")
+	}
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		return &Scanner{network: "unix", address: path}, nil
+	}
+	return &Scanner{network: "tcp", address: address}, nil
+}
+
+// Scan implements malware.Scanner. Content above clamd's StreamMaxLength
+// fails the scan.
+func (s *Scanner) Scan(ctx context.Context, r io.Reader) (malware.Verdict, error) {
+	conn, err := s.dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return malware.Verdict{}, fmt.Errorf("connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if err := stream(conn, r); err != nil {
+		return malware.Verdict{}, fmt.Errorf("stream to clamd: %w", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return malware.Verdict{}, fmt.Errorf("read clamd reply: %w", err)
+	}
+	return parseReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// stream sends r as an INSTREAM command: length-prefixed chunks ended by
+// an empty one.
+func stream(w io.Writer, r io.Reader) error {
+	if _, err := io.WriteString(w, "zINSTREAM\x00"); err != nil {
+		return err
+	}
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, err := w.Write(buf[:4+n]); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// parseReply reads a reply such as "stream: OK" or
+// "stream: Eicar-Test-Signature FOUND".
+func parseReply(reply string) (malware.Verdict, error) {
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return malware.Verdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return malware.Verdict{Infected: true, Threat: strings.TrimSuffix(result, " FOUND")}, nil
+	case strings.HasSuffix(result, " ERROR"):
+		return malware.Verdict{}, fmt.Errorf("clamd: %s", strings.TrimSuffix(result, " ERROR"))
+	}
+	return malware.Verdict{}, fmt.Errorf("unexpected clamd reply %q", reply)
+}
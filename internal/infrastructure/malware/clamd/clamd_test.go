@@ -0,0 +1,114 @@
+package clamd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd accepts INSTREAM commands and replies with reply for the
+// received content.
+func fakeClamd(t *testing.T, reply func(content []byte) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				command, err := r.ReadString(0)
+				if err != nil || command != "zINSTREAM\x00" {
+					return
+				}
+				var content bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&content, r, int64(size)); err != nil {
+						return
+					}
+				}
+				_, _ = io.WriteString(conn, reply(content.Bytes())+"\x00")
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func eicarReply(content []byte) string {
+	if bytes.Contains(content, []byte("EICAR")) {
+		return "stream: Eicar-Test-Signature FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestScanner_Scan(t *testing.T) {
+	scanner, err := New(fakeClamd(t, eicarReply))
+	require.NoError(t, err)
+
+	verdict, err := scanner.Scan(context.Background(), strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.False(t, verdict.Infected)
+
+	// Content spanning several chunks is streamed in full
+	infected := strings.Repeat("x", 3*chunkSize) + "EICAR"
+	verdict, err = scanner.Scan(context.Background(), strings.NewReader(infected))
+	require.NoError(t, err)
+	assert.True(t, verdict.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", verdict.Threat)
+}
+
+func TestScanner_ScanErrors(t *testing.T) {
+	scanner, err := New(fakeClamd(t, func([]byte) string { return "INSTREAM size limit exceeded. ERROR" }))
+	require.NoError(t, err)
+	_, err = scanner.Scan(context.Background(), strings.NewReader("hello"))
+	assert.ErrorContains(t, err, "size limit exceeded")
+
+	// A daemon that never replies fails with the context
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			_, _ = io.Copy(io.Discard, conn)
+		}
+	}()
+	scanner, err = New(ln.Addr().String())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = scanner.Scan(ctx, strings.NewReader("hello"))
+	assert.Error(t, err)
+}
+
+func TestNew_UnixSocket(t *testing.T) {
+	scanner, err := New("unix:/run/clamav/clamd.ctl")
+	require.NoError(t, err)
+	assert.Equal(t, "unix", scanner.network)
+	assert.Equal(t, "/run/clamav/clamd.ctl", scanner.address)
+
+	_, err = New("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+// Package httpscan scans files with an external scanning API.
+//
+// The content is posted as the raw request body to the configured URL,
+// with the token as a bearer credential, and the API answers 200 with
+// its verdict:
+//
+//	{"infected": true, "threat": "Eicar-Test-Signature"}
+//
+// Gateways to commercial scanners can adopt this contract to plug in
+// without an adapter per vendor.
+package httpscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/luminosita/change-me/internal/core/malware"
+)
+
+// Scanner is a malware.Scanner posting content to a scanning API.
+type Scanner struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// New creates a scanner posting to url, authenticated with token when
+// set, with client.
+func New(url, token string, client *http.Client) (*Scanner, error) {
+	if url == "" {
+		return nil, errors.New("scan url is required")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Scanner{url: url, token: token, http: client}, nil
+}
+
+// verdict is the response body of the scanning API.
+type verdict struct {
+	Infected bool   `json:"infected"`
+	Threat   string `json:"threat"`
+}
+
+// Scan implements malware.Scanner.
+func (s *Scanner) Scan(ctx context.Context, r io.Reader) (malware.Verdict, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, r)
+	if err != nil {
+		return malware.Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Accept", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return malware.Verdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return malware.Verdict{}, fmt.Errorf("scan api status %d: %s", resp.StatusCode, body)
+	}
+
+	var v verdict
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return malware.Verdict{}, fmt.Errorf("decode scan verdict: %w", err)
+	}
+	return malware.Verdict{Infected: v.Infected, Threat: v.Threat}, nil
+}
@@ -0,0 +1,54 @@
+package httpscan
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	var authorization string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("EICAR")) {
+			_, _ = io.WriteString(w, `{"infected":true,"threat":"Eicar-Test-Signature"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"infected":false}`)
+	}))
+	defer api.Close()
+
+	scanner, err := New(api.URL, "secret", api.Client())
+	require.NoError(t, err)
+
+	verdict, err := scanner.Scan(context.Background(), strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.False(t, verdict.Infected)
+	assert.Equal(t, "Bearer secret", authorization)
+
+	verdict, err = scanner.Scan(context.Background(), strings.NewReader("EICAR"))
+	require.NoError(t, err)
+	assert.True(t, verdict.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", verdict.Threat)
+}
+
+func TestScanner_ScanFailsOnErrorStatus(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "scanner overloaded", http.StatusServiceUnavailable)
+	}))
+	defer api.Close()
+
+	scanner, err := New(api.URL, "", api.Client())
+	require.NoError(t, err)
+
+	_, err = scanner.Scan(context.Background(), strings.NewReader("hello"))
+	assert.ErrorContains(t, err, "status 503")
+}
@@ -0,0 +1,60 @@
+// Package kafka provides Kafka-backed producers for application events.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/luminosita/change-me/internal/core/metering"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// UsageSink publishes metering events as JSON messages keyed by subject,
+// so each subject's events stay ordered within a partition.
+type UsageSink struct {
+	writer    *kafkago.Writer
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewUsageSink creates a sink producing to topic on brokers.
+func NewUsageSink(brokers []string, topic string) *UsageSink {
+	return &UsageSink{
+		writer: &kafkago.Writer{
+			Addr:         kafkago.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafkago.Hash{},
+			RequiredAcks: kafkago.RequireAll,
+		},
+	}
+}
+
+// Write implements metering.Sink.
+func (s *UsageSink) Write(ctx context.Context, events []metering.Event) error {
+	msgs := make([]kafkago.Message, 0, len(events))
+	for _, e := range events {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encode usage event: %w", err)
+		}
+		msgs = append(msgs, kafkago.Message{
+			Key:   []byte(e.Subject),
+			Value: value,
+			Time:  e.OccurredAt,
+		})
+	}
+	if err := s.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("publish usage events: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the producer. It is safe to call more than once.
+func (s *UsageSink) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.writer.Close()
+	})
+	return s.closeErr
+}
@@ -0,0 +1,135 @@
+// Package fcm delivers push notifications through the Firebase Cloud
+// Messaging HTTP v1 API, authenticating with a service account key.
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+)
+
+// DefaultBaseURL is the FCM API.
+const DefaultBaseURL = "https://fcm.googleapis.com"
+
+// Config names the service account key file and the Firebase project;
+// the project defaults to the one of the key.
+type Config struct {
+	CredentialsFile string
+	ProjectID       string
+
+	// BaseURL overrides DefaultBaseURL
+	BaseURL string
+}
+
+// Notifier is a notifications.Notifier sending push notifications to
+// device registration tokens.
+type Notifier struct {
+	endpoint string
+	tokens   *tokenSource
+	http     *http.Client
+}
+
+// New creates an FCM notifier sending requests with client.
+func New(cfg Config, client *http.Client) (*Notifier, error) {
+	data, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("fcm credentials: %w", err)
+	}
+	var key serviceAccount
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("fcm credentials: %w", err)
+	}
+	if cfg.ProjectID == "" {
+		cfg.ProjectID = key.ProjectID
+	}
+	if cfg.ProjectID == "" {
+		return nil, errors.New("fcm project id is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	tokens, err := newTokenSource(key, client)
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{
+		endpoint: strings.TrimSuffix(cfg.BaseURL, "/") + "/v1/projects/" + cfg.ProjectID + "/messages:send",
+		tokens:   tokens,
+		http:     client,
+	}, nil
+}
+
+// Send implements notifications.Notifier, sending one message per device
+// token; the subject is the notification title. Unregistered and invalid
+// tokens fail permanently.
+func (n *Notifier) Send(ctx context.Context, notification notifications.Notification) error {
+	for _, to := range notification.To {
+		if err := n.send(ctx, to, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send sends notification to one device token.
+func (n *Notifier) send(ctx context.Context, to string, notification notifications.Notification) error {
+	token, err := n.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token": to,
+			"notification": map[string]string{
+				"title": notification.Subject,
+				"body":  notification.Body,
+			},
+		},
+	})
+	if err != nil {
+		return notifications.Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	var apiErr struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+	err = fmt.Errorf("fcm responded %d (%s): %s", resp.StatusCode, apiErr.Error.Status, apiErr.Error.Message)
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusForbidden:
+		return notifications.Permanent(err)
+	case http.StatusUnauthorized:
+		n.tokens.Reset()
+	}
+	return err
+}
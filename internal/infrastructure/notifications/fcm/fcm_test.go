@@ -0,0 +1,98 @@
+package fcm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier_Send(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var exchanges int
+	var messages []map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		require.Len(t, parts, 3)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		assert.Contains(t, string(claims), `"iss":"push@demo.iam.gserviceaccount.com"`)
+
+		_, _ = w.Write([]byte(`{"access_token":"ya29.token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/v1/projects/demo/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer ya29.token", r.Header.Get("Authorization"))
+		var body struct {
+			Message map[string]any `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body.Message["token"] == "stale" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"status":"NOT_FOUND","message":"Requested entity was not found."}}`))
+			return
+		}
+		messages = append(messages, body.Message)
+		_, _ = w.Write([]byte(`{"name":"projects/demo/messages/1"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	credentials, _ := json.Marshal(serviceAccount{
+		ProjectID:   "demo",
+		ClientEmail: "push@demo.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    server.URL + "/token",
+	})
+	file := filepath.Join(t.TempDir(), "service-account.json")
+	require.NoError(t, os.WriteFile(file, credentials, 0o600))
+
+	n, err := New(Config{CredentialsFile: file, BaseURL: server.URL}, server.Client())
+	require.NoError(t, err)
+
+	err = n.Send(context.Background(), notifications.Notification{
+		To: []string{"device-a", "device-b"}, Subject: "New message", Body: "Jane sent you a message",
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "device-a", messages[0]["token"])
+	assert.Equal(t, map[string]any{"title": "New message", "body": "Jane sent you a message"}, messages[0]["notification"])
+	assert.Equal(t, 1, exchanges, "access token is cached")
+
+	err = n.Send(context.Background(), notifications.Notification{To: []string{"stale"}, Body: "x"})
+	require.Error(t, err)
+	assert.True(t, notifications.IsPermanent(err))
+}
+
+func TestNew_RequiresProject(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "service-account.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"client_email":"a@b","private_key":"x"}`), 0o600))
+
+	_, err := New(Config{CredentialsFile: file}, nil)
+	assert.ErrorContains(t, err, "project id")
+}
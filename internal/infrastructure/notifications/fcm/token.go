@@ -0,0 +1,147 @@
+package fcm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// messagingScope is the OAuth scope of the FCM API.
+const messagingScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// defaultTokenURI is the Google OAuth token endpoint.
+const defaultTokenURI = "https://oauth2.googleapis.com/token"
+
+// serviceAccount holds the fields of a service account key file.
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// tokenSource exchanges signed service account assertions for access
+// tokens (RFC 7523) and caches them until shortly before they expire.
+type tokenSource struct {
+	email    string
+	key      *rsa.PrivateKey
+	tokenURI string
+	http     *http.Client
+	now      func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newTokenSource creates the token source of the service account key.
+func newTokenSource(key serviceAccount, client *http.Client) (*tokenSource, error) {
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, errors.New("fcm credentials: client_email and private_key are required")
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("fcm credentials: private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fcm credentials: %w", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("fcm credentials: private_key is not an RSA key")
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = defaultTokenURI
+	}
+	return &tokenSource{email: key.ClientEmail, key: rsaKey, tokenURI: key.TokenURI, http: client, now: time.Now}, nil
+}
+
+// Token returns a valid access token.
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.now().Before(s.expires) {
+		return s.token, nil
+	}
+
+	assertion, err := s.assertion()
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("fcm token: responded %d: %s", resp.StatusCode, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("fcm token: decode response: %w", err)
+	}
+	s.token = token.AccessToken
+	// Renew a minute early so tokens do not expire in flight
+	s.expires = s.now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
+
+// Reset drops the cached token, e.g. after it was rejected.
+func (s *tokenSource) Reset() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
+
+// assertion returns the RS256-signed JWT asserting the service account.
+func (s *tokenSource) assertion() (string, error) {
+	now := s.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   s.email,
+		"scope": messagingScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("fcm token: sign assertion: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
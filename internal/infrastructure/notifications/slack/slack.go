@@ -0,0 +1,85 @@
+// Package slack delivers notifications to Slack channels through incoming
+// webhooks.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+)
+
+// Notifier is a notifications.Notifier posting to Slack incoming webhooks.
+// Recipients name webhooks, each posting to the channel it was created
+// for.
+type Notifier struct {
+	webhooks map[string]string
+	http     *http.Client
+}
+
+// New creates a Slack notifier posting to webhooks, keyed by recipient
+// name, with client.
+func New(webhooks map[string]string, client *http.Client) (*Notifier, error) {
+	if len(webhooks) == 0 {
+		return nil, errors.New("slack webhooks are required")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Notifier{webhooks: webhooks, http: client}, nil
+}
+
+// Send implements notifications.Notifier. The subject, when set, is
+// posted in bold above the body. Unknown recipients and rejected payloads
+// fail permanently.
+func (n *Notifier) Send(ctx context.Context, notification notifications.Notification) error {
+	text := notification.Body
+	if notification.Subject != "" {
+		text = "*" + notification.Subject + "*\n" + text
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return notifications.Permanent(err)
+	}
+
+	for _, to := range notification.To {
+		webhook, ok := n.webhooks[to]
+		if !ok {
+			return notifications.Permanent(fmt.Errorf("unknown slack webhook %q", to))
+		}
+		if err := n.post(ctx, webhook, payload); err != nil {
+			return fmt.Errorf("slack webhook %s: %w", to, err)
+		}
+	}
+	return nil
+}
+
+// post sends payload to webhook.
+func (n *Notifier) post(ctx context.Context, webhook string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(payload))
+	if err != nil {
+		return notifications.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	err = fmt.Errorf("responded %d: %s", resp.StatusCode, body)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return notifications.Permanent(err)
+	}
+	return err
+}
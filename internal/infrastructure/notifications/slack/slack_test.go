@@ -0,0 +1,51 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier_Send(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/revoked" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("invalid_token"))
+			return
+		}
+		var payload struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		texts = append(texts, payload.Text)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	n, err := New(map[string]string{
+		"alerts":  server.URL + "/alerts",
+		"revoked": server.URL + "/revoked",
+	}, server.Client())
+	require.NoError(t, err)
+
+	err = n.Send(context.Background(), notifications.Notification{
+		To: []string{"alerts"}, Subject: "Deploy", Body: "v1.2.0 is live",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*Deploy*\nv1.2.0 is live"}, texts)
+
+	err = n.Send(context.Background(), notifications.Notification{To: []string{"revoked"}, Body: "x"})
+	require.Error(t, err)
+	assert.True(t, notifications.IsPermanent(err))
+
+	err = n.Send(context.Background(), notifications.Notification{To: []string{"unknown"}, Body: "x"})
+	require.Error(t, err)
+	assert.True(t, notifications.IsPermanent(err))
+}
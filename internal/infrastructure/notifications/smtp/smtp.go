@@ -0,0 +1,185 @@
+// Package smtp delivers email notifications through an SMTP relay.
+//
+// Connections upgrade with STARTTLS when the server offers it; PLAIN
+// authentication is only sent over TLS or to a local relay.
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	netsmtp "net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+)
+
+// Config locates and authenticates against the relay.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Notifier is a notifications.Notifier sending email over SMTP.
+type Notifier struct {
+	cfg  Config
+	from *mail.Address
+	now  func() time.Time
+}
+
+// New creates an SMTP notifier sending as cfg.From.
+func New(cfg Config) (*Notifier, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp sender %q: %w", cfg.From, err)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &Notifier{cfg: cfg, from: from, now: time.Now}, nil
+}
+
+// Send implements notifications.Notifier. Recipients rejected by the relay
+// fail permanently.
+func (n *Notifier) Send(ctx context.Context, notification notifications.Notification) error {
+	msg, err := n.message(notification)
+	if err != nil {
+		return notifications.Permanent(err)
+	}
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := netsmtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := c.Auth(netsmtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return classify("smtp auth", err)
+		}
+	}
+	if err := c.Mail(n.from.Address); err != nil {
+		return classify("smtp sender", err)
+	}
+	for _, to := range notification.To {
+		if err := c.Rcpt(to); err != nil {
+			return classify("smtp recipient "+to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return classify("smtp data", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return classify("smtp data", err)
+	}
+	return c.Quit()
+}
+
+// classify marks permanent (5xx) SMTP replies with notifications.Permanent.
+func classify(op string, err error) error {
+	err = fmt.Errorf("%s: %w", op, err)
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return notifications.Permanent(err)
+	}
+	return err
+}
+
+// message returns the RFC 5322 message of notification, a
+// multipart/alternative one when it has an HTML body.
+func (n *Notifier) message(notification notifications.Notification) ([]byte, error) {
+	to := make([]string, len(notification.To))
+	for i, addr := range notification.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", addr, err)
+		}
+		to[i] = parsed.String()
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) { fmt.Fprintf(&buf, "%s: %s\r\n", key, value) }
+	header("From", n.from.String())
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", notification.Subject))
+	header("Date", n.now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if notification.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuoted(&buf, notification.Body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", notification.Body},
+		{"text/html; charset=utf-8", notification.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuoted(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeQuoted writes s quoted-printable encoded to w.
+func writeQuoted(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
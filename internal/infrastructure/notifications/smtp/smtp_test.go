@@ -0,0 +1,141 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRelay accepts one SMTP session and records its envelope and
+// message, rejecting the recipients in reject.
+type fakeRelay struct {
+	addr   string
+	reject string
+	rcpts  []string
+	data   string
+	done   chan struct{}
+}
+
+func newFakeRelay(t *testing.T, reject string) *fakeRelay {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	r := &fakeRelay{addr: ln.Addr().String(), reject: reject, done: make(chan struct{})}
+	go func() {
+		defer close(r.done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+		reply := func(line string) { rw.WriteString(line + "\r\n"); rw.Flush() }
+
+		reply("220 fake ESMTP")
+		for {
+			line, err := rw.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 fake")
+			case strings.HasPrefix(cmd, "MAIL FROM"):
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "RCPT TO"):
+				rcpt := strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>")
+				if rcpt == r.reject {
+					reply("550 no such user")
+					continue
+				}
+				r.rcpts = append(r.rcpts, rcpt)
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := rw.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				r.data = data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return r
+}
+
+func (r *fakeRelay) notifier(t *testing.T) *Notifier {
+	host, port, _ := net.SplitHostPort(r.addr)
+	p, _ := strconv.Atoi(port)
+	n, err := New(Config{Host: host, Port: p, From: "App <noreply@example.com>"})
+	require.NoError(t, err)
+	return n
+}
+
+func TestNotifier_SendsAlternativeMessage(t *testing.T) {
+	relay := newFakeRelay(t, "")
+	n := relay.notifier(t)
+
+	err := n.Send(context.Background(), notifications.Notification{
+		Channel: notifications.ChannelEmail,
+		To:      []string{"jane@example.com"},
+		Subject: "Welcome, Jane",
+		Body:    "Hello Jane",
+		HTML:    "<p>Hello Jane</p>",
+	})
+	require.NoError(t, err)
+	<-relay.done
+
+	assert.Equal(t, []string{"jane@example.com"}, relay.rcpts)
+	msg, err := mail.ReadMessage(strings.NewReader(relay.data))
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome, Jane", msg.Header.Get("Subject"))
+	assert.Equal(t, `"App" <noreply@example.com>`, msg.Header.Get("From"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			break
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, types)
+}
+
+func TestNotifier_RejectedRecipientIsPermanent(t *testing.T) {
+	relay := newFakeRelay(t, "ghost@example.com")
+	n := relay.notifier(t)
+
+	err := n.Send(context.Background(), notifications.Notification{
+		To:   []string{"ghost@example.com"},
+		Body: "Hello",
+	})
+	require.Error(t, err)
+	assert.True(t, notifications.IsPermanent(err))
+}
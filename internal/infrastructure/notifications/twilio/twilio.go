@@ -0,0 +1,102 @@
+// Package twilio delivers SMS notifications through the Twilio Messages
+// API.
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+)
+
+// DefaultBaseURL is the Twilio REST API.
+const DefaultBaseURL = "https://api.twilio.com"
+
+// Config authenticates against the account and names the sending number
+// or messaging service (MG...).
+type Config struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+
+	// BaseURL overrides DefaultBaseURL, e.g. for a regional edge
+	BaseURL string
+}
+
+// Notifier is a notifications.Notifier sending SMS with Twilio.
+type Notifier struct {
+	cfg      Config
+	endpoint string
+	http     *http.Client
+}
+
+// New creates a Twilio notifier sending requests with client.
+func New(cfg Config, client *http.Client) (*Notifier, error) {
+	if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.From == "" {
+		return nil, errors.New("twilio account sid, auth token and sender are required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	endpoint := strings.TrimSuffix(cfg.BaseURL, "/") + "/2010-04-01/Accounts/" + url.PathEscape(cfg.AccountSID) + "/Messages.json"
+	return &Notifier{cfg: cfg, endpoint: endpoint, http: client}, nil
+}
+
+// Send implements notifications.Notifier, sending one message per
+// recipient. Requests Twilio rejects (4xx other than 429) fail
+// permanently.
+func (n *Notifier) Send(ctx context.Context, notification notifications.Notification) error {
+	for _, to := range notification.To {
+		if err := n.send(ctx, to, notification.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send sends body to one recipient.
+func (n *Notifier) send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "Body": {body}}
+	if strings.HasPrefix(n.cfg.From, "MG") {
+		form.Set("MessagingServiceSid", n.cfg.From)
+	} else {
+		form.Set("From", n.cfg.From)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(n.cfg.AccountSID, n.cfg.AuthToken)
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	var apiErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+	err = fmt.Errorf("twilio responded %d (code %d): %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return notifications.Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package twilio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier_Send(t *testing.T) {
+	var forms []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "secret", pass)
+		require.NoError(t, r.ParseForm())
+
+		if r.PostForm.Get("To") == "+15550000000" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+			return
+		}
+		forms = append(forms, map[string]string{
+			"To": r.PostForm.Get("To"), "From": r.PostForm.Get("From"), "Body": r.PostForm.Get("Body"),
+		})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM1"}`))
+	}))
+	defer server.Close()
+
+	n, err := New(Config{AccountSID: "AC123", AuthToken: "secret", From: "+15551234567", BaseURL: server.URL}, server.Client())
+	require.NoError(t, err)
+
+	err = n.Send(context.Background(), notifications.Notification{
+		To:   []string{"+15557654321", "+15559876543"},
+		Body: "Your code is 123456",
+	})
+	require.NoError(t, err)
+	require.Len(t, forms, 2)
+	assert.Equal(t, map[string]string{"To": "+15557654321", "From": "+15551234567", "Body": "Your code is 123456"}, forms[0])
+
+	err = n.Send(context.Background(), notifications.Notification{To: []string{"+15550000000"}, Body: "Hi"})
+	require.Error(t, err)
+	assert.True(t, notifications.IsPermanent(err))
+	assert.Contains(t, err.Error(), "21211")
+}
+
+func TestNotifier_ServerErrorsAreRetryable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	n, err := New(Config{AccountSID: "AC123", AuthToken: "secret", From: "+15551234567", BaseURL: server.URL}, nil)
+	require.NoError(t, err)
+
+	err = n.Send(context.Background(), notifications.Notification{To: []string{"+15557654321"}, Body: "Hi"})
+	require.Error(t, err)
+	assert.False(t, notifications.IsPermanent(err))
+}
@@ -0,0 +1,173 @@
+// Package local provides an objectstore.Store over a local directory.
+//
+// Download links point at the application's files route and carry an
+// expiry and an HMAC signature over the key and expiry, verified by Open,
+// so only links the store handed out are served. Instances sharing the
+// directory must share the signing key.
+package local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/objectstore"
+)
+
+// ErrLinkInvalid is returned by Open for expired or forged links.
+var ErrLinkInvalid = errors.New("object link invalid or expired")
+
+// Store is an objectstore.Store keeping objects as files below a
+// directory.
+type Store struct {
+	dir     string
+	baseURL string
+	key     []byte
+	now     func() time.Time
+}
+
+// New creates a store below dir, created when missing. Links are baseURL
+// followed by the object key, signed with signingKey.
+func New(dir, baseURL string, signingKey []byte) (*Store, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("object store signing key is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("object store: %w", err)
+	}
+	return &Store{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), key: signingKey, now: time.Now}, nil
+}
+
+// Put implements objectstore.Store. Objects are written to a temporary
+// file and renamed into place, so readers never see partial content.
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// URL implements objectstore.Store.
+func (s *Store) URL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return "", objectstore.ErrNotFound
+	}
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// Delete implements objectstore.Store.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List implements objectstore.Lister. Partially written uploads are not
+// listed.
+func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if objectstore.ValidKey(key) && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// Get implements objectstore.Lister.
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, objectstore.ErrNotFound
+	}
+	return f, err
+}
+
+// Open returns the object of a link's key, expiry and signature. It fails
+// with ErrLinkInvalid before touching the directory when the link is
+// expired or was not signed by the store.
+func (s *Store) Open(key, expires, signature string) (*os.File, error) {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > unix ||
+		!hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return nil, ErrLinkInvalid
+	}
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, objectstore.ErrNotFound
+	}
+	return f, err
+}
+
+// sign returns the signature of a link to key expiring at expires.
+func (s *Store) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path returns the file of key.
+func (s *Store) path(key string) (string, error) {
+	if !objectstore.ValidKey(key) {
+		return "", objectstore.ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,84 @@
+package local
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/objectstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SignedLinks(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(t.TempDir(), "/api/v1/files/", []byte("secret"))
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Put(ctx, "reports/a.pdf", strings.NewReader("%PDF"), "application/pdf"))
+	link, err := store.URL(ctx, "reports/a.pdf", time.Minute)
+	require.NoError(t, err)
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/files/reports/a.pdf", u.Path)
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	f, err := store.Open("reports/a.pdf", expires, signature)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(f)
+	f.Close()
+	assert.Equal(t, "%PDF", string(data))
+
+	_, err = store.Open("reports/b.pdf", expires, signature)
+	assert.ErrorIs(t, err, ErrLinkInvalid, "signatures are bound to the key")
+
+	now = now.Add(2 * time.Minute)
+	_, err = store.Open("reports/a.pdf", expires, signature)
+	assert.ErrorIs(t, err, ErrLinkInvalid, "links expire")
+}
+
+func TestStore_Keys(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(t.TempDir(), "/files", []byte("secret"))
+	require.NoError(t, err)
+
+	for _, key := range []string{"../etc/passwd", "/abs", "a//b", "a/./b", ""} {
+		assert.ErrorIs(t, store.Put(ctx, key, strings.NewReader("x"), ""), objectstore.ErrInvalidKey, key)
+	}
+
+	_, err = store.URL(ctx, "missing.pdf", time.Minute)
+	assert.ErrorIs(t, err, objectstore.ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, "missing.pdf"))
+}
+
+func TestStore_ListAndGet(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(t.TempDir(), "/files", []byte("secret"))
+	require.NoError(t, err)
+	for _, key := range []string{"recordings/2.json", "recordings/1.json", "reports/a.pdf"} {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader(key), ""))
+	}
+
+	keys, err := store.List(ctx, "recordings/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"recordings/1.json", "recordings/2.json"}, keys)
+	keys, err = store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+
+	r, err := store.Get(ctx, "reports/a.pdf")
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "reports/a.pdf", string(data))
+
+	_, err = store.Get(ctx, "reports/b.pdf")
+	assert.ErrorIs(t, err, objectstore.ErrNotFound)
+}
@@ -0,0 +1,109 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	bbolt "go.etcd.io/bbolt"
+)
+
+// CacheStore is a cache.Store persisted in a DB, so cached results
+// survive restarts of a single-instance deployment. Expired entries are
+// dropped when read and purged by compaction.
+type CacheStore struct {
+	db  *DB
+	now func() time.Time
+}
+
+// NewCacheStore creates a cache store over db.
+func NewCacheStore(db *DB) *CacheStore {
+	return &CacheStore{db: db, now: time.Now}
+}
+
+// Entries are stored as the expiry in Unix nanoseconds followed by the
+// value.
+const expiryLen = 8
+
+// Get returns the value under key if present and not expired.
+func (s *CacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	var expired bool
+	err := s.db.view(ctx, func(tx *bbolt.Tx) error {
+		entry := tx.Bucket(bucketCache).Get([]byte(key))
+		if len(entry) < expiryLen {
+			return nil
+		}
+		if !s.now().Before(expiry(entry)) {
+			expired = true
+			return nil
+		}
+		value = bytes.Clone(entry[expiryLen:])
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if expired {
+		_ = s.Delete(ctx, key)
+	}
+	return value, value != nil, nil
+}
+
+// Set stores value under key for ttl.
+func (s *CacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := make([]byte, expiryLen+len(value))
+	binary.BigEndian.PutUint64(entry, uint64(s.now().Add(ttl).UnixNano()))
+	copy(entry[expiryLen:], value)
+	return s.db.update(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketCache).Put([]byte(key), entry)
+	})
+}
+
+// Delete removes keys.
+func (s *CacheStore) Delete(ctx context.Context, keys ...string) error {
+	return s.db.update(ctx, func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucketCache)
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeletePrefix removes every key starting with prefix.
+func (s *CacheStore) DeletePrefix(ctx context.Context, prefix string) error {
+	return s.db.update(ctx, func(tx *bbolt.Tx) error {
+		c := tx.Bucket(bucketCache).Cursor()
+		p := []byte(prefix)
+		for k, _ := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, _ = c.Next() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// purgeExpired removes the cache entries expired at now.
+func (d *DB) purgeExpired(now time.Time) error {
+	return d.update(context.Background(), func(tx *bbolt.Tx) error {
+		c := tx.Bucket(bucketCache).Cursor()
+		for k, entry := c.First(); k != nil; k, entry = c.Next() {
+			if len(entry) < expiryLen || !now.Before(expiry(entry)) {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// expiry returns the expiry of a cache entry.
+func expiry(entry []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(entry)))
+}
@@ -0,0 +1,137 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/luminosita/change-me/internal/core/consent"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// ConsentRepository is a consent.Repository persisted in a DB. Policy
+// versions and acceptances are stored as JSON under their name or
+// principal followed by a sequence number, so a prefix scan returns them
+// in insertion order.
+type ConsentRepository struct {
+	db *DB
+}
+
+// NewConsentRepository creates a consent repository over db.
+func NewConsentRepository(db *DB) *ConsentRepository {
+	return &ConsentRepository{db: db}
+}
+
+// Publish implements consent.Repository.
+func (r *ConsentRepository) Publish(ctx context.Context, policy *consent.Policy) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(bucketPolicies)
+		versions, err := scanSequenced[consent.Policy](bucket, policy.Name)
+		if err != nil {
+			return err
+		}
+		for _, p := range versions {
+			if p.Version == policy.Version {
+				return consent.ErrVersionExists
+			}
+		}
+		return putSequenced(bucket, policy.Name, policy)
+	})
+}
+
+// Versions implements consent.Repository.
+func (r *ConsentRepository) Versions(ctx context.Context, name string) ([]consent.Policy, error) {
+	var versions []consent.Policy
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		versions, err = scanSequenced[consent.Policy](tx.Bucket(bucketPolicies), name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, consent.ErrPolicyNotFound
+	}
+	slices.Reverse(versions)
+	return versions, nil
+}
+
+// Latest implements consent.Repository.
+func (r *ConsentRepository) Latest(ctx context.Context) ([]consent.Policy, error) {
+	latest := make(map[string]consent.Policy)
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketPolicies).ForEach(func(_, data []byte) error {
+			var policy consent.Policy
+			if err := json.Unmarshal(data, &policy); err != nil {
+				return err
+			}
+			latest[policy.Name] = policy
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]consent.Policy, 0, len(latest))
+	for _, p := range latest {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Accept implements consent.Repository.
+func (r *ConsentRepository) Accept(ctx context.Context, acceptance *consent.Acceptance) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		return putSequenced(tx.Bucket(bucketAcceptances), acceptance.Principal, acceptance)
+	})
+}
+
+// Acceptances implements consent.Repository.
+func (r *ConsentRepository) Acceptances(ctx context.Context, principal string) ([]consent.Acceptance, error) {
+	var out []consent.Acceptance
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		out, err = scanSequenced[consent.Acceptance](tx.Bucket(bucketAcceptances), principal)
+		return err
+	})
+	return out, err
+}
+
+// sequencedKey returns the key of the seq-th value stored under owner.
+func sequencedKey(owner string, seq uint64) []byte {
+	key := append([]byte(owner), 0)
+	return binary.BigEndian.AppendUint64(key, seq)
+}
+
+// putSequenced stores v as JSON under owner and the next sequence of bucket.
+func putSequenced(bucket *bbolt.Bucket, owner string, v any) error {
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(sequencedKey(owner, seq), data)
+}
+
+// scanSequenced decodes the values stored under owner in insertion order.
+func scanSequenced[T any](bucket *bbolt.Bucket, owner string) ([]T, error) {
+	prefix := append([]byte(owner), 0)
+	var out []T
+	c := bucket.Cursor()
+	for k, data := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, data = c.Next() {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package bolt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/consent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsentRepository_VersionsAndAcceptances(t *testing.T) {
+	db, path := openTestDB(t)
+	repo := NewConsentRepository(db)
+	ctx := context.Background()
+
+	for _, p := range []consent.Policy{
+		{Name: "terms", Version: "v1"},
+		{Name: "privacy", Version: "2024-01"},
+		{Name: "terms", Version: "v2"},
+	} {
+		require.NoError(t, repo.Publish(ctx, &p))
+	}
+	assert.ErrorIs(t, repo.Publish(ctx, &consent.Policy{Name: "terms", Version: "v1"}), consent.ErrVersionExists)
+
+	require.NoError(t, repo.Accept(ctx, &consent.Acceptance{Principal: "alice", Policy: "terms", Version: "v1"}))
+	require.NoError(t, repo.Accept(ctx, &consent.Acceptance{Principal: "bob", Policy: "terms", Version: "v2"}))
+	require.NoError(t, repo.Accept(ctx, &consent.Acceptance{Principal: "alice", Policy: "terms", Version: "v2"}))
+
+	// Policies and acceptances survive reopening the file
+	require.NoError(t, db.Close())
+	db, err := Open(path, Options{})
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewConsentRepository(db)
+
+	versions, err := repo.Versions(ctx, "terms")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "v2", versions[0].Version)
+	assert.Equal(t, "v1", versions[1].Version)
+	_, err = repo.Versions(ctx, "term")
+	assert.ErrorIs(t, err, consent.ErrPolicyNotFound)
+
+	latest, err := repo.Latest(ctx)
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.Equal(t, "privacy", latest[0].Name)
+	assert.Equal(t, "v2", latest[1].Version)
+
+	history, err := repo.Acceptances(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "v1", history[0].Version)
+	assert.Equal(t, "v2", history[1].Version)
+	history, err = repo.Acceptances(ctx, "carol")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
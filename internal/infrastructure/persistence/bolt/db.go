@@ -0,0 +1,289 @@
+// Package bolt provides repository and cache implementations over an
+// embedded bbolt database file, so small deployments run as a single
+// binary without Redis or Postgres.
+//
+// Data survives restarts but is local to the instance: run one replica
+// per database file. The file grows as data is rewritten; Compact rewrites
+// it to its live size and Backup streams a consistent snapshot while
+// serving.
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// Buckets of the stores sharing a database.
+var (
+	bucketUsers           = []byte("users")
+	bucketUsersByEmail    = []byte("users_by_email")
+	bucketUsersByUsername = []byte("users_by_username")
+	bucketCache           = []byte("cache")
+	bucketDeletions       = []byte("privacy_deletions")
+	bucketPolicies        = []byte("consent_policies")
+	bucketAcceptances     = []byte("consent_acceptances")
+	bucketToggles         = []byte("toggles")
+	bucketToggleChanges   = []byte("toggle_changes")
+	bucketTwoFactor       = []byte("two_factor")
+)
+
+// compactTxSize bounds the bytes copied per transaction when compacting.
+const compactTxSize = 4 << 20
+
+// Options configures a DB.
+type Options struct {
+	// CompactInterval compacts the database and purges expired cache
+	// entries periodically; 0 disables it
+	CompactInterval time.Duration
+
+	// Metrics registers the database size metrics when set
+	Metrics prometheus.Registerer
+
+	Logger *logger.Logger
+}
+
+// DB is an embedded database file shared by the stores of this package.
+// Compaction briefly blocks their operations.
+type DB struct {
+	path string
+	log  *logger.Logger
+
+	mu sync.RWMutex // Held exclusively while the file is swapped
+	db *bbolt.DB
+
+	compactions prometheus.Counter
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// CompactResult reports the file size before and after a compaction.
+type CompactResult struct {
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+}
+
+// Open opens or creates the database at path.
+func Open(path string, opts Options) (*DB, error) {
+	db, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DB{
+		path: path,
+		log:  opts.Logger,
+		db:   db,
+		compactions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "embedded_store_compactions_total",
+			Help: "Compactions of the embedded store file.",
+		}),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if opts.Metrics != nil {
+		opts.Metrics.MustRegister(d.compactions, &collector{db: d})
+	}
+
+	if opts.CompactInterval > 0 {
+		go d.compactLoop(opts.CompactInterval)
+	} else {
+		close(d.done)
+	}
+	return d, nil
+}
+
+// open opens the bbolt file at path and creates the buckets.
+func open(path string) (*bbolt.DB, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open embedded store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{bucketUsers, bucketUsersByEmail, bucketUsersByUsername, bucketCache, bucketDeletions, bucketPolicies, bucketAcceptances, bucketToggles, bucketToggleChanges, bucketTwoFactor} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open embedded store %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// view runs fn in a read-only transaction. bbolt transactions cannot be
+// interrupted, so ctx is checked once the database is available: requests
+// whose deadline passed while a compaction held it fail without querying.
+func (d *DB) view(ctx context.Context, fn func(tx *bbolt.Tx) error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.db.View(fn)
+}
+
+// update runs fn in a read-write transaction, checking ctx like view.
+func (d *DB) update(ctx context.Context, fn func(tx *bbolt.Tx) error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.db.Update(fn)
+}
+
+// Size returns the size of the database in bytes.
+func (d *DB) Size() (int64, error) {
+	var size int64
+	err := d.view(context.Background(), func(tx *bbolt.Tx) error {
+		size = tx.Size()
+		return nil
+	})
+	return size, err
+}
+
+// Backup writes a consistent snapshot of the database to w, without
+// blocking writers, and returns the bytes written.
+func (d *DB) Backup(w io.Writer) (int64, error) {
+	var n int64
+	err := d.view(context.Background(), func(tx *bbolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// Compact purges expired cache entries and rewrites the database file to
+// its live size.
+func (d *DB) Compact(ctx context.Context) (CompactResult, error) {
+	if err := d.purgeExpired(time.Now()); err != nil {
+		return CompactResult{}, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return CompactResult{}, err
+	}
+
+	var result CompactResult
+	if info, err := os.Stat(d.path); err == nil {
+		result.SizeBefore = info.Size()
+	}
+
+	tmp := d.path + ".compact"
+	_ = os.Remove(tmp)
+	dst, err := bbolt.Open(tmp, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return result, fmt.Errorf("compact embedded store: %w", err)
+	}
+	if err := bbolt.Compact(dst, d.db, compactTxSize); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return result, fmt.Errorf("compact embedded store: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return result, fmt.Errorf("compact embedded store: %w", err)
+	}
+
+	// Swap the compacted file in; the stores wait on d.mu meanwhile
+	if err := d.db.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return result, fmt.Errorf("compact embedded store: %w", err)
+	}
+	renameErr := os.Rename(tmp, d.path)
+	db, err := open(d.path)
+	if err != nil {
+		return result, err
+	}
+	d.db = db
+	if renameErr != nil {
+		_ = os.Remove(tmp)
+		return result, fmt.Errorf("compact embedded store: %w", renameErr)
+	}
+
+	if info, err := os.Stat(d.path); err == nil {
+		result.SizeAfter = info.Size()
+	}
+	d.compactions.Inc()
+	return result, nil
+}
+
+// compactLoop compacts the database every interval until Close.
+func (d *DB) compactLoop(interval time.Duration) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			result, err := d.Compact(context.Background())
+			if d.log == nil {
+				continue
+			}
+			if err != nil {
+				d.log.Errorw("embedded_store_compaction_failed", "error", err)
+				continue
+			}
+			d.log.Infow("embedded_store_compacted", "size_before", result.SizeBefore, "size_after", result.SizeAfter)
+		}
+	}
+}
+
+// Close stops the periodic compaction and closes the database.
+func (d *DB) Close() error {
+	select {
+	case <-d.stop:
+	default:
+		close(d.stop)
+	}
+	<-d.done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.Close()
+}
+
+// collector reports the database size and the keys per bucket at scrape
+// time.
+type collector struct {
+	db *DB
+}
+
+var (
+	sizeDesc = prometheus.NewDesc("embedded_store_size_bytes",
+		"Size of the embedded store file.", nil, nil)
+	keysDesc = prometheus.NewDesc("embedded_store_keys",
+		"Keys in the embedded store per bucket.", []string{"bucket"}, nil)
+)
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sizeDesc
+	ch <- keysDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	_ = c.db.view(context.Background(), func(tx *bbolt.Tx) error {
+		ch <- prometheus.MustNewConstMetric(sizeDesc, prometheus.GaugeValue, float64(tx.Size()))
+		return tx.ForEach(func(name []byte, b *bbolt.Bucket) error {
+			ch <- prometheus.MustNewConstMetric(keysDesc, prometheus.GaugeValue, float64(b.Stats().KeyN), string(name))
+			return nil
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheStore_Expiry(t *testing.T) {
+	db, _ := openTestDB(t)
+	store := NewCacheStore(db)
+	now := time.Unix(1000, 0)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "users:1", []byte("a"), time.Minute))
+	require.NoError(t, store.Set(ctx, "users:2", []byte("b"), time.Hour))
+	require.NoError(t, store.Set(ctx, "other", []byte("c"), time.Hour))
+
+	value, ok, err := store.Get(ctx, "users:1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), value)
+
+	now = now.Add(2 * time.Minute)
+	_, ok, err = store.Get(ctx, "users:1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.DeletePrefix(ctx, "users:"))
+	_, ok, _ = store.Get(ctx, "users:2")
+	assert.False(t, ok)
+	_, ok, _ = store.Get(ctx, "other")
+	assert.True(t, ok)
+}
+
+func TestDB_CompactAndBackup(t *testing.T) {
+	db, path := openTestDB(t)
+	store := NewCacheStore(db)
+	ctx := context.Background()
+
+	value := bytes.Repeat([]byte("x"), 4096)
+	for i := range 200 {
+		require.NoError(t, store.Set(ctx, fmt.Sprintf("tmp:%d", i), value, time.Hour))
+	}
+	require.NoError(t, store.Set(ctx, "keep", []byte("v"), time.Hour))
+	require.NoError(t, store.DeletePrefix(ctx, "tmp:"))
+
+	result, err := db.Compact(ctx)
+	require.NoError(t, err)
+	assert.Less(t, result.SizeAfter, result.SizeBefore)
+	_, err = os.Stat(path + ".compact")
+	assert.True(t, os.IsNotExist(err))
+
+	// The stores keep working on the swapped file
+	got, ok, err := store.Get(ctx, "keep")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v"), got)
+
+	var backup bytes.Buffer
+	n, err := db.Backup(&backup)
+	require.NoError(t, err)
+	assert.Equal(t, int64(backup.Len()), n)
+
+	restored := filepath.Join(t.TempDir(), "restored.db")
+	require.NoError(t, os.WriteFile(restored, backup.Bytes(), 0o600))
+	copyDB, err := Open(restored, Options{})
+	require.NoError(t, err)
+	defer copyDB.Close()
+	got, ok, err = NewCacheStore(copyDB).Get(ctx, "keep")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v"), got)
+}
+
+func TestDB_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	db, err := Open(filepath.Join(t.TempDir(), "store.db"), Options{Metrics: reg})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, NewCacheStore(db).Set(context.Background(), "k", []byte("v"), time.Hour))
+
+	expected := `
+# HELP embedded_store_keys Keys in the embedded store per bucket.
+# TYPE embedded_store_keys gauge
+embedded_store_keys{bucket="cache"} 1
+embedded_store_keys{bucket="consent_acceptances"} 0
+embedded_store_keys{bucket="consent_policies"} 0
+embedded_store_keys{bucket="privacy_deletions"} 0
+embedded_store_keys{bucket="toggle_changes"} 0
+embedded_store_keys{bucket="toggles"} 0
+embedded_store_keys{bucket="two_factor"} 0
+embedded_store_keys{bucket="users"} 0
+embedded_store_keys{bucket="users_by_email"} 0
+embedded_store_keys{bucket="users_by_username"} 0
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "embedded_store_keys"))
+
+	count, err := testutil.GatherAndCount(reg, "embedded_store_size_bytes")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
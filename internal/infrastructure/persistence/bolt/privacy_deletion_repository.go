@@ -0,0 +1,95 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/luminosita/change-me/internal/core/privacy"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// PrivacyDeletionRepository is a privacy.DeletionRepository persisted in
+// a DB. Requests are stored as JSON under their ID.
+type PrivacyDeletionRepository struct {
+	db *DB
+}
+
+// NewPrivacyDeletionRepository creates a deletion request repository
+// over db.
+func NewPrivacyDeletionRepository(db *DB) *PrivacyDeletionRepository {
+	return &PrivacyDeletionRepository{db: db}
+}
+
+// Create implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Create(ctx context.Context, deletion *privacy.Deletion) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		return putDeletion(tx, deletion)
+	})
+}
+
+// Get implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Get(ctx context.Context, id string) (*privacy.Deletion, error) {
+	var deletion *privacy.Deletion
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		deletion, err = getDeletion(tx, id)
+		return err
+	})
+	return deletion, err
+}
+
+// Update implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Update(ctx context.Context, id string, fn func(*privacy.Deletion) error) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		deletion, err := getDeletion(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(deletion); err != nil {
+			return err
+		}
+		return putDeletion(tx, deletion)
+	})
+}
+
+// Scheduled implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Scheduled(ctx context.Context) ([]privacy.Deletion, error) {
+	var out []privacy.Deletion
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketDeletions).ForEach(func(_, data []byte) error {
+			var deletion privacy.Deletion
+			if err := json.Unmarshal(data, &deletion); err != nil {
+				return err
+			}
+			if deletion.Status == privacy.DeletionScheduled {
+				out = append(out, deletion)
+			}
+			return nil
+		})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].PurgeAfter.Before(out[j].PurgeAfter) })
+	return out, err
+}
+
+// getDeletion decodes the request of id, or returns ErrDeletionNotFound.
+func getDeletion(tx *bbolt.Tx, id string) (*privacy.Deletion, error) {
+	data := tx.Bucket(bucketDeletions).Get([]byte(id))
+	if data == nil {
+		return nil, privacy.ErrDeletionNotFound
+	}
+	var deletion privacy.Deletion
+	if err := json.Unmarshal(data, &deletion); err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// putDeletion stores deletion under its ID.
+func putDeletion(tx *bbolt.Tx, deletion *privacy.Deletion) error {
+	data, err := json.Marshal(deletion)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucketDeletions).Put([]byte(deletion.ID), data)
+}
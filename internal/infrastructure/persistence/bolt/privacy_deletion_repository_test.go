@@ -0,0 +1,58 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/identity"
+	"github.com/luminosita/change-me/internal/core/privacy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivacyDeletionRepository_ScheduledInPurgeOrder(t *testing.T) {
+	db, path := openTestDB(t)
+	repo := NewPrivacyDeletionRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for i, id := range []string{"later", "sooner", "done"} {
+		require.NoError(t, repo.Create(ctx, &privacy.Deletion{
+			ID:         id,
+			UserID:     identity.UserID{15: byte(i + 1)},
+			Status:     privacy.DeletionScheduled,
+			PurgeAfter: now.Add(time.Duration(2-i) * time.Hour),
+		}))
+	}
+	require.NoError(t, repo.Update(ctx, "done", func(d *privacy.Deletion) error {
+		d.Status = privacy.DeletionCompleted
+		d.Trail = append(d.Trail, privacy.TrailEntry{At: now, Action: privacy.ActionCompleted})
+		return nil
+	}))
+	failing := errors.New("rejected")
+	assert.ErrorIs(t, repo.Update(ctx, "later", func(*privacy.Deletion) error { return failing }), failing)
+	assert.ErrorIs(t, repo.Update(ctx, "missing", func(*privacy.Deletion) error { return nil }), privacy.ErrDeletionNotFound)
+
+	// Requests survive reopening the file
+	require.NoError(t, db.Close())
+	db, err := Open(path, Options{})
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewPrivacyDeletionRepository(db)
+
+	scheduled, err := repo.Scheduled(ctx)
+	require.NoError(t, err)
+	require.Len(t, scheduled, 2)
+	assert.Equal(t, "sooner", scheduled[0].ID)
+	assert.Equal(t, "later", scheduled[1].ID)
+
+	done, err := repo.Get(ctx, "done")
+	require.NoError(t, err)
+	assert.Equal(t, privacy.DeletionCompleted, done.Status)
+	require.Len(t, done.Trail, 1)
+	assert.Equal(t, now, done.Trail[0].At)
+	_, err = repo.Get(ctx, "missing")
+	assert.ErrorIs(t, err, privacy.ErrDeletionNotFound)
+}
@@ -0,0 +1,136 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/luminosita/change-me/internal/core/toggles"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// ToggleRepository is a toggles.Repository persisted in a DB. Overrides
+// are stored as JSON under their kind and name, so a scan returns them in
+// order; changes are stored under their sequence number.
+type ToggleRepository struct {
+	db *DB
+}
+
+// NewToggleRepository creates a toggle repository over db.
+func NewToggleRepository(db *DB) *ToggleRepository {
+	return &ToggleRepository{db: db}
+}
+
+// List implements toggles.Repository.
+func (r *ToggleRepository) List(ctx context.Context) ([]toggles.Toggle, error) {
+	var out []toggles.Toggle
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketToggles).ForEach(func(_, data []byte) error {
+			var t toggles.Toggle
+			if err := json.Unmarshal(data, &t); err != nil {
+				return err
+			}
+			out = append(out, t)
+			return nil
+		})
+	})
+	return out, err
+}
+
+// Get implements toggles.Repository.
+func (r *ToggleRepository) Get(ctx context.Context, kind toggles.Kind, name string) (toggles.Toggle, error) {
+	var t toggles.Toggle
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		t, err = getToggle(tx.Bucket(bucketToggles), kind, name)
+		return err
+	})
+	return t, err
+}
+
+// Put implements toggles.Repository.
+func (r *ToggleRepository) Put(ctx context.Context, toggle *toggles.Toggle, change *toggles.Change) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(bucketToggles)
+		current, err := getToggle(bucket, toggle.Kind, toggle.Name)
+		if err != nil && !errors.Is(err, toggles.ErrToggleNotFound) {
+			return err
+		}
+		if current.Version != toggle.Version-1 {
+			return toggles.ErrVersionConflict
+		}
+		data, err := json.Marshal(toggle)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(toggleKey(toggle.Kind, toggle.Name), data); err != nil {
+			return err
+		}
+		return putChange(tx.Bucket(bucketToggleChanges), change)
+	})
+}
+
+// Delete implements toggles.Repository.
+func (r *ToggleRepository) Delete(ctx context.Context, kind toggles.Kind, name string, version int64, change *toggles.Change) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(bucketToggles)
+		current, err := getToggle(bucket, kind, name)
+		if err != nil {
+			return err
+		}
+		if current.Version != version {
+			return toggles.ErrVersionConflict
+		}
+		if err := bucket.Delete(toggleKey(kind, name)); err != nil {
+			return err
+		}
+		return putChange(tx.Bucket(bucketToggleChanges), change)
+	})
+}
+
+// Changes implements toggles.Repository.
+func (r *ToggleRepository) Changes(ctx context.Context, limit int) ([]toggles.Change, error) {
+	var out []toggles.Change
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		c := tx.Bucket(bucketToggleChanges).Cursor()
+		for k, data := c.Last(); k != nil && len(out) < limit; k, data = c.Prev() {
+			var change toggles.Change
+			if err := json.Unmarshal(data, &change); err != nil {
+				return err
+			}
+			out = append(out, change)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// toggleKey returns the key of the override of kind and name.
+func toggleKey(kind toggles.Kind, name string) []byte {
+	return append(append([]byte(kind), 0), name...)
+}
+
+// getToggle decodes the override of kind and name, or returns
+// toggles.ErrToggleNotFound.
+func getToggle(bucket *bbolt.Bucket, kind toggles.Kind, name string) (toggles.Toggle, error) {
+	var t toggles.Toggle
+	data := bucket.Get(toggleKey(kind, name))
+	if data == nil {
+		return t, toggles.ErrToggleNotFound
+	}
+	return t, json.Unmarshal(data, &t)
+}
+
+// putChange stores change as JSON under the next sequence of bucket.
+func putChange(bucket *bbolt.Bucket, change *toggles.Change) error {
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(binary.BigEndian.AppendUint64(nil, seq), data)
+}
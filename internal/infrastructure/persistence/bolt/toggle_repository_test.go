@@ -0,0 +1,53 @@
+package bolt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/toggles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToggleRepository_OverridesAndChanges(t *testing.T) {
+	db, path := openTestDB(t)
+	repo := NewToggleRepository(db)
+	ctx := context.Background()
+
+	route := toggles.Toggle{Kind: toggles.KindRoute, Name: "POST /api/v1/reports", Status: 503, Version: 1}
+	require.NoError(t, repo.Put(ctx, &route, &toggles.Change{Name: route.Name, Action: toggles.ActionSet}))
+	feature := toggles.Toggle{Kind: toggles.KindFeature, Name: "beta", Enabled: true, Version: 1}
+	require.NoError(t, repo.Put(ctx, &feature, &toggles.Change{Name: feature.Name, Action: toggles.ActionSet}))
+	feature.Version = 2
+	require.NoError(t, repo.Put(ctx, &feature, &toggles.Change{Name: feature.Name, Action: toggles.ActionSet}))
+
+	// Versions must follow the stored one
+	stale := toggles.Toggle{Kind: toggles.KindFeature, Name: "beta", Version: 2}
+	assert.ErrorIs(t, repo.Put(ctx, &stale, &toggles.Change{}), toggles.ErrVersionConflict)
+	assert.ErrorIs(t, repo.Delete(ctx, toggles.KindFeature, "beta", 1, &toggles.Change{}), toggles.ErrVersionConflict)
+	assert.ErrorIs(t, repo.Delete(ctx, toggles.KindFeature, "alpha", 1, &toggles.Change{}), toggles.ErrToggleNotFound)
+
+	// Overrides and changes survive reopening the file
+	require.NoError(t, db.Close())
+	db, err := Open(path, Options{})
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewToggleRepository(db)
+
+	list, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "beta", list[0].Name)
+	assert.Equal(t, int64(2), list[0].Version)
+	assert.Equal(t, 503, list[1].Status)
+
+	require.NoError(t, repo.Delete(ctx, toggles.KindFeature, "beta", 2, &toggles.Change{Name: "beta", Action: toggles.ActionReset}))
+	_, err = repo.Get(ctx, toggles.KindFeature, "beta")
+	assert.ErrorIs(t, err, toggles.ErrToggleNotFound)
+
+	changes, err := repo.Changes(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, toggles.ActionReset, changes[0].Action)
+	assert.Equal(t, "beta", changes[1].Name)
+}
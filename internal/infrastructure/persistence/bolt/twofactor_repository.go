@@ -0,0 +1,91 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// TwoFactorRepository is a twofactor.Repository persisted in a DB, so
+// enrollments, backup codes and lockouts survive restarts. Enrollments
+// are stored as JSON under their principal.
+type TwoFactorRepository struct {
+	db  *DB
+	now func() time.Time
+}
+
+// NewTwoFactorRepository creates an enrollment repository over db.
+func NewTwoFactorRepository(db *DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db, now: time.Now}
+}
+
+// Get implements twofactor.Repository.
+func (r *TwoFactorRepository) Get(ctx context.Context, principal string) (*twofactor.Enrollment, error) {
+	var enrollment *twofactor.Enrollment
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		enrollment, err = getEnrollment(tx, principal)
+		return err
+	})
+	return enrollment, err
+}
+
+// Save implements twofactor.Repository.
+func (r *TwoFactorRepository) Save(ctx context.Context, enrollment *twofactor.Enrollment) error {
+	enrollment.Fields.Created(ctx, r.now())
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		return putEnrollment(tx, enrollment)
+	})
+}
+
+// Update implements twofactor.Repository.
+func (r *TwoFactorRepository) Update(ctx context.Context, principal string, fn func(*twofactor.Enrollment) error) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		enrollment, err := getEnrollment(tx, principal)
+		if err != nil {
+			return err
+		}
+		if err := fn(enrollment); err != nil {
+			return err
+		}
+		enrollment.Fields.Updated(ctx, r.now())
+		return putEnrollment(tx, enrollment)
+	})
+}
+
+// Delete implements twofactor.Repository.
+func (r *TwoFactorRepository) Delete(ctx context.Context, principal string) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucketTwoFactor)
+		if b.Get([]byte(principal)) == nil {
+			return twofactor.ErrNotEnrolled
+		}
+		return b.Delete([]byte(principal))
+	})
+}
+
+// getEnrollment decodes the enrollment of principal, or returns
+// ErrNotEnrolled.
+func getEnrollment(tx *bbolt.Tx, principal string) (*twofactor.Enrollment, error) {
+	data := tx.Bucket(bucketTwoFactor).Get([]byte(principal))
+	if data == nil {
+		return nil, twofactor.ErrNotEnrolled
+	}
+	var enrollment twofactor.Enrollment
+	if err := json.Unmarshal(data, &enrollment); err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// putEnrollment stores enrollment under its principal.
+func putEnrollment(tx *bbolt.Tx, enrollment *twofactor.Enrollment) error {
+	data, err := json.Marshal(enrollment)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucketTwoFactor).Put([]byte(enrollment.Principal), data)
+}
@@ -0,0 +1,55 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorRepository_SaveUpdateDelete(t *testing.T) {
+	db, path := openTestDB(t)
+	repo := NewTwoFactorRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.Get(ctx, "jane")
+	assert.ErrorIs(t, err, twofactor.ErrNotEnrolled)
+	require.NoError(t, repo.Save(ctx, &twofactor.Enrollment{Principal: "jane", Secret: "sealed", BackupCodes: []string{"a", "b"}}))
+
+	lockedUntil := time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Update(ctx, "jane", func(e *twofactor.Enrollment) error {
+		e.Confirmed = true
+		e.FailedAttempts = 5
+		e.LockedUntil = lockedUntil
+		return nil
+	}))
+	failed := errors.New("rejected")
+	assert.ErrorIs(t, repo.Update(ctx, "jane", func(e *twofactor.Enrollment) error {
+		e.Confirmed = false
+		return failed
+	}), failed)
+	assert.ErrorIs(t, repo.Update(ctx, "bob", func(*twofactor.Enrollment) error { return nil }), twofactor.ErrNotEnrolled)
+
+	// Enrollments survive reopening the file
+	require.NoError(t, db.Close())
+	db, err = Open(path, Options{})
+	require.NoError(t, err)
+	defer db.Close()
+	repo = NewTwoFactorRepository(db)
+
+	e, err := repo.Get(ctx, "jane")
+	require.NoError(t, err)
+	assert.True(t, e.Confirmed, "failed updates are not saved")
+	assert.Equal(t, "sealed", e.Secret)
+	assert.Equal(t, []string{"a", "b"}, e.BackupCodes)
+	assert.Equal(t, 5, e.FailedAttempts)
+	assert.True(t, lockedUntil.Equal(e.LockedUntil))
+	assert.False(t, e.UpdatedAt.IsZero())
+
+	require.NoError(t, repo.Delete(ctx, "jane"))
+	assert.ErrorIs(t, repo.Delete(ctx, "jane"), twofactor.ErrNotEnrolled)
+}
@@ -0,0 +1,268 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/pkg/ids"
+	"github.com/luminosita/change-me/pkg/pagination"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// UserRepository is a users.Repository persisted in a DB. Users are
+// stored as JSON under the 16 bytes of their ID, so cursors iterate them
+// in ID order; index buckets map lowercased emails and usernames to IDs.
+type UserRepository struct {
+	db  *DB
+	ids ids.Generator
+	now func() time.Time
+}
+
+// NewUserRepository creates a user repository over db assigning the IDs
+// made by generator, or by ids.Default when nil.
+func NewUserRepository(db *DB, generator ids.Generator) *UserRepository {
+	return &UserRepository{db: db, ids: ids.OrDefault(generator), now: time.Now}
+}
+
+// Create implements users.Repository.
+func (r *UserRepository) Create(ctx context.Context, user *users.User) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		byEmail := tx.Bucket(bucketUsersByEmail)
+		byUsername := tx.Bucket(bucketUsersByUsername)
+		email := []byte(strings.ToLower(user.Email))
+		if byEmail.Get(email) != nil {
+			return users.ErrEmailTaken
+		}
+		if byUsername.Get([]byte(user.Username)) != nil {
+			return users.ErrUsernameTaken
+		}
+
+		b := tx.Bucket(bucketUsers)
+		created := *user
+		created.ID = users.NewID(r.ids)
+		created.Fields.Created(ctx, r.now())
+		data, err := json.Marshal(created)
+		if err != nil {
+			return err
+		}
+
+		key := userKey(created.ID)
+		if err := b.Put(key, data); err != nil {
+			return err
+		}
+		if err := byEmail.Put(email, key); err != nil {
+			return err
+		}
+		if err := byUsername.Put([]byte(created.Username), key); err != nil {
+			return err
+		}
+		*user = created
+		return nil
+	})
+}
+
+// GetByID implements users.Repository.
+func (r *UserRepository) GetByID(ctx context.Context, id users.UserID) (*users.User, error) {
+	var user *users.User
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		user, err = getUser(ctx, tx, userKey(id))
+		return err
+	})
+	return user, err
+}
+
+// GetByEmail implements users.Repository.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*users.User, error) {
+	var user *users.User
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		key := tx.Bucket(bucketUsersByEmail).Get([]byte(strings.ToLower(email)))
+		if key == nil {
+			return users.ErrNotFound
+		}
+		var err error
+		user, err = getUser(ctx, tx, key)
+		return err
+	})
+	return user, err
+}
+
+// List implements users.Repository.
+func (r *UserRepository) List(ctx context.Context, params pagination.Params) (pagination.Page[users.User], error) {
+	var all []users.User
+	err := r.db.view(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketUsers).ForEach(func(_, data []byte) error {
+			var user users.User
+			if err := json.Unmarshal(data, &user); err != nil {
+				return err
+			}
+			if audit.Visible(ctx, user.Fields) && params.Filter.Match(func(field string) any { return users.FilterValue(user, field) }) {
+				all = append(all, user)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return pagination.Page[users.User]{}, err
+	}
+
+	after, err := users.AfterID(params)
+	if err != nil {
+		return pagination.Page[users.User]{}, err
+	}
+	if len(params.After) > 0 {
+		params.Offset = sort.Search(len(all), func(i int) bool { return all[i].ID.Compare(after) > 0 })
+	}
+
+	start, end := pagination.Window(params, len(all))
+	return pagination.Page[users.User]{
+		Items:  all[start:end],
+		Limit:  params.Limit,
+		Offset: params.Offset,
+		Total:  len(all),
+	}, nil
+}
+
+// Update implements users.Repository. Index entries follow a changed
+// email or username in the same transaction.
+func (r *UserRepository) Update(ctx context.Context, user *users.User) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		key := userKey(user.ID)
+		stored, err := getUser(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if stored.IsDeleted() {
+			return users.ErrNotFound
+		}
+		if !user.UpdatedAt.IsZero() && !user.UpdatedAt.Equal(stored.UpdatedAt) {
+			return users.ErrModified
+		}
+
+		byEmail := tx.Bucket(bucketUsersByEmail)
+		byUsername := tx.Bucket(bucketUsersByUsername)
+		oldEmail, email := []byte(strings.ToLower(stored.Email)), []byte(strings.ToLower(user.Email))
+		oldUsername, username := []byte(stored.Username), []byte(user.Username)
+		if owner := byEmail.Get(email); owner != nil && !bytes.Equal(owner, key) {
+			return users.ErrEmailTaken
+		}
+		if owner := byUsername.Get(username); owner != nil && !bytes.Equal(owner, key) {
+			return users.ErrUsernameTaken
+		}
+
+		stored.Email, stored.Username = user.Email, user.Username
+		stored.FullName, stored.Phone, stored.IsActive = user.FullName, user.Phone, user.IsActive
+		stored.Fields.Updated(ctx, r.now())
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketUsers).Put(key, data); err != nil {
+			return err
+		}
+		if !bytes.Equal(oldEmail, email) {
+			if err := byEmail.Delete(oldEmail); err != nil {
+				return err
+			}
+			if err := byEmail.Put(email, key); err != nil {
+				return err
+			}
+		}
+		if !bytes.Equal(oldUsername, username) {
+			if err := byUsername.Delete(oldUsername); err != nil {
+				return err
+			}
+			if err := byUsername.Put(username, key); err != nil {
+				return err
+			}
+		}
+		*user = *stored
+		return nil
+	})
+}
+
+// Delete implements users.Repository.
+func (r *UserRepository) Delete(ctx context.Context, id users.UserID) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		key := userKey(id)
+		user, err := getUser(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if user.IsDeleted() {
+			return users.ErrNotFound
+		}
+		user.Fields.Deleted(ctx, r.now())
+		data, err := json.Marshal(user)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketUsers).Put(key, data)
+	})
+}
+
+// Restore implements users.Repository.
+func (r *UserRepository) Restore(ctx context.Context, id users.UserID) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		key := userKey(id)
+		user, err := getUser(audit.WithDeleted(ctx), tx, key)
+		if err != nil {
+			return err
+		}
+		if !user.IsDeleted() {
+			return users.ErrNotFound
+		}
+		user.Fields.Restored(ctx, r.now())
+		data, err := json.Marshal(user)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketUsers).Put(key, data)
+	})
+}
+
+// Purge implements users.Repository.
+func (r *UserRepository) Purge(ctx context.Context, id users.UserID) error {
+	return r.db.update(ctx, func(tx *bbolt.Tx) error {
+		key := userKey(id)
+		user, err := getUser(audit.WithDeleted(ctx), tx, key)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketUsersByEmail).Delete([]byte(strings.ToLower(user.Email))); err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketUsersByUsername).Delete([]byte(user.Username)); err != nil {
+			return err
+		}
+		return tx.Bucket(bucketUsers).Delete(key)
+	})
+}
+
+// getUser decodes the user stored under key, or returns ErrNotFound when
+// it is missing or not visible under ctx.
+func getUser(ctx context.Context, tx *bbolt.Tx, key []byte) (*users.User, error) {
+	data := tx.Bucket(bucketUsers).Get(key)
+	if data == nil {
+		return nil, users.ErrNotFound
+	}
+	var user users.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	if !audit.Visible(ctx, user.Fields) {
+		return nil, users.ErrNotFound
+	}
+	return &user, nil
+}
+
+// userKey returns the key of the user with id.
+func userKey(id users.UserID) []byte {
+	return id[:]
+}
@@ -0,0 +1,226 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/pkg/filter"
+	"github.com/luminosita/change-me/pkg/ids"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestDB(t *testing.T) (*DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := Open(path, Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, path
+}
+
+// sequentialIDs makes the IDs 1, 2, 3... in their last byte, so tests can
+// refer to the users they created in order.
+type sequentialIDs struct{ last byte }
+
+func (s *sequentialIDs) Generate() ids.Raw {
+	s.last++
+	return ids.Raw{15: s.last}
+}
+
+// userID returns the ID sequentialIDs makes n-th.
+func userID(n byte) users.UserID { return users.UserID{15: n} }
+
+func TestUserRepository_CreateAndList(t *testing.T) {
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, &sequentialIDs{})
+	ctx := context.Background()
+
+	for _, name := range []string{"ann", "bob", "cid"} {
+		require.NoError(t, repo.Create(ctx, &users.User{Email: name + "@example.com", Username: name}))
+	}
+	assert.ErrorIs(t, repo.Create(ctx, &users.User{Email: "ANN@example.com", Username: "ann2"}), users.ErrEmailTaken)
+	assert.ErrorIs(t, repo.Create(ctx, &users.User{Email: "ann2@example.com", Username: "ann"}), users.ErrUsernameTaken)
+
+	user, err := repo.GetByEmail(ctx, "Bob@Example.com")
+	require.NoError(t, err)
+	assert.Equal(t, userID(2), user.ID)
+
+	page, err := repo.List(ctx, pagination.Params{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "bob", page.Items[0].Username)
+	assert.Equal(t, "cid", page.Items[1].Username)
+}
+
+func TestUserRepository_ListAfterKey(t *testing.T) {
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, &sequentialIDs{})
+	ctx := context.Background()
+	for _, name := range []string{"ann", "bob", "cid", "dan"} {
+		require.NoError(t, repo.Create(ctx, &users.User{Email: name + "@example.com", Username: name}))
+	}
+
+	// A user deleted before the key does not move the next page
+	require.NoError(t, repo.Delete(ctx, userID(1)))
+	page, err := repo.List(ctx, pagination.Params{Limit: 2, Offset: 99, After: []string{userID(2).String()}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "cid", page.Items[0].Username)
+	assert.Equal(t, 1, page.Offset)
+	assert.False(t, page.HasMore())
+
+	_, err = repo.List(ctx, pagination.Params{Limit: 2, After: []string{"bob"}})
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
+
+func TestUserRepository_ListFilter(t *testing.T) {
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, &sequentialIDs{})
+	ctx := context.Background()
+	for _, name := range []string{"ann", "bob", "cid", "dan"} {
+		require.NoError(t, repo.Create(ctx, &users.User{Email: name + "@example.com", Username: name, IsActive: name != "bob"}))
+	}
+
+	spec, err := filter.Parse("is_active==true;username!=c*", users.ListFilter)
+	require.NoError(t, err)
+	page, err := repo.List(ctx, pagination.Params{Limit: 1, After: []string{userID(1).String()}, Filter: spec})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "dan", page.Items[0].Username)
+	assert.Equal(t, 1, page.Offset, "the offset counts matching users only")
+}
+
+func TestUserRepository_SoftDelete(t *testing.T) {
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "admin"})
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, nil)
+	user := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(ctx, user))
+	assert.Equal(t, "admin", user.CreatedBy)
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, user.ID), users.ErrNotFound)
+	_, err := repo.GetByID(ctx, user.ID)
+	assert.ErrorIs(t, err, users.ErrNotFound)
+	page, err := repo.List(ctx, pagination.Params{Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+	assert.ErrorIs(t, repo.Create(ctx, &users.User{Email: "jane@example.com", Username: "jane2"}), users.ErrEmailTaken)
+
+	deleted, err := repo.GetByID(audit.WithDeleted(ctx), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", deleted.DeletedBy)
+}
+
+func TestUserRepository_RestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, nil)
+	user := &users.User{Email: "Jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	assert.ErrorIs(t, repo.Restore(ctx, user.ID), users.ErrNotFound, "only deleted users are restored")
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	require.NoError(t, repo.Restore(ctx, user.ID))
+	restored, err := repo.GetByEmail(ctx, "jane@example.com")
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	require.NoError(t, repo.Purge(ctx, user.ID))
+	_, err = repo.GetByID(audit.WithDeleted(ctx), user.ID)
+	assert.ErrorIs(t, err, users.ErrNotFound)
+	assert.ErrorIs(t, repo.Purge(ctx, user.ID), users.ErrNotFound)
+	assert.NoError(t, repo.Create(ctx, &users.User{Email: "jane@example.com", Username: "jane"}), "purging releases the indexes")
+}
+
+func TestUserRepository_Update(t *testing.T) {
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "editor"})
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, nil)
+	user := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(context.Background(), user))
+	require.NoError(t, repo.Create(ctx, &users.User{Email: "bob@example.com", Username: "bob"}))
+
+	update := &users.User{ID: user.ID, Email: "Joan@example.com", Username: "joan", FullName: "Joan"}
+	require.NoError(t, repo.Update(ctx, update))
+	assert.Equal(t, "editor", update.UpdatedBy)
+	assert.Equal(t, user.CreatedAt, update.CreatedAt, "audit fields are kept")
+
+	_, err := repo.GetByEmail(ctx, "jane@example.com")
+	assert.ErrorIs(t, err, users.ErrNotFound, "the old email is released")
+	stored, err := repo.GetByEmail(ctx, "joan@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Joan", stored.FullName)
+	assert.NoError(t, repo.Create(ctx, &users.User{Email: "jane@example.com", Username: "jane"}), "the old username is released")
+
+	assert.ErrorIs(t, repo.Update(ctx, &users.User{ID: user.ID, Email: "BOB@example.com", Username: "joan"}), users.ErrEmailTaken)
+	assert.ErrorIs(t, repo.Update(ctx, &users.User{ID: user.ID, Email: "joan@example.com", Username: "bob"}), users.ErrUsernameTaken)
+	assert.NoError(t, repo.Update(ctx, &users.User{ID: user.ID, Email: "joan@example.com", Username: "joan"}), "unchanged values are not conflicts")
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	assert.ErrorIs(t, repo.Update(ctx, update), users.ErrNotFound, "deleted users are not updated")
+}
+
+func TestUserRepository_UpdateRejectsStaleWrites(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, nil)
+	user := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(ctx, user))
+	read, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	first := &users.User{ID: user.ID, Email: "jane@example.com", Username: "joan", Fields: read.Fields}
+	require.NoError(t, repo.Update(ctx, first))
+	stale := &users.User{ID: user.ID, Email: "jane@example.com", Username: "jo", Fields: read.Fields}
+	assert.ErrorIs(t, repo.Update(ctx, stale), users.ErrModified)
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "joan", stored.Username, "the first update is kept")
+}
+
+func TestUserRepository_PersistsAcrossReopen(t *testing.T) {
+	db, path := openTestDB(t)
+	ctx := context.Background()
+	jane := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, NewUserRepository(db, nil).Create(ctx, jane))
+	require.NoError(t, db.Close())
+
+	reopened, err := Open(path, Options{})
+	require.NoError(t, err)
+	defer reopened.Close()
+	repo := NewUserRepository(reopened, nil)
+
+	user, err := repo.GetByID(ctx, jane.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "jane", user.Username)
+	assert.False(t, user.ID.IsZero())
+}
+
+func TestUserRepository_ExpiredContext(t *testing.T) {
+	db, _ := openTestDB(t)
+	repo := NewUserRepository(db, nil)
+	ann := &users.User{Email: "ann@example.com", Username: "ann"}
+	require.NoError(t, repo.Create(context.Background(), ann))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.GetByID(ctx, ann.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, repo.Create(ctx, &users.User{Email: "bob@example.com", Username: "bob"}), context.Canceled)
+
+	page, err := repo.List(context.Background(), pagination.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total, "nothing written for the cancelled request")
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheEntries bounds a CacheStore created with maxEntries <= 0.
+const DefaultCacheEntries = 10000
+
+// CacheStore is an in-memory expiring key/value store. It satisfies
+// cache.Store and is suitable for single-instance deployments.
+type CacheStore struct {
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	maxEntries int
+	now        func() time.Time
+}
+
+type cacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewCacheStore creates an empty cache store holding at most maxEntries
+// values. When full, expired entries are purged first, then arbitrary
+// entries are evicted.
+func NewCacheStore(maxEntries int) *CacheStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+	return &CacheStore{
+		entries:    make(map[string]cacheEntry),
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Get returns the value under key if present and not expired.
+func (s *CacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !s.now().Before(e.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set stores value under key for ttl.
+func (s *CacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.entries[key] = cacheEntry{value: value, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+// Delete removes keys.
+func (s *CacheStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// DeletePrefix removes every key starting with prefix.
+func (s *CacheStore) DeletePrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+// evict makes room for one entry. Callers hold mu.
+func (s *CacheStore) evict() {
+	now := s.now()
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	for key := range s.entries {
+		if len(s.entries) < s.maxEntries {
+			return
+		}
+		delete(s.entries, key)
+	}
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/luminosita/change-me/internal/core/consent"
+)
+
+// ConsentRepository is an in-memory consent.Repository.
+type ConsentRepository struct {
+	mu          sync.Mutex
+	policies    map[string][]consent.Policy // Versions in publish order
+	acceptances map[string][]consent.Acceptance
+}
+
+// NewConsentRepository creates an empty in-memory consent repository.
+func NewConsentRepository() *ConsentRepository {
+	return &ConsentRepository{
+		policies:    make(map[string][]consent.Policy),
+		acceptances: make(map[string][]consent.Acceptance),
+	}
+}
+
+// Publish implements consent.Repository.
+func (r *ConsentRepository) Publish(ctx context.Context, policy *consent.Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.policies[policy.Name] {
+		if p.Version == policy.Version {
+			return consent.ErrVersionExists
+		}
+	}
+	r.policies[policy.Name] = append(r.policies[policy.Name], *policy)
+	return nil
+}
+
+// Versions implements consent.Repository.
+func (r *ConsentRepository) Versions(ctx context.Context, name string) ([]consent.Policy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := r.policies[name]
+	if len(versions) == 0 {
+		return nil, consent.ErrPolicyNotFound
+	}
+	out := slices.Clone(versions)
+	slices.Reverse(out)
+	return out, nil
+}
+
+// Latest implements consent.Repository.
+func (r *ConsentRepository) Latest(ctx context.Context) ([]consent.Policy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]consent.Policy, 0, len(r.policies))
+	for _, versions := range r.policies {
+		out = append(out, versions[len(versions)-1])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Accept implements consent.Repository.
+func (r *ConsentRepository) Accept(ctx context.Context, acceptance *consent.Acceptance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.acceptances[acceptance.Principal] = append(r.acceptances[acceptance.Principal], *acceptance)
+	return nil
+}
+
+// Acceptances implements consent.Repository.
+func (r *ConsentRepository) Acceptances(ctx context.Context, principal string) ([]consent.Acceptance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.acceptances[principal]), nil
+}
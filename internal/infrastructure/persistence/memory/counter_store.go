@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CounterStore is an in-memory expiring counter store. It satisfies
+// quota.Store and is suitable for single-instance deployments.
+type CounterStore struct {
+	mu       sync.Mutex
+	counters map[string]counter
+	now      func() time.Time
+}
+
+type counter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewCounterStore creates an empty counter store.
+func NewCounterStore() *CounterStore {
+	return &CounterStore{
+		counters: make(map[string]counter),
+		now:      time.Now,
+	}
+}
+
+// Increment adds delta to key. expiresAt is applied when the key is created.
+func (s *CounterStore) Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.live(key)
+	if !ok {
+		c = counter{expiresAt: expiresAt}
+	}
+	c.value += delta
+	s.counters[key] = c
+	return c.value, nil
+}
+
+// Get returns the value of key, or 0 if it is absent or expired.
+func (s *CounterStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, _ := s.live(key)
+	return c.value, nil
+}
+
+// Delete removes keys.
+func (s *CounterStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.counters, key)
+	}
+	return nil
+}
+
+// live returns the counter for key, dropping it if expired. Callers hold mu.
+func (s *CounterStore) live(key string) (counter, bool) {
+	c, ok := s.counters[key]
+	if !ok {
+		return counter{}, false
+	}
+	if !c.expiresAt.IsZero() && !s.now().Before(c.expiresAt) {
+		delete(s.counters, key)
+		return counter{}, false
+	}
+	return c, true
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+)
+
+// NotificationQueue is an in-process notifications.Queue. Jobs are only
+// delivered by workers of the same process and are lost on restart.
+type NotificationQueue struct {
+	jobs chan notifications.Job
+}
+
+// NewNotificationQueue creates a queue holding up to size jobs.
+func NewNotificationQueue(size int) *NotificationQueue {
+	return &NotificationQueue{jobs: make(chan notifications.Job, size)}
+}
+
+// Enqueue implements notifications.Queue.
+func (q *NotificationQueue) Enqueue(ctx context.Context, job notifications.Job) error {
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return notifications.ErrQueueFull
+	}
+}
+
+// Dequeue implements notifications.Queue.
+func (q *NotificationQueue) Dequeue(ctx context.Context) (notifications.Job, error) {
+	select {
+	case job := <-q.jobs:
+		return job, nil
+	case <-ctx.Done():
+		return notifications.Job{}, ctx.Err()
+	}
+}
+
+// Len returns the number of queued jobs.
+func (q *NotificationQueue) Len() int {
+	return len(q.jobs)
+}
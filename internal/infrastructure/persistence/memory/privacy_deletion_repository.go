@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/luminosita/change-me/internal/core/privacy"
+)
+
+// PrivacyDeletionRepository is an in-memory privacy.DeletionRepository.
+// Scheduled deletions are lost on restart, so users suspended before are
+// not erased; persist requests with the embedded store in production.
+type PrivacyDeletionRepository struct {
+	mu   sync.Mutex
+	byID map[string]privacy.Deletion
+}
+
+// NewPrivacyDeletionRepository creates an empty in-memory deletion
+// request repository.
+func NewPrivacyDeletionRepository() *PrivacyDeletionRepository {
+	return &PrivacyDeletionRepository{byID: make(map[string]privacy.Deletion)}
+}
+
+// Create implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Create(ctx context.Context, deletion *privacy.Deletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[deletion.ID] = cloneDeletion(*deletion)
+	return nil
+}
+
+// Get implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Get(ctx context.Context, id string) (*privacy.Deletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.byID[id]
+	if !ok {
+		return nil, privacy.ErrDeletionNotFound
+	}
+	d = cloneDeletion(d)
+	return &d, nil
+}
+
+// Update implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Update(ctx context.Context, id string, fn func(*privacy.Deletion) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.byID[id]
+	if !ok {
+		return privacy.ErrDeletionNotFound
+	}
+	d = cloneDeletion(d)
+	if err := fn(&d); err != nil {
+		return err
+	}
+	r.byID[id] = d
+	return nil
+}
+
+// Scheduled implements privacy.DeletionRepository.
+func (r *PrivacyDeletionRepository) Scheduled(ctx context.Context) ([]privacy.Deletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []privacy.Deletion
+	for _, d := range r.byID {
+		if d.Status == privacy.DeletionScheduled {
+			out = append(out, cloneDeletion(d))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PurgeAfter.Before(out[j].PurgeAfter) })
+	return out, nil
+}
+
+// cloneDeletion copies d so stored requests do not share slices with
+// callers.
+func cloneDeletion(d privacy.Deletion) privacy.Deletion {
+	d.Suspended = slices.Clone(d.Suspended)
+	d.Trail = slices.Clone(d.Trail)
+	return d
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/luminosita/change-me/pkg/clock"
+)
+
+// minSessionSweep is the number of families from which Create sweeps
+// expired ones.
+const minSessionSweep = 64
+
+// SessionStore is an in-memory sessions.Store for single-instance
+// deployments. Expired families are dropped when accessed, and swept by
+// Create whenever the store doubled in size since the last sweep, so
+// abandoned logins do not accumulate.
+type SessionStore struct {
+	mu       sync.Mutex
+	families map[string]sessions.Family
+	sweepAt  int // Number of families at which Create sweeps
+	now      func() time.Time
+}
+
+// NewSessionStore creates an empty session store.
+func NewSessionStore() *SessionStore {
+	return &SessionStore{
+		families: make(map[string]sessions.Family),
+		sweepAt:  minSessionSweep,
+		now:      time.Now,
+	}
+}
+
+// WithClock tells family expiry with clk, which should be the clock of
+// the sessions.Service using the store.
+func (s *SessionStore) WithClock(clk clock.Clock) *SessionStore {
+	s.now = clk.Now
+	return s
+}
+
+// Create implements sessions.Store.
+func (s *SessionStore) Create(ctx context.Context, family *sessions.Family) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.families) >= s.sweepAt {
+		s.sweep()
+	}
+	s.families[family.ID] = copyFamily(*family)
+	return nil
+}
+
+// Update implements sessions.Store.
+func (s *SessionStore) Update(ctx context.Context, id string, fn func(*sessions.Family) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.live(id)
+	if !ok {
+		return sessions.ErrInvalidToken
+	}
+	updated := copyFamily(f)
+	if err := fn(&updated); err != nil {
+		return err
+	}
+	s.families[id] = updated
+	return nil
+}
+
+// Delete implements sessions.Store.
+func (s *SessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.families, id)
+	return nil
+}
+
+// DeletePrincipal implements sessions.Store.
+func (s *SessionStore) DeletePrincipal(ctx context.Context, principal string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := 0
+	for id := range s.families {
+		f, ok := s.live(id)
+		if ok && f.Principal == principal {
+			delete(s.families, id)
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// live returns the family id unless it expired, deleting expired ones.
+// The caller must hold s.mu.
+func (s *SessionStore) live(id string) (sessions.Family, bool) {
+	f, ok := s.families[id]
+	if !ok {
+		return sessions.Family{}, false
+	}
+	if !s.now().Before(f.ExpiresAt) {
+		delete(s.families, id)
+		return sessions.Family{}, false
+	}
+	return f, true
+}
+
+// sweep deletes the expired families. The caller must hold s.mu.
+func (s *SessionStore) sweep() {
+	for id := range s.families {
+		s.live(id)
+	}
+	s.sweepAt = max(2*len(s.families), minSessionSweep)
+}
+
+// copyFamily copies f so callers cannot alias the stored digests.
+func copyFamily(f sessions.Family) sessions.Family {
+	f.Used = slices.Clone(f.Used)
+	return f
+}
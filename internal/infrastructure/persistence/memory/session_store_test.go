@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStore_ExpiredFamiliesAreDropped(t *testing.T) {
+	ctx := context.Background()
+	store := NewSessionStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Create(ctx, &sessions.Family{ID: "a", Principal: "jane", ExpiresAt: now.Add(time.Minute)}))
+	require.NoError(t, store.Create(ctx, &sessions.Family{ID: "b", Principal: "jane", ExpiresAt: now.Add(time.Hour)}))
+
+	now = now.Add(2 * time.Minute)
+	err := store.Update(ctx, "a", func(*sessions.Family) error { return nil })
+	assert.ErrorIs(t, err, sessions.ErrInvalidToken)
+
+	revoked, err := store.DeletePrincipal(ctx, "jane")
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+	assert.Empty(t, store.families)
+}
+
+func TestSessionStore_CreateSweepsExpiredFamilies(t *testing.T) {
+	ctx := context.Background()
+	store := NewSessionStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	for i := range minSessionSweep - 1 {
+		require.NoError(t, store.Create(ctx, &sessions.Family{ID: strconv.Itoa(i), Principal: "jane", ExpiresAt: now.Add(time.Minute)}))
+	}
+	require.NoError(t, store.Create(ctx, &sessions.Family{ID: "live", Principal: "jane", ExpiresAt: now.Add(time.Hour)}))
+	assert.Len(t, store.families, minSessionSweep)
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, store.Create(ctx, &sessions.Family{ID: "new", Principal: "john", ExpiresAt: now.Add(time.Hour)}))
+	assert.Len(t, store.families, 2, "abandoned families are swept")
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/luminosita/change-me/internal/core/toggles"
+)
+
+// ToggleRepository is an in-memory toggles.Repository; overrides last
+// until the process exits.
+type ToggleRepository struct {
+	mu        sync.Mutex
+	overrides map[toggleKey]toggles.Toggle
+	changes   []toggles.Change // Oldest first
+}
+
+// toggleKey identifies an override.
+type toggleKey struct {
+	kind toggles.Kind
+	name string
+}
+
+// NewToggleRepository creates an empty in-memory toggle repository.
+func NewToggleRepository() *ToggleRepository {
+	return &ToggleRepository{overrides: make(map[toggleKey]toggles.Toggle)}
+}
+
+// List implements toggles.Repository.
+func (r *ToggleRepository) List(ctx context.Context) ([]toggles.Toggle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]toggles.Toggle, 0, len(r.overrides))
+	for _, t := range r.overrides {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// Get implements toggles.Repository.
+func (r *ToggleRepository) Get(ctx context.Context, kind toggles.Kind, name string) (toggles.Toggle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.overrides[toggleKey{kind, name}]
+	if !ok {
+		return toggles.Toggle{}, toggles.ErrToggleNotFound
+	}
+	return t, nil
+}
+
+// Put implements toggles.Repository.
+func (r *ToggleRepository) Put(ctx context.Context, toggle *toggles.Toggle, change *toggles.Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := toggleKey{toggle.Kind, toggle.Name}
+	if r.overrides[key].Version != toggle.Version-1 {
+		return toggles.ErrVersionConflict
+	}
+	r.overrides[key] = *toggle
+	r.changes = append(r.changes, *change)
+	return nil
+}
+
+// Delete implements toggles.Repository.
+func (r *ToggleRepository) Delete(ctx context.Context, kind toggles.Kind, name string, version int64, change *toggles.Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := toggleKey{kind, name}
+	current, ok := r.overrides[key]
+	if !ok {
+		return toggles.ErrToggleNotFound
+	}
+	if current.Version != version {
+		return toggles.ErrVersionConflict
+	}
+	delete(r.overrides, key)
+	r.changes = append(r.changes, *change)
+	return nil
+}
+
+// Changes implements toggles.Repository.
+func (r *ToggleRepository) Changes(ctx context.Context, limit int) ([]toggles.Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]toggles.Change, 0, min(limit, len(r.changes)))
+	for i := len(r.changes) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, r.changes[i])
+	}
+	return out, nil
+}
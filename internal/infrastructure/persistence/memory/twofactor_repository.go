@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/twofactor"
+)
+
+// TwoFactorRepository is an in-memory twofactor.Repository.
+type TwoFactorRepository struct {
+	mu          sync.Mutex
+	byPrincipal map[string]twofactor.Enrollment
+	now         func() time.Time
+}
+
+// NewTwoFactorRepository creates an empty in-memory enrollment repository.
+func NewTwoFactorRepository() *TwoFactorRepository {
+	return &TwoFactorRepository{
+		byPrincipal: make(map[string]twofactor.Enrollment),
+		now:         time.Now,
+	}
+}
+
+// Get implements twofactor.Repository.
+func (r *TwoFactorRepository) Get(ctx context.Context, principal string) (*twofactor.Enrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.byPrincipal[principal]
+	if !ok {
+		return nil, twofactor.ErrNotEnrolled
+	}
+	return clone(e), nil
+}
+
+// Save implements twofactor.Repository.
+func (r *TwoFactorRepository) Save(ctx context.Context, enrollment *twofactor.Enrollment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment.Fields.Created(ctx, r.now())
+	r.byPrincipal[enrollment.Principal] = *clone(*enrollment)
+	return nil
+}
+
+// Update implements twofactor.Repository.
+func (r *TwoFactorRepository) Update(ctx context.Context, principal string, fn func(*twofactor.Enrollment) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.byPrincipal[principal]
+	if !ok {
+		return twofactor.ErrNotEnrolled
+	}
+	updated := clone(e)
+	if err := fn(updated); err != nil {
+		return err
+	}
+	updated.Fields.Updated(ctx, r.now())
+	r.byPrincipal[principal] = *updated
+	return nil
+}
+
+// Delete implements twofactor.Repository.
+func (r *TwoFactorRepository) Delete(ctx context.Context, principal string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byPrincipal[principal]; !ok {
+		return twofactor.ErrNotEnrolled
+	}
+	delete(r.byPrincipal, principal)
+	return nil
+}
+
+// clone copies e so callers cannot alias the stored backup codes.
+func clone(e twofactor.Enrollment) *twofactor.Enrollment {
+	e.BackupCodes = slices.Clone(e.BackupCodes)
+	return &e
+}
@@ -0,0 +1,185 @@
+// Package memory provides in-memory repository implementations.
+//
+// They are intended for development, tests, and single-instance demos:
+// data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/pkg/ids"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// UserRepository is an in-memory users.Repository.
+type UserRepository struct {
+	mu   sync.RWMutex
+	ids  ids.Generator
+	byID map[users.UserID]users.User
+	now  func() time.Time
+}
+
+// NewUserRepository creates an empty in-memory user repository assigning
+// the IDs made by generator, or by ids.Default when nil.
+func NewUserRepository(generator ids.Generator) *UserRepository {
+	return &UserRepository{
+		ids:  ids.OrDefault(generator),
+		byID: make(map[users.UserID]users.User),
+		now:  time.Now,
+	}
+}
+
+// Create implements users.Repository.
+func (r *UserRepository) Create(ctx context.Context, user *users.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.byID {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return users.ErrEmailTaken
+		}
+		if existing.Username == user.Username {
+			return users.ErrUsernameTaken
+		}
+	}
+
+	user.ID = users.NewID(r.ids)
+	user.Fields.Created(ctx, r.now())
+	r.byID[user.ID] = *user
+	return nil
+}
+
+// GetByID implements users.Repository.
+func (r *UserRepository) GetByID(ctx context.Context, id users.UserID) (*users.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.byID[id]
+	if !ok || !audit.Visible(ctx, user.Fields) {
+		return nil, users.ErrNotFound
+	}
+	return &user, nil
+}
+
+// GetByEmail implements users.Repository.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*users.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.byID {
+		if strings.EqualFold(user.Email, email) && audit.Visible(ctx, user.Fields) {
+			u := user
+			return &u, nil
+		}
+	}
+	return nil, users.ErrNotFound
+}
+
+// List implements users.Repository.
+func (r *UserRepository) List(ctx context.Context, params pagination.Params) (pagination.Page[users.User], error) {
+	r.mu.RLock()
+	all := make([]users.User, 0, len(r.byID))
+	for _, user := range r.byID {
+		if audit.Visible(ctx, user.Fields) && params.Filter.Match(func(field string) any { return users.FilterValue(user, field) }) {
+			all = append(all, user)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(all, func(a, b users.User) int { return a.ID.Compare(b.ID) })
+
+	after, err := users.AfterID(params)
+	if err != nil {
+		return pagination.Page[users.User]{}, err
+	}
+	if len(params.After) > 0 {
+		params.Offset = sort.Search(len(all), func(i int) bool { return all[i].ID.Compare(after) > 0 })
+	}
+
+	start, end := pagination.Window(params, len(all))
+	return pagination.Page[users.User]{
+		Items:  all[start:end],
+		Limit:  params.Limit,
+		Offset: params.Offset,
+		Total:  len(all),
+	}, nil
+}
+
+// Update implements users.Repository.
+func (r *UserRepository) Update(ctx context.Context, user *users.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byID[user.ID]
+	if !ok || stored.IsDeleted() {
+		return users.ErrNotFound
+	}
+	if !user.UpdatedAt.IsZero() && !user.UpdatedAt.Equal(stored.UpdatedAt) {
+		return users.ErrModified
+	}
+	for id, existing := range r.byID {
+		if id == user.ID {
+			continue
+		}
+		if strings.EqualFold(existing.Email, user.Email) {
+			return users.ErrEmailTaken
+		}
+		if existing.Username == user.Username {
+			return users.ErrUsernameTaken
+		}
+	}
+
+	stored.Email, stored.Username = user.Email, user.Username
+	stored.FullName, stored.Phone, stored.IsActive = user.FullName, user.Phone, user.IsActive
+	stored.Fields.Updated(ctx, r.now())
+	r.byID[user.ID] = stored
+	*user = stored
+	return nil
+}
+
+// Delete implements users.Repository.
+func (r *UserRepository) Delete(ctx context.Context, id users.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.byID[id]
+	if !ok || user.IsDeleted() {
+		return users.ErrNotFound
+	}
+	user.Fields.Deleted(ctx, r.now())
+	r.byID[id] = user
+	return nil
+}
+
+// Restore implements users.Repository.
+func (r *UserRepository) Restore(ctx context.Context, id users.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.byID[id]
+	if !ok || !user.IsDeleted() {
+		return users.ErrNotFound
+	}
+	user.Fields.Restored(ctx, r.now())
+	r.byID[id] = user
+	return nil
+}
+
+// Purge implements users.Repository.
+func (r *UserRepository) Purge(ctx context.Context, id users.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[id]; !ok {
+		return users.ErrNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/audit"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository_SoftDelete(t *testing.T) {
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "admin"})
+	repo := NewUserRepository(nil)
+	user := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(ctx, user))
+	assert.Equal(t, "admin", user.CreatedBy)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	_, err := repo.GetByID(ctx, user.ID)
+	assert.ErrorIs(t, err, users.ErrNotFound)
+	_, err = repo.GetByEmail(ctx, "jane@example.com")
+	assert.ErrorIs(t, err, users.ErrNotFound)
+	page, err := repo.List(ctx, pagination.Params{Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+	assert.ErrorIs(t, repo.Create(ctx, &users.User{Email: "jane@example.com", Username: "jane2"}), users.ErrEmailTaken)
+
+	deleted, err := repo.GetByID(audit.WithDeleted(ctx), user.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted())
+	assert.Equal(t, "admin", deleted.DeletedBy)
+}
+
+func TestUserRepository_RestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(nil)
+	user := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	assert.ErrorIs(t, repo.Restore(ctx, user.ID), users.ErrNotFound, "only deleted users are restored")
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	require.NoError(t, repo.Restore(ctx, user.ID))
+	restored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+
+	require.NoError(t, repo.Purge(ctx, user.ID))
+	_, err = repo.GetByID(audit.WithDeleted(ctx), user.ID)
+	assert.ErrorIs(t, err, users.ErrNotFound)
+	assert.ErrorIs(t, repo.Purge(ctx, user.ID), users.ErrNotFound)
+	assert.NoError(t, repo.Create(ctx, &users.User{Email: "jane@example.com", Username: "jane"}), "purging releases the email")
+}
+
+func TestUserRepository_Update(t *testing.T) {
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{Principal: "editor"})
+	repo := NewUserRepository(nil)
+	user := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(context.Background(), user))
+	other := &users.User{Email: "bob@example.com", Username: "bob"}
+	require.NoError(t, repo.Create(ctx, other))
+
+	update := &users.User{ID: user.ID, Email: "joan@example.com", Username: "jane", FullName: "Joan", IsActive: true}
+	require.NoError(t, repo.Update(ctx, update))
+	assert.Equal(t, "editor", update.UpdatedBy)
+	assert.Equal(t, user.CreatedAt, update.CreatedAt, "audit fields are kept")
+	stored, err := repo.GetByEmail(ctx, "joan@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Joan", stored.FullName)
+
+	assert.ErrorIs(t, repo.Update(ctx, &users.User{ID: user.ID, Email: "BOB@example.com", Username: "jane"}), users.ErrEmailTaken)
+	assert.ErrorIs(t, repo.Update(ctx, &users.User{ID: user.ID, Email: "joan@example.com", Username: "bob"}), users.ErrUsernameTaken)
+
+	require.NoError(t, repo.Delete(ctx, other.ID))
+	assert.ErrorIs(t, repo.Update(ctx, other), users.ErrNotFound, "deleted users are not updated")
+	assert.ErrorIs(t, repo.Update(ctx, &users.User{ID: users.UserID{15: 99}}), users.ErrNotFound)
+}
+
+func TestUserRepository_UpdateRejectsStaleWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(nil)
+	user := &users.User{Email: "jane@example.com", Username: "jane"}
+	require.NoError(t, repo.Create(ctx, user))
+	read := user.Fields
+
+	first := &users.User{ID: user.ID, Email: "jane@example.com", Username: "joan", Fields: read}
+	require.NoError(t, repo.Update(ctx, first))
+	stale := &users.User{ID: user.ID, Email: "jane@example.com", Username: "jo", Fields: read}
+	assert.ErrorIs(t, repo.Update(ctx, stale), users.ErrModified)
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "joan", stored.Username, "the first update is kept")
+}
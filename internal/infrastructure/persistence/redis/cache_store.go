@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// scanBatch is the SCAN page size used by DeletePrefix.
+const scanBatch = 500
+
+// CacheStore is a Redis key/value store with expiry. It satisfies cache.Store.
+type CacheStore struct {
+	client goredis.UniversalClient
+}
+
+// NewCacheStore creates a cache store using client.
+func NewCacheStore(client goredis.UniversalClient) *CacheStore {
+	return &CacheStore{client: client}
+}
+
+// Get returns the value under key if present.
+func (s *CacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, err := s.client.Get(ctx, key).Bytes()
+	if err == goredis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// Set stores value under key for ttl.
+func (s *CacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete removes keys.
+func (s *CacheStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// DeletePrefix removes every key starting with prefix using SCAN, so large
+// keyspaces are not blocked. Glob metacharacters in prefix are not escaped.
+func (s *CacheStore) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := s.client.Scan(ctx, 0, prefix+"*", scanBatch).Iterator()
+	keys := make([]string, 0, scanBatch)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanBatch {
+			if err := s.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return s.Delete(ctx, keys...)
+}
@@ -0,0 +1,56 @@
+// Package redis provides Redis-backed store implementations shared by all
+// application instances.
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// incrementScript adds ARGV[1] to KEYS[1] and sets the expiry (unix ms in
+// ARGV[2]) when the key has none, atomically.
+var incrementScript = goredis.NewScript(`
+local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+if ARGV[2] ~= '0' and redis.call('PTTL', KEYS[1]) == -1 then
+	redis.call('PEXPIREAT', KEYS[1], ARGV[2])
+end
+return v
+`)
+
+// CounterStore is a Redis expiring counter store. It satisfies quota.Store.
+type CounterStore struct {
+	client goredis.UniversalClient
+}
+
+// NewCounterStore creates a counter store using client.
+func NewCounterStore(client goredis.UniversalClient) *CounterStore {
+	return &CounterStore{client: client}
+}
+
+// Increment adds delta to key. expiresAt is applied when the key has no expiry.
+func (s *CounterStore) Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	var expiry int64
+	if !expiresAt.IsZero() {
+		expiry = expiresAt.UnixMilli()
+	}
+	return incrementScript.Run(ctx, s.client, []string{key}, delta, expiry).Int64()
+}
+
+// Get returns the value of key, or 0 if it is absent.
+func (s *CounterStore) Get(ctx context.Context, key string) (int64, error) {
+	v, err := s.client.Get(ctx, key).Int64()
+	if err == goredis.Nil {
+		return 0, nil
+	}
+	return v, err
+}
+
+// Delete removes keys.
+func (s *CounterStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
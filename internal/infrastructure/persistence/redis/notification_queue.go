@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/notifications"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// notificationQueueKey is the list holding the queued notification jobs.
+const notificationQueueKey = "notifications:queue"
+
+// dequeueTimeout bounds each blocking pop so cancellation is observed.
+const dequeueTimeout = 5 * time.Second
+
+// enqueueScript pushes ARGV[1] onto KEYS[1] unless it holds ARGV[2] items
+// already, atomically. It returns 0 when the list is full.
+var enqueueScript = goredis.NewScript(`
+if redis.call('LLEN', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('LPUSH', KEYS[1], ARGV[1])
+return 1
+`)
+
+// NotificationQueue is a Redis notifications.Queue shared by all
+// application instances, so API processes enqueue jobs for the workers.
+type NotificationQueue struct {
+	client goredis.UniversalClient
+	size   int
+}
+
+// NewNotificationQueue creates a queue holding up to size jobs using client.
+func NewNotificationQueue(client goredis.UniversalClient, size int) *NotificationQueue {
+	return &NotificationQueue{client: client, size: size}
+}
+
+// Enqueue implements notifications.Queue.
+func (q *NotificationQueue) Enqueue(ctx context.Context, job notifications.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	added, err := enqueueScript.Run(ctx, q.client, []string{notificationQueueKey}, data, q.size).Int()
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		return notifications.ErrQueueFull
+	}
+	return nil
+}
+
+// Dequeue implements notifications.Queue.
+func (q *NotificationQueue) Dequeue(ctx context.Context) (notifications.Job, error) {
+	for {
+		res, err := q.client.BRPop(ctx, dequeueTimeout, notificationQueueKey).Result()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return notifications.Job{}, ctx.Err()
+			}
+			return notifications.Job{}, err
+		}
+		var job notifications.Job
+		if err := json.Unmarshal([]byte(res[1]), &job); err != nil {
+			return notifications.Job{}, err
+		}
+		return job, nil
+	}
+}
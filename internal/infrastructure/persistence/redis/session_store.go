@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/sessions"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Key prefixes of the session store.
+const (
+	sessionFamilyPrefix    = "sessions:family:"
+	sessionPrincipalPrefix = "sessions:principal:"
+)
+
+// extendScript moves the expiry of KEYS[1] to ARGV[1] (unix ms) unless
+// it already expires later, atomically.
+var extendScript = goredis.NewScript(`
+local now = redis.call('TIME')
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl == -1 or now[1] * 1000 + math.floor(now[2] / 1000) + ttl < tonumber(ARGV[1]) then
+	redis.call('PEXPIREAT', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// SessionStore is a Redis sessions.Store shared by all instances, so a
+// token revoked by one instance is rejected by all. Families expire with
+// their current token, and the index of the families of a principal with
+// the last of them.
+type SessionStore struct {
+	client goredis.UniversalClient
+}
+
+// NewSessionStore creates a session store using client.
+func NewSessionStore(client goredis.UniversalClient) *SessionStore {
+	return &SessionStore{client: client}
+}
+
+// Create implements sessions.Store.
+func (s *SessionStore) Create(ctx context.Context, family *sessions.Family) error {
+	data, err := json.Marshal(family)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, sessionFamilyPrefix+family.ID, data, 0)
+		pipe.ExpireAt(ctx, sessionFamilyPrefix+family.ID, family.ExpiresAt)
+		pipe.SAdd(ctx, sessionPrincipalPrefix+family.Principal, family.ID)
+		extendIndex(ctx, pipe, family.Principal, family.ExpiresAt)
+		return nil
+	})
+	return err
+}
+
+// Update implements sessions.Store. Concurrent updates of a family are
+// detected with WATCH; the losing update fails with ErrInvalidToken so
+// only one refresh of a token succeeds.
+func (s *SessionStore) Update(ctx context.Context, id string, fn func(*sessions.Family) error) error {
+	key := sessionFamilyPrefix + id
+	err := s.client.Watch(ctx, func(tx *goredis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if errors.Is(err, goredis.Nil) {
+			return sessions.ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+		var family sessions.Family
+		if err := json.Unmarshal(data, &family); err != nil {
+			return fmt.Errorf("decode session family %s: %w", id, err)
+		}
+		if err := fn(&family); err != nil {
+			return err
+		}
+		if data, err = json.Marshal(&family); err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, data, 0)
+			pipe.ExpireAt(ctx, key, family.ExpiresAt)
+			extendIndex(ctx, pipe, family.Principal, family.ExpiresAt)
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, goredis.TxFailedErr) {
+		return sessions.ErrInvalidToken
+	}
+	return err
+}
+
+// Delete implements sessions.Store. The family is also removed from the
+// index of its principal.
+func (s *SessionStore) Delete(ctx context.Context, id string) error {
+	key := sessionFamilyPrefix + id
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var family sessions.Family
+	if err := json.Unmarshal(data, &family); err != nil {
+		return fmt.Errorf("decode session family %s: %w", id, err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.SRem(ctx, sessionPrincipalPrefix+family.Principal, id)
+		return nil
+	})
+	return err
+}
+
+// DeletePrincipal implements sessions.Store. Families that already
+// expired are not counted; their IDs stay in the principal index until
+// it expires with the last family.
+func (s *SessionStore) DeletePrincipal(ctx context.Context, principal string) (int, error) {
+	index := sessionPrincipalPrefix + principal
+	ids, err := s.client.SMembers(ctx, index).Result()
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionFamilyPrefix + id
+	}
+
+	var deleted *goredis.IntCmd
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		deleted = pipe.Del(ctx, keys...)
+		pipe.SRem(ctx, index, toAny(ids)...)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(deleted.Val()), nil
+}
+
+// extendIndex queues extending the expiry of the index of principal to
+// expiresAt.
+func extendIndex(ctx context.Context, pipe goredis.Pipeliner, principal string, expiresAt time.Time) {
+	extendScript.Eval(ctx, pipe, []string{sessionPrincipalPrefix + principal}, expiresAt.UnixMilli())
+}
+
+// toAny converts strings to variadic command arguments.
+func toAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// twoFactorPrefix prefixes the enrollment keys.
+const twoFactorPrefix = "twofactor:"
+
+// twoFactorUpdateRetries bounds the retries of an update losing a race.
+const twoFactorUpdateRetries = 10
+
+// TwoFactorRepository is a Redis twofactor.Repository shared by all
+// instances, so enrollments survive restarts and wrong codes count
+// towards one lockout whichever instance receives them.
+type TwoFactorRepository struct {
+	client goredis.UniversalClient
+	now    func() time.Time
+}
+
+// NewTwoFactorRepository creates an enrollment repository using client.
+func NewTwoFactorRepository(client goredis.UniversalClient) *TwoFactorRepository {
+	return &TwoFactorRepository{client: client, now: time.Now}
+}
+
+// Get implements twofactor.Repository.
+func (r *TwoFactorRepository) Get(ctx context.Context, principal string) (*twofactor.Enrollment, error) {
+	return getEnrollment(ctx, r.client, principal)
+}
+
+// Save implements twofactor.Repository.
+func (r *TwoFactorRepository) Save(ctx context.Context, enrollment *twofactor.Enrollment) error {
+	enrollment.Fields.Created(ctx, r.now())
+	data, err := json.Marshal(enrollment)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, twoFactorPrefix+enrollment.Principal, data, 0).Err()
+}
+
+// Update implements twofactor.Repository. Concurrent updates of an
+// enrollment are detected with WATCH and the losing one is retried, so no
+// failed attempt goes uncounted.
+func (r *TwoFactorRepository) Update(ctx context.Context, principal string, fn func(*twofactor.Enrollment) error) error {
+	key := twoFactorPrefix + principal
+	update := func(tx *goredis.Tx) error {
+		enrollment, err := getEnrollment(ctx, tx, principal)
+		if err != nil {
+			return err
+		}
+		if err := fn(enrollment); err != nil {
+			return err
+		}
+		enrollment.Fields.Updated(ctx, r.now())
+		data, err := json.Marshal(enrollment)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, data, 0)
+			return nil
+		})
+		return err
+	}
+	for range twoFactorUpdateRetries {
+		err := r.client.Watch(ctx, update, key)
+		if !errors.Is(err, goredis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("update two-factor enrollment %s: too many concurrent updates", principal)
+}
+
+// Delete implements twofactor.Repository.
+func (r *TwoFactorRepository) Delete(ctx context.Context, principal string) error {
+	deleted, err := r.client.Del(ctx, twoFactorPrefix+principal).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return twofactor.ErrNotEnrolled
+	}
+	return nil
+}
+
+// getEnrollment decodes the enrollment of principal read through cmd, or
+// returns ErrNotEnrolled.
+func getEnrollment(ctx context.Context, cmd goredis.Cmdable, principal string) (*twofactor.Enrollment, error) {
+	data, err := cmd.Get(ctx, twoFactorPrefix+principal).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, twofactor.ErrNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	var enrollment twofactor.Enrollment
+	if err := json.Unmarshal(data, &enrollment); err != nil {
+		return nil, fmt.Errorf("decode two-factor enrollment %s: %w", principal, err)
+	}
+	return &enrollment, nil
+}
@@ -0,0 +1,334 @@
+// Package elastic provides a search.Index over the REST API shared by
+// Elasticsearch (7 and later) and OpenSearch.
+//
+// Writes use the bulk API with refresh=wait_for, so documents are
+// searchable when Put returns; Text queries are multi_match queries over
+// the text fields of the index.
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// Config locates and authenticates against the cluster. APIKey takes
+// precedence over basic authentication.
+type Config struct {
+	URL      string
+	Username string
+	Password string
+	APIKey   string
+}
+
+// Client is a search.Index backed by an Elasticsearch or OpenSearch
+// cluster.
+type Client struct {
+	base *url.URL
+	cfg  Config
+	http *http.Client
+}
+
+// New creates a client for the cluster of cfg, sending requests with
+// client.
+func New(cfg Config, client *http.Client) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid search URL %q", cfg.URL)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Client{base: base, cfg: cfg, http: client}, nil
+}
+
+// mappingTypes are the field types of the mapping declarations.
+var mappingTypes = map[string]func(search.Mapping) []string{
+	"text":    func(m search.Mapping) []string { return m.Text },
+	"keyword": func(m search.Mapping) []string { return m.Keyword },
+}
+
+// CreateIndex implements search.Index. Fields outside the mapping are
+// stored but not indexed.
+func (c *Client) CreateIndex(ctx context.Context, name string, mapping search.Mapping) error {
+	properties := make(map[string]any)
+	for typ, fields := range mappingTypes {
+		for _, f := range fields(mapping) {
+			properties[f] = map[string]string{"type": typ}
+		}
+	}
+	body := map[string]any{
+		"mappings": map[string]any{"dynamic": false, "properties": properties},
+	}
+	return c.do(ctx, http.MethodPut, "/"+url.PathEscape(name), nil, body, nil)
+}
+
+// DeleteIndex implements search.Index.
+func (c *Client) DeleteIndex(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// Indexes implements search.Index.
+func (c *Client) Indexes(ctx context.Context, prefix string) ([]string, error) {
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	query := url.Values{"format": {"json"}, "h": {"index"}}
+	if err := c.do(ctx, http.MethodGet, "/_cat/indices/"+url.PathEscape(prefix)+"*", query, nil, &rows); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Index)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ResolveAlias implements search.Index.
+func (c *Client) ResolveAlias(ctx context.Context, alias string) (string, error) {
+	names, err := c.aliasIndexes(ctx, alias)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", search.ErrIndexNotFound
+	}
+	return names[0], nil
+}
+
+// aliasIndexes returns the indexes alias points to.
+func (c *Client) aliasIndexes(ctx context.Context, alias string) ([]string, error) {
+	var indexes map[string]json.RawMessage
+	err := c.do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), nil, nil, &indexes)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(indexes))
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SetAlias implements search.Index.
+func (c *Client) SetAlias(ctx context.Context, alias, index string) error {
+	current, err := c.aliasIndexes(ctx, alias)
+	if err != nil {
+		return err
+	}
+	actions := make([]any, 0, len(current)+1)
+	for _, name := range current {
+		actions = append(actions, map[string]any{"remove": map[string]string{"index": name, "alias": alias}})
+	}
+	actions = append(actions, map[string]any{"add": map[string]string{"index": index, "alias": alias}})
+	return c.do(ctx, http.MethodPost, "/_aliases", nil, map[string]any{"actions": actions}, nil)
+}
+
+// Put implements search.Index.
+func (c *Client) Put(ctx context.Context, index string, docs ...search.Document) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		_ = enc.Encode(map[string]any{"index": map[string]string{"_index": index, "_id": doc.ID}})
+		_ = enc.Encode(doc.Fields)
+	}
+	return c.bulk(ctx, &body, len(docs))
+}
+
+// Delete implements search.Index.
+func (c *Client) Delete(ctx context.Context, index string, ids ...string) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, id := range ids {
+		_ = enc.Encode(map[string]any{"delete": map[string]string{"_index": index, "_id": id}})
+	}
+	return c.bulk(ctx, &body, len(ids))
+}
+
+// bulkResult is the outcome of a bulk action.
+type bulkResult struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// bulk sends n bulk actions and fails when any item failed. Deletes of
+// missing documents are not failures.
+func (c *Client) bulk(ctx context.Context, body *bytes.Buffer, n int) error {
+	if n == 0 {
+		return nil
+	}
+	var resp struct {
+		Errors bool                    `json:"errors"`
+		Items  []map[string]bulkResult `json:"items"`
+	}
+	query := url.Values{"refresh": {"wait_for"}}
+	if err := c.send(ctx, http.MethodPost, "/_bulk", query, "application/x-ndjson", body, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+	for _, item := range resp.Items {
+		for action, result := range item {
+			if action == "delete" && result.Status == http.StatusNotFound {
+				continue
+			}
+			if len(result.Error) > 0 {
+				return fmt.Errorf("bulk %s: %s", action, result.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// Search implements search.Index.
+func (c *Client) Search(ctx context.Context, index string, q search.Query) (pagination.Page[search.Hit], error) {
+	params := q.Normalize(pagination.MaxLimit)
+
+	query := map[string]any{"match_all": map[string]any{}}
+	if strings.TrimSpace(q.Text) != "" {
+		query = map[string]any{"multi_match": map[string]any{"query": q.Text}}
+	}
+	filters := make([]any, 0, len(q.Filters))
+	for field, value := range q.Filters {
+		filters = append(filters, map[string]any{"term": map[string]string{field: value}})
+	}
+	body := map[string]any{
+		"from":             params.Offset,
+		"size":             params.Limit,
+		"track_total_hits": true,
+		"query":            map[string]any{"bool": map[string]any{"must": query, "filter": filters}},
+	}
+	if q.Highlight {
+		body["highlight"] = map[string]any{
+			"pre_tags":  []string{search.HighlightPre},
+			"post_tags": []string{search.HighlightPost},
+			"fields":    map[string]any{"*": map[string]any{}},
+		}
+	}
+
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID        string              `json:"_id"`
+				Score     float64             `json:"_score"`
+				Source    map[string]any      `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", nil, body, &resp); err != nil {
+		return pagination.Page[search.Hit]{}, err
+	}
+
+	hits := make([]search.Hit, len(resp.Hits.Hits))
+	for i, h := range resp.Hits.Hits {
+		fields := make(map[string]string, len(h.Source))
+		for k, v := range h.Source {
+			if s, ok := v.(string); ok {
+				fields[k] = s
+			} else {
+				fields[k] = fmt.Sprint(v)
+			}
+		}
+		hits[i] = search.Hit{ID: h.ID, Score: h.Score, Fields: fields, Highlights: h.Highlight}
+	}
+	return pagination.Page[search.Hit]{
+		Items:  hits,
+		Limit:  params.Limit,
+		Offset: params.Offset,
+		Total:  resp.Hits.Total.Value,
+	}, nil
+}
+
+// statusError is a non-2xx response of the cluster.
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("search cluster responded %d: %s", e.status, e.body)
+}
+
+// isNotFound reports whether err is a 404 response.
+func isNotFound(err error) bool {
+	se, ok := err.(*statusError)
+	return ok && se.status == http.StatusNotFound
+}
+
+// do sends a JSON request and decodes the JSON response into out. Missing
+// indexes are reported as search.ErrIndexNotFound.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	err := c.send(ctx, method, path, query, "application/json", body, out)
+	if se, ok := err.(*statusError); ok && se.status == http.StatusNotFound &&
+		strings.Contains(se.body, "index_not_found_exception") {
+		return fmt.Errorf("%w: %s", search.ErrIndexNotFound, path)
+	}
+	return err
+}
+
+// send sends a request and decodes the JSON response into out.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader, out any) error {
+	u := *c.base
+	u.Path += path
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.cfg.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.cfg.APIKey)
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("search cluster: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &statusError{status: resp.StatusCode, body: string(data)}
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("search cluster: decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package elastic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Search(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST /users/_search", r.Method+" "+r.URL.Path)
+		assert.Equal(t, "ApiKey secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = io.WriteString(w, `{"hits":{"total":{"value":7},"hits":[
+			{"_id":"1","_score":2.5,"_source":{"username":"jane","is_active":"true"},
+			 "highlight":{"username":["<em>jane</em>"]}}]}}`)
+	}))
+	defer srv.Close()
+	client, err := New(Config{URL: srv.URL + "/", APIKey: "secret"}, srv.Client())
+	require.NoError(t, err)
+
+	page, err := client.Search(context.Background(), "users", search.Query{
+		Text:      "jane",
+		Filters:   map[string]string{"is_active": "true"},
+		Highlight: true,
+		Params:    pagination.Params{Limit: 5, Offset: 10},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, pagination.Page[search.Hit]{
+		Items: []search.Hit{{
+			ID: "1", Score: 2.5,
+			Fields:     map[string]string{"username": "jane", "is_active": "true"},
+			Highlights: map[string][]string{"username": {"<em>jane</em>"}},
+		}},
+		Limit: 5, Offset: 10, Total: 7,
+	}, page)
+	assert.EqualValues(t, 10, body["from"])
+	assert.EqualValues(t, 5, body["size"])
+	query := body["query"].(map[string]any)["bool"].(map[string]any)
+	assert.Equal(t, map[string]any{"multi_match": map[string]any{"query": "jane"}}, query["must"])
+	assert.Equal(t, []any{map[string]any{"term": map[string]any{"is_active": "true"}}}, query["filter"])
+	assert.Contains(t, body, "highlight")
+}
+
+func TestClient_SetAliasMovesAlias(t *testing.T) {
+	var actions []any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /_alias/users":
+			_, _ = io.WriteString(w, `{"users-1":{"aliases":{"users":{}}}}`)
+		case "POST /_aliases":
+			var body struct{ Actions []any }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			actions = body.Actions
+			_, _ = io.WriteString(w, `{"acknowledged":true}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	client, err := New(Config{URL: srv.URL}, srv.Client())
+	require.NoError(t, err)
+
+	require.NoError(t, client.SetAlias(context.Background(), "users", "users-2"))
+	assert.Equal(t, []any{
+		map[string]any{"remove": map[string]any{"index": "users-1", "alias": "users"}},
+		map[string]any{"add": map[string]any{"index": "users-2", "alias": "users"}},
+	}, actions)
+}
+
+func TestClient_MissingIndexAndAlias(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		if r.URL.Path == "/_alias/users" {
+			_, _ = io.WriteString(w, `{"error":"alias [users] missing","status":404}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"error":{"type":"index_not_found_exception"},"status":404}`)
+	}))
+	defer srv.Close()
+	client, err := New(Config{URL: srv.URL}, srv.Client())
+	require.NoError(t, err)
+
+	_, err = client.ResolveAlias(context.Background(), "users")
+	assert.ErrorIs(t, err, search.ErrIndexNotFound)
+	_, err = client.Search(context.Background(), "users", search.Query{})
+	assert.ErrorIs(t, err, search.ErrIndexNotFound)
+}
+
+func TestClient_PutSendsBulkAndReportsItemErrors(t *testing.T) {
+	var lines []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "wait_for", r.URL.Query().Get("refresh"))
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "elastic:changeme", user+":"+pass)
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		_, _ = io.WriteString(w, `{"errors":true,"items":[{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`)
+	}))
+	defer srv.Close()
+	client, err := New(Config{URL: srv.URL, Username: "elastic", Password: "changeme"}, srv.Client())
+	require.NoError(t, err)
+
+	err = client.Put(context.Background(), "users", search.Document{ID: "1", Fields: map[string]string{"username": "jane"}})
+	require.ErrorContains(t, err, "mapper_parsing_exception")
+	assert.Equal(t, []string{
+		`{"index":{"_id":"1","_index":"users"}}`,
+		`{"username":"jane"}`,
+	}, lines)
+}
@@ -0,0 +1,374 @@
+// Package embedded provides an in-process search.Index for single-instance
+// deployments and tests, so search works without an Elasticsearch or
+// OpenSearch cluster.
+//
+// Indexes are held in memory and rebuilt from the source of truth on
+// start (see search.Manager.Ensure). Text fields are split on non-letter,
+// non-digit characters and lowercased; documents are ranked by TF-IDF and
+// match any query term.
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// Index is an in-memory search.Index.
+type Index struct {
+	mu      sync.RWMutex
+	indexes map[string]*index
+	aliases map[string]string
+}
+
+// index is a named index with its postings.
+type index struct {
+	text    map[string]bool
+	keyword map[string]bool
+	docs    map[string]search.Document
+
+	// postings maps a term to the documents containing it to the term
+	// frequency per text field
+	postings map[string]map[string]map[string]int
+}
+
+// New creates an empty embedded index.
+func New() *Index {
+	return &Index{
+		indexes: make(map[string]*index),
+		aliases: make(map[string]string),
+	}
+}
+
+// CreateIndex implements search.Index.
+func (x *Index) CreateIndex(ctx context.Context, name string, mapping search.Mapping) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.indexes[name]; ok {
+		return fmt.Errorf("index %q already exists", name)
+	}
+	if _, ok := x.aliases[name]; ok {
+		return fmt.Errorf("index %q conflicts with an alias", name)
+	}
+	idx := &index{
+		text:     make(map[string]bool, len(mapping.Text)),
+		keyword:  make(map[string]bool, len(mapping.Keyword)),
+		docs:     make(map[string]search.Document),
+		postings: make(map[string]map[string]map[string]int),
+	}
+	for _, f := range mapping.Text {
+		idx.text[f] = true
+	}
+	for _, f := range mapping.Keyword {
+		idx.keyword[f] = true
+	}
+	x.indexes[name] = idx
+	return nil
+}
+
+// DeleteIndex implements search.Index. Aliases pointing to the index are
+// removed with it.
+func (x *Index) DeleteIndex(ctx context.Context, name string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.indexes[name]; !ok {
+		return search.ErrIndexNotFound
+	}
+	delete(x.indexes, name)
+	for alias, target := range x.aliases {
+		if target == name {
+			delete(x.aliases, alias)
+		}
+	}
+	return nil
+}
+
+// Indexes implements search.Index.
+func (x *Index) Indexes(ctx context.Context, prefix string) ([]string, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var names []string
+	for name := range x.indexes {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ResolveAlias implements search.Index.
+func (x *Index) ResolveAlias(ctx context.Context, alias string) (string, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	name, ok := x.aliases[alias]
+	if !ok {
+		return "", search.ErrIndexNotFound
+	}
+	return name, nil
+}
+
+// SetAlias implements search.Index.
+func (x *Index) SetAlias(ctx context.Context, alias, name string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.indexes[name]; !ok {
+		return search.ErrIndexNotFound
+	}
+	if _, ok := x.indexes[alias]; ok {
+		return fmt.Errorf("alias %q conflicts with an index", alias)
+	}
+	x.aliases[alias] = name
+	return nil
+}
+
+// lookup returns the index or alias target called name.
+func (x *Index) lookup(name string) (*index, error) {
+	if target, ok := x.aliases[name]; ok {
+		name = target
+	}
+	idx, ok := x.indexes[name]
+	if !ok {
+		return nil, search.ErrIndexNotFound
+	}
+	return idx, nil
+}
+
+// Put implements search.Index.
+func (x *Index) Put(ctx context.Context, name string, docs ...search.Document) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	idx, err := x.lookup(name)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		idx.remove(doc.ID)
+		idx.add(doc)
+	}
+	return nil
+}
+
+// Delete implements search.Index.
+func (x *Index) Delete(ctx context.Context, name string, ids ...string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	idx, err := x.lookup(name)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		idx.remove(id)
+	}
+	return nil
+}
+
+// Search implements search.Index.
+func (x *Index) Search(ctx context.Context, name string, q search.Query) (pagination.Page[search.Hit], error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	idx, err := x.lookup(name)
+	if err != nil {
+		return pagination.Page[search.Hit]{}, err
+	}
+
+	terms := uniqueTerms(q.Text)
+	scores := idx.score(terms)
+	hits := make([]search.Hit, 0, len(scores))
+	for id, score := range scores {
+		doc := idx.docs[id]
+		if !matches(doc, q.Filters) {
+			continue
+		}
+		hit := search.Hit{ID: id, Score: score, Fields: doc.Fields}
+		if q.Highlight && len(terms) > 0 {
+			hit.Highlights = idx.highlight(doc, terms)
+		}
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	params := q.Normalize(pagination.MaxLimit)
+	start, end := pagination.Window(params, len(hits))
+	return pagination.Page[search.Hit]{
+		Items:  hits[start:end],
+		Limit:  params.Limit,
+		Offset: params.Offset,
+		Total:  len(hits),
+	}, nil
+}
+
+// add indexes the text fields of doc.
+func (idx *index) add(doc search.Document) {
+	idx.docs[doc.ID] = doc
+	for field, value := range doc.Fields {
+		if !idx.text[field] {
+			continue
+		}
+		for _, tok := range tokenize(value) {
+			docs := idx.postings[tok.term]
+			if docs == nil {
+				docs = make(map[string]map[string]int)
+				idx.postings[tok.term] = docs
+			}
+			fields := docs[doc.ID]
+			if fields == nil {
+				fields = make(map[string]int)
+				docs[doc.ID] = fields
+			}
+			fields[field]++
+		}
+	}
+}
+
+// remove drops the document with id and its postings.
+func (idx *index) remove(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	delete(idx.docs, id)
+	for field, value := range doc.Fields {
+		if !idx.text[field] {
+			continue
+		}
+		for _, tok := range tokenize(value) {
+			delete(idx.postings[tok.term], id)
+			if len(idx.postings[tok.term]) == 0 {
+				delete(idx.postings, tok.term)
+			}
+		}
+	}
+}
+
+// score ranks the documents containing any of terms by TF-IDF; without
+// terms every document matches with a score of 1.
+func (idx *index) score(terms []string) map[string]float64 {
+	scores := make(map[string]float64)
+	if len(terms) == 0 {
+		for id := range idx.docs {
+			scores[id] = 1
+		}
+		return scores
+	}
+
+	n := float64(len(idx.docs))
+	for _, term := range terms {
+		docs := idx.postings[term]
+		idf := 1 + math.Log(n/float64(len(docs)+1)+1)
+		for id, fields := range docs {
+			for _, tf := range fields {
+				scores[id] += (1 + math.Log(float64(tf))) * idf
+			}
+		}
+	}
+	return scores
+}
+
+// highlight returns the text fields of doc containing terms, with the
+// matched terms wrapped in the highlight tags.
+func (idx *index) highlight(doc search.Document, terms []string) map[string][]string {
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	highlights := make(map[string][]string)
+	for field, value := range doc.Fields {
+		if !idx.text[field] {
+			continue
+		}
+		var b strings.Builder
+		last, matched := 0, false
+		for _, tok := range tokenize(value) {
+			if !wanted[tok.term] {
+				continue
+			}
+			b.WriteString(value[last:tok.start])
+			b.WriteString(search.HighlightPre)
+			b.WriteString(value[tok.start:tok.end])
+			b.WriteString(search.HighlightPost)
+			last, matched = tok.end, true
+		}
+		if matched {
+			b.WriteString(value[last:])
+			highlights[field] = []string{b.String()}
+		}
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+	return highlights
+}
+
+// matches reports whether the fields of doc equal filters.
+func matches(doc search.Document, filters map[string]string) bool {
+	for field, want := range filters {
+		if doc.Fields[field] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// token is a term and its byte offsets in the analyzed text.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits s into lowercased runs of letters and digits.
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	for i := 0; i <= len(s); {
+		r, size := utf8.RuneError, 1
+		if i < len(s) {
+			r, size = utf8.DecodeRuneInString(s[i:])
+		}
+		inWord := i < len(s) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, token{term: strings.ToLower(s[start:i]), start: start, end: i})
+			start = -1
+		}
+		i += size
+	}
+	return tokens
+}
+
+// uniqueTerms returns the distinct terms of a query.
+func uniqueTerms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, tok := range tokenize(text) {
+		if !seen[tok.term] {
+			seen[tok.term] = true
+			terms = append(terms, tok.term)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,81 @@
+package embedded
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex(t *testing.T) *Index {
+	t.Helper()
+	idx := New()
+	ctx := context.Background()
+	require.NoError(t, idx.CreateIndex(ctx, "people-1", search.Mapping{Text: []string{"name", "bio"}, Keyword: []string{"team"}}))
+	require.NoError(t, idx.SetAlias(ctx, "people", "people-1"))
+	require.NoError(t, idx.Put(ctx, "people",
+		search.Document{ID: "1", Fields: map[string]string{"name": "Jane Doe", "bio": "Go and Rust", "team": "core"}},
+		search.Document{ID: "2", Fields: map[string]string{"name": "John Doe", "bio": "Go, Go, Go!", "team": "web"}},
+		search.Document{ID: "3", Fields: map[string]string{"name": "Ann Lee", "bio": "Python", "team": "core"}},
+	))
+	return idx
+}
+
+func TestIndex_SearchRanksAndHighlights(t *testing.T) {
+	idx := newTestIndex(t)
+
+	page, err := idx.Search(context.Background(), "people", search.Query{Text: "go", Highlight: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	assert.Equal(t, "2", page.Items[0].ID, "higher term frequency ranks first")
+	assert.Equal(t, []string{"<em>Go</em>, <em>Go</em>, <em>Go</em>!"}, page.Items[0].Highlights["bio"])
+	assert.NotContains(t, page.Items[0].Highlights, "name")
+
+	page, err = idx.Search(context.Background(), "people", search.Query{Text: "doe", Filters: map[string]string{"team": "core"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	assert.Equal(t, "1", page.Items[0].ID)
+	assert.Nil(t, page.Items[0].Highlights)
+}
+
+func TestIndex_EmptyQueryMatchesAllPaginated(t *testing.T) {
+	idx := newTestIndex(t)
+
+	page, err := idx.Search(context.Background(), "people", search.Query{Params: pagination.Params{Limit: 2, Offset: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "2", page.Items[0].ID)
+	assert.Equal(t, "3", page.Items[1].ID)
+}
+
+func TestIndex_PutReplacesAndDeleteRemoves(t *testing.T) {
+	idx := newTestIndex(t)
+	ctx := context.Background()
+
+	require.NoError(t, idx.Put(ctx, "people", search.Document{ID: "3", Fields: map[string]string{"name": "Ann Lee", "bio": "Go"}}))
+	require.NoError(t, idx.Delete(ctx, "people", "2", "missing"))
+
+	page, err := idx.Search(ctx, "people", search.Query{Text: "go"})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	assert.ElementsMatch(t, []string{"1", "3"}, []string{page.Items[0].ID, page.Items[1].ID})
+
+	page, err = idx.Search(ctx, "people", search.Query{Text: "python"})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+}
+
+func TestIndex_DeleteIndexRemovesAliases(t *testing.T) {
+	idx := newTestIndex(t)
+	ctx := context.Background()
+
+	require.NoError(t, idx.DeleteIndex(ctx, "people-1"))
+	_, err := idx.ResolveAlias(ctx, "people")
+	assert.ErrorIs(t, err, search.ErrIndexNotFound)
+	_, err = idx.Search(ctx, "people", search.Query{})
+	assert.ErrorIs(t, err, search.ErrIndexNotFound)
+}
@@ -0,0 +1,158 @@
+package temporal
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/client"
+)
+
+// metricLabels are the SDK tags kept as labels. Other tags are dropped so
+// every series of a metric has the same label names.
+var metricLabels = []string{
+	"namespace", "task_queue", "workflow_type", "activity_type",
+	"operation", "worker_type", "poller_type", "failure_reason",
+}
+
+// latencyBuckets cover request latencies up to the one minute long polls.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// NewMetricsHandler exports the SDK metrics to reg: counters get a _total
+// suffix and timers become histograms in seconds, e.g.
+// temporal_request_latency_seconds. Metrics are registered when first
+// emitted.
+func NewMetricsHandler(reg prometheus.Registerer) client.MetricsHandler {
+	return &metricsHandler{vecs: &metricVecs{
+		reg:      reg,
+		counters: make(map[string]*prometheus.CounterVec),
+		gauges:   make(map[string]*prometheus.GaugeVec),
+		timers:   make(map[string]*prometheus.HistogramVec),
+	}}
+}
+
+// metricsHandler emits to the series of its tags.
+type metricsHandler struct {
+	vecs *metricVecs
+	tags map[string]string
+}
+
+// WithTags implements client.MetricsHandler.
+func (h *metricsHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(h.tags)+len(tags))
+	for k, v := range h.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return &metricsHandler{vecs: h.vecs, tags: merged}
+}
+
+// Counter implements client.MetricsHandler.
+func (h *metricsHandler) Counter(name string) client.MetricsCounter {
+	counter := h.vecs.counter(name).With(h.labels())
+	return counterFunc(func(delta int64) { counter.Add(float64(delta)) })
+}
+
+// Gauge implements client.MetricsHandler.
+func (h *metricsHandler) Gauge(name string) client.MetricsGauge {
+	gauge := h.vecs.gauge(name).With(h.labels())
+	return gaugeFunc(gauge.Set)
+}
+
+// Timer implements client.MetricsHandler.
+func (h *metricsHandler) Timer(name string) client.MetricsTimer {
+	histogram := h.vecs.timer(name).With(h.labels())
+	return timerFunc(func(d time.Duration) { histogram.Observe(d.Seconds()) })
+}
+
+// labels returns the values of metricLabels, empty for missing tags.
+func (h *metricsHandler) labels() prometheus.Labels {
+	labels := make(prometheus.Labels, len(metricLabels))
+	for _, name := range metricLabels {
+		labels[name] = h.tags[name]
+	}
+	return labels
+}
+
+// metricVecs holds the registered metrics shared by all handlers derived
+// from one NewMetricsHandler.
+type metricVecs struct {
+	reg      prometheus.Registerer
+	mu       sync.Mutex
+	counters map[string]*prometheus.CounterVec
+	gauges   map[string]*prometheus.GaugeVec
+	timers   map[string]*prometheus.HistogramVec
+}
+
+func (m *metricVecs) counter(name string) *prometheus.CounterVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if vec, ok := m.counters[name]; ok {
+		return vec
+	}
+	vec := register(m.reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: name + "_total",
+		Help: "Temporal SDK counter " + name + ".",
+	}, metricLabels))
+	m.counters[name] = vec
+	return vec
+}
+
+func (m *metricVecs) gauge(name string) *prometheus.GaugeVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if vec, ok := m.gauges[name]; ok {
+		return vec
+	}
+	vec := register(m.reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: name,
+		Help: "Temporal SDK gauge " + name + ".",
+	}, metricLabels))
+	m.gauges[name] = vec
+	return vec
+}
+
+func (m *metricVecs) timer(name string) *prometheus.HistogramVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if vec, ok := m.timers[name]; ok {
+		return vec
+	}
+	vec := register(m.reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name + "_seconds",
+		Help:    "Temporal SDK timer " + name + ".",
+		Buckets: latencyBuckets,
+	}, metricLabels))
+	m.timers[name] = vec
+	return vec
+}
+
+// register registers c with reg, returning the collector already
+// registered under the same name, e.g. by another client.
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	if err := reg.Register(c); err != nil {
+		var exists prometheus.AlreadyRegisteredError
+		if errors.As(err, &exists) {
+			if existing, ok := exists.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+type counterFunc func(int64)
+
+func (f counterFunc) Inc(delta int64) { f(delta) }
+
+type gaugeFunc func(float64)
+
+func (f gaugeFunc) Update(value float64) { f(value) }
+
+type timerFunc func(time.Duration)
+
+func (f timerFunc) Record(d time.Duration) { f(d) }
@@ -0,0 +1,112 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+)
+
+// requestContextHeader is the workflow header carrying the request context.
+const requestContextHeader = "x-request-context"
+
+// propagated is the part of reqctx.RequestContext sent with workflows and
+// activities. Deadlines and feature flags stay with the request.
+type propagated struct {
+	RequestID string `json:"request_id,omitempty"`
+	Principal string `json:"principal,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+	Locale    string `json:"locale,omitempty"`
+}
+
+type workflowContextKey struct{}
+
+// RequestContextPropagator returns the context propagator sending the
+// request ID, principal, tenant and locale of the starting request to the
+// workflow, and from the workflow to its activities and child workflows.
+// Activities read them with the reqctx accessors, workflows with
+// RequestContext.
+func RequestContextPropagator() workflow.ContextPropagator {
+	return requestContextPropagator{}
+}
+
+// RequestContext returns the request context propagated to the workflow,
+// empty when it was started outside a request.
+func RequestContext(ctx workflow.Context) *reqctx.RequestContext {
+	v, _ := ctx.Value(workflowContextKey{}).(propagated)
+	return v.requestContext()
+}
+
+type requestContextPropagator struct{}
+
+// Inject implements workflow.ContextPropagator.
+func (requestContextPropagator) Inject(ctx context.Context, w workflow.HeaderWriter) error {
+	rc := reqctx.From(ctx)
+	return writeHeader(w, propagated{
+		RequestID: rc.RequestID,
+		Principal: rc.Principal,
+		Tenant:    rc.Tenant,
+		Locale:    rc.Locale,
+	})
+}
+
+// Extract implements workflow.ContextPropagator.
+func (requestContextPropagator) Extract(ctx context.Context, r workflow.HeaderReader) (context.Context, error) {
+	v, ok, err := readHeader(r)
+	if !ok || err != nil {
+		return ctx, err
+	}
+	return reqctx.With(ctx, v.requestContext()), nil
+}
+
+// InjectFromWorkflow implements workflow.ContextPropagator.
+func (requestContextPropagator) InjectFromWorkflow(ctx workflow.Context, w workflow.HeaderWriter) error {
+	v, _ := ctx.Value(workflowContextKey{}).(propagated)
+	return writeHeader(w, v)
+}
+
+// ExtractToWorkflow implements workflow.ContextPropagator.
+func (requestContextPropagator) ExtractToWorkflow(ctx workflow.Context, r workflow.HeaderReader) (workflow.Context, error) {
+	v, ok, err := readHeader(r)
+	if !ok || err != nil {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, workflowContextKey{}, v), nil
+}
+
+func (v propagated) requestContext() *reqctx.RequestContext {
+	return &reqctx.RequestContext{
+		RequestID: v.RequestID,
+		Principal: v.Principal,
+		Tenant:    v.Tenant,
+		Locale:    v.Locale,
+	}
+}
+
+// writeHeader sets the header from v; nothing is sent outside a request.
+func writeHeader(w workflow.HeaderWriter, v propagated) error {
+	if v == (propagated{}) {
+		return nil
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(v)
+	if err != nil {
+		return fmt.Errorf("encode request context: %w", err)
+	}
+	w.Set(requestContextHeader, payload)
+	return nil
+}
+
+// readHeader decodes the header, reporting whether it was sent.
+func readHeader(r workflow.HeaderReader) (propagated, bool, error) {
+	payload, ok := r.Get(requestContextHeader)
+	if !ok {
+		return propagated{}, false, nil
+	}
+	var v propagated
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &v); err != nil {
+		return propagated{}, false, fmt.Errorf("decode request context: %w", err)
+	}
+	return v, true, nil
+}
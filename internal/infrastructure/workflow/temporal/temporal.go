@@ -0,0 +1,109 @@
+// Package temporal connects the application to a Temporal cluster, so
+// modules can run durable workflows alongside the HTTP handlers.
+//
+// Modules register their workflows and activities on a task queue of the
+// Registry; the worker process mode polls each queue with a worker:
+//
+//	registry.Register("", func(r worker.Registry) {
+//		r.RegisterWorkflow(billing.InvoiceWorkflow)
+//		r.RegisterActivity(billing.NewActivities(service))
+//	})
+//
+// Handlers start workflows with the client; the request ID, principal,
+// tenant and locale of the request context reach the workflow and its
+// activities (see RequestContext).
+package temporal
+
+import (
+	"fmt"
+
+	"github.com/luminosita/change-me/pkg/grpcclient"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Config locates the cluster and namespace.
+type Config struct {
+	// HostPort is the frontend address, e.g. "localhost:7233"
+	HostPort  string
+	Namespace string
+
+	// APIKey authenticates with Temporal Cloud; empty sends none
+	APIKey string
+
+	TLS grpcclient.TLSConfig
+}
+
+// Options configures the optional collaborators of the client.
+type Options struct {
+	// Logger receives the SDK logs; nil keeps the SDK default
+	Logger *logger.Logger
+
+	// Metrics registers the SDK metrics (see NewMetricsHandler); nil
+	// disables them
+	Metrics prometheus.Registerer
+
+	// Interceptors wrap client calls; those also implementing
+	// interceptor.WorkerInterceptor intercept the workers as well
+	Interceptors []interceptor.ClientInterceptor
+}
+
+// New creates a client for the namespace of cfg. It connects on first
+// use, so the process starts while the cluster is unreachable.
+//
+// Parameters:
+//   - cfg: Cluster address, namespace and credentials
+//   - opts: Logger, metrics and interceptors
+//
+// Returns:
+//   - client.Client: Temporal client; close it on shutdown
+//   - error: Unreadable TLS files
+func New(cfg Config, opts Options) (client.Client, error) {
+	tlsConfig, err := cfg.TLS.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("temporal tls: %w", err)
+	}
+
+	options := client.Options{
+		HostPort:           cfg.HostPort,
+		Namespace:          cfg.Namespace,
+		ContextPropagators: []workflow.ContextPropagator{RequestContextPropagator()},
+		ConnectionOptions:  client.ConnectionOptions{TLS: tlsConfig},
+		Interceptors:       opts.Interceptors,
+	}
+	if cfg.APIKey != "" {
+		options.Credentials = client.NewAPIKeyStaticCredentials(cfg.APIKey)
+	}
+	if opts.Logger != nil {
+		options.Logger = NewLogger(opts.Logger)
+	}
+	if opts.Metrics != nil {
+		options.MetricsHandler = NewMetricsHandler(opts.Metrics)
+	}
+	return client.NewLazyClient(options)
+}
+
+// NewLogger adapts log to the SDK logger interface.
+func NewLogger(log *logger.Logger) log.Logger {
+	return sdkLogger{log: log}
+}
+
+// sdkLogger writes SDK logs as structured entries of the application
+// logger.
+type sdkLogger struct {
+	log *logger.Logger
+}
+
+func (l sdkLogger) Debug(msg string, keyvals ...any) { l.log.Debugw(msg, keyvals...) }
+func (l sdkLogger) Info(msg string, keyvals ...any)  { l.log.Infow(msg, keyvals...) }
+func (l sdkLogger) Warn(msg string, keyvals ...any)  { l.log.Warnw(msg, keyvals...) }
+func (l sdkLogger) Error(msg string, keyvals ...any) { l.log.Errorw(msg, keyvals...) }
+
+// With implements log.WithLogger.
+func (l sdkLogger) With(keyvals ...any) log.Logger {
+	return sdkLogger{log: l.log.With(keyvals...)}
+}
@@ -0,0 +1,149 @@
+package temporal
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/grpcclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// header adapts a workflow header to the propagator reader and writer.
+type header struct{ *commonpb.Header }
+
+func (h header) Set(key string, p *commonpb.Payload) { h.Fields[key] = p }
+
+func (h header) Get(key string) (*commonpb.Payload, bool) {
+	p, ok := h.Fields[key]
+	return p, ok
+}
+
+func (h header) ForEachKey(fn func(string, *commonpb.Payload) error) error {
+	for k, p := range h.Fields {
+		if err := fn(k, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callerActivity reports the caller seen by an activity.
+func callerActivity(ctx context.Context) (string, error) {
+	return reqctx.RequestID(ctx) + " " + reqctx.Principal(ctx), nil
+}
+
+// callerWorkflow reports the tenant seen by the workflow and the caller
+// seen by its activity.
+func callerWorkflow(ctx workflow.Context) (string, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+	var caller string
+	if err := workflow.ExecuteActivity(ctx, callerActivity).Get(ctx, &caller); err != nil {
+		return "", err
+	}
+	return RequestContext(ctx).Tenant + " " + caller, nil
+}
+
+func TestRequestContextPropagator_ReachesWorkflowAndActivities(t *testing.T) {
+	registry := NewRegistry("default")
+	registry.Register("", func(r worker.Registry) {
+		r.RegisterWorkflow(callerWorkflow)
+		r.RegisterActivity(callerActivity)
+	})
+
+	ctx := reqctx.With(context.Background(), &reqctx.RequestContext{
+		RequestID: "req-1",
+		Principal: "ann",
+		Tenant:    "acme",
+		Deadline:  time.Now().Add(time.Second),
+	})
+	h := header{&commonpb.Header{Fields: map[string]*commonpb.Payload{}}}
+	require.NoError(t, RequestContextPropagator().Inject(ctx, h))
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetContextPropagators([]workflow.ContextPropagator{RequestContextPropagator()})
+	env.SetHeader(h.Header)
+	registry.Apply("default", env)
+
+	env.ExecuteWorkflow(callerWorkflow)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result string
+	require.NoError(t, env.GetWorkflowResult(&result))
+	assert.Equal(t, "acme req-1 ann", result)
+}
+
+func TestRequestContextPropagator_NothingOutsideRequests(t *testing.T) {
+	h := header{&commonpb.Header{Fields: map[string]*commonpb.Payload{}}}
+
+	require.NoError(t, RequestContextPropagator().Inject(context.Background(), h))
+	ctx, err := RequestContextPropagator().Extract(context.Background(), h)
+
+	require.NoError(t, err)
+	assert.Empty(t, h.Fields)
+	assert.Empty(t, reqctx.RequestID(ctx))
+}
+
+func TestMetricsHandler_ExportsSeriesByTags(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	handler := NewMetricsHandler(reg).WithTags(map[string]string{"namespace": "default", "client_name": "sdk"})
+	queue := handler.WithTags(map[string]string{"task_queue": "billing"})
+
+	queue.Counter("temporal_request").Inc(2)
+	queue.Counter("temporal_request").Inc(1)
+	queue.Gauge("temporal_worker_task_slots_available").Update(5)
+	queue.Timer("temporal_request_latency").Record(1500 * time.Millisecond)
+	// A second client on the registry shares the series
+	NewMetricsHandler(reg).Counter("temporal_request").Inc(1)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP temporal_request_total Temporal SDK counter temporal_request.
+# TYPE temporal_request_total counter
+temporal_request_total{activity_type="",failure_reason="",namespace="",operation="",poller_type="",task_queue="",worker_type="",workflow_type=""} 1
+temporal_request_total{activity_type="",failure_reason="",namespace="default",operation="",poller_type="",task_queue="billing",worker_type="",workflow_type=""} 3
+# HELP temporal_worker_task_slots_available Temporal SDK gauge temporal_worker_task_slots_available.
+# TYPE temporal_worker_task_slots_available gauge
+temporal_worker_task_slots_available{activity_type="",failure_reason="",namespace="default",operation="",poller_type="",task_queue="billing",worker_type="",workflow_type=""} 5
+`), "temporal_request_total", "temporal_worker_task_slots_available"))
+	assert.Equal(t, 1, testutil.CollectAndCount(reg, "temporal_request_latency_seconds"))
+}
+
+func TestRegistry_WorkerPerTaskQueue(t *testing.T) {
+	c, err := New(Config{HostPort: "127.0.0.1:1", Namespace: "default"}, Options{})
+	require.NoError(t, err, "the client connects on first use")
+	defer c.Close()
+
+	registry := NewRegistry("default")
+	registry.Register("billing", func(r worker.Registry) { r.RegisterActivity(callerActivity) })
+	registry.Register("", func(r worker.Registry) { r.RegisterWorkflow(callerWorkflow) })
+	registry.Register("billing", func(r worker.Registry) { r.RegisterWorkflow(callerWorkflow) })
+
+	workers := registry.Workers(c, worker.Options{})
+
+	assert.Equal(t, []string{"billing", "default"}, registry.TaskQueues())
+	require.Len(t, workers, 2)
+	assert.Equal(t, "temporal:billing", workers[0].Name())
+	assert.Equal(t, "temporal:default", workers[1].Name())
+}
+
+func TestNew_UnreadableTLSFiles(t *testing.T) {
+	_, err := New(Config{
+		HostPort:  "127.0.0.1:7233",
+		Namespace: "default",
+		TLS:       grpcclient.TLSConfig{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	}, Options{})
+
+	assert.ErrorContains(t, err, "temporal tls")
+}
@@ -0,0 +1,89 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	appworker "github.com/luminosita/change-me/internal/core/worker"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Registrar registers the workflows and activities of a module.
+type Registrar func(r worker.Registry)
+
+// Registry collects the registrations of modules per task queue.
+type Registry struct {
+	defaultQueue string
+
+	mu         sync.Mutex
+	queues     []string
+	registrars map[string][]Registrar
+}
+
+// NewRegistry creates a registry whose registrations without a task queue
+// go to defaultQueue.
+func NewRegistry(defaultQueue string) *Registry {
+	return &Registry{defaultQueue: defaultQueue, registrars: make(map[string][]Registrar)}
+}
+
+// Register adds fn to the registrations of taskQueue, the default queue
+// when empty.
+func (r *Registry) Register(taskQueue string, fn Registrar) {
+	if taskQueue == "" {
+		taskQueue = r.defaultQueue
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.registrars[taskQueue]; !ok {
+		r.queues = append(r.queues, taskQueue)
+	}
+	r.registrars[taskQueue] = append(r.registrars[taskQueue], fn)
+}
+
+// TaskQueues returns the queues with registrations in registration order.
+func (r *Registry) TaskQueues() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.queues...)
+}
+
+// Apply runs the registrations of taskQueue on target, e.g. a test
+// workflow environment.
+func (r *Registry) Apply(taskQueue string, target worker.Registry) {
+	r.mu.Lock()
+	registrars := append([]Registrar(nil), r.registrars[taskQueue]...)
+	r.mu.Unlock()
+	for _, fn := range registrars {
+		fn(target)
+	}
+}
+
+// Workers returns a background worker polling each task queue with c.
+// Each stops polling when its context is canceled, after the activities
+// in progress finish or opts.WorkerStopTimeout elapses.
+//
+// Parameters:
+//   - c: Temporal client
+//   - opts: Options of the SDK workers, e.g. concurrency and interceptors
+//
+// Returns:
+//   - []appworker.Worker: One "temporal:<queue>" worker per task queue
+func (r *Registry) Workers(c client.Client, opts worker.Options) []appworker.Worker {
+	queues := r.TaskQueues()
+	workers := make([]appworker.Worker, 0, len(queues))
+	for _, queue := range queues {
+		w := worker.New(c, queue, opts)
+		r.Apply(queue, w)
+		workers = append(workers, appworker.New("temporal:"+queue, func(ctx context.Context) error {
+			if err := w.Start(); err != nil {
+				return fmt.Errorf("start temporal worker for %s: %w", queue, err)
+			}
+			<-ctx.Done()
+			w.Stop()
+			return nil
+		}))
+	}
+	return workers
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/strictjson"
+)
+
+// bindJSON binds the JSON body of the request to obj and validates it.
+// Routes the StrictJSON middleware applies to are decoded with
+// pkg/strictjson, rejecting unknown fields and oversized documents and
+// reporting every problem as a violation; others bind like
+// c.ShouldBindJSON.
+func bindJSON(c *gin.Context, obj any) error {
+	opts, strict := strictjson.FromContext(c.Request.Context())
+	if !strict {
+		return c.ShouldBindJSON(obj)
+	}
+	if c.Request.Body == nil {
+		return &strictjson.Error{Violations: []strictjson.Violation{
+			{Code: strictjson.CodeSyntax, Message: "request body is empty"},
+		}}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	if err := strictjson.Decode(body, obj, opts); err != nil {
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		if violations := strictjson.ValidationViolations(err, obj); violations != nil {
+			return &strictjson.Error{Violations: violations}
+		}
+		return err
+	}
+	return nil
+}
+
+// respondBindError aborts the request with 400 for a bindJSON error,
+// listing the violations of strictly decoded bodies.
+func respondBindError(c *gin.Context, err error) {
+	var strictErr *strictjson.Error
+	if !errors.As(err, &strictErr) {
+		respondError(c, http.StatusBadRequest, string(apperrors.KindInvalid), err.Error())
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
+		Error:      string(apperrors.KindInvalid),
+		Code:       string(apperrors.KindInvalid),
+		Message:    strictErr.Error(),
+		Violations: strictErr.Violations,
+	})
+}
+
+// bindPathID reads the route parameter name into id, typically an
+// ids.ID, aborting the request with 400 when it is malformed.
+func bindPathID(c *gin.Context, name string, id encoding.TextUnmarshaler) bool {
+	if err := id.UnmarshalText([]byte(c.Param(name))); err != nil {
+		respondError(c, http.StatusBadRequest, string(apperrors.KindInvalid), name+" is not a valid id")
+		return false
+	}
+	return true
+}
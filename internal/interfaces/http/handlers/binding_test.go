@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reports"
+	"github.com/luminosita/change-me/pkg/strictjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type request struct {
+		Email string   `json:"email" binding:"required,email"`
+		Tags  []string `json:"tags" binding:"max=2"`
+	}
+	newRouter := func(strict bool) *gin.Engine {
+		router := gin.New()
+		if strict {
+			router.Use(func(c *gin.Context) {
+				opts := strictjson.Options{DisallowUnknownFields: true, MaxArrayLen: 3}
+				c.Request = c.Request.WithContext(strictjson.WithOptions(c.Request.Context(), opts))
+			})
+		}
+		router.POST("/", func(c *gin.Context) {
+			var req request
+			if err := bindJSON(c, &req); err != nil {
+				respondBindError(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, req)
+		})
+		return router
+	}
+	post := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Lenient binding drops unknown fields
+	w := post(newRouter(false), `{"email":"jane@example.com","emial":"typo"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	strict := newRouter(true)
+	w = post(strict, `{"email":"jane@example.com","emial":"typo"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"error": "invalid_request",
+		"code": "invalid_request",
+		"message": "emial: unknown field",
+		"violations": [{"field": "emial", "code": "unknown_field", "message": "unknown field"}]
+	}`, w.Body.String())
+
+	w = post(strict, `{"tags":["a","b","c","d"]}`)
+	assert.Contains(t, w.Body.String(), `"code":"max_items"`)
+
+	w = post(strict, `{"tags":["a","b","c"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []strictjson.Violation{
+		{Field: "email", Code: "required", Message: "is required"},
+		{Field: "tags", Code: "max", Message: "must satisfy max=2"},
+	}, resp.Violations)
+
+	w = post(strict, `{"email":"jane@example.com"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBindPathID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/operations/:id", func(c *gin.Context) {
+		var id reports.OperationID
+		if !bindPathID(c, "id", &id) {
+			return
+		}
+		c.String(http.StatusOK, id.String())
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/operations/01H455VB4PEX5VSKNK084SN02Q")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "01890a5d-ac96-774b-bcce-b302099a8057", w.Body.String(), "ULIDs are read and written as UUIDs")
+
+	w = get("/operations/42")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "id is not a valid id")
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/batch"
+)
+
+// MaxBulkItems caps the number of operations accepted in one bulk request.
+const MaxBulkItems = 100
+
+// codeTooManyItems is returned when a bulk request exceeds MaxBulkItems.
+var codeTooManyItems = apperrors.Register(apperrors.Entry{
+	Code:        "too_many_items",
+	Kind:        apperrors.KindInvalid,
+	Status:      http.StatusRequestEntityTooLarge,
+	Description: fmt.Sprintf("A bulk request carried more than %d items.", MaxBulkItems),
+})
+
+// BulkRequest is the envelope for bulk endpoints.
+type BulkRequest[T any] struct {
+	Items []T `json:"items"`
+}
+
+// BulkItemResult reports the outcome of a single bulk operation.
+type BulkItemResult[T any] struct {
+	Index  int            `json:"index" example:"0"`
+	Status int            `json:"status" example:"201"`
+	Data   *T             `json:"data,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
+
+// BulkResponse is the 207 Multi-Status body returned by bulk endpoints.
+type BulkResponse[T any] struct {
+	Results   []BulkItemResult[T] `json:"results"`
+	Succeeded int                 `json:"succeeded" example:"1"`
+	Failed    int                 `json:"failed" example:"0"`
+}
+
+// bulkOperation describes how a bulk endpoint executes its items.
+type bulkOperation[Req, Resp any] struct {
+	// successStatus is reported for items that succeed (e.g. 201).
+	successStatus int
+
+	// execute runs the validated items; outcomes are indexed like the input.
+	execute func(ctx context.Context, items []Req) []batch.Outcome[Resp]
+
+	// mapError converts an item failure into a status and error body.
+	mapError func(err error) (int, ErrorResponse)
+}
+
+// handleBulk binds a BulkRequest, validates every item independently, runs
+// the valid ones and responds with per-item results. The response is 200 when
+// every item succeeded and 207 Multi-Status otherwise.
+func handleBulk[Req, Resp any](c *gin.Context, op bulkOperation[Req, Resp]) {
+	var req BulkRequest[Req]
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if len(req.Items) == 0 {
+		respondError(c, http.StatusBadRequest, "invalid_request", "items must not be empty")
+		return
+	}
+	if len(req.Items) > MaxBulkItems {
+		respondError(c, http.StatusRequestEntityTooLarge, codeTooManyItems,
+			fmt.Sprintf("at most %d items are allowed per request", MaxBulkItems))
+		return
+	}
+
+	results := make([]BulkItemResult[Resp], len(req.Items))
+	valid := make([]Req, 0, len(req.Items))
+	positions := make([]int, 0, len(req.Items))
+
+	for i := range req.Items {
+		results[i].Index = i
+		if err := binding.Validator.ValidateStruct(&req.Items[i]); err != nil {
+			results[i].Status = http.StatusUnprocessableEntity
+			results[i].Error = &ErrorResponse{Error: "invalid_request", Code: "invalid_request", Message: err.Error()}
+			continue
+		}
+		valid = append(valid, req.Items[i])
+		positions = append(positions, i)
+	}
+
+	if len(valid) > 0 {
+		for j, outcome := range op.execute(c.Request.Context(), valid) {
+			res := &results[positions[j]]
+			if outcome.Err != nil {
+				status, body := op.mapError(outcome.Err)
+				res.Status = status
+				res.Error = &body
+				continue
+			}
+			value := outcome.Value
+			res.Status = op.successStatus
+			res.Data = &value
+		}
+	}
+
+	resp := BulkResponse[Resp]{Results: results}
+	for _, r := range results {
+		if r.Error == nil {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	status := http.StatusOK
+	if resp.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, resp)
+}
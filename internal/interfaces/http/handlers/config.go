@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/config"
+)
+
+// ConfigResponse is the effective configuration keyed by environment
+// variable.
+type ConfigResponse struct {
+	Settings map[string]config.Setting `json:"settings"`
+}
+
+// ConfigHandler serves the effective configuration for debugging.
+type ConfigHandler struct {
+	cfg *config.Config
+}
+
+// NewConfigHandler creates a new configuration handler.
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{cfg: cfg}
+}
+
+// Register mounts the configuration route on the admin group.
+func (h *ConfigHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/config", h.Get)
+}
+
+// Get handles GET /admin/config.
+//
+// @Summary Describe the effective configuration
+// @Description Returns every setting with its effective value and where it was loaded from (default, file or env), with secrets and URL passwords redacted
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} ConfigResponse
+// @Router /admin/config [get]
+func (h *ConfigHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, ConfigResponse{Settings: h.cfg.Settings()})
+}
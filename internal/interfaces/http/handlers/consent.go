@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/consent"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// ConsentHandler handles policy documents and their acceptance by the
+// calling principal.
+type ConsentHandler struct {
+	service *consent.Service
+	log     *logger.Logger
+}
+
+// NewConsentHandler creates a new consent handler.
+func NewConsentHandler(service *consent.Service, log *logger.Logger) *ConsentHandler {
+	return &ConsentHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// PolicyResponse represents a published policy version.
+type PolicyResponse struct {
+	Name        string `json:"name" example:"terms"`
+	Version     string `json:"version" example:"2024-01-15"`
+	Title       string `json:"title,omitempty" example:"Terms of Service"`
+	URL         string `json:"url,omitempty" example:"https://example.com/terms"`
+	Body        string `json:"body,omitempty"`
+	PublishedAt string `json:"published_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// PublishPolicyRequest represents a new policy version.
+type PublishPolicyRequest struct {
+	Name    string `json:"name" binding:"required,max=64" example:"terms"`
+	Version string `json:"version" binding:"required,max=64" example:"2024-01-15"`
+	Title   string `json:"title" binding:"max=256" example:"Terms of Service"`
+	URL     string `json:"url" binding:"omitempty,url,max=2048" example:"https://example.com/terms"`
+	Body    string `json:"body" binding:"max=262144"`
+}
+
+// AcceptPolicyRequest carries the accepted version, which must be the
+// latest.
+type AcceptPolicyRequest struct {
+	Version string `json:"version" binding:"required,max=64" example:"2024-01-15"`
+}
+
+// AcceptanceResponse represents a recorded acceptance.
+type AcceptanceResponse struct {
+	Principal  string `json:"principal" example:"jane"`
+	Policy     string `json:"policy" example:"terms"`
+	Version    string `json:"version" example:"2024-01-15"`
+	AcceptedAt string `json:"accepted_at" example:"2024-01-15T10:30:00Z"`
+	ClientIP   string `json:"client_ip,omitempty" example:"192.0.2.1" pii:"ip"`
+	UserAgent  string `json:"user_agent,omitempty" example:"Mozilla/5.0"`
+}
+
+// ConsentStatusResponse represents the consent of the caller to the
+// latest version of a policy.
+type ConsentStatusResponse struct {
+	Policy          PolicyResponse `json:"policy"`
+	AcceptedVersion string         `json:"accepted_version,omitempty" example:"2023-06-01"`
+	AcceptedAt      string         `json:"accepted_at,omitempty" example:"2023-06-01T10:30:00Z"`
+	Current         bool           `json:"current" example:"false"`
+}
+
+// Register mounts the consent routes on the API group.
+func (h *ConsentHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/consent", h.Status)
+	rg.GET("/consent/policies", h.ListPolicies)
+	rg.GET("/consent/policies/:name", h.GetPolicy)
+	rg.POST("/consent/policies/:name/acceptances", h.Accept)
+}
+
+// RegisterAdmin mounts the policy publishing and audit routes on the admin
+// group.
+func (h *ConsentHandler) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.POST("/consent/policies", h.Publish)
+	rg.GET("/consent/acceptances", h.Acceptances)
+}
+
+// Status handles GET /api/v1/consent.
+//
+// @Summary Get consent status
+// @Description Lists the latest version of every policy with the version the caller accepted
+// @Tags Consent
+// @Produce json
+// @Success 200 {array} ConsentStatusResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/consent [get]
+func (h *ConsentHandler) Status(c *gin.Context) {
+	principal := reqctx.Principal(c.Request.Context())
+	if principal == "" {
+		respondError(c, http.StatusUnauthorized, "unauthorized", "authenticated principal required")
+		return
+	}
+
+	status, err := h.service.Status(c.Request.Context(), principal)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	out := make([]ConsentStatusResponse, len(status))
+	for i, s := range status {
+		out[i] = ConsentStatusResponse{
+			Policy:          toPolicyResponse(s.Policy, false),
+			AcceptedVersion: s.AcceptedVersion,
+			Current:         s.Current(),
+		}
+		if !s.AcceptedAt.IsZero() {
+			out[i].AcceptedAt = s.AcceptedAt.UTC().Format(time.RFC3339)
+		}
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// ListPolicies handles GET /api/v1/consent/policies.
+//
+// @Summary List policies
+// @Description Lists the latest version of every policy, without bodies
+// @Tags Consent
+// @Produce json
+// @Success 200 {array} PolicyResponse
+// @Router /api/v1/consent/policies [get]
+func (h *ConsentHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.service.Policies(c.Request.Context())
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	out := make([]PolicyResponse, len(policies))
+	for i, p := range policies {
+		out[i] = toPolicyResponse(p, false)
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GetPolicy handles GET /api/v1/consent/policies/:name.
+//
+// @Summary Get policy
+// @Tags Consent
+// @Produce json
+// @Param name path string true "Policy name"
+// @Param version query string false "Version (default: latest)"
+// @Success 200 {object} PolicyResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/consent/policies/{name} [get]
+func (h *ConsentHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.service.Policy(c.Request.Context(), c.Param("name"), c.Query("version"))
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPolicyResponse(policy, true))
+}
+
+// Accept handles POST /api/v1/consent/policies/:name/acceptances.
+//
+// @Summary Accept policy
+// @Description Records the caller accepting the latest version of the policy with the client IP and user agent
+// @Tags Consent
+// @Accept json
+// @Produce json
+// @Param name path string true "Policy name"
+// @Param request body AcceptPolicyRequest true "Accepted version"
+// @Success 201 {object} AcceptanceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/consent/policies/{name}/acceptances [post]
+func (h *ConsentHandler) Accept(c *gin.Context) {
+	principal := reqctx.Principal(c.Request.Context())
+	if principal == "" {
+		respondError(c, http.StatusUnauthorized, "unauthorized", "authenticated principal required")
+		return
+	}
+	var req AcceptPolicyRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	acceptance, err := h.service.Accept(c.Request.Context(), principal, c.Param("name"), req.Version, consent.Evidence{
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	h.log.Infow("consent_accepted", "principal", principal, "policy", acceptance.Policy, "version", acceptance.Version)
+	c.JSON(http.StatusCreated, toAcceptanceResponse(acceptance))
+}
+
+// Publish handles POST /admin/consent/policies.
+//
+// @Summary Publish policy version
+// @Description Publishes a new version of a policy; routes requiring the policy reject callers until they accept it
+// @Tags Consent
+// @Accept json
+// @Produce json
+// @Param request body PublishPolicyRequest true "Policy version"
+// @Success 201 {object} PolicyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/consent/policies [post]
+func (h *ConsentHandler) Publish(c *gin.Context) {
+	var req PublishPolicyRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	policy, err := h.service.Publish(c.Request.Context(), consent.Policy{
+		Name:    req.Name,
+		Version: req.Version,
+		Title:   req.Title,
+		URL:     req.URL,
+		Body:    req.Body,
+	})
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	h.log.Infow("consent_policy_published", "policy", policy.Name, "version", policy.Version)
+	c.JSON(http.StatusCreated, toPolicyResponse(policy, true))
+}
+
+// Acceptances handles GET /admin/consent/acceptances.
+//
+// @Summary List acceptances
+// @Description Lists the acceptances of a principal, oldest first
+// @Tags Consent
+// @Produce json
+// @Param principal query string true "Principal"
+// @Success 200 {array} AcceptanceResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/consent/acceptances [get]
+func (h *ConsentHandler) Acceptances(c *gin.Context) {
+	principal := c.Query("principal")
+	if principal == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request", "principal is required")
+		return
+	}
+
+	history, err := h.service.History(c.Request.Context(), principal)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	out := make([]AcceptanceResponse, len(history))
+	for i, a := range history {
+		out[i] = toAcceptanceResponse(a)
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// respondServiceError maps consent domain errors to HTTP responses.
+func (h *ConsentHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("consent_request_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
+
+// toPolicyResponse maps a policy to its response schema, with its body
+// when withBody is set.
+func toPolicyResponse(p consent.Policy, withBody bool) PolicyResponse {
+	out := PolicyResponse{
+		Name:        p.Name,
+		Version:     p.Version,
+		Title:       p.Title,
+		URL:         p.URL,
+		PublishedAt: p.PublishedAt.UTC().Format(time.RFC3339),
+	}
+	if withBody {
+		out.Body = p.Body
+	}
+	return out
+}
+
+// toAcceptanceResponse maps an acceptance to its response schema.
+func toAcceptanceResponse(a consent.Acceptance) AcceptanceResponse {
+	return AcceptanceResponse{
+		Principal:  a.Principal,
+		Policy:     a.Policy,
+		Version:    a.Version,
+		AcceptedAt: a.AcceptedAt.UTC().Format(time.RFC3339),
+		ClientIP:   a.ClientIP,
+		UserAgent:  a.UserAgent,
+	}
+}
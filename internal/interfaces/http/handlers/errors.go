@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/strictjson"
+)
+
+// ErrorResponse represents error response schema.
+// Error is the error category and Code the catalog code clients match on.
+// Violations lists the offending fields of bodies rejected by strict JSON
+// decoding.
+type ErrorResponse struct {
+	Error      string                 `json:"error" example:"not_found"`
+	Code       string                 `json:"code" example:"user_not_found"`
+	Message    string                 `json:"message" example:"user not found"`
+	Violations []strictjson.Violation `json:"violations,omitempty"`
+}
+
+// ErrorCatalogResponse lists the error codes clients may receive.
+type ErrorCatalogResponse struct {
+	Items []apperrors.Entry `json:"items"`
+}
+
+// respondError aborts the request with a JSON error body for a catalog
+// code that is also its category.
+func respondError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Error:   code,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// ErrorCatalogHandler serves the error code catalog.
+type ErrorCatalogHandler struct{}
+
+// NewErrorCatalogHandler creates a new error catalog handler.
+func NewErrorCatalogHandler() *ErrorCatalogHandler {
+	return &ErrorCatalogHandler{}
+}
+
+// Register mounts the catalog route on the API group.
+func (h *ErrorCatalogHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/errors", h.List)
+}
+
+// List handles GET /api/v1/errors.
+//
+// @Summary List error codes
+// @Description Returns every error code the API may respond with, its category and HTTP status
+// @Tags Errors
+// @Produce json
+// @Success 200 {object} ErrorCatalogResponse
+// @Router /api/v1/errors [get]
+func (h *ErrorCatalogHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, ErrorCatalogResponse{Items: apperrors.Catalog()})
+}
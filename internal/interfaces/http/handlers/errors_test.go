@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCatalog_ListsDomainAndTransportCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewErrorCatalogHandler().Register(router.Group("/api/v1"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/errors", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body ErrorCatalogResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	statuses := make(map[string]int, len(body.Items))
+	for _, e := range body.Items {
+		statuses[e.Code] = e.Status
+	}
+	assert.Equal(t, http.StatusNotFound, statuses["user_not_found"])
+	assert.Equal(t, http.StatusConflict, statuses["user_email_taken"])
+	assert.Equal(t, http.StatusRequestEntityTooLarge, statuses["too_many_items"])
+	assert.Equal(t, http.StatusBadRequest, statuses["invalid_request"])
+	assert.Equal(t, http.StatusServiceUnavailable, statuses["search_index_not_found"], "as the search handlers respond")
+}
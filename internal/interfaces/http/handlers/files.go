@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/objectstore"
+	"github.com/luminosita/change-me/internal/infrastructure/objectstore/local"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// FileHandler serves the signed download links of the local object store.
+type FileHandler struct {
+	store *local.Store
+	log   *logger.Logger
+}
+
+// NewFileHandler creates a new file download handler.
+func NewFileHandler(store *local.Store, log *logger.Logger) *FileHandler {
+	return &FileHandler{
+		store: store,
+		log:   log,
+	}
+}
+
+// Register mounts the file routes on the API group.
+func (h *FileHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/files/*key", h.Download)
+}
+
+// Download handles GET /api/v1/files/{key}.
+//
+// @Summary Download a file
+// @Description Serves a generated file through a link handed out by the API, e.g. the
+// @Description download link of a report. Links expire and are bound to their file.
+// @Tags Files
+// @Produce application/octet-stream
+// @Param key path string true "Object key"
+// @Param expires query int true "Link expiry (unix seconds)"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/files/{key} [get]
+func (h *FileHandler) Download(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	f, err := h.store.Open(key, c.Query("expires"), c.Query("signature"))
+	switch {
+	case errors.Is(err, local.ErrLinkInvalid):
+		respondError(c, http.StatusForbidden, "forbidden", "download link invalid or expired")
+		return
+	case errors.Is(err, objectstore.ErrNotFound), errors.Is(err, objectstore.ErrInvalidKey):
+		respondError(c, http.StatusNotFound, "not_found", "file not found")
+		return
+	case err != nil:
+		h.log.Errorw("file_download_failed", "key", key, "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		h.log.Errorw("file_download_failed", "key", key, "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+path.Base(key)+`"`)
+	c.Header("Cache-Control", "private, no-store")
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), f)
+}
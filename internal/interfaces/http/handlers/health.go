@@ -1,27 +1,60 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"runtime"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/pkg/clock"
 )
 
+// healthCheckTimeout bounds each dependency check of the details endpoint.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck probes a dependency for the health details endpoint.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
 // HealthHandler handles health check requests.
 type HealthHandler struct {
+	clock       clock.Clock
 	startupTime time.Time
 	version     string
+	checks      []HealthCheck
+	ready       func() bool
 }
 
 // NewHealthHandler creates a new health check handler.
-func NewHealthHandler(version string) *HealthHandler {
+// Checks are only run by the details endpoint.
+func NewHealthHandler(version string, checks ...HealthCheck) *HealthHandler {
 	return &HealthHandler{
+		clock:       clock.Real(),
 		startupTime: time.Now(),
 		version:     version,
+		checks:      checks,
 	}
 }
 
+// WithReadiness makes the readiness endpoint answer 503 until ready
+// reports true. Without it the instance is ready once it serves requests.
+func (h *HealthHandler) WithReadiness(ready func() bool) *HealthHandler {
+	h.ready = ready
+	return h
+}
+
+// WithClock tells uptime and timestamps with clk, restarting the uptime
+// at its current time.
+func (h *HealthHandler) WithClock(clk clock.Clock) *HealthHandler {
+	h.clock = clk
+	h.startupTime = clk.Now()
+	return h
+}
+
 // HealthCheckResponse represents health check response schema.
 type HealthCheckResponse struct {
 	Status        string  `json:"status" example:"healthy"`
@@ -40,7 +73,7 @@ type HealthCheckResponse struct {
 // @Success 200 {object} HealthCheckResponse
 // @Router /health [get]
 func (h *HealthHandler) Check(c *gin.Context) {
-	currentTime := time.Now()
+	currentTime := h.clock.Now()
 	uptime := currentTime.Sub(h.startupTime).Seconds()
 
 	response := HealthCheckResponse{
@@ -52,3 +85,112 @@ func (h *HealthHandler) Check(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// Ready handles GET /health/ready endpoint.
+//
+// @Summary Readiness probe
+// @Description Responds 503 while the instance is warming up and 200 once it accepts traffic
+// @Tags Health
+// @Produce json
+// @Success 200 {object} HealthCheckResponse
+// @Failure 503 {object} HealthCheckResponse
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	currentTime := h.clock.Now()
+	response := HealthCheckResponse{
+		Status:        constants.HealthStatusHealthy,
+		Version:       h.version,
+		UptimeSeconds: currentTime.Sub(h.startupTime).Seconds(),
+		Timestamp:     currentTime.UTC().Format(time.RFC3339),
+	}
+
+	code := http.StatusOK
+	if h.ready != nil && !h.ready() {
+		response.Status = constants.HealthStatusStarting
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, response)
+}
+
+// HealthDetailsResponse represents the verbose health schema.
+type HealthDetailsResponse struct {
+	HealthCheckResponse
+	Checks  map[string]DependencyStatus `json:"checks"`
+	Runtime RuntimeStatus               `json:"runtime"`
+}
+
+// DependencyStatus reports the outcome of a dependency check.
+type DependencyStatus struct {
+	Status     string `json:"status" example:"healthy"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms" example:"2"`
+}
+
+// RuntimeStatus reports Go runtime statistics.
+type RuntimeStatus struct {
+	GoVersion      string `json:"go_version" example:"go1.24.0"`
+	Goroutines     int    `json:"goroutines" example:"12"`
+	GOMAXPROCS     int    `json:"gomaxprocs" example:"4"`
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes" example:"4194304"`
+	SysBytes       uint64 `json:"sys_bytes" example:"16777216"`
+	NumGC          uint32 `json:"num_gc" example:"7"`
+}
+
+// Details handles GET /health/details.
+//
+// @Summary Detailed health
+// @Description Runs dependency checks and reports runtime statistics. Responds 503 when a check fails.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} HealthDetailsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 503 {object} HealthDetailsResponse
+// @Router /health/details [get]
+func (h *HealthHandler) Details(c *gin.Context) {
+	currentTime := h.clock.Now()
+	response := HealthDetailsResponse{
+		HealthCheckResponse: HealthCheckResponse{
+			Status:        constants.HealthStatusHealthy,
+			Version:       h.version,
+			UptimeSeconds: currentTime.Sub(h.startupTime).Seconds(),
+			Timestamp:     currentTime.UTC().Format(time.RFC3339),
+		},
+		Checks:  make(map[string]DependencyStatus, len(h.checks)),
+		Runtime: runtimeStatus(),
+	}
+
+	for _, check := range h.checks {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+		start := time.Now()
+		err := check.Check(ctx)
+		cancel()
+
+		status := DependencyStatus{Status: constants.HealthStatusHealthy, DurationMS: time.Since(start).Milliseconds()}
+		if err != nil {
+			status.Status = constants.HealthStatusUnhealthy
+			status.Error = err.Error()
+			response.Status = constants.HealthStatusUnhealthy
+		}
+		response.Checks[check.Name] = status
+	}
+
+	code := http.StatusOK
+	if response.Status != constants.HealthStatusHealthy {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, response)
+}
+
+// runtimeStatus samples Go runtime statistics.
+func runtimeStatus() RuntimeStatus {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return RuntimeStatus{
+		GoVersion:      runtime.Version(),
+		Goroutines:     runtime.NumGoroutine(),
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+		HeapAllocBytes: mem.HeapAlloc,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+	}
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/tests/testkit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -168,6 +171,82 @@ func TestHealthCheck_MultipleCallsIdempotent(t *testing.T) {
 	}
 }
 
+func TestHealthCheck_GoldenResponse(t *testing.T) {
+	router, _ := setupHealthTest()
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	testkit.AssertGolden(t, "health", w.Result(),
+		testkit.WithHeaders("Content-Type"),
+		testkit.IgnoreFields("uptime_seconds"),
+	)
+}
+
+func TestHealthDetails_ReportsChecksAndRuntime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewHealthHandler("0.1.0", HealthCheck{
+		Name:  "redis",
+		Check: func(ctx context.Context) error { return nil },
+	})
+	router.GET("/health/details", handler.Details)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/health/details", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var data HealthDetailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, constants.HealthStatusHealthy, data.Status)
+	assert.Equal(t, "0.1.0", data.Version)
+	assert.Equal(t, constants.HealthStatusHealthy, data.Checks["redis"].Status)
+	assert.NotEmpty(t, data.Runtime.GoVersion)
+	assert.Positive(t, data.Runtime.Goroutines)
+}
+
+func TestHealthDetails_FailingCheckReturns503(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewHealthHandler("0.1.0", HealthCheck{
+		Name:  "redis",
+		Check: func(ctx context.Context) error { return errors.New("connection refused") },
+	})
+	router.GET("/health/details", handler.Details)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/health/details", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var data HealthDetailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, constants.HealthStatusUnhealthy, data.Status)
+	assert.Equal(t, "connection refused", data.Checks["redis"].Error)
+}
+
+func TestHealthReady_WaitsForReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ready := false
+	handler := NewHealthHandler("0.1.0").WithReadiness(func() bool { return ready })
+	router.GET("/health/ready", handler.Ready)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/health/ready", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var data HealthCheckResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, constants.HealthStatusStarting, data.Status)
+
+	ready = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/health/ready", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, constants.HealthStatusHealthy, data.Status)
+}
+
 // setupHealthTest creates a test Gin router with health handler
 func setupHealthTest() (*gin.Engine, *HealthHandler) {
 	gin.SetMode(gin.TestMode)
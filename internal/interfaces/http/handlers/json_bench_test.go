@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luminosita/change-me/pkg/jsoncodec"
+	"github.com/stretchr/testify/require"
+)
+
+// BenchmarkJSONEncoders compares the JSON_ENCODER codecs on the health
+// and users list endpoints; bytes/s is the response throughput. Builds
+// with another Gin codec tag (e.g. -tags sonic) also measure it as default:
+//
+//	go test -run '^$' -bench JSONEncoders -benchmem ./internal/interfaces/http/handlers/
+func BenchmarkJSONEncoders(b *testing.B) {
+	health, _ := setupHealthTest()
+	list := setupUsersTest(b)
+	createUsers(b, list, 100)
+
+	endpoints := []struct {
+		name   string
+		router http.Handler
+		path   string
+	}{
+		{"health", health, "/health"},
+		{"users_list", list, "/api/v1/users?limit=100"},
+	}
+
+	codecs := []string{jsoncodec.Std, jsoncodec.GoJSON}
+	if builtin := jsoncodec.Package(); builtin != "encoding/json" && builtin != "github.com/goccy/go-json" {
+		codecs = append(codecs, jsoncodec.Default)
+	}
+
+	b.Cleanup(func() { _ = jsoncodec.Use(jsoncodec.Default) })
+	for _, ep := range endpoints {
+		for _, codec := range codecs {
+			b.Run(ep.name+"/"+codec, func(b *testing.B) {
+				require.NoError(b, jsoncodec.Use(codec))
+				w := perform(ep.router, http.MethodGet, ep.path, "")
+				require.Equal(b, http.StatusOK, w.Code)
+				b.SetBytes(int64(w.Body.Len()))
+				b.ReportAllocs()
+
+				for b.Loop() {
+					w := httptest.NewRecorder()
+					ep.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ep.path, nil))
+				}
+			})
+		}
+	}
+}
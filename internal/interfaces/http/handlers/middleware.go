@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/interfaces/http/routing"
+)
+
+// MiddlewareChainResponse describes the effective middleware chain: the
+// router-wide middleware in order, then the middleware of each route group.
+type MiddlewareChainResponse struct {
+	Global []routing.Entry      `json:"global"`
+	Groups []routing.GroupChain `json:"groups"`
+}
+
+// MiddlewareHandler serves the middleware chain for debugging.
+type MiddlewareHandler struct {
+	chain *routing.Chain
+	table *routing.Table
+}
+
+// NewMiddlewareHandler creates a new middleware chain handler.
+func NewMiddlewareHandler(chain *routing.Chain, table *routing.Table) *MiddlewareHandler {
+	return &MiddlewareHandler{
+		chain: chain,
+		table: table,
+	}
+}
+
+// Register mounts the middleware chain route on the admin group.
+func (h *MiddlewareHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/middleware", h.List)
+}
+
+// List handles GET /admin/middleware.
+//
+// @Summary Describe the middleware chain
+// @Description Returns the router-wide middleware in execution order with priorities, ordering constraints and availability, and the middleware of each mounted route group
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} MiddlewareChainResponse
+// @Router /admin/middleware [get]
+func (h *MiddlewareHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, MiddlewareChainResponse{
+		Global: h.chain.Describe(),
+		Groups: h.table.Describe(),
+	})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/notifications"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// NotificationHandler serves the notifications admin API.
+type NotificationHandler struct {
+	service *notifications.Service
+	log     *logger.Logger
+}
+
+// NewNotificationHandler creates a new notifications admin handler.
+func NewNotificationHandler(service *notifications.Service, log *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// SendNotificationRequest represents a notification to enqueue.
+type SendNotificationRequest struct {
+	Channel  string         `json:"channel" binding:"required,oneof=email sms push slack" example:"email"`
+	To       []string       `json:"to" binding:"required,min=1,max=100,dive,required" example:"jane@example.com"`
+	Template string         `json:"template" binding:"required,max=64" example:"message"`
+	Data     map[string]any `json:"data"`
+}
+
+// SendNotificationResponse identifies an enqueued notification.
+type SendNotificationResponse struct {
+	ID string `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+}
+
+// Register mounts the notification routes on the admin group.
+func (h *NotificationHandler) Register(rg *gin.RouterGroup) {
+	rg.POST("/notifications", h.Send)
+}
+
+// Send handles POST /admin/notifications.
+//
+// @Summary Send a notification
+// @Description Renders a template for the channel and enqueues it for the workers;
+// @Description delivery failures are retried per the channel's retry policy and logged.
+// @Description The built-in "message" template takes Subject and Text data.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body SendNotificationRequest true "Notification"
+// @Success 202 {object} SendNotificationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /admin/notifications [post]
+func (h *NotificationHandler) Send(c *gin.Context) {
+	var req SendNotificationRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	id, err := h.service.Notify(c.Request.Context(), notifications.Message{
+		Channel:  req.Channel,
+		To:       req.To,
+		Template: req.Template,
+		Data:     req.Data,
+	})
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	h.log.Infow("notification_enqueued", "id", id, "channel", req.Channel, "template", req.Template)
+	c.JSON(http.StatusAccepted, SendNotificationResponse{ID: id})
+}
+
+// respondServiceError writes the response for a notifications domain
+// error. Unclassified errors are logged and reported as internal errors.
+func (h *NotificationHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("notification_enqueue_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
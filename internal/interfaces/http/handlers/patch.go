@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/jsonpatch"
+	"github.com/luminosita/change-me/pkg/strictjson"
+)
+
+// Codes of PATCH bodies that cannot be applied.
+var (
+	codePatchMediaType = apperrors.Register(apperrors.Entry{
+		Code:        "patch_media_type_unsupported",
+		Kind:        apperrors.KindInvalid,
+		Status:      http.StatusUnsupportedMediaType,
+		Description: "A PATCH body was neither application/json-patch+json nor application/merge-patch+json.",
+	})
+	codePatchInvalid = apperrors.Register(apperrors.Entry{
+		Code:        "patch_invalid",
+		Kind:        apperrors.KindInvalid,
+		Status:      http.StatusBadRequest,
+		Description: "A PATCH body was not a valid JSON Patch or JSON Merge Patch document.",
+	})
+	codePatchPathNotAllowed = apperrors.Register(apperrors.Entry{
+		Code:        "patch_path_not_allowed",
+		Kind:        apperrors.KindInvalid,
+		Status:      http.StatusBadRequest,
+		Description: "A PATCH body changed a field the resource does not allow to update.",
+	})
+	codePatchConflict = apperrors.Register(apperrors.Entry{
+		Code:        "patch_conflict",
+		Kind:        apperrors.KindConflict,
+		Status:      http.StatusConflict,
+		Description: "A JSON Patch operation referenced a missing location or its test operation failed.",
+	})
+)
+
+// bindPatch applies the JSON Patch or JSON Merge Patch body of the request,
+// chosen by its Content-Type, to the JSON representation of current and
+// binds the result to out, which is validated like a bindJSON body. Only
+// the JSON Pointers of allowed and below may change; the other fields of
+// current can still be tested. Callers store the result conditionally on
+// current being unchanged, or a concurrent update is lost.
+func bindPatch(c *gin.Context, current any, allowed []string, out any) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	var patch []byte
+	if c.Request.Body != nil {
+		if patch, err = io.ReadAll(c.Request.Body); err != nil {
+			return err
+		}
+	}
+
+	patched, err := jsonpatch.Apply(c.ContentType(), doc, patch, allowed)
+	if err != nil {
+		return err
+	}
+	// Fields outside out, such as read-only ones of current, are dropped
+	if err := strictjson.Decode(patched, out, strictjson.Options{}); err != nil {
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(out); err != nil {
+		if violations := strictjson.ValidationViolations(err, out); violations != nil {
+			return &strictjson.Error{Violations: violations}
+		}
+		return err
+	}
+	return nil
+}
+
+// respondPatchError aborts the request for a bindPatch error: 415 for
+// unsupported media types, 409 for patches not applying to the current
+// document and 400 otherwise.
+func respondPatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jsonpatch.ErrUnsupportedMediaType):
+		respondError(c, http.StatusUnsupportedMediaType, codePatchMediaType,
+			"content type must be "+jsonpatch.MediaTypeJSONPatch+" or "+jsonpatch.MediaTypeMergePatch)
+	case errors.Is(err, jsonpatch.ErrPathNotAllowed):
+		respondError(c, http.StatusBadRequest, codePatchPathNotAllowed, err.Error())
+	case errors.Is(err, jsonpatch.ErrPathNotFound), errors.Is(err, jsonpatch.ErrTestFailed):
+		respondError(c, http.StatusConflict, codePatchConflict, err.Error())
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		respondError(c, http.StatusBadRequest, codePatchInvalid, err.Error())
+	default:
+		respondBindError(c, err)
+	}
+}
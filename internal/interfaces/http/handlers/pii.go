@@ -0,0 +1,38 @@
+package handlers
+
+import "github.com/luminosita/change-me/pkg/pii"
+
+// piiSchemas are the request and response schemas carrying personal data
+// or credentials.
+var piiSchemas = []any{
+	UserResponse{},
+	CreateUserRequest{},
+	UpdateUserRequest{},
+	UserSearchHit{},
+	AcceptanceResponse{},
+	TokenResponse{},
+	RefreshTokenRequest{},
+	TwoFactorEnrollmentRequest{},
+	TwoFactorEnrollmentResponse{},
+	TwoFactorBackupCodesResponse{},
+}
+
+// PIIFields returns the JSON fields the pii tags of the API schemas
+// classify as personal data (see pkg/pii), for masking responses and
+// recordings.
+func PIIFields() (map[string]pii.Kind, error) {
+	return pii.JSONFields(piiSchemas...)
+}
+
+// ResponsePIIFields returns the fields of fields masked in responses.
+// Secrets are credentials issued to the caller, such as refresh tokens,
+// so they are kept out of recordings but served unmasked.
+func ResponsePIIFields(fields map[string]pii.Kind) map[string]pii.Kind {
+	out := make(map[string]pii.Kind, len(fields))
+	for name, kind := range fields {
+		if kind != pii.Secret {
+			out[name] = kind
+		}
+	}
+	return out
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/luminosita/change-me/pkg/pii"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPIIFields(t *testing.T) {
+	fields, err := PIIFields()
+	require.NoError(t, err)
+	assert.Equal(t, pii.Email, fields["email"])
+	assert.Equal(t, pii.Name, fields["full_name"])
+	assert.NotContains(t, fields, "username")
+	assert.Equal(t, pii.Secret, fields["refresh_token"])
+	assert.Equal(t, pii.Secret, fields["access_token"])
+	assert.Equal(t, pii.Secret, fields["secret"])
+	assert.Equal(t, pii.Secret, fields["backup_codes"])
+
+	response := ResponsePIIFields(fields)
+	assert.Equal(t, pii.Email, response["email"])
+	assert.NotContains(t, response, "refresh_token", "credentials are served to their owner")
+	assert.NotContains(t, response, "backup_codes")
+}
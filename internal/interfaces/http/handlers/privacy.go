@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/identity"
+	"github.com/luminosita/change-me/internal/core/privacy"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// PrivacyHandler serves the privacy admin API: data exports and account
+// deletion requests.
+type PrivacyHandler struct {
+	privacy *privacy.Service
+	log     *logger.Logger
+}
+
+// NewPrivacyHandler creates a new privacy admin handler.
+func NewPrivacyHandler(service *privacy.Service, log *logger.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacy: service,
+		log:     log,
+	}
+}
+
+// ExportResponse represents a data export.
+type ExportResponse struct {
+	ID          string `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	UserID      string `json:"user_id" example:"01890a5d-ac96-774b-bcce-b302099a8057"`
+	Status      string `json:"status" example:"running" enums:"pending,running,succeeded,failed"`
+	RequestedBy string `json:"requested_by,omitempty" example:"admin"`
+	Error       string `json:"error,omitempty"`
+
+	// DownloadURL links the zip archive of succeeded exports until
+	// DownloadExpiresAt; poll again for a fresh link
+	DownloadURL       string `json:"download_url,omitempty"`
+	DownloadExpiresAt string `json:"download_expires_at,omitempty" example:"2024-01-15T10:45:00Z"`
+
+	CreatedAt   string `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	CompletedAt string `json:"completed_at,omitempty" example:"2024-01-15T10:30:02Z"`
+	ExpiresAt   string `json:"expires_at,omitempty" example:"2024-01-16T10:30:02Z"`
+}
+
+// DeletionResponse represents an account deletion request.
+type DeletionResponse struct {
+	ID          string `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	UserID      string `json:"user_id" example:"01890a5d-ac96-774b-bcce-b302099a8057"`
+	Status      string `json:"status" example:"scheduled" enums:"scheduled,cancelled,completed"`
+	RequestedBy string `json:"requested_by,omitempty" example:"admin"`
+	RequestedAt string `json:"requested_at" example:"2024-01-15T10:30:00Z"`
+	PurgeAfter  string `json:"purge_after" example:"2024-02-14T10:30:00Z"`
+	CompletedAt string `json:"completed_at,omitempty" example:"2024-02-14T10:31:00Z"`
+
+	Trail []TrailEntryResponse `json:"trail"`
+}
+
+// TrailEntryResponse represents a step of a deletion request.
+type TrailEntryResponse struct {
+	At     string `json:"at" example:"2024-01-15T10:30:00Z"`
+	Actor  string `json:"actor,omitempty" example:"admin"`
+	Action string `json:"action" example:"requested"`
+	Source string `json:"source,omitempty" example:"users"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Register mounts the privacy routes on the admin group.
+func (h *PrivacyHandler) Register(rg *gin.RouterGroup) {
+	rg.POST("/privacy/users/:id/export", h.StartExport)
+	rg.GET("/privacy/exports/:id", h.GetExport)
+	rg.POST("/privacy/users/:id/deletion", h.RequestDeletion)
+	rg.GET("/privacy/deletions/:id", h.GetDeletion)
+	rg.DELETE("/privacy/deletions/:id", h.CancelDeletion)
+}
+
+// StartExport handles POST /admin/privacy/users/{id}/export.
+//
+// @Summary Export a user's data
+// @Description Starts assembling the data every privacy source holds about the user into a
+// @Description zip archive and returns the export, linked in the Location header. Poll the
+// @Description export until it succeeded to get the download link.
+// @Tags Privacy
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 202 {object} ExportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /admin/privacy/users/{id}/export [post]
+func (h *PrivacyHandler) StartExport(c *gin.Context) {
+	var userID identity.UserID
+	if !bindPathID(c, "id", &userID) {
+		return
+	}
+
+	export, err := h.privacy.StartExport(c.Request.Context(), userID)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	h.log.Infow("privacy_export_started", "id", export.ID, "user_id", userID.String())
+	c.Header("Location", privacyPath(c, "exports", export.ID))
+	c.JSON(http.StatusAccepted, h.toExportResponse(c, export))
+}
+
+// GetExport handles GET /admin/privacy/exports/{id}.
+//
+// @Summary Get a data export
+// @Description Returns the status of a data export, with a download link once it succeeded.
+// @Description Exports are kept by the instance that started them until their archive expires.
+// @Tags Privacy
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} ExportResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/privacy/exports/{id} [get]
+func (h *PrivacyHandler) GetExport(c *gin.Context) {
+	export, err := h.privacy.GetExport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, h.toExportResponse(c, export))
+}
+
+// RequestDeletion handles POST /admin/privacy/users/{id}/deletion.
+//
+// @Summary Request account deletion
+// @Description Hides the user's data immediately and schedules its erasure across every
+// @Description privacy source once the grace period ends. Until then the request can be
+// @Description cancelled, which restores the data.
+// @Tags Privacy
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 202 {object} DeletionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/privacy/users/{id}/deletion [post]
+func (h *PrivacyHandler) RequestDeletion(c *gin.Context) {
+	var userID identity.UserID
+	if !bindPathID(c, "id", &userID) {
+		return
+	}
+
+	deletion, err := h.privacy.RequestDeletion(c.Request.Context(), userID)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	h.log.Infow("privacy_deletion_requested", "id", deletion.ID, "user_id", userID.String(),
+		"purge_after", deletion.PurgeAfter)
+	c.Header("Location", privacyPath(c, "deletions", deletion.ID))
+	c.JSON(http.StatusAccepted, toDeletionResponse(deletion))
+}
+
+// GetDeletion handles GET /admin/privacy/deletions/{id}.
+//
+// @Summary Get an account deletion request
+// @Description Returns the status of a deletion request with its audit trail.
+// @Tags Privacy
+// @Produce json
+// @Param id path string true "Deletion request ID"
+// @Success 200 {object} DeletionResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/privacy/deletions/{id} [get]
+func (h *PrivacyHandler) GetDeletion(c *gin.Context) {
+	deletion, err := h.privacy.GetDeletion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toDeletionResponse(deletion))
+}
+
+// CancelDeletion handles DELETE /admin/privacy/deletions/{id}.
+//
+// @Summary Cancel an account deletion request
+// @Description Cancels a scheduled deletion request during its grace period and restores
+// @Description the user's data.
+// @Tags Privacy
+// @Produce json
+// @Param id path string true "Deletion request ID"
+// @Success 200 {object} DeletionResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/privacy/deletions/{id} [delete]
+func (h *PrivacyHandler) CancelDeletion(c *gin.Context) {
+	deletion, err := h.privacy.CancelDeletion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	h.log.Infow("privacy_deletion_cancelled", "id", deletion.ID, "user_id", deletion.UserID.String())
+	c.JSON(http.StatusOK, toDeletionResponse(deletion))
+}
+
+// privacyPath joins elem to the privacy routes of the group serving a
+// /privacy/users/{id}/... request, whatever its prefix.
+func privacyPath(c *gin.Context, elem ...string) string {
+	base := path.Dir(path.Dir(path.Dir(c.Request.URL.Path)))
+	return path.Join(append([]string{base}, elem...)...)
+}
+
+// toExportResponse maps an export to its response schema, linking the
+// archive of succeeded exports.
+func (h *PrivacyHandler) toExportResponse(c *gin.Context, export privacy.Export) ExportResponse {
+	resp := ExportResponse{
+		ID:          export.ID,
+		UserID:      export.UserID.String(),
+		Status:      string(export.Status),
+		RequestedBy: export.RequestedBy,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if !export.CompletedAt.IsZero() {
+		resp.CompletedAt = export.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	if !export.ExpiresAt.IsZero() {
+		resp.ExpiresAt = export.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if export.Status == privacy.ExportSucceeded {
+		link, err := h.privacy.ExportURL(c.Request.Context(), export)
+		if err != nil {
+			h.log.Errorw("privacy_export_link_failed", "id", export.ID, "error", err)
+			return resp
+		}
+		resp.DownloadURL = link
+		resp.DownloadExpiresAt = time.Now().Add(h.privacy.LinkTTL()).UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// toDeletionResponse maps a deletion request to its response schema.
+func toDeletionResponse(d privacy.Deletion) DeletionResponse {
+	resp := DeletionResponse{
+		ID:          d.ID,
+		UserID:      d.UserID.String(),
+		Status:      string(d.Status),
+		RequestedBy: d.RequestedBy,
+		RequestedAt: d.RequestedAt.UTC().Format(time.RFC3339),
+		PurgeAfter:  d.PurgeAfter.UTC().Format(time.RFC3339),
+		Trail:       make([]TrailEntryResponse, len(d.Trail)),
+	}
+	if !d.CompletedAt.IsZero() {
+		resp.CompletedAt = d.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	for i, entry := range d.Trail {
+		resp.Trail[i] = TrailEntryResponse{
+			At:     entry.At.UTC().Format(time.RFC3339),
+			Actor:  entry.Actor,
+			Action: entry.Action,
+			Source: entry.Source,
+			Error:  entry.Error,
+		}
+	}
+	return resp
+}
+
+// respondServiceError writes the response for a privacy domain error.
+// Unclassified errors are logged and reported as internal errors.
+func (h *PrivacyHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("privacy_request_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
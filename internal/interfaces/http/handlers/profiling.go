@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/profiling"
+)
+
+var codeProfileInProgress = apperrors.Register(apperrors.Entry{
+	Code:        "profile_in_progress",
+	Kind:        apperrors.KindConflict,
+	Description: "Another profile capture is running on this instance.",
+})
+
+// ProfilingHandler serves the on-demand profiling admin API.
+type ProfilingHandler struct {
+	profiler *profiling.Profiler
+	log      *logger.Logger
+}
+
+// NewProfilingHandler creates a new profiling admin handler.
+func NewProfilingHandler(profiler *profiling.Profiler, log *logger.Logger) *ProfilingHandler {
+	return &ProfilingHandler{
+		profiler: profiler,
+		log:      log,
+	}
+}
+
+// StartProfileRequest represents a profile capture request.
+type StartProfileRequest struct {
+	Kind    profiling.Kind `json:"kind" binding:"required,oneof=cpu heap block mutex goroutine" example:"cpu"`
+	Seconds int            `json:"seconds" binding:"min=0" example:"30"`
+}
+
+// ProfileResponse describes a capture and where to download it.
+type ProfileResponse struct {
+	profiling.Capture
+	URL string `json:"url,omitempty" example:"/admin/profiles/cpu-20240115T103000-1a2b3c4d/data"`
+}
+
+// ProfileListResponse lists retained captures, newest first.
+type ProfileListResponse struct {
+	Items []ProfileResponse `json:"items"`
+}
+
+// Register mounts the profiling routes on the admin group.
+func (h *ProfilingHandler) Register(rg *gin.RouterGroup) {
+	rg.POST("/profiles", h.Start)
+	rg.GET("/profiles", h.List)
+	rg.GET("/profiles/:id", h.Get)
+	rg.GET("/profiles/:id/data", h.Download)
+}
+
+// Start handles POST /admin/profiles.
+//
+// @Summary Capture a runtime profile
+// @Description Starts a capture in the background. CPU, block and mutex profiles sample
+// @Description for the requested seconds (at least 1, clamped to PROFILING_MAX_DURATION);
+// @Description heap and goroutine profiles are snapshots. Poll the returned capture until completed.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body StartProfileRequest true "Profile to capture"
+// @Success 202 {object} ProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/profiles [post]
+func (h *ProfilingHandler) Start(c *gin.Context) {
+	var req StartProfileRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	// The capture outlives this request
+	ctx := context.WithoutCancel(c.Request.Context())
+	capture, err := h.profiler.Start(ctx, req.Kind, time.Duration(req.Seconds)*time.Second)
+	switch {
+	case errors.Is(err, profiling.ErrBusy):
+		respondError(c, http.StatusConflict, codeProfileInProgress, err.Error())
+		return
+	case err != nil:
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	h.log.Infow("profile_capture_started", "id", capture.ID, "kind", capture.Kind, "seconds", capture.Seconds)
+	c.JSON(http.StatusAccepted, toProfileResponse(capture))
+}
+
+// List handles GET /admin/profiles.
+//
+// @Summary List profile captures
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} ProfileListResponse
+// @Router /admin/profiles [get]
+func (h *ProfilingHandler) List(c *gin.Context) {
+	captures := h.profiler.List()
+	items := make([]ProfileResponse, len(captures))
+	for i, capture := range captures {
+		items[i] = toProfileResponse(capture)
+	}
+	c.JSON(http.StatusOK, ProfileListResponse{Items: items})
+}
+
+// Get handles GET /admin/profiles/:id.
+//
+// @Summary Get profile capture status
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Capture ID"
+// @Success 200 {object} ProfileResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/profiles/{id} [get]
+func (h *ProfilingHandler) Get(c *gin.Context) {
+	capture, ok := h.profiler.Get(c.Param("id"))
+	if !ok {
+		respondError(c, http.StatusNotFound, "not_found", profiling.ErrNotFound.Error())
+		return
+	}
+	c.JSON(http.StatusOK, toProfileResponse(capture))
+}
+
+// Download handles GET /admin/profiles/:id/data.
+//
+// @Summary Download a profile
+// @Description Returns the pprof data of a completed capture, for `go tool pprof`.
+// @Description Profiles kept in object storage redirect to a short-lived link.
+// @Tags Admin
+// @Produce application/octet-stream
+// @Param id path string true "Capture ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to the object storage link"
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/profiles/{id}/data [get]
+func (h *ProfilingHandler) Download(c *gin.Context) {
+	id := c.Param("id")
+	link, err := h.profiler.Link(c.Request.Context(), id)
+	if err == nil && link != "" {
+		c.Redirect(http.StatusFound, link)
+		return
+	}
+	var data io.ReadCloser
+	if err == nil {
+		data, err = h.profiler.Open(c.Request.Context(), id)
+	}
+	if errors.Is(err, profiling.ErrNotFound) {
+		respondError(c, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	if err != nil {
+		h.log.Errorw("profile_download_failed", "id", id, "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	defer data.Close()
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", `attachment; filename="`+id+`.pprof"`)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, data); err != nil {
+		h.log.Errorw("profile_download_failed", "id", id, "error", err)
+	}
+}
+
+// toProfileResponse adds the download link to a capture.
+func toProfileResponse(capture profiling.Capture) ProfileResponse {
+	resp := ProfileResponse{Capture: capture}
+	if capture.Status == profiling.StatusCompleted {
+		resp.URL = constants.AdminPrefix + "/profiles/" + capture.ID + "/data"
+	}
+	return resp
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/quota"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// QuotaHandler serves the quota admin API.
+type QuotaHandler struct {
+	service *quota.Service
+	log     *logger.Logger
+}
+
+// NewQuotaHandler creates a new quota admin handler.
+func NewQuotaHandler(service *quota.Service, log *logger.Logger) *QuotaHandler {
+	return &QuotaHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// SetQuotaLimitsRequest represents a per-subject limits override.
+type SetQuotaLimitsRequest struct {
+	Daily   int64 `json:"daily" binding:"min=0" example:"1000"`
+	Monthly int64 `json:"monthly" binding:"min=0" example:"20000"`
+}
+
+// Register mounts the quota routes on the admin group.
+func (h *QuotaHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/quotas/:subject", h.Get)
+	rg.PUT("/quotas/:subject/limits", h.SetLimits)
+	rg.DELETE("/quotas/:subject/usage", h.Reset)
+}
+
+// Get handles GET /admin/quotas/:subject.
+//
+// @Summary Inspect quota usage
+// @Tags Admin
+// @Produce json
+// @Param subject path string true "API key ID or tenant"
+// @Success 200 {object} quota.Usage
+// @Router /admin/quotas/{subject} [get]
+func (h *QuotaHandler) Get(c *gin.Context) {
+	usage, err := h.service.Usage(c.Request.Context(), c.Param("subject"))
+	if err != nil {
+		h.log.Errorw("quota_usage_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// SetLimits handles PUT /admin/quotas/:subject/limits.
+//
+// @Summary Override quota limits
+// @Description Limits of 0 are unlimited. Overrides are held in memory by the receiving instance.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param subject path string true "API key ID or tenant"
+// @Param request body SetQuotaLimitsRequest true "Limits"
+// @Success 200 {object} quota.Usage
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/quotas/{subject}/limits [put]
+func (h *QuotaHandler) SetLimits(c *gin.Context) {
+	var req SetQuotaLimitsRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	subject := c.Param("subject")
+	h.service.SetLimits(subject, quota.Limits{Daily: req.Daily, Monthly: req.Monthly})
+	h.log.Infow("quota_limits_updated", "subject", subject, "daily", req.Daily, "monthly", req.Monthly)
+	h.Get(c)
+}
+
+// Reset handles DELETE /admin/quotas/:subject/usage.
+//
+// @Summary Reset quota usage
+// @Tags Admin
+// @Param subject path string true "API key ID or tenant"
+// @Success 204
+// @Router /admin/quotas/{subject}/usage [delete]
+func (h *QuotaHandler) Reset(c *gin.Context) {
+	subject := c.Param("subject")
+	if err := h.service.Reset(c.Request.Context(), subject); err != nil {
+		h.log.Errorw("quota_reset_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	h.log.Infow("quota_usage_reset", "subject", subject)
+	c.Status(http.StatusNoContent)
+}
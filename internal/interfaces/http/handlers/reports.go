@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/reports"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// ReportHandler serves the reports API.
+type ReportHandler struct {
+	reports *reports.Service
+	log     *logger.Logger
+}
+
+// NewReportHandler creates a new reports handler.
+func NewReportHandler(service *reports.Service, log *logger.Logger) *ReportHandler {
+	return &ReportHandler{
+		reports: service,
+		log:     log,
+	}
+}
+
+// ReportInfo describes an available report.
+type ReportInfo struct {
+	Name        string `json:"name" example:"users"`
+	Description string `json:"description" example:"Users with their status and creation date"`
+}
+
+// ReportListResponse lists the available reports.
+type ReportListResponse struct {
+	Items []ReportInfo `json:"items"`
+}
+
+// StartReportRequest selects the format and parameters of a report.
+type StartReportRequest struct {
+	Format string            `json:"format" binding:"required,oneof=pdf html" example:"pdf"`
+	Params map[string]string `json:"params" binding:"max=20"`
+}
+
+// ReportOperationResponse represents a report operation.
+type ReportOperationResponse struct {
+	ID       string            `json:"id" example:"01890a5d-ac96-774b-bcce-b302099a8057"`
+	Report   string            `json:"report" example:"users"`
+	Format   string            `json:"format" example:"pdf"`
+	Params   map[string]string `json:"params,omitempty"`
+	Status   string            `json:"status" example:"running" enums:"pending,running,succeeded,failed"`
+	Progress float64           `json:"progress" example:"0.45"`
+	Error    string            `json:"error,omitempty"`
+
+	// DownloadURL links the output of succeeded operations until
+	// DownloadExpiresAt; poll again for a fresh link
+	DownloadURL       string `json:"download_url,omitempty"`
+	DownloadExpiresAt string `json:"download_expires_at,omitempty" example:"2024-01-15T10:45:00Z"`
+
+	CreatedAt   string `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	StartedAt   string `json:"started_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	CompletedAt string `json:"completed_at,omitempty" example:"2024-01-15T10:30:02Z"`
+}
+
+// Register mounts the reports routes on the API group.
+func (h *ReportHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/reports", h.List)
+	rg.POST("/reports/:name", h.Start)
+	rg.GET("/reports/operations/:id", h.Get)
+}
+
+// List handles GET /api/v1/reports.
+//
+// @Summary List reports
+// @Description Lists the reports that can be generated.
+// @Tags Reports
+// @Produce json
+// @Success 200 {object} ReportListResponse
+// @Router /api/v1/reports [get]
+func (h *ReportHandler) List(c *gin.Context) {
+	list := h.reports.Reports()
+	items := make([]ReportInfo, len(list))
+	for i, report := range list {
+		items[i] = ReportInfo{Name: report.Name(), Description: report.Description()}
+	}
+	c.JSON(http.StatusOK, ReportListResponse{Items: items})
+}
+
+// Start handles POST /api/v1/reports/{name}.
+//
+// @Summary Generate a report
+// @Description Starts generating the report in the background and returns its operation,
+// @Description linked in the Location header. Poll the operation until it succeeded to
+// @Description get the download link of the PDF or HTML file.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param name path string true "Report name"
+// @Param request body StartReportRequest true "Format and report parameters"
+// @Success 202 {object} ReportOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/v1/reports/{name} [post]
+func (h *ReportHandler) Start(c *gin.Context) {
+	var req StartReportRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	op, err := h.reports.Start(c.Request.Context(), c.Param("name"), req.Format, req.Params)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	h.log.Infow("report_started", "id", op.ID.String(), "report", op.Report, "format", op.Format)
+	c.Header("Location", path.Join(path.Dir(c.Request.URL.Path), "operations", op.ID.String()))
+	c.JSON(http.StatusAccepted, h.toResponse(c, op))
+}
+
+// Get handles GET /api/v1/reports/operations/{id}.
+//
+// @Summary Get a report operation
+// @Description Returns the status and progress of a report operation, with a download
+// @Description link once it succeeded. Operations are kept by the instance that started them
+// @Description and visible only to the caller that started them.
+// @Tags Reports
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} ReportOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/reports/operations/{id} [get]
+func (h *ReportHandler) Get(c *gin.Context) {
+	var id reports.OperationID
+	if !bindPathID(c, "id", &id) {
+		return
+	}
+
+	op, err := h.reports.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, h.toResponse(c, op))
+}
+
+// toResponse maps an operation to its response schema, linking the
+// output of succeeded operations.
+func (h *ReportHandler) toResponse(c *gin.Context, op reports.Operation) ReportOperationResponse {
+	resp := ReportOperationResponse{
+		ID:        op.ID.String(),
+		Report:    op.Report,
+		Format:    op.Format,
+		Params:    op.Params,
+		Status:    string(op.Status),
+		Progress:  op.Progress,
+		Error:     op.Error,
+		CreatedAt: op.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if !op.StartedAt.IsZero() {
+		resp.StartedAt = op.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if !op.CompletedAt.IsZero() {
+		resp.CompletedAt = op.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	if op.Status == reports.StatusSucceeded {
+		link, err := h.reports.URL(c.Request.Context(), op)
+		if err != nil {
+			h.log.Errorw("report_link_failed", "id", op.ID.String(), "error", err)
+			return resp
+		}
+		resp.DownloadURL = link
+		resp.DownloadExpiresAt = time.Now().Add(h.reports.LinkTTL()).UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// respondServiceError writes the response for a reports domain error.
+// Unclassified errors are logged and reported as internal errors.
+func (h *ReportHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("reports_request_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/search"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pagination"
+)
+
+// SearchHandler serves the users search API and its index admin API.
+type SearchHandler struct {
+	search *users.Search
+	log    *logger.Logger
+}
+
+// NewSearchHandler creates a new users search handler.
+func NewSearchHandler(search *users.Search, log *logger.Logger) *SearchHandler {
+	return &SearchHandler{
+		search: search,
+		log:    log,
+	}
+}
+
+// SearchUsersRequest represents a users search query.
+type SearchUsersRequest struct {
+	Q      string `form:"q" binding:"max=256" example:"jane"`
+	Active *bool  `form:"active" example:"true"`
+	pagination.Params
+}
+
+// UserSearchHit represents a matching user.
+type UserSearchHit struct {
+	ID       string  `json:"id" example:"01890a5d-ac96-774b-bcce-b302099a8057"`
+	Email    string  `json:"email" example:"jane@example.com" pii:"email"`
+	Username string  `json:"username" example:"jane"`
+	FullName string  `json:"full_name" example:"Jane Doe" pii:"name"`
+	IsActive bool    `json:"is_active" example:"true"`
+	Score    float64 `json:"score" example:"1.42"`
+
+	// Highlights holds the matched fragments per field, terms in <em>
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// UserSearchResponse represents a page of matching users, best first.
+type UserSearchResponse struct {
+	Items  []UserSearchHit `json:"items"`
+	Limit  int             `json:"limit" example:"20"`
+	Offset int             `json:"offset" example:"0"`
+	Total  int             `json:"total" example:"3"`
+}
+
+// ReindexResponse names the index version a rebuild swapped in.
+type ReindexResponse struct {
+	Index string `json:"index" example:"users-20240115103000000000000"`
+}
+
+// Register mounts the search routes on the API group.
+func (h *SearchHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/users/search", h.SearchUsers)
+}
+
+// RegisterAdmin mounts the index lifecycle routes on the admin group.
+func (h *SearchHandler) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.POST("/search/users/reindex", h.ReindexUsers)
+}
+
+// SearchUsers handles GET /api/v1/users/search.
+//
+// @Summary Search users
+// @Description Full-text search over email, username and full name, best matches
+// @Description first, with the matched terms highlighted. Without q every user matches.
+// @Tags Users
+// @Produce json
+// @Param q query string false "Search terms"
+// @Param active query bool false "Only active or inactive users"
+// @Param limit query int false "Page size (max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} UserSearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/users/search [get]
+func (h *SearchHandler) SearchUsers(c *gin.Context) {
+	var req SearchUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	q := search.Query{Text: req.Q, Highlight: true, Params: req.Params}
+	if req.Active != nil {
+		q.Filters = map[string]string{"is_active": strconv.FormatBool(*req.Active)}
+	}
+	page, err := h.search.Query(c.Request.Context(), q)
+	if err != nil {
+		h.respondSearchError(c, err)
+		return
+	}
+
+	items := make([]UserSearchHit, len(page.Items))
+	for i, hit := range page.Items {
+		items[i] = toUserSearchHit(hit)
+	}
+	c.JSON(http.StatusOK, UserSearchResponse{
+		Items:  items,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+		Total:  page.Total,
+	})
+}
+
+// ReindexUsers handles POST /admin/search/users/reindex.
+//
+// @Summary Rebuild the users search index
+// @Description Builds a new index version from the users repository, moves the alias
+// @Description to it and deletes the versions beyond SEARCH_RETAIN_VERSIONS. Searches
+// @Description use the previous version until the swap.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} ReindexResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/search/users/reindex [post]
+func (h *SearchHandler) ReindexUsers(c *gin.Context) {
+	index, err := h.search.Reindex(c.Request.Context())
+	if err != nil {
+		h.log.Errorw("search_reindex_failed", "index", users.SearchAlias, "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	h.log.Infow("search_reindexed", "index", users.SearchAlias, "version", index)
+	c.JSON(http.StatusOK, ReindexResponse{Index: index})
+}
+
+// respondSearchError reports a missing index, e.g. before the startup
+// rebuild completed, as unavailable and other failures as internal errors.
+func (h *SearchHandler) respondSearchError(c *gin.Context, err error) {
+	if errors.Is(err, search.ErrIndexNotFound) {
+		respondError(c, apperrors.HTTPStatus(err), search.ErrIndexNotFound.Code(), search.ErrIndexNotFound.Message())
+		return
+	}
+	h.log.Errorw("search_failed", "index", users.SearchAlias, "error", err)
+	respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+}
+
+// toUserSearchHit maps a users search hit to its response schema.
+func toUserSearchHit(hit search.Hit) UserSearchHit {
+	active, _ := strconv.ParseBool(hit.Fields["is_active"])
+	return UserSearchHit{
+		ID:         hit.ID,
+		Email:      hit.Fields["email"],
+		Username:   hit.Fields["username"],
+		FullName:   hit.Fields["full_name"],
+		IsActive:   active,
+		Score:      hit.Score,
+		Highlights: hit.Highlights,
+	}
+}
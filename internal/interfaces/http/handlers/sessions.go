@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// SessionHandler issues, rotates and revokes refresh tokens.
+type SessionHandler struct {
+	service      *sessions.Service
+	authenticate func(*gin.Context) string
+	log          *logger.Logger
+}
+
+// NewSessionHandler creates a new session handler. authenticate verifies
+// the credentials of a request starting a session and returns their
+// principal, empty when they are missing or invalid.
+func NewSessionHandler(service *sessions.Service, authenticate func(*gin.Context) string, log *logger.Logger) *SessionHandler {
+	return &SessionHandler{
+		service:      service,
+		authenticate: authenticate,
+		log:          log,
+	}
+}
+
+// TokenResponse carries the credentials of a session.
+type TokenResponse struct {
+	RefreshToken     string `json:"refresh_token" example:"3q2-7wE1Sk6mZ8rQ0a9wAg.Zm9vYmFy" pii:"secret"`
+	RefreshExpiresAt string `json:"refresh_expires_at" example:"2024-01-22T10:30:00Z"`
+	AccessToken      string `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiJ9.e30.sig" pii:"secret"`
+	AccessExpiresAt  string `json:"access_expires_at,omitempty" example:"2024-01-15T10:45:00Z"`
+}
+
+// RefreshTokenRequest carries a refresh token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required,max=256" pii:"secret"`
+}
+
+// LogoutAllResponse reports the sessions revoked by logging out everywhere.
+type LogoutAllResponse struct {
+	Revoked int `json:"revoked" example:"3"`
+}
+
+// Register mounts the session routes on the API group.
+func (h *SessionHandler) Register(rg *gin.RouterGroup) {
+	rg.POST("/auth/sessions", h.Issue)
+	rg.POST("/auth/refresh", h.Refresh)
+	rg.POST("/auth/logout", h.Logout)
+	rg.POST("/auth/logout-all", h.LogoutAll)
+}
+
+// Issue handles POST /api/v1/auth/sessions.
+//
+// @Summary Start a session
+// @Description Issues a refresh token once the caller's credentials are verified
+// @Tags Auth
+// @Produce json
+// @Success 201 {object} TokenResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/sessions [post]
+func (h *SessionHandler) Issue(c *gin.Context) {
+	principal := h.authenticate(c)
+	if principal == "" {
+		respondError(c, http.StatusUnauthorized, "unauthorized", "valid credentials required")
+		return
+	}
+
+	tokens, err := h.service.Issue(c.Request.Context(), principal)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTokenResponse(tokens))
+}
+
+// Refresh handles POST /api/v1/auth/refresh.
+//
+// @Summary Refresh a session
+// @Description Rotates the refresh token; presenting a rotated token again revokes the session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body RefreshTokenRequest true "Current refresh token"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/refresh [post]
+func (h *SessionHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if errors.Is(err, sessions.ErrTokenReused) {
+		h.log.Warnw("refresh_token_reused",
+			"request_id", reqctx.RequestID(c.Request.Context()),
+			"client_ip", c.ClientIP())
+	}
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTokenResponse(tokens))
+}
+
+// Logout handles POST /api/v1/auth/logout.
+//
+// @Summary End a session
+// @Tags Auth
+// @Accept json
+// @Param request body RefreshTokenRequest true "Refresh token of the session"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/logout [post]
+func (h *SessionHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), req.RefreshToken); err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll handles POST /api/v1/auth/logout-all.
+//
+// @Summary End all sessions
+// @Description Revokes every refresh token of the principal of a live session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body RefreshTokenRequest true "Current refresh token of a session"
+// @Success 200 {object} LogoutAllResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/logout-all [post]
+func (h *SessionHandler) LogoutAll(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	revoked, err := h.service.RevokeAll(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LogoutAllResponse{Revoked: revoked})
+}
+
+// respondServiceError writes the response for a sessions domain error.
+// Unclassified errors are logged and reported as internal errors.
+func (h *SessionHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("sessions_request_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
+
+// toTokenResponse maps session credentials to the response schema.
+func toTokenResponse(t *sessions.Tokens) TokenResponse {
+	resp := TokenResponse{
+		RefreshToken:     t.RefreshToken,
+		RefreshExpiresAt: t.RefreshExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if t.AccessToken != "" {
+		resp.AccessToken = t.AccessToken
+		resp.AccessExpiresAt = t.AccessExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
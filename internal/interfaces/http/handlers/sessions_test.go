@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/pkg/accesstoken"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions_RefreshRotationAndReuse(t *testing.T) {
+	router := setupSessionsTest(t, nil)
+
+	issued := issueSession(t, router)
+	w := perform(router, "POST", "/api/v1/auth/refresh", `{"refresh_token":"`+issued.RefreshToken+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var refreshed TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+	assert.NotEqual(t, issued.RefreshToken, refreshed.RefreshToken)
+	assert.Empty(t, refreshed.AccessToken)
+
+	w = perform(router, "POST", "/api/v1/auth/refresh", `{"refresh_token":"`+issued.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "refresh_token_reused")
+
+	w = perform(router, "POST", "/api/v1/auth/refresh", `{"refresh_token":"`+refreshed.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "refresh_token_invalid")
+
+	assert.Equal(t, http.StatusBadRequest, perform(router, "POST", "/api/v1/auth/refresh", `{}`).Code)
+}
+
+func TestSessions_RefreshIssuesAccessTokens(t *testing.T) {
+	signer, err := accesstoken.New([]byte(strings.Repeat("k", accesstoken.MinKeyLen)), accesstoken.Options{TTL: 15 * time.Minute, Issuer: "api"})
+	require.NoError(t, err)
+	router := setupSessionsTest(t, signer)
+
+	issued := issueSession(t, router)
+	require.NotEmpty(t, issued.AccessToken)
+	assert.NotEmpty(t, issued.AccessExpiresAt)
+
+	w := perform(router, "POST", "/api/v1/auth/refresh", `{"refresh_token":"`+issued.RefreshToken+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var refreshed TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+
+	claims, err := signer.Verify(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "jane", claims.Subject)
+	expiresAt, err := time.Parse(time.RFC3339, refreshed.AccessExpiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, claims.ExpiresAt, expiresAt.Unix())
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, time.Minute)
+}
+
+func TestSessions_Logout(t *testing.T) {
+	router := setupSessionsTest(t, nil)
+
+	first, second, third := issueSession(t, router), issueSession(t, router), issueSession(t, router)
+	assert.Equal(t, http.StatusNoContent, perform(router, "POST", "/api/v1/auth/logout", `{"refresh_token":"`+first.RefreshToken+`"}`).Code)
+
+	w := perform(router, "POST", "/api/v1/auth/logout-all", `{"refresh_token":"`+first.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "a revoked session cannot log out the others")
+	assert.Equal(t, http.StatusBadRequest, perform(router, "POST", "/api/v1/auth/logout-all", "").Code)
+
+	w = perform(router, "POST", "/api/v1/auth/logout-all", `{"refresh_token":"`+second.RefreshToken+`"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"revoked":2}`, w.Body.String())
+
+	for _, tokens := range []TokenResponse{first, second, third} {
+		w = perform(router, "POST", "/api/v1/auth/refresh", `{"refresh_token":"`+tokens.RefreshToken+`"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestSessions_RequireCredentials(t *testing.T) {
+	router := setupSessionsTest(t, nil)
+
+	assert.Equal(t, http.StatusUnauthorized, perform(router, "POST", "/api/v1/auth/sessions", "").Code,
+		"a request principal is not a verified credential")
+	assert.Equal(t, http.StatusUnauthorized, startSession(router, "wrong").Code)
+}
+
+// setupSessionsTest returns a router verifying the X-API-Key "jane-key"
+// as jane, minting access tokens with access when set; every request
+// claims to be jane in its request context.
+func setupSessionsTest(t *testing.T, access sessions.AccessIssuer) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(reqctx.With(c.Request.Context(), &reqctx.RequestContext{Principal: "jane"}))
+	})
+	authenticate := func(c *gin.Context) string {
+		if c.GetHeader("X-API-Key") == "jane-key" {
+			return "jane"
+		}
+		return ""
+	}
+	service := sessions.NewService(memory.NewSessionStore(), sessions.Options{TTL: time.Hour, Access: access})
+	NewSessionHandler(service, authenticate, log).Register(router.Group("/api/v1"))
+	return router
+}
+
+// startSession requests a session with the API key key.
+func startSession(router http.Handler, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/v1/auth/sessions", nil)
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// issueSession starts a session through the API.
+func issueSession(t *testing.T, router *gin.Engine) TokenResponse {
+	t.Helper()
+	w := startSession(router, "jane-key")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var tokens TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	return tokens
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/bolt"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// StoreHandler serves the embedded store admin API.
+type StoreHandler struct {
+	store *bolt.DB
+	log   *logger.Logger
+	now   func() time.Time
+}
+
+// NewStoreHandler creates a new embedded store admin handler.
+func NewStoreHandler(store *bolt.DB, log *logger.Logger) *StoreHandler {
+	return &StoreHandler{
+		store: store,
+		log:   log,
+		now:   time.Now,
+	}
+}
+
+// Register mounts the embedded store routes on the admin group.
+func (h *StoreHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/store/backup", h.Backup)
+	rg.POST("/store/compact", h.Compact)
+}
+
+// Backup handles GET /admin/store/backup.
+//
+// @Summary Download an embedded store backup
+// @Description Streams a consistent snapshot of the embedded store file while the
+// @Description instance keeps serving. Restore it by starting an instance with
+// @Description EMBEDDED_STORE_PATH pointing at the downloaded file.
+// @Tags Admin
+// @Produce application/octet-stream
+// @Success 200 {file} file
+// @Router /admin/store/backup [get]
+func (h *StoreHandler) Backup(c *gin.Context) {
+	name := "store-" + h.now().UTC().Format("20060102T150405") + ".db"
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+
+	n, err := h.store.Backup(c.Writer)
+	if err != nil {
+		h.log.Errorw("store_backup_failed", "error", err)
+		return
+	}
+	h.log.Infow("store_backup_completed", "bytes", n)
+}
+
+// Compact handles POST /admin/store/compact.
+//
+// @Summary Compact the embedded store
+// @Description Purges expired cache entries and rewrites the store file to its live
+// @Description size. Store operations wait while the file is swapped.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} bolt.CompactResult
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/store/compact [post]
+func (h *StoreHandler) Compact(c *gin.Context) {
+	result, err := h.store.Compact(c.Request.Context())
+	if err != nil {
+		h.log.Errorw("store_compaction_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	h.log.Infow("store_compacted", "size_before", result.SizeBefore, "size_after", result.SizeAfter)
+	c.JSON(http.StatusOK, result)
+}
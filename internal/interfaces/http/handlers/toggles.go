@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/toggles"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// ToggleHandler flips feature flags and route flags at runtime.
+type ToggleHandler struct {
+	service *toggles.Service
+	log     *logger.Logger
+}
+
+// NewToggleHandler creates a new toggle handler.
+func NewToggleHandler(service *toggles.Service, log *logger.Logger) *ToggleHandler {
+	return &ToggleHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// ToggleResponse represents the state of a feature or route. Version is 0
+// for the state declared by configuration.
+type ToggleResponse struct {
+	Kind      string `json:"kind" example:"route"`
+	Name      string `json:"name" example:"POST /api/v1/reports"`
+	Enabled   bool   `json:"enabled" example:"false"`
+	Status    int    `json:"status,omitempty" example:"503"`
+	Version   int64  `json:"version" example:"2"`
+	UpdatedAt string `json:"updated_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	UpdatedBy string `json:"updated_by,omitempty" example:"ops"`
+}
+
+// SetToggleRequest overrides a toggle. Version is the version of the
+// toggle that was read, 0 when it is not overridden.
+type SetToggleRequest struct {
+	Kind    string `json:"kind" binding:"required,oneof=feature route" example:"route"`
+	Name    string `json:"name" binding:"required,max=256" example:"POST /api/v1/reports"`
+	Enabled *bool  `json:"enabled" binding:"required" example:"false"`
+	Status  int    `json:"status" binding:"omitempty,oneof=404 503" example:"503"`
+	Version *int64 `json:"version" binding:"required,min=0" example:"1"`
+}
+
+// ResetToggleRequest removes the override of a toggle.
+type ResetToggleRequest struct {
+	Kind    string `form:"kind" binding:"required,oneof=feature route"`
+	Name    string `form:"name" binding:"required,max=256"`
+	Version int64  `form:"version" binding:"required,min=1"`
+}
+
+// ToggleChangesRequest selects the most recent changes.
+type ToggleChangesRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// ToggleChangeResponse represents an audit record of a toggle change.
+type ToggleChangeResponse struct {
+	Kind      string          `json:"kind" example:"route"`
+	Name      string          `json:"name" example:"POST /api/v1/reports"`
+	Action    string          `json:"action" example:"set"`
+	Before    *ToggleResponse `json:"before"`
+	After     *ToggleResponse `json:"after"`
+	At        string          `json:"at" example:"2024-01-15T10:30:00Z"`
+	By        string          `json:"by,omitempty" example:"ops"`
+	RequestID string          `json:"request_id,omitempty" example:"3f2a9c1e"`
+}
+
+// defaultToggleChanges is the number of changes listed without a limit.
+const defaultToggleChanges = 50
+
+// Register mounts the toggle routes on the admin group.
+func (h *ToggleHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/toggles", h.List)
+	rg.PUT("/toggles", h.Set)
+	rg.DELETE("/toggles", h.Reset)
+	rg.GET("/toggles/changes", h.Changes)
+}
+
+// List handles GET /admin/toggles.
+//
+// @Summary List toggles
+// @Description Lists the overridden feature and route flags and the ones declared by configuration
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} ToggleResponse
+// @Router /admin/toggles [get]
+func (h *ToggleHandler) List(c *gin.Context) {
+	list, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	out := make([]ToggleResponse, len(list))
+	for i, t := range list {
+		out[i] = toToggleResponse(t)
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// Set handles PUT /admin/toggles.
+//
+// @Summary Override toggle
+// @Description Enables or disables a feature or route until reset; the override is persisted and applied immediately
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body SetToggleRequest true "Toggle state and the version it replaces"
+// @Success 200 {object} ToggleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/toggles [put]
+func (h *ToggleHandler) Set(c *gin.Context) {
+	var req SetToggleRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	toggle, err := h.service.Set(c.Request.Context(), toggles.Toggle{
+		Kind:    toggles.Kind(req.Kind),
+		Name:    req.Name,
+		Enabled: *req.Enabled,
+		Status:  req.Status,
+	}, *req.Version)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	h.log.Infow("toggle_set", "kind", toggle.Kind, "name", toggle.Name, "enabled", toggle.Enabled, "version", toggle.Version)
+	c.JSON(http.StatusOK, toToggleResponse(toggle))
+}
+
+// Reset handles DELETE /admin/toggles.
+//
+// @Summary Reset toggle
+// @Description Removes the override of a feature or route, restoring the state declared by configuration
+// @Tags Admin
+// @Param kind query string true "feature or route"
+// @Param name query string true "Feature name or route"
+// @Param version query int true "Version of the override"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/toggles [delete]
+func (h *ToggleHandler) Reset(c *gin.Context) {
+	var req ResetToggleRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if err := h.service.Reset(c.Request.Context(), toggles.Kind(req.Kind), req.Name, req.Version); err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	h.log.Infow("toggle_reset", "kind", req.Kind, "name", req.Name, "version", req.Version)
+	c.Status(http.StatusNoContent)
+}
+
+// Changes handles GET /admin/toggles/changes.
+//
+// @Summary List toggle changes
+// @Description Lists the audit trail of overrides set and reset, newest first
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Number of changes (default 50, max 500)"
+// @Success 200 {array} ToggleChangeResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/toggles/changes [get]
+func (h *ToggleHandler) Changes(c *gin.Context) {
+	req := ToggleChangesRequest{Limit: defaultToggleChanges}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	changes, err := h.service.Changes(c.Request.Context(), req.Limit)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	out := make([]ToggleChangeResponse, len(changes))
+	for i, change := range changes {
+		out[i] = ToggleChangeResponse{
+			Kind:      string(change.Kind),
+			Name:      change.Name,
+			Action:    change.Action,
+			At:        change.At.UTC().Format(time.RFC3339),
+			By:        change.By,
+			RequestID: change.RequestID,
+		}
+		if change.Before != nil {
+			before := toToggleResponse(*change.Before)
+			out[i].Before = &before
+		}
+		if change.After != nil {
+			after := toToggleResponse(*change.After)
+			out[i].After = &after
+		}
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// respondServiceError maps toggles domain errors to HTTP responses.
+func (h *ToggleHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("toggles_request_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
+
+// toToggleResponse maps a toggle to its response schema.
+func toToggleResponse(t toggles.Toggle) ToggleResponse {
+	out := ToggleResponse{
+		Kind:      string(t.Kind),
+		Name:      t.Name,
+		Enabled:   t.Enabled,
+		Status:    t.Status,
+		Version:   t.Version,
+		UpdatedBy: t.UpdatedBy,
+	}
+	if !t.UpdatedAt.IsZero() {
+		out.UpdatedAt = t.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// TwoFactorHandler handles two-factor enrollment and verification of the
+// calling principal.
+type TwoFactorHandler struct {
+	service      *twofactor.Service
+	authenticate func(*gin.Context) string
+	log          *logger.Logger
+}
+
+// NewTwoFactorHandler creates a new two-factor handler. authenticate
+// verifies the credentials of a request and returns their principal,
+// empty when they are missing or invalid.
+func NewTwoFactorHandler(service *twofactor.Service, authenticate func(*gin.Context) string, log *logger.Logger) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		service:      service,
+		authenticate: authenticate,
+		log:          log,
+	}
+}
+
+// TwoFactorStatusResponse represents the two-factor state of the caller.
+type TwoFactorStatusResponse struct {
+	Enabled          bool `json:"enabled" example:"true"`
+	BackupCodesCount int  `json:"backup_codes_count" example:"10"`
+}
+
+// TwoFactorEnrollmentResponse carries the secret of a new enrollment.
+type TwoFactorEnrollmentResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP" pii:"secret"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/Example:jane@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Example" pii:"secret"`
+}
+
+// TwoFactorEnrollmentRequest names the account of a new enrollment.
+type TwoFactorEnrollmentRequest struct {
+	Account string `json:"account" binding:"required,max=254" example:"jane@example.com" pii:"email"`
+}
+
+// TwoFactorBackupCodesResponse carries the backup codes issued on
+// confirmation.
+type TwoFactorBackupCodesResponse struct {
+	BackupCodes []string `json:"backup_codes" example:"3f2a-91bc" pii:"secret"`
+}
+
+// TwoFactorCodeRequest carries a one-time or backup code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=16" example:"123456"`
+}
+
+// Register mounts the two-factor routes on the API group.
+func (h *TwoFactorHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/2fa", h.Status)
+	rg.POST("/2fa/enrollment", h.Enroll)
+	rg.POST("/2fa/enrollment/confirm", h.Confirm)
+	rg.POST("/2fa/verify", h.Verify)
+	rg.POST("/2fa/disable", h.Disable)
+}
+
+// Status handles GET /api/v1/2fa.
+//
+// @Summary Get two-factor status
+// @Tags TwoFactor
+// @Produce json
+// @Success 200 {object} TwoFactorStatusResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/2fa [get]
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Status(c.Request.Context(), principal)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorStatusResponse{
+		Enabled:          status.Enabled,
+		BackupCodesCount: status.BackupCodesCount,
+	})
+}
+
+// Enroll handles POST /api/v1/2fa/enrollment.
+//
+// @Summary Start two-factor enrollment
+// @Description Returns a new TOTP secret and its otpauth URI for authenticator apps, labeled with the account; confirm it with a first code
+// @Tags TwoFactor
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorEnrollmentRequest true "Account shown by authenticator apps"
+// @Success 201 {object} TwoFactorEnrollmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/2fa/enrollment [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+	var req TwoFactorEnrollmentRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	provisioning, err := h.service.Enroll(c.Request.Context(), principal, req.Account)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, TwoFactorEnrollmentResponse{
+		Secret: provisioning.Secret,
+		URI:    provisioning.URI,
+	})
+}
+
+// Confirm handles POST /api/v1/2fa/enrollment/confirm.
+//
+// @Summary Confirm two-factor enrollment
+// @Description Enables two-factor authentication and returns single-use backup codes, shown only once
+// @Tags TwoFactor
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} TwoFactorBackupCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/2fa/enrollment/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	principal, code, ok := h.codeRequest(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.service.Confirm(c.Request.Context(), principal, code)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorBackupCodesResponse{BackupCodes: codes})
+}
+
+// Verify handles POST /api/v1/2fa/verify.
+//
+// @Summary Verify a two-factor code
+// @Description Accepts a one-time code or consumes a backup code; repeated wrong codes lock verification for a while
+// @Tags TwoFactor
+// @Accept json
+// @Param request body TwoFactorCodeRequest true "One-time or backup code"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/v1/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	principal, code, ok := h.codeRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Verify(c.Request.Context(), principal, code); err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Disable handles POST /api/v1/2fa/disable.
+//
+// @Summary Disable two-factor authentication
+// @Tags TwoFactor
+// @Accept json
+// @Param request body TwoFactorCodeRequest true "One-time or backup code"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/v1/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	principal, code, ok := h.codeRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Disable(c.Request.Context(), principal, code); err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// principal returns the verified caller or responds 401.
+func (h *TwoFactorHandler) principal(c *gin.Context) (string, bool) {
+	principal := h.authenticate(c)
+	if principal == "" {
+		respondError(c, http.StatusUnauthorized, "unauthorized", "valid credentials required")
+		return "", false
+	}
+	return principal, true
+}
+
+// codeRequest returns the caller and the code of a TwoFactorCodeRequest
+// body, responding with an error when either is missing.
+func (h *TwoFactorHandler) codeRequest(c *gin.Context) (string, string, bool) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return "", "", false
+	}
+	var req TwoFactorCodeRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return "", "", false
+	}
+	return principal, req.Code, true
+}
+
+// respondServiceError writes the response for a two-factor domain error.
+// Unclassified errors are logged and reported as internal errors.
+func (h *TwoFactorHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("two_factor_request_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
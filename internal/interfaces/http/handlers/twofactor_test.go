@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/pkg/crypto"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactor_EnrollmentFlow(t *testing.T) {
+	router := setupTwoFactorTest(t, "jane")
+
+	assert.Equal(t, http.StatusBadRequest, perform(router, "POST", "/api/v1/2fa/enrollment", `{}`).Code, "the account label is required")
+	w := perform(router, "POST", "/api/v1/2fa/enrollment", `{"account":"jane@example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var enrollment TwoFactorEnrollmentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrollment))
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Example:jane@example.com?"), enrollment.URI)
+
+	code, err := totp.Code(enrollment.Secret, time.Now(), totp.Options{})
+	require.NoError(t, err)
+	w = perform(router, "POST", "/api/v1/2fa/enrollment/confirm", `{"code":"`+code+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var backup TwoFactorBackupCodesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &backup))
+	require.Len(t, backup.BackupCodes, 2)
+
+	w = perform(router, "POST", "/api/v1/2fa/verify", `{"code":"`+code+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "replayed code")
+	assert.Contains(t, w.Body.String(), "two_factor_invalid_code")
+	assert.Equal(t, http.StatusNoContent, perform(router, "POST", "/api/v1/2fa/verify", `{"code":"`+backup.BackupCodes[0]+`"}`).Code)
+
+	w = perform(router, "GET", "/api/v1/2fa", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled":true,"backup_codes_count":1}`, w.Body.String())
+
+	assert.Equal(t, http.StatusConflict, perform(router, "POST", "/api/v1/2fa/enrollment", `{"account":"jane@example.com"}`).Code)
+	assert.Equal(t, http.StatusNoContent, perform(router, "POST", "/api/v1/2fa/disable", `{"code":"`+backup.BackupCodes[1]+`"}`).Code)
+	assert.Equal(t, http.StatusNotFound, perform(router, "POST", "/api/v1/2fa/verify", `{"code":"123456"}`).Code)
+}
+
+func TestTwoFactor_RequiresCredentials(t *testing.T) {
+	router := setupTwoFactorTest(t, "")
+
+	assert.Equal(t, http.StatusUnauthorized, perform(router, "GET", "/api/v1/2fa", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, perform(router, "POST", "/api/v1/2fa/enrollment", `{"account":"jane@example.com"}`).Code)
+}
+
+func TestTwoFactor_LocksAfterFailedAttempts(t *testing.T) {
+	router := setupTwoFactorTest(t, "jane")
+
+	w := perform(router, "POST", "/api/v1/2fa/enrollment", `{"account":"jane@example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var enrollment TwoFactorEnrollmentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrollment))
+	code, err := totp.Code(enrollment.Secret, time.Now(), totp.Options{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, perform(router, "POST", "/api/v1/2fa/enrollment/confirm", `{"code":"`+code+`"}`).Code)
+
+	for i := 0; i < twofactor.DefaultMaxAttempts; i++ {
+		assert.Equal(t, http.StatusUnauthorized, perform(router, "POST", "/api/v1/2fa/verify", `{"code":"000000"}`).Code)
+	}
+	w = perform(router, "POST", "/api/v1/2fa/disable", `{"code":"000000"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "two_factor_locked")
+}
+
+func TestTwoFactor_CodeValidation(t *testing.T) {
+	router := setupTwoFactorTest(t, "jane")
+
+	w := perform(router, "POST", "/api/v1/2fa/verify", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_request")
+}
+
+// setupTwoFactorTest returns a router verifying every request as
+// principal, none when empty.
+func setupTwoFactorTest(t *testing.T, principal string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	keyring, err := crypto.NewKeyring(map[string][]byte{"k1": make([]byte, crypto.KeySize)}, "k1")
+	require.NoError(t, err)
+	service := twofactor.NewService(memory.NewTwoFactorRepository(), crypto.NewEnvelope(keyring), twofactor.Options{Issuer: "Example", Skew: 1, BackupCodes: 2})
+
+	router := gin.New()
+	authenticate := func(*gin.Context) string { return principal }
+	NewTwoFactorHandler(service, authenticate, log).Register(router.Group("/api/v1"))
+	return router
+}
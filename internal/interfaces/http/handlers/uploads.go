@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/uploads"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// UploadHandler serves file uploads.
+type UploadHandler struct {
+	uploads *uploads.Service
+	log     *logger.Logger
+}
+
+// NewUploadHandler creates a new upload handler.
+func NewUploadHandler(service *uploads.Service, log *logger.Logger) *UploadHandler {
+	return &UploadHandler{
+		uploads: service,
+		log:     log,
+	}
+}
+
+// UploadResponse represents a stored upload.
+type UploadResponse struct {
+	Key         string `json:"key" example:"uploads/9f86d081884c7d659a2feaa0c55ad015/avatar.png"`
+	Name        string `json:"name" example:"avatar.png"`
+	ContentType string `json:"content_type,omitempty" example:"image/png"`
+	Size        int64  `json:"size" example:"48213"`
+
+	DownloadURL       string `json:"download_url"`
+	DownloadExpiresAt string `json:"download_expires_at" example:"2024-01-15T10:45:00Z"`
+}
+
+// Register mounts the upload routes on the API group.
+func (h *UploadHandler) Register(rg *gin.RouterGroup) {
+	rg.POST("/uploads", h.Upload)
+}
+
+// Upload handles POST /api/v1/uploads.
+//
+// @Summary Upload a file
+// @Description Stores the file of the multipart "file" field, scanned for malware first when
+// @Description a scanner is configured. Infected files are rejected with file_infected; files
+// @Description that could not be scanned are rejected with 503 unless scanning fails open.
+// @Tags Uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to upload"
+// @Success 201 {object} UploadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/uploads [post]
+func (h *UploadHandler) Upload(c *gin.Context) {
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.uploads.MaxSize()+64<<10)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondServiceError(c, uploads.ErrTooLarge)
+			return
+		}
+		respondError(c, http.StatusBadRequest, "invalid_request", "multipart field \"file\" is required")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		h.log.Errorw("upload_read_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	defer file.Close()
+
+	upload, err := h.uploads.Upload(c.Request.Context(), header.Filename, header.Header.Get("Content-Type"), header.Size, file)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	link, err := h.uploads.URL(c.Request.Context(), upload.Key)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+	h.log.Infow("file_uploaded", "key", upload.Key, "size", upload.Size)
+	c.JSON(http.StatusCreated, UploadResponse{
+		Key:               upload.Key,
+		Name:              upload.Name,
+		ContentType:       upload.ContentType,
+		Size:              upload.Size,
+		DownloadURL:       link,
+		DownloadExpiresAt: time.Now().Add(h.uploads.LinkTTL()).UTC().Format(time.RFC3339),
+	})
+}
+
+// respondServiceError writes the response for an uploads domain error,
+// answering 413 for files above the size limit. Unclassified errors are
+// logged and reported as internal errors.
+func (h *UploadHandler) respondServiceError(c *gin.Context, err error) {
+	kind := apperrors.KindOf(err)
+	if errors.Is(err, uploads.ErrTooLarge) {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+		return
+	}
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("upload_failed", "error", err)
+		respondError(c, http.StatusInternalServerError, string(kind), "internal server error")
+		return
+	}
+	c.AbortWithStatusJSON(apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/metering"
+)
+
+// UsageHandler reports metered usage.
+type UsageHandler struct {
+	aggregator *metering.Aggregator
+	subject    func(*gin.Context) string
+}
+
+// NewUsageHandler creates a new usage handler. subject returns the verified
+// caller of the self-service route, empty when unknown.
+func NewUsageHandler(aggregator *metering.Aggregator, subject func(*gin.Context) string) *UsageHandler {
+	return &UsageHandler{
+		aggregator: aggregator,
+		subject:    subject,
+	}
+}
+
+// Register mounts the self-service usage route on the API group.
+func (h *UsageHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/usage", h.Own)
+}
+
+// RegisterAdmin mounts the per-subject usage route on the admin group.
+func (h *UsageHandler) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.GET("/usage/:subject", h.Subject)
+}
+
+// Own handles GET /api/v1/usage.
+//
+// @Summary Get own usage
+// @Tags Usage
+// @Produce json
+// @Param period query string false "daily or monthly (default monthly)"
+// @Success 200 {object} metering.Summary
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/usage [get]
+func (h *UsageHandler) Own(c *gin.Context) {
+	subject := h.subject(c)
+	if subject == "" {
+		respondError(c, http.StatusUnauthorized, "unauthorized", "a valid API key is required")
+		return
+	}
+	h.respond(c, subject)
+}
+
+// Subject handles GET /admin/usage/:subject.
+//
+// @Summary Get usage of a subject
+// @Tags Admin
+// @Produce json
+// @Param subject path string true "API key ID or tenant ID"
+// @Param period query string false "daily or monthly (default monthly)"
+// @Success 200 {object} metering.Summary
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/usage/{subject} [get]
+func (h *UsageHandler) Subject(c *gin.Context) {
+	h.respond(c, c.Param("subject"))
+}
+
+// respond writes the usage summary for subject in the requested period.
+func (h *UsageHandler) respond(c *gin.Context, subject string) {
+	period := metering.Period(c.DefaultQuery("period", string(metering.Monthly)))
+	if period != metering.Daily && period != metering.Monthly {
+		respondError(c, http.StatusBadRequest, "invalid_request", "period must be daily or monthly")
+		return
+	}
+	c.JSON(http.StatusOK, h.aggregator.Summary(subject, period))
+}
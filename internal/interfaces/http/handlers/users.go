@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/pkg/batch"
+	"github.com/luminosita/change-me/pkg/export"
+	"github.com/luminosita/change-me/pkg/filter"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/luminosita/change-me/pkg/pii"
+)
+
+// UserHandler handles users module requests.
+type UserHandler struct {
+	service users.UserService
+	log     *logger.Logger
+	cursors *pagination.Cursors
+}
+
+// NewUserHandler creates a new users handler.
+func NewUserHandler(service users.UserService, log *logger.Logger) *UserHandler {
+	return &UserHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// WithCursors pages lists by the signed cursors of c: list responses
+// carry a next_cursor, accepted back as the cursor parameter.
+func (h *UserHandler) WithCursors(c *pagination.Cursors) *UserHandler {
+	h.cursors = c
+	return h
+}
+
+// UserResponse represents user response schema.
+type UserResponse struct {
+	ID        string `json:"id" example:"01890a5d-ac96-774b-bcce-b302099a8057" export:"ID"`
+	Email     string `json:"email" example:"jane@example.com" export:"Email" pii:"email"`
+	Username  string `json:"username" example:"jane" export:"Username"`
+	FullName  string `json:"full_name" example:"Jane Doe" export:"Full Name" pii:"name"`
+	Phone     string `json:"phone,omitempty" example:"+14155550100" export:"Phone" pii:"phone"`
+	IsActive  bool   `json:"is_active" example:"true" export:"Active"`
+	CreatedAt string `json:"created_at" example:"2024-01-15T10:30:00Z" export:"Created At"`
+	UpdatedAt string `json:"updated_at" example:"2024-01-15T10:30:00Z" export:"Updated At"`
+}
+
+// UserListResponse represents a page of users.
+type UserListResponse struct {
+	Items      []UserResponse `json:"items"`
+	Limit      int            `json:"limit" example:"20"`
+	Offset     int            `json:"offset" example:"0"`
+	Total      int            `json:"total" example:"42"`
+	NextCursor string         `json:"next_cursor,omitempty" example:"AXsiYSI6WyIyMCJdLCJlIjoxNzA1MzE4MjAwfQ"`
+}
+
+// userListQuery holds the query parameters of a user listing.
+type userListQuery struct {
+	pagination.Params
+	Cursor string `form:"cursor"`
+	Filter string `form:"filter"`
+}
+
+// cursorFilters returns the filters a cursor of a listing by spec carries,
+// so it cannot be replayed on another filter.
+func cursorFilters(spec filter.Spec) map[string]string {
+	if spec.IsZero() {
+		return nil
+	}
+	return map[string]string{"filter": spec.String()}
+}
+
+// CreateUserRequest represents user creation request schema.
+type CreateUserRequest struct {
+	Email    string `json:"email" binding:"required,email,max=254" sanitize:"" example:"jane@example.com" pii:"email"`
+	Username string `json:"username" binding:"required,min=3,max=32" sanitize:"nfkc" example:"jane"`
+	FullName string `json:"full_name" binding:"max=128" sanitize:"text" example:"Jane Doe" pii:"name"`
+	Phone    string `json:"phone" binding:"omitempty,max=32,phone" sanitize:"" example:"+14155550100" pii:"phone"`
+	IsActive *bool  `json:"is_active" example:"true"`
+}
+
+// UpdateUserRequest is the user representation a PATCH must produce.
+type UpdateUserRequest struct {
+	Email    string `json:"email" binding:"required,email,max=254" sanitize:"" pii:"email"`
+	Username string `json:"username" binding:"required,min=3,max=32" sanitize:"nfkc"`
+	FullName string `json:"full_name" binding:"max=128" sanitize:"text" pii:"name"`
+	Phone    string `json:"phone" binding:"omitempty,max=32,phone" sanitize:"" pii:"phone"`
+	IsActive bool   `json:"is_active"`
+}
+
+// userPatchPaths are the fields of UserResponse a PATCH may change.
+var userPatchPaths = []string{"/email", "/username", "/full_name", "/phone", "/is_active"}
+
+// exportPageSize bounds the number of users held in memory during export.
+const exportPageSize = pagination.MaxLimit
+
+// Register mounts the users routes on the API group.
+func (h *UserHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/users", h.List)
+	rg.POST("/users", h.Create)
+	rg.POST("/users/bulk", h.BulkCreate)
+	rg.GET("/users/export", h.Export)
+	rg.GET("/users/:id", h.Get)
+	rg.PATCH("/users/:id", h.Update)
+	rg.DELETE("/users/:id", h.Delete)
+}
+
+// List handles GET /api/v1/users.
+//
+// @Summary List users
+// @Tags Users
+// @Produce json
+// @Param limit query int false "Page size (default 20, max PAGINATION_MAX_PAGE_SIZE; required when DEBUG=true)"
+// @Param offset query int false "Items to skip"
+// @Param cursor query string false "next_cursor of the previous page; replaces offset"
+// @Param filter query string false "RSQL filter, e.g. is_active==true;created_at>2024-01-01"
+// @Success 200 {object} UserListResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/users [get]
+func (h *UserHandler) List(c *gin.Context) {
+	var query userListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	params := query.Params
+	spec, err := filter.Parse(query.Filter, users.ListFilter)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	params.Filter = spec
+	if query.Cursor != "" {
+		if h.cursors == nil {
+			respondError(c, http.StatusBadRequest, "invalid_request", "cursor pagination is not enabled")
+			return
+		}
+		cursor, err := h.cursors.Decode(query.Cursor)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		if !cursor.Matches(cursorFilters(spec)) {
+			respondError(c, http.StatusBadRequest, "invalid_request", "cursor belongs to a listing with another filter")
+			return
+		}
+		params.After, params.Offset = cursor.After, 0
+	}
+
+	page, err := h.service.List(c.Request.Context(), params)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	items := make([]UserResponse, len(page.Items))
+	for i := range page.Items {
+		items[i] = toUserResponse(&page.Items[i])
+	}
+
+	resp := UserListResponse{
+		Items:  items,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+		Total:  page.Total,
+	}
+	if h.cursors != nil && page.HasMore() && len(page.Items) > 0 {
+		resp.NextCursor = h.cursors.Encode(pagination.Cursor{
+			After:   users.ListKey(page.Items[len(page.Items)-1]),
+			Filters: cursorFilters(spec),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Get handles GET /api/v1/users/:id.
+//
+// @Summary Get user
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} UserResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/users/{id} [get]
+func (h *UserHandler) Get(c *gin.Context) {
+	var id users.UserID
+	if !bindPathID(c, "id", &id) {
+		return
+	}
+
+	user, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// Update handles PATCH /api/v1/users/:id.
+//
+// @Summary Update user
+// @Description Applies a JSON Patch (RFC 6902) or JSON Merge Patch (RFC 7386) to the user.
+// @Description Only email, username, full_name, phone and is_active may change; other fields can be tested.
+// @Description The result is stored only if the user was not updated meanwhile; otherwise it fails with 409 user_modified.
+// @Tags Users
+// @Accept application/json-patch+json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Param request body []jsonpatch.Operation true "JSON Patch operations, or a merge patch of UserResponse"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Router /api/v1/users/{id} [patch]
+func (h *UserHandler) Update(c *gin.Context) {
+	var id users.UserID
+	if !bindPathID(c, "id", &id) {
+		return
+	}
+
+	user, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := bindPatch(c, toUserResponse(user), userPatchPaths, &req); err != nil {
+		respondPatchError(c, err)
+		return
+	}
+
+	// Store the result only over the state it was derived from
+	in := req.toInput()
+	in.UpdatedAt = user.UpdatedAt
+	user, err = h.service.Update(c.Request.Context(), id, in)
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// Delete handles DELETE /api/v1/users/:id.
+//
+// @Summary Delete user
+// @Description Soft-deletes the user; it is no longer listed or returned, and its email and username stay reserved
+// @Tags Users
+// @Param id path string true "User ID" format(uuid)
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/users/{id} [delete]
+func (h *UserHandler) Delete(c *gin.Context) {
+	var id users.UserID
+	if !bindPathID(c, "id", &id) {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Create handles POST /api/v1/users.
+//
+// @Summary Create user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body CreateUserRequest true "User to create"
+// @Success 201 {object} UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users [post]
+func (h *UserHandler) Create(c *gin.Context) {
+	var req CreateUserRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	user, err := h.service.Create(c.Request.Context(), req.toInput())
+	if err != nil {
+		h.respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toUserResponse(user))
+}
+
+// BulkCreate handles POST /api/v1/users/bulk.
+//
+// @Summary Create users in bulk
+// @Description Validates and creates each item independently. Responds 200 when
+// @Description every item succeeded and 207 with per-item results otherwise.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body BulkRequest[CreateUserRequest] true "Users to create"
+// @Success 200 {object} BulkResponse[UserResponse]
+// @Success 207 {object} BulkResponse[UserResponse]
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Router /api/v1/users/bulk [post]
+func (h *UserHandler) BulkCreate(c *gin.Context) {
+	handleBulk(c, bulkOperation[CreateUserRequest, UserResponse]{
+		successStatus: http.StatusCreated,
+		execute: func(ctx context.Context, items []CreateUserRequest) []batch.Outcome[UserResponse] {
+			inputs := make([]users.CreateInput, len(items))
+			for i := range items {
+				inputs[i] = items[i].toInput()
+			}
+
+			created := h.service.CreateMany(ctx, inputs)
+			out := make([]batch.Outcome[UserResponse], len(created))
+			for i, o := range created {
+				out[i] = batch.Outcome[UserResponse]{Index: o.Index, Err: o.Err}
+				if o.Err == nil {
+					out[i].Value = toUserResponse(o.Value)
+				}
+			}
+			return out
+		},
+		mapError: h.mapServiceError,
+	})
+}
+
+// Export handles GET /api/v1/users/export.
+//
+// @Summary Export users
+// @Description Streams all users as CSV (default) or XLSX
+// @Tags Users
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv or xlsx"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/users/export [get]
+func (h *UserHandler) Export(c *gin.Context) {
+	format := export.FormatFromRequest(c.Request)
+	if !export.IsSupported(format) {
+		respondError(c, http.StatusBadRequest, "invalid_request", "format must be csv or xlsx")
+		return
+	}
+
+	fetch := func(ctx context.Context, offset, limit int) ([]UserResponse, error) {
+		// The pagination guard may bound pages below limit; a short page
+		// would end the export early
+		var items []users.User
+		for len(items) < limit {
+			page, err := h.service.List(ctx, pagination.Params{Limit: limit - len(items), Offset: offset + len(items)})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, page.Items...)
+			if len(page.Items) == 0 || !page.HasMore() {
+				break
+			}
+		}
+		out := make([]UserResponse, len(items))
+		for i := range items {
+			out[i] = toUserResponse(&items[i])
+			if pii.Masking(ctx) {
+				out[i] = pii.Masked(out[i]).(UserResponse)
+			}
+		}
+		return out, nil
+	}
+
+	n, err := export.ServeHTTP(c.Request.Context(), c.Writer, format, "users", exportPageSize, fetch)
+	if err != nil {
+		h.log.Errorw("users_export_failed", "format", format, "rows", n, "error", err)
+		return
+	}
+	h.log.Infow("users_exported", "format", format, "rows", n)
+}
+
+// respondServiceError maps users domain errors to HTTP responses.
+func (h *UserHandler) respondServiceError(c *gin.Context, err error) {
+	status, body := h.mapServiceError(err)
+	c.AbortWithStatusJSON(status, body)
+}
+
+// mapServiceError returns the status and error body for a users domain error.
+// Unclassified errors are logged and reported as internal errors.
+func (h *UserHandler) mapServiceError(err error) (int, ErrorResponse) {
+	kind := apperrors.KindOf(err)
+	if kind == apperrors.KindInternal {
+		h.log.Errorw("users_request_failed", "error", err)
+		return http.StatusInternalServerError, ErrorResponse{Error: string(kind), Code: string(kind), Message: "internal server error"}
+	}
+	return apperrors.HTTPStatus(err), ErrorResponse{Error: string(kind), Code: apperrors.CodeOf(err), Message: err.Error()}
+}
+
+// toInput maps the request to service input, defaulting is_active to true.
+func (r *CreateUserRequest) toInput() users.CreateInput {
+	active := true
+	if r.IsActive != nil {
+		active = *r.IsActive
+	}
+	return users.CreateInput{
+		Email:    r.Email,
+		Username: r.Username,
+		FullName: r.FullName,
+		Phone:    r.Phone,
+		IsActive: active,
+	}
+}
+
+// toInput maps the request to service input.
+func (r *UpdateUserRequest) toInput() users.UpdateInput {
+	return users.UpdateInput{
+		Email:    r.Email,
+		Username: r.Username,
+		FullName: r.FullName,
+		Phone:    r.Phone,
+		IsActive: r.IsActive,
+	}
+}
+
+// toUserResponse maps a domain user to its response schema.
+func toUserResponse(u *users.User) UserResponse {
+	return UserResponse{
+		ID:        u.ID.String(),
+		Email:     u.Email,
+		Username:  u.Username,
+		FullName:  u.FullName,
+		Phone:     u.Phone,
+		IsActive:  u.IsActive,
+		CreatedAt: u.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: u.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
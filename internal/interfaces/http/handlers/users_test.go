@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/pkg/ids"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsers_CreateAndGet(t *testing.T) {
+	router := setupUsersTest(t)
+
+	w := perform(router, "POST", "/api/v1/users", `{"email":"Jane@Example.com","username":"jane","full_name":"Jane Doe"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "jane@example.com", created.Email)
+	assert.True(t, created.IsActive)
+
+	w = perform(router, "GET", "/api/v1/users/"+userID(1), "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, created, got)
+}
+
+func TestUsers_CreateSanitizesInput(t *testing.T) {
+	router := setupUsersTest(t)
+
+	w := perform(router, "POST", "/api/v1/users", `{"email":" jane@example.com ","username":"ｊａｎｅ","full_name":"<b>Jane</b> Doe<script>alert(1)</script>"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "jane@example.com", created.Email)
+	assert.Equal(t, "jane", created.Username)
+	assert.Equal(t, "Jane Doe", created.FullName)
+}
+
+func TestUsers_CreateValidation(t *testing.T) {
+	router := setupUsersTest(t)
+
+	w := perform(router, "POST", "/api/v1/users", `{"email":"not-an-email","username":"jane"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_request")
+}
+
+func TestUsers_Phone(t *testing.T) {
+	router := setupUsersTest(t)
+
+	w := perform(router, "POST", "/api/v1/users", `{"email":"jane@example.com","username":"jane","phone":"+1 (415) 555-0100"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "+14155550100", created.Phone, "phones are stored in E.164 form")
+
+	w = perform(router, "POST", "/api/v1/users", `{"email":"joan@example.com","username":"joan","phone":"415 555 0100"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "national numbers have no calling code")
+
+	w = performPatch(router, "/api/v1/users/"+userID(1), "application/merge-patch+json", `{"phone":null}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), `"phone"`)
+}
+
+func TestUsers_CreateDuplicateEmail(t *testing.T) {
+	router := setupUsersTest(t)
+
+	perform(router, "POST", "/api/v1/users", `{"email":"jane@example.com","username":"jane"}`)
+	w := perform(router, "POST", "/api/v1/users", `{"email":"jane@example.com","username":"jane2"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var body ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "conflict", body.Error)
+	assert.Equal(t, "user_email_taken", body.Code)
+}
+
+func TestUsers_GetNotFound(t *testing.T) {
+	router := setupUsersTest(t)
+
+	assert.Equal(t, http.StatusNotFound, perform(router, "GET", "/api/v1/users/"+userID(99), "").Code)
+	assert.Equal(t, http.StatusBadRequest, perform(router, "GET", "/api/v1/users/abc", "").Code)
+}
+
+func TestUsers_DeleteHidesUser(t *testing.T) {
+	router := setupUsersTest(t)
+	createUsers(t, router, 2)
+
+	assert.Equal(t, http.StatusNoContent, perform(router, "DELETE", "/api/v1/users/"+userID(1), "").Code)
+	assert.Equal(t, http.StatusNotFound, perform(router, "GET", "/api/v1/users/"+userID(1), "").Code)
+	assert.Equal(t, http.StatusNotFound, perform(router, "DELETE", "/api/v1/users/"+userID(1), "").Code)
+
+	var page UserListResponse
+	require.NoError(t, json.Unmarshal(perform(router, "GET", "/api/v1/users", "").Body.Bytes(), &page))
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, userID(2), page.Items[0].ID)
+}
+
+func TestUsers_UpdateJSONPatch(t *testing.T) {
+	router := setupUsersTest(t)
+	createUsers(t, router, 1)
+
+	w := performPatch(router, "/api/v1/users/"+userID(1), "application/json-patch+json", `[
+		{"op":"test","path":"/id","value":"`+userID(1)+`"},
+		{"op":"replace","path":"/email","value":"Jane@Example.com"},
+		{"op":"replace","path":"/full_name","value":"<b>Jane</b> Doe"},
+		{"op":"replace","path":"/is_active","value":false}
+	]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var updated UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "jane@example.com", updated.Email)
+	assert.Equal(t, "user000", updated.Username)
+	assert.Equal(t, "Jane Doe", updated.FullName, "patched values are sanitized")
+	assert.False(t, updated.IsActive)
+}
+
+func TestUsers_UpdateMergePatch(t *testing.T) {
+	router := setupUsersTest(t)
+	perform(router, "POST", "/api/v1/users", `{"email":"jane@example.com","username":"jane","full_name":"Jane Doe"}`)
+
+	w := performPatch(router, "/api/v1/users/"+userID(1), "application/merge-patch+json", `{"username":"joan","full_name":null}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var updated UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "joan", updated.Username)
+	assert.Empty(t, updated.FullName)
+	assert.True(t, updated.IsActive)
+}
+
+func TestUsers_UpdateErrors(t *testing.T) {
+	router := setupUsersTest(t)
+	createUsers(t, router, 2)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		status      int
+		code        string
+	}{
+		{"media type", "/api/v1/users/" + userID(1), "application/json", `{"username":"joan"}`, http.StatusUnsupportedMediaType, "patch_media_type_unsupported"},
+		{"malformed patch", "/api/v1/users/" + userID(1), "application/json-patch+json", `{"op":"add"}`, http.StatusBadRequest, "patch_invalid"},
+		{"read-only field", "/api/v1/users/" + userID(1), "application/json-patch+json", `[{"op":"replace","path":"/id","value":7}]`, http.StatusBadRequest, "patch_path_not_allowed"},
+		{"read-only merge", "/api/v1/users/" + userID(1), "application/merge-patch+json", `{"created_at":"2020-01-01T00:00:00Z"}`, http.StatusBadRequest, "patch_path_not_allowed"},
+		{"failed test", "/api/v1/users/" + userID(1), "application/json-patch+json", `[{"op":"test","path":"/updated_at","value":"2020-01-01T00:00:00Z"}]`, http.StatusConflict, "patch_conflict"},
+		{"invalid result", "/api/v1/users/" + userID(1), "application/merge-patch+json", `{"email":null}`, http.StatusBadRequest, "invalid_request"},
+		{"wrong type", "/api/v1/users/" + userID(1), "application/merge-patch+json", `{"is_active":"yes"}`, http.StatusBadRequest, "invalid_request"},
+		{"taken username", "/api/v1/users/" + userID(1), "application/merge-patch+json", `{"username":"user001"}`, http.StatusConflict, "user_username_taken"},
+		{"missing user", "/api/v1/users/" + userID(99), "application/merge-patch+json", `{"username":"joan"}`, http.StatusNotFound, "user_not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performPatch(router, tt.path, tt.contentType, tt.body)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+
+			var body ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.code, body.Code)
+		})
+	}
+
+	var body ErrorResponse
+	w := performPatch(router, "/api/v1/users/"+userID(1), "application/merge-patch+json", `{"email":null}`)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Violations, 1)
+	assert.Equal(t, "email", body.Violations[0].Field)
+}
+
+func TestUsers_ListPaginates(t *testing.T) {
+	router := setupUsersTest(t)
+	createUsers(t, router, 5)
+
+	w := perform(router, "GET", "/api/v1/users?limit=2&offset=2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page UserListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 5, page.Total)
+	assert.Equal(t, 2, page.Limit)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, userID(3), page.Items[0].ID)
+}
+
+func TestUsers_ListCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	cursors, err := pagination.NewCursors([]byte("test-key"), pagination.CursorOptions{})
+	require.NoError(t, err)
+	repo := memory.NewUserRepository(&sequentialIDs{})
+	router := gin.New()
+	NewUserHandler(users.NewService(repo), log).WithCursors(cursors).Register(router.Group("/api/v1"))
+	createUsers(t, router, 5)
+
+	var first UserListResponse
+	w := perform(router, "GET", "/api/v1/users?limit=2", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	require.NotEmpty(t, first.NextCursor)
+
+	// Deleting a listed user does not shift the next page
+	require.Equal(t, http.StatusNoContent, perform(router, "DELETE", "/api/v1/users/"+userID(1), "").Code)
+
+	var second UserListResponse
+	w = perform(router, "GET", "/api/v1/users?limit=2&cursor="+first.NextCursor, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	require.Len(t, second.Items, 2)
+	assert.Equal(t, userID(3), second.Items[0].ID)
+	assert.Equal(t, userID(4), second.Items[1].ID)
+
+	var last UserListResponse
+	w = perform(router, "GET", "/api/v1/users?limit=2&cursor="+second.NextCursor, "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &last))
+	require.Len(t, last.Items, 1)
+	assert.Empty(t, last.NextCursor)
+
+	tampered := []byte(first.NextCursor)
+	tampered[5] ^= 1 // 'A' <-> 'B', 'a' <-> 'b', ...
+	w = perform(router, "GET", "/api/v1/users?cursor="+string(tampered), "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid cursor")
+}
+
+func TestUsers_ListFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	cursors, err := pagination.NewCursors([]byte("test-key"), pagination.CursorOptions{})
+	require.NoError(t, err)
+	router := gin.New()
+	NewUserHandler(users.NewService(memory.NewUserRepository(&sequentialIDs{})), log).WithCursors(cursors).Register(router.Group("/api/v1"))
+	createUsers(t, router, 5)
+	filter := "?filter=" + url.QueryEscape("username=out=(user001,user003)")
+
+	var first UserListResponse
+	w := perform(router, "GET", "/api/v1/users"+filter+"&limit=2", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	assert.Equal(t, 3, first.Total, "the total counts matching users only")
+	require.Len(t, first.Items, 2)
+	assert.Equal(t, userID(1), first.Items[0].ID)
+	assert.Equal(t, userID(3), first.Items[1].ID)
+
+	var second UserListResponse
+	w = perform(router, "GET", "/api/v1/users"+filter+"&limit=2&cursor="+first.NextCursor, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	require.Len(t, second.Items, 1)
+	assert.Equal(t, userID(5), second.Items[0].ID)
+
+	w = perform(router, "GET", "/api/v1/users?limit=2&cursor="+first.NextCursor, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a cursor does not carry over to another filter")
+
+	w = perform(router, "GET", "/api/v1/users?filter="+url.QueryEscape("full_name==Jane"), "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown field \"full_name\"`)
+
+	for expr, want := range map[string]int{"email==u1@example.com": 1, "email==u*": 0} {
+		var byEmail UserListResponse
+		w = perform(router, "GET", "/api/v1/users?filter="+url.QueryEscape(expr), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &byEmail))
+		assert.Equal(t, want, byEmail.Total, "emails match exactly, not by prefix: %s", expr)
+	}
+}
+
+func TestUsers_ExportCSV(t *testing.T) {
+	router := setupUsersTest(t)
+	createUsers(t, router, 150)
+
+	w := perform(router, "GET", "/api/v1/users/export?format=csv", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "attachment; filename=users.csv", w.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 151, "header plus every user across pages")
+	assert.Equal(t, "Email", records[0][1])
+}
+
+func TestUsers_ExportRejectsUnknownFormat(t *testing.T) {
+	router := setupUsersTest(t)
+
+	assert.Equal(t, http.StatusBadRequest, perform(router, "GET", "/api/v1/users/export?format=pdf", "").Code)
+}
+
+func TestUsers_BulkCreatePartialSuccess(t *testing.T) {
+	router := setupUsersTest(t)
+
+	body := `{"items":[
+		{"email":"a@example.com","username":"alice"},
+		{"email":"not-an-email","username":"bob"},
+		{"email":"A@example.com","username":"alice2"}
+	]}`
+	w := perform(router, "POST", "/api/v1/users/bulk", body)
+	require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+
+	var resp BulkResponse[UserResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 2, resp.Failed)
+	require.Len(t, resp.Results, 3)
+
+	assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+	require.NotNil(t, resp.Results[0].Data)
+	assert.Equal(t, "alice", resp.Results[0].Data.Username)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Results[1].Status)
+	assert.Equal(t, "invalid_request", resp.Results[1].Error.Error)
+
+	assert.Equal(t, 2, resp.Results[2].Index)
+	assert.Equal(t, http.StatusConflict, resp.Results[2].Status)
+}
+
+func TestUsers_BulkCreateAllSucceeded(t *testing.T) {
+	router := setupUsersTest(t)
+
+	w := perform(router, "POST", "/api/v1/users/bulk",
+		`{"items":[{"email":"a@example.com","username":"alice"},{"email":"b@example.com","username":"bob"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = perform(router, "GET", "/api/v1/users", "")
+	var page UserListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 2, page.Total)
+}
+
+func TestUsers_BulkCreateRejectsEnvelope(t *testing.T) {
+	router := setupUsersTest(t)
+
+	assert.Equal(t, http.StatusBadRequest, perform(router, "POST", "/api/v1/users/bulk", `{"items":[]}`).Code)
+
+	items := make([]string, MaxBulkItems+1)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"email":"u%d@example.com","username":"user%03d"}`, i, i)
+	}
+	body := `{"items":[` + strings.Join(items, ",") + `]}`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, perform(router, "POST", "/api/v1/users/bulk", body).Code)
+}
+
+// setupUsersTest creates a router with the users routes over an in-memory repository.
+func setupUsersTest(t testing.TB) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	handler := NewUserHandler(users.NewService(memory.NewUserRepository(&sequentialIDs{})), log)
+	handler.Register(router.Group("/api/v1"))
+	return router
+}
+
+// sequentialIDs makes the IDs 1, 2, 3... in their last byte, so tests can
+// address the users they created in order.
+type sequentialIDs struct{ last byte }
+
+func (s *sequentialIDs) Generate() ids.Raw {
+	s.last++
+	return ids.Raw{15: s.last}
+}
+
+// userID returns the ID of the n-th user created by a test.
+func userID(n byte) string { return users.UserID{15: n}.String() }
+
+// createUsers registers n users through the API.
+func createUsers(t testing.TB, router *gin.Engine, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		body := fmt.Sprintf(`{"email":"u%d@example.com","username":"user%03d"}`, i, i)
+		require.Equal(t, http.StatusCreated, perform(router, "POST", "/api/v1/users", body).Code)
+	}
+}
+
+// performPatch sends a PATCH request with a body of contentType.
+func performPatch(router http.Handler, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// perform sends a request with an optional JSON body.
+func perform(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/luminosita/change-me/pkg/sanitize"
+	"github.com/luminosita/change-me/pkg/validation"
+)
+
+// Register the shared custom tags (phone, address, currency, public_url,
+// see pkg/validation) with the request binding validator, and sanitize
+// bound requests before they are validated.
+var _ = applyValidators()
+
+// applyValidators registers the shared tags on gin's validator engine and
+// wraps it with sanitization.
+func applyValidators() error {
+	binding.Validator = sanitizingValidator{binding.Validator}
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+	return validation.Apply(v)
+}
+
+// sanitizingValidator cleans the fields tagged sanitize (see pkg/sanitize)
+// of bound requests, so validation sees the stored values.
+type sanitizingValidator struct {
+	binding.StructValidator
+}
+
+// ValidateStruct sanitizes obj in place when it is a pointer, then
+// validates it.
+func (v sanitizingValidator) ValidateStruct(obj any) error {
+	if rv := reflect.ValueOf(obj); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		if err := sanitize.Struct(obj); err != nil {
+			return err
+		}
+	}
+	return v.StructValidator.ValidateStruct(obj)
+}
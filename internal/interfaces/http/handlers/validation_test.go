@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindingValidator_SharedTags(t *testing.T) {
+	type request struct {
+		Phone   string `binding:"omitempty,phone"`
+		Webhook string `binding:"omitempty,public_url"`
+	}
+
+	assert.NoError(t, binding.Validator.ValidateStruct(&request{Phone: "+1 415 555 0100", Webhook: "https://hooks.example.com"}))
+	assert.Error(t, binding.Validator.ValidateStruct(&request{Phone: "555-0100"}))
+	assert.Error(t, binding.Validator.ValidateStruct(&request{Webhook: "http://localhost/hook"}))
+}
+
+func TestBindingValidator_SanitizesBeforeValidation(t *testing.T) {
+	type request struct {
+		Name string `binding:"required,max=4" sanitize:"text"`
+	}
+
+	req := request{Name: " <b>Jane</b>\x00 "}
+	assert.NoError(t, binding.Validator.ValidateStruct(&req))
+	assert.Equal(t, "Jane", req.Name)
+	assert.Error(t, binding.Validator.ValidateStruct(&request{Name: "<script>x</script>"}), "empty after sanitization")
+}
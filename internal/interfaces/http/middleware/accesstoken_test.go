@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/accesstoken"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessToken_SetsPrincipal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	signer, err := accesstoken.New([]byte(strings.Repeat("k", accesstoken.MinKeyLen)), accesstoken.Options{TTL: time.Minute, Issuer: "api"})
+	require.NoError(t, err)
+	foreign, err := accesstoken.New([]byte(strings.Repeat("f", accesstoken.MinKeyLen)), accesstoken.Options{Issuer: "api"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ctx := reqctx.With(c.Request.Context(), &reqctx.RequestContext{Principal: c.GetHeader("X-Principal"), RequestID: "req-1"})
+		c.Request = c.Request.WithContext(ctx)
+	})
+	router.Use(AccessToken(signer))
+	router.GET("/me", func(c *gin.Context) {
+		rc := reqctx.From(c.Request.Context())
+		c.String(http.StatusOK, rc.Principal+"/"+rc.RequestID)
+	})
+
+	token, _, err := signer.IssueAccess(context.Background(), "jane")
+	require.NoError(t, err)
+	forged, _, err := foreign.IssueAccess(context.Background(), "jane")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+		body   string
+	}{
+		{"access token", http.Header{"Authorization": {"Bearer " + token}}, http.StatusOK, "jane/req-1"},
+		{"api key", http.Header{"X-Principal": {"key-1"}}, http.StatusOK, "key-1/req-1"},
+		{"anonymous", nil, http.StatusOK, "/req-1"},
+		{"forged", http.Header{"Authorization": {"Bearer " + forged}}, http.StatusUnauthorized, `"code":"access_token_invalid"`},
+		{"malformed", http.Header{"Authorization": {"Bearer nope"}}, http.StatusUnauthorized, `"code":"access_token_invalid"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header = tt.header.Clone()
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.body)
+			if tt.status == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "invalid_token")
+			}
+		})
+	}
+}
+
+func TestAccessTokenPrincipal_FallsBack(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	signer, err := accesstoken.New([]byte(strings.Repeat("k", accesstoken.MinKeyLen)), accesstoken.Options{})
+	require.NoError(t, err)
+	token, _, err := signer.IssueAccess(context.Background(), "jane")
+	require.NoError(t, err)
+	principal := AccessTokenPrincipal(signer, func(c *gin.Context) string { return c.GetHeader("X-Principal") })
+
+	for authorization, want := range map[string]string{"Bearer " + token: "jane", "Bearer admin-token": "key-1", "": "key-1"} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Authorization", authorization)
+		c.Request.Header.Set("X-Principal", "key-1")
+
+		assert.Equal(t, want, principal(c), authorization)
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth returns a middleware that requires "Authorization: Bearer <token>"
+// on administrative routes.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasAdminToken(c, token) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"code":    "unauthorized",
+				"message": "valid admin token required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasAdminToken reports whether the request carries the admin token as
+// "Authorization: Bearer <token>". It is false when token is empty.
+func HasAdminToken(c *gin.Context, token string) bool {
+	got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
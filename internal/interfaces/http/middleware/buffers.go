@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize caps the capacity of the buffers kept for reuse, so
+// an occasional large response is not pinned in memory by the pools.
+const maxPooledBufferSize = 1 << 20
+
+// bufferPool recycles the request bodies captured by the middleware.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// getBuffer returns an empty pooled buffer.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns b to the pool; its contents must no longer be used.
+func putBuffer(b *bytes.Buffer) {
+	if !reusable(b) {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// reusable reports whether b is small enough to be pooled.
+func reusable(b *bytes.Buffer) bool {
+	return b.Cap() <= maxPooledBufferSize
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pii"
+	"github.com/luminosita/change-me/pkg/recording"
+	"github.com/stretchr/testify/require"
+)
+
+// BenchmarkHotPath reports the allocations the logging and body-capturing
+// middleware add to a 1 KiB JSON response; the logger is at error level
+// like 2xx requests mapped to debug by LOG_REQUEST_LEVELS in production,
+// except for logger_info, which writes every entry:
+//
+//	go test -run '^$' -bench HotPath -benchmem ./internal/interfaces/http/middleware/
+func BenchmarkHotPath(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	log := newMiddlewareTestLogger(b)
+	infoLog, err := logger.New(logger.Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Writer: io.Discard, Format: "json", Level: "INFO"}},
+	})
+	require.NoError(b, err)
+	body := []byte(`{"items":[` + strings.Repeat(`{"email":"jane@example.com","name":"Jane"},`, 23) + `{}]}`)
+
+	benchmarks := []struct {
+		name       string
+		middleware gin.HandlerFunc
+	}{
+		{"none", func(c *gin.Context) { c.Next() }},
+		{"logger", Logger(log, LoggerConfig{SkipPaths: []string{"/health", "/metrics", "/health/*"}})},
+		{"logger_info", Logger(infoLog, LoggerConfig{Headers: []string{"User-Agent", "Authorization"}})},
+		{"dedup", Dedup(DedupConfig{})},
+		{"pii_mask", MaskPII(PIIMaskConfig{Fields: map[string]pii.Kind{"email": pii.Email}}, log)},
+		{"recorder", Recorder(RecorderConfig{Store: recording.NewMemoryStore(16)}, log)},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			router := gin.New()
+			router.Use(bm.middleware)
+			router.GET("/api/v1/items", func(c *gin.Context) {
+				c.Data(http.StatusOK, "application/json", body)
+			})
+			b.ReportAllocs()
+
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/items", nil)
+				req.Header.Set("User-Agent", "bench/1.0")
+				router.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		})
+	}
+}
+
+// BenchmarkRouteSet measures the route matching run by most middleware.
+func BenchmarkRouteSet(b *testing.B) {
+	routes := newRouteSet([]string{"* /health", "GET /api/v1/users/:id", "* /api/v1/consent/*"})
+	b.ReportAllocs()
+
+	for b.Loop() {
+		routes.contains(http.MethodPost, "/api/v1/privacy/requests/:id/export")
+	}
+}
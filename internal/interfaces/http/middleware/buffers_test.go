@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/pii"
+	"github.com/luminosita/change-me/pkg/recording"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPooledWriters_AreRestoredAndReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := newMiddlewareTestLogger(t)
+	store := recording.NewMemoryStore(8)
+
+	tests := []struct {
+		name       string
+		middleware gin.HandlerFunc
+	}{
+		{"dedup", Dedup(DedupConfig{})},
+		{"pii_mask", MaskPII(PIIMaskConfig{Fields: map[string]pii.Kind{"email": pii.Email}}, log)},
+		{"recorder", Recorder(RecorderConfig{Store: store}, log)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			var outer gin.ResponseWriter
+			router.Use(func(c *gin.Context) {
+				outer = c.Writer
+				c.Next()
+				assert.Same(t, outer, c.Writer, "the writer of outer middleware is restored")
+				assert.Equal(t, http.StatusCreated, c.Writer.Status())
+			})
+			router.Use(tt.middleware)
+			router.GET("/items/:n", func(c *gin.Context) {
+				c.JSON(http.StatusCreated, gin.H{"n": c.Param("n")})
+			})
+
+			for i := range 3 {
+				w := performJSON(router, http.MethodGet, "/items/"+strconv.Itoa(i), "")
+				require.Equal(t, http.StatusCreated, w.Code)
+				assert.JSONEq(t, `{"n":"`+strconv.Itoa(i)+`"}`, w.Body.String(), "reused writers start empty")
+			}
+		})
+	}
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for i, entry := range entries {
+		assert.JSONEq(t, `{"n":"`+strconv.Itoa(i)+`"}`, string(entry.Response.Body), "recordings own their bodies")
+	}
+}
+
+func TestRouteSet(t *testing.T) {
+	routes := newRouteSet([]string{"GET /users/:id", "* /health", "POST /admin/*"})
+
+	assert.True(t, routes.contains(http.MethodGet, "/users/:id"))
+	assert.False(t, routes.contains(http.MethodDelete, "/users/:id"))
+	assert.True(t, routes.contains(http.MethodPatch, "/health"))
+	assert.True(t, routes.contains(http.MethodPost, "/admin/users"))
+	assert.True(t, routes.contains(http.MethodPost, "/admin"), "prefixes match their root")
+	assert.False(t, routes.contains(http.MethodPost, "/administrators"))
+	assert.False(t, routes.contains(http.MethodGet, "/admin/users"))
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/pkg/captcha"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// DefaultCaptchaHeader carries the CAPTCHA response token of a request.
+const DefaultCaptchaHeader = "X-Captcha-Token"
+
+// Error codes of the captcha middleware.
+var (
+	codeCaptchaRequired = apperrors.Register(apperrors.Entry{
+		Code:        "captcha_required",
+		Kind:        apperrors.KindInvalid,
+		Status:      http.StatusBadRequest,
+		Description: "The endpoint requires a CAPTCHA response token.",
+	})
+	codeCaptchaFailed = apperrors.Register(apperrors.Entry{
+		Code:        "captcha_failed",
+		Kind:        apperrors.KindForbidden,
+		Status:      http.StatusForbidden,
+		Description: "The CAPTCHA response token is invalid, expired or was already used.",
+	})
+	codeCaptchaUnavailable = apperrors.Register(apperrors.Entry{
+		Code:        "captcha_unavailable",
+		Kind:        apperrors.KindInternal,
+		Status:      http.StatusServiceUnavailable,
+		Description: "The CAPTCHA provider could not be reached; retry later.",
+	})
+)
+
+// CaptchaConfig configures the Captcha middleware.
+type CaptchaConfig struct {
+	// Routes requiring a token as "METHOD /route/template"; * matches any
+	// method and a trailing /* every path below the prefix
+	Routes []string
+
+	Header   string // Header carrying the token (default DefaultCaptchaHeader)
+	FailOpen bool   // Admit requests while the provider is unavailable
+}
+
+// Captcha returns a middleware that verifies the CAPTCHA token of requests
+// to the configured routes with verifier, rejecting missing tokens with
+// 400 and unsolved ones with 403. When the provider cannot be reached,
+// requests are rejected with 503 unless cfg.FailOpen is set.
+func Captcha(verifier *captcha.Verifier, cfg CaptchaConfig, log *logger.Logger) gin.HandlerFunc {
+	if cfg.Header == "" {
+		cfg.Header = DefaultCaptchaHeader
+	}
+	routes := newRouteSet(cfg.Routes)
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		if !routes.contains(c.Request.Method, route) {
+			c.Next()
+			return
+		}
+
+		token := c.GetHeader(cfg.Header)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   string(apperrors.KindInvalid),
+				"code":    codeCaptchaRequired,
+				"message": cfg.Header + " header required",
+			})
+			return
+		}
+
+		// ClientIP only honours forwarding headers sent by TRUSTED_PROXIES,
+		// so callers cannot choose the address the provider checks
+		ok, err := verifier.Verify(c.Request.Context(), token, c.ClientIP())
+		switch {
+		case err != nil && cfg.FailOpen:
+			log.Warnw("captcha_unavailable", "route", route, "fail_open", true, "error", err)
+			c.Next()
+		case err != nil:
+			log.Errorw("captcha_unavailable", "route", route, "error", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":   string(apperrors.KindInternal),
+				"code":    codeCaptchaUnavailable,
+				"message": "captcha verification unavailable",
+			})
+		case !ok:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   string(apperrors.KindForbidden),
+				"code":    codeCaptchaFailed,
+				"message": "captcha verification failed",
+			})
+		default:
+			c.Next()
+		}
+	}
+}
+
+// routeSet matches requests against "METHOD /route/template" patterns.
+// Lookups do not allocate, as most middleware run them on every request.
+type routeSet struct {
+	exact    map[routeKey]struct{}
+	prefixes []routeKey // Routes keep the trailing / of their pattern
+}
+
+// routeKey is a parsed pattern.
+type routeKey struct {
+	method, route string
+}
+
+// newRouteSet indexes patterns, keeping prefix patterns without the *.
+func newRouteSet(patterns []string) routeSet {
+	s := routeSet{exact: make(map[routeKey]struct{}, len(patterns))}
+	for _, p := range patterns {
+		method, route, _ := strings.Cut(p, " ")
+		if strings.HasSuffix(route, "/*") {
+			s.prefixes = append(s.prefixes, routeKey{method, strings.TrimSuffix(route, "*")})
+			continue
+		}
+		s.exact[routeKey{method, route}] = struct{}{}
+	}
+	return s
+}
+
+// contains reports whether a request to route matches a pattern.
+func (s routeSet) contains(method, route string) bool {
+	if _, ok := s.exact[routeKey{method, route}]; ok {
+		return true
+	}
+	if _, ok := s.exact[routeKey{"*", route}]; ok {
+		return true
+	}
+	for _, p := range s.prefixes {
+		if (p.method == "*" || p.method == method) &&
+			(strings.HasPrefix(route, p.route) || (len(route)+1 == len(p.route) && strings.HasPrefix(p.route, route))) {
+			return true
+		}
+	}
+	return false
+}
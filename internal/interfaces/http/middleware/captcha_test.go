@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/captcha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptcha_ProtectsConfiguredRoutes(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("response") == "solved" {
+			_, _ = w.Write([]byte(`{"success":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":false}`))
+	}))
+	defer provider.Close()
+
+	router := newCaptchaTestRouter(t, provider, CaptchaConfig{Routes: []string{"POST /users", "* /auth/*"}})
+
+	assert.Equal(t, http.StatusOK, performCaptcha(router, http.MethodGet, "/users", "").Code, "other methods are unprotected")
+
+	w := performCaptcha(router, http.MethodPost, "/users", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "captcha_required")
+
+	w = performCaptcha(router, http.MethodPost, "/users", "forged")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "captcha_failed")
+
+	assert.Equal(t, http.StatusOK, performCaptcha(router, http.MethodPost, "/users", "solved").Code)
+	assert.Equal(t, http.StatusForbidden, performCaptcha(router, http.MethodPost, "/auth/sessions", "forged").Code, "prefix routes are protected")
+}
+
+func TestCaptcha_ProviderUnavailable(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer provider.Close()
+
+	closed := newCaptchaTestRouter(t, provider, CaptchaConfig{Routes: []string{"POST /users"}})
+	w := performCaptcha(closed, http.MethodPost, "/users", "solved")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "captcha_unavailable")
+
+	open := newCaptchaTestRouter(t, provider, CaptchaConfig{Routes: []string{"POST /users"}, FailOpen: true})
+	assert.Equal(t, http.StatusOK, performCaptcha(open, http.MethodPost, "/users", "solved").Code)
+}
+
+// newCaptchaTestRouter serves /users and /auth/sessions behind the
+// captcha middleware verifying tokens with provider.
+func newCaptchaTestRouter(t *testing.T, provider *httptest.Server, cfg CaptchaConfig) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	verifier, err := captcha.New(captcha.Config{Provider: captcha.Turnstile, Secret: "s3cret", VerifyURL: provider.URL}, provider.Client())
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Captcha(verifier, cfg, newMiddlewareTestLogger(t)))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/users", ok)
+	router.POST("/users", ok)
+	router.POST("/auth/sessions", ok)
+	return router
+}
+
+// performCaptcha sends a request carrying token in the captcha header.
+func performCaptcha(router http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set(DefaultCaptchaHeader, token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
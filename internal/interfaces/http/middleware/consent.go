@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/consent"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// ConsentConfig configures the RequireConsent middleware.
+type ConsentConfig struct {
+	// Routes requiring consent as "METHOD /route/template"; * matches any
+	// method and a trailing /* every path below the prefix
+	Routes []string
+
+	// Policies whose latest version must be accepted; policies that were
+	// never published are not required
+	Policies []string
+}
+
+// RequireConsent returns a middleware that admits requests to the
+// configured routes only from principals who accepted the latest version
+// of the configured policies. Anonymous requests are rejected with 401 and
+// principals with missing acceptances with 403 listing the policies to
+// accept.
+func RequireConsent(service *consent.Service, cfg ConsentConfig, log *logger.Logger) gin.HandlerFunc {
+	routes := newRouteSet(cfg.Routes)
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		if !routes.contains(c.Request.Method, route) {
+			c.Next()
+			return
+		}
+
+		principal := reqctx.Principal(c.Request.Context())
+		if principal == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   string(apperrors.KindUnauthorized),
+				"code":    string(apperrors.KindUnauthorized),
+				"message": "authenticated principal required",
+			})
+			return
+		}
+
+		missing, err := service.Missing(c.Request.Context(), principal, cfg.Policies)
+		if err != nil {
+			log.Errorw("consent_check_failed", "route", route, "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":   string(apperrors.KindInternal),
+				"code":    string(apperrors.KindInternal),
+				"message": "internal server error",
+			})
+			return
+		}
+		if len(missing) > 0 {
+			policies := make([]gin.H, len(missing))
+			for i, p := range missing {
+				policies[i] = gin.H{"name": p.Name, "version": p.Version}
+				if p.URL != "" {
+					policies[i]["url"] = p.URL
+				}
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":    string(apperrors.KindForbidden),
+				"code":     apperrors.CodeOf(consent.ErrConsentRequired),
+				"message":  consent.ErrConsentRequired.Error(),
+				"policies": policies,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/consent"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupConsentTest(t *testing.T) (*gin.Engine, *consent.Service) {
+	gin.SetMode(gin.TestMode)
+	service := consent.NewService(memory.NewConsentRepository())
+	_, err := service.Publish(context.Background(), consent.Policy{Name: "terms", Version: "v1", URL: "https://example.com/terms"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ctx := reqctx.With(c.Request.Context(), &reqctx.RequestContext{Principal: c.GetHeader("X-Principal")})
+		c.Request = c.Request.WithContext(ctx)
+	})
+	router.Use(RequireConsent(service, ConsentConfig{
+		Routes:   []string{"POST /orders"},
+		Policies: []string{"terms", "unpublished"},
+	}, newMiddlewareTestLogger(t)))
+	router.POST("/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, service
+}
+
+func performAs(router *gin.Engine, method, path, principal string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if principal != "" {
+		req.Header.Set("X-Principal", principal)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireConsent_RejectsMissingAcceptance(t *testing.T) {
+	router, service := setupConsentTest(t)
+
+	w := performAs(router, http.MethodPost, "/orders", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = performAs(router, http.MethodPost, "/orders", "jane")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{
+		"error": "forbidden",
+		"code": "consent_required",
+		"message": "acceptance of the latest policy versions required",
+		"policies": [{"name": "terms", "version": "v1", "url": "https://example.com/terms"}]
+	}`, w.Body.String())
+
+	_, err := service.Accept(context.Background(), "jane", "terms", "v1", consent.Evidence{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, performAs(router, http.MethodPost, "/orders", "jane").Code)
+
+	// A new version requires accepting it again
+	_, err = service.Publish(context.Background(), consent.Policy{Name: "terms", Version: "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, performAs(router, http.MethodPost, "/orders", "jane").Code)
+}
+
+func TestRequireConsent_SkipsOtherRoutes(t *testing.T) {
+	router, _ := setupConsentTest(t)
+	assert.Equal(t, http.StatusOK, performAs(router, http.MethodGet, "/orders", "").Code)
+}
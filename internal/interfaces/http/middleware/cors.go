@@ -1,35 +1,100 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/luminosita/change-me/internal/core/constants"
 )
 
-// CORS returns a CORS middleware configured for development.
-// Allows frontend applications on localhost to access the API.
-func CORS() gin.HandlerFunc {
+// CORSConfig configures the CORS middleware. Zero values fall back to the
+// development defaults in constants.
+type CORSConfig struct {
+	AllowOrigins  []string      // Allowed origins, sent credentials; "*" allows any origin without them
+	AllowMethods  []string      // Methods allowed in preflight responses
+	AllowHeaders  []string      // Request headers allowed; "*" echoes the requested headers
+	ExposeHeaders []string      // Response headers readable by browser scripts
+	MaxAge        time.Duration // How long browsers may cache preflight results (0 = not sent)
+}
+
+// CORS returns a CORS middleware.
+// Allows frontend applications on the configured origins to access the API.
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	origins := cfg.AllowOrigins
+	if len(origins) == 0 {
+		origins = constants.CORSAllowOrigins
+	}
+	methods := cfg.AllowMethods
+	if len(methods) == 0 {
+		methods = constants.CORSAllowMethods
+	}
+	headers := cfg.AllowHeaders
+	if len(headers) == 0 {
+		headers = constants.CORSAllowHeaders
+	}
+
+	allowAny := false
+	allowed := make(map[string]struct{}, len(origins))
+	for _, o := range origins {
+		if o == "*" {
+			allowAny = true
+		}
+		allowed[o] = struct{}{}
+	}
+
+	wildcardHeaders := len(headers) == 1 && headers[0] == "*"
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(cfg.ExposeHeaders, ", ")
+	maxAge := ""
+	if cfg.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
 	return func(c *gin.Context) {
+		h := c.Writer.Header()
 		origin := c.Request.Header.Get("Origin")
 
-		// Check if origin is allowed
-		for _, allowed := range constants.CORSAllowOrigins {
-			if origin == allowed {
-				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-				break
-			}
+		// Responses differ per origin, so caches must key on it
+		h.Add("Vary", "Origin")
+
+		// Only explicitly listed origins may send credentials; "*" lets any
+		// other origin read responses without them
+		if _, ok := allowed[origin]; origin != "" && ok {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+		} else if allowAny {
+			h.Set("Access-Control-Allow-Origin", "*")
 		}
 
 		// Set other CORS headers
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "*")
+		h.Set("Access-Control-Allow-Methods", allowMethods)
+		if exposeHeaders != "" {
+			h.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
 
 		// Handle preflight requests
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if c.Request.Method == http.MethodOptions {
+			requested := c.Request.Header.Get("Access-Control-Request-Headers")
+			if wildcardHeaders && requested != "" {
+				// "*" is not a wildcard for credentialed requests; echo instead
+				h.Set("Access-Control-Allow-Headers", requested)
+			} else {
+				h.Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if maxAge != "" {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
+		h.Set("Access-Control-Allow-Headers", allowHeaders)
 		c.Next()
 	}
 }
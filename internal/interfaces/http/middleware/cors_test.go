@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS_PreflightCachingAndVary(t *testing.T) {
+	router := setupCORSTest(CORSConfig{
+		AllowOrigins: []string{"https://app.example.com"},
+		MaxAge:       10 * time.Minute,
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/ping", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-API-Key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "Content-Type, X-API-Key", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.ElementsMatch(t,
+		[]string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		w.Header().Values("Vary"))
+}
+
+func TestCORS_ExposeHeadersAndUnknownOrigin(t *testing.T) {
+	router := setupCORSTest(CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com"},
+		ExposeHeaders: []string{"X-Quota-Remaining", "Retry-After"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "X-Quota-Remaining, Retry-After", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORS_AnyOriginWithoutCredentials(t *testing.T) {
+	router := setupCORSTest(CORSConfig{AllowOrigins: []string{"https://app.example.com", "*"}})
+
+	for origin, want := range map[string][2]string{
+		"https://app.example.com":  {"https://app.example.com", "true"},
+		"https://evil.example.com": {"*", ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want[0], w.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Equal(t, want[1], w.Header().Get("Access-Control-Allow-Credentials"), origin)
+	}
+}
+
+func TestDefaultHeaders_HandlersCanOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(DefaultHeaders(map[string]string{"Server": "change-me", "X-App-Version": "1.2.3"}))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/custom", func(c *gin.Context) {
+		c.Header("Server", "custom")
+		c.Status(http.StatusOK)
+	})
+
+	w := performJSON(router, "GET", "/ping", "")
+	assert.Equal(t, "change-me", w.Header().Get("Server"))
+	assert.Equal(t, "1.2.3", w.Header().Get("X-App-Version"))
+	assert.Equal(t, "custom", performJSON(router, "GET", "/custom", "").Header().Get("Server"))
+}
+
+// setupCORSTest creates a router serving /ping behind the CORS middleware.
+func setupCORSTest(cfg CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(cfg))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultDedupMaxBodyBytes is the largest request body hashed for coalescing.
+const defaultDedupMaxBodyBytes = 64 * 1024
+
+// DedupHeader marks responses that were shared from a coalesced execution.
+const DedupHeader = "X-Dedup"
+
+// defaultDedupVary are the request headers responses commonly vary on.
+var defaultDedupVary = []string{"Accept", "Accept-Encoding", "Accept-Language", "Cookie"}
+
+// DedupConfig configures the request deduplication middleware.
+type DedupConfig struct {
+	Methods      []string                    // Methods eligible for coalescing (default GET, HEAD)
+	Principal    func(c *gin.Context) string // Caller identity (default Authorization and X-API-Key headers)
+	MaxBodyBytes int                         // Requests with larger bodies are never coalesced (default 64 KiB)
+	SkipPaths    []string                    // Paths never coalesced (e.g. /health)
+
+	// Vary lists the request headers responses vary on; only requests
+	// agreeing on all of them are coalesced (default Accept,
+	// Accept-Encoding, Accept-Language and Cookie)
+	Vary []string
+}
+
+// sharedResponse is the captured leader response fanned out to followers.
+type sharedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Dedup returns a middleware that coalesces identical concurrent requests.
+// Requests sharing principal, method, path, query, Vary headers and body
+// hash wait for a single execution and receive a copy of its response.
+func Dedup(cfg DedupConfig) gin.HandlerFunc {
+	methods := cfg.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead}
+	}
+	allowed := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		allowed[m] = struct{}{}
+	}
+
+	principal := cfg.Principal
+	if principal == nil {
+		principal = headerPrincipal
+	}
+
+	vary := cfg.Vary
+	if len(vary) == 0 {
+		vary = defaultDedupVary
+	}
+
+	maxBody := cfg.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = defaultDedupMaxBodyBytes
+	}
+
+	skip := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = struct{}{}
+	}
+
+	var group singleflight.Group
+
+	return func(c *gin.Context) {
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+		if _, ok := allowed[c.Request.Method]; !ok {
+			c.Next()
+			return
+		}
+
+		key, ok := dedupKey(c, principal(c), vary, maxBody)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		leader := false
+		v, _, shared := group.Do(key, func() (any, error) {
+			leader = true
+
+			writer := newBufferedWriter(c.Writer)
+			defer writer.release()
+			c.Writer = writer
+			c.Next()
+			c.Writer = writer.ResponseWriter
+
+			resp := &sharedResponse{
+				status: writer.Status(),
+				header: writer.Header().Clone(),
+				body:   bytes.Clone(writer.body.Bytes()),
+			}
+			writer.flush()
+			return resp, nil
+		})
+		if leader {
+			return
+		}
+
+		resp := v.(*sharedResponse)
+		header := c.Writer.Header()
+		for k, values := range resp.header {
+			header[k] = values
+		}
+		if shared {
+			header.Set(DedupHeader, "shared")
+		}
+		c.Status(resp.status)
+		if c.Request.Method != http.MethodHead {
+			_, _ = c.Writer.Write(resp.body)
+		}
+		c.Abort()
+	}
+}
+
+// dedupKey hashes the request identity. It reports false when the body is
+// too large or unreadable to be coalesced safely.
+func dedupKey(c *gin.Context, principal string, vary []string, maxBody int) (string, bool) {
+	identity := getBuffer()
+	defer putBuffer(identity)
+	for _, part := range [...]string{principal, c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery} {
+		identity.WriteString(part)
+		identity.WriteByte(0)
+	}
+	for _, name := range vary {
+		for _, value := range c.Request.Header.Values(name) {
+			identity.WriteString(value)
+			identity.WriteByte(',')
+		}
+		identity.WriteByte(0)
+	}
+
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		raw, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBody)+1))
+		rest := c.Request.Body
+		c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(raw), rest), Closer: rest}
+		if err != nil || len(raw) > maxBody {
+			return "", false
+		}
+		identity.Write(raw)
+	}
+
+	sum := sha256.Sum256(identity.Bytes())
+	return hex.EncodeToString(sum[:]), true
+}
+
+// headerPrincipal identifies callers by their credentials headers.
+var headerPrincipal = CredentialPrincipal(nil, "Authorization", "X-API-Key")
+
+// CredentialPrincipal identifies callers by subject, when set, and the raw
+// values of the credential headers, so callers presenting different
+// credentials are never coalesced even when subject cannot tell them apart.
+func CredentialPrincipal(subject func(c *gin.Context) string, headers ...string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		var b strings.Builder
+		if subject != nil {
+			b.WriteString(subject(c))
+		}
+		for _, name := range headers {
+			b.WriteByte(0)
+			b.WriteString(c.GetHeader(name))
+		}
+		return b.String()
+	}
+}
+
+// readCloser pairs a replayed body reader with the original closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
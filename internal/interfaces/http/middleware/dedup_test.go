@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedup_CoalescesConcurrentIdenticalRequests(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	router := setupDedupTest(DedupConfig{}, func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		c.Header("X-Report", "ready")
+		c.JSON(http.StatusOK, gin.H{"report": "done"})
+	})
+
+	const n = 5
+	results := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = performJSON(router, "GET", "/report?period=2024", "")
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	shared := 0
+	for _, w := range results {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"report":"done"}`, w.Body.String())
+		assert.Equal(t, "ready", w.Header().Get("X-Report"))
+		if w.Header().Get(DedupHeader) == "shared" {
+			shared++
+		}
+	}
+	assert.Equal(t, n-1, shared)
+}
+
+func TestDedup_SeparatesPrincipals(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	router := setupDedupTest(DedupConfig{}, func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		c.String(http.StatusOK, c.GetHeader("Authorization"))
+	})
+
+	var wg sync.WaitGroup
+	for _, token := range []string{"Bearer a", "Bearer b"} {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/report", nil)
+			req.Header.Set("Authorization", token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, token, w.Body.String())
+		}(token)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestDedup_SeparatesVaryingRequests(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	router := setupDedupTest(DedupConfig{}, func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		c.String(http.StatusOK, c.GetHeader("Accept")+c.GetHeader("Accept-Encoding")+c.GetHeader("Cookie"))
+	})
+
+	variants := []map[string]string{
+		{"Accept": "application/json"},
+		{"Accept": "text/csv"},
+		{"Accept-Encoding": "gzip"},
+		{"Cookie": "session=a"},
+	}
+	var wg sync.WaitGroup
+	for _, headers := range variants {
+		wg.Add(1)
+		go func(headers map[string]string) {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/report", nil)
+			var want string
+			for k, v := range headers {
+				req.Header.Set(k, v)
+				want += v
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, want, w.Body.String())
+		}(headers)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(len(variants)), atomic.LoadInt32(&calls))
+}
+
+func TestDedup_IgnoresUnlistedMethods(t *testing.T) {
+	var calls int32
+	router := setupDedupTest(DedupConfig{}, func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		body, _ := c.GetRawData()
+		c.String(http.StatusOK, string(body))
+	})
+
+	w := performJSON(router, "POST", "/report", `{"a":1}`)
+
+	assert.Equal(t, `{"a":1}`, w.Body.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestDedup_PreservesBodyForHandlers(t *testing.T) {
+	router := setupDedupTest(DedupConfig{Methods: []string{http.MethodPost}, MaxBodyBytes: 4}, func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		c.String(http.StatusOK, string(body))
+	})
+
+	assert.Equal(t, "abc", performJSON(router, "POST", "/report", "abc").Body.String())
+	assert.Equal(t, "too large", performJSON(router, "POST", "/report", "too large").Body.String())
+}
+
+// setupDedupTest creates a router serving /report behind the dedup middleware.
+func TestDedup_CredentialPrincipalSeparatesCustomHeader(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	router := setupDedupTest(DedupConfig{
+		Principal: CredentialPrincipal(func(*gin.Context) string { return "" }, "Authorization", "X-Client-Key"),
+	}, func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		c.String(http.StatusOK, c.GetHeader("X-Client-Key"))
+	})
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"key-a", "key-b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/report", nil)
+			req.Header.Set("X-Client-Key", key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, key, w.Body.String())
+			assert.Empty(t, w.Header().Get(DedupHeader))
+		}(key)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func setupDedupTest(cfg DedupConfig, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Dedup(cfg))
+	router.Any("/report", handler)
+	return router
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointAuth_OpenWithoutConfig(t *testing.T) {
+	router := setupEndpointAuthTest(EndpointAuthConfig{})
+
+	w := serveEndpointAuth(router, "192.0.2.1:1234", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestEndpointAuth_Credentials(t *testing.T) {
+	router := setupEndpointAuthTest(EndpointAuthConfig{
+		BasicUser:     "prometheus",
+		BasicPassword: "secret",
+		BearerToken:   "token-1",
+	})
+
+	tests := []struct {
+		name   string
+		auth   func(r *http.Request)
+		status int
+	}{
+		{"missing", nil, http.StatusUnauthorized},
+		{"bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer token-1") }, http.StatusOK},
+		{"wrong bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"basic", func(r *http.Request) { r.SetBasicAuth("prometheus", "secret") }, http.StatusOK},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("prometheus", "nope") }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveEndpointAuth(router, "192.0.2.1:1234", tt.auth)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.Contains(t, w.Body.String(), `"code":"unauthorized"`)
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+			}
+		})
+	}
+}
+
+func TestEndpointAuth_AllowCIDRs(t *testing.T) {
+	router := setupEndpointAuthTest(EndpointAuthConfig{AllowCIDRs: []string{"10.0.0.0/8", "::1/128"}})
+
+	assert.Equal(t, http.StatusOK, serveEndpointAuth(router, "10.1.2.3:1234", nil).Code)
+	assert.Equal(t, http.StatusOK, serveEndpointAuth(router, "[::1]:1234", nil).Code)
+
+	w := serveEndpointAuth(router, "192.0.2.1:1234", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"forbidden"`)
+}
+
+func TestEndpointAuth_AllowCIDRsBehindProxy(t *testing.T) {
+	router := setupEndpointAuthTest(EndpointAuthConfig{AllowCIDRs: []string{"10.0.0.0/8"}})
+	require.NoError(t, router.SetTrustedProxies([]string{"192.0.2.10"}))
+	forwarded := func(r *http.Request) { r.Header.Set("X-Forwarded-For", "10.1.2.3") }
+
+	assert.Equal(t, http.StatusOK, serveEndpointAuth(router, "192.0.2.10:1234", forwarded).Code)
+	assert.Equal(t, http.StatusForbidden, serveEndpointAuth(router, "203.0.113.9:1234", forwarded).Code,
+		"untrusted peers cannot forward an allowed address")
+}
+
+// setupEndpointAuthTest creates a router serving /metrics behind the middleware.
+func setupEndpointAuthTest(cfg EndpointAuthConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", EndpointAuth(cfg), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return router
+}
+
+// serveEndpointAuth requests /metrics from remoteAddr.
+func serveEndpointAuth(router *gin.Engine, remoteAddr string, auth func(r *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = remoteAddr
+	if auth != nil {
+		auth(req)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultHeaders returns a middleware that sets the given headers on every
+// response before handlers run, so handlers can still override them.
+func DefaultHeaders(headers map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		for name, value := range headers {
+			h.Set(name, value)
+		}
+		c.Next()
+	}
+}
@@ -1,28 +1,125 @@
 package middleware
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reqctx"
 	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+	"go.uber.org/zap/zapcore"
 )
 
-// Logger returns a middleware that logs HTTP requests using structured logging.
-func Logger(log *logger.Logger) gin.HandlerFunc {
+// redactedHeaders are never logged in clear, even when allowlisted.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// LoggerConfig configures the Logger middleware. The zero value logs every
+// request at info level without headers.
+type LoggerConfig struct {
+	// SkipPaths are not logged: exact paths or route templates, or
+	// prefixes with a trailing /* (e.g. /health/*)
+	SkipPaths []string
+
+	// Levels maps status classes (2 for 2xx, ...) to the level requests
+	// are logged at; unmapped classes log at info
+	Levels map[int]zapcore.Level
+
+	// Headers are request headers added to the entry; credentials are
+	// redacted
+	Headers []string
+}
+
+// Logger returns a middleware that logs HTTP requests using structured
+// logging, at the level of their status class.
+func Logger(log *logger.Logger, cfg LoggerConfig) gin.HandlerFunc {
+	patterns := make([]string, len(cfg.SkipPaths))
+	for i, p := range cfg.SkipPaths {
+		patterns[i] = "* " + p
+	}
+	skip := newRouteSet(patterns)
+	headers := make([]string, len(cfg.Headers))
+	for i, h := range cfg.Headers {
+		headers[i] = http.CanonicalHeaderKey(h)
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 
 		// Process request
 		c.Next()
 
+		if skip.contains(c.Request.Method, c.Request.URL.Path) || skip.contains(c.Request.Method, c.FullPath()) {
+			return
+		}
+
 		// Log request details after request completes
-		duration := time.Since(start)
-		log.Infow("http_request",
-			"method", c.Request.Method,
-			"path", c.Request.URL.Path,
-			"status", c.Writer.Status(),
-			"duration_ms", duration.Milliseconds(),
-			"ip", c.ClientIP(),
+		status := c.Writer.Status()
+		level, ok := cfg.Levels[status/100]
+		if !ok {
+			level = zapcore.InfoLevel
+		}
+		ce := log.Zap().Check(level, "http_request")
+		if ce == nil {
+			// Dropped by every output; skip building the entry
+			return
+		}
+		ctx := c.Request.Context()
+		fields := make([]logger.Field, 0, 10)
+		fields = append(fields,
+			logger.String("method", c.Request.Method),
+			logger.String("route", routeTemplate(c)),
+			logger.String("path", c.Request.URL.Path),
+			logger.Int("status", status),
+			logger.Int64("duration_ms", time.Since(start).Milliseconds()),
+			logger.String("ip", c.ClientIP()),
+			logger.String("request_id", reqctx.RequestID(ctx)),
+			logger.String("trace_id", tracecontext.TraceID(ctx)),
 		)
+		if captured := (requestHeaders{header: c.Request.Header, names: headers}); captured.present() {
+			fields = append(fields, logger.Object("headers", captured))
+		}
+		if timing := RequestTiming(c); timing != nil {
+			fields = append(fields, logger.Object("timing", timing))
+		}
+		ce.Write(fields...)
+	}
+}
+
+// requestHeaders encodes the present headers of names, redacting
+// credentials.
+type requestHeaders struct {
+	header http.Header
+	names  []string
+}
+
+// present reports whether any of the headers is set.
+func (h requestHeaders) present() bool {
+	for _, name := range h.names {
+		if h.header.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (h requestHeaders) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, name := range h.names {
+		value := h.header.Get(name)
+		if value == "" {
+			continue
+		}
+		if redactedHeaders[name] {
+			value = "[redacted]"
+		}
+		enc.AddString(name, value)
 	}
+	return nil
 }
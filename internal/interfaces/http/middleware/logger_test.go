@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// newRequestLogRouter returns a router logging requests to the returned
+// buffer as JSON lines.
+func newRequestLogRouter(t *testing.T, cfg LoggerConfig) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	log, err := logger.New(logger.Config{
+		Level:     "DEBUG",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Format: "json", Level: "DEBUG", Writer: &buf}},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Logger(log, cfg))
+	router.GET("/health/live", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	router.GET("/boom", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	return router, &buf
+}
+
+// requestLogs decodes the http_request entries of buf.
+func requestLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		if entry["msg"] == "http_request" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestLogger_LevelsByStatusClass(t *testing.T) {
+	router, buf := newRequestLogRouter(t, LoggerConfig{
+		Levels: map[int]zapcore.Level{2: zapcore.DebugLevel, 5: zapcore.ErrorLevel},
+	})
+
+	performMethod(router, http.MethodGet, "/users/1")
+	performMethod(router, http.MethodGet, "/missing")
+	performMethod(router, http.MethodGet, "/boom")
+
+	entries := requestLogs(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "debug", entries[0]["level"])
+	assert.Equal(t, "/users/:id", entries[0]["route"])
+	assert.Equal(t, "/users/1", entries[0]["path"])
+	assert.Equal(t, "info", entries[1]["level"], "unmapped classes log at info")
+	assert.Equal(t, "error", entries[2]["level"])
+}
+
+func TestLogger_SkipPaths(t *testing.T) {
+	router, buf := newRequestLogRouter(t, LoggerConfig{SkipPaths: []string{"/health/*", "/users/:id"}})
+
+	performMethod(router, http.MethodGet, "/health/live")
+	performMethod(router, http.MethodGet, "/users/1")
+	performMethod(router, http.MethodGet, "/boom")
+
+	entries := requestLogs(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/boom", entries[0]["path"])
+}
+
+func TestLogger_CapturesAllowlistedHeaders(t *testing.T) {
+	router, buf := newRequestLogRouter(t, LoggerConfig{Headers: []string{"user-agent", "Authorization", "X-Absent"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("User-Agent", "probe/1.0")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Referer", "https://example.com")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := requestLogs(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, map[string]any{
+		"User-Agent":    "probe/1.0",
+		"Authorization": "[redacted]",
+	}, entries[0]["headers"])
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/metering"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+)
+
+// MeteringConfig configures the usage metering middleware.
+type MeteringConfig struct {
+	// Subject identifies the verified billed party; requests with an empty
+	// subject are not metered. Defaults to the request context principal.
+	Subject func(c *gin.Context) string
+}
+
+// Metering returns a middleware that records a request event plus request
+// and response byte counts per route template for every metered request.
+// Request bytes are those the handlers read, whatever Content-Length
+// claims.
+func Metering(pipeline *metering.Pipeline, cfg MeteringConfig) gin.HandlerFunc {
+	subjectOf := cfg.Subject
+	if subjectOf == nil {
+		subjectOf = func(c *gin.Context) string { return reqctx.Principal(c.Request.Context()) }
+	}
+
+	return func(c *gin.Context) {
+		subject := subjectOf(c)
+		if subject == "" {
+			c.Next()
+			return
+		}
+
+		body := &countingReader{ReadCloser: c.Request.Body}
+		if c.Request.Body != nil {
+			c.Request.Body = body
+		}
+		c.Next()
+
+		route := routeTemplate(c)
+
+		pipeline.Record(metering.Event{Subject: subject, Kind: metering.KindRequest, Route: route, Quantity: 1})
+		if n := body.n; n > 0 {
+			pipeline.Record(metering.Event{Subject: subject, Kind: metering.KindBytesIn, Route: route, Quantity: n})
+		}
+		if n := c.Writer.Size(); n > 0 {
+			pipeline.Record(metering.Event{Subject: subject, Kind: metering.KindBytesOut, Route: route, Quantity: int64(n)})
+		}
+	}
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
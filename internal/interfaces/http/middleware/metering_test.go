@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/metering"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetering_RecordsRequestAndBytesByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	agg := metering.NewAggregator()
+	pipeline := metering.NewPipeline(agg, metering.Options{}, newMiddlewareTestLogger(t))
+
+	router := gin.New()
+	router.Use(RequestContext(RequestContextConfig{Principal: func(c *gin.Context) string { return c.GetHeader("X-API-Key") }}))
+	router.Use(Metering(pipeline, MeteringConfig{}))
+	router.POST("/items/:id", func(c *gin.Context) {
+		_, _ = io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "created")
+	})
+
+	for _, key := range []string{"key-1", "key-1", ""} {
+		req := httptest.NewRequest("POST", "/items/7", strings.NewReader(`{"a":1}`))
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.NoError(t, pipeline.Close(context.Background()))
+
+	summary := agg.Summary("key-1", metering.Daily)
+	assert.Equal(t, int64(2), summary.Totals[metering.KindRequest])
+	assert.Equal(t, int64(14), summary.Totals[metering.KindBytesIn])
+	assert.Equal(t, int64(14), summary.Totals[metering.KindBytesOut])
+	require.Len(t, summary.Routes, 1)
+	assert.Equal(t, "/items/:id", summary.Routes[0].Route)
+	assert.Zero(t, agg.Summary("", metering.Daily).Totals[metering.KindRequest], "anonymous requests are not metered")
+}
+
+func TestMetering_CountsBytesRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	agg := metering.NewAggregator()
+	pipeline := metering.NewPipeline(agg, metering.Options{}, newMiddlewareTestLogger(t))
+
+	router := gin.New()
+	router.Use(Metering(pipeline, MeteringConfig{Subject: func(*gin.Context) string { return "key-1" }}))
+	router.POST("/read", func(c *gin.Context) { _, _ = io.ReadAll(c.Request.Body) })
+	router.POST("/ignore", func(c *gin.Context) {})
+
+	// A chunked body has no length; a declared length is not trusted
+	chunked := httptest.NewRequest("POST", "/read", strings.NewReader(`{"a":1}`))
+	chunked.ContentLength = -1
+	router.ServeHTTP(httptest.NewRecorder(), chunked)
+	ignored := httptest.NewRequest("POST", "/ignore", strings.NewReader(`{"a":1}`))
+	router.ServeHTTP(httptest.NewRecorder(), ignored)
+	require.NoError(t, pipeline.Close(context.Background()))
+
+	summary := agg.Summary("key-1", metering.Daily)
+	assert.Equal(t, int64(2), summary.Totals[metering.KindRequest])
+	assert.Equal(t, int64(7), summary.Totals[metering.KindBytesIn], "only the bytes read are counted")
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/httpmetrics"
+)
+
+// Metrics returns a middleware that records every finished request by
+// route template, so path parameters do not multiply the series.
+func Metrics(metrics *httpmetrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		done := metrics.Start()
+		defer done()
+
+		c.Next()
+
+		metrics.Observe(c.Request.Method, routeTemplate(c), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// routeTemplate returns the route template the request matched, or
+// httpmetrics.Unmatched, for labels that must not grow with raw paths.
+func routeTemplate(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return httpmetrics.Unmatched
+}
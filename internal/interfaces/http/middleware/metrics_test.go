@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/httpmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_LabelsByRouteTemplate(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	router := gin.New()
+	router.Use(Metrics(httpmetrics.NewMetrics(reg)))
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	performMethod(router, http.MethodGet, "/users/1")
+	performMethod(router, http.MethodGet, "/users/2")
+	performMethod(router, http.MethodGet, "/no/such/route")
+	performMethod(router, http.MethodGet, "/another/miss")
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	counts := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "http_server_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range m.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			counts[labels["route"]+" "+labels["status"]] = m.GetCounter().GetValue()
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"/users/:id 200":               2,
+		httpmetrics.Unmatched + " 404": 2,
+	}, counts)
+}
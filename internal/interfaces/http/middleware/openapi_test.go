@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/api"
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info: {title: test, version: "1.0"}
+paths:
+  /items:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string, minLength: 1}
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: integer}
+`
+
+func TestOpenAPIValidator_RejectsInvalidRequest(t *testing.T) {
+	router := setupOpenAPITest(t, testSpec, constants.OpenAPIValidationReject, false, gin.H{"id": 1})
+
+	w := performJSON(router, http.MethodPost, "/items", `{"name": ""}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "request_validation_failed")
+}
+
+func TestOpenAPIValidator_AcceptsValidRequest(t *testing.T) {
+	router := setupOpenAPITest(t, testSpec, constants.OpenAPIValidationReject, true, gin.H{"id": 1})
+
+	w := performJSON(router, http.MethodPost, "/items", `{"name": "widget"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id": 1}`, w.Body.String())
+}
+
+func TestOpenAPIValidator_LogModePassesInvalidRequest(t *testing.T) {
+	router := setupOpenAPITest(t, testSpec, constants.OpenAPIValidationLog, false, gin.H{"id": 1})
+
+	w := performJSON(router, http.MethodPost, "/items", `{}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestOpenAPIValidator_RejectsInvalidResponse(t *testing.T) {
+	router := setupOpenAPITest(t, testSpec, constants.OpenAPIValidationReject, true, gin.H{"id": "not-a-number"})
+
+	w := performJSON(router, http.MethodPost, "/items", `{"name": "widget"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "response_validation_failed")
+	assert.NotContains(t, w.Body.String(), "not-a-number")
+}
+
+func TestOpenAPIValidator_PassesUnknownRoutes(t *testing.T) {
+	router := setupOpenAPITest(t, testSpec, constants.OpenAPIValidationReject, true, gin.H{"id": 1})
+	router.GET("/undocumented", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	w := performJSON(router, http.MethodGet, "/undocumented", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+}
+
+func TestOpenAPIValidator_WarnsOnceOnUndocumentedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	log, err := logger.New(logger.Config{
+		Level:     "DEBUG",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Format: "json", Level: "DEBUG", Writer: &buf}},
+	})
+	require.NoError(t, err)
+	validator, err := OpenAPIValidator(OpenAPIValidatorConfig{Spec: []byte(testSpec), Mode: constants.OpenAPIValidationLog}, log)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(validator)
+	router.GET("/undocumented/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	performJSON(router, http.MethodGet, "/undocumented/1", "")
+	performJSON(router, http.MethodGet, "/undocumented/2", "")
+	performJSON(router, http.MethodGet, "/unrouted", "")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "openapi_route_undocumented"), buf.String())
+	assert.Contains(t, buf.String(), `"route":"/undocumented/:id"`)
+}
+
+func TestOpenAPIValidator_HealthMatchesPublishedSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator, err := OpenAPIValidator(OpenAPIValidatorConfig{
+		Spec:              api.OpenAPISpec,
+		Mode:              constants.OpenAPIValidationReject,
+		ValidateResponses: true,
+	}, newMiddlewareTestLogger(t))
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(validator)
+	router.GET("/health", handlers.NewHealthHandler("0.1.0").Check)
+
+	w := performJSON(router, http.MethodGet, "/health", "")
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestOpenAPIValidator_InvalidSpec(t *testing.T) {
+	_, err := OpenAPIValidator(OpenAPIValidatorConfig{Spec: []byte("not: [valid")}, newMiddlewareTestLogger(t))
+	assert.Error(t, err)
+}
+
+// setupOpenAPITest creates a router with the validator and a POST /items handler.
+func setupOpenAPITest(t *testing.T, spec, mode string, responses bool, reply gin.H) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	validator, err := OpenAPIValidator(OpenAPIValidatorConfig{
+		Spec:              []byte(spec),
+		Mode:              mode,
+		ValidateResponses: responses,
+	}, newMiddlewareTestLogger(t))
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(validator)
+	router.POST("/items", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, reply)
+	})
+	return router
+}
+
+// performJSON sends a request with an optional JSON body.
+func performJSON(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// newMiddlewareTestLogger creates a quiet logger for middleware tests.
+func newMiddlewareTestLogger(t testing.TB) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
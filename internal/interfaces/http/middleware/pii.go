@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pii"
+)
+
+// codePIIMaskFailed reports a JSON response that could not be masked.
+const codePIIMaskFailed = "pii_mask_failed"
+
+// PIIMaskConfig configures the PII masking middleware.
+type PIIMaskConfig struct {
+	Fields     map[string]pii.Kind       // JSON fields masked, see pii.JSONFields
+	Privileged func(c *gin.Context) bool // Callers served unmasked responses (default: none)
+}
+
+// MaskPII returns a middleware masking the personal data of the JSON
+// responses served to unprivileged callers: string values of the
+// classified fields are masked at any depth. JSON responses are buffered
+// to be masked; other responses pass through, their handlers seeing
+// pii.Masking on the request context. A JSON response that cannot be
+// parsed is replaced by a 500 rather than served unmasked.
+func MaskPII(cfg PIIMaskConfig, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(cfg.Fields) == 0 || (cfg.Privileged != nil && cfg.Privileged(c)) {
+			c.Next()
+			return
+		}
+
+		c.Request = c.Request.WithContext(pii.WithMasking(c.Request.Context()))
+		writer := newMaskingWriter(c.Writer)
+		defer writer.release()
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if !writer.buffered {
+			writer.finish(nil)
+			return
+		}
+		masked, err := pii.MaskJSON(writer.body.Bytes(), cfg.Fields)
+		if err != nil {
+			log.Errorw("pii_mask_failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+			writer.status = http.StatusInternalServerError
+			masked = []byte(`{"error":"internal","code":"` + codePIIMaskFailed + `","message":"internal server error"}`)
+		}
+		writer.finish(masked)
+	}
+}
+
+// isJSON reports whether contentType is a JSON media type.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || mediaType == "application/problem+json"
+}
+
+// maskingWriter buffers JSON responses until finish and passes other
+// responses through. Whether to buffer is decided by the Content-Type
+// set when the body is first written.
+type maskingWriter struct {
+	gin.ResponseWriter
+	status   int
+	decided  bool
+	buffered bool
+	body     bytes.Buffer
+}
+
+// maskingWriters recycles maskingWriter values and their buffers.
+var maskingWriters = sync.Pool{New: func() any { return new(maskingWriter) }}
+
+// newMaskingWriter returns a pooled maskingWriter over w.
+func newMaskingWriter(w gin.ResponseWriter) *maskingWriter {
+	mw := maskingWriters.Get().(*maskingWriter)
+	mw.ResponseWriter = w
+	return mw
+}
+
+// release returns w to the pool once the response is finished.
+func (w *maskingWriter) release() {
+	if !reusable(&w.body) {
+		return
+	}
+	w.ResponseWriter = nil
+	w.status, w.decided, w.buffered = 0, false, false
+	w.body.Reset()
+	maskingWriters.Put(w)
+}
+
+// passThrough reports whether the response goes to the client as written.
+func (w *maskingWriter) passThrough() bool {
+	return w.decided && !w.buffered
+}
+
+// decide selects buffering on the first write, sending the recorded
+// status of responses that pass through.
+func (w *maskingWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	w.buffered = isJSON(w.Header().Get("Content-Type"))
+	if !w.buffered && w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// WriteHeader records the status code until the body is written.
+func (w *maskingWriter) WriteHeader(code int) {
+	if w.passThrough() {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+// WriteHeaderNow is a no-op until the body is written or finish.
+func (w *maskingWriter) WriteHeaderNow() {
+	if w.passThrough() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Write buffers b for JSON responses and writes it through otherwise.
+func (w *maskingWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if w.buffered {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString buffers s for JSON responses and writes it through otherwise.
+func (w *maskingWriter) WriteString(s string) (int, error) {
+	w.decide()
+	if w.buffered {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush flushes responses that pass through; buffered ones are sent by
+// finish.
+func (w *maskingWriter) Flush() {
+	if w.passThrough() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Status returns the status code of the response.
+func (w *maskingWriter) Status() int {
+	if w.passThrough() {
+		return w.ResponseWriter.Status()
+	}
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Size returns the number of body bytes written.
+func (w *maskingWriter) Size() int {
+	if w.buffered {
+		return w.body.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+// Written reports whether a status or body has been written.
+func (w *maskingWriter) Written() bool {
+	return w.status != 0 || w.decided || w.ResponseWriter.Written()
+}
+
+// finish sends the status of responses without a body, or the buffered
+// response with body replaced.
+func (w *maskingWriter) finish(body []byte) {
+	switch {
+	case w.passThrough():
+	case !w.decided:
+		if w.status != 0 {
+			w.ResponseWriter.WriteHeader(w.status)
+			w.ResponseWriter.WriteHeaderNow()
+		}
+	default:
+		w.Header().Del("Content-Length")
+		w.ResponseWriter.WriteHeader(w.Status())
+		_, _ = w.ResponseWriter.Write(body)
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/pii"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPIITest(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MaskPII(PIIMaskConfig{
+		Fields:     map[string]pii.Kind{"email": pii.Email},
+		Privileged: func(c *gin.Context) bool { return HasAdminToken(c, "secret") },
+	}, newMiddlewareTestLogger(t)))
+	router.GET("/users", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"items": []gin.H{{"id": 1, "email": "jane@example.com"}}})
+	})
+	router.GET("/text", func(c *gin.Context) {
+		c.String(http.StatusAccepted, `{"email":"jane@example.com"}`)
+	})
+	router.GET("/broken", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"email":"jane@example.com"`))
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestMaskPII_MasksJSONForUnprivilegedCallers(t *testing.T) {
+	router := setupPIITest(t)
+
+	w := performJSON(router, http.MethodGet, "/users", "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"items":[{"id":1,"email":"j***@example.com"}]}`, w.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"items":[{"id":1,"email":"jane@example.com"}]}`, w.Body.String())
+}
+
+func TestMaskPII_PassesOtherResponsesThrough(t *testing.T) {
+	router := setupPIITest(t)
+
+	w := performJSON(router, http.MethodGet, "/text", "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, `{"email":"jane@example.com"}`, w.Body.String())
+
+	w = performJSON(router, http.MethodGet, "/empty", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestMaskPII_FailsClosedOnInvalidJSON(t *testing.T) {
+	w := performJSON(setupPIITest(t), http.MethodGet, "/broken", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "jane")
+	assert.Contains(t, w.Body.String(), codePIIMaskFailed)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/priority"
+)
+
+// codeServerOverloaded is returned when a priority class has no capacity.
+var codeServerOverloaded = apperrors.Register(apperrors.Entry{
+	Code:        "server_overloaded",
+	Kind:        apperrors.KindInternal,
+	Status:      http.StatusServiceUnavailable,
+	Description: "The server is at capacity for this kind of request; retry after the Retry-After delay.",
+})
+
+// PriorityConfig configures the request prioritization middleware.
+type PriorityConfig struct {
+	// Class names the priority class of a request. Defaults to
+	// constants.PriorityClassPublic for every request.
+	Class func(c *gin.Context) string
+}
+
+// Priority returns a middleware that serves each request within the
+// concurrency budget of its class, queueing it while the class is busy.
+// Requests rejected by a full queue or a queue timeout get 503.
+func Priority(queue *priority.Queue, cfg PriorityConfig, log *logger.Logger) gin.HandlerFunc {
+	classOf := cfg.Class
+	if classOf == nil {
+		classOf = func(*gin.Context) string { return constants.PriorityClassPublic }
+	}
+
+	return func(c *gin.Context) {
+		class := classOf(c)
+		release, err := queue.Acquire(c.Request.Context(), class)
+		if err != nil {
+			log.Warnw("priority_queue_rejected", "class", class, "path", c.Request.URL.Path, "error", err)
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":   string(apperrors.KindInternal),
+				"code":    codeServerOverloaded,
+				"message": "server is at capacity",
+			})
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
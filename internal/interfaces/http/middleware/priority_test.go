@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/pkg/priority"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriority_AdminServedWhilePublicSaturated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	queue := priority.New([]priority.Class{
+		{Name: constants.PriorityClassAdmin, Concurrency: 1},
+		{Name: constants.PriorityClassPublic, Concurrency: 1},
+	}, nil)
+
+	entered, unblock := make(chan struct{}), make(chan struct{})
+	router := gin.New()
+	router.Use(Priority(queue, PriorityConfig{
+		Class: func(c *gin.Context) string {
+			if strings.HasPrefix(c.Request.URL.Path, "/admin") {
+				return constants.PriorityClassAdmin
+			}
+			return constants.PriorityClassPublic
+		},
+	}, newMiddlewareTestLogger(t)))
+	router.GET("/slow", func(c *gin.Context) {
+		close(entered)
+		<-unblock
+		c.Status(http.StatusOK)
+	})
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/admin/status", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	done := make(chan int)
+	go func() { done <- performPriority(router, "/slow").Code }()
+	<-entered
+
+	w := performPriority(router, "/items")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "server_overloaded")
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, performPriority(router, "/admin/status").Code)
+
+	close(unblock)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, performPriority(router, "/items").Code)
+}
+
+// performPriority sends a GET request to router.
+func performPriority(router http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/quota"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Quota response headers describing the most constrained period.
+const (
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	QuotaResetHeader     = "X-Quota-Reset"
+	QuotaPeriodHeader    = "X-Quota-Period"
+)
+
+// QuotaConfig configures the quota middleware.
+type QuotaConfig struct {
+	// Subject identifies the verified quota holder; requests with an empty
+	// subject are not metered. Defaults to the request context principal.
+	Subject func(c *gin.Context) string
+}
+
+// Quota returns a middleware that consumes one unit of the caller's quota per
+// request and rejects requests with 429 once a daily or monthly quota is used up.
+// Store failures are logged and the request is allowed.
+func Quota(service *quota.Service, cfg QuotaConfig, log *logger.Logger) gin.HandlerFunc {
+	subjectOf := cfg.Subject
+	if subjectOf == nil {
+		subjectOf = func(c *gin.Context) string { return reqctx.Principal(c.Request.Context()) }
+	}
+
+	return func(c *gin.Context) {
+		subject := subjectOf(c)
+		if subject == "" {
+			c.Next()
+			return
+		}
+
+		usage, err := service.Consume(c.Request.Context(), subject)
+		switch {
+		case errors.Is(err, quota.ErrExceeded):
+			period := setQuotaHeaders(c, usage)
+			retry := time.Until(period.ResetsAt).Seconds()
+			c.Header("Retry-After", strconv.Itoa(int(max(retry, 1))))
+			log.Zap().Warn("quota_exceeded", logger.String("subject", subject), logger.String("period", string(period.Period)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   string(apperrors.KindOf(err)),
+				"code":    apperrors.CodeOf(err),
+				"message": fmt.Sprintf("%s quota of %d requests exceeded", period.Period, period.Limit),
+			})
+			return
+		case err != nil:
+			log.Errorw("quota_check_failed", "subject", subject, "error", err)
+		default:
+			setQuotaHeaders(c, usage)
+		}
+
+		c.Next()
+	}
+}
+
+// setQuotaHeaders writes the headers for the tightest limited period.
+func setQuotaHeaders(c *gin.Context, usage quota.Usage) quota.PeriodUsage {
+	period, ok := usage.Tightest()
+	if !ok {
+		return period
+	}
+	c.Header(QuotaLimitHeader, strconv.FormatInt(period.Limit, 10))
+	c.Header(QuotaRemainingHeader, strconv.FormatInt(period.Remaining, 10))
+	c.Header(QuotaResetHeader, strconv.FormatInt(period.ResetsAt.Unix(), 10))
+	c.Header(QuotaPeriodHeader, string(period.Period))
+	return period
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/quota"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota_RejectsWhenExhausted(t *testing.T) {
+	router := setupQuotaTest(t, quota.Limits{Daily: 1})
+
+	w := performWithAPIKey(router, "key-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get(QuotaLimitHeader))
+	assert.Equal(t, "0", w.Header().Get(QuotaRemainingHeader))
+	assert.Equal(t, "daily", w.Header().Get(QuotaPeriodHeader))
+
+	w = performWithAPIKey(router, "key-1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "quota_exceeded")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.NotEmpty(t, w.Header().Get(QuotaResetHeader))
+
+	assert.Equal(t, http.StatusOK, performWithAPIKey(router, "key-2").Code, "quotas are per subject")
+}
+
+func TestQuota_SkipsAnonymousRequests(t *testing.T) {
+	router := setupQuotaTest(t, quota.Limits{Daily: 1})
+
+	for i := 0; i < 3; i++ {
+		w := performWithAPIKey(router, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(QuotaLimitHeader))
+	}
+}
+
+func TestAdminAuth_RequiresBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", AdminAuth("secret"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for header, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer secret": http.StatusOK,
+	} {
+		req := httptest.NewRequest("GET", "/admin", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, header)
+	}
+}
+
+// setupQuotaTest creates a router metering /ping with the given default limits.
+func setupQuotaTest(t *testing.T, limits quota.Limits) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	svc := quota.NewService(memory.NewCounterStore(), limits, nil)
+	router := gin.New()
+	router.Use(RequestContext(RequestContextConfig{Principal: func(c *gin.Context) string { return c.GetHeader("X-API-Key") }}))
+	router.Use(Quota(svc, QuotaConfig{}, newMiddlewareTestLogger(t)))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// performWithAPIKey sends GET /ping with an optional X-API-Key header, the
+// caller principal.
+func performWithAPIKey(router http.Handler, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/ping", nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
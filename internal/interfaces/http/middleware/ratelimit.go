@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/ratelimit"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Rate limit response headers describing the matched rule's window.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitConfig configures the rate limit middleware.
+type RateLimitConfig struct {
+	// Subject identifies a verified caller, "" for anonymous ones, who are
+	// limited by client IP. Defaults to the request context principal.
+	Subject func(c *gin.Context) string
+}
+
+// RateLimit returns a middleware that charges each request its route cost
+// against the first matching rule and rejects requests with 429 once the
+// window's allowance is used up. Paths without a rule are not limited.
+// Store failures are logged and the request is allowed. Counters and
+// logs name callers by a digest of their subject, so credentials used as
+// subjects are not stored.
+func RateLimit(limiter *ratelimit.Limiter, cfg RateLimitConfig, log *logger.Logger) gin.HandlerFunc {
+	subjectOf := cfg.Subject
+	if subjectOf == nil {
+		subjectOf = func(c *gin.Context) string { return reqctx.Principal(c.Request.Context()) }
+	}
+
+	return func(c *gin.Context) {
+		rule, ok := limiter.Rule(c.Request.URL.Path)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		subject := "ip:" + c.ClientIP()
+		if s := subjectOf(c); s != "" {
+			subject = subjectDigest(s)
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), rule, subject, c.Request.Method, route)
+		switch {
+		case errors.Is(err, ratelimit.ErrExceeded):
+			setRateLimitHeaders(c, result)
+			retry := time.Until(result.ResetsAt).Seconds()
+			c.Header("Retry-After", strconv.Itoa(int(max(retry, 1))))
+			log.Zap().Warn("rate_limit_exceeded",
+				logger.String("subject", subject), logger.String("rule", rule.Name), logger.Int64("cost", result.Cost))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   string(apperrors.KindOf(err)),
+				"code":    apperrors.CodeOf(err),
+				"message": fmt.Sprintf("rate limit of %d per %s exceeded", rule.Limit, rule.Window),
+			})
+			return
+		case err != nil:
+			log.Errorw("rate_limit_check_failed", "subject", subject, "rule", rule.Name, "error", err)
+		default:
+			setRateLimitHeaders(c, result)
+		}
+
+		c.Next()
+	}
+}
+
+// subjectDigest names a subject by the start of its SHA-256 digest.
+func subjectDigest(subject string) string {
+	sum := sha256.Sum256([]byte(subject))
+	return "sub:" + hex.EncodeToString(sum[:8])
+}
+
+// setRateLimitHeaders writes the window state headers.
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header(RateLimitLimitHeader, strconv.FormatInt(result.Limit, 10))
+	c.Header(RateLimitRemainingHeader, strconv.FormatInt(result.Remaining, 10))
+	c.Header(RateLimitResetHeader, strconv.FormatInt(result.ResetsAt.Unix(), 10))
+}
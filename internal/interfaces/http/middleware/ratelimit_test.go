@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/ratelimit"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit_ChargesRouteCost(t *testing.T) {
+	router := setupRateLimitTest(t)
+
+	w := performRateLimited(router, http.MethodPost, "/api/items/bulk", "key-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", w.Header().Get(RateLimitRemainingHeader))
+	assert.NotEmpty(t, w.Header().Get(RateLimitResetHeader))
+
+	w = performRateLimited(router, http.MethodGet, "/api/items/7", "key-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get(RateLimitRemainingHeader))
+
+	w = performRateLimited(router, http.MethodGet, "/api/items/7", "key-1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "rate_limit_exceeded")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, performRateLimited(router, http.MethodGet, "/api/items/7", "key-2").Code,
+		"limits are per subject")
+}
+
+func TestRateLimit_AnonymousCallersByIP(t *testing.T) {
+	router := setupRateLimitTest(t)
+
+	// Unknown keys do not open new budgets
+	for i, key := range []string{"", "random-1", "random-2", "random-3", "random-4"} {
+		assert.Equal(t, http.StatusOK, performRateLimited(router, http.MethodGet, "/api/items/7", "unknown:"+key).Code, i)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, performRateLimited(router, http.MethodGet, "/api/items/7", "unknown:random-5").Code)
+	assert.Equal(t, http.StatusOK, performRateLimited(router, http.MethodGet, "/api/items/7", "key-1").Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/items/7", nil)
+	req.RemoteAddr = "192.0.2.99:1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "other addresses have their own budget")
+}
+
+func TestRateLimit_SkipsPathsWithoutRule(t *testing.T) {
+	router := setupRateLimitTest(t)
+
+	for i := 0; i < 10; i++ {
+		w := performRateLimited(router, http.MethodGet, "/ping", "key-1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+	}
+}
+
+// setupRateLimitTest creates a router limiting /api/ to 5 units per minute,
+// with bulk writes costing 4.
+func setupRateLimitTest(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.New(memory.NewCounterStore(), []ratelimit.Rule{{
+		Name:   "api",
+		Prefix: "/api/",
+		Limit:  5,
+		Window: time.Minute,
+		Costs:  map[string]int64{"POST /api/items/bulk": 4},
+	}})
+	router := gin.New()
+	// Keys prefixed "unknown:" are anonymous
+	router.Use(RequestContext(RequestContextConfig{Principal: func(c *gin.Context) string {
+		if key := c.GetHeader("X-API-Key"); !strings.HasPrefix(key, "unknown:") {
+			return key
+		}
+		return ""
+	}}))
+	router.Use(RateLimit(limiter, RateLimitConfig{}, newMiddlewareTestLogger(t)))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/api/items/bulk", ok)
+	router.GET("/api/items/:id", ok)
+	router.GET("/ping", ok)
+	return router
+}
+
+// performRateLimited sends a request with an X-API-Key header, the caller
+// principal unless prefixed "unknown:".
+func performRateLimited(router http.Handler, method, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
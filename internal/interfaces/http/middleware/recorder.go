@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/pii"
+	"github.com/luminosita/change-me/pkg/recording"
+)
+
+// defaultRecorderMaxBodyBytes caps captured bodies when no limit is configured.
+const defaultRecorderMaxBodyBytes = 64 * 1024
+
+// RecorderConfig configures the request recorder middleware.
+type RecorderConfig struct {
+	Store            recording.Store
+	MaxBodyBytes     int      // Per-body capture limit (default 64 KiB)
+	SensitiveHeaders []string // Headers redacted in addition to recording.DefaultSensitiveHeaders
+	SensitiveParams  []string // Query parameters redacted in addition to recording.DefaultSensitiveParams
+	SkipPaths        []string // Paths never recorded (e.g. /health)
+
+	// PIIFields are masked in recorded JSON bodies (see pii.MaskJSON);
+	// bodies that cannot be parsed, such as truncated ones, are replaced
+	// by recording.Redacted
+	PIIFields map[string]pii.Kind
+}
+
+// Recorder returns a middleware that captures sanitized request/response
+// pairs into the configured store for later replay.
+func Recorder(cfg RecorderConfig, log *logger.Logger) gin.HandlerFunc {
+	maxBody := cfg.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = defaultRecorderMaxBodyBytes
+	}
+
+	sensitive := append([]string{}, recording.DefaultSensitiveHeaders...)
+	sensitive = append(sensitive, cfg.SensitiveHeaders...)
+	params := append([]string{}, recording.DefaultSensitiveParams...)
+	params = append(params, cfg.SensitiveParams...)
+
+	skip := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		truncated := false
+
+		// Capture the head of the request body and replay it ahead of the
+		// rest for downstream handlers, so large bodies are never buffered
+		// whole; the pooled buffers are recycled once the entry holds copies
+		var reqBody []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			raw := getBuffer()
+			defer putBuffer(raw)
+			_, err := raw.ReadFrom(io.LimitReader(c.Request.Body, int64(maxBody)+1))
+			rest := c.Request.Body
+			c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(raw.Bytes()), rest), Closer: rest}
+			if err == nil {
+				reqBody = raw.Bytes()
+				if len(reqBody) > maxBody {
+					reqBody = reqBody[:maxBody]
+					truncated = true
+				}
+			}
+		}
+
+		writer := newBodyCaptureWriter(c.Writer, maxBody)
+		defer writer.release()
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		entry := recording.Entry{
+			ID:         newRecordingID(),
+			RecordedAt: start.UTC(),
+			DurationMS: time.Since(start).Milliseconds(),
+			Request: recording.Request{
+				Method: c.Request.Method,
+				Path:   c.Request.URL.Path,
+				Query:  recording.SanitizeQuery(c.Request.URL.RawQuery, params),
+				Header: recording.SanitizeHeader(c.Request.Header, sensitive),
+				Body:   maskBody(reqBody, c.Request.Header, cfg.PIIFields),
+			},
+			Response: recording.Response{
+				Status: writer.Status(),
+				Header: recording.SanitizeHeader(writer.Header(), sensitive),
+				Body:   maskBody(writer.buf.Bytes(), writer.Header(), cfg.PIIFields),
+			},
+			Truncated: truncated || writer.truncated,
+		}
+
+		if err := cfg.Store.Save(entry); err != nil {
+			log.Warnw("request_recording_failed", "error", err)
+		}
+	}
+}
+
+// maskBody returns a copy of a recorded body with the personal data of
+// JSON bodies masked.
+func maskBody(body []byte, header http.Header, fields map[string]pii.Kind) []byte {
+	if len(fields) == 0 || len(body) == 0 || !isJSON(header.Get("Content-Type")) {
+		return bytes.Clone(body)
+	}
+	masked, err := pii.MaskJSON(body, fields)
+	if err != nil {
+		return []byte(recording.Redacted)
+	}
+	return masked
+}
+
+// bodyCaptureWriter tees the response body into a size-capped buffer.
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// bodyCaptureWriters recycles bodyCaptureWriter values and their buffers.
+var bodyCaptureWriters = sync.Pool{New: func() any { return new(bodyCaptureWriter) }}
+
+// newBodyCaptureWriter returns a pooled bodyCaptureWriter over w
+// capturing up to limit bytes.
+func newBodyCaptureWriter(w gin.ResponseWriter, limit int) *bodyCaptureWriter {
+	cw := bodyCaptureWriters.Get().(*bodyCaptureWriter)
+	cw.ResponseWriter, cw.limit = w, limit
+	return cw
+}
+
+// release returns w to the pool once the captured body is copied.
+func (w *bodyCaptureWriter) release() {
+	if !reusable(&w.buf) {
+		return
+	}
+	w.ResponseWriter, w.truncated = nil, false
+	w.buf.Reset()
+	bodyCaptureWriters.Put(w)
+}
+
+// Write implements io.Writer.
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString implements io.StringWriter.
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture appends b to the buffer up to the limit.
+func (w *bodyCaptureWriter) capture(b []byte) {
+	remaining := w.limit - w.buf.Len()
+	if remaining <= 0 {
+		w.truncated = w.truncated || len(b) > 0
+		return
+	}
+	if len(b) > remaining {
+		b = b[:remaining]
+		w.truncated = true
+	}
+	w.buf.Write(b)
+}
+
+// newRecordingID returns a random identifier for a recorded entry.
+func newRecordingID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
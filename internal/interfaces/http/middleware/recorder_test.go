@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/pkg/recording"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_CapturesHeadOfLargeBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := recording.NewMemoryStore(4)
+
+	var received int
+	router := gin.New()
+	router.Use(Recorder(RecorderConfig{Store: store, MaxBodyBytes: 8}, newMiddlewareTestLogger(t)))
+	router.POST("/upload", func(c *gin.Context) {
+		n, err := io.Copy(io.Discard, c.Request.Body)
+		require.NoError(t, err)
+		received = int(n)
+		c.Status(http.StatusNoContent)
+	})
+
+	body := strings.Repeat("x", 1000)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body)))
+
+	assert.Equal(t, len(body), received, "handlers read the whole body")
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "xxxxxxxx", string(entries[0].Request.Body))
+	assert.True(t, entries[0].Truncated)
+}
+
+func TestRecorder_RedactsQueryTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := recording.NewMemoryStore(4)
+
+	router := gin.New()
+	router.Use(Recorder(RecorderConfig{Store: store, SensitiveParams: []string{"invite"}}, newMiddlewareTestLogger(t)))
+	router.GET("/files", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/files?page=2&access_token=abc&invite=xyz", nil))
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "page=2&access_token=%5BREDACTED%5D&invite=%5BREDACTED%5D", entries[0].Request.Query)
+}
+
+func TestRecorder_RedactsSessionTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := recording.NewMemoryStore(4)
+	fields, err := handlers.PIIFields()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Recorder(RecorderConfig{Store: store, PIIFields: fields}, newMiddlewareTestLogger(t)))
+	router.POST("/auth/refresh", func(c *gin.Context) {
+		c.JSON(http.StatusOK, handlers.TokenResponse{RefreshToken: "fam.next-secret", AccessToken: "eyJ.access.sig"})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token":"fam.old-secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.JSONEq(t, `{"refresh_token":"[redacted]"}`, string(entries[0].Request.Body))
+	assert.NotContains(t, string(entries[0].Response.Body), "secret")
+	assert.NotContains(t, string(entries[0].Response.Body), "eyJ.access.sig")
+	assert.Contains(t, string(entries[0].Response.Body), `"refresh_token":"[redacted]"`)
+}
+
+func TestRecorder_RedactsTwoFactorSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := recording.NewMemoryStore(4)
+	fields, err := handlers.PIIFields()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Recorder(RecorderConfig{Store: store, PIIFields: fields}, newMiddlewareTestLogger(t)))
+	router.POST("/2fa/enrollment", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, handlers.TwoFactorEnrollmentResponse{Secret: "JBSWY3DP", URI: "otpauth://totp/x?secret=JBSWY3DP"})
+	})
+	router.POST("/2fa/enrollment/confirm", func(c *gin.Context) {
+		c.JSON(http.StatusOK, handlers.TwoFactorBackupCodesResponse{BackupCodes: []string{"3f2a-91bc", "77aa-0c1d"}})
+	})
+
+	for _, path := range []string{"/2fa/enrollment", "/2fa/enrollment/confirm"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"account":"jane@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.JSONEq(t, `{"account":"j***@example.com"}`, string(entries[0].Request.Body))
+	assert.JSONEq(t, `{"secret":"[redacted]","otpauth_uri":"[redacted]"}`, string(entries[0].Response.Body))
+	assert.JSONEq(t, `{"backup_codes":["[redacted]","[redacted]"]}`, string(entries[1].Response.Body))
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+)
+
+// Request metadata headers.
+const (
+	RequestIDHeader = "X-Request-ID"
+	TimezoneHeader  = "X-Timezone"
+)
+
+// maxRequestIDLength bounds client-supplied request IDs.
+const maxRequestIDLength = 128
+
+// RequestContextConfig configures the RequestContext middleware.
+type RequestContextConfig struct {
+	Principal       func(*gin.Context) string // Verifies the caller and returns its ID (default: anonymous)
+	TenantHeader    string                    // Header naming the tenant (empty disables)
+	DefaultLocale   string                    // Locale without Accept-Language (default reqctx.DefaultLocale)
+	DefaultLocation *time.Location            // Time zone without X-Timezone (default UTC)
+	Features        map[string]bool           // Feature flags enabled for every request
+	FeatureSource   func() map[string]bool    // Current feature flags, used instead of Features when set
+}
+
+// RequestContext returns a middleware that assembles a reqctx.RequestContext
+// and attaches it to the request context, so handlers pass services a plain
+// context.Context. Client request IDs are kept when well-formed, otherwise
+// one is generated; either way it is echoed in X-Request-ID.
+func RequestContext(cfg RequestContextConfig) gin.HandlerFunc {
+	if cfg.DefaultLocale == "" {
+		cfg.DefaultLocale = reqctx.DefaultLocale
+	}
+	if cfg.DefaultLocation == nil {
+		cfg.DefaultLocation = time.UTC
+	}
+
+	return func(c *gin.Context) {
+		rc := &reqctx.RequestContext{
+			RequestID:  requestID(c.GetHeader(RequestIDHeader)),
+			Locale:     preferredLocale(c.GetHeader("Accept-Language"), cfg.DefaultLocale),
+			Location:   cfg.DefaultLocation,
+			ReceivedAt: time.Now(),
+			Features:   cfg.Features,
+		}
+		if cfg.FeatureSource != nil {
+			rc.Features = cfg.FeatureSource()
+		}
+		if cfg.Principal != nil {
+			rc.Principal = cfg.Principal(c)
+		}
+		if cfg.TenantHeader != "" {
+			rc.Tenant = c.GetHeader(cfg.TenantHeader)
+		}
+		if tz := c.GetHeader(TimezoneHeader); tz != "" {
+			if loc, err := time.LoadLocation(tz); err == nil {
+				rc.Location = loc
+			}
+		}
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			rc.Deadline = deadline
+		}
+
+		c.Header(RequestIDHeader, rc.RequestID)
+		c.Request = c.Request.WithContext(reqctx.With(c.Request.Context(), rc))
+		c.Next()
+	}
+}
+
+// requestID returns the client ID when it is short and printable,
+// otherwise a new random ID.
+func requestID(id string) string {
+	if id != "" && len(id) <= maxRequestIDLength && strings.IndexFunc(id, func(r rune) bool {
+		return r <= ' ' || r > '~'
+	}) < 0 {
+		return id
+	}
+
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// preferredLocale returns the highest-priority tag from an Accept-Language
+// header, ignoring wildcards.
+func preferredLocale(header, fallback string) string {
+	best, bestQ := "", -1.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	if best == "" || bestQ <= 0 {
+		return fallback
+	}
+	return best
+}
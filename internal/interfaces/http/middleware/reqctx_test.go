@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestContext_AssemblesFromHeaders(t *testing.T) {
+	var got *reqctx.RequestContext
+	router := setupRequestContextTest(func(c *gin.Context) { got = reqctx.From(c.Request.Context()) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	req.Header.Set("X-API-Key", "key-1")
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("Accept-Language", "fr;q=0.5, de-DE, *;q=0.1")
+	req.Header.Set(TimezoneHeader, "UTC")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.NotNil(t, got)
+	assert.Equal(t, "req-123", got.RequestID)
+	assert.Equal(t, "req-123", w.Header().Get(RequestIDHeader))
+	assert.Equal(t, "key-1", got.Principal)
+	assert.Equal(t, "acme", got.Tenant)
+	assert.Equal(t, "de-DE", got.Locale)
+	assert.Equal(t, "UTC", got.Location.String())
+	assert.True(t, got.Features["bulk_import"])
+	assert.False(t, got.ReceivedAt.IsZero())
+}
+
+func TestRequestContext_GeneratesIDAndFallsBack(t *testing.T) {
+	var got *reqctx.RequestContext
+	router := setupRequestContextTest(func(c *gin.Context) { got = reqctx.From(c.Request.Context()) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIDHeader, strings.Repeat("x", 200))
+	req.Header.Set(TimezoneHeader, "Nowhere/Invalid")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.NotNil(t, got)
+	assert.Len(t, got.RequestID, 32, "oversized client IDs are replaced")
+	assert.Equal(t, got.RequestID, w.Header().Get(RequestIDHeader))
+	assert.Equal(t, "en", got.Locale)
+	assert.Equal(t, "UTC", got.Location.String())
+	assert.Empty(t, got.Principal)
+}
+
+func TestPreferredLocale(t *testing.T) {
+	assert.Equal(t, "en-GB", preferredLocale("en-GB,en;q=0.8", "en"))
+	assert.Equal(t, "fr", preferredLocale("de;q=0.2, fr;q=0.9", "en"))
+	assert.Equal(t, "en", preferredLocale("*", "en"))
+	assert.Equal(t, "en", preferredLocale("de;q=0", "en"))
+	assert.Equal(t, "en", preferredLocale("", "en"))
+}
+
+// setupRequestContextTest creates a router serving /ping behind the middleware.
+func setupRequestContextTest(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestContext(RequestContextConfig{
+		Principal:    func(c *gin.Context) string { return c.GetHeader("X-API-Key") },
+		TenantHeader: "X-Tenant-ID",
+		Features:     map[string]bool{"bulk_import": true},
+	}))
+	router.GET("/ping", handler)
+	return router
+}
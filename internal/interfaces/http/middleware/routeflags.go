@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/routeflags"
+)
+
+// codeRouteUnavailable is returned by routes disabled with status 503.
+var codeRouteUnavailable = apperrors.Register(apperrors.Entry{
+	Code:        "route_unavailable",
+	Kind:        apperrors.KindInternal,
+	Status:      http.StatusServiceUnavailable,
+	Description: "The endpoint is temporarily disabled by the operators.",
+})
+
+// RouteFlags returns a middleware that rejects requests to routes disabled
+// by flags. Routes disabled with 404 answer like unmatched routes.
+func RouteFlags(flags *routeflags.Flags) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		flag, disabled := flags.Disabled(c.Request.Method, route)
+		if !disabled {
+			c.Next()
+			return
+		}
+
+		if flag.StatusOf() == http.StatusNotFound {
+			c.String(http.StatusNotFound, "404 page not found")
+			c.Abort()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":   string(apperrors.KindInternal),
+			"code":    codeRouteUnavailable,
+			"message": "endpoint is temporarily disabled",
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/routeflags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteFlags_DisablesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	flags := routeflags.New([]routeflags.Flag{
+		{Route: "POST /users"},
+		{Route: "* /users/:id", Status: http.StatusServiceUnavailable},
+	})
+
+	router := gin.New()
+	router.Use(RouteFlags(flags))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/users", ok)
+	router.POST("/users", ok)
+	router.GET("/users/:id", ok)
+
+	assert.Equal(t, http.StatusOK, performMethod(router, http.MethodGet, "/users").Code)
+
+	w := performMethod(router, http.MethodPost, "/users")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found", w.Body.String())
+
+	w = performMethod(router, http.MethodGet, "/users/7")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "route_unavailable")
+
+	flags.Set(nil)
+	assert.Equal(t, http.StatusOK, performMethod(router, http.MethodPost, "/users").Code, "flags apply without rebuilding the router")
+}
+
+// performMethod sends a request without a body.
+func performMethod(router http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScopeFactory opens the dependency scope of a request: it returns the
+// request context carrying the scope and the function ending it, told
+// whether the request failed.
+type ScopeFactory func(ctx context.Context) (context.Context, func(failed bool))
+
+// Scope returns a middleware that opens a dependency scope per request and
+// ends it once the handlers returned. Requests answered with a 4xx or 5xx
+// status, or that panicked, end their scope as failed.
+func Scope(open ScopeFactory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, end := open(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		completed := false
+		defer func() {
+			end(!completed || c.Writer.Status() >= http.StatusBadRequest)
+		}()
+		c.Next()
+		completed = true
+	}
+}
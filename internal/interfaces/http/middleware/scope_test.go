@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scopeKey struct{}
+
+func TestScope_EndsScopeWithOutcome(t *testing.T) {
+	var outcomes []bool
+	open := func(ctx context.Context) (context.Context, func(bool)) {
+		return context.WithValue(ctx, scopeKey{}, "scoped"), func(failed bool) { outcomes = append(outcomes, failed) }
+	}
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	router.Use(Scope(open))
+	router.GET("/ok", func(c *gin.Context) {
+		assert.Equal(t, "scoped", c.Request.Context().Value(scopeKey{}))
+		c.Status(http.StatusOK)
+	})
+	router.GET("/invalid", func(c *gin.Context) { c.Status(http.StatusUnprocessableEntity) })
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	performMethod(router, http.MethodGet, "/ok")
+	performMethod(router, http.MethodGet, "/invalid")
+	w := performMethod(router, http.MethodGet, "/panic")
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, []bool{false, true, true}, outcomes)
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+// serverTimingKey stores the *Timing of a request in the gin context.
+const serverTimingKey = "server_timing"
+
+// Server-Timing metric names of the request phases.
+const (
+	TimingMiddleware    = "middleware"    // Middleware chain up to the route handler
+	TimingHandler       = "handler"       // Route handler up to rendering the response
+	TimingSerialization = "serialization" // Rendering the response up to sending its headers
+	TimingTotal         = "total"
+)
+
+// ServerTimingConfig configures the ServerTiming middleware.
+type ServerTimingConfig struct {
+	// Expose reports whether the response carries the Server-Timing
+	// header; nil exposes it on every response. Phases are recorded for
+	// the request log either way.
+	Expose func(c *gin.Context) bool
+}
+
+// Timing holds the phase boundaries of a request. The phases are final
+// once the response headers are sent.
+type Timing struct {
+	start   time.Time
+	handler time.Time // Route handler entered; zero for requests rejected by middleware
+	render  time.Time // Response status set; zero until then
+	sent    time.Time // Response headers sent; zero until then
+}
+
+// Phases returns the duration of each phase in order. Phases that did not
+// happen are omitted.
+func (t *Timing) Phases() []TimingPhase {
+	sent := t.sent
+	if sent.IsZero() {
+		sent = time.Now()
+	}
+	render := t.render
+	if render.IsZero() {
+		render = sent
+	}
+
+	phases := make([]TimingPhase, 0, 4)
+	if t.handler.IsZero() {
+		phases = append(phases, TimingPhase{TimingMiddleware, render.Sub(t.start)})
+	} else {
+		phases = append(phases,
+			TimingPhase{TimingMiddleware, t.handler.Sub(t.start)},
+			TimingPhase{TimingHandler, render.Sub(t.handler)},
+		)
+	}
+	return append(phases,
+		TimingPhase{TimingSerialization, sent.Sub(render)},
+		TimingPhase{TimingTotal, sent.Sub(t.start)},
+	)
+}
+
+// TimingPhase is the duration of one phase of a request.
+type TimingPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// header formats the phases as a Server-Timing header value, e.g.
+// "middleware;dur=0.420, handler;dur=12.301".
+func (t *Timing) header() string {
+	var b strings.Builder
+	for i, phase := range t.Phases() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s;dur=%.3f", phase.Name, milliseconds(phase.Duration))
+	}
+	return b.String()
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler with the phase
+// durations in milliseconds.
+func (t *Timing) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, phase := range t.Phases() {
+		enc.AddFloat64(phase.Name+"_ms", milliseconds(phase.Duration))
+	}
+	return nil
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// RequestTiming returns the timing of the request, or nil when the
+// ServerTiming middleware is not installed.
+func RequestTiming(c *gin.Context) *Timing {
+	if v, ok := c.Get(serverTimingKey); ok {
+		return v.(*Timing)
+	}
+	return nil
+}
+
+// ServerTiming returns a middleware timing the phases of each request:
+// the middleware chain, the route handler and the serialization of the
+// response. The handler phase starts at ServerTimingHandler, which must
+// run right before the route handlers, and ends when the handler sets the
+// response status, which the gin renderers do before encoding the body.
+// The phases are sent in a Server-Timing header with the response
+// headers, so the time spent writing the body is not included.
+func ServerTiming(cfg ServerTimingConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timing := &Timing{start: time.Now()}
+		c.Set(serverTimingKey, timing)
+		expose := cfg.Expose == nil || cfg.Expose(c)
+		w := &timingWriter{ResponseWriter: c.Writer, timing: timing, expose: expose}
+		c.Writer = w
+
+		c.Next()
+
+		// Responses without a body are sent by gin after the chain returns
+		w.send()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// ServerTimingHandler marks the start of the route handler; install it
+// after every other middleware. The last mark wins, so it may be
+// installed both router-wide and on route groups adding middleware.
+func ServerTimingHandler(c *gin.Context) {
+	if timing := RequestTiming(c); timing != nil {
+		timing.handler = time.Now()
+	}
+}
+
+// timingWriter records when the response status is set and adds the
+// Server-Timing header before the headers are sent.
+type timingWriter struct {
+	gin.ResponseWriter
+	timing *Timing
+	expose bool
+}
+
+// WriteHeader implements http.ResponseWriter; gin only records the status
+// until the body is written.
+func (w *timingWriter) WriteHeader(code int) {
+	t := w.timing
+	if t.render.IsZero() && !t.handler.IsZero() {
+		t.render = time.Now()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// WriteHeaderNow implements gin.ResponseWriter.
+func (w *timingWriter) WriteHeaderNow() {
+	w.send()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Write implements io.Writer.
+func (w *timingWriter) Write(b []byte) (int, error) {
+	w.send()
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString implements io.StringWriter.
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.send()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush implements http.Flusher.
+func (w *timingWriter) Flush() {
+	w.send()
+	w.ResponseWriter.Flush()
+}
+
+// send ends the phases when the headers are about to be sent.
+func (w *timingWriter) send() {
+	t := w.timing
+	if !t.sent.IsZero() || w.ResponseWriter.Written() {
+		return
+	}
+	t.sent = time.Now()
+	if w.expose {
+		w.Header().Add("Server-Timing", t.header())
+	}
+}
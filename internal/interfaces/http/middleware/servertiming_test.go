@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serverTimingPhase matches one metric of a Server-Timing header.
+var serverTimingPhase = regexp.MustCompile(`(\w+);dur=([0-9.]+)`)
+
+// serverTimings parses a Server-Timing header into durations by phase.
+func serverTimings(t *testing.T, header string) map[string]time.Duration {
+	t.Helper()
+	phases := map[string]time.Duration{}
+	for _, m := range serverTimingPhase.FindAllStringSubmatch(header, -1) {
+		ms, err := strconv.ParseFloat(m[2], 64)
+		require.NoError(t, err)
+		phases[m[1]] = time.Duration(ms * float64(time.Millisecond))
+	}
+	return phases
+}
+
+// newServerTimingRouter returns a router with slow middleware, handler and
+// response rendering.
+func newServerTimingRouter(cfg ServerTimingConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ServerTiming(cfg), func(c *gin.Context) {
+		if c.Request.URL.Path == "/rejected" {
+			time.Sleep(10 * time.Millisecond)
+			c.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}, ServerTimingHandler)
+	router.GET("/report", func(c *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		c.Render(http.StatusOK, slowRender{delay: 15 * time.Millisecond})
+	})
+	router.DELETE("/report", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// slowRender renders a body after a delay standing in for encoding.
+type slowRender struct{ delay time.Duration }
+
+func (r slowRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	time.Sleep(r.delay)
+	_, err := w.Write([]byte(`{"ok":true}`))
+	return err
+}
+
+func (slowRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+}
+
+func TestServerTiming_BreaksDownPhases(t *testing.T) {
+	router := newServerTimingRouter(ServerTimingConfig{})
+
+	w := performMethod(router, http.MethodGet, "/report")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	phases := serverTimings(t, w.Header().Get("Server-Timing"))
+	require.Len(t, phases, 4)
+	assert.GreaterOrEqual(t, phases[TimingMiddleware], 10*time.Millisecond)
+	assert.GreaterOrEqual(t, phases[TimingHandler], 20*time.Millisecond)
+	assert.GreaterOrEqual(t, phases[TimingSerialization], 15*time.Millisecond)
+	assert.GreaterOrEqual(t, phases[TimingTotal], 45*time.Millisecond)
+}
+
+func TestServerTiming_ResponsesWithoutHandlerOrBody(t *testing.T) {
+	router := newServerTimingRouter(ServerTimingConfig{})
+
+	rejected := performMethod(router, http.MethodGet, "/rejected")
+	deleted := performMethod(router, http.MethodDelete, "/report")
+
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	phases := serverTimings(t, rejected.Header().Get("Server-Timing"))
+	assert.NotContains(t, phases, TimingHandler, "middleware rejected the request")
+	assert.GreaterOrEqual(t, phases[TimingMiddleware], 10*time.Millisecond)
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	assert.Contains(t, serverTimings(t, deleted.Header().Get("Server-Timing")), TimingHandler)
+}
+
+func TestServerTiming_ExposeAndLog(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(logger.Config{
+		Level:     "DEBUG",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Format: "json", Level: "DEBUG", Writer: &buf}},
+	})
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(ServerTiming(ServerTimingConfig{Expose: func(c *gin.Context) bool { return c.GetHeader("X-Debug") != "" }}),
+		Logger(log, LoggerConfig{}), ServerTimingHandler)
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	hidden := performMethod(router, http.MethodGet, "/users/1")
+	exposed := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("X-Debug", "1")
+	router.ServeHTTP(exposed, req)
+
+	assert.Empty(t, hidden.Header().Get("Server-Timing"))
+	assert.Contains(t, exposed.Header().Get("Server-Timing"), "handler;dur=")
+	entries := requestLogs(t, &buf)
+	require.Len(t, entries, 2)
+	timing, ok := entries[0]["timing"].(map[string]any)
+	require.True(t, ok, "phases are logged even when the header is hidden")
+	assert.Contains(t, timing, "middleware_ms")
+	assert.Contains(t, timing, "handler_ms")
+	assert.Contains(t, timing, "serialization_ms")
+	assert.Contains(t, timing, "total_ms")
+}
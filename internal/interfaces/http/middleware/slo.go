@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/slo"
+)
+
+// SLO returns a middleware that counts every finished request toward the
+// service level objectives matching its path.
+func SLO(tracker *slo.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		tracker.Record(c.Request.Method, c.Request.URL.Path, c.Writer.Status(), time.Since(start))
+	}
+}
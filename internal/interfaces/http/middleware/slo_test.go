@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/slo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLO_RecordsResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := slo.NewTracker([]slo.Objective{{Name: "api", Prefix: "/api/", Availability: 0.9, Window: 6 * time.Hour}},
+		slo.Options{Interval: time.Hour}, newMiddlewareTestLogger(t))
+	defer tracker.Close()
+
+	router := gin.New()
+	router.Use(SLO(tracker))
+	router.GET("/api/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+
+	for _, path := range []string{"/api/ok", "/api/ok", "/api/ok", "/api/fail"} {
+		performMethod(router, http.MethodGet, path)
+	}
+
+	statuses := tracker.Evaluate()
+	require.Len(t, statuses, 1)
+	assert.InDelta(t, 0.75, statuses[0].Compliance, 1e-9)
+}
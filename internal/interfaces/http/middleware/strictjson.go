@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/strictjson"
+)
+
+// StrictJSONConfig configures the StrictJSON middleware.
+type StrictJSONConfig struct {
+	// Routes decoded strictly as "METHOD /route/template"; * matches any
+	// method and a trailing /* every path below the prefix. Empty applies
+	// to every route.
+	Routes []string
+
+	// Options of the decoding; unknown fields are always rejected
+	Options strictjson.Options
+}
+
+// StrictJSON returns a middleware that makes handlers binding JSON bodies
+// decode them with pkg/strictjson: unknown fields, trailing data and
+// documents beyond the nesting and array limits are rejected with 400 and
+// a list of violations instead of being silently ignored.
+func StrictJSON(cfg StrictJSONConfig) gin.HandlerFunc {
+	routes := newRouteSet(cfg.Routes)
+	opts := cfg.Options
+	opts.DisallowUnknownFields = true
+
+	return func(c *gin.Context) {
+		if len(cfg.Routes) > 0 {
+			route := c.FullPath()
+			if route == "" {
+				route = c.Request.URL.Path
+			}
+			if !routes.contains(c.Request.Method, route) {
+				c.Next()
+				return
+			}
+		}
+		c.Request = c.Request.WithContext(strictjson.WithOptions(c.Request.Context(), opts))
+		c.Next()
+	}
+}
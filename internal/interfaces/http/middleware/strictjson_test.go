@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/strictjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictJSON_AppliesToConfiguredRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(StrictJSON(StrictJSONConfig{
+		Routes:  []string{"POST /orders"},
+		Options: strictjson.Options{MaxDepth: 4},
+	}))
+	report := func(c *gin.Context) {
+		opts, strict := strictjson.FromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"strict": strict, "unknown": opts.DisallowUnknownFields, "depth": opts.MaxDepth})
+	}
+	router.POST("/orders", report)
+	router.PUT("/orders", report)
+
+	w := performJSON(router, http.MethodPost, "/orders", `{}`)
+	assert.JSONEq(t, `{"strict": true, "unknown": true, "depth": 4}`, w.Body.String())
+
+	w = performJSON(router, http.MethodPut, "/orders", `{}`)
+	assert.JSONEq(t, `{"strict": false, "unknown": false, "depth": 0}`, w.Body.String())
+}
+
+func TestStrictJSON_AppliesToEveryRouteByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(StrictJSON(StrictJSONConfig{}))
+	router.PATCH("/orders/:id", func(c *gin.Context) {
+		_, strict := strictjson.FromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"strict": strict})
+	})
+
+	w := performJSON(router, http.MethodPatch, "/orders/1", `{}`)
+	assert.JSONEq(t, `{"strict": true}`, w.Body.String())
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+)
+
+// Timeout returns a middleware setting the deadline of the request context
+// to timeout after the request is received. Handlers pass the context to
+// services, so repository queries and outbound calls stop at the deadline
+// (see deadline.Budget for their shares). Requests whose deadline expired
+// before a response was written get 504.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+				"error":   string(apperrors.KindTimeout),
+				"code":    apperrors.CodeOf(apperrors.ErrTimeout),
+				"message": "request deadline of " + timeout.String() + " exceeded",
+			})
+		}
+	}
+}
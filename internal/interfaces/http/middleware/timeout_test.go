@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTimeoutTest(timeout time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Timeout(timeout), RequestContext(RequestContextConfig{}))
+	router.GET("/wait", func(c *gin.Context) { <-c.Request.Context().Done() })
+	router.GET("/late", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusInternalServerError, "late")
+	})
+	router.GET("/deadline", func(c *gin.Context) {
+		deadline, ok := reqctx.Deadline(c.Request.Context())
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, deadline.Format(time.RFC3339Nano))
+	})
+	return router
+}
+
+func TestTimeout_AnswersExpiredRequests(t *testing.T) {
+	router := setupTimeoutTest(20 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wait", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, `{"error":"deadline_exceeded","code":"deadline_exceeded","message":"request deadline of 20ms exceeded"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/late", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "responses written by handlers are kept")
+}
+
+func TestTimeout_DeadlineReachesRequestContext(t *testing.T) {
+	router := setupTimeoutTest(time.Minute)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/deadline", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	deadline, err := time.Parse(time.RFC3339Nano, w.Body.String())
+	assert.NoError(t, err)
+	assert.WithinDuration(t, start.Add(time.Minute), deadline, time.Second)
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+)
+
+// TraceContext returns a middleware that continues the W3C trace context of
+// incoming requests (traceparent/tracestate), or starts a new trace, so
+// handlers, logs and outgoing calls share one trace ID.
+func TraceContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := tracecontext.FromHeader(c.Request.Context(), c.Request.Header)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var traceID string
+	router := gin.New()
+	router.Use(TraceContext())
+	router.GET("/ping", func(c *gin.Context) {
+		traceID = tracecontext.TraceID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(tracecontext.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+
+	// Requests without a parent start a new trace
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Len(t, traceID, 32)
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/reqctx"
+	"github.com/luminosita/change-me/internal/core/watchdog"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+)
+
+// Watchdog returns a middleware registering every request with the slow
+// request watchdog while it is in flight.
+func Watchdog(w *watchdog.Watchdog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		done := w.Start(watchdog.Request{
+			Method:    c.Request.Method,
+			Route:     routeTemplate(c),
+			Path:      c.Request.URL.Path,
+			RequestID: reqctx.RequestID(ctx),
+			TraceID:   tracecontext.TraceID(ctx),
+		})
+		defer done()
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/watchdog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog_TracksRequestsInFlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := watchdog.New(watchdog.Options{Threshold: 10 * time.Millisecond, Interval: time.Hour}, newMiddlewareTestLogger(t))
+	defer w.Close()
+
+	var flagged int
+	router := gin.New()
+	router.Use(Watchdog(w))
+	router.GET("/reports/:id", func(c *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		flagged = w.Check()
+		c.Status(http.StatusOK)
+	})
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	performMethod(router, http.MethodGet, "/reports/7")
+	assert.Equal(t, 1, flagged, "the request is flagged while in flight")
+
+	performMethod(router, http.MethodGet, "/health")
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, w.Check(), "finished requests are not tracked")
+}
+
+func TestWatchdog_UnmatchedRequestsShareARoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	w := watchdog.New(watchdog.Options{Threshold: 10 * time.Millisecond, Interval: time.Hour, Metrics: registry}, newMiddlewareTestLogger(t))
+	defer w.Close()
+
+	router := gin.New()
+	router.Use(Watchdog(w))
+	router.NoRoute(func(c *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		w.Check()
+		c.Status(http.StatusNotFound)
+	})
+
+	performMethod(router, http.MethodGet, "/scan/1")
+	performMethod(router, http.MethodGet, "/scan/2")
+
+	expected := `
+		# HELP http_slow_requests_total Requests still in flight after the slow request threshold by method and route.
+		# TYPE http_slow_requests_total counter
+		http_slow_requests_total{method="GET",route="unmatched"} 2
+	`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "http_slow_requests_total"))
+}
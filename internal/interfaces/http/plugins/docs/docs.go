@@ -0,0 +1,51 @@
+// Package docs is the plugin publishing the OpenAPI document of the API.
+package docs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Name is the plugin name enabling it in PLUGINS_ENABLED.
+const Name = "docs"
+
+// Config configures the docs plugin.
+type Config struct {
+	// Path is the prefix the document is served below
+	Path string `yaml:"path"`
+}
+
+// Validate checks the path is absolute.
+func (c *Config) Validate() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("path %q must start with /", c.Path)
+	}
+	return nil
+}
+
+// Module serves an OpenAPI document at <path>/openapi.yaml.
+type Module struct {
+	spec []byte
+	cfg  Config
+}
+
+// New creates the docs plugin serving spec, by default below /docs.
+func New(spec []byte) *Module {
+	return &Module{spec: spec, cfg: Config{Path: "/docs"}}
+}
+
+func (m *Module) Name() string                    { return Name }
+func (m *Module) Config() any                     { return &m.cfg }
+func (m *Module) Start(ctx context.Context) error { return nil }
+func (m *Module) Stop(ctx context.Context) error  { return nil }
+
+// RegisterRoutes mounts the document route.
+func (m *Module) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET(strings.TrimSuffix(m.cfg.Path, "/")+"/openapi.yaml", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", m.spec)
+	})
+}
@@ -0,0 +1,155 @@
+// Package extensions plugs the extension processes of EXTENSIONS_CONFIG
+// into the server as plugins: each process is started with the server,
+// answers its declared routes and, when declared, provides a route group
+// middleware (see pkg/extension).
+package extensions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/extension"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Module runs an extension process as a plugin.
+type Module struct {
+	cfg  extension.Config
+	log  *logger.Logger
+	proc atomic.Pointer[extension.Process]
+}
+
+// New creates the plugin of the extension declared by cfg.
+func New(cfg extension.Config, log *logger.Logger) *Module {
+	return &Module{cfg: cfg, log: log}
+}
+
+func (m *Module) Name() string { return m.cfg.Name }
+func (m *Module) Config() any  { return nil }
+
+// RegisterRoutes routes the declared routes to the extension.
+func (m *Module) RegisterRoutes(rg *gin.RouterGroup) {
+	for _, route := range m.cfg.Routes {
+		method, path, _ := strings.Cut(route, " ")
+		rg.Handle(method, path, m.handle)
+	}
+}
+
+// Start launches the extension process.
+func (m *Module) Start(ctx context.Context) error {
+	proc, err := extension.Start(ctx, m.cfg, m.log)
+	if err != nil {
+		return err
+	}
+	m.proc.Store(proc)
+	return nil
+}
+
+// Stop stops the extension process.
+func (m *Module) Stop(ctx context.Context) error {
+	if proc := m.proc.Swap(nil); proc != nil {
+		return proc.Stop(ctx)
+	}
+	return nil
+}
+
+// Middleware returns the middleware calling the extension Intercept, nil
+// unless the extension declares one.
+func (m *Module) Middleware() gin.HandlerFunc {
+	if !m.cfg.Middleware {
+		return nil
+	}
+	return func(c *gin.Context) {
+		proc := m.proc.Load()
+		if proc == nil {
+			m.unavailable(c, http.StatusServiceUnavailable, errNotRunning, m.cfg.FailOpen)
+			return
+		}
+		req, ok := m.request(c, proc)
+		if !ok {
+			return
+		}
+
+		decision, err := proc.Intercept(c.Request.Context(), req)
+		if err != nil {
+			m.unavailable(c, http.StatusBadGateway, err, m.cfg.FailOpen)
+			return
+		}
+		if !decision.Continue {
+			if decision.Response == nil {
+				decision.Response = &extension.Response{Status: http.StatusForbidden}
+			}
+			decision.Response.Write(c.Writer)
+			c.Abort()
+			return
+		}
+		for name, values := range decision.Header {
+			c.Request.Header[http.CanonicalHeaderKey(name)] = values
+		}
+		c.Next()
+	}
+}
+
+// errNotRunning reports a request reaching an extension that is not running.
+var errNotRunning = errors.New("extension not running")
+
+// handle answers a declared route with the extension response.
+func (m *Module) handle(c *gin.Context) {
+	proc := m.proc.Load()
+	if proc == nil {
+		m.unavailable(c, http.StatusServiceUnavailable, errNotRunning, false)
+		return
+	}
+	req, ok := m.request(c, proc)
+	if !ok {
+		return
+	}
+
+	resp, err := proc.Handle(c.Request.Context(), req)
+	if err != nil {
+		m.unavailable(c, http.StatusBadGateway, err, false)
+		return
+	}
+	resp.Write(c.Writer)
+}
+
+// request builds the request forwarded to proc, answering c with 413 when
+// its body is too large.
+func (m *Module) request(c *gin.Context, proc *extension.Process) (*extension.Request, bool) {
+	req, err := extension.NewRequest(c.Request, proc.Config().MaxBodyBytes)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   extension.ErrorCode,
+			"code":    extension.ErrorCode,
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+	req.Route = c.FullPath()
+	if len(c.Params) > 0 {
+		req.Params = make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			req.Params[p.Key] = p.Value
+		}
+	}
+	return req, true
+}
+
+// unavailable logs err and lets the request continue when failOpen is
+// set, or answers it with status and the extension error body.
+func (m *Module) unavailable(c *gin.Context, status int, err error, failOpen bool) {
+	m.log.Warnw("extension_call_failed", "extension", m.cfg.Name, "fail_open", failOpen, "error", err)
+	if failOpen {
+		c.Next()
+		return
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"error":   extension.ErrorCode,
+		"code":    extension.ErrorCode,
+		"message": m.cfg.Name + " extension unavailable",
+	})
+}
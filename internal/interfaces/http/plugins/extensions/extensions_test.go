@@ -0,0 +1,119 @@
+package extensions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/pkg/extension"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test binary serves echoExtension when launched as an extension.
+func TestMain(m *testing.M) {
+	if os.Getenv(extension.MagicCookieKey) == extension.MagicCookieValue {
+		if err := extension.Serve(echoExtension{}); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type echoExtension struct{}
+
+func (echoExtension) Handle(_ context.Context, req *extension.Request) (*extension.Response, error) {
+	return &extension.Response{
+		Status: http.StatusAccepted,
+		Body:   []byte(req.Route + " " + req.Params["id"] + " " + string(req.Body)),
+	}, nil
+}
+
+func (echoExtension) Intercept(_ context.Context, req *extension.Request) (*extension.Decision, error) {
+	if req.Header.Get("X-Token") == "" {
+		return &extension.Decision{Response: &extension.Response{Status: http.StatusUnauthorized}}, nil
+	}
+	return &extension.Decision{Continue: true, Header: http.Header{"X-Caller": {"extension"}}}, nil
+}
+
+func newTestModule(t *testing.T, cfg extension.Config) (*Module, *gin.Engine) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+
+	cfg.Name = "echo"
+	cfg.Command = os.Args[0]
+	cfg.StartTimeout = 5 * time.Second
+	m := New(cfg, log)
+
+	router := gin.New()
+	rg := router.Group("/ext")
+	if mw := m.Middleware(); mw != nil {
+		rg.Use(mw)
+	}
+	m.RegisterRoutes(rg)
+	rg.POST("/local", func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		c.String(http.StatusOK, c.GetHeader("X-Caller")+" "+string(body))
+	})
+	return m, router
+}
+
+func serve(router *gin.Engine, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestModule_ForwardsRoutesAndMiddleware(t *testing.T) {
+	m, router := newTestModule(t, extension.Config{Routes: []string{"POST /items/:id"}, Middleware: true})
+	require.NoError(t, m.Start(context.Background()))
+	defer func() { assert.NoError(t, m.Stop(context.Background())) }()
+	token := http.Header{"X-Token": {"t"}}
+
+	w := serve(router, http.MethodPost, "/ext/items/7", "payload", token)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/ext/items/:id 7 payload", w.Body.String())
+
+	w = serve(router, http.MethodPost, "/ext/local", "kept", token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "extension kept", w.Body.String(), "continued requests keep their body")
+
+	w = serve(router, http.MethodPost, "/ext/local", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestModule_UnavailableUntilStarted(t *testing.T) {
+	_, router := newTestModule(t, extension.Config{Routes: []string{"GET /items"}, Middleware: true, FailOpen: true})
+
+	w := serve(router, http.MethodGet, "/ext/items", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), extension.ErrorCode)
+
+	w = serve(router, http.MethodPost, "/ext/local", "open", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "the middleware fails open")
+}
+
+func TestModule_RejectsLargeBodies(t *testing.T) {
+	m, router := newTestModule(t, extension.Config{Routes: []string{"POST /items/:id"}, MaxBodyBytes: 4})
+	require.NoError(t, m.Start(context.Background()))
+	defer func() { assert.NoError(t, m.Stop(context.Background())) }()
+
+	w := serve(router, http.MethodPost, "/ext/items/1", "too large", nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestModule_MiddlewareOnlyWhenDeclared(t *testing.T) {
+	assert.Nil(t, New(extension.Config{Name: "plain"}, nil).Middleware())
+}
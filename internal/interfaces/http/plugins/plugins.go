@@ -0,0 +1,221 @@
+// Package plugins lets optional features plug into the server without
+// editing it. A feature implements Module and adds itself to the default
+// registry from an init function; operators enable modules by name with
+// PLUGINS_ENABLED and configure them in the PLUGINS_CONFIG file:
+//
+//	func init() {
+//		plugins.Register(&Module{})
+//	}
+//
+// Importing the package for its side effect, e.g. from cmd/api, makes the
+// module available. Enabled modules are mounted as route modules under
+// their name, started before the server accepts traffic and stopped after
+// it drained.
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// Module is an optional feature of the server.
+type Module interface {
+	// Name returns the unique module identifier, used to enable it and in
+	// route groups.
+	Name() string
+
+	// Config returns a pointer to the module configuration, decoded from
+	// the module's PLUGINS_CONFIG entry when enabled, or nil when the
+	// module takes none. A configuration implementing Validator is
+	// validated after decoding.
+	Config() any
+
+	// RegisterRoutes registers the module routes; it is called before
+	// Start and may register none.
+	RegisterRoutes(rg *gin.RouterGroup)
+
+	// Start runs the module before the server accepts traffic.
+	Start(ctx context.Context) error
+
+	// Stop releases the module after the server drained.
+	Stop(ctx context.Context) error
+}
+
+// MiddlewareProvider is implemented by modules that also provide a route
+// group middleware, registered under the module name.
+type MiddlewareProvider interface {
+	Middleware() gin.HandlerFunc
+}
+
+// Validator is implemented by module configurations checking their values.
+type Validator interface {
+	Validate() error
+}
+
+// Registry holds the available modules and which of them are enabled.
+type Registry struct {
+	mu      sync.Mutex
+	modules []Module
+	enabled []Module
+	started []Module
+}
+
+// NewRegistry creates an empty plugin registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds modules to the registry. It returns an error if a module
+// name is empty or already registered.
+func (r *Registry) Register(modules ...Module) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range modules {
+		name := m.Name()
+		if name == "" {
+			return fmt.Errorf("plugin name must not be empty")
+		}
+		if r.lookup(name) != nil {
+			return fmt.Errorf("plugin %q already registered", name)
+		}
+		r.modules = append(r.modules, m)
+	}
+	return nil
+}
+
+// lookup returns the module registered under name, or nil. r.mu must be held.
+func (r *Registry) lookup(name string) Module {
+	for _, m := range r.modules {
+		if m.Name() == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Modules returns the registered modules in registration order.
+func (r *Registry) Modules() []Module {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.modules)
+}
+
+// Names returns the registered module names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.modules))
+	for _, m := range r.modules {
+		names = append(names, m.Name())
+	}
+	return names
+}
+
+// Enable enables the named modules in order, decoding their configuration
+// from settings. Nothing is enabled when a name is unknown or a
+// configuration does not decode.
+func (r *Registry) Enable(names []string, settings map[string]yaml.Node) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enabled := make([]Module, 0, len(names))
+	for _, name := range names {
+		m := r.lookup(name)
+		if m == nil {
+			return fmt.Errorf("unknown plugin %q", name)
+		}
+		if slices.Contains(enabled, m) {
+			continue
+		}
+		cfg := m.Config()
+		if node := settings[name]; cfg != nil && !node.IsZero() {
+			if err := node.Decode(cfg); err != nil {
+				return fmt.Errorf("plugin %q: invalid config: %w", name, err)
+			}
+		}
+		if v, ok := cfg.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("plugin %q: invalid config: %w", name, err)
+			}
+		}
+		enabled = append(enabled, m)
+	}
+	r.enabled = enabled
+	return nil
+}
+
+// Enabled returns the enabled modules in enabling order.
+func (r *Registry) Enabled() []Module {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.enabled)
+}
+
+// Start starts the enabled modules in order. When one fails, the modules
+// already started are stopped in reverse order and its error is returned.
+func (r *Registry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.enabled {
+		if err := m.Start(ctx); err != nil {
+			err = fmt.Errorf("plugin %q: start: %w", m.Name(), err)
+			return errors.Join(err, r.stop(ctx))
+		}
+		r.started = append(r.started, m)
+	}
+	return nil
+}
+
+// Stop stops the started modules in reverse order and returns their
+// errors joined.
+func (r *Registry) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stop(ctx)
+}
+
+// stop stops the started modules. r.mu must be held.
+func (r *Registry) stop(ctx context.Context) error {
+	var errs []error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		m := r.started[i]
+		if err := m.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %q: stop: %w", m.Name(), err))
+		}
+	}
+	r.started = nil
+	return errors.Join(errs...)
+}
+
+// registered holds the modules added with Register.
+var registered = NewRegistry()
+
+// Register adds m to the modules available to every server; call it from
+// an init function. It panics if the name is empty or already registered,
+// as registrations are declared in code.
+func Register(m Module) {
+	if err := registered.Register(m); err != nil {
+		panic(err)
+	}
+}
+
+// Default returns a new registry holding the modules added with Register,
+// so each server enables and starts its own.
+func Default() *Registry {
+	registered.mu.Lock()
+	defer registered.mu.Unlock()
+
+	return &Registry{modules: slices.Clone(registered.modules)}
+}
@@ -0,0 +1,117 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type testConfig struct {
+	Greeting string `yaml:"greeting"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Greeting == "" {
+		return errors.New("greeting must not be empty")
+	}
+	return nil
+}
+
+type testModule struct {
+	name     string
+	cfg      testConfig
+	startErr error
+	events   *[]string
+}
+
+func (m *testModule) Name() string                    { return m.name }
+func (m *testModule) Config() any                     { return &m.cfg }
+func (m *testModule) RegisterRoutes(*gin.RouterGroup) {}
+
+func (m *testModule) Start(context.Context) error {
+	*m.events = append(*m.events, "start "+m.name)
+	return m.startErr
+}
+
+func (m *testModule) Stop(context.Context) error {
+	*m.events = append(*m.events, "stop "+m.name)
+	return nil
+}
+
+func newModule(name string, events *[]string) *testModule {
+	return &testModule{name: name, cfg: testConfig{Greeting: "hello"}, events: events}
+}
+
+func TestRegistry_RegisterRejectsInvalidNames(t *testing.T) {
+	var events []string
+	r := NewRegistry()
+
+	require.NoError(t, r.Register(newModule("a", &events), newModule("b", &events)))
+	assert.ErrorContains(t, r.Register(newModule("", &events)), "must not be empty")
+	assert.ErrorContains(t, r.Register(newModule("a", &events)), "already registered")
+	assert.Equal(t, []string{"a", "b"}, r.Names())
+}
+
+func TestRegistry_EnableDecodesAndValidatesConfig(t *testing.T) {
+	var events []string
+	a := newModule("a", &events)
+	r := NewRegistry()
+	require.NoError(t, r.Register(a, newModule("b", &events)))
+
+	var settings map[string]yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("a:\n  greeting: hi\n"), &settings))
+
+	require.NoError(t, r.Enable([]string{"a", "a"}, settings))
+	assert.Equal(t, "hi", a.cfg.Greeting)
+	require.Len(t, r.Enabled(), 1)
+
+	assert.ErrorContains(t, r.Enable([]string{"b", "missing"}, nil), `unknown plugin "missing"`)
+	require.NoError(t, yaml.Unmarshal([]byte("a:\n  greeting: \"\"\n"), &settings))
+	assert.ErrorContains(t, r.Enable([]string{"a"}, settings), "greeting must not be empty")
+	assert.Len(t, r.Enabled(), 1, "a failed Enable keeps the enabled modules")
+}
+
+func TestRegistry_StartStopsStartedModulesOnFailure(t *testing.T) {
+	var events []string
+	failing := newModule("c", &events)
+	failing.startErr = errors.New("boom")
+	r := NewRegistry()
+	require.NoError(t, r.Register(newModule("a", &events), newModule("b", &events), failing))
+	require.NoError(t, r.Enable([]string{"a", "b", "c"}, nil))
+
+	err := r.Start(context.Background())
+
+	assert.ErrorContains(t, err, `plugin "c": start: boom`)
+	assert.Equal(t, []string{"start a", "start b", "start c", "stop b", "stop a"}, events)
+	require.NoError(t, r.Stop(context.Background()))
+	assert.Len(t, events, 5, "stopped modules are not stopped again")
+}
+
+func TestRegistry_StopsInReverseOrder(t *testing.T) {
+	var events []string
+	r := NewRegistry()
+	require.NoError(t, r.Register(newModule("a", &events), newModule("b", &events)))
+	require.NoError(t, r.Enable([]string{"b", "a"}, nil))
+
+	require.NoError(t, r.Start(context.Background()))
+	require.NoError(t, r.Stop(context.Background()))
+
+	assert.Equal(t, []string{"start b", "start a", "stop a", "stop b"}, events)
+}
+
+func TestDefault_CopiesRegisteredModules(t *testing.T) {
+	var events []string
+	Register(newModule("default_test", &events))
+	assert.Panics(t, func() { Register(newModule("default_test", &events)) })
+
+	r := Default()
+	require.NoError(t, r.Register(newModule("local", &events)))
+
+	assert.Contains(t, r.Names(), "default_test")
+	assert.NotContains(t, Default().Names(), "local")
+}
@@ -0,0 +1,101 @@
+package routing
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware is a named entry of a Chain.
+type Middleware struct {
+	Name     string
+	Priority int             // Lower priorities run first
+	After    []string        // Middleware that must run before this one
+	Handler  gin.HandlerFunc // nil marks the middleware unavailable
+}
+
+// Entry describes a middleware of an effective chain.
+type Entry struct {
+	Name     string   `json:"name" example:"logger"`
+	Priority int      `json:"priority" example:"50"`
+	After    []string `json:"after,omitempty"`
+	Enabled  bool     `json:"enabled" example:"true"`
+}
+
+// GroupChain describes the middleware and modules of a mounted group.
+type GroupChain struct {
+	Name       string   `json:"name" example:"api"`
+	Prefix     string   `json:"prefix" example:"/api/v1"`
+	Middleware []Entry  `json:"middleware"`
+	Modules    []string `json:"modules"`
+}
+
+// Chain orders the global middleware by priority. Registration order
+// breaks ties.
+type Chain struct {
+	entries []Middleware
+}
+
+// NewChain creates an empty middleware chain.
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Register adds a middleware to the chain. It returns an error if the name
+// is empty or already registered.
+func (c *Chain) Register(m Middleware) error {
+	if m.Name == "" {
+		return fmt.Errorf("middleware name must not be empty")
+	}
+	if slices.ContainsFunc(c.entries, func(e Middleware) bool { return e.Name == m.Name }) {
+		return fmt.Errorf("middleware %q already registered", m.Name)
+	}
+	c.entries = append(c.entries, m)
+	sort.SliceStable(c.entries, func(i, j int) bool { return c.entries[i].Priority < c.entries[j].Priority })
+	return nil
+}
+
+// Validate checks the ordering constraints: every After name is
+// registered, unavailable or not, and runs earlier.
+func (c *Chain) Validate() error {
+	for i, m := range c.entries {
+		for _, name := range m.After {
+			j := slices.IndexFunc(c.entries, func(e Middleware) bool { return e.Name == name })
+			switch {
+			case j < 0:
+				return fmt.Errorf("middleware %q: runs after unknown middleware %q", m.Name, name)
+			case j >= i:
+				return fmt.Errorf("middleware %q (priority %d) must run after %q (priority %d)",
+					m.Name, m.Priority, name, c.entries[j].Priority)
+			}
+		}
+	}
+	return nil
+}
+
+// Handlers validates the chain and returns the available handlers in
+// order.
+func (c *Chain) Handlers() ([]gin.HandlerFunc, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	var handlers []gin.HandlerFunc
+	for _, m := range c.entries {
+		if m.Handler != nil {
+			handlers = append(handlers, m.Handler)
+		}
+	}
+	return handlers, nil
+}
+
+// Describe returns the effective chain in order, including unavailable
+// middleware.
+func (c *Chain) Describe() []Entry {
+	entries := make([]Entry, 0, len(c.entries))
+	for _, m := range c.entries {
+		entries = append(entries, Entry{Name: m.Name, Priority: m.Priority, After: m.After, Enabled: m.Handler != nil})
+	}
+	return entries
+}
@@ -0,0 +1,58 @@
+package routing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain_OrdersByPriority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tag := func(name string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Request.Header.Add("X-Trail", name)
+			c.Next()
+		}
+	}
+
+	chain := NewChain()
+	require.NoError(t, chain.Register(Middleware{Name: "logger", Priority: 20, After: []string{"request_id"}, Handler: tag("logger")}))
+	require.NoError(t, chain.Register(Middleware{Name: "request_id", Priority: 10, Handler: tag("request_id")}))
+	require.NoError(t, chain.Register(Middleware{Name: "cache", Priority: 20}))
+	require.NoError(t, chain.Register(Middleware{Name: "auth", Priority: 20, Handler: tag("auth")}))
+	assert.Error(t, chain.Register(Middleware{Name: "auth"}), "names are unique")
+
+	handlers, err := chain.Handlers()
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(handlers...)
+	router.GET("/", func(c *gin.Context) { c.String(http.StatusOK, "%v", c.Request.Header.Values("X-Trail")) })
+	assert.Equal(t, "[request_id logger auth]", get(router, "/").Body.String(), "ties keep registration order")
+
+	assert.Equal(t, []Entry{
+		{Name: "request_id", Priority: 10, Enabled: true},
+		{Name: "logger", Priority: 20, After: []string{"request_id"}, Enabled: true},
+		{Name: "cache", Priority: 20},
+		{Name: "auth", Priority: 20, Enabled: true},
+	}, chain.Describe())
+}
+
+func TestChain_ValidatesOrdering(t *testing.T) {
+	chain := NewChain()
+	require.NoError(t, chain.Register(Middleware{Name: "request_id", Priority: 20}))
+	require.NoError(t, chain.Register(Middleware{Name: "logger", Priority: 10, After: []string{"request_id"}}))
+	_, err := chain.Handlers()
+	assert.ErrorContains(t, err, `"logger" (priority 10) must run after "request_id" (priority 20)`)
+
+	chain = NewChain()
+	require.NoError(t, chain.Register(Middleware{Name: "logger", After: []string{"request_ids"}}))
+	assert.ErrorContains(t, chain.Validate(), `unknown middleware "request_ids"`)
+
+	chain = NewChain()
+	require.NoError(t, chain.Register(Middleware{Name: "request_id", Priority: 10}))
+	require.NoError(t, chain.Register(Middleware{Name: "logger", Priority: 10, After: []string{"request_id"}}))
+	assert.NoError(t, chain.Validate(), "registration order satisfies ties")
+}
@@ -0,0 +1,99 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_MountComposesGroups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	table := NewTable()
+	require.NoError(t, table.Module("users", func(rg *gin.RouterGroup) {
+		rg.GET("/users", func(c *gin.Context) { c.String(http.StatusOK, c.GetHeader("X-Trail")) })
+	}))
+	require.NoError(t, table.Module("reports", nil))
+	require.NoError(t, table.Middleware("tag", func(c *gin.Context) {
+		c.Request.Header.Add("X-Trail", "tag")
+		c.Next()
+	}))
+	require.NoError(t, table.Middleware("cache", nil))
+	assert.Error(t, table.Module("users", nil), "names are unique")
+
+	router := gin.New()
+	require.NoError(t, table.Mount(router, []Group{
+		{Name: "public", Prefix: "/v1", Modules: []string{"users"}},
+		{Name: "tagged", Prefix: "/v2", Middleware: []string{"tag", "cache"}, Modules: []string{"users", "reports"}},
+	}))
+
+	assert.Equal(t, "", get(router, "/v1/users").Body.String())
+	assert.Equal(t, "tag", get(router, "/v2/users").Body.String(), "unavailable middleware is skipped")
+	assert.Equal(t, http.StatusNotFound, get(router, "/v3/users").Code)
+}
+
+func TestTable_BeforeRunsAfterGroupMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	table := NewTable()
+	require.NoError(t, table.Module("users", func(rg *gin.RouterGroup) {
+		rg.GET("/users", func(c *gin.Context) {
+			c.String(http.StatusOK, strings.Join(c.Request.Header.Values("X-Trail"), ","))
+		})
+	}))
+	require.NoError(t, table.Middleware("tag", func(c *gin.Context) { c.Request.Header.Add("X-Trail", "tag") }))
+	table.Before(func(c *gin.Context) { c.Request.Header.Add("X-Trail", "before") })
+
+	router := gin.New()
+	require.NoError(t, table.Mount(router, []Group{{Name: "api", Prefix: "/v1", Middleware: []string{"tag"}, Modules: []string{"users"}}}))
+
+	assert.Equal(t, "tag,before", get(router, "/v1/users").Body.String())
+}
+
+func TestTable_MountRejectsUnknownNames(t *testing.T) {
+	table := NewTable()
+	require.NoError(t, table.Module("users", func(rg *gin.RouterGroup) {
+		rg.GET("/users", func(c *gin.Context) {})
+	}))
+
+	router := gin.New()
+	err := table.Mount(router, []Group{
+		{Name: "api", Prefix: "/v1", Modules: []string{"users"}},
+		{Name: "admin", Prefix: "/admin", Middleware: []string{"auth"}, Modules: []string{"users"}},
+	})
+	assert.ErrorContains(t, err, `unknown middleware "auth"`)
+	assert.Empty(t, router.Routes(), "nothing is mounted")
+
+	err = table.Mount(router, []Group{{Name: "api", Prefix: "/v1", Modules: []string{"orders"}}})
+	assert.ErrorContains(t, err, `unknown module "orders"`)
+}
+
+func TestUses(t *testing.T) {
+	groups := []Group{{Middleware: []string{"quota"}}, {Middleware: []string{"ratelimit"}}}
+	assert.True(t, Uses(groups, "ratelimit"))
+	assert.False(t, Uses(groups, "dedup"))
+}
+
+func get(router http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestTable_Describe(t *testing.T) {
+	table := NewTable()
+	require.NoError(t, table.Module("users", func(rg *gin.RouterGroup) {}))
+	require.NoError(t, table.Middleware("quota", nil))
+	require.NoError(t, table.Middleware("tag", func(c *gin.Context) {}))
+	require.NoError(t, table.Mount(gin.New(), []Group{
+		{Name: "api", Prefix: "/v1", Middleware: []string{"tag", "quota"}, Modules: []string{"users"}},
+	}))
+
+	chains := table.Describe()
+	require.Len(t, chains, 1)
+	assert.Equal(t, "/v1", chains[0].Prefix)
+	assert.Equal(t, []Entry{{Name: "tag", Priority: 0, Enabled: true}, {Name: "quota", Priority: 1}}, chains[0].Middleware)
+}
@@ -4,21 +4,70 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/api"
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/apperrors"
+	"github.com/luminosita/change-me/internal/core/constants"
 	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/internal/core/toggles"
+	"github.com/luminosita/change-me/internal/core/warmup"
 	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
 	"github.com/luminosita/change-me/internal/interfaces/http/middleware"
+	"github.com/luminosita/change-me/internal/interfaces/http/plugins"
+	"github.com/luminosita/change-me/internal/interfaces/http/plugins/docs"
+	"github.com/luminosita/change-me/internal/interfaces/http/plugins/extensions"
+	"github.com/luminosita/change-me/internal/interfaces/http/routing"
+	"github.com/luminosita/change-me/pkg/accesstoken"
+	"github.com/luminosita/change-me/pkg/apikey"
+	"github.com/luminosita/change-me/pkg/breaker"
+	"github.com/luminosita/change-me/pkg/captcha"
+	"github.com/luminosita/change-me/pkg/conntrack"
+	"github.com/luminosita/change-me/pkg/extension"
+	"github.com/luminosita/change-me/pkg/jsoncodec"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/profiling"
+	"github.com/luminosita/change-me/pkg/proxy"
+	"github.com/luminosita/change-me/pkg/spa"
+	"github.com/luminosita/change-me/pkg/startup"
+	"github.com/luminosita/change-me/pkg/strictjson"
+	"github.com/luminosita/change-me/web"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/netutil"
 )
 
+// Document the error code written by proxy routes.
+var _ = apperrors.Register(apperrors.Entry{
+	Code:        proxy.ErrorCode,
+	Kind:        apperrors.KindInternal,
+	Status:      http.StatusBadGateway,
+	Description: "A proxied upstream failed, timed out (504) or has its circuit open (503).",
+})
+
+// Document the error code written by extension routes and middleware.
+var _ = apperrors.Register(apperrors.Entry{
+	Code:        extension.ErrorCode,
+	Kind:        apperrors.KindInternal,
+	Status:      http.StatusBadGateway,
+	Description: "An extension process failed (502), is not running (503) or the request body exceeds its limit (413).",
+})
+
 // Server represents the HTTP server.
 type Server struct {
 	router    *gin.Engine
 	container *dependencies.Container
+	plugins   *plugins.Registry
+	startup   *startup.Timer
 }
 
 // New creates a new HTTP server with all routes and middleware configured.
@@ -28,22 +77,745 @@ func New(container *dependencies.Container) *Server {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Create Gin router
+	// JSON library of rendering and binding; process-wide like the mode
+	if err := jsoncodec.Use(container.Config.JSONEncoder); err != nil {
+		container.Logger.Errorw("json_encoder_ignored", "error", err)
+	}
+	container.Logger.Infow("json_encoder", "package", jsoncodec.Package())
+
+	// Create Gin router; forwarding headers only count from trusted
+	// proxies, validated at load time
 	router := gin.New()
+	_ = router.SetTrustedProxies(container.Config.TrustedProxies)
 
-	// Register middleware
-	router.Use(gin.Recovery()) // Panic recovery
-	router.Use(middleware.CORS())
-	router.Use(middleware.Logger(container.Logger))
+	// Optional feature plugins enabled by PLUGINS_ENABLED
+	registry := pluginRegistry(container)
+	enabled := pluginNames(registry)
+
+	// Register the router-wide middleware, ordered by priority
+	groups := routeGroups(container.Config, enabled)
+	chain := middlewareChain(container, groups)
+	global, err := chain.Handlers()
+	if err != nil {
+		// Priorities are declared in code; a violation is a programming error
+		panic(err)
+	}
+	router.Use(global...)
+	if container.Config.ServerTiming != constants.ServerTimingOff {
+		// The handler phase starts after the router-wide and group middleware
+		router.Use(middleware.ServerTimingHandler)
+	}
 
-	// Health check handler
-	healthHandler := handlers.NewHealthHandler(container.Config.AppVersion)
+	// Health check handler; the minimal liveness response stays public
+	// and so does readiness, which waits for the warmup hooks
+	healthHandler := handlers.NewHealthHandler(container.Config.AppVersion, healthChecks(container)...).
+		WithReadiness(container.Warmup.Ready)
 	router.GET("/health", healthHandler.Check)
+	router.GET("/health/ready", healthHandler.Ready)
+
+	// Verbose health and metrics can leak internals and are access controlled
+	observability := router.Group("", middleware.EndpointAuth(endpointAuthConfig(container.Config)))
+	observability.GET("/health/details", healthHandler.Details)
+	if container.Metrics != nil {
+		observability.GET("/metrics", gin.WrapH(promhttp.HandlerFor(container.Metrics, promhttp.HandlerOpts{})))
+	}
+
+	// Module routes, mounted in the route groups of ROUTES_CONFIG or the
+	// built-in groups
+	table := routeTable(container, chain, registry)
+	if container.Config.ServerTiming != constants.ServerTimingOff {
+		table.Before(middleware.ServerTimingHandler)
+	}
+	if err := table.Mount(router, groups); err != nil {
+		container.Logger.Errorw("routes_config_ignored", "error", err)
+		groups = defaultRouteGroups(container.Config, enabled)
+		_ = table.Mount(router, groups)
+	}
+	for _, group := range groups {
+		container.Logger.Infow("route_group_mounted", "group", group.Name, "prefix", group.Prefix,
+			"middleware", group.Middleware, "modules", group.Modules)
+	}
+
+	// Routes not matched above fall through to proxy routes, then the SPA
+	if fallback := fallbackHandlers(container); len(fallback) > 0 {
+		router.NoRoute(fallback...)
+	}
+
+	// Serving a request in-process runs the middleware chain and response
+	// encoding once before real traffic arrives
+	_ = container.Warmup.Register(warmup.New("router", func(ctx context.Context) error {
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/health", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return fmt.Errorf("GET /health: status %d", w.Code)
+		}
+		return nil
+	}))
 
 	return &Server{
 		router:    router,
 		container: container,
+		plugins:   registry,
+	}
+}
+
+// Names of the middleware available to route groups.
+const (
+	middlewareAdminAuth    = "admin_auth"
+	middlewareEndpointAuth = "endpoint_auth"
+	middlewareAccessToken  = "access_token"
+	middlewareRateLimit    = "ratelimit"
+	middlewareQuota        = "quota"
+	middlewareMetering     = "metering"
+	middlewareDedup        = "dedup"
+	middlewareStrictJSON   = "strict_json"
+)
+
+// Names of the router-wide middleware.
+const (
+	middlewareRecovery       = "recovery"
+	middlewareTraceContext   = "trace_context"
+	middlewareServerTiming   = "server_timing"
+	middlewareTimeout        = "timeout"
+	middlewareRequestContext = "request_context"
+	middlewareScope          = "scope"
+	middlewareDefaultHeaders = "default_headers"
+	middlewareCORS           = "cors"
+	middlewareLogger         = "logger"
+	middlewareWatchdog       = "watchdog"
+	middlewareMetrics        = "metrics"
+	middlewareSLO            = "slo"
+	middlewareRouteFlags     = "route_flags"
+	middlewarePriority       = "priority"
+	middlewareCaptcha        = "captcha"
+	middlewareConsent        = "consent"
+	middlewareRecorder       = "recorder"
+	middlewarePIIMask        = "pii_mask"
+	middlewareOpenAPI        = "openapi"
+)
+
+// middlewareChain registers the router-wide middleware; disabled features
+// are registered as unavailable so the admin API lists them.
+func middlewareChain(container *dependencies.Container, groups []routing.Group) *routing.Chain {
+	cfg := container.Config
+	chain := routing.NewChain()
+
+	_ = chain.Register(routing.Middleware{Name: middlewareRecovery, Priority: 0, Handler: gin.Recovery()})
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareTraceContext,
+		Priority: 10,
+		After:    []string{middlewareRecovery},
+		Handler:  middleware.TraceContext(),
+	})
+	// Phase timing covers the rest of the chain
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareServerTiming,
+		Priority: 15,
+		After:    []string{middlewareTraceContext},
+		Handler:  serverTiming(cfg),
+	})
+	// The request deadline is set before the request context records it
+	var timeout gin.HandlerFunc
+	if cfg.ServerRequestTimeout > 0 {
+		timeout = middleware.Timeout(cfg.ServerRequestTimeout)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareTimeout, Priority: 18, After: []string{middlewareTraceContext}, Handler: timeout})
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareRequestContext,
+		Priority: 20,
+		After:    []string{middlewareTraceContext, middlewareTimeout},
+		Handler:  middleware.RequestContext(requestContextConfig(cfg, container.Toggles, container.AccessTokens)),
+	})
+	// Request-scoped dependencies are built from the request context
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareScope,
+		Priority: 25,
+		After:    []string{middlewareRequestContext},
+		Handler:  middleware.Scope(container.OpenScope),
+	})
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareDefaultHeaders,
+		Priority: 30,
+		Handler:  middleware.DefaultHeaders(defaultHeaders(cfg)),
+	})
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareCORS,
+		Priority: 40,
+		Handler: middleware.CORS(middleware.CORSConfig{
+			AllowOrigins:  cfg.CORSAllowOrigins,
+			ExposeHeaders: cfg.CORSExposeHeaders,
+			MaxAge:        cfg.CORSMaxAge,
+		}),
+	})
+	// Logs carry the request and trace IDs
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareLogger,
+		Priority: 50,
+		After:    []string{middlewareRequestContext},
+		Handler:  middleware.Logger(container.Logger, requestLogging(cfg)),
+	})
+
+	// Slow requests are flagged with their request and trace IDs
+	var watched gin.HandlerFunc
+	if container.Watchdog != nil {
+		watched = middleware.Watchdog(container.Watchdog)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareWatchdog, Priority: 52, After: []string{middlewareLogger}, Handler: watched})
+
+	// Request metrics by route template see every response too
+	_ = chain.Register(routing.Middleware{
+		Name:     middlewareMetrics,
+		Priority: 55,
+		After:    []string{middlewareLogger},
+		Handler:  middleware.Metrics(container.HTTPMetrics),
+	})
+
+	// Service level objectives see every response, including rejections
+	var objectives gin.HandlerFunc
+	if container.SLO != nil {
+		objectives = middleware.SLO(container.SLO)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareSLO, Priority: 60, After: []string{middlewareLogger}, Handler: objectives})
+
+	// Routes disabled by operators, evaluated per request so flag file
+	// reloads apply immediately
+	var flags gin.HandlerFunc
+	if container.RouteFlags != nil {
+		flags = middleware.RouteFlags(container.RouteFlags)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareRouteFlags, Priority: 70, After: []string{middlewareSLO}, Handler: flags})
+
+	// Optional per-class concurrency budgets so admin traffic is served
+	// while public endpoints are saturated
+	var queue gin.HandlerFunc
+	if container.RequestQueue != nil {
+		queue = middleware.Priority(container.RequestQueue, middleware.PriorityConfig{
+			Class: priorityClass,
+		}, container.Logger)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewarePriority, Priority: 80, After: []string{middlewareRouteFlags}, Handler: queue})
+
+	// Optional cost-weighted rate limits per route group, unless the route
+	// table scopes them to the groups listing the ratelimit middleware
+	var rateLimited gin.HandlerFunc
+	if container.RateLimiter != nil && !routing.Uses(groups, middlewareRateLimit) {
+		rateLimited = rateLimit(container)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareRateLimit, Priority: 90, After: []string{middlewarePriority}, Handler: rateLimited})
+
+	// Optional CAPTCHA verification of sensitive routes; rate limits apply
+	// first so rejected clients do not cost provider calls
+	_ = chain.Register(routing.Middleware{Name: middlewareCaptcha, Priority: 95, After: []string{middlewareRateLimit}, Handler: captchaMiddleware(container)})
+
+	// Optional acceptance of the latest policy versions on the routes
+	// requiring it
+	var consented gin.HandlerFunc
+	if len(cfg.ConsentRoutes) > 0 && len(cfg.ConsentPolicies) > 0 {
+		consented = middleware.RequireConsent(container.Consent, middleware.ConsentConfig{
+			Routes:   cfg.ConsentRoutes,
+			Policies: cfg.ConsentPolicies,
+		}, container.Logger)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareConsent, Priority: 97, After: []string{middlewareCaptcha}, Handler: consented})
+
+	// Personal data classified by the pii tags of the API schemas
+	piiFields, err := handlers.PIIFields()
+	if err != nil {
+		container.Logger.Errorw("pii_fields_invalid", "error", err)
+	}
+
+	// Optional request recorder for debugging
+	var recorder gin.HandlerFunc
+	if container.Recordings != nil {
+		store, err := container.Recordings.Get()
+		if err != nil {
+			container.Logger.Errorw("request_recorder_disabled", "error", err)
+		} else {
+			recorder = middleware.Recorder(middleware.RecorderConfig{
+				Store:        store,
+				MaxBodyBytes: cfg.RecorderMaxBodyBytes,
+				SkipPaths:    []string{"/health", "/metrics"},
+				PIIFields:    piiFields,
+			}, container.Logger)
+		}
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareRecorder, Priority: 100, Handler: recorder})
+
+	// Optional masking of personal data for callers without the admin
+	// token
+	var masking gin.HandlerFunc
+	if cfg.PIIMaskResponses {
+		masking = middleware.MaskPII(middleware.PIIMaskConfig{
+			Fields:     handlers.ResponsePIIFields(piiFields),
+			Privileged: func(c *gin.Context) bool { return middleware.HasAdminToken(c, cfg.AdminToken) },
+		}, container.Logger)
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewarePIIMask, Priority: 105, After: []string{middlewareRecorder}, Handler: masking})
+
+	// Optional OpenAPI contract validation; loading the contract is the
+	// costliest step of building the router, so it may be deferred
+	var validator gin.HandlerFunc
+	if mode := cfg.OpenAPIValidation; mode != "" && mode != constants.OpenAPIValidationOff {
+		build := func() (gin.HandlerFunc, error) {
+			v, err := middleware.OpenAPIValidator(middleware.OpenAPIValidatorConfig{
+				Spec:              api.OpenAPISpec,
+				Mode:              mode,
+				ValidateResponses: cfg.OpenAPIValidateResponses,
+			}, container.Logger)
+			if err != nil {
+				container.Logger.Errorw("openapi_validation_disabled", "error", err)
+			}
+			return v, err
+		}
+		if cfg.StartupDeferModules {
+			validator = deferredMiddleware(container, middlewareOpenAPI, build)
+		} else if v, err := build(); err == nil {
+			validator = v
+		}
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareOpenAPI, Priority: 110, After: []string{middlewareRecorder}, Handler: validator})
+
+	// Optional strict decoding of JSON bodies, unless the route table
+	// scopes it to the groups listing the strict_json middleware
+	var strict gin.HandlerFunc
+	if (cfg.StrictJSON || len(cfg.StrictJSONRoutes) > 0) && !routing.Uses(groups, middlewareStrictJSON) {
+		strict = middleware.StrictJSON(strictJSONConfig(cfg, cfg.StrictJSONRoutes))
 	}
+	_ = chain.Register(routing.Middleware{Name: middlewareStrictJSON, Priority: 115, After: []string{middlewareOpenAPI}, Handler: strict})
+
+	// Optional coalescing of identical concurrent requests, unless the
+	// route table scopes it to the groups listing the dedup middleware
+	var dedup gin.HandlerFunc
+	if cfg.DedupEnabled && !routing.Uses(groups, middlewareDedup) {
+		dedup = middleware.Dedup(middleware.DedupConfig{
+			Principal: dedupPrincipal(cfg),
+			SkipPaths: []string{"/health"},
+		})
+	}
+	_ = chain.Register(routing.Middleware{Name: middlewareDedup, Priority: 120, After: []string{middlewareOpenAPI}, Handler: dedup})
+
+	return chain
+}
+
+// deferredMiddleware builds a heavy optional middleware on first use, or
+// in its warmup hook when that runs first, instead of before the server
+// listens. A failed build passes requests through, like a middleware that
+// is disabled at startup.
+func deferredMiddleware(container *dependencies.Container, name string, build func() (gin.HandlerFunc, error)) gin.HandlerFunc {
+	handler := dependencies.NewLazy(build)
+	_ = container.Warmup.Register(warmup.New(name, func(context.Context) error {
+		_, err := handler.Get()
+		return err
+	}))
+	return func(c *gin.Context) {
+		h, err := handler.Get()
+		if err != nil {
+			c.Next()
+			return
+		}
+		h(c)
+	}
+}
+
+// strictJSONConfig maps configuration to the StrictJSON middleware of
+// routes, every route when empty.
+func strictJSONConfig(cfg *config.Config, routes []string) middleware.StrictJSONConfig {
+	return middleware.StrictJSONConfig{
+		Routes: routes,
+		Options: strictjson.Options{
+			MaxDepth:    cfg.StrictJSONMaxDepth,
+			MaxArrayLen: cfg.StrictJSONMaxArrayLen,
+		},
+	}
+}
+
+// captchaMiddleware returns the CAPTCHA middleware of the configured
+// provider, or nil when disabled or in the test profile. It panics when
+// the verifier cannot be built rather than leave the routes unprotected.
+func captchaMiddleware(container *dependencies.Container) gin.HandlerFunc {
+	cfg := container.Config
+	if cfg.CaptchaProvider == "" || len(cfg.CaptchaRoutes) == 0 {
+		return nil
+	}
+	if cfg.Environment == constants.EnvTest {
+		container.Logger.Infow("captcha_bypassed", "environment", cfg.Environment)
+		return nil
+	}
+	verifier, err := captcha.New(captcha.Config{
+		Provider:  cfg.CaptchaProvider,
+		Secret:    cfg.CaptchaSecret,
+		VerifyURL: cfg.CaptchaVerifyURL,
+		Breaker:   breaker.New(0, 0),
+	}, container.HTTPClients.Client(middlewareCaptcha))
+	if err != nil {
+		// Load-time validation covers the provider settings, so this is
+		// a programming error like an invalid middleware priority
+		panic(err)
+	}
+	return middleware.Captcha(verifier, middleware.CaptchaConfig{
+		Routes:   cfg.CaptchaRoutes,
+		Header:   cfg.CaptchaHeader,
+		FailOpen: cfg.CaptchaFailOpen,
+	}, container.Logger)
+}
+
+// routeGroups returns the route groups of ROUTES_CONFIG, or the built-in
+// groups when none are declared.
+func routeGroups(cfg *config.Config, plugins []string) []routing.Group {
+	if len(cfg.RouteGroups) > 0 {
+		return cfg.RouteGroups
+	}
+	return defaultRouteGroups(cfg, plugins)
+}
+
+// defaultRouteGroups mounts the error catalog and the metered API, which
+// accepts access tokens, under the API prefix, and the admin modules when
+// an admin token is configured.
+func defaultRouteGroups(cfg *config.Config, plugins []string) []routing.Group {
+	groups := []routing.Group{
+		{Name: "catalog", Prefix: constants.APIPrefix, Modules: []string{"errors"}},
+		{
+			Name:       "api",
+			Prefix:     constants.APIPrefix,
+			Middleware: []string{middlewareAccessToken, middlewareQuota, middlewareMetering},
+			Modules:    []string{"usage", "users", "search", "sessions", "twofactor", "consent", "reports", "files", "uploads"},
+		},
+	}
+	if cfg.AdminToken != "" {
+		groups = append(groups, routing.Group{
+			Name:       "admin",
+			Prefix:     constants.AdminPrefix,
+			Middleware: []string{middlewareAdminAuth},
+			Modules:    []string{"quotas", "usage_admin", "search_admin", "profiling", "store", "notifications", "privacy", "consent_admin", "middleware", "config", "toggles"},
+		})
+	}
+	if len(plugins) > 0 {
+		groups = append(groups, routing.Group{Name: "plugins", Prefix: "/", Modules: plugins})
+	}
+	return groups
+}
+
+// routeTable registers the handler modules and the middleware available
+// to route groups; disabled features are registered as unavailable.
+func routeTable(container *dependencies.Container, chain *routing.Chain, registry *plugins.Registry) *routing.Table {
+	cfg := container.Config
+	table := routing.NewTable()
+
+	log := container.Logger
+	_ = table.Module("errors", handlers.NewErrorCatalogHandler().Register)
+	_ = table.Module("users", module(log, container.UsersDeps, func(d dependencies.UsersDeps) routing.Registrar {
+		return handlers.NewUserHandler(d.UserService, d.Logger).WithCursors(d.Cursors).Register
+	}))
+
+	var usage, usageAdmin routing.Registrar
+	if d, err := container.UsageDeps(); err != nil {
+		log.Infow("module_unavailable", "error", err)
+	} else {
+		usageHandler := handlers.NewUsageHandler(d.UsageAggregator, apiKeySubject(d.Config, d.Config.MeteringSubjectHeader))
+		usage, usageAdmin = usageHandler.Register, usageHandler.RegisterAdmin
+	}
+	_ = table.Module("usage", usage)
+	_ = table.Module("usage_admin", usageAdmin)
+
+	var userSearch, searchAdmin routing.Registrar
+	if d, err := container.SearchDeps(); err != nil {
+		log.Infow("module_unavailable", "error", err)
+	} else {
+		searchHandler := handlers.NewSearchHandler(d.UserSearch, d.Logger)
+		userSearch, searchAdmin = searchHandler.Register, searchHandler.RegisterAdmin
+	}
+	_ = table.Module("search", userSearch)
+	_ = table.Module("search_admin", searchAdmin)
+
+	_ = table.Module("quotas", module(log, container.QuotasDeps, func(d dependencies.QuotasDeps) routing.Registrar {
+		return handlers.NewQuotaHandler(d.QuotaService, d.Logger).Register
+	}))
+	_ = table.Module("sessions", module(log, container.SessionsDeps, func(d dependencies.SessionsDeps) routing.Registrar {
+		return handlers.NewSessionHandler(d.Sessions, apiKeySubject(cfg, cfg.PrincipalHeader), d.Logger).Register
+	}))
+	_ = table.Module("twofactor", module(log, container.TwofactorDeps, func(d dependencies.TwofactorDeps) routing.Registrar {
+		return handlers.NewTwoFactorHandler(d.TwoFactor, apiKeySubject(cfg, cfg.PrincipalHeader), d.Logger).Register
+	}))
+
+	var policies, policiesAdmin routing.Registrar
+	if d, err := container.ConsentDeps(); err != nil {
+		log.Infow("module_unavailable", "error", err)
+	} else {
+		consentHandler := handlers.NewConsentHandler(d.Consent, d.Logger)
+		policies, policiesAdmin = consentHandler.Register, consentHandler.RegisterAdmin
+	}
+	_ = table.Module("consent", policies)
+	_ = table.Module("consent_admin", policiesAdmin)
+
+	var profiling routing.Registrar
+	if cfg.AdminToken != "" {
+		if profiler, err := newProfiler(container); err != nil {
+			container.Logger.Errorw("profiling_disabled", "error", err)
+		} else {
+			profiling = handlers.NewProfilingHandler(profiler, container.Logger).Register
+		}
+	}
+	_ = table.Module("profiling", profiling)
+	_ = table.Module("store", module(log, container.StoreDeps, func(d dependencies.StoreDeps) routing.Registrar {
+		return handlers.NewStoreHandler(d.Store, d.Logger).Register
+	}))
+	_ = table.Module("reports", module(log, container.ReportsDeps, func(d dependencies.ReportsDeps) routing.Registrar {
+		return handlers.NewReportHandler(d.Reports, d.Logger).Register
+	}))
+	_ = table.Module("uploads", module(log, container.UploadsDeps, func(d dependencies.UploadsDeps) routing.Registrar {
+		return handlers.NewUploadHandler(d.Uploads, d.Logger).Register
+	}))
+	_ = table.Module("files", module(log, container.FilesDeps, func(d dependencies.FilesDeps) routing.Registrar {
+		return handlers.NewFileHandler(d.Files, d.Logger).Register
+	}))
+	_ = table.Module("notifications", module(log, container.NotificationsDeps, func(d dependencies.NotificationsDeps) routing.Registrar {
+		return handlers.NewNotificationHandler(d.Notifications, d.Logger).Register
+	}))
+	_ = table.Module("privacy", module(log, container.PrivacyDeps, func(d dependencies.PrivacyDeps) routing.Registrar {
+		return handlers.NewPrivacyHandler(d.Privacy, d.Logger).Register
+	}))
+	_ = table.Module("middleware", handlers.NewMiddlewareHandler(chain, table).Register)
+	_ = table.Module("config", handlers.NewConfigHandler(cfg).Register)
+	_ = table.Module("toggles", module(log, container.TogglesDeps, func(d dependencies.TogglesDeps) routing.Registrar {
+		return handlers.NewToggleHandler(d.Toggles, d.Logger).Register
+	}))
+
+	// Plugins are modules, and middleware when they provide one, under
+	// their name; they are unavailable unless enabled
+	enabled := make(map[plugins.Module]bool)
+	for _, m := range registry.Enabled() {
+		enabled[m] = true
+	}
+	for _, m := range registry.Modules() {
+		var register routing.Registrar
+		if enabled[m] {
+			register = m.RegisterRoutes
+		}
+		if err := table.Module(m.Name(), register); err != nil {
+			log.Errorw("plugin_routes_failed", "plugin", m.Name(), "error", err)
+		}
+		if provider, ok := m.(plugins.MiddlewareProvider); ok {
+			var handler gin.HandlerFunc
+			if enabled[m] {
+				handler = provider.Middleware()
+			}
+			if err := table.Middleware(m.Name(), handler); err != nil {
+				log.Errorw("plugin_middleware_failed", "plugin", m.Name(), "error", err)
+			}
+		}
+	}
+
+	_ = table.Middleware(middlewareAdminAuth, middleware.AdminAuth(cfg.AdminToken))
+	_ = table.Middleware(middlewareEndpointAuth, middleware.EndpointAuth(endpointAuthConfig(cfg)))
+	var accessToken gin.HandlerFunc
+	if container.AccessTokens != nil {
+		accessToken = middleware.AccessToken(container.AccessTokens)
+	}
+	_ = table.Middleware(middlewareAccessToken, accessToken)
+	_ = table.Middleware(middlewareDedup, middleware.Dedup(middleware.DedupConfig{Principal: dedupPrincipal(cfg)}))
+	_ = table.Middleware(middlewareStrictJSON, middleware.StrictJSON(strictJSONConfig(cfg, nil)))
+
+	var rateLimited, quota, metering gin.HandlerFunc
+	if container.RateLimiter != nil {
+		rateLimited = rateLimit(container)
+	}
+	if cfg.QuotaEnabled {
+		quota = middleware.Quota(container.QuotaService, middleware.QuotaConfig{
+			Subject: quotaSubject(cfg),
+		}, container.Logger)
+	}
+	if container.Metering != nil {
+		metering = middleware.Metering(container.Metering, middleware.MeteringConfig{
+			Subject: apiKeySubject(cfg, cfg.MeteringSubjectHeader),
+		})
+	}
+	_ = table.Middleware(middlewareRateLimit, rateLimited)
+	_ = table.Middleware(middlewareQuota, quota)
+	_ = table.Middleware(middlewareMetering, metering)
+
+	return table
+}
+
+// pluginRegistry returns the plugins added with plugins.Register, the
+// built-in ones and the extensions of EXTENSIONS_CONFIG, with those of
+// PLUGINS_ENABLED enabled. Invalid plugin settings are logged and no plugin
+// is enabled.
+func pluginRegistry(container *dependencies.Container) *plugins.Registry {
+	cfg := container.Config
+	registry := plugins.Default()
+	_ = registry.Register(docs.New(api.OpenAPISpec))
+	for _, ext := range cfg.Extensions {
+		if err := registry.Register(extensions.New(ext, container.Logger)); err != nil {
+			container.Logger.Errorw("extension_ignored", "extension", ext.Name, "error", err)
+		}
+	}
+
+	if err := registry.Enable(cfg.PluginsEnabled, cfg.PluginSettings); err != nil {
+		container.Logger.Errorw("plugins_disabled", "error", err)
+	}
+	return registry
+}
+
+// pluginNames returns the names of the enabled plugins.
+func pluginNames(registry *plugins.Registry) []string {
+	var names []string
+	for _, m := range registry.Enabled() {
+		names = append(names, m.Name())
+	}
+	return names
+}
+
+// module builds the registrar of a route module from its container
+// dependencies. A module whose required dependencies are missing is left
+// unavailable rather than registered with nil dependencies.
+func module[D any](log *logger.Logger, deps func() (D, error), build func(D) routing.Registrar) routing.Registrar {
+	d, err := deps()
+	if err != nil {
+		log.Infow("module_unavailable", "error", err)
+		return nil
+	}
+	return build(d)
+}
+
+// rateLimit builds the cost-weighted rate limit middleware.
+func rateLimit(container *dependencies.Container) gin.HandlerFunc {
+	return middleware.RateLimit(container.RateLimiter, middleware.RateLimitConfig{
+		Subject: apiKeySubject(container.Config, container.Config.RateLimitSubjectHeader),
+	}, container.Logger)
+}
+
+// healthChecks returns the dependency checks run by /health/details.
+func healthChecks(container *dependencies.Container) []handlers.HealthCheck {
+	var checks []handlers.HealthCheck
+	if container.Redis != nil {
+		checks = append(checks, handlers.HealthCheck{
+			Name:  "redis",
+			Check: func(ctx context.Context) error { return container.Redis.Ping(ctx).Err() },
+		})
+	}
+	if container.Temporal != nil {
+		checks = append(checks, handlers.HealthCheck{
+			Name: "temporal",
+			Check: func(ctx context.Context) error {
+				_, err := container.Temporal.CheckHealth(ctx, &temporalclient.CheckHealthRequest{})
+				return err
+			},
+		})
+	}
+	// Synthetic checks report their latest probe rather than probing again
+	if container.Synthetic != nil {
+		for _, name := range container.Synthetic.Names() {
+			checks = append(checks, handlers.HealthCheck{
+				Name:  "synthetic:" + name,
+				Check: func(context.Context) error { return container.Synthetic.Err(name) },
+			})
+		}
+	}
+	return checks
+}
+
+// priorityClass puts admin, health and metrics requests in the admin class.
+func priorityClass(c *gin.Context) string {
+	path := c.Request.URL.Path
+	if path == "/health" || path == "/metrics" || strings.HasPrefix(path, "/health/") ||
+		path == constants.AdminPrefix || strings.HasPrefix(path, constants.AdminPrefix+"/") {
+		return constants.PriorityClassAdmin
+	}
+	return constants.PriorityClassPublic
+}
+
+// endpointAuthConfig maps OBSERVABILITY_* settings to the access control
+// middleware. OBSERVABILITY_BASIC_AUTH is validated as user:password.
+func endpointAuthConfig(cfg *config.Config) middleware.EndpointAuthConfig {
+	user, password, _ := strings.Cut(cfg.ObservabilityBasicAuth, ":")
+	return middleware.EndpointAuthConfig{
+		BasicUser:     user,
+		BasicPassword: password,
+		BearerToken:   cfg.ObservabilityBearerToken,
+		AllowCIDRs:    cfg.ObservabilityAllowCIDRs,
+	}
+}
+
+// newProfiler creates the on-demand profiler of PROFILING_STORAGE, indexed
+// in PROFILING_DIR.
+func newProfiler(container *dependencies.Container) (*profiling.Profiler, error) {
+	cfg := container.Config
+	files, err := profiling.NewFileStore(cfg.ProfilingDir)
+	if err != nil {
+		return nil, err
+	}
+	var store profiling.Store = files
+	if cfg.ProfilingStorage == "object" {
+		if container.ObjectStore == nil {
+			return nil, fmt.Errorf("PROFILING_STORAGE=object requires OBJECT_STORAGE_PROVIDER")
+		}
+		store = profiling.NewObjectStore(container.ObjectStore, "profiles")
+	}
+	return profiling.New(store, profiling.Options{
+		MaxDuration: cfg.ProfilingMaxDuration,
+		MaxCaptures: cfg.ProfilingMaxCaptures,
+		IndexFile:   filepath.Join(cfg.ProfilingDir, "index.json"),
+	})
+}
+
+// fallbackHandlers builds the handlers for unmatched routes: declared proxy
+// routes first, then the embedded SPA.
+func fallbackHandlers(container *dependencies.Container) []gin.HandlerFunc {
+	var fallback []gin.HandlerFunc
+
+	for _, route := range container.Config.ProxyRoutes {
+		opts := []proxy.Option{proxy.WithMetrics(container.HTTPClientMetrics)}
+		if container.HTTPClients != nil {
+			clients, name := container.HTTPClients, route.Client
+			opts = append(opts, proxy.WithFallback(func(next http.RoundTripper) http.RoundTripper {
+				return clients.Fallback(name, next)
+			}))
+		}
+		handler, err := proxy.New(route, proxyTransport(container, route), container.Logger, opts...)
+		if err != nil {
+			container.Logger.Errorw("proxy_route_disabled", "route", route.Name, "error", err)
+			continue
+		}
+		route := route
+		fallback = append(fallback, func(c *gin.Context) {
+			if !route.Matches(c.Request.URL.Path) {
+				return
+			}
+			handler.ServeHTTP(c.Writer, c.Request)
+			c.Abort()
+		})
+		container.Logger.Infow("proxy_route_registered", "route", route.Name, "prefix", route.Prefix, "upstream", route.Upstream)
+	}
+
+	if container.Config.SPAEnabled {
+		frontend, err := spa.New(web.Dist(), spa.Options{
+			ExcludePrefixes: []string{"/api/", constants.AdminPrefix + "/", "/health", "/metrics"},
+		})
+		if err != nil {
+			container.Logger.Errorw("spa_disabled", "error", err)
+		} else {
+			fallback = append(fallback, gin.WrapH(frontend))
+		}
+	}
+
+	return fallback
+}
+
+// proxyTransport returns the transport of the route's named client; its
+// fallback is applied by the proxy after retries.
+func proxyTransport(container *dependencies.Container, route proxy.Route) http.RoundTripper {
+	if container.HTTPClients == nil {
+		return container.HTTPClient.Transport
+	}
+	return container.HTTPClients.Upstream(route.Client)
+}
+
+// WithStartup makes Run mark the listen and warmup phases on timer and
+// report the startup against STARTUP_BUDGET once warm.
+func (s *Server) WithStartup(timer *startup.Timer) *Server {
+	s.startup = timer
+	return s
 }
 
 // Router returns the underlying Gin router for testing.
@@ -51,8 +823,34 @@ func (s *Server) Router() *gin.Engine {
 	return s.router
 }
 
+// Plugins returns the plugin registry, which Run starts and stops.
+func (s *Server) Plugins() *plugins.Registry {
+	return s.plugins
+}
+
 // Start starts the HTTP server with graceful shutdown support.
+// It blocks until SIGINT/SIGTERM, then shuts down and closes the container.
 func (s *Server) Start() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := s.Run(ctx); err != nil {
+		return err
+	}
+
+	// Close dependencies
+	if err := s.container.Close(); err != nil {
+		s.container.Logger.Errorw("dependencies_close_error", "error", err)
+		return err
+	}
+
+	s.container.Logger.Infow("application_shutdown_complete")
+	return nil
+}
+
+// Run serves HTTP until ctx is canceled, then shuts the listener down
+// gracefully. The container is left open for the caller to close.
+func (s *Server) Run(ctx context.Context) error {
 	cfg := s.container.Config
 	log := s.container.Logger
 
@@ -61,11 +859,51 @@ func (s *Server) Start() error {
 
 	// Create HTTP server
 	srv := &http.Server{
-		Addr:         addr,
-		Handler:      s.router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		Addr:              addr,
+		Handler:           conntrack.MaxRequests(s.router, cfg.ServerMaxRequestsPerConn),
+		ReadTimeout:       cfg.ServerReadTimeout,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+		MaxHeaderBytes:    cfg.ServerMaxHeaderBytes,
+		ConnContext:       conntrack.ConnContext,
+		ConnState:         s.container.Connections.ConnState,
+	}
+	srv.SetKeepAlivesEnabled(cfg.ServerKeepAlive)
+
+	// Plugins start before traffic is accepted and stop once it drained
+	if err := s.plugins.Start(ctx); err != nil {
+		log.Errorw("server_failed", "error", err)
+		return err
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), cfg.ServerShutdownTimeout)
+		defer cancel()
+		if err := s.plugins.Stop(stopCtx); err != nil {
+			log.Errorw("plugins_stop_failed", "error", err)
+		}
+	}()
+
+	// Cloud Run throttles the CPU once the startup probe saw the port
+	// open, so warmup runs before listening there
+	warmFirst := cfg.CloudRun && cfg.CloudRunCPUThrottled
+	if warmFirst {
+		s.container.Warmup.Run(ctx, log, cfg.WarmupTimeout)
+		s.startup.Mark(startup.PhaseWarmup)
+	}
+
+	listener, err := conntrack.Listen(ctx, addr, conntrack.KeepAlive{
+		Enabled:  cfg.ServerTCPKeepAlive,
+		Idle:     cfg.ServerTCPKeepAliveIdle,
+		Interval: cfg.ServerTCPKeepAliveInterval,
+		Count:    cfg.ServerTCPKeepAliveCount,
+	})
+	if err != nil {
+		log.Errorw("server_failed", "error", err)
+		return err
+	}
+	if cfg.ServerMaxConnections > 0 {
+		listener = netutil.LimitListener(listener, cfg.ServerMaxConnections)
 	}
 
 	// Log startup information
@@ -77,39 +915,157 @@ func (s *Server) Start() error {
 		"debug", cfg.Debug,
 		"log_level", cfg.LogLevel,
 		"log_format", cfg.LogFormat,
+		"max_connections", cfg.ServerMaxConnections,
+		"keep_alive", cfg.ServerKeepAlive,
 	)
 
 	// Start server in goroutine
+	serveErr := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalw("server_failed", "error", err)
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
 		}
+		close(serveErr)
 	}()
 
 	log.Infow("application_startup_complete", "address", addr)
+	s.startup.Mark(startup.PhaseListen)
 
-	// Wait for interrupt signal for graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
+	// Warm up while liveness is answered; readiness flips once the hooks ran
+	if !warmFirst {
+		s.container.Warmup.Run(ctx, log, cfg.WarmupTimeout)
+		s.startup.Mark(startup.PhaseWarmup)
+	}
+	s.startup.Report(log, cfg.StartupBudget)
+
+	// Wait for shutdown request or listener failure
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			log.Errorw("server_failed", "error", err)
+			return err
+		}
+	case <-ctx.Done():
+	}
 
 	log.Infow("application_shutdown_started")
 
 	// Shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ServerShutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Errorw("server_shutdown_error", "error", err)
 		return err
 	}
 
-	// Close dependencies
-	if err := s.container.Close(); err != nil {
-		log.Errorw("dependencies_close_error", "error", err)
-		return err
+	return nil
+}
+
+// requestContextConfig maps configuration to the request context middleware,
+// taking the feature flags from overrides when set. Callers are identified
+// by the subject of their access token when signer is set, else by the ID
+// of their API key, never the key itself. DEFAULT_TIMEZONE and API_KEYS
+// are validated at load time.
+func requestContextConfig(cfg *config.Config, overrides *toggles.Service, signer *accesstoken.Signer) middleware.RequestContextConfig {
+	loc, _ := time.LoadLocation(cfg.DefaultTimezone)
+	features := make(map[string]bool, len(cfg.FeatureFlags))
+	for _, name := range cfg.FeatureFlags {
+		features[name] = true
 	}
 
-	log.Infow("application_shutdown_complete")
+	principal := apiKeySubject(cfg, cfg.PrincipalHeader)
+	if signer != nil {
+		principal = middleware.AccessTokenPrincipal(signer, principal)
+	}
+	out := middleware.RequestContextConfig{
+		Principal:       principal,
+		TenantHeader:    cfg.TenantHeader,
+		DefaultLocale:   cfg.DefaultLocale,
+		DefaultLocation: loc,
+		Features:        features,
+	}
+	if overrides != nil {
+		out.FeatureSource = overrides.Features
+	}
+	return out
+}
+
+// apiKeySubject identifies callers by the ID of the API key they send in
+// header, "" when it is missing or unknown, so clients cannot pick the
+// identity they are accounted under. API_KEYS is validated at load time.
+func apiKeySubject(cfg *config.Config, header string) func(*gin.Context) string {
+	keys, _ := apikey.Parse(cfg.APIKeys)
+	return func(c *gin.Context) string {
+		id, _ := keys.Lookup(c.GetHeader(header))
+		return id
+	}
+}
+
+// dedupPrincipal keys coalesced requests on the caller's API key ID and the
+// raw Authorization, X-API-Key and PRINCIPAL_HEADER values.
+func dedupPrincipal(cfg *config.Config) func(*gin.Context) string {
+	return middleware.CredentialPrincipal(apiKeySubject(cfg, cfg.PrincipalHeader), "Authorization", "X-API-Key", cfg.PrincipalHeader)
+}
+
+// quotaSubject returns the quota subject of QUOTA_SUBJECT: the caller's API
+// key ID, or in tenant mode the API_KEY_TENANTS tenant of that key, "" for
+// keys without one. Tenants come from configuration only, never from a
+// request header.
+func quotaSubject(cfg *config.Config) func(*gin.Context) string {
+	key := apiKeySubject(cfg, cfg.QuotaSubjectHeader)
+	if cfg.QuotaSubject != "tenant" {
+		return key
+	}
+	tenants := make(map[string]string, len(cfg.APIKeyTenants))
+	for _, pair := range cfg.APIKeyTenants {
+		id, tenant, _ := strings.Cut(pair, "=")
+		tenants[id] = tenant
+	}
+	return func(c *gin.Context) string {
+		return tenants[key(c)]
+	}
+}
+
+// requestLogging returns the request logging exclusions, levels and
+// captured headers of cfg.
+func requestLogging(cfg *config.Config) middleware.LoggerConfig {
+	levels := make(map[int]zapcore.Level, len(cfg.LogRequestLevels))
+	for _, pair := range cfg.LogRequestLevels {
+		class, level, _ := strings.Cut(pair, "=")
+		levels[int(class[0]-'0')], _ = logger.ParseLevel(level)
+	}
+	return middleware.LoggerConfig{
+		SkipPaths: cfg.LogRequestSkipPaths,
+		Levels:    levels,
+		Headers:   cfg.LogRequestHeaders,
+	}
+}
+
+// serverTiming returns the phase timing middleware of the SERVER_TIMING
+// mode, or nil when off.
+func serverTiming(cfg *config.Config) gin.HandlerFunc {
+	switch cfg.ServerTiming {
+	case constants.ServerTimingAlways:
+		return middleware.ServerTiming(middleware.ServerTimingConfig{})
+	case constants.ServerTimingDebug:
+		return middleware.ServerTiming(middleware.ServerTimingConfig{
+			Expose: func(c *gin.Context) bool { return cfg.Debug || middleware.HasAdminToken(c, cfg.AdminToken) },
+		})
+	}
 	return nil
 }
+
+// defaultHeaders builds the headers set on every response from the
+// configured Name=Value pairs and the optional version header.
+func defaultHeaders(cfg *config.Config) map[string]string {
+	headers := make(map[string]string, len(cfg.DefaultHeaders)+1)
+	for _, pair := range cfg.DefaultHeaders {
+		name, value, _ := strings.Cut(pair, "=")
+		headers[name] = value
+	}
+	if cfg.VersionHeaderEnabled {
+		headers[constants.HeaderAppVersion] = cfg.AppVersion
+	}
+	return headers
+}
@@ -0,0 +1,127 @@
+// Package accesstoken mints and verifies short-lived HS256 JSON Web
+// Tokens identifying a principal.
+//
+//	signer, _ := accesstoken.New(key, accesstoken.Options{TTL: 15 * time.Minute, Issuer: "api"})
+//	token, expiresAt, _ := signer.IssueAccess(ctx, "jane")
+//	claims, err := signer.Verify(token) // claims.Subject == "jane"
+//
+// Tokens carry the subject, issuer, issue and expiry times; they are not
+// revocable, so keep their lifetime short and pair them with refresh
+// tokens.
+package accesstoken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/clock"
+)
+
+// MinKeyLen is the shortest accepted signing key, the HS256 output size.
+const MinKeyLen = sha256.Size
+
+// defaultTTL is the token lifetime when Options.TTL is not set.
+const defaultTTL = 15 * time.Minute
+
+// header is the encoded JOSE header of every token.
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// ErrInvalidToken is returned for malformed, forged, foreign or expired
+// tokens.
+var ErrInvalidToken = errors.New("accesstoken: invalid token")
+
+// Options configures a Signer.
+type Options struct {
+	TTL    time.Duration // Token lifetime (default 15m)
+	Issuer string        // "iss" claim, checked on verification when set
+	Clock  clock.Clock   // Tells issue and expiry times (nil uses the system clock)
+}
+
+// Claims are the registered claims of a token.
+type Claims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer mints and verifies tokens with one HMAC key.
+type Signer struct {
+	key   []byte
+	opts  Options
+	clock clock.Clock
+}
+
+// New creates a signer over key, which must be at least MinKeyLen bytes.
+func New(key []byte, opts Options) (*Signer, error) {
+	if len(key) < MinKeyLen {
+		return nil, fmt.Errorf("accesstoken: signing key must be at least %d bytes", MinKeyLen)
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+	return &Signer{key: key, opts: opts, clock: clock.OrReal(opts.Clock)}, nil
+}
+
+// IssueAccess mints a token for principal, implementing
+// sessions.AccessIssuer.
+func (s *Signer) IssueAccess(_ context.Context, principal string) (string, time.Time, error) {
+	if principal == "" {
+		return "", time.Time{}, errors.New("accesstoken: empty principal")
+	}
+	now := s.clock.Now()
+	expiresAt := now.Add(s.opts.TTL).Truncate(time.Second)
+	payload, err := json.Marshal(Claims{
+		Subject:   principal,
+		Issuer:    s.opts.Issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("accesstoken: encode claims: %w", err)
+	}
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + s.sign(signed), expiresAt, nil
+}
+
+// Verify checks the signature, issuer and expiry of token and returns its
+// claims.
+func (s *Signer) Verify(token string) (*Claims, error) {
+	head, rest, ok := strings.Cut(token, ".")
+	if !ok || head != header {
+		return nil, ErrInvalidToken
+	}
+	payload, sig, ok := strings.Cut(rest, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(head+"."+payload))) {
+		return nil, ErrInvalidToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(raw, &claims); err != nil || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	if s.opts.Issuer != "" && claims.Issuer != s.opts.Issuer {
+		return nil, ErrInvalidToken
+	}
+	if s.clock.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// sign returns the encoded HS256 signature of signed.
+func (s *Signer) sign(signed string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(signed))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
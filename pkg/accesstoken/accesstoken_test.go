@@ -0,0 +1,72 @@
+package accesstoken
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = []byte(strings.Repeat("k", MinKeyLen))
+
+func TestSigner_IssueAndVerify(t *testing.T) {
+	clk := mocks.NewFakeClock(time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC))
+	signer, err := New(testKey, Options{TTL: 15 * time.Minute, Issuer: "api", Clock: clk})
+	require.NoError(t, err)
+
+	token, expiresAt, err := signer.IssueAccess(context.Background(), "jane")
+	require.NoError(t, err)
+	assert.Equal(t, clk.Now().Add(15*time.Minute), expiresAt)
+	assert.Len(t, strings.Split(token, "."), 3)
+
+	claims, err := signer.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, Claims{Subject: "jane", Issuer: "api", IssuedAt: clk.Now().Unix(), ExpiresAt: expiresAt.Unix()}, *claims)
+
+	clk.Set(expiresAt)
+	_, err = signer.Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "expired")
+}
+
+func TestSigner_RejectsForgedTokens(t *testing.T) {
+	signer, err := New(testKey, Options{Issuer: "api"})
+	require.NoError(t, err)
+	token, _, err := signer.IssueAccess(context.Background(), "jane")
+	require.NoError(t, err)
+
+	other, err := New([]byte(strings.Repeat("o", MinKeyLen)), Options{Issuer: "api"})
+	require.NoError(t, err)
+	_, err = other.Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "another key")
+
+	foreign, err := New(testKey, Options{Issuer: "billing"})
+	require.NoError(t, err)
+	_, err = foreign.Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "another issuer")
+
+	parts := strings.Split(token, ".")
+	for _, forged := range []string{"", "a.b", parts[0] + ".e30." + parts[2], "e30." + parts[1] + "." + parts[2]} {
+		_, err = signer.Verify(forged)
+		assert.ErrorIs(t, err, ErrInvalidToken, forged)
+	}
+}
+
+func TestNew_RejectsShortKeys(t *testing.T) {
+	_, err := New([]byte("short"), Options{})
+	assert.Error(t, err)
+
+	_, _, err = mustNew(t).IssueAccess(context.Background(), "")
+	assert.Error(t, err, "empty principal")
+}
+
+// mustNew returns a signer over testKey.
+func mustNew(t *testing.T) *Signer {
+	t.Helper()
+	signer, err := New(testKey, Options{})
+	require.NoError(t, err)
+	return signer
+}
@@ -0,0 +1,223 @@
+// Package address normalizes postal addresses entered in forms, so equal
+// addresses are stored alike and obviously wrong ones are refused:
+//
+//	a, err := address.Normalize(address.Address{
+//		Line1:      "  1600  Amphitheatre Pkwy ",
+//		City:       "Mountain View",
+//		Region:     "ca",
+//		PostalCode: "940431351",
+//		Country:    "United States",
+//	})
+//	// a.Region == "CA", a.PostalCode == "94043-1351", a.Country == "US"
+//
+// Countries are stored as ISO 3166-1 alpha-2 codes; names and alpha-3
+// codes of common countries are accepted. Postal codes are checked and
+// formatted for the countries with a known layout and kept as entered,
+// uppercased, elsewhere. Street names are not verified.
+package address
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/luminosita/change-me/pkg/sanitize"
+)
+
+// Errors returned by Normalize.
+var (
+	ErrMissingField      = errors.New("missing address field")
+	ErrUnknownCountry    = errors.New("unknown country")
+	ErrInvalidPostalCode = errors.New("invalid postal code")
+)
+
+// Address is a postal address.
+type Address struct {
+	Line1      string `json:"line1" pii:"address"`
+	Line2      string `json:"line2,omitempty" pii:"address"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"` // State, province or county
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"` // ISO 3166-1 alpha-2
+}
+
+// countryCodes lists the ISO 3166-1 alpha-2 codes.
+var countryCodes = toSet(`
+AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL
+BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV
+CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD
+GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM
+IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK
+LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW
+MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR
+PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS
+ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY
+UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`)
+
+// countryAliases maps names and alpha-3 codes of common countries, in
+// upper case, to their alpha-2 codes.
+var countryAliases = map[string]string{
+	"USA": "US", "UNITED STATES": "US", "UNITED STATES OF AMERICA": "US",
+	"GBR": "GB", "UK": "GB", "UNITED KINGDOM": "GB", "GREAT BRITAIN": "GB",
+	"CAN": "CA", "CANADA": "CA", "AUS": "AU", "AUSTRALIA": "AU",
+	"DEU": "DE", "GERMANY": "DE", "FRA": "FR", "FRANCE": "FR",
+	"ESP": "ES", "SPAIN": "ES", "ITA": "IT", "ITALY": "IT",
+	"NLD": "NL", "NETHERLANDS": "NL", "BEL": "BE", "BELGIUM": "BE",
+	"CHE": "CH", "SWITZERLAND": "CH", "AUT": "AT", "AUSTRIA": "AT",
+	"IRL": "IE", "IRELAND": "IE", "SWE": "SE", "SWEDEN": "SE",
+	"POL": "PL", "POLAND": "PL", "PRT": "PT", "PORTUGAL": "PT",
+	"SRB": "RS", "SERBIA": "RS", "HRV": "HR", "CROATIA": "HR",
+	"JPN": "JP", "JAPAN": "JP", "CHN": "CN", "CHINA": "CN",
+	"IND": "IN", "INDIA": "IN", "BRA": "BR", "BRAZIL": "BR",
+	"MEX": "MX", "MEXICO": "MX",
+}
+
+// postalLayouts are the postal code layouts of countries: 9 is a digit,
+// A a letter, other characters are separators.
+var postalLayouts = map[string][]string{
+	"US": {"99999", "99999-9999"},
+	"CA": {"A9A 9A9"},
+	"GB": {"A9 9AA", "A99 9AA", "AA9 9AA", "AA99 9AA", "A9A 9AA", "AA9A 9AA"},
+	"NL": {"9999 AA"},
+	"SE": {"999 99"},
+	"PL": {"99-999"},
+	"PT": {"9999-999"},
+	"JP": {"999-9999"},
+	"BR": {"99999-999"},
+	"DE": {"99999"}, "ES": {"99999"}, "FI": {"99999"}, "FR": {"99999"},
+	"HR": {"99999"}, "IT": {"99999"}, "MX": {"99999"}, "RS": {"99999"},
+	"AT": {"9999"}, "AU": {"9999"}, "BE": {"9999"}, "BG": {"9999"},
+	"CH": {"9999"}, "DK": {"9999"}, "HU": {"9999"}, "NO": {"9999"},
+	"NZ": {"9999"}, "SI": {"9999"}, "ZA": {"9999"},
+	"CN": {"999999"}, "IN": {"999999"}, "RO": {"999999"}, "RU": {"999999"},
+	"SG": {"999999"},
+}
+
+// codeRegions lists the countries whose regions are written as upper
+// case codes (states and provinces).
+var codeRegions = toSet("US CA AU BR MX")
+
+// genericPostalCode bounds postal codes of countries without a layout.
+var genericPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,11}$`)
+
+// CountryCode resolves an alpha-2 code, or the name or alpha-3 code of a
+// common country, to its alpha-2 code.
+func CountryCode(country string) (string, bool) {
+	key := strings.ToUpper(collapse(country))
+	if countryCodes[key] {
+		return key, true
+	}
+	code, ok := countryAliases[key]
+	return code, ok
+}
+
+// PostalCode returns code in the layout of country (alpha-2), e.g.
+// "sw1a1aa" as "SW1A 1AA" for GB.
+func PostalCode(country, code string) (string, error) {
+	compact := strings.NewReplacer(" ", "", "-", "").Replace(strings.ToUpper(collapse(code)))
+	layouts, ok := postalLayouts[country]
+	if !ok {
+		upper := strings.ToUpper(collapse(code))
+		if !genericPostalCode.MatchString(upper) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidPostalCode, code)
+		}
+		return upper, nil
+	}
+	for _, layout := range layouts {
+		if formatted, ok := applyLayout(layout, compact); ok {
+			return formatted, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q in %s", ErrInvalidPostalCode, code, country)
+}
+
+// Normalize returns a with collapsed whitespace, the alpha-2 country, an
+// upper case region code where regions are codes, and the postal code in
+// the layout of the country.
+//
+// Parameters:
+//   - a: Address as entered
+//
+// Returns:
+//   - Address: Normalized address
+//   - error: ErrMissingField (line1, city, country, or the postal code
+//     of a country with a layout), ErrUnknownCountry or ErrInvalidPostalCode
+func Normalize(a Address) (Address, error) {
+	out := Address{
+		Line1:      collapse(a.Line1),
+		Line2:      collapse(a.Line2),
+		City:       collapse(a.City),
+		Region:     collapse(a.Region),
+		PostalCode: collapse(a.PostalCode),
+	}
+	switch {
+	case out.Line1 == "":
+		return Address{}, fmt.Errorf("%w: line1", ErrMissingField)
+	case out.City == "":
+		return Address{}, fmt.Errorf("%w: city", ErrMissingField)
+	case strings.TrimSpace(a.Country) == "":
+		return Address{}, fmt.Errorf("%w: country", ErrMissingField)
+	}
+
+	country, ok := CountryCode(a.Country)
+	if !ok {
+		return Address{}, fmt.Errorf("%w: %q", ErrUnknownCountry, a.Country)
+	}
+	out.Country = country
+
+	if codeRegions[country] && len(out.Region) <= 3 {
+		out.Region = strings.ToUpper(out.Region)
+	}
+
+	if out.PostalCode == "" {
+		if _, ok := postalLayouts[country]; ok {
+			return Address{}, fmt.Errorf("%w: postal_code", ErrMissingField)
+		}
+		return out, nil
+	}
+	postal, err := PostalCode(country, out.PostalCode)
+	if err != nil {
+		return Address{}, err
+	}
+	out.PostalCode = postal
+	return out, nil
+}
+
+// applyLayout formats compact (no separators) in layout, reporting
+// whether it fits.
+func applyLayout(layout, compact string) (string, bool) {
+	var b strings.Builder
+	i := 0
+	for _, c := range layout {
+		switch c {
+		case '9', 'A':
+			if i >= len(compact) {
+				return "", false
+			}
+			ch := compact[i]
+			if (c == '9' && (ch < '0' || ch > '9')) || (c == 'A' && (ch < 'A' || ch > 'Z')) {
+				return "", false
+			}
+			b.WriteByte(ch)
+			i++
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String(), i == len(compact)
+}
+
+// collapse normalizes s to NFC, strips control characters and reduces
+// runs of whitespace to single spaces.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(sanitize.StripControl(sanitize.Normalize(s), false)), " ")
+}
+
+func toSet(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, item := range strings.Fields(list) {
+		set[item] = true
+	}
+	return set
+}
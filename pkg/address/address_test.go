@@ -0,0 +1,95 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize(Address{
+		Line1:      "  1600  Amphitheatre\tPkwy ",
+		City:       "Mountain View",
+		Region:     "ca",
+		PostalCode: "940431351",
+		Country:    "United States",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Address{
+		Line1:      "1600 Amphitheatre Pkwy",
+		City:       "Mountain View",
+		Region:     "CA",
+		PostalCode: "94043-1351",
+		Country:    "US",
+	}, got)
+}
+
+func TestNormalize_Errors(t *testing.T) {
+	valid := Address{Line1: "10 Downing Street", City: "London", PostalCode: "SW1A 2AA", Country: "GB"}
+	tests := map[string]struct {
+		mutate func(a *Address)
+		err    error
+	}{
+		"line1":           {func(a *Address) { a.Line1 = " " }, ErrMissingField},
+		"city":            {func(a *Address) { a.City = "" }, ErrMissingField},
+		"country":         {func(a *Address) { a.Country = "" }, ErrMissingField},
+		"unknown country": {func(a *Address) { a.Country = "Atlantis" }, ErrUnknownCountry},
+		"postal layout":   {func(a *Address) { a.PostalCode = "12345" }, ErrInvalidPostalCode},
+		"postal required": {func(a *Address) { a.PostalCode = "" }, ErrMissingField},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := valid
+			tt.mutate(&a)
+			_, err := Normalize(a)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err := Normalize(valid)
+	assert.NoError(t, err)
+}
+
+func TestPostalCode(t *testing.T) {
+	tests := []struct {
+		country string
+		code    string
+		want    string
+	}{
+		{"US", "94043", "94043"},
+		{"US", "94043 1351", "94043-1351"},
+		{"CA", "k1a0b1", "K1A 0B1"},
+		{"GB", "sw1a1aa", "SW1A 1AA"},
+		{"GB", "M1 1AE", "M1 1AE"},
+		{"NL", "1012ab", "1012 AB"},
+		{"SE", "11455", "114 55"},
+		{"PL", "00 950", "00-950"},
+		{"JP", "1000001", "100-0001"},
+		{"RS", "11000", "11000"},
+		{"IE", "d02 x285", "D02 X285"}, // no layout: kept, uppercased
+	}
+	for _, tt := range tests {
+		t.Run(tt.country+" "+tt.code, func(t *testing.T) {
+			got, err := PostalCode(tt.country, tt.code)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, bad := range [][2]string{{"US", "9404"}, {"CA", "123 456"}, {"DE", "1234a"}, {"IE", "D02 X285 !"}} {
+		_, err := PostalCode(bad[0], bad[1])
+		assert.ErrorIs(t, err, ErrInvalidPostalCode, bad)
+	}
+}
+
+func TestCountryCode(t *testing.T) {
+	for _, name := range []string{"de", "DEU", "Germany", " germany "} {
+		code, ok := CountryCode(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, "DE", code, name)
+	}
+	_, ok := CountryCode("Narnia")
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+// Package apikey verifies API keys against a keyring of their SHA-256
+// digests, so neither configuration nor anything downstream of the
+// verification holds the keys themselves.
+//
+//	keys, _ := apikey.Parse([]string{"ci=" + apikey.Digest("s3cret")})
+//	id, ok := keys.Lookup(r.Header.Get("X-API-Key")) // "ci", true
+//
+// Callers are identified by the ID a key is registered under, which is
+// safe to log and store.
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Keyring maps the digests of known keys to their IDs. The zero value
+// and nil know no keys.
+type Keyring struct {
+	ids map[[sha256.Size]byte]string
+}
+
+// Digest returns the hex SHA-256 digest of key, as registered in a
+// keyring. It is the output of `printf %s "$KEY" | sha256sum`.
+func Digest(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Parse builds a keyring from "id=digest" entries.
+func Parse(entries []string) (*Keyring, error) {
+	k := &Keyring{ids: make(map[[sha256.Size]byte]string, len(entries))}
+	for _, entry := range entries {
+		id, digest, ok := strings.Cut(entry, "=")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("apikey: entry %q is not id=digest", entry)
+		}
+		var sum [sha256.Size]byte
+		if len(digest) != hex.EncodedLen(len(sum)) {
+			return nil, fmt.Errorf("apikey: digest of %q is not a hex SHA-256 digest", id)
+		}
+		if _, err := hex.Decode(sum[:], []byte(digest)); err != nil {
+			return nil, fmt.Errorf("apikey: digest of %q is not a hex SHA-256 digest", id)
+		}
+		if other, dup := k.ids[sum]; dup {
+			return nil, fmt.Errorf("apikey: %q and %q share a key", other, id)
+		}
+		k.ids[sum] = id
+	}
+	return k, nil
+}
+
+// Lookup returns the ID of key, and false for empty or unknown keys.
+// Keys are compared by digest, so lookups take the same time whichever
+// key matches.
+func (k *Keyring) Lookup(key string) (string, bool) {
+	if k == nil || key == "" {
+		return "", false
+	}
+	id, ok := k.ids[sha256.Sum256([]byte(key))]
+	return id, ok
+}
+
+// Len returns the number of known keys.
+func (k *Keyring) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.ids)
+}
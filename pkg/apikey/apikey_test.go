@@ -0,0 +1,50 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigest(t *testing.T) {
+	// printf %s abc | sha256sum
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", Digest("abc"))
+}
+
+func TestKeyring_Lookup(t *testing.T) {
+	keys, err := Parse([]string{"ci=" + Digest("ci-key"), "ops=" + strings.ToUpper(Digest("ops-key"))})
+	require.NoError(t, err)
+	assert.Equal(t, 2, keys.Len())
+
+	id, ok := keys.Lookup("ci-key")
+	assert.True(t, ok)
+	assert.Equal(t, "ci", id)
+	id, ok = keys.Lookup("ops-key")
+	assert.True(t, ok)
+	assert.Equal(t, "ops", id)
+
+	for _, key := range []string{"", "unknown", Digest("ci-key")} {
+		_, ok := keys.Lookup(key)
+		assert.False(t, ok, key)
+	}
+
+	var none *Keyring
+	_, ok = none.Lookup("ci-key")
+	assert.False(t, ok)
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, entries := range [][]string{
+		{"ci"},
+		{"=" + Digest("k")},
+		{"ci=abc"},
+		{"ci=" + Digest("k") + "00"},
+		{"ci=" + strings.Repeat("z", 64)},
+		{"ci=" + Digest("k"), "ops=" + Digest("k")},
+	} {
+		_, err := Parse(entries)
+		assert.Error(t, err, entries)
+	}
+}
@@ -0,0 +1,325 @@
+// Package apisnapshot reduces the surface of the HTTP API, the mounted
+// route table and the OpenAPI contract, to sorted lines each describing
+// one fact about the shape of an endpoint:
+//
+//	route GET /api/v1/users/:id
+//	GET /api/v1/users param query limit integer
+//	GET /api/v1/users/{id} response 200 application/json .email string(email) required
+//
+// A snapshot committed next to the code is diffed against the current
+// surface, so endpoints do not change shape unintentionally. Lines stand
+// alone, which keeps diffs precise: a changed field shows as one removed
+// and one added line.
+package apisnapshot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxSchemaDepth bounds how deep nested schemas are described.
+const maxSchemaDepth = 12
+
+// header starts a written snapshot.
+const header = "# API snapshot: mounted routes and OpenAPI request/response shapes.\n" +
+	"# Regenerate with `api snapshot -update` after intended API changes.\n"
+
+// Route is a mounted route.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Snapshot is the sorted, de-duplicated lines describing an API.
+type Snapshot []string
+
+// Build describes the routes and the OpenAPI document spec.
+//
+// Parameters:
+//   - routes: Mounted routes, e.g. from gin.Engine.Routes
+//   - spec: OpenAPI 3 document (YAML or JSON)
+//
+// Returns:
+//   - Snapshot: Sorted lines
+//   - error: Spec parse error
+func Build(routes []Route, spec []byte) (Snapshot, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load OpenAPI document: %w", err)
+	}
+
+	var lines []string
+	for _, route := range routes {
+		lines = append(lines, "route "+route.Method+" "+route.Path)
+	}
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			d := describer{prefix: method + " " + path}
+			lines = append(lines, d.operation(item, op)...)
+		}
+	}
+	return normalize(lines), nil
+}
+
+// Parse reads a snapshot written by WriteTo. Blank lines and lines
+// starting with "#" are ignored.
+func Parse(r io.Reader) (Snapshot, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	return normalize(lines), nil
+}
+
+// WriteTo writes the snapshot with a header comment, one line per fact.
+func (s Snapshot) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	b.WriteString(header)
+	for _, line := range s {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// normalize sorts lines and drops duplicates.
+func normalize(lines []string) Snapshot {
+	sort.Strings(lines)
+	return Snapshot(slices.Compact(lines))
+}
+
+// Change is a line added to or removed from a snapshot.
+type Change struct {
+	Line  string
+	Added bool
+
+	// Breaking marks changes that can break existing clients: removed
+	// lines, and added required parameters or request fields
+	Breaking bool
+}
+
+// String formats the change as a diff line.
+func (c Change) String() string {
+	sign := "-"
+	if c.Added {
+		sign = "+"
+	}
+	return sign + " " + c.Line
+}
+
+// Diff returns the changes from old to current, removals first, each in
+// line order.
+func Diff(old, current Snapshot) []Change {
+	var removed, added []Change
+	i, j := 0, 0
+	for i < len(old) || j < len(current) {
+		switch {
+		case j == len(current) || (i < len(old) && old[i] < current[j]):
+			removed = append(removed, Change{Line: old[i], Breaking: true})
+			i++
+		case i == len(old) || current[j] < old[i]:
+			added = append(added, Change{Line: current[j], Added: true, Breaking: requiresInput(current[j])})
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return append(removed, added...)
+}
+
+// requiresInput reports whether an added line makes clients send
+// something they did not have to before.
+func requiresInput(line string) bool {
+	if !strings.HasSuffix(line, " required") {
+		return false
+	}
+	return strings.Contains(line, " param ") || strings.Contains(line, " request ")
+}
+
+// describer describes the operation at prefix ("METHOD /path").
+type describer struct {
+	prefix string
+	lines  []string
+}
+
+// add appends a line for the operation.
+func (d *describer) add(parts ...string) {
+	d.lines = append(d.lines, d.prefix+" "+strings.Join(parts, " "))
+}
+
+// operation describes op with the parameters shared by its path item.
+func (d *describer) operation(item *openapi3.PathItem, op *openapi3.Operation) []string {
+	d.add("operation")
+	if op.Deprecated {
+		d.add("deprecated")
+	}
+	for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+		for _, ref := range params {
+			if p := ref.Value; p != nil {
+				d.add("param", p.In, p.Name, schemaType(p.Schema)+required(p.Required))
+			}
+		}
+	}
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		body := op.RequestBody.Value
+		for _, contentType := range sortedKeys(body.Content) {
+			d.add("request", contentType+required(body.Required))
+			d.schema("request "+contentType, ".", body.Content[contentType].Schema, nil, 0)
+		}
+	}
+	if op.Responses != nil {
+		responses := op.Responses.Map()
+		for _, status := range sortedKeys(responses) {
+			resp := responses[status].Value
+			d.add("response", status)
+			if resp == nil {
+				continue
+			}
+			for _, contentType := range sortedKeys(resp.Content) {
+				d.add("response", status, contentType)
+				d.schema("response "+status+" "+contentType, ".", resp.Content[contentType].Schema, nil, 0)
+			}
+		}
+	}
+	return d.lines
+}
+
+// schema describes the schema at pointer ptr and its nested schemas.
+// seen holds the references being described, to stop at cycles.
+func (d *describer) schema(kind, ptr string, ref *openapi3.SchemaRef, seen []string, depth int) {
+	if ref == nil || ref.Value == nil || depth > maxSchemaDepth {
+		return
+	}
+	if ref.Ref != "" {
+		if slices.Contains(seen, ref.Ref) {
+			d.add(kind, ptr, "cycle", refName(ref.Ref))
+			return
+		}
+		seen = append(seen, ref.Ref)
+	}
+	s := ref.Value
+	if ptr == "." {
+		d.add(kind, ptr, schemaType(ref))
+	}
+
+	// allOf merges the properties of its members
+	properties := map[string]*openapi3.SchemaRef{}
+	requiredProps := map[string]bool{}
+	members := []*openapi3.SchemaRef{ref}
+	for _, member := range s.AllOf {
+		if member.Ref != "" {
+			if slices.Contains(seen, member.Ref) {
+				d.add(kind, ptr, "cycle", refName(member.Ref))
+				continue
+			}
+			seen = append(seen, member.Ref)
+		}
+		members = append(members, member)
+	}
+	for _, member := range members {
+		if member.Value == nil {
+			continue
+		}
+		for name, prop := range member.Value.Properties {
+			properties[name] = prop
+		}
+		for _, name := range member.Value.Required {
+			requiredProps[name] = true
+		}
+	}
+	for _, name := range sortedKeys(properties) {
+		child := strings.TrimSuffix(ptr, ".") + "." + name
+		d.add(kind, child, schemaType(properties[name])+required(requiredProps[name]))
+		d.schema(kind, child, properties[name], seen, depth+1)
+	}
+
+	if s.Items != nil {
+		child := strings.TrimSuffix(ptr, ".") + "[]"
+		if ptr == "." {
+			child = ".[]"
+		}
+		d.add(kind, child, schemaType(s.Items))
+		d.schema(kind, child, s.Items, seen, depth+1)
+	}
+	if extra := s.AdditionalProperties.Schema; extra != nil {
+		child := strings.TrimSuffix(ptr, ".") + ".*"
+		d.add(kind, child, schemaType(extra))
+		d.schema(kind, child, extra, seen, depth+1)
+	}
+	for name, variants := range map[string]openapi3.SchemaRefs{"oneOf": s.OneOf, "anyOf": s.AnyOf} {
+		for i, variant := range variants {
+			child := fmt.Sprintf("%s|%s%d", ptr, name, i)
+			d.add(kind, child, schemaType(variant))
+			d.schema(kind, child, variant, seen, depth+1)
+		}
+	}
+}
+
+// schemaType formats the type of a schema, e.g. "string(date-time)",
+// "string enum[a,b]" or "object nullable".
+func schemaType(ref *openapi3.SchemaRef) string {
+	if ref == nil || ref.Value == nil {
+		return "any"
+	}
+	s := ref.Value
+	typ := "any"
+	if s.Type != nil && len(s.Type.Slice()) > 0 {
+		typ = strings.Join(s.Type.Slice(), "|")
+	} else if len(s.Properties) > 0 || len(s.AllOf) > 0 {
+		typ = "object"
+	}
+	if s.Format != "" {
+		typ += "(" + s.Format + ")"
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		typ += " enum[" + strings.Join(values, ",") + "]"
+	}
+	if s.Nullable {
+		typ += " nullable"
+	}
+	return typ
+}
+
+// required returns the marker of required parameters and fields.
+func required(ok bool) string {
+	if ok {
+		return " required"
+	}
+	return ""
+}
+
+// refName returns the component name of a reference.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
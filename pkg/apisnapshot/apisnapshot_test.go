@@ -0,0 +1,142 @@
+package apisnapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/luminosita/change-me/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info: {title: test, version: "1"}
+paths:
+  /nodes/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string, format: uuid}}
+    get:
+      parameters:
+        - {name: depth, in: query, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Node"}
+        "404": {description: missing}
+    put:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              allOf:
+                - {$ref: "#/components/schemas/Base"}
+                - type: object
+                  required: [label]
+                  properties:
+                    label: {type: string}
+      responses:
+        "204": {description: updated}
+components:
+  schemas:
+    Base:
+      type: object
+      properties:
+        kind: {type: string, enum: [leaf, branch]}
+    Node:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        children:
+          type: array
+          items: {$ref: "#/components/schemas/Node"}
+        parent: {allOf: [{$ref: "#/components/schemas/Node"}], nullable: true}
+`
+
+func TestBuild_DescribesRoutesAndShapes(t *testing.T) {
+	snapshot, err := Build([]Route{{Method: "GET", Path: "/nodes/:id"}}, []byte(testSpec))
+	require.NoError(t, err)
+
+	assert.Equal(t, Snapshot{
+		"GET /nodes/{id} operation",
+		"GET /nodes/{id} param path id string(uuid) required",
+		"GET /nodes/{id} param query depth integer",
+		"GET /nodes/{id} response 200",
+		"GET /nodes/{id} response 200 application/json",
+		"GET /nodes/{id} response 200 application/json . object",
+		"GET /nodes/{id} response 200 application/json .children array",
+		"GET /nodes/{id} response 200 application/json .children[] object",
+		"GET /nodes/{id} response 200 application/json .children[] cycle Node",
+		"GET /nodes/{id} response 200 application/json .id string required",
+		"GET /nodes/{id} response 200 application/json .parent object nullable",
+		"GET /nodes/{id} response 200 application/json .parent cycle Node",
+		"GET /nodes/{id} response 404",
+		"PUT /nodes/{id} operation",
+		"PUT /nodes/{id} param path id string(uuid) required",
+		"PUT /nodes/{id} request application/json required",
+		"PUT /nodes/{id} request application/json . object",
+		"PUT /nodes/{id} request application/json .kind string enum[leaf,branch]",
+		"PUT /nodes/{id} request application/json .label string required",
+		"PUT /nodes/{id} response 204",
+		"route GET /nodes/:id",
+	}.sorted(), snapshot)
+}
+
+func TestBuild_EmbeddedContract(t *testing.T) {
+	snapshot, err := Build(nil, api.OpenAPISpec)
+	require.NoError(t, err)
+
+	assert.Contains(t, snapshot, "GET /health operation")
+	assert.Contains(t, snapshot, "POST /api/v1/users request application/json .email string(email) required")
+}
+
+func TestSnapshot_WriteAndParse(t *testing.T) {
+	snapshot := Snapshot{"GET /a operation", "route GET /a"}
+
+	var buf bytes.Buffer
+	_, err := snapshot.WriteTo(&buf)
+	require.NoError(t, err)
+	parsed, err := Parse(strings.NewReader(buf.String() + "\n# trailing comment\nroute GET /a\n"))
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), "# API snapshot"))
+	assert.Equal(t, snapshot, parsed)
+}
+
+func TestDiff_ClassifiesChanges(t *testing.T) {
+	old := Snapshot{
+		"GET /a operation",
+		"GET /a response 200 application/json .name string required",
+		"route GET /a",
+	}.sorted()
+	current := Snapshot{
+		"GET /a operation",
+		"GET /a param query page integer",
+		"GET /a param query tenant string required",
+		"GET /a response 200 application/json .name string",
+		"POST /a request application/json .id string required",
+		"route GET /a",
+	}.sorted()
+
+	changes := Diff(old, current)
+
+	assert.Equal(t, []Change{
+		{Line: "GET /a response 200 application/json .name string required", Breaking: true},
+		{Line: "GET /a param query page integer", Added: true},
+		{Line: "GET /a param query tenant string required", Added: true, Breaking: true},
+		{Line: "GET /a response 200 application/json .name string", Added: true},
+		{Line: "POST /a request application/json .id string required", Added: true, Breaking: true},
+	}, changes)
+	assert.Equal(t, "+ GET /a param query page integer", changes[1].String())
+	assert.Empty(t, Diff(current, current))
+}
+
+// sorted returns the lines normalized like Build output.
+func (s Snapshot) sorted() Snapshot {
+	return normalize(append([]string(nil), s...))
+}
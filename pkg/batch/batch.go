@@ -0,0 +1,145 @@
+// Package batch provides service-layer helpers for processing collections of
+// independent operations with bounded concurrency and per-item outcomes.
+package batch
+
+import (
+	"context"
+	"sync"
+)
+
+// Options configures Process.
+type Options struct {
+	// Concurrency is the number of items processed in parallel (default 1).
+	Concurrency int
+
+	// ChunkSize splits items into sequential chunks; each chunk is processed
+	// with Concurrency workers before the next begins (0 = single chunk).
+	ChunkSize int
+
+	// StopOnError skips remaining chunks after the first failed item.
+	StopOnError bool
+}
+
+// Outcome is the result of processing one item.
+type Outcome[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// Skipped is the error recorded for items not processed because of
+// StopOnError or context cancellation.
+type Skipped struct {
+	Cause error
+}
+
+// Error implements error.
+func (e *Skipped) Error() string {
+	if e.Cause == nil {
+		return "skipped"
+	}
+	return "skipped: " + e.Cause.Error()
+}
+
+// Unwrap returns the cause of skipping.
+func (e *Skipped) Unwrap() error {
+	return e.Cause
+}
+
+// Process applies fn to every item and returns one outcome per item in input
+// order. Individual failures do not stop processing unless StopOnError is set.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation
+//   - items: Inputs to process
+//   - opts: Concurrency and chunking options
+//   - fn: Operation applied to each item
+//
+// Returns:
+//   - []Outcome[Out]: Per-item results, indexed like items
+func Process[In, Out any](ctx context.Context, items []In, opts Options, fn func(ctx context.Context, item In) (Out, error)) []Outcome[Out] {
+	outcomes := make([]Outcome[Out], len(items))
+	for i := range outcomes {
+		outcomes[i].Index = i
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	chunk := opts.ChunkSize
+	if chunk <= 0 {
+		chunk = len(items)
+	}
+
+	failed := false
+	for start := 0; start < len(items); start += chunk {
+		end := min(start+chunk, len(items))
+
+		if err := ctx.Err(); err != nil || (opts.StopOnError && failed) {
+			skip(outcomes[start:], err)
+			break
+		}
+
+		if processChunk(ctx, items, outcomes, start, end, workers, fn) {
+			failed = true
+		}
+	}
+
+	return outcomes
+}
+
+// Succeeded counts outcomes without errors.
+func Succeeded[T any](outcomes []Outcome[T]) int {
+	n := 0
+	for _, o := range outcomes {
+		if o.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// processChunk runs fn over items[start:end] using workers goroutines and
+// reports whether any item failed.
+func processChunk[In, Out any](ctx context.Context, items []In, outcomes []Outcome[Out], start, end, workers int,
+	fn func(ctx context.Context, item In) (Out, error)) bool {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+		next   = make(chan int)
+	)
+
+	for w := 0; w < min(workers, end-start); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				value, err := fn(ctx, items[i])
+				outcomes[i].Value = value
+				outcomes[i].Err = err
+				if err != nil {
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := start; i < end; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return failed
+}
+
+// skip marks outcomes as not processed.
+func skip[T any](outcomes []Outcome[T], cause error) {
+	for i := range outcomes {
+		outcomes[i].Err = &Skipped{Cause: cause}
+	}
+}
@@ -0,0 +1,75 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errOdd = errors.New("odd")
+
+func double(ctx context.Context, n int) (int, error) {
+	if n%2 == 1 {
+		return 0, errOdd
+	}
+	return n * 2, nil
+}
+
+func TestProcess_PartialSuccessPreservesOrder(t *testing.T) {
+	outcomes := Process(context.Background(), []int{2, 3, 4}, Options{Concurrency: 3}, double)
+
+	require.Len(t, outcomes, 3)
+	assert.Equal(t, 4, outcomes[0].Value)
+	assert.ErrorIs(t, outcomes[1].Err, errOdd)
+	assert.Equal(t, 8, outcomes[2].Value)
+	assert.Equal(t, 1, outcomes[1].Index)
+	assert.Equal(t, 2, Succeeded(outcomes))
+}
+
+func TestProcess_StopOnErrorSkipsLaterChunks(t *testing.T) {
+	outcomes := Process(context.Background(), []int{1, 2, 4, 6}, Options{ChunkSize: 2, StopOnError: true}, double)
+
+	assert.ErrorIs(t, outcomes[0].Err, errOdd)
+	assert.NoError(t, outcomes[1].Err, "items in the failing chunk still run")
+
+	var skipped *Skipped
+	assert.ErrorAs(t, outcomes[2].Err, &skipped)
+	assert.ErrorAs(t, outcomes[3].Err, &skipped)
+}
+
+func TestProcess_BoundsConcurrency(t *testing.T) {
+	var active, peak int64
+	fn := func(ctx context.Context, n int) (int, error) {
+		cur := atomic.AddInt64(&active, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if cur <= p || atomic.CompareAndSwapInt64(&peak, p, cur) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt64(&active, -1)
+		return n, nil
+	}
+
+	items := make([]int, 20)
+	Process(context.Background(), items, Options{Concurrency: 3}, fn)
+
+	assert.LessOrEqual(t, atomic.LoadInt64(&peak), int64(3))
+}
+
+func TestProcess_CanceledContextSkipsItems(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outcomes := Process(ctx, []int{2, 4}, Options{}, double)
+
+	for _, o := range outcomes {
+		assert.ErrorIs(t, o.Err, context.Canceled)
+	}
+}
@@ -0,0 +1,135 @@
+// Package breaker implements a consecutive-failure circuit breaker.
+//
+// The breaker opens after a run of failures, rejects calls during a
+// cooldown, then lets a single probe through (half-open). A successful
+// probe closes the circuit; a failed one reopens it.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the circuit is open.
+var ErrOpen = errors.New("circuit breaker open")
+
+// State is the breaker state.
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+// String returns the state name.
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a circuit breaker safe for concurrent use.
+type Breaker struct {
+	mu         sync.Mutex
+	threshold  int
+	cooldown   time.Duration
+	failures   int
+	state      State
+	changedAt  time.Time
+	generation uint64
+	now        func() time.Time
+}
+
+// Call is a call admitted by Allow; report its outcome with Success or
+// Failure. Outcomes of calls admitted before the last state change are
+// ignored, so calls started while closed cannot settle a half-open probe.
+type Call struct {
+	b          *Breaker
+	generation uint64
+}
+
+// New creates a breaker that opens after threshold consecutive failures
+// and stays open for cooldown. Non-positive values default to 5 and 30s.
+func New(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may proceed. While half-open only the
+// probe proceeds; a probe that reports no outcome within the cooldown is
+// abandoned for a new one.
+func (b *Breaker) Allow() (Call, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open, HalfOpen:
+		if b.now().Sub(b.changedAt) < b.cooldown {
+			return Call{}, ErrOpen
+		}
+		b.transition(HalfOpen)
+	}
+	return Call{b: b, generation: b.generation}, nil
+}
+
+// Success records a successful call and closes the circuit.
+func (c Call) Success() {
+	b := c.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c.generation != b.generation {
+		return
+	}
+	b.failures = 0
+	if b.state != Closed {
+		b.transition(Closed)
+	}
+}
+
+// Failure records a failed call and opens the circuit once the threshold
+// is reached or a half-open probe fails.
+func (c Call) Failure() {
+	b := c.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c.generation != b.generation {
+		return
+	}
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.transition(Open)
+	}
+}
+
+// State returns the current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// transition enters state, leaving the calls admitted so far without a
+// say. Callers hold b.mu.
+func (b *Breaker) transition(state State) {
+	b.state = state
+	b.changedAt = b.now()
+	b.generation++
+}
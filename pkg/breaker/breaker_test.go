@@ -0,0 +1,101 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b := New(2, time.Minute)
+
+	first, err := b.Allow()
+	require.NoError(t, err)
+	second, err := b.Allow()
+	require.NoError(t, err)
+	first.Failure()
+	assert.Equal(t, Closed, b.State())
+	second.Failure()
+
+	assert.Equal(t, Open, b.State())
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	b := New(1, time.Second)
+	b.now = func() time.Time { return now }
+
+	call, err := b.Allow()
+	require.NoError(t, err)
+	call.Failure()
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+
+	now = now.Add(2 * time.Second)
+	probe, err := b.Allow()
+	assert.NoError(t, err, "first call after cooldown is a probe")
+	assert.Equal(t, HalfOpen, b.State())
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen, "only one probe at a time")
+
+	probe.Failure()
+	assert.Equal(t, Open, b.State())
+
+	now = now.Add(2 * time.Second)
+	probe, err = b.Allow()
+	require.NoError(t, err)
+	probe.Success()
+	assert.Equal(t, Closed, b.State())
+	_, err = b.Allow()
+	assert.NoError(t, err)
+}
+
+func TestBreaker_IgnoresCallsAdmittedBeforeTheProbe(t *testing.T) {
+	now := time.Now()
+	b := New(1, time.Second)
+	b.now = func() time.Time { return now }
+
+	slow, err := b.Allow()
+	require.NoError(t, err)
+	failing, err := b.Allow()
+	require.NoError(t, err)
+	failing.Failure()
+
+	now = now.Add(2 * time.Second)
+	probe, err := b.Allow()
+	require.NoError(t, err)
+
+	slow.Success()
+	assert.Equal(t, HalfOpen, b.State(), "a call started while closed does not settle the probe")
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+
+	probe.Success()
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestBreaker_ReplacesAbandonedProbe(t *testing.T) {
+	now := time.Now()
+	b := New(1, time.Second)
+	b.now = func() time.Time { return now }
+
+	call, err := b.Allow()
+	require.NoError(t, err)
+	call.Failure()
+	now = now.Add(2 * time.Second)
+	abandoned, err := b.Allow()
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Second)
+	probe, err := b.Allow()
+	require.NoError(t, err, "a probe without an outcome within the cooldown is replaced")
+
+	abandoned.Failure()
+	assert.Equal(t, HalfOpen, b.State())
+	probe.Success()
+	assert.Equal(t, Closed, b.State())
+}
@@ -0,0 +1,64 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/breaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "s3cret", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+		if r.PostForm.Get("response") == "solved" {
+			_, _ = w.Write([]byte(`{"success":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+	}))
+	defer provider.Close()
+
+	v, err := New(Config{Provider: Turnstile, Secret: "s3cret", VerifyURL: provider.URL}, provider.Client())
+	require.NoError(t, err)
+
+	ok, err := v.Verify(context.Background(), "solved", "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = v.Verify(context.Background(), "forged", "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestVerifier_ProviderFailureOpensBreaker(t *testing.T) {
+	calls := 0
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer provider.Close()
+
+	v, err := New(Config{Provider: HCaptcha, Secret: "s3cret", VerifyURL: provider.URL, Breaker: breaker.New(2, time.Minute)}, provider.Client())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = v.Verify(context.Background(), "token", "")
+		assert.Error(t, err)
+	}
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Equal(t, 2, calls)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(Config{Provider: "captchaco", Secret: "s"}, http.DefaultClient)
+	assert.ErrorContains(t, err, "unknown provider")
+	_, err = New(Config{Provider: ReCAPTCHA}, http.DefaultClient)
+	assert.ErrorContains(t, err, "secret required")
+}
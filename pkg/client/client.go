@@ -0,0 +1,171 @@
+// Package client is a typed Go SDK for the HTTP API, generated from
+// api/openapi.yaml by cmd/clientgen. client_gen.go holds one method per
+// operation and the schema types; this file the transport they share.
+//
+//	c := client.New("https://api.example.com", client.WithHeader("X-API-Key", key))
+//	user, err := c.GetUser(ctx, 1)
+//	var apiErr *client.Error
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound { ... }
+package client
+
+//go:generate go run github.com/luminosita/change-me/cmd/clientgen -spec ../../api/openapi.yaml -output client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the API at a base URL.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	header  http.Header
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through hc instead of http.DefaultClient,
+// e.g. one built by pkg/httpclient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithHeader sets a header on every request, e.g. the principal header.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Set(key, value) }
+}
+
+// WithBearerToken authenticates every request with token.
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// New creates a client of the API at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    http.DefaultClient,
+		header:  make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is a non-2xx response; Kind, Code, Message and Violations are
+// those of the API error body when it has one.
+type Error struct {
+	StatusCode int
+	Kind       string      `json:"error"`
+	Code       string      `json:"code"`
+	Message    string      `json:"message"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Error returns the status and message of the response.
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("api: %d %s", e.StatusCode, e.Message)
+}
+
+// request describes a call built by the generated methods.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        any    // Encoded as JSON unless an io.Reader
+	contentType string // Of an io.Reader body
+}
+
+// do sends req and decodes a JSON response into out, if not nil.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("api: decode %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// stream sends req and returns the body of a successful response, which
+// the caller closes.
+func (c *Client) stream(ctx context.Context, req request) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// send sends req, returning an *Error for non-2xx responses.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	contentType := req.contentType
+	switch b := req.body.(type) {
+	case nil:
+	case io.Reader:
+		body = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("api: encode %s %s request: %w", req.method, req.path, err)
+		}
+		body, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		httpReq.Header[key] = values
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return nil, apiErr
+	}
+	return resp, nil
+}
@@ -0,0 +1,1173 @@
+// Code generated by clientgen from CHANGE_ME API 0.1.0. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AcceptPolicyRequest is a schema of the API.
+type AcceptPolicyRequest struct {
+	// Latest version of the policy
+	Version string `json:"version"`
+}
+
+// AcceptanceResponse is a schema of the API.
+type AcceptanceResponse struct {
+	AcceptedAt time.Time `json:"accepted_at"`
+	ClientIP   *string   `json:"client_ip,omitempty"`
+	Policy     string    `json:"policy"`
+	Principal  string    `json:"principal"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	Version    string    `json:"version"`
+}
+
+// BulkCreateUsersRequest is a schema of the API.
+type BulkCreateUsersRequest struct {
+	// Up to 100 CreateUserRequest objects. Items are validated individually so invalid entries are reported per item instead of failing the request.
+	Items []map[string]any `json:"items"`
+}
+
+// BulkUserResult is a schema of the API.
+type BulkUserResult struct {
+	Data   *UserResponse  `json:"data,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+	Index  int            `json:"index"`
+	Status int            `json:"status"`
+}
+
+// BulkUsersResponse is a schema of the API.
+type BulkUsersResponse struct {
+	Failed    int              `json:"failed"`
+	Results   []BulkUserResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+}
+
+// CompactResult is a schema of the API.
+type CompactResult struct {
+	SizeAfter  int64 `json:"size_after"`
+	SizeBefore int64 `json:"size_before"`
+}
+
+// ConfigResponse is a schema of the API.
+type ConfigResponse struct {
+	Settings map[string]Setting `json:"settings"`
+}
+
+// ConsentStatusResponse is a schema of the API.
+type ConsentStatusResponse struct {
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	// Omitted when the caller never accepted the policy
+	AcceptedVersion *string `json:"accepted_version,omitempty"`
+	// Whether the caller accepted the latest version
+	Current bool           `json:"current"`
+	Policy  PolicyResponse `json:"policy"`
+}
+
+// CreateUserRequest is a schema of the API.
+type CreateUserRequest struct {
+	Email    string  `json:"email"`
+	FullName *string `json:"full_name,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+	// International phone number, stored in E.164 form
+	Phone    *string `json:"phone,omitempty"`
+	Username string  `json:"username"`
+}
+
+// DeletionResponse is a schema of the API.
+type DeletionResponse struct {
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ID          string       `json:"id"`
+	PurgeAfter  time.Time    `json:"purge_after"`
+	RequestedAt time.Time    `json:"requested_at"`
+	RequestedBy *string      `json:"requested_by,omitempty"`
+	Status      string       `json:"status"`
+	Trail       []TrailEntry `json:"trail"`
+	UserID      string       `json:"user_id"`
+}
+
+// ErrorCatalog is a schema of the API.
+type ErrorCatalog struct {
+	Items []ErrorCatalogEntry `json:"items"`
+}
+
+// ErrorCatalogEntry is a schema of the API.
+type ErrorCatalogEntry struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	Status      int    `json:"status"`
+}
+
+// ErrorResponse is a schema of the API.
+type ErrorResponse struct {
+	// Machine-readable code listed by GET /api/v1/errors
+	Code *string `json:"code,omitempty"`
+	// Error category
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	// Offending fields of bodies rejected by strict JSON decoding
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// ExportResponse is a schema of the API.
+type ExportResponse struct {
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+	// Signed link to the archive, once the export succeeded
+	DownloadURL *string    `json:"download_url,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ID          string     `json:"id"`
+	RequestedBy *string    `json:"requested_by,omitempty"`
+	Status      string     `json:"status"`
+	UserID      string     `json:"user_id"`
+}
+
+// HealthCheckResponse is a schema of the API.
+type HealthCheckResponse struct {
+	Status        string    `json:"status"`
+	Timestamp     time.Time `json:"timestamp"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	Version       string    `json:"version"`
+}
+
+// JSONPatchOperation is a schema of the API.
+type JSONPatchOperation struct {
+	// Source JSON Pointer of move and copy operations
+	From *string `json:"from,omitempty"`
+	Op   string  `json:"op"`
+	// JSON Pointer (RFC 6901) of the target location
+	Path string `json:"path"`
+	// Value of add, replace and test operations
+	Value any `json:"value,omitempty"`
+}
+
+// LogoutAllResponse is a schema of the API.
+type LogoutAllResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// MiddlewareChainResponse is a schema of the API.
+type MiddlewareChainResponse struct {
+	Global []MiddlewareEntry `json:"global"`
+	Groups []MiddlewareGroup `json:"groups"`
+}
+
+// MiddlewareEntry is a schema of the API.
+type MiddlewareEntry struct {
+	After    []string `json:"after,omitempty"`
+	Enabled  bool     `json:"enabled"`
+	Name     string   `json:"name"`
+	Priority int      `json:"priority"`
+}
+
+// MiddlewareGroup is a schema of the API.
+type MiddlewareGroup struct {
+	Middleware []MiddlewareEntry `json:"middleware"`
+	Modules    []string          `json:"modules"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"`
+}
+
+// PolicyResponse is a schema of the API.
+type PolicyResponse struct {
+	// Omitted from listings
+	Body        *string   `json:"body,omitempty"`
+	Name        string    `json:"name"`
+	PublishedAt time.Time `json:"published_at"`
+	Title       *string   `json:"title,omitempty"`
+	URL         *string   `json:"url,omitempty"`
+	Version     string    `json:"version"`
+}
+
+// ProfileListResponse is a schema of the API.
+type ProfileListResponse struct {
+	Items []ProfileResponse `json:"items"`
+}
+
+// ProfileResponse is a schema of the API.
+type ProfileResponse struct {
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	// Where the profile is stored
+	Location  *string   `json:"location,omitempty"`
+	Seconds   int       `json:"seconds"`
+	SizeBytes *int      `json:"size_bytes,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Status    string    `json:"status"`
+	// Download path, once completed
+	URL *string `json:"url,omitempty"`
+}
+
+// PublishPolicyRequest is a schema of the API.
+type PublishPolicyRequest struct {
+	Body    *string `json:"body,omitempty"`
+	Name    string  `json:"name"`
+	Title   *string `json:"title,omitempty"`
+	URL     *string `json:"url,omitempty"`
+	Version string  `json:"version"`
+}
+
+// QuotaUsage is a schema of the API.
+type QuotaUsage struct {
+	Periods []QuotaUsagePeriodsItem `json:"periods"`
+	Subject string                  `json:"subject"`
+}
+
+// QuotaUsagePeriodsItem is a schema of the API.
+type QuotaUsagePeriodsItem struct {
+	// 0 is unlimited
+	Limit     int64     `json:"limit"`
+	Period    string    `json:"period"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+	Used      int64     `json:"used"`
+}
+
+// RefreshTokenRequest is a schema of the API.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ReindexResponse is a schema of the API.
+type ReindexResponse struct {
+	// Index version now serving searches
+	Index string `json:"index"`
+}
+
+// ReportInfo is a schema of the API.
+type ReportInfo struct {
+	Description string `json:"description"`
+	Name        string `json:"name"`
+}
+
+// ReportListResponse is a schema of the API.
+type ReportListResponse struct {
+	Items []ReportInfo `json:"items"`
+}
+
+// ReportOperationResponse is a schema of the API.
+type ReportOperationResponse struct {
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+	// Signed link to the file, once the operation succeeded
+	DownloadURL *string           `json:"download_url,omitempty"`
+	Error       *string           `json:"error,omitempty"`
+	Format      string            `json:"format"`
+	ID          string            `json:"id"`
+	Params      map[string]string `json:"params,omitempty"`
+	Progress    float64           `json:"progress"`
+	Report      string            `json:"report"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	Status      string            `json:"status"`
+}
+
+// SendNotificationRequest is a schema of the API.
+type SendNotificationRequest struct {
+	Channel string `json:"channel"`
+	// Template data
+	Data     map[string]any `json:"data,omitempty"`
+	Template string         `json:"template"`
+	To       []string       `json:"to"`
+}
+
+// SendNotificationResponse is a schema of the API.
+type SendNotificationResponse struct {
+	ID string `json:"id"`
+}
+
+// SetQuotaLimitsRequest is a schema of the API.
+type SetQuotaLimitsRequest struct {
+	Daily   *int64 `json:"daily,omitempty"`
+	Monthly *int64 `json:"monthly,omitempty"`
+}
+
+// SetToggleRequest is a schema of the API.
+type SetToggleRequest struct {
+	Enabled bool   `json:"enabled"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	// Status of requests to a disabled route, 404 or 503
+	Status *int `json:"status,omitempty"`
+	// Version of the toggle replaced
+	Version int64 `json:"version"`
+}
+
+// Setting is a schema of the API.
+type Setting struct {
+	File     *string `json:"file,omitempty"`
+	Redacted *bool   `json:"redacted,omitempty"`
+	Source   string  `json:"source"`
+	// Effective value
+	Value any `json:"value"`
+}
+
+// StartProfileRequest is a schema of the API.
+type StartProfileRequest struct {
+	Kind string `json:"kind"`
+	// Sampling duration of cpu, block and mutex profiles
+	Seconds *int `json:"seconds,omitempty"`
+}
+
+// StartReportRequest is a schema of the API.
+type StartReportRequest struct {
+	Format string            `json:"format"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// ToggleChangeResponse is a schema of the API.
+type ToggleChangeResponse struct {
+	Action    string         `json:"action"`
+	After     ToggleResponse `json:"after"`
+	At        time.Time      `json:"at"`
+	Before    ToggleResponse `json:"before"`
+	By        *string        `json:"by,omitempty"`
+	Kind      string         `json:"kind"`
+	Name      string         `json:"name"`
+	RequestID *string        `json:"request_id,omitempty"`
+}
+
+// ToggleResponse is a schema of the API.
+type ToggleResponse struct {
+	Enabled bool   `json:"enabled"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	// Status of requests to a disabled route
+	Status    *int       `json:"status,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy *string    `json:"updated_by,omitempty"`
+	Version   int64      `json:"version"`
+}
+
+// TokenResponse is a schema of the API.
+type TokenResponse struct {
+	AccessExpiresAt *time.Time `json:"access_expires_at,omitempty"`
+	// Present when an access token issuer is configured
+	AccessToken      *string   `json:"access_token,omitempty"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+}
+
+// TrailEntry is a schema of the API.
+type TrailEntry struct {
+	Action string    `json:"action"`
+	Actor  *string   `json:"actor,omitempty"`
+	At     time.Time `json:"at"`
+	Error  *string   `json:"error,omitempty"`
+	Source *string   `json:"source,omitempty"`
+}
+
+// TwoFactorBackupCodesResponse is a schema of the API.
+type TwoFactorBackupCodesResponse struct {
+	BackupCodes []string `json:"backup_codes"`
+}
+
+// TwoFactorCodeRequest is a schema of the API.
+type TwoFactorCodeRequest struct {
+	// One-time code or backup code
+	Code string `json:"code"`
+}
+
+// TwoFactorEnrollmentRequest is a schema of the API.
+type TwoFactorEnrollmentRequest struct {
+	// Account label shown by authenticator apps
+	Account string `json:"account"`
+}
+
+// TwoFactorEnrollmentResponse is a schema of the API.
+type TwoFactorEnrollmentResponse struct {
+	// Provisioning URI rendered as a QR code
+	OtpauthURI string `json:"otpauth_uri"`
+	// Base32 TOTP secret for manual entry
+	Secret string `json:"secret"`
+}
+
+// TwoFactorStatusResponse is a schema of the API.
+type TwoFactorStatusResponse struct {
+	BackupCodesCount int  `json:"backup_codes_count"`
+	Enabled          bool `json:"enabled"`
+}
+
+// UploadResponse is a schema of the API.
+type UploadResponse struct {
+	ContentType       *string   `json:"content_type,omitempty"`
+	DownloadExpiresAt time.Time `json:"download_expires_at"`
+	DownloadURL       string    `json:"download_url"`
+	Key               string    `json:"key"`
+	Name              string    `json:"name"`
+	Size              int64     `json:"size"`
+}
+
+// UsageSummary is a schema of the API.
+type UsageSummary struct {
+	End     time.Time                `json:"end"`
+	Period  string                   `json:"period"`
+	Routes  []UsageSummaryRoutesItem `json:"routes"`
+	Start   time.Time                `json:"start"`
+	Subject string                   `json:"subject"`
+	Totals  map[string]int           `json:"totals"`
+}
+
+// UsageSummaryRoutesItem is a schema of the API.
+type UsageSummaryRoutesItem struct {
+	BytesIn  int    `json:"bytes_in"`
+	BytesOut int    `json:"bytes_out"`
+	Jobs     int    `json:"jobs"`
+	Requests int    `json:"requests"`
+	Route    string `json:"route"`
+}
+
+// UserListResponse is a schema of the API.
+type UserListResponse struct {
+	Items []UserResponse `json:"items"`
+	Limit int            `json:"limit"`
+	// Cursor of the next page, omitted on the last one
+	NextCursor *string `json:"next_cursor,omitempty"`
+	Offset     int     `json:"offset"`
+	Total      int     `json:"total"`
+}
+
+// UserMergePatch Fields to change; null removes full_name and phone
+type UserMergePatch struct {
+	Email    *string `json:"email,omitempty"`
+	FullName *string `json:"full_name,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+	Username *string `json:"username,omitempty"`
+}
+
+// UserResponse is a schema of the API.
+type UserResponse struct {
+	CreatedAt time.Time `json:"created_at"`
+	Email     string    `json:"email"`
+	FullName  string    `json:"full_name"`
+	ID        string    `json:"id"`
+	IsActive  bool      `json:"is_active"`
+	// E.164 phone number, omitted when unset
+	Phone     *string   `json:"phone,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Username  string    `json:"username"`
+}
+
+// UserSearchHit is a schema of the API.
+type UserSearchHit struct {
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	// Matched fragments per field, terms wrapped in <em>
+	Highlights map[string][]string `json:"highlights,omitempty"`
+	ID         string              `json:"id"`
+	IsActive   bool                `json:"is_active"`
+	Score      float64             `json:"score"`
+	Username   string              `json:"username"`
+}
+
+// UserSearchResponse is a schema of the API.
+type UserSearchResponse struct {
+	Items  []UserSearchHit `json:"items"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+	Total  int             `json:"total"`
+}
+
+// Violation is a schema of the API.
+type Violation struct {
+	// unknown_field, type, syntax, max_depth, max_items or the failed validation rule
+	Code string `json:"code"`
+	// JSON path of the field, empty for the whole body
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// GetEffectiveConfig calls GET /admin/config: Describe the effective configuration.
+func (c *Client) GetEffectiveConfig(ctx context.Context) (*ConfigResponse, error) {
+	req := request{method: "GET", path: "/admin/config"}
+	var out ConfigResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAcceptancesParams holds the query and header parameters of ListAcceptances.
+type ListAcceptancesParams struct {
+	Principal string
+}
+
+// ListAcceptances calls GET /admin/consent/acceptances: List acceptances.
+func (c *Client) ListAcceptances(ctx context.Context, params *ListAcceptancesParams) (*[]AcceptanceResponse, error) {
+	req := request{method: "GET", path: "/admin/consent/acceptances"}
+	if params != nil {
+		req.query = url.Values{}
+		req.query.Set("principal", fmt.Sprint(params.Principal))
+	}
+	var out []AcceptanceResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PublishPolicy calls POST /admin/consent/policies: Publish policy version.
+func (c *Client) PublishPolicy(ctx context.Context, body PublishPolicyRequest) (*PolicyResponse, error) {
+	req := request{method: "POST", path: "/admin/consent/policies"}
+	req.body = body
+	var out PolicyResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DescribeMiddleware calls GET /admin/middleware: Describe the middleware chain.
+func (c *Client) DescribeMiddleware(ctx context.Context) (*MiddlewareChainResponse, error) {
+	req := request{method: "GET", path: "/admin/middleware"}
+	var out MiddlewareChainResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SendNotification calls POST /admin/notifications: Send a notification.
+func (c *Client) SendNotification(ctx context.Context, body SendNotificationRequest) (*SendNotificationResponse, error) {
+	req := request{method: "POST", path: "/admin/notifications"}
+	req.body = body
+	var out SendNotificationResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDeletion calls GET /admin/privacy/deletions/{id}: Get an account deletion request.
+func (c *Client) GetDeletion(ctx context.Context, id string) (*DeletionResponse, error) {
+	req := request{method: "GET", path: "/admin/privacy/deletions/" + url.PathEscape(fmt.Sprint(id))}
+	var out DeletionResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelDeletion calls DELETE /admin/privacy/deletions/{id}: Cancel an account deletion request.
+func (c *Client) CancelDeletion(ctx context.Context, id string) (*DeletionResponse, error) {
+	req := request{method: "DELETE", path: "/admin/privacy/deletions/" + url.PathEscape(fmt.Sprint(id))}
+	var out DeletionResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetExport calls GET /admin/privacy/exports/{id}: Get a data export.
+func (c *Client) GetExport(ctx context.Context, id string) (*ExportResponse, error) {
+	req := request{method: "GET", path: "/admin/privacy/exports/" + url.PathEscape(fmt.Sprint(id))}
+	var out ExportResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RequestDeletion calls POST /admin/privacy/users/{id}/deletion: Request account deletion.
+func (c *Client) RequestDeletion(ctx context.Context, id string) (*DeletionResponse, error) {
+	req := request{method: "POST", path: "/admin/privacy/users/" + url.PathEscape(fmt.Sprint(id)) + "/deletion"}
+	var out DeletionResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportUserData calls POST /admin/privacy/users/{id}/export: Export a user's data.
+func (c *Client) ExportUserData(ctx context.Context, id string) (*ExportResponse, error) {
+	req := request{method: "POST", path: "/admin/privacy/users/" + url.PathEscape(fmt.Sprint(id)) + "/export"}
+	var out ExportResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListProfiles calls GET /admin/profiles: List profile captures.
+func (c *Client) ListProfiles(ctx context.Context) (*ProfileListResponse, error) {
+	req := request{method: "GET", path: "/admin/profiles"}
+	var out ProfileListResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartProfile calls POST /admin/profiles: Capture a runtime profile.
+func (c *Client) StartProfile(ctx context.Context, body StartProfileRequest) (*ProfileResponse, error) {
+	req := request{method: "POST", path: "/admin/profiles"}
+	req.body = body
+	var out ProfileResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetProfile calls GET /admin/profiles/{id}: Get profile capture status.
+func (c *Client) GetProfile(ctx context.Context, id string) (*ProfileResponse, error) {
+	req := request{method: "GET", path: "/admin/profiles/" + url.PathEscape(fmt.Sprint(id))}
+	var out ProfileResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadProfile calls GET /admin/profiles/{id}/data: Download a profile.
+// The caller closes the returned body.
+func (c *Client) DownloadProfile(ctx context.Context, id string) (io.ReadCloser, error) {
+	req := request{method: "GET", path: "/admin/profiles/" + url.PathEscape(fmt.Sprint(id)) + "/data"}
+	if req.header == nil {
+		req.header = http.Header{}
+	}
+	req.header.Set("Accept", "application/octet-stream")
+	return c.stream(ctx, req)
+}
+
+// GetQuotaUsage calls GET /admin/quotas/{subject}: Inspect quota usage.
+func (c *Client) GetQuotaUsage(ctx context.Context, subject string) (*QuotaUsage, error) {
+	req := request{method: "GET", path: "/admin/quotas/" + url.PathEscape(fmt.Sprint(subject))}
+	var out QuotaUsage
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetQuotaLimits calls PUT /admin/quotas/{subject}/limits: Override quota limits.
+func (c *Client) SetQuotaLimits(ctx context.Context, subject string, body SetQuotaLimitsRequest) (*QuotaUsage, error) {
+	req := request{method: "PUT", path: "/admin/quotas/" + url.PathEscape(fmt.Sprint(subject)) + "/limits"}
+	req.body = body
+	var out QuotaUsage
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResetQuotaUsage calls DELETE /admin/quotas/{subject}/usage: Reset quota usage.
+func (c *Client) ResetQuotaUsage(ctx context.Context, subject string) error {
+	req := request{method: "DELETE", path: "/admin/quotas/" + url.PathEscape(fmt.Sprint(subject)) + "/usage"}
+	return c.do(ctx, req, nil)
+}
+
+// ReindexUsers calls POST /admin/search/users/reindex: Rebuild the users search index.
+func (c *Client) ReindexUsers(ctx context.Context) (*ReindexResponse, error) {
+	req := request{method: "POST", path: "/admin/search/users/reindex"}
+	var out ReindexResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BackupStore calls GET /admin/store/backup: Download an embedded store backup.
+// The caller closes the returned body.
+func (c *Client) BackupStore(ctx context.Context) (io.ReadCloser, error) {
+	req := request{method: "GET", path: "/admin/store/backup"}
+	if req.header == nil {
+		req.header = http.Header{}
+	}
+	req.header.Set("Accept", "application/octet-stream")
+	return c.stream(ctx, req)
+}
+
+// CompactStore calls POST /admin/store/compact: Compact the embedded store.
+func (c *Client) CompactStore(ctx context.Context) (*CompactResult, error) {
+	req := request{method: "POST", path: "/admin/store/compact"}
+	var out CompactResult
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListToggles calls GET /admin/toggles: List toggles.
+func (c *Client) ListToggles(ctx context.Context) (*[]ToggleResponse, error) {
+	req := request{method: "GET", path: "/admin/toggles"}
+	var out []ToggleResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetToggle calls PUT /admin/toggles: Override toggle.
+func (c *Client) SetToggle(ctx context.Context, body SetToggleRequest) (*ToggleResponse, error) {
+	req := request{method: "PUT", path: "/admin/toggles"}
+	req.body = body
+	var out ToggleResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResetToggleParams holds the query and header parameters of ResetToggle.
+type ResetToggleParams struct {
+	Kind    string
+	Name    string
+	Version int64
+}
+
+// ResetToggle calls DELETE /admin/toggles: Reset toggle.
+func (c *Client) ResetToggle(ctx context.Context, params *ResetToggleParams) error {
+	req := request{method: "DELETE", path: "/admin/toggles"}
+	if params != nil {
+		req.query = url.Values{}
+		req.query.Set("kind", fmt.Sprint(params.Kind))
+		req.query.Set("name", fmt.Sprint(params.Name))
+		req.query.Set("version", fmt.Sprint(params.Version))
+	}
+	return c.do(ctx, req, nil)
+}
+
+// ListToggleChangesParams holds the query and header parameters of ListToggleChanges.
+type ListToggleChangesParams struct {
+	Limit *int
+}
+
+// ListToggleChanges calls GET /admin/toggles/changes: List toggle changes.
+func (c *Client) ListToggleChanges(ctx context.Context, params *ListToggleChangesParams) (*[]ToggleChangeResponse, error) {
+	req := request{method: "GET", path: "/admin/toggles/changes"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Limit != nil {
+			req.query.Set("limit", fmt.Sprint(*params.Limit))
+		}
+	}
+	var out []ToggleChangeResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSubjectUsageParams holds the query and header parameters of GetSubjectUsage.
+type GetSubjectUsageParams struct {
+	Period *string
+}
+
+// GetSubjectUsage calls GET /admin/usage/{subject}: Get usage of a subject.
+func (c *Client) GetSubjectUsage(ctx context.Context, subject string, params *GetSubjectUsageParams) (*UsageSummary, error) {
+	req := request{method: "GET", path: "/admin/usage/" + url.PathEscape(fmt.Sprint(subject))}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Period != nil {
+			req.query.Set("period", fmt.Sprint(*params.Period))
+		}
+	}
+	var out UsageSummary
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTwoFactorStatus calls GET /api/v1/2fa: Get two-factor status.
+func (c *Client) GetTwoFactorStatus(ctx context.Context) (*TwoFactorStatusResponse, error) {
+	req := request{method: "GET", path: "/api/v1/2fa"}
+	var out TwoFactorStatusResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DisableTwoFactor calls POST /api/v1/2fa/disable: Disable two-factor authentication.
+func (c *Client) DisableTwoFactor(ctx context.Context, body TwoFactorCodeRequest) error {
+	req := request{method: "POST", path: "/api/v1/2fa/disable"}
+	req.body = body
+	return c.do(ctx, req, nil)
+}
+
+// EnrollTwoFactor calls POST /api/v1/2fa/enrollment: Start two-factor enrollment.
+func (c *Client) EnrollTwoFactor(ctx context.Context, body TwoFactorEnrollmentRequest) (*TwoFactorEnrollmentResponse, error) {
+	req := request{method: "POST", path: "/api/v1/2fa/enrollment"}
+	req.body = body
+	var out TwoFactorEnrollmentResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ConfirmTwoFactor calls POST /api/v1/2fa/enrollment/confirm: Confirm two-factor enrollment.
+func (c *Client) ConfirmTwoFactor(ctx context.Context, body TwoFactorCodeRequest) (*TwoFactorBackupCodesResponse, error) {
+	req := request{method: "POST", path: "/api/v1/2fa/enrollment/confirm"}
+	req.body = body
+	var out TwoFactorBackupCodesResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// VerifyTwoFactor calls POST /api/v1/2fa/verify: Verify a two-factor code.
+func (c *Client) VerifyTwoFactor(ctx context.Context, body TwoFactorCodeRequest) error {
+	req := request{method: "POST", path: "/api/v1/2fa/verify"}
+	req.body = body
+	return c.do(ctx, req, nil)
+}
+
+// Logout calls POST /api/v1/auth/logout: End a session.
+func (c *Client) Logout(ctx context.Context, body RefreshTokenRequest) error {
+	req := request{method: "POST", path: "/api/v1/auth/logout"}
+	req.body = body
+	return c.do(ctx, req, nil)
+}
+
+// LogoutAll calls POST /api/v1/auth/logout-all: End all sessions.
+func (c *Client) LogoutAll(ctx context.Context, body RefreshTokenRequest) (*LogoutAllResponse, error) {
+	req := request{method: "POST", path: "/api/v1/auth/logout-all"}
+	req.body = body
+	var out LogoutAllResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RefreshSession calls POST /api/v1/auth/refresh: Refresh a session.
+func (c *Client) RefreshSession(ctx context.Context, body RefreshTokenRequest) (*TokenResponse, error) {
+	req := request{method: "POST", path: "/api/v1/auth/refresh"}
+	req.body = body
+	var out TokenResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateSession calls POST /api/v1/auth/sessions: Start a session.
+func (c *Client) CreateSession(ctx context.Context) (*TokenResponse, error) {
+	req := request{method: "POST", path: "/api/v1/auth/sessions"}
+	var out TokenResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConsentStatus calls GET /api/v1/consent: Get consent status.
+func (c *Client) GetConsentStatus(ctx context.Context) (*[]ConsentStatusResponse, error) {
+	req := request{method: "GET", path: "/api/v1/consent"}
+	var out []ConsentStatusResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListPolicies calls GET /api/v1/consent/policies: List policies.
+func (c *Client) ListPolicies(ctx context.Context) (*[]PolicyResponse, error) {
+	req := request{method: "GET", path: "/api/v1/consent/policies"}
+	var out []PolicyResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPolicyParams holds the query and header parameters of GetPolicy.
+type GetPolicyParams struct {
+	Version *string
+}
+
+// GetPolicy calls GET /api/v1/consent/policies/{name}: Get policy.
+func (c *Client) GetPolicy(ctx context.Context, name string, params *GetPolicyParams) (*PolicyResponse, error) {
+	req := request{method: "GET", path: "/api/v1/consent/policies/" + url.PathEscape(fmt.Sprint(name))}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Version != nil {
+			req.query.Set("version", fmt.Sprint(*params.Version))
+		}
+	}
+	var out PolicyResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AcceptPolicy calls POST /api/v1/consent/policies/{name}/acceptances: Accept policy.
+func (c *Client) AcceptPolicy(ctx context.Context, name string, body AcceptPolicyRequest) (*AcceptanceResponse, error) {
+	req := request{method: "POST", path: "/api/v1/consent/policies/" + url.PathEscape(fmt.Sprint(name)) + "/acceptances"}
+	req.body = body
+	var out AcceptanceResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListErrorCodes calls GET /api/v1/errors: List error codes.
+func (c *Client) ListErrorCodes(ctx context.Context) (*ErrorCatalog, error) {
+	req := request{method: "GET", path: "/api/v1/errors"}
+	var out ErrorCatalog
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadFileParams holds the query and header parameters of DownloadFile.
+type DownloadFileParams struct {
+	Expires   int
+	Signature string
+}
+
+// DownloadFile calls GET /api/v1/files/{key}: Download a file.
+// The caller closes the returned body.
+func (c *Client) DownloadFile(ctx context.Context, key string, params *DownloadFileParams) (io.ReadCloser, error) {
+	req := request{method: "GET", path: "/api/v1/files/" + url.PathEscape(fmt.Sprint(key))}
+	if params != nil {
+		req.query = url.Values{}
+		req.query.Set("expires", fmt.Sprint(params.Expires))
+		req.query.Set("signature", fmt.Sprint(params.Signature))
+	}
+	if req.header == nil {
+		req.header = http.Header{}
+	}
+	req.header.Set("Accept", "*/*")
+	return c.stream(ctx, req)
+}
+
+// ListReports calls GET /api/v1/reports: List reports.
+func (c *Client) ListReports(ctx context.Context) (*ReportListResponse, error) {
+	req := request{method: "GET", path: "/api/v1/reports"}
+	var out ReportListResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReportOperation calls GET /api/v1/reports/operations/{id}: Get a report operation.
+func (c *Client) GetReportOperation(ctx context.Context, id string) (*ReportOperationResponse, error) {
+	req := request{method: "GET", path: "/api/v1/reports/operations/" + url.PathEscape(fmt.Sprint(id))}
+	var out ReportOperationResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartReport calls POST /api/v1/reports/{name}: Generate a report.
+func (c *Client) StartReport(ctx context.Context, name string, body StartReportRequest) (*ReportOperationResponse, error) {
+	req := request{method: "POST", path: "/api/v1/reports/" + url.PathEscape(fmt.Sprint(name))}
+	req.body = body
+	var out ReportOperationResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UploadFile calls POST /api/v1/uploads: Upload a file.
+func (c *Client) UploadFile(ctx context.Context, body io.Reader, contentType string) (*UploadResponse, error) {
+	req := request{method: "POST", path: "/api/v1/uploads"}
+	req.body = body
+	req.contentType = contentType
+	var out UploadResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOwnUsageParams holds the query and header parameters of GetOwnUsage.
+type GetOwnUsageParams struct {
+	Period *string
+}
+
+// GetOwnUsage calls GET /api/v1/usage: Get own usage.
+func (c *Client) GetOwnUsage(ctx context.Context, params *GetOwnUsageParams) (*UsageSummary, error) {
+	req := request{method: "GET", path: "/api/v1/usage"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Period != nil {
+			req.query.Set("period", fmt.Sprint(*params.Period))
+		}
+	}
+	var out UsageSummary
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListUsersParams holds the query and header parameters of ListUsers.
+type ListUsersParams struct {
+	Limit  *int
+	Offset *int
+	Cursor *string
+	Filter *string
+}
+
+// ListUsers calls GET /api/v1/users: List users.
+func (c *Client) ListUsers(ctx context.Context, params *ListUsersParams) (*UserListResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Limit != nil {
+			req.query.Set("limit", fmt.Sprint(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", fmt.Sprint(*params.Offset))
+		}
+		if params.Cursor != nil {
+			req.query.Set("cursor", fmt.Sprint(*params.Cursor))
+		}
+		if params.Filter != nil {
+			req.query.Set("filter", fmt.Sprint(*params.Filter))
+		}
+	}
+	var out UserListResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateUser calls POST /api/v1/users: Create user.
+func (c *Client) CreateUser(ctx context.Context, body CreateUserRequest) (*UserResponse, error) {
+	req := request{method: "POST", path: "/api/v1/users"}
+	req.body = body
+	var out UserResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkCreateUsers calls POST /api/v1/users/bulk: Create users in bulk.
+func (c *Client) BulkCreateUsers(ctx context.Context, body BulkCreateUsersRequest) (*BulkUsersResponse, error) {
+	req := request{method: "POST", path: "/api/v1/users/bulk"}
+	req.body = body
+	var out BulkUsersResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportUsersParams holds the query and header parameters of ExportUsers.
+type ExportUsersParams struct {
+	Format *string
+}
+
+// ExportUsers calls GET /api/v1/users/export: Export users.
+// The caller closes the returned body.
+func (c *Client) ExportUsers(ctx context.Context, params *ExportUsersParams) (io.ReadCloser, error) {
+	req := request{method: "GET", path: "/api/v1/users/export"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Format != nil {
+			req.query.Set("format", fmt.Sprint(*params.Format))
+		}
+	}
+	if req.header == nil {
+		req.header = http.Header{}
+	}
+	req.header.Set("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet, text/csv")
+	return c.stream(ctx, req)
+}
+
+// SearchUsersParams holds the query and header parameters of SearchUsers.
+type SearchUsersParams struct {
+	Q      *string
+	Active *bool
+	Limit  *int
+	Offset *int
+}
+
+// SearchUsers calls GET /api/v1/users/search: Search users.
+func (c *Client) SearchUsers(ctx context.Context, params *SearchUsersParams) (*UserSearchResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users/search"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Q != nil {
+			req.query.Set("q", fmt.Sprint(*params.Q))
+		}
+		if params.Active != nil {
+			req.query.Set("active", fmt.Sprint(*params.Active))
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", fmt.Sprint(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", fmt.Sprint(*params.Offset))
+		}
+	}
+	var out UserSearchResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetUser calls GET /api/v1/users/{id}: Get user.
+func (c *Client) GetUser(ctx context.Context, id string) (*UserResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(fmt.Sprint(id))}
+	var out UserResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateUser calls PATCH /api/v1/users/{id}: Update user.
+func (c *Client) UpdateUser(ctx context.Context, id string, body io.Reader, contentType string) (*UserResponse, error) {
+	req := request{method: "PATCH", path: "/api/v1/users/" + url.PathEscape(fmt.Sprint(id))}
+	req.body = body
+	req.contentType = contentType
+	var out UserResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteUser calls DELETE /api/v1/users/{id}: Delete user.
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/users/" + url.PathEscape(fmt.Sprint(id))}
+	return c.do(ctx, req, nil)
+}
+
+// HealthCheck calls GET /health: Health check endpoint.
+func (c *Client) HealthCheck(ctx context.Context) (*HealthCheckResponse, error) {
+	req := request{method: "GET", path: "/health"}
+	var out HealthCheckResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HealthReady calls GET /health/ready: Readiness probe.
+func (c *Client) HealthReady(ctx context.Context) (*HealthCheckResponse, error) {
+	req := request{method: "GET", path: "/health/ready"}
+	var out HealthCheckResponse
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SendsTypedRequests(t *testing.T) {
+	var got *http.Request
+	var body CreateUserRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"01890a5d-ac96-774b-bcce-b302099a8057","email":"jane@example.com","username":"jane","full_name":"","is_active":true,
+				"created_at":"2024-01-15T10:30:00Z","updated_at":"2024-01-15T10:30:00Z"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items":[],"limit":5,"offset":0,"total":0}`))
+	}))
+	defer srv.Close()
+	c := New(srv.URL+"/", WithHeader("X-API-Key", "jane"))
+	ctx := context.Background()
+
+	user, err := c.CreateUser(ctx, CreateUserRequest{Email: "jane@example.com", Username: "jane"})
+	require.NoError(t, err)
+	assert.Equal(t, "01890a5d-ac96-774b-bcce-b302099a8057", user.ID)
+	assert.Equal(t, 2024, user.CreatedAt.Year())
+	assert.Equal(t, "/api/v1/users", got.URL.Path)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "jane", got.Header.Get("X-API-Key"))
+	assert.Equal(t, "jane", body.Username)
+	assert.Nil(t, body.FullName)
+
+	limit := 5
+	page, err := c.ListUsers(ctx, &ListUsersParams{Limit: &limit})
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Limit)
+	assert.Equal(t, "limit=5", got.URL.RawQuery)
+}
+
+func TestClient_ReturnsAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/users/01890a5d-ac96-774b-bcce-b302099a8058" {
+			http.Error(w, "gone", http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"not_found","code":"user_not_found","message":"user not found"}`))
+	}))
+	defer srv.Close()
+	c := New(srv.URL)
+
+	_, err := c.GetUser(context.Background(), "01890a5d-ac96-774b-bcce-b302099a8057")
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "user_not_found", apiErr.Code)
+	assert.EqualError(t, err, "api: 404 user_not_found: user not found")
+
+	err = c.DeleteUser(context.Background(), "01890a5d-ac96-774b-bcce-b302099a8058")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "Bad Gateway", apiErr.Message)
+}
+
+func TestClient_StreamsFiles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "csv", r.URL.Query().Get("format"))
+		assert.Contains(t, r.Header.Get("Accept"), "text/csv")
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("ID,Email\n"))
+	}))
+	defer srv.Close()
+
+	format := "csv"
+	body, err := New(srv.URL).ExportUsers(context.Background(), &ExportUsersParams{Format: &format})
+	require.NoError(t, err)
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "ID,Email\n", string(data))
+}
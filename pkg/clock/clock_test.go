@@ -0,0 +1,38 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReal_TickersAndTimersFire(t *testing.T) {
+	clk := Real()
+	start := clk.Now()
+
+	ticker := clk.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	timer := clk.NewTimer(time.Millisecond)
+
+	select {
+	case <-ticker.C():
+	case <-time.After(time.Second):
+		t.Fatal("ticker did not fire")
+	}
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("timer did not fire")
+	}
+	assert.False(t, timer.Stop(), "fired timers are no longer active")
+	assert.Positive(t, clk.Since(start))
+}
+
+func TestOrReal(t *testing.T) {
+	require.NotNil(t, OrReal(nil))
+
+	clk := Real()
+	assert.Equal(t, clk, OrReal(clk))
+}
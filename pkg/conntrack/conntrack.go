@@ -0,0 +1,128 @@
+// Package conntrack tunes and observes inbound server connections: TCP
+// keep-alive probes on the listener, a per-connection request limit and
+// connection state metrics fed by http.Server.ConnState:
+//
+//	listener, err := conntrack.Listen(ctx, addr, conntrack.KeepAlive{Enabled: true})
+//	srv := &http.Server{
+//		Handler:     conntrack.MaxRequests(handler, 1000),
+//		ConnContext: conntrack.ConnContext,
+//		ConnState:   metrics.ConnState,
+//	}
+package conntrack
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// KeepAlive configures TCP keep-alive probes of accepted connections.
+// Zero durations and counts use the operating system defaults.
+type KeepAlive struct {
+	Enabled  bool
+	Idle     time.Duration // Idle time before the first probe
+	Interval time.Duration // Time between probes
+	Count    int           // Unanswered probes before the connection is dropped
+}
+
+// Listen announces on the TCP address addr with the keep-alive settings.
+func Listen(ctx context.Context, addr string, keepAlive KeepAlive) (net.Listener, error) {
+	lc := net.ListenConfig{KeepAliveConfig: net.KeepAliveConfig{
+		Enable:   keepAlive.Enabled,
+		Idle:     keepAlive.Idle,
+		Interval: keepAlive.Interval,
+		Count:    keepAlive.Count,
+	}}
+	if !keepAlive.Enabled {
+		lc.KeepAlive = -1
+	}
+	return lc.Listen(ctx, "tcp", addr)
+}
+
+// requestsKey carries the request counter of a connection.
+type requestsKey struct{}
+
+// ConnContext attaches a request counter to the context of a connection;
+// set it as http.Server.ConnContext for MaxRequests to take effect.
+func ConnContext(ctx context.Context, _ net.Conn) context.Context {
+	return context.WithValue(ctx, requestsKey{}, new(atomic.Int64))
+}
+
+// MaxRequests closes a connection after it served max requests by
+// answering the last one with "Connection: close", so long-lived clients
+// rebalance across instances. It returns next unchanged when max is zero.
+func MaxRequests(next http.Handler, max int) http.Handler {
+	if max <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if served, ok := r.Context().Value(requestsKey{}).(*atomic.Int64); ok && served.Add(1) >= int64(max) {
+			w.Header().Set("Connection", "close")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Metrics holds the connection collectors. A nil *Metrics records nothing.
+type Metrics struct {
+	connections *prometheus.GaugeVec
+	transitions *prometheus.CounterVec
+
+	mu     sync.Mutex
+	states map[net.Conn]http.ConnState
+}
+
+// NewMetrics creates the connection collectors and registers them.
+// It panics if the collectors are already registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_server_connections",
+			Help: "Open inbound connections by state (new, active, idle).",
+		}, []string{"state"}),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_connection_state_changes_total",
+			Help: "Inbound connection state changes by new state.",
+		}, []string{"state"}),
+		states: map[net.Conn]http.ConnState{},
+	}
+	reg.MustRegister(m.connections, m.transitions)
+	return m
+}
+
+// ConnState records a state change; set it as http.Server.ConnState.
+func (m *Metrics) ConnState(conn net.Conn, state http.ConnState) {
+	if m == nil {
+		return
+	}
+	m.transitions.WithLabelValues(state.String()).Inc()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if previous, ok := m.states[conn]; ok {
+		m.connections.WithLabelValues(previous.String()).Dec()
+	}
+	// Hijacked and closed connections are no longer tracked by the server
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(m.states, conn)
+	default:
+		m.states[conn] = state
+		m.connections.WithLabelValues(state.String()).Inc()
+	}
+}
+
+// Open returns the number of connections the server has not closed yet.
+func (m *Metrics) Open() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.states)
+}
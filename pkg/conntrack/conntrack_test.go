@@ -0,0 +1,75 @@
+package conntrack
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxRequests_ClosesConnectionAfterLimit(t *testing.T) {
+	srv := httptest.NewUnstartedServer(MaxRequests(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}), 2))
+	srv.Config.ConnContext = ConnContext
+	srv.Start()
+	defer srv.Close()
+
+	var closed []bool
+	for i := 0; i < 3; i++ {
+		resp, err := srv.Client().Get(srv.URL)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		closed = append(closed, resp.Close)
+	}
+	assert.Equal(t, []bool{false, true, false}, closed, "the second request ends the connection, the third opens a new one")
+}
+
+func TestMaxRequests_ZeroIsUnlimited(t *testing.T) {
+	next := http.NewServeMux()
+	assert.Same(t, next, MaxRequests(next, 0))
+}
+
+func TestMetrics_ConnState(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+
+	lis, err := Listen(context.Background(), "127.0.0.1:0", KeepAlive{Enabled: true})
+	require.NoError(t, err)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.connections.WithLabelValues(http.StateActive.String())))
+		assert.Equal(t, 1, metrics.Open())
+	}))
+	_ = srv.Listener.Close()
+	srv.Listener = lis
+	srv.Config.ConnState = metrics.ConnState
+	srv.Start()
+
+	resp, err := srv.Client().Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	srv.Close()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.transitions.WithLabelValues(http.StateNew.String())))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.transitions.WithLabelValues(http.StateClosed.String())))
+	assert.Zero(t, testutil.ToFloat64(metrics.connections.WithLabelValues(http.StateIdle.String())))
+	assert.Empty(t, metrics.states)
+	assert.Zero(t, metrics.Open())
+
+	var none *Metrics
+	none.ConnState(nil, http.StateNew)
+	assert.Zero(t, none.Open())
+}
+
+func TestListen_KeepAliveDisabled(t *testing.T) {
+	lis, err := Listen(context.Background(), "127.0.0.1:0", KeepAlive{})
+	require.NoError(t, err)
+	assert.NoError(t, lis.Close())
+}
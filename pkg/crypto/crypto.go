@@ -0,0 +1,194 @@
+// Package crypto provides field-level envelope encryption.
+//
+// Every value is encrypted with a fresh data key (AES-256-GCM) that is
+// itself wrapped by a key encryption key of a KeyProvider: a local Keyring
+// loaded from configuration or a KMS adapter. The key ID of the wrapping
+// key travels with the ciphertext, so keys can be rotated without a
+// migration: new values use the primary key, older ones still decrypt with
+// retired keys until Rotate re-wraps them.
+//
+//	keyring, _ := crypto.NewKeyring(keys, "2024-06")
+//	envelope := crypto.NewEnvelope(keyring)
+//	sealed, _ := envelope.Encrypt(ctx, []byte("4111 1111 1111 1111"), []byte("cards.number"))
+//
+// Structs mark sensitive string fields with an encrypt tag and are sealed
+// in place with EncryptFields and DecryptFields.
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the size of data and local key encryption keys (AES-256).
+const KeySize = 32
+
+// version prefixes ciphertexts of the current format.
+const version = "v1"
+
+// Errors returned by envelopes and keyrings.
+var (
+	ErrUnknownKey    = errors.New("crypto: unknown key id")
+	ErrMalformed     = errors.New("crypto: malformed ciphertext")
+	ErrDecryptFailed = errors.New("crypto: decryption failed")
+)
+
+// KeyProvider wraps and unwraps data keys with key encryption keys; KMS
+// clients implement it to keep the key material out of the process.
+type KeyProvider interface {
+	// WrapKey encrypts a data key with the primary key and returns the ID
+	// of that key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts a data key wrapped by the key keyID.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+
+	// PrimaryKeyID returns the ID of the key new data keys are wrapped with.
+	PrimaryKeyID() string
+}
+
+// Envelope encrypts values with data keys wrapped by a KeyProvider. It is
+// safe for concurrent use.
+type Envelope struct {
+	keys KeyProvider
+}
+
+// NewEnvelope creates an envelope backed by keys.
+func NewEnvelope(keys KeyProvider) *Envelope {
+	return &Envelope{keys: keys}
+}
+
+// Encrypt seals plaintext under a fresh data key. The associated data is
+// authenticated but not stored; pass the same value to Decrypt (for
+// example the table and column) so ciphertexts cannot be swapped between
+// fields. The result is printable and safe to store in a text column.
+func (e *Envelope) Encrypt(ctx context.Context, plaintext, associated []byte) (string, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("crypto: generate data key: %w", err)
+	}
+	sealed, err := seal(dataKey, plaintext, associated)
+	if err != nil {
+		return "", err
+	}
+	keyID, wrapped, err := e.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("crypto: wrap data key: %w", err)
+	}
+	return format(keyID, wrapped, sealed), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt with the same associated
+// data.
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext string, associated []byte) ([]byte, error) {
+	keyID, wrapped, sealed, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := e.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, sealed, associated)
+}
+
+// KeyID returns the ID of the key that wrapped the data key of ciphertext.
+func KeyID(ciphertext string) (string, error) {
+	keyID, _, _, err := parse(ciphertext)
+	return keyID, err
+}
+
+// NeedsRotation reports whether ciphertext is wrapped by a key other than
+// the primary one.
+func (e *Envelope) NeedsRotation(ciphertext string) bool {
+	keyID, err := KeyID(ciphertext)
+	return err == nil && keyID != e.keys.PrimaryKeyID()
+}
+
+// Rotate re-wraps the data key of ciphertext with the primary key. The
+// encrypted value itself is unchanged, so no associated data is needed.
+func (e *Envelope) Rotate(ctx context.Context, ciphertext string) (string, error) {
+	keyID, wrapped, sealed, err := parse(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if keyID == e.keys.PrimaryKeyID() {
+		return ciphertext, nil
+	}
+	dataKey, err := e.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+	keyID, wrapped, err = e.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("crypto: wrap data key: %w", err)
+	}
+	return format(keyID, wrapped, sealed), nil
+}
+
+// format encodes a ciphertext as v1:<key id>:<wrapped data key>:<sealed>.
+func format(keyID string, wrapped, sealed []byte) string {
+	enc := base64.RawURLEncoding
+	return strings.Join([]string{version, keyID, enc.EncodeToString(wrapped), enc.EncodeToString(sealed)}, ":")
+}
+
+// parse decodes a ciphertext produced by format.
+func parse(ciphertext string) (keyID string, wrapped, sealed []byte, err error) {
+	parts := strings.Split(ciphertext, ":")
+	if len(parts) != 4 || parts[0] != version || parts[1] == "" {
+		return "", nil, nil, ErrMalformed
+	}
+	enc := base64.RawURLEncoding
+	if wrapped, err = enc.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	if sealed, err = enc.DecodeString(parts[3]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	return parts[1], wrapped, sealed, nil
+}
+
+// seal encrypts plaintext with AES-GCM under key, prefixing the nonce.
+func seal(key, plaintext, associated []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("crypto: generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, associated), nil
+}
+
+// open decrypts a value produced by seal.
+func open(key, sealed, associated []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, body := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, body, associated)
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plaintext, nil
+}
+
+// newGCM creates an AES-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,128 @@
+package crypto
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	envelope := NewEnvelope(newTestKeyring(t, "k1", "k1"))
+
+	sealed, err := envelope.Encrypt(ctx, []byte("secret"), []byte("users.ssn"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "v1:k1:"))
+	assert.NotContains(t, sealed, "secret")
+
+	again, err := envelope.Encrypt(ctx, []byte("secret"), []byte("users.ssn"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every value gets its own data key and nonce")
+
+	plaintext, err := envelope.Decrypt(ctx, sealed, []byte("users.ssn"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	_, err = envelope.Decrypt(ctx, sealed, []byte("users.email"))
+	assert.ErrorIs(t, err, ErrDecryptFailed, "associated data binds the field")
+	_, err = envelope.Decrypt(ctx, "v1:k1:not-base64!:x", nil)
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestEnvelope_Rotation(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestKeys(t, "old", "new")
+
+	oldRing, err := NewKeyring(map[string][]byte{"old": keys["old"]}, "old")
+	require.NoError(t, err)
+	sealed, err := NewEnvelope(oldRing).Encrypt(ctx, []byte("secret"), nil)
+	require.NoError(t, err)
+
+	rotatedRing, err := NewKeyring(keys, "new")
+	require.NoError(t, err)
+	envelope := NewEnvelope(rotatedRing)
+	assert.True(t, envelope.NeedsRotation(sealed))
+	plaintext, err := envelope.Decrypt(ctx, sealed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext), "retired keys still decrypt")
+
+	rotated, err := envelope.Rotate(ctx, sealed)
+	require.NoError(t, err)
+	keyID, err := KeyID(rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "new", keyID)
+	assert.False(t, envelope.NeedsRotation(rotated))
+
+	newOnly, err := NewKeyring(map[string][]byte{"new": keys["new"]}, "new")
+	require.NoError(t, err)
+	plaintext, err = NewEnvelope(newOnly).Decrypt(ctx, rotated, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+	_, err = NewEnvelope(newOnly).Decrypt(ctx, sealed, nil)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestEnvelope_Fields(t *testing.T) {
+	ctx := context.Background()
+	envelope := NewEnvelope(newTestKeyring(t, "k1", "k1"))
+
+	type card struct {
+		Holder string
+		Number string `encrypt:"cards.number"`
+		CVC    string `encrypt:""`
+		Note   string `encrypt:""`
+	}
+	c := card{Holder: "Jane", Number: "4111", CVC: "123"}
+	require.NoError(t, envelope.EncryptFields(ctx, &c))
+	assert.Equal(t, "Jane", c.Holder)
+	assert.True(t, strings.HasPrefix(c.Number, "v1:"))
+	assert.Empty(t, c.Note)
+
+	plaintext, err := envelope.Decrypt(ctx, c.CVC, []byte("CVC"))
+	require.NoError(t, err, "the field name is the default associated data")
+	assert.Equal(t, "123", string(plaintext))
+
+	changed, err := envelope.RotateFields(ctx, &c)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.NoError(t, envelope.DecryptFields(ctx, &c))
+	assert.Equal(t, card{Holder: "Jane", Number: "4111", CVC: "123"}, c)
+
+	assert.Error(t, envelope.EncryptFields(ctx, c), "a pointer is required")
+	assert.Error(t, envelope.EncryptFields(ctx, &struct {
+		PIN int `encrypt:""`
+	}{PIN: 1}))
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys([]string{"k1=" + strings.Repeat("A", 43) + "="})
+	require.NoError(t, err)
+	assert.Len(t, keys["k1"], KeySize)
+
+	_, err = ParseKeys([]string{"k1"})
+	assert.Error(t, err)
+	_, err = NewKeyring(map[string][]byte{"k1": []byte("short")}, "k1")
+	assert.ErrorContains(t, err, "must be 32 bytes")
+	_, err = NewKeyring(keys, "k2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func newTestKeys(t *testing.T, ids ...string) map[string][]byte {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = []byte(strings.Repeat(string(rune('a'+i)), KeySize))
+	}
+	return keys
+}
+
+func newTestKeyring(t *testing.T, primary string, ids ...string) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(newTestKeys(t, ids...), primary)
+	require.NoError(t, err)
+	return keyring
+}
@@ -0,0 +1,75 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Keyring is a KeyProvider holding local key encryption keys by ID.
+// Retired keys stay in the keyring to decrypt values not yet rotated.
+type Keyring struct {
+	keys    map[string][]byte
+	primary string
+}
+
+// NewKeyring creates a keyring wrapping new data keys with the primary key.
+// Keys must be KeySize bytes.
+func NewKeyring(keys map[string][]byte, primary string) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("crypto: primary key %q: %w", primary, ErrUnknownKey)
+	}
+	k := &Keyring{keys: make(map[string][]byte, len(keys)), primary: primary}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("crypto: invalid key id %q", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("crypto: key %q must be %d bytes, got %d", id, KeySize, len(key))
+		}
+		k.keys[id] = append([]byte(nil), key...)
+	}
+	return k, nil
+}
+
+// ParseKeys decodes keys given as id=base64 pairs, the format of the
+// ENCRYPTION_KEYS setting.
+func ParseKeys(pairs []string) (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(pairs))
+	for _, pair := range pairs {
+		id, encoded, ok := strings.Cut(pair, "=")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("crypto: key %q must be id=base64", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: key %q: %w", id, err)
+		}
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("crypto: duplicate key id %q", id)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// WrapKey implements KeyProvider.
+func (k *Keyring) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := seal(k.keys[k.primary], dataKey, []byte(k.primary))
+	return k.primary, wrapped, err
+}
+
+// UnwrapKey implements KeyProvider.
+func (k *Keyring) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	return open(key, wrapped, []byte(keyID))
+}
+
+// PrimaryKeyID implements KeyProvider.
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
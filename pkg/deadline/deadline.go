@@ -0,0 +1,104 @@
+// Package deadline derives per-dependency sub-deadlines from the request
+// deadline, so a slow dependency fails while there is still time to
+// answer the caller instead of consuming the whole request budget.
+//
+// The request context carries the deadline set by the timeout middleware;
+// calls to a dependency take a fraction of the time remaining:
+//
+//	ctx, cancel := deadline.Budget(ctx, 0.8)
+//	defer cancel()
+//	row, err := repo.Get(ctx, id)
+//
+// Contexts without a deadline are passed through unbounded.
+package deadline
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Remaining returns the time left until the deadline of ctx and whether
+// ctx has one. It is negative once the deadline passed.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(d), true
+}
+
+// Budget returns a context whose deadline is the given fraction of the
+// time remaining until the deadline of ctx. The deadline of ctx is kept
+// when ctx has none or fraction is outside (0, 1).
+//
+// Parameters:
+//   - ctx: Parent context, usually the request context
+//   - fraction: Share of the remaining time granted to the call, e.g. 0.8
+//
+// Returns:
+//   - context.Context: Derived context
+//   - context.CancelFunc: Releases the derived context; always call it
+func Budget(ctx context.Context, fraction float64) (context.Context, context.CancelFunc) {
+	remaining, ok := Remaining(ctx)
+	if !ok || fraction <= 0 || fraction >= 1 || remaining <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(float64(remaining)*fraction))
+}
+
+// Transport returns a round tripper sending each request with the budget
+// of its context (see Budget). The derived context is released when the
+// response body is closed. base is returned unchanged when fraction is
+// outside (0, 1).
+func Transport(base http.RoundTripper, fraction float64) http.RoundTripper {
+	if fraction <= 0 || fraction >= 1 {
+		return base
+	}
+	return &RoundTripper{Base: base, Fraction: fraction}
+}
+
+// RoundTripper sends requests with a share of their context deadline
+// before delegating to Base.
+type RoundTripper struct {
+	Base     http.RoundTripper
+	Fraction float64
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := req.Context().Deadline(); !ok {
+		return t.Base.RoundTrip(req)
+	}
+
+	ctx, cancel := Budget(req.Context(), t.Fraction)
+	resp, err := t.Base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// CloseIdleConnections forwards to Base so http.Client.CloseIdleConnections
+// keeps working.
+func (t *RoundTripper) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// cancelBody releases the request context when the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
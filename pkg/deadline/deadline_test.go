@@ -0,0 +1,87 @@
+package deadline
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget_TakesFractionOfRemainingTime(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ctx, release := Budget(parent, 0.5)
+	defer release()
+
+	remaining, ok := Remaining(ctx)
+	require.True(t, ok)
+	assert.LessOrEqual(t, remaining, 500*time.Millisecond)
+	assert.Greater(t, remaining, 400*time.Millisecond)
+}
+
+func TestBudget_KeepsParentDeadline(t *testing.T) {
+	unbounded, release := Budget(context.Background(), 0.8)
+	defer release()
+	_, ok := unbounded.Deadline()
+	assert.False(t, ok, "no deadline to derive from")
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	full, release := Budget(parent, 1)
+	defer release()
+	want, _ := parent.Deadline()
+	got, _ := full.Deadline()
+	assert.Equal(t, want, got)
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	ctx, release := Budget(expired, 0.8)
+	defer release()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func TestTransport_CancelsSlowUpstreamWithinBudget(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+	client := &http.Client{Transport: Transport(http.DefaultTransport, 0.5)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL+"/fast", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "ok", string(body))
+
+	start := time.Now()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL+"/slow", nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 300*time.Millisecond, "upstream call gets half of the remaining time")
+	assert.NoError(t, ctx.Err(), "the caller still has time to answer")
+}
+
+func TestTransport_DisabledFraction(t *testing.T) {
+	assert.Same(t, http.DefaultTransport, Transport(http.DefaultTransport, 0))
+	assert.Same(t, http.DefaultTransport, Transport(http.DefaultTransport, 1))
+}
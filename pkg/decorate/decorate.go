@@ -0,0 +1,55 @@
+// Package decorate provides the runtime support for service decorators
+// generated by cmd/decorgen.
+//
+// Generated decorators wrap a service interface with logging, metrics and
+// tracing so the business logic stays free of cross-cutting concerns.
+package decorate
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Call outcomes used as metric label values.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Recorder observes completed service calls.
+type Recorder interface {
+	Observe(service, method string, duration time.Duration, err error)
+}
+
+// Outcome returns the outcome label for a call result.
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeOK
+}
+
+// PrometheusRecorder records service call latency in a histogram labelled
+// by service, method and outcome.
+type PrometheusRecorder struct {
+	calls *prometheus.HistogramVec
+}
+
+// NewPrometheusRecorder creates a recorder and registers its collector.
+// It panics if the collector is already registered.
+func NewPrometheusRecorder(reg prometheus.Registerer) *PrometheusRecorder {
+	calls := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "service_call_duration_seconds",
+		Help:    "Duration of service method calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service", "method", "outcome"})
+	reg.MustRegister(calls)
+
+	return &PrometheusRecorder{calls: calls}
+}
+
+// Observe implements Recorder.
+func (r *PrometheusRecorder) Observe(service, method string, duration time.Duration, err error) {
+	r.calls.WithLabelValues(service, method, Outcome(err)).Observe(duration.Seconds())
+}
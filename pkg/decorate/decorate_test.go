@@ -0,0 +1,33 @@
+package decorate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusRecorder_ObservesByOutcome(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	rec := NewPrometheusRecorder(reg)
+
+	rec.Observe("users.UserService", "Get", 10*time.Millisecond, nil)
+	rec.Observe("users.UserService", "Get", 20*time.Millisecond, errors.New("boom"))
+	rec.Observe("users.UserService", "List", time.Millisecond, nil)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(reg, "service_call_duration_seconds"))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Len(t, families[0].GetMetric(), 3)
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeOK, Outcome(nil))
+	assert.Equal(t, OutcomeError, Outcome(errors.New("boom")))
+}
@@ -0,0 +1,191 @@
+// Package discovery resolves service names to upstream endpoints through
+// Consul or DNS SRV records, with client-side round-robin load balancing.
+//
+// Endpoint lists are cached for the refresh interval; a failed refresh
+// keeps serving the previous list. Endpoints reported as failing are
+// ejected for the ejection period, unless every endpoint is ejected:
+//
+//	resolver := discovery.New(discovery.NewConsulSource(consulConfig), discovery.Config{})
+//	endpoint, err := resolver.Pick(ctx, "billing")
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults applied to zero-valued Config fields.
+const (
+	DefaultRefreshInterval = 30 * time.Second
+	DefaultEjectionPeriod  = 30 * time.Second
+)
+
+// ErrNoEndpoints is returned for services without endpoints.
+var ErrNoEndpoints = errors.New("no endpoints")
+
+// Endpoint is an instance of a service.
+type Endpoint struct {
+	Host string
+	Port int
+}
+
+// Addr returns the endpoint as "host:port".
+func (e Endpoint) Addr() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+// Source looks up the healthy endpoints of a service.
+type Source interface {
+	Lookup(ctx context.Context, service string) ([]Endpoint, error)
+}
+
+// Config configures a Resolver.
+type Config struct {
+	// RefreshInterval is how long endpoint lists are cached (default 30s)
+	RefreshInterval time.Duration
+
+	// EjectionPeriod is how long failing endpoints are skipped (default 30s)
+	EjectionPeriod time.Duration
+}
+
+// Resolver caches and balances the endpoints of services.
+type Resolver struct {
+	source Source
+	cfg    Config
+	now    func() time.Time
+
+	mu       sync.Mutex
+	services map[string]*service
+}
+
+// service is the cached state of one service.
+type service struct {
+	endpoints []Endpoint
+	fetchedAt time.Time
+	next      int
+	ejected   map[Endpoint]time.Time
+}
+
+// New creates a resolver querying source.
+func New(source Source, cfg Config) *Resolver {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultRefreshInterval
+	}
+	if cfg.EjectionPeriod <= 0 {
+		cfg.EjectionPeriod = DefaultEjectionPeriod
+	}
+	return &Resolver{source: source, cfg: cfg, now: time.Now, services: map[string]*service{}}
+}
+
+// RefreshInterval returns how long endpoint lists are cached.
+func (r *Resolver) RefreshInterval() time.Duration {
+	return r.cfg.RefreshInterval
+}
+
+// Endpoints returns the endpoints of name that are not ejected, or all of
+// them when every endpoint is ejected.
+func (r *Resolver) Endpoints(ctx context.Context, name string) ([]Endpoint, error) {
+	svc, err := r.lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.available(svc), nil
+}
+
+// Pick returns the next endpoint of name in round-robin order.
+func (r *Resolver) Pick(ctx context.Context, name string) (Endpoint, error) {
+	svc, err := r.lookup(ctx, name)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	endpoints := r.available(svc)
+	endpoint := endpoints[svc.next%len(endpoints)]
+	svc.next++
+	return endpoint, nil
+}
+
+// Eject skips endpoint of name for the ejection period.
+func (r *Resolver) Eject(name string, endpoint Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if svc, ok := r.services[name]; ok {
+		svc.ejected[endpoint] = r.now().Add(r.cfg.EjectionPeriod)
+	}
+}
+
+// lookup returns the cached state of name, refreshing it when expired.
+func (r *Resolver) lookup(ctx context.Context, name string) (*service, error) {
+	r.mu.Lock()
+	svc, ok := r.services[name]
+	fresh := ok && r.now().Sub(svc.fetchedAt) < r.cfg.RefreshInterval
+	r.mu.Unlock()
+	if fresh {
+		return svc, nil
+	}
+
+	endpoints, err := r.source.Lookup(ctx, name)
+	if err == nil && len(endpoints) == 0 {
+		err = ErrNoEndpoints
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	svc, ok = r.services[name]
+	if err != nil {
+		// Keep serving the previous list while the source is unavailable
+		if ok {
+			return svc, nil
+		}
+		return nil, &Error{Service: name, Err: err}
+	}
+	if !ok {
+		svc = &service{ejected: map[Endpoint]time.Time{}}
+		r.services[name] = svc
+	}
+	svc.endpoints = endpoints
+	svc.fetchedAt = r.now()
+	return svc, nil
+}
+
+// available returns the endpoints of svc not ejected; the caller holds mu.
+func (r *Resolver) available(svc *service) []Endpoint {
+	now := r.now()
+	healthy := make([]Endpoint, 0, len(svc.endpoints))
+	for _, endpoint := range svc.endpoints {
+		if until, ok := svc.ejected[endpoint]; ok && now.Before(until) {
+			continue
+		}
+		delete(svc.ejected, endpoint)
+		healthy = append(healthy, endpoint)
+	}
+	if len(healthy) == 0 {
+		return svc.endpoints
+	}
+	return healthy
+}
+
+// Error describes a failed service lookup.
+type Error struct {
+	Service string
+	Err     error
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return "discovery of " + e.Service + " failed: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
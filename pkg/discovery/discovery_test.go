@@ -0,0 +1,181 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestResolver_RoundRobinAndEjection(t *testing.T) {
+	a, b := Endpoint{Host: "10.0.0.1", Port: 80}, Endpoint{Host: "10.0.0.2", Port: 80}
+	r := New(&staticSource{endpoints: []Endpoint{a, b}}, Config{})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	var picked []Endpoint
+	for i := 0; i < 4; i++ {
+		endpoint, err := r.Pick(ctx, "billing")
+		require.NoError(t, err)
+		picked = append(picked, endpoint)
+	}
+	assert.Equal(t, []Endpoint{a, b, a, b}, picked)
+
+	r.Eject("billing", a)
+	endpoints, err := r.Endpoints(ctx, "billing")
+	require.NoError(t, err)
+	assert.Equal(t, []Endpoint{b}, endpoints)
+
+	r.Eject("billing", b)
+	endpoints, _ = r.Endpoints(ctx, "billing")
+	assert.Len(t, endpoints, 2, "all endpoints are used when every endpoint is ejected")
+
+	now = now.Add(DefaultEjectionPeriod)
+	r.Eject("billing", b)
+	endpoints, _ = r.Endpoints(ctx, "billing")
+	assert.Equal(t, []Endpoint{a}, endpoints, "ejections expire")
+}
+
+func TestResolver_CachesAndKeepsStaleEndpoints(t *testing.T) {
+	source := &staticSource{endpoints: []Endpoint{{Host: "10.0.0.1", Port: 80}}}
+	r := New(source, Config{RefreshInterval: time.Minute})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := r.Pick(ctx, "billing")
+	require.NoError(t, err)
+	_, _ = r.Pick(ctx, "billing")
+	assert.Equal(t, int32(1), source.lookups.Load())
+
+	now = now.Add(time.Minute)
+	source.err = errors.New("consul unavailable")
+	endpoint, err := r.Pick(ctx, "billing")
+	require.NoError(t, err, "stale endpoints are served while the source fails")
+	assert.Equal(t, "10.0.0.1:80", endpoint.Addr())
+
+	_, err = r.Pick(ctx, "unknown")
+	var lookupErr *Error
+	require.ErrorAs(t, err, &lookupErr)
+	assert.Equal(t, "unknown", lookupErr.Service)
+
+	source.err = nil
+	source.endpoints = nil
+	_, err = r.Pick(ctx, "empty")
+	assert.ErrorIs(t, err, ErrNoEndpoints)
+}
+
+func TestConsulSource_Lookup(t *testing.T) {
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/health/service/billing", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("passing"))
+		assert.Equal(t, "dc2", r.URL.Query().Get("dc"))
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+		_, _ = w.Write([]byte(`[
+			{"Node": {"Address": "10.0.0.1"}, "Service": {"Address": "", "Port": 8080}},
+			{"Node": {"Address": "10.0.0.2"}, "Service": {"Address": "172.16.0.2", "Port": 8081}}
+		]`))
+	}))
+	defer consul.Close()
+
+	source := NewConsulSource(ConsulConfig{Address: consul.URL, Token: "secret", Datacenter: "dc2"})
+	endpoints, err := source.Lookup(context.Background(), "billing")
+	require.NoError(t, err)
+	assert.Equal(t, []Endpoint{{Host: "10.0.0.1", Port: 8080}, {Host: "172.16.0.2", Port: 8081}}, endpoints)
+}
+
+func TestTransport_RoutesToEndpointsAndEjectsFailures(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Host + r.URL.Path))
+	}))
+	defer upstream.Close()
+	live := endpointOf(t, upstream.URL)
+	dead := Endpoint{Host: "127.0.0.1", Port: freePort(t)}
+
+	r := New(&staticSource{endpoints: []Endpoint{dead, live}}, Config{})
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport, Resolver: r, Service: "billing"}}
+
+	_, err := client.Get("http://billing/v1/invoices")
+	require.Error(t, err, "first pick is the closed port")
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("http://billing/v1/invoices")
+		require.NoError(t, err)
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		_ = resp.Body.Close()
+		assert.Equal(t, "billing/v1/invoices", string(body[:n]), "path and Host header are kept")
+	}
+}
+
+func TestGRPCBuilder_BalancesDiscoveredEndpoints(t *testing.T) {
+	var endpoints []Endpoint
+	for i := 0; i < 2; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpc.NewServer()
+		healthpb.RegisterHealthServer(srv, health.NewServer())
+		go func() { _ = srv.Serve(listener) }()
+		t.Cleanup(srv.Stop)
+		endpoints = append(endpoints, endpointOf(t, "http://"+listener.Addr().String()))
+	}
+
+	r := New(&staticSource{endpoints: endpoints}, Config{})
+	conn, err := grpc.NewClient("discovery:///search",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(GRPCBuilder(r)),
+	)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+// staticSource returns fixed endpoints.
+type staticSource struct {
+	endpoints []Endpoint
+	err       error
+	lookups   atomic.Int32
+}
+
+func (s *staticSource) Lookup(context.Context, string) ([]Endpoint, error) {
+	s.lookups.Add(1)
+	return s.endpoints, s.err
+}
+
+func endpointOf(t *testing.T, raw string) Endpoint {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return Endpoint{Host: u.Hostname(), Port: port}
+}
+
+// freePort returns a local port nothing listens on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+	return port
+}
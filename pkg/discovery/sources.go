@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ConsulConfig configures the Consul source.
+type ConsulConfig struct {
+	Address    string // Agent URL (default http://127.0.0.1:8500)
+	Token      string // ACL token sent as X-Consul-Token
+	Datacenter string // Datacenter queried (default: the agent's)
+	Tag        string // Only instances carrying this tag
+}
+
+// ConsulSource returns the instances of a service passing all health
+// checks from the Consul health API.
+type ConsulSource struct {
+	cfg    ConsulConfig
+	client *http.Client
+}
+
+// NewConsulSource creates a Consul source.
+func NewConsulSource(cfg ConsulConfig) *ConsulSource {
+	if cfg.Address == "" {
+		cfg.Address = "http://127.0.0.1:8500"
+	}
+	return &ConsulSource{cfg: cfg, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// consulEntry is the subset of a /v1/health/service entry the source reads.
+type consulEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		Address string
+		Port    int
+	}
+}
+
+// Lookup implements Source.
+func (s *ConsulSource) Lookup(ctx context.Context, service string) ([]Endpoint, error) {
+	query := url.Values{"passing": {"true"}}
+	if s.cfg.Datacenter != "" {
+		query.Set("dc", s.cfg.Datacenter)
+	}
+	if s.cfg.Tag != "" {
+		query.Set("tag", s.cfg.Tag)
+	}
+	endpoint := strings.TrimRight(s.cfg.Address, "/") + "/v1/health/service/" + url.PathEscape(service) + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", s.cfg.Token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul returned status %d", resp.StatusCode)
+	}
+
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode consul response: %w", err)
+	}
+	endpoints := make([]Endpoint, 0, len(entries))
+	for _, entry := range entries {
+		// Services registered without an address use their node's
+		host := entry.Service.Address
+		if host == "" {
+			host = entry.Node.Address
+		}
+		endpoints = append(endpoints, Endpoint{Host: host, Port: entry.Service.Port})
+	}
+	return endpoints, nil
+}
+
+// SRVSource returns the targets of _service._tcp.domain SRV records.
+// DNS has no health information; failing targets are ejected by the
+// resolver.
+type SRVSource struct {
+	domain   string
+	resolver *net.Resolver
+}
+
+// NewSRVSource creates a DNS SRV source for services under domain
+// (for example "service.consul" or "svc.cluster.local").
+func NewSRVSource(domain string) *SRVSource {
+	return &SRVSource{domain: domain, resolver: net.DefaultResolver}
+}
+
+// Lookup implements Source.
+func (s *SRVSource) Lookup(ctx context.Context, service string) ([]Endpoint, error) {
+	_, records, err := s.resolver.LookupSRV(ctx, service, "tcp", s.domain)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make([]Endpoint, 0, len(records))
+	for _, record := range records {
+		endpoints = append(endpoints, Endpoint{Host: strings.TrimSuffix(record.Target, "."), Port: int(record.Port)})
+	}
+	return endpoints, nil
+}
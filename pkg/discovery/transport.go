@@ -0,0 +1,139 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// Transport sends every request to an endpoint of Service, keeping the
+// path, query and Host header of the request URL. Endpoints failing with
+// a transport error are ejected.
+type Transport struct {
+	Base     http.RoundTripper
+	Resolver *Resolver
+	Service  string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint, err := t.Resolver.Pick(req.Context(), t.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	out := req.Clone(req.Context())
+	out.URL.Host = endpoint.Addr()
+	if out.Host == "" {
+		out.Host = req.URL.Host
+	}
+	resp, err := t.Base.RoundTrip(out)
+	if err != nil {
+		t.Resolver.Eject(t.Service, endpoint)
+	}
+	return resp, err
+}
+
+// CloseIdleConnections forwards to Base so http.Client.CloseIdleConnections
+// keeps working.
+func (t *Transport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// GRPCScheme is the target scheme of discovered gRPC services
+// ("discovery:///billing").
+const GRPCScheme = "discovery"
+
+// roundRobin balances the addresses of a discovered service per call.
+const roundRobin = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+
+// GRPCBuilder returns a gRPC resolver for "discovery:///<service>" targets
+// that refreshes the service endpoints every refresh interval and balances
+// calls round-robin. Register it with grpc.WithResolvers.
+func GRPCBuilder(r *Resolver) resolver.Builder {
+	return &grpcBuilder{resolver: r}
+}
+
+type grpcBuilder struct {
+	resolver *Resolver
+}
+
+// Scheme implements resolver.Builder.
+func (b *grpcBuilder) Scheme() string { return GRPCScheme }
+
+// Build implements resolver.Builder.
+func (b *grpcBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &grpcWatcher{
+		resolver: b.resolver,
+		service:  target.Endpoint(),
+		cc:       cc,
+		config:   cc.ParseServiceConfig(roundRobin),
+		refresh:  make(chan struct{}, 1),
+		cancel:   cancel,
+	}
+	w.wg.Add(1)
+	go w.watch(ctx)
+	return w, nil
+}
+
+// grpcWatcher pushes the endpoints of a service to a gRPC connection.
+type grpcWatcher struct {
+	resolver *Resolver
+	service  string
+	cc       resolver.ClientConn
+	config   *serviceconfig.ParseResult
+	refresh  chan struct{}
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// ResolveNow implements resolver.Resolver.
+func (w *grpcWatcher) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case w.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Close implements resolver.Resolver.
+func (w *grpcWatcher) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// watch updates the connection until ctx is canceled.
+func (w *grpcWatcher) watch(ctx context.Context) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.resolver.RefreshInterval())
+	defer ticker.Stop()
+	for {
+		w.update(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.refresh:
+		}
+	}
+}
+
+// update pushes the current endpoints.
+func (w *grpcWatcher) update(ctx context.Context) {
+	endpoints, err := w.resolver.Endpoints(ctx, w.service)
+	if err != nil {
+		w.cc.ReportError(err)
+		return
+	}
+	addresses := make([]resolver.Address, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		addresses = append(addresses, resolver.Address{Addr: endpoint.Addr()})
+	}
+	_ = w.cc.UpdateState(resolver.State{Addresses: addresses, ServiceConfig: w.config})
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// formulaPrefixes start cell values that spreadsheet applications evaluate
+// as formulas when opening a CSV file.
+const formulaPrefixes = "=+-@\t\r"
+
+// csvWriter writes rows as RFC 4180 CSV.
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+// NewCSVWriter creates a RowWriter producing CSV. Values that would be
+// interpreted as spreadsheet formulas are prefixed with a single quote;
+// numbers such as -12.5 are written as is.
+func NewCSVWriter(w io.Writer) RowWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+// WriteRow implements RowWriter.
+func (c *csvWriter) WriteRow(values []string) error {
+	safe := make([]string, len(values))
+	for i, v := range values {
+		if isFormula(v) {
+			v = "'" + v
+		}
+		safe[i] = v
+	}
+	if err := c.w.Write(safe); err != nil {
+		return err
+	}
+
+	// Flush periodically so large exports stream instead of buffering
+	c.rows++
+	if c.rows%100 == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+// Close implements RowWriter.
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// isFormula reports whether a spreadsheet would evaluate v. Signed numbers
+// start with a formula prefix but are plain values.
+func isFormula(v string) bool {
+	if v == "" || !containsByte(formulaPrefixes, v[0]) {
+		return false
+	}
+	_, err := strconv.ParseFloat(v, 64)
+	return err != nil
+}
+
+// containsByte reports whether b occurs in s.
+func containsByte(s string, b byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return true
+		}
+	}
+	return false
+}
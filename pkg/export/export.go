@@ -0,0 +1,207 @@
+// Package export streams paginated results as CSV or XLSX documents.
+//
+// Rows are fetched page by page and written directly to the destination, so
+// memory usage is bounded by the page size rather than the result set size.
+// Columns are derived from `export` struct tags:
+//
+//	type User struct {
+//		ID       int       `export:"ID"`
+//		Email    string    `export:"Email Address"`
+//		Password string    `export:"-"`
+//		Created  time.Time `export:"Created At"`
+//	}
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported export formats.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// DefaultPageSize is used when Write is called with a non-positive page size.
+const DefaultPageSize = 500
+
+// tagName is the struct tag holding column headers.
+const tagName = "export"
+
+// Fetch returns up to limit items starting at offset. Returning fewer than
+// limit items ends the export.
+type Fetch[T any] func(ctx context.Context, offset, limit int) ([]T, error)
+
+// RowWriter writes tabular rows to an output document.
+type RowWriter interface {
+	// WriteRow writes a single row of cell values.
+	WriteRow(values []string) error
+
+	// Close flushes buffered data and finalizes the document.
+	Close() error
+}
+
+// Column maps a struct field to an output column.
+type Column struct {
+	Header string
+	index  []int
+}
+
+// Columns derives the column mapping of T from `export` struct tags.
+// Untagged exported fields use the field name; `export:"-"` skips a field.
+func Columns[T any]() ([]Column, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("export: %s is not a struct", t)
+	}
+
+	var cols []Column
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		header := f.Name
+		if tag, ok := f.Tag.Lookup(tagName); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				header = tag
+			}
+		}
+		cols = append(cols, Column{Header: header, index: f.Index})
+	}
+
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("export: %s has no exportable fields", t)
+	}
+	return cols, nil
+}
+
+// NewWriter creates a RowWriter for the given format.
+func NewWriter(w io.Writer, format string) (RowWriter, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, "Export")
+	default:
+		return nil, fmt.Errorf("export: unsupported format %q", format)
+	}
+}
+
+// Write streams every page returned by fetch into w in the given format.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation
+//   - w: Destination (file, HTTP response, ...)
+//   - format: FormatCSV or FormatXLSX
+//   - pageSize: Items fetched per page
+//   - fetch: Page source, typically a repository List call
+//
+// Returns:
+//   - int: Number of data rows written
+//   - error: Fetch, mapping, or write error
+func Write[T any](ctx context.Context, w io.Writer, format string, pageSize int, fetch Fetch[T]) (int, error) {
+	cols, err := Columns[T]()
+	if err != nil {
+		return 0, err
+	}
+
+	rw, err := NewWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := writeRows(ctx, rw, cols, pageSize, fetch)
+	if closeErr := rw.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// writeRows writes the header row followed by all fetched pages.
+func writeRows[T any](ctx context.Context, rw RowWriter, cols []Column, pageSize int, fetch Fetch[T]) (int, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	row := make([]string, len(cols))
+	for i, c := range cols {
+		row[i] = c.Header
+	}
+	if err := rw.WriteRow(row); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for offset := 0; ; offset += pageSize {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		items, err := fetch(ctx, offset, pageSize)
+		if err != nil {
+			return written, fmt.Errorf("export: fetch failed at offset %d: %w", offset, err)
+		}
+
+		for i := range items {
+			v := reflect.Indirect(reflect.ValueOf(&items[i]).Elem())
+			for j, c := range cols {
+				row[j] = formatValue(v.FieldByIndex(c.index))
+			}
+			if err := rw.WriteRow(row); err != nil {
+				return written, err
+			}
+			written++
+		}
+
+		if len(items) < pageSize {
+			return written, nil
+		}
+	}
+}
+
+// formatValue renders a field value as cell text.
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch val := v.Interface().(type) {
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.UTC().Format(time.RFC3339)
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,182 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exportRow struct {
+	ID       int       `export:"ID"`
+	Email    string    `export:"Email Address"`
+	Secret   string    `export:"-"`
+	Active   bool      `export:"Active"`
+	Created  time.Time `export:"Created At"`
+	Nickname *string
+	internal string
+}
+
+func rows(n int) []exportRow {
+	out := make([]exportRow, n)
+	for i := range out {
+		out[i] = exportRow{
+			ID:      i + 1,
+			Email:   "user@example.com",
+			Secret:  "hidden",
+			Active:  i%2 == 0,
+			Created: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		}
+	}
+	return out
+}
+
+// pagedFetch serves data in pages and records the number of fetch calls.
+func pagedFetch(data []exportRow, calls *int) Fetch[exportRow] {
+	return func(ctx context.Context, offset, limit int) ([]exportRow, error) {
+		*calls++
+		if offset >= len(data) {
+			return nil, nil
+		}
+		end := offset + limit
+		if end > len(data) {
+			end = len(data)
+		}
+		return data[offset:end], nil
+	}
+}
+
+func TestColumns_UsesTags(t *testing.T) {
+	cols, err := Columns[exportRow]()
+	require.NoError(t, err)
+
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.Header
+	}
+	assert.Equal(t, []string{"ID", "Email Address", "Active", "Created At", "Nickname"}, headers)
+}
+
+func TestColumns_RejectsNonStruct(t *testing.T) {
+	_, err := Columns[int]()
+	assert.Error(t, err)
+}
+
+func TestWrite_CSVStreamsAllPages(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+
+	n, err := Write(context.Background(), &buf, FormatCSV, 2, pagedFetch(rows(5), &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 3, calls, "5 rows with page size 2 need 3 fetches")
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, []string{"ID", "Email Address", "Active", "Created At", "Nickname"}, records[0])
+	assert.Equal(t, []string{"1", "user@example.com", "true", "2024-01-15T10:30:00Z", ""}, records[1])
+	assert.NotContains(t, buf.String(), "hidden")
+}
+
+func TestWrite_CSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	data := []exportRow{{ID: 1, Email: "=HYPERLINK(\"http://evil\")"}}
+	calls := 0
+
+	_, err := Write(context.Background(), &buf, FormatCSV, 10, pagedFetch(data, &calls))
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(records[1][1], "'="))
+}
+
+func TestCSVWriter_KeepsSignedNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	require.NoError(t, w.WriteRow([]string{"-12.5", "+3", "-1+1", "-", "@SUM(A1)"}))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-12.5", "+3", "'-1+1", "'-", "'@SUM(A1)"}, records[0])
+}
+
+func TestWrite_XLSXProducesWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+
+	n, err := Write(context.Background(), &buf, FormatXLSX, 100, pagedFetch(rows(3), &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files, "xl/workbook.xml")
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="B1" t="inlineStr"><is><t xml:space="preserve">Email Address</t></is></c>`)
+	assert.Contains(t, sheet, `<row r="4">`)
+}
+
+func TestWrite_PropagatesFetchError(t *testing.T) {
+	boom := errors.New("boom")
+	fetch := func(ctx context.Context, offset, limit int) ([]exportRow, error) { return nil, boom }
+
+	_, err := Write(context.Background(), io.Discard, FormatCSV, 10, Fetch[exportRow](fetch))
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestWrite_UnsupportedFormat(t *testing.T) {
+	calls := 0
+	_, err := Write(context.Background(), io.Discard, "pdf", 10, pagedFetch(nil, &calls))
+	assert.Error(t, err)
+}
+
+func TestServeHTTP_SetsAttachmentHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	calls := 0
+
+	_, err := ServeHTTP(context.Background(), w, FormatCSV, "users-2024", 10, pagedFetch(rows(1), &calls))
+	require.NoError(t, err)
+
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=users-2024.csv`, w.Header().Get("Content-Disposition"))
+}
+
+func TestFormatFromRequest(t *testing.T) {
+	assert.Equal(t, FormatXLSX, FormatFromRequest(httptest.NewRequest("GET", "/x?format=XLSX", nil)))
+	assert.Equal(t, FormatCSV, FormatFromRequest(httptest.NewRequest("GET", "/x", nil)))
+
+	req := httptest.NewRequest("GET", "/x", nil)
+	req.Header.Set("Accept", contentTypes[FormatXLSX])
+	assert.Equal(t, FormatXLSX, FormatFromRequest(req))
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// contentTypes maps export formats to MIME types.
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// FormatFromRequest selects the export format from the `format` query
+// parameter, falling back to the Accept header and finally CSV.
+func FormatFromRequest(r *http.Request) string {
+	if f := strings.ToLower(r.URL.Query().Get("format")); f != "" {
+		return f
+	}
+	if strings.Contains(r.Header.Get("Accept"), contentTypes[FormatXLSX]) {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// IsSupported reports whether format can be exported.
+func IsSupported(format string) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+// ContentDisposition builds an attachment header value for filename,
+// including the RFC 6266 UTF-8 form for non-ASCII names.
+func ContentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// ServeHTTP streams an export as a downloadable attachment named
+// <basename>.<format>. Once streaming has started the status code can no
+// longer change, so fetch errors truncate the download and are returned
+// to the caller for logging.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation (usually the request context)
+//   - w: Response writer
+//   - format: FormatCSV or FormatXLSX
+//   - basename: Download file name without extension
+//   - pageSize: Items fetched per page
+//   - fetch: Page source
+//
+// Returns:
+//   - int: Number of data rows written
+//   - error: Export error
+func ServeHTTP[T any](ctx context.Context, w http.ResponseWriter, format, basename string, pageSize int, fetch Fetch[T]) (int, error) {
+	if _, err := Columns[T](); err != nil {
+		return 0, err
+	}
+
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", ContentDisposition(basename+"."+format))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	return Write(ctx, w, format, pageSize, fetch)
+}
@@ -0,0 +1,170 @@
+// Package extension runs HTTP handlers and middleware in external
+// processes, so teams extend the server without forking it.
+//
+// The host launches an extension executable with a magic cookie in its
+// environment; the extension listens on a loopback address, prints a
+// handshake line on stdout and serves the Extension gRPC API until the host
+// closes its stdin or signals it:
+//
+//	func main() {
+//		if err := extension.Serve(myExtension{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The host side starts the process and forwards requests to it:
+//
+//	proc, err := extension.Start(ctx, cfg, log)
+//	resp, err := proc.Handle(ctx, req)
+//
+// Messages are encoded as JSON over gRPC, so extensions can be written
+// without generated code.
+package extension
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc"
+)
+
+// ProtocolVersion is the version of the handshake and the Extension API.
+const ProtocolVersion = 1
+
+// MagicCookieKey and MagicCookieValue are set in the environment of
+// launched extensions. They are not a security measure; they keep an
+// extension executable from serving when run directly.
+const (
+	MagicCookieKey   = "CHANGE_ME_EXTENSION_COOKIE"
+	MagicCookieValue = "b9f1d8c4e6a24f3aa07c5e1d2c3b4a59"
+)
+
+// ErrorCode is the error code of JSON bodies written when an extension
+// fails or cannot be reached.
+const ErrorCode = "extension_unavailable"
+
+// Extension is the API an extension process serves.
+type Extension interface {
+	// Handle answers a request routed to the extension.
+	Handle(ctx context.Context, req *Request) (*Response, error)
+
+	// Intercept decides whether a request continues to the handlers of
+	// the route group the extension middleware applies to.
+	Intercept(ctx context.Context, req *Request) (*Decision, error)
+}
+
+// Request is an inbound HTTP request forwarded to an extension.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+
+	// Route is the matched route template and Params its path parameters
+	Route  string            `json:"route,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Response is the HTTP response an extension answers with.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Decision is the outcome of an extension middleware. A request that
+// continues gets Header set on it; otherwise Response is written.
+type Decision struct {
+	Continue bool        `json:"continue"`
+	Header   http.Header `json:"header,omitempty"`
+	Response *Response   `json:"response,omitempty"`
+}
+
+// NewRequest reads r into a Request. It fails when the body is larger than
+// maxBody bytes (0 means unbounded). The body of r is replaced by the bytes
+// read, so middleware letting the request continue leaves it readable.
+func NewRequest(r *http.Request, maxBody int64) (*Request, error) {
+	req := &Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return req, nil
+	}
+
+	body := io.Reader(r.Body)
+	if maxBody > 0 {
+		body = io.LimitReader(r.Body, maxBody+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	if maxBody > 0 && int64(len(data)) > maxBody {
+		return nil, fmt.Errorf("request body exceeds %d bytes", maxBody)
+	}
+	req.Body = data
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return req, nil
+}
+
+// Write writes the response to w; a zero status is written as 200.
+func (resp *Response) Write(w http.ResponseWriter) {
+	for name, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(resp.Body)
+}
+
+// serviceName is the gRPC service of the Extension API.
+const serviceName = "extension.v1.Extension"
+
+// serviceDesc describes the Extension API for grpc.Server.RegisterService.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Extension)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Handle", Handler: unaryHandler("Handle", Extension.Handle)},
+		{MethodName: "Intercept", Handler: unaryHandler("Intercept", Extension.Intercept)},
+	},
+	Metadata: "extension/v1",
+}
+
+// unaryHandler adapts an Extension method to a gRPC method handler.
+func unaryHandler[Resp any](method string, call func(Extension, context.Context, *Request) (Resp, error)) grpc.MethodHandler {
+	fullMethod := "/" + serviceName + "/" + method
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Request)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return call(srv.(Extension), ctx, req.(*Request))
+		}
+		if interceptor == nil {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+	}
+}
+
+// jsonCodec encodes the Extension API messages as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return "json" }
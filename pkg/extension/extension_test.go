@@ -0,0 +1,167 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test binary serves testExtension when launched by a host, so the
+// tests start it as the extension process.
+func TestMain(m *testing.M) {
+	if os.Getenv(MagicCookieKey) == MagicCookieValue {
+		if os.Getenv("EXTENSION_TEST_MODE") == "silent" {
+			time.Sleep(time.Minute)
+		}
+		if err := Serve(testExtension{}); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type testExtension struct{}
+
+func (testExtension) Handle(_ context.Context, req *Request) (*Response, error) {
+	if req.Path == "/fail" {
+		return nil, errors.New("boom")
+	}
+	return &Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"X-Route": {req.Route}},
+		Body:   []byte(strings.ToUpper(string(req.Body)) + " " + req.Params["id"]),
+	}, nil
+}
+
+func (testExtension) Intercept(_ context.Context, req *Request) (*Decision, error) {
+	if req.Header.Get("X-Token") != "secret" {
+		return &Decision{Response: &Response{Status: http.StatusUnauthorized}}, nil
+	}
+	return &Decision{Continue: true, Header: http.Header{"X-Caller": {"trusted"}}}, nil
+}
+
+func startTestProcess(t *testing.T, env ...string) (*Process, error) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return Start(context.Background(), Config{
+		Name:         "test",
+		Command:      os.Args[0],
+		Env:          env,
+		StartTimeout: 5 * time.Second,
+	}, log)
+}
+
+func TestProcess_HandleAndIntercept(t *testing.T) {
+	p, err := startTestProcess(t)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, p.Stop(context.Background())) }()
+
+	resp, err := p.Handle(context.Background(), &Request{
+		Method: http.MethodPost,
+		Path:   "/items/7",
+		Route:  "/items/:id",
+		Params: map[string]string{"id": "7"},
+		Body:   []byte("hello"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.Status)
+	assert.Equal(t, "/items/:id", resp.Header.Get("X-Route"))
+	assert.Equal(t, "HELLO 7", string(resp.Body))
+
+	_, err = p.Handle(context.Background(), &Request{Path: "/fail"})
+	assert.ErrorContains(t, err, "boom")
+
+	decision, err := p.Intercept(context.Background(), &Request{Header: http.Header{"X-Token": {"secret"}}})
+	require.NoError(t, err)
+	assert.True(t, decision.Continue)
+	assert.Equal(t, "trusted", decision.Header.Get("X-Caller"))
+
+	decision, err = p.Intercept(context.Background(), &Request{})
+	require.NoError(t, err)
+	assert.False(t, decision.Continue)
+	assert.Equal(t, http.StatusUnauthorized, decision.Response.Status)
+}
+
+func TestProcess_StopEndsProcess(t *testing.T) {
+	p, err := startTestProcess(t)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Stop(context.Background()))
+
+	_, err = p.Handle(context.Background(), &Request{})
+	assert.ErrorContains(t, err, "process exited")
+}
+
+func TestStart_FailsWithoutHandshake(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+
+	_, err = Start(context.Background(), Config{
+		Name:         "silent",
+		Command:      os.Args[0],
+		Env:          []string{"EXTENSION_TEST_MODE=silent"},
+		StartTimeout: 100 * time.Millisecond,
+	}, log)
+	assert.ErrorContains(t, err, "no handshake within 100ms")
+
+	_, err = Start(context.Background(), Config{Name: "missing", Command: "/nonexistent/extension"}, log)
+	assert.ErrorContains(t, err, `extension "missing": start`)
+}
+
+func TestServe_RequiresHost(t *testing.T) {
+	assert.ErrorIs(t, Serve(testExtension{}), ErrNotLaunched)
+}
+
+func TestParseHandshake(t *testing.T) {
+	target, err := parseHandshake("1|tcp|127.0.0.1:4000|grpc\n")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:4000", target)
+
+	target, err = parseHandshake("1|unix|/tmp/ext.sock|grpc")
+	require.NoError(t, err)
+	assert.Equal(t, "unix:/tmp/ext.sock", target)
+
+	for _, line := range []string{"", "2|tcp|127.0.0.1:1|grpc", "1|tcp|127.0.0.1:1|netrpc", "1|udp|x|grpc"} {
+		_, err := parseHandshake(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestNewRequest_BoundsBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/items?page=2", strings.NewReader("payload"))
+	r.Header.Set("Content-Type", "text/plain")
+
+	req, err := NewRequest(r, 16)
+	require.NoError(t, err)
+	assert.Equal(t, "/items", req.Path)
+	assert.Equal(t, "page=2", req.Query)
+	assert.Equal(t, "text/plain", req.Header.Get("Content-Type"))
+	assert.Equal(t, "payload", string(req.Body))
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(body), "the body stays readable")
+
+	_, err = NewRequest(httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload")), 4)
+	assert.ErrorContains(t, err, "exceeds 4 bytes")
+}
+
+func TestResponse_Write(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&Response{Header: http.Header{"X-A": {"1"}}, Body: []byte("ok")}).Write(w)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-A"))
+	assert.Equal(t, "ok", w.Body.String())
+}
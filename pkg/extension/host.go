@@ -0,0 +1,263 @@
+package extension
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Defaults applied to zero-valued Config fields.
+const (
+	DefaultStartTimeout = 10 * time.Second
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxBodyBytes = 1 << 20
+)
+
+// Config declares an extension process.
+type Config struct {
+	Name    string   `yaml:"name" validate:"required"`
+	Command string   `yaml:"command" validate:"required"`
+	Args    []string `yaml:"args"`
+
+	// Env holds "KEY=value" pairs added to the host environment
+	Env []string `yaml:"env" validate:"dive,contains=="`
+
+	// Routes are the "METHOD /route/template" routes answered by Handle
+	Routes []string `yaml:"routes" validate:"dive,extension_route"`
+
+	// Middleware registers a route group middleware calling Intercept;
+	// FailOpen lets requests continue when the extension fails
+	Middleware bool `yaml:"middleware"`
+	FailOpen   bool `yaml:"fail_open"`
+
+	// MaxBodyBytes bounds the forwarded request bodies
+	MaxBodyBytes int64 `yaml:"max_body_bytes" validate:"min=0"`
+
+	// StartTimeout bounds the handshake and Timeout each call
+	StartTimeout time.Duration `yaml:"start_timeout" validate:"min=0"`
+	Timeout      time.Duration `yaml:"timeout" validate:"min=0"`
+}
+
+// withDefaults returns cfg with zero values replaced by the defaults.
+func (cfg Config) withDefaults() Config {
+	if cfg.MaxBodyBytes == 0 {
+		cfg.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if cfg.StartTimeout == 0 {
+		cfg.StartTimeout = DefaultStartTimeout
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	return cfg
+}
+
+// Process is a running extension. It implements Extension by calling the
+// process.
+type Process struct {
+	cfg    Config
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	conn   *grpc.ClientConn
+	exited chan struct{}
+}
+
+// Start launches the extension of cfg and connects to it once it
+// completed the handshake. The process is killed when the handshake fails
+// or does not complete within the start timeout or ctx.
+//
+// Parameters:
+//   - ctx: Context bounding the start
+//   - cfg: Extension declaration
+//   - log: Structured logger receiving the process output and exit
+//
+// Returns:
+//   - *Process: The connected extension
+//   - error: The launch or handshake failure
+func Start(ctx context.Context, cfg Config, log *logger.Logger) (*Process, error) {
+	cfg = cfg.withDefaults()
+
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Env = append(os.Environ(), cfg.Env...)
+	cmd.Env = append(cmd.Env, MagicCookieKey+"="+MagicCookieValue)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("extension %q: start: %w", cfg.Name, err)
+	}
+
+	p := &Process{cfg: cfg, cmd: cmd, stdin: stdin, exited: make(chan struct{})}
+
+	// Wait closes the pipes, so it runs once the output was read
+	var output sync.WaitGroup
+	output.Add(2)
+	go func() {
+		defer output.Done()
+		p.logOutput(log, "stderr", bufio.NewScanner(stderr))
+	}()
+	lines := bufio.NewScanner(stdout)
+	handshake := make(chan string, 1)
+	go func() {
+		defer output.Done()
+		if lines.Scan() {
+			handshake <- lines.Text()
+		}
+		close(handshake)
+		p.logOutput(log, "stdout", lines)
+	}()
+	go func() {
+		output.Wait()
+		err := cmd.Wait()
+		close(p.exited)
+		log.Infow("extension_exited", "extension", cfg.Name, "error", err)
+	}()
+
+	timer := time.NewTimer(cfg.StartTimeout)
+	defer timer.Stop()
+
+	var line string
+	select {
+	case l, ok := <-handshake:
+		if !ok {
+			p.kill()
+			return nil, fmt.Errorf("extension %q: exited before the handshake", cfg.Name)
+		}
+		line = l
+	case <-timer.C:
+		p.kill()
+		return nil, fmt.Errorf("extension %q: no handshake within %s", cfg.Name, cfg.StartTimeout)
+	case <-ctx.Done():
+		p.kill()
+		return nil, ctx.Err()
+	}
+
+	target, err := parseHandshake(line)
+	if err != nil {
+		p.kill()
+		return nil, fmt.Errorf("extension %q: %w", cfg.Name, err)
+	}
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		p.kill()
+		return nil, fmt.Errorf("extension %q: connect: %w", cfg.Name, err)
+	}
+	p.conn = conn
+
+	log.Infow("extension_started", "extension", cfg.Name, "pid", cmd.Process.Pid, "target", target)
+	return p, nil
+}
+
+// parseHandshake returns the gRPC target of a "version|network|address|grpc"
+// handshake line.
+func parseHandshake(line string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 {
+		return "", fmt.Errorf("invalid handshake %q", line)
+	}
+	if version, err := strconv.Atoi(parts[0]); err != nil || version != ProtocolVersion {
+		return "", fmt.Errorf("unsupported protocol version %q, want %d", parts[0], ProtocolVersion)
+	}
+	if parts[3] != "grpc" {
+		return "", fmt.Errorf("unsupported protocol %q", parts[3])
+	}
+	switch parts[1] {
+	case "tcp":
+		return parts[2], nil
+	case "unix":
+		return "unix:" + parts[2], nil
+	}
+	return "", fmt.Errorf("unsupported network %q", parts[1])
+}
+
+// logOutput logs the lines the process writes to stream.
+func (p *Process) logOutput(log *logger.Logger, stream string, lines *bufio.Scanner) {
+	for lines.Scan() {
+		log.Infow("extension_output", "extension", p.cfg.Name, "stream", stream, "line", lines.Text())
+	}
+}
+
+// Config returns the declaration of the extension with defaults applied.
+func (p *Process) Config() Config {
+	return p.cfg
+}
+
+// Handle forwards req to the extension, bounded by the call timeout.
+func (p *Process) Handle(ctx context.Context, req *Request) (*Response, error) {
+	resp := new(Response)
+	if err := p.invoke(ctx, "Handle", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Intercept asks the extension whether req continues, bounded by the call
+// timeout.
+func (p *Process) Intercept(ctx context.Context, req *Request) (*Decision, error) {
+	decision := new(Decision)
+	if err := p.invoke(ctx, "Intercept", req, decision); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// invoke calls method of the Extension API.
+func (p *Process) invoke(ctx context.Context, method string, req, resp any) error {
+	select {
+	case <-p.exited:
+		return fmt.Errorf("extension %q: process exited", p.cfg.Name)
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+	if err := p.conn.Invoke(ctx, "/"+serviceName+"/"+method, req, resp); err != nil {
+		return fmt.Errorf("extension %q: %s: %w", p.cfg.Name, method, err)
+	}
+	return nil
+}
+
+// Stop asks the extension to exit by closing its stdin and waits for it;
+// the process is killed when ctx is done first.
+func (p *Process) Stop(ctx context.Context) error {
+	err := p.conn.Close()
+	_ = p.stdin.Close()
+
+	select {
+	case <-p.exited:
+		return err
+	case <-ctx.Done():
+		p.kill()
+		return errors.Join(err, fmt.Errorf("extension %q: killed: %w", p.cfg.Name, ctx.Err()))
+	}
+}
+
+// kill kills the process and waits for it to exit.
+func (p *Process) kill() {
+	_ = p.cmd.Process.Kill()
+	<-p.exited
+}
@@ -0,0 +1,63 @@
+package extension
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+)
+
+// ErrNotLaunched is returned by Serve when the process was not launched by
+// a host.
+var ErrNotLaunched = errors.New("extension: not launched by a host; run it through EXTENSIONS_CONFIG")
+
+// Serve serves ext to the host that launched the process. It returns when
+// the host closes the process stdin or sends SIGINT or SIGTERM, after the
+// calls in progress completed.
+func Serve(ext Extension) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return ErrNotLaunched
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("extension: listen: %w", err)
+	}
+
+	srv := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	srv.RegisterService(&serviceDesc, ext)
+
+	// The host reads the address from the handshake line
+	if _, err := fmt.Fprintf(os.Stdout, "%d|tcp|%s|grpc\n", ProtocolVersion, lis.Addr()); err != nil {
+		_ = lis.Close()
+		return fmt.Errorf("extension: handshake: %w", err)
+	}
+
+	stop := make(chan struct{}, 2)
+	go func() {
+		// A closed stdin means the host stopped or exited
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		stop <- struct{}{}
+	}()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		stop <- struct{}{}
+	}()
+	go func() {
+		<-stop
+		srv.GracefulStop()
+	}()
+
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("extension: serve: %w", err)
+	}
+	return nil
+}
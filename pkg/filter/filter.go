@@ -0,0 +1,466 @@
+// Package filter parses the filter expressions of list endpoints, a subset
+// of RSQL, into specs repositories evaluate or translate to their query
+// language:
+//
+//	spec, err := filter.Parse("status==active;created_at>2024-01-01", schema)
+//
+// Comparisons are joined by ";" (and) and "," (or, binding looser), and
+// grouped with parentheses. The operators are == and != (with * wildcards
+// on string fields that allow them), <, <=, >, >= (or =lt=, =le=, =gt=,
+// =ge=), and =in= and =out= over a parenthesized list. Values containing
+// spaces or reserved characters are quoted with ' or ".
+//
+// Only the fields of the Schema may be used, each with the operators of
+// its type or the ones it lists, so clients cannot filter on unindexed or
+// private fields.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is wrapped by the errors of Parse.
+var ErrInvalid = errors.New("invalid filter")
+
+// Error is a syntax or schema error at a byte offset of the expression.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s at position %d", ErrInvalid, e.Msg, e.Pos)
+}
+
+// Unwrap returns ErrInvalid.
+func (e *Error) Unwrap() error { return ErrInvalid }
+
+// Op is a comparison operator.
+type Op string
+
+// Operators. Like and NotLike are == and != with a wildcard pattern.
+const (
+	Eq      Op = "=="
+	Ne      Op = "!="
+	Lt      Op = "=lt="
+	Le      Op = "=le="
+	Gt      Op = "=gt="
+	Ge      Op = "=ge="
+	In      Op = "=in="
+	Out     Op = "=out="
+	Like    Op = "=like="
+	NotLike Op = "=notlike="
+)
+
+// operators maps the operator spellings to their Op, longest first.
+var operators = []struct {
+	text string
+	op   Op
+}{
+	{"=out=", Out}, {"=in=", In}, {"=lt=", Lt}, {"=le=", Le}, {"=gt=", Gt}, {"=ge=", Ge},
+	{"==", Eq}, {"!=", Ne}, {"<=", Le}, {">=", Ge}, {"<", Lt}, {">", Gt},
+}
+
+// Type is the type of a field, which its values are parsed as.
+type Type int
+
+// Field types.
+const (
+	String Type = iota
+	Int         // int64
+	Bool
+	Time // RFC 3339 timestamps or 2006-01-02 dates, in UTC
+)
+
+// defaultOps are the operators allowed on each type.
+var defaultOps = map[Type][]Op{
+	String: {Eq, Ne, In, Out},
+	Int:    {Eq, Ne, Lt, Le, Gt, Ge, In, Out},
+	Bool:   {Eq, Ne},
+	Time:   {Eq, Ne, Lt, Le, Gt, Ge},
+}
+
+// Field declares a filterable field.
+type Field struct {
+	Type Type
+
+	// Ops restricts the operators of the field (default: those of the
+	// type)
+	Ops []Op
+
+	// Wildcard lets == and != of string values match * as any run of
+	// characters
+	Wildcard bool
+}
+
+// allows reports whether op may compare the field.
+func (f Field) allows(op Op) bool {
+	switch op {
+	case Like:
+		op = Eq
+	case NotLike:
+		op = Ne
+	}
+	ops := f.Ops
+	if ops == nil {
+		ops = defaultOps[f.Type]
+	}
+	return slices.Contains(ops, op)
+}
+
+// Schema is the allow-list of the fields of a listing.
+type Schema struct {
+	Fields map[string]Field
+
+	// MaxComparisons bounds the comparisons of an expression (default 10)
+	MaxComparisons int
+
+	// MaxLength bounds the length of an expression (default 512)
+	MaxLength int
+}
+
+// Node is a node of a spec: an And, an Or or a Comparison.
+type Node interface {
+	match(value func(field string) any) bool
+	write(b *strings.Builder, nested bool)
+}
+
+// And matches when all its nodes match.
+type And []Node
+
+// Or matches when any of its nodes matches.
+type Or []Node
+
+// Comparison compares a field with values: one, or the list of In and
+// Out. Values have the Go type of the field type: string, int64, bool or
+// time.Time.
+type Comparison struct {
+	Field  string
+	Op     Op
+	Values []any
+}
+
+// Spec is a parsed filter. The zero value matches everything.
+type Spec struct {
+	root Node
+}
+
+// Root returns the root node, nil for the zero value.
+func (s Spec) Root() Node { return s.root }
+
+// IsZero reports whether s filters nothing out.
+func (s Spec) IsZero() bool { return s.root == nil }
+
+// Match reports whether the item whose field values value returns
+// matches. Values have the Go type of the field, or any integer type for
+// Int fields.
+func (s Spec) Match(value func(field string) any) bool {
+	return s.root == nil || s.root.match(value)
+}
+
+// String returns the canonical expression of s, equal for equal filters
+// however they were written, e.g. to key caches and cursors.
+func (s Spec) String() string {
+	if s.root == nil {
+		return ""
+	}
+	var b strings.Builder
+	s.root.write(&b, false)
+	return b.String()
+}
+
+// Parse parses expr against schema. An empty expression is the zero Spec.
+//
+// Parameters:
+//   - expr: RSQL expression, e.g. "status==active;created_at>2024-01-01"
+//   - schema: Fields the expression may use
+//
+// Returns:
+//   - Spec: Parsed filter
+//   - error: *Error wrapping ErrInvalid
+func Parse(expr string, schema Schema) (Spec, error) {
+	if schema.MaxComparisons <= 0 {
+		schema.MaxComparisons = 10
+	}
+	if schema.MaxLength <= 0 {
+		schema.MaxLength = 512
+	}
+	if len(expr) > schema.MaxLength {
+		return Spec{}, &Error{Pos: schema.MaxLength, Msg: fmt.Sprintf("expression longer than %d characters", schema.MaxLength)}
+	}
+	p := &parser{in: expr, schema: schema}
+	p.skipSpace()
+	if p.pos == len(p.in) {
+		return Spec{}, nil
+	}
+	root, err := p.or()
+	if err != nil {
+		return Spec{}, err
+	}
+	if p.pos < len(p.in) {
+		return Spec{}, p.errorf("unexpected %q", p.in[p.pos])
+	}
+	return Spec{root: root}, nil
+}
+
+// parser is a recursive descent parser of expressions.
+type parser struct {
+	in          string
+	pos         int
+	schema      Schema
+	comparisons int
+	depth       int
+}
+
+// maxDepth bounds the nesting of parentheses.
+const maxDepth = 8
+
+func (p *parser) errorf(format string, args ...any) *Error {
+	return &Error{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// or parses and { "," and }.
+func (p *parser) or() (Node, error) {
+	var nodes Or
+	for {
+		node, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.consume(',') {
+			break
+		}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// and parses constraint { ";" constraint }.
+func (p *parser) and() (Node, error) {
+	var nodes And
+	for {
+		node, err := p.constraint()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.consume(';') {
+			break
+		}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// constraint parses "(" or ")" or a comparison.
+func (p *parser) constraint() (Node, error) {
+	if !p.consume('(') {
+		return p.comparison()
+	}
+	if p.depth++; p.depth > maxDepth {
+		return nil, p.errorf("groups nested deeper than %d", maxDepth)
+	}
+	node, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume(')') {
+		return nil, p.errorf("missing )")
+	}
+	p.depth--
+	return node, nil
+}
+
+// comparison parses selector operator arguments.
+func (p *parser) comparison() (Node, error) {
+	start := p.pos
+	for p.pos < len(p.in) && isSelector(p.in[p.pos], p.pos == start) {
+		p.pos++
+	}
+	name := p.in[start:p.pos]
+	if name == "" {
+		return nil, p.errorf("expected a field")
+	}
+	field, ok := p.schema.Fields[name]
+	if !ok {
+		return nil, &Error{Pos: start, Msg: fmt.Sprintf("unknown field %q", name)}
+	}
+	if p.comparisons++; p.comparisons > p.schema.MaxComparisons {
+		return nil, &Error{Pos: start, Msg: fmt.Sprintf("more than %d comparisons", p.schema.MaxComparisons)}
+	}
+
+	p.skipSpace()
+	opPos := p.pos
+	var op Op
+	for _, o := range operators {
+		if strings.HasPrefix(p.in[p.pos:], o.text) {
+			op = o.op
+			p.pos += len(o.text)
+			break
+		}
+	}
+	if op == "" {
+		return nil, &Error{Pos: opPos, Msg: "expected an operator"}
+	}
+	p.skipSpace()
+
+	var raws []rawValue
+	if op == In || op == Out {
+		if !p.consume('(') {
+			return nil, p.errorf("expected ( after %s", op)
+		}
+		for {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			raws = append(raws, v)
+			if !p.consume(',') {
+				break
+			}
+		}
+		if !p.consume(')') {
+			return nil, p.errorf("missing )")
+		}
+	} else {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, v)
+	}
+
+	if field.Type == String && field.Wildcard && !raws[0].quoted && strings.Contains(raws[0].text, "*") {
+		switch op {
+		case Eq:
+			op = Like
+		case Ne:
+			op = NotLike
+		}
+	}
+	if !field.allows(op) {
+		return nil, &Error{Pos: opPos, Msg: fmt.Sprintf("operator %s not allowed on %s", op, name)}
+	}
+
+	c := Comparison{Field: name, Op: op, Values: make([]any, len(raws))}
+	for i, raw := range raws {
+		v, err := convert(field.Type, raw.text)
+		if err != nil {
+			return nil, &Error{Pos: raw.pos, Msg: fmt.Sprintf("%s of %s", err, name)}
+		}
+		c.Values[i] = v
+	}
+	p.skipSpace()
+	return c, nil
+}
+
+// rawValue is a value as written.
+type rawValue struct {
+	text   string
+	pos    int
+	quoted bool
+}
+
+// value parses a quoted or unreserved value.
+func (p *parser) value() (rawValue, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.in) && (p.in[p.pos] == '"' || p.in[p.pos] == '\'') {
+		quote := p.in[p.pos]
+		var b strings.Builder
+		for p.pos++; p.pos < len(p.in); p.pos++ {
+			c := p.in[p.pos]
+			switch {
+			case c == '\\' && p.pos+1 < len(p.in):
+				p.pos++
+				b.WriteByte(p.in[p.pos])
+			case c == quote:
+				p.pos++
+				p.skipSpace()
+				return rawValue{text: b.String(), pos: start, quoted: true}, nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return rawValue{}, &Error{Pos: start, Msg: "unterminated quote"}
+	}
+	for p.pos < len(p.in) && !isReserved(p.in[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return rawValue{}, p.errorf("expected a value")
+	}
+	text := p.in[start:p.pos]
+	p.skipSpace()
+	return rawValue{text: text, pos: start}, nil
+}
+
+// consume skips c and the spaces around it, reporting whether it was next.
+func (p *parser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.in) && p.in[p.pos] == c {
+		p.pos++
+		p.skipSpace()
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.in) && (p.in[p.pos] == ' ' || p.in[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func isSelector(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9', c == '.':
+		return !first
+	}
+	return false
+}
+
+// isReserved reports whether c ends an unquoted value.
+func isReserved(c byte) bool {
+	return strings.IndexByte(" \t\"'();,=!~<>", c) >= 0
+}
+
+// convert parses text as a value of t.
+func convert(t Type, text string) (any, error) {
+	switch t {
+	case Int:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", text)
+		}
+		return n, nil
+	case Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", text)
+		}
+		return b, nil
+	case Time:
+		if ts, err := time.Parse(time.RFC3339Nano, text); err == nil {
+			return ts.UTC(), nil
+		}
+		if ts, err := time.Parse(time.DateOnly, text); err == nil {
+			return ts, nil
+		}
+		return nil, fmt.Errorf("%q is not a date or RFC 3339 time", text)
+	default:
+		return text, nil
+	}
+}
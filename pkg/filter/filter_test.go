@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schema = Schema{Fields: map[string]Field{
+	"status":     {Type: String},
+	"email":      {Type: String, Wildcard: true},
+	"age":        {Type: Int},
+	"active":     {Type: Bool},
+	"created_at": {Type: Time},
+	"role":       {Type: String, Ops: []Op{Eq}},
+}}
+
+func TestParse_Canonical(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"status==active;created_at>2024-01-01", "status==active;created_at=gt=2024-01-01T00:00:00Z"},
+		{" status == active ; age =ge= 18 ", "status==active;age=ge=18"},
+		{"age<18,age>=65;active==true", "age=lt=18,age=ge=65;active==true"},
+		{"(age<18,age>=65);active==true", "(age=lt=18,age=ge=65);active==true"},
+		{"status=in=(active, 'on hold')", `status=in=(active,"on hold")`},
+		{"status=out=(a)", "status=out=(a)"},
+		{`email==*@example.com`, `email==*@example.com`},
+		{`email=="*@example.com"`, `email=="*@example.com"`},
+		{`status=='it\'s'`, `status=="it's"`},
+		{"created_at<=2024-01-15T10:30:00+02:00", "created_at=le=2024-01-15T08:30:00Z"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			spec, err := Parse(tt.in, schema)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, spec.String())
+
+			again, err := Parse(spec.String(), schema)
+			require.NoError(t, err)
+			assert.Equal(t, spec, again, "the canonical form parses back")
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		in  string
+		msg string
+		pos int
+	}{
+		{"password==x", `unknown field "password"`, 0},
+		{"status~=x", "expected an operator", 6},
+		{"status<x", "operator =lt= not allowed on status", 6},
+		{"role!=admin", "operator != not allowed on role", 4},
+		{"active==yes", `"yes" is not true or false of active`, 8},
+		{"age==1.5", `"1.5" is not an integer of age`, 5},
+		{"created_at>yesterday", `"yesterday" is not a date or RFC 3339 time of created_at`, 11},
+		{"status==", "expected a value", 8},
+		{"status=in=a", "expected ( after =in=", 10},
+		{"(status==a", "missing )", 10},
+		{"status==a;", "expected a field", 10},
+		{"status==a)", `unexpected ')'`, 9},
+		{"status=='a", "unterminated quote", 8},
+		{"((((((((((status==a))))))))))", "groups nested deeper than 8", 9},
+		{"age==1;age==2;age==3;age==4;age==5;age==6;age==7;age==8;age==9;age==10;age==11", "more than 10 comparisons", 71},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in, schema)
+			require.ErrorIs(t, err, ErrInvalid)
+			var ferr *Error
+			require.ErrorAs(t, err, &ferr)
+			assert.Equal(t, tt.msg, ferr.Msg)
+			assert.Equal(t, tt.pos, ferr.Pos)
+		})
+	}
+
+	_, err := Parse(string(make([]byte, 513)), schema)
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestSpec_Match(t *testing.T) {
+	item := map[string]any{
+		"status":     "active",
+		"email":      "jane@example.com",
+		"age":        30,
+		"active":     true,
+		"created_at": time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		"role":       "admin",
+	}
+	value := func(field string) any { return item[field] }
+
+	tests := map[string]bool{
+		"":                                       true,
+		"status==active":                         true,
+		"status!=active":                         false,
+		"status==active;created_at>2024-01-01":   true,
+		"status==active;created_at<2024-01-01":   false,
+		"status==inactive,age>=18":               true,
+		"(status==inactive,age<18);active==true": false,
+		"age=in=(10,20,30)":                      true,
+		"age=out=(10,20,30)":                     false,
+		"email==*@example.com":                   true,
+		"email==jane*":                           true,
+		"email==*@example.org":                   false,
+		"email!=*@example.org":                   true,
+		"email==j*e@*.com":                       true,
+		`email=="*@example.com"`:                 false,
+		"created_at==2024-03-01":                 true,
+	}
+	for expr, want := range tests {
+		t.Run(expr, func(t *testing.T) {
+			spec, err := Parse(expr, schema)
+			require.NoError(t, err)
+			assert.Equal(t, want, spec.Match(value))
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package filter
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (n And) match(value func(string) any) bool {
+	for _, node := range n {
+		if !node.match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n Or) match(value func(string) any) bool {
+	for _, node := range n {
+		if node.match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Comparison) match(value func(string) any) bool {
+	actual := value(c.Field)
+	switch c.Op {
+	case Like, NotLike:
+		s, ok := actual.(string)
+		return ok && glob(c.Values[0].(string), s) == (c.Op == Like)
+	case In, Out:
+		found := false
+		for _, v := range c.Values {
+			if order, ok := compare(actual, v); ok && order == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (c.Op == In)
+	}
+
+	order, ok := compare(actual, c.Values[0])
+	if !ok {
+		return c.Op == Ne
+	}
+	switch c.Op {
+	case Eq:
+		return order == 0
+	case Ne:
+		return order != 0
+	case Lt:
+		return order < 0
+	case Le:
+		return order <= 0
+	case Gt:
+		return order > 0
+	case Ge:
+		return order >= 0
+	}
+	return false
+}
+
+// compare orders the value of an item with a parsed value, reporting
+// false when their types differ.
+func compare(actual, want any) (int, bool) {
+	switch w := want.(type) {
+	case string:
+		a, ok := actual.(string)
+		return strings.Compare(a, w), ok
+	case int64:
+		a, ok := toInt64(actual)
+		return cmp.Compare(a, w), ok
+	case bool:
+		a, ok := actual.(bool)
+		if !ok || a == w {
+			return 0, ok
+		}
+		return 1, true
+	case time.Time:
+		a, ok := actual.(time.Time)
+		return a.Compare(w), ok
+	}
+	return 0, false
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// glob reports whether s matches pattern, in which * matches any run of
+// characters.
+func glob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return len(parts) > 1 && strings.HasSuffix(s, last) || len(parts) == 1 && s == ""
+}
+
+func (n And) write(b *strings.Builder, _ bool) {
+	for i, node := range n {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		node.write(b, true)
+	}
+}
+
+func (n Or) write(b *strings.Builder, nested bool) {
+	if nested {
+		b.WriteByte('(')
+	}
+	for i, node := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		node.write(b, false)
+	}
+	if nested {
+		b.WriteByte(')')
+	}
+}
+
+func (c Comparison) write(b *strings.Builder, _ bool) {
+	b.WriteString(c.Field)
+	switch c.Op {
+	case Like:
+		b.WriteString(string(Eq))
+	case NotLike:
+		b.WriteString(string(Ne))
+	default:
+		b.WriteString(string(c.Op))
+	}
+	if c.Op == In || c.Op == Out {
+		b.WriteByte('(')
+	}
+	for i, v := range c.Values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(formatValue(v, c.Op == Like || c.Op == NotLike))
+	}
+	if c.Op == In || c.Op == Out {
+		b.WriteByte(')')
+	}
+}
+
+// formatValue writes v so it parses back to itself; strings are quoted
+// when they hold reserved characters, or a * that is no wildcard.
+func formatValue(v any, pattern bool) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case string:
+		if pattern || (v != "" && !strings.ContainsFunc(v, func(r rune) bool {
+			return r == '*' || r == '\\' || r < 0x80 && isReserved(byte(r))
+		})) {
+			return v
+		}
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+	}
+	return ""
+}
@@ -0,0 +1,235 @@
+// Package grpcclient provides named gRPC client connections, the gRPC
+// counterpart of the httpclient registry.
+//
+// Every connection propagates the trace context of the call context,
+// retries failed unary calls, logs failures and records per-client
+// Prometheus metrics:
+//
+//	registry, err := grpcclient.NewRegistry(configs, grpcclient.Options{Metrics: metrics, Logger: log})
+//	conn, ok := registry.Conn("billing")
+//	client := billingpb.NewBillingClient(conn)
+package grpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/discovery"
+	"github.com/luminosita/change-me/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Retry defaults applied to zero-valued Config fields.
+const (
+	DefaultRetryBackoff    = 100 * time.Millisecond
+	DefaultMaxRetryBackoff = 2 * time.Second
+)
+
+// DefaultRetryCodes are the status codes retried when Config.RetryCodes is
+// empty: failures where the request was not processed.
+var DefaultRetryCodes = []string{"Unavailable", "ResourceExhausted"}
+
+// Config configures a named connection.
+type Config struct {
+	Name string `yaml:"name" validate:"required"`
+
+	// Target is a gRPC target ("host:port", "dns:///host:port", or
+	// "discovery:///service" resolved by Options.Discovery)
+	Target string `yaml:"target" validate:"required"`
+
+	// Timeout bounds calls whose context has no deadline (0 disables)
+	Timeout time.Duration `yaml:"timeout" validate:"min=0"`
+
+	TLS       TLSConfig       `yaml:"tls"`
+	Keepalive KeepaliveConfig `yaml:"keepalive"`
+
+	// Retries of unary calls failing with RetryCodes, waiting RetryBackoff
+	// times the attempt number (capped at MaxRetryBackoff) in between
+	Retries         int           `yaml:"retries" validate:"min=0,max=10"`
+	RetryBackoff    time.Duration `yaml:"retry_backoff" validate:"min=0"`
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff" validate:"min=0"`
+	RetryCodes      []string      `yaml:"retry_codes" validate:"dive,required"`
+}
+
+// TLSConfig configures transport security. Without Enabled the connection
+// is plaintext.
+type TLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"`     // PEM roots (default: system pool)
+	CertFile   string `yaml:"cert_file"`   // Client certificate for mTLS
+	KeyFile    string `yaml:"key_file"`    // Client key for mTLS
+	ServerName string `yaml:"server_name"` // Overrides the verified host name
+
+	// InsecureSkipVerify disables certificate verification (development only)
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// KeepaliveConfig configures client keepalive pings; a zero Time disables
+// them.
+type KeepaliveConfig struct {
+	Time                time.Duration `yaml:"time" validate:"min=0"`
+	Timeout             time.Duration `yaml:"timeout" validate:"min=0"`
+	PermitWithoutStream bool          `yaml:"permit_without_stream"`
+}
+
+// Options holds the dependencies shared by the registry's connections.
+type Options struct {
+	Metrics *Metrics       // Call metrics (nil records nothing)
+	Logger  *logger.Logger // Failed call log (nil disables logging)
+
+	// Discovery resolves "discovery:///service" targets, balancing calls
+	// round-robin over the service endpoints
+	Discovery *discovery.Resolver
+
+	// DialOptions are appended to the options built from Config
+	DialOptions []grpc.DialOption
+}
+
+// Registry holds the named connections.
+type Registry struct {
+	conns map[string]*grpc.ClientConn
+}
+
+// NewRegistry creates a connection per config. Connections are
+// established lazily on first use.
+//
+// Parameters:
+//   - configs: Named connections
+//   - opts: Shared dependencies
+//
+// Returns:
+//   - *Registry: Connection registry
+//   - error: Invalid settings or unreadable TLS files
+func NewRegistry(configs []Config, opts Options) (*Registry, error) {
+	r := &Registry{conns: make(map[string]*grpc.ClientConn, len(configs))}
+	for _, cfg := range configs {
+		conn, err := newConn(cfg, opts)
+		if err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("grpc client %s: %w", cfg.Name, err)
+		}
+		r.conns[cfg.Name] = conn
+	}
+	return r, nil
+}
+
+// Conn returns the named connection and whether it is declared.
+func (r *Registry) Conn(name string) (*grpc.ClientConn, bool) {
+	conn, ok := r.conns[name]
+	return conn, ok
+}
+
+// Names returns the declared connection names in order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.conns))
+	for name := range r.conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close closes every connection.
+func (r *Registry) Close() error {
+	var errs []error
+	for _, conn := range r.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// newConn creates a connection with the configured credentials, keepalive
+// and interceptors (outermost first: timeout, tracing, retry, logging,
+// metrics, so every attempt is logged and measured).
+func newConn(cfg Config, opts Options) (*grpc.ClientConn, error) {
+	creds, err := transportCredentials(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := newRetrier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(
+			timeoutUnary(cfg.Timeout),
+			tracingUnary(),
+			retry.unary(opts.Metrics),
+			loggingUnary(cfg.Name, opts.Logger),
+			opts.Metrics.unary(cfg.Name),
+		),
+		grpc.WithChainStreamInterceptor(
+			tracingStream(),
+			loggingStream(cfg.Name, opts.Logger),
+			opts.Metrics.stream(cfg.Name),
+		),
+	}
+	if cfg.Keepalive.Time > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.Keepalive.Time,
+			Timeout:             cfg.Keepalive.Timeout,
+			PermitWithoutStream: cfg.Keepalive.PermitWithoutStream,
+		}))
+	}
+	if opts.Discovery != nil {
+		dialOpts = append(dialOpts, grpc.WithResolvers(discovery.GRPCBuilder(opts.Discovery)))
+	}
+	dialOpts = append(dialOpts, opts.DialOptions...)
+
+	return grpc.NewClient(cfg.Target, dialOpts...)
+}
+
+// transportCredentials builds the connection security from cfg.
+func transportCredentials(cfg TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	tlsConfig, err := cfg.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// ClientConfig builds the client TLS settings of c, loading its files. It
+// returns nil when c is not enabled, for clients dialing plaintext.
+func (c TLSConfig) ClientConfig() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,107 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/tracecontext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestRegistry_RetriesAndPropagatesTraceContext(t *testing.T) {
+	server := &flakyHealth{failures: 2}
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+	registry := newTestRegistry(t, server, Config{Name: "billing", Target: "passthrough:///bufnet", Retries: 2, RetryBackoff: time.Millisecond}, metrics)
+
+	conn, ok := registry.Conn("billing")
+	require.True(t, ok)
+	ctx := tracecontext.NewRoot(context.Background())
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	assert.Equal(t, int32(3), server.calls.Load())
+	assert.Contains(t, server.traceparent.Load(), tracecontext.TraceID(ctx))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.retries.WithLabelValues("billing")))
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "grpc_client_requests_total"), "Unavailable and OK attempts")
+}
+
+func TestRegistry_DoesNotRetryOtherCodes(t *testing.T) {
+	server := &flakyHealth{failures: 5, code: codes.InvalidArgument}
+	registry := newTestRegistry(t, server, Config{Name: "billing", Target: "passthrough:///bufnet", Retries: 3}, nil)
+
+	conn, _ := registry.Conn("billing")
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, int32(1), server.calls.Load())
+}
+
+func TestNewRegistry_InvalidConfig(t *testing.T) {
+	_, err := NewRegistry([]Config{{Name: "billing", Target: "localhost:9000", RetryCodes: []string{"Sometimes"}}}, Options{})
+	assert.ErrorContains(t, err, "grpc client billing")
+
+	_, err = NewRegistry([]Config{{Name: "billing", Target: "localhost:9000", TLS: TLSConfig{Enabled: true, CAFile: "missing.pem"}}}, Options{})
+	assert.ErrorContains(t, err, "CA file")
+
+	registry, err := NewRegistry([]Config{{Name: "b", Target: "localhost:1"}, {Name: "a", Target: "localhost:2", RetryCodes: []string{"UNAVAILABLE"}}}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, registry.Names())
+	assert.NoError(t, registry.Close())
+}
+
+// newTestRegistry serves server over an in-memory listener and returns a
+// registry connected to it.
+func newTestRegistry(t *testing.T, server healthpb.HealthServer, cfg Config, metrics *Metrics) *Registry {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, server)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	registry, err := NewRegistry([]Config{cfg}, Options{
+		Metrics: metrics,
+		DialOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		})},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = registry.Close() })
+	return registry
+}
+
+// flakyHealth fails the first calls with code (default Unavailable).
+type flakyHealth struct {
+	healthpb.UnimplementedHealthServer
+	failures    int32
+	code        codes.Code
+	calls       atomic.Int32
+	traceparent atomic.Value
+}
+
+func (s *flakyHealth) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(tracecontext.HeaderTraceparent)) > 0 {
+		s.traceparent.Store(md.Get(tracecontext.HeaderTraceparent)[0])
+	}
+	if s.calls.Add(1) <= s.failures {
+		code := s.code
+		if code == codes.OK {
+			code = codes.Unavailable
+		}
+		return nil, status.Error(code, "not ready")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
@@ -0,0 +1,283 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics holds the gRPC client collectors. A nil *Metrics records nothing.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+}
+
+// NewMetrics creates the gRPC client collectors and registers them.
+// It panics if the collectors are already registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_requests_total",
+			Help: "Outbound gRPC calls by client, method and status code.",
+		}, []string{"client", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_client_request_duration_seconds",
+			Help:    "Duration of outbound gRPC calls (streams until they end).",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"client", "method"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_retries_total",
+			Help: "Outbound gRPC call retries by client.",
+		}, []string{"client"}),
+	}
+	reg.MustRegister(m.requests, m.duration, m.retries)
+	return m
+}
+
+// observe records a finished call.
+func (m *Metrics) observe(client, method string, err error, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(client, method, status.Code(err).String()).Inc()
+	m.duration.WithLabelValues(client, method).Observe(elapsed.Seconds())
+}
+
+// unary measures every unary call attempt.
+func (m *Metrics) unary(client string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(client, method, err, time.Since(start))
+		return err
+	}
+}
+
+// stream measures streams from creation until they end.
+func (m *Metrics) stream(client string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		return onFinish(desc, stream, err, func(err error) {
+			m.observe(client, method, err, time.Since(start))
+		})
+	}
+}
+
+// loggingUnary logs failed unary call attempts.
+func loggingUnary(client string, log *logger.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logFailure(log, client, method, err, time.Since(start))
+		return err
+	}
+}
+
+// loggingStream logs streams ending with an error.
+func loggingStream(client string, log *logger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		return onFinish(desc, stream, err, func(err error) {
+			logFailure(log, client, method, err, time.Since(start))
+		})
+	}
+}
+
+// logFailure logs a call that ended with err.
+func logFailure(log *logger.Logger, client, method string, err error, elapsed time.Duration) {
+	if err == nil || log == nil {
+		return
+	}
+	log.Warnw("grpc_client_call_failed",
+		"client", client,
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration_ms", elapsed.Milliseconds(),
+		"error", err,
+	)
+}
+
+// timeoutUnary bounds unary calls without a deadline.
+func timeoutUnary(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+var propagator = propagation.TraceContext{}
+
+// metadataCarrier adapts outgoing metadata to the propagation API.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// withTraceContext returns ctx with its trace context in the outgoing
+// metadata.
+func withTraceContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// tracingUnary propagates the trace context of unary calls.
+func tracingUnary() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withTraceContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// tracingStream propagates the trace context of streams.
+func tracingStream() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withTraceContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// retrier retries unary calls failing with retryable codes.
+type retrier struct {
+	client     string
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	codes      map[codes.Code]bool
+}
+
+// newRetrier validates the retry settings of cfg.
+func newRetrier(cfg Config) (*retrier, error) {
+	r := &retrier{
+		client:     cfg.Name,
+		retries:    cfg.Retries,
+		backoff:    cfg.RetryBackoff,
+		maxBackoff: cfg.MaxRetryBackoff,
+		codes:      map[codes.Code]bool{},
+	}
+	if r.backoff <= 0 {
+		r.backoff = DefaultRetryBackoff
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = DefaultMaxRetryBackoff
+	}
+
+	names := cfg.RetryCodes
+	if len(names) == 0 {
+		names = DefaultRetryCodes
+	}
+	for _, name := range names {
+		code, ok := parseCode(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown retry code %q", name)
+		}
+		r.codes[code] = true
+	}
+	return r, nil
+}
+
+// unary returns the retrying interceptor.
+func (r *retrier) unary(metrics *Metrics) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+		for attempt := 0; attempt <= r.retries; attempt++ {
+			if attempt > 0 {
+				if metrics != nil {
+					metrics.retries.WithLabelValues(r.client).Inc()
+				}
+				select {
+				case <-ctx.Done():
+					return err
+				case <-time.After(min(r.backoff*time.Duration(attempt), r.maxBackoff)):
+				}
+			}
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !r.codes[status.Code(err)] {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+// parseCode resolves a status code name ("Unavailable" or "UNAVAILABLE").
+func parseCode(name string) (codes.Code, bool) {
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(`"` + name + `"`)); err == nil {
+		return code, true
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == name {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// onFinish wraps a newly created stream so finish is called once when it
+// ends: on creation failure, on a receive error, at io.EOF or after the
+// single response of a client-streaming call.
+func onFinish(desc *grpc.StreamDesc, stream grpc.ClientStream, err error, finish func(error)) (grpc.ClientStream, error) {
+	if err != nil {
+		finish(err)
+		return nil, err
+	}
+	return &finishingStream{ClientStream: stream, serverStreams: desc.ServerStreams, finish: finish}, nil
+}
+
+// finishingStream reports the end of a stream.
+type finishingStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	once          sync.Once
+	finish        func(error)
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *finishingStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.serverStreams {
+		s.once.Do(func() {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			s.finish(err)
+		})
+	}
+	return err
+}
@@ -0,0 +1,204 @@
+// Package heartbeat pushes periodic health reports to external monitors
+// (Healthchecks.io, OpsGenie heartbeats, Uptime Kuma push URLs, ...), which
+// alert when reports stop arriving.
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Defaults applied to zero-valued Options fields.
+const (
+	DefaultInterval = time.Minute
+	DefaultTimeout  = 10 * time.Second
+	DefaultBackoff  = time.Second
+)
+
+// Status values reported to monitors.
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+)
+
+// Report is the JSON body posted to every monitor URL.
+type Report struct {
+	Status        string  `json:"status"`
+	Service       string  `json:"service"`
+	Version       string  `json:"version"`
+	Instance      string  `json:"instance,omitempty"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	Timestamp     string  `json:"timestamp"`
+}
+
+// Options configures a Reporter.
+type Options struct {
+	URLs       []string      // Monitor endpoints receiving the report
+	Interval   time.Duration // Time between reports (default 1m)
+	Timeout    time.Duration // Per-attempt request timeout (default 10s)
+	Retries    int           // Extra attempts per URL and report
+	Backoff    time.Duration // Delay before the first retry, doubled per retry (default 1s)
+	FailSuffix string        // Appended to a URL for unhealthy reports, e.g. "/fail" for Healthchecks.io
+	Service    string        // Service name in reports
+	Version    string        // Service version in reports
+	Instance   string        // Instance identifier, e.g. the hostname
+}
+
+// Reporter periodically posts a health Report to each configured URL.
+type Reporter struct {
+	opts    Options
+	client  *http.Client
+	status  func(context.Context) string
+	log     *logger.Logger
+	started time.Time
+	failing map[string]bool
+}
+
+// New creates a reporter. status returns StatusHealthy or StatusUnhealthy;
+// nil always reports healthy.
+func New(opts Options, client *http.Client, status func(context.Context) string, log *logger.Logger) *Reporter {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if status == nil {
+		status = func(context.Context) string { return StatusHealthy }
+	}
+
+	return &Reporter{
+		opts:    opts,
+		client:  client,
+		status:  status,
+		log:     log,
+		started: time.Now(),
+		failing: make(map[string]bool, len(opts.URLs)),
+	}
+}
+
+// Run reports immediately and then every interval until ctx is done.
+// It always returns nil; delivery failures are logged.
+func (r *Reporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.Beat(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Beat sends one report to every URL, retrying failed deliveries.
+func (r *Reporter) Beat(ctx context.Context) {
+	now := time.Now()
+	report := Report{
+		Status:        r.status(ctx),
+		Service:       r.opts.Service,
+		Version:       r.opts.Version,
+		Instance:      r.opts.Instance,
+		UptimeSeconds: now.Sub(r.started).Seconds(),
+		Timestamp:     now.UTC().Format(time.RFC3339),
+	}
+	body, _ := json.Marshal(report)
+
+	for _, monitor := range r.opts.URLs {
+		target := monitor
+		if report.Status != StatusHealthy {
+			target += r.opts.FailSuffix
+		}
+
+		err := r.deliver(ctx, target, body)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return
+		case err != nil:
+			// Monitors alert on missing reports; log so the cause is visible locally
+			r.failing[monitor] = true
+			r.log.Errorw("heartbeat_failed", "monitor", monitorHost(monitor), "attempts", r.opts.Retries+1, "error", err)
+		case r.failing[monitor]:
+			delete(r.failing, monitor)
+			r.log.Infow("heartbeat_recovered", "monitor", monitorHost(monitor))
+		}
+	}
+}
+
+// deliver posts body to target, retrying with exponential backoff.
+func (r *Reporter) deliver(ctx context.Context, target string, body []byte) error {
+	backoff := r.opts.Backoff
+	var err error
+	for attempt := 0; attempt <= r.opts.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = r.post(ctx, target, body); err == nil {
+			return nil
+		}
+		r.log.Warnw("heartbeat_attempt_failed", "monitor", monitorHost(target), "attempt", attempt+1, "error", err)
+	}
+	return err
+}
+
+func (r *Reporter) post(ctx context.Context, target string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return redactURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return redactURL(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("monitor responded %s", resp.Status)
+	}
+	return nil
+}
+
+// redactURL drops the monitor URL that *url.Error messages quote, so the
+// secret check ID does not reach the logs.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// monitorHost returns the host of a monitor URL for logging; ping URLs
+// usually embed a secret check ID in the path.
+func monitorHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "invalid"
+	}
+	return u.Host
+}
@@ -0,0 +1,140 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
+
+func TestBeat_PostsReport(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		reports []Report
+		paths   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report Report
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+		mu.Lock()
+		reports = append(reports, report)
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	status := StatusHealthy
+	r := New(Options{
+		URLs:       []string{srv.URL + "/a", srv.URL + "/b"},
+		FailSuffix: "/fail",
+		Service:    "svc",
+		Version:    "1.2.3",
+	}, srv.Client(), func(context.Context) string { return status }, newTestLogger(t))
+
+	r.Beat(context.Background())
+	status = StatusUnhealthy
+	r.Beat(context.Background())
+
+	require.Len(t, reports, 4)
+	assert.Equal(t, []string{"/a", "/b", "/a/fail", "/b/fail"}, paths)
+	assert.Equal(t, StatusHealthy, reports[0].Status)
+	assert.Equal(t, "svc", reports[0].Service)
+	assert.Equal(t, "1.2.3", reports[0].Version)
+	assert.NotEmpty(t, reports[0].Timestamp)
+	assert.Equal(t, StatusUnhealthy, reports[2].Status)
+}
+
+func TestBeat_RetriesFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	r := New(Options{URLs: []string{srv.URL}, Retries: 2, Backoff: time.Millisecond}, srv.Client(), nil, newTestLogger(t))
+	r.Beat(context.Background())
+
+	assert.Equal(t, int32(3), calls.Load())
+	assert.False(t, r.failing[srv.URL])
+}
+
+func TestBeat_TracksFailingMonitor(t *testing.T) {
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	r := New(Options{URLs: []string{srv.URL}, Backoff: time.Millisecond}, srv.Client(), nil, newTestLogger(t))
+
+	r.Beat(context.Background())
+	assert.True(t, r.failing[srv.URL])
+
+	healthy.Store(true)
+	r.Beat(context.Background())
+	assert.False(t, r.failing[srv.URL])
+}
+
+func TestPost_RedactsMonitorURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	target := srv.URL + "/ping/secret-check-id"
+	srv.Close()
+
+	r := New(Options{URLs: []string{target}}, http.DefaultClient, nil, newTestLogger(t))
+	err := r.post(context.Background(), target, nil)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-check-id")
+
+	err = r.post(context.Background(), "http://monitor.example.com/secret-check-id\x7f", nil)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-check-id")
+}
+
+func TestRun_StopsOnCancel(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := New(Options{URLs: []string{srv.URL}, Interval: 5 * time.Millisecond}, srv.Client(), nil, newTestLogger(t))
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestMonitorHost(t *testing.T) {
+	assert.Equal(t, "hc-ping.com", monitorHost("https://hc-ping.com/secret-uuid"))
+	assert.Equal(t, "invalid", monitorHost("not a url"))
+}
@@ -0,0 +1,358 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache defaults applied to zero-valued CacheConfig fields.
+const (
+	DefaultCacheMaxBodyBytes  = 1 << 20
+	DefaultCacheRevalidateTTL = time.Hour
+)
+
+// CacheStatusHeader reports how the cache served a response: HIT (fresh
+// entry), REVALIDATED (304 from the upstream) or MISS.
+const CacheStatusHeader = "X-Cache"
+
+// Cache statuses.
+const (
+	CacheHit         = "HIT"
+	CacheRevalidated = "REVALIDATED"
+	CacheMiss        = "MISS"
+)
+
+// cacheKeyPrefix namespaces entries in shared stores.
+const cacheKeyPrefix = "httpcache:"
+
+// cacheableStatus lists statuses cacheable by default (RFC 7231 6.1).
+var cacheableStatus = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+// ResponseStore persists cached responses. It is satisfied by the
+// service result cache stores.
+type ResponseStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// CacheConfig configures a client's response cache.
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// MaxBodyBytes skips caching larger responses (default 1 MiB)
+	MaxBodyBytes int64 `yaml:"max_body_bytes" validate:"min=0"`
+
+	// RevalidateTTL keeps stale entries with an ETag or Last-Modified
+	// validator for conditional requests (default 1h)
+	RevalidateTTL time.Duration `yaml:"revalidate_ttl" validate:"min=0"`
+}
+
+// cachedResponse is the stored form of a response.
+type cachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+	Vary     http.Header `json:"vary,omitempty"`
+}
+
+// CacheTransport is a private HTTP cache (RFC 7234) for GET requests.
+// Fresh entries are served without contacting the upstream; stale entries
+// with validators are revalidated with If-None-Match / If-Modified-Since.
+// Successful unsafe requests invalidate the target URL. Store failures
+// fall back to the upstream.
+type CacheTransport struct {
+	Base    http.RoundTripper
+	store   ResponseStore
+	cfg     CacheConfig
+	metrics *Metrics
+	now     func() time.Time
+}
+
+// NewCacheTransport wraps base with a response cache kept in store.
+func NewCacheTransport(base http.RoundTripper, store ResponseStore, cfg CacheConfig, metrics *Metrics) *CacheTransport {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultCacheMaxBodyBytes
+	}
+	if cfg.RevalidateTTL <= 0 {
+		cfg.RevalidateTTL = DefaultCacheRevalidateTTL
+	}
+	return &CacheTransport{Base: base, store: store, cfg: cfg, metrics: metrics, now: time.Now}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key := cacheKey(req)
+
+	if req.Method != http.MethodGet {
+		resp, err := t.Base.RoundTrip(req)
+		if err == nil && unsafeMethod(req.Method) && resp.StatusCode < 400 {
+			_ = t.store.Delete(ctx, key)
+		}
+		return resp, err
+	}
+
+	reqDirectives := parseCacheControl(req.Header)
+	if _, ok := reqDirectives["no-store"]; ok {
+		return t.Base.RoundTrip(req)
+	}
+
+	entry := t.load(ctx, key, req)
+	if entry != nil && t.fresh(entry, reqDirectives) {
+		t.metrics.ObserveCache(req.URL.Host, CacheHit)
+		return t.respond(req, entry, CacheHit), nil
+	}
+
+	outReq := req
+	if entry != nil {
+		outReq = conditional(req, entry)
+	}
+	resp, err := t.Base.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != nil && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		for name, values := range resp.Header {
+			entry.Header[name] = values
+		}
+		entry.StoredAt = t.now()
+		t.save(ctx, key, entry)
+		t.metrics.ObserveCache(req.URL.Host, CacheRevalidated)
+		return t.respond(req, entry, CacheRevalidated), nil
+	}
+
+	t.metrics.ObserveCache(req.URL.Host, CacheMiss)
+	resp.Header.Set(CacheStatusHeader, CacheMiss)
+	return t.storeResponse(ctx, key, req, resp), nil
+}
+
+// CloseIdleConnections forwards to Base so http.Client.CloseIdleConnections
+// keeps working.
+func (t *CacheTransport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// load returns the stored entry for req, or nil when absent, unreadable or
+// selected by different Vary header values.
+func (t *CacheTransport) load(ctx context.Context, key string, req *http.Request) *cachedResponse {
+	data, ok, err := t.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	for name, values := range entry.Vary {
+		if strings.Join(req.Header.Values(name), ",") != strings.Join(values, ",") {
+			return nil
+		}
+	}
+	return &entry
+}
+
+// save stores entry for its freshness lifetime, extended by RevalidateTTL
+// when it carries validators.
+func (t *CacheTransport) save(ctx context.Context, key string, entry *cachedResponse) {
+	ttl := freshness(entry.Header, entry.StoredAt) - currentAge(entry, entry.StoredAt)
+	if hasValidator(entry.Header) {
+		ttl = max(ttl, 0) + t.cfg.RevalidateTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_ = t.store.Set(ctx, key, data, ttl)
+}
+
+// fresh reports whether entry may be served without revalidation.
+func (t *CacheTransport) fresh(entry *cachedResponse, reqDirectives map[string]string) bool {
+	if _, ok := reqDirectives["no-cache"]; ok {
+		return false
+	}
+	if _, ok := parseCacheControl(entry.Header)["no-cache"]; ok {
+		return false
+	}
+
+	lifetime := freshness(entry.Header, entry.StoredAt)
+	if maxAge, ok := directiveSeconds(reqDirectives, "max-age"); ok {
+		lifetime = min(lifetime, maxAge)
+	}
+	return currentAge(entry, t.now()) < lifetime
+}
+
+// respond builds a response from entry.
+func (t *CacheTransport) respond(req *http.Request, entry *cachedResponse, status string) *http.Response {
+	header := entry.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(currentAge(entry, t.now()).Seconds())))
+	header.Set(CacheStatusHeader, status)
+	return newResponse(req, entry.Status, header, entry.Body)
+}
+
+// storeResponse caches resp when cacheable and returns a response with an
+// unread body for the caller.
+func (t *CacheTransport) storeResponse(ctx context.Context, key string, req *http.Request, resp *http.Response) *http.Response {
+	if !cacheable(resp, t.now()) {
+		return resp
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.cfg.MaxBodyBytes+1))
+	if err != nil || int64(len(body)) > t.cfg.MaxBodyBytes {
+		// Hand back what was read followed by the rest of the stream
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &cachedResponse{
+		Status:   resp.StatusCode,
+		Header:   resp.Header.Clone(),
+		Body:     body,
+		StoredAt: t.now(),
+	}
+	entry.Header.Del(CacheStatusHeader)
+	for _, name := range resp.Header.Values("Vary") {
+		for _, field := range strings.Split(name, ",") {
+			if field = http.CanonicalHeaderKey(strings.TrimSpace(field)); field != "" {
+				if entry.Vary == nil {
+					entry.Vary = http.Header{}
+				}
+				entry.Vary[field] = req.Header.Values(field)
+			}
+		}
+	}
+	t.save(ctx, key, entry)
+	return resp
+}
+
+// cacheable reports whether resp received at now may be stored.
+func cacheable(resp *http.Response, now time.Time) bool {
+	if !cacheableStatus[resp.StatusCode] {
+		return false
+	}
+	directives := parseCacheControl(resp.Header)
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	if strings.Contains(resp.Header.Get("Vary"), "*") {
+		return false
+	}
+	return freshness(resp.Header, now) > 0 || hasValidator(resp.Header)
+}
+
+// conditional returns a copy of req validating entry.
+func conditional(req *http.Request, entry *cachedResponse) *http.Request {
+	out := req.Clone(req.Context())
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		out.Header.Set("If-None-Match", etag)
+	}
+	if modified := entry.Header.Get("Last-Modified"); modified != "" {
+		out.Header.Set("If-Modified-Since", modified)
+	}
+	return out
+}
+
+// freshness returns the explicit freshness lifetime of a response
+// received at received: max-age, else Expires relative to Date.
+func freshness(header http.Header, received time.Time) time.Duration {
+	directives := parseCacheControl(header)
+	if maxAge, ok := directiveSeconds(directives, "max-age"); ok {
+		return maxAge
+	}
+	expires := header.Get("Expires")
+	if expires == "" {
+		return 0
+	}
+	expiresAt, err := http.ParseTime(expires)
+	if err != nil {
+		return 0
+	}
+	date := received
+	if d, err := http.ParseTime(header.Get("Date")); err == nil {
+		date = d
+	}
+	return expiresAt.Sub(date)
+}
+
+// currentAge returns the age of entry at now, including the Age reported
+// by the upstream.
+func currentAge(entry *cachedResponse, now time.Time) time.Duration {
+	age := now.Sub(entry.StoredAt)
+	if seconds, err := strconv.Atoi(entry.Header.Get("Age")); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+	return age
+}
+
+// hasValidator reports whether a response can be revalidated.
+func hasValidator(header http.Header) bool {
+	return header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
+
+// parseCacheControl returns the Cache-Control directives of header.
+func parseCacheControl(header http.Header) map[string]string {
+	directives := map[string]string{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+	return directives
+}
+
+// directiveSeconds returns a delta-seconds directive.
+func directiveSeconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, true
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// unsafeMethod reports whether method may change the target resource.
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	default:
+		return true
+	}
+}
+
+// cacheKey identifies the cached response of req. Credentials are part
+// of the key so responses are never shared between identities.
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Authorization")))
+	return cacheKeyPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,189 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheTransport_ServesFreshResponses(t *testing.T) {
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "rates")
+	}))
+	defer upstream.Close()
+
+	reg := prometheus.NewRegistry()
+	client, now := newCachingClient(t, CacheConfig{}, NewMetrics(reg))
+
+	resp := get(t, client, upstream.URL, nil)
+	assert.Equal(t, CacheMiss, resp.Header.Get(CacheStatusHeader))
+
+	*now = now.Add(30 * time.Second)
+	resp = get(t, client, upstream.URL, nil)
+	assert.Equal(t, CacheHit, resp.Header.Get(CacheStatusHeader))
+	assert.Equal(t, "30", resp.Header.Get("Age"))
+	assert.Equal(t, "rates", readBody(t, resp))
+	assert.Equal(t, int32(1), hits.Load())
+
+	resp = get(t, client, upstream.URL, http.Header{"Cache-Control": {"max-age=10"}})
+	assert.Equal(t, CacheMiss, resp.Header.Get(CacheStatusHeader), "request max-age limits the accepted age")
+
+	*now = now.Add(61 * time.Second)
+	get(t, client, upstream.URL, nil)
+	assert.Equal(t, int32(3), hits.Load(), "expired entries without validators are fetched again")
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "http_client_cache_requests_total"))
+}
+
+func TestCacheTransport_RevalidatesWithETag(t *testing.T) {
+	var conditional atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "max-age=0")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = io.WriteString(w, "profile")
+	}))
+	defer upstream.Close()
+
+	client, _ := newCachingClient(t, CacheConfig{}, nil)
+	get(t, client, upstream.URL, nil)
+
+	resp := get(t, client, upstream.URL, nil)
+	assert.Equal(t, CacheRevalidated, resp.Header.Get(CacheStatusHeader))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "profile", readBody(t, resp))
+	assert.Equal(t, int32(1), conditional.Load())
+}
+
+func TestCacheTransport_SkipsUncacheableResponses(t *testing.T) {
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/any":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "*")
+		case "/large":
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		_, _ = io.WriteString(w, strings.Repeat("x", 64))
+	}))
+	defer upstream.Close()
+
+	client, _ := newCachingClient(t, CacheConfig{MaxBodyBytes: 32}, nil)
+	for _, path := range []string{"/private", "/any", "/large"} {
+		get(t, client, upstream.URL+path, nil)
+		resp := get(t, client, upstream.URL+path, nil)
+		assert.Equal(t, CacheMiss, resp.Header.Get(CacheStatusHeader), path)
+		assert.Len(t, readBody(t, resp), 64, "oversized bodies are passed through whole")
+	}
+	assert.Equal(t, int32(6), hits.Load())
+}
+
+func TestCacheTransport_VaryAndInvalidation(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = io.WriteString(w, r.Header.Get("Accept-Language"))
+	}))
+	defer upstream.Close()
+
+	client, _ := newCachingClient(t, CacheConfig{}, nil)
+	get(t, client, upstream.URL, http.Header{"Accept-Language": {"en"}})
+
+	resp := get(t, client, upstream.URL, http.Header{"Accept-Language": {"de"}})
+	assert.Equal(t, CacheMiss, resp.Header.Get(CacheStatusHeader), "different Vary values miss")
+	assert.Equal(t, "de", readBody(t, resp))
+
+	resp = get(t, client, upstream.URL, http.Header{"Accept-Language": {"de"}})
+	assert.Equal(t, CacheHit, resp.Header.Get(CacheStatusHeader))
+
+	req, err := http.NewRequest(http.MethodPut, upstream.URL, strings.NewReader("{}"))
+	require.NoError(t, err)
+	put, err := client.Do(req)
+	require.NoError(t, err)
+	_ = put.Body.Close()
+
+	resp = get(t, client, upstream.URL, http.Header{"Accept-Language": {"de"}})
+	assert.Equal(t, CacheMiss, resp.Header.Get(CacheStatusHeader), "successful writes invalidate the URL")
+}
+
+// newCachingClient returns a client caching in memory and a pointer to its
+// clock.
+func newCachingClient(t *testing.T, cfg CacheConfig, metrics *Metrics) (*http.Client, *time.Time) {
+	t.Helper()
+	now := time.Now()
+	store := &mapStore{entries: map[string][]byte{}}
+	transport := NewCacheTransport(http.DefaultTransport, store, cfg, metrics)
+	transport.now = func() time.Time { return now }
+	return &http.Client{Transport: transport}, &now
+}
+
+// get performs a GET request with header.
+func get(t *testing.T, client *http.Client, url string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// mapStore is a ResponseStore ignoring TTLs.
+type mapStore struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func (s *mapStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.entries[key]
+	return value, ok, nil
+}
+
+func (s *mapStore) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = value
+	return nil
+}
+
+func (s *mapStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/sync/singleflight"
+)
+
+// DNS cache defaults applied to zero-valued DNSCacheConfig fields.
+const (
+	DefaultDNSMaxTTL      = 5 * time.Minute
+	DefaultDNSNegativeTTL = 5 * time.Second
+	DefaultDNSFallbackTTL = 30 * time.Second
+)
+
+// dnsLookupTimeout bounds a query shared by concurrent lookups.
+const dnsLookupTimeout = 15 * time.Second
+
+// errNoAddresses is cached for names without A or AAAA records.
+var errNoAddresses = errors.New("no addresses")
+
+// DNSCacheConfig configures a client's caching resolver.
+type DNSCacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Record TTLs are clamped to [MinTTL, MaxTTL] (default 0 and 5m)
+	MinTTL time.Duration `yaml:"min_ttl" validate:"min=0"`
+	MaxTTL time.Duration `yaml:"max_ttl" validate:"min=0"`
+
+	// NegativeTTL caches failed lookups (default 5s)
+	NegativeTTL time.Duration `yaml:"negative_ttl" validate:"min=0"`
+
+	// Servers are nameserver host:port addresses; empty reads /etc/resolv.conf
+	Servers []string `yaml:"servers" validate:"dive,hostname_port"`
+}
+
+// Resolver is a caching DNS resolver honoring record TTLs. Names are
+// queried directly against the nameservers to learn TTLs; names they do
+// not resolve (hosts file entries, search domains) fall back to the system
+// resolver and are cached for DefaultDNSFallbackTTL.
+type Resolver struct {
+	cfg     DNSCacheConfig
+	servers []string
+	lookup  func(ctx context.Context, host string) ([]net.IP, time.Duration, error)
+	system  *net.Resolver
+	group   singleflight.Group
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	ips       []net.IP
+	err       error
+	expiresAt time.Time
+}
+
+// NewResolver creates a caching resolver.
+func NewResolver(cfg DNSCacheConfig) *Resolver {
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = DefaultDNSMaxTTL
+	}
+	if cfg.NegativeTTL <= 0 {
+		cfg.NegativeTTL = DefaultDNSNegativeTTL
+	}
+	servers := cfg.Servers
+	if len(servers) == 0 {
+		servers = systemNameservers("/etc/resolv.conf")
+	}
+
+	r := &Resolver{
+		cfg:     cfg,
+		servers: servers,
+		system:  net.DefaultResolver,
+		now:     time.Now,
+		entries: make(map[string]dnsEntry),
+	}
+	r.lookup = r.resolve
+	return r
+}
+
+// LookupIP returns the addresses of host, from cache while the answer's
+// TTL lasts. Concurrent lookups of the same name share one query.
+func (r *Resolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	r.mu.Lock()
+	entry, ok := r.entries[host]
+	r.mu.Unlock()
+	if ok && r.now().Before(entry.expiresAt) {
+		return entry.ips, entry.err
+	}
+
+	// The shared query outlives the caller that started it, bounded by
+	// dnsLookupTimeout, so canceling one caller does not fail the others
+	results := r.group.DoChan(host, func() (any, error) {
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dnsLookupTimeout)
+		defer cancel()
+		ips, ttl, err := r.lookup(lookupCtx, host)
+
+		entry := dnsEntry{ips: ips, err: err}
+		if err != nil {
+			entry.expiresAt = r.now().Add(r.cfg.NegativeTTL)
+		} else {
+			entry.expiresAt = r.now().Add(min(max(ttl, r.cfg.MinTTL), r.cfg.MaxTTL))
+		}
+		r.mu.Lock()
+		r.entries[host] = entry
+		r.mu.Unlock()
+		return ips, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-results:
+		if res.Err != nil {
+			return nil, &net.DNSError{Err: res.Err.Error(), Name: host, IsNotFound: errors.Is(res.Err, errNoAddresses)}
+		}
+		ips, _ := res.Val.([]net.IP)
+		return ips, nil
+	}
+}
+
+// DialContext returns a dial function resolving names through the cache
+// and trying each address in turn.
+func (r *Resolver) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		ips, err := r.LookupIP(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastErr error
+		for _, ip := range ips {
+			if (network == "tcp4" && ip.To4() == nil) || (network == "tcp6" && ip.To4() != nil) {
+				continue
+			}
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if lastErr == nil {
+			lastErr = &net.AddrError{Err: "no suitable address", Addr: host}
+		}
+		return nil, lastErr
+	}
+}
+
+// resolve queries the nameservers for A and AAAA records, falling back to
+// the system resolver.
+func (r *Resolver) resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	for _, server := range r.servers {
+		ips, ttl, err := queryAddresses(ctx, server, host)
+		if err == nil && len(ips) > 0 {
+			return ips, ttl, nil
+		}
+	}
+
+	addrs, err := r.system.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(addrs) == 0 {
+		return nil, 0, errNoAddresses
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+	return ips, DefaultDNSFallbackTTL, nil
+}
+
+// queryAddresses returns the A and AAAA records of host from server (IPv4
+// first) and the lowest record TTL.
+func queryAddresses(ctx context.Context, server, host string) ([]net.IP, time.Duration, error) {
+	name, err := dnsmessage.NewName(host + ".")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		ips []net.IP
+		ttl = time.Duration(-1)
+	)
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		msg, err := exchange(ctx, server, dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET})
+		if err != nil {
+			return nil, 0, err
+		}
+		if msg.RCode == dnsmessage.RCodeNameError {
+			return nil, 0, errNoAddresses
+		}
+		if msg.RCode != dnsmessage.RCodeSuccess {
+			return nil, 0, fmt.Errorf("dns query %s: %s", host, msg.RCode)
+		}
+
+		for _, answer := range msg.Answers {
+			var ip net.IP
+			switch body := answer.Body.(type) {
+			case *dnsmessage.AResource:
+				ip = net.IP(body.A[:])
+			case *dnsmessage.AAAAResource:
+				ip = net.IP(body.AAAA[:])
+			default:
+				continue
+			}
+			ips = append(ips, ip)
+			if recordTTL := time.Duration(answer.Header.TTL) * time.Second; ttl < 0 || recordTTL < ttl {
+				ttl = recordTTL
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return nil, 0, errNoAddresses
+	}
+	return ips, ttl, nil
+}
+
+// exchange sends a recursive query over UDP, retrying over TCP when the
+// answer is truncated.
+func exchange(ctx context.Context, server string, question dnsmessage.Question) (*dnsmessage.Message, error) {
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32()), RecursionDesired: true},
+		Questions: []dnsmessage.Question{question},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+	}
+
+	for _, network := range []string{"udp", "tcp"} {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, network, server)
+		if err != nil {
+			return nil, err
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+
+		reply, err := roundTrip(conn, network, packet)
+		_ = conn.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var msg dnsmessage.Message
+		if err := msg.Unpack(reply); err != nil {
+			return nil, err
+		}
+		if msg.ID != query.ID {
+			return nil, errors.New("dns reply id mismatch")
+		}
+		if !msg.Truncated {
+			return &msg, nil
+		}
+	}
+	return nil, errors.New("dns reply truncated")
+}
+
+// roundTrip writes a DNS packet and reads the reply, using length prefixes
+// on stream connections.
+func roundTrip(conn net.Conn, network string, packet []byte) ([]byte, error) {
+	if network == "udp" {
+		if _, err := conn.Write(packet); err != nil {
+			return nil, err
+		}
+		reply := make([]byte, 1232)
+		n, err := conn.Read(reply)
+		return reply[:n], err
+	}
+
+	framed := binary.BigEndian.AppendUint16(nil, uint16(len(packet)))
+	if _, err := conn.Write(append(framed, packet...)); err != nil {
+		return nil, err
+	}
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, binary.BigEndian.Uint16(size[:]))
+	_, err := io.ReadFull(conn, reply)
+	return reply, err
+}
+
+// systemNameservers reads nameserver addresses from a resolv.conf file.
+func systemNameservers(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, net.JoinHostPort(fields[1], "53"))
+		}
+	}
+	return servers
+}
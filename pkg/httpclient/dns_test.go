@@ -0,0 +1,195 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestResolver_CachesForRecordTTL(t *testing.T) {
+	server := newFakeDNS(t, map[string]string{"api.example.com.": "127.0.0.1"}, 60)
+	r := newTestResolver(server, DNSCacheConfig{Enabled: true})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ips, err := r.LookupIP(context.Background(), "API.example.com")
+		require.NoError(t, err)
+		require.Len(t, ips, 1)
+		assert.Equal(t, "127.0.0.1", ips[0].String())
+	}
+	assert.Equal(t, int32(2), server.queries.Load(), "one A and one AAAA query")
+
+	now = now.Add(61 * time.Second)
+	_, err := r.LookupIP(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), server.queries.Load(), "expired answers are queried again")
+}
+
+func TestResolver_ClampsTTL(t *testing.T) {
+	server := newFakeDNS(t, map[string]string{"api.example.com.": "127.0.0.1"}, 3600)
+	r := newTestResolver(server, DNSCacheConfig{Enabled: true, MaxTTL: time.Minute})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	_, err := r.LookupIP(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = r.LookupIP(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), server.queries.Load())
+}
+
+func TestResolver_CachesFailures(t *testing.T) {
+	server := newFakeDNS(t, nil, 60)
+	r := newTestResolver(server, DNSCacheConfig{Enabled: true, NegativeTTL: time.Minute})
+
+	_, err := r.LookupIP(context.Background(), "missing.example.com")
+	var dnsErr *net.DNSError
+	require.ErrorAs(t, err, &dnsErr)
+	queries := server.queries.Load()
+
+	_, err = r.LookupIP(context.Background(), "missing.example.com")
+	assert.Error(t, err)
+	assert.Equal(t, queries, server.queries.Load(), "failures are served from cache")
+}
+
+func TestResolver_SharedLookupSurvivesCanceledCaller(t *testing.T) {
+	r := NewResolver(DNSCacheConfig{Enabled: true})
+	release := make(chan struct{})
+	var lookups atomic.Int32
+	r.lookup = func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		lookups.Add(1)
+		select {
+		case <-release:
+			return []net.IP{net.ParseIP("127.0.0.1")}, time.Minute, nil
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := r.LookupIP(ctx, "api.example.com")
+		canceled <- err
+	}()
+	require.Eventually(t, func() bool { return lookups.Load() == 1 }, time.Second, time.Millisecond)
+
+	shared := make(chan []net.IP, 1)
+	go func() {
+		ips, _ := r.LookupIP(context.Background(), "api.example.com")
+		shared <- ips
+	}()
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+
+	close(release)
+	ips := <-shared
+	require.Len(t, ips, 1, "the other caller still gets the answer")
+	assert.Equal(t, "127.0.0.1", ips[0].String())
+	assert.Equal(t, int32(1), lookups.Load())
+}
+
+func TestResolver_DialsResolvedAddress(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+	_, port, err := net.SplitHostPort(upstream.Listener.Addr().String())
+	require.NoError(t, err)
+
+	server := newFakeDNS(t, map[string]string{"upstream.internal.": "127.0.0.1"}, 60)
+	r := newTestResolver(server, DNSCacheConfig{Enabled: true})
+	client := &http.Client{Transport: &http.Transport{DialContext: r.DialContext(&net.Dialer{})}}
+
+	resp, err := client.Get("http://upstream.internal:" + port)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestSystemNameservers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolv.conf")
+	require.NoError(t, os.WriteFile(path, []byte("search svc.local\nnameserver 10.0.0.2\nnameserver ::1\n"), 0o600))
+
+	assert.Equal(t, []string{"10.0.0.2:53", "[::1]:53"}, systemNameservers(path))
+	assert.Empty(t, systemNameservers(filepath.Join(t.TempDir(), "missing")))
+}
+
+// newTestResolver creates a resolver whose queries and system fallback both
+// go to server.
+func newTestResolver(server *fakeDNS, cfg DNSCacheConfig) *Resolver {
+	cfg.Servers = []string{server.addr}
+	r := NewResolver(cfg)
+	r.system = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", server.addr)
+		},
+	}
+	return r
+}
+
+// fakeDNS answers A queries for fixed names over UDP.
+type fakeDNS struct {
+	addr    string
+	queries atomic.Int32
+}
+
+func newFakeDNS(t *testing.T, records map[string]string, ttl uint32) *fakeDNS {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	server := &fakeDNS{addr: conn.LocalAddr().String()}
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			server.queries.Add(1)
+
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			question := query.Questions[0]
+			reply := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+				Questions: query.Questions,
+			}
+			ip, ok := records[question.Name.String()]
+			switch {
+			case !ok:
+				reply.RCode = dnsmessage.RCodeNameError
+			case question.Type == dnsmessage.TypeA:
+				var a [4]byte
+				copy(a[:], net.ParseIP(ip).To4())
+				reply.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+					Body:   &dnsmessage.AResource{A: a},
+				}}
+			}
+			packet, err := reply.Pack()
+			if err == nil {
+				_, _ = conn.WriteTo(packet, from)
+			}
+		}
+	}()
+	return server
+}
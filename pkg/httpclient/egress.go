@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// ErrEgressDenied matches every error returned for requests the egress
+// policy blocks.
+var ErrEgressDenied = errors.New("egress denied")
+
+// Reasons reported by EgressError.
+const (
+	ReasonScheme  = "scheme_not_allowed"
+	ReasonHost    = "host_not_allowed"
+	ReasonPort    = "port_not_allowed"
+	ReasonAddress = "address_blocked"
+)
+
+// metadataIPs are cloud instance metadata endpoints outside link-local ranges.
+var metadataIPs = []net.IP{
+	net.ParseIP("100.100.100.200"), // Alibaba Cloud
+	net.ParseIP("fd00:ec2::254"),   // AWS IPv6
+}
+
+// EgressError describes a blocked request.
+type EgressError struct {
+	Host   string
+	Reason string
+}
+
+// Error implements error.
+func (e *EgressError) Error() string {
+	return fmt.Sprintf("egress denied to %s: %s", e.Host, strings.ReplaceAll(e.Reason, "_", " "))
+}
+
+// Unwrap makes errors.Is(err, ErrEgressDenied) match.
+func (e *EgressError) Unwrap() error {
+	return ErrEgressDenied
+}
+
+// EgressPolicy restricts the destinations a client may reach. Empty
+// allowlists allow any value. Address checks apply to the resolved IP at
+// dial time, so names resolving to blocked addresses (including DNS
+// rebinding) are refused too.
+type EgressPolicy struct {
+	// AllowHosts lists host names; "*.example.com" matches subdomains
+	AllowHosts   []string `yaml:"allow_hosts" validate:"dive,required"`
+	AllowPorts   []int    `yaml:"allow_ports" validate:"dive,min=1,max=65535"`
+	AllowSchemes []string `yaml:"allow_schemes" validate:"dive,oneof=http https"`
+
+	// BlockLinkLocal refuses link-local and cloud metadata addresses
+	// (169.254.0.0/16, fe80::/10, 100.100.100.200, fd00:ec2::254)
+	BlockLinkLocal bool `yaml:"block_link_local"`
+
+	// BlockPrivate refuses loopback, private and unspecified addresses, for
+	// clients calling user-supplied URLs (webhooks, URL previews)
+	BlockPrivate bool `yaml:"block_private"`
+}
+
+// enabled reports whether the policy restricts anything.
+func (p EgressPolicy) enabled() bool {
+	return len(p.AllowHosts) > 0 || len(p.AllowPorts) > 0 || len(p.AllowSchemes) > 0 ||
+		p.BlockLinkLocal || p.BlockPrivate
+}
+
+// CheckURL reports whether u may be requested, without resolving its host.
+// Use it to validate user-supplied URLs before accepting them; literal IP
+// hosts are checked against the address rules.
+func (p EgressPolicy) CheckURL(u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	scheme := strings.ToLower(u.Scheme)
+
+	if len(p.AllowSchemes) > 0 && !slices.Contains(p.AllowSchemes, scheme) {
+		return &EgressError{Host: host, Reason: ReasonScheme}
+	}
+	if len(p.AllowHosts) > 0 && !slices.ContainsFunc(p.AllowHosts, func(pattern string) bool { return matchHost(pattern, host) }) {
+		return &EgressError{Host: host, Reason: ReasonHost}
+	}
+	if len(p.AllowPorts) > 0 && !slices.Contains(p.AllowPorts, urlPort(u)) {
+		return &EgressError{Host: host, Reason: ReasonPort}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return p.CheckIP(ip)
+	}
+	return nil
+}
+
+// CheckIP reports whether ip may be dialed.
+func (p EgressPolicy) CheckIP(ip net.IP) error {
+	blocked := p.BlockLinkLocal && (ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		slices.ContainsFunc(metadataIPs, ip.Equal))
+	blocked = blocked || p.BlockPrivate && (ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified())
+	if blocked {
+		return &EgressError{Host: ip.String(), Reason: ReasonAddress}
+	}
+	return nil
+}
+
+// control is a net.Dialer Control hook applying the address rules.
+func (p EgressPolicy) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return &EgressError{Host: host, Reason: ReasonAddress}
+	}
+	return p.CheckIP(ip)
+}
+
+// matchHost reports whether host matches an allowlist pattern.
+func matchHost(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// urlPort returns the explicit or scheme default port of u.
+func urlPort(u *url.URL) int {
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		return port
+	}
+	if strings.EqualFold(u.Scheme, "https") {
+		return 443
+	}
+	return 80
+}
+
+// EgressTransport enforces URL rules and audits blocked requests; address
+// rules are enforced by the dialer.
+type EgressTransport struct {
+	Base    http.RoundTripper
+	client  string
+	policy  EgressPolicy
+	metrics *Metrics
+	log     *logger.Logger
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *EgressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.CheckURL(req.URL); err != nil {
+		t.audit(req, err)
+		return nil, err
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if errors.Is(err, ErrEgressDenied) {
+		t.audit(req, err)
+	}
+	return resp, err
+}
+
+// CloseIdleConnections forwards to Base so http.Client.CloseIdleConnections
+// keeps working.
+func (t *EgressTransport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// audit logs and counts a blocked request.
+func (t *EgressTransport) audit(req *http.Request, err error) {
+	reason := ReasonAddress
+	var egressErr *EgressError
+	if errors.As(err, &egressErr) {
+		reason = egressErr.Reason
+	}
+	t.metrics.ObserveBlocked(req.URL.Host, reason)
+	if t.log != nil {
+		t.log.Warnw("egress_blocked",
+			"client", t.client,
+			"method", req.Method,
+			"host", req.URL.Host,
+			"reason", reason,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressPolicy_CheckURL(t *testing.T) {
+	policy := EgressPolicy{
+		AllowHosts:     []string{"api.example.com", "*.internal.example.com"},
+		AllowPorts:     []int{443, 8443},
+		AllowSchemes:   []string{"https"},
+		BlockLinkLocal: true,
+	}
+
+	tests := []struct {
+		url    string
+		reason string
+	}{
+		{"https://api.example.com/v1", ""},
+		{"https://API.example.com:8443/v1", ""},
+		{"https://billing.internal.example.com", ""},
+		{"http://api.example.com", ReasonScheme},
+		{"https://internal.example.com", ReasonHost},
+		{"https://evil.com/?api.example.com", ReasonHost},
+		{"https://api.example.com:8080", ReasonPort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := policy.CheckURL(mustParseURL(t, tt.url))
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var egressErr *EgressError
+			require.ErrorAs(t, err, &egressErr)
+			assert.Equal(t, tt.reason, egressErr.Reason)
+			assert.ErrorIs(t, err, ErrEgressDenied)
+		})
+	}
+}
+
+func TestEgressPolicy_CheckIP(t *testing.T) {
+	linkLocal := EgressPolicy{BlockLinkLocal: true}
+	assert.Error(t, linkLocal.CheckIP(net.ParseIP("169.254.169.254")))
+	assert.Error(t, linkLocal.CheckIP(net.ParseIP("::ffff:169.254.169.254")))
+	assert.Error(t, linkLocal.CheckIP(net.ParseIP("fe80::1")))
+	assert.Error(t, linkLocal.CheckIP(net.ParseIP("fd00:ec2::254")))
+	assert.Error(t, linkLocal.CheckIP(net.ParseIP("100.100.100.200")))
+	assert.NoError(t, linkLocal.CheckIP(net.ParseIP("10.0.0.1")))
+	assert.NoError(t, linkLocal.CheckIP(net.ParseIP("127.0.0.1")))
+
+	private := EgressPolicy{BlockPrivate: true}
+	for _, ip := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "fd12::1", "0.0.0.0"} {
+		assert.Error(t, private.CheckIP(net.ParseIP(ip)), ip)
+	}
+	assert.NoError(t, private.CheckIP(net.ParseIP("93.184.216.34")))
+}
+
+func TestRegistry_BlocksAndAuditsEgress(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	var logs bytes.Buffer
+	log, err := logger.New(logger.Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Writer: &logs, Format: "json"}},
+	})
+	require.NoError(t, err)
+
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+	registry := NewRegistry(Config{}, []Config{
+		{Name: "webhooks", Egress: &EgressPolicy{BlockPrivate: true}},
+	}, Options{Metrics: metrics, Logger: log})
+
+	_, err = registry.Client("webhooks").Get(upstream.URL)
+	require.ErrorIs(t, err, ErrEgressDenied, "literal loopback address is refused")
+
+	_, err = registry.Client("webhooks").Get("http://localhost:" + mustParseURL(t, upstream.URL).Port())
+	require.ErrorIs(t, err, ErrEgressDenied, "names resolving to loopback are refused at dial time")
+
+	resp, err := registry.Default().Get(upstream.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, 2, bytes.Count(logs.Bytes(), []byte(`"msg":"egress_blocked"`)))
+	assert.Contains(t, logs.String(), `"client":"webhooks"`)
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "http_client_egress_blocked_total"))
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
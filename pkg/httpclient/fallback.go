@@ -0,0 +1,263 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFallbackCachedTTL is how long successful responses are kept for
+// the cached fallback when FallbackConfig.CachedTTL is zero.
+const DefaultFallbackCachedTTL = time.Hour
+
+// FallbackHeader names the strategy that produced a fallback response.
+const FallbackHeader = "X-Fallback"
+
+// Fallback strategies, tried in this order.
+const (
+	FallbackCached   = "cached"
+	FallbackUpstream = "upstream"
+	FallbackStatic   = "static"
+)
+
+// fallbackExhausted labels failures no strategy could answer.
+const fallbackExhausted = "exhausted"
+
+// fallbackKeyPrefix namespaces last-known-good responses in shared stores.
+const fallbackKeyPrefix = "httpfallback:"
+
+// defaultFallbackStatuses trigger fallbacks besides transport errors.
+var defaultFallbackStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// FallbackConfig declares how a client degrades when its upstream fails
+// with a transport error or one of Statuses. Strategies are tried in
+// order: the last successful response, the alternate upstream, then the
+// static response.
+type FallbackConfig struct {
+	// Cached serves the last successful GET response of the URL, kept in
+	// Options.CacheStore for CachedTTL (default 1h)
+	Cached    bool          `yaml:"cached"`
+	CachedTTL time.Duration `yaml:"cached_ttl" validate:"min=0"`
+
+	// Upstream re-sends the request to this base URL
+	Upstream string `yaml:"upstream" validate:"omitempty,url"`
+
+	// Static is the default response
+	Static *StaticResponse `yaml:"static"`
+
+	// Statuses trigger the fallback (default 502, 503, 504)
+	Statuses []int `yaml:"statuses" validate:"dive,min=400,max=599"`
+}
+
+// enabled reports whether any strategy is declared.
+func (c FallbackConfig) enabled() bool {
+	return c.Cached || c.Upstream != "" || c.Static != nil
+}
+
+// StaticResponse is a fixed fallback response.
+type StaticResponse struct {
+	Status      int    `yaml:"status" validate:"omitempty,min=200,max=599"`
+	ContentType string `yaml:"content_type"`
+	Body        string `yaml:"body"`
+}
+
+// FallbackTransport answers failed requests with the client's fallback
+// strategies.
+type FallbackTransport struct {
+	Base     http.RoundTripper
+	direct   http.RoundTripper // Sends to the alternate upstream
+	client   string
+	cfg      FallbackConfig
+	upstream *url.URL
+	store    ResponseStore
+	metrics  *Metrics
+}
+
+// newFallbackTransport wraps base with the fallbacks of cfg, sending
+// alternate upstream requests through direct; it returns base when none
+// apply.
+func newFallbackTransport(base, direct http.RoundTripper, client string, cfg FallbackConfig, opts Options) http.RoundTripper {
+	if !cfg.enabled() {
+		return base
+	}
+	if cfg.CachedTTL <= 0 {
+		cfg.CachedTTL = DefaultFallbackCachedTTL
+	}
+	if len(cfg.Statuses) == 0 {
+		cfg.Statuses = defaultFallbackStatuses
+	}
+	t := &FallbackTransport{Base: base, direct: direct, client: client, cfg: cfg, store: opts.CacheStore, metrics: opts.Metrics}
+	if cfg.Upstream != "" {
+		t.upstream, _ = url.Parse(cfg.Upstream)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Base.RoundTrip(req)
+	if err == nil && !slices.Contains(t.cfg.Statuses, resp.StatusCode) {
+		if t.cfg.Cached && req.Method == http.MethodGet && resp.StatusCode < 300 {
+			resp = t.remember(req, resp)
+		}
+		return resp, nil
+	}
+	// The caller canceled; there is nobody to degrade for
+	if err := req.Context().Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return resp, err
+	}
+
+	fallback, strategy := t.degrade(req)
+	if fallback == nil {
+		t.metrics.ObserveFallback(t.client, fallbackExhausted)
+		return resp, err
+	}
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	t.metrics.ObserveFallback(t.client, strategy)
+	fallback.Header.Set(FallbackHeader, strategy)
+	return fallback, nil
+}
+
+// CloseIdleConnections forwards to Base so http.Client.CloseIdleConnections
+// keeps working.
+func (t *FallbackTransport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// degrade returns the first fallback response and its strategy, or nil.
+func (t *FallbackTransport) degrade(req *http.Request) (*http.Response, string) {
+	// Fallbacks may run after the request deadline expired
+	ctx := req.Context()
+	if ctx.Err() != nil {
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	if t.cfg.Cached && req.Method == http.MethodGet && t.store != nil {
+		if resp := t.recall(ctx, req); resp != nil {
+			return resp, FallbackCached
+		}
+	}
+	if t.upstream != nil {
+		if resp := t.alternate(ctx, req); resp != nil {
+			return resp, FallbackUpstream
+		}
+	}
+	if t.cfg.Static != nil {
+		return staticResponse(req, *t.cfg.Static), FallbackStatic
+	}
+	return nil, ""
+}
+
+// remember stores a successful response as the last known good one.
+func (t *FallbackTransport) remember(req *http.Request, resp *http.Response) *http.Response {
+	if t.store == nil {
+		return resp
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, DefaultCacheMaxBodyBytes+1))
+	if err != nil || len(body) > DefaultCacheMaxBodyBytes {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data, err := json.Marshal(cachedResponse{Status: resp.StatusCode, Header: resp.Header, Body: body, StoredAt: time.Now()})
+	if err == nil {
+		_ = t.store.Set(req.Context(), fallbackKey(req), data, t.cfg.CachedTTL)
+	}
+	return resp
+}
+
+// recall returns the last known good response of req, or nil.
+func (t *FallbackTransport) recall(ctx context.Context, req *http.Request) *http.Response {
+	data, ok, err := t.store.Get(ctx, fallbackKey(req))
+	if err != nil || !ok {
+		return nil
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	resp := newResponse(req, entry.Status, entry.Header.Clone(), entry.Body)
+	resp.Header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+	return resp
+}
+
+// alternate re-sends req to the alternate upstream, returning nil when it
+// fails too or the body cannot be replayed.
+func (t *FallbackTransport) alternate(ctx context.Context, req *http.Request) *http.Response {
+	out := req.Clone(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		out.Body = body
+	}
+	out.URL.Scheme = t.upstream.Scheme
+	out.URL.Host = t.upstream.Host
+	out.URL.Path = strings.TrimRight(t.upstream.Path, "/") + req.URL.Path
+	out.URL.RawPath = ""
+	out.Host = ""
+
+	resp, err := t.direct.RoundTrip(out)
+	if err != nil {
+		return nil
+	}
+	if slices.Contains(t.cfg.Statuses, resp.StatusCode) {
+		_ = resp.Body.Close()
+		return nil
+	}
+	return resp
+}
+
+// staticResponse builds the static fallback of req.
+func staticResponse(req *http.Request, static StaticResponse) *http.Response {
+	status := static.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := http.Header{}
+	if static.ContentType != "" {
+		header.Set("Content-Type", static.ContentType)
+	}
+	return newResponse(req, status, header, []byte(static.Body))
+}
+
+// newResponse builds an in-memory response to req.
+func newResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// fallbackKey identifies the last known good response of req.
+func fallbackKey(req *http.Request) string {
+	return fallbackKeyPrefix + strings.TrimPrefix(cacheKey(req), cacheKeyPrefix)
+}
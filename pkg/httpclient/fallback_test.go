@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallback_ServesLastGoodResponse(t *testing.T) {
+	var failing atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("rates v1"))
+	}))
+	defer upstream.Close()
+
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+	registry := NewRegistry(Config{}, []Config{
+		{Name: "rates", Fallback: &FallbackConfig{Cached: true}},
+	}, Options{Metrics: metrics, CacheStore: &mapStore{entries: map[string][]byte{}}})
+	client := registry.Client("rates")
+
+	resp := get(t, client, upstream.URL+"/eur", nil)
+	assert.Equal(t, "rates v1", readBody(t, resp))
+	assert.Empty(t, resp.Header.Get(FallbackHeader))
+
+	failing.Store(true)
+	resp = get(t, client, upstream.URL+"/eur", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, FallbackCached, resp.Header.Get(FallbackHeader))
+	assert.Equal(t, "rates v1", readBody(t, resp))
+
+	resp = get(t, client, upstream.URL+"/usd", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "nothing cached and no other strategy")
+	_ = resp.Body.Close()
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.fallback.WithLabelValues("rates", FallbackCached)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.fallback.WithLabelValues("rates", fallbackExhausted)))
+}
+
+func TestFallback_AlternateUpstreamThenStatic(t *testing.T) {
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mirror/down" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("secondary " + r.URL.Path))
+	}))
+	defer secondary.Close()
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	primaryURL := primary.URL
+	primary.Close()
+
+	registry := NewRegistry(Config{Timeout: time.Second}, []Config{{
+		Name: "search",
+		Fallback: &FallbackConfig{
+			Upstream: secondary.URL + "/mirror",
+			Static:   &StaticResponse{Status: http.StatusOK, ContentType: "application/json", Body: `{"results":[]}`},
+		},
+	}}, Options{})
+	client := registry.Client("search")
+
+	resp := get(t, client, primaryURL+"/query", nil)
+	assert.Equal(t, FallbackUpstream, resp.Header.Get(FallbackHeader))
+	assert.Equal(t, "secondary /mirror/query", readBody(t, resp))
+
+	resp = get(t, client, primaryURL+"/down", nil)
+	assert.Equal(t, FallbackStatic, resp.Header.Get(FallbackHeader))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"results":[]}`, readBody(t, resp))
+}
+
+func TestRegistry_FallbackWrapsCallerTransport(t *testing.T) {
+	registry := NewRegistry(Config{}, []Config{
+		{Name: "search", Fallback: &FallbackConfig{Static: &StaticResponse{Body: "empty"}}},
+	}, Options{})
+
+	failing := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, assert.AnError
+	})
+	rt := registry.Fallback("search", failing)
+	req, err := http.NewRequest(http.MethodGet, "http://search.internal/q", nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "empty", readBody(t, resp))
+
+	_, err = registry.Fallback("unknown", failing).RoundTrip(req)
+	assert.ErrorIs(t, err, assert.AnError, "clients without fallback return next")
+	assert.IsType(t, &FallbackTransport{}, registry.Client("search").Transport)
+	assert.NotSame(t, registry.Client("search").Transport, registry.Upstream("search"))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
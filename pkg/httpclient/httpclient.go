@@ -0,0 +1,247 @@
+// Package httpclient instruments outbound HTTP traffic with per-host
+// Prometheus metrics.
+//
+// Transport records request counts and durations, and uses httptrace to
+// time DNS lookups, TCP connects and TLS handshakes of new connections.
+// Components that retry or guard upstreams with a circuit breaker report
+// through ObserveRetry and SetBreakerState so all outbound metrics share
+// the host label:
+//
+//	metrics := httpclient.NewMetrics(registry)
+//	client := &http.Client{Transport: metrics.Transport(nil)}
+//
+// The clients of a Registry also track their connection pools per host:
+// idle, active and waiting connections and dials are exported with the
+// other metrics, and a PoolReporter warns about exhausted pools.
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/breaker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatusError is the status label of requests that failed without a response.
+const StatusError = "error"
+
+// Metrics holds the outbound HTTP collectors. A nil *Metrics records nothing.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	dns      *prometheus.HistogramVec
+	connect  *prometheus.HistogramVec
+	tls      *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+	breakers *prometheus.GaugeVec
+	blocked  *prometheus.CounterVec
+	cache    *prometheus.CounterVec
+	fallback *prometheus.CounterVec
+	connWait *prometheus.HistogramVec
+	pools    *poolCollector
+}
+
+// NewMetrics creates the outbound collectors and registers them.
+// It panics if the collectors are already registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	phase := func(name, help string) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name,
+			Help:    help,
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"host"})
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_client_requests_total",
+			Help: "Outbound HTTP requests by host, method and status code.",
+		}, []string{"host", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_client_request_duration_seconds",
+			Help:    "Duration of outbound HTTP requests until response headers.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"host", "method"}),
+		dns:     phase("http_client_dns_duration_seconds", "DNS lookup duration of new outbound connections."),
+		connect: phase("http_client_connect_duration_seconds", "TCP connect duration of new outbound connections."),
+		tls:     phase("http_client_tls_duration_seconds", "TLS handshake duration of new outbound connections."),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_client_retries_total",
+			Help: "Outbound HTTP request retries by host.",
+		}, []string{"host"}),
+		breakers: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_client_breaker_state",
+			Help: "Circuit breaker state by host (0 closed, 1 open, 2 half-open).",
+		}, []string{"host"}),
+		blocked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_client_egress_blocked_total",
+			Help: "Outbound HTTP requests refused by the egress policy by host and reason.",
+		}, []string{"host", "reason"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_client_cache_requests_total",
+			Help: "Outbound GET requests seen by the response cache by host and result (HIT, REVALIDATED, MISS).",
+		}, []string{"host", "result"}),
+		fallback: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_client_fallbacks_total",
+			Help: "Failed outbound requests by client and fallback strategy that answered them (exhausted when none did).",
+		}, []string{"client", "strategy"}),
+		connWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_client_connection_wait_seconds",
+			Help:    "Time outbound requests waited for a pooled or new connection by client.",
+			Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+		}, []string{"client"}),
+		pools: newPoolCollector(),
+	}
+	reg.MustRegister(m.requests, m.duration, m.dns, m.connect, m.tls, m.retries, m.breakers, m.blocked, m.cache, m.fallback,
+		m.connWait, m.pools)
+	return m
+}
+
+// ObserveRetry counts a retried request to host.
+func (m *Metrics) ObserveRetry(host string) {
+	if m == nil {
+		return
+	}
+	m.retries.WithLabelValues(host).Inc()
+}
+
+// SetBreakerState records the circuit breaker state guarding host.
+func (m *Metrics) SetBreakerState(host string, state breaker.State) {
+	if m == nil {
+		return
+	}
+	m.breakers.WithLabelValues(host).Set(float64(state))
+}
+
+// ObserveBlocked counts a request to host refused by the egress policy.
+func (m *Metrics) ObserveBlocked(host, reason string) {
+	if m == nil {
+		return
+	}
+	m.blocked.WithLabelValues(host, reason).Inc()
+}
+
+// ObserveCache counts a GET request to host by response cache result.
+func (m *Metrics) ObserveCache(host, result string) {
+	if m == nil {
+		return
+	}
+	m.cache.WithLabelValues(host, result).Inc()
+}
+
+// ObserveFallback counts a failed request of client by fallback strategy.
+func (m *Metrics) ObserveFallback(client, strategy string) {
+	if m == nil {
+		return
+	}
+	m.fallback.WithLabelValues(client, strategy).Inc()
+}
+
+// observeConnWait records how long a request of client waited for a
+// connection.
+func (m *Metrics) observeConnWait(client string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.connWait.WithLabelValues(client).Observe(d.Seconds())
+}
+
+// addPool exports the connection pool p.
+func (m *Metrics) addPool(p *pool) {
+	if m == nil {
+		return
+	}
+	m.pools.add(p)
+}
+
+// Transport wraps base (http.DefaultTransport when nil) to record metrics
+// of every request.
+func (m *Metrics) Transport(base http.RoundTripper) *RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RoundTripper{Base: base, metrics: m}
+}
+
+// RoundTripper records outbound metrics before delegating to Base.
+type RoundTripper struct {
+	Base    http.RoundTripper
+	metrics *Metrics
+	pool    *pool // Connection pool of registry clients, fed by request traces
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.pool != nil {
+		var done func()
+		req, done = t.pool.track(req)
+		defer done()
+	}
+	m := t.metrics
+	if m == nil {
+		return t.Base.RoundTrip(req)
+	}
+
+	host := req.URL.Host
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), m.clientTrace(host)))
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	m.duration.WithLabelValues(host, req.Method).Observe(time.Since(start).Seconds())
+
+	status := StatusError
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	m.requests.WithLabelValues(host, req.Method, status).Inc()
+	return resp, err
+}
+
+// CloseIdleConnections forwards to Base so http.Client.CloseIdleConnections
+// keeps working.
+func (t *RoundTripper) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// clientTrace times the connection phases of a request to host. Reused
+// connections report no phases. Dual-stack dialing may connect to several
+// addresses concurrently, so connect starts are tracked per address.
+func (m *Metrics) clientTrace(host string) *httptrace.ClientTrace {
+	var (
+		dnsStart, tlsStart time.Time
+		mu                 sync.Mutex
+		connectStart       = make(map[string]time.Time, 1)
+	)
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			m.dns.WithLabelValues(host).Observe(time.Since(dnsStart).Seconds())
+		},
+		ConnectStart: func(_, addr string) {
+			mu.Lock()
+			connectStart[addr] = time.Now()
+			mu.Unlock()
+		},
+		ConnectDone: func(_, addr string, err error) {
+			mu.Lock()
+			start := connectStart[addr]
+			mu.Unlock()
+			if err == nil {
+				m.connect.WithLabelValues(host).Observe(time.Since(start).Seconds())
+			}
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				m.tls.WithLabelValues(host).Observe(time.Since(tlsStart).Seconds())
+			}
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/luminosita/change-me/pkg/breaker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport_RecordsRequestsAndPhases(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+	host := mustHost(t, server.URL)
+
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+	client := &http.Client{Transport: metrics.Transport(server.Client().Transport)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues(host, http.MethodGet, "418")))
+	assert.Equal(t, 1, testutil.CollectAndCount(reg, "http_client_request_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(reg, "http_client_connect_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(reg, "http_client_tls_duration_seconds"))
+}
+
+func TestTransport_LabelsFailedRequests(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	host := mustHost(t, server.URL)
+	server.Close()
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	client := &http.Client{Transport: metrics.Transport(nil)}
+
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(host, http.MethodGet, StatusError)))
+}
+
+func TestMetrics_RetriesAndBreakerState(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	metrics.ObserveRetry("billing:8080")
+	metrics.ObserveRetry("billing:8080")
+	metrics.SetBreakerState("billing:8080", breaker.Open)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.retries.WithLabelValues("billing:8080")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.breakers.WithLabelValues("billing:8080")))
+
+	var disabled *Metrics
+	assert.NotPanics(t, func() {
+		disabled.ObserveRetry("billing:8080")
+		disabled.SetBreakerState("billing:8080", breaker.Open)
+	})
+}
+
+func mustHost(t *testing.T, raw string) string {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u.Host
+}
@@ -0,0 +1,386 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Connection states reported by HostStats and http_client_connections.
+const (
+	ConnIdle        = "idle"        // Pooled HTTP/1 connections
+	ConnActive      = "active"      // HTTP/1 connections carrying a request
+	ConnMultiplexed = "multiplexed" // HTTP/2 connections, shared by requests
+)
+
+// DefaultPoolReportInterval is the PoolReporter interval used when none is
+// configured.
+const DefaultPoolReportInterval = time.Minute
+
+// pool tracks the connections of a client's transport per host, fed by its
+// dialer and the httptrace hooks of its requests. HTTP/1 connections are
+// active from GotConn until returned to the idle pool; HTTP/2 connections
+// are reported as multiplexed once they carried a request.
+type pool struct {
+	client          string
+	maxConnsPerHost int
+	metrics         *Metrics
+
+	mu    sync.Mutex
+	hosts map[string]*hostPool
+}
+
+// hostPool holds the counts of one host.
+type hostPool struct {
+	idle, active, multiplexed, waiting int
+	dials, dialErrors                  uint64
+}
+
+// count returns the counter of connections in state.
+func (h *hostPool) count(state string) *int {
+	switch state {
+	case ConnActive:
+		return &h.active
+	case ConnMultiplexed:
+		return &h.multiplexed
+	default:
+		return &h.idle
+	}
+}
+
+// PoolStats is a snapshot of the connection pool of a client.
+type PoolStats struct {
+	Client          string
+	MaxConnsPerHost int         // Connection limit per host; 0 is unlimited
+	Hosts           []HostStats // Sorted by host
+}
+
+// HostStats is a snapshot of the connections to one host:port as dialed.
+type HostStats struct {
+	Host        string
+	Idle        int
+	Active      int
+	Multiplexed int
+	Waiting     int    // Requests waiting for a connection
+	Dials       uint64 // Connections opened
+	DialErrors  uint64 // Failed dials
+}
+
+// Open returns the number of open connections.
+func (s HostStats) Open() int {
+	return s.Idle + s.Active + s.Multiplexed
+}
+
+// Exhausted reports whether requests wait for a connection while the host
+// has none idle and reached maxConnsPerHost (0 is unlimited).
+func (s HostStats) Exhausted(maxConnsPerHost int) bool {
+	return maxConnsPerHost > 0 && s.Waiting > 0 && s.Idle == 0 && s.Open() >= maxConnsPerHost
+}
+
+// newPool creates the pool of the client of cfg, collected by metrics.
+func newPool(cfg Config, metrics *Metrics) *pool {
+	p := &pool{
+		client:          cfg.Name,
+		maxConnsPerHost: cfg.MaxConnsPerHost,
+		metrics:         metrics,
+		hosts:           map[string]*hostPool{},
+	}
+	metrics.addPool(p)
+	return p
+}
+
+// stats returns a snapshot of the pool.
+func (p *pool) stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := PoolStats{Client: p.client, MaxConnsPerHost: p.maxConnsPerHost, Hosts: make([]HostStats, 0, len(p.hosts))}
+	for host, h := range p.hosts {
+		stats.Hosts = append(stats.Hosts, HostStats{
+			Host:        host,
+			Idle:        h.idle,
+			Active:      h.active,
+			Multiplexed: h.multiplexed,
+			Waiting:     h.waiting,
+			Dials:       h.dials,
+			DialErrors:  h.dialErrors,
+		})
+	}
+	sort.Slice(stats.Hosts, func(i, j int) bool { return stats.Hosts[i].Host < stats.Hosts[j].Host })
+	return stats
+}
+
+// update applies fn to the counts of host under the lock.
+func (p *pool) update(host string, fn func(h *hostPool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h, ok := p.hosts[host]
+	if !ok {
+		h = &hostPool{}
+		p.hosts[host] = h
+	}
+	fn(h)
+}
+
+// dialContext wraps dial to count dials and track the state of the
+// connections it opens.
+func (p *pool) dialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			p.update(addr, func(h *hostPool) { h.dialErrors++ })
+			return nil, err
+		}
+		p.update(addr, func(h *hostPool) {
+			h.dials++
+			h.idle++
+		})
+		return &trackedConn{Conn: conn, pool: p, host: addr, state: ConnIdle}, nil
+	}
+}
+
+// track adds the hooks following the connection used by req; done must
+// be called once the round trip returned.
+func (p *pool) track(req *http.Request) (traced *http.Request, done func()) {
+	var (
+		mu        sync.Mutex
+		waiting   string // Host req waits for a connection to
+		waitStart time.Time
+		conn      *trackedConn
+	)
+	// stopWaiting returns when req started waiting, if it still was
+	stopWaiting := func() (time.Time, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if waiting == "" {
+			return time.Time{}, false
+		}
+		p.update(waiting, func(h *hostPool) { h.waiting-- })
+		waiting = ""
+		return waitStart, true
+	}
+
+	trace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if waiting != "" {
+				return
+			}
+			waiting, waitStart = hostPort, time.Now()
+			p.update(hostPort, func(h *hostPool) { h.waiting++ })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if start, ok := stopWaiting(); ok {
+				p.metrics.observeConnWait(p.client, time.Since(start))
+			}
+			tc := trackedOf(info.Conn)
+			if tc == nil {
+				return
+			}
+			state := ConnActive
+			if tlsConn, ok := info.Conn.(*tls.Conn); ok && tlsConn.ConnectionState().NegotiatedProtocol == "h2" {
+				state = ConnMultiplexed
+			}
+			tc.setState(state)
+			mu.Lock()
+			conn = tc
+			mu.Unlock()
+		},
+		// Not called for HTTP/2 connections
+		PutIdleConn: func(err error) {
+			mu.Lock()
+			tc := conn
+			mu.Unlock()
+			if err == nil && tc != nil {
+				tc.setState(ConnIdle)
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), func() { _, _ = stopWaiting() }
+}
+
+// trackedConn is a connection opened through pool.dialContext.
+type trackedConn struct {
+	net.Conn
+	pool *pool
+	host string
+
+	mu    sync.Mutex
+	state string // Empty once closed
+}
+
+// trackedOf returns the tracked connection behind conn, or nil.
+func trackedOf(conn net.Conn) *trackedConn {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tc, _ := conn.(*trackedConn)
+	return tc
+}
+
+// setState moves an open connection to state; multiplexed connections
+// stay multiplexed.
+func (c *trackedConn) setState(state string) {
+	c.mu.Lock()
+	old := c.state
+	if old == "" || old == state || old == ConnMultiplexed {
+		c.mu.Unlock()
+		return
+	}
+	c.state = state
+	c.mu.Unlock()
+	c.pool.update(c.host, func(h *hostPool) {
+		*h.count(old)--
+		*h.count(state)++
+	})
+}
+
+// Close implements net.Conn.
+func (c *trackedConn) Close() error {
+	c.mu.Lock()
+	old := c.state
+	c.state = ""
+	c.mu.Unlock()
+	if old != "" {
+		c.pool.update(c.host, func(h *hostPool) { *h.count(old)-- })
+	}
+	return c.Conn.Close()
+}
+
+// poolCollector exports the pools of the registry's clients.
+type poolCollector struct {
+	mu    sync.Mutex
+	pools []*pool
+
+	conns   *prometheus.Desc
+	waiting *prometheus.Desc
+	dials   *prometheus.Desc
+	limit   *prometheus.Desc
+}
+
+// newPoolCollector creates the pool metric descriptions.
+func newPoolCollector() *poolCollector {
+	return &poolCollector{
+		conns: prometheus.NewDesc("http_client_connections",
+			"Open outbound connections by client, host and state (idle, active, multiplexed).",
+			[]string{"client", "host", "state"}, nil),
+		waiting: prometheus.NewDesc("http_client_connections_waiting",
+			"Outbound requests waiting for a connection by client and host.",
+			[]string{"client", "host"}, nil),
+		dials: prometheus.NewDesc("http_client_dials_total",
+			"Outbound connection dials by client, host and result (success, error).",
+			[]string{"client", "host", "result"}, nil),
+		limit: prometheus.NewDesc("http_client_max_conns_per_host",
+			"Connection limit per host by client (0 is unlimited).",
+			[]string{"client"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.conns
+	ch <- c.waiting
+	ch <- c.dials
+	ch <- c.limit
+}
+
+// Collect implements prometheus.Collector.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	pools := append([]*pool(nil), c.pools...)
+	c.mu.Unlock()
+
+	for _, p := range pools {
+		stats := p.stats()
+		ch <- prometheus.MustNewConstMetric(c.limit, prometheus.GaugeValue, float64(stats.MaxConnsPerHost), stats.Client)
+		for _, h := range stats.Hosts {
+			ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(h.Idle), stats.Client, h.Host, ConnIdle)
+			ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(h.Active), stats.Client, h.Host, ConnActive)
+			ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(h.Multiplexed), stats.Client, h.Host, ConnMultiplexed)
+			ch <- prometheus.MustNewConstMetric(c.waiting, prometheus.GaugeValue, float64(h.Waiting), stats.Client, h.Host)
+			ch <- prometheus.MustNewConstMetric(c.dials, prometheus.CounterValue, float64(h.Dials), stats.Client, h.Host, "success")
+			ch <- prometheus.MustNewConstMetric(c.dials, prometheus.CounterValue, float64(h.DialErrors), stats.Client, h.Host, "error")
+		}
+	}
+}
+
+// add starts collecting p.
+func (c *poolCollector) add(p *pool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pools = append(c.pools, p)
+}
+
+// PoolReporter periodically logs the connection pools of a registry at
+// debug level and warns about exhausted hosts, whose requests queue for a
+// connection because they reached max_conns_per_host.
+type PoolReporter struct {
+	registry *Registry
+	interval time.Duration
+	log      *logger.Logger
+}
+
+// NewPoolReporter creates a reporter; a non-positive interval uses
+// DefaultPoolReportInterval.
+func NewPoolReporter(registry *Registry, interval time.Duration, log *logger.Logger) *PoolReporter {
+	if interval <= 0 {
+		interval = DefaultPoolReportInterval
+	}
+	return &PoolReporter{registry: registry, interval: interval, log: log}
+}
+
+// Run reports every interval until ctx is done. It always returns nil.
+func (r *PoolReporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.Report()
+		}
+	}
+}
+
+// Report logs the pools once and returns the number of exhausted hosts.
+func (r *PoolReporter) Report() int {
+	exhausted := 0
+	for _, stats := range r.registry.PoolStats() {
+		var open, idle, waiting int
+		for _, h := range stats.Hosts {
+			open += h.Open()
+			idle += h.Idle
+			waiting += h.Waiting
+			if !h.Exhausted(stats.MaxConnsPerHost) {
+				continue
+			}
+			exhausted++
+			r.log.Warnw("http_client_pool_exhausted",
+				"client", stats.Client,
+				"host", h.Host,
+				"active", h.Active+h.Multiplexed,
+				"waiting", h.Waiting,
+				"max_conns_per_host", stats.MaxConnsPerHost,
+			)
+		}
+		r.log.Debugw("http_client_pool",
+			"client", stats.Client,
+			"hosts", len(stats.Hosts),
+			"open", open,
+			"idle", idle,
+			"waiting", waiting,
+		)
+	}
+	return exhausted
+}
@@ -0,0 +1,134 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingServer answers requests once release is closed; entered
+// receives a value per request handled.
+func blockingServer(t *testing.T) (server *httptest.Server, entered chan struct{}, release chan struct{}) {
+	t.Helper()
+	entered, release = make(chan struct{}, 8), make(chan struct{})
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+	return server, entered, release
+}
+
+// drain performs a GET request and drains the response so its connection
+// is returned to the pool.
+func drain(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// hostStats returns the pool stats of the default client for host.
+func hostStats(t *testing.T, registry *Registry, host string) HostStats {
+	t.Helper()
+	for _, stats := range registry.PoolStats() {
+		if stats.Client != DefaultClient {
+			continue
+		}
+		for _, h := range stats.Hosts {
+			if h.Host == host {
+				return h
+			}
+		}
+	}
+	return HostStats{Host: host}
+}
+
+func TestPool_TracksConnectionStates(t *testing.T) {
+	server, entered, release := blockingServer(t)
+	host := mustHost(t, server.URL)
+	reg := prometheus.NewRegistry()
+	registry := NewRegistry(Config{MaxIdleConnsPerHost: 2}, nil, Options{Metrics: NewMetrics(reg)})
+
+	done := make(chan error, 1)
+	go func() { done <- drain(registry.Default(), server.URL) }()
+	<-entered
+
+	active := hostStats(t, registry, host)
+	assert.Equal(t, 1, active.Active)
+	assert.Equal(t, 0, active.Idle)
+	assert.Equal(t, uint64(1), active.Dials)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.Eventually(t, func() bool { return hostStats(t, registry, host).Idle == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, hostStats(t, registry, host).Active, "drained connections return to the pool")
+	assert.Equal(t, 3, testutil.CollectAndCount(reg, "http_client_connections"), "a series per state")
+	assert.Equal(t, 1, testutil.CollectAndCount(reg, "http_client_connection_wait_seconds"))
+
+	require.NoError(t, drain(registry.Default(), server.URL))
+	assert.Equal(t, uint64(1), hostStats(t, registry, host).Dials, "idle connections are reused")
+
+	registry.CloseIdleConnections()
+	assert.Equal(t, 0, hostStats(t, registry, host).Open())
+}
+
+func TestPool_CountsDialErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	host := mustHost(t, server.URL)
+	server.Close()
+	registry := NewRegistry(Config{}, nil, Options{})
+
+	require.Error(t, drain(registry.Default(), server.URL))
+
+	stats := hostStats(t, registry, host)
+	assert.Equal(t, uint64(1), stats.DialErrors)
+	assert.Zero(t, stats.Dials)
+	assert.Zero(t, stats.Waiting, "failed requests stop waiting")
+}
+
+func TestPoolReporter_WarnsAboutExhaustedHosts(t *testing.T) {
+	server, entered, release := blockingServer(t)
+	host := mustHost(t, server.URL)
+	var buf bytes.Buffer
+	log, err := logger.New(logger.Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Writer: &buf, Format: "json", Level: "INFO"}},
+	})
+	require.NoError(t, err)
+	registry := NewRegistry(Config{MaxConnsPerHost: 1}, nil, Options{})
+	reporter := NewPoolReporter(registry, 0, log)
+
+	assert.Zero(t, reporter.Report())
+
+	done := make(chan error, 2)
+	for range 2 {
+		go func() { done <- drain(registry.Default(), server.URL) }()
+	}
+	<-entered
+	require.Eventually(t, func() bool { return hostStats(t, registry, host).Waiting == 1 }, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 1, reporter.Report())
+	assert.Contains(t, buf.String(), `"msg":"http_client_pool_exhausted"`)
+	assert.Contains(t, buf.String(), `"max_conns_per_host":1`)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.NoError(t, <-done)
+	assert.Equal(t, uint64(1), hostStats(t, registry, host).Dials, "the limit holds")
+	assert.Zero(t, reporter.Report())
+}
@@ -0,0 +1,274 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/deadline"
+	"github.com/luminosita/change-me/pkg/discovery"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/tracecontext"
+)
+
+// DefaultClient is the name of the shared client.
+const DefaultClient = "default"
+
+// Config configures a named client. Zero-valued fields inherit the
+// default client's settings.
+type Config struct {
+	Name                  string         `yaml:"name" validate:"required"`
+	Timeout               time.Duration  `yaml:"timeout" validate:"min=0"`
+	MaxIdleConns          int            `yaml:"max_idle_conns" validate:"min=0"`
+	MaxIdleConnsPerHost   int            `yaml:"max_idle_conns_per_host" validate:"min=0"`
+	MaxConnsPerHost       int            `yaml:"max_conns_per_host" validate:"min=0"`
+	IdleConnTimeout       time.Duration  `yaml:"idle_conn_timeout" validate:"min=0"`
+	DialTimeout           time.Duration  `yaml:"dial_timeout" validate:"min=0"`
+	TLSHandshakeTimeout   time.Duration  `yaml:"tls_handshake_timeout" validate:"min=0"`
+	ResponseHeaderTimeout time.Duration  `yaml:"response_header_timeout" validate:"min=0"`
+	DNSCache              DNSCacheConfig `yaml:"dns_cache"`
+
+	// Budget is the share of the time remaining until the request context
+	// deadline granted to each request, so the caller can still answer
+	// when the upstream is slow; 0 and 1 grant all of it
+	Budget float64 `yaml:"budget" validate:"min=0,max=1"`
+
+	// Egress restricts reachable destinations; nil inherits the default
+	// client's policy
+	Egress *EgressPolicy `yaml:"egress"`
+
+	// Cache serves repeated GET requests from Options.CacheStore
+	Cache CacheConfig `yaml:"cache"`
+
+	// Discovery sends every request to an endpoint of the named service
+	// resolved by Options.Discovery instead of the URL host (not inherited)
+	Discovery string `yaml:"discovery"`
+
+	// Fallback answers requests failing after the transport gave up
+	// (not inherited)
+	Fallback *FallbackConfig `yaml:"fallback"`
+}
+
+// inherit fills zero-valued fields from base.
+func (c Config) inherit(base Config) Config {
+	if c.Timeout == 0 {
+		c.Timeout = base.Timeout
+	}
+	if c.Budget == 0 {
+		c.Budget = base.Budget
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = base.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = base.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost == 0 {
+		c.MaxConnsPerHost = base.MaxConnsPerHost
+	}
+	if c.IdleConnTimeout == 0 {
+		c.IdleConnTimeout = base.IdleConnTimeout
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = base.DialTimeout
+	}
+	if c.TLSHandshakeTimeout == 0 {
+		c.TLSHandshakeTimeout = base.TLSHandshakeTimeout
+	}
+	if c.ResponseHeaderTimeout == 0 {
+		c.ResponseHeaderTimeout = base.ResponseHeaderTimeout
+	}
+	if !c.DNSCache.Enabled && base.DNSCache.Enabled {
+		c.DNSCache = base.DNSCache
+	}
+	if c.Egress == nil {
+		c.Egress = base.Egress
+	}
+	if !c.Cache.Enabled && base.Cache.Enabled {
+		c.Cache = base.Cache
+	}
+	return c
+}
+
+// Options holds the dependencies shared by the registry's clients.
+type Options struct {
+	Metrics    *Metrics       // Outbound metrics (nil records nothing)
+	Logger     *logger.Logger // Audit log of blocked requests (nil disables logging)
+	CacheStore ResponseStore  // Response cache storage; nil disables caching
+
+	// Discovery resolves Config.Discovery services; nil ignores them
+	Discovery *discovery.Resolver
+}
+
+// Registry holds the named outbound clients. Their requests carry the
+// trace context of the request context and a share of its deadline (see
+// Config.Budget), are measured per host, are subject to the client's
+// egress policy and may be served from the response cache or a fallback
+// strategy. Their connection pools are tracked per host (see PoolStats).
+type Registry struct {
+	opts      Options
+	clients   map[string]*http.Client
+	pools     map[string]*pool
+	upstreams map[string]http.RoundTripper
+	fallbacks map[string]FallbackConfig
+}
+
+// NewRegistry builds the default client from base and a client per
+// declared config.
+//
+// Parameters:
+//   - base: Default client settings, inherited by named clients
+//   - configs: Named clients; a config named "default" overrides base
+//   - opts: Shared dependencies
+//
+// Returns:
+//   - *Registry: Client registry
+func NewRegistry(base Config, configs []Config, opts Options) *Registry {
+	base.Name = DefaultClient
+	for _, cfg := range configs {
+		if cfg.Name == DefaultClient {
+			base = cfg.inherit(base)
+		}
+	}
+
+	r := &Registry{
+		opts:      opts,
+		clients:   map[string]*http.Client{},
+		pools:     map[string]*pool{},
+		upstreams: map[string]http.RoundTripper{},
+		fallbacks: map[string]FallbackConfig{},
+	}
+	r.add(base)
+	for _, cfg := range configs {
+		if cfg.Name != DefaultClient {
+			r.add(cfg.inherit(base))
+		}
+	}
+	return r
+}
+
+// add creates the client of cfg.
+func (r *Registry) add(cfg Config) {
+	pool := newPool(cfg, r.opts.Metrics)
+	client := newClient(cfg, pool, r.opts)
+	r.pools[cfg.Name] = pool
+	r.upstreams[cfg.Name] = client.Transport
+	if cfg.Fallback != nil {
+		r.fallbacks[cfg.Name] = *cfg.Fallback
+		client.Transport = newFallbackTransport(client.Transport, client.Transport, cfg.Name, *cfg.Fallback, r.opts)
+	}
+	r.clients[cfg.Name] = client
+}
+
+// Client returns the named client, or the default client when no client
+// with that name is declared.
+func (r *Registry) Client(name string) *http.Client {
+	if client, ok := r.clients[name]; ok {
+		return client
+	}
+	return r.clients[DefaultClient]
+}
+
+// Upstream returns the transport of the named client without its
+// fallback, for callers that retry before degrading (see Fallback).
+func (r *Registry) Upstream(name string) http.RoundTripper {
+	if rt, ok := r.upstreams[name]; ok {
+		return rt
+	}
+	return r.upstreams[DefaultClient]
+}
+
+// Fallback wraps next with the fallback strategies of the named client;
+// next is returned unchanged when the client declares none. Alternate
+// upstream requests bypass next, so its retries and breaker do not apply.
+func (r *Registry) Fallback(name string, next http.RoundTripper) http.RoundTripper {
+	if _, ok := r.clients[name]; !ok {
+		name = DefaultClient
+	}
+	cfg, ok := r.fallbacks[name]
+	if !ok {
+		return next
+	}
+	return newFallbackTransport(next, r.upstreams[name], name, cfg, r.opts)
+}
+
+// Default returns the shared client.
+func (r *Registry) Default() *http.Client {
+	return r.clients[DefaultClient]
+}
+
+// Names returns the declared client names in order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PoolStats returns a snapshot of the connection pool of every client,
+// ordered by client name.
+func (r *Registry) PoolStats() []PoolStats {
+	stats := make([]PoolStats, 0, len(r.pools))
+	for _, name := range r.Names() {
+		stats = append(stats, r.pools[name].stats())
+	}
+	return stats
+}
+
+// CloseIdleConnections closes idle connections of every client.
+func (r *Registry) CloseIdleConnections() {
+	for _, client := range r.clients {
+		client.CloseIdleConnections()
+	}
+}
+
+// newClient creates a client with connection pooling tracked by pool, the
+// optional caching resolver, egress policy, service discovery and response
+// cache.
+func newClient(cfg Config, pool *pool, opts Options) *http.Client {
+	// A custom dialer disables HTTP/2 unless forced, like for
+	// http.DefaultTransport
+	transport := &http.Transport{
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		ForceAttemptHTTP2:     true,
+	}
+
+	policy := cfg.Egress != nil && cfg.Egress.enabled()
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+	if policy {
+		dialer.Control = cfg.Egress.control
+	}
+	dial := dialer.DialContext
+	if cfg.DNSCache.Enabled {
+		dial = NewResolver(cfg.DNSCache).DialContext(dialer)
+	}
+	transport.DialContext = pool.dialContext(dial)
+
+	var rt http.RoundTripper = transport
+	if policy {
+		rt = &EgressTransport{Base: transport, client: cfg.Name, policy: *cfg.Egress, metrics: opts.Metrics, log: opts.Logger}
+	}
+	// Discovered requests are measured and cached under the service name
+	if cfg.Discovery != "" && opts.Discovery != nil {
+		rt = &discovery.Transport{Base: rt, Resolver: opts.Discovery, Service: cfg.Discovery}
+	}
+	// Cache hits are not outbound requests, so the cache wraps the metrics
+	measured := opts.Metrics.Transport(rt)
+	measured.pool = pool
+	rt = measured
+	if cfg.Cache.Enabled && opts.CacheStore != nil {
+		rt = NewCacheTransport(rt, opts.CacheStore, cfg.Cache, opts.Metrics)
+	}
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: tracecontext.Transport(deadline.Transport(rt, cfg.Budget)),
+	}
+}
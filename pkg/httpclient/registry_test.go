@@ -0,0 +1,106 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/tracecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_NamedClientsInheritDefaults(t *testing.T) {
+	base := Config{
+		Timeout:             30 * time.Second,
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 10,
+		MaxConnsPerHost:     20,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	registry := NewRegistry(base, []Config{
+		{Name: "billing", Timeout: 5 * time.Second, MaxConnsPerHost: 50, DNSCache: DNSCacheConfig{Enabled: true}},
+	}, Options{})
+
+	assert.Equal(t, []string{"billing", DefaultClient}, registry.Names())
+	assert.Equal(t, 30*time.Second, registry.Default().Timeout)
+
+	billing := registry.Client("billing")
+	assert.Equal(t, 5*time.Second, billing.Timeout)
+	transport := unwrapTransport(t, billing)
+	assert.Equal(t, 10, transport.MaxIdleConnsPerHost, "unset fields inherit the default")
+	assert.Equal(t, 10*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, 50, transport.MaxConnsPerHost)
+	assert.Equal(t, 20, unwrapTransport(t, registry.Default()).MaxConnsPerHost)
+	assert.NotNil(t, transport.DialContext, "dials are tracked by the pool")
+
+	assert.Same(t, registry.Default(), registry.Client("unknown"))
+}
+
+func TestRegistry_NamedClientsInheritEgressPolicy(t *testing.T) {
+	base := Config{Egress: &EgressPolicy{BlockLinkLocal: true}}
+	registry := NewRegistry(base, []Config{
+		{Name: "billing"},
+		{Name: "webhooks", Egress: &EgressPolicy{BlockPrivate: true}},
+		{Name: "open", Egress: &EgressPolicy{}},
+	}, Options{})
+
+	assert.True(t, egressPolicy(t, registry.Client("billing")).BlockLinkLocal)
+	assert.True(t, egressPolicy(t, registry.Client("webhooks")).BlockPrivate)
+	assert.False(t, egressPolicy(t, registry.Client("webhooks")).BlockLinkLocal, "declared policies replace the default")
+
+	measured := registry.Client("open").Transport.(*tracecontext.RoundTripper).Base.(*RoundTripper)
+	assert.IsType(t, &http.Transport{}, measured.Base, "empty policies add no egress layer")
+}
+
+func TestRegistry_DefaultOverride(t *testing.T) {
+	registry := NewRegistry(Config{Timeout: 30 * time.Second}, []Config{{Name: DefaultClient, Timeout: time.Second}}, Options{})
+
+	assert.Equal(t, []string{DefaultClient}, registry.Names())
+	assert.Equal(t, time.Second, registry.Default().Timeout)
+}
+
+func TestRegistry_BudgetBoundsRequestsByDeadline(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+	registry := NewRegistry(Config{Budget: 0.5}, []Config{{Name: "billing"}}, Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+	require.NoError(t, err)
+	_, err = registry.Client("billing").Do(req)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, ctx.Err(), "the inherited budget leaves the caller time to answer")
+}
+
+// unwrapTransport returns the pooled transport behind trace propagation
+// and metrics.
+func unwrapTransport(t *testing.T, client *http.Client) *http.Transport {
+	t.Helper()
+	traced, ok := client.Transport.(*tracecontext.RoundTripper)
+	require.True(t, ok)
+	measured, ok := traced.Base.(*RoundTripper)
+	require.True(t, ok)
+	transport, ok := measured.Base.(*http.Transport)
+	require.True(t, ok)
+	return transport
+}
+
+// egressPolicy returns the policy enforced by client.
+func egressPolicy(t *testing.T, client *http.Client) EgressPolicy {
+	t.Helper()
+	traced, ok := client.Transport.(*tracecontext.RoundTripper)
+	require.True(t, ok)
+	measured, ok := traced.Base.(*RoundTripper)
+	require.True(t, ok)
+	egress, ok := measured.Base.(*EgressTransport)
+	require.True(t, ok)
+	return egress.policy
+}
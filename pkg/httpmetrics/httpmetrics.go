@@ -0,0 +1,81 @@
+// Package httpmetrics records inbound HTTP request metrics labelled by
+// route template rather than raw path, so /users/1 and /users/2 share the
+// /users/:id series. Requests that matched no route share the Unmatched
+// bucket, keeping label cardinality bounded under scans and typos:
+//
+//	metrics := httpmetrics.NewMetrics(registry)
+//	metrics.Observe(http.MethodGet, "/users/:id", http.StatusOK, elapsed)
+package httpmetrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Unmatched is the route label of requests that matched no route.
+const Unmatched = "unmatched"
+
+// methods are the method labels kept as is; others are recorded as OTHER.
+var methods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodOptions: true,
+}
+
+// Metrics holds the inbound request collectors. A nil *Metrics records
+// nothing.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// NewMetrics creates the inbound request collectors and registers them.
+// It panics if the collectors are already registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_requests_total",
+			Help: "Inbound HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_server_request_duration_seconds",
+			Help:    "Duration of inbound HTTP requests by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_server_requests_in_flight",
+			Help: "Inbound HTTP requests being served.",
+		}),
+	}
+	reg.MustRegister(m.requests, m.duration, m.inFlight)
+	return m
+}
+
+// Start counts a request in flight until the returned function is called.
+func (m *Metrics) Start() (done func()) {
+	if m == nil {
+		return func() {}
+	}
+	m.inFlight.Inc()
+	return m.inFlight.Dec
+}
+
+// Observe records a finished request to route, the template it matched
+// (Unmatched when empty).
+func (m *Metrics) Observe(method, route string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	if !methods[method] {
+		method = "OTHER"
+	}
+	if route == "" {
+		route = Unmatched
+	}
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,40 @@
+package httpmetrics
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Observe(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	metrics.Observe(http.MethodGet, "/users/:id", http.StatusOK, 10*time.Millisecond)
+	metrics.Observe(http.MethodGet, "/users/:id", http.StatusOK, 20*time.Millisecond)
+	metrics.Observe(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+	metrics.Observe("PROPFIND", "", http.StatusNotFound, time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues(http.MethodGet, "/users/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(http.MethodGet, Unmatched, "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("OTHER", Unmatched, "404")), "unknown methods share a label")
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics.requests))
+}
+
+func TestMetrics_Start(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	done := metrics.Start()
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.inFlight))
+	done()
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.inFlight))
+}
+
+func TestMetrics_NilRecordsNothing(t *testing.T) {
+	var metrics *Metrics
+	metrics.Start()()
+	metrics.Observe(http.MethodGet, "/", http.StatusOK, time.Millisecond)
+}
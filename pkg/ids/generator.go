@@ -0,0 +1,91 @@
+package ids
+
+import (
+	"crypto/rand"
+	"sync"
+
+	"github.com/luminosita/change-me/pkg/clock"
+)
+
+// Generator makes IDs. Code takes a Generator, defaulting to Default, and
+// tests pass one with predictable output (mocks.SequentialIDs):
+//
+//	func NewService(opts Options) *Service {
+//		return &Service{ids: ids.OrDefault(opts.IDs)}
+//	}
+type Generator interface {
+	Generate() Raw
+}
+
+// defaultGenerator is shared so timestamps across the process never step
+// back.
+var defaultGenerator = NewUUIDv7(nil)
+
+// Default returns the process-wide UUIDv7 generator.
+func Default() Generator {
+	return defaultGenerator
+}
+
+// OrDefault returns g, or Default when g is nil.
+func OrDefault(g Generator) Generator {
+	if g == nil {
+		return Default()
+	}
+	return g
+}
+
+// NewUUIDv7 returns a generator of RFC 9562 version 7 UUIDs: a 48-bit
+// millisecond timestamp, the version and variant, and 74 random bits.
+//
+// Parameters:
+//   - clk: Clock of the timestamps (nil uses the system clock)
+func NewUUIDv7(clk clock.Clock) Generator {
+	return &generator{
+		clock: clock.OrReal(clk),
+		stamp: func(r *Raw) {
+			r[6] = 0x70 | r[6]&0x0f
+			r[8] = 0x80 | r[8]&0x3f
+		},
+	}
+}
+
+// NewULID returns a generator of ULIDs: a 48-bit millisecond timestamp
+// and 80 random bits.
+//
+// Parameters:
+//   - clk: Clock of the timestamps (nil uses the system clock)
+func NewULID(clk clock.Clock) Generator {
+	return &generator{
+		clock: clock.OrReal(clk),
+		stamp: func(*Raw) {},
+	}
+}
+
+// generator draws the random bits of every ID, so an ID tells nothing
+// about the IDs made before or after it: IDs sort by their millisecond,
+// in random order within it. The timestamp never steps back with the
+// clock, so IDs do not sort before earlier ones' millisecond.
+type generator struct {
+	clock clock.Clock
+	stamp func(*Raw)
+
+	mu sync.Mutex
+	ms int64
+}
+
+// Generate implements Generator.
+func (g *generator) Generate() Raw {
+	g.mu.Lock()
+	g.ms = max(g.clock.Now().UnixMilli(), g.ms)
+	ms := g.ms
+	g.mu.Unlock()
+
+	var raw Raw
+	_, _ = rand.Read(raw[6:])
+	for i := 5; i >= 0; i-- {
+		raw[i] = byte(ms)
+		ms >>= 8
+	}
+	g.stamp(&raw)
+	return raw
+}
@@ -0,0 +1,258 @@
+// Package ids provides typed, time-ordered identifiers, so the ID of one
+// entity cannot be passed where another's is expected and malformed IDs
+// are refused where they enter: JSON, SQL columns and route parameters.
+//
+// Each entity declares a kind, a type picking the text form of its IDs,
+// and an alias of ID over it:
+//
+//	type user struct{}
+//
+//	func (user) IDFormat() ids.Format { return ids.UUID }
+//
+//	type UserID = ids.ID[user]
+//
+//	id := ids.New[user](gen) // "01890a5d-ac96-774b-bcce-b302099a8057"
+//
+// IDs are 128-bit UUIDv7 or ULID values, made by a Generator. Both start
+// with a millisecond timestamp, so they sort by creation time, followed by
+// at least 74 random bits, so they cannot be guessed from one another.
+// Both text forms are accepted whatever the kind writes.
+package ids
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is returned for text that is not a UUID or ULID.
+var ErrInvalid = errors.New("invalid id")
+
+// Format is the text form of IDs.
+type Format int
+
+// Text forms.
+const (
+	UUID Format = iota // "01890a5d-ac96-774b-bcce-b302099a8057"
+	ULID               // "01H455VB4PEX5VSKNK084SN02Q"
+)
+
+// Kind is implemented by the types telling IDs of different entities
+// apart; its method is called on the zero value.
+type Kind interface {
+	IDFormat() Format
+}
+
+// Raw is the 128 bits of an ID, as produced by a Generator.
+type Raw [16]byte
+
+// ID identifies an entity of kind K. The zero value is no ID: it is
+// written as "" in JSON and text and as NULL in SQL.
+type ID[K Kind] Raw
+
+// New returns a new ID of kind K made by g, or by Default when g is nil.
+func New[K Kind](g Generator) ID[K] {
+	return ID[K](OrDefault(g).Generate())
+}
+
+// Parse reads an ID of kind K in either text form.
+func Parse[K Kind](s string) (ID[K], error) {
+	raw, err := parseRaw(s)
+	if err != nil {
+		return ID[K]{}, err
+	}
+	return ID[K](raw), nil
+}
+
+// MustParse is Parse panicking on errors, for constants and tests.
+func MustParse[K Kind](s string) ID[K] {
+	id, err := Parse[K](s)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// IsZero reports whether id is the zero value.
+func (id ID[K]) IsZero() bool { return id == ID[K]{} }
+
+// Compare orders id and other by their bytes, which is creation order
+// across milliseconds: -1, 0 or +1.
+func (id ID[K]) Compare(other ID[K]) int {
+	return bytes.Compare(id[:], other[:])
+}
+
+// Time returns the creation time encoded in id, to the millisecond.
+func (id ID[K]) Time() time.Time {
+	ms := int64(0)
+	for _, b := range id[:6] {
+		ms = ms<<8 | int64(b)
+	}
+	return time.UnixMilli(ms)
+}
+
+// String returns id in the text form of K, "" for the zero value.
+func (id ID[K]) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	var k K
+	if k.IDFormat() == ULID {
+		return encodeULID(Raw(id))
+	}
+	return encodeUUID(Raw(id))
+}
+
+// MarshalText implements encoding.TextMarshaler, which JSON uses.
+func (id ID[K]) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler; "" is the zero value.
+func (id *ID[K]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*id = ID[K]{}
+		return nil
+	}
+	parsed, err := Parse[K](string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// UnmarshalParam implements binding.BindUnmarshaler, so IDs bind from
+// route and query parameters with c.ShouldBindUri and c.ShouldBindQuery.
+func (id *ID[K]) UnmarshalParam(param string) error {
+	return id.UnmarshalText([]byte(param))
+}
+
+// Value implements driver.Valuer, storing the text form; the zero value
+// is NULL.
+func (id ID[K]) Value() (driver.Value, error) {
+	if id.IsZero() {
+		return nil, nil
+	}
+	return id.String(), nil
+}
+
+// Scan implements sql.Scanner for text columns and 16-byte binary ones
+// (BINARY(16), bytea).
+func (id *ID[K]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = ID[K]{}
+		return nil
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == len(Raw{}) {
+			*id = ID[K](v)
+			return nil
+		}
+		return id.UnmarshalText(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalid, src)
+	}
+}
+
+// crockford is the ULID alphabet: Crockford's base32, without I, L, O, U.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// crockfordValues maps upper and lower case ULID characters to their
+// values; others map to 0xff.
+var crockfordValues = func() [256]byte {
+	var values [256]byte
+	for i := range values {
+		values[i] = 0xff
+	}
+	for i := 0; i < len(crockford); i++ {
+		values[crockford[i]] = byte(i)
+		values[strings.ToLower(crockford[i : i+1])[0]] = byte(i)
+	}
+	return values
+}()
+
+// parseRaw reads a UUID, with or without hyphens, or a ULID.
+func parseRaw(s string) (Raw, error) {
+	var raw Raw
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return Raw{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+		fallthrough
+	case 32:
+		if _, err := hex.Decode(raw[:], []byte(s)); err != nil {
+			return Raw{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		return raw, nil
+	case 26:
+		// 26 characters hold 130 bits; the first may only use the low 3
+		if crockfordValues[s[0]] > 7 {
+			return Raw{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		var bits, n uint
+		pos := 0
+		for i := 0; i < len(s); i++ {
+			v := crockfordValues[s[i]]
+			if v == 0xff {
+				return Raw{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+			}
+			bits = bits<<5 | uint(v)
+			n += 5
+			if i == 0 {
+				n = 3 // drop the two padding bits
+			}
+			if n >= 8 {
+				n -= 8
+				raw[pos] = byte(bits >> n)
+				pos++
+			}
+		}
+		return raw, nil
+	default:
+		return Raw{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+}
+
+// encodeUUID writes raw in the canonical 8-4-4-4-12 UUID form.
+func encodeUUID(raw Raw) string {
+	var b [36]byte
+	hex.Encode(b[0:8], raw[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], raw[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], raw[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], raw[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], raw[10:])
+	return string(b[:])
+}
+
+// encodeULID writes raw as 26 Crockford base32 characters, most
+// significant first.
+func encodeULID(raw Raw) string {
+	var b [26]byte
+	// Read the 128 bits as 130, two zero bits in front
+	var bits, n uint
+	pos := 0
+	for i := len(b) - 1; i >= 0; i-- {
+		for n < 5 && pos < len(raw) {
+			bits |= uint(raw[len(raw)-1-pos]) << n
+			n += 8
+			pos++
+		}
+		b[i] = crockford[bits&31]
+		bits >>= 5
+		n -= min(n, 5)
+	}
+	return string(b[:])
+}
@@ -0,0 +1,190 @@
+package ids
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/luminosita/change-me/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct{}
+
+func (user) IDFormat() Format { return UUID }
+
+type order struct{}
+
+func (order) IDFormat() Format { return ULID }
+
+type (
+	userID  = ID[user]
+	orderID = ID[order]
+)
+
+// fixedClock is a clock.Clock reading a settable time.
+type fixedClock struct {
+	clock.Clock
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time { return c.now }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ms   int64
+	}{
+		{"017f22e2-79b0-7cc3-98c4-dc0c0c07398f", "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", 1645557742000},
+		{"017F22E2-79B0-7CC3-98C4-DC0C0C07398F", "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", 1645557742000},
+		{"017f22e279b07cc398c4dc0c0c07398f", "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", 1645557742000},
+		{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "01563e3a-b5d3-d676-4c61-efb99302bd5b", 1469922850259},
+		{"01arz3ndektsv4rrffq69g5fav", "01563e3a-b5d3-d676-4c61-efb99302bd5b", 1469922850259},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			id, err := Parse[user](tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, id.String())
+			assert.Equal(t, tt.ms, id.Time().UnixMilli())
+		})
+	}
+
+	for _, in := range []string{
+		"",
+		"017f22e2-79b0-7cc3-98c4-dc0c0c07398",
+		"017f22e2_79b0_7cc3_98c4_dc0c0c07398f",
+		"zz7f22e279b07cc398c4dc0c0c07398f",
+		"81ARZ3NDEKTSV4RRFFQ69G5FAV", // over 128 bits
+		"01ARZ3NDEKTSV4RRFFQ69G5FAU", // U is not in the alphabet
+	} {
+		_, err := Parse[user](in)
+		assert.ErrorIs(t, err, ErrInvalid, in)
+	}
+}
+
+func TestID_Formats(t *testing.T) {
+	u := MustParse[user]("01563e3a-b5d3-d676-4c61-efb99302bd5b")
+	o := orderID(u)
+
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", o.String())
+	assert.Equal(t, u, userID(MustParse[order](o.String())))
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", orderID(Raw{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}).String())
+	assert.Empty(t, userID{}.String())
+	assert.True(t, userID{}.IsZero())
+}
+
+func TestID_Compare(t *testing.T) {
+	earlier := MustParse[user]("017f22e2-79b0-7cc3-98c4-dc0c0c07398f")
+	later := MustParse[user]("017f22e2-79b1-7000-8000-000000000000")
+
+	assert.Equal(t, -1, earlier.Compare(later))
+	assert.Equal(t, 1, later.Compare(earlier))
+	assert.Equal(t, 0, earlier.Compare(earlier))
+	assert.Equal(t, -1, userID{}.Compare(earlier), "the zero ID sorts first")
+}
+
+func TestID_JSON(t *testing.T) {
+	type body struct {
+		ID     userID  `json:"id"`
+		Parent userID  `json:"parent"`
+		Order  orderID `json:"order"`
+	}
+	in := body{ID: MustParse[user]("017f22e2-79b0-7cc3-98c4-dc0c0c07398f"), Order: MustParse[order]("01ARZ3NDEKTSV4RRFFQ69G5FAV")}
+
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"017f22e2-79b0-7cc3-98c4-dc0c0c07398f","parent":"","order":"01ARZ3NDEKTSV4RRFFQ69G5FAV"}`, string(data))
+
+	var out body
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"id":"42"}`), &out), ErrInvalid)
+}
+
+func TestID_SQL(t *testing.T) {
+	id := MustParse[user]("017f22e2-79b0-7cc3-98c4-dc0c0c07398f")
+
+	value, err := id.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", value)
+	null, err := userID{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, null)
+
+	var scanned userID
+	require.NoError(t, scanned.Scan("017f22e2-79b0-7cc3-98c4-dc0c0c07398f"))
+	assert.Equal(t, id, scanned)
+	require.NoError(t, scanned.Scan(id[:]))
+	assert.Equal(t, id, scanned, "16 bytes are read as binary")
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsZero())
+	assert.ErrorIs(t, scanned.Scan(int64(1)), ErrInvalid)
+}
+
+func TestID_BindsRouteParameters(t *testing.T) {
+	var params struct {
+		ID userID `uri:"id" binding:"required"`
+	}
+
+	require.NoError(t, binding.Uri.BindUri(map[string][]string{"id": {"017f22e2-79b0-7cc3-98c4-dc0c0c07398f"}}, &params))
+	assert.Equal(t, "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", params.ID.String())
+
+	assert.ErrorIs(t, binding.Uri.BindUri(map[string][]string{"id": {"1"}}, &params), ErrInvalid)
+}
+
+func TestGenerator_UUIDv7(t *testing.T) {
+	clk := &fixedClock{now: time.UnixMilli(1645557742000)}
+	g := NewUUIDv7(clk)
+
+	id := New[user](g)
+	assert.Equal(t, byte(0x70), id[6]&0xf0, "version 7")
+	assert.Equal(t, byte(0x80), id[8]&0xc0, "RFC 9562 variant")
+	assert.Equal(t, clk.now, id.Time())
+	assert.Equal(t, "017f22e2-79b0-7", id.String()[:15])
+}
+
+func TestGenerator_Unpredictable(t *testing.T) {
+	for name, newGenerator := range map[string]func(clock.Clock) Generator{"uuidv7": NewUUIDv7, "ulid": NewULID} {
+		t.Run(name, func(t *testing.T) {
+			clk := &fixedClock{now: time.UnixMilli(1645557742000)}
+			g := newGenerator(clk)
+
+			var made []string
+			var previous ID[order]
+			for i := 0; i < 100; i++ {
+				if i == 50 {
+					clk.now = clk.now.Add(-time.Second) // the clock steps back
+				}
+				id := New[order](g)
+				made = append(made, id.String())
+				assert.Equal(t, time.UnixMilli(1645557742000), id.Time(), "timestamps do not step back")
+				if i > 0 {
+					assert.NotEqual(t, previous[6:14], id[6:14], "random bits are drawn, not incremented")
+				}
+				previous = id
+			}
+			assert.Len(t, uniq(made), len(made))
+		})
+	}
+}
+
+func TestOrDefault(t *testing.T) {
+	assert.Same(t, Default(), OrDefault(nil))
+	g := NewULID(nil)
+	assert.Same(t, g, OrDefault(g))
+	assert.False(t, New[user](nil).IsZero())
+}
+
+func uniq(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,87 @@
+// Package jsoncodec selects the JSON library Gin renders responses and
+// binds request bodies with.
+//
+// Gin picks its codec at compile time from the go_json, sonic and jsoniter
+// build tags (encoding/json without them). Use switches it at startup
+// instead, so a faster encoder can be enabled by configuration:
+//
+//	if err := jsoncodec.Use(jsoncodec.GoJSON); err != nil { ... }
+//
+// Builds with -tags sonic (amd64 and arm64 on supported Go releases) keep
+// sonic as the Default codec.
+package jsoncodec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	ginjson "github.com/gin-gonic/gin/codec/json"
+	gojson "github.com/goccy/go-json"
+)
+
+// Codec names accepted by Use.
+const (
+	Default = "default" // The codec selected by Gin's build tags
+	Std     = "std"     // encoding/json
+	GoJSON  = "go-json" // github.com/goccy/go-json
+)
+
+// compiled is the codec Gin was built with.
+var compiled = struct {
+	api     ginjson.Core
+	pkg     string
+	current string
+}{api: ginjson.API, pkg: ginjson.Package, current: ginjson.Package}
+
+// Use makes Gin encode and decode JSON with the named codec. It is meant
+// to be called once at startup, before requests are served.
+func Use(name string) error {
+	switch name {
+	case "", Default:
+		ginjson.API, compiled.current = compiled.api, compiled.pkg
+	case Std:
+		ginjson.API, compiled.current = stdCodec{}, "encoding/json"
+	case GoJSON:
+		ginjson.API, compiled.current = goJSONCodec{}, "github.com/goccy/go-json"
+	default:
+		return fmt.Errorf("unknown JSON codec %q", name)
+	}
+	return nil
+}
+
+// Package returns the import path of the JSON library in use.
+func Package() string {
+	return compiled.current
+}
+
+// stdCodec is the encoding/json codec.
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (stdCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func (stdCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (stdCodec) NewEncoder(w io.Writer) ginjson.Encoder { return json.NewEncoder(w) }
+
+func (stdCodec) NewDecoder(r io.Reader) ginjson.Decoder { return json.NewDecoder(r) }
+
+// goJSONCodec is the github.com/goccy/go-json codec, a drop-in
+// replacement for encoding/json generating type-specialized encoders.
+type goJSONCodec struct{}
+
+func (goJSONCodec) Marshal(v any) ([]byte, error) { return gojson.Marshal(v) }
+
+func (goJSONCodec) Unmarshal(data []byte, v any) error { return gojson.Unmarshal(data, v) }
+
+func (goJSONCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return gojson.MarshalIndent(v, prefix, indent)
+}
+
+func (goJSONCodec) NewEncoder(w io.Writer) ginjson.Encoder { return gojson.NewEncoder(w) }
+
+func (goJSONCodec) NewDecoder(r io.Reader) ginjson.Decoder { return gojson.NewDecoder(r) }
@@ -0,0 +1,79 @@
+package jsoncodec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payload struct {
+	Name  string            `json:"name"`
+	At    time.Time         `json:"at"`
+	Tags  []string          `json:"tags,omitempty"`
+	Attrs map[string]string `json:"attrs"`
+	Skip  string            `json:"-"`
+}
+
+func TestUse(t *testing.T) {
+	t.Cleanup(func() { _ = Use(Default) })
+	builtin := Package()
+
+	require.NoError(t, Use(GoJSON))
+	assert.Equal(t, "github.com/goccy/go-json", Package())
+	require.NoError(t, Use(Std))
+	assert.Equal(t, "encoding/json", Package())
+	require.NoError(t, Use(Default))
+	assert.Equal(t, builtin, Package())
+
+	assert.EqualError(t, Use("simdjson"), `unknown JSON codec "simdjson"`)
+	assert.Equal(t, builtin, Package(), "unknown codecs keep the current one")
+}
+
+func TestCodecs_RenderAndBindAlike(t *testing.T) {
+	t.Cleanup(func() { _ = Use(Default) })
+	gin.SetMode(gin.TestMode)
+
+	in := payload{
+		Name:  "<Jane & Joan>",
+		At:    time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Attrs: map[string]string{"b": "2", "a": "1"},
+		Skip:  "hidden",
+	}
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) { c.JSON(http.StatusOK, in) })
+	router.POST("/", func(c *gin.Context) {
+		var out payload
+		if err := c.ShouldBindJSON(&out); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, out)
+	})
+
+	bodies := map[string]string{}
+	for _, codec := range []string{Std, GoJSON} {
+		require.NoError(t, Use(codec))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		bodies[codec] = w.Body.String()
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(w.Body.String()))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, bodies[codec], w.Body.String(), codec)
+	}
+
+	assert.Equal(t, bodies[Std], bodies[GoJSON])
+	assert.Contains(t, bodies[Std], `\u003cJane \u0026 Joan\u003e`, "HTML is escaped")
+	assert.Contains(t, bodies[Std], `"attrs":{"a":"1","b":"2"}`, "map keys are sorted")
+}
@@ -0,0 +1,502 @@
+// Package jsonpatch applies JSON Patch (RFC 6902) and JSON Merge Patch
+// (RFC 7386) documents to JSON documents, restricted to an allow-list of
+// paths so clients can only change the fields a resource exposes for
+// update.
+//
+// Paths are JSON Pointers (RFC 6901). An allowed path permits changes to
+// the value at that pointer and below it:
+//
+//	allowed := []string{"/email", "/full_name", "/tags"}
+//	out, err := jsonpatch.Apply(jsonpatch.MediaTypeJSONPatch, doc,
+//		[]byte(`[{"op":"add","path":"/tags/-","value":"vip"}]`), allowed)
+//
+// Failures are reported as an *Error wrapping one of the sentinel errors,
+// so transports can tell malformed patches (ErrInvalidPatch,
+// ErrPathNotAllowed) from patches that do not apply to the current
+// document (ErrPathNotFound, ErrTestFailed).
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Media types of the patch formats.
+const (
+	MediaTypeJSONPatch  = "application/json-patch+json"
+	MediaTypeMergePatch = "application/merge-patch+json"
+)
+
+// Sentinel errors wrapped by *Error.
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported patch media type")
+	ErrInvalidPatch         = errors.New("invalid patch")
+	ErrPathNotAllowed       = errors.New("path not allowed")
+	ErrPathNotFound         = errors.New("path not found")
+	ErrTestFailed           = errors.New("test failed")
+)
+
+// Error is a patch that could not be applied.
+type Error struct {
+	Op   int    // Index of the failing operation; -1 for merge patches
+	Path string // JSON Pointer the failure relates to
+	Err  error  // One of the sentinel errors
+	msg  string
+}
+
+// Error describes the failing operation and path.
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.Op >= 0 {
+		fmt.Fprintf(&b, "operation %d: ", e.Op)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, "%s: ", e.Path)
+	}
+	b.WriteString(e.Err.Error())
+	if e.msg != "" {
+		b.WriteString(": " + e.msg)
+	}
+	return b.String()
+}
+
+// Unwrap returns the sentinel error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Operation is a JSON Patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies patch, of the JSON Patch or JSON Merge Patch mediaType, to
+// doc. Only the paths of allowed may change; nil allows every path.
+func Apply(mediaType string, doc, patch []byte, allowed []string) ([]byte, error) {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	switch {
+	case err != nil:
+		return nil, &Error{Op: -1, Err: ErrUnsupportedMediaType, msg: mediaType}
+	case mt == MediaTypeJSONPatch:
+		return ApplyPatch(doc, patch, allowed)
+	case mt == MediaTypeMergePatch:
+		return ApplyMergePatch(doc, patch, allowed)
+	default:
+		return nil, &Error{Op: -1, Err: ErrUnsupportedMediaType, msg: mt}
+	}
+}
+
+// ApplyPatch applies the RFC 6902 JSON Patch patch to doc. Operations are
+// applied in order and the patch fails as a whole when one of them does.
+func ApplyPatch(doc, patch []byte, allowed []string) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, &Error{Op: -1, Err: ErrInvalidPatch, msg: "patch must be an array of operations"}
+	}
+	root, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	policy := newPolicy(allowed)
+
+	for i, op := range ops {
+		if root, err = applyOperation(root, op, policy); err != nil {
+			var patchErr *Error
+			if errors.As(err, &patchErr) {
+				patchErr.Op = i
+			}
+			return nil, err
+		}
+	}
+	return json.Marshal(root)
+}
+
+// applyOperation applies op to root and returns the new document.
+func applyOperation(root any, op Operation, policy policy) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	if op.Op != "test" && !policy.allows(op.Path) {
+		return nil, &Error{Path: op.Path, Err: ErrPathNotAllowed}
+	}
+
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, &Error{Path: op.Path, Err: ErrInvalidPatch, msg: op.Op + " requires a value"}
+		}
+		if value, err = decode(op.Value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" && !policy.allows(op.From) {
+			return nil, &Error{Path: op.From, Err: ErrPathNotAllowed}
+		}
+		if op.Op == "move" && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, &Error{Path: op.From, Err: ErrInvalidPatch, msg: "cannot move a value into itself"}
+		}
+		if value, err = get(root, from); err != nil {
+			return nil, notFound(op.From)
+		}
+		if op.Op == "move" {
+			if root, err = remove(root, from); err != nil {
+				return nil, notFound(op.From)
+			}
+		} else {
+			value = deepCopy(value)
+		}
+	case "remove":
+	default:
+		return nil, &Error{Path: op.Path, Err: ErrInvalidPatch, msg: fmt.Sprintf("unknown op %q", op.Op)}
+	}
+
+	switch op.Op {
+	case "add", "move", "copy":
+		root, err = add(root, path, value)
+	case "remove":
+		root, err = remove(root, path)
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+		if root, err = remove(root, path); err == nil {
+			root, err = add(root, path, value)
+		}
+	case "test":
+		var current any
+		if current, err = get(root, path); err == nil && !equal(current, value) {
+			return nil, &Error{Path: op.Path, Err: ErrTestFailed}
+		}
+	}
+	if err != nil {
+		return nil, notFound(op.Path)
+	}
+	return root, nil
+}
+
+// ApplyMergePatch applies the RFC 7386 JSON Merge Patch patch to doc:
+// object members of patch are merged recursively, null members removed and
+// any other value replaces the target.
+func ApplyMergePatch(doc, patch []byte, allowed []string) ([]byte, error) {
+	root, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, &Error{Op: -1, Err: ErrInvalidPatch, msg: "patch must be a JSON document"}
+	}
+	if err := newPolicy(allowed).checkMerge("", p); err != nil {
+		err.Op = -1
+		return nil, err
+	}
+	return json.Marshal(merge(root, p))
+}
+
+// merge returns target with patch merged into it.
+func merge(target, patch any) any {
+	obj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for name, value := range obj {
+		if value == nil {
+			delete(t, name)
+			continue
+		}
+		t[name] = merge(t[name], value)
+	}
+	return t
+}
+
+// policy is an allow-list of JSON Pointers; nil allows every path.
+type policy struct {
+	allowed []string
+	all     bool
+}
+
+func newPolicy(allowed []string) policy {
+	return policy{allowed: allowed, all: allowed == nil}
+}
+
+// allows reports whether path is an allowed pointer or lies below one.
+func (p policy) allows(path string) bool {
+	if p.all {
+		return true
+	}
+	for _, a := range p.allowed {
+		if path == a || strings.HasPrefix(path, a+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ancestor reports whether path lies above an allowed pointer, so objects
+// merged into it may reach allowed members.
+func (p policy) ancestor(path string) bool {
+	for _, a := range p.allowed {
+		if strings.HasPrefix(a, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMerge reports the first member of the merge patch value at path that
+// changes a path not allowed.
+func (p policy) checkMerge(path string, value any) *Error {
+	if p.allows(path) {
+		return nil
+	}
+	obj, ok := value.(map[string]any)
+	if !ok || !p.ancestor(path) {
+		return &Error{Path: path, Err: ErrPathNotAllowed}
+	}
+	for name, member := range obj {
+		if err := p.checkMerge(path+"/"+escape(name), member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decode parses a JSON document keeping numbers exact.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, &Error{Op: -1, Err: ErrInvalidPatch, msg: err.Error()}
+	}
+	if dec.More() {
+		return nil, &Error{Op: -1, Err: ErrInvalidPatch, msg: "unexpected data after the JSON document"}
+	}
+	return v, nil
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, &Error{Path: pointer, Err: ErrInvalidPatch, msg: "JSON pointer must start with /"}
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// escape escapes a reference token of a JSON Pointer.
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// errMissing is returned by the document helpers for pointers that do not
+// resolve; operations report it as ErrPathNotFound with their path.
+var errMissing = errors.New("missing")
+
+// notFound returns the ErrPathNotFound error of path.
+func notFound(path string) *Error {
+	return &Error{Path: path, Err: ErrPathNotFound}
+}
+
+// get returns the value at path.
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[token]
+			if !ok {
+				return nil, errMissing
+			}
+			node = v
+		case []any:
+			i, err := arrayIndex(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, errMissing
+		}
+	}
+	return node, nil
+}
+
+// add sets the member or inserts the array item at path.
+func add(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(root, path, func(parent any, token string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[token] = value
+			return p, nil
+		case []any:
+			i := len(p)
+			if token != "-" {
+				var err error
+				if i, err = arrayIndex(token, len(p)); err != nil {
+					return nil, err
+				}
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		default:
+			return nil, errMissing
+		}
+	})
+}
+
+// remove deletes the member or array item at path, which must exist.
+func remove(root any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errMissing
+	}
+	return update(root, path, func(parent any, token string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			if _, ok := p[token]; !ok {
+				return nil, errMissing
+			}
+			delete(p, token)
+			return p, nil
+		case []any:
+			i, err := arrayIndex(token, len(p)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:i], p[i+1:]...), nil
+		default:
+			return nil, errMissing
+		}
+	})
+}
+
+// update replaces the container of the last token of path with the result
+// of fn, rebuilding the arrays on the way.
+func update(node any, path []string, fn func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, errMissing
+		}
+		updated, err := update(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = updated
+		return n, nil
+	case []any:
+		i, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := update(n[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = updated
+		return n, nil
+	default:
+		return nil, errMissing
+	}
+}
+
+// arrayIndex parses an array index token no greater than maxIndex.
+func arrayIndex(token string, maxIndex int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, errMissing
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > maxIndex {
+		return 0, errMissing
+	}
+	return i, nil
+}
+
+// equal compares JSON values, numbers by value.
+func equal(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		return errX == nil && errY == nil && fx == fy
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// deepCopy copies the objects and arrays of v.
+func deepCopy(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, w := range x {
+			out[k] = deepCopy(w)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, w := range x {
+			out[i] = deepCopy(w)
+		}
+		return out
+	default:
+		return v
+	}
+}
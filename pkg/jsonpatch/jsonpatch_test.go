@@ -0,0 +1,165 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch_Operations(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"insert item", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append item", `{"foo":[1]}`, `[{"op":"add","path":"/foo/-","value":2}]`, `{"foo":[1,2]}`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove item", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move item", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			`{"foo":["all","cows","eat","grass"]}`},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+			`{"a":{"b":1},"c":{"b":2}}`},
+		{"test", `{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+		{"large number", `{"id":9007199254740993}`, `[{"op":"add","path":"/x","value":true}]`, `{"id":9007199254740993,"x":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ApplyPatch([]byte(tt.doc), []byte(tt.patch), nil)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(out))
+		})
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  error
+		op    int
+		path  string
+	}{
+		{"not an array", `{"op":"add"}`, ErrInvalidPatch, -1, ""},
+		{"unknown op", `[{"op":"merge","path":"/a"}]`, ErrInvalidPatch, 0, "/a"},
+		{"missing value", `[{"op":"add","path":"/a"}]`, ErrInvalidPatch, 0, "/a"},
+		{"bad pointer", `[{"op":"remove","path":"a"}]`, ErrInvalidPatch, 0, "a"},
+		{"missing member", `[{"op":"remove","path":"/missing"}]`, ErrPathNotFound, 0, "/missing"},
+		{"index out of range", `[{"op":"add","path":"/list/5","value":1}]`, ErrPathNotFound, 0, "/list/5"},
+		{"leading zero index", `[{"op":"remove","path":"/list/01"}]`, ErrPathNotFound, 0, "/list/01"},
+		{"missing parent", `[{"op":"add","path":"/x/y","value":1}]`, ErrPathNotFound, 0, "/x/y"},
+		{"failed test", `[{"op":"add","path":"/b","value":1},{"op":"test","path":"/a","value":"other"}]`, ErrTestFailed, 1, "/a"},
+		{"move into child", `[{"op":"move","from":"/obj","path":"/obj/child"}]`, ErrInvalidPatch, 0, "/obj"},
+	}
+	doc := []byte(`{"a":"value","list":[1,2],"obj":{}}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyPatch(doc, []byte(tt.patch), nil)
+			require.ErrorIs(t, err, tt.want)
+			var patchErr *Error
+			require.ErrorAs(t, err, &patchErr)
+			assert.Equal(t, tt.op, patchErr.Op)
+			assert.Equal(t, tt.path, patchErr.Path)
+		})
+	}
+}
+
+func TestApplyPatch_AllowedPaths(t *testing.T) {
+	doc := []byte(`{"id":1,"email":"a@example.com","tags":["x"]}`)
+	allowed := []string{"/email", "/tags"}
+
+	out, err := ApplyPatch(doc, []byte(`[
+		{"op":"test","path":"/id","value":1},
+		{"op":"replace","path":"/email","value":"b@example.com"},
+		{"op":"add","path":"/tags/-","value":"y"}
+	]`), allowed)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"email":"b@example.com","tags":["x","y"]}`, string(out))
+
+	for _, patch := range []string{
+		`[{"op":"replace","path":"/id","value":2}]`,
+		`[{"op":"add","path":"/emails","value":"c"}]`,
+		`[{"op":"move","from":"/id","path":"/email"}]`,
+		`[{"op":"replace","path":"","value":{}}]`,
+	} {
+		_, err := ApplyPatch(doc, []byte(patch), allowed)
+		assert.ErrorIs(t, err, ErrPathNotAllowed, patch)
+	}
+
+	_, err = ApplyPatch(doc, []byte(`[{"op":"copy","from":"/id","path":"/email"}]`), allowed)
+	assert.NoError(t, err, "values may be copied from any path")
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	// RFC 7386 appendix A
+	tests := []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		out, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch), nil)
+		require.NoError(t, err, tt.patch)
+		assert.JSONEq(t, tt.want, string(out), tt.patch)
+	}
+}
+
+func TestApplyMergePatch_AllowedPaths(t *testing.T) {
+	doc := []byte(`{"id":1,"profile":{"name":"Jane","role":"user"}}`)
+	allowed := []string{"/profile/name"}
+
+	out, err := ApplyMergePatch(doc, []byte(`{"profile":{"name":"Joan"}}`), allowed)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"profile":{"name":"Joan","role":"user"}}`, string(out))
+
+	for patch, path := range map[string]string{
+		`{"id":2}`:                        "/id",
+		`{"profile":{"role":"admin"}}`:    "/profile/role",
+		`{"profile":null}`:                "/profile",
+		`["replaces the whole document"]`: "",
+	} {
+		_, err := ApplyMergePatch(doc, []byte(patch), allowed)
+		var patchErr *Error
+		require.ErrorAs(t, err, &patchErr, patch)
+		assert.ErrorIs(t, err, ErrPathNotAllowed)
+		assert.Equal(t, path, patchErr.Path)
+		assert.Equal(t, -1, patchErr.Op)
+	}
+}
+
+func TestApply_MediaTypes(t *testing.T) {
+	doc := []byte(`{"a":1}`)
+
+	out, err := Apply("application/merge-patch+json; charset=utf-8", doc, []byte(`{"b":2}`), nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":1,"b":2}`, string(out))
+
+	out, err = Apply(MediaTypeJSONPatch, doc, []byte(`[{"op":"remove","path":"/a"}]`), nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(out))
+
+	_, err = Apply("application/json", doc, []byte(`{}`), nil)
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+	assert.EqualError(t, err, "unsupported patch media type: application/json")
+}
@@ -0,0 +1,272 @@
+// Package loadtest drives concurrent HTTP load against a target and reports
+// latency percentiles and error rates, with optional budget assertions.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options configures a load test run.
+type Options struct {
+	URL         string        // Target URL
+	Method      string        // HTTP method (default GET)
+	Headers     http.Header   // Extra request headers
+	Concurrency int           // Number of concurrent workers (default 10)
+	Requests    int           // Total requests to send (0 = bounded by Duration only)
+	Duration    time.Duration // Maximum run duration (default 10s)
+	Timeout     time.Duration // Per-request timeout (default 5s)
+	Client      *http.Client  // HTTP client (default: pooled client sized to Concurrency)
+}
+
+// Thresholds defines latency and error budgets. Zero latencies and a nil
+// MaxErrorRate are not checked.
+type Thresholds struct {
+	P50          time.Duration
+	P95          time.Duration
+	P99          time.Duration
+	MaxErrorRate *float64 // Fraction of failed requests, 0..1; 0 allows none
+}
+
+// Report summarizes the results of a run.
+type Report struct {
+	Requests   int
+	Errors     int
+	StatusCode map[int]int
+	Elapsed    time.Duration
+	Min        time.Duration
+	Max        time.Duration
+	Mean       time.Duration
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+}
+
+// ErrorRate returns the fraction of requests that failed.
+// Transport errors and 5xx responses count as failures.
+func (r *Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// Throughput returns completed requests per second.
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// Check compares the report with the thresholds and returns an error
+// describing every budget that was exceeded.
+func (r *Report) Check(th Thresholds) error {
+	var violations []string
+
+	check := func(name string, got, budget time.Duration) {
+		if budget > 0 && got > budget {
+			violations = append(violations, fmt.Sprintf("%s %s exceeds budget %s", name, got, budget))
+		}
+	}
+	check("p50", r.P50, th.P50)
+	check("p95", r.P95, th.P95)
+	check("p99", r.P99, th.P99)
+
+	if th.MaxErrorRate != nil && r.ErrorRate() > *th.MaxErrorRate {
+		violations = append(violations,
+			fmt.Sprintf("error rate %.4f exceeds budget %.4f", r.ErrorRate(), *th.MaxErrorRate))
+	}
+
+	if len(violations) > 0 {
+		return errors.New(strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// String renders a human-readable summary.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "requests:    %d (%.1f req/s)\n", r.Requests, r.Throughput())
+	fmt.Fprintf(&b, "errors:      %d (%.2f%%)\n", r.Errors, r.ErrorRate()*100)
+	fmt.Fprintf(&b, "latency:     min=%s mean=%s max=%s\n", r.Min, r.Mean, r.Max)
+	fmt.Fprintf(&b, "percentiles: p50=%s p95=%s p99=%s\n", r.P50, r.P95, r.P99)
+
+	codes := make([]int, 0, len(r.StatusCode))
+	for code := range r.StatusCode {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(&b, "status %d:  %d\n", code, r.StatusCode[code])
+	}
+	return b.String()
+}
+
+// result captures the outcome of a single request.
+type result struct {
+	latency time.Duration
+	status  int
+	failed  bool
+}
+
+// Run executes the load test until Requests are sent, Duration elapses,
+// or ctx is canceled, whichever happens first.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation
+//   - opts: Load test options
+//
+// Returns:
+//   - *Report: Aggregated results
+//   - error: Invalid options
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.URL == "" {
+		return nil, errors.New("loadtest: URL is required")
+	}
+	applyDefaults(&opts)
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	var (
+		sent    int64
+		mu      sync.Mutex
+		results = make([]result, 0, max(opts.Requests, 1024))
+		wg      sync.WaitGroup
+	)
+
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if opts.Requests > 0 && atomic.AddInt64(&sent, 1) > int64(opts.Requests) {
+					return
+				}
+				res := do(ctx, &opts)
+				if ctx.Err() != nil && res.status == 0 {
+					// Request aborted by the run deadline, not a server failure
+					return
+				}
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return summarize(results, time.Since(start)), nil
+}
+
+// applyDefaults fills unset options.
+func applyDefaults(opts *Options) {
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = 10 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{
+			Transport: &http.Transport{
+				MaxIdleConns:        opts.Concurrency,
+				MaxIdleConnsPerHost: opts.Concurrency,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		}
+	}
+}
+
+// do sends a single request and measures its latency.
+func do(ctx context.Context, opts *Options) result {
+	reqCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, opts.Method, opts.URL, nil)
+	if err != nil {
+		return result{failed: true}
+	}
+	for k, v := range opts.Headers {
+		req.Header[k] = v
+	}
+
+	start := time.Now()
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		return result{latency: time.Since(start), failed: true}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	return result{
+		latency: time.Since(start),
+		status:  resp.StatusCode,
+		failed:  resp.StatusCode >= http.StatusInternalServerError,
+	}
+}
+
+// summarize aggregates individual results into a report.
+func summarize(results []result, elapsed time.Duration) *Report {
+	report := &Report{
+		Requests:   len(results),
+		StatusCode: make(map[int]int),
+		Elapsed:    elapsed,
+	}
+	if len(results) == 0 {
+		return report
+	}
+
+	latencies := make([]time.Duration, len(results))
+	var total time.Duration
+	for i, res := range results {
+		latencies[i] = res.latency
+		total += res.latency
+		if res.failed {
+			report.Errors++
+		}
+		if res.status != 0 {
+			report.StatusCode[res.status]++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	report.Min = latencies[0]
+	report.Max = latencies[len(latencies)-1]
+	report.Mean = total / time.Duration(len(latencies))
+	report.P50 = percentile(latencies, 50)
+	report.P95 = percentile(latencies, 95)
+	report.P99 = percentile(latencies, 99)
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
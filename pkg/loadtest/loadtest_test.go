@@ -0,0 +1,113 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_SendsRequestedCount(t *testing.T) {
+	var hits int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	report, err := Run(context.Background(), Options{
+		URL:         srv.URL,
+		Concurrency: 4,
+		Requests:    50,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 50, report.Requests)
+	assert.Equal(t, int64(50), atomic.LoadInt64(&hits))
+	assert.Equal(t, 50, report.StatusCode[http.StatusOK])
+	assert.Zero(t, report.Errors)
+	assert.LessOrEqual(t, report.P50, report.P95)
+	assert.LessOrEqual(t, report.P95, report.P99)
+	assert.LessOrEqual(t, report.P99, report.Max)
+}
+
+func TestRun_CountsServerErrors(t *testing.T) {
+	var n int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&n, 1)%2 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	report, err := Run(context.Background(), Options{URL: srv.URL, Concurrency: 1, Requests: 10})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Errors)
+	assert.InDelta(t, 0.5, report.ErrorRate(), 0.001)
+	assert.Error(t, report.Check(Thresholds{MaxErrorRate: rate(0.1)}))
+	assert.NoError(t, report.Check(Thresholds{MaxErrorRate: rate(0.6)}))
+	assert.NoError(t, report.Check(Thresholds{}), "no error budget set")
+}
+
+func TestCheck_ZeroErrorBudget(t *testing.T) {
+	assert.NoError(t, (&Report{Requests: 10}).Check(Thresholds{MaxErrorRate: rate(0)}))
+	assert.ErrorContains(t, (&Report{Requests: 10, Errors: 1}).Check(Thresholds{MaxErrorRate: rate(0)}),
+		"error rate 0.1000 exceeds budget 0.0000")
+}
+
+// rate returns a pointer to an error budget.
+func rate(v float64) *float64 {
+	return &v
+}
+
+func TestRun_StopsAtDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	report, err := Run(context.Background(), Options{URL: srv.URL, Concurrency: 2, Duration: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Positive(t, report.Requests)
+	assert.Zero(t, report.Errors)
+}
+
+func TestRun_RequiresURL(t *testing.T) {
+	_, err := Run(context.Background(), Options{})
+	assert.Error(t, err)
+}
+
+func TestReport_CheckLatencyBudgets(t *testing.T) {
+	report := &Report{Requests: 100, P50: 10 * time.Millisecond, P95: 40 * time.Millisecond, P99: 90 * time.Millisecond}
+
+	assert.NoError(t, report.Check(Thresholds{}))
+	assert.NoError(t, report.Check(Thresholds{P99: 100 * time.Millisecond}))
+
+	err := report.Check(Thresholds{P95: 20 * time.Millisecond, P99: 50 * time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "p95")
+	assert.Contains(t, err.Error(), "p99")
+}
+
+func TestPercentile_NearestRank(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(sorted, 95))
+	assert.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 99))
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field is a typed field of entries logged through Logger.Zap.
+type Field = zap.Field
+
+// String returns a string field.
+func String(key, value string) Field { return zap.String(key, value) }
+
+// Int returns an int field.
+func Int(key string, value int) Field { return zap.Int(key, value) }
+
+// Int64 returns an int64 field.
+func Int64(key string, value int64) Field { return zap.Int64(key, value) }
+
+// Uint64 returns a uint64 field.
+func Uint64(key string, value uint64) Field { return zap.Uint64(key, value) }
+
+// Float64 returns a float64 field.
+func Float64(key string, value float64) Field { return zap.Float64(key, value) }
+
+// Bool returns a bool field.
+func Bool(key string, value bool) Field { return zap.Bool(key, value) }
+
+// Duration returns a time.Duration field, encoded like the sugared logger
+// encodes durations.
+func Duration(key string, value time.Duration) Field { return zap.Duration(key, value) }
+
+// Time returns a time.Time field.
+func Time(key string, value time.Time) Field { return zap.Time(key, value) }
+
+// Err returns the error field, logged under "error" like the sugared
+// "error", err pairs.
+func Err(err error) Field { return zap.Error(err) }
+
+// Object returns a field encoded by value's MarshalLogObject, without
+// reflection.
+func Object(key string, value zapcore.ObjectMarshaler) Field { return zap.Object(key, value) }
+
+// Any returns a field for value, falling back to reflection for types
+// without a typed constructor; prefer those on hot paths.
+func Any(key string, value any) Field { return zap.Any(key, value) }
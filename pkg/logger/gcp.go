@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// Special fields of Google Cloud structured logging, which Cloud Logging
+// lifts into the log entry.
+const (
+	gcpTraceKey = "logging.googleapis.com/trace"
+	gcpSpanKey  = "logging.googleapis.com/spanId"
+)
+
+// gcpEncoderConfig returns the JSON encoder configuration of the gcp
+// format: severity, message and time under the keys Cloud Logging reads,
+// and stack traces where Error Reporting finds them.
+func gcpEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "severity",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "message",
+		StacktraceKey:  "stack_trace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    encodeGCPSeverity,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.MillisDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+}
+
+// encodeGCPSeverity encodes levels as Cloud Logging severities.
+func encodeGCPSeverity(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch level {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.PanicLevel:
+		enc.AppendString("ALERT")
+	case zapcore.FatalLevel:
+		enc.AppendString("EMERGENCY")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
+
+// gcpTraceCore adds the Cloud Logging trace fields to entries logging a
+// trace_id (and span_id), so they are grouped with the request trace. The
+// trace field needs the project, so without one entries are left as is.
+type gcpTraceCore struct {
+	zapcore.Core
+	project string
+}
+
+// newGCPTraceCore wraps core with the trace fields of project.
+func newGCPTraceCore(core zapcore.Core, project string) zapcore.Core {
+	if project == "" {
+		return core
+	}
+	return &gcpTraceCore{Core: core, project: project}
+}
+
+// With implements zapcore.Core.
+func (c *gcpTraceCore) With(fields []zapcore.Field) zapcore.Core {
+	return &gcpTraceCore{Core: c.Core.With(c.traceFields(fields)), project: c.project}
+}
+
+// Check implements zapcore.Core, adding c rather than the wrapped core so
+// entries are written through Write.
+func (c *gcpTraceCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *gcpTraceCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.traceFields(fields))
+}
+
+// traceFields returns fields with the Cloud Logging trace fields appended
+// for their trace_id and span_id, copying the slice only when they are
+// present.
+func (c *gcpTraceCore) traceFields(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	for _, f := range fields {
+		if f.Type != zapcore.StringType || f.String == "" {
+			continue
+		}
+		switch f.Key {
+		case "trace_id":
+			out = appendCopy(out, len(fields), String(gcpTraceKey, "projects/"+c.project+"/traces/"+f.String))
+		case "span_id":
+			out = appendCopy(out, len(fields), String(gcpSpanKey, f.String))
+		}
+	}
+	return out
+}
+
+// appendCopy appends f to out, copying out first while it still is the
+// caller's slice of length n.
+func appendCopy(out []zapcore.Field, n int, f zapcore.Field) []zapcore.Field {
+	if len(out) == n {
+		out = append(make([]zapcore.Field, 0, n+2), out...)
+	}
+	return append(out, f)
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newGCPTestLogger returns a logger encoding gcp entries of project to buf.
+func newGCPTestLogger(buf *bytes.Buffer, project string) *zap.Logger {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(gcpEncoderConfig()), zapcore.AddSync(buf), zapcore.DebugLevel)
+	return zap.New(newGCPTraceCore(core, project))
+}
+
+func TestGCPFormat_EncodesSeverityAndTraceFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := newGCPTestLogger(&buf, "my-project")
+
+	log.With(String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736")).Warn("upstream_slow", String("span_id", "00f067aa0ba902b7"))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARNING", entry["severity"])
+	assert.Equal(t, "upstream_slow", entry["message"])
+	assert.Contains(t, entry, "time")
+	assert.Equal(t, "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736", entry[gcpTraceKey])
+	assert.Equal(t, "00f067aa0ba902b7", entry[gcpSpanKey])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"], "the original fields are kept")
+}
+
+func TestGCPFormat_WithoutProjectOrTrace(t *testing.T) {
+	var buf bytes.Buffer
+	newGCPTestLogger(&buf, "").Error("failed", String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"))
+	newGCPTestLogger(&buf, "my-project").Info("started", String("trace_id", ""))
+
+	assert.NotContains(t, buf.String(), gcpTraceKey)
+	assert.Contains(t, buf.String(), `"severity":"ERROR"`)
+}
+
+func TestNew_GCPFormat(t *testing.T) {
+	log, err := New(Config{Level: "INFO", Format: "gcp", GCPProject: "my-project"})
+	require.NoError(t, err)
+	assert.True(t, log.Zap().Core().Enabled(zapcore.InfoLevel))
+}
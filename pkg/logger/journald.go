@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// journaldSocket is the systemd-journald native protocol socket.
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldCore writes entries to systemd-journald with the native protocol,
+// so zap fields become journal fields (user_id -> USER_ID).
+type journaldCore struct {
+	zapcore.LevelEnabler
+	conn       net.Conn
+	identifier string
+	fields     []zapcore.Field
+}
+
+func newJournaldCore(out Output, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	path := out.Address
+	if path == "" {
+		path = journaldSocket
+	}
+	conn, err := net.Dial("unixgram", path)
+	if err != nil {
+		return nil, fmt.Errorf("connect to journald: %w", err)
+	}
+	return &journaldCore{LevelEnabler: level, conn: conn, identifier: out.AppName}, nil
+}
+
+func (c *journaldCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	return &clone
+}
+
+func (c *journaldCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *journaldCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	var buf bytes.Buffer
+	journaldField(&buf, "MESSAGE", ent.Message)
+	journaldField(&buf, "PRIORITY", fmt.Sprint(syslogSeverity(ent.Level)))
+	if c.identifier != "" {
+		journaldField(&buf, "SYSLOG_IDENTIFIER", c.identifier)
+	}
+	if ent.Caller.Defined {
+		journaldField(&buf, "CODE_FILE", ent.Caller.File)
+		journaldField(&buf, "CODE_LINE", fmt.Sprint(ent.Caller.Line))
+		journaldField(&buf, "CODE_FUNC", ent.Caller.Function)
+	}
+	if ent.Stack != "" {
+		journaldField(&buf, "STACKTRACE", ent.Stack)
+	}
+	for key, value := range enc.Fields {
+		if name := journaldName(key); name != "" {
+			journaldField(&buf, name, journaldValue(value))
+		}
+	}
+
+	_, err := c.conn.Write(buf.Bytes())
+	return err
+}
+
+func (c *journaldCore) Sync() error { return nil }
+
+// journaldField appends a field, using the length-prefixed form for values
+// containing newlines.
+func journaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journaldName converts a zap key to a journal field name: uppercase
+// letters, digits and underscores, not starting with an underscore.
+func journaldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// journaldValue renders a field value; composite values are JSON encoded.
+func journaldValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	case []any, map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -1,19 +1,87 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-// Logger wraps zap.SugaredLogger for structured logging.
+// Logger wraps zap.SugaredLogger for structured logging. Hot paths log
+// through Zap with typed fields instead, which avoids boxing the key-value
+// pairs.
 type Logger struct {
 	*zap.SugaredLogger
+	base       *zap.Logger
+	suppressed *atomic.Uint64
+	dropped    *atomic.Uint64
 }
 
 // Config defines logger configuration options.
 type Config struct {
+	Level    string    // DEBUG, INFO, WARNING, ERROR, CRITICAL
+	Format   string    // json, text or gcp (Google Cloud structured logging)
+	Sampling *Sampling // Duplicate suppression; nil logs every entry
+	Outputs  []Output  // Destinations written in addition to stderr
+
+	// NoConsole disables the stderr output, for hosts that collect logs
+	// through syslog or journald instead of scraping stdout
+	NoConsole bool
+
+	// RedactPII masks personal data in every output: string values logged
+	// under pii.Keys and the pii-tagged fields of logged structs
+	RedactPII bool
+
+	// GCPProject is the Google Cloud project of the trace field added to
+	// entries logging a trace_id in the gcp format
+	GCPProject string
+}
+
+// Output types.
+const (
+	OutputFile          = "file"
+	OutputSyslog        = "syslog"
+	OutputJournald      = "journald"
+	OutputLoki          = "loki"
+	OutputElasticsearch = "elasticsearch"
+)
+
+// Output is an additional log destination with its own format and level,
+// e.g. JSON to a file next to human-readable console output.
+type Output struct {
+	Type   string // file (default), syslog, journald, loki or elasticsearch
+	Format string // json or text (file and syslog message body)
 	Level  string // DEBUG, INFO, WARNING, ERROR, CRITICAL
-	Format string // json or text
+
+	// File outputs
+	Path   string    // File path (appended to), "stdout" or "stderr"
+	Writer io.Writer // Used instead of Path when set
+
+	// Syslog, journald and shipping outputs
+	Network  string // Syslog transport: udp (default), tcp or unix; ignored without Address
+	Address  string // Syslog host:port or socket path (local socket when empty); journald socket path; Loki/Elasticsearch base URL
+	Facility string // Syslog facility name (default local0)
+	AppName  string // Syslog APP-NAME / journald SYSLOG_IDENTIFIER / Loki service label
+
+	// Loki and Elasticsearch outputs
+	Labels  map[string]string // Loki stream labels
+	Index   string            // Elasticsearch index or data stream
+	Headers map[string]string // Request headers, e.g. Authorization
+	Batch   Batch             // Batching, queueing and retries
+}
+
+// Sampling limits duplicate entries (same level and message): within each
+// Tick the first Initial entries are logged, then every Thereafter-th.
+type Sampling struct {
+	Tick       time.Duration // Sampling window (default 1s)
+	Initial    int           // Entries logged per window before sampling; 0 disables sampling
+	Thereafter int           // After Initial, log every Nth entry; 0 drops the rest
 }
 
 // New creates a new structured logger instance.
@@ -27,19 +95,24 @@ type Config struct {
 //   - error: Configuration or initialization error
 func New(cfg Config) (*Logger, error) {
 	// Parse log level
-	level, err := parseLevel(cfg.Level)
+	level, err := ParseLevel(cfg.Level)
 	if err != nil {
 		return nil, err
 	}
 
 	// Configure encoder based on format
 	var zapConfig zap.Config
-	if cfg.Format == "json" {
+	switch cfg.Format {
+	case "json":
 		// JSON format for production (machine-readable)
 		zapConfig = zap.NewProductionConfig()
 		zapConfig.EncoderConfig.TimeKey = "timestamp"
 		zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	} else {
+	case "gcp":
+		// JSON with the fields read by Google Cloud Logging
+		zapConfig = zap.NewProductionConfig()
+		zapConfig.EncoderConfig = gcpEncoderConfig()
+	default:
 		// Text format for development (human-readable)
 		zapConfig = zap.NewDevelopmentConfig()
 		zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
@@ -48,19 +121,175 @@ func New(cfg Config) (*Logger, error) {
 
 	zapConfig.Level = zap.NewAtomicLevelAt(level)
 
+	// Trace fields of the gcp format are added to the console core only
+	var opts []zap.Option
+	if cfg.Format == "gcp" {
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newGCPTraceCore(core, cfg.GCPProject)
+		}))
+	}
+
+	// Redaction wraps each output so the console core is wrapped before
+	// it is teed with the additional outputs
+	if cfg.RedactPII {
+		opts = append(opts, zap.WrapCore(newRedactCore))
+	}
+
+	// Additional outputs are teed with the console core
+	dropped := new(atomic.Uint64)
+	if len(cfg.Outputs) > 0 || cfg.NoConsole {
+		cores, err := outputCores(cfg.Outputs, dropped)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.RedactPII {
+			for i, core := range cores {
+				cores[i] = newRedactCore(core)
+			}
+		}
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			if cfg.NoConsole {
+				return zapcore.NewTee(cores...)
+			}
+			return zapcore.NewTee(append([]zapcore.Core{core}, cores...)...)
+		}))
+	}
+
+	// Sampling is applied below so suppressed entries can be counted
+	zapConfig.Sampling = nil
+	suppressed := new(atomic.Uint64)
+	if s := cfg.Sampling; s != nil && s.Initial > 0 {
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newSampler(core, *s, suppressed)
+		}))
+	}
+
 	// Build logger
-	zapLogger, err := zapConfig.Build()
+	zapLogger, err := zapConfig.Build(opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Logger{
 		SugaredLogger: zapLogger.Sugar(),
+		base:          zapLogger,
+		suppressed:    suppressed,
+		dropped:       dropped,
 	}, nil
 }
 
-// parseLevel converts string log level to zapcore.Level.
-func parseLevel(level string) (zapcore.Level, error) {
+// With returns a child logger adding the key-value pairs to every entry,
+// sharing l's outputs and counters.
+func (l *Logger) With(args ...any) *Logger {
+	sugared := l.SugaredLogger.With(args...)
+	return &Logger{
+		SugaredLogger: sugared,
+		base:          sugared.Desugar(),
+		suppressed:    l.suppressed,
+		dropped:       l.dropped,
+	}
+}
+
+// Zap returns the non-sugared logger sharing l's outputs and fields. It
+// takes typed fields and allocates nothing for entries below its level:
+//
+//	if ce := log.Zap().Check(level, "http_request"); ce != nil {
+//		ce.Write(logger.String("method", method), logger.Int("status", status))
+//	}
+func (l *Logger) Zap() *zap.Logger {
+	switch {
+	case l.base != nil:
+		return l.base
+	case l.SugaredLogger != nil:
+		return l.SugaredLogger.Desugar()
+	default:
+		return zap.NewNop()
+	}
+}
+
+// Suppressed returns the number of entries dropped by sampling.
+func (l *Logger) Suppressed() uint64 {
+	if l.suppressed == nil {
+		return 0
+	}
+	return l.suppressed.Load()
+}
+
+// outputCores builds a core per additional output. Shipping outputs count
+// undelivered entries in dropped.
+func outputCores(outputs []Output, dropped *atomic.Uint64) ([]zapcore.Core, error) {
+	cores := make([]zapcore.Core, 0, len(outputs))
+	for _, out := range outputs {
+		level, err := ParseLevel(out.Level)
+		if err != nil {
+			return nil, err
+		}
+
+		var core zapcore.Core
+		switch out.Type {
+		case "", OutputFile:
+			core, err = fileCore(out, level)
+		case OutputSyslog:
+			core, err = newSyslogCore(out, level)
+		case OutputJournald:
+			core, err = newJournaldCore(out, level)
+		case OutputLoki, OutputElasticsearch:
+			core, err = newShippingCore(out, level, dropped)
+		default:
+			err = fmt.Errorf("unknown log output type %q", out.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+	return cores, nil
+}
+
+// fileCore writes encoded entries to a file, stream or writer.
+func fileCore(out Output, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	if out.Writer != nil {
+		return zapcore.NewCore(newEncoder(out.Format, false), zapcore.AddSync(out.Writer), level), nil
+	}
+
+	sink, _, err := zap.Open(out.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open log output %q: %w", out.Path, err)
+	}
+	console := out.Path == "stdout" || out.Path == "stderr"
+	return zapcore.NewCore(newEncoder(out.Format, console), sink, level), nil
+}
+
+// newEncoder returns the encoder for format, colorizing text levels only
+// for terminals.
+func newEncoder(format string, color bool) zapcore.Encoder {
+	if format == "json" {
+		enc := zap.NewProductionEncoderConfig()
+		enc.TimeKey = "timestamp"
+		enc.EncodeTime = zapcore.ISO8601TimeEncoder
+		return zapcore.NewJSONEncoder(enc)
+	}
+
+	enc := zap.NewDevelopmentEncoderConfig()
+	enc.EncodeTime = zapcore.ISO8601TimeEncoder
+	if color {
+		enc.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(enc)
+}
+
+// Dropped returns the number of entries shipping outputs failed to deliver,
+// because their queue was full or retries were exhausted.
+func (l *Logger) Dropped() uint64 {
+	if l.dropped == nil {
+		return 0
+	}
+	return l.dropped.Load()
+}
+
+// ParseLevel converts a configured log level to zapcore.Level; unknown
+// levels map to info.
+func ParseLevel(level string) (zapcore.Level, error) {
 	switch level {
 	case "DEBUG":
 		return zapcore.DebugLevel, nil
@@ -78,7 +307,15 @@ func parseLevel(level string) (zapcore.Level, error) {
 }
 
 // Sync flushes any buffered log entries.
-// Applications should call Sync before exiting.
+// Applications should call Sync before exiting. Terminals and pipes, such
+// as stderr under a container runtime or test runner, cannot be synced;
+// those errors are ignored.
 func (l *Logger) Sync() error {
-	return l.SugaredLogger.Sync()
+	var errs []error
+	for _, err := range multierr.Errors(l.SugaredLogger.Sync()) {
+		if !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTTY) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNew_TeesOutputsWithIndependentLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var text bytes.Buffer
+
+	log, err := New(Config{
+		Level:  "ERROR",
+		Format: "json",
+		Outputs: []Output{
+			{Path: path, Format: "json", Level: "DEBUG"},
+			{Writer: &text, Format: "text", Level: "WARNING"},
+		},
+	})
+	require.NoError(t, err)
+
+	log.Debugw("debug_event", "k", "v")
+	log.Warnw("warn_event")
+	require.NoError(t, log.Sync(), "unsyncable stderr is not an error")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "debug_event", entry["msg"])
+	assert.Equal(t, "v", entry["k"])
+	assert.Contains(t, entry, "timestamp")
+
+	// Text outputs that are not terminals are not colorized
+	assert.NotContains(t, text.String(), "debug_event")
+	assert.Contains(t, text.String(), "WARN")
+	assert.Contains(t, text.String(), "warn_event")
+	assert.NotContains(t, text.String(), "\x1b[")
+}
+
+func TestLogger_WithKeepsCounters(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []Output{{Writer: &buf, Format: "json", Level: "INFO"}},
+		Sampling:  &Sampling{Tick: time.Minute, Initial: 1, Thereafter: 100},
+	})
+	require.NoError(t, err)
+
+	child := log.With("request_id", "req-1")
+	child.Infow("repeated")
+	child.Infow("repeated")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.Split(buf.String(), "\n")[0]), &entry))
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, uint64(1), log.Suppressed(), "children count toward the parent's counters")
+	assert.Equal(t, log.Suppressed(), child.Suppressed())
+}
+
+func TestLogger_ZapSharesOutputsAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []Output{{Writer: &buf, Format: "json", Level: "INFO"}},
+		RedactPII: true,
+	})
+	require.NoError(t, err)
+
+	log.With("request_id", "req-1").Zap().Warn("user_created",
+		String("email", "jane@example.com"),
+		Int("status", 201),
+		Duration("took", 1500*time.Millisecond),
+		Err(errors.New("boom")),
+		Object("headers", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("Accept", "application/json")
+			return nil
+		})),
+	)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "user_created", entry["msg"])
+	assert.Equal(t, "req-1", entry["request_id"], "fields of With are kept")
+	assert.Equal(t, "j***@example.com", entry["email"], "typed fields are redacted too")
+	assert.EqualValues(t, 201, entry["status"])
+	assert.EqualValues(t, 1.5, entry["took"])
+	assert.Equal(t, "boom", entry["error"])
+	assert.Equal(t, map[string]any{"Accept": "application/json"}, entry["headers"])
+}
+
+func TestLogger_ZapSkipsDisabledLevelsWithoutAllocating(t *testing.T) {
+	log, err := New(Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	zl := log.Zap()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if ce := zl.Check(zapcore.InfoLevel, "http_request"); ce != nil {
+			ce.Write(String("method", "GET"), Int("status", 200))
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestLogger_ZapOfZeroValue(t *testing.T) {
+	assert.NotPanics(t, func() { (&Logger{}).Zap().Info("dropped") })
+}
+
+func TestNew_InvalidOutputPath(t *testing.T) {
+	_, err := New(Config{
+		Level:   "INFO",
+		Format:  "json",
+		Outputs: []Output{{Path: filepath.Join(t.TempDir(), "missing", "app.log")}},
+	})
+	assert.Error(t, err)
+}
+
+func TestNew_RedactPII(t *testing.T) {
+	type profile struct {
+		Email string `json:"email" pii:"email"`
+		Plan  string `json:"plan"`
+	}
+	var buf bytes.Buffer
+	log, err := New(Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []Output{{Writer: &buf, Format: "json", Level: "INFO"}},
+		RedactPII: true,
+	})
+	require.NoError(t, err)
+
+	log.With("email", "jane@example.com").Infow("user_created",
+		"profile", profile{Email: "jane@example.com", Plan: "pro"}, "id", 1)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "j***@example.com", entry["email"])
+	assert.Equal(t, map[string]any{"email": "j***@example.com", "plan": "pro"}, entry["profile"])
+	assert.EqualValues(t, 1, entry["id"])
+	assert.NotContains(t, buf.String(), "jane@")
+}
+
+// BenchmarkLogger compares an enabled entry logged with key-value pairs
+// and with typed fields.
+func BenchmarkLogger(b *testing.B) {
+	log, err := New(Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []Output{{Writer: io.Discard, Format: "json", Level: "INFO"}},
+	})
+	require.NoError(b, err)
+
+	b.Run("sugared", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			log.Infow("http_request", "method", "GET", "path", "/api/v1/users", "status", 200, "duration_ms", int64(3))
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		zl := log.Zap()
+		b.ReportAllocs()
+		for b.Loop() {
+			zl.Info("http_request", String("method", "GET"), String("path", "/api/v1/users"),
+				Int("status", 200), Int64("duration_ms", 3))
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"reflect"
+
+	"github.com/luminosita/change-me/pkg/pii"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactCore masks personal data in the fields of entries before they
+// reach a leaf core: string values logged under pii.Keys and the pii-tagged
+// fields of logged structs (see pkg/pii).
+type redactCore struct {
+	zapcore.Core
+}
+
+// newRedactCore wraps core with PII redaction.
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core}
+}
+
+// With implements zapcore.Core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+// Check implements zapcore.Core, adding c rather than the wrapped core so
+// entries are written through Write.
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields returns fields with personal data masked, copying the
+// slice only when a field changes.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	copied := false
+	for i, f := range fields {
+		redacted, ok := redactField(f)
+		if !ok {
+			continue
+		}
+		if !copied {
+			out = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		out[i] = redacted
+	}
+	return out
+}
+
+// redactField returns f masked and true when it carries personal data.
+func redactField(f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.StringType:
+		if kind, ok := pii.Keys[f.Key]; ok {
+			return zap.String(f.Key, pii.Mask(kind, f.String)), true
+		}
+	case zapcore.ReflectType:
+		if f.Interface != nil && pii.Contains(reflect.TypeOf(f.Interface)) {
+			return zap.Reflect(f.Key, pii.Masked(f.Interface)), true
+		}
+	}
+	return f, false
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultSamplingTick is the sampling window used when Sampling.Tick is unset.
+const defaultSamplingTick = time.Second
+
+// newSampler wraps core with zap's sampler, counting dropped entries.
+func newSampler(core zapcore.Core, s Sampling, suppressed *atomic.Uint64) zapcore.Core {
+	tick := s.Tick
+	if tick <= 0 {
+		tick = defaultSamplingTick
+	}
+	hook := zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped != 0 {
+			suppressed.Add(1)
+		}
+	})
+	return zapcore.NewSamplerWithOptions(core, tick, s.Initial, s.Thereafter, hook)
+}
+
+// Limiter rate-limits logging from a hot path to one entry per interval and
+// counts the entries it suppressed in between:
+//
+//	var dropLog = logger.Every(10 * time.Second)
+//
+//	if ok, skipped := dropLog.Allow(); ok {
+//		log.Warnw("event_dropped", "suppressed", skipped)
+//	}
+type Limiter struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	next       time.Time
+	suppressed int64
+	total      int64
+}
+
+// Every creates a limiter allowing one entry per interval.
+func Every(interval time.Duration) *Limiter {
+	return &Limiter{interval: interval}
+}
+
+// Allow reports whether an entry may be logged now. When it may, it also
+// returns how many entries were suppressed since the last allowed one.
+func (l *Limiter) Allow() (bool, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.next) {
+		l.suppressed++
+		l.total++
+		return false, 0
+	}
+
+	l.next = now.Add(l.interval)
+	skipped := l.suppressed
+	l.suppressed = 0
+	return true, skipped
+}
+
+// Suppressed returns the total number of entries suppressed by the limiter.
+func (l *Limiter) Suppressed() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.total
+}
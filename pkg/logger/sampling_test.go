@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSampler_DropsDuplicatesAndCounts(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	suppressed := new(atomic.Uint64)
+	log := &Logger{
+		SugaredLogger: zap.New(newSampler(core, Sampling{Tick: time.Minute, Initial: 2, Thereafter: 3}, suppressed)).Sugar(),
+		suppressed:    suppressed,
+	}
+
+	for range 8 {
+		log.Infow("hot_path")
+	}
+	log.Infow("other")
+
+	// 2 initial entries, then every 3rd of the remaining 6
+	assert.Equal(t, 1, logs.FilterMessage("other").Len())
+	assert.Equal(t, 4, logs.FilterMessage("hot_path").Len())
+	assert.Equal(t, uint64(4), log.Suppressed())
+}
+
+func TestNew_Sampling(t *testing.T) {
+	log, err := New(Config{Level: "INFO", Format: "json", Sampling: &Sampling{Initial: 1}})
+	require.NoError(t, err)
+
+	log.Debugw("below_level")
+	for range 3 {
+		log.Infow("repeated")
+	}
+	assert.Equal(t, uint64(2), log.Suppressed())
+
+	var zero Logger
+	assert.Zero(t, zero.Suppressed())
+}
+
+func TestLimiter(t *testing.T) {
+	l := Every(20 * time.Millisecond)
+
+	ok, skipped := l.Allow()
+	assert.True(t, ok)
+	assert.Zero(t, skipped)
+
+	for range 3 {
+		ok, _ = l.Allow()
+		assert.False(t, ok)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	ok, skipped = l.Allow()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), skipped)
+	assert.Equal(t, int64(3), l.Suppressed())
+}
@@ -0,0 +1,360 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Defaults applied to zero-valued Batch fields.
+const (
+	defaultBatchSize     = 500
+	defaultBatchInterval = 2 * time.Second
+	defaultQueueSize     = 10000
+	defaultShipTimeout   = 10 * time.Second
+	shipSyncTimeout      = 5 * time.Second
+	maxShipBackoff       = 5 * time.Second
+)
+
+// Batch configures how shipping outputs buffer and deliver entries. Entries
+// are queued without blocking the caller; when the queue is full new entries
+// are dropped and counted.
+type Batch struct {
+	Size      int           // Entries per push (default 500)
+	Interval  time.Duration // Longest time an entry waits for a push (default 2s)
+	QueueSize int           // Entries buffered while pushes are pending (default 10000)
+	Retries   int           // Extra attempts for failed pushes, with exponential backoff
+	Timeout   time.Duration // Per-push request timeout (default 10s)
+}
+
+// shipEntry is an encoded entry waiting to be pushed.
+type shipEntry struct {
+	time  time.Time
+	level zapcore.Level
+	line  []byte
+}
+
+// pushFunc delivers one batch.
+type pushFunc func(ctx context.Context, entries []shipEntry) error
+
+// permanentError marks push failures that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// shippingCore encodes entries as JSON and hands them to a shipper.
+type shippingCore struct {
+	zapcore.LevelEnabler
+	enc     zapcore.Encoder
+	shipper *shipper
+}
+
+func newShippingCore(out Output, level zapcore.LevelEnabler, dropped *atomic.Uint64) (zapcore.Core, error) {
+	if out.Address == "" {
+		return nil, fmt.Errorf("%s output requires an address", out.Type)
+	}
+	client := &http.Client{}
+	base := strings.TrimRight(out.Address, "/")
+
+	encCfg := zap.NewProductionEncoderConfig()
+	encCfg.TimeKey = "timestamp"
+	encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var push pushFunc
+	switch out.Type {
+	case OutputLoki:
+		labels := map[string]string{}
+		if out.AppName != "" {
+			labels["service"] = out.AppName
+		}
+		for k, v := range out.Labels {
+			labels[k] = v
+		}
+		push = lokiPush(client, base+"/loki/api/v1/push", out.Headers, labels)
+	case OutputElasticsearch:
+		if out.Index == "" {
+			return nil, errors.New("elasticsearch output requires an index")
+		}
+		encCfg.TimeKey = "@timestamp"
+		encCfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		push = elasticsearchPush(client, base+"/_bulk", out.Headers, out.Index)
+	}
+
+	return &shippingCore{
+		LevelEnabler: level,
+		enc:          zapcore.NewJSONEncoder(encCfg),
+		shipper:      newShipper(out.Type, push, out.Batch, dropped),
+	}, nil
+}
+
+func (c *shippingCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &shippingCore{LevelEnabler: c.LevelEnabler, enc: enc, shipper: c.shipper}
+}
+
+func (c *shippingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *shippingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	line := bytes.TrimRight(buf.Bytes(), "\n")
+	c.shipper.enqueue(shipEntry{time: ent.Time, level: ent.Level, line: append([]byte(nil), line...)})
+	buf.Free()
+	return nil
+}
+
+// Sync waits until queued entries have been pushed.
+func (c *shippingCore) Sync() error {
+	return c.shipper.flush(shipSyncTimeout)
+}
+
+// shipper batches queued entries and pushes them from a single goroutine.
+type shipper struct {
+	name    string
+	push    pushFunc
+	batch   Batch
+	queue   chan shipEntry
+	flushes chan chan struct{}
+	dropped *atomic.Uint64
+	errLog  *Limiter
+}
+
+func newShipper(name string, push pushFunc, batch Batch, dropped *atomic.Uint64) *shipper {
+	if batch.Size <= 0 {
+		batch.Size = defaultBatchSize
+	}
+	if batch.Interval <= 0 {
+		batch.Interval = defaultBatchInterval
+	}
+	if batch.QueueSize <= 0 {
+		batch.QueueSize = defaultQueueSize
+	}
+	if batch.Timeout <= 0 {
+		batch.Timeout = defaultShipTimeout
+	}
+
+	s := &shipper{
+		name:    name,
+		push:    push,
+		batch:   batch,
+		queue:   make(chan shipEntry, batch.QueueSize),
+		flushes: make(chan chan struct{}),
+		dropped: dropped,
+		errLog:  Every(time.Minute),
+	}
+	go s.run()
+	return s
+}
+
+// enqueue adds an entry without blocking, dropping it when the queue is full.
+func (s *shipper) enqueue(e shipEntry) {
+	select {
+	case s.queue <- e:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// flush pushes everything queued so far, waiting at most timeout.
+func (s *shipper) flush(timeout time.Duration) error {
+	done := make(chan struct{})
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case s.flushes <- done:
+	case <-timer.C:
+		return fmt.Errorf("%s log shipping: flush timed out", s.name)
+	}
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%s log shipping: flush timed out", s.name)
+	}
+}
+
+func (s *shipper) run() {
+	ticker := time.NewTicker(s.batch.Interval)
+	defer ticker.Stop()
+
+	pending := make([]shipEntry, 0, s.batch.Size)
+	for {
+		select {
+		case e := <-s.queue:
+			pending = append(pending, e)
+			if len(pending) >= s.batch.Size {
+				s.send(pending)
+				pending = pending[:0]
+			}
+		case <-ticker.C:
+			if len(pending) > 0 {
+				s.send(pending)
+				pending = pending[:0]
+			}
+		case done := <-s.flushes:
+			for drained := false; !drained; {
+				select {
+				case e := <-s.queue:
+					pending = append(pending, e)
+					if len(pending) >= s.batch.Size {
+						s.send(pending)
+						pending = pending[:0]
+					}
+				default:
+					drained = true
+				}
+			}
+			if len(pending) > 0 {
+				s.send(pending)
+				pending = pending[:0]
+			}
+			close(done)
+		}
+	}
+}
+
+// send pushes a batch, retrying transient failures. Undeliverable entries
+// are counted as dropped.
+func (s *shipper) send(entries []shipEntry) {
+	backoff := 100 * time.Millisecond
+	var err error
+	for attempt := 0; attempt <= s.batch.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff = min(backoff*2, maxShipBackoff)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.batch.Timeout)
+		err = s.push(ctx, entries)
+		cancel()
+
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) {
+			break
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	s.dropped.Add(uint64(len(entries)))
+	// The logger cannot log its own delivery failures; report them on stderr
+	if ok, skipped := s.errLog.Allow(); ok {
+		fmt.Fprintf(os.Stderr, "%s log shipping failed, dropped %d entries (%d failures suppressed): %v\n",
+			s.name, len(entries), skipped, err)
+	}
+}
+
+// lokiPush returns a push function for the Loki HTTP push API, with one
+// stream per level.
+func lokiPush(client *http.Client, url string, headers, labels map[string]string) pushFunc {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	return func(ctx context.Context, entries []shipEntry) error {
+		byLevel := map[zapcore.Level]*stream{}
+		var streams []*stream
+		for _, e := range entries {
+			st, ok := byLevel[e.level]
+			if !ok {
+				st = &stream{Stream: map[string]string{"level": e.level.String()}}
+				for k, v := range labels {
+					st.Stream[k] = v
+				}
+				byLevel[e.level] = st
+				streams = append(streams, st)
+			}
+			st.Values = append(st.Values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), string(e.line)})
+		}
+
+		body, err := json.Marshal(map[string]any{"streams": streams})
+		if err != nil {
+			return &permanentError{err}
+		}
+		_, err = post(ctx, client, url, "application/json", headers, body)
+		return err
+	}
+}
+
+// elasticsearchPush returns a push function for the Elasticsearch bulk API.
+// Batches with rejected documents are not retried, to avoid duplicates.
+func elasticsearchPush(client *http.Client, url string, headers map[string]string, index string) pushFunc {
+	action, _ := json.Marshal(map[string]any{"create": map[string]string{"_index": index}})
+
+	return func(ctx context.Context, entries []shipEntry) error {
+		var body bytes.Buffer
+		for _, e := range entries {
+			body.Write(action)
+			body.WriteByte('\n')
+			body.Write(e.line)
+			body.WriteByte('\n')
+		}
+
+		resp, err := post(ctx, client, url, "application/x-ndjson", headers, body.Bytes())
+		if err != nil {
+			return err
+		}
+		var result struct {
+			Errors bool `json:"errors"`
+		}
+		if err := json.Unmarshal(resp, &result); err == nil && result.Errors {
+			return &permanentError{errors.New("elasticsearch rejected documents in bulk request")}
+		}
+		return nil
+	}
+}
+
+// post sends body and returns the response body. 4xx responses other than
+// 429 are permanent failures.
+func post(ctx context.Context, client *http.Client, url, contentType string, headers map[string]string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, &permanentError{err}
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	switch {
+	case resp.StatusCode < 300:
+		return data, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return nil, &permanentError{fmt.Errorf("push rejected: %s", resp.Status)}
+	default:
+		return nil, fmt.Errorf("push failed: %s", resp.Status)
+	}
+}
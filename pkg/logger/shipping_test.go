@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShippingLogger(t *testing.T, out Output) *Logger {
+	t.Helper()
+	log, err := New(Config{Level: "DEBUG", Format: "json", NoConsole: true, Outputs: []Output{out}})
+	require.NoError(t, err)
+	return log
+}
+
+func TestLokiOutput(t *testing.T) {
+	type push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	var (
+		mu     sync.Mutex
+		pushes []push
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var p push
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		mu.Lock()
+		pushes = append(pushes, p)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	log := newShippingLogger(t, Output{
+		Type:    OutputLoki,
+		Address: srv.URL + "/",
+		AppName: "change-me",
+		Labels:  map[string]string{"env": "test"},
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Level:   "INFO",
+	})
+
+	log.Debugw("filtered")
+	log.Infow("first", "k", 1)
+	log.Errorw("second")
+	_ = log.Sync()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, pushes, 1)
+	require.Len(t, pushes[0].Streams, 2)
+	info := pushes[0].Streams[0]
+	assert.Equal(t, map[string]string{"service": "change-me", "env": "test", "level": "info"}, info.Stream)
+	require.Len(t, info.Values, 1)
+	assert.Contains(t, info.Values[0][1], `"msg":"first"`)
+	assert.Contains(t, info.Values[0][1], `"k":1`)
+	assert.Equal(t, "error", pushes[0].Streams[1].Stream["level"])
+	assert.Zero(t, log.Dropped())
+}
+
+func TestElasticsearchOutput(t *testing.T) {
+	var lines [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, bytes.Clone(scanner.Bytes()))
+		}
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer srv.Close()
+
+	log := newShippingLogger(t, Output{Type: OutputElasticsearch, Address: srv.URL, Index: "logs-app"})
+	log.With("svc", "api").Warnw("slow", "ms", 1200)
+	_ = log.Sync()
+
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"create":{"_index":"logs-app"}}`, string(lines[0]))
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(lines[1], &doc))
+	assert.Equal(t, "slow", doc["msg"])
+	assert.Equal(t, "api", doc["svc"])
+	assert.Contains(t, doc, "@timestamp")
+}
+
+func TestShippingOutput_RetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	log := newShippingLogger(t, Output{Type: OutputLoki, Address: srv.URL, Batch: Batch{Retries: 2}})
+	log.Infow("event")
+	_ = log.Sync()
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Zero(t, log.Dropped())
+}
+
+func TestShippingOutput_CountsRejectedAndOverflowingEntries(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	log := newShippingLogger(t, Output{
+		Type:    OutputLoki,
+		Address: srv.URL,
+		Batch:   Batch{Size: 1, QueueSize: 2, Retries: 3},
+	})
+
+	// The first entry blocks in a push; two more fill the queue
+	log.Infow("blocked")
+	require.Eventually(t, func() bool {
+		log.Infow("queued")
+		return log.Dropped() > 0
+	}, time.Second, time.Millisecond)
+	overflow := log.Dropped()
+
+	close(release)
+	_ = log.Sync()
+
+	// Rejected pushes are not retried; every pushed entry is dropped
+	assert.Equal(t, overflow+3, log.Dropped())
+}
+
+func TestShippingOutput_RequiresAddressAndIndex(t *testing.T) {
+	_, err := New(Config{Level: "INFO", Outputs: []Output{{Type: OutputLoki}}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Level: "INFO", Outputs: []Output{{Type: OutputElasticsearch, Address: "http://localhost:9200"}}})
+	assert.Error(t, err)
+}
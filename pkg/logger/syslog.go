@@ -0,0 +1,203 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogFacilities maps facility names to RFC 5424 facility codes.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// localSyslogSockets are probed when no syslog address is configured.
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogSeverity maps zap levels to RFC 5424 severities.
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return 7
+	case level == zapcore.InfoLevel:
+		return 6
+	case level == zapcore.WarnLevel:
+		return 4
+	case level == zapcore.ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// syslogCore writes entries as RFC 5424 messages.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc    zapcore.Encoder
+	writer *syslogWriter
+}
+
+func newSyslogCore(out Output, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	facility := syslogFacilities["local0"]
+	if out.Facility != "" {
+		code, ok := syslogFacilities[strings.ToLower(out.Facility)]
+		if !ok {
+			return nil, fmt.Errorf("unknown syslog facility %q", out.Facility)
+		}
+		facility = code
+	}
+
+	w := &syslogWriter{
+		network:  out.Network,
+		address:  out.Address,
+		facility: facility,
+		appName:  syslogToken(out.AppName, 48),
+		hostname: syslogToken(hostname(), 255),
+		procID:   strconv.Itoa(os.Getpid()),
+	}
+	if err := w.connect(); err != nil {
+		return nil, fmt.Errorf("connect to syslog: %w", err)
+	}
+
+	return &syslogCore{LevelEnabler: level, enc: newEncoder(out.Format, false), writer: w}, nil
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, enc: enc, writer: c.writer}
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	return c.writer.write(syslogSeverity(ent.Level), ent.Time, strings.TrimRight(buf.String(), "\n"))
+}
+
+func (c *syslogCore) Sync() error { return nil }
+
+// syslogWriter sends messages over a (re)connected socket. Stream
+// connections use octet-counting framing (RFC 6587).
+type syslogWriter struct {
+	network, address string
+	facility         int
+	appName          string
+	hostname         string
+	procID           string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// connect dials the configured address, or the local syslog socket.
+func (w *syslogWriter) connect() error {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	if w.address != "" {
+		network := w.network
+		if network == "" {
+			network = "udp"
+		}
+		conn, err := net.DialTimeout(network, w.address, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		return nil
+	}
+
+	for _, path := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				w.conn = conn
+				return nil
+			}
+		}
+	}
+	return errors.New("no local syslog socket found")
+}
+
+func (w *syslogWriter) write(severity int, t time.Time, msg string) error {
+	line := fmt.Sprintf("<%d>1 %s %s %s %s - - %s",
+		w.facility*8+severity, t.UTC().Format(time.RFC3339Nano), w.hostname, w.appName, w.procID, msg)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// One reconnect attempt covers restarted syslog daemons
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				continue
+			}
+		}
+		if _, err = w.conn.Write(w.frame(line)); err == nil {
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+// frame adds octet-counting framing on TCP and newline termination on
+// local stream sockets; datagrams carry one message each.
+func (w *syslogWriter) frame(line string) []byte {
+	switch w.conn.LocalAddr().Network() {
+	case "tcp", "tcp4", "tcp6":
+		return []byte(strconv.Itoa(len(line)) + " " + line)
+	case "unix":
+		return []byte(line + "\n")
+	default:
+		return []byte(line)
+	}
+}
+
+// syslogToken returns s as a printable header token of at most limit
+// bytes, or the NILVALUE "-".
+func syslogToken(s string, limit int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > limit {
+		s = s[:limit]
+	}
+	return s
+}
+
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
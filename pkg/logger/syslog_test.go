@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc5424Pattern matches the header written by syslogWriter.
+var rfc5424Pattern = regexp.MustCompile(`(?s)^<(\d+)>1 \S+ \S+ (\S+) \d+ - - (.*)$`)
+
+func TestSyslogOutput_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	log, err := New(Config{Level: "INFO", Format: "json", NoConsole: true, Outputs: []Output{{
+		Type:     OutputSyslog,
+		Format:   "json",
+		Level:    "INFO",
+		Address:  conn.LocalAddr().String(),
+		Facility: "local3",
+		AppName:  "my app",
+	}}})
+	require.NoError(t, err)
+
+	log.With("service", "users").Warnw("disk_low", "free_mb", 12)
+
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	m := rfc5424Pattern.FindStringSubmatch(string(buf[:n]))
+	require.NotNil(t, m, string(buf[:n]))
+	assert.Equal(t, strconv.Itoa(19*8+4), m[1])
+	assert.Equal(t, "myapp", m[2])
+	assert.Contains(t, m[3], `"msg":"disk_low"`)
+	assert.Contains(t, m[3], `"service":"users"`)
+	assert.Contains(t, m[3], `"free_mb":12`)
+}
+
+func TestSyslogOutput_TCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		size, _ := r.ReadString(' ')
+		n, _ := strconv.Atoi(strings.TrimSpace(size))
+		msg := make([]byte, n)
+		_, _ = io.ReadFull(r, msg)
+		received <- string(msg)
+	}()
+
+	log, err := New(Config{Level: "INFO", Format: "json", NoConsole: true, Outputs: []Output{{
+		Type:    OutputSyslog,
+		Network: "tcp",
+		Address: ln.Addr().String(),
+	}}})
+	require.NoError(t, err)
+
+	log.Errorw("boom")
+
+	msg := <-received
+	m := rfc5424Pattern.FindStringSubmatch(msg)
+	require.NotNil(t, m, msg)
+	assert.Equal(t, strconv.Itoa(16*8+3), m[1])
+	assert.Equal(t, "-", m[2])
+}
+
+func TestSyslogOutput_UnknownFacility(t *testing.T) {
+	_, err := New(Config{Level: "INFO", Outputs: []Output{{Type: OutputSyslog, Address: "127.0.0.1:514", Facility: "local9"}}})
+	assert.Error(t, err)
+}
+
+func TestJournaldOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	log, err := New(Config{Level: "INFO", NoConsole: true, Outputs: []Output{{
+		Type:    OutputJournald,
+		Address: path,
+		AppName: "change-me",
+	}}})
+	require.NoError(t, err)
+
+	log.With("user_id", 7).Infow("user_created", "note", "line1\nline2", "tags", []string{"a", "b"})
+
+	buf := make([]byte, 8192)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	fields := parseJournal(t, buf[:n])
+
+	assert.Equal(t, "user_created", fields["MESSAGE"])
+	assert.Equal(t, "6", fields["PRIORITY"])
+	assert.Equal(t, "change-me", fields["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "7", fields["USER_ID"])
+	assert.Equal(t, "line1\nline2", fields["NOTE"])
+	assert.Equal(t, `["a","b"]`, fields["TAGS"])
+}
+
+// parseJournal decodes a native journald protocol datagram.
+func parseJournal(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := strings.IndexAny(string(data), "=\n")
+		require.GreaterOrEqual(t, i, 0)
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := strings.IndexByte(string(data[i+1:]), '\n')
+			fields[name] = string(data[i+1 : i+1+end])
+			data = data[i+2+end:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[i+1 : i+9])
+		fields[name] = string(data[i+9 : i+9+int(size)])
+		data = data[i+10+int(size):]
+	}
+	return fields
+}
@@ -0,0 +1,139 @@
+package mockserver
+
+import (
+	"math"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxDepth bounds the nesting of generated values so recursive schemas
+// terminate.
+const maxDepth = 8
+
+// Example returns a value valid against ref: its example, default or
+// first enum value when declared, and a value generated from its type and
+// constraints otherwise. Objects get all their properties and arrays their
+// minimum number of items, at least one.
+func Example(ref *openapi3.SchemaRef) any {
+	return example(ref, 0)
+}
+
+func example(ref *openapi3.SchemaRef, depth int) any {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	s := ref.Value
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		merged := map[string]any{}
+		for _, part := range s.AllOf {
+			if obj, ok := example(part, depth).(map[string]any); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	case len(s.OneOf) > 0:
+		return example(s.OneOf[0], depth)
+	case len(s.AnyOf) > 0:
+		return example(s.AnyOf[0], depth)
+	}
+
+	switch {
+	case s.Type.Is("string"):
+		return exampleString(s)
+	case s.Type.Is("integer"):
+		return int64(exampleNumber(s, 1))
+	case s.Type.Is("number"):
+		return exampleNumber(s, 1.5)
+	case s.Type.Is("boolean"):
+		return true
+	case s.Type.Is("array"):
+		if depth >= maxDepth {
+			return []any{}
+		}
+		n := int(s.MinItems)
+		if n == 0 {
+			n = 1
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i] = example(s.Items, depth+1)
+		}
+		return items
+	case s.Type.Is("object") || s.Type == nil:
+		obj := map[string]any{}
+		if depth >= maxDepth {
+			return obj
+		}
+		for name, prop := range s.Properties {
+			obj[name] = example(prop, depth+1)
+		}
+		if ap := s.AdditionalProperties.Schema; ap != nil && len(obj) == 0 {
+			obj["key"] = example(ap, depth+1)
+		}
+		return obj
+	default:
+		return nil
+	}
+}
+
+// formatExamples are the generated values of string formats.
+var formatExamples = map[string]string{
+	"email":     "user@example.com",
+	"date-time": "2024-01-15T10:30:00Z",
+	"date":      "2024-01-15",
+	"time":      "10:30:00",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "ZXhhbXBsZQ==",
+	"password":  "********",
+}
+
+// exampleString returns a string of the format and length bounds of s.
+func exampleString(s *openapi3.Schema) string {
+	v, ok := formatExamples[s.Format]
+	if !ok {
+		v = "string"
+	}
+	if n := int(s.MinLength); len(v) < n {
+		v += strings.Repeat("x", n-len(v))
+	}
+	if s.MaxLength != nil && uint64(len(v)) > *s.MaxLength {
+		v = v[:*s.MaxLength]
+	}
+	return v
+}
+
+// exampleNumber returns fallback bounded by the minimum and maximum of s.
+func exampleNumber(s *openapi3.Schema, fallback float64) float64 {
+	v := fallback
+	if s.Min != nil && v < *s.Min {
+		v = *s.Min
+		if s.ExclusiveMin {
+			v++
+		}
+	}
+	if s.Max != nil && v > *s.Max {
+		v = *s.Max
+		if s.ExclusiveMax {
+			v--
+		}
+	}
+	if s.Type.Is("integer") {
+		v = math.Ceil(v)
+	}
+	return v
+}
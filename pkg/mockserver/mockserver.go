@@ -0,0 +1,292 @@
+// Package mockserver serves example responses derived from an OpenAPI 3
+// document, so clients can be developed against an API before its
+// handlers exist.
+//
+// Each operation answers with its lowest 2xx response, using the examples
+// of the document when declared and values generated from the schemas
+// otherwise. Requests are validated against the contract first. Latency
+// and errors can be injected to exercise client timeouts and retries, and
+// a request can select another declared response with a Prefer header:
+//
+//	Prefer: code=404
+//	Prefer: example=inactive
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// Error codes of the bodies written by the mock server itself.
+const (
+	ErrorCode        = "mock_error"           // Injected errors and undeclared responses
+	InvalidErrorCode = "mock_request_invalid" // Requests the contract rejects
+)
+
+// Options configures a mock server.
+type Options struct {
+	Latency time.Duration // Added to every response
+	Jitter  time.Duration // Random extra latency up to this value
+
+	// ErrorRate is the fraction of requests answered with ErrorStatus,
+	// from 0 to 1
+	ErrorRate   float64
+	ErrorStatus int // Status of injected errors (default 500)
+
+	// SkipValidation serves requests that do not match the contract
+	SkipValidation bool
+}
+
+// Server serves the operations of an OpenAPI document.
+type Server struct {
+	router routers.Router
+	opts   Options
+	random func() float64
+}
+
+// New parses the OpenAPI document spec (YAML or JSON) and returns a mock
+// server of its operations.
+func New(spec []byte, opts Options) (*Server, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("build OpenAPI router: %w", err)
+	}
+	if opts.ErrorRate < 0 || opts.ErrorRate > 1 {
+		return nil, fmt.Errorf("error rate %v not between 0 and 1", opts.ErrorRate)
+	}
+	if opts.ErrorStatus == 0 {
+		opts.ErrorStatus = http.StatusInternalServerError
+	}
+	return &Server{router: router, opts: opts, random: rand.Float64}, nil
+}
+
+// ServeHTTP answers r with an example response of its operation.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, pathParams, err := s.router.FindRoute(r)
+	// The router returns new RouteErrors, so they are matched by reason
+	var routeErr *routers.RouteError
+	switch {
+	case errors.As(err, &routeErr) && routeErr.Reason == routers.ErrMethodNotAllowed.Error():
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	case err != nil:
+		writeError(w, http.StatusNotFound, "not_found", "no operation matches "+r.Method+" "+r.URL.Path)
+		return
+	}
+
+	if !s.opts.SkipValidation {
+		err := openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		})
+		if err != nil {
+			writeError(w, http.StatusBadRequest, InvalidErrorCode, err.Error())
+			return
+		}
+	}
+
+	if !s.delay(r.Context()) {
+		return
+	}
+
+	prefer := parsePrefer(r.Header.Get("Prefer"))
+	code := prefer["code"]
+	if code == "" && s.opts.ErrorRate > 0 && s.random() < s.opts.ErrorRate {
+		code = strconv.Itoa(s.opts.ErrorStatus)
+		if route.Operation.Responses.Value(code) == nil {
+			writeError(w, s.opts.ErrorStatus, ErrorCode, "injected mock error")
+			return
+		}
+	}
+
+	status, resp, err := selectResponse(route.Operation, code)
+	if err != nil {
+		writeError(w, http.StatusNotImplemented, ErrorCode, err.Error())
+		return
+	}
+	writeResponse(w, status, resp, prefer["example"])
+}
+
+// delay waits for the configured latency, reporting false when the
+// request was canceled first.
+func (s *Server) delay(ctx context.Context) bool {
+	d := s.opts.Latency
+	if s.opts.Jitter > 0 {
+		d += time.Duration(s.random() * float64(s.opts.Jitter))
+	}
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// selectResponse returns the response of op declared for code, or its
+// lowest 2xx response when code is empty.
+func selectResponse(op *openapi3.Operation, code string) (int, *openapi3.Response, error) {
+	responses := op.Responses.Map()
+	if code == "" {
+		codes := make([]string, 0, len(responses))
+		for c := range responses {
+			if len(c) == 3 && c[0] == '2' {
+				codes = append(codes, c)
+			}
+		}
+		if len(codes) == 0 {
+			return 0, nil, fmt.Errorf("operation %s declares no success response", op.OperationID)
+		}
+		sort.Strings(codes)
+		code = codes[0]
+	}
+
+	ref := responses[code]
+	if ref == nil || ref.Value == nil {
+		return 0, nil, fmt.Errorf("operation %s declares no %s response", op.OperationID, code)
+	}
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 100 || status > 599 {
+		return 0, nil, fmt.Errorf("response %s is not a status code", code)
+	}
+	return status, ref.Value, nil
+}
+
+// writeResponse writes status and an example of resp, the named one when
+// example is set and declared.
+func writeResponse(w http.ResponseWriter, status int, resp *openapi3.Response, example string) {
+	mediaType, content := selectContent(resp.Content)
+	if content == nil || status == http.StatusNoContent || status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+
+	value := pickExample(content, example)
+	w.Header().Set("Content-Type", mediaType)
+	if isJSON(mediaType) {
+		body, err := json.Marshal(value)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, ErrorCode, err.Error())
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+		return
+	}
+	w.WriteHeader(status)
+	if s, ok := value.(string); ok {
+		_, _ = w.Write([]byte(s))
+	}
+}
+
+// selectContent returns the JSON media type of content, or the first
+// other one.
+func selectContent(content openapi3.Content) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	types := make([]string, 0, len(content))
+	for mt := range content {
+		if isJSON(mt) {
+			return mt, content[mt]
+		}
+		types = append(types, mt)
+	}
+	sort.Strings(types)
+	return types[0], content[types[0]]
+}
+
+// pickExample returns the named example of content, its example, or one
+// generated from its schema.
+func pickExample(content *openapi3.MediaType, name string) any {
+	if name != "" {
+		if ex := content.Examples[name]; ex != nil && ex.Value != nil {
+			return ex.Value.Value
+		}
+	}
+	if content.Example != nil {
+		return content.Example
+	}
+	names := make([]string, 0, len(content.Examples))
+	for n := range content.Examples {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if ex := content.Examples[n]; ex != nil && ex.Value != nil {
+			return ex.Value.Value
+		}
+	}
+	return Example(content.Schema)
+}
+
+// parsePrefer returns the key=value preferences of a Prefer header.
+func parsePrefer(header string) map[string]string {
+	prefs := make(map[string]string)
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		prefs[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return prefs
+}
+
+// isJSON reports whether mediaType is a JSON media type.
+func isJSON(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// writeError writes an API error body.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error":   kindOf(status),
+		"code":    code,
+		"message": message,
+	})
+}
+
+// kindOf returns the error category of status.
+func kindOf(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusMethodNotAllowed:
+		return "method_not_allowed"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
@@ -0,0 +1,242 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/luminosita/change-me/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info: {title: test, version: "1"}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, minimum: 1, maximum: 100}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  users: {type: array, items: {$ref: "#/components/schemas/User"}}
+                  total: {type: integer, minimum: 0}
+    post:
+      operationId: createUser
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/User"}
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              examples:
+                active: {value: {id: "u1", email: "a@example.com", status: active}}
+                inactive: {value: {id: "u2", email: "b@example.com", status: inactive}}
+        "409":
+          description: conflict
+          content:
+            application/json:
+              example: {error: conflict, code: user_exists, message: exists}
+  /users/{id}:
+    delete:
+      operationId: deleteUser
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "204": {description: deleted}
+        "500":
+          description: failed
+          content:
+            application/json:
+              example: {error: internal, code: declared_failure, message: failed}
+components:
+  schemas:
+    User:
+      type: object
+      required: [email]
+      properties:
+        id: {type: string, format: uuid}
+        email: {type: string, format: email}
+        status: {type: string, enum: [active, inactive]}
+        created_at: {type: string, format: date-time}
+        manager: {$ref: "#/components/schemas/User"}
+`
+
+func TestServer_GeneratesResponsesFromSchemas(t *testing.T) {
+	s := newTestServer(t, Options{})
+
+	w := do(s, http.MethodGet, "/users?limit=5", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body struct {
+		Users []map[string]any `json:"users"`
+		Total int              `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Users, 1)
+	assert.Equal(t, 1, body.Total)
+	assert.Equal(t, "user@example.com", body.Users[0]["email"])
+	assert.Equal(t, "active", body.Users[0]["status"])
+	assert.Equal(t, "2024-01-15T10:30:00Z", body.Users[0]["created_at"])
+	assert.Contains(t, body.Users[0], "manager", "recursive schemas are generated up to a depth")
+}
+
+func TestServer_UsesDeclaredExamples(t *testing.T) {
+	s := newTestServer(t, Options{})
+	user := `{"email":"new@example.com"}`
+
+	w := do(s, http.MethodPost, "/users", user, nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id":"u1","email":"a@example.com","status":"active"}`, w.Body.String())
+
+	w = do(s, http.MethodPost, "/users", user, http.Header{"Prefer": {"example=inactive"}})
+	assert.JSONEq(t, `{"id":"u2","email":"b@example.com","status":"inactive"}`, w.Body.String())
+
+	w = do(s, http.MethodPost, "/users", user, http.Header{"Prefer": {"code=409"}})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "user_exists")
+
+	w = do(s, http.MethodPost, "/users", user, http.Header{"Prefer": {"code=418"}})
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Contains(t, w.Body.String(), ErrorCode)
+}
+
+func TestServer_NoContent(t *testing.T) {
+	s := newTestServer(t, Options{})
+
+	w := do(s, http.MethodDelete, "/users/u1", "", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestServer_ValidatesRequests(t *testing.T) {
+	s := newTestServer(t, Options{})
+
+	w := do(s, http.MethodGet, "/users?limit=500", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), InvalidErrorCode)
+
+	w = do(s, http.MethodPost, "/users", `{"id":"u1"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "required property missing")
+
+	w = do(s, http.MethodGet, "/missing", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(s, http.MethodPut, "/users", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	s = newTestServer(t, Options{SkipValidation: true})
+	w = do(s, http.MethodGet, "/users?limit=500", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServer_InjectsErrors(t *testing.T) {
+	s := newTestServer(t, Options{ErrorRate: 0.5})
+	s.random = func() float64 { return 0.25 }
+
+	w := do(s, http.MethodGet, "/users", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), ErrorCode, "undeclared error statuses get a generic body")
+
+	w = do(s, http.MethodDelete, "/users/u1", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "declared_failure", "declared error responses are served")
+
+	s.random = func() float64 { return 0.75 }
+	w = do(s, http.MethodGet, "/users", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	s = newTestServer(t, Options{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable})
+	w = do(s, http.MethodGet, "/users", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestServer_InjectsLatency(t *testing.T) {
+	s := newTestServer(t, Options{Latency: 20 * time.Millisecond, Jitter: 20 * time.Millisecond})
+	s.random = func() float64 { return 0.5 }
+
+	start := time.Now()
+	w := do(s, http.MethodGet, "/users", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	// A canceled request stops waiting and gets no response
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s = newTestServer(t, Options{Latency: time.Hour})
+	req := httptest.NewRequest(http.MethodGet, "/users", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestNew_RejectsInvalidInput(t *testing.T) {
+	_, err := New([]byte("not: [openapi"), Options{})
+	assert.Error(t, err)
+
+	_, err = New([]byte(testSpec), Options{ErrorRate: 2})
+	assert.Error(t, err)
+}
+
+func TestNew_ServesEmbeddedContract(t *testing.T) {
+	s, err := New(api.OpenAPISpec, Options{SkipValidation: true})
+	require.NoError(t, err)
+
+	w := do(s, http.MethodGet, "/health", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, json.Valid(w.Body.Bytes()))
+}
+
+func TestExample_Constraints(t *testing.T) {
+	min, maxLen := 10.0, uint64(3)
+	schema := openapi3.NewObjectSchema().
+		WithProperty("count", &openapi3.Schema{Type: &openapi3.Types{"integer"}, Min: &min}).
+		WithProperty("code", &openapi3.Schema{Type: &openapi3.Types{"string"}, MaxLength: &maxLen}).
+		WithProperty("tags", &openapi3.Schema{Type: &openapi3.Types{"array"}, MinItems: 2, Items: openapi3.NewStringSchema().NewRef()}).
+		WithProperty("ratio", openapi3.NewFloat64Schema().WithDefault(0.5))
+
+	assert.Equal(t, map[string]any{
+		"count": int64(10),
+		"code":  "str",
+		"tags":  []any{"string", "string"},
+		"ratio": 0.5,
+	}, Example(schema.NewRef()))
+}
+
+func newTestServer(t *testing.T, opts Options) *Server {
+	t.Helper()
+	s, err := New([]byte(testSpec), opts)
+	require.NoError(t, err)
+	return s
+}
+
+func do(h http.Handler, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
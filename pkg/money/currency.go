@@ -0,0 +1,55 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code, e.g. "USD".
+type Currency string
+
+// Common currencies.
+const (
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	JPY Currency = "JPY"
+)
+
+// minorDigits holds the supported currencies with their ISO 4217 minor
+// units: the digits after the decimal separator.
+var minorDigits = map[Currency]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2,
+	"CHF": 2, "CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2,
+	"EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2,
+	"ISK": 0, "JOD": 3, "JPY": 0, "KES": 2, "KRW": 0, "KWD": 3, "MXN": 2,
+	"MYR": 2, "NGN": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PEN": 2, "PHP": 2,
+	"PLN": 2, "QAR": 2, "RON": 2, "RSD": 2, "SAR": 2, "SEK": 2, "SGD": 2,
+	"THB": 2, "TND": 3, "TRY": 2, "TWD": 2, "UAH": 2, "UGX": 0, "USD": 2,
+	"VND": 0, "XAF": 0, "XOF": 0, "ZAR": 2,
+}
+
+// ParseCurrency returns the supported currency of code, case-insensitive.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+// Valid reports whether c is a supported currency.
+func (c Currency) Valid() bool {
+	_, ok := minorDigits[c]
+	return ok
+}
+
+// Digits returns the minor unit digits of c, e.g. 2 for USD and 0 for JPY.
+func (c Currency) Digits() int {
+	return minorDigits[c]
+}
+
+// String implements fmt.Stringer.
+func (c Currency) String() string {
+	return string(c)
+}
@@ -0,0 +1,343 @@
+// Package money represents monetary amounts exactly, as integer minor
+// units of an ISO 4217 currency, so sums and splits never pick up the
+// rounding errors of float64:
+//
+//	price, _ := money.Parse("19.99", money.USD)
+//	tax, _ := price.MulRate("0.0825")     // 1.65 USD, rounded half to even
+//	total, _ := price.Add(tax)            // 21.64 USD
+//	parts, _ := total.Allocate(1, 1, 1)   // 7.22, 7.21, 7.21 USD
+//
+// Amounts travel as decimal strings: JSON as {"amount":"21.64",
+// "currency":"USD"} and database columns as "21.64 USD". Request fields
+// are checked with the currency and money_* validation tags.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Errors returned by constructors and arithmetic.
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrPrecision        = errors.New("amount has more decimals than its currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount out of range")
+)
+
+// amountPattern matches plain decimal amounts: no exponent, no grouping.
+var amountPattern = regexp.MustCompile(`^([+-]?)([0-9]+)(?:\.([0-9]+))?$`)
+
+// Money is an amount in minor units (cents for USD) of a currency. The
+// zero value has no currency and is encoded as null.
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// New returns minor units of currency, e.g. New(1999, USD) is 19.99 USD.
+func New(minor int64, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Zero returns no money of currency.
+func Zero(currency Currency) (Money, error) {
+	return New(0, currency)
+}
+
+// Parse reads a decimal amount of currency such as "19.99" or "-5". It
+// rejects amounts with more significant decimals than the currency has,
+// rather than round them.
+//
+// Parameters:
+//   - amount: Decimal amount; trailing zero decimals are accepted
+//   - currency: Supported ISO 4217 currency
+//
+// Returns:
+//   - Money: Exact amount
+//   - error: ErrUnknownCurrency, ErrInvalidAmount, ErrPrecision or ErrOverflow
+func Parse(amount string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	m := amountPattern.FindStringSubmatch(strings.TrimSpace(amount))
+	if m == nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	sign, whole, frac := m[1], m[2], m[3]
+
+	digits := currency.Digits()
+	if len(frac) > digits {
+		if strings.Trim(frac[digits:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q has %d decimals in %s", ErrPrecision, amount, len(frac), currency)
+		}
+		frac = frac[:digits]
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+
+	minor, ok := new(big.Int).SetString(sign+whole+frac, 10)
+	if !ok || !minor.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q", ErrOverflow, amount)
+	}
+	return Money{minor: minor.Int64(), currency: currency}, nil
+}
+
+// MustParse is Parse panicking on errors, for constants and tests.
+func MustParse(amount string, currency Currency) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// ParseString reads the "21.64 USD" form of String.
+func ParseString(s string) (Money, error) {
+	amount, code, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q has no currency", ErrInvalidAmount, s)
+	}
+	currency, err := ParseCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Parse(amount, currency)
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 { return m.minor }
+
+// Currency returns the currency, empty for the zero value.
+func (m Money) Currency() Currency { return m.currency }
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool { return m.minor == 0 }
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool { return m.minor < 0 }
+
+// IsPositive reports whether the amount is above zero.
+func (m Money) IsPositive() bool { return m.minor > 0 }
+
+// Add returns m plus o, both of the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.minor > 0 && m.minor > math.MaxInt64-o.minor) || (o.minor < 0 && m.minor < math.MinInt64-o.minor) {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: m.minor + o.minor, currency: m.currency}, nil
+}
+
+// Sub returns m minus o, both of the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if o.minor == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{minor: -o.minor, currency: o.currency})
+}
+
+// Neg returns the amount with the opposite sign.
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Cmp compares m with o of the same currency, returning -1, 0 or +1.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < o.minor:
+		return -1, nil
+	case m.minor > o.minor:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Mul returns m times n, e.g. a unit price times a quantity.
+func (m Money) Mul(n int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(n))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: product.Int64(), currency: m.currency}, nil
+}
+
+// MulRate returns m times an exact decimal or fractional rate ("0.0825",
+// "1/3"), rounded half to even to the minor unit.
+func (m Money) MulRate(rate string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: rate %q", ErrInvalidAmount, rate)
+	}
+	product := roundHalfEven(r.Mul(r, new(big.Rat).SetInt64(m.minor)))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: product.Int64(), currency: m.currency}, nil
+}
+
+// Allocate splits m in proportion to ratios without losing minor units:
+// the remainder goes one unit at a time to the first parts.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	total := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidAmount, r)
+		}
+		total.Add(total, big.NewInt(int64(r)))
+	}
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("%w: ratios sum to zero", ErrInvalidAmount)
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.minor
+	for i, r := range ratios {
+		share := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(int64(r)))
+		share.Quo(share, total)
+		parts[i] = Money{minor: share.Int64(), currency: m.currency}
+		remainder -= share.Int64()
+	}
+	unit := int64(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := 0; remainder != 0; i++ {
+		if ratios[i%len(ratios)] == 0 {
+			continue
+		}
+		parts[i%len(ratios)].minor += unit
+		remainder -= unit
+	}
+	return parts, nil
+}
+
+// Amount formats the decimal amount without currency, e.g. "-0.05".
+func (m Money) Amount() string {
+	digits := m.currency.Digits()
+	abs := new(big.Int).Abs(big.NewInt(m.minor)).String()
+	if len(abs) <= digits {
+		abs = strings.Repeat("0", digits-len(abs)+1) + abs
+	}
+	sign := ""
+	if m.minor < 0 {
+		sign = "-"
+	}
+	if digits == 0 {
+		return sign + abs
+	}
+	return sign + abs[:len(abs)-digits] + "." + abs[len(abs)-digits:]
+}
+
+// String formats the amount with its currency, e.g. "21.64 USD".
+func (m Money) String() string {
+	return m.Amount() + " " + string(m.currency)
+}
+
+// moneyJSON is the wire form of Money.
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.currency == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Amount(), string(m.currency)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The amount is a string or a
+// number, read from its literal digits rather than through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*m = Money{}
+		return nil
+	}
+	var wire moneyJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	currency, err := ParseCurrency(wire.Currency)
+	if err != nil {
+		return err
+	}
+	parsed, err := Parse(wire.Amount.String(), currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing "21.64 USD"; the zero value is
+// NULL.
+func (m Money) Value() (driver.Value, error) {
+	if m.currency == "" {
+		return nil, nil
+	}
+	return m.String(), nil
+}
+
+// Scan implements sql.Scanner for columns written by Value.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case string:
+		parsed, err := ParseString(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case []byte:
+		return m.Scan(string(v))
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, src)
+	}
+}
+
+// sameCurrency returns ErrCurrencyMismatch unless o has the currency of m.
+func (m Money) sameCurrency(o Money) error {
+	if m.currency != o.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	return nil
+}
+
+// roundHalfEven rounds r to the nearest integer, ties to even.
+func roundHalfEven(r *big.Rat) *big.Int {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	switch cmp := twice.Cmp(r.Denom()); {
+	case cmp > 0, cmp == 0 && q.Bit(0) == 1:
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
@@ -0,0 +1,200 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency Currency
+		minor    int64
+		err      error
+	}{
+		{"19.99", USD, 1999, nil},
+		{"-0.05", USD, -5, nil},
+		{"+3", EUR, 300, nil},
+		{"12.50", USD, 1250, nil},
+		{"12.500", USD, 1250, nil},
+		{"1500", JPY, 1500, nil},
+		{"1.234", "KWD", 1234, nil},
+		{"1.999", USD, 0, ErrPrecision},
+		{"1.5", JPY, 0, ErrPrecision},
+		{"1e3", USD, 0, ErrInvalidAmount},
+		{"1,000.00", USD, 0, ErrInvalidAmount},
+		{".5", USD, 0, ErrInvalidAmount},
+		{"", USD, 0, ErrInvalidAmount},
+		{"92233720368547758.08", USD, 0, ErrOverflow},
+		{"1.00", "XYZ", 0, ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+string(tt.currency), func(t *testing.T) {
+			m, err := Parse(tt.amount, tt.currency)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.minor, m.Minor())
+			assert.Equal(t, tt.currency, m.Currency())
+		})
+	}
+}
+
+func TestMoney_Format(t *testing.T) {
+	assert.Equal(t, "19.99 USD", MustParse("19.99", USD).String())
+	assert.Equal(t, "-0.05", MustParse("-0.05", USD).Amount())
+	assert.Equal(t, "0.00", MustParse("0", EUR).Amount())
+	assert.Equal(t, "1500", MustParse("1500", JPY).Amount())
+	assert.Equal(t, "0.007", MustParse("0.007", "BHD").Amount())
+
+	min, err := New(math.MinInt64, USD)
+	require.NoError(t, err)
+	assert.Equal(t, "-92233720368547758.08", min.Amount())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	price := MustParse("19.99", USD)
+
+	tax, err := price.MulRate("0.0825")
+	require.NoError(t, err)
+	total, err := price.Add(tax)
+	require.NoError(t, err)
+	change, err := MustParse("25", USD).Sub(total)
+	require.NoError(t, err)
+	three, err := price.Mul(3)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.65 USD", tax.String())
+	assert.Equal(t, "21.64 USD", total.String())
+	assert.Equal(t, "3.36 USD", change.String())
+	assert.Equal(t, "59.97 USD", three.String())
+	assert.Equal(t, "-19.99 USD", price.Neg().String())
+
+	cmp, err := price.Cmp(total)
+	require.NoError(t, err)
+	assert.Equal(t, -1, cmp)
+
+	_, err = price.Add(MustParse("1", EUR))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = MustParse("1", EUR).Cmp(price)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	max, _ := New(math.MaxInt64, USD)
+	_, err = max.Add(MustParse("0.01", USD))
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = max.Mul(2)
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestMoney_MulRateRoundsHalfToEven(t *testing.T) {
+	tests := []struct {
+		amount string
+		rate   string
+		want   string
+	}{
+		{"0.05", "0.5", "0.02"}, // 2.5 cents rounds to even
+		{"0.15", "0.5", "0.08"}, // 7.5 cents rounds to even
+		{"-0.05", "0.5", "-0.02"},
+		{"-0.15", "0.5", "-0.08"},
+		{"10.00", "1/3", "3.33"},
+		{"0.10", "0.26", "0.03"}, // 2.6 cents
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount+"*"+tt.rate, func(t *testing.T) {
+			got, err := MustParse(tt.amount, USD).MulRate(tt.rate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Amount())
+		})
+	}
+
+	_, err := MustParse("1", USD).MulRate("eight percent")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestMoney_AllocateKeepsEveryMinorUnit(t *testing.T) {
+	parts, err := MustParse("21.64", USD).Allocate(1, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7.22", "7.21", "7.21"}, amounts(parts))
+
+	parts, err = MustParse("-0.05", USD).Allocate(1, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-0.03", "0.00", "-0.02"}, amounts(parts))
+
+	parts, err = MustParse("100", USD).Allocate(70, 20, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"70.00", "20.00", "10.00"}, amounts(parts))
+
+	_, err = MustParse("1", USD).Allocate(0, 0)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = MustParse("1", USD).Allocate(1, -1)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func amounts(parts []Money) []string {
+	out := make([]string, len(parts))
+	for i, p := range parts {
+		out[i] = p.Amount()
+	}
+	return out
+}
+
+func TestMoney_JSON(t *testing.T) {
+	type order struct {
+		Total    Money `json:"total"`
+		Discount Money `json:"discount"`
+	}
+
+	data, err := json.Marshal(order{Total: MustParse("21.64", USD)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total":{"amount":"21.64","currency":"USD"},"discount":null}`, string(data))
+
+	var decoded order
+	require.NoError(t, json.Unmarshal([]byte(`{"total":{"amount":0.1,"currency":"usd"},"discount":{"amount":"0.20","currency":"USD"}}`), &decoded))
+	assert.Equal(t, int64(10), decoded.Total.Minor(), "numbers are read from their digits")
+	assert.Equal(t, USD, decoded.Total.Currency())
+	assert.Equal(t, int64(20), decoded.Discount.Minor())
+
+	for _, body := range []string{
+		`{"amount":"0.001","currency":"USD"}`,
+		`{"amount":"1","currency":"XYZ"}`,
+		`{"amount":"one","currency":"USD"}`,
+		`{"amount":"1"}`,
+	} {
+		var m Money
+		assert.Error(t, json.Unmarshal([]byte(body), &m), body)
+	}
+}
+
+func TestMoney_SQL(t *testing.T) {
+	value, err := MustParse("21.64", USD).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "21.64 USD", value)
+	null, err := Money{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, null)
+
+	var m Money
+	require.NoError(t, m.Scan([]byte("-3 JPY")))
+	assert.Equal(t, "-3 JPY", m.String())
+	require.NoError(t, m.Scan(nil))
+	assert.Equal(t, Money{}, m)
+	assert.Error(t, m.Scan("21.64"))
+	assert.Error(t, m.Scan(int64(2164)))
+}
+
+func TestParseCurrency(t *testing.T) {
+	c, err := ParseCurrency(" eur ")
+	require.NoError(t, err)
+	assert.Equal(t, EUR, c)
+	assert.Equal(t, 2, c.Digits())
+	assert.Equal(t, 0, JPY.Digits())
+
+	_, err = ParseCurrency("EURO")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
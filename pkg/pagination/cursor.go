@@ -0,0 +1,119 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/clock"
+)
+
+// Cursor errors returned by Decode.
+var (
+	ErrInvalidCursor = errors.New("pagination: invalid cursor")
+	ErrCursorExpired = errors.New("pagination: cursor expired")
+)
+
+// cursorVersion is the layout of issued tokens; tokens of other versions
+// are refused, so the layout can change without misreading old ones.
+const cursorVersion byte = 1
+
+// Cursor is the position of a keyset paginated listing. Unlike an offset,
+// it stays on the same item while items are added or removed before it.
+type Cursor struct {
+	After   []string          // Sort key of the last item of the previous page
+	Filters map[string]string // Filters of the listing the cursor belongs to
+}
+
+// Matches reports whether the cursor belongs to a listing with filters.
+func (c Cursor) Matches(filters map[string]string) bool {
+	return maps.Equal(c.Filters, filters)
+}
+
+// cursorPayload is the signed content of a token.
+type cursorPayload struct {
+	After     []string          `json:"a"`
+	Filters   map[string]string `json:"f,omitempty"`
+	ExpiresAt int64             `json:"e"`
+}
+
+// CursorOptions configures Cursors.
+type CursorOptions struct {
+	TTL   time.Duration // Validity of issued tokens (default 1h)
+	Clock clock.Clock   // Tells token expiry (nil uses the system clock)
+}
+
+// Cursors issues and reads opaque cursor tokens: a version byte, the
+// cursor with its expiry as JSON, and an HMAC-SHA256 of both, in URL-safe
+// base64. Clients cannot read or alter the cursor of a token.
+type Cursors struct {
+	key   []byte
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+// NewCursors creates a cursor codec signing tokens with key.
+func NewCursors(key []byte, opts CursorOptions) (*Cursors, error) {
+	if len(key) == 0 {
+		return nil, errors.New("pagination: cursor signing key is empty")
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = time.Hour
+	}
+	return &Cursors{key: key, ttl: opts.TTL, clock: clock.OrReal(opts.Clock)}, nil
+}
+
+// Encode returns the token of cursor, valid for the TTL.
+func (c *Cursors) Encode(cursor Cursor) string {
+	payload, _ := json.Marshal(cursorPayload{
+		After:     cursor.After,
+		Filters:   cursor.Filters,
+		ExpiresAt: c.clock.Now().Add(c.ttl).Unix(),
+	})
+	data := append([]byte{cursorVersion}, payload...)
+	return base64.RawURLEncoding.EncodeToString(c.sign(data))
+}
+
+// Decode returns the cursor of token.
+//
+// Parameters:
+//   - token: Token issued by Encode with the same key
+//
+// Returns:
+//   - Cursor: The encoded cursor
+//   - error: ErrInvalidCursor for malformed, altered or unknown version
+//     tokens, ErrCursorExpired past their TTL
+func (c *Cursors) Decode(token string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) < 1+sha256.Size {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if data[0] != cursorVersion {
+		return Cursor{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidCursor, data[0])
+	}
+	signed := data[:len(data)-sha256.Size]
+	if !hmac.Equal(c.sign(signed), data) {
+		return Cursor{}, fmt.Errorf("%w: bad signature", ErrInvalidCursor)
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(signed[1:], &payload); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.clock.Now().Unix() >= payload.ExpiresAt {
+		return Cursor{}, ErrCursorExpired
+	}
+	return Cursor{After: payload.After, Filters: payload.Filters}, nil
+}
+
+// sign returns data followed by its MAC.
+func (c *Cursors) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(data)
+	return mac.Sum(data[:len(data):len(data)])
+}
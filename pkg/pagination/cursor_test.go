@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursors_RoundTrip(t *testing.T) {
+	cursors, err := NewCursors([]byte("key"), CursorOptions{})
+	require.NoError(t, err)
+
+	in := Cursor{After: []string{"2024-01-15T10:30:00Z", "42"}, Filters: map[string]string{"status": "active"}}
+	token := cursors.Encode(in)
+
+	out, err := cursors.Decode(token)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+	assert.True(t, out.Matches(map[string]string{"status": "active"}))
+	assert.False(t, out.Matches(map[string]string{"status": "inactive"}))
+	assert.NotContains(t, token, "active", "tokens are opaque")
+}
+
+func TestCursors_RejectsForeignTokens(t *testing.T) {
+	cursors, err := NewCursors([]byte("key"), CursorOptions{})
+	require.NoError(t, err)
+	other, err := NewCursors([]byte("other key"), CursorOptions{})
+	require.NoError(t, err)
+	token := cursors.Encode(Cursor{After: []string{"42"}})
+
+	_, err = other.Decode(token)
+	assert.ErrorIs(t, err, ErrInvalidCursor, "signed with another key")
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	data[5] ^= 1
+	_, err = cursors.Decode(base64.RawURLEncoding.EncodeToString(data))
+	assert.ErrorIs(t, err, ErrInvalidCursor, "altered")
+
+	// A correctly signed token of another layout version
+	data[0] = cursorVersion + 1
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write(data[:len(data)-sha256.Size])
+	resigned := append(data[:len(data)-sha256.Size:len(data)-sha256.Size], mac.Sum(nil)...)
+	_, err = cursors.Decode(base64.RawURLEncoding.EncodeToString(resigned))
+	assert.ErrorIs(t, err, ErrInvalidCursor, "unsupported version")
+
+	for _, token := range []string{"", "not base64!", "AQ"} {
+		_, err = cursors.Decode(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
+
+func TestCursors_Expire(t *testing.T) {
+	clk := mocks.NewFakeClock(time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC))
+	cursors, err := NewCursors([]byte("key"), CursorOptions{TTL: time.Minute, Clock: clk})
+	require.NoError(t, err)
+	token := cursors.Encode(Cursor{After: []string{"42"}})
+
+	clk.Advance(59 * time.Second)
+	_, err = cursors.Decode(token)
+	require.NoError(t, err)
+
+	clk.Advance(time.Second)
+	_, err = cursors.Decode(token)
+	assert.ErrorIs(t, err, ErrCursorExpired)
+}
+
+func TestNewCursors_RequiresKey(t *testing.T) {
+	_, err := NewCursors(nil, CursorOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Guard errors returned in strict mode.
+var (
+	ErrMissingLimit  = errors.New("pagination: query without a limit")
+	ErrLimitExceeded = errors.New("pagination: limit above the maximum page size")
+	ErrPageTooLarge  = errors.New("pagination: page larger than its limit")
+)
+
+// Guard violation reasons.
+const (
+	ReasonMissingLimit  = "missing_limit"
+	ReasonLimitExceeded = "limit_exceeded"
+	ReasonPageTooLarge  = "page_too_large"
+)
+
+// GuardOptions configures a Guard.
+type GuardOptions struct {
+	MaxLimit     int           // Largest page size (default MaxLimit)
+	DefaultLimit int           // Page size of queries without a limit (default DefaultLimit, at most MaxLimit)
+	Strict       bool          // Fail violations instead of bounding them
+	Metrics      *GuardMetrics // Counts violations when set
+}
+
+// Guard protects repository boundaries against unbounded result sets.
+// Queries without a limit get the default page size, queries above the
+// maximum page size and pages holding more items than requested are
+// bounded to it; in strict mode all of them fail so they are caught
+// during development.
+type Guard struct {
+	opts GuardOptions
+}
+
+// NewGuard creates a pagination guard.
+func NewGuard(opts GuardOptions) *Guard {
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = MaxLimit
+	}
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = DefaultLimit
+	}
+	opts.DefaultLimit = min(opts.DefaultLimit, opts.MaxLimit)
+	return &Guard{opts: opts}
+}
+
+// Params checks the params of a query on resource and returns them
+// bounded to the maximum page size, with a non-negative offset.
+func (g *Guard) Params(resource string, p Params) (Params, error) {
+	switch {
+	case p.Limit <= 0:
+		if err := g.violation(resource, ReasonMissingLimit, ErrMissingLimit); err != nil {
+			return p, err
+		}
+		p.Limit = g.opts.DefaultLimit
+	case p.Limit > g.opts.MaxLimit:
+		if err := g.violation(resource, ReasonLimitExceeded, ErrLimitExceeded); err != nil {
+			return p, err
+		}
+		p.Limit = g.opts.MaxLimit
+	}
+	p.Offset = max(p.Offset, 0)
+	return p, nil
+}
+
+// Enforce checks that page holds at most the items requested by params,
+// truncating it otherwise.
+func Enforce[T any](g *Guard, resource string, p Params, page Page[T]) (Page[T], error) {
+	if len(page.Items) <= p.Limit {
+		return page, nil
+	}
+	if err := g.violation(resource, ReasonPageTooLarge, ErrPageTooLarge); err != nil {
+		return page, err
+	}
+	page.Items = page.Items[:p.Limit]
+	page.Limit = p.Limit
+	return page, nil
+}
+
+// violation counts a violation and returns the strict mode error.
+func (g *Guard) violation(resource, reason string, err error) error {
+	action := "bounded"
+	if g.opts.Strict {
+		action = "rejected"
+	}
+	g.opts.Metrics.violation(resource, reason, action)
+	if g.opts.Strict {
+		return fmt.Errorf("%s: %w", resource, err)
+	}
+	return nil
+}
+
+// GuardMetrics holds the guard collectors. A nil *GuardMetrics records
+// nothing.
+type GuardMetrics struct {
+	violations *prometheus.CounterVec
+}
+
+// NewGuardMetrics creates the guard collectors and registers them.
+// It panics if the collectors are already registered.
+func NewGuardMetrics(reg prometheus.Registerer) *GuardMetrics {
+	m := &GuardMetrics{
+		violations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pagination_guard_violations_total",
+			Help: "Unbounded or oversized queries by resource, reason and action (bounded, rejected).",
+		}, []string{"resource", "reason", "action"}),
+	}
+	reg.MustRegister(m.violations)
+	return m
+}
+
+// violation records a guard violation.
+func (m *GuardMetrics) violation(resource, reason, action string) {
+	if m == nil {
+		return
+	}
+	m.violations.WithLabelValues(resource, reason, action).Inc()
+}
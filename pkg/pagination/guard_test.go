@@ -0,0 +1,58 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard_BoundsQueries(t *testing.T) {
+	metrics := NewGuardMetrics(prometheus.NewRegistry())
+	guard := NewGuard(GuardOptions{MaxLimit: 50, Metrics: metrics})
+
+	p, err := guard.Params("users", Params{Offset: 10})
+	require.NoError(t, err)
+	assert.Equal(t, Params{Limit: DefaultLimit, Offset: 10}, p)
+
+	p, err = guard.Params("users", Params{Limit: 500})
+	require.NoError(t, err)
+	assert.Equal(t, 50, p.Limit)
+
+	p, err = guard.Params("users", Params{Limit: 20, Offset: -1})
+	require.NoError(t, err)
+	assert.Equal(t, Params{Limit: 20}, p)
+
+	p, err = NewGuard(GuardOptions{MaxLimit: 500}).Params("users", Params{Limit: 300})
+	require.NoError(t, err)
+	assert.Equal(t, 300, p.Limit, "the configured maximum applies, not MaxLimit")
+	p, err = NewGuard(GuardOptions{MaxLimit: 10}).Params("users", Params{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, p.Limit, "the default page size is bounded too")
+
+	page, err := Enforce(guard, "users", Params{Limit: 2}, Page[int]{Items: []int{1, 2, 3}, Limit: 0, Total: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, page.Items)
+	assert.True(t, page.HasMore())
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.violations.WithLabelValues("users", ReasonMissingLimit, "bounded")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.violations.WithLabelValues("users", ReasonLimitExceeded, "bounded")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.violations.WithLabelValues("users", ReasonPageTooLarge, "bounded")))
+}
+
+func TestGuard_StrictModeFails(t *testing.T) {
+	guard := NewGuard(GuardOptions{Strict: true})
+
+	_, err := guard.Params("users", Params{})
+	assert.ErrorIs(t, err, ErrMissingLimit)
+	_, err = guard.Params("users", Params{Limit: MaxLimit + 1})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	_, err = Enforce(guard, "users", Params{Limit: 1}, Page[int]{Items: []int{1, 2}})
+	assert.ErrorIs(t, err, ErrPageTooLarge)
+	assert.ErrorContains(t, err, "users:")
+
+	_, err = guard.Params("users", Params{Limit: MaxLimit})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,71 @@
+// Package pagination provides the pagination parameters and pages shared
+// by repositories and list endpoints. Pages are selected by offset, or by
+// a signed Cursor after the sort key of the last item seen (keyset
+// pagination), which stays stable while items are written.
+package pagination
+
+import "github.com/luminosita/change-me/pkg/filter"
+
+// Default limits applied by Normalize.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Params selects a window of results.
+type Params struct {
+	Limit  int `form:"limit" json:"limit"`
+	Offset int `form:"offset" json:"offset"`
+
+	// After selects the items following this sort key instead of Offset;
+	// repositories report the offset of the first item returned
+	After []string `form:"-" json:"after,omitempty"`
+
+	// Filter restricts the items listed; the page and its total only
+	// count the matching ones
+	Filter filter.Spec `form:"-" json:"-"`
+}
+
+// Normalize returns params with the limit clamped to [1, maxLimit]
+// (DefaultLimit when unset) and a non-negative offset.
+func (p Params) Normalize(maxLimit int) Params {
+	if maxLimit <= 0 {
+		maxLimit = MaxLimit
+	}
+	if p.Limit <= 0 {
+		p.Limit = DefaultLimit
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	return p
+}
+
+// Page is a window of results with the total number of matching items.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// HasMore reports whether items exist beyond this page.
+func (p Page[T]) HasMore() bool {
+	return p.Offset+len(p.Items) < p.Total
+}
+
+// Window returns the slice bounds [start, end) for params over total items.
+func Window(p Params, total int) (start, end int) {
+	start = p.Offset
+	if start > total {
+		start = total
+	}
+	end = start + p.Limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
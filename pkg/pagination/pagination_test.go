@@ -0,0 +1,42 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Params
+		max  int
+		want Params
+	}{
+		{"defaults", Params{}, 0, Params{Limit: DefaultLimit}},
+		{"clamps limit", Params{Limit: 500}, 50, Params{Limit: 50}},
+		{"negative offset", Params{Limit: 10, Offset: -5}, 0, Params{Limit: 10}},
+		{"unchanged", Params{Limit: 10, Offset: 30}, 0, Params{Limit: 10, Offset: 30}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.in.Normalize(tt.max))
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	start, end := Window(Params{Limit: 10, Offset: 5}, 12)
+	assert.Equal(t, 5, start)
+	assert.Equal(t, 12, end)
+
+	start, end = Window(Params{Limit: 10, Offset: 50}, 12)
+	assert.Equal(t, 12, start)
+	assert.Equal(t, 12, end)
+}
+
+func TestPage_HasMore(t *testing.T) {
+	assert.True(t, Page[int]{Items: []int{1, 2}, Offset: 0, Total: 3}.HasMore())
+	assert.False(t, Page[int]{Items: []int{3}, Offset: 2, Total: 3}.HasMore())
+}
@@ -0,0 +1,355 @@
+// Package pdf writes simple flowing text documents as PDF 1.4.
+//
+// A Document lays out headings, paragraphs and tables top to bottom,
+// breaking pages as it goes, with the standard Helvetica fonts every PDF
+// reader ships, so no font files are embedded:
+//
+//	doc := pdf.New(pdf.A4, "Users report")
+//	doc.Heading("Summary")
+//	doc.Paragraph("42 users, 40 active.")
+//	doc.Table([]string{"ID", "Email"}, rows)
+//	_, err := doc.WriteTo(w)
+//
+// Text is encoded as WinAnsi (Latin-1); other characters render as "?".
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// PageSize is a page size in points (1/72 inch).
+type PageSize struct {
+	Width, Height float64
+}
+
+// Common page sizes.
+var (
+	A4     = PageSize{Width: 595.28, Height: 841.89}
+	Letter = PageSize{Width: 612, Height: 792}
+)
+
+// Layout metrics in points.
+const (
+	margin         = 56
+	bodySize       = 10
+	headingSize    = 15
+	titleSize      = 20
+	footerSize     = 8
+	lineHeight     = 1.4
+	cellPadding    = 4
+	paragraphSpace = 6
+)
+
+// font selects one of the two standard fonts.
+type font int
+
+const (
+	regular font = iota
+	bold
+)
+
+// resourceNames are the font resource names of the page content.
+var resourceNames = [...]string{regular: "F1", bold: "F2"}
+
+// Document is a PDF document under construction.
+type Document struct {
+	// Title is set in the document information and drawn on the first page
+	Title string
+
+	// Created is the creation date of the document information
+	Created time.Time
+
+	size  PageSize
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// New creates a document of size pages, drawing title at the top of the
+// first page when it is not empty.
+func New(size PageSize, title string) *Document {
+	d := &Document{Title: title, Created: time.Now(), size: size}
+	d.newPage()
+	if title != "" {
+		d.lines(d.wrap(title, bold, titleSize, d.contentWidth()), bold, titleSize, margin)
+		d.y -= paragraphSpace * 2
+	}
+	return d
+}
+
+// Pages returns the number of pages laid out so far.
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// Heading adds a section heading, starting a new page when fewer than a
+// few lines fit below it.
+func (d *Document) Heading(text string) {
+	d.y -= paragraphSpace
+	if d.y-headingSize*lineHeight-3*bodySize*lineHeight < margin {
+		d.newPage()
+	}
+	d.lines(d.wrap(text, bold, headingSize, d.contentWidth()), bold, headingSize, margin)
+	d.y -= paragraphSpace / 2
+}
+
+// Paragraph adds text wrapped to the page width. Newlines start new lines.
+func (d *Document) Paragraph(text string) {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, d.wrap(line, regular, bodySize, d.contentWidth())...)
+	}
+	d.lines(lines, regular, bodySize, margin)
+	d.y -= paragraphSpace
+}
+
+// Table adds a table of equally wide columns with a bold header row,
+// repeated on every page it spans. Cells too wide for their column are
+// truncated.
+func (d *Document) Table(header []string, rows [][]string) {
+	if len(header) == 0 {
+		return
+	}
+	colWidth := d.contentWidth() / float64(len(header))
+	rowHeight := bodySize*lineHeight + cellPadding
+
+	drawHeader := func() {
+		d.row(header, bold, colWidth, rowHeight)
+		fmt.Fprintf(d.page(), "0.5 w %s %s m %s %s l S\n",
+			num(margin), num(d.y+cellPadding/2), num(d.size.Width-margin), num(d.y+cellPadding/2))
+	}
+	if d.y-2*rowHeight < margin {
+		d.newPage()
+	}
+	drawHeader()
+	for _, cells := range rows {
+		if d.y-rowHeight < margin {
+			d.newPage()
+			drawHeader()
+		}
+		d.row(cells, regular, colWidth, rowHeight)
+	}
+	d.y -= paragraphSpace
+}
+
+// row draws one table row.
+func (d *Document) row(cells []string, f font, colWidth, rowHeight float64) {
+	d.y -= rowHeight
+	for i, cell := range cells {
+		x := margin + float64(i)*colWidth
+		d.text(truncate(cell, f, bodySize, colWidth-cellPadding), f, bodySize, x, d.y+cellPadding/2)
+	}
+}
+
+// lines draws lines of text at x, breaking pages as needed.
+func (d *Document) lines(lines []string, f font, size, x float64) {
+	for _, line := range lines {
+		if d.y-size*lineHeight < margin {
+			d.newPage()
+		}
+		d.y -= size * lineHeight
+		d.text(line, f, size, x, d.y)
+	}
+}
+
+// text draws s with its baseline at (x, y).
+func (d *Document) text(s string, f font, size, x, y float64) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(d.page(), "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		resourceNames[f], num(size), num(x), num(y), escape(encode(s)))
+}
+
+// page returns the content of the current page.
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// newPage starts a page.
+func (d *Document) newPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+	d.y = d.size.Height - margin
+}
+
+// contentWidth is the width between the margins.
+func (d *Document) contentWidth() float64 {
+	return d.size.Width - 2*margin
+}
+
+// wrap breaks s into lines no wider than width, splitting words longer
+// than a line.
+func (d *Document) wrap(s string, f font, size, width float64) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	var line string
+	for _, word := range words {
+		for textWidth(word, f, size) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			n := fit(word, f, size, width)
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(candidate, f, size) > width {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	return append(lines, line)
+}
+
+// WriteTo writes the document to w, numbering the pages in their footers.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...any) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&out, format, args...)
+		out.WriteString("\nendobj\n")
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-5 are the catalog, page tree, fonts and information; each
+	// page is followed by its content stream
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Title (%s) /Producer (change-me) /CreationDate (D:%s) >>",
+		escape(encode(d.Title)), d.Created.UTC().Format("20060102150405Z"))
+
+	for i, content := range d.pages {
+		footer := fmt.Sprintf("%d / %d", i+1, len(d.pages))
+		var page bytes.Buffer
+		page.Write(content.Bytes())
+		fmt.Fprintf(&page, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n", num(footerSize),
+			num(d.size.Width-margin-textWidth(footer, regular, footerSize)), num(margin/2), footer)
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		_, _ = zw.Write(page.Bytes())
+		_ = zw.Close()
+
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(d.size.Width), num(d.size.Height), firstPage+2*i+1)
+		object("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+// encode converts s to WinAnsi, replacing unsupported characters.
+func encode(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b = append(b, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b = append(b, byte(r))
+		default:
+			b = append(b, '?')
+		}
+	}
+	return string(b)
+}
+
+// escape escapes the delimiters of a PDF string literal.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
+
+// num formats a coordinate.
+func num(f float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", f), "0"), ".")
+}
+
+// truncate shortens s with "..." to fit width.
+func truncate(s string, f font, size, width float64) string {
+	if textWidth(s, f, size) <= width {
+		return s
+	}
+	const ellipsis = "..."
+	n := fit(s, f, size, width-textWidth(ellipsis, f, size))
+	return s[:n] + ellipsis
+}
+
+// fit returns the length of the longest prefix of s, at least one byte,
+// no wider than width.
+func fit(s string, f font, size, width float64) int {
+	var w float64
+	for i, r := range s {
+		w += charWidth(r, f) * size / 1000
+		if w > width && i > 0 {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// textWidth returns the width of s in points.
+func textWidth(s string, f font, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		w += charWidth(r, f)
+	}
+	return w * size / 1000
+}
+
+// charWidth returns the advance of r in thousandths of the font size.
+func charWidth(r rune, f font) float64 {
+	if r >= 0x20 && r < 0x7f {
+		return float64(widths[f][r-0x20])
+	}
+	return 556
+}
+
+// widths are the advances of the printable ASCII characters in the
+// Helvetica and Helvetica-Bold font metrics.
+var widths = [...][95]int{
+	regular: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	bold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
@@ -0,0 +1,103 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contents returns the decompressed page content streams of a document.
+func contents(t *testing.T, data []byte) []string {
+	t.Helper()
+	var pages []string
+	for _, m := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(data, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		require.NoError(t, err)
+		page, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		pages = append(pages, string(page))
+	}
+	return pages
+}
+
+func TestDocument_WriteTo(t *testing.T) {
+	doc := New(A4, "Users (2024)")
+	doc.Heading("Summary")
+	doc.Paragraph("Two users, one active.")
+	doc.Table([]string{"ID", "Email"}, [][]string{{"1", "jane@example.com"}, {"2", "jöhn@example.com"}})
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	data := buf.Bytes()
+	assert.Equal(t, int64(len(data)), n)
+
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	assert.Contains(t, buf.String(), "/Count 1")
+	assert.Contains(t, buf.String(), `/Title (Users \(2024\))`)
+
+	pages := contents(t, data)
+	require.Len(t, pages, 1)
+	assert.Contains(t, pages[0], "(Summary) Tj")
+	assert.Contains(t, pages[0], "(jane@example.com) Tj")
+	assert.Contains(t, pages[0], "(j\xf6hn@example.com) Tj", "Latin-1 text is WinAnsi encoded")
+	assert.Contains(t, pages[0], "(1 / 1) Tj")
+}
+
+func TestDocument_XrefOffsets(t *testing.T) {
+	doc := New(Letter, "")
+	doc.Paragraph("Hello")
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	data := buf.String()
+
+	start := strings.LastIndex(data, "startxref\n")
+	xref, err := strconv.Atoi(strings.Fields(data[start+len("startxref\n"):])[0])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(data[xref:], "xref\n"))
+
+	entries := strings.Split(data[xref:], "\n")[3:]
+	for i := 1; strings.HasSuffix(entries[i-1], " n "); i++ {
+		offset, err := strconv.Atoi(entries[i-1][:10])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(data[offset:], fmt.Sprintf("%d 0 obj", i)), "object %d", i)
+	}
+}
+
+func TestDocument_BreaksPages(t *testing.T) {
+	doc := New(A4, "Long")
+	rows := make([][]string, 200)
+	for i := range rows {
+		rows[i] = []string{strconv.Itoa(i), strings.Repeat("x", 200)}
+	}
+	doc.Table([]string{"ID", "Value"}, rows)
+	doc.Paragraph(strings.Repeat("lorem ipsum ", 2000))
+	assert.Greater(t, doc.Pages(), 5)
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	pages := contents(t, buf.Bytes())
+	require.Len(t, pages, doc.Pages())
+	assert.Contains(t, pages[1], "(Value) Tj", "the table header repeats on every page")
+	assert.Contains(t, pages[0], "...) Tj", "wide cells are truncated")
+}
+
+func TestWrap(t *testing.T) {
+	doc := New(A4, "")
+	lines := doc.wrap("the quick brown fox "+strings.Repeat("a", 300), regular, bodySize, 100)
+	for _, line := range lines {
+		assert.LessOrEqual(t, textWidth(line, regular, bodySize), 100.0, line)
+	}
+	assert.Equal(t, "the quick brown fox", lines[0])
+}
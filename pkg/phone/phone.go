@@ -0,0 +1,146 @@
+// Package phone normalizes telephone numbers to E.164 ("+14155550100"),
+// the form to store, compare and send to SMS providers:
+//
+//	phone.Normalize("+1 (415) 555-0100", "") // "+14155550100"
+//	phone.Normalize("020 7946 0958", "GB")   // "+442079460958"
+//	phone.Format("+442079460958")            // "+44 2079460958"
+//
+// National numbers are read with the calling code and trunk prefix of a
+// region (ISO 3166-1 alpha-2). The checks are structural: a number of
+// valid length in a known calling code, not one assigned to a subscriber.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by Normalize.
+var (
+	ErrInvalid       = errors.New("invalid phone number")
+	ErrUnknownRegion = errors.New("unknown phone region")
+)
+
+// region holds the dialing plan of a country.
+type region struct {
+	code  string // Country calling code
+	trunk string // National prefix dropped in international form
+}
+
+// regions maps ISO 3166-1 alpha-2 codes to their dialing plans.
+var regions = map[string]region{
+	"AE": {"971", "0"}, "AR": {"54", "0"}, "AT": {"43", "0"}, "AU": {"61", "0"},
+	"BE": {"32", "0"}, "BG": {"359", "0"}, "BR": {"55", "0"}, "CA": {"1", "1"},
+	"CH": {"41", "0"}, "CN": {"86", "0"}, "CZ": {"420", ""}, "DE": {"49", "0"},
+	"DK": {"45", ""}, "EG": {"20", "0"}, "ES": {"34", ""}, "FI": {"358", "0"},
+	"FR": {"33", "0"}, "GB": {"44", "0"}, "GR": {"30", ""}, "HK": {"852", ""},
+	"HR": {"385", "0"}, "HU": {"36", "06"}, "IE": {"353", "0"}, "IL": {"972", "0"},
+	"IN": {"91", "0"}, "IT": {"39", ""}, "JP": {"81", "0"}, "KR": {"82", "0"},
+	"MX": {"52", ""}, "NG": {"234", "0"}, "NL": {"31", "0"}, "NO": {"47", ""},
+	"NZ": {"64", "0"}, "PL": {"48", ""}, "PT": {"351", ""}, "RO": {"40", "0"},
+	"RS": {"381", "0"}, "RU": {"7", "8"}, "SA": {"966", "0"}, "SE": {"46", "0"},
+	"SG": {"65", ""}, "SI": {"386", "0"}, "TR": {"90", "0"}, "UA": {"380", "0"},
+	"US": {"1", "1"}, "ZA": {"27", "0"},
+}
+
+// callingCodes maps the calling codes of regions to the region reported
+// for them; shared codes report the main region.
+var callingCodes = func() map[string]string {
+	codes := make(map[string]string, len(regions))
+	for name, r := range regions {
+		if _, ok := codes[r.code]; !ok || name == "US" || name == "RU" {
+			codes[r.code] = name
+		}
+	}
+	return codes
+}()
+
+// separators are accepted between digit groups.
+var separators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "", "\u00a0", "")
+
+// CountryCode returns the calling code of region, e.g. "44" for "GB".
+func CountryCode(regionCode string) (string, bool) {
+	r, ok := regions[strings.ToUpper(regionCode)]
+	return r.code, ok
+}
+
+// Normalize returns raw in E.164 form. Numbers starting with "+" or the
+// "00" international prefix are read as international; others as national
+// numbers of regionCode, which may be empty to require international ones.
+//
+// Parameters:
+//   - raw: Number as entered, with spaces, dashes, dots or parentheses
+//   - regionCode: ISO 3166-1 alpha-2 region of national numbers, or ""
+//
+// Returns:
+//   - string: E.164 number
+//   - error: ErrInvalid or ErrUnknownRegion
+func Normalize(raw string, regionCode string) (string, error) {
+	s := strings.TrimSpace(raw)
+	international := strings.HasPrefix(s, "+")
+	// A trunk prefix kept in parentheses after the calling code: +44 (0)20
+	s = strings.Replace(s, "(0)", "", 1)
+	digits := separators.Replace(strings.TrimPrefix(s, "+"))
+	if !international && strings.HasPrefix(digits, "00") {
+		international, digits = true, digits[2:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, raw)
+	}
+
+	if !international {
+		if regionCode == "" {
+			return "", fmt.Errorf("%w: %q has no country calling code", ErrInvalid, raw)
+		}
+		r, ok := regions[strings.ToUpper(regionCode)]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrUnknownRegion, regionCode)
+		}
+		if r.trunk != "" && strings.HasPrefix(digits, r.trunk) && len(digits) > nationalLength(r) {
+			digits = digits[len(r.trunk):]
+		}
+		digits = r.code + digits
+	}
+
+	if digits[0] == '0' || len(digits) < 7 || len(digits) > 15 {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, raw)
+	}
+	if r, ok := regions[Region("+"+digits)]; ok && r.code == "1" && len(digits) != 11 {
+		return "", fmt.Errorf("%w: %q is not a 10 digit NANP number", ErrInvalid, raw)
+	}
+	return "+" + digits, nil
+}
+
+// nationalLength is the length below which a leading trunk digit belongs
+// to the number: NANP numbers have 10 digits, the trunk prefix makes 11.
+func nationalLength(r region) int {
+	if r.code == "1" {
+		return 10
+	}
+	return len(r.trunk)
+}
+
+// Region returns the region of the calling code of an E.164 number, "" when
+// it is unknown. Regions sharing a code (US and CA) report the main one.
+func Region(e164 string) string {
+	digits := strings.TrimPrefix(e164, "+")
+	for n := 1; n <= 3 && n <= len(digits); n++ {
+		if name, ok := callingCodes[digits[:n]]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// Format separates the calling code of an E.164 number from the national
+// number for display, e.g. "+44 2079460958". Numbers of unknown calling
+// codes are returned unchanged.
+func Format(e164 string) string {
+	name := Region(e164)
+	if name == "" {
+		return e164
+	}
+	code := regions[name].code
+	return "+" + code + " " + strings.TrimPrefix(e164, "+"+code)
+}
@@ -0,0 +1,63 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   string
+		err    error
+	}{
+		{"+14155550100", "", "+14155550100", nil},
+		{"+1 (415) 555-0100", "", "+14155550100", nil},
+		{"+44 (0)20 7946 0958", "", "+442079460958", nil},
+		{"0044 20 7946 0958", "", "+442079460958", nil},
+		{"+49 30.1234567", "", "+49301234567", nil},
+		{"(415) 555-0100", "US", "+14155550100", nil},
+		{"1-415-555-0100", "us", "+14155550100", nil},
+		{"020 7946 0958", "GB", "+442079460958", nil},
+		{"06 1 234 5678", "HU", "+3612345678", nil},
+		{"8 495 123-45-67", "RU", "+74951234567", nil},
+		{"06 12345678", "IT", "+390612345678", nil}, // Italy keeps the leading 0
+		{"4155550100", "", "", ErrInvalid},
+		{"020 7946 0958", "XX", "", ErrUnknownRegion},
+		{"+1 415 555 010", "", "", ErrInvalid},
+		{"+0123456789", "", "", ErrInvalid},
+		{"+1234567890123456", "", "", ErrInvalid},
+		{"+44 20 7946 0958 ext 12", "", "", ErrInvalid},
+		{"", "US", "", ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw+" "+tt.region, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.region)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegionAndFormat(t *testing.T) {
+	assert.Equal(t, "US", Region("+14155550100"))
+	assert.Equal(t, "IE", Region("+35312345678"))
+	assert.Equal(t, "", Region("+999123456"))
+
+	assert.Equal(t, "+44 2079460958", Format("+442079460958"))
+	assert.Equal(t, "+1 4155550100", Format("+14155550100"))
+	assert.Equal(t, "+999123456", Format("+999123456"))
+
+	code, ok := CountryCode("rs")
+	assert.True(t, ok)
+	assert.Equal(t, "381", code)
+	_, ok = CountryCode("XX")
+	assert.False(t, ok)
+}
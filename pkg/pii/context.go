@@ -0,0 +1,18 @@
+package pii
+
+import "context"
+
+type maskingKey struct{}
+
+// WithMasking returns a copy of ctx under which personal data must be
+// masked, for serializers the response masking cannot reach such as
+// CSV exports.
+func WithMasking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, maskingKey{}, true)
+}
+
+// Masking reports whether personal data must be masked under ctx.
+func Masking(ctx context.Context) bool {
+	masking, _ := ctx.Value(maskingKey{}).(bool)
+	return masking
+}
@@ -0,0 +1,126 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// MaskJSON returns the JSON document data with the string values of the
+// keys in fields masked at any depth, keeping the order of keys and the
+// representation of numbers. Containers under a classified key have all
+// their strings masked, e.g. a list of emails. Whitespace is not kept.
+func MaskJSON(data []byte, fields map[string]Kind) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	// frame is an open object or array; kind is inherited from the
+	// classified key holding it
+	type frame struct {
+		object    bool
+		expectKey bool
+		count     int
+		kind      Kind
+	}
+	var (
+		out     bytes.Buffer
+		stack   []*frame
+		pending Kind // Kind of the value following the last key
+		values  int  // Top-level values, separated by newlines
+	)
+
+	// separate writes what precedes a key or value and returns the kind
+	// masking a value, if any
+	separate := func() Kind {
+		if len(stack) == 0 {
+			if values > 0 {
+				out.WriteByte('\n')
+			}
+			return ""
+		}
+		top := stack[len(stack)-1]
+		if top.object && !top.expectKey {
+			if pending != "" {
+				return pending
+			}
+			return top.kind
+		}
+		if top.count > 0 {
+			out.WriteByte(',')
+		}
+		return top.kind
+	}
+	// complete records that a value was written
+	complete := func() {
+		pending = ""
+		if len(stack) == 0 {
+			values++
+			return
+		}
+		top := stack[len(stack)-1]
+		top.count++
+		if top.object {
+			top.expectKey = true
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '{', '[':
+				kind := separate()
+				out.WriteByte(byte(tok))
+				pending = ""
+				stack = append(stack, &frame{object: tok == '{', expectKey: tok == '{', kind: kind})
+			default:
+				stack = stack[:len(stack)-1]
+				out.WriteByte(byte(tok))
+				complete()
+			}
+		case string:
+			if top := len(stack) - 1; top >= 0 && stack[top].object && stack[top].expectKey {
+				if stack[top].count > 0 {
+					out.WriteByte(',')
+				}
+				writeString(&out, tok)
+				out.WriteByte(':')
+				stack[top].expectKey = false
+				pending = fields[tok]
+				continue
+			}
+			if kind := separate(); kind != "" {
+				tok = Mask(kind, tok)
+			}
+			writeString(&out, tok)
+			complete()
+		default:
+			separate()
+			b, err := json.Marshal(tok)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(b)
+			complete()
+		}
+	}
+	if len(stack) > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return out.Bytes(), nil
+}
+
+// writeString writes s quoted and escaped as encoding/json does.
+func writeString(out *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	out.Write(b)
+}
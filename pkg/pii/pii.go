@@ -0,0 +1,383 @@
+// Package pii classifies personal data with struct tags and masks it in
+// responses, logs and recordings.
+//
+// Structs opt in per string field with a pii tag naming the kind of data:
+//
+//	type UserResponse struct {
+//		Email    string `json:"email" pii:"email"`
+//		FullName string `json:"full_name" pii:"name"`
+//	}
+//
+//	masked := pii.Masked(resp)              // typed copy, e.g. for logs
+//	fields, err := pii.JSONFields(resp)     // {"email": Email, "full_name": Name}
+//	body, err = pii.MaskJSON(body, fields)  // serialized responses
+//
+// Masking keeps enough of a value to recognize it ("j***@example.com",
+// "J*** D***", "+* *** *** **67"); secrets and unknown formats are
+// replaced with Redacted.
+package pii
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tag is the struct tag classifying a field as personal data.
+const Tag = "pii"
+
+// Redacted replaces values that keep nothing when masked.
+const Redacted = "[redacted]"
+
+// Kind classifies personal data, selecting how it is masked.
+type Kind string
+
+// Kinds of personal data.
+const (
+	Email   Kind = "email"   // Keeps the first character and the domain
+	Phone   Kind = "phone"   // Keeps the last two digits and the format
+	Name    Kind = "name"    // Keeps the initial of each word
+	Address Kind = "address" // Redacted
+	IP      Kind = "ip"      // Keeps the network: /16 for IPv4, /32 for IPv6
+	Secret  Kind = "secret"  // Redacted
+)
+
+// Keys classifies the logged keys that carry personal data.
+var Keys = map[string]Kind{
+	"email":     Email,
+	"phone":     Phone,
+	"full_name": Name,
+	"address":   Address,
+}
+
+// ParseKind parses the value of a pii tag; an empty tag is Secret.
+func ParseKind(tag string) (Kind, error) {
+	switch kind := Kind(strings.TrimSpace(tag)); kind {
+	case "":
+		return Secret, nil
+	case Email, Phone, Name, Address, IP, Secret:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("pii: unknown kind %q", tag)
+	}
+}
+
+// Mask returns s masked as personal data of kind. Empty strings stay empty
+// so masked responses still show which fields are unset.
+func Mask(kind Kind, s string) string {
+	if s == "" {
+		return s
+	}
+	switch kind {
+	case Email:
+		local, domain, ok := strings.Cut(s, "@")
+		if !ok || local == "" {
+			return Redacted
+		}
+		r, _ := utf8.DecodeRuneInString(local)
+		return string(r) + "***@" + domain
+	case Phone:
+		return maskPhone(s)
+	case Name:
+		words := strings.Fields(s)
+		for i, word := range words {
+			r, _ := utf8.DecodeRuneInString(word)
+			words[i] = string(r) + "***"
+		}
+		return strings.Join(words, " ")
+	case IP:
+		return maskIP(s)
+	default:
+		return Redacted
+	}
+}
+
+// maskPhone replaces all but the last two digits of s.
+func maskPhone(s string) string {
+	digits := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits < 4 {
+		return Redacted
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits--
+			if digits >= 2 {
+				r = '*'
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// maskIP keeps the network part of an IP address.
+func maskIP(s string) string {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return Redacted
+	}
+	if addr.Is4() {
+		b := addr.As4()
+		return fmt.Sprintf("%d.%d.*.*", b[0], b[1])
+	}
+	prefix, _ := addr.Prefix(32)
+	return strings.TrimSuffix(prefix.Addr().String(), "::") + "::*"
+}
+
+// Masked returns a copy of v with its tagged fields masked, descending
+// into nested structs, pointers, slices, arrays, maps and interfaces. v
+// itself is not modified; values without personal data are returned as is.
+func Masked(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !Contains(rv.Type()) {
+		return v
+	}
+	return masked(rv).Interface()
+}
+
+// masked returns a masked copy of v.
+func masked(v reflect.Value) reflect.Value {
+	if !Contains(v.Type()) {
+		return v
+	}
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out = reflect.New(v.Type().Elem())
+		out.Elem().Set(masked(v.Elem()))
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out.Set(masked(v.Elem()))
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(masked(v.Index(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(masked(v.Index(i)))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out = reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), masked(iter.Value()))
+		}
+	case reflect.Struct:
+		out.Set(v)
+		for _, f := range fieldsOf(v.Type()) {
+			field := out.Field(f.index)
+			if f.kind == "" {
+				field.Set(masked(v.Field(f.index)))
+				continue
+			}
+			maskValue(field, f.kind)
+		}
+	default:
+		return v
+	}
+	return out
+}
+
+// maskValue masks a tagged string, *string or []string field in place;
+// the pointer and slice are replaced, not written through.
+func maskValue(field reflect.Value, kind Kind) {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(Mask(kind, field.String()))
+	case reflect.Pointer:
+		if !field.IsNil() {
+			p := reflect.New(field.Type().Elem())
+			p.Elem().SetString(Mask(kind, field.Elem().String()))
+			field.Set(p)
+		}
+	case reflect.Slice:
+		if !field.IsNil() {
+			s := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+			for i := 0; i < field.Len(); i++ {
+				s.Index(i).SetString(Mask(kind, field.Index(i).String()))
+			}
+			field.Set(s)
+		}
+	}
+}
+
+// JSONFields returns the kinds of the tagged fields of the struct values,
+// by JSON name, including the fields of nested structs. It fails on tags
+// naming unknown kinds or fields that are not strings.
+func JSONFields(values ...any) (map[string]Kind, error) {
+	fields := make(map[string]Kind)
+	seen := make(map[reflect.Type]bool)
+	var collect func(t reflect.Type) error
+	collect = func(t reflect.Type) error {
+		t = elemType(t)
+		if t.Kind() != reflect.Struct || seen[t] {
+			return nil
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			tag, ok := sf.Tag.Lookup(Tag)
+			if !ok {
+				if err := collect(sf.Type); err != nil {
+					return err
+				}
+				continue
+			}
+			kind, err := ParseKind(tag)
+			if err != nil {
+				return fmt.Errorf("%w (field %s.%s)", err, t.Name(), sf.Name)
+			}
+			if !isStringLike(sf.Type) {
+				return fmt.Errorf("pii: field %s.%s: only string, *string and []string fields can be tagged", t.Name(), sf.Name)
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			fields[name] = kind
+		}
+		return nil
+	}
+	for _, v := range values {
+		if err := collect(reflect.TypeOf(v)); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// field is a struct field that is masked (kind set) or may contain masked
+// fields.
+type field struct {
+	index int
+	kind  Kind
+}
+
+// fieldCache holds the relevant fields per struct type.
+var fieldCache sync.Map
+
+// fieldsOf returns the relevant fields of struct type t. Tags naming
+// unknown kinds and tagged fields that are not strings are redacted.
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup(Tag)
+		if !ok {
+			if Contains(sf.Type) {
+				fields = append(fields, field{index: i})
+			}
+			continue
+		}
+		kind, err := ParseKind(tag)
+		if err != nil {
+			kind = Secret
+		}
+		if isStringLike(sf.Type) {
+			fields = append(fields, field{index: i, kind: kind})
+		}
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// containsCache holds whether values of a type may hold tagged fields.
+var containsCache sync.Map
+
+// Contains reports whether values of t may hold tagged fields. Interface
+// types may hold anything and are reported as containing them.
+func Contains(t reflect.Type) bool {
+	if cached, ok := containsCache.Load(t); ok {
+		return cached.(bool)
+	}
+	found := contains(t, make(map[reflect.Type]bool))
+	containsCache.Store(t, found)
+	return found
+}
+
+// contains reports whether values of t may hold tagged fields. Types
+// being visited are skipped so recursive types terminate; only the
+// result for the outermost type is therefore cached.
+func contains(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return contains(t.Elem(), visiting)
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			if _, tagged := sf.Tag.Lookup(Tag); tagged {
+				if isStringLike(sf.Type) {
+					return true
+				}
+			} else if contains(sf.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// elemType returns the type of the values held by pointers, slices,
+// arrays and maps of t.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isStringLike reports whether t is a string, *string or []string.
+func isStringLike(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
@@ -0,0 +1,109 @@
+package pii
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type contact struct {
+	Email  string   `json:"email" pii:"email"`
+	Phones []string `json:"phones,omitempty" pii:"phone"`
+	Note   *string  `json:"note,omitempty" pii:""`
+	Label  string   `json:"label"`
+}
+
+type account struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"full_name" pii:"name"`
+	Contact  contact   `json:"contact"`
+	Previous []contact `json:"previous"`
+	Extra    any       `json:"extra,omitempty"`
+	internal string
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		in   string
+		want string
+	}{
+		{Email, "jane@example.com", "j***@example.com"},
+		{Email, "not-an-email", Redacted},
+		{Phone, "+1 555 123 4567", "+* *** *** **67"},
+		{Phone, "12", Redacted},
+		{Name, "Jane  van Doe", "J*** v*** D***"},
+		{IP, "192.168.10.20", "192.168.*.*"},
+		{IP, "2001:db8:85a3::8a2e:370:7334", "2001:db8::*"},
+		{Address, "1 Main St", Redacted},
+		{Secret, "hunter2", Redacted},
+		{Email, "", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Mask(tt.kind, tt.in), "%s %q", tt.kind, tt.in)
+	}
+}
+
+func TestMasked(t *testing.T) {
+	note := "call after 5pm"
+	in := account{
+		ID:       1,
+		Name:     "Jane Doe",
+		Contact:  contact{Email: "jane@example.com", Phones: []string{"+1 555 123 4567"}, Note: &note, Label: "home"},
+		Previous: []contact{{Email: "j.doe@example.org"}},
+		Extra:    &contact{Email: "extra@example.com"},
+		internal: "kept",
+	}
+
+	out, ok := Masked(in).(account)
+	require.True(t, ok)
+	assert.Equal(t, 1, out.ID)
+	assert.Equal(t, "J*** D***", out.Name)
+	assert.Equal(t, "j***@example.com", out.Contact.Email)
+	assert.Equal(t, []string{"+* *** *** **67"}, out.Contact.Phones)
+	assert.Equal(t, Redacted, *out.Contact.Note)
+	assert.Equal(t, "home", out.Contact.Label)
+	assert.Equal(t, "j***@example.org", out.Previous[0].Email)
+	assert.Equal(t, "e***@example.com", out.Extra.(*contact).Email)
+	assert.Equal(t, "kept", out.internal)
+
+	// The original is untouched, including shared slices and pointers
+	assert.Equal(t, "jane@example.com", in.Contact.Email)
+	assert.Equal(t, "+1 555 123 4567", in.Contact.Phones[0])
+	assert.Equal(t, "call after 5pm", note)
+	assert.Equal(t, "j.doe@example.org", in.Previous[0].Email)
+
+	plain := struct{ Name string }{"Jane"}
+	assert.Equal(t, plain, Masked(plain))
+	assert.Nil(t, Masked(nil))
+}
+
+func TestJSONFields(t *testing.T) {
+	fields, err := JSONFields(account{}, []contact{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Kind{"full_name": Name, "email": Email, "phones": Phone, "note": Secret}, fields)
+
+	_, err = JSONFields(struct {
+		Age int `pii:"secret"`
+	}{})
+	assert.Error(t, err)
+	_, err = JSONFields(struct {
+		SSN string `pii:"ssn"`
+	}{})
+	assert.Error(t, err)
+}
+
+func TestMaskJSON(t *testing.T) {
+	fields := map[string]Kind{"email": Email, "full_name": Name}
+	in := `{"items":[{"id":1,"email":"jane@example.com","full_name":"Jane Doe","score":1.50,"active":true,"x":null},` +
+		`{"id":2,"email":"","highlights":{"email":["<em>john</em>@example.com"]}}],"total":2}`
+
+	out, err := MaskJSON([]byte(in), fields)
+	require.NoError(t, err)
+	assert.Equal(t, `{"items":[{"id":1,"email":"j***@example.com","full_name":"J*** D***","score":1.50,"active":true,"x":null},`+
+		`{"id":2,"email":"","highlights":{"email":["\u003c***@example.com"]}}],"total":2}`, string(out))
+
+	_, err = MaskJSON([]byte(`{"email":`), fields)
+	assert.Error(t, err)
+}
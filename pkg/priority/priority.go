@@ -0,0 +1,186 @@
+// Package priority admits requests per traffic class so that a saturated
+// class cannot starve the others.
+//
+// Every class has its own concurrency budget and a bounded queue of
+// waiting requests. Requests beyond the budget wait in arrival order until
+// a slot frees, their wait times out or the queue is full:
+//
+//	queue := priority.New([]priority.Class{
+//		{Name: "admin", Concurrency: 16, QueueSize: 64},
+//		{Name: "public", Concurrency: 256, QueueSize: 1024, MaxWait: 2 * time.Second},
+//	}, priority.NewMetrics(registry))
+//	release, err := queue.Acquire(ctx, "public")
+package priority
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Errors returned by Acquire.
+var (
+	ErrQueueFull    = errors.New("priority queue full")
+	ErrQueueTimeout = errors.New("priority queue wait timed out")
+	ErrUnknownClass = errors.New("unknown priority class")
+)
+
+// Class declares the budget of a traffic class.
+type Class struct {
+	Name        string
+	Concurrency int           // Requests served concurrently (minimum 1)
+	QueueSize   int           // Requests waiting for a slot; 0 rejects when busy
+	MaxWait     time.Duration // Longest wait for a slot; 0 waits until the context ends
+}
+
+// Queue admits requests per class. It is safe for concurrent use.
+type Queue struct {
+	classes map[string]*class
+	metrics *Metrics
+}
+
+// class is the admission state of one class.
+type class struct {
+	Class
+	slots chan struct{}
+
+	mu      sync.Mutex
+	waiting int
+}
+
+// New creates a queue for classes.
+func New(classes []Class, metrics *Metrics) *Queue {
+	q := &Queue{classes: make(map[string]*class, len(classes)), metrics: metrics}
+	for _, c := range classes {
+		c.Concurrency = max(c.Concurrency, 1)
+		q.classes[c.Name] = &class{Class: c, slots: make(chan struct{}, c.Concurrency)}
+	}
+	return q
+}
+
+// Acquire waits for a slot of the named class. The returned release
+// function must be called once the request is served.
+func (q *Queue) Acquire(ctx context.Context, name string) (release func(), err error) {
+	c, ok := q.classes[name]
+	if !ok {
+		return nil, ErrUnknownClass
+	}
+	release = func() {
+		<-c.slots
+		q.metrics.released(name)
+	}
+
+	// Fast path: a slot is free
+	select {
+	case c.slots <- struct{}{}:
+		q.metrics.admitted(name, 0)
+		return release, nil
+	default:
+	}
+
+	c.mu.Lock()
+	if c.waiting >= c.QueueSize {
+		c.mu.Unlock()
+		q.metrics.rejected(name, "full")
+		return nil, ErrQueueFull
+	}
+	c.waiting++
+	c.mu.Unlock()
+	q.metrics.queued(name, 1)
+	defer func() {
+		c.mu.Lock()
+		c.waiting--
+		c.mu.Unlock()
+		q.metrics.queued(name, -1)
+	}()
+
+	var timeout <-chan time.Time
+	if c.MaxWait > 0 {
+		timer := time.NewTimer(c.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	start := time.Now()
+	select {
+	case c.slots <- struct{}{}:
+		q.metrics.admitted(name, time.Since(start))
+		return release, nil
+	case <-timeout:
+		q.metrics.rejected(name, "timeout")
+		return nil, ErrQueueTimeout
+	case <-ctx.Done():
+		q.metrics.rejected(name, "canceled")
+		return nil, ctx.Err()
+	}
+}
+
+// Metrics holds the queue collectors. A nil *Metrics records nothing.
+type Metrics struct {
+	wait       *prometheus.HistogramVec
+	rejections *prometheus.CounterVec
+	depth      *prometheus.GaugeVec
+	inFlight   *prometheus.GaugeVec
+}
+
+// NewMetrics creates the queue collectors and registers them.
+// It panics if the collectors are already registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		wait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_server_queue_wait_seconds",
+			Help:    "Time admitted requests waited for a slot of their priority class.",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"class"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_queue_rejections_total",
+			Help: "Requests rejected by their priority class (full, timeout, canceled).",
+		}, []string{"class", "reason"}),
+		depth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_server_queue_depth",
+			Help: "Requests waiting for a slot by priority class.",
+		}, []string{"class"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_server_queue_in_flight",
+			Help: "Requests holding a slot by priority class.",
+		}, []string{"class"}),
+	}
+	reg.MustRegister(m.wait, m.rejections, m.depth, m.inFlight)
+	return m
+}
+
+// admitted records a request granted a slot after waiting.
+func (m *Metrics) admitted(class string, waited time.Duration) {
+	if m == nil {
+		return
+	}
+	m.wait.WithLabelValues(class).Observe(waited.Seconds())
+	m.inFlight.WithLabelValues(class).Inc()
+}
+
+// released records a request giving its slot back.
+func (m *Metrics) released(class string) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(class).Dec()
+}
+
+// queued records requests starting (1) or ending (-1) a wait.
+func (m *Metrics) queued(class string, delta float64) {
+	if m == nil {
+		return
+	}
+	m.depth.WithLabelValues(class).Add(delta)
+}
+
+// rejected records a request denied a slot.
+func (m *Metrics) rejected(class, reason string) {
+	if m == nil {
+		return
+	}
+	m.rejections.WithLabelValues(class, reason).Inc()
+}
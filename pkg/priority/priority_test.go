@@ -0,0 +1,80 @@
+package priority
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_ClassesHaveSeparateBudgets(t *testing.T) {
+	q := New([]Class{{Name: "admin", Concurrency: 1}, {Name: "public", Concurrency: 1}}, nil)
+	ctx := context.Background()
+
+	release, err := q.Acquire(ctx, "public")
+	require.NoError(t, err)
+	_, err = q.Acquire(ctx, "public")
+	assert.ErrorIs(t, err, ErrQueueFull, "a saturated class without a queue rejects")
+
+	adminRelease, err := q.Acquire(ctx, "admin")
+	require.NoError(t, err, "admin traffic is served while public is saturated")
+	adminRelease()
+
+	release()
+	release, err = q.Acquire(ctx, "public")
+	require.NoError(t, err)
+	release()
+
+	_, err = q.Acquire(ctx, "batch")
+	assert.ErrorIs(t, err, ErrUnknownClass)
+}
+
+func TestQueue_WaitsForSlot(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	q := New([]Class{{Name: "public", Concurrency: 1, QueueSize: 1, MaxWait: time.Second}}, metrics)
+	ctx := context.Background()
+
+	release, err := q.Acquire(ctx, "public")
+	require.NoError(t, err)
+
+	admitted := make(chan error, 1)
+	go func() {
+		next, err := q.Acquire(ctx, "public")
+		if err == nil {
+			next()
+		}
+		admitted <- err
+	}()
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.depth.WithLabelValues("public")) == 1
+	}, time.Second, time.Millisecond)
+
+	_, err = q.Acquire(ctx, "public")
+	assert.ErrorIs(t, err, ErrQueueFull, "the queue holds one waiting request")
+
+	release()
+	require.NoError(t, <-admitted)
+	assert.Zero(t, testutil.ToFloat64(metrics.depth.WithLabelValues("public")))
+	assert.Zero(t, testutil.ToFloat64(metrics.inFlight.WithLabelValues("public")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.rejections.WithLabelValues("public", "full")))
+}
+
+func TestQueue_TimesOutAndHonorsContext(t *testing.T) {
+	q := New([]Class{{Name: "public", Concurrency: 1, QueueSize: 2, MaxWait: 10 * time.Millisecond}}, nil)
+
+	release, err := q.Acquire(context.Background(), "public")
+	require.NoError(t, err)
+	defer release()
+
+	_, err = q.Acquire(context.Background(), "public")
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.Acquire(ctx, "public")
+	assert.ErrorIs(t, err, context.Canceled)
+}
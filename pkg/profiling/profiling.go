@@ -0,0 +1,415 @@
+// Package profiling captures runtime profiles on demand and keeps them in a
+// Store, so operators can fetch pprof data without exec-ing into containers.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kind names a runtime profile.
+type Kind string
+
+// Supported profile kinds. CPU, block and mutex profiles sample for the
+// requested duration; heap and goroutine profiles are snapshots.
+const (
+	KindCPU       Kind = "cpu"
+	KindHeap      Kind = "heap"
+	KindBlock     Kind = "block"
+	KindMutex     Kind = "mutex"
+	KindGoroutine Kind = "goroutine"
+)
+
+// Capture states.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Defaults applied to zero-valued Options fields.
+const (
+	DefaultMaxDuration = time.Minute
+	DefaultMaxCaptures = 20
+)
+
+// linkTTL is the validity of download links handed out by a Linker.
+const linkTTL = 5 * time.Minute
+
+var (
+	// ErrBusy is returned when a capture is already running.
+	ErrBusy = errors.New("a profile capture is already running")
+
+	// ErrUnknownKind is returned for unsupported profile kinds.
+	ErrUnknownKind = errors.New("unknown profile kind")
+
+	// ErrNotFound is returned for unknown or still running captures.
+	ErrNotFound = errors.New("profile not found")
+
+	// ErrNoDuration is returned for sampled kinds requested without a
+	// duration, which would record an empty profile.
+	ErrNoDuration = errors.New("sampled profiles need a duration")
+)
+
+// Capture describes a requested profile.
+type Capture struct {
+	ID          string     `json:"id" example:"cpu-20240115T103000-1a2b3c4d"`
+	Kind        Kind       `json:"kind" example:"cpu"`
+	Seconds     int        `json:"seconds" example:"30"`
+	Status      string     `json:"status" example:"completed"`
+	Error       string     `json:"error,omitempty"`
+	Size        int        `json:"size_bytes,omitempty" example:"24576"`
+	Location    string     `json:"location,omitempty" example:"profiles/cpu-20240115T103000-1a2b3c4d.pprof"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Store persists captured profiles.
+// FileStore keeps them on local disk; ObjectStore keeps them in object
+// storage.
+type Store interface {
+	// Save stores data under name and returns where it was written.
+	Save(ctx context.Context, name string, data []byte) (location string, err error)
+	// Open returns the profile stored under name.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Delete removes the profile stored under name.
+	Delete(ctx context.Context, name string) error
+}
+
+// Linker is implemented by stores handing out download links; profiles of
+// such stores are fetched from the link rather than through Open.
+type Linker interface {
+	// URL returns a link downloading the profile stored under name for ttl.
+	URL(ctx context.Context, name string, ttl time.Duration) (string, error)
+}
+
+// Options configures a Profiler.
+type Options struct {
+	MaxDuration time.Duration // Upper bound for sampling profiles (default 1m)
+	MaxCaptures int           // Captures retained before the oldest are deleted (default 20)
+
+	// IndexFile keeps the capture index across restarts; empty keeps it
+	// in memory only.
+	IndexFile string
+
+	// BlockProfileRate is the rate the process otherwise samples blocking
+	// events at, restored after block captures. The runtime does not
+	// report it, unlike the mutex profile fraction.
+	BlockProfileRate int
+}
+
+// Profiler runs one capture at a time and tracks recent captures.
+type Profiler struct {
+	store Store
+	opts  Options
+
+	mu       sync.Mutex
+	running  bool
+	captures map[string]*Capture
+	order    []string
+}
+
+// New creates a profiler writing to store, loading the captures recorded
+// in opts.IndexFile. Captures that were running when the index was last
+// written are marked failed.
+func New(store Store, opts Options) (*Profiler, error) {
+	if opts.MaxDuration <= 0 {
+		opts.MaxDuration = DefaultMaxDuration
+	}
+	if opts.MaxCaptures <= 0 {
+		opts.MaxCaptures = DefaultMaxCaptures
+	}
+	p := &Profiler{
+		store:    store,
+		opts:     opts,
+		captures: make(map[string]*Capture),
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MaxDuration returns the longest accepted sampling duration.
+func (p *Profiler) MaxDuration() time.Duration { return p.opts.MaxDuration }
+
+// Start begins capturing a profile in the background and returns its
+// descriptor. Durations are clamped to MaxDuration and ignored for snapshot
+// kinds, which sampled kinds require. Cancelling ctx ends the capture early, so callers pass a context
+// that outlives the triggering request.
+func (p *Profiler) Start(ctx context.Context, kind Kind, duration time.Duration) (Capture, error) {
+	if !kind.valid() {
+		return Capture{}, fmt.Errorf("%w %q", ErrUnknownKind, kind)
+	}
+	if !kind.sampled() {
+		duration = 0
+	} else if duration <= 0 {
+		return Capture{}, ErrNoDuration
+	}
+	duration = min(duration, p.opts.MaxDuration)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		return Capture{}, ErrBusy
+	}
+
+	now := time.Now().UTC()
+	capture := &Capture{
+		ID:        newID(kind, now),
+		Kind:      kind,
+		Seconds:   int(duration / time.Second),
+		Status:    StatusRunning,
+		StartedAt: now,
+	}
+	p.captures[capture.ID] = capture
+	p.order = append(p.order, capture.ID)
+	if err := p.save(); err != nil {
+		delete(p.captures, capture.ID)
+		p.order = p.order[:len(p.order)-1]
+		return Capture{}, err
+	}
+	p.running = true
+
+	go p.run(ctx, capture.ID, kind, duration)
+
+	return *capture, nil
+}
+
+// Get returns the capture with the given ID.
+func (p *Profiler) Get(id string) (Capture, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	capture, ok := p.captures[id]
+	if !ok {
+		return Capture{}, false
+	}
+	return *capture, true
+}
+
+// List returns the retained captures, newest first.
+func (p *Profiler) List() []Capture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]Capture, 0, len(p.captures))
+	for _, capture := range p.captures {
+		out = append(out, *capture)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out
+}
+
+// Open returns the data of a completed capture.
+func (p *Profiler) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	capture, ok := p.Get(id)
+	if !ok || capture.Status != StatusCompleted {
+		return nil, ErrNotFound
+	}
+	return p.store.Open(ctx, fileName(id))
+}
+
+// Link returns a download link for a completed capture, or "" when the
+// store does not hand out links and the data is read through Open.
+func (p *Profiler) Link(ctx context.Context, id string) (string, error) {
+	capture, ok := p.Get(id)
+	if !ok || capture.Status != StatusCompleted {
+		return "", ErrNotFound
+	}
+	linker, ok := p.store.(Linker)
+	if !ok {
+		return "", nil
+	}
+	return linker.URL(ctx, fileName(id), linkTTL)
+}
+
+// run performs the capture and records its outcome.
+func (p *Profiler) run(ctx context.Context, id string, kind Kind, duration time.Duration) {
+	data, err := collect(ctx, kind, duration, p.opts.BlockProfileRate)
+
+	var location string
+	if err == nil {
+		location, err = p.store.Save(ctx, fileName(id), data)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running = false
+	capture := p.captures[id]
+	completed := time.Now().UTC()
+	capture.CompletedAt = &completed
+	if err != nil {
+		capture.Status = StatusFailed
+		capture.Error = err.Error()
+	} else {
+		capture.Status = StatusCompleted
+		capture.Size = len(data)
+		capture.Location = location
+	}
+	p.evict(ctx)
+	// A failed write is retried with the next capture, which rewrites
+	// the whole index
+	_ = p.save()
+}
+
+// evict drops the oldest captures beyond MaxCaptures. Callers hold p.mu.
+func (p *Profiler) evict(ctx context.Context) {
+	for len(p.order) > p.opts.MaxCaptures {
+		id := p.order[0]
+		p.order = p.order[1:]
+		if p.captures[id].Status == StatusCompleted {
+			_ = p.store.Delete(ctx, fileName(id))
+		}
+		delete(p.captures, id)
+	}
+}
+
+// load restores the captures of the index file. Callers own p.
+func (p *Profiler) load() error {
+	if p.opts.IndexFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(p.opts.IndexFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read profile index: %w", err)
+	}
+	var captures []*Capture
+	if err := json.Unmarshal(data, &captures); err != nil {
+		return fmt.Errorf("failed to decode profile index: %w", err)
+	}
+	for _, capture := range captures {
+		if capture.Status == StatusRunning {
+			capture.Status = StatusFailed
+			capture.Error = "interrupted by a restart"
+		}
+		p.captures[capture.ID] = capture
+		p.order = append(p.order, capture.ID)
+	}
+	p.evict(context.Background())
+	return nil
+}
+
+// save writes the captures to the index file, oldest first, replacing it
+// atomically. Callers hold p.mu.
+func (p *Profiler) save() error {
+	if p.opts.IndexFile == "" {
+		return nil
+	}
+	captures := make([]*Capture, len(p.order))
+	for i, id := range p.order {
+		captures[i] = p.captures[id]
+	}
+	data, err := json.Marshal(captures)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p.opts.IndexFile), ".index-*")
+	if err != nil {
+		return fmt.Errorf("failed to write profile index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write profile index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write profile index: %w", err)
+	}
+	return os.Rename(tmp.Name(), p.opts.IndexFile)
+}
+
+// collect records a profile of the given kind in pprof format. Block
+// captures restore blockRate afterwards.
+func collect(ctx context.Context, kind Kind, duration time.Duration, blockRate int) ([]byte, error) {
+	var buf bytes.Buffer
+	switch kind {
+	case KindCPU:
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return nil, err
+		}
+		wait(ctx, duration)
+		pprof.StopCPUProfile()
+	case KindBlock:
+		runtime.SetBlockProfileRate(1)
+		wait(ctx, duration)
+		err := pprof.Lookup("block").WriteTo(&buf, 0)
+		runtime.SetBlockProfileRate(blockRate)
+		if err != nil {
+			return nil, err
+		}
+	case KindMutex:
+		previous := runtime.SetMutexProfileFraction(1)
+		wait(ctx, duration)
+		err := pprof.Lookup("mutex").WriteTo(&buf, 0)
+		runtime.SetMutexProfileFraction(previous)
+		if err != nil {
+			return nil, err
+		}
+	case KindHeap:
+		runtime.GC()
+		if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+	case KindGoroutine:
+		if err := pprof.Lookup("goroutine").WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), ctx.Err()
+}
+
+// wait sleeps for d or until ctx is done.
+func wait(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (k Kind) valid() bool {
+	switch k {
+	case KindCPU, KindHeap, KindBlock, KindMutex, KindGoroutine:
+		return true
+	default:
+		return false
+	}
+}
+
+// sampled reports whether the kind records over a duration.
+func (k Kind) sampled() bool {
+	return k == KindCPU || k == KindBlock || k == KindMutex
+}
+
+// newID returns a sortable, unique capture ID.
+func newID(kind Kind, at time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%s-%s", kind, at.Format("20060102T150405"), hex.EncodeToString(b))
+}
+
+// fileName returns the store name for a capture.
+func fileName(id string) string {
+	return id + ".pprof"
+}
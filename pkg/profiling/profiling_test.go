@@ -0,0 +1,214 @@
+package profiling
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProfiler(t *testing.T, opts Options) *Profiler {
+	t.Helper()
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	p, err := New(store, opts)
+	require.NoError(t, err)
+	return p
+}
+
+// waitDone polls until the capture leaves the running state.
+func waitDone(t *testing.T, p *Profiler, id string) Capture {
+	t.Helper()
+	var capture Capture
+	require.Eventually(t, func() bool {
+		capture, _ = p.Get(id)
+		return capture.Status != StatusRunning
+	}, 5*time.Second, 5*time.Millisecond)
+	return capture
+}
+
+func TestStart_CapturesProfile(t *testing.T) {
+	for _, kind := range []Kind{KindCPU, KindHeap, KindBlock, KindMutex, KindGoroutine} {
+		t.Run(string(kind), func(t *testing.T) {
+			p := newTestProfiler(t, Options{MaxDuration: 50 * time.Millisecond})
+
+			started, err := p.Start(context.Background(), kind, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, StatusRunning, started.Status)
+
+			capture := waitDone(t, p, started.ID)
+			require.Equal(t, StatusCompleted, capture.Status, capture.Error)
+			assert.Positive(t, capture.Size)
+			assert.NotEmpty(t, capture.Location)
+
+			data, err := p.Open(context.Background(), capture.ID)
+			require.NoError(t, err)
+			defer data.Close()
+			body, err := io.ReadAll(data)
+			require.NoError(t, err)
+			assert.Len(t, body, capture.Size)
+		})
+	}
+}
+
+func TestStart_ClampsDuration(t *testing.T) {
+	p := newTestProfiler(t, Options{MaxDuration: 2 * time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cpu, err := p.Start(ctx, KindCPU, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cpu.Seconds)
+	cancel()
+	waitDone(t, p, cpu.ID)
+
+	// Snapshot kinds ignore the duration
+	heap, err := p.Start(context.Background(), KindHeap, time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, heap.Seconds)
+	waitDone(t, p, heap.ID)
+}
+
+func TestStart_Busy(t *testing.T) {
+	p := newTestProfiler(t, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first, err := p.Start(ctx, KindBlock, time.Minute)
+	require.NoError(t, err)
+
+	_, err = p.Start(context.Background(), KindHeap, 0)
+	assert.ErrorIs(t, err, ErrBusy)
+
+	_, err = p.Open(context.Background(), first.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	cancel()
+	assert.Equal(t, StatusFailed, waitDone(t, p, first.ID).Status)
+}
+
+func TestStart_RequiresDurationForSampledKinds(t *testing.T) {
+	p := newTestProfiler(t, Options{})
+	for _, kind := range []Kind{KindCPU, KindBlock, KindMutex} {
+		_, err := p.Start(context.Background(), kind, 0)
+		assert.ErrorIs(t, err, ErrNoDuration, kind)
+	}
+	assert.Empty(t, p.List())
+}
+
+func TestStart_RestoresMutexFraction(t *testing.T) {
+	previous := runtime.SetMutexProfileFraction(5)
+	defer runtime.SetMutexProfileFraction(previous)
+
+	p := newTestProfiler(t, Options{MaxDuration: 10 * time.Millisecond})
+	capture, err := p.Start(context.Background(), KindMutex, time.Second)
+	require.NoError(t, err)
+	waitDone(t, p, capture.ID)
+
+	assert.Equal(t, 5, runtime.SetMutexProfileFraction(-1))
+}
+
+func TestStart_UnknownKind(t *testing.T) {
+	p := newTestProfiler(t, Options{})
+	_, err := p.Start(context.Background(), "threads", 0)
+	assert.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func TestList_EvictsOldest(t *testing.T) {
+	p := newTestProfiler(t, Options{MaxCaptures: 2})
+
+	ids := make([]string, 3)
+	for i := range ids {
+		capture, err := p.Start(context.Background(), KindGoroutine, 0)
+		require.NoError(t, err)
+		waitDone(t, p, capture.ID)
+		ids[i] = capture.ID
+	}
+
+	captures := p.List()
+	require.Len(t, captures, 2)
+	_, ok := p.Get(ids[0])
+	assert.False(t, ok)
+	_, err := p.store.Open(context.Background(), fileName(ids[0]))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNew_RestoresIndex(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+	opts := Options{IndexFile: filepath.Join(dir, "index.json")}
+
+	first, err := New(store, opts)
+	require.NoError(t, err)
+	capture, err := first.Start(context.Background(), KindGoroutine, 0)
+	require.NoError(t, err)
+	waitDone(t, first, capture.ID)
+
+	restarted, err := New(store, opts)
+	require.NoError(t, err)
+	restored, ok := restarted.Get(capture.ID)
+	require.True(t, ok)
+	assert.Equal(t, StatusCompleted, restored.Status)
+	data, err := restarted.Open(context.Background(), capture.ID)
+	require.NoError(t, err)
+	data.Close()
+}
+
+func TestNew_FailsInterruptedCaptures(t *testing.T) {
+	index := filepath.Join(t.TempDir(), "index.json")
+	require.NoError(t, os.WriteFile(index, []byte(`[{"id":"cpu-1","kind":"cpu","status":"running"}]`), 0o600))
+
+	p, err := New(nil, Options{IndexFile: index})
+	require.NoError(t, err)
+	capture, ok := p.Get("cpu-1")
+	require.True(t, ok)
+	assert.Equal(t, StatusFailed, capture.Status)
+	assert.NotEmpty(t, capture.Error)
+}
+
+// memObjects is an in-memory Objects.
+type memObjects struct {
+	data map[string][]byte
+}
+
+func (m *memObjects) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	data, err := io.ReadAll(r)
+	m.data[key] = data
+	return err
+}
+
+func (m *memObjects) URL(_ context.Context, key string, _ time.Duration) (string, error) {
+	return "https://bucket.example.com/" + key, nil
+}
+
+func (m *memObjects) Delete(_ context.Context, key string) error {
+	delete(m.data, key)
+	return nil
+}
+
+func TestObjectStore_LinksCaptures(t *testing.T) {
+	objects := &memObjects{data: map[string][]byte{}}
+	p, err := New(NewObjectStore(objects, "profiles/"), Options{MaxCaptures: 1})
+	require.NoError(t, err)
+
+	first, err := p.Start(context.Background(), KindHeap, 0)
+	require.NoError(t, err)
+	capture := waitDone(t, p, first.ID)
+	require.Equal(t, StatusCompleted, capture.Status, capture.Error)
+	assert.Equal(t, "profiles/"+first.ID+".pprof", capture.Location)
+	assert.Len(t, objects.data[capture.Location], capture.Size)
+
+	link, err := p.Link(context.Background(), first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://bucket.example.com/"+capture.Location, link)
+
+	second, err := p.Start(context.Background(), KindHeap, 0)
+	require.NoError(t, err)
+	waitDone(t, p, second.ID)
+	assert.NotContains(t, objects.data, capture.Location, "evicted captures are deleted")
+}
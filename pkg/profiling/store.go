@@ -0,0 +1,109 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileStore keeps profiles as files in a local directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store writing to dir, creating it if missing.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(_ context.Context, name string, data []byte) (string, error) {
+	path := filepath.Join(s.dir, filepath.Base(name))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Open implements Store.
+func (s *FileStore) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.Base(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(_ context.Context, name string) error {
+	err := os.Remove(filepath.Join(s.dir, filepath.Base(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Objects is the part of an object storage client ObjectStore writes to.
+type Objects interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	URL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// errLinkOnly is returned by ObjectStore.Open; its profiles are downloaded
+// through the links of URL.
+var errLinkOnly = errors.New("object storage profiles are downloaded through links")
+
+// ObjectStore keeps profiles as objects under a key prefix. It implements
+// Linker, so downloads go to the object storage directly.
+type ObjectStore struct {
+	objects Objects
+	prefix  string
+}
+
+// NewObjectStore creates a store writing under prefix.
+func NewObjectStore(objects Objects, prefix string) *ObjectStore {
+	return &ObjectStore{objects: objects, prefix: strings.Trim(prefix, "/")}
+}
+
+// Save implements Store and returns the object key.
+func (s *ObjectStore) Save(ctx context.Context, name string, data []byte) (string, error) {
+	key := s.key(name)
+	if err := s.objects.Put(ctx, key, bytes.NewReader(data), "application/octet-stream"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Open implements Store. Object storage profiles are read through URL.
+func (s *ObjectStore) Open(context.Context, string) (io.ReadCloser, error) {
+	return nil, errLinkOnly
+}
+
+// Delete implements Store.
+func (s *ObjectStore) Delete(ctx context.Context, name string) error {
+	return s.objects.Delete(ctx, s.key(name))
+}
+
+// URL implements Linker.
+func (s *ObjectStore) URL(ctx context.Context, name string, ttl time.Duration) (string, error) {
+	return s.objects.URL(ctx, s.key(name), ttl)
+}
+
+// key returns the object key of name.
+func (s *ObjectStore) key(name string) string {
+	return path.Join(s.prefix, path.Base(name))
+}
@@ -0,0 +1,244 @@
+// Package proxy forwards requests matching a path prefix to an upstream
+// service, adding header rewriting, timeouts, retries and circuit breaking
+// on top of httputil.ReverseProxy.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/breaker"
+	"github.com/luminosita/change-me/pkg/httpclient"
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Defaults applied to zero-valued Route fields.
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultRetryBackoff = 100 * time.Millisecond
+)
+
+// ErrorCode is the error code of JSON bodies written when an upstream fails.
+const ErrorCode = "upstream_unavailable"
+
+// BreakerConfig configures a route's circuit breaker.
+type BreakerConfig struct {
+	Failures int           `yaml:"failures" validate:"min=0"` // Consecutive failures before opening (0 disables)
+	Cooldown time.Duration `yaml:"cooldown" validate:"min=0"` // Time the circuit stays open (default 30s)
+}
+
+// Route declares a proxied path prefix.
+type Route struct {
+	Name string `yaml:"name" validate:"required"`
+
+	// Prefix is matched on segment boundaries: "/search" covers
+	// "/search/docs" but not "/searches".
+	Prefix       string        `yaml:"prefix" validate:"required,startswith=/"`
+	Upstream     string        `yaml:"upstream" validate:"required,url"`
+	Client       string        `yaml:"client"` // Named outbound client (default client when empty)
+	StripPrefix  bool          `yaml:"strip_prefix"`
+	Timeout      time.Duration `yaml:"timeout" validate:"min=0"`
+	Retries      int           `yaml:"retries" validate:"min=0,max=5"`
+	RetryBackoff time.Duration `yaml:"retry_backoff" validate:"min=0"`
+
+	// RequestHeaders and ResponseHeaders are set on the forwarded request
+	// and the returned response; an empty value removes the header.
+	RequestHeaders  map[string]string `yaml:"request_headers"`
+	ResponseHeaders map[string]string `yaml:"response_headers"`
+
+	Breaker BreakerConfig `yaml:"breaker"`
+}
+
+// Matches reports whether path falls under the route prefix.
+func (r Route) Matches(path string) bool {
+	rest, ok := strings.CutPrefix(path, r.Prefix)
+	return ok && (rest == "" || rest[0] == '/' || strings.HasSuffix(r.Prefix, "/"))
+}
+
+// retryableStatus lists upstream statuses retried for idempotent requests.
+var retryableStatus = map[int]bool{
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// Option configures a route handler.
+type Option func(*retryTransport)
+
+// WithMetrics reports retries and breaker state of the route's upstream.
+func WithMetrics(metrics *httpclient.Metrics) Option {
+	return func(t *retryTransport) { t.metrics = metrics }
+}
+
+// WithFallback degrades requests failing after retries and the circuit
+// breaker gave up, typically with httpclient.Registry.Fallback.
+func WithFallback(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(t *retryTransport) { t.fallback = wrap }
+}
+
+// New creates the handler for a route.
+//
+// Parameters:
+//   - route: Route declaration
+//   - transport: Upstream transport (nil uses http.DefaultTransport)
+//   - log: Structured logger
+//   - opts: Optional settings
+//
+// Returns:
+//   - http.Handler: Proxy handler
+//   - error: Invalid upstream URL
+func New(route Route, transport http.RoundTripper, log *logger.Logger, opts ...Option) (http.Handler, error) {
+	target, err := url.Parse(route.Upstream)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("proxy route %q: invalid upstream %q", route.Name, route.Upstream)
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if route.Timeout <= 0 {
+		route.Timeout = DefaultTimeout
+	}
+	if route.RetryBackoff <= 0 {
+		route.RetryBackoff = DefaultRetryBackoff
+	}
+
+	rt := &retryTransport{next: transport, route: route, host: target.Host}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	if route.Breaker.Failures > 0 {
+		rt.breaker = breaker.New(route.Breaker.Failures, route.Breaker.Cooldown)
+		rt.metrics.SetBreakerState(rt.host, breaker.Closed)
+	}
+
+	var upstream http.RoundTripper = rt
+	if rt.fallback != nil {
+		upstream = rt.fallback(rt)
+	}
+
+	rp := &httputil.ReverseProxy{
+		Transport: upstream,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if route.StripPrefix {
+				pr.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(pr.In.URL.Path, route.Prefix), "/")
+				pr.Out.URL.RawPath = ""
+			}
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			applyHeaders(pr.Out.Header, route.RequestHeaders)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			applyHeaders(resp.Header, route.ResponseHeaders)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			status := http.StatusBadGateway
+			switch {
+			case errors.Is(err, breaker.ErrOpen):
+				status = http.StatusServiceUnavailable
+			case errors.Is(err, context.DeadlineExceeded):
+				status = http.StatusGatewayTimeout
+			case errors.Is(err, context.Canceled):
+				return
+			}
+			log.Warnw("proxy_request_failed", "route", route.Name, "path", r.URL.Path, "status", status, "error", err)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(status)
+			_, _ = fmt.Fprintf(w, `{"error":%q,"code":%q,"message":%q}`, ErrorCode, ErrorCode, route.Name+" upstream unavailable")
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), route.Timeout)
+		defer cancel()
+		rp.ServeHTTP(w, r.WithContext(ctx))
+	}), nil
+}
+
+// applyHeaders sets headers, deleting those with empty values.
+func applyHeaders(h http.Header, values map[string]string) {
+	for name, value := range values {
+		if value == "" {
+			h.Del(name)
+			continue
+		}
+		h.Set(name, value)
+	}
+}
+
+// retryTransport retries idempotent requests and guards the upstream with
+// an optional circuit breaker.
+type retryTransport struct {
+	next     http.RoundTripper
+	route    Route
+	host     string
+	breaker  *breaker.Breaker
+	metrics  *httpclient.Metrics
+	fallback func(http.RoundTripper) http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if idempotent(req) {
+		attempts += t.route.Retries
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(t.route.RetryBackoff * time.Duration(attempt)):
+			}
+			t.metrics.ObserveRetry(t.host)
+		}
+
+		var call breaker.Call
+		if t.breaker != nil {
+			call, err = t.breaker.Allow()
+			t.metrics.SetBreakerState(t.host, t.breaker.State())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err = t.next.RoundTrip(req)
+		failed := err != nil || retryableStatus[resp.StatusCode]
+		if t.breaker != nil {
+			if failed {
+				call.Failure()
+			} else {
+				call.Success()
+			}
+			t.metrics.SetBreakerState(t.host, t.breaker.State())
+		}
+		if !failed || attempt == attempts-1 {
+			break
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+	}
+	return resp, err
+}
+
+// idempotent reports whether req can be replayed safely.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody
+	default:
+		return false
+	}
+}
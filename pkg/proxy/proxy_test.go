@@ -0,0 +1,188 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/httpclient"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy_ForwardsWithPrefixStripAndHeaders(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "upstream/1.0")
+		w.Header().Set("X-Seen-Path", r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("X-Seen-Gateway", r.Header.Get("X-Gateway"))
+		w.Header().Set("X-Seen-Cookie", r.Header.Get("Cookie"))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	h := newTestProxy(t, Route{
+		Name:            "billing",
+		Prefix:          "/billing/",
+		Upstream:        upstream.URL,
+		StripPrefix:     true,
+		RequestHeaders:  map[string]string{"X-Gateway": "change-me", "Cookie": ""},
+		ResponseHeaders: map[string]string{"Server": ""},
+	})
+
+	req := httptest.NewRequest("GET", "/billing/invoices/7?expand=lines", nil)
+	req.Header.Set("Cookie", "session=secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Equal(t, "/invoices/7?expand=lines", w.Header().Get("X-Seen-Path"))
+	assert.Equal(t, "change-me", w.Header().Get("X-Seen-Gateway"))
+	assert.Empty(t, w.Header().Get("X-Seen-Cookie"))
+	assert.Empty(t, w.Header().Get("Server"))
+}
+
+func TestProxy_RetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	h := newTestProxy(t, Route{Name: "svc", Prefix: "/svc/", Upstream: upstream.URL, Retries: 2, RetryBackoff: time.Millisecond})
+
+	assert.Equal(t, http.StatusOK, serve(h, "GET", "/svc/x").Code)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(h, "POST", "/svc/x").Code, "non-idempotent requests are not retried")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestProxy_CircuitBreakerAndTimeout(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer upstream.Close()
+
+	h := newTestProxy(t, Route{
+		Name:     "slow",
+		Prefix:   "/slow/",
+		Upstream: upstream.URL,
+		Timeout:  10 * time.Millisecond,
+		Breaker:  BreakerConfig{Failures: 2, Cooldown: time.Minute},
+	})
+
+	assert.Equal(t, http.StatusGatewayTimeout, serve(h, "GET", "/slow/a").Code)
+	assert.Equal(t, http.StatusGatewayTimeout, serve(h, "GET", "/slow/a").Code)
+
+	w := serve(h, "GET", "/slow/a")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "upstream_unavailable")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "open circuit short-circuits the upstream")
+}
+
+func TestProxy_ReportsRetriesAndBreakerState(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	reg := prometheus.NewRegistry()
+	h := newTestProxy(t, Route{
+		Name:         "svc",
+		Prefix:       "/svc/",
+		Upstream:     upstream.URL,
+		Retries:      1,
+		RetryBackoff: time.Millisecond,
+		Breaker:      BreakerConfig{Failures: 2, Cooldown: time.Minute},
+	}, WithMetrics(httpclient.NewMetrics(reg)))
+
+	assert.Equal(t, http.StatusBadGateway, serve(h, "GET", "/svc/x").Code)
+
+	host := strings.TrimPrefix(upstream.URL, "http://")
+	expected := fmt.Sprintf(`
+# HELP http_client_breaker_state Circuit breaker state by host (0 closed, 1 open, 2 half-open).
+# TYPE http_client_breaker_state gauge
+http_client_breaker_state{host=%[1]q} 1
+# HELP http_client_retries_total Outbound HTTP request retries by host.
+# TYPE http_client_retries_total counter
+http_client_retries_total{host=%[1]q} 1
+`, host)
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"http_client_breaker_state", "http_client_retries_total"))
+}
+
+func TestProxy_FallbackAfterRetriesAndBreaker(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	registry := httpclient.NewRegistry(httpclient.Config{}, []httpclient.Config{{
+		Name:     "svc",
+		Fallback: &httpclient.FallbackConfig{Static: &httpclient.StaticResponse{ContentType: "application/json", Body: `{"items":[]}`}},
+	}}, httpclient.Options{})
+	h := newTestProxy(t, Route{
+		Name:         "svc",
+		Prefix:       "/svc/",
+		Upstream:     upstream.URL,
+		Retries:      1,
+		RetryBackoff: time.Millisecond,
+		Breaker:      BreakerConfig{Failures: 2, Cooldown: time.Minute},
+	}, WithFallback(func(next http.RoundTripper) http.RoundTripper { return registry.Fallback("svc", next) }))
+
+	for i := 0; i < 2; i++ {
+		w := serve(h, "GET", "/svc/items")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"items":[]}`, w.Body.String())
+		assert.Equal(t, httpclient.FallbackStatic, w.Header().Get(httpclient.FallbackHeader))
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "retried once, then the open breaker falls back directly")
+}
+
+func TestNew_RejectsInvalidUpstream(t *testing.T) {
+	_, err := New(Route{Name: "bad", Prefix: "/bad/", Upstream: "not-a-url"}, nil, nil)
+	assert.Error(t, err)
+}
+
+func newTestProxy(t *testing.T, route Route, opts ...Option) http.Handler {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	h, err := New(route, nil, log, opts...)
+	require.NoError(t, err)
+	return h
+}
+
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestRoute_MatchesOnSegmentBoundaries(t *testing.T) {
+	route := Route{Prefix: "/search"}
+	assert.True(t, route.Matches("/search"))
+	assert.True(t, route.Matches("/search/docs"))
+	assert.False(t, route.Matches("/searches"))
+
+	slashed := Route{Prefix: "/search/"}
+	assert.True(t, slashed.Matches("/search/docs"))
+	assert.False(t, slashed.Matches("/search"))
+}
@@ -0,0 +1,120 @@
+// Package recording captures sanitized HTTP request/response pairs into a
+// bounded ring buffer and replays them against another instance, which helps
+// reproduce production issues locally.
+package recording
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Redacted replaces sensitive header values in recorded entries.
+const Redacted = "[REDACTED]"
+
+// DefaultSensitiveHeaders are redacted from every recorded entry.
+var DefaultSensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// DefaultSensitiveParams are query parameters redacted from every recorded
+// entry, matched case-insensitively.
+var DefaultSensitiveParams = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"code",
+	"id_token",
+	"key",
+	"password",
+	"refresh_token",
+	"secret",
+	"signature",
+	"token",
+	"x-amz-signature",
+}
+
+// Entry is a single recorded request/response pair.
+type Entry struct {
+	ID         string    `json:"id"`
+	RecordedAt time.Time `json:"recorded_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+	Truncated  bool      `json:"truncated,omitempty"`
+}
+
+// Request is the recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Response is the recorded response.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Store persists recorded entries. Implementations must be safe for
+// concurrent use and evict the oldest entries once their capacity is reached.
+type Store interface {
+	// Save stores an entry, evicting the oldest entry when full.
+	Save(entry Entry) error
+
+	// List returns stored entries ordered from oldest to newest.
+	List() ([]Entry, error)
+}
+
+// SanitizeHeader returns a copy of h with sensitive values redacted.
+func SanitizeHeader(h http.Header, sensitive []string) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+
+	out := h.Clone()
+	for _, name := range sensitive {
+		key := http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if _, ok := out[key]; ok {
+			out[key] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+// SanitizeQuery returns the raw query with the values of sensitive
+// parameters redacted, keeping the order and encoding of the others.
+func SanitizeQuery(raw string, sensitive []string) string {
+	if raw == "" {
+		return raw
+	}
+
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		rawName, _, hasValue := strings.Cut(pair, "=")
+		if !hasValue {
+			continue
+		}
+		name, err := url.QueryUnescape(rawName)
+		if err != nil {
+			name = rawName
+		}
+		for _, s := range sensitive {
+			if strings.EqualFold(name, strings.TrimSpace(s)) {
+				pairs[i] = rawName + "=" + url.QueryEscape(Redacted)
+				break
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
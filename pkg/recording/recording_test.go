@@ -0,0 +1,233 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeHeader_RedactsSensitiveValues(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer secret")
+	h.Set("Cookie", "session=abc")
+	h.Set("Accept", "application/json")
+
+	out := SanitizeHeader(h, DefaultSensitiveHeaders)
+
+	assert.Equal(t, Redacted, out.Get("Authorization"))
+	assert.Equal(t, Redacted, out.Get("Cookie"))
+	assert.Equal(t, "application/json", out.Get("Accept"))
+	assert.Equal(t, "Bearer secret", h.Get("Authorization"), "original must not be modified")
+}
+
+func TestSanitizeQuery_RedactsSensitiveParams(t *testing.T) {
+	out := SanitizeQuery("page=2&Token=abc&api%5Fkey=k1&flag&sort=name", DefaultSensitiveParams)
+
+	assert.Equal(t, "page=2&Token=%5BREDACTED%5D&api%5Fkey=%5BREDACTED%5D&flag&sort=name", out)
+	assert.Empty(t, SanitizeQuery("", DefaultSensitiveParams))
+}
+
+func TestMemoryStore_EvictsOldest(t *testing.T) {
+	s := NewMemoryStore(3)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, s.Save(Entry{ID: fmt.Sprint(i)}))
+	}
+
+	entries, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{"3", "4", "5"}, ids(entries))
+}
+
+func TestFileStore_EvictsOldestAndReloads(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(dir, 2)
+	require.NoError(t, err)
+
+	base := time.Now()
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, s.Save(Entry{ID: fmt.Sprint(i), RecordedAt: base.Add(time.Duration(i) * time.Millisecond)}))
+	}
+
+	entries, err := s.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, ids(entries))
+
+	// Reopening with a smaller cap evicts immediately
+	_, err = NewFileStore(dir, 1)
+	require.NoError(t, err)
+	entries, err = ReadDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, ids(entries))
+}
+
+// objects is an in-memory Objects.
+type objects struct {
+	mu      sync.Mutex
+	data    map[string][]byte
+	release chan struct{} // Blocks Put until closed when set
+}
+
+func (o *objects) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if o.release != nil {
+		<-o.release
+	}
+	data, err := io.ReadAll(r)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.data[key] = data
+	return err
+}
+
+func (o *objects) Delete(ctx context.Context, key string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.data, key)
+	return nil
+}
+
+func (o *objects) List(ctx context.Context, prefix string) ([]string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var keys []string
+	for key := range o.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (o *objects) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	data, ok := o.data[key]
+	if !ok {
+		return nil, fmt.Errorf("no object %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestObjectStore_EvictsOldestObjects(t *testing.T) {
+	o := &objects{data: make(map[string][]byte)}
+	s, err := NewObjectStore(o, "recordings/", ObjectOptions{MaxEntries: 2})
+	require.NoError(t, err)
+
+	base := time.Now()
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, s.Save(Entry{ID: fmt.Sprint(i), RecordedAt: base.Add(time.Duration(i) * time.Millisecond)}))
+	}
+	require.NoError(t, s.Close())
+
+	entries, err := s.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, ids(entries))
+	require.Len(t, o.data, 2)
+	for key, data := range o.data {
+		assert.True(t, strings.HasPrefix(key, "recordings/"), key)
+		var e Entry
+		require.NoError(t, json.Unmarshal(data, &e))
+		assert.NotEqual(t, "1", e.ID, "the oldest object is deleted")
+	}
+}
+
+func TestObjectStore_EvictsObjectsOfEarlierRuns(t *testing.T) {
+	o := &objects{data: map[string][]byte{"other/keep.json": []byte("{}")}}
+	base := time.Now()
+	first, err := NewObjectStore(o, "recordings", ObjectOptions{MaxEntries: 2})
+	require.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		require.NoError(t, first.Save(Entry{ID: fmt.Sprint(i), RecordedAt: base.Add(time.Duration(i) * time.Millisecond)}))
+	}
+	require.NoError(t, first.Close())
+
+	// A restart adopts the objects below the prefix
+	second, err := NewObjectStore(o, "recordings", ObjectOptions{MaxEntries: 2})
+	require.NoError(t, err)
+	require.NoError(t, second.Save(Entry{ID: "3", RecordedAt: base.Add(3 * time.Millisecond)}))
+	require.NoError(t, second.Close())
+
+	entries, err := ReadObjects(context.Background(), o, "recordings")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, ids(entries))
+	assert.Contains(t, o.data, "other/keep.json", "objects outside the prefix are not evicted")
+
+	// Shrinking the cap evicts on startup
+	third, err := NewObjectStore(o, "recordings", ObjectOptions{MaxEntries: 1})
+	require.NoError(t, err)
+	require.NoError(t, third.Close())
+	entries, err = ReadObjects(context.Background(), o, "recordings")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, ids(entries))
+}
+
+func TestObjectStore_SaveDoesNotWaitForUploads(t *testing.T) {
+	o := &objects{data: make(map[string][]byte), release: make(chan struct{})}
+	s, err := NewObjectStore(o, "recordings", ObjectOptions{MaxEntries: 10, QueueSize: 2})
+	require.NoError(t, err)
+
+	// The uploader holds the first entry; two more fill the queue
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, s.Save(Entry{ID: fmt.Sprint(i), RecordedAt: time.Now()}))
+		if i == 1 {
+			require.Eventually(t, func() bool { return len(s.queue) == 0 }, time.Second, time.Millisecond)
+		}
+	}
+	assert.ErrorIs(t, s.Save(Entry{ID: "4"}), ErrQueueFull)
+
+	close(o.release)
+	require.NoError(t, s.Close())
+	assert.Len(t, o.data, 3, "Close uploads the queued entries")
+	assert.Error(t, s.Save(Entry{ID: "5"}), "closed stores accept no entries")
+}
+
+func TestReplay_SendsRecordedRequests(t *testing.T) {
+	var gotAuth, gotBody, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotQuery = r.URL.RawQuery
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	entries := []Entry{{
+		Request: Request{
+			Method: http.MethodPost,
+			Path:   "/api/v1/items",
+			Query:  "dry_run=true",
+			Header: http.Header{"Authorization": {Redacted}, "Content-Type": {"application/json"}},
+			Body:   []byte(`{"name":"x"}`),
+		},
+		Response: Response{Status: http.StatusCreated},
+	}}
+
+	extra := http.Header{"Authorization": {"Bearer local"}}
+	results := Replay(context.Background(), srv.Client(), srv.URL, entries, extra)
+
+	require.Len(t, results, 1)
+	assert.True(t, results[0].StatusMatches())
+	assert.Equal(t, "Bearer local", gotAuth)
+	assert.Equal(t, `{"name":"x"}`, gotBody)
+	assert.Equal(t, "dry_run=true", gotQuery)
+}
+
+func ids(entries []Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.ID
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// hopHeaders are not forwarded when replaying a request.
+var hopHeaders = map[string]struct{}{
+	"Connection":        {},
+	"Content-Length":    {},
+	"Keep-Alive":        {},
+	"Transfer-Encoding": {},
+	"Upgrade":           {},
+}
+
+// ReplayResult describes the outcome of replaying one entry.
+type ReplayResult struct {
+	Entry    Entry
+	Status   int
+	Duration time.Duration
+	Body     []byte
+	Err      error
+}
+
+// StatusMatches reports whether the replayed status equals the recorded one.
+func (r ReplayResult) StatusMatches() bool {
+	return r.Err == nil && r.Status == r.Entry.Response.Status
+}
+
+// Replay re-sends entries to baseURL in recorded order. Redacted headers are
+// omitted, so authenticated endpoints need credentials supplied via extra.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation
+//   - client: HTTP client used to send requests
+//   - baseURL: Target instance (e.g. http://localhost:8000)
+//   - entries: Recorded entries to replay
+//   - extra: Headers added to every replayed request
+//
+// Returns:
+//   - []ReplayResult: One result per entry
+func Replay(ctx context.Context, client *http.Client, baseURL string, entries []Entry, extra http.Header) []ReplayResult {
+	baseURL = strings.TrimRight(baseURL, "/")
+	results := make([]ReplayResult, 0, len(entries))
+
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, replayOne(ctx, client, baseURL, e, extra))
+	}
+	return results
+}
+
+// replayOne sends a single recorded request.
+func replayOne(ctx context.Context, client *http.Client, baseURL string, e Entry, extra http.Header) ReplayResult {
+	res := ReplayResult{Entry: e}
+
+	url := baseURL + e.Request.Path
+	if e.Request.Query != "" {
+		url += "?" + e.Request.Query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, e.Request.Method, url, bytes.NewReader(e.Request.Body))
+	if err != nil {
+		res.Err = fmt.Errorf("failed to build request: %w", err)
+		return res
+	}
+
+	for name, values := range e.Request.Header {
+		if _, hop := hopHeaders[name]; hop {
+			continue
+		}
+		if len(values) == 1 && values[0] == Redacted {
+			continue
+		}
+		req.Header[name] = values
+	}
+	for name, values := range extra {
+		req.Header[name] = values
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	res.Duration = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer resp.Body.Close()
+
+	res.Status = resp.StatusCode
+	res.Body, res.Err = io.ReadAll(resp.Body)
+	return res
+}
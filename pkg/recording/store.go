@@ -0,0 +1,414 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// fileExt is the extension used for entries written by FileStore.
+const fileExt = ".json"
+
+// MemoryStore is an in-memory ring buffer of entries.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewMemoryStore creates a ring buffer holding at most capacity entries.
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryStore{entries: make([]Entry, capacity)}
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[s.next] = entry
+	s.next = (s.next + 1) % len(s.entries)
+	if s.next == 0 {
+		s.full = true
+	}
+	return nil
+}
+
+// List implements Store.
+func (s *MemoryStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.full {
+		out := make([]Entry, s.next)
+		copy(out, s.entries[:s.next])
+		return out, nil
+	}
+
+	out := make([]Entry, 0, len(s.entries))
+	out = append(out, s.entries[s.next:]...)
+	out = append(out, s.entries[:s.next]...)
+	return out, nil
+}
+
+// FileStore writes each entry as a JSON file in a directory, keeping at most
+// maxEntries files by deleting the oldest ones.
+type FileStore struct {
+	mu         sync.Mutex
+	dir        string
+	maxEntries int
+	files      []string
+}
+
+// NewFileStore creates a directory-backed ring buffer.
+// Existing recordings in dir are adopted and count towards the cap.
+//
+// Parameters:
+//   - dir: Directory for recordings (created if missing)
+//   - maxEntries: Maximum number of recordings kept on disk
+//
+// Returns:
+//   - *FileStore: Initialized store
+//   - error: Directory creation or listing error
+func NewFileStore(dir string, maxEntries int) (*FileStore, error) {
+	if maxEntries <= 0 {
+		maxEntries = 1
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	files, err := listEntryFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{dir: dir, maxEntries: maxEntries, files: files}
+	if err := s.evict(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	name := entryName(entry)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o640); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	s.files = append(s.files, name)
+	return s.evict()
+}
+
+// List implements Store.
+func (s *FileStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	files := make([]string, len(s.files))
+	copy(files, s.files)
+	s.mu.Unlock()
+
+	return readEntries(s.dir, files)
+}
+
+// evict removes the oldest files beyond the cap. Callers must hold s.mu.
+func (s *FileStore) evict() error {
+	for len(s.files) > s.maxEntries {
+		if err := os.Remove(filepath.Join(s.dir, s.files[0])); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to evict recording: %w", err)
+		}
+		s.files = s.files[1:]
+	}
+	return nil
+}
+
+// Objects is the part of an object storage client ObjectStore writes to
+// and reads back from.
+type Objects interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+
+	// List returns the keys below prefix in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Get opens the object of key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// objectTimeout bounds each object storage call.
+const objectTimeout = 10 * time.Second
+
+// defaultObjectQueueSize is the upload queue length when
+// ObjectOptions.QueueSize is not set.
+const defaultObjectQueueSize = 100
+
+// ErrQueueFull is returned by ObjectStore.Save when the upload queue is
+// full; the entry is dropped.
+var ErrQueueFull = errors.New("recording upload queue full")
+
+// errStoreClosed is returned by ObjectStore.Save after Close.
+var errStoreClosed = errors.New("recording store closed")
+
+// ObjectOptions configures an ObjectStore.
+type ObjectOptions struct {
+	MaxEntries int            // Objects kept below the prefix (default 1)
+	QueueSize  int            // Entries waiting for upload before Save drops new ones (default 100)
+	Logger     *logger.Logger // Reports failed uploads and evictions (nil discards them)
+}
+
+// ObjectStore writes each entry as a JSON object under a key prefix. Save
+// only queues the entry; a background goroutine uploads it, so requests
+// never wait on the object storage. At most MaxEntries objects are kept
+// below the prefix, those left by earlier runs included, by deleting the
+// oldest. List returns the entries saved by this process; ReadObjects
+// reads back all of them.
+type ObjectStore struct {
+	objects Objects
+	prefix  string
+	max     int
+	log     *logger.Logger
+	recent  *MemoryStore
+	keys    []string // Stored keys, oldest first; owned by the uploader
+
+	mu     sync.Mutex // Guards closed and sends on queue
+	closed bool
+	queue  chan Entry
+	done   chan struct{}
+}
+
+// NewObjectStore creates an object-backed ring buffer under prefix.
+// Objects already below prefix are adopted and count towards the cap.
+func NewObjectStore(objects Objects, prefix string, opts ObjectOptions) (*ObjectStore, error) {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultObjectQueueSize
+	}
+	prefix = strings.Trim(prefix, "/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), objectTimeout)
+	defer cancel()
+	keys, err := listEntryKeys(ctx, objects, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ObjectStore{
+		objects: objects,
+		prefix:  prefix,
+		max:     opts.MaxEntries,
+		log:     opts.Logger,
+		recent:  NewMemoryStore(opts.MaxEntries),
+		keys:    keys,
+		queue:   make(chan Entry, opts.QueueSize),
+		done:    make(chan struct{}),
+	}
+	if err := s.evict(ctx); err != nil {
+		return nil, err
+	}
+	go s.upload()
+	return s, nil
+}
+
+// Save implements Store. It returns ErrQueueFull, dropping entry, while
+// the uploads lag behind.
+func (s *ObjectStore) Save(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errStoreClosed
+	}
+	select {
+	case s.queue <- entry:
+	default:
+		return ErrQueueFull
+	}
+	return s.recent.Save(entry)
+}
+
+// List implements Store.
+func (s *ObjectStore) List() ([]Entry, error) {
+	return s.recent.List()
+}
+
+// Close uploads the queued entries and stops the uploader.
+func (s *ObjectStore) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+	return nil
+}
+
+// upload stores the queued entries until Close.
+func (s *ObjectStore) upload() {
+	defer close(s.done)
+
+	for entry := range s.queue {
+		if err := s.put(entry); err != nil && s.log != nil {
+			s.log.Warnw("request_recording_upload_failed", "id", entry.ID, "error", err)
+		}
+	}
+}
+
+// put uploads entry and evicts the oldest objects beyond the cap.
+func (s *ObjectStore) put(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	key := path.Join(s.prefix, entryName(entry))
+
+	ctx, cancel := context.WithTimeout(context.Background(), objectTimeout)
+	defer cancel()
+	if err := s.objects.Put(ctx, key, bytes.NewReader(data), "application/json"); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	s.keys = append(s.keys, key)
+	return s.evict(ctx)
+}
+
+// evict deletes the oldest objects beyond the cap. Only the uploader, or
+// the constructor before starting it, may call it.
+func (s *ObjectStore) evict(ctx context.Context) error {
+	for len(s.keys) > s.max {
+		if err := s.objects.Delete(ctx, s.keys[0]); err != nil {
+			return fmt.Errorf("failed to evict recording: %w", err)
+		}
+		s.keys = s.keys[1:]
+	}
+	return nil
+}
+
+// ReadObjects loads all recordings below prefix ordered from oldest to
+// newest.
+func ReadObjects(ctx context.Context, objects Objects, prefix string) ([]Entry, error) {
+	keys, err := listEntryKeys(ctx, objects, strings.Trim(prefix, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		r, err := objects.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording %s: %w", key, err)
+		}
+		data, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording %s: %w", key, err)
+		}
+
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("failed to decode recording %s: %w", key, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// listEntryKeys returns the recording keys below prefix sorted by age.
+func listEntryKeys(ctx context.Context, objects Objects, prefix string) ([]string, error) {
+	if prefix != "" {
+		prefix += "/"
+	}
+	all, err := objects.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	var keys []string
+	for _, key := range all {
+		name := strings.TrimPrefix(key, prefix)
+		if strings.HasSuffix(name, fileExt) && !strings.Contains(name, "/") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// entryName returns the file or object name of entry. The nanosecond
+// timestamp prefix keeps lexical order equal to recording order.
+func entryName(entry Entry) string {
+	return fmt.Sprintf("%020d-%s%s", entry.RecordedAt.UnixNano(), entry.ID, fileExt)
+}
+
+// ReadDir loads all recordings from dir ordered from oldest to newest.
+func ReadDir(dir string) ([]Entry, error) {
+	files, err := listEntryFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return readEntries(dir, files)
+}
+
+// listEntryFiles returns recording file names in dir sorted by age.
+func listEntryFiles(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	var files []string
+	for _, de := range dirEntries {
+		if !de.IsDir() && strings.HasSuffix(de.Name(), fileExt) {
+			files = append(files, de.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readEntries decodes the named recording files.
+func readEntries(dir string, files []string) ([]Entry, error) {
+	entries := make([]Entry, 0, len(files))
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // evicted concurrently
+			}
+			return nil, fmt.Errorf("failed to read recording %s: %w", name, err)
+		}
+
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("failed to decode recording %s: %w", name, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
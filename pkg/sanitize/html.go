@@ -0,0 +1,149 @@
+package sanitize
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags is the markup kept by HTML: basic formatting, lists,
+// quotes, code and links.
+var allowedTags = map[atom.Atom]bool{
+	atom.A: true, atom.B: true, atom.Blockquote: true, atom.Br: true,
+	atom.Code: true, atom.Em: true, atom.I: true, atom.Li: true,
+	atom.Ol: true, atom.P: true, atom.Pre: true, atom.Strong: true,
+	atom.U: true, atom.Ul: true,
+}
+
+// droppedTags are removed together with their content.
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+	atom.Embed: true, atom.Noscript: true, atom.Template: true, atom.Svg: true,
+	atom.Math: true, atom.Textarea: true, atom.Select: true,
+}
+
+// blockTags end a line of text when markup is stripped.
+var blockTags = map[atom.Atom]bool{
+	atom.Br: true, atom.P: true, atom.Div: true, atom.Li: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// linkSchemes are the URL schemes kept in links.
+var linkSchemes = []string{"http", "https", "mailto"}
+
+// HTML returns s with only the allowed markup: basic formatting, lists,
+// quotes, code and links to http(s) and mailto URLs. Links get
+// rel="nofollow noopener noreferrer"; other attributes are removed, as
+// are scripts, styles and embedded content including their text. All
+// other tags are dropped keeping their text, and text is escaped, so the
+// result is safe to render as HTML.
+func HTML(s string) string {
+	z := html.NewTokenizer(strings.NewReader(s))
+	var b strings.Builder
+	var open []atom.Atom
+	dropped := 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i].String() + ">")
+			}
+			return b.String()
+		case html.TextToken:
+			if dropped == 0 {
+				b.WriteString(html.EscapeString(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if droppedTags[tok.DataAtom] {
+				if tt == html.StartTagToken {
+					dropped++
+				}
+				continue
+			}
+			if dropped > 0 || !allowedTags[tok.DataAtom] {
+				continue
+			}
+			writeStartTag(&b, tok)
+			if tt == html.StartTagToken && tok.DataAtom != atom.Br {
+				open = append(open, tok.DataAtom)
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			if droppedTags[tok.DataAtom] {
+				if dropped > 0 {
+					dropped--
+				}
+				continue
+			}
+			if dropped > 0 {
+				continue
+			}
+			// Close the element and any left open inside it
+			if i := slices.Index(open, tok.DataAtom); i >= 0 {
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j].String() + ">")
+				}
+				open = open[:i]
+			}
+		}
+	}
+}
+
+// writeStartTag writes tok without attributes other than safe link
+// targets.
+func writeStartTag(b *strings.Builder, tok html.Token) {
+	b.WriteString("<" + tok.DataAtom.String())
+	if tok.DataAtom == atom.A {
+		for _, attr := range tok.Attr {
+			if attr.Key == "href" && safeLink(attr.Val) {
+				b.WriteString(` href="` + html.EscapeString(attr.Val) + `" rel="nofollow noopener noreferrer"`)
+				break
+			}
+		}
+	}
+	b.WriteString(">")
+}
+
+// safeLink reports whether href is an absolute URL of an allowed scheme.
+func safeLink(href string) bool {
+	u, err := url.Parse(strings.TrimSpace(href))
+	return err == nil && slices.Contains(linkSchemes, strings.ToLower(u.Scheme)) && (u.Host != "" || u.Scheme == "mailto")
+}
+
+// StripTags returns the text content of s without any markup. Scripts,
+// styles and embedded content are removed with their text, block
+// elements end a line, and entities are decoded: the result is plain
+// text, to be escaped when rendered as HTML.
+func StripTags(s string) string {
+	z := html.NewTokenizer(strings.NewReader(s))
+	var b strings.Builder
+	dropped := 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			if dropped == 0 {
+				b.Write(z.Text())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			name, _ := z.TagName()
+			a := atom.Lookup(name)
+			switch {
+			case droppedTags[a] && tt == html.StartTagToken:
+				dropped++
+			case droppedTags[a] && tt == html.EndTagToken && dropped > 0:
+				dropped--
+			case blockTags[a] && dropped == 0 && (tt != html.StartTagToken || a == atom.Br):
+				b.WriteByte('\n')
+			}
+		}
+	}
+}
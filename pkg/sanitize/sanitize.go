@@ -0,0 +1,266 @@
+// Package sanitize cleans user-generated text before it is validated and
+// stored: Unicode normalization, control character stripping, rune-safe
+// truncation and HTML sanitization.
+//
+// Structs opt in per string field with a sanitize tag listing options;
+// every tagged field is normalized to NFC, stripped of control and bidi
+// override characters and trimmed:
+//
+//	type Comment struct {
+//		Author string `json:"author" sanitize:"text,max=64"`
+//		Body   string `json:"body" sanitize:"html,multiline"`
+//	}
+//
+//	err := sanitize.Struct(&comment)
+//
+// Options:
+//   - text: remove all markup, keeping the text content
+//   - html: keep the safe markup allowed by HTML, escaping the rest
+//   - nfkc: normalize to NFKC, folding compatibility characters such as
+//     full-width letters and ligatures (for identifiers)
+//   - multiline: keep newlines and tabs instead of replacing them by spaces
+//   - max=N: truncate to N runes (not with html)
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tag is the struct tag selecting the sanitization of a field.
+const Tag = "sanitize"
+
+// Rules are the parsed options of a sanitize tag.
+type Rules struct {
+	HTML      bool // Keep safe markup
+	Text      bool // Remove all markup
+	NFKC      bool // Compatibility instead of canonical normalization
+	Multiline bool // Keep newlines and tabs
+	MaxLength int  // Maximum length in runes (0 = unlimited)
+}
+
+// ParseRules parses the options of a sanitize tag.
+func ParseRules(tag string) (Rules, error) {
+	var r Rules
+	for _, opt := range strings.Split(tag, ",") {
+		switch name, value, _ := strings.Cut(strings.TrimSpace(opt), "="); name {
+		case "":
+		case "html":
+			r.HTML = true
+		case "text":
+			r.Text = true
+		case "nfkc":
+			r.NFKC = true
+		case "multiline":
+			r.Multiline = true
+		case "max":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return Rules{}, fmt.Errorf("sanitize: invalid max %q", value)
+			}
+			r.MaxLength = n
+		default:
+			return Rules{}, fmt.Errorf("sanitize: unknown option %q", name)
+		}
+	}
+	if r.HTML && r.Text {
+		return Rules{}, fmt.Errorf("sanitize: html and text are exclusive")
+	}
+	if r.HTML && r.MaxLength > 0 {
+		return Rules{}, fmt.Errorf("sanitize: max would cut html markup; validate the length instead")
+	}
+	return r, nil
+}
+
+// Apply returns s sanitized by r. Markup is handled first so characters
+// hidden in entities are normalized and stripped too.
+func (r Rules) Apply(s string) string {
+	switch {
+	case r.HTML:
+		s = HTML(s)
+	case r.Text:
+		s = StripTags(s)
+	}
+	if r.NFKC {
+		s = norm.NFKC.String(strings.ToValidUTF8(s, string(utf8.RuneError)))
+	} else {
+		s = Normalize(s)
+	}
+	s = StripControl(s, r.Multiline)
+	s = strings.TrimSpace(s)
+	if r.MaxLength > 0 {
+		s = strings.TrimRightFunc(Truncate(s, r.MaxLength), unicode.IsSpace)
+	}
+	return s
+}
+
+// Normalize returns s in Unicode normalization form C, replacing invalid
+// UTF-8 with U+FFFD, so equal-looking strings compare equal.
+func Normalize(s string) string {
+	return norm.NFC.String(strings.ToValidUTF8(s, string(utf8.RuneError)))
+}
+
+// StripControl removes control characters and the bidi overrides and
+// isolates used to disguise text. Newlines and tabs are kept when
+// multiline is set and become spaces otherwise; carriage returns are
+// removed.
+func StripControl(s string, multiline bool) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			if multiline {
+				return r
+			}
+			return ' '
+		case unicode.IsControl(r), isBidiControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isBidiControl reports whether r overrides or isolates text direction.
+func isBidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// Truncate shortens s to at most n runes.
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
+
+// Struct sanitizes the tagged string fields of the struct v points to in
+// place, descending into nested structs, pointers and slices. String
+// slices apply the field's rules to each element.
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("sanitize: %T: want a non-nil pointer", v)
+	}
+	return walk(rv.Elem())
+}
+
+// walk sanitizes the tagged fields reachable from v.
+func walk(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return walk(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := walk(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields, err := fieldsOf(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			field := v.Field(f.index)
+			if f.rules == nil {
+				if err := walk(field); err != nil {
+					return err
+				}
+				continue
+			}
+			apply(field, *f.rules)
+		}
+	}
+	return nil
+}
+
+// apply sanitizes a tagged string, *string or []string field.
+func apply(field reflect.Value, r Rules) {
+	if !field.CanSet() {
+		return
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(r.Apply(field.String()))
+	case reflect.Pointer:
+		if !field.IsNil() {
+			apply(field.Elem(), r)
+		}
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			apply(field.Index(i), r)
+		}
+	}
+}
+
+// field is a struct field that is sanitized (rules set) or may contain
+// sanitized fields.
+type field struct {
+	index int
+	rules *Rules
+}
+
+// fieldCache holds the parsed fields per struct type.
+var fieldCache sync.Map
+
+// fieldsOf returns the relevant fields of struct type t.
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field), nil
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup(Tag)
+		if !ok {
+			if containsStructs(sf.Type) {
+				fields = append(fields, field{index: i})
+			}
+			continue
+		}
+		if !isStringLike(sf.Type) {
+			return nil, fmt.Errorf("sanitize: field %s.%s: only string, *string and []string fields can be sanitized", t.Name(), sf.Name)
+		}
+		rules, err := ParseRules(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w (field %s.%s)", err, t.Name(), sf.Name)
+		}
+		fields = append(fields, field{index: i, rules: &rules})
+	}
+	fieldCache.Store(t, fields)
+	return fields, nil
+}
+
+// isStringLike reports whether t is a string, *string or []string.
+func isStringLike(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// containsStructs reports whether values of t may hold structs.
+func containsStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Interface
+}
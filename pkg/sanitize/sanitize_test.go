@@ -0,0 +1,102 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"formatting kept", `<p>Hello <b>world</b></p>`, `<p>Hello <b>world</b></p>`},
+		{"scripts removed with content", `hi<script>alert(1)</script>!`, `hi!`},
+		{"attributes removed", `<p onclick="x()" style="color:red">a</p>`, `<p>a</p>`},
+		{"unknown tags dropped, text kept", `<div><span>a</span></div>`, `a`},
+		{"safe links", `<a href="https://example.com/?a=1&amp;b=2" target="_blank">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener noreferrer">x</a>`},
+		{"script links", `<a href="jav&#x61;script:alert(1)">x</a>`, `<a>x</a>`},
+		{"unclosed tags closed", `<ul><li>a<li>b`, `<ul><li>a<li>b</li></li></ul>`},
+		{"stray end tags ignored", `a</b></p>`, `a`},
+		{"text escaped", `1 < 2 & "q"`, `1 &lt; 2 &amp; &#34;q&#34;`},
+		{"void elements", `a<br/>b<img src=x onerror=alert(1)>`, `a<br>b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HTML(tt.in))
+		})
+	}
+}
+
+func TestStripTags(t *testing.T) {
+	assert.Equal(t, "Tom & Jerry", StripTags(`<b>Tom</b> &amp; Jerry<style>b{}</style>`))
+	assert.Equal(t, "a\nb\n", StripTags(`<p>a</p>b<br>`))
+}
+
+func TestRules_Apply(t *testing.T) {
+	tests := []struct {
+		name, tag, in, want string
+	}{
+		{"normalized to NFC", "", "Jose\u0301", "Jos\u00e9"},
+		{"controls and bidi overrides stripped", "", " a\x00b\u202ec\r\n", "abc"},
+		{"newlines become spaces", "", "a\nb\tc", "a b c"},
+		{"multiline keeps newlines", "multiline", "a\r\nb\tc", "a\nb\tc"},
+		{"nfkc folds compatibility characters", "nfkc", "ｊａｎｅﬁ", "janefi"},
+		{"text strips markup", "text", "<i>Jane</i> Doe", "Jane Doe"},
+		{"entities cannot smuggle controls", "text", "a&#x202E;b", "ab"},
+		{"truncated by runes", "max=3", "żółw", "żół"},
+		{"invalid utf-8 replaced", "", "a\xffb", "a�b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRules(tt.tag)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, r.Apply(tt.in))
+		})
+	}
+}
+
+func TestParseRules_Invalid(t *testing.T) {
+	for _, tag := range []string{"max=0", "max=x", "html,text", "html,max=10", "trim"} {
+		_, err := ParseRules(tag)
+		assert.Error(t, err, tag)
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type author struct {
+		Name string `sanitize:"text,max=5"`
+	}
+	type post struct {
+		Title    string   `sanitize:""`
+		Body     *string  `sanitize:"html,multiline"`
+		Tags     []string `sanitize:"nfkc"`
+		Raw      string
+		Author   author
+		Comments []*author
+	}
+	body := "<p>hi</p>\n<script>x</script>"
+	p := post{
+		Title:    " Title\u200f\x07 ",
+		Body:     &body,
+		Tags:     []string{"ＧＯ"},
+		Raw:      " <b>raw</b> ",
+		Author:   author{Name: "<b>Jane Doe</b>"},
+		Comments: []*author{{Name: "<i>John</i>"}, nil},
+	}
+
+	require.NoError(t, Struct(&p))
+	assert.Equal(t, "Title\u200f", p.Title, "only control and bidi override characters are removed")
+	assert.Equal(t, "<p>hi</p>", *p.Body)
+	assert.Equal(t, []string{"GO"}, p.Tags)
+	assert.Equal(t, " <b>raw</b> ", p.Raw, "untagged fields are unchanged")
+	assert.Equal(t, "Jane", p.Author.Name)
+	assert.Equal(t, "John", p.Comments[0].Name)
+
+	type invalid struct {
+		Count int `sanitize:""`
+	}
+	assert.ErrorContains(t, Struct(&invalid{}), "only string")
+	assert.Error(t, Struct(p), "a pointer is required")
+}
@@ -0,0 +1,430 @@
+// Package serverless runs an http.Handler as an AWS Lambda function behind
+// API Gateway REST APIs (payload format 1.0), HTTP APIs (payload format
+// 2.0) and Application Load Balancer target groups:
+//
+//	lambda.Start(serverless.New(router, serverless.Options{}))
+//
+// Every invocation is served as one request whose context is the
+// invocation context, so handlers see the function deadline and
+// lambdacontext.FromContext; the source event is available through
+// EventFromContext.
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Sources of the events served by the adapter.
+const (
+	SourceAPIGateway = "apigateway" // REST API, payload format 1.0
+	SourceHTTPAPI    = "httpapi"    // HTTP API, payload format 2.0
+	SourceALB        = "alb"        // Application Load Balancer
+)
+
+// requestIDHeader is set from the event request ID when the caller did
+// not send one, so logs correlate with the gateway access logs.
+const requestIDHeader = "X-Request-ID"
+
+// Options configures the adapter.
+type Options struct {
+	// BasePath is stripped from request paths, e.g. the stage of an HTTP
+	// API ("/prod") or the base path mapping of a custom domain.
+	BasePath string
+}
+
+// Event describes the invocation event a request was built from.
+type Event struct {
+	// Source is one of the Source constants.
+	Source string
+	// RequestID is the gateway request ID; empty for load balancers.
+	RequestID string
+	// Stage is the API Gateway stage; empty for load balancers.
+	Stage string
+	// StageVariables are the variables of the API Gateway stage.
+	StageVariables map[string]string
+	// Payload is the raw event, for fields the adapter does not map such
+	// as authorizer claims.
+	Payload json.RawMessage
+}
+
+// eventKey is the request context key of the Event.
+type eventKey struct{}
+
+// EventFromContext returns the invocation event of a request served by
+// the adapter.
+func EventFromContext(ctx context.Context) (*Event, bool) {
+	event, ok := ctx.Value(eventKey{}).(*Event)
+	return event, ok
+}
+
+// Adapter serves Lambda invocations with an http.Handler. It implements
+// lambda.Handler.
+type Adapter struct {
+	handler  http.Handler
+	basePath string
+}
+
+// New returns an adapter serving invocations with handler.
+func New(handler http.Handler, opts Options) *Adapter {
+	return &Adapter{handler: handler, basePath: strings.TrimSuffix(opts.BasePath, "/")}
+}
+
+// probe holds the fields telling the payload formats apart.
+type probe struct {
+	Version        string `json:"version"`
+	RequestContext struct {
+		ELB *json.RawMessage `json:"elb"`
+	} `json:"requestContext"`
+}
+
+// Invoke serves the event in payload and returns the encoded response in
+// the format of the event source.
+func (a *Adapter) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var p probe
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("decode event: %w", err)
+	}
+
+	switch {
+	case p.Version == "2.0":
+		var event events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("decode %s event: %w", SourceHTTPAPI, err)
+		}
+		return a.serveHTTPAPI(ctx, payload, event)
+	case p.RequestContext.ELB != nil:
+		var event events.ALBTargetGroupRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("decode %s event: %w", SourceALB, err)
+		}
+		return a.serveALB(ctx, payload, event)
+	default:
+		var event events.APIGatewayProxyRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("decode %s event: %w", SourceAPIGateway, err)
+		}
+		return a.serveAPIGateway(ctx, payload, event)
+	}
+}
+
+// serveAPIGateway serves a REST API event.
+func (a *Adapter) serveAPIGateway(ctx context.Context, payload []byte, event events.APIGatewayProxyRequest) ([]byte, error) {
+	header := multiValueHeader(event.Headers, event.MultiValueHeaders)
+	query := url.Values(event.MultiValueQueryStringParameters)
+	if len(query) == 0 {
+		query = url.Values{}
+		for k, v := range event.QueryStringParameters {
+			query.Set(k, v)
+		}
+	}
+
+	rc := event.RequestContext
+	req, err := a.request(ctx, &Event{
+		Source:         SourceAPIGateway,
+		RequestID:      rc.RequestID,
+		Stage:          rc.Stage,
+		StageVariables: event.StageVariables,
+		Payload:        payload,
+	}, incoming{
+		method:  event.HTTPMethod,
+		path:    event.Path,
+		query:   query.Encode(),
+		header:  header,
+		host:    rc.DomainName,
+		remote:  rc.Identity.SourceIP,
+		body:    event.Body,
+		encoded: event.IsBase64Encoded,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := a.serve(req)
+	body, encoded := resp.body()
+	return json.Marshal(events.APIGatewayProxyResponse{
+		StatusCode:        resp.status,
+		MultiValueHeaders: resp.header,
+		Body:              body,
+		IsBase64Encoded:   encoded,
+	})
+}
+
+// serveHTTPAPI serves an HTTP API event.
+func (a *Adapter) serveHTTPAPI(ctx context.Context, payload []byte, event events.APIGatewayV2HTTPRequest) ([]byte, error) {
+	header := multiValueHeader(event.Headers, nil)
+	if len(event.Cookies) > 0 {
+		header.Set("Cookie", strings.Join(event.Cookies, "; "))
+	}
+
+	rc := event.RequestContext
+	req, err := a.request(ctx, &Event{
+		Source:         SourceHTTPAPI,
+		RequestID:      rc.RequestID,
+		Stage:          rc.Stage,
+		StageVariables: event.StageVariables,
+		Payload:        payload,
+	}, incoming{
+		method:  rc.HTTP.Method,
+		path:    event.RawPath,
+		escaped: true,
+		query:   event.RawQueryString,
+		header:  header,
+		host:    rc.DomainName,
+		remote:  rc.HTTP.SourceIP,
+		body:    event.Body,
+		encoded: event.IsBase64Encoded,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := a.serve(req)
+	body, encoded := resp.body()
+	cookies := resp.header.Values("Set-Cookie")
+	resp.header.Del("Set-Cookie")
+	return json.Marshal(events.APIGatewayV2HTTPResponse{
+		StatusCode:      resp.status,
+		Headers:         singleValueHeader(resp.header),
+		Cookies:         cookies,
+		Body:            body,
+		IsBase64Encoded: encoded,
+	})
+}
+
+// serveALB serves a load balancer event. Load balancers pass the path and
+// query parameters as received, so they are not encoded again.
+func (a *Adapter) serveALB(ctx context.Context, payload []byte, event events.ALBTargetGroupRequest) ([]byte, error) {
+	multiValue := len(event.MultiValueHeaders) > 0
+	header := multiValueHeader(event.Headers, event.MultiValueHeaders)
+	query := event.MultiValueQueryStringParameters
+	if len(query) == 0 {
+		query = make(map[string][]string, len(event.QueryStringParameters))
+		for k, v := range event.QueryStringParameters {
+			query[k] = []string{v}
+		}
+	}
+
+	// The load balancer appends the address of its peer to X-Forwarded-For
+	var remote string
+	if forwarded := header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(forwarded[len(forwarded)-1], ",")
+		remote = strings.TrimSpace(hops[len(hops)-1])
+	}
+
+	req, err := a.request(ctx, &Event{Source: SourceALB, Payload: payload}, incoming{
+		method:  event.HTTPMethod,
+		path:    event.Path,
+		escaped: true,
+		query:   rawQuery(query),
+		header:  header,
+		remote:  remote,
+		body:    event.Body,
+		encoded: event.IsBase64Encoded,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := a.serve(req)
+	body, encoded := resp.body()
+	out := events.ALBTargetGroupResponse{
+		StatusCode:        resp.status,
+		StatusDescription: strconv.Itoa(resp.status) + " " + http.StatusText(resp.status),
+		Body:              body,
+		IsBase64Encoded:   encoded,
+	}
+	// The response must use the header format of the target group
+	if multiValue {
+		out.MultiValueHeaders = resp.header
+	} else {
+		out.Headers = singleValueHeader(resp.header)
+	}
+	return json.Marshal(out)
+}
+
+// incoming holds the HTTP fields of an event.
+type incoming struct {
+	method  string
+	path    string
+	escaped bool // path is URL encoded
+	query   string
+	header  http.Header
+	host    string // used without a Host header
+	remote  string // client address
+	body    string
+	encoded bool // body is base64 encoded
+}
+
+// request builds the HTTP request of an event.
+func (a *Adapter) request(ctx context.Context, event *Event, in incoming) (*http.Request, error) {
+	if in.method == "" {
+		return nil, fmt.Errorf("%s event has no HTTP method", event.Source)
+	}
+	body := []byte(in.body)
+	if in.encoded {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(in.body); err != nil {
+			return nil, fmt.Errorf("decode %s body: %w", event.Source, err)
+		}
+	}
+
+	u := &url.URL{Path: in.path, RawQuery: in.query}
+	if in.escaped {
+		path, err := url.PathUnescape(in.path)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s path: %w", event.Source, err)
+		}
+		u.Path, u.RawPath = path, in.path
+	}
+	if a.basePath != "" && (u.Path == a.basePath || strings.HasPrefix(u.Path, a.basePath+"/")) {
+		u.Path, u.RawPath = strings.TrimPrefix(u.Path, a.basePath), strings.TrimPrefix(u.RawPath, a.basePath)
+	}
+	if u.Path == "" {
+		u.Path, u.RawPath = "/", ""
+	}
+
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, eventKey{}, event), in.method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build %s request: %w", event.Source, err)
+	}
+	if in.header.Get(requestIDHeader) == "" && event.RequestID != "" {
+		in.header.Set(requestIDHeader, event.RequestID)
+	}
+	req.Header = in.header
+	req.RequestURI = u.RequestURI()
+	req.Host = in.header.Get("Host")
+	if req.Host == "" {
+		req.Host = in.host
+	}
+	if in.remote != "" {
+		req.RemoteAddr = net.JoinHostPort(in.remote, "0")
+	}
+	return req, nil
+}
+
+// serve runs the handler on req.
+func (a *Adapter) serve(req *http.Request) *response {
+	resp := &response{header: http.Header{}}
+	a.handler.ServeHTTP(resp, req)
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+	return resp
+}
+
+// response buffers the response of an invocation.
+type response struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+}
+
+func (r *response) Header() http.Header { return r.header }
+
+func (r *response) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *response) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.buf.Write(p)
+}
+
+// Flush is a no-op: the response is returned once the handler finished.
+func (r *response) Flush() {}
+
+// body returns the response body, base64 encoded unless it is
+// uncompressed text.
+func (r *response) body() (string, bool) {
+	if r.buf.Len() == 0 {
+		return "", false
+	}
+	if r.header.Get("Content-Encoding") == "" && textual(r.header.Get("Content-Type")) {
+		return r.buf.String(), false
+	}
+	return base64.StdEncoding.EncodeToString(r.buf.Bytes()), true
+}
+
+// textual reports whether bodies of contentType can be returned as is.
+func textual(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, marker := range []string{"json", "xml", "javascript", "yaml", "x-www-form-urlencoded"} {
+		if strings.Contains(mediaType, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// multiValueHeader returns the canonical request header of an event,
+// preferring the multi-value form when present.
+func multiValueHeader(single map[string]string, multi map[string][]string) http.Header {
+	header := make(http.Header, len(single))
+	if len(multi) > 0 {
+		for k, values := range multi {
+			for _, v := range values {
+				header.Add(k, v)
+			}
+		}
+		return header
+	}
+	for k, v := range single {
+		header.Set(k, v)
+	}
+	return header
+}
+
+// singleValueHeader joins repeated response header values with commas.
+func singleValueHeader(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for k, values := range header {
+		out[k] = strings.Join(values, ",")
+	}
+	return out
+}
+
+// rawQuery joins query parameters that are already URL encoded, in key
+// order.
+func rawQuery(query map[string][]string) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range query[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(k)
+			b.WriteByte('=')
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
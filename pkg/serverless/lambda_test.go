@@ -0,0 +1,182 @@
+package serverless
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seen records the request served by echoHandler.
+type seen struct {
+	method, path, query, host, remote string
+	header                            http.Header
+	body                              []byte
+	event                             *Event
+}
+
+// echoHandler records the request and answers with a JSON body, a cookie
+// and a repeated header.
+func echoHandler(t *testing.T, got *seen) http.Handler {
+	t.Helper()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		event, _ := EventFromContext(r.Context())
+		*got = seen{
+			method: r.Method, path: r.URL.Path, query: r.URL.RawQuery, host: r.Host, remote: r.RemoteAddr,
+			header: r.Header, body: body, event: event,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Origin")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	})
+}
+
+// invoke encodes event, invokes the adapter and decodes the response into
+// out.
+func invoke(t *testing.T, adapter *Adapter, event, out any) {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	resp, err := adapter.Invoke(context.Background(), payload)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(resp, out))
+}
+
+func TestAdapter_ServesAPIGatewayEvents(t *testing.T) {
+	var got seen
+	adapter := New(echoHandler(t, &got), Options{})
+
+	var resp events.APIGatewayProxyResponse
+	invoke(t, adapter, events.APIGatewayProxyRequest{
+		HTTPMethod:                      http.MethodPost,
+		Path:                            "/api/v1/users",
+		MultiValueHeaders:               map[string][]string{"content-type": {"application/json"}, "host": {"api.example.com"}},
+		MultiValueQueryStringParameters: map[string][]string{"tag": {"a b", "c"}},
+		Body:                            base64.StdEncoding.EncodeToString([]byte(`{"name":"x"}`)),
+		IsBase64Encoded:                 true,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID: "req-1",
+			Stage:     "prod",
+			Identity:  events.APIGatewayRequestIdentity{SourceIP: "203.0.113.7"},
+		},
+	}, &resp)
+
+	assert.Equal(t, http.MethodPost, got.method)
+	assert.Equal(t, "/api/v1/users", got.path)
+	assert.Equal(t, "tag=a+b&tag=c", got.query)
+	assert.Equal(t, "api.example.com", got.host)
+	assert.Equal(t, "203.0.113.7:0", got.remote)
+	assert.Equal(t, "application/json", got.header.Get("Content-Type"))
+	assert.Equal(t, "req-1", got.header.Get("X-Request-ID"), "the gateway request ID is used without one")
+	assert.Equal(t, `{"name":"x"}`, string(got.body))
+	require.NotNil(t, got.event)
+	assert.Equal(t, SourceAPIGateway, got.event.Source)
+	assert.Equal(t, "prod", got.event.Stage)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `{"ok":true}`, resp.Body)
+	assert.False(t, resp.IsBase64Encoded)
+	assert.Equal(t, []string{"Accept", "Origin"}, resp.MultiValueHeaders["Vary"])
+}
+
+func TestAdapter_ServesHTTPAPIEvents(t *testing.T) {
+	var got seen
+	adapter := New(echoHandler(t, &got), Options{BasePath: "/prod/"})
+
+	var resp events.APIGatewayV2HTTPResponse
+	invoke(t, adapter, events.APIGatewayV2HTTPRequest{
+		Version:        "2.0",
+		RawPath:        "/prod/files/a%2Fb",
+		RawQueryString: "q=x%20y",
+		Cookies:        []string{"a=1", "b=2"},
+		Headers:        map[string]string{"x-request-id": "client-id"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RequestID:  "req-2",
+			DomainName: "abc.execute-api.eu-west-1.amazonaws.com",
+			HTTP:       events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodGet, SourceIP: "198.51.100.1"},
+		},
+	}, &resp)
+
+	assert.Equal(t, "/files/a/b", got.path, "the base path is stripped")
+	assert.Equal(t, "q=x%20y", got.query)
+	assert.Equal(t, "abc.execute-api.eu-west-1.amazonaws.com", got.host)
+	assert.Equal(t, "a=1; b=2", got.header.Get("Cookie"))
+	assert.Equal(t, "client-id", got.header.Get("X-Request-ID"))
+	assert.Equal(t, SourceHTTPAPI, got.event.Source)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []string{"session=abc"}, resp.Cookies)
+	assert.Equal(t, "Accept,Origin", resp.Headers["Vary"])
+	assert.NotContains(t, resp.Headers, "Set-Cookie")
+}
+
+func TestAdapter_ServesALBEvents(t *testing.T) {
+	var got seen
+	adapter := New(echoHandler(t, &got), Options{})
+	event := events.ALBTargetGroupRequest{
+		HTTPMethod:            http.MethodGet,
+		Path:                  "/health",
+		QueryStringParameters: map[string]string{"verbose": "true", "q": "a%20b"},
+		Headers:               map[string]string{"x-forwarded-for": "203.0.113.9, 10.0.0.1"},
+		RequestContext:        events.ALBTargetGroupRequestContext{ELB: events.ELBContext{TargetGroupArn: "arn"}},
+	}
+
+	var resp events.ALBTargetGroupResponse
+	invoke(t, adapter, event, &resp)
+
+	assert.Equal(t, "q=a%20b&verbose=true", got.query, "query parameters are passed as received")
+	assert.Equal(t, "10.0.0.1:0", got.remote)
+	assert.Equal(t, SourceALB, got.event.Source)
+	assert.Equal(t, "201 Created", resp.StatusDescription)
+	assert.Equal(t, "Accept,Origin", resp.Headers["Vary"])
+	assert.Empty(t, resp.MultiValueHeaders)
+
+	event.Headers, event.MultiValueHeaders = nil, map[string][]string{"accept": {"*/*"}}
+	resp = events.ALBTargetGroupResponse{}
+	invoke(t, adapter, event, &resp)
+
+	assert.Equal(t, []string{"Accept", "Origin"}, resp.MultiValueHeaders["Vary"], "multi-value target groups get multi-value headers")
+	assert.Empty(t, resp.Headers)
+}
+
+func TestAdapter_EncodesBinaryResponses(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G'}
+	adapter := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(png)
+	}), Options{})
+
+	var resp events.APIGatewayProxyResponse
+	invoke(t, adapter, events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/logo.png"}, &resp)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, resp.IsBase64Encoded)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(png), resp.Body)
+}
+
+func TestAdapter_RejectsInvalidEvents(t *testing.T) {
+	adapter := New(http.NotFoundHandler(), Options{})
+
+	for name, payload := range map[string]string{
+		"malformed": `{`,
+		"no method": `{"path": "/"}`,
+		"bad body":  `{"httpMethod": "POST", "path": "/", "body": "!", "isBase64Encoded": true}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := adapter.Invoke(context.Background(), []byte(payload))
+			assert.Error(t, err)
+		})
+	}
+}
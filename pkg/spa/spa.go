@@ -0,0 +1,195 @@
+// Package spa serves a built single-page application from an fs.FS.
+//
+// Static files are served with cache headers suited to fingerprinted
+// builds, pre-compressed .gz siblings are preferred when the client accepts
+// gzip, and unknown paths fall back to index.html so client-side routes
+// survive reloads.
+package spa
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Cache-Control values applied to served files.
+const (
+	CacheImmutable = "public, max-age=31536000, immutable"
+	CacheDefault   = "public, max-age=3600"
+	CacheNoCache   = "no-cache"
+)
+
+// Options configures the SPA handler.
+type Options struct {
+	// Index is the fallback document (default index.html).
+	Index string
+
+	// ImmutablePrefixes are directories holding fingerprinted assets that
+	// may be cached forever (default assets/).
+	ImmutablePrefixes []string
+
+	// ExcludePrefixes are URL paths never answered with the fallback
+	// document (e.g. /api/), so unknown API routes still 404.
+	ExcludePrefixes []string
+}
+
+// Handler serves SPA files from fsys.
+type Handler struct {
+	fsys      fs.FS
+	opts      Options
+	modTime   time.Time
+	indexBody []byte
+}
+
+// New creates an SPA handler. It fails when fsys has no index document.
+//
+// Parameters:
+//   - fsys: Built frontend files rooted at the document root
+//   - opts: Caching and routing options
+//
+// Returns:
+//   - *Handler: SPA handler
+//   - error: Missing index document
+func New(fsys fs.FS, opts Options) (*Handler, error) {
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+	if opts.ImmutablePrefixes == nil {
+		opts.ImmutablePrefixes = []string{"assets/"}
+	}
+
+	index, err := fs.ReadFile(fsys, opts.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		fsys:      fsys,
+		opts:      opts,
+		modTime:   time.Now(),
+		indexBody: index,
+	}, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	for _, prefix := range h.opts.ExcludePrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = h.opts.Index
+	}
+
+	if name != h.opts.Index && h.serveFile(w, r, name) {
+		return
+	}
+
+	// Missing files with an extension are real 404s, not client routes
+	if name != h.opts.Index && path.Ext(name) != "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", CacheNoCache)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeContent(w, r, h.opts.Index, h.modTime, bytes.NewReader(h.indexBody))
+}
+
+// serveFile writes name (or its .gz sibling) and reports whether it existed.
+func (h *Handler) serveFile(w http.ResponseWriter, r *http.Request, name string) bool {
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	// Caches must key on Accept-Encoding whenever a compressed variant
+	// exists, including for the identity response
+	served := name
+	if gz, err := fs.Stat(h.fsys, name+".gz"); err == nil && !gz.IsDir() {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			served = name + ".gz"
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+	}
+
+	content, err := open(h.fsys, served)
+	if err != nil {
+		return false
+	}
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		w.Header().Set("Content-Type", ctype)
+	}
+	w.Header().Set("Cache-Control", h.cacheControl(name))
+
+	// Range requests over compressed bytes would be ambiguous
+	if served != name {
+		r.Header.Del("Range")
+	}
+	http.ServeContent(w, r, name, h.modTime, content)
+	return true
+}
+
+// cacheControl picks the Cache-Control policy for name.
+func (h *Handler) cacheControl(name string) string {
+	for _, prefix := range h.opts.ImmutablePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return CacheImmutable
+		}
+	}
+	return CacheDefault
+}
+
+// open returns a seekable reader for name.
+func open(fsys fs.FS, name string) (io.ReadSeeker, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return readSeekCloser{ReadSeeker: rs, Closer: f}, nil
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// readSeekCloser keeps the file closable after the seeker assertion.
+type readSeekCloser struct {
+	io.ReadSeeker
+	io.Closer
+}
+
+// acceptsGzip reports whether the client accepts gzip responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") && strings.TrimSpace(params) != "q=0" {
+			return true
+		}
+	}
+	return false
+}
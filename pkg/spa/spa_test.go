@@ -0,0 +1,96 @@
+package spa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ServesAssetsWithCacheHeaders(t *testing.T) {
+	h := newTestHandler(t)
+
+	w := get(h, "/assets/app.3f2a.js", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "console.log('app')", w.Body.String())
+	assert.Equal(t, CacheImmutable, w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+
+	w = get(h, "/favicon.txt", "")
+	assert.Equal(t, CacheDefault, w.Header().Get("Cache-Control"))
+}
+
+func TestHandler_PrefersPrecompressedAssets(t *testing.T) {
+	h := newTestHandler(t)
+
+	w := get(h, "/assets/app.3f2a.js", "br, gzip")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Get("Vary"), "Accept-Encoding")
+
+	zr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "console.log('app')", string(body))
+
+	w = get(h, "/assets/app.3f2a.js", "gzip;q=0")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Get("Vary"), "Accept-Encoding", "identity responses of compressed assets vary too")
+
+	w = get(h, "/assets/app.3f2a.js", "")
+	assert.Contains(t, w.Header().Get("Vary"), "Accept-Encoding")
+}
+
+func TestHandler_HistoryFallback(t *testing.T) {
+	h := newTestHandler(t)
+
+	for _, p := range []string{"/", "/users/42/settings", "/index.html"} {
+		w := get(h, p, "")
+		assert.Equal(t, http.StatusOK, w.Code, p)
+		assert.Contains(t, w.Body.String(), "<div id=app>", p)
+		assert.Equal(t, CacheNoCache, w.Header().Get("Cache-Control"), p)
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(h, "/assets/missing.js", "").Code)
+	assert.Equal(t, http.StatusNotFound, get(h, "/api/v1/unknown", "").Code)
+}
+
+func TestNew_RequiresIndex(t *testing.T) {
+	_, err := New(fstest.MapFS{}, Options{})
+	assert.Error(t, err)
+}
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte("console.log('app')"))
+	require.NoError(t, zw.Close())
+
+	h, err := New(fstest.MapFS{
+		"index.html":            {Data: []byte("<div id=app></div>")},
+		"favicon.txt":           {Data: []byte("icon")},
+		"assets/app.3f2a.js":    {Data: []byte("console.log('app')")},
+		"assets/app.3f2a.js.gz": {Data: gz.Bytes()},
+	}, Options{ExcludePrefixes: []string{"/api/"}})
+	require.NoError(t, err)
+	return h
+}
+
+func get(h http.Handler, target, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
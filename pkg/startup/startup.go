@@ -0,0 +1,129 @@
+// Package startup times the phases of process startup against a budget,
+// so cold start regressions of serverless and autoscaled deployments show
+// up in the logs:
+//
+//	timer := startup.New(time.Now())
+//	cfg, err := config.Load()
+//	timer.Mark("config")
+//	// ...
+//	timer.Report(log, budget)
+//
+// A nil *Timer records nothing.
+package startup
+
+import (
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+)
+
+// Phase names marked by the serve command.
+const (
+	PhaseConfig       = "config"       // Loading and validating the configuration
+	PhaseDependencies = "dependencies" // Building the container
+	PhaseRuntime      = "runtime"      // Runtime tuning, seeding and worker registration
+	PhaseRoutes       = "routes"       // Building the router
+	PhaseListen       = "listen"       // Starting plugins and the listener
+	PhaseWarmup       = "warmup"       // Running the warmup hooks
+)
+
+// Phase is a timed startup step.
+type Phase struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+
+	duration time.Duration
+}
+
+// Duration returns how long the phase took.
+func (p Phase) Duration() time.Duration {
+	return p.duration
+}
+
+// Timer records consecutive startup phases.
+type Timer struct {
+	mu       sync.Mutex
+	start    time.Time
+	last     time.Time
+	phases   []Phase
+	reported bool
+}
+
+// New returns a timer whose first phase starts at start, e.g. the process
+// start time.
+func New(start time.Time) *Timer {
+	return &Timer{start: start, last: start}
+}
+
+// Mark ends the phase name, which started when the previous one ended,
+// and returns its duration.
+func (t *Timer) Mark(name string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	d := now.Sub(t.last)
+	t.last = now
+	t.phases = append(t.phases, Phase{Name: name, DurationMS: d.Milliseconds(), duration: d})
+	return d
+}
+
+// Phases returns the phases marked so far, in order.
+func (t *Timer) Phases() []Phase {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Phase(nil), t.phases...)
+}
+
+// Elapsed returns the time from the start to the end of the last phase.
+func (t *Timer) Elapsed() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last.Sub(t.start)
+}
+
+// Report logs the phases and the total startup time once, warning with
+// the slowest phase when the total exceeds budget (0 is unlimited). It
+// reports whether startup stayed within the budget.
+func (t *Timer) Report(log *logger.Logger, budget time.Duration) bool {
+	if t == nil {
+		return true
+	}
+	t.mu.Lock()
+	if t.reported {
+		t.mu.Unlock()
+		return budget <= 0 || t.last.Sub(t.start) <= budget
+	}
+	t.reported = true
+	t.mu.Unlock()
+
+	phases, total := t.Phases(), t.Elapsed()
+	log.Infow("startup_complete", "duration_ms", total.Milliseconds(), "phases", phases)
+	if budget <= 0 || total <= budget {
+		return true
+	}
+
+	var slowest Phase
+	for _, p := range phases {
+		if p.duration > slowest.duration {
+			slowest = p
+		}
+	}
+	log.Warnw("startup_budget_exceeded",
+		"duration_ms", total.Milliseconds(),
+		"budget_ms", budget.Milliseconds(),
+		"slowest_phase", slowest.Name,
+		"slowest_phase_ms", slowest.DurationMS,
+	)
+	return false
+}
@@ -0,0 +1,93 @@
+package startup
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBufferLogger returns a logger writing JSON entries to buf.
+func newBufferLogger(t *testing.T, buf *bytes.Buffer) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{
+		Level:     "INFO",
+		Format:    "json",
+		NoConsole: true,
+		Outputs:   []logger.Output{{Writer: buf, Format: "json", Level: "INFO"}},
+	})
+	require.NoError(t, err)
+	return log
+}
+
+// entries decodes the JSON log lines of buf.
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestTimer_MarksConsecutivePhases(t *testing.T) {
+	timer := New(time.Now().Add(-20 * time.Millisecond))
+
+	first := timer.Mark(PhaseConfig)
+	time.Sleep(5 * time.Millisecond)
+	second := timer.Mark(PhaseRoutes)
+
+	phases := timer.Phases()
+	require.Len(t, phases, 2)
+	assert.Equal(t, PhaseConfig, phases[0].Name)
+	assert.GreaterOrEqual(t, first, 20*time.Millisecond, "the first phase starts at the start time")
+	assert.GreaterOrEqual(t, second, 5*time.Millisecond)
+	assert.Equal(t, first+second, timer.Elapsed())
+}
+
+func TestTimer_ReportWithinBudget(t *testing.T) {
+	var buf bytes.Buffer
+	timer := New(time.Now())
+	timer.Mark(PhaseConfig)
+
+	assert.True(t, timer.Report(newBufferLogger(t, &buf), time.Minute))
+
+	logged := entries(t, &buf)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "startup_complete", logged[0]["msg"])
+	phases, ok := logged[0]["phases"].([]any)
+	require.True(t, ok)
+	assert.Equal(t, PhaseConfig, phases[0].(map[string]any)["name"])
+}
+
+func TestTimer_ReportExceededBudget(t *testing.T) {
+	var buf bytes.Buffer
+	timer := New(time.Now().Add(-50 * time.Millisecond))
+	timer.Mark(PhaseDependencies)
+	timer.Mark(PhaseRoutes)
+	log := newBufferLogger(t, &buf)
+
+	assert.False(t, timer.Report(log, 10*time.Millisecond))
+	assert.False(t, timer.Report(log, 10*time.Millisecond), "later reports only check the budget")
+
+	logged := entries(t, &buf)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "startup_budget_exceeded", logged[1]["msg"])
+	assert.Equal(t, PhaseDependencies, logged[1]["slowest_phase"])
+	assert.EqualValues(t, 10, logged[1]["budget_ms"])
+}
+
+func TestTimer_Nil(t *testing.T) {
+	var timer *Timer
+
+	assert.Zero(t, timer.Mark(PhaseConfig))
+	assert.Empty(t, timer.Phases())
+	assert.True(t, timer.Report(nil, time.Nanosecond))
+}
@@ -0,0 +1,229 @@
+package strictjson
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	jsonUnmarshaler = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// field is a struct field decoded from a JSON object key.
+type field struct {
+	name   string // JSON key
+	goName string
+	typ    reflect.Type
+}
+
+// structFields caches the JSON fields of struct types.
+var structFields sync.Map // reflect.Type -> []field
+
+// fieldsOf returns the JSON fields of the struct type t, including those
+// promoted from embedded structs, like encoding/json.
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := structFields.Load(t); ok {
+		return cached.([]field)
+	}
+	var out []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			out = append(out, fieldsOf(ft)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		out = append(out, field{name: name, goName: sf.Name, typ: sf.Type})
+	}
+	structFields.Store(t, out)
+	return out
+}
+
+// lookup returns the field decoded from key, matched case-insensitively
+// like encoding/json.
+func lookup(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// unknownFields returns a violation for every key of doc that decoding
+// into v would drop.
+func unknownFields(v any, doc any) []Violation {
+	var out []Violation
+	walkUnknown(reflect.TypeOf(v), doc, "", &out)
+	return out
+}
+
+func walkUnknown(t reflect.Type, doc any, path string, out *[]Violation) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || decodesItself(t) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return // Reported as a type error when decoding
+		}
+		fields := fieldsOf(t)
+		for _, key := range sortedKeys(obj) {
+			f, ok := lookup(fields, key)
+			if !ok {
+				*out = append(*out, Violation{Field: joinPath(path, key), Code: CodeUnknownField, Message: "unknown field"})
+				continue
+			}
+			walkUnknown(f.typ, obj[key], joinPath(path, key), out)
+		}
+	case reflect.Map:
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			walkUnknown(t.Elem(), obj[key], joinPath(path, key), out)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := doc.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			walkUnknown(t.Elem(), item, path+"["+strconv.Itoa(i)+"]", out)
+		}
+	}
+}
+
+// decodesItself reports whether t implements its own JSON decoding, whose
+// accepted keys cannot be known.
+func decodesItself(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(jsonUnmarshaler) || pt.Implements(textUnmarshaler)
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidationViolations converts the validator errors of v into violations
+// named by JSON path. It returns nil when err is not a
+// validator.ValidationErrors.
+func ValidationViolations(err error, v any) []Violation {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	out := make([]Violation, len(errs))
+	for i, fe := range errs {
+		out[i] = Violation{
+			Field:   jsonPath(reflect.TypeOf(v), fe.StructNamespace()),
+			Code:    fe.Tag(),
+			Message: validationMessage(fe),
+		}
+	}
+	return out
+}
+
+// validationMessage describes the rule a field failed.
+func validationMessage(fe validator.FieldError) string {
+	switch {
+	case fe.Tag() == "required":
+		return "is required"
+	case fe.Param() != "":
+		return fmt.Sprintf("must satisfy %s=%s", fe.Tag(), fe.Param())
+	default:
+		return "must satisfy " + fe.Tag()
+	}
+}
+
+// jsonPath maps the struct namespace ns of the root type t, e.g.
+// "CreateUserRequest.Items[0].Email", to the JSON path "items[0].email".
+// Segments that cannot be resolved keep their Go names.
+func jsonPath(t reflect.Type, ns string) string {
+	_, rest, _ := strings.Cut(ns, ".")
+	var path string
+	for _, seg := range strings.Split(rest, ".") {
+		name, index, _ := strings.Cut(seg, "[")
+		if index != "" {
+			index = "[" + index
+		}
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		key := name
+		var next reflect.Type
+		if t != nil && t.Kind() == reflect.Struct {
+			if sf, ok := t.FieldByName(name); ok {
+				next = sf.Type
+				if tag, _, _ := strings.Cut(sf.Tag.Get("json"), ","); sf.Anonymous && tag == "" {
+					// Embedded struct fields are promoted into the object
+					t = next
+					continue
+				}
+				for _, f := range fieldsOf(t) {
+					if f.goName == name {
+						key = f.name
+						break
+					}
+				}
+			}
+		}
+		path = joinPath(path, key) + index
+
+		// Each [i] or [key] steps into the element type
+		for n := strings.Count(index, "["); n > 0 && next != nil; n-- {
+			for next.Kind() == reflect.Pointer {
+				next = next.Elem()
+			}
+			switch next.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				next = next.Elem()
+			default:
+				next = nil
+			}
+		}
+		t = next
+	}
+	return path
+}
@@ -0,0 +1,243 @@
+// Package strictjson decodes JSON request bodies strictly: unknown object
+// fields, trailing data and bodies nested or sized beyond the configured
+// limits are rejected instead of silently ignored, and every problem is
+// reported as a Violation naming the offending field.
+//
+// encoding/json drops fields the target struct does not declare, so a
+// client sending "emial" instead of "email" only learns about the typo from
+// a confusing "email is required" error, or not at all for optional fields:
+//
+//	var req CreateUserRequest
+//	if err := strictjson.Decode(body, &req, strictjson.Options{DisallowUnknownFields: true}); err != nil {
+//		// err is an *Error listing {"field": "emial", "code": "unknown_field"}
+//	}
+package strictjson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Violation codes reported for malformed bodies. Validation failures are
+// reported with the validation tag as their code (e.g. "required").
+const (
+	CodeSyntax       = "syntax"
+	CodeType         = "type"
+	CodeUnknownField = "unknown_field"
+	CodeMaxDepth     = "max_depth"
+	CodeMaxItems     = "max_items"
+)
+
+// Options selects the checks applied by Decode.
+type Options struct {
+	DisallowUnknownFields bool
+	MaxDepth              int // Nesting of objects and arrays; 0 is unlimited
+	MaxArrayLen           int // Items of any one array; 0 is unlimited
+}
+
+// Violation is a problem with one field of a request body.
+type Violation struct {
+	// Field is the JSON path of the field, e.g. "items[2].email"; empty for
+	// the whole body
+	Field   string `json:"field" example:"emial"`
+	Code    string `json:"code" example:"unknown_field"`
+	Message string `json:"message" example:"unknown field"`
+}
+
+// Error lists the violations of a rejected body.
+type Error struct {
+	Violations []Violation
+}
+
+// Error describes the first violation and how many more follow.
+func (e *Error) Error() string {
+	if len(e.Violations) == 0 {
+		return "invalid request body"
+	}
+	v := e.Violations[0]
+	msg := v.Message
+	if v.Field != "" {
+		msg = v.Field + ": " + msg
+	}
+	if n := len(e.Violations) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+// Decode decodes the JSON document data into v, which must be a pointer,
+// applying opts. Malformed bodies are reported as an *Error.
+func Decode(data []byte, v any, opts Options) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return violation("", CodeSyntax, "request body is empty")
+	}
+	if err := checkShape(data, opts); err != nil {
+		return err
+	}
+	if opts.DisallowUnknownFields {
+		var doc any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return violation("", CodeSyntax, err.Error())
+		}
+		if found := unknownFields(v, doc); len(found) > 0 {
+			return &Error{Violations: found}
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return violation(bracketIndices(typeErr.Field), CodeType, fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value))
+		}
+		var invalid *json.InvalidUnmarshalError
+		if errors.As(err, &invalid) {
+			return err
+		}
+		return violation("", CodeSyntax, err.Error())
+	}
+	return nil
+}
+
+// frame is an open object or array while scanning a document.
+type frame struct {
+	array   bool
+	items   int    // Values read so far
+	wantKey bool   // The next token of an object is a key
+	key     string // Key of the value being read
+	path    string
+}
+
+// checkShape scans the tokens of data, rejecting syntax errors, trailing
+// data and documents beyond the depth and array limits before anything is
+// allocated for them.
+func checkShape(data []byte, opts Options) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []frame
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return violation(currentPath(stack), CodeSyntax, err.Error())
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			if err := endValue(dec, stack); err != nil {
+				return err
+			}
+			continue
+		}
+		if n := len(stack); n > 0 && stack[n-1].wantKey {
+			stack[n-1].key, stack[n-1].wantKey = tok.(string), false
+			continue
+		}
+
+		path := valuePath(stack)
+		if n := len(stack); n > 0 {
+			top := &stack[n-1]
+			top.items++
+			if top.array && opts.MaxArrayLen > 0 && top.items > opts.MaxArrayLen {
+				return violation(top.path, CodeMaxItems, fmt.Sprintf("array has more than %d items", opts.MaxArrayLen))
+			}
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			if opts.MaxDepth > 0 && len(stack) >= opts.MaxDepth {
+				return violation(path, CodeMaxDepth, fmt.Sprintf("nested deeper than %d levels", opts.MaxDepth))
+			}
+			stack = append(stack, frame{array: delim == '[', wantKey: delim == '{', path: path})
+			continue
+		}
+		if err := endValue(dec, stack); err != nil {
+			return err
+		}
+	}
+}
+
+// endValue records that a value of the innermost open object or array was
+// read, or rejects data following the root value.
+func endValue(dec *json.Decoder, stack []frame) error {
+	if n := len(stack); n > 0 {
+		stack[n-1].wantKey = !stack[n-1].array
+		return nil
+	}
+	if dec.More() {
+		return violation("", CodeSyntax, "unexpected data after the JSON document")
+	}
+	return nil
+}
+
+// valuePath returns the path of the value about to be read.
+func valuePath(stack []frame) string {
+	n := len(stack)
+	if n == 0 {
+		return ""
+	}
+	top := stack[n-1]
+	if top.array {
+		return top.path + "[" + strconv.Itoa(top.items) + "]"
+	}
+	return joinPath(top.path, top.key)
+}
+
+// currentPath returns the path of the innermost open value.
+func currentPath(stack []frame) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	return stack[len(stack)-1].path
+}
+
+// joinPath appends the object key to path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// bracketIndices rewrites the array indices of an encoding/json field
+// path, "items.0.email", in the form of violations, "items[0].email".
+func bracketIndices(field string) string {
+	segments := strings.Split(field, ".")
+	var path string
+	for _, seg := range segments {
+		if _, err := strconv.Atoi(seg); err == nil && path != "" {
+			path += "[" + seg + "]"
+			continue
+		}
+		path = joinPath(path, seg)
+	}
+	return path
+}
+
+// violation returns an *Error with a single violation.
+func violation(field, code, message string) *Error {
+	return &Error{Violations: []Violation{{Field: field, Code: code, Message: message}}}
+}
+
+type contextKey struct{}
+
+// WithOptions returns a copy of ctx requesting strict decoding with opts.
+func WithOptions(ctx context.Context, opts Options) context.Context {
+	return context.WithValue(ctx, contextKey{}, opts)
+}
+
+// FromContext returns the options stored by WithOptions and whether strict
+// decoding was requested.
+func FromContext(ctx context.Context) (Options, bool) {
+	opts, ok := ctx.Value(contextKey{}).(Options)
+	return opts, ok
+}
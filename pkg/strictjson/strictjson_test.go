@@ -0,0 +1,135 @@
+package strictjson
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type audit struct {
+	Note string `json:"note"`
+}
+
+type request struct {
+	audit
+	Email     string            `json:"email" validate:"required,email"`
+	Name      string            `json:"name,omitempty"`
+	Addresses []address         `json:"addresses" validate:"dive"`
+	Labels    map[string]string `json:"labels"`
+	Extra     json.RawMessage   `json:"extra"`
+	At        time.Time         `json:"at"`
+	Internal  string            `json:"-"`
+}
+
+func TestDecode_UnknownFields(t *testing.T) {
+	opts := Options{DisallowUnknownFields: true}
+	body := `{"emial":"a@example.com","Name":"Jane","note":"n","addresses":[{"city":"Oslo","zip":"0150"}],
+		"labels":{"any":"key"},"extra":{"free":"form"},"Internal":"x"}`
+
+	var req request
+	err := Decode([]byte(body), &req, opts)
+
+	var strictErr *Error
+	require.ErrorAs(t, err, &strictErr)
+	assert.Equal(t, []Violation{
+		{Field: "Internal", Code: CodeUnknownField, Message: "unknown field"},
+		{Field: "addresses[0].zip", Code: CodeUnknownField, Message: "unknown field"},
+		{Field: "emial", Code: CodeUnknownField, Message: "unknown field"},
+	}, strictErr.Violations)
+	assert.Equal(t, "Internal: unknown field (and 2 more)", err.Error())
+
+	// Without the option unknown fields are dropped like encoding/json
+	require.NoError(t, Decode([]byte(body), &req, Options{}))
+	assert.Equal(t, "Jane", req.Name, "keys match case-insensitively")
+	assert.Equal(t, "n", req.Note, "embedded fields are promoted")
+}
+
+func TestDecode_Limits(t *testing.T) {
+	var v any
+	tests := []struct {
+		name string
+		body string
+		opts Options
+		want Violation
+	}{
+		{
+			name: "depth",
+			body: `{"a":{"b":[{"c":1}]}}`,
+			opts: Options{MaxDepth: 3},
+			want: Violation{Field: "a.b[0]", Code: CodeMaxDepth, Message: "nested deeper than 3 levels"},
+		},
+		{
+			name: "array length",
+			body: `{"items":[{"tags":[1,2,3]}]}`,
+			opts: Options{MaxArrayLen: 2},
+			want: Violation{Field: "items[0].tags", Code: CodeMaxItems, Message: "array has more than 2 items"},
+		},
+		{
+			name: "trailing data",
+			body: `{"a":1} {"b":2}`,
+			want: Violation{Code: CodeSyntax, Message: "unexpected data after the JSON document"},
+		},
+		{
+			name: "empty",
+			body: " ",
+			want: Violation{Code: CodeSyntax, Message: "request body is empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Decode([]byte(tt.body), &v, tt.opts)
+			var strictErr *Error
+			require.ErrorAs(t, err, &strictErr)
+			assert.Equal(t, []Violation{tt.want}, strictErr.Violations)
+		})
+	}
+
+	require.NoError(t, Decode([]byte(`{"a":{"b":[1,2]}}`), &v, Options{MaxDepth: 3, MaxArrayLen: 2}))
+}
+
+func TestDecode_SyntaxAndTypeErrors(t *testing.T) {
+	var req request
+	var strictErr *Error
+
+	err := Decode([]byte(`{"email":`), &req, Options{})
+	require.ErrorAs(t, err, &strictErr)
+	assert.Equal(t, CodeSyntax, strictErr.Violations[0].Code)
+
+	err = Decode([]byte(`{"addresses":[{"city":7}]}`), &req, Options{})
+	require.ErrorAs(t, err, &strictErr)
+	assert.Equal(t, "addresses[0].city", strictErr.Violations[0].Field)
+	assert.Equal(t, CodeType, strictErr.Violations[0].Code)
+	assert.True(t, strings.HasPrefix(strictErr.Violations[0].Message, "expected string"))
+}
+
+func TestValidationViolations(t *testing.T) {
+	req := request{Email: "not-an-email", Addresses: []address{{City: "Oslo"}, {}}}
+	err := validator.New().Struct(&req)
+	require.Error(t, err)
+
+	assert.Equal(t, []Violation{
+		{Field: "email", Code: "email", Message: "must satisfy email"},
+		{Field: "addresses[1].city", Code: "required", Message: "is required"},
+	}, ValidationViolations(err, &req))
+	assert.Nil(t, ValidationViolations(assert.AnError, &req))
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	opts := Options{DisallowUnknownFields: true, MaxDepth: 4}
+	got, ok := FromContext(WithOptions(context.Background(), opts))
+	assert.True(t, ok)
+	assert.Equal(t, opts, got)
+}
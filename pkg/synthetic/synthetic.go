@@ -0,0 +1,313 @@
+// Package synthetic probes HTTP endpoints on a schedule, the server's own
+// routes as well as upstreams, so outages show up before users report them.
+//
+// Every Check runs on its own interval. Results are published as metrics
+// and kept per check: a check becomes failing after Failures consecutive
+// failed probes and recovers with the first successful one, which lets
+// health endpoints aggregate them without probing on every request.
+package synthetic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Defaults applied to zero-valued Check and Options fields.
+const (
+	DefaultInterval = time.Minute
+	DefaultTimeout  = 10 * time.Second
+)
+
+// maxBodyBytes bounds the response body read to match Contains.
+const maxBodyBytes = 1 << 20
+
+// Check declares one probe.
+type Check struct {
+	Name string `yaml:"name" validate:"required"`
+
+	// URL is probed as is when absolute; a path is probed on the server
+	// itself
+	URL     string            `yaml:"url" validate:"required,url|startswith=/"`
+	Method  string            `yaml:"method" validate:"omitempty,oneof=GET HEAD POST PUT"` // Default GET
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	Client  string            `yaml:"client"` // Named HTTP client (default client otherwise)
+
+	Interval time.Duration `yaml:"interval" validate:"omitempty,min=1s"` // Time between probes (default Options.Interval)
+	Timeout  time.Duration `yaml:"timeout" validate:"min=0"`             // Probe timeout (default Options.Timeout)
+
+	// Expectations; a probe fails on a transport error or when any is unmet
+	Status     []int         `yaml:"status" validate:"dive,min=100,max=599"` // Accepted statuses (default any 2xx)
+	Contains   string        `yaml:"contains"`                               // Substring of the response body
+	MaxLatency time.Duration `yaml:"max_latency" validate:"min=0"`           // Slowest acceptable response
+
+	// Failures is the number of consecutive failed probes before the check
+	// reports failing (default 1)
+	Failures int `yaml:"failures" validate:"min=0"`
+}
+
+// Result is the latest outcome of a check.
+type Result struct {
+	Check    string        `json:"check"`
+	OK       bool          `json:"ok"`
+	Failing  bool          `json:"failing"` // Failures consecutive probes failed
+	Status   int           `json:"status,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+	Failures int           `json:"consecutive_failures"`
+	At       time.Time     `json:"at"`
+}
+
+// Options configures a Runner.
+type Options struct {
+	BaseURL  string                // Resolves the path URLs of checks, e.g. http://127.0.0.1:8000
+	Interval time.Duration         // Interval of checks without one (default 1m)
+	Timeout  time.Duration         // Timeout of checks without one (default 10s)
+	Metrics  prometheus.Registerer // Registers the check metrics when set
+}
+
+// Runner executes checks and keeps their results. It is safe for
+// concurrent use.
+type Runner struct {
+	checks  []Check
+	opts    Options
+	clients func(name string) *http.Client
+	log     *logger.Logger
+	metrics *metrics
+
+	mu      sync.RWMutex
+	results map[string]Result
+}
+
+// New creates a runner; call Run to start probing.
+//
+// Parameters:
+//   - checks: Probes to run; names must be unique
+//   - opts: Base URL of the server, default interval and timeout, metrics registry
+//   - clients: Returns the HTTP client named by a check ("" for the default)
+//   - log: Structured logger receiving failures and recoveries
+//
+// Returns:
+//   - *Runner: Runner with no results yet
+func New(checks []Check, opts Options, clients func(name string) *http.Client, log *logger.Logger) *Runner {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	r := &Runner{opts: opts, clients: clients, log: log, results: make(map[string]Result, len(checks))}
+	for _, check := range checks {
+		if check.Method == "" {
+			check.Method = http.MethodGet
+		}
+		if check.Interval <= 0 {
+			check.Interval = opts.Interval
+		}
+		if check.Timeout <= 0 {
+			check.Timeout = opts.Timeout
+		}
+		if check.Failures <= 0 {
+			check.Failures = 1
+		}
+		if strings.HasPrefix(check.URL, "/") {
+			check.URL = strings.TrimSuffix(opts.BaseURL, "/") + check.URL
+		}
+		r.checks = append(r.checks, check)
+	}
+	if opts.Metrics != nil {
+		r.metrics = newMetrics(opts.Metrics)
+	}
+	return r
+}
+
+// Names returns the names of the checks in declaration order.
+func (r *Runner) Names() []string {
+	names := make([]string, len(r.checks))
+	for i, check := range r.checks {
+		names[i] = check.Name
+	}
+	return names
+}
+
+// Run probes every check immediately and then on its interval until ctx
+// is done. It always returns nil; failures are recorded and logged.
+func (r *Runner) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, check := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(check.Interval)
+			defer ticker.Stop()
+			for {
+				r.probe(ctx, check)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Probe runs every check once and returns the results.
+func (r *Runner) Probe(ctx context.Context) []Result {
+	for _, check := range r.checks {
+		r.probe(ctx, check)
+	}
+	return r.Results()
+}
+
+// Results returns the latest result of every check that ran, in
+// declaration order.
+func (r *Runner) Results() []Result {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Result, 0, len(r.results))
+	for _, check := range r.checks {
+		if result, ok := r.results[check.Name]; ok {
+			out = append(out, result)
+		}
+	}
+	return out
+}
+
+// Err returns the last error of a failing check; nil while the check
+// passes, has not run yet or is unknown.
+func (r *Runner) Err(name string) error {
+	r.mu.RLock()
+	result, ok := r.results[name]
+	r.mu.RUnlock()
+	if !ok || !result.Failing {
+		return nil
+	}
+	return errors.New(result.Error)
+}
+
+// probe runs check once and records the result.
+func (r *Runner) probe(ctx context.Context, check Check) {
+	start := time.Now()
+	status, err := r.do(ctx, check)
+	latency := time.Since(start)
+	if err == nil && check.MaxLatency > 0 && latency > check.MaxLatency {
+		err = fmt.Errorf("responded in %s, above %s", latency.Round(time.Millisecond), check.MaxLatency)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the probe says nothing about the target
+		return
+	}
+
+	r.mu.Lock()
+	previous := r.results[check.Name]
+	result := Result{Check: check.Name, OK: err == nil, Status: status, Latency: latency, At: start}
+	if err != nil {
+		result.Error = err.Error()
+		result.Failures = previous.Failures + 1
+		result.Failing = result.Failures >= check.Failures
+	}
+	r.results[check.Name] = result
+	r.mu.Unlock()
+
+	r.metrics.record(result)
+	switch {
+	case result.Failing && !previous.Failing:
+		r.log.Warnw("synthetic_check_failed", "check", check.Name, "status", status,
+			"failures", result.Failures, "error", result.Error)
+	case !result.Failing && previous.Failing:
+		r.log.Infow("synthetic_check_recovered", "check", check.Name, "latency_ms", latency.Milliseconds())
+	}
+}
+
+// do sends the request of check and verifies the response.
+func (r *Runner) do(ctx context.Context, check Check) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if check.Body != "" {
+		body = strings.NewReader(check.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, check.Method, check.URL, body)
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range check.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.clients(check.Client).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if len(check.Status) > 0 && !slices.Contains(check.Status, resp.StatusCode) ||
+		len(check.Status) == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if check.Contains != "" && !strings.Contains(string(data), check.Contains) {
+		return resp.StatusCode, fmt.Errorf("response does not contain %q", check.Contains)
+	}
+	return resp.StatusCode, nil
+}
+
+// metrics publishes probe results. A nil *metrics records nothing.
+type metrics struct {
+	duration *prometheus.HistogramVec
+	probes   *prometheus.CounterVec
+	up       *prometheus.GaugeVec
+}
+
+// newMetrics creates the check metrics and registers them.
+// It panics if the metrics are already registered.
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "synthetic_check_duration_seconds",
+			Help:    "Latency of synthetic probes by check.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"check"}),
+		probes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "synthetic_checks_total",
+			Help: "Synthetic probes by check and result (success or failure).",
+		}, []string{"check", "result"}),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "synthetic_check_up",
+			Help: "Whether the last synthetic probe of a check succeeded (1) or failed (0).",
+		}, []string{"check"}),
+	}
+	reg.MustRegister(m.duration, m.probes, m.up)
+	return m
+}
+
+// record publishes result.
+func (m *metrics) record(result Result) {
+	if m == nil {
+		return
+	}
+	m.duration.WithLabelValues(result.Check).Observe(result.Latency.Seconds())
+	outcome, up := "failure", 0.0
+	if result.OK {
+		outcome, up = "success", 1
+	}
+	m.probes.WithLabelValues(result.Check, outcome).Inc()
+	m.up.WithLabelValues(result.Check).Set(up)
+}
@@ -0,0 +1,141 @@
+package synthetic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "ERROR", Format: "json"})
+	require.NoError(t, err)
+	return log
+}
+
+func TestProbe_VerifiesExpectations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health/ready":
+			_, _ = w.Write([]byte(`{"status":"healthy"}`))
+		case "/created":
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "probe", r.Header.Get("X-Synthetic"))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewRegistry()
+	clients := func(string) *http.Client { return srv.Client() }
+	runner := New([]Check{
+		{Name: "ready", URL: "/health/ready", Contains: `"healthy"`},
+		{Name: "create", URL: srv.URL + "/created", Method: http.MethodPost, Headers: map[string]string{"X-Synthetic": "probe"}, Status: []int{201}},
+		{Name: "missing", URL: "/missing"},
+		{Name: "wording", URL: "/health/ready", Contains: "degraded"},
+	}, Options{BaseURL: srv.URL + "/", Metrics: reg}, clients, newTestLogger(t))
+
+	results := runner.Probe(context.Background())
+
+	require.Len(t, results, 4)
+	assert.True(t, results[0].OK)
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.True(t, results[1].OK)
+	assert.False(t, results[2].OK)
+	assert.Contains(t, results[2].Error, "unexpected status 404")
+	assert.Contains(t, results[3].Error, `does not contain "degraded"`)
+	assert.NoError(t, runner.Err("ready"))
+	assert.Error(t, runner.Err("missing"))
+	assert.NoError(t, runner.Err("unknown"))
+	assert.Equal(t, []string{"ready", "create", "missing", "wording"}, runner.Names())
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(runner.metrics.up.WithLabelValues("ready")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(runner.metrics.up.WithLabelValues("missing")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(runner.metrics.probes.WithLabelValues("missing", "failure")))
+	assert.Equal(t, 4, testutil.CollectAndCount(runner.metrics.duration))
+}
+
+func TestProbe_FailsAfterConsecutiveFailures(t *testing.T) {
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	runner := New([]Check{{Name: "upstream", URL: srv.URL, Failures: 2}}, Options{},
+		func(string) *http.Client { return srv.Client() }, newTestLogger(t))
+	ctx := context.Background()
+
+	result := runner.Probe(ctx)[0]
+	assert.False(t, result.OK)
+	assert.False(t, result.Failing, "a single failure is tolerated")
+	assert.NoError(t, runner.Err("upstream"))
+
+	result = runner.Probe(ctx)[0]
+	assert.True(t, result.Failing)
+	assert.Equal(t, 2, result.Failures)
+	assert.ErrorContains(t, runner.Err("upstream"), "503")
+
+	healthy.Store(true)
+	result = runner.Probe(ctx)[0]
+	assert.True(t, result.OK)
+	assert.Zero(t, result.Failures)
+	assert.NoError(t, runner.Err("upstream"))
+}
+
+func TestProbe_EnforcesLatencyAndTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	runner := New([]Check{
+		{Name: "slow", URL: srv.URL, MaxLatency: 10 * time.Millisecond},
+		{Name: "timeout", URL: srv.URL, Timeout: 10 * time.Millisecond},
+	}, Options{}, func(string) *http.Client { return srv.Client() }, newTestLogger(t))
+
+	results := runner.Probe(context.Background())
+
+	assert.Contains(t, results[0].Error, "above 10ms")
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.True(t, strings.Contains(results[1].Error, "deadline exceeded"), results[1].Error)
+}
+
+func TestRun_ProbesUntilCanceled(t *testing.T) {
+	var probes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+	}))
+	defer srv.Close()
+
+	runner := New([]Check{{Name: "self", URL: "/", Interval: 10 * time.Millisecond}}, Options{BaseURL: srv.URL},
+		func(string) *http.Client { return srv.Client() }, newTestLogger(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runner.Run(ctx) }()
+
+	assert.Eventually(t, func() bool { return probes.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+	require.Len(t, runner.Results(), 1)
+	assert.True(t, runner.Results()[0].OK)
+}
@@ -0,0 +1,170 @@
+// Package totp implements time-based one-time passwords (RFC 6238) as
+// used by authenticator apps, and single-use backup codes.
+//
+//	secret, _ := totp.GenerateSecret()
+//	uri := totp.URI("Example", "jane@example.com", secret, totp.Options{})
+//	counter, ok := totp.Validate(code, secret, time.Now(), totp.Options{Skew: 1})
+//
+// Validate returns the time step a code matched so callers can reject
+// replays by only accepting steps after the last accepted one.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of authenticator apps, which commonly ignore other values.
+const (
+	DefaultPeriod = 30 * time.Second
+	DefaultDigits = 6
+)
+
+// secretSize is the secret length recommended by RFC 4226 (160 bits).
+const secretSize = 20
+
+// encoding is the unpadded base32 alphabet of provisioning URIs.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Options configures code generation and validation.
+type Options struct {
+	Period time.Duration // Time step in whole seconds (default 30s)
+	Digits int           // Code length, 6 to 8 (default 6)
+	Skew   int           // Time steps accepted before and after the current one
+}
+
+// withDefaults fills unset options. Periods are truncated to whole
+// seconds, as provisioning URIs carry them; sub-second periods get the
+// default.
+func (o Options) withDefaults() Options {
+	o.Period = o.Period.Truncate(time.Second)
+	if o.Period <= 0 {
+		o.Period = DefaultPeriod
+	}
+	if o.Digits < 6 || o.Digits > 8 {
+		o.Digits = DefaultDigits
+	}
+	if o.Skew < 0 {
+		o.Skew = 0
+	}
+	return o
+}
+
+// GenerateSecret returns a random base32 secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("totp: generate secret: %w", err)
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// URI returns the otpauth:// provisioning URI rendered as a QR code for
+// authenticator apps.
+func URI(issuer, account, secret string, opts Options) string {
+	opts = opts.withDefaults()
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(opts.Digits))
+	query.Set("period", strconv.Itoa(int(opts.Period/time.Second)))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Code returns the code of secret at t.
+func Code(secret string, t time.Time, opts Options) (string, error) {
+	opts = opts.withDefaults()
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, step(t, opts.Period), opts.Digits), nil
+}
+
+// Validate checks code against secret at t, accepting opts.Skew steps of
+// clock drift either way. It returns the matched time step.
+func Validate(code, secret string, t time.Time, opts Options) (int64, bool) {
+	opts = opts.withDefaults()
+	code = strings.ReplaceAll(code, " ", "")
+	key, err := decodeSecret(secret)
+	if err != nil || len(code) != opts.Digits {
+		return 0, false
+	}
+	current := step(t, opts.Period)
+	for delta := -opts.Skew; delta <= opts.Skew; delta++ {
+		counter := current + int64(delta)
+		if subtle.ConstantTimeCompare([]byte(hotp(key, counter, opts.Digits)), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateBackupCodes returns n random single-use codes formatted as
+// xxxx-xxxx. Store only their HashBackupCode digests.
+func GenerateBackupCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("totp: generate backup code: %w", err)
+		}
+		s := hex.EncodeToString(b)
+		codes[i] = s[:4] + "-" + s[4:]
+	}
+	return codes, nil
+}
+
+// HashBackupCode returns the digest stored for a backup code. Codes are
+// compared case-insensitively and without separators.
+func HashBackupCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// step returns the time step counter of t.
+func step(t time.Time, period time.Duration) int64 {
+	return t.Unix() / int64(period/time.Second)
+}
+
+// decodeSecret decodes a base32 secret, tolerating lowercase, spaces and
+// padding as typed by users.
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.TrimRight(strings.ToUpper(strings.ReplaceAll(secret, " ", "")), "=")
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("totp: invalid secret")
+	}
+	return key, nil
+}
+
+// hotp computes the HOTP value of counter (RFC 4226).
+func hotp(key []byte, counter int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -0,0 +1,87 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "94287082",
+		1111111109: "07081804",
+		1234567890: "89005924",
+		2000000000: "69279037",
+	}
+	for unix, want := range vectors {
+		code, err := Code(rfcSecret, time.Unix(unix, 0), Options{Digits: 8})
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "t=%d", unix)
+	}
+}
+
+func TestValidate_DriftWindow(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+	previous, err := Code(secret, now.Add(-DefaultPeriod), Options{})
+	require.NoError(t, err)
+
+	_, ok := Validate(previous, secret, now, Options{})
+	assert.False(t, ok, "no drift accepted by default")
+
+	counter, ok := Validate(previous, secret, now, Options{Skew: 1})
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30-1, counter)
+
+	_, ok = Validate("12345", secret, now, Options{Skew: 1})
+	assert.False(t, ok)
+	_, ok = Validate("123456", "not base32!", now, Options{})
+	assert.False(t, ok)
+}
+
+func TestOptions_SubSecondPeriodUsesDefault(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+
+	code, err := Code(secret, now, Options{Period: 500 * time.Millisecond})
+	require.NoError(t, err)
+	want, err := Code(secret, now, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, want, code)
+
+	counter, ok := Validate(code, secret, now, Options{Period: time.Nanosecond})
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, counter)
+	assert.Equal(t, 90*time.Second, Options{Period: 90*time.Second + 400*time.Millisecond}.withDefaults().Period)
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Example Co", "jane@example.com", "JBSWY3DPEHPK3PXP", Options{}))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Example Co:jane@example.com", uri.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	assert.Equal(t, "Example Co", uri.Query().Get("issuer"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
+
+func TestBackupCodes(t *testing.T) {
+	codes, err := GenerateBackupCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	assert.Regexp(t, `^[0-9a-f]{4}-[0-9a-f]{4}$`, codes[0])
+	assert.NotEqual(t, codes[0], codes[1])
+
+	assert.Equal(t, HashBackupCode("ab12-cd34"), HashBackupCode("AB12CD34"))
+	assert.NotEqual(t, HashBackupCode("ab12-cd34"), HashBackupCode("ab12-cd35"))
+}
@@ -0,0 +1,175 @@
+// Package tracecontext propagates W3C Trace Context (traceparent and
+// tracestate) through contexts, HTTP requests and CLI invocations, whether
+// or not an OpenTelemetry exporter is configured, so logs can always carry
+// trace IDs. Requests from Google front ends carrying only
+// X-Cloud-Trace-Context continue that trace.
+//
+// Span contexts are stored with the OpenTelemetry trace API, so spans
+// started by any tracer continue the propagated trace.
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header and environment variable names.
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+	HeaderCloudTrace  = "X-Cloud-Trace-Context"
+	EnvTraceparent    = "TRACEPARENT"
+	EnvTracestate     = "TRACESTATE"
+)
+
+var propagator = propagation.TraceContext{}
+
+// Extract returns ctx carrying the trace context read from carrier. When
+// the carrier holds none, a new sampled root trace is started so every
+// operation has a trace ID. A valid span context already in ctx is kept.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	ctx = propagator.Extract(ctx, carrier)
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	return NewRoot(ctx)
+}
+
+// FromHeader extracts the trace context of an incoming HTTP request,
+// falling back to X-Cloud-Trace-Context without a traceparent header.
+func FromHeader(ctx context.Context, h http.Header) context.Context {
+	if h.Get(HeaderTraceparent) == "" && !trace.SpanContextFromContext(ctx).IsValid() {
+		if sc, ok := parseCloudTrace(h.Get(HeaderCloudTrace)); ok {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+		}
+	}
+	return Extract(ctx, propagation.HeaderCarrier(h))
+}
+
+// parseCloudTrace parses an X-Cloud-Trace-Context value,
+// TRACE_ID[/SPAN_ID][;o=OPTIONS] with a hex trace ID and a decimal span ID.
+// A missing span ID is replaced by a random one.
+func parseCloudTrace(value string) (trace.SpanContext, bool) {
+	value, options, _ := strings.Cut(value, ";")
+	traceHex, spanDec, _ := strings.Cut(value, "/")
+
+	var cfg trace.SpanContextConfig
+	if len(traceHex) != 2*len(cfg.TraceID) {
+		return trace.SpanContext{}, false
+	}
+	if _, err := hex.Decode(cfg.TraceID[:], []byte(traceHex)); err != nil {
+		return trace.SpanContext{}, false
+	}
+	if span, err := strconv.ParseUint(spanDec, 10, 64); err == nil && span != 0 {
+		binary.BigEndian.PutUint64(cfg.SpanID[:], span)
+	} else {
+		_, _ = rand.Read(cfg.SpanID[:])
+	}
+	if options == "o=1" {
+		cfg.TraceFlags = trace.FlagsSampled
+	}
+	cfg.Remote = true
+
+	sc := trace.NewSpanContext(cfg)
+	return sc, sc.IsValid()
+}
+
+// FromEnv extracts the trace context passed to a CLI invocation in the
+// TRACEPARENT and TRACESTATE environment variables.
+func FromEnv(ctx context.Context) context.Context {
+	return Extract(ctx, propagation.MapCarrier{
+		HeaderTraceparent: os.Getenv(EnvTraceparent),
+		HeaderTracestate:  os.Getenv(EnvTracestate),
+	})
+}
+
+// NewRoot returns ctx carrying a new sampled trace with random IDs.
+func NewRoot(ctx context.Context) context.Context {
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	_, _ = rand.Read(traceID[:])
+	_, _ = rand.Read(spanID[:])
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(ctx, sc)
+}
+
+// Inject writes the trace context of ctx to outgoing request headers.
+func Inject(ctx context.Context, h http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// TraceID returns the hex trace ID of ctx, or "" without a trace.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// SpanID returns the hex span ID of ctx, or "" without a trace.
+func SpanID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.SpanID().String()
+}
+
+// Traceparent returns the traceparent value for ctx, or "" without a trace.
+func Traceparent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier[HeaderTraceparent]
+}
+
+// Transport wraps base (http.DefaultTransport when nil) to add the request
+// context's trace headers to outgoing requests that do not set their own.
+func Transport(base http.RoundTripper) *RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RoundTripper{Base: base}
+}
+
+// RoundTripper injects trace headers before delegating to Base.
+type RoundTripper struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(HeaderTraceparent) != "" || !trace.SpanContextFromContext(req.Context()).IsValid() {
+		return t.Base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	Inject(req.Context(), req.Header)
+	return t.Base.RoundTrip(req)
+}
+
+// CloseIdleConnections forwards to Base so http.Client.CloseIdleConnections
+// keeps working.
+func (t *RoundTripper) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,122 @@
+package tracecontext
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	parent   = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	parentID = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+func TestFromHeader_ParsesTraceparent(t *testing.T) {
+	h := http.Header{}
+	h.Set(HeaderTraceparent, parent)
+	h.Set(HeaderTracestate, "vendor=value")
+
+	ctx := FromHeader(context.Background(), h)
+
+	assert.Equal(t, parentID, TraceID(ctx))
+	assert.Equal(t, "00f067aa0ba902b7", SpanID(ctx))
+	assert.Equal(t, parent, Traceparent(ctx))
+	assert.Equal(t, "vendor=value", trace.SpanContextFromContext(ctx).TraceState().String())
+}
+
+func TestFromHeader_StartsRootWhenMissingOrInvalid(t *testing.T) {
+	for _, value := range []string{"", "garbage", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+		h := http.Header{}
+		if value != "" {
+			h.Set(HeaderTraceparent, value)
+		}
+
+		ctx := FromHeader(context.Background(), h)
+
+		assert.Len(t, TraceID(ctx), 32, value)
+		assert.NotEqual(t, "00000000000000000000000000000000", TraceID(ctx), value)
+		assert.Len(t, SpanID(ctx), 16, value)
+	}
+}
+
+func TestFromHeader_ContinuesCloudTrace(t *testing.T) {
+	h := http.Header{}
+	h.Set(HeaderCloudTrace, parentID+"/1;o=1")
+
+	ctx := FromHeader(context.Background(), h)
+
+	assert.Equal(t, parentID, TraceID(ctx))
+	assert.Equal(t, "0000000000000001", SpanID(ctx))
+	assert.True(t, trace.SpanContextFromContext(ctx).IsSampled())
+
+	h.Set(HeaderCloudTrace, parentID)
+	ctx = FromHeader(context.Background(), h)
+	assert.Equal(t, parentID, TraceID(ctx), "the span ID is optional")
+	assert.False(t, trace.SpanContextFromContext(ctx).IsSampled())
+
+	h.Set(HeaderTraceparent, "00-0af7651916cd43dd8448eb211c80319c-00f067aa0ba902b7-01")
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", TraceID(FromHeader(context.Background(), h)), "traceparent takes precedence")
+
+	h = http.Header{}
+	h.Set(HeaderCloudTrace, "not-a-trace/1")
+	assert.NotEmpty(t, TraceID(FromHeader(context.Background(), h)), "invalid values start a root trace")
+}
+
+func TestExtract_KeepsExistingSpanContext(t *testing.T) {
+	ctx := NewRoot(context.Background())
+	h := http.Header{}
+	h.Set(HeaderTraceparent, parent)
+
+	assert.Equal(t, TraceID(ctx), TraceID(FromHeader(ctx, h)))
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvTraceparent, parent)
+	t.Setenv(EnvTracestate, "")
+
+	assert.Equal(t, parentID, TraceID(FromEnv(context.Background())))
+}
+
+func TestTransport_InjectsHeaders(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(HeaderTraceparent))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	h := http.Header{}
+	h.Set(HeaderTraceparent, parent)
+	ctx := FromHeader(context.Background(), h)
+
+	// Propagated from the context
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, req.Header.Get(HeaderTraceparent), "caller request must not be modified")
+
+	// Explicit headers win
+	explicit := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(HeaderTraceparent, explicit)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// No trace in context
+	req, err = http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{parent, explicit, ""}, got)
+}
@@ -0,0 +1,176 @@
+// Package tuning sizes the Go runtime to its container: GOMAXPROCS from the
+// cgroup CPU quota and GOMEMLIMIT from the cgroup memory limit, unless set
+// explicitly.
+package tuning
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/KimMachineGun/automemlimit/memlimit"
+	"go.uber.org/automaxprocs/maxprocs"
+)
+
+// DefaultMemoryLimitRatio is the share of the cgroup memory limit used as
+// GOMEMLIMIT, leaving headroom for non-heap memory.
+const DefaultMemoryLimitRatio = 0.9
+
+// Sources of the effective values.
+const (
+	SourceConfig  = "config"  // Options override
+	SourceEnv     = "env"     // GOMAXPROCS/GOMEMLIMIT/GOGC environment variable
+	SourceCgroup  = "cgroup"  // Derived from the container limits
+	SourceDefault = "default" // Runtime default (no limit found)
+)
+
+// Options overrides the detected settings. Zero values auto-detect.
+type Options struct {
+	MaxProcs         int     // GOMAXPROCS override
+	MemoryLimit      int64   // GOMEMLIMIT override in bytes
+	MemoryLimitRatio float64 // Share of the cgroup memory limit (default 0.9)
+	GCPercent        int     // GOGC override
+}
+
+// Result reports the effective runtime settings and where they came from.
+type Result struct {
+	MaxProcs          int
+	MaxProcsSource    string
+	MemoryLimit       int64 // math.MaxInt64 when unlimited
+	MemoryLimitSource string
+	GCPercent         int // -1 when the collector is off
+	GCPercentSource   string
+}
+
+// Apply configures the runtime and returns the effective settings.
+func Apply(opts Options) (Result, error) {
+	var res Result
+	var err error
+
+	if res.MaxProcsSource, err = applyMaxProcs(opts.MaxProcs); err != nil {
+		return res, fmt.Errorf("set GOMAXPROCS: %w", err)
+	}
+	res.MaxProcs = runtime.GOMAXPROCS(0)
+
+	if res.MemoryLimitSource, err = applyMemoryLimit(opts.MemoryLimit, opts.MemoryLimitRatio); err != nil {
+		return res, fmt.Errorf("set GOMEMLIMIT: %w", err)
+	}
+	res.MemoryLimit = debug.SetMemoryLimit(-1)
+
+	res.GCPercent, res.GCPercentSource = applyGCPercent(opts.GCPercent)
+
+	return res, nil
+}
+
+func applyMaxProcs(override int) (string, error) {
+	switch {
+	case override > 0:
+		runtime.GOMAXPROCS(override)
+		return SourceConfig, nil
+	case os.Getenv("GOMAXPROCS") != "":
+		return SourceEnv, nil
+	}
+
+	before := runtime.GOMAXPROCS(0)
+	if _, err := maxprocs.Set(maxprocs.Logger(func(string, ...any) {})); err != nil {
+		return "", err
+	}
+	if runtime.GOMAXPROCS(0) != before {
+		return SourceCgroup, nil
+	}
+	return SourceDefault, nil
+}
+
+func applyMemoryLimit(override int64, ratio float64) (string, error) {
+	switch {
+	case override > 0:
+		debug.SetMemoryLimit(override)
+		return SourceConfig, nil
+	case os.Getenv("GOMEMLIMIT") != "":
+		return SourceEnv, nil
+	}
+
+	if ratio <= 0 || ratio > 1 {
+		ratio = DefaultMemoryLimitRatio
+	}
+	limit, err := memlimit.SetGoMemLimitWithOpts(
+		memlimit.WithRatio(ratio),
+		memlimit.WithProvider(memlimit.FromCgroup),
+	)
+	switch {
+	case errors.Is(err, memlimit.ErrNoLimit), errors.Is(err, memlimit.ErrCgroupsNotSupported), errors.Is(err, memlimit.ErrNoCgroup):
+		return SourceDefault, nil
+	case err != nil:
+		return "", err
+	case limit == 0:
+		return SourceDefault, nil
+	}
+	return SourceCgroup, nil
+}
+
+func applyGCPercent(override int) (int, string) {
+	if override != 0 {
+		debug.SetGCPercent(override)
+		return override, SourceConfig
+	}
+
+	// SetGCPercent reports the previous value; restore it straight away
+	current := debug.SetGCPercent(100)
+	debug.SetGCPercent(current)
+	if os.Getenv("GOGC") != "" {
+		return current, SourceEnv
+	}
+	return current, SourceDefault
+}
+
+// byteUnits are the GOMEMLIMIT size suffixes.
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseBytes parses a size in GOMEMLIMIT syntax ("512MiB", "2GiB", "1048576").
+func ParseBytes(s string) (int64, error) {
+	unit := int64(1)
+	number := s
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			unit, number = u.size, strings.TrimSuffix(s, u.suffix)
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	if n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("byte size %q overflows", s)
+	}
+	return n * unit, nil
+}
+
+// FormatBytes formats a size in the largest whole GOMEMLIMIT unit, and
+// "unlimited" for math.MaxInt64.
+func FormatBytes(n int64) string {
+	if n == math.MaxInt64 {
+		return "unlimited"
+	}
+	for _, u := range byteUnits {
+		if n >= u.size && n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
@@ -0,0 +1,82 @@
+package tuning
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply_Overrides(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+	limit := debug.SetMemoryLimit(-1)
+	gc := debug.SetGCPercent(100)
+	t.Cleanup(func() {
+		runtime.GOMAXPROCS(procs)
+		debug.SetMemoryLimit(limit)
+		debug.SetGCPercent(gc)
+	})
+	t.Setenv("GOMAXPROCS", "")
+	t.Setenv("GOMEMLIMIT", "")
+
+	res, err := Apply(Options{MaxProcs: 3, MemoryLimit: 256 << 20, GCPercent: 50})
+	require.NoError(t, err)
+
+	assert.Equal(t, Result{
+		MaxProcs:          3,
+		MaxProcsSource:    SourceConfig,
+		MemoryLimit:       256 << 20,
+		MemoryLimitSource: SourceConfig,
+		GCPercent:         50,
+		GCPercentSource:   SourceConfig,
+	}, res)
+	assert.Equal(t, 3, runtime.GOMAXPROCS(0))
+	assert.Equal(t, int64(256<<20), debug.SetMemoryLimit(-1))
+}
+
+func TestApply_EnvWins(t *testing.T) {
+	limit := debug.SetMemoryLimit(-1)
+	t.Cleanup(func() { debug.SetMemoryLimit(limit) })
+	t.Setenv("GOMAXPROCS", "2")
+	t.Setenv("GOMEMLIMIT", "1GiB")
+
+	res, err := Apply(Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, SourceEnv, res.MaxProcsSource)
+	assert.Equal(t, SourceEnv, res.MemoryLimitSource)
+	assert.Equal(t, limit, res.MemoryLimit)
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := map[string]int64{
+		"0":       0,
+		"1048576": 1 << 20,
+		"512B":    512,
+		"64KiB":   64 << 10,
+		"512MiB":  512 << 20,
+		"2GiB":    2 << 30,
+		"1TiB":    1 << 40,
+	}
+	for in, want := range tests {
+		got, err := ParseBytes(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "MiB", "1.5GiB", "-1", "512MB", "99999999999TiB"} {
+		_, err := ParseBytes(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512MiB", FormatBytes(512<<20))
+	assert.Equal(t, "1536KiB", FormatBytes(1536<<10))
+	assert.Equal(t, "1000B", FormatBytes(1000))
+	assert.Equal(t, "0B", FormatBytes(0))
+	assert.Equal(t, "unlimited", FormatBytes(math.MaxInt64))
+}
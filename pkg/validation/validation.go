@@ -0,0 +1,169 @@
+// Package validation holds the custom validator tags shared by
+// configuration loading and request binding, so both accept the same
+// phone numbers, addresses, URLs, CIDR lists, durations, currencies and
+// amounts:
+//
+//	v := validator.New()
+//	_ = validation.Apply(v)
+//
+// Components may add tags with Register before validators are created.
+package validation
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/luminosita/change-me/pkg/address"
+	"github.com/luminosita/change-me/pkg/httpclient"
+	"github.com/luminosita/change-me/pkg/money"
+	"github.com/luminosita/change-me/pkg/phone"
+)
+
+var (
+	mu    sync.RWMutex
+	rules = map[string]validator.Func{
+		"phone":      phoneNumber,
+		"country":    country,
+		"address":    postalAddress,
+		"public_url": publicURL,
+		"cidr_list":  cidrList,
+		"duration":   duration,
+
+		"currency":          currency,
+		"money_positive":    moneySign(func(m money.Money) bool { return m.IsPositive() }),
+		"money_nonnegative": moneySign(func(m money.Money) bool { return !m.IsNegative() }),
+	}
+)
+
+// publicPolicy refuses URLs that cannot reach a public host.
+var publicPolicy = httpclient.EgressPolicy{
+	AllowSchemes:   []string{"http", "https"},
+	BlockLinkLocal: true,
+	BlockPrivate:   true,
+}
+
+// Register adds a custom tag, replacing any tag with the same name. It
+// applies to validators created by later Apply calls.
+func Register(tag string, fn validator.Func) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules[tag] = fn
+}
+
+// Apply registers every custom tag on v.
+func Apply(v *validator.Validate) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tags returns the registered custom tags in order.
+func Tags() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	tags := make([]string, 0, len(rules))
+	for tag := range rules {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// phoneNumber accepts numbers phone.Normalize reads: international ones
+// with spaces, dashes, dots and parentheses between digit groups
+// ("+1 (555) 010-0000"), and national ones of the region given as
+// parameter ("phone=GB").
+func phoneNumber(fl validator.FieldLevel) bool {
+	_, err := phone.Normalize(fl.Field().String(), fl.Param())
+	return err == nil
+}
+
+// country accepts ISO 3166-1 alpha-2 codes and the names and alpha-3 codes
+// address.CountryCode resolves.
+func country(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+	_, ok := address.CountryCode(fl.Field().String())
+	return ok
+}
+
+// postalAddress accepts address.Address values address.Normalize accepts.
+func postalAddress(fl validator.FieldLevel) bool {
+	a, ok := fl.Field().Interface().(address.Address)
+	if !ok {
+		return false
+	}
+	_, err := address.Normalize(a)
+	return err == nil
+}
+
+// publicURL accepts absolute http(s) URLs whose host is not localhost or a
+// loopback, private or link-local address.
+func publicURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	return publicPolicy.CheckURL(u) == nil
+}
+
+// cidrList accepts comma-separated CIDR notations ("10.0.0.0/8, ::1/128").
+func cidrList(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if value == "" {
+		return false
+	}
+	for _, part := range strings.Split(value, ",") {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(part)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// duration accepts Go duration strings ("1h30m"); time.Duration fields
+// are always valid.
+func duration(fl validator.FieldLevel) bool {
+	if fl.Field().Type() == reflect.TypeOf(time.Duration(0)) {
+		return true
+	}
+	_, err := time.ParseDuration(fl.Field().String())
+	return err == nil
+}
+
+// currency accepts supported ISO 4217 codes ("USD") and money.Money
+// values with a currency.
+func currency(fl validator.FieldLevel) bool {
+	if m, ok := fl.Field().Interface().(money.Money); ok {
+		return m.Currency().Valid()
+	}
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+	_, err := money.ParseCurrency(fl.Field().String())
+	return err == nil
+}
+
+// moneySign accepts money.Money values with a currency passing ok.
+func moneySign(ok func(money.Money) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		m, isMoney := fl.Field().Interface().(money.Money)
+		return isMoney && m.Currency().Valid() && ok(m)
+	}
+}
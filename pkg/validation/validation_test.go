@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/luminosita/change-me/pkg/address"
+	"github.com/luminosita/change-me/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply_SharedTags(t *testing.T) {
+	v := validator.New()
+	require.NoError(t, Apply(v))
+
+	tests := []struct {
+		tag   string
+		value string
+		valid bool
+	}{
+		{"phone", "+14155550100", true},
+		{"phone", "+1 (415) 555-0100", true},
+		{"phone", "+44 20 7946 0958", true},
+		{"phone", "4155550100", false},
+		{"phone", "+0123", false},
+		{"phone", "+1234567890123456", false},
+		{"phone", "020 7946 0958", false},
+		{"phone=GB", "020 7946 0958", true},
+		{"phone=GB", "+1 415 555 0100", true},
+		{"country", "RS", true},
+		{"country", "Serbia", true},
+		{"country", "XX", false},
+		{"public_url", "https://hooks.example.com/events", true},
+		{"public_url", "http://93.184.216.34/", true},
+		{"public_url", "ftp://files.example.com", false},
+		{"public_url", "http://localhost:8080", false},
+		{"public_url", "http://127.0.0.1", false},
+		{"public_url", "http://10.0.0.1/hook", false},
+		{"public_url", "http://169.254.169.254/latest", false},
+		{"public_url", "/relative", false},
+		{"cidr_list", "10.0.0.0/8", true},
+		{"cidr_list", "10.0.0.0/8, 192.168.0.0/16,::1/128", true},
+		{"cidr_list", "10.0.0.0/8,", false},
+		{"cidr_list", "10.0.0.1", false},
+		{"duration", "1h30m", true},
+		{"duration", "250ms", true},
+		{"duration", "5", false},
+		{"duration", "soon", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag+" "+tt.value, func(t *testing.T) {
+			err := v.Var(tt.value, tt.tag)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, v.Var(time.Minute, "duration"), "duration fields are always valid")
+}
+
+func TestApply_MoneyTags(t *testing.T) {
+	v := validator.New()
+	require.NoError(t, Apply(v))
+
+	type payment struct {
+		Amount   money.Money  `validate:"money_positive"`
+		Fee      money.Money  `validate:"money_nonnegative"`
+		Refund   *money.Money `validate:"omitempty,currency"`
+		Currency string       `validate:"currency"`
+	}
+	refund := money.MustParse("1", money.EUR)
+	valid := payment{
+		Amount:   money.MustParse("19.99", money.USD),
+		Fee:      money.MustParse("0", money.USD),
+		Refund:   &refund,
+		Currency: "usd",
+	}
+	assert.NoError(t, v.Struct(valid))
+
+	for name, mutate := range map[string]func(p *payment){
+		"zero amount":      func(p *payment) { p.Amount = money.MustParse("0", money.USD) },
+		"missing amount":   func(p *payment) { p.Amount = money.Money{} },
+		"negative fee":     func(p *payment) { p.Fee = money.MustParse("-0.01", money.USD) },
+		"refund currency":  func(p *payment) { p.Refund = &money.Money{} },
+		"unknown currency": func(p *payment) { p.Currency = "XYZ" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := valid
+			mutate(&p)
+			assert.Error(t, v.Struct(p))
+		})
+	}
+}
+
+func TestApply_AddressTag(t *testing.T) {
+	v := validator.New()
+	require.NoError(t, Apply(v))
+
+	type shipment struct {
+		To address.Address `validate:"address"`
+	}
+
+	assert.NoError(t, v.Struct(shipment{To: address.Address{
+		Line1: "Knez Mihailova 1", City: "Beograd", PostalCode: "11000", Country: "Serbia",
+	}}))
+	assert.Error(t, v.Struct(shipment{To: address.Address{
+		Line1: "Knez Mihailova 1", City: "Beograd", PostalCode: "110", Country: "RS",
+	}}))
+	assert.Error(t, v.Struct(shipment{}))
+}
+
+func TestRegister_AddsTagsToLaterValidators(t *testing.T) {
+	Register("even_length", func(fl validator.FieldLevel) bool { return len(fl.Field().String())%2 == 0 })
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(rules, "even_length")
+		mu.Unlock()
+	})
+
+	v := validator.New()
+	require.NoError(t, Apply(v))
+	assert.Contains(t, Tags(), "even_length")
+	assert.NoError(t, v.Var("ab", "even_length"))
+	assert.Error(t, v.Var("abc", "even_length"))
+}
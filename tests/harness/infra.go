@@ -0,0 +1,110 @@
+// Package harness provides integration test helpers that run the application
+// against real infrastructure started with testcontainers-go: Redis, which
+// backs the cache, sessions, rate limits, quotas and the notification queue,
+// and Kafka, which receives usage events. The application has no
+// Postgres-backed components, so no database is started; users and the
+// other stores run in memory or on the embedded store.
+//
+// Typical usage:
+//
+//	infra := harness.StartInfra(t, harness.WithRedis())
+//	ts := harness.NewTestServer(t, infra)
+//	testkit.New(t, ts.URL).Get("/health").ExpectStatus(http.StatusOK)
+//
+// Containers are terminated automatically when the test completes. Tests are
+// skipped when no container runtime (Docker or Podman) is reachable.
+package harness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/kafka"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// Default container images used by the harness.
+const (
+	RedisImage = "redis:7-alpine"
+	KafkaImage = "confluentinc/confluent-local:7.5.0"
+)
+
+// startupTimeout bounds how long the harness waits for all containers.
+const startupTimeout = 2 * time.Minute
+
+// Infra holds connection details for the containers started for a test.
+type Infra struct {
+	RedisURL     string
+	KafkaBrokers []string
+}
+
+// options selects the infrastructure started by StartInfra.
+type options struct {
+	redis bool
+	kafka bool
+}
+
+// Option configures StartInfra.
+type Option func(*options)
+
+// WithRedis starts a Redis container.
+func WithRedis() Option {
+	return func(o *options) {
+		o.redis = true
+	}
+}
+
+// WithKafka starts a single-node Kafka (KRaft) container.
+func WithKafka() Option {
+	return func(o *options) {
+		o.kafka = true
+	}
+}
+
+// StartInfra starts the requested containers and registers their cleanup on t.
+// It skips the test when no container runtime is available.
+//
+// Parameters:
+//   - t: Test using the infrastructure
+//   - opts: Containers to start
+//
+// Returns:
+//   - *Infra: Connection details for the started containers
+func StartInfra(t *testing.T, opts ...Option) *Infra {
+	t.Helper()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), startupTimeout)
+	defer cancel()
+
+	infra := &Infra{}
+
+	if o.redis {
+		ctr, err := redis.Run(ctx, RedisImage)
+		testcontainers.CleanupContainer(t, ctr)
+		require.NoError(t, err, "failed to start redis container")
+
+		infra.RedisURL, err = ctr.ConnectionString(ctx)
+		require.NoError(t, err)
+	}
+
+	if o.kafka {
+		ctr, err := kafka.Run(ctx, KafkaImage, kafka.WithClusterID("app-test"))
+		testcontainers.CleanupContainer(t, ctr)
+		require.NoError(t, err, "failed to start kafka container")
+
+		infra.KafkaBrokers, err = ctr.Brokers(ctx)
+		require.NoError(t, err)
+	}
+
+	return infra
+}
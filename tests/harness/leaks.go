@@ -0,0 +1,95 @@
+package harness
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// fdReleaseTimeout bounds how long VerifyNoLeaks waits for file
+// descriptors to be closed after the test.
+const fdReleaseTimeout = 5 * time.Second
+
+// VerifyNoLeaks fails t when goroutines or file descriptors created during
+// the test outlive it. Call it first: the check is registered as the first
+// cleanup, so it runs after every other cleanup, such as the shutdown of a
+// test server, released its resources. Goroutines running before the call
+// are ignored, as are goroutines matched by opts. Idle client connections
+// of http.DefaultTransport are closed before the check, since they outlive
+// requests by design. Tests using it must not run in parallel.
+//
+// Parameters:
+//   - t: Test to check
+//   - opts: Additional goroutines to ignore
+func VerifyNoLeaks(t *testing.T, opts ...goleak.Option) {
+	t.Helper()
+
+	opts = append([]goleak.Option{goleak.IgnoreCurrent()}, opts...)
+	before, countable := openFDs()
+
+	t.Cleanup(func() {
+		http.DefaultClient.CloseIdleConnections()
+
+		if err := goleak.Find(opts...); err != nil {
+			t.Errorf("goroutines leaked by the test: %v", err)
+		}
+		if !countable {
+			return
+		}
+
+		// Closed sockets may be released by the runtime shortly after
+		var leaked []string
+		deadline := time.Now().Add(fdReleaseTimeout)
+		for {
+			leaked = newFDs(before)
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if len(leaked) > 0 {
+			t.Errorf("file descriptors leaked by the test: %s", strings.Join(leaked, ", "))
+		}
+	})
+}
+
+// openFDs returns the open file descriptors of the process with their
+// targets, e.g. "socket:[1234]". It reports false where /proc is not
+// available.
+func openFDs() (map[string]string, bool) {
+	dir := "/proc/self/fd"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, false
+	}
+	fds := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		// The descriptor reading the directory is gone by now
+		target, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		fds[entry.Name()] = target
+	}
+	return fds, true
+}
+
+// newFDs returns the descriptors open now that were not open in before,
+// formatted as "fd -> target".
+func newFDs(before map[string]string) []string {
+	now, _ := openFDs()
+	var leaked []string
+	for fd, target := range now {
+		if before[fd] != target {
+			leaked = append(leaked, fd+" -> "+target)
+		}
+	}
+	sort.Strings(leaked)
+	return leaked
+}
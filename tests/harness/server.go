@@ -0,0 +1,194 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readyTimeout bounds how long StartServer waits for the server to report
+// ready.
+const readyTimeout = 10 * time.Second
+
+// TestServer is a fully wired application served over a real listener.
+type TestServer struct {
+	*httptest.Server
+
+	Container *dependencies.Container
+	App       *httpserver.Server
+}
+
+// NewTestServer builds the dependency container against infra and serves the
+// application router on a random local port. Pass nil infra to run without
+// external services. Config options are the mocks.With* helpers.
+//
+// Parameters:
+//   - t: Test owning the server
+//   - infra: Infrastructure from StartInfra (optional)
+//   - opts: Config overrides applied after infra wiring
+//
+// Returns:
+//   - *TestServer: Running server, closed automatically on test cleanup
+func NewTestServer(t *testing.T, infra *Infra, opts ...func(*config.Config)) *TestServer {
+	t.Helper()
+
+	container := newContainer(t, infra, opts...)
+	app := httpserver.New(container)
+	srv := httptest.NewServer(app.Router())
+
+	// Start plugins and warm up as Server.Run does so the instance reports ready
+	require.NoError(t, app.Plugins().Start(context.Background()))
+	container.Warmup.Run(context.Background(), container.Logger, container.Config.WarmupTimeout)
+
+	t.Cleanup(func() {
+		srv.Close()
+		assert.NoError(t, app.Plugins().Stop(context.Background()), "stop plugins")
+		assert.NoError(t, container.Close(), "close dependencies")
+	})
+
+	return &TestServer{
+		Server:    srv,
+		Container: container,
+		App:       app,
+	}
+}
+
+// RunningServer is the application served by Server.Run, the production
+// entry point, including its listener, connection tracking and graceful
+// shutdown.
+type RunningServer struct {
+	URL       string
+	Container *dependencies.Container
+	App       *httpserver.Server
+
+	cancel   context.CancelFunc
+	done     chan error
+	once     sync.Once
+	shutdown error
+}
+
+// StartServer builds the dependency container like NewTestServer and runs
+// Server.Run on a free local port. It returns once the server reports
+// ready. The server is shut down on test cleanup unless Shutdown was
+// called.
+//
+// Parameters:
+//   - t: Test owning the server
+//   - infra: Infrastructure from StartInfra (optional)
+//   - opts: Config overrides applied after infra wiring
+//
+// Returns:
+//   - *RunningServer: Ready server
+func StartServer(t *testing.T, infra *Infra, opts ...func(*config.Config)) *RunningServer {
+	t.Helper()
+
+	port := freePort(t)
+	container := newContainer(t, infra, append(opts, func(cfg *config.Config) {
+		cfg.Host = "127.0.0.1"
+		cfg.Port = port
+	})...)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &RunningServer{
+		URL:       fmt.Sprintf("http://127.0.0.1:%d", port),
+		Container: container,
+		App:       httpserver.New(container),
+		cancel:    cancel,
+		done:      make(chan error, 1),
+	}
+	go func() { s.done <- s.App.Run(ctx) }()
+	t.Cleanup(func() {
+		assert.NoError(t, s.Shutdown(), "shut down server")
+	})
+
+	require.NoError(t, s.waitReady(), "server did not become ready")
+	return s
+}
+
+// Shutdown stops the server as a termination signal does, waits for
+// Server.Run to return and closes the dependencies. It returns the errors
+// of both; later calls return the same errors.
+//
+// Returns:
+//   - error: Server.Run and Container.Close errors
+func (s *RunningServer) Shutdown() error {
+	s.once.Do(func() {
+		s.cancel()
+		s.shutdown = errors.Join(<-s.done, s.Container.Close())
+	})
+	return s.shutdown
+}
+
+// waitReady polls the readiness endpoint until it answers 200, Run fails
+// or readyTimeout elapses.
+func (s *RunningServer) waitReady() error {
+	client := &http.Client{Transport: &http.Transport{}, Timeout: time.Second}
+	defer client.CloseIdleConnections()
+
+	deadline := time.Now().Add(readyTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-s.done:
+			// Keep the result for Shutdown
+			s.done <- err
+			return fmt.Errorf("server stopped: %w", err)
+		default:
+		}
+		resp, err := client.Get(s.URL + "/health/ready")
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return fmt.Errorf("not ready after %s", readyTimeout)
+}
+
+// newContainer builds the dependency container for a test server.
+func newContainer(t *testing.T, infra *Infra, opts ...func(*config.Config)) *dependencies.Container {
+	t.Helper()
+
+	cfg := mocks.NewTestConfig()
+	cfg.LogLevel = "ERROR"
+	if infra != nil {
+		cfg.RedisURL = infra.RedisURL
+		cfg.KafkaBrokers = infra.KafkaBrokers
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	require.NoError(t, config.Validate(cfg), "invalid test configuration")
+
+	log, err := logger.New(logger.Config{
+		Level:  cfg.LogLevel,
+		Format: cfg.LogFormat,
+	})
+	require.NoError(t, err)
+
+	return dependencies.NewContainer(cfg, log)
+}
+
+// freePort returns a local TCP port that is free at the time of the call.
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
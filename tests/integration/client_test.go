@@ -0,0 +1,54 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/pkg/client"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ================
+// API Client Tests
+// ================
+
+// TestClient_MatchesServer exercises the generated SDK against the server,
+// catching drift between the OpenAPI document and the handlers.
+func TestClient_MatchesServer(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+	c := client.New(ts.URL)
+	ctx := context.Background()
+
+	// Act
+	created, err := c.CreateUser(ctx, client.CreateUserRequest{Email: "jane@example.com", Username: "jane"})
+	require.NoError(t, err)
+	got, err := c.GetUser(ctx, created.ID)
+	require.NoError(t, err)
+	limit := 1
+	page, err := c.ListUsers(ctx, &client.ListUsersParams{Limit: &limit})
+	require.NoError(t, err)
+	health, err := c.HealthCheck(ctx)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "jane", got.Username)
+	assert.True(t, got.IsActive)
+	assert.False(t, got.CreatedAt.IsZero())
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, 1, page.Limit)
+	assert.NotEmpty(t, health.Status)
+
+	require.NoError(t, c.DeleteUser(ctx, created.ID))
+	_, err = c.GetUser(ctx, created.ID)
+	var apiErr *client.Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "user_not_found", apiErr.Code)
+}
@@ -0,0 +1,63 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Configuration Dump Tests
+// ====================
+
+func TestConfigDump_AdminEndpointRedactsSecrets(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.RedisURL = "redis://:hunter2@localhost:6379/0"
+		cfg.Sources = map[string]config.Origin{"ADMIN_TOKEN": {Source: config.SourceEnv}}
+	})
+
+	// Act
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/admin/config", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var dump handlers.ConfigResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&dump))
+
+	// Assert
+	admin := dump.Settings["ADMIN_TOKEN"]
+	assert.Equal(t, "[redacted]", admin.Value)
+	assert.True(t, admin.Redacted)
+	assert.Equal(t, config.SourceEnv, admin.Source)
+	assert.Equal(t, "redis://:xxxxx@localhost:6379/0", dump.Settings["REDIS_URL"].Value)
+	assert.Equal(t, config.SourceDefault, dump.Settings["APP_NAME"].Source)
+	assert.NotContains(t, dump.Settings, "SOURCES")
+}
+
+func TestConfigDump_RequiresAdminToken(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+	})
+
+	// Act
+	resp, err := http.Get(ts.URL + "/admin/config")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,110 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/pkg/apikey"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============
+// Consent Tests
+// =============
+
+// consentRequest sends a JSON request with the given headers and decodes
+// the response into out.
+func consentRequest(t *testing.T, ts *harness.TestServer, method, path string, headers map[string]string, body any, out any) int {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+	req, err := http.NewRequest(method, ts.URL+path, &payload)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestConsent_RequiredRoutesNeedLatestVersion(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.PrincipalHeader = "X-API-Key"
+		cfg.APIKeys = []string{"jane=" + apikey.Digest("jane-key")}
+		cfg.ConsentRoutes = []string{"GET /api/v1/users"}
+		cfg.ConsentPolicies = []string{"terms"}
+	})
+	admin := map[string]string{"Authorization": "Bearer secret"}
+	jane := map[string]string{"X-API-Key": "jane-key"}
+
+	// Unpublished policies are not required
+	assert.Equal(t, http.StatusOK, consentRequest(t, ts, http.MethodGet, "/api/v1/users?limit=20", jane, nil, nil))
+
+	var published handlers.PolicyResponse
+	status := consentRequest(t, ts, http.MethodPost, "/admin/consent/policies", admin,
+		handlers.PublishPolicyRequest{Name: "terms", Version: "v1", Title: "Terms of Service", Body: "Be nice."}, &published)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "v1", published.Version)
+
+	// Act & Assert - consent is required once published
+	var rejected struct {
+		Code     string                    `json:"code"`
+		Policies []handlers.PolicyResponse `json:"policies"`
+	}
+	assert.Equal(t, http.StatusForbidden, consentRequest(t, ts, http.MethodGet, "/api/v1/users?limit=20", jane, nil, &rejected))
+	assert.Equal(t, "consent_required", rejected.Code)
+	require.Len(t, rejected.Policies, 1)
+	assert.Equal(t, "v1", rejected.Policies[0].Version)
+	assert.Equal(t, http.StatusUnauthorized, consentRequest(t, ts, http.MethodGet, "/api/v1/users?limit=20", nil, nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, consentRequest(t, ts, http.MethodGet, "/api/v1/users?limit=20", map[string]string{"X-API-Key": "jane"}, nil, nil),
+		"unknown keys are anonymous")
+
+	var policy handlers.PolicyResponse
+	require.Equal(t, http.StatusOK, consentRequest(t, ts, http.MethodGet, "/api/v1/consent/policies/terms", nil, nil, &policy))
+	assert.Equal(t, "Be nice.", policy.Body)
+
+	var acceptance handlers.AcceptanceResponse
+	status = consentRequest(t, ts, http.MethodPost, "/api/v1/consent/policies/terms/acceptances", jane,
+		handlers.AcceptPolicyRequest{Version: "v1"}, &acceptance)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "jane", acceptance.Principal)
+	assert.Equal(t, http.StatusOK, consentRequest(t, ts, http.MethodGet, "/api/v1/users?limit=20", jane, nil, nil))
+
+	// A new version supersedes the acceptance
+	status = consentRequest(t, ts, http.MethodPost, "/admin/consent/policies", admin,
+		handlers.PublishPolicyRequest{Name: "terms", Version: "v2"}, nil)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, http.StatusForbidden, consentRequest(t, ts, http.MethodGet, "/api/v1/users?limit=20", jane, nil, nil))
+
+	var current []handlers.ConsentStatusResponse
+	require.Equal(t, http.StatusOK, consentRequest(t, ts, http.MethodGet, "/api/v1/consent", jane, nil, &current))
+	require.Len(t, current, 1)
+	assert.Equal(t, "v1", current[0].AcceptedVersion)
+	assert.False(t, current[0].Current)
+
+	status = consentRequest(t, ts, http.MethodPost, "/api/v1/consent/policies/terms/acceptances", jane,
+		handlers.AcceptPolicyRequest{Version: "v1"}, nil)
+	assert.Equal(t, http.StatusConflict, status, "only the latest version can be accepted")
+
+	var history []handlers.AcceptanceResponse
+	require.Equal(t, http.StatusOK, consentRequest(t, ts, http.MethodGet, "/admin/consent/acceptances?principal=jane", admin, nil, &history))
+	assert.Len(t, history, 1)
+}
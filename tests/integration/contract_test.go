@@ -0,0 +1,133 @@
+//go:build integration
+
+package integration
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/luminosita/change-me/api"
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// OpenAPI Contract Tests
+// ====================
+
+// undocumentedRoutes are mounted outside the published contract.
+var undocumentedRoutes = map[string]bool{
+	"GET /metrics":        true, // Prometheus exposition format
+	"GET /health/details": true, // Operator diagnostics behind endpoint auth
+}
+
+// routeParam matches gin path parameters (":id", "*key").
+var routeParam = regexp.MustCompile(`[:*]([A-Za-z_]+)`)
+
+func TestContract_RouterMatchesSpec(t *testing.T) {
+	// Arrange - every optional module enabled
+	ts := newContractServer(t, nil)
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPISpec)
+	require.NoError(t, err)
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	// Act
+	mounted := map[string]bool{}
+	for _, route := range ts.App.Router().Routes() {
+		mounted[route.Method+" "+routeParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+
+	// Assert - both directions, so neither side drifts
+	var missing, stale []string
+	for route := range mounted {
+		if !documented[route] && !undocumentedRoutes[route] {
+			missing = append(missing, route)
+		}
+	}
+	for route := range documented {
+		if !mounted[route] {
+			stale = append(stale, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	assert.Empty(t, missing, "mounted routes missing from api/openapi.yaml")
+	assert.Empty(t, stale, "api/openapi.yaml operations without a route")
+}
+
+func TestContract_ResponsesMatchSpec(t *testing.T) {
+	// Arrange - responses that break the contract are replaced with 500
+	ts := newContractServer(t, func(cfg *config.Config) {
+		cfg.OpenAPIValidation = constants.OpenAPIValidationReject
+		cfg.OpenAPIValidateResponses = true
+	})
+	paths := []string{
+		"/api/v1/consent/policies",
+		"/api/v1/reports",
+		"/api/v1/users/search?q=jane",
+		"/admin/config",
+		"/admin/consent/acceptances?principal=jane",
+		"/admin/middleware",
+		"/admin/profiles",
+		"/admin/quotas/key-1",
+		"/admin/toggles",
+		"/admin/toggles/changes",
+		"/admin/usage/key-1",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			// Act
+			req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer secret")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		})
+	}
+}
+
+// newContractServer starts a test server with every optional module
+// enabled, so all routes are mounted.
+func newContractServer(t *testing.T, override func(*config.Config)) *harness.TestServer {
+	t.Helper()
+	return harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.MeteringEnabled = true
+		cfg.SearchProvider = "embedded"
+		cfg.EmbeddedStorePath = filepath.Join(t.TempDir(), "store.db")
+		cfg.ObjectStorageProvider = "local"
+		cfg.ObjectStorageDir = t.TempDir()
+		cfg.ObjectStoragePublicURL = "/api/v1/files"
+		cfg.ObjectStorageSigningKey = "test-signing-key"
+		cfg.EncryptionKeys = []string{"k1=" + strings.Repeat("A", 43) + "="}
+		cfg.EncryptionPrimaryKeyID = "k1"
+		cfg.SlackWebhooks = []string{"alerts=https://hooks.example.com/alerts"}
+		cfg.QuotaEnabled = true
+		cfg.ProfilingDir = t.TempDir()
+		if override != nil {
+			override(cfg)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/pkg/proxy"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/luminosita/change-me/tests/stubserver"
+	"github.com/luminosita/change-me/tests/testkit"
+	"github.com/stretchr/testify/assert"
+)
+
+// ====================
+// Deadline Propagation Tests
+// ====================
+
+func TestDeadline_UpstreamCallGetsShareOfRequestDeadline(t *testing.T) {
+	// Arrange
+	upstream := stubserver.New(t)
+	upstream.On(stubserver.GET("/slow")).Delay(5 * time.Second)
+	upstream.On(stubserver.GET("/fast")).Body("fast")
+
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.ServerRequestTimeout = 600 * time.Millisecond
+		cfg.HTTPClientDeadlineBudget = 0.5
+		cfg.ProxyRoutes = []proxy.Route{
+			{Name: "billing", Prefix: "/billing/", Upstream: upstream.URL, StripPrefix: true, Timeout: time.Minute},
+		}
+	})
+	client := testkit.New(t, ts.URL)
+
+	// Act
+	start := time.Now()
+	slow := client.Get("/billing/slow").Do()
+	elapsed := time.Since(start)
+	fast := client.Get("/billing/fast").Do()
+
+	// Assert - the upstream was abandoned at its share of the request
+	// deadline, well before the route timeout
+	slow.ExpectStatus(http.StatusGatewayTimeout)
+	assert.Less(t, elapsed, 600*time.Millisecond)
+	assert.GreaterOrEqual(t, elapsed, 250*time.Millisecond)
+	fast.ExpectStatus(http.StatusOK).ExpectBodyContains("fast")
+	upstream.Verify(stubserver.GET("/slow"), 1)
+}
+
+func TestDeadline_RequestsWithinDeadlineSucceed(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.ServerRequestTimeout = 2 * time.Second
+		cfg.StoreDeadlineBudget = 0.5
+	})
+	client := testkit.New(t, ts.URL)
+
+	// Act
+	created := client.Post("/api/v1/users").
+		JSON(map[string]any{"email": "jane@example.com", "username": "jane"}).
+		Do()
+	fetched := client.Get(fmt.Sprint("/api/v1/users/", created.JSONPath("id"))).Do()
+
+	// Assert
+	created.ExpectStatus(http.StatusCreated)
+	fetched.ExpectStatus(http.StatusOK).ExpectJSONPath("username", "jane")
+}
@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,8 +11,13 @@ import (
 
 	"github.com/luminosita/change-me/internal/config"
 	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/internal/core/events"
+	"github.com/luminosita/change-me/internal/core/reqctx"
 	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
+	"github.com/luminosita/change-me/pkg/deadline"
+	"github.com/luminosita/change-me/pkg/httpclient"
 	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/pkg/tracecontext"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -79,8 +85,18 @@ func TestDependencyInjection_HTTPClientIsInjected(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.Equal(t, 30*time.Second, client.Timeout)
 
-	// Verify transport is configured
-	transport, ok := client.Transport.(*http.Transport)
+	// Verify transport is configured behind trace context propagation, the
+	// deadline budget, outbound metrics and the egress policy
+	traced, ok := client.Transport.(*tracecontext.RoundTripper)
+	require.True(t, ok)
+	budgeted, ok := traced.Base.(*deadline.RoundTripper)
+	require.True(t, ok)
+	assert.Equal(t, 0.8, budgeted.Fraction)
+	measured, ok := budgeted.Base.(*httpclient.RoundTripper)
+	require.True(t, ok)
+	egress, ok := measured.Base.(*httpclient.EgressTransport)
+	require.True(t, ok)
+	transport, ok := egress.Base.(*http.Transport)
 	require.True(t, ok)
 	assert.Equal(t, 10, transport.MaxIdleConns)
 	assert.Equal(t, 10, transport.MaxIdleConnsPerHost)
@@ -331,6 +347,47 @@ func TestDependencyInjection_DependencyLifecycle(t *testing.T) {
 	}
 }
 
+// scopeTestEvent is published within request scopes.
+type scopeTestEvent struct{}
+
+func (scopeTestEvent) EventName() string { return "test.scoped" }
+
+func TestDependencyInjection_RequestScope(t *testing.T) {
+	// Arrange
+	cfg := &config.Config{AppName: "Scope Test", LogLevel: "ERROR", LogFormat: "json"}
+	log, err := logger.New(logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	require.NoError(t, err)
+	container := dependencies.NewContainer(cfg, log)
+	defer container.Close()
+
+	delivered := 0
+	container.Events.Subscribe("test.scoped", func(context.Context, events.Event) { delivered++ })
+	request := reqctx.With(context.Background(), &reqctx.RequestContext{RequestID: "req-1", Principal: "key-1"})
+
+	// Act - a successful request
+	ctx, end := container.OpenScope(request)
+	scope := dependencies.ScopeFrom(ctx)
+	container.Events.Publish(ctx, scopeTestEvent{})
+
+	// Assert - request-scoped values, events held until the scope ends
+	require.NotNil(t, scope)
+	assert.Equal(t, "key-1", scope.Principal)
+	assert.NotSame(t, container.Logger, scope.Logger)
+	assert.Zero(t, delivered)
+	end(false)
+	assert.Equal(t, 1, delivered)
+
+	// Act - a failed request drops its events and gets its own scope
+	failedCtx, endFailed := container.OpenScope(request)
+	assert.NotSame(t, scope, dependencies.ScopeFrom(failedCtx))
+	container.Events.Publish(failedCtx, scopeTestEvent{})
+	endFailed(true)
+
+	// Assert
+	assert.Equal(t, 1, delivered)
+	assert.Nil(t, dependencies.ScopeFrom(context.Background()))
+}
+
 // ====================
 // Test Helpers
 // ====================
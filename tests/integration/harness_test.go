@@ -0,0 +1,34 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Harness Tests
+// ====================
+
+func TestHarness_NewTestServerWithoutInfra(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+
+	// Act
+	resp, err := http.Get(ts.URL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ready, err := http.Get(ts.URL + "/health/ready")
+	require.NoError(t, err)
+	defer ready.Body.Close()
+	assert.Equal(t, http.StatusOK, ready.StatusCode, "the harness runs the warmup hooks")
+}
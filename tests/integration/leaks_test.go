@@ -0,0 +1,90 @@
+//go:build integration
+
+package integration
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/slo"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Leak Detection Tests
+// ====================
+
+// withBackgroundWork enables the features running goroutines or holding
+// files for the lifetime of the container.
+func withBackgroundWork(t *testing.T) func(*config.Config) {
+	dir := t.TempDir()
+	flags := filepath.Join(dir, "route-flags.yaml")
+	require.NoError(t, os.WriteFile(flags, []byte("routes: []\n"), 0o600))
+
+	return func(cfg *config.Config) {
+		cfg.EmbeddedStorePath = filepath.Join(dir, "store.db")
+		cfg.EmbeddedStoreCompactInterval = time.Hour
+		cfg.SlowRequestThreshold = time.Second
+		cfg.MeteringEnabled = true
+		cfg.MeteringFlushInterval = time.Second
+		cfg.MeteringBatchSize = 10
+		cfg.RouteFlagsConfigFile = flags
+		cfg.RouteFlagsReloadInterval = time.Second
+		cfg.SLOObjectives = []slo.Objective{{Name: "api", Prefix: "/api", Availability: 0.99}}
+	}
+}
+
+// getBody performs a GET request and drains the response so the
+// connection is reused.
+func getBody(t *testing.T, client *http.Client, url string) int {
+	t.Helper()
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestServerLifecycle_NoLeaksAcrossStartAndShutdown(t *testing.T) {
+	// Arrange
+	harness.VerifyNoLeaks(t)
+	server := harness.StartServer(t, nil, withBackgroundWork(t))
+	client := &http.Client{Transport: &http.Transport{}}
+	defer client.CloseIdleConnections()
+
+	// Act - keep-alive connections stay idle until the server shuts down
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, getBody(t, client, server.URL+"/health"))
+	}
+	assert.Equal(t, http.StatusNotFound, getBody(t, client, server.URL+"/api/v1/does-not-exist"))
+	idle := server.Container.Connections.Open()
+	err := server.Shutdown()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, idle, "requests reuse one keep-alive connection")
+	// Closed connections report their state once their goroutine exits
+	assert.Eventually(t, func() bool { return server.Container.Connections.Open() == 0 },
+		time.Second, 10*time.Millisecond, "shutdown closes idle connections")
+}
+
+func TestHarness_TestServerCleanupReleasesResources(t *testing.T) {
+	// Arrange
+	harness.VerifyNoLeaks(t)
+
+	// Act - the subtest cleanup closes the server before the leak check
+	t.Run("server", func(t *testing.T) {
+		ts := harness.NewTestServer(t, nil, withBackgroundWork(t))
+		assert.Equal(t, http.StatusOK, getBody(t, ts.Client(), ts.URL+"/health/ready"))
+	})
+
+	// Assert - VerifyNoLeaks fails the test on cleanup
+}
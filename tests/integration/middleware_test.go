@@ -0,0 +1,133 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/constants"
+	"github.com/luminosita/change-me/internal/core/dependencies"
+	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Middleware Chain Tests
+// ====================
+
+func TestMiddlewareChain_AdminEndpointListsEffectiveChain(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.DedupEnabled = true
+	})
+
+	// Act
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/admin/middleware", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var chain handlers.MiddlewareChainResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&chain))
+
+	// Assert - router-wide middleware in execution order
+	names := make([]string, 0, len(chain.Global))
+	enabled := map[string]bool{}
+	for _, entry := range chain.Global {
+		names = append(names, entry.Name)
+		enabled[entry.Name] = entry.Enabled
+	}
+	assert.Equal(t, []string{
+		"recovery", "trace_context", "server_timing", "timeout", "request_context", "scope", "default_headers", "cors", "logger", "watchdog",
+		"metrics", "slo", "route_flags", "priority", "ratelimit", "captcha", "consent", "recorder", "pii_mask", "openapi", "strict_json", "dedup",
+	}, names)
+	assert.True(t, enabled["dedup"])
+	assert.False(t, enabled["slo"], "features without configuration are listed as disabled")
+	assert.False(t, enabled["timeout"], "no request deadline by default")
+
+	// Assert - route groups with their own middleware
+	require.Len(t, chain.Groups, 3)
+	assert.Equal(t, "admin", chain.Groups[2].Name)
+	assert.Equal(t, "admin_auth", chain.Groups[2].Middleware[0].Name)
+	assert.True(t, chain.Groups[2].Middleware[0].Enabled)
+}
+
+func TestMiddlewareChain_DeferredOpenAPIValidatorBuildsInWarmup(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.OpenAPIValidation = constants.OpenAPIValidationReject
+		cfg.StartupDeferModules = true
+	})
+
+	// Act
+	resp, err := http.Post(ts.URL+"/api/v1/users", "application/json", strings.NewReader(`{"email": 1}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Contains(t, ts.Container.Warmup.Names(), "openapi", "the validator is built by its warmup hook")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), "request_validation_failed")
+}
+
+func TestCaptcha_UnbuildableVerifierFailsStartup(t *testing.T) {
+	// Arrange - a provider without a secret, which load-time validation
+	// would reject
+	cfg := &config.Config{
+		AppName:         "Test Server",
+		Environment:     constants.EnvProduction,
+		LogLevel:        "ERROR",
+		LogFormat:       "json",
+		CaptchaProvider: "turnstile",
+		CaptchaRoutes:   []string{"POST /api/v1/users"},
+	}
+	log, err := logger.New(logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	require.NoError(t, err)
+	container := dependencies.NewContainer(cfg, log)
+	defer container.Close()
+
+	// Act & Assert - the routes are never served unprotected
+	assert.PanicsWithError(t, "captcha: secret required", func() { httpserver.New(container) })
+}
+
+func TestServerTiming_ExposedToAdminCallersInDebugMode(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.Debug = false
+		cfg.ServerTiming = constants.ServerTimingDebug
+	})
+
+	// Act
+	public, err := http.Get(ts.URL + "/api/v1/errors")
+	require.NoError(t, err)
+	public.Body.Close()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/errors", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	admin, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	admin.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, admin.StatusCode)
+	assert.Empty(t, public.Header.Get("Server-Timing"))
+	timing := admin.Header.Get("Server-Timing")
+	for _, phase := range []string{"middleware;dur=", "handler;dur=", "serialization;dur=", "total;dur="} {
+		assert.Contains(t, timing, phase)
+	}
+}
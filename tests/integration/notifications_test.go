@@ -0,0 +1,102 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===================
+// Notifications Tests
+// ===================
+
+func TestNotifications_EnqueueAndDeliver(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var texts []string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		texts = append(texts, payload.Text)
+		mu.Unlock()
+	}))
+	defer slack.Close()
+
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.SlackWebhooks = []string{"alerts=" + slack.URL}
+	})
+	require.NotNil(t, ts.Container.Notifications)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = ts.Container.Notifications.Worker().Run(ctx) }()
+
+	send := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/admin/notifications", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Act
+	accepted := send(`{"channel":"slack","to":["alerts"],"template":"message","data":{"Subject":"Deploy","Text":"v1.2.0 is live"}}`)
+	defer accepted.Body.Close()
+	unconfigured := send(`{"channel":"sms","to":["+15551234567"],"template":"message"}`)
+	defer unconfigured.Body.Close()
+
+	// Assert
+	require.Equal(t, http.StatusAccepted, accepted.StatusCode)
+	var resp handlers.SendNotificationResponse
+	require.NoError(t, json.NewDecoder(accepted.Body).Decode(&resp))
+	assert.NotEmpty(t, resp.ID)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(texts) == 1 && texts[0] == "*Deploy*\nv1.2.0 is live"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, http.StatusBadRequest, unconfigured.StatusCode)
+	var errResp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(unconfigured.Body).Decode(&errResp))
+	assert.Equal(t, "notification_channel_unavailable", errResp.Code)
+}
+
+func TestNotifications_UnavailableWithoutChannels(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+	})
+
+	// Act
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/admin/notifications", bytes.NewBufferString(`{}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Nil(t, ts.Container.Notifications)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
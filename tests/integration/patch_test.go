@@ -0,0 +1,71 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luminosita/change-me/pkg/client"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===================
+// User PATCH Tests
+// ===================
+
+// TestUserPatch_AppliesBothFormats updates a user with a JSON Patch guarded
+// by a test of updated_at, then with a merge patch.
+func TestUserPatch_AppliesBothFormats(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+	c := client.New(ts.URL)
+	ctx := context.Background()
+	created, err := c.CreateUser(ctx, client.CreateUserRequest{Email: "jane@example.com", Username: "jane"})
+	require.NoError(t, err)
+	stamp := created.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z")
+
+	// Act
+	patched, err := c.UpdateUser(ctx, created.ID, strings.NewReader(`[
+		{"op":"test","path":"/updated_at","value":"`+stamp+`"},
+		{"op":"replace","path":"/full_name","value":"Jane Doe"}
+	]`), "application/json-patch+json")
+	require.NoError(t, err)
+	merged, err := c.UpdateUser(ctx, created.ID, strings.NewReader(`{"is_active":false}`), "application/merge-patch+json")
+	require.NoError(t, err)
+	got, err := c.GetUser(ctx, created.ID)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "Jane Doe", patched.FullName)
+	assert.False(t, merged.IsActive)
+	assert.Equal(t, "Jane Doe", merged.FullName)
+	assert.Equal(t, merged.IsActive, got.IsActive, "reads see the update")
+}
+
+// TestUserPatch_RejectsDisallowedPaths keeps read-only fields unchanged.
+func TestUserPatch_RejectsDisallowedPaths(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+	c := client.New(ts.URL)
+	ctx := context.Background()
+	created, err := c.CreateUser(ctx, client.CreateUserRequest{Email: "jane@example.com", Username: "jane"})
+	require.NoError(t, err)
+
+	// Act
+	_, err = c.UpdateUser(ctx, created.ID, strings.NewReader(`[{"op":"replace","path":"/id","value":42}]`),
+		"application/json-patch+json")
+
+	// Assert
+	var apiErr *client.Error
+	require.True(t, errors.As(err, &apiErr), err)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "patch_path_not_allowed", apiErr.Code)
+	_, err = c.GetUser(ctx, created.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =================
+// PII Masking Tests
+// =================
+
+func TestPII_MasksResponsesForUnprivilegedCallers(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.PIIMaskResponses = true
+	})
+	user := &users.User{Email: "jane@example.com", Username: "jane", FullName: "Jane Doe", IsActive: true}
+	require.NoError(t, ts.Container.UserRepository.Create(context.Background(), user))
+
+	get := func(path, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	decode := func(resp *http.Response) handlers.UserResponse {
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var user handlers.UserResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		return user
+	}
+
+	// Act
+	masked := decode(get("/api/v1/users/"+user.ID.String(), ""))
+	clear := decode(get("/api/v1/users/"+user.ID.String(), "secret"))
+	exported := get("/api/v1/users/export", "")
+	defer exported.Body.Close()
+	csv, err := io.ReadAll(exported.Body)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "j***@example.com", masked.Email)
+	assert.Equal(t, "J*** D***", masked.FullName)
+	assert.Equal(t, "jane", masked.Username)
+	assert.Equal(t, "jane@example.com", clear.Email)
+	assert.Equal(t, "Jane Doe", clear.FullName)
+	assert.Contains(t, string(csv), "j***@example.com")
+	assert.NotContains(t, string(csv), "jane@example.com")
+}
@@ -0,0 +1,86 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/plugins"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// probePlugin is a downstream plugin added from an init function.
+type probePlugin struct {
+	started atomic.Bool
+}
+
+func (p *probePlugin) Name() string { return "probe" }
+func (p *probePlugin) Config() any  { return nil }
+
+func (p *probePlugin) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/probe", func(c *gin.Context) { c.String(http.StatusOK, "probe") })
+}
+
+func (p *probePlugin) Start(context.Context) error {
+	p.started.Store(true)
+	return nil
+}
+
+func (p *probePlugin) Stop(context.Context) error { return nil }
+
+var probe = &probePlugin{}
+
+func init() {
+	plugins.Register(probe)
+}
+
+// ====================
+// Plugin Tests
+// ====================
+
+func TestPlugins_EnabledModulesMountAndStart(t *testing.T) {
+	// Arrange
+	var settings map[string]yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("docs:\n  path: /reference\n"), &settings))
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.PluginsEnabled = []string{"docs", "probe"}
+		cfg.PluginSettings = settings
+	})
+
+	// Act
+	spec, err := http.Get(ts.URL + "/reference/openapi.yaml")
+	require.NoError(t, err)
+	defer spec.Body.Close()
+	resp, err := http.Get(ts.URL + "/probe")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, spec.StatusCode)
+	assert.Equal(t, "application/yaml", spec.Header.Get("Content-Type"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, probe.started.Load(), "enabled plugins are started")
+}
+
+func TestPlugins_DisabledModulesAreNotMounted(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+
+	// Act
+	for _, path := range []string{"/docs/openapi.yaml", "/probe"} {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+}
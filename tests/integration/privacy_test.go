@@ -0,0 +1,153 @@
+//go:build integration
+
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============
+// Privacy Tests
+// =============
+
+// privacyRequest sends an authenticated request to the privacy admin API
+// and decodes a successful response into out.
+func privacyRequest(t *testing.T, ts *harness.TestServer, method, path string, wantStatus int, out any) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, wantStatus, resp.StatusCode)
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp
+}
+
+func TestPrivacy_ExportUserData(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.ObjectStorageProvider = "local"
+		cfg.ObjectStorageDir = t.TempDir()
+		cfg.ObjectStoragePublicURL = "/api/v1/files"
+		cfg.ObjectStorageSigningKey = "test-signing-key"
+	})
+	user := &users.User{Email: "jane@example.com", Username: "jane", FullName: "Jane Doe", IsActive: true}
+	require.NoError(t, ts.Container.UserRepository.Create(context.Background(), user))
+
+	// Act
+	var export handlers.ExportResponse
+	resp := privacyRequest(t, ts, http.MethodPost, "/admin/privacy/users/"+user.ID.String()+"/export", http.StatusAccepted, &export)
+	location := resp.Header.Get("Location")
+	require.Equal(t, "/admin/privacy/exports/"+export.ID, location)
+
+	require.Eventually(t, func() bool {
+		privacyRequest(t, ts, http.MethodGet, location, http.StatusOK, &export)
+		return export.Status == "succeeded" || export.Status == "failed"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Assert
+	require.Equal(t, "succeeded", export.Status, export.Error)
+	require.NotEmpty(t, export.DownloadURL)
+
+	download, err := http.Get(ts.URL + export.DownloadURL)
+	require.NoError(t, err)
+	defer download.Body.Close()
+	require.Equal(t, http.StatusOK, download.StatusCode)
+	body, err := io.ReadAll(download.Body)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(body, []byte("PK")))
+
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.ElementsMatch(t, []string{"manifest.json", "users.json"}, names)
+
+	privacyRequest(t, ts, http.MethodPost, "/admin/privacy/users/"+users.NewID(nil).String()+"/export", http.StatusNotFound, nil)
+	privacyRequest(t, ts, http.MethodPost, "/admin/privacy/users/abc/export", http.StatusBadRequest, nil)
+}
+
+func TestPrivacy_DeletionAfterGracePeriod(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.PrivacyDeletionGrace = 0
+	})
+	ctx := context.Background()
+	user := &users.User{Email: "jane@example.com", Username: "jane", IsActive: true}
+	require.NoError(t, ts.Container.UserRepository.Create(ctx, user))
+
+	// Act
+	var deletion handlers.DeletionResponse
+	resp := privacyRequest(t, ts, http.MethodPost, "/admin/privacy/users/"+user.ID.String()+"/deletion", http.StatusAccepted, &deletion)
+	require.Equal(t, "/admin/privacy/deletions/"+deletion.ID, resp.Header.Get("Location"))
+	assert.Equal(t, "scheduled", deletion.Status)
+	privacyRequest(t, ts, http.MethodPost, "/admin/privacy/users/"+user.ID.String()+"/deletion", http.StatusConflict, nil)
+
+	_, err := ts.Container.Privacy.Sweep(ctx)
+	require.NoError(t, err)
+
+	// Assert
+	privacyRequest(t, ts, http.MethodGet, "/admin/privacy/deletions/"+deletion.ID, http.StatusOK, &deletion)
+	assert.Equal(t, "completed", deletion.Status)
+	var actions []string
+	for _, entry := range deletion.Trail {
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, "requested", actions[0])
+	assert.Equal(t, "completed", actions[len(actions)-1])
+	assert.Contains(t, strings.Join(actions, ","), "erased")
+
+	assert.NoError(t, ts.Container.UserRepository.Create(ctx, &users.User{Email: "jane@example.com", Username: "jane"}),
+		"erasure releases the email")
+	privacyRequest(t, ts, http.MethodDelete, "/admin/privacy/deletions/"+deletion.ID, http.StatusConflict, nil)
+}
+
+func TestPrivacy_CancelDeletionRestoresUser(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.PrivacyDeletionGrace = time.Hour
+	})
+	ctx := context.Background()
+	user := &users.User{Email: "jane@example.com", Username: "jane", IsActive: true}
+	require.NoError(t, ts.Container.UserRepository.Create(ctx, user))
+
+	var deletion handlers.DeletionResponse
+	privacyRequest(t, ts, http.MethodPost, "/admin/privacy/users/"+user.ID.String()+"/deletion", http.StatusAccepted, &deletion)
+	_, err := ts.Container.UserRepository.GetByID(ctx, user.ID)
+	require.ErrorIs(t, err, users.ErrNotFound, "the user is hidden during the grace period")
+
+	// Act
+	privacyRequest(t, ts, http.MethodDelete, "/admin/privacy/deletions/"+deletion.ID, http.StatusOK, &deletion)
+	_, err = ts.Container.Privacy.Sweep(ctx)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "cancelled", deletion.Status)
+	restored, err := ts.Container.UserRepository.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+}
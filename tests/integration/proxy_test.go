@@ -0,0 +1,56 @@
+//go:build integration
+
+package integration
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/pkg/proxy"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Proxy Route Tests
+// ====================
+
+func TestProxyRoutes_ForwardUnmatchedPaths(t *testing.T) {
+	// Arrange
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "upstream:"+r.URL.Path)
+	}))
+	defer upstream.Close()
+
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.ProxyRoutes = []proxy.Route{
+			{Name: "search", Prefix: "/search/", Upstream: upstream.URL, StripPrefix: true},
+		}
+	})
+
+	// Act
+	resp, err := http.Get(ts.URL + "/search/docs")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "upstream:/docs", string(body))
+
+	// Act - application routes and unknown paths are not proxied
+	health, err := http.Get(ts.URL + "/health")
+	require.NoError(t, err)
+	health.Body.Close()
+	missing, err := http.Get(ts.URL + "/unknown")
+	require.NoError(t, err)
+	missing.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, health.StatusCode)
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+}
@@ -0,0 +1,107 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/middleware"
+	"github.com/luminosita/change-me/pkg/apikey"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Quota Tests
+// ====================
+
+func TestQuota_RedisBackedEnforcement(t *testing.T) {
+	// Arrange - skipped automatically without a container runtime
+	infra := harness.StartInfra(t, harness.WithRedis())
+	ts := harness.NewTestServer(t, infra, func(cfg *config.Config) {
+		cfg.QuotaEnabled = true
+		cfg.QuotaSubjectHeader = "X-API-Key"
+		cfg.APIKeys = []string{"ci=" + apikey.Digest("key-1")}
+		cfg.QuotaDailyLimit = 2
+		cfg.AdminToken = "secret"
+	})
+	require.NotNil(t, ts.Container.Redis)
+
+	// Act
+	codes := make([]int, 3)
+	for i := range codes {
+		resp := doQuotaRequest(t, "GET", ts.URL+"/api/v1/users?limit=20", "X-API-Key", "key-1")
+		codes[i] = resp.StatusCode
+		if i == 2 {
+			assert.Equal(t, "0", resp.Header.Get(middleware.QuotaRemainingHeader))
+		}
+	}
+
+	// Assert
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+
+	// Act - reset through the admin API
+	resp := doQuotaRequest(t, "DELETE", ts.URL+"/admin/quotas/ci/usage", "Authorization", "Bearer secret")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// Assert
+	resp = doQuotaRequest(t, "GET", ts.URL+"/api/v1/users?limit=20", "X-API-Key", "key-1")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doQuotaRequest(t, "GET", ts.URL+"/api/v1/users?limit=20", "X-API-Key", "key-2")
+	assert.Empty(t, resp.Header.Get(middleware.QuotaRemainingHeader), "unknown keys are not quota subjects")
+}
+
+func TestQuota_TenantSubjectsShareLimits(t *testing.T) {
+	// Arrange - two keys of one tenant and a key without a tenant
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.QuotaEnabled = true
+		cfg.QuotaSubject = "tenant"
+		cfg.QuotaSubjectHeader = "X-API-Key"
+		cfg.APIKeys = []string{
+			"ci=" + apikey.Digest("key-1"),
+			"ops=" + apikey.Digest("key-2"),
+			"solo=" + apikey.Digest("key-3"),
+		}
+		cfg.APIKeyTenants = []string{"ci=acme", "ops=acme"}
+		cfg.QuotaDailyLimit = 2
+		cfg.AdminToken = "secret"
+	})
+
+	// Act
+	codes := []int{
+		doQuotaRequest(t, "GET", ts.URL+"/api/v1/users?limit=20", "X-API-Key", "key-1").StatusCode,
+		doQuotaRequest(t, "GET", ts.URL+"/api/v1/users?limit=20", "X-API-Key", "key-2").StatusCode,
+		doQuotaRequest(t, "GET", ts.URL+"/api/v1/users?limit=20", "X-API-Key", "key-1").StatusCode,
+	}
+
+	// Assert
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+	resp := doQuotaRequest(t, "GET", ts.URL+"/admin/quotas/acme", "Authorization", "Bearer secret")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Assert - a tenant header from the client does not pick the subject
+	req, err := http.NewRequest("GET", ts.URL+"/api/v1/users?limit=20", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", "key-3")
+	req.Header.Set("X-Tenant-ID", "acme")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(middleware.QuotaRemainingHeader), "keys without a tenant are not quota subjects")
+}
+
+// doQuotaRequest sends a request with a single header and closes the body.
+func doQuotaRequest(t *testing.T, method, url, header, value string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	req.Header.Set(header, value)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	return resp
+}
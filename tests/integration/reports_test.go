@@ -0,0 +1,107 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============
+// Reports Tests
+// =============
+
+func TestReports_GenerateAndDownload(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.ObjectStorageProvider = "local"
+		cfg.ObjectStorageDir = t.TempDir()
+		cfg.ObjectStoragePublicURL = "/api/v1/files"
+		cfg.ObjectStorageSigningKey = "test-signing-key"
+	})
+	require.NotNil(t, ts.Container.Reports)
+	require.NoError(t, ts.Container.UserRepository.Create(context.Background(),
+		&users.User{Email: "jane@example.com", Username: "jane", FullName: "Jane Doe", IsActive: true}))
+
+	getOperation := func(url string) handlers.ReportOperationResponse {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var op handlers.ReportOperationResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&op))
+		return op
+	}
+
+	// Act
+	resp, err := http.Post(ts.URL+"/api/v1/reports/users", "application/json",
+		bytes.NewBufferString(`{"format":"pdf","params":{"active":"true"}}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	require.True(t, strings.HasPrefix(location, "/api/v1/reports/operations/"), location)
+
+	var op handlers.ReportOperationResponse
+	require.Eventually(t, func() bool {
+		op = getOperation(ts.URL + location)
+		return op.Status == "succeeded" || op.Status == "failed"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Assert
+	require.Equal(t, "succeeded", op.Status, op.Error)
+	assert.Equal(t, 1.0, op.Progress)
+	require.NotEmpty(t, op.DownloadURL)
+
+	download, err := http.Get(ts.URL + op.DownloadURL)
+	require.NoError(t, err)
+	defer download.Body.Close()
+	require.Equal(t, http.StatusOK, download.StatusCode)
+	assert.Equal(t, "application/pdf", download.Header.Get("Content-Type"))
+	body, err := io.ReadAll(download.Body)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(body, []byte("%PDF-")))
+
+	tampered, err := http.Get(ts.URL + strings.Replace(op.DownloadURL, "signature=", "signature=0", 1))
+	require.NoError(t, err)
+	tampered.Body.Close()
+	assert.Equal(t, http.StatusForbidden, tampered.StatusCode)
+}
+
+func TestReports_UnknownReportAndFormat(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.ObjectStorageProvider = "local"
+		cfg.ObjectStorageDir = t.TempDir()
+	})
+
+	// Act
+	unknown, err := http.Post(ts.URL+"/api/v1/reports/missing", "application/json",
+		bytes.NewBufferString(`{"format":"pdf"}`))
+	require.NoError(t, err)
+	defer unknown.Body.Close()
+	badFormat, err := http.Post(ts.URL+"/api/v1/reports/users", "application/json",
+		bytes.NewBufferString(`{"format":"docx"}`))
+	require.NoError(t, err)
+	badFormat.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, unknown.StatusCode)
+	var errResp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(unknown.Body).Decode(&errResp))
+	assert.Equal(t, "report_not_found", errResp.Code)
+	assert.Equal(t, http.StatusBadRequest, badFormat.StatusCode)
+}
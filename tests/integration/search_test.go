@@ -0,0 +1,84 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Search Tests
+// ====================
+
+func TestSearch_UsersIndexedOnCreateAndReindex(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.SearchProvider = "embedded"
+	})
+	resp, err := http.Post(ts.URL+"/api/v1/users", "application/json",
+		strings.NewReader(`{"email":"jane@example.com","username":"jane","full_name":"Jane Doe"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	searchUsers := func(query string) handlers.UserSearchResponse {
+		resp, err := http.Get(ts.URL + "/api/v1/users/search?" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var page handlers.UserSearchResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page
+	}
+
+	// Act & Assert - created users are searchable with highlights
+	page := searchUsers("q=doe")
+	require.Equal(t, 1, page.Total)
+	assert.Equal(t, "jane", page.Items[0].Username)
+	assert.Equal(t, []string{"Jane <em>Doe</em>"}, page.Items[0].Highlights["full_name"])
+
+	// Act & Assert - users written behind the service appear after a reindex
+	require.NoError(t, ts.Container.UserRepository.Create(context.Background(),
+		&users.User{Email: "john@example.com", Username: "john", FullName: "John Doe"}))
+	assert.Equal(t, 1, searchUsers("q=doe").Total)
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/admin/search/users/reindex", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	reindex, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	reindex.Body.Close()
+	require.Equal(t, http.StatusOK, reindex.StatusCode)
+
+	page = searchUsers("q=doe&limit=1")
+	assert.Equal(t, 2, page.Total)
+	assert.Len(t, page.Items, 1)
+	inactive := searchUsers("q=doe&active=false")
+	require.Equal(t, 1, inactive.Total)
+	assert.Equal(t, "john", inactive.Items[0].Username)
+}
+
+func TestSearch_UnavailableWithoutProvider(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+
+	// Act
+	resp, err := http.Get(ts.URL + "/api/v1/users/search?q=jane")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// Assert - the path falls through to the users module
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+}
@@ -7,13 +7,16 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/luminosita/change-me/internal/config"
 	"github.com/luminosita/change-me/internal/core/dependencies"
+	"github.com/luminosita/change-me/internal/core/warmup"
 	httpserver "github.com/luminosita/change-me/internal/interfaces/http"
 	"github.com/luminosita/change-me/pkg/logger"
+	"github.com/luminosita/change-me/tests/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -88,6 +91,33 @@ func TestServerLifecycle_StartAndShutdown(t *testing.T) {
 	}
 }
 
+func TestServerLifecycle_ReadinessWaitsForWarmup(t *testing.T) {
+	// Arrange
+	cfg := &config.Config{AppName: "Test Server", AppVersion: "0.1.0", LogLevel: "ERROR", LogFormat: "json"}
+	log, err := logger.New(logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	require.NoError(t, err)
+	container := dependencies.NewContainer(cfg, log)
+	defer container.Close()
+	router := httpserver.New(container).Router()
+
+	probe := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		return w.Code
+	}
+
+	// Assert - liveness is answered while warming up, readiness is not
+	assert.Equal(t, http.StatusServiceUnavailable, probe())
+
+	// Act
+	report := container.Warmup.Run(context.Background(), log, time.Second)
+
+	// Assert
+	assert.Equal(t, []string{"users", "router"}, report.Completed)
+	assert.Empty(t, report.Failed)
+	assert.Equal(t, http.StatusOK, probe())
+}
+
 func TestServerLifecycle_GracefulShutdown(t *testing.T) {
 	// Arrange
 	port := findAvailablePort(t)
@@ -343,6 +373,45 @@ func TestServerLifecycle_ServerTimeoutConfiguration(t *testing.T) {
 	_ = httpSrv.Shutdown(ctx)
 }
 
+func TestServerLifecycle_CloudRunWarmsUpBeforeListening(t *testing.T) {
+	// Arrange - a slow warmup hook under Cloud Run CPU throttling
+	cfg := mocks.NewTestConfig()
+	cfg.Host, cfg.Port = "127.0.0.1", findAvailablePort(t)
+	cfg.LogLevel = "ERROR"
+	cfg.CloudRun, cfg.CloudRunCPUThrottled = true, true
+	log, err := logger.New(logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	require.NoError(t, err)
+	container := dependencies.NewContainer(cfg, log)
+	defer container.Close()
+	require.NoError(t, container.Warmup.Register(warmup.New("slow", func(context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})))
+	server := httpserver.New(container)
+
+	// Act
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+	addr := fmt.Sprintf("127.0.0.1:%d", cfg.Port)
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// Assert - the port opens only once the instance is ready
+	resp, err := http.Get("http://" + addr + "/health/ready")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
 // ====================
 // Test Helpers
 // ====================
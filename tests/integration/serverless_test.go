@@ -0,0 +1,64 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/luminosita/change-me/pkg/serverless"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Serverless Adapter Tests
+// ====================
+
+func TestServerless_ServesRouterThroughHTTPAPIEvents(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+	adapter := serverless.New(ts.App.Router(), serverless.Options{BasePath: "/prod"})
+	payload, err := json.Marshal(events.APIGatewayV2HTTPRequest{
+		Version: "2.0",
+		RawPath: "/prod/health",
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RequestID: "gateway-request",
+			HTTP:      events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodGet, SourceIP: "203.0.113.7"},
+		},
+	})
+	require.NoError(t, err)
+
+	// Act
+	out, err := adapter.Invoke(context.Background(), payload)
+	require.NoError(t, err)
+	var resp events.APIGatewayV2HTTPResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.False(t, resp.IsBase64Encoded)
+	assert.Contains(t, resp.Body, `"status"`)
+	assert.Equal(t, "gateway-request", resp.Headers["X-Request-Id"], "the gateway request ID is the request ID")
+}
+
+func TestServerless_ServesUnknownRoutesThroughAPIGatewayEvents(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+	adapter := serverless.New(ts.App.Router(), serverless.Options{})
+	payload, err := json.Marshal(events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/api/v1/missing"})
+	require.NoError(t, err)
+
+	// Act
+	out, err := adapter.Invoke(context.Background(), payload)
+	require.NoError(t, err)
+	var resp events.APIGatewayProxyResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
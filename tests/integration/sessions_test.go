@@ -0,0 +1,89 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/sessions"
+	redisstore "github.com/luminosita/change-me/internal/infrastructure/persistence/redis"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/pkg/apikey"
+	"github.com/luminosita/change-me/tests/harness"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Session Store Tests
+// ====================
+
+func TestSessions_RedisPrincipalIndexShrinksOnLogout(t *testing.T) {
+	// Arrange - skipped automatically without a container runtime
+	infra := harness.StartInfra(t, harness.WithRedis())
+	opts, err := goredis.ParseURL(infra.RedisURL)
+	require.NoError(t, err)
+	client := goredis.NewClient(opts)
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	service := sessions.NewService(redisstore.NewSessionStore(client), sessions.Options{TTL: time.Hour})
+	first, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+	second, err := service.Issue(ctx, "jane")
+	require.NoError(t, err)
+
+	index := "sessions:principal:jane"
+	members, err := client.SCard(ctx, index).Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, members)
+	ttl, err := client.TTL(ctx, index).Result()
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour.Seconds(), ttl.Seconds(), 60, "the index expires with the last family")
+
+	// Act
+	require.NoError(t, service.Revoke(ctx, first.RefreshToken))
+
+	// Assert
+	members, err = client.SCard(ctx, index).Result()
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, members)
+
+	revoked, err := service.RevokeAll(ctx, second.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+	exists, err := client.Exists(ctx, index).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestSessions_AccessTokenAuthenticatesAPI(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.PrincipalHeader = "X-API-Key"
+		cfg.APIKeys = []string{"jane=" + apikey.Digest("jane-key")}
+		cfg.AccessTokenSigningKey = strings.Repeat("k", 32)
+	})
+	var tokens handlers.TokenResponse
+	status := consentRequest(t, ts, http.MethodPost, "/api/v1/auth/sessions", map[string]string{"X-API-Key": "jane-key"}, nil, &tokens)
+	require.Equal(t, http.StatusCreated, status)
+	require.NotEmpty(t, tokens.AccessToken)
+
+	// Act & Assert - the access token identifies the caller
+	bearer := map[string]string{"Authorization": "Bearer " + tokens.AccessToken}
+	assert.Equal(t, http.StatusOK, consentRequest(t, ts, http.MethodGet, "/api/v1/consent", bearer, nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, consentRequest(t, ts, http.MethodGet, "/api/v1/consent", nil, nil, nil))
+
+	var rejected struct {
+		Code string `json:"code"`
+	}
+	forged := map[string]string{"Authorization": "Bearer " + tokens.AccessToken + "x"}
+	assert.Equal(t, http.StatusUnauthorized, consentRequest(t, ts, http.MethodGet, "/api/v1/users", forged, nil, &rejected))
+	assert.Equal(t, "access_token_invalid", rejected.Code)
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/bolt"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/luminosita/change-me/tests/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Embedded Store Tests
+// ====================
+
+func TestEmbeddedStore_BackupAndCompact(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.EmbeddedStorePath = filepath.Join(t.TempDir(), "store.db")
+	})
+	require.NotNil(t, ts.Container.Store)
+	require.NoError(t, ts.Container.UserRepository.Create(context.Background(),
+		&users.User{Email: "jane@example.com", Username: "jane"}))
+
+	admin := testkit.New(t, ts.URL).WithAuth("secret")
+
+	// Act
+	compact := admin.Post("/admin/store/compact").Do()
+	backup := admin.Get("/admin/store/backup").Do()
+
+	// Assert
+	var result bolt.CompactResult
+	compact.ExpectStatus(http.StatusOK).Decode(&result)
+	assert.Positive(t, result.SizeAfter)
+
+	backup.ExpectStatus(http.StatusOK).ExpectHeader("Content-Type", "application/octet-stream")
+	path := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, os.WriteFile(path, backup.Body, 0o600))
+	restored, err := bolt.Open(path, bolt.Options{})
+	require.NoError(t, err)
+	defer restored.Close()
+	user, err := bolt.NewUserRepository(restored, nil).GetByEmail(context.Background(), "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "jane", user.Username)
+}
+
+func TestEmbeddedStore_AdminRoutesUnavailableWithoutStore(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+	})
+
+	// Act & Assert
+	testkit.New(t, ts.URL).Get("/admin/store/backup").WithAuth("secret").ExpectStatus(http.StatusNotFound)
+}
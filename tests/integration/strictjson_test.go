@@ -0,0 +1,55 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/pkg/strictjson"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+)
+
+// =================
+// Strict JSON Tests
+// =================
+
+func TestStrictJSON_RejectsUnknownFieldsOnConfiguredRoutes(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.StrictJSONRoutes = []string{"POST /api/v1/users"}
+	})
+	typo := map[string]any{"email": "jane@example.com", "username": "jane", "fullname": "Jane Doe"}
+
+	// Act
+	var rejected handlers.ErrorResponse
+	status := consentRequest(t, ts, http.MethodPost, "/api/v1/users", nil, typo, &rejected)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_request", rejected.Code)
+	assert.Equal(t, []strictjson.Violation{
+		{Field: "fullname", Code: strictjson.CodeUnknownField, Message: "unknown field"},
+	}, rejected.Violations)
+
+	var invalid handlers.ErrorResponse
+	status = consentRequest(t, ts, http.MethodPost, "/api/v1/users", nil, map[string]any{"email": "jane"}, &invalid)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []strictjson.Violation{
+		{Field: "email", Code: "email", Message: "must satisfy email"},
+		{Field: "username", Code: "required", Message: "is required"},
+	}, invalid.Violations)
+
+	delete(typo, "fullname")
+	assert.Equal(t, http.StatusCreated, consentRequest(t, ts, http.MethodPost, "/api/v1/users", nil, typo, nil))
+}
+
+func TestStrictJSON_DisabledByDefault(t *testing.T) {
+	ts := harness.NewTestServer(t, nil)
+	body := map[string]any{"email": "john@example.com", "username": "john", "fullname": "John Doe"}
+
+	assert.Equal(t, http.StatusCreated, consentRequest(t, ts, http.MethodPost, "/api/v1/users", nil, body, nil))
+}
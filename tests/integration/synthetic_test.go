@@ -0,0 +1,113 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/pkg/httpclient"
+	"github.com/luminosita/change-me/pkg/synthetic"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/luminosita/change-me/tests/stubserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ======================
+// Synthetic Check Tests
+// ======================
+
+// healthDetails fetches /health/details.
+func healthDetails(t *testing.T, ts *harness.TestServer) (int, handlers.HealthDetailsResponse) {
+	t.Helper()
+	resp, err := http.Get(ts.URL + "/health/details")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var details handlers.HealthDetailsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&details))
+	return resp.StatusCode, details
+}
+
+func TestSynthetic_FailingUpstreamDegradesHealth(t *testing.T) {
+	// Arrange
+	upstream := stubserver.New(t)
+	upstream.On(stubserver.GET("/health")).Body("ok")
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.SyntheticChecks = []synthetic.Check{{Name: "billing", URL: upstream.URL + "/health", Contains: "ok"}}
+	})
+	runner := ts.Container.Synthetic
+	require.NotNil(t, runner)
+
+	// Act
+	runner.Probe(context.Background())
+	healthyCode, healthy := healthDetails(t, ts)
+	upstream.On(stubserver.GET("/health")).Reply(http.StatusBadGateway)
+	runner.Probe(context.Background())
+	failingCode, failing := healthDetails(t, ts)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, healthyCode)
+	assert.Equal(t, "healthy", healthy.Checks["synthetic:billing"].Status)
+	assert.Equal(t, http.StatusServiceUnavailable, failingCode)
+	assert.Equal(t, "unhealthy", failing.Checks["synthetic:billing"].Status)
+	assert.Contains(t, failing.Checks["synthetic:billing"].Error, "502")
+	upstream.Verify(stubserver.GET("/health"), 2)
+}
+
+func TestSynthetic_NamedClientTimesOutOnSlowUpstream(t *testing.T) {
+	// Arrange
+	upstream := stubserver.New(t)
+	upstream.On(stubserver.GET("/v1/ping")).Body("pong")
+	upstream.On(stubserver.GET("/v1/ping")).Body("pong").Delay(time.Second).Times(1)
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.HTTPClients = []httpclient.Config{{Name: "billing", Timeout: 100 * time.Millisecond}}
+		cfg.SyntheticChecks = []synthetic.Check{{
+			Name:    "billing",
+			URL:     upstream.URL + "/v1/ping",
+			Client:  "billing",
+			Headers: map[string]string{"X-Probe": "synthetic"},
+		}}
+	})
+
+	// Act
+	slow := ts.Container.Synthetic.Probe(context.Background())
+	fast := ts.Container.Synthetic.Probe(context.Background())
+
+	// Assert - the named client's timeout applies, not the check default
+	require.Len(t, slow, 1)
+	assert.False(t, slow[0].OK)
+	assert.Less(t, slow[0].Latency, time.Second)
+	require.Len(t, fast, 1)
+	assert.True(t, fast[0].OK, fast[0].Error)
+	upstream.Verify(stubserver.GET("/v1/ping").WithHeader("X-Probe", "synthetic"), 2)
+	upstream.RequireNoUnmatched()
+}
+
+func TestSynthetic_ProbesOwnEndpoints(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.SyntheticChecks = []synthetic.Check{
+			{Name: "ready", URL: "/health/ready"},
+			{Name: "missing", URL: "/api/v1/does-not-exist"},
+		}
+	})
+	// The harness listens on a random port, so resolve paths against it
+	runner := synthetic.New(ts.Container.Config.SyntheticChecks, synthetic.Options{BaseURL: ts.URL},
+		ts.Container.HTTPClients.Client, ts.Container.Logger)
+
+	// Act
+	results := runner.Probe(context.Background())
+
+	// Assert
+	require.Len(t, results, 2)
+	assert.True(t, results[0].OK, results[0].Error)
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.False(t, results[1].OK)
+	assert.Equal(t, http.StatusNotFound, results[1].Status)
+}
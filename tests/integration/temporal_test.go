@@ -0,0 +1,48 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Temporal Client Tests
+// ====================
+
+func TestTemporal_DisabledWithoutAddress(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil)
+
+	// Act
+	_, details := healthDetails(t, ts)
+
+	// Assert
+	assert.Nil(t, ts.Container.Temporal)
+	assert.Nil(t, ts.Container.Workflows)
+	assert.NotContains(t, details.Checks, "temporal")
+}
+
+func TestTemporal_UnreachableClusterDegradesHealth(t *testing.T) {
+	// Arrange - the client connects on first use, so the server boots
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.TemporalAddress = "127.0.0.1:1"
+		cfg.TemporalNamespace = "default"
+		cfg.TemporalTaskQueue = "billing"
+	})
+	require.NotNil(t, ts.Container.Temporal)
+
+	// Act
+	code, details := healthDetails(t, ts)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unhealthy", details.Checks["temporal"].Status)
+	assert.Equal(t, []string(nil), ts.Container.Workflows.TaskQueues(), "no module registers workflows yet")
+}
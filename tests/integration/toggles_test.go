@@ -0,0 +1,77 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/luminosita/change-me/tests/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Runtime Toggle Tests
+// ====================
+
+func TestToggles_DisableRouteWithOptimisticConcurrency(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+	})
+	client := testkit.New(t, ts.URL)
+	admin := client.WithAuth("secret")
+	disable := map[string]any{"kind": "route", "name": "GET /api/v1/errors", "enabled": false, "status": 503, "version": 0}
+
+	// Act & Assert
+	admin.Put("/admin/toggles").JSON(disable).ExpectStatus(http.StatusOK).ExpectJSONPath("version", 1)
+	client.Get("/api/v1/errors").ExpectStatus(http.StatusServiceUnavailable)
+	// Writers must present the current version
+	admin.Put("/admin/toggles").JSON(disable).ExpectStatus(http.StatusConflict)
+
+	// Act & Assert - reset the override
+	admin.Delete("/admin/toggles").
+		WithQuery("kind", "route").
+		WithQuery("name", "GET /api/v1/errors").
+		WithQuery("version", "1").
+		ExpectStatus(http.StatusNoContent)
+	client.Get("/api/v1/errors").ExpectStatus(http.StatusOK)
+
+	var changes []handlers.ToggleChangeResponse
+	admin.Get("/admin/toggles/changes").ExpectStatus(http.StatusOK).Decode(&changes)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "reset", changes[0].Action)
+	assert.Equal(t, "set", changes[1].Action)
+	require.NotNil(t, changes[1].After)
+	assert.Equal(t, 503, changes[1].After.Status)
+}
+
+func TestToggles_PersistAcrossRestarts(t *testing.T) {
+	// Arrange
+	store := filepath.Join(t.TempDir(), "store.db")
+	configure := func(cfg *config.Config) {
+		cfg.AdminToken = "secret"
+		cfg.EmbeddedStorePath = store
+	}
+	first := harness.NewTestServer(t, nil, configure)
+	testkit.New(t, first.URL).Put("/admin/toggles").WithAuth("secret").
+		JSON(map[string]any{"kind": "feature", "name": "bulk_import", "enabled": true, "version": 0}).
+		ExpectStatus(http.StatusOK)
+	require.NoError(t, first.Container.Store.Close())
+
+	// Act
+	second := harness.NewTestServer(t, nil, configure)
+	resp := testkit.New(t, second.URL).Get("/admin/toggles").WithAuth("secret").ExpectStatus(http.StatusOK)
+
+	// Assert
+	assert.Len(t, resp.JSONPath(""), 1)
+	resp.ExpectJSONPath("0.name", "bulk_import").
+		ExpectJSONPath("0.enabled", true).
+		ExpectJSONPath("0.version", 1)
+	assert.True(t, second.Container.Toggles.Features()["bulk_import"])
+}
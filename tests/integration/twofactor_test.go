@@ -0,0 +1,57 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/twofactor"
+	"github.com/luminosita/change-me/pkg/totp"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Two-Factor Tests
+// ====================
+
+func TestTwoFactor_RedisLockoutSharedAcrossInstances(t *testing.T) {
+	// Arrange - skipped automatically without a container runtime
+	infra := harness.StartInfra(t, harness.WithRedis())
+	withEncryption := func(cfg *config.Config) {
+		cfg.EncryptionKeys = []string{"k1=" + strings.Repeat("A", 43) + "="}
+		cfg.EncryptionPrimaryKeyID = "k1"
+	}
+	first := harness.NewTestServer(t, infra, withEncryption)
+	second := harness.NewTestServer(t, infra, withEncryption)
+	require.NotNil(t, first.Container.TwoFactor)
+	ctx := context.Background()
+
+	provisioning, err := first.Container.TwoFactor.Enroll(ctx, "jane", "jane@example.com")
+	require.NoError(t, err)
+	code, err := totp.Code(provisioning.Secret, time.Now(), totp.Options{})
+	require.NoError(t, err)
+	backup, err := first.Container.TwoFactor.Confirm(ctx, "jane", code)
+	require.NoError(t, err)
+
+	// Act - spread wrong codes over both instances
+	for i := 0; i < twofactor.DefaultMaxAttempts; i++ {
+		service := first.Container.TwoFactor
+		if i%2 == 1 {
+			service = second.Container.TwoFactor
+		}
+		assert.ErrorIs(t, service.Verify(ctx, "jane", "000000"), twofactor.ErrInvalidCode)
+	}
+
+	// Assert
+	status, err := second.Container.TwoFactor.Status(ctx, "jane")
+	require.NoError(t, err)
+	assert.True(t, status.Enabled, "enrollments are visible to every instance")
+	assert.ErrorIs(t, second.Container.TwoFactor.Verify(ctx, "jane", backup[0]), twofactor.ErrLocked)
+	assert.ErrorIs(t, first.Container.TwoFactor.Verify(ctx, "jane", backup[0]), twofactor.ErrLocked)
+}
@@ -0,0 +1,178 @@
+//go:build integration
+
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/harness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============
+// Upload Tests
+// ============
+
+// fakeClamd serves the clamd INSTREAM protocol, flagging content
+// containing "EICAR".
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if _, err := r.ReadString(0); err != nil {
+					return
+				}
+				var content bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil || size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&content, r, int64(size)); err != nil {
+						return
+					}
+				}
+				reply := "stream: OK"
+				if bytes.Contains(content.Bytes(), []byte("EICAR")) {
+					reply = "stream: Eicar-Test-Signature FOUND"
+				}
+				_, _ = io.WriteString(conn, reply+"\x00")
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func upload(t *testing.T, url, name, content string) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, _ = io.WriteString(part, content)
+	require.NoError(t, form.Close())
+
+	resp, err := http.Post(url+"/api/v1/uploads", form.FormDataContentType(), &body)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestUploads_ScannedBeforeStorage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.ObjectStorageProvider = "local"
+		cfg.ObjectStorageDir = dir
+		cfg.ObjectStoragePublicURL = "/api/v1/files"
+		cfg.ObjectStorageSigningKey = "test-signing-key"
+		cfg.MalwareScanner = "clamd"
+		cfg.MalwareClamdAddress = fakeClamd(t)
+	})
+
+	// Act
+	clean := upload(t, ts.URL, "notes.txt", "hello")
+	defer clean.Body.Close()
+	infected := upload(t, ts.URL, "virus.com", `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR`)
+	defer infected.Body.Close()
+
+	// Assert - clean files are stored and downloadable
+	require.Equal(t, http.StatusCreated, clean.StatusCode)
+	var stored handlers.UploadResponse
+	require.NoError(t, json.NewDecoder(clean.Body).Decode(&stored))
+	assert.Equal(t, "notes.txt", stored.Name)
+	assert.Equal(t, int64(5), stored.Size)
+
+	download, err := http.Get(ts.URL + stored.DownloadURL)
+	require.NoError(t, err)
+	defer download.Body.Close()
+	require.Equal(t, http.StatusOK, download.StatusCode)
+	content, err := io.ReadAll(download.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	// Assert - infected files are rejected and quarantined
+	assert.Equal(t, http.StatusBadRequest, infected.StatusCode)
+	var errResp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(infected.Body).Decode(&errResp))
+	assert.Equal(t, "file_infected", errResp.Code)
+
+	quarantined, err := filepath.Glob(filepath.Join(dir, "quarantine", "uploads", "*", "virus.com"))
+	require.NoError(t, err)
+	assert.Len(t, quarantined, 1)
+	stray, err := filepath.Glob(filepath.Join(dir, "uploads", "*", "virus.com"))
+	require.NoError(t, err)
+	assert.Empty(t, stray)
+}
+
+func TestUploads_ScannerUnavailable(t *testing.T) {
+	// Arrange - nothing listens on the clamd address
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	newServer := func(failOpen bool) *harness.TestServer {
+		return harness.NewTestServer(t, nil, func(cfg *config.Config) {
+			cfg.ObjectStorageProvider = "local"
+			cfg.ObjectStorageDir = t.TempDir()
+			cfg.MalwareScanner = "clamd"
+			cfg.MalwareClamdAddress = address
+			cfg.MalwareFailOpen = failOpen
+		})
+	}
+
+	// Act
+	closed := upload(t, newServer(false).URL, "notes.txt", "hello")
+	defer closed.Body.Close()
+	open := upload(t, newServer(true).URL, "notes.txt", "hello")
+	defer open.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, closed.StatusCode)
+	var errResp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(closed.Body).Decode(&errResp))
+	assert.Equal(t, "malware_scan_unavailable", errResp.Code)
+	assert.Equal(t, http.StatusCreated, open.StatusCode)
+}
+
+func TestUploads_RejectsFilesAboveLimit(t *testing.T) {
+	// Arrange
+	ts := harness.NewTestServer(t, nil, func(cfg *config.Config) {
+		cfg.ObjectStorageProvider = "local"
+		cfg.ObjectStorageDir = t.TempDir()
+		cfg.UploadsMaxSize = 4
+	})
+
+	// Act
+	resp := upload(t, ts.URL, "notes.txt", "hello")
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	var errResp handlers.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, "upload_too_large", errResp.Code)
+}
@@ -0,0 +1,220 @@
+package mocks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/pkg/clock"
+)
+
+// ====================
+// Fake Clock
+// ====================
+
+// FakeClockEpoch is the time a FakeClock starts at by default.
+var FakeClockEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// FakeClock is a clock.Clock that only moves when the test moves it.
+// Tickers and timers fire while Advance passes their deadlines, in
+// deadline order, so their channels hold a tick once Advance returns. Like
+// time.Ticker, ticks are dropped when the channel already holds one.
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond // Signaled when waiters are added
+	now     time.Time
+	waiters map[*fakeWaiter]struct{}
+}
+
+// fakeWaiter is a pending ticker or timer.
+type fakeWaiter struct {
+	clock    *FakeClock
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration // Zero for timers
+}
+
+// NewFakeClock creates a fake clock at start, or at FakeClockEpoch when
+// start is zero.
+//
+// Parameters:
+//   - start: Initial time (optional)
+//
+// Returns:
+//   - *FakeClock: Stopped clock
+func NewFakeClock(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = FakeClockEpoch
+	}
+	c := &FakeClock{now: start, waiters: make(map[*fakeWaiter]struct{})}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now implements clock.Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since implements clock.Clock.
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// NewTicker implements clock.Clock. It panics if d is not positive, as
+// time.NewTicker does.
+func (c *FakeClock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("mocks: non-positive interval for FakeClock.NewTicker")
+	}
+	return &fakeTicker{c.add(d, d)}
+}
+
+// NewTimer implements clock.Clock.
+func (c *FakeClock) NewTimer(d time.Duration) clock.Timer {
+	return &fakeTimer{c.add(d, 0)}
+}
+
+// Advance moves the clock forward by d, firing the tickers and timers due
+// on the way.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		next := c.nextDue(target)
+		if next == nil {
+			break
+		}
+		c.now = next.deadline
+		next.fire(c.now)
+	}
+	c.now = target
+}
+
+// Set moves the clock to t. Moving forward fires the tickers and timers
+// due on the way, as Advance does; moving back fires nothing.
+func (c *FakeClock) Set(t time.Time) {
+	c.Advance(t.Sub(c.Now()))
+}
+
+// Waiters returns the number of active tickers and timers.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n tickers and timers are active, e.g.
+// until a goroutine under test created its ticker, so the next Advance
+// is not missed.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.changed.Wait()
+	}
+}
+
+// add registers a waiter due after d.
+func (c *FakeClock) add(d, period time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1), deadline: c.now.Add(d), period: period}
+	if d <= 0 {
+		w.fire(c.now)
+	} else {
+		c.schedule(w)
+	}
+	return w
+}
+
+// schedule (re)activates w; c.mu must be held.
+func (c *FakeClock) schedule(w *fakeWaiter) {
+	c.waiters[w] = struct{}{}
+	c.changed.Broadcast()
+}
+
+// nextDue returns the waiter with the earliest deadline not after target;
+// c.mu must be held.
+func (c *FakeClock) nextDue(target time.Time) *fakeWaiter {
+	var next *fakeWaiter
+	for w := range c.waiters {
+		if !w.deadline.After(target) && (next == nil || w.deadline.Before(next.deadline)) {
+			next = w
+		}
+	}
+	return next
+}
+
+// fire delivers a tick at now and reschedules tickers; c.mu must be held.
+func (w *fakeWaiter) fire(now time.Time) {
+	select {
+	case w.c <- now:
+	default:
+	}
+	if w.period > 0 {
+		w.deadline = now.Add(w.period)
+		return
+	}
+	delete(w.clock.waiters, w)
+}
+
+// stop deactivates w and reports whether it was active.
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	_, active := w.clock.waiters[w]
+	delete(w.clock.waiters, w)
+	return active
+}
+
+// reset reschedules w after d and reports whether it was active.
+func (w *fakeWaiter) reset(d time.Duration) bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, active := c.waiters[w]
+	w.deadline = c.now.Add(d)
+	if w.period > 0 {
+		w.period = d
+	}
+	if d <= 0 && w.period == 0 {
+		delete(c.waiters, w)
+		w.fire(c.now)
+	} else {
+		c.schedule(w)
+	}
+	return active
+}
+
+// fakeTicker is the clock.Ticker of a FakeClock.
+type fakeTicker struct{ w *fakeWaiter }
+
+// C implements clock.Ticker.
+func (t *fakeTicker) C() <-chan time.Time { return t.w.c }
+
+// Reset implements clock.Ticker.
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("mocks: non-positive interval for FakeClock ticker Reset")
+	}
+	t.w.reset(d)
+}
+
+// Stop implements clock.Ticker.
+func (t *fakeTicker) Stop() { t.w.stop() }
+
+// fakeTimer is the clock.Timer of a FakeClock.
+type fakeTimer struct{ w *fakeWaiter }
+
+// C implements clock.Timer.
+func (t *fakeTimer) C() <-chan time.Time { return t.w.c }
+
+// Reset implements clock.Timer.
+func (t *fakeTimer) Reset(d time.Duration) bool { return t.w.reset(d) }
+
+// Stop implements clock.Timer.
+func (t *fakeTimer) Stop() bool { return t.w.stop() }
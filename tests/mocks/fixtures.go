@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/luminosita/change-me/internal/config"
+	"github.com/luminosita/change-me/internal/core/seed/fixtures"
 	"github.com/luminosita/change-me/pkg/logger"
 	"github.com/stretchr/testify/mock"
 )
@@ -82,13 +83,47 @@ func (m *MockLogger) Sync() error {
 // Use functional options to override specific fields.
 func NewTestConfig(opts ...func(*config.Config)) *config.Config {
 	cfg := &config.Config{
-		AppName:    "TestApp",
-		AppVersion: "0.1.0",
-		Debug:      true,
-		Host:       "127.0.0.1",
-		Port:       8080,
-		LogLevel:   "INFO",
-		LogFormat:  "json",
+		AppName:     "TestApp",
+		AppVersion:  "0.1.0",
+		Debug:       true,
+		Environment: "test",
+		Host:        "127.0.0.1",
+		Port:        8080,
+		LogLevel:    "INFO",
+		LogFormat:   "json",
+
+		DefaultLocale:   "en",
+		DefaultTimezone: "UTC",
+
+		ServerShutdownTimeout: 30 * time.Second,
+		ServerMaxHeaderBytes:  1 << 20,
+		ServerKeepAlive:       true,
+
+		PriorityAdminConcurrency:  16,
+		PriorityPublicConcurrency: 256,
+
+		RouteFlagsReloadInterval: 10 * time.Second,
+		SLOEvaluationInterval:    30 * time.Second,
+		SyntheticInterval:        time.Minute,
+		SyntheticTimeout:         10 * time.Second,
+		PaginationMaxPageSize:    100,
+		RefreshTokenTTL:          7 * 24 * time.Hour,
+		AccessTokenTTL:           15 * time.Minute,
+		SearchRetainVersions:     2,
+		NotificationsConcurrency: 4,
+		NotificationsQueueSize:   1000,
+		ReportsConcurrency:       2,
+		ReportsMaxPending:        100,
+		ReportsRetention:         1000,
+		ReportsLinkTTL:           15 * time.Minute,
+		UploadsMaxSize:           10 << 20,
+		UploadsLinkTTL:           15 * time.Minute,
+		MalwareScanTimeout:       30 * time.Second,
+		MalwareQuarantine:        true,
+		PrivacyDeletionGrace:     30 * 24 * time.Hour,
+		PrivacyExportTTL:         24 * time.Hour,
+		PrivacyLinkTTL:           15 * time.Minute,
+		PrivacySweepInterval:     time.Minute,
 	}
 
 	for _, opt := range opts {
@@ -155,56 +190,17 @@ func NewMockHTTPClient(transport *MockRoundTripper) *http.Client {
 // Sample Data Fixtures
 // ====================
 
-// SampleUserData represents sample user data for testing.
-type SampleUserData struct {
-	ID       int
-	Email    string
-	Username string
-	FullName string
-	IsActive bool
-}
-
-// NewSampleUser creates sample user data with defaults.
-func NewSampleUser(opts ...func(*SampleUserData)) *SampleUserData {
-	user := &SampleUserData{
-		ID:       1,
-		Email:    "test@example.com",
-		Username: "testuser",
-		FullName: "Test User",
-		IsActive: true,
-	}
-
-	for _, opt := range opts {
-		opt(user)
-	}
-
-	return user
-}
-
-// WithUserID sets the user ID.
-func WithUserID(id int) func(*SampleUserData) {
-	return func(u *SampleUserData) {
-		u.ID = id
-	}
-}
+// The sample data lives in internal/core/seed/fixtures so seeders can use
+// it without pulling in test dependencies.
 
-// WithUserEmail sets the user email.
-func WithUserEmail(email string) func(*SampleUserData) {
-	return func(u *SampleUserData) {
-		u.Email = email
-	}
-}
-
-// WithUsername sets the username.
-func WithUsername(username string) func(*SampleUserData) {
-	return func(u *SampleUserData) {
-		u.Username = username
-	}
-}
-
-// WithUserActive sets the active status.
-func WithUserActive(active bool) func(*SampleUserData) {
-	return func(u *SampleUserData) {
-		u.IsActive = active
-	}
-}
+// SampleUserData represents sample user data for testing.
+type SampleUserData = fixtures.SampleUser
+
+// Sample user factory and options.
+var (
+	NewSampleUser  = fixtures.NewSampleUser
+	WithUserID     = fixtures.WithUserID
+	WithUserEmail  = fixtures.WithUserEmail
+	WithUsername   = fixtures.WithUsername
+	WithUserActive = fixtures.WithUserActive
+)
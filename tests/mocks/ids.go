@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/luminosita/change-me/pkg/ids"
+)
+
+// ====================
+// Sequential IDs
+// ====================
+
+// SequentialIDs is an ids.Generator making IDs 1, 2, 3... in their last
+// bytes, so tests can assert IDs without depending on time or chance:
+// the first UUID is "00000000-0000-0000-0000-000000000001".
+type SequentialIDs struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewSequentialIDs creates a generator whose first ID is 1.
+func NewSequentialIDs() *SequentialIDs {
+	return &SequentialIDs{next: 1}
+}
+
+// Generate implements ids.Generator.
+func (s *SequentialIDs) Generate() ids.Raw {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var raw ids.Raw
+	binary.BigEndian.PutUint64(raw[8:], s.next)
+	s.next++
+	return raw
+}
+
+var _ ids.Generator = (*SequentialIDs)(nil)
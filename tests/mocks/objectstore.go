@@ -0,0 +1,95 @@
+package mocks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luminosita/change-me/internal/core/objectstore"
+)
+
+// ====================
+// In-Memory Object Store
+// ====================
+
+// ObjectStore is an in-memory objectstore.Store. Its links point at
+// https://files.example.com/<key> and never expire.
+type ObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// NewObjectStore creates an empty store.
+func NewObjectStore() *ObjectStore {
+	return &ObjectStore{objects: make(map[string][]byte)}
+}
+
+// Put implements objectstore.Store.
+func (s *ObjectStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	data, err := io.ReadAll(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return err
+}
+
+// URL implements objectstore.Store.
+func (s *ObjectStore) URL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return "https://files.example.com/" + key, nil
+}
+
+// Delete implements objectstore.Store.
+func (s *ObjectStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// List implements objectstore.Lister.
+func (s *ObjectStore) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Get implements objectstore.Lister.
+func (s *ObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, objectstore.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Object returns the data stored under key, or nil.
+func (s *ObjectStore) Object(key string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[key]
+}
+
+// Len returns the number of stored objects.
+func (s *ObjectStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+var (
+	_ objectstore.Store  = (*ObjectStore)(nil)
+	_ objectstore.Lister = (*ObjectStore)(nil)
+)
@@ -0,0 +1,482 @@
+// Package stubserver runs a programmable HTTP stub standing in for an
+// upstream service in integration tests, in the spirit of WireMock.
+// Stubs match requests by method, path pattern, headers, query and body
+// and answer with canned or templated responses or injected faults;
+// received requests are recorded for verification:
+//
+//	stub := stubserver.New(t)
+//	stub.On(stubserver.GET("/v1/charges/{id}").WithHeader("Authorization", "Bearer key")).
+//		Reply(http.StatusOK).Template(`{"id":"{{.Params.id}}"}`)
+//	stub.On(stubserver.POST("/v1/charges")).Fault(stubserver.FaultConnectionReset)
+//
+//	cfg.HTTPClients = []httpclient.Config{{Name: "billing", Timeout: time.Second}}
+//	// ... exercise code calling stub.URL with the "billing" client ...
+//
+//	stub.Verify(stubserver.POST("/v1/charges"), 1)
+//
+// Requests matching no stub are answered 404 and reported by Unmatched.
+package stubserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Fault is a broken response injected instead of a reply.
+type Fault string
+
+// Injectable faults.
+const (
+	FaultConnectionReset   Fault = "connection_reset"   // Close the connection with a TCP reset
+	FaultEmptyResponse     Fault = "empty_response"     // Close the connection without answering
+	FaultMalformedResponse Fault = "malformed_response" // Answer bytes that are not HTTP
+)
+
+// Server is a running stub server, closed on test cleanup. It is safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+
+	t         testing.TB
+	mu        sync.Mutex
+	stubs     []*Stub
+	requests  []Request
+	unmatched []Request
+}
+
+// New starts a stub server without stubs.
+//
+// Parameters:
+//   - t: Test owning the server
+//
+// Returns:
+//   - *Server: Running server; URL is its base URL
+func New(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{t: t}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// On adds a stub answering requests matched by m. Stubs added later take
+// precedence, so a test can override a default stub. Without a reply the
+// stub answers 200 with an empty body.
+func (s *Server) On(m Matcher) *Stub {
+	stub := &Stub{matcher: m, status: http.StatusOK, header: http.Header{}}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stubs = append(s.stubs, stub)
+	return stub
+}
+
+// Reset removes the stubs and the recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stubs, s.requests, s.unmatched = nil, nil, nil
+}
+
+// Requests returns the received requests matched by m, in arrival order.
+func (s *Server) Requests(m Matcher) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Request
+	for _, req := range s.requests {
+		if _, ok := m.match(req); ok {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// Verify checks that n received requests were matched by m.
+func (s *Server) Verify(m Matcher, n int) {
+	s.t.Helper()
+	assert.Len(s.t, s.Requests(m), n, "requests matching %s", m)
+}
+
+// Unmatched returns the received requests no stub matched.
+func (s *Server) Unmatched() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.unmatched...)
+}
+
+// RequireNoUnmatched fails the test when a request matched no stub.
+func (s *Server) RequireNoUnmatched() {
+	s.t.Helper()
+	unmatched := s.Unmatched()
+	descriptions := make([]string, len(unmatched))
+	for i, req := range unmatched {
+		descriptions[i] = req.Method + " " + req.Path
+	}
+	require.Empty(s.t, descriptions, "requests matched no stub")
+}
+
+// serve answers a request with the newest matching stub.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: body}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	var stub *Stub
+	var params map[string]string
+	for i := len(s.stubs) - 1; i >= 0; i-- {
+		candidate := s.stubs[i]
+		if p, ok := candidate.matcher.match(req); ok && candidate.take() {
+			stub, params = candidate, p
+			break
+		}
+	}
+	if stub == nil {
+		s.unmatched = append(s.unmatched, req)
+	}
+	s.mu.Unlock()
+
+	if stub == nil {
+		http.Error(w, fmt.Sprintf("stubserver: no stub matches %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+		return
+	}
+	stub.respond(w, r, req, params)
+}
+
+// Request is a received request.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the request body into out.
+func (r Request) JSON(out any) error {
+	return json.Unmarshal(r.Body, out)
+}
+
+// Matcher selects requests. The zero Matcher matches every request.
+type Matcher struct {
+	method   string
+	pattern  string
+	header   http.Header
+	query    url.Values
+	contains []string
+	json     any
+}
+
+// Match matches requests with method, or any method when empty, whose
+// path matches pattern. Pattern segments in braces, as in
+// "/users/{id}", match any segment and are available to templates as
+// Params; a final "*" matches the rest of the path. An empty pattern
+// matches every path.
+func Match(method, pattern string) Matcher {
+	return Matcher{method: method, pattern: pattern}
+}
+
+// GET matches GET requests for pattern.
+func GET(pattern string) Matcher { return Match(http.MethodGet, pattern) }
+
+// POST matches POST requests for pattern.
+func POST(pattern string) Matcher { return Match(http.MethodPost, pattern) }
+
+// PUT matches PUT requests for pattern.
+func PUT(pattern string) Matcher { return Match(http.MethodPut, pattern) }
+
+// PATCH matches PATCH requests for pattern.
+func PATCH(pattern string) Matcher { return Match(http.MethodPatch, pattern) }
+
+// DELETE matches DELETE requests for pattern.
+func DELETE(pattern string) Matcher { return Match(http.MethodDelete, pattern) }
+
+// WithHeader also requires the header value.
+func (m Matcher) WithHeader(name, value string) Matcher {
+	m.header = m.header.Clone()
+	if m.header == nil {
+		m.header = http.Header{}
+	}
+	m.header.Add(name, value)
+	return m
+}
+
+// WithQuery also requires the query parameter value.
+func (m Matcher) WithQuery(name, value string) Matcher {
+	query := url.Values{}
+	for k, v := range m.query {
+		query[k] = append([]string(nil), v...)
+	}
+	query.Add(name, value)
+	m.query = query
+	return m
+}
+
+// WithBodyContaining also requires the body to contain s.
+func (m Matcher) WithBodyContaining(s string) Matcher {
+	m.contains = append(append([]string(nil), m.contains...), s)
+	return m
+}
+
+// WithJSONBody also requires the body to be JSON equal to v, regardless
+// of key order and formatting.
+func (m Matcher) WithJSONBody(v any) Matcher {
+	m.json = v
+	return m
+}
+
+// String describes the matcher for failure messages.
+func (m Matcher) String() string {
+	method, pattern := m.method, m.pattern
+	if method == "" {
+		method = "ANY"
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	return method + " " + pattern
+}
+
+// match reports whether req matches and returns the path parameters.
+func (m Matcher) match(req Request) (map[string]string, bool) {
+	if m.method != "" && m.method != req.Method {
+		return nil, false
+	}
+	params, ok := matchPath(m.pattern, req.Path)
+	if !ok {
+		return nil, false
+	}
+	for name, values := range m.header {
+		for _, v := range values {
+			if !contains(req.Header.Values(name), v) {
+				return nil, false
+			}
+		}
+	}
+	for name, values := range m.query {
+		for _, v := range values {
+			if !contains(req.Query[name], v) {
+				return nil, false
+			}
+		}
+	}
+	for _, s := range m.contains {
+		if !bytes.Contains(req.Body, []byte(s)) {
+			return nil, false
+		}
+	}
+	if m.json != nil && !jsonEqual(m.json, req.Body) {
+		return nil, false
+	}
+	return params, true
+}
+
+// matchPath matches path against pattern, capturing brace segments.
+func matchPath(pattern, path string) (map[string]string, bool) {
+	params := map[string]string{}
+	if pattern == "" {
+		return params, true
+	}
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range want {
+		if segment == "*" && i == len(want)-1 {
+			return params, true
+		}
+		if i >= len(got) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			params[segment[1:len(segment)-1]] = got[i]
+		case segment != got[i]:
+			return nil, false
+		}
+	}
+	return params, len(want) == len(got)
+}
+
+// jsonEqual reports whether body is JSON equal to want.
+func jsonEqual(want any, body []byte) bool {
+	data, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var a, b any
+	if json.Unmarshal(data, &a) != nil || json.Unmarshal(body, &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// contains reports whether values holds v.
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Stub is the response to matched requests. Its methods configure it
+// and return it for chaining; configure stubs before sending requests.
+type Stub struct {
+	matcher  Matcher
+	status   int
+	header   http.Header
+	body     []byte
+	template *template.Template
+	delay    time.Duration
+	fault    Fault
+	times    int  // Remaining matches when limited
+	limited  bool // Set by Times
+}
+
+// Reply sets the response status.
+func (s *Stub) Reply(status int) *Stub {
+	s.status = status
+	return s
+}
+
+// Header sets a response header.
+func (s *Stub) Header(name, value string) *Stub {
+	s.header.Set(name, value)
+	return s
+}
+
+// Body sets a canned response body.
+func (s *Stub) Body(body string) *Stub {
+	s.body = []byte(body)
+	return s
+}
+
+// JSON sets v encoded as the response body with a JSON content type. It
+// panics if v cannot be encoded.
+func (s *Stub) JSON(v any) *Stub {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("stubserver: encode stub body: %v", err))
+	}
+	s.body = data
+	return s.Header("Content-Type", "application/json")
+}
+
+// Template renders the response body with text/template from the
+// request: .Method, .Path, .Params (path parameters), .Query, .Header
+// and .Body (a string). It panics if text does not parse.
+func (s *Stub) Template(text string) *Stub {
+	s.template = template.Must(template.New("stub").Option("missingkey=zero").Parse(text))
+	return s
+}
+
+// Delay holds the response for d, or until the client gives up.
+func (s *Stub) Delay(d time.Duration) *Stub {
+	s.delay = d
+	return s
+}
+
+// Fault answers with a broken response instead of the reply.
+func (s *Stub) Fault(f Fault) *Stub {
+	s.fault = f
+	return s
+}
+
+// Times limits the stub to the next n matched requests, after which
+// older stubs answer, e.g. to fail twice before succeeding.
+func (s *Stub) Times(n int) *Stub {
+	s.times, s.limited = n, true
+	return s
+}
+
+// take consumes one use of a limited stub; the server mutex must be held.
+func (s *Stub) take() bool {
+	if !s.limited {
+		return true
+	}
+	if s.times <= 0 {
+		return false
+	}
+	s.times--
+	return true
+}
+
+// templateData is the request seen by response templates.
+type templateData struct {
+	Method string
+	Path   string
+	Params map[string]string
+	Query  url.Values
+	Header http.Header
+	Body   string
+}
+
+// respond writes the stub response to w.
+func (s *Stub) respond(w http.ResponseWriter, r *http.Request, req Request, params map[string]string) {
+	if s.delay > 0 {
+		timer := time.NewTimer(s.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if s.fault != "" {
+		injectFault(w, s.fault)
+		return
+	}
+
+	body := s.body
+	if s.template != nil {
+		var buf bytes.Buffer
+		data := templateData{Method: req.Method, Path: req.Path, Params: params, Query: req.Query, Header: req.Header, Body: string(req.Body)}
+		if err := s.template.Execute(&buf, data); err != nil {
+			http.Error(w, "stubserver: render template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body = buf.Bytes()
+	}
+	for name, values := range s.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(s.status)
+	_, _ = w.Write(body)
+}
+
+// injectFault breaks the connection of w as f describes.
+func injectFault(w http.ResponseWriter, f Fault) {
+	conn, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "stubserver: cannot inject fault: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+	switch f {
+	case FaultConnectionReset:
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			_ = tcp.SetLinger(0)
+		}
+	case FaultMalformedResponse:
+		_, _ = buf.WriteString("\x00\x01 not http \r\n\r\n")
+		_ = buf.Flush()
+	}
+}
@@ -0,0 +1,124 @@
+package stubserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// send performs a request against the stub and returns status and body.
+func send(t *testing.T, s *Server, method, path, body string, header http.Header) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := s.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestServer_MatchesRequests(t *testing.T) {
+	s := New(t)
+	s.On(GET("/v1/charges/{id}").WithHeader("Authorization", "Bearer key").WithQuery("expand", "customer")).
+		Reply(http.StatusOK).Body("charge")
+	s.On(POST("/v1/charges").WithJSONBody(map[string]any{"amount": 100, "currency": "eur"})).
+		Reply(http.StatusCreated)
+	s.On(Match("", "/files/*")).Reply(http.StatusNoContent)
+	auth := http.Header{"Authorization": {"Bearer key"}}
+
+	code, body := send(t, s, http.MethodGet, "/v1/charges/ch_1?expand=customer", "", auth)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "charge", body)
+	code, _ = send(t, s, http.MethodGet, "/v1/charges/ch_1", "", auth)
+	assert.Equal(t, http.StatusNotFound, code, "query parameter missing")
+	code, _ = send(t, s, http.MethodGet, "/v1/charges/ch_1/refunds?expand=customer", "", auth)
+	assert.Equal(t, http.StatusNotFound, code, "extra path segment")
+	code, _ = send(t, s, http.MethodPost, "/v1/charges", `{"currency":"eur", "amount":100}`, nil)
+	assert.Equal(t, http.StatusCreated, code)
+	code, _ = send(t, s, http.MethodPut, "/files/a/b.txt", "", nil)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	assert.Len(t, s.Unmatched(), 2)
+}
+
+func TestServer_TemplatedResponses(t *testing.T) {
+	s := New(t)
+	s.On(POST("/users/{id}/greet")).
+		Header("Content-Type", "application/json").
+		Template(`{"id":"{{.Params.id}}","lang":"{{.Query.Get "lang"}}","agent":"{{.Header.Get "X-Agent"}}","echo":{{.Body}}}`)
+
+	code, body := send(t, s, http.MethodPost, "/users/42/greet?lang=de", `{"n":1}`, http.Header{"X-Agent": {"tests"}})
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"id":"42","lang":"de","agent":"tests","echo":{"n":1}}`, body)
+}
+
+func TestStub_TimesFallsBackToOlderStubs(t *testing.T) {
+	s := New(t)
+	s.On(GET("/status")).JSON(map[string]string{"status": "ok"})
+	s.On(GET("/status")).Reply(http.StatusServiceUnavailable).Times(2)
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		code, _ := send(t, s, http.MethodGet, "/status", "", nil)
+		codes = append(codes, code)
+	}
+
+	assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}, codes)
+}
+
+func TestStub_Faults(t *testing.T) {
+	s := New(t)
+	s.On(GET("/reset")).Fault(FaultConnectionReset)
+	s.On(GET("/empty")).Fault(FaultEmptyResponse)
+	s.On(GET("/garbage")).Fault(FaultMalformedResponse)
+	s.On(GET("/slow")).Delay(time.Second)
+
+	for _, path := range []string{"/reset", "/empty", "/garbage"} {
+		resp, err := s.Client().Get(s.URL + path)
+		if err == nil {
+			resp.Body.Close()
+		}
+		assert.Error(t, err, path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"/slow", nil)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = s.Client().Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestServer_VerifiesRequests(t *testing.T) {
+	s := New(t)
+	s.On(POST("/events"))
+
+	send(t, s, http.MethodPost, "/events", `{"type":"signup","user":"1"}`, nil)
+	send(t, s, http.MethodPost, "/events", `{"type":"login","user":"1"}`, nil)
+
+	s.Verify(POST("/events"), 2)
+	s.Verify(POST("/events").WithBodyContaining(`"signup"`), 1)
+	s.Verify(GET("/events"), 0)
+	s.RequireNoUnmatched()
+
+	var event map[string]string
+	require.NoError(t, s.Requests(POST("/events"))[1].JSON(&event))
+	assert.Equal(t, "login", event["type"])
+
+	s.Reset()
+	s.Verify(Match("", ""), 0)
+}
@@ -0,0 +1,207 @@
+// Package testkit provides reusable assertions and helpers for HTTP tests.
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites golden files instead of comparing against them.
+// Run with: go test ./internal/interfaces/http/handlers/ -run Golden -update
+var update = flag.Bool("update", false, "update golden files")
+
+// GoldenDir is the directory, relative to the test package, holding golden files.
+const GoldenDir = "testdata"
+
+// Placeholders substituted for volatile response values.
+const (
+	PlaceholderTimestamp = "<timestamp>"
+	PlaceholderUUID      = "<uuid>"
+	PlaceholderIgnored   = "<ignored>"
+)
+
+var (
+	timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+	uuidPattern      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Snapshot is the normalized, comparable form of an HTTP response.
+type Snapshot struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body"`
+}
+
+// replacement substitutes string values matching pattern.
+type replacement struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// goldenOptions controls response normalization.
+type goldenOptions struct {
+	headers      []string
+	ignored      map[string]struct{}
+	replacements []replacement
+}
+
+// GoldenOption configures AssertGolden.
+type GoldenOption func(*goldenOptions)
+
+// WithHeaders includes the named response headers in the snapshot.
+// Headers are excluded by default because most are transport noise.
+func WithHeaders(names ...string) GoldenOption {
+	return func(o *goldenOptions) {
+		o.headers = append(o.headers, names...)
+	}
+}
+
+// IgnoreFields replaces the values of the named JSON keys, at any depth,
+// with a fixed placeholder. Use it for volatile numbers such as durations.
+func IgnoreFields(keys ...string) GoldenOption {
+	return func(o *goldenOptions) {
+		for _, k := range keys {
+			o.ignored[k] = struct{}{}
+		}
+	}
+}
+
+// WithReplacement replaces string values fully matching pattern with placeholder.
+func WithReplacement(pattern *regexp.Regexp, placeholder string) GoldenOption {
+	return func(o *goldenOptions) {
+		o.replacements = append(o.replacements, replacement{pattern: pattern, placeholder: placeholder})
+	}
+}
+
+// AssertGolden compares resp against testdata/<name>.golden.json.
+// Timestamps (RFC 3339) and UUIDs are normalized to placeholders so golden
+// files stay stable across runs. With -update the golden file is rewritten.
+//
+// Parameters:
+//   - t: Current test
+//   - name: Golden file base name
+//   - resp: Response to snapshot (use httptest.ResponseRecorder.Result())
+//   - opts: Normalization options
+func AssertGolden(t testing.TB, name string, resp *http.Response, opts ...GoldenOption) {
+	t.Helper()
+
+	o := goldenOptions{
+		ignored: make(map[string]struct{}),
+		replacements: []replacement{
+			{pattern: timestampPattern, placeholder: PlaceholderTimestamp},
+			{pattern: uuidPattern, placeholder: PlaceholderUUID},
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	got := marshalSnapshot(t, snapshot(t, resp, &o))
+	path := filepath.Join(GoldenDir, name+".golden.json")
+
+	if *update {
+		require.NoError(t, os.MkdirAll(GoldenDir, 0o755))
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file missing; run tests with -update to create %s", path)
+
+	assert.JSONEq(t, string(want), string(got), "response differs from %s (run with -update to accept)", path)
+}
+
+// snapshot builds the normalized snapshot of resp.
+func snapshot(t testing.TB, resp *http.Response, o *goldenOptions) Snapshot {
+	t.Helper()
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+	snap := Snapshot{Status: resp.StatusCode}
+
+	if len(o.headers) > 0 {
+		snap.Headers = make(map[string]string, len(o.headers))
+		for _, h := range o.headers {
+			if v := resp.Header.Get(h); v != "" {
+				snap.Headers[http.CanonicalHeaderKey(h)] = normalizeString(v, o)
+			}
+		}
+	}
+
+	if len(raw) > 0 {
+		var body interface{}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			// Non-JSON bodies are compared verbatim
+			snap.Body = normalizeString(string(raw), o)
+		} else {
+			snap.Body = normalize(body, o)
+		}
+	}
+
+	return snap
+}
+
+// normalize walks a decoded JSON value applying ignore and replacement rules.
+func normalize(v interface{}, o *goldenOptions) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, skip := o.ignored[k]; skip {
+				val[k] = PlaceholderIgnored
+				continue
+			}
+			val[k] = normalize(val[k], o)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = normalize(val[i], o)
+		}
+		return val
+	case string:
+		return normalizeString(val, o)
+	default:
+		return val
+	}
+}
+
+// normalizeString applies replacement rules to a single string value.
+func normalizeString(s string, o *goldenOptions) string {
+	for _, r := range o.replacements {
+		if r.pattern.MatchString(s) {
+			return r.placeholder
+		}
+	}
+	return s
+}
+
+// marshalSnapshot renders the snapshot as indented JSON with a trailing newline.
+func marshalSnapshot(t testing.TB, snap Snapshot) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	require.NoError(t, enc.Encode(snap))
+	return buf.Bytes()
+}
@@ -0,0 +1,283 @@
+// Package testkit provides a fluent HTTP client for integration tests. A
+// request is built, sent and checked in one chain; failed expectations
+// are reported on the test with the response body:
+//
+//	client := testkit.New(t, ts.URL)
+//	client.Get("/health").WithAuth(token).ExpectStatus(200).ExpectJSONPath("status", "healthy")
+//
+//	var user dto.UserResponse
+//	client.Post("/api/v1/users").JSON(body).ExpectStatus(201).Decode(&user)
+//
+// Clients created with NewHandler serve requests in memory through
+// httptest instead of a listener.
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Client sends requests to one server. Its With* methods return a copy
+// carrying defaults for every request, so a base client can be shared.
+type Client struct {
+	t       testing.TB
+	baseURL string
+	http    *http.Client
+	header  http.Header
+}
+
+// New creates a client for the server at baseURL, e.g. a
+// harness.TestServer URL. Its idle connections are closed on test
+// cleanup.
+//
+// Parameters:
+//   - t: Test reporting failed expectations
+//   - baseURL: Scheme and host requests are resolved against
+//
+// Returns:
+//   - *Client: Client without default headers
+func New(t testing.TB, baseURL string) *Client {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{}}
+	t.Cleanup(client.CloseIdleConnections)
+	return &Client{t: t, baseURL: strings.TrimSuffix(baseURL, "/"), http: client, header: http.Header{}}
+}
+
+// NewHandler creates a client serving requests with handler in memory,
+// e.g. a gin router, without opening a listener.
+func NewHandler(t testing.TB, handler http.Handler) *Client {
+	t.Helper()
+	client := &http.Client{Transport: handlerTransport{handler}}
+	return &Client{t: t, baseURL: "http://testkit.local", http: client, header: http.Header{}}
+}
+
+// handlerTransport serves requests with a handler through httptest.
+type handlerTransport struct{ handler http.Handler }
+
+// RoundTrip implements http.RoundTripper.
+func (h handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	h.handler.ServeHTTP(w, req)
+	resp := w.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// WithHeader returns a copy of the client sending the header on every
+// request.
+func (c *Client) WithHeader(name, value string) *Client {
+	clone := *c
+	clone.header = c.header.Clone()
+	clone.header.Set(name, value)
+	return &clone
+}
+
+// WithAuth returns a copy of the client sending the bearer token on every
+// request.
+func (c *Client) WithAuth(token string) *Client {
+	return c.WithHeader("Authorization", "Bearer "+token)
+}
+
+// Get starts a GET request for path.
+func (c *Client) Get(path string) *Request { return c.Request(http.MethodGet, path) }
+
+// Post starts a POST request for path.
+func (c *Client) Post(path string) *Request { return c.Request(http.MethodPost, path) }
+
+// Put starts a PUT request for path.
+func (c *Client) Put(path string) *Request { return c.Request(http.MethodPut, path) }
+
+// Patch starts a PATCH request for path.
+func (c *Client) Patch(path string) *Request { return c.Request(http.MethodPatch, path) }
+
+// Delete starts a DELETE request for path.
+func (c *Client) Delete(path string) *Request { return c.Request(http.MethodDelete, path) }
+
+// Request starts a request with method for path, which may carry a query.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{client: c, method: method, path: path, header: c.header.Clone(), query: url.Values{}}
+}
+
+// Request is a request being built. It is sent by Do or the first
+// expectation.
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   io.Reader
+}
+
+// WithHeader sets a request header.
+func (r *Request) WithHeader(name, value string) *Request {
+	r.header.Set(name, value)
+	return r
+}
+
+// WithAuth sends the bearer token.
+func (r *Request) WithAuth(token string) *Request {
+	return r.WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithQuery adds a query parameter.
+func (r *Request) WithQuery(name, value string) *Request {
+	r.query.Add(name, value)
+	return r
+}
+
+// JSON sends v encoded as the JSON body.
+func (r *Request) JSON(v any) *Request {
+	r.client.t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(r.client.t, err, "encode request body")
+	return r.Body("application/json", bytes.NewReader(data))
+}
+
+// Body sends body with the content type.
+func (r *Request) Body(contentType string, body io.Reader) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+// Do sends the request and reads the response. Transport errors fail the
+// test immediately.
+func (r *Request) Do() *Response {
+	t := r.client.t
+	t.Helper()
+
+	target := r.client.baseURL + r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(r.path, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	req, err := http.NewRequest(r.method, target, r.body)
+	require.NoError(t, err, "build request")
+	req.Header = r.header
+
+	resp, err := r.client.http.Do(req)
+	require.NoError(t, err, "%s %s", r.method, r.path)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "read response body")
+
+	return &Response{Response: resp, Body: body, t: t, name: r.method + " " + r.path}
+}
+
+// ExpectStatus sends the request and checks the response status.
+func (r *Request) ExpectStatus(code int) *Response {
+	r.client.t.Helper()
+	return r.Do().ExpectStatus(code)
+}
+
+// Response is a received response. Body holds the read body in place of
+// the closed http.Response.Body.
+type Response struct {
+	*http.Response
+	Body []byte
+
+	t    testing.TB
+	name string // Method and path, for failure messages
+}
+
+// ExpectStatus checks the response status.
+func (r *Response) ExpectStatus(code int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, code, r.StatusCode, "%s: unexpected status; body: %s", r.name, r.Body)
+	return r
+}
+
+// ExpectHeader checks a response header.
+func (r *Response) ExpectHeader(name, value string) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, value, r.Header.Get(name), "%s: header %s", r.name, name)
+	return r
+}
+
+// ExpectBodyContains checks that the body contains s.
+func (r *Response) ExpectBodyContains(s string) *Response {
+	r.t.Helper()
+	assert.Contains(r.t, string(r.Body), s, "%s: body", r.name)
+	return r
+}
+
+// ExpectJSONPath checks the JSON body value at path (see JSONPath).
+// Expected values compare as their JSON encoding, so an int matches a
+// JSON number and a struct matches an object with the same fields.
+func (r *Response) ExpectJSONPath(path string, want any) *Response {
+	r.t.Helper()
+	got, err := r.lookup(path)
+	if !assert.NoError(r.t, err, "%s: body: %s", r.name, r.Body) {
+		return r
+	}
+	data, err := json.Marshal(want)
+	require.NoError(r.t, err, "encode expected value")
+	var normalized any
+	require.NoError(r.t, json.Unmarshal(data, &normalized))
+	assert.Equal(r.t, normalized, got, "%s: JSON path %q", r.name, path)
+	return r
+}
+
+// JSONPath returns the JSON body value at path, a dot-separated list of
+// object keys and array indexes such as "users.0.email". An empty path
+// is the whole body. Missing values fail the test.
+func (r *Response) JSONPath(path string) any {
+	r.t.Helper()
+	got, err := r.lookup(path)
+	assert.NoError(r.t, err, "%s: body: %s", r.name, r.Body)
+	return got
+}
+
+// Decode decodes the JSON body into out.
+func (r *Response) Decode(out any) *Response {
+	r.t.Helper()
+	require.NoError(r.t, json.Unmarshal(r.Body, out), "%s: decode body: %s", r.name, r.Body)
+	return r
+}
+
+// lookup resolves path in the JSON body.
+func (r *Response) lookup(path string) (any, error) {
+	var value any
+	if err := json.Unmarshal(r.Body, &value); err != nil {
+		return nil, fmt.Errorf("body is not JSON: %w", err)
+	}
+	if path == "" {
+		return value, nil
+	}
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		at := strings.Join(keys[:i+1], ".")
+		switch node := value.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("no JSON path %q", at)
+			}
+			value = v
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("no JSON path %q: %d elements", at, len(node))
+			}
+			value = node[idx]
+		default:
+			return nil, fmt.Errorf("no JSON path %q: %T is not an object or array", at, node)
+		}
+	}
+	return value, nil
+}
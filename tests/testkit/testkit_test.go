@@ -0,0 +1,102 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT records failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) FailNow() {}
+
+// newRouter returns a router echoing the request on /echo.
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/echo", func(c *gin.Context) {
+		var body any
+		_ = c.ShouldBindJSON(&body)
+		c.Header("X-Method", c.Request.Method)
+		c.JSON(http.StatusOK, gin.H{
+			"auth":  c.GetHeader("Authorization"),
+			"tag":   c.GetHeader("X-Tag"),
+			"query": c.Query("q"),
+			"body":  body,
+			"items": []gin.H{{"id": 1}, {"id": 2}},
+		})
+	})
+	return router
+}
+
+func TestClient_BuildsRequestsAndChecksResponses(t *testing.T) {
+	client := NewHandler(t, newRouter()).WithHeader("X-Tag", "suite")
+
+	resp := client.Post("/echo").
+		WithAuth("secret").
+		WithQuery("q", "a b").
+		JSON(map[string]any{"name": "Ada"}).
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("X-Method", http.MethodPost).
+		ExpectJSONPath("auth", "Bearer secret").
+		ExpectJSONPath("tag", "suite").
+		ExpectJSONPath("query", "a b").
+		ExpectJSONPath("body.name", "Ada").
+		ExpectJSONPath("items.1.id", 2).
+		ExpectJSONPath("items", []map[string]int{{"id": 1}, {"id": 2}}).
+		ExpectBodyContains(`"name":"Ada"`)
+
+	var decoded struct {
+		Items []struct{ ID int } `json:"items"`
+	}
+	resp.Decode(&decoded)
+	assert.Len(t, decoded.Items, 2)
+	assert.Equal(t, "Ada", resp.JSONPath("body.name"))
+}
+
+func TestClient_WithHeaderDoesNotChangeBaseClient(t *testing.T) {
+	base := NewHandler(t, newRouter())
+	authed := base.WithAuth("secret")
+
+	authed.Get("/echo").Do().ExpectJSONPath("auth", "Bearer secret")
+	base.Get("/echo").Do().ExpectJSONPath("auth", "")
+}
+
+func TestResponse_ReportsFailedExpectations(t *testing.T) {
+	rec := &recordingT{TB: t}
+	client := NewHandler(rec, newRouter())
+
+	client.Get("/echo").
+		ExpectStatus(http.StatusCreated).
+		ExpectJSONPath("items.5.id", 1).
+		ExpectJSONPath("auth.token", "x").
+		ExpectJSONPath("query", "other")
+
+	require.Len(t, rec.failures, 4)
+	assert.Contains(t, rec.failures[0], "GET /echo: unexpected status")
+	assert.Contains(t, rec.failures[1], `no JSON path "items.5": 2 elements`)
+	assert.Contains(t, rec.failures[2], `no JSON path "auth.token": string is not an object or array`)
+	assert.Contains(t, rec.failures[3], `GET /echo: JSON path "query"`)
+}
+
+func TestNew_SendsOverTheNetwork(t *testing.T) {
+	srv := httptest.NewServer(newRouter())
+	defer srv.Close()
+
+	New(t, srv.URL+"/").Get("/echo?q=1").ExpectStatus(http.StatusOK).ExpectJSONPath("query", "1")
+}
@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/users"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/internal/interfaces/http/middleware"
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Deadline Propagation Tests
+// ====================
+
+// blockingRepository answers GetByID only when the query context ends,
+// like a database stalled on a lock.
+type blockingRepository struct {
+	users.Repository
+	queried  chan struct{}
+	deadline time.Time
+	err      error
+}
+
+func (r *blockingRepository) GetByID(ctx context.Context, _ users.UserID) (*users.User, error) {
+	r.deadline, _ = ctx.Deadline()
+	close(r.queried)
+	<-ctx.Done()
+	r.err = ctx.Err()
+	return nil, r.err
+}
+
+// deadlineRouter serves GET /users/:id with the request timeout and the
+// repository budget.
+func deadlineRouter(t *testing.T, repo users.Repository, timeout time.Duration, budget float64) *gin.Engine {
+	t.Helper()
+	log, err := mocks.NewTestLogger()
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Timeout(timeout))
+	handler := handlers.NewUserHandler(users.NewService(users.NewDeadlineRepository(repo, budget)), log)
+	router.GET("/users/:id", handler.Get)
+	return router
+}
+
+func TestDeadline_RepositoryQueryStopsAtItsBudget(t *testing.T) {
+	// Arrange
+	repo := &blockingRepository{Repository: memory.NewUserRepository(nil), queried: make(chan struct{})}
+	router := deadlineRouter(t, repo, 400*time.Millisecond, 0.5)
+	req := httptest.NewRequest(http.MethodGet, "/users/00000000-0000-0000-0000-000000000001", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	start := time.Now()
+	router.ServeHTTP(rec, req)
+	elapsed := time.Since(start)
+
+	// Assert - the query got half the request deadline and the handler
+	// answered while the request was still in time
+	assert.ErrorIs(t, repo.err, context.DeadlineExceeded)
+	assert.WithinDuration(t, start.Add(200*time.Millisecond), repo.deadline, 50*time.Millisecond)
+	assert.Less(t, elapsed, 400*time.Millisecond)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	var body handlers.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "deadline_exceeded", body.Code)
+}
+
+func TestDeadline_ClientDisconnectCancelsRepositoryQuery(t *testing.T) {
+	// Arrange
+	repo := &blockingRepository{Repository: memory.NewUserRepository(nil), queried: make(chan struct{})}
+	router := deadlineRouter(t, repo, time.Minute, 0.8)
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/users/00000000-0000-0000-0000-000000000001", nil).WithContext(ctx)
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		defer close(done)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	<-repo.queried
+	cancel()
+
+	// Assert
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("query not cancelled with the request")
+	}
+	assert.ErrorIs(t, repo.err, context.Canceled)
+}
+
+func TestDeadline_ExpiredRequestSkipsRepository(t *testing.T) {
+	// Arrange
+	repo := memory.NewUserRepository(nil)
+	ann := &users.User{Email: "ann@example.com", Username: "ann"}
+	require.NoError(t, repo.Create(context.Background(), ann))
+	guarded := users.NewDeadlineRepository(repo, 0.8)
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Millisecond))
+	defer cancel()
+
+	// Act
+	_, err := guarded.GetByID(ctx, ann.ID)
+	user, unbounded := guarded.GetByID(context.Background(), ann.ID)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, unbounded, "queries outside a request deadline are unbounded")
+	assert.Equal(t, "ann", user.Username)
+}
@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luminosita/change-me/internal/core/sessions"
+	"github.com/luminosita/change-me/internal/core/worker"
+	"github.com/luminosita/change-me/internal/infrastructure/persistence/memory"
+	"github.com/luminosita/change-me/internal/interfaces/http/handlers"
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ====================
+// Fake Clock Tests
+// ====================
+
+// ticks drains the ticks waiting on c.
+func ticks(c <-chan time.Time) []time.Time {
+	var out []time.Time
+	for {
+		select {
+		case tick := <-c:
+			out = append(out, tick)
+		default:
+			return out
+		}
+	}
+}
+
+func TestFakeClock_AdvanceAndSet(t *testing.T) {
+	// Arrange
+	clk := mocks.NewFakeClock(time.Time{})
+	start := clk.Now()
+
+	// Act
+	clk.Advance(90 * time.Second)
+	advanced := clk.Since(start)
+	clk.Set(mocks.FakeClockEpoch.Add(-time.Hour))
+
+	// Assert
+	assert.Equal(t, mocks.FakeClockEpoch, start)
+	assert.Equal(t, 90*time.Second, advanced)
+	assert.Equal(t, mocks.FakeClockEpoch.Add(-time.Hour), clk.Now(), "the clock can travel back")
+}
+
+func TestFakeClock_TickersAndTimers(t *testing.T) {
+	// Arrange
+	clk := mocks.NewFakeClock(time.Time{})
+	ticker := clk.NewTicker(time.Minute)
+	timer := clk.NewTimer(90 * time.Second)
+
+	// Act & Assert - nothing fires before its deadline
+	clk.Advance(59 * time.Second)
+	assert.Empty(t, ticks(ticker.C()))
+	assert.Empty(t, ticks(timer.C()))
+
+	// Act & Assert - ticks fire at their deadline, not at the target time
+	clk.Advance(time.Second)
+	assert.Equal(t, []time.Time{mocks.FakeClockEpoch.Add(time.Minute)}, ticks(ticker.C()))
+	clk.Advance(time.Minute)
+	assert.Equal(t, []time.Time{mocks.FakeClockEpoch.Add(90 * time.Second)}, ticks(timer.C()))
+	assert.Len(t, ticks(ticker.C()), 1)
+	assert.False(t, timer.Stop(), "fired timers are no longer active")
+
+	// Act & Assert - unread ticks are dropped like time.Ticker does
+	clk.Advance(5 * time.Minute)
+	assert.Len(t, ticks(ticker.C()), 1)
+
+	// Act & Assert - stopped tickers fire no more, reset timers fire again
+	ticker.Stop()
+	assert.False(t, timer.Reset(time.Second))
+	clk.Advance(time.Hour)
+	assert.Empty(t, ticks(ticker.C()))
+	assert.Len(t, ticks(timer.C()), 1)
+	assert.Zero(t, clk.Waiters())
+}
+
+func TestFakeClock_DrivesHealthUptime(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	clk := mocks.NewFakeClock(time.Time{})
+	router := gin.New()
+	router.GET("/health", handlers.NewHealthHandler("0.1.0").WithClock(clk).Check)
+	clk.Advance(2 * time.Hour)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	// Assert
+	var resp handlers.HealthCheckResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(7200), resp.UptimeSeconds)
+	assert.Equal(t, "2024-01-01T02:00:00Z", resp.Timestamp)
+}
+
+func TestFakeClock_DrivesScheduledWorker(t *testing.T) {
+	// Arrange
+	clk := mocks.NewFakeClock(time.Time{})
+	log, err := mocks.NewTestLogger()
+	require.NoError(t, err)
+	runs := make(chan time.Time)
+	job := worker.EveryClock("cleanup", time.Hour, clk, log, func(ctx context.Context) error {
+		runs <- clk.Now()
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- job.Run(ctx) }()
+	clk.BlockUntil(1)
+
+	// Act
+	clk.Advance(time.Hour)
+	first := <-runs
+	clk.Advance(time.Hour)
+	second := <-runs
+	cancel()
+
+	// Assert
+	assert.Equal(t, mocks.FakeClockEpoch.Add(time.Hour), first)
+	assert.Equal(t, mocks.FakeClockEpoch.Add(2*time.Hour), second)
+	assert.NoError(t, <-done)
+}
+
+func TestFakeClock_DrivesRefreshTokenExpiry(t *testing.T) {
+	// Arrange
+	clk := mocks.NewFakeClock(time.Time{})
+	store := memory.NewSessionStore().WithClock(clk)
+	service := sessions.NewService(store, sessions.Options{TTL: time.Hour, MaxAge: 90 * time.Minute, Clock: clk})
+	ctx := context.Background()
+	issued, err := service.Issue(ctx, "user-1")
+	require.NoError(t, err)
+
+	// Act - rotate before the TTL, then outlive the family lifetime
+	clk.Advance(59 * time.Minute)
+	rotated, rotateErr := service.Refresh(ctx, issued.RefreshToken)
+	require.NoError(t, rotateErr)
+	clk.Advance(31 * time.Minute)
+	_, expiredErr := service.Refresh(ctx, rotated.RefreshToken)
+
+	// Assert
+	assert.Equal(t, mocks.FakeClockEpoch.Add(time.Hour), issued.RefreshExpiresAt)
+	assert.Equal(t, mocks.FakeClockEpoch.Add(90*time.Minute), rotated.RefreshExpiresAt, "capped by the family lifetime")
+	assert.ErrorIs(t, expiredErr, sessions.ErrInvalidToken)
+}
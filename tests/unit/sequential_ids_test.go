@@ -0,0 +1,30 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/luminosita/change-me/pkg/ids"
+	"github.com/luminosita/change-me/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// ====================
+// Sequential IDs Tests
+// ====================
+
+type widget struct{}
+
+func (widget) IDFormat() ids.Format { return ids.UUID }
+
+func TestSequentialIDs(t *testing.T) {
+	// Arrange
+	gen := mocks.NewSequentialIDs()
+
+	// Act
+	first := ids.New[widget](gen)
+	second := ids.New[widget](gen)
+
+	// Assert
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", first.String())
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", second.String())
+}
@@ -0,0 +1,23 @@
+// Package web embeds the built frontend single-page application.
+//
+// The frontend build writes its output to web/dist; the placeholder
+// index.html committed here is replaced by the real build in CI. Assets
+// under dist/assets are expected to be fingerprinted and are cached forever.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// Dist returns the built frontend rooted at the document root.
+func Dist() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err) // dist is embedded at compile time
+	}
+	return sub
+}